@@ -39,6 +39,10 @@ func init() {
 		API:        API,
 		MethodName: jointPositions.String(),
 	}, newJointPositionsCollector)
+	data.RegisterCollector(data.MethodMetadata{
+		API:        API,
+		MethodName: configurationMetrics.String(),
+	}, newConfigurationMetricsCollector)
 }
 
 // SubtypeName is a constant that identifies the component resource API string "arm".
@@ -158,6 +158,37 @@ func (c *client) DoCommand(ctx context.Context, cmd map[string]interface{}) (map
 	return rprotoutils.DoFromResourceClient(ctx, c.client, c.name, cmd)
 }
 
+func (c *client) SetJointVelocities(ctx context.Context, velocitiesDegsPerSec []float64, extra map[string]interface{}) error {
+	velocities := make([]interface{}, 0, len(velocitiesDegsPerSec))
+	for _, v := range velocitiesDegsPerSec {
+		velocities = append(velocities, v)
+	}
+	_, err := c.DoCommand(ctx, map[string]interface{}{
+		"command":                 setJointVelocitiesCommand,
+		"velocities_degs_per_sec": velocities,
+		"extra":                   extra,
+	})
+	return err
+}
+
+func (c *client) MoveThroughJointPositions(
+	ctx context.Context, positions []*pb.JointPositions, options *MoveOptions, extra map[string]interface{},
+) error {
+	_, err := c.DoCommand(ctx, moveThroughJointPositionsToCommand(positions, options, extra))
+	return err
+}
+
+func (c *client) ConfigurationMetrics(ctx context.Context, extra map[string]interface{}) (*ConfigurationMetrics, error) {
+	resp, err := c.DoCommand(ctx, map[string]interface{}{
+		"command": getConfigurationMetricsCommand,
+		"extra":   extra,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return configurationMetricsFromCommand(resp)
+}
+
 func (c *client) IsMoving(ctx context.Context) (bool, error) {
 	resp, err := c.client.IsMoving(ctx, &pb.IsMovingRequest{Name: c.name})
 	if err != nil {
@@ -2,6 +2,7 @@ package arm_test
 
 import (
 	"context"
+	"math"
 	"net"
 	"testing"
 
@@ -33,12 +34,22 @@ func TestClient(t *testing.T) {
 	var (
 		capArmPos      spatialmath.Pose
 		capArmJointPos *componentpb.JointPositions
+		capVelocities  []float64
+		capPositions   []*componentpb.JointPositions
+		capOptions     *arm.MoveOptions
 		extraOptions   map[string]interface{}
 	)
 
 	pos1 := spatialmath.NewPoseFromPoint(r3.Vector{X: 1, Y: 2, Z: 3})
 	jointPos1 := &componentpb.JointPositions{Values: []float64{1.0, 2.0, 3.0}}
 	expectedGeometries := []spatialmath.Geometry{spatialmath.NewPoint(r3.Vector{1, 2, 3}, "")}
+	expectedMetrics := &arm.ConfigurationMetrics{
+		Manipulability:              0.02,
+		InverseConditionNumber:      0.1,
+		JointLimitMargins:           []float64{10, math.Inf(1), 300},
+		NormalizedJointLimitMargins: []float64{0.05, 1, 0.9},
+		NearestJointLimit:           0,
+	}
 	injectArm := &inject.Arm{}
 	injectArm.EndPositionFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
 		extraOptions = extra
@@ -72,6 +83,23 @@ func TestClient(t *testing.T) {
 	injectArm.GeometriesFunc = func(ctx context.Context) ([]spatialmath.Geometry, error) {
 		return expectedGeometries, nil
 	}
+	injectArm.SetJointVelocitiesFunc = func(ctx context.Context, velocitiesDegsPerSec []float64, extra map[string]interface{}) error {
+		capVelocities = velocitiesDegsPerSec
+		extraOptions = extra
+		return nil
+	}
+	injectArm.MoveThroughJointPositionsFunc = func(
+		ctx context.Context, positions []*componentpb.JointPositions, options *arm.MoveOptions, extra map[string]interface{},
+	) error {
+		capPositions = positions
+		capOptions = options
+		extraOptions = extra
+		return nil
+	}
+	injectArm.ConfigurationMetricsFunc = func(ctx context.Context, extra map[string]interface{}) (*arm.ConfigurationMetrics, error) {
+		extraOptions = extra
+		return expectedMetrics, nil
+	}
 
 	pos2 := spatialmath.NewPoseFromPoint(r3.Vector{X: 4, Y: 5, Z: 6})
 	jointPos2 := &componentpb.JointPositions{Values: []float64{4.0, 5.0, 6.0}}
@@ -176,6 +204,28 @@ func TestClient(t *testing.T) {
 		test.That(t, err.Error(), test.ShouldContainSubstring, errStopUnimplemented.Error())
 		test.That(t, extraOptions, test.ShouldResemble, map[string]interface{}{"foo": "Stop"})
 
+		controller, ok := arm1Client.(arm.JointVelocityController)
+		test.That(t, ok, test.ShouldBeTrue)
+		err = controller.SetJointVelocities(context.Background(), []float64{1, -2.5, 0}, map[string]interface{}{"foo": "SetJointVelocities"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, capVelocities, test.ShouldResemble, []float64{1, -2.5, 0})
+		test.That(t, extraOptions, test.ShouldResemble, map[string]interface{}{"foo": "SetJointVelocities"})
+
+		executor, ok := arm1Client.(arm.TrajectoryExecutor)
+		test.That(t, ok, test.ShouldBeTrue)
+		err = executor.MoveThroughJointPositions(context.Background(), []*componentpb.JointPositions{jointPos1, jointPos2},
+			&arm.MoveOptions{BlendRadiusMM: 5}, map[string]interface{}{"foo": "MoveThroughJointPositions"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, capPositions, test.ShouldHaveLength, 2)
+		test.That(t, capPositions[1].Values, test.ShouldResemble, jointPos2.Values)
+		test.That(t, capOptions, test.ShouldResemble, &arm.MoveOptions{BlendRadiusMM: 5})
+		test.That(t, extraOptions, test.ShouldResemble, map[string]interface{}{"foo": "MoveThroughJointPositions"})
+
+		metrics, err := arm.ConfigurationMetricsOf(context.Background(), arm1Client, map[string]interface{}{"foo": "ConfigurationMetrics"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, metrics, test.ShouldResemble, expectedMetrics)
+		test.That(t, extraOptions, test.ShouldResemble, map[string]interface{}{"foo": "ConfigurationMetrics"})
+
 		geometries, err := arm1Client.Geometries(context.Background(), map[string]interface{}{"foo": "Geometries"})
 		test.That(t, err, test.ShouldBeNil)
 		for i, geometry := range geometries {
@@ -199,6 +249,16 @@ func TestClient(t *testing.T) {
 		err = client2.Stop(context.Background(), nil)
 		test.That(t, err, test.ShouldBeNil)
 
+		err = arm.SetJointVelocities(context.Background(), client2, []float64{1, 2, 3}, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, arm.ErrJointVelocitiesUnsupported.Error())
+
+		// arms which cannot blend stop at every position
+		err = arm.MoveThroughJointPositions(context.Background(), client2, []*componentpb.JointPositions{jointPos2, jointPos1},
+			&arm.MoveOptions{BlendRadiusMM: 5}, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, capArmJointPos.Values, test.ShouldResemble, jointPos1.Values)
+
 		test.That(t, conn.Close(), test.ShouldBeNil)
 	})
 }
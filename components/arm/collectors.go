@@ -18,6 +18,7 @@ type method int64
 const (
 	endPosition method = iota
 	jointPositions
+	configurationMetrics
 )
 
 func (m method) String() string {
@@ -26,6 +27,8 @@ func (m method) String() string {
 		return "EndPosition"
 	case jointPositions:
 		return "JointPositions"
+	case configurationMetrics:
+		return "ConfigurationMetrics"
 	}
 	return "Unknown"
 }
@@ -89,6 +92,30 @@ func newJointPositionsCollector(resource interface{}, params data.CollectorParam
 	return data.NewCollector(cFunc, params)
 }
 
+// newConfigurationMetricsCollector returns a collector to register a configuration metrics method, so that how close
+// the arm comes to singular configurations and joint limits can be captured as it moves. If one is already registered
+// with the same MethodMetadata it will panic.
+func newConfigurationMetricsCollector(resource interface{}, params data.CollectorParams) (data.Collector, error) {
+	arm, err := assertArm(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	cFunc := data.CaptureFunc(func(ctx context.Context, _ map[string]*anypb.Any) (interface{}, error) {
+		metrics, err := ConfigurationMetricsOf(ctx, arm, data.FromDMExtraMap)
+		if err != nil {
+			// A modular filter component can be created to filter the readings from a component. The error ErrNoCaptureToStore
+			// is used in the datamanager to exclude readings from being captured and stored.
+			if errors.Is(err, data.ErrNoCaptureToStore) {
+				return nil, err
+			}
+			return nil, data.FailedToReadErr(params.ComponentName, configurationMetrics.String(), err)
+		}
+		return configurationMetricsToCommand(metrics), nil
+	})
+	return data.NewCollector(cFunc, params)
+}
+
 func assertArm(resource interface{}) (Arm, error) {
 	arm, ok := resource.(Arm)
 	if !ok {
@@ -2,6 +2,7 @@ package arm_test
 
 import (
 	"context"
+	"math"
 	"testing"
 	"time"
 
@@ -57,6 +58,17 @@ func TestCollectors(t *testing.T) {
 				},
 			}),
 		},
+		{
+			name:      "Configuration metrics collector should write the metrics",
+			collector: arm.NewConfigurationMetricsCollector,
+			expected: map[string]any{
+				"manipulability":                 0.5,
+				"inverse_condition_number":       0.25,
+				"joint_limit_margins":            []any{10., nil},
+				"normalized_joint_limit_margins": []any{0.1, 1.},
+				"nearest_joint_limit":            0.,
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -98,5 +110,14 @@ func newArm() arm.Arm {
 			Values: floatList,
 		}, nil
 	}
+	a.ConfigurationMetricsFunc = func(ctx context.Context, extra map[string]interface{}) (*arm.ConfigurationMetrics, error) {
+		return &arm.ConfigurationMetrics{
+			Manipulability:              0.5,
+			InverseConditionNumber:      0.25,
+			JointLimitMargins:           []float64{10, math.Inf(1)},
+			NormalizedJointLimitMargins: []float64{0.1, 1},
+			NearestJointLimit:           0,
+		}, nil
+	}
 	return a
 }
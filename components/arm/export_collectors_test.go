@@ -3,6 +3,7 @@ package arm
 
 // Exported variables for testing collectors, see unexported collectors for implementation details.
 var (
-	NewEndPositionCollector    = newEndPositionCollector
-	NewJointPositionsCollector = newJointPositionsCollector
+	NewEndPositionCollector          = newEndPositionCollector
+	NewJointPositionsCollector       = newJointPositionsCollector
+	NewConfigurationMetricsCollector = newConfigurationMetricsCollector
 )
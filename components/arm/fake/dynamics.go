@@ -0,0 +1,104 @@
+package fake
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// jointMotion is a move of the joints from start to goal which follows a trapezoidal velocity profile. The joints
+// are synchronized, so every joint starts and finishes together and only the joint moving furthest reaches the
+// velocity and acceleration limits.
+type jointMotion struct {
+	start []float64
+	goal  []float64
+	began time.Time
+
+	// distance, velocity and acceleration describe the profile of the joint moving furthest, and duration is how
+	// long it takes to move that far.
+	distance     float64
+	velocity     float64
+	acceleration float64
+	duration     time.Duration
+}
+
+// newJointMotion plans a move from start to goal within the velocity and acceleration limits, either of which may
+// be zero for no limit.
+func newJointMotion(start, goal []float64, maxVelocity, maxAcceleration float64) *jointMotion {
+	m := &jointMotion{
+		start:        append([]float64(nil), start...),
+		goal:         append([]float64(nil), goal...),
+		began:        time.Now(),
+		velocity:     math.Inf(1),
+		acceleration: math.Inf(1),
+	}
+	if maxVelocity > 0 {
+		m.velocity = maxVelocity
+	}
+	if maxAcceleration > 0 {
+		m.acceleration = maxAcceleration
+	}
+	for i := range start {
+		m.distance = math.Max(m.distance, math.Abs(goal[i]-start[i]))
+	}
+
+	var seconds float64
+	switch {
+	case m.distance == 0:
+	case math.IsInf(m.acceleration, 1):
+		seconds = m.distance / m.velocity
+	case m.distance < m.velocity*m.velocity/m.acceleration:
+		// the joint never reaches the velocity limit, so it speeds up for half the move and slows down for the rest
+		m.velocity = math.Sqrt(m.distance * m.acceleration)
+		seconds = 2 * m.velocity / m.acceleration
+	default:
+		seconds = m.distance/m.velocity + m.velocity/m.acceleration
+	}
+	m.duration = time.Duration(seconds * float64(time.Second))
+	return m
+}
+
+// positionsAt returns the joint positions at the given time and whether the move is done.
+func (m *jointMotion) positionsAt(t time.Time) ([]float64, bool) {
+	elapsed := t.Sub(m.began)
+	if elapsed >= m.duration {
+		return append([]float64(nil), m.goal...), true
+	}
+	fraction := m.traveled(elapsed.Seconds()) / m.distance
+	positions := make([]float64, len(m.start))
+	for i := range m.start {
+		positions[i] = m.start[i] + fraction*(m.goal[i]-m.start[i])
+	}
+	return positions, false
+}
+
+// traveled returns how far the joint moving furthest has gone after the given number of seconds.
+func (m *jointMotion) traveled(seconds float64) float64 {
+	if math.IsInf(m.acceleration, 1) {
+		return m.velocity * seconds
+	}
+	total := m.duration.Seconds()
+	rampTime := m.velocity / m.acceleration
+	switch {
+	case seconds < rampTime:
+		return m.acceleration * seconds * seconds / 2
+	case seconds > total-rampTime:
+		remaining := total - seconds
+		return m.distance - m.acceleration*remaining*remaining/2
+	default:
+		return m.velocity*rampTime/2 + m.velocity*(seconds-rampTime)
+	}
+}
+
+// withTrackingError returns the positions offset by a uniformly random error of at most maxError degrees per joint,
+// like a controller which settles short of or past its goal.
+func withTrackingError(positions []float64, maxError float64) []float64 {
+	if maxError <= 0 {
+		return positions
+	}
+	offset := make([]float64, len(positions))
+	for i, p := range positions {
+		offset[i] = p + (2*rand.Float64()-1)*maxError //nolint:gosec
+	}
+	return offset
+}
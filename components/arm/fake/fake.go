@@ -4,18 +4,23 @@ package fake
 import (
 	"context"
 	_ "embed"
+	"math"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	pb "go.viam.com/api/component/arm/v1"
+	"go.viam.com/utils"
 
 	"go.viam.com/rdk/components/arm"
 	"go.viam.com/rdk/components/arm/eva"
 	ur "go.viam.com/rdk/components/arm/universalrobots"
 	"go.viam.com/rdk/components/arm/xarm"
+	"go.viam.com/rdk/components/fakeconditions"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/operation"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/referenceframe/urdf"
 	"go.viam.com/rdk/resource"
@@ -40,6 +45,16 @@ var dofbotjson []byte
 type Config struct {
 	ArmModel      string `json:"arm-model,omitempty"`
 	ModelFilePath string `json:"model-path,omitempty"`
+
+	// MaxJointVelocityDegsPerSec and MaxJointAccelerationDegsPerSecPerSec make the arm take time to reach the
+	// joint positions it is moved to. Without either the arm is at its goal as soon as it is moved.
+	MaxJointVelocityDegsPerSec           float64 `json:"max_joint_velocity_degs_per_sec,omitempty"`
+	MaxJointAccelerationDegsPerSecPerSec float64 `json:"max_joint_acceleration_degs_per_sec_per_sec,omitempty"`
+	// TrackingErrorDegs is the most each joint may settle away from the position it is moved to.
+	TrackingErrorDegs float64 `json:"tracking_error_degs,omitempty"`
+
+	// Conditions simulates the latency of every call and the noise of joint positions, in degrees.
+	fakeconditions.Config `json:",squash"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -53,7 +68,23 @@ func (conf *Config) Validate(path string) ([]string, error) {
 	case conf.ArmModel == "" && conf.ModelFilePath != "":
 		_, err = modelFromPath(conf.ModelFilePath, "")
 	}
-	return nil, err
+	if err != nil {
+		return nil, err
+	}
+	if conf.MaxJointVelocityDegsPerSec < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("max_joint_velocity_degs_per_sec cannot be negative"))
+	}
+	if conf.MaxJointAccelerationDegsPerSecPerSec < 0 {
+		return nil, resource.NewConfigValidationError(path,
+			errors.New("max_joint_acceleration_degs_per_sec_per_sec cannot be negative"))
+	}
+	if conf.TrackingErrorDegs < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("tracking_error_degs cannot be negative"))
+	}
+	if err := conf.Config.Validate(path); err != nil {
+		return nil, err
+	}
+	return nil, nil
 }
 
 func init() {
@@ -67,6 +98,7 @@ func NewArm(ctx context.Context, deps resource.Dependencies, conf resource.Confi
 	a := &Arm{
 		Named:  conf.ResourceName().AsNamed(),
 		logger: logger,
+		opMgr:  operation.NewSingleOperationManager(),
 	}
 	if err := a.Reconfigure(ctx, deps, conf); err != nil {
 		return nil, err
@@ -102,10 +134,25 @@ type Arm struct {
 	resource.Named
 	CloseCount int
 	logger     logging.Logger
+	opMgr      *operation.SingleOperationManager
 
 	mu     sync.RWMutex
 	joints *pb.JointPositions
 	model  referenceframe.Model
+
+	// motion is the move in progress, which has not yet been applied to joints.
+	motion          *jointMotion
+	maxVelocity     float64
+	maxAcceleration float64
+	trackingError   float64
+
+	// velocities are the joint velocities being streamed, in degrees per second, which have moved the joints since
+	// velocitiesSince.
+	velocities       []float64
+	velocitiesSince  time.Time
+	velocityWatchdog *arm.VelocityWatchdog
+
+	conditions *fakeconditions.Conditions
 }
 
 // Reconfigure atomically reconfigures this arm in place based on the new config.
@@ -131,6 +178,12 @@ func (a *Arm) Reconfigure(ctx context.Context, deps resource.Dependencies, conf
 	defer a.mu.Unlock()
 	a.joints = &pb.JointPositions{Values: make([]float64, dof)}
 	a.model = model
+	a.velocities = nil
+	a.motion = nil
+	a.maxVelocity = newConf.MaxJointVelocityDegsPerSec
+	a.maxAcceleration = newConf.MaxJointAccelerationDegsPerSecPerSec
+	a.trackingError = newConf.TrackingErrorDegs
+	a.conditions = fakeconditions.New(newConf.Config)
 
 	return nil
 }
@@ -142,6 +195,14 @@ func (a *Arm) ModelFrame() referenceframe.Model {
 	return a.model
 }
 
+// wait waits out the simulated latency of a call.
+func (a *Arm) wait(ctx context.Context) error {
+	a.mu.RLock()
+	conditions := a.conditions
+	a.mu.RUnlock()
+	return conditions.Wait(ctx)
+}
+
 // EndPosition returns the set position.
 func (a *Arm) EndPosition(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
 	joints, err := a.JointPositions(ctx, extra)
@@ -158,37 +219,160 @@ func (a *Arm) MoveToPosition(ctx context.Context, pos spatialmath.Pose, extra ma
 	return arm.Move(ctx, a.logger, a, pos)
 }
 
-// MoveToJointPositions sets the joints.
+// MoveToJointPositions sets the joints, or moves them there over time if the arm has joint velocity or
+// acceleration limits.
 func (a *Arm) MoveToJointPositions(ctx context.Context, joints *pb.JointPositions, extra map[string]interface{}) error {
+	ctx, done := a.opMgr.New(ctx)
+	defer done()
+	if err := a.wait(ctx); err != nil {
+		return err
+	}
+
 	inputs := a.model.InputFromProtobuf(joints)
 	if err := arm.CheckDesiredJointPositions(ctx, a, inputs); err != nil {
 		return err
 	}
-	a.mu.RLock()
-	defer a.mu.RUnlock()
+	a.mu.Lock()
 	pos, err := a.model.Transform(inputs)
 	if err != nil {
+		a.mu.Unlock()
 		return err
 	}
 	_ = pos
-	copy(a.joints.Values, joints.Values)
+	// a move ends any joint velocity stream
+	if a.velocityWatchdog != nil {
+		a.velocityWatchdog.Disarm()
+	}
+	a.integrateVelocities()
+	a.velocities = nil
+	a.settleMotion()
+
+	goal := append([]float64(nil), a.joints.Values...)
+	copy(goal, joints.Values)
+	goal = withTrackingError(goal, a.trackingError)
+	if a.maxVelocity == 0 && a.maxAcceleration == 0 {
+		copy(a.joints.Values, goal)
+		a.mu.Unlock()
+		return nil
+	}
+	motion := newJointMotion(a.joints.Values, goal, a.maxVelocity, a.maxAcceleration)
+	a.motion = motion
+	a.mu.Unlock()
+
+	finished := utils.SelectContextOrWait(ctx, motion.duration)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.motion == motion {
+		a.settleMotion()
+		// a cancelled move stops the arm where it is
+		a.motion = nil
+	}
+	if !finished {
+		return ctx.Err()
+	}
 	return nil
 }
 
 // JointPositions returns joints.
 func (a *Arm) JointPositions(ctx context.Context, extra map[string]interface{}) (*pb.JointPositions, error) {
-	retJoint := &pb.JointPositions{Values: a.joints.Values}
+	if err := a.wait(ctx); err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.integrateVelocities()
+	a.settleMotion()
+	retJoint := &pb.JointPositions{Values: a.conditions.NoiseSlice(a.joints.Values)}
 	return retJoint, nil
 }
 
-// Stop doesn't do anything for a fake arm.
+// SetJointVelocities moves the joints at the given velocities until Stop is called or the stream stalls. Velocities
+// beyond the arm's joint velocity limit are capped at it.
+func (a *Arm) SetJointVelocities(ctx context.Context, velocitiesDegsPerSec []float64, extra map[string]interface{}) error {
+	a.opMgr.CancelRunning(ctx)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(velocitiesDegsPerSec) != len(a.joints.Values) {
+		return errors.Errorf("fake arm has %d joints but %d joint velocities were given",
+			len(a.joints.Values), len(velocitiesDegsPerSec))
+	}
+	a.integrateVelocities()
+	a.settleMotion()
+	a.motion = nil
+	a.velocities = append([]float64(nil), velocitiesDegsPerSec...)
+	if a.maxVelocity > 0 {
+		for i, v := range a.velocities {
+			a.velocities[i] = math.Max(-a.maxVelocity, math.Min(v, a.maxVelocity))
+		}
+	}
+	a.velocitiesSince = time.Now()
+	if a.velocityWatchdog == nil {
+		a.velocityWatchdog = arm.NewVelocityWatchdog(arm.DefaultVelocityStreamTimeout, func() {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			a.integrateVelocities()
+			a.velocities = nil
+		})
+	}
+	a.velocityWatchdog.Feed()
+	return nil
+}
+
+// integrateVelocities moves the joints by the streamed velocities, and must be called with mu held.
+func (a *Arm) integrateVelocities() {
+	if a.velocities == nil {
+		return
+	}
+	now := time.Now()
+	dt := now.Sub(a.velocitiesSince).Seconds()
+	for i, v := range a.velocities {
+		a.joints.Values[i] += v * dt
+	}
+	a.velocitiesSince = now
+}
+
+// settleMotion moves the joints to where the move in progress has taken them, and must be called with mu held.
+func (a *Arm) settleMotion() {
+	if a.motion == nil {
+		return
+	}
+	positions, done := a.motion.positionsAt(time.Now())
+	copy(a.joints.Values, positions)
+	if done {
+		a.motion = nil
+	}
+}
+
+// Stop halts the arm wherever it is, whether it is moving to joint positions or at streamed joint velocities.
 func (a *Arm) Stop(ctx context.Context, extra map[string]interface{}) error {
+	a.opMgr.CancelRunning(ctx)
+	if err := a.wait(ctx); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.velocityWatchdog != nil {
+		a.velocityWatchdog.Disarm()
+	}
+	a.integrateVelocities()
+	a.velocities = nil
+	a.settleMotion()
+	a.motion = nil
 	return nil
 }
 
-// IsMoving is always false for a fake arm.
+// IsMoving returns whether the fake arm is moving to joint positions or at streamed joint velocities.
 func (a *Arm) IsMoving(ctx context.Context) (bool, error) {
-	return false, nil
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.velocities != nil {
+		return true, nil
+	}
+	if a.motion == nil {
+		return false, nil
+	}
+	_, done := a.motion.positionsAt(time.Now())
+	return !done, nil
 }
 
 // CurrentInputs TODO.
@@ -202,6 +386,8 @@ func (a *Arm) CurrentInputs(ctx context.Context) ([]referenceframe.Input, error)
 
 // GoToInputs TODO.
 func (a *Arm) GoToInputs(ctx context.Context, inputSteps ...[]referenceframe.Input) error {
+	ctx, done := a.opMgr.New(ctx)
+	defer done()
 	for _, goal := range inputSteps {
 		a.mu.RLock()
 		positionDegs := a.model.ProtobufFromInput(goal)
@@ -217,10 +403,17 @@ func (a *Arm) GoToInputs(ctx context.Context, inputSteps ...[]referenceframe.Inp
 	return nil
 }
 
-// Close does nothing.
+// Close stops the arm.
 func (a *Arm) Close(ctx context.Context) error {
+	// an arm made without NewArm, such as in tests, has no operation manager
+	if a.opMgr != nil {
+		a.opMgr.CancelRunning(ctx)
+	}
 	a.mu.Lock()
 	defer a.mu.Unlock()
+	if a.velocityWatchdog != nil {
+		a.velocityWatchdog.Close()
+	}
 	a.CloseCount++
 	return nil
 }
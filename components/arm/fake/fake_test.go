@@ -3,10 +3,13 @@ package fake
 import (
 	"context"
 	"testing"
+	"time"
 
 	pb "go.viam.com/api/component/arm/v1"
 	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
 
+	"go.viam.com/rdk/components/fakeconditions"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
@@ -101,3 +104,158 @@ func TestReconfigure(t *testing.T) {
 	test.That(t, fakeArm.joints.Values, test.ShouldResemble, modelJoints)
 	test.That(t, fakeArm.model, test.ShouldResemble, model)
 }
+
+func TestSetJointVelocities(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	cfg := resource.Config{
+		Name:                "testArm",
+		ConvertedAttributes: &Config{ArmModel: "ur5e"},
+	}
+	a, err := NewArm(context.Background(), nil, cfg, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer a.Close(context.Background())
+	fakeArm := a.(*Arm)
+
+	err = fakeArm.SetJointVelocities(context.Background(), []float64{10}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "6 joints")
+
+	velocities := []float64{100, -100, 0, 0, 0, 0}
+	for i := 0; i < 5; i++ {
+		test.That(t, fakeArm.SetJointVelocities(context.Background(), velocities, nil), test.ShouldBeNil)
+		time.Sleep(20 * time.Millisecond)
+	}
+	moving, err := fakeArm.IsMoving(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeTrue)
+	joints, err := fakeArm.JointPositions(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, joints.Values[0], test.ShouldBeGreaterThan, 5)
+	test.That(t, joints.Values[1], test.ShouldBeLessThan, -5)
+	test.That(t, joints.Values[2], test.ShouldEqual, 0)
+
+	// the stream stalls, so the watchdog stops the arm
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		moving, err := fakeArm.IsMoving(context.Background())
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, moving, test.ShouldBeFalse)
+	})
+	stalled, err := fakeArm.JointPositions(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	time.Sleep(50 * time.Millisecond)
+	joints, err = fakeArm.JointPositions(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, joints.Values, test.ShouldResemble, stalled.Values)
+
+	// Stop ends a stream right away
+	test.That(t, fakeArm.SetJointVelocities(context.Background(), velocities, nil), test.ShouldBeNil)
+	test.That(t, fakeArm.Stop(context.Background(), nil), test.ShouldBeNil)
+	moving, err = fakeArm.IsMoving(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeFalse)
+}
+
+func TestJointDynamics(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	cfg := resource.Config{
+		Name: "testArm",
+		ConvertedAttributes: &Config{
+			ArmModel:                             "ur5e",
+			MaxJointVelocityDegsPerSec:           100,
+			MaxJointAccelerationDegsPerSecPerSec: 1000,
+		},
+	}
+	a, err := NewArm(context.Background(), nil, cfg, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer a.Close(context.Background())
+
+	// 0.1s to reach full speed, 0.4s at it and 0.1s to stop
+	goal := &pb.JointPositions{Values: []float64{50, -25, 0, 0, 0, 0}}
+	start := time.Now()
+	test.That(t, a.MoveToJointPositions(context.Background(), goal, nil), test.ShouldBeNil)
+	test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 600*time.Millisecond)
+	joints, err := a.JointPositions(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, joints.Values, test.ShouldResemble, goal.Values)
+
+	// Stop cancels the move part of the way there
+	moveErr := make(chan error, 1)
+	go func() {
+		moveErr <- a.MoveToJointPositions(context.Background(), &pb.JointPositions{Values: make([]float64, 6)}, nil)
+	}()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		moving, err := a.IsMoving(context.Background())
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, moving, test.ShouldBeTrue)
+	})
+	time.Sleep(100 * time.Millisecond)
+	test.That(t, a.Stop(context.Background(), nil), test.ShouldBeNil)
+	test.That(t, <-moveErr, test.ShouldNotBeNil)
+	moving, err := a.IsMoving(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeFalse)
+	joints, err = a.JointPositions(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, joints.Values[0], test.ShouldBeBetween, 0, 50)
+	test.That(t, joints.Values[1], test.ShouldAlmostEqual, -joints.Values[0]/2, 1e-6)
+
+	// tracking error leaves the joints near but not at their goal
+	cfg.ConvertedAttributes = &Config{ArmModel: "ur5e", TrackingErrorDegs: 0.5}
+	test.That(t, a.Reconfigure(context.Background(), nil, cfg), test.ShouldBeNil)
+	test.That(t, a.MoveToJointPositions(context.Background(), goal, nil), test.ShouldBeNil)
+	joints, err = a.JointPositions(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	for i, v := range joints.Values {
+		test.That(t, v, test.ShouldAlmostEqual, goal.Values[i], 0.5)
+	}
+}
+
+func TestJointMotionProfile(t *testing.T) {
+	// too short a move to reach the velocity limit
+	m := newJointMotion([]float64{0, 0}, []float64{10, 5}, 100, 1000)
+	test.That(t, m.duration.Seconds(), test.ShouldAlmostEqual, 0.2, 1e-6)
+	test.That(t, m.traveled(0.1), test.ShouldAlmostEqual, 5, 1e-6)
+	positions, done := m.positionsAt(m.began.Add(100 * time.Millisecond))
+	test.That(t, done, test.ShouldBeFalse)
+	test.That(t, positions[0], test.ShouldAlmostEqual, 5, 1e-6)
+	test.That(t, positions[1], test.ShouldAlmostEqual, 2.5, 1e-6)
+
+	m = newJointMotion([]float64{0}, []float64{-100}, 100, 1000)
+	test.That(t, m.duration.Seconds(), test.ShouldAlmostEqual, 1.1, 1e-6)
+	test.That(t, m.traveled(0.55), test.ShouldAlmostEqual, 50, 1e-6)
+	positions, done = m.positionsAt(m.began.Add(2 * time.Second))
+	test.That(t, done, test.ShouldBeTrue)
+	test.That(t, positions, test.ShouldResemble, []float64{-100})
+
+	m = newJointMotion([]float64{0}, []float64{30}, 60, 0)
+	test.That(t, m.duration.Seconds(), test.ShouldAlmostEqual, 0.5, 1e-6)
+}
+
+func TestConditions(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	cfg := resource.Config{
+		Name: "testArm",
+		ConvertedAttributes: &Config{
+			ArmModel: "ur5e",
+			Config:   fakeconditions.Config{LatencyMS: 20, NoiseStdDev: 0.1, Seed: 1},
+		},
+	}
+	a, err := NewArm(context.Background(), nil, cfg, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer a.Close(context.Background())
+
+	start := time.Now()
+	joints, err := a.JointPositions(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 20*time.Millisecond)
+	for _, v := range joints.Values {
+		test.That(t, v, test.ShouldNotEqual, 0)
+		test.That(t, v, test.ShouldAlmostEqual, 0, 1)
+	}
+
+	cfg.ConvertedAttributes = &Config{ArmModel: "ur5e", Config: fakeconditions.Config{JitterMS: -1}}
+	_, err = cfg.ConvertedAttributes.(*Config).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,431 @@
+// Package guard implements an arm which checks every command sent to another arm against the joint limits and
+// static obstacles of its cell, so clients driving the arm directly cannot bypass the safety the motion service
+// would give them.
+package guard
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/pkg/errors"
+	pb "go.viam.com/api/component/arm/v1"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/operation"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("guarded_arm")
+
+const (
+	// defaultResolutionDegs is the largest joint step between the positions checked for collisions along a move.
+	defaultResolutionDegs = 2.
+	// defaultLookaheadSec is how far ahead joint velocities are followed to find where they will take the arm.
+	defaultLookaheadSec = 0.25
+)
+
+// JointLimit is the range, in degrees or millimeters, a joint may be commanded to.
+type JointLimit struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// Config is used for converting config attributes.
+type Config struct {
+	ArmName string `json:"arm-name"`
+	// Obstacles are the static geometries of the cell, in the frame of the arm's base.
+	Obstacles []spatialmath.GeometryConfig `json:"obstacles,omitempty"`
+	// JointLimits narrows the limits of the arm's kinematic model, one per joint.
+	JointLimits []JointLimit `json:"joint_limits,omitempty"`
+	// ClampToLimits moves joints commanded past their limits to the limits instead of rejecting the command.
+	ClampToLimits bool `json:"clamp_to_limits,omitempty"`
+	// ResolutionDegs is the largest joint step between the positions checked for collisions along a move.
+	ResolutionDegs float64 `json:"resolution_degs,omitempty"`
+	// LookaheadSec is how far ahead joint velocities are followed to find where they will take the arm.
+	LookaheadSec float64 `json:"lookahead_sec,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if cfg.ArmName == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "arm-name")
+	}
+	for i := range cfg.Obstacles {
+		if _, err := cfg.Obstacles[i].ParseConfig(); err != nil {
+			return nil, resource.NewConfigValidationError(fmt.Sprintf("%s.obstacles.%d", path, i), err)
+		}
+	}
+	for i, limit := range cfg.JointLimits {
+		if limit.Min > limit.Max {
+			return nil, resource.NewConfigValidationError(path,
+				errors.Errorf("joint_limits %d has a min of %v above its max of %v", i, limit.Min, limit.Max))
+		}
+	}
+	if cfg.ResolutionDegs < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("resolution_degs cannot be negative"))
+	}
+	if cfg.LookaheadSec < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("lookahead_sec cannot be negative"))
+	}
+	return []string{cfg.ArmName}, nil
+}
+
+func init() {
+	resource.RegisterComponent(arm.API, model, resource.Registration[arm.Arm, *Config]{
+		Constructor: NewGuardedArm,
+	})
+}
+
+// Arm passes commands on to another arm once they are checked against the joint limits and obstacles. Joint
+// positions past the limits are clamped or rejected, and moves which would take the arm through an obstacle are
+// rejected, as are joint velocities which would do so within the lookahead. DoCommand is passed on unchecked.
+type Arm struct {
+	resource.Named
+	resource.TriviallyCloseable
+	logger logging.Logger
+	opMgr  *operation.SingleOperationManager
+
+	mu         sync.RWMutex
+	actual     arm.Arm
+	limits     []referenceframe.Limit
+	obstacles  []spatialmath.Geometry
+	clamp      bool
+	resolution float64
+	lookahead  float64
+}
+
+// NewGuardedArm returns an arm which guards another arm.
+func NewGuardedArm(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (arm.Arm, error) {
+	a := &Arm{
+		Named:  conf.ResourceName().AsNamed(),
+		logger: logger,
+		opMgr:  operation.NewSingleOperationManager(),
+	}
+	if err := a.Reconfigure(ctx, deps, conf); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Reconfigure atomically reconfigures this arm in place based on the new config.
+func (a *Arm) Reconfigure(ctx context.Context, deps resource.Dependencies, conf resource.Config) error {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return err
+	}
+	actual, err := arm.FromDependencies(deps, newConf.ArmName)
+	if err != nil {
+		return err
+	}
+	m := actual.ModelFrame()
+	if m == nil {
+		return errors.Errorf("arm %q has no kinematic model to guard", newConf.ArmName)
+	}
+
+	limits := append([]referenceframe.Limit(nil), m.DoF()...)
+	if len(newConf.JointLimits) != 0 {
+		if len(newConf.JointLimits) != len(limits) {
+			return errors.Errorf("arm %q has %d joints but %d joint_limits were given",
+				newConf.ArmName, len(limits), len(newConf.JointLimits))
+		}
+		lows := make([]float64, 0, len(limits))
+		highs := make([]float64, 0, len(limits))
+		for _, limit := range newConf.JointLimits {
+			lows = append(lows, limit.Min)
+			highs = append(highs, limit.Max)
+		}
+		lowInputs := m.InputFromProtobuf(&pb.JointPositions{Values: lows})
+		highInputs := m.InputFromProtobuf(&pb.JointPositions{Values: highs})
+		for i := range limits {
+			limits[i].Min = math.Max(limits[i].Min, lowInputs[i].Value)
+			limits[i].Max = math.Min(limits[i].Max, highInputs[i].Value)
+		}
+	}
+
+	obstacles := make([]spatialmath.Geometry, 0, len(newConf.Obstacles))
+	for i := range newConf.Obstacles {
+		obstacle, err := newConf.Obstacles[i].ParseConfig()
+		if err != nil {
+			return err
+		}
+		obstacles = append(obstacles, obstacle)
+	}
+
+	resolution := newConf.ResolutionDegs
+	if resolution == 0 {
+		resolution = defaultResolutionDegs
+	}
+	lookahead := newConf.LookaheadSec
+	if lookahead == 0 {
+		lookahead = defaultLookaheadSec
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.actual = actual
+	a.limits = limits
+	a.obstacles = obstacles
+	a.clamp = newConf.ClampToLimits
+	a.resolution = utils.DegToRad(resolution)
+	a.lookahead = lookahead
+	return nil
+}
+
+// ModelFrame returns the dynamic frame of the guarded arm.
+func (a *Arm) ModelFrame() referenceframe.Model {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.actual.ModelFrame()
+}
+
+// EndPosition returns the end position of the guarded arm.
+func (a *Arm) EndPosition(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.actual.EndPosition(ctx, extra)
+}
+
+// MoveToPosition plans a move to the pose and runs it through the guard.
+func (a *Arm) MoveToPosition(ctx context.Context, pos spatialmath.Pose, extra map[string]interface{}) error {
+	ctx, done := a.opMgr.New(ctx)
+	defer done()
+	return arm.Move(ctx, a.logger, a, pos)
+}
+
+// MoveToJointPositions moves the guarded arm to the joints, once they are checked.
+func (a *Arm) MoveToJointPositions(ctx context.Context, joints *pb.JointPositions, extra map[string]interface{}) error {
+	ctx, done := a.opMgr.New(ctx)
+	defer done()
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	checked, err := a.checkPath(ctx, []*pb.JointPositions{joints})
+	if err != nil {
+		return err
+	}
+	return a.actual.MoveToJointPositions(ctx, checked[0], extra)
+}
+
+// MoveThroughJointPositions moves the guarded arm through the joints, once the whole path is checked. Blending
+// cuts the corners of the path, so the check is only as good as the blend radius is small next to the obstacles.
+func (a *Arm) MoveThroughJointPositions(
+	ctx context.Context, positions []*pb.JointPositions, options *arm.MoveOptions, extra map[string]interface{},
+) error {
+	ctx, done := a.opMgr.New(ctx)
+	defer done()
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	checked, err := a.checkPath(ctx, positions)
+	if err != nil {
+		return err
+	}
+	return arm.MoveThroughJointPositions(ctx, a.actual, checked, options, extra)
+}
+
+// SetJointVelocities sets the joint velocities of the guarded arm. Joints which would pass their limits within the
+// lookahead are held, or the command rejected, and the arm is stopped if the velocities would take it into an
+// obstacle.
+func (a *Arm) SetJointVelocities(ctx context.Context, velocitiesDegsPerSec []float64, extra map[string]interface{}) error {
+	a.opMgr.CancelRunning(ctx)
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	m := a.actual.ModelFrame()
+	if len(velocitiesDegsPerSec) != len(a.limits) {
+		return errors.Errorf("arm has %d joints but %d joint velocities were given", len(a.limits), len(velocitiesDegsPerSec))
+	}
+	current, err := a.currentInputs(ctx)
+	if err != nil {
+		return err
+	}
+	velocities := m.InputFromProtobuf(&pb.JointPositions{Values: velocitiesDegsPerSec})
+	checked := append([]float64(nil), velocitiesDegsPerSec...)
+	ahead := make([]referenceframe.Input, len(current))
+	for i, v := range velocities {
+		ahead[i] = referenceframe.Input{Value: current[i].Value + v.Value*a.lookahead}
+		limit := a.limits[i]
+		if (ahead[i].Value > limit.Max && v.Value > 0) || (ahead[i].Value < limit.Min && v.Value < 0) {
+			if !a.clamp {
+				return errors.Errorf("joint %d would pass its limit moving at %v", i, velocitiesDegsPerSec[i])
+			}
+			checked[i] = 0
+			ahead[i] = current[i]
+		}
+	}
+	if err := a.checkCollisions(m, ahead); err != nil {
+		return stopRejected(ctx, a.actual, err)
+	}
+	return arm.SetJointVelocities(ctx, a.actual, checked, extra)
+}
+
+// stopRejected stops the arm after a command is rejected for heading into an obstacle.
+func stopRejected(ctx context.Context, actual arm.Arm, err error) error {
+	if stopErr := actual.Stop(ctx, nil); stopErr != nil {
+		return errors.Wrapf(err, "and failed to stop: %v", stopErr)
+	}
+	return err
+}
+
+// JointPositions returns the joints of the guarded arm.
+func (a *Arm) JointPositions(ctx context.Context, extra map[string]interface{}) (*pb.JointPositions, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.actual.JointPositions(ctx, extra)
+}
+
+// Stop stops the guarded arm.
+func (a *Arm) Stop(ctx context.Context, extra map[string]interface{}) error {
+	a.opMgr.CancelRunning(ctx)
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.actual.Stop(ctx, extra)
+}
+
+// IsMoving returns whether the guarded arm is moving.
+func (a *Arm) IsMoving(ctx context.Context) (bool, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.actual.IsMoving(ctx)
+}
+
+// CurrentInputs returns the current inputs of the guarded arm.
+func (a *Arm) CurrentInputs(ctx context.Context) ([]referenceframe.Input, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.currentInputs(ctx)
+}
+
+func (a *Arm) currentInputs(ctx context.Context) ([]referenceframe.Input, error) {
+	joints, err := a.actual.JointPositions(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return a.actual.ModelFrame().InputFromProtobuf(joints), nil
+}
+
+// GoToInputs moves the guarded arm through the goal inputs, once the whole path is checked.
+func (a *Arm) GoToInputs(ctx context.Context, inputSteps ...[]referenceframe.Input) error {
+	ctx, done := a.opMgr.New(ctx)
+	defer done()
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	m := a.actual.ModelFrame()
+	positions := make([]*pb.JointPositions, 0, len(inputSteps))
+	for _, goal := range inputSteps {
+		positions = append(positions, m.ProtobufFromInput(goal))
+	}
+	checked, err := a.checkPath(ctx, positions)
+	if err != nil {
+		return err
+	}
+	for _, joints := range checked {
+		if err := a.actual.MoveToJointPositions(ctx, joints, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Geometries returns the geometries of the guarded arm.
+func (a *Arm) Geometries(ctx context.Context, extra map[string]interface{}) ([]spatialmath.Geometry, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.actual.Geometries(ctx, extra)
+}
+
+// DoCommand passes the command on to the guarded arm.
+func (a *Arm) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.actual.DoCommand(ctx, cmd)
+}
+
+// checkPath checks a path from the current joint positions through each of the given ones, and returns them
+// clamped to the limits if the arm clamps. It must be called with mu held.
+func (a *Arm) checkPath(ctx context.Context, positions []*pb.JointPositions) ([]*pb.JointPositions, error) {
+	m := a.actual.ModelFrame()
+	from, err := a.currentInputs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	checked := make([]*pb.JointPositions, 0, len(positions))
+	for _, joints := range positions {
+		to := m.InputFromProtobuf(joints)
+		if len(to) != len(a.limits) {
+			return nil, errors.Errorf("arm has %d joints but %d joint positions were given", len(a.limits), len(to))
+		}
+		for i, input := range to {
+			limit := a.limits[i]
+			// like arm.CheckDesiredJointPositions, a joint already past its limit may move back within it
+			low, high := math.Min(limit.Min, from[i].Value), math.Max(limit.Max, from[i].Value)
+			if input.Value >= low && input.Value <= high {
+				continue
+			}
+			if !a.clamp {
+				return nil, errors.Errorf("joint %d needs to be within range [%v, %v] and cannot be moved to %v",
+					i, low, high, input.Value)
+			}
+			to[i].Value = math.Max(low, math.Min(input.Value, high))
+		}
+		if err := a.checkSegment(m, from, to); err != nil {
+			return nil, err
+		}
+		checked = append(checked, m.ProtobufFromInput(to))
+		from = to
+	}
+	return checked, nil
+}
+
+// checkSegment checks the positions along a straight line in joint space, at most the resolution apart.
+func (a *Arm) checkSegment(m referenceframe.Model, from, to []referenceframe.Input) error {
+	if len(a.obstacles) == 0 {
+		return nil
+	}
+	var longest float64
+	for i := range from {
+		longest = math.Max(longest, math.Abs(to[i].Value-from[i].Value))
+	}
+	steps := int(math.Ceil(longest / a.resolution))
+	step := make([]referenceframe.Input, len(from))
+	for s := 1; s <= steps; s++ {
+		for i := range from {
+			step[i] = referenceframe.Input{Value: from[i].Value + (to[i].Value-from[i].Value)*float64(s)/float64(steps)}
+		}
+		if err := a.checkCollisions(m, step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkCollisions returns an error if the arm collides with an obstacle at the inputs.
+func (a *Arm) checkCollisions(m referenceframe.Model, inputs []referenceframe.Input) error {
+	if len(a.obstacles) == 0 {
+		return nil
+	}
+	gif, err := m.Geometries(inputs)
+	if err != nil {
+		return err
+	}
+	for _, geometry := range gif.Geometries() {
+		for _, obstacle := range a.obstacles {
+			collides, err := geometry.CollidesWith(obstacle, 0)
+			if err != nil {
+				return err
+			}
+			if collides {
+				return errors.Errorf("%s would collide with obstacle %q at joint positions %v",
+					geometry.Label(), obstacle.Label(), m.ProtobufFromInput(inputs).GetValues())
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package guard
+
+import (
+	"context"
+	"testing"
+
+	pb "go.viam.com/api/component/arm/v1"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/arm/fake"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+)
+
+func setupGuardedArm(t *testing.T, conf *Config) (arm.Arm, arm.Arm) {
+	t.Helper()
+	logger := logging.NewTestLogger(t)
+	actual, err := fake.NewArm(context.Background(), nil, resource.Config{
+		Name:                "actual",
+		ConvertedAttributes: &fake.Config{ArmModel: "ur5e"},
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	conf.ArmName = "actual"
+	deps := resource.Dependencies{arm.Named("actual"): actual}
+	guarded, err := NewGuardedArm(context.Background(), deps, resource.Config{
+		Name:                "guarded",
+		ConvertedAttributes: conf,
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	return guarded, actual
+}
+
+func TestJointLimits(t *testing.T) {
+	ctx := context.Background()
+	limits := []JointLimit{{-90, 90}, {-90, 90}, {-90, 90}, {-90, 90}, {-90, 90}, {-90, 90}}
+	guarded, actual := setupGuardedArm(t, &Config{JointLimits: limits})
+
+	goal := &pb.JointPositions{Values: []float64{45, 0, 0, 0, 0, 0}}
+	test.That(t, guarded.MoveToJointPositions(ctx, goal, nil), test.ShouldBeNil)
+	joints, err := actual.JointPositions(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, joints.Values[0], test.ShouldAlmostEqual, 45)
+
+	err = guarded.MoveToJointPositions(ctx, &pb.JointPositions{Values: []float64{120, 0, 0, 0, 0, 0}}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "joint 0")
+	joints, err = actual.JointPositions(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, joints.Values[0], test.ShouldAlmostEqual, 45)
+
+	err = guarded.(*Arm).SetJointVelocities(ctx, []float64{1000, 0, 0, 0, 0, 0}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "limit")
+
+	guarded, actual = setupGuardedArm(t, &Config{JointLimits: limits, ClampToLimits: true})
+	test.That(t, guarded.MoveToJointPositions(ctx, &pb.JointPositions{Values: []float64{120, -100, 0, 0, 0, 0}}, nil),
+		test.ShouldBeNil)
+	joints, err = actual.JointPositions(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, joints.Values[0], test.ShouldAlmostEqual, 90)
+	test.That(t, joints.Values[1], test.ShouldAlmostEqual, -90)
+
+	// joints at their limits are held while the others keep moving
+	test.That(t, guarded.(*Arm).SetJointVelocities(ctx, []float64{10, 0, 10, 0, 0, 0}, nil), test.ShouldBeNil)
+	test.That(t, actual.Stop(ctx, nil), test.ShouldBeNil)
+	joints, err = actual.JointPositions(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, joints.Values[0], test.ShouldAlmostEqual, 90)
+	test.That(t, joints.Values[2], test.ShouldBeGreaterThan, 0)
+}
+
+func TestObstacles(t *testing.T) {
+	ctx := context.Background()
+	_, actual := setupGuardedArm(t, &Config{})
+
+	// place an obstacle where the end of the arm will be
+	goal := &pb.JointPositions{Values: []float64{90, 0, 0, 0, 0, 0}}
+	m := actual.ModelFrame()
+	gif, err := m.Geometries(m.InputFromProtobuf(goal))
+	test.That(t, err, test.ShouldBeNil)
+	geometries := gif.Geometries()
+	test.That(t, geometries, test.ShouldNotBeEmpty)
+	end := geometries[len(geometries)-1].Pose().Point()
+	obstacle := spatialmath.GeometryConfig{Type: spatialmath.SphereType, R: 20, TranslationOffset: end, Label: "post"}
+	guarded, actual := setupGuardedArm(t, &Config{Obstacles: []spatialmath.GeometryConfig{obstacle}})
+
+	err = guarded.MoveToJointPositions(ctx, goal, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `obstacle "post"`)
+	joints, err := actual.JointPositions(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, joints.Values, test.ShouldResemble, make([]float64, 6))
+
+	// the path is checked, not only its end
+	err = arm.MoveThroughJointPositions(ctx, guarded, []*pb.JointPositions{
+		{Values: []float64{180, 0, 0, 0, 0, 0}},
+		{Values: []float64{0, 0, 0, 0, 0, 0}},
+	}, nil, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `obstacle "post"`)
+
+	err = guarded.(*Arm).SetJointVelocities(ctx, []float64{360, 0, 0, 0, 0, 0}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	moving, err := actual.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeFalse)
+
+	test.That(t, guarded.MoveToJointPositions(ctx, &pb.JointPositions{Values: []float64{-90, 0, 0, 0, 0, 0}}, nil),
+		test.ShouldBeNil)
+}
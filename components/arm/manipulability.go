@@ -0,0 +1,222 @@
+//go:build !no_cgo
+
+package arm
+
+import (
+	"context"
+	"math"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	pb "go.viam.com/api/component/arm/v1"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/num/quat"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// getConfigurationMetricsCommand is the command which carries ConfigurationMetrics over DoCommand, since the arm
+// service has no method for it.
+const getConfigurationMetricsCommand = "get_configuration_metrics"
+
+// jacobianStep is the change of each joint, in radians or millimeters, which the jacobian is estimated over.
+const jacobianStep = 1e-6
+
+// ConfigurationMetrics describe how well an arm can move from its current joint positions: how close it is to a
+// singular configuration, where it loses the ability to move its end in some direction, and how close each joint is
+// to its limits.
+type ConfigurationMetrics struct {
+	// Manipulability is the Yoshikawa manipulability of the arm, the volume of the ellipsoid of end velocities which
+	// unit joint velocities can reach, with the end's translation measured in meters and its rotation in radians. It
+	// falls to zero at a singular configuration.
+	Manipulability float64
+	// InverseConditionNumber is the ratio of the slowest to the fastest direction the end of the arm can move in,
+	// from 1 where it moves equally well in every direction to 0 at a singular configuration. Unlike Manipulability
+	// it does not depend on the size of the arm, so a single threshold suits every arm.
+	InverseConditionNumber float64
+	// JointLimitMargins is how far each joint is from its nearest limit, in degrees or millimeters like the joint
+	// positions, or +Inf for joints without limits. It is negative for joints beyond their limits.
+	JointLimitMargins []float64
+	// NormalizedJointLimitMargins is each margin as a fraction of half the joint's range, from 1 in the middle of the
+	// range to 0 at a limit.
+	NormalizedJointLimitMargins []float64
+	// NearestJointLimit is the index of the joint closest to its limits, relative to its range, or -1 if no joint
+	// has limits.
+	NearestJointLimit int
+}
+
+// A ConfigurationMetricsReporter is an arm which reports its own ConfigurationMetrics, such as a client of a remote
+// arm, which has them worked out next to the arm rather than fetching its joint positions.
+type ConfigurationMetricsReporter interface {
+	// ConfigurationMetrics returns how close the arm's current joint positions are to a singular configuration and
+	// to the joint limits, so that operators and planners can steer clear of them.
+	//
+	//    myArm, err := arm.FromRobot(machine, "my_arm")
+	//    metrics, err := arm.ConfigurationMetricsOf(context.Background(), myArm, nil)
+	//    if metrics.InverseConditionNumber < 0.05 {
+	//            logger.Warn("the arm is close to a singular configuration")
+	//    }
+	ConfigurationMetrics(ctx context.Context, extra map[string]interface{}) (*ConfigurationMetrics, error)
+}
+
+// ConfigurationMetricsOf returns the ConfigurationMetrics of the arm's current joint positions, which it reports
+// itself if it is a ConfigurationMetricsReporter or which are worked out from its model otherwise.
+func ConfigurationMetricsOf(ctx context.Context, a Arm, extra map[string]interface{}) (*ConfigurationMetrics, error) {
+	if reporter, ok := a.(ConfigurationMetricsReporter); ok {
+		return reporter.ConfigurationMetrics(ctx, extra)
+	}
+	model := a.ModelFrame()
+	if model == nil {
+		return nil, errors.New("arm has no kinematic model to work out its configuration metrics from")
+	}
+	positions, err := a.JointPositions(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	return ComputeConfigurationMetrics(model, positions)
+}
+
+// ComputeConfigurationMetrics returns the ConfigurationMetrics of the model at the joint positions.
+func ComputeConfigurationMetrics(model referenceframe.Frame, positions *pb.JointPositions) (*ConfigurationMetrics, error) {
+	limits := model.DoF()
+	if len(positions.GetValues()) != len(limits) {
+		return nil, errors.Errorf("the arm has %d joints but %d joint positions were given", len(limits), len(positions.GetValues()))
+	}
+	inputs := model.InputFromProtobuf(positions)
+	jacobian, err := estimateJacobian(model, inputs)
+	if err != nil {
+		return nil, err
+	}
+	metrics := &ConfigurationMetrics{}
+
+	var svd mat.SVD
+	if !svd.Factorize(jacobian, mat.SVDNone) {
+		return nil, errors.New("failed to factorize the jacobian of the arm")
+	}
+	// The singular values are the lengths of the axes of the ellipsoid of end velocities, so their product is its
+	// volume. For arms with fewer than six joints this is the square root of det(J^T J) rather than of det(J J^T),
+	// which would always be zero.
+	values := svd.Values(nil)
+	metrics.Manipulability = 1
+	for _, v := range values {
+		metrics.Manipulability *= v
+	}
+	if len(values) > 0 && values[0] > 0 {
+		metrics.InverseConditionNumber = values[len(values)-1] / values[0]
+	}
+
+	margins := make([]referenceframe.Input, len(limits))
+	metrics.NormalizedJointLimitMargins = make([]float64, len(limits))
+	metrics.NearestJointLimit = -1
+	for i, limit := range limits {
+		if math.IsInf(limit.Min, -1) && math.IsInf(limit.Max, 1) {
+			margins[i].Value = math.Inf(1)
+			metrics.NormalizedJointLimitMargins[i] = 1
+			continue
+		}
+		margins[i].Value = math.Min(inputs[i].Value-limit.Min, limit.Max-inputs[i].Value)
+		metrics.NormalizedJointLimitMargins[i] = margins[i].Value / ((limit.Max - limit.Min) / 2)
+		nearest := metrics.NearestJointLimit
+		if nearest < 0 || metrics.NormalizedJointLimitMargins[i] < metrics.NormalizedJointLimitMargins[nearest] {
+			metrics.NearestJointLimit = i
+		}
+	}
+	// The margins are converted to degrees or millimeters the same way as joint positions, which scales them.
+	metrics.JointLimitMargins = model.ProtobufFromInput(margins).GetValues()
+	return metrics, nil
+}
+
+// estimateJacobian estimates the jacobian of the model's end pose at the inputs by central differences. Its rows are
+// the end's translation in meters and rotation in radians, in the frame of the base of the model.
+func estimateJacobian(model referenceframe.Frame, inputs []referenceframe.Input) (*mat.Dense, error) {
+	transform := func(in []referenceframe.Input) (spatialmath.Pose, error) {
+		// Out of bounds inputs are allowed, since the joints may be at their limits, and still transform.
+		pose, err := model.Transform(in)
+		if pose == nil {
+			return nil, err
+		}
+		return pose, nil
+	}
+	jacobian := mat.NewDense(6, len(inputs), nil)
+	perturbed := append([]referenceframe.Input(nil), inputs...)
+	for j := range inputs {
+		perturbed[j].Value = inputs[j].Value - jacobianStep
+		before, err := transform(perturbed)
+		if err != nil {
+			return nil, err
+		}
+		perturbed[j].Value = inputs[j].Value + jacobianStep
+		after, err := transform(perturbed)
+		if err != nil {
+			return nil, err
+		}
+		perturbed[j].Value = inputs[j].Value
+
+		delta := spatialmath.PoseDelta(before, after)
+		translation := delta.Point().Mul(1e-3 / (2 * jacobianStep))
+		// For so small a rotation, its rotation vector is twice the imaginary part of its quaternion.
+		q := delta.Orientation().Quaternion()
+		if q.Real < 0 {
+			q = quat.Scale(-1, q)
+		}
+		rotation := r3.Vector{X: q.Imag, Y: q.Jmag, Z: q.Kmag}.Mul(1 / jacobianStep)
+		jacobian.SetCol(j, []float64{translation.X, translation.Y, translation.Z, rotation.X, rotation.Y, rotation.Z})
+	}
+	return jacobian, nil
+}
+
+// configurationMetricsToCommand and configurationMetricsFromCommand carry ConfigurationMetrics over DoCommand.
+// Joints without limits have no margin, since infinities cannot be sent as JSON.
+func configurationMetricsToCommand(metrics *ConfigurationMetrics) map[string]interface{} {
+	margins := make([]interface{}, 0, len(metrics.JointLimitMargins))
+	for _, m := range metrics.JointLimitMargins {
+		if math.IsInf(m, 0) {
+			margins = append(margins, nil)
+			continue
+		}
+		margins = append(margins, m)
+	}
+	normalized := make([]interface{}, 0, len(metrics.NormalizedJointLimitMargins))
+	for _, m := range metrics.NormalizedJointLimitMargins {
+		normalized = append(normalized, m)
+	}
+	return map[string]interface{}{
+		"manipulability":                 metrics.Manipulability,
+		"inverse_condition_number":       metrics.InverseConditionNumber,
+		"joint_limit_margins":            margins,
+		"normalized_joint_limit_margins": normalized,
+		"nearest_joint_limit":            metrics.NearestJointLimit,
+	}
+}
+
+func configurationMetricsFromCommand(resp map[string]interface{}) (*ConfigurationMetrics, error) {
+	manipulability, okManipulability := resp["manipulability"].(float64)
+	inverseConditionNumber, okCondition := resp["inverse_condition_number"].(float64)
+	margins, okMargins := resp["joint_limit_margins"].([]interface{})
+	normalized, okNormalized := resp["normalized_joint_limit_margins"].([]interface{})
+	nearest, okNearest := resp["nearest_joint_limit"].(float64)
+	if !okManipulability || !okCondition || !okMargins || !okNormalized || !okNearest || len(margins) != len(normalized) {
+		return nil, errors.New("malformed configuration metrics")
+	}
+	metrics := &ConfigurationMetrics{
+		Manipulability:              manipulability,
+		InverseConditionNumber:      inverseConditionNumber,
+		JointLimitMargins:           make([]float64, 0, len(margins)),
+		NormalizedJointLimitMargins: make([]float64, 0, len(normalized)),
+		NearestJointLimit:           int(nearest),
+	}
+	for i := range margins {
+		margin, ok := margins[i].(float64)
+		if margins[i] == nil {
+			margin, ok = math.Inf(1), true
+		}
+		normalizedMargin, okNormalizedMargin := normalized[i].(float64)
+		if !ok || !okNormalizedMargin {
+			return nil, errors.New("malformed configuration metrics")
+		}
+		metrics.JointLimitMargins = append(metrics.JointLimitMargins, margin)
+		metrics.NormalizedJointLimitMargins = append(metrics.NormalizedJointLimitMargins, normalizedMargin)
+	}
+	return metrics, nil
+}
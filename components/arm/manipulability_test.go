@@ -0,0 +1,70 @@
+package arm_test
+
+import (
+	"context"
+	"testing"
+
+	pb "go.viam.com/api/component/arm/v1"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+)
+
+func TestConfigurationMetrics(t *testing.T) {
+	model, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/universalrobots/ur5e.json"), "")
+	test.That(t, err, test.ShouldBeNil)
+
+	t.Run("away from singularities", func(t *testing.T) {
+		metrics, err := arm.ComputeConfigurationMetrics(model, &pb.JointPositions{Values: []float64{0, -60, 90, -120, -90, 0}})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, metrics.Manipulability, test.ShouldBeGreaterThan, 0.01)
+		test.That(t, metrics.InverseConditionNumber, test.ShouldBeBetween, 0.05, 1)
+	})
+
+	t.Run("at singularities", func(t *testing.T) {
+		// the wrist is singular when the fifth joint lines the fourth and sixth up, and the elbow when it is straight
+		for _, values := range [][]float64{{0, -60, 90, -120, 0, 0}, {0, -60, 0, -120, -90, 0}} {
+			metrics, err := arm.ComputeConfigurationMetrics(model, &pb.JointPositions{Values: values})
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, metrics.Manipulability, test.ShouldAlmostEqual, 0, 1e-6)
+			test.That(t, metrics.InverseConditionNumber, test.ShouldAlmostEqual, 0, 1e-6)
+		}
+	})
+
+	t.Run("joint limits", func(t *testing.T) {
+		metrics, err := arm.ComputeConfigurationMetrics(model, &pb.JointPositions{Values: []float64{0, -60, 90, -120, -90, 350}})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, metrics.JointLimitMargins, test.ShouldHaveLength, 6)
+		test.That(t, metrics.JointLimitMargins[0], test.ShouldAlmostEqual, 360)
+		test.That(t, metrics.NormalizedJointLimitMargins[0], test.ShouldAlmostEqual, 1)
+		test.That(t, metrics.JointLimitMargins[5], test.ShouldAlmostEqual, 10)
+		test.That(t, metrics.NormalizedJointLimitMargins[5], test.ShouldAlmostEqual, 10./360)
+		test.That(t, metrics.NearestJointLimit, test.ShouldEqual, 5)
+
+		metrics, err = arm.ComputeConfigurationMetrics(model, &pb.JointPositions{Values: []float64{0, -60, 90, -120, -90, 370}})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, metrics.JointLimitMargins[5], test.ShouldAlmostEqual, -10)
+	})
+
+	t.Run("from the arm", func(t *testing.T) {
+		injectArm := inject.NewArm("arm")
+		injectArm.ModelFrameFunc = func() referenceframe.Model {
+			return model
+		}
+		injectArm.JointPositionsFunc = func(ctx context.Context, extra map[string]interface{}) (*pb.JointPositions, error) {
+			return &pb.JointPositions{Values: []float64{0, -60, 0, -120, -90, 0}}, nil
+		}
+		metrics, err := arm.ConfigurationMetricsOf(context.Background(), injectArm, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, metrics.InverseConditionNumber, test.ShouldAlmostEqual, 0, 1e-6)
+
+		injectArm.JointPositionsFunc = func(ctx context.Context, extra map[string]interface{}) (*pb.JointPositions, error) {
+			return &pb.JointPositions{Values: []float64{0, 0}}, nil
+		}
+		_, err = arm.ConfigurationMetricsOf(context.Background(), injectArm, nil)
+		test.That(t, err, test.ShouldBeError, "the arm has 6 joints but 2 joint positions were given")
+	})
+}
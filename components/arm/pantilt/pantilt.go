@@ -0,0 +1,352 @@
+// Package pantilt implements a pan-tilt unit as an arm of two joints, each a servo: the pan joint turns about the
+// unit's Z axis, and the tilt joint above it raises the unit's end from pointing along its X axis toward its Z axis.
+// The end's own Z axis points where the unit aims, as a camera's does, so a camera mounted on the unit can have it as
+// its parent frame.
+//
+// Joint positions are in degrees from the servo angles the config centers the joints at. The unit aims at targets
+// with DoCommand, given in the frame of any part of the robot, which is the world by default:
+//
+//	{"command": "point_at", "x": 1000, "y": 200, "z": 300, "frame": "world"}
+//
+// MoveToPosition aims the unit at the point of the pose it is given, in the frame of the unit.
+package pantilt
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	pb "go.viam.com/api/component/arm/v1"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/servo"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/operation"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("pan_tilt")
+
+const (
+	defaultCenterDeg = 90.
+	servoRangeDeg    = 180.
+)
+
+// Config is used for converting config attributes.
+type Config struct {
+	PanServo  string `json:"pan_servo"`
+	TiltServo string `json:"tilt_servo"`
+	// PanCenterDeg and TiltCenterDeg are the servo angles at which the unit points along its X axis, 90 by default.
+	PanCenterDeg  *float64 `json:"pan_center_deg,omitempty"`
+	TiltCenterDeg *float64 `json:"tilt_center_deg,omitempty"`
+	// InvertPan and InvertTilt are for servos which turn the other way, clockwise when seen from above for pan and
+	// down for tilt.
+	InvertPan  bool `json:"invert_pan,omitempty"`
+	InvertTilt bool `json:"invert_tilt,omitempty"`
+	// TiltHeightMM is the height of the tilt axis above the pan servo, and EndOffsetMM how far the end is from the
+	// tilt axis, along where the unit aims.
+	TiltHeightMM float64 `json:"tilt_height_mm,omitempty"`
+	EndOffsetMM  float64 `json:"end_offset_mm,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.PanServo == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "pan_servo")
+	}
+	if conf.TiltServo == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "tilt_servo")
+	}
+	for field, center := range map[string]*float64{"pan_center_deg": conf.PanCenterDeg, "tilt_center_deg": conf.TiltCenterDeg} {
+		if center != nil && (*center < 0 || *center > servoRangeDeg) {
+			return nil, resource.NewConfigValidationError(path, errors.Errorf("%s must be between 0 and %v", field, servoRangeDeg))
+		}
+	}
+	return []string{conf.PanServo, conf.TiltServo, framesystem.InternalServiceName.String()}, nil
+}
+
+func init() {
+	resource.RegisterComponent(arm.API, model, resource.Registration[arm.Arm, *Config]{
+		Constructor: func(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (arm.Arm, error) {
+			newConf, err := resource.NativeConfig[*Config](conf)
+			if err != nil {
+				return nil, err
+			}
+			return newPanTilt(deps, conf.ResourceName(), newConf, logger)
+		},
+	})
+}
+
+// joint maps the angles of a joint to the angles of its servo.
+type joint struct {
+	servo     servo.Servo
+	centerDeg float64
+	sign      float64
+}
+
+func newJoint(s servo.Servo, center *float64, invert bool) joint {
+	j := joint{servo: s, centerDeg: defaultCenterDeg, sign: 1}
+	if center != nil {
+		j.centerDeg = *center
+	}
+	if invert {
+		j.sign = -1
+	}
+	return j
+}
+
+// limit is the range of the joint in radians, as far as its servo turns.
+func (j joint) limit() referenceframe.Limit {
+	a, b := -j.centerDeg*j.sign, (servoRangeDeg-j.centerDeg)*j.sign
+	return referenceframe.Limit{Min: utils.DegToRad(math.Min(a, b)), Max: utils.DegToRad(math.Max(a, b))}
+}
+
+func (j joint) position(ctx context.Context) (float64, error) {
+	angle, err := j.servo.Position(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	return (float64(angle) - j.centerDeg) * j.sign, nil
+}
+
+func (j joint) move(ctx context.Context, deg float64) error {
+	angle := math.Round(j.centerDeg + deg*j.sign)
+	if angle < 0 || angle > servoRangeDeg {
+		return errors.Errorf("joint position %.1f is beyond the servo's range", deg)
+	}
+	return j.servo.Move(ctx, uint32(angle), nil)
+}
+
+type panTilt struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+	logger logging.Logger
+	opMgr  *operation.SingleOperationManager
+
+	pan, tilt    joint
+	tiltHeightMM float64
+	model        referenceframe.Model
+	fs           framesystem.Service
+}
+
+func newPanTilt(deps resource.Dependencies, name resource.Name, conf *Config, logger logging.Logger) (arm.Arm, error) {
+	panServo, err := servo.FromDependencies(deps, conf.PanServo)
+	if err != nil {
+		return nil, err
+	}
+	tiltServo, err := servo.FromDependencies(deps, conf.TiltServo)
+	if err != nil {
+		return nil, err
+	}
+	fs, err := framesystem.FromDependencies(deps)
+	if err != nil {
+		return nil, err
+	}
+	pt := &panTilt{
+		Named:        name.AsNamed(),
+		logger:       logger,
+		opMgr:        operation.NewSingleOperationManager(),
+		pan:          newJoint(panServo, conf.PanCenterDeg, conf.InvertPan),
+		tilt:         newJoint(tiltServo, conf.TiltCenterDeg, conf.InvertTilt),
+		tiltHeightMM: conf.TiltHeightMM,
+		fs:           fs,
+	}
+	pt.model, err = buildModel(name.ShortName(), pt.pan.limit(), pt.tilt.limit(), conf.TiltHeightMM, conf.EndOffsetMM)
+	if err != nil {
+		return nil, err
+	}
+	return pt, nil
+}
+
+// buildModel builds the kinematics of the unit: the pan joint, the tilt joint above it, and the end in front of the
+// tilt joint, its Z axis pointing along the X axis of the unit when both joints are centered.
+func buildModel(name string, panLimit, tiltLimit referenceframe.Limit, tiltHeightMM, endOffsetMM float64,
+) (referenceframe.Model, error) {
+	pan, err := referenceframe.NewRotationalFrame(name+"_pan", spatialmath.R4AA{RZ: 1}, panLimit)
+	if err != nil {
+		return nil, err
+	}
+	tiltOffset, err := referenceframe.NewStaticFrame(name+"_tilt_offset", spatialmath.NewPoseFromPoint(r3.Vector{Z: tiltHeightMM}))
+	if err != nil {
+		return nil, err
+	}
+	// positive tilt turns X toward Z, which is a negative turn about Y
+	tilt, err := referenceframe.NewRotationalFrame(name+"_tilt", spatialmath.R4AA{RY: -1}, tiltLimit)
+	if err != nil {
+		return nil, err
+	}
+	end, err := referenceframe.NewStaticFrame(name, spatialmath.NewPose(
+		r3.Vector{X: endOffsetMM}, &spatialmath.OrientationVector{OX: 1}))
+	if err != nil {
+		return nil, err
+	}
+	m := referenceframe.NewSimpleModel(name)
+	m.OrdTransforms = append(m.OrdTransforms, pan, tiltOffset, tilt, end)
+	return m, nil
+}
+
+// ModelFrame returns the kinematics of the unit.
+func (pt *panTilt) ModelFrame() referenceframe.Model {
+	return pt.model
+}
+
+// JointPositions returns the pan and tilt angles in degrees.
+func (pt *panTilt) JointPositions(ctx context.Context, extra map[string]interface{}) (*pb.JointPositions, error) {
+	panDeg, err := pt.pan.position(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the position of the pan servo")
+	}
+	tiltDeg, err := pt.tilt.position(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the position of the tilt servo")
+	}
+	return &pb.JointPositions{Values: []float64{panDeg, tiltDeg}}, nil
+}
+
+// MoveToJointPositions moves both servos at once to the pan and tilt angles in degrees.
+func (pt *panTilt) MoveToJointPositions(ctx context.Context, positions *pb.JointPositions, extra map[string]interface{}) error {
+	if len(positions.Values) != 2 {
+		return errors.Errorf("a pan-tilt unit has 2 joints but %d joint positions were given", len(positions.Values))
+	}
+	ctx, done := pt.opMgr.New(ctx)
+	defer done()
+	if err := arm.CheckDesiredJointPositions(ctx, pt, pt.model.InputFromProtobuf(positions)); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	var panErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		panErr = pt.pan.move(ctx, positions.Values[0])
+	}()
+	tiltErr := pt.tilt.move(ctx, positions.Values[1])
+	wg.Wait()
+	return multierr.Combine(panErr, tiltErr)
+}
+
+// EndPosition returns the pose of the end of the unit.
+func (pt *panTilt) EndPosition(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
+	joints, err := pt.JointPositions(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	return motionplan.ComputeOOBPosition(pt.model, joints)
+}
+
+// MoveToPosition aims the unit at the point of the pose, ignoring its orientation.
+func (pt *panTilt) MoveToPosition(ctx context.Context, pose spatialmath.Pose, extra map[string]interface{}) error {
+	return pt.MoveToJointPositions(ctx, pt.aim(pose.Point()), extra)
+}
+
+// aim returns the joint positions aiming the unit at a point in its frame. The end's offset is along where it aims,
+// so the unit aims from its tilt axis.
+func (pt *panTilt) aim(target r3.Vector) *pb.JointPositions {
+	panRad := math.Atan2(target.Y, target.X)
+	tiltRad := math.Atan2(target.Z-pt.tiltHeightMM, math.Hypot(target.X, target.Y))
+	return &pb.JointPositions{Values: []float64{utils.RadToDeg(panRad), utils.RadToDeg(tiltRad)}}
+}
+
+// PointAt aims the unit at a point in a frame of the robot's frame system.
+func (pt *panTilt) PointAt(ctx context.Context, target *referenceframe.PoseInFrame) error {
+	inUnit, err := pt.fs.TransformPose(ctx, target, pt.Name().ShortName()+"_origin", nil)
+	if err != nil {
+		return errors.Wrap(err, "could not find the target in the frame of the unit")
+	}
+	return pt.MoveToJointPositions(ctx, pt.aim(inUnit.Pose().Point()), nil)
+}
+
+// DoCommand aims the unit at points in the frame system.
+func (pt *panTilt) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case "point_at":
+		var target r3.Vector
+		for key, v := range map[string]*float64{"x": &target.X, "y": &target.Y, "z": &target.Z} {
+			f, ok := cmd[key].(float64)
+			if !ok {
+				return nil, errors.Errorf("point_at needs a number %s", key)
+			}
+			*v = f
+		}
+		frame := referenceframe.World
+		if f, ok := cmd["frame"].(string); ok && f != "" {
+			frame = f
+		}
+		if err := pt.PointAt(ctx, referenceframe.NewPoseInFrame(frame, spatialmath.NewPoseFromPoint(target))); err != nil {
+			return nil, err
+		}
+		joints, err := pt.JointPositions(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"pan_deg": joints.Values[0], "tilt_deg": joints.Values[1]}, nil
+	default:
+		return nil, errors.Errorf("unknown command %q", name)
+	}
+}
+
+// Stop stops both servos.
+func (pt *panTilt) Stop(ctx context.Context, extra map[string]interface{}) error {
+	pt.opMgr.CancelRunning(ctx)
+	return multierr.Combine(pt.pan.servo.Stop(ctx, extra), pt.tilt.servo.Stop(ctx, extra))
+}
+
+// IsMoving returns whether either servo is moving.
+func (pt *panTilt) IsMoving(ctx context.Context) (bool, error) {
+	if pt.opMgr.OpRunning() {
+		return true, nil
+	}
+	for _, j := range []joint{pt.pan, pt.tilt} {
+		moving, err := j.servo.IsMoving(ctx)
+		if err != nil || moving {
+			return moving, err
+		}
+	}
+	return false, nil
+}
+
+// CurrentInputs returns the pan and tilt angles in radians.
+func (pt *panTilt) CurrentInputs(ctx context.Context) ([]referenceframe.Input, error) {
+	joints, err := pt.JointPositions(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return pt.model.InputFromProtobuf(joints), nil
+}
+
+// GoToInputs moves the unit through each of the pan and tilt angles in radians in turn.
+func (pt *panTilt) GoToInputs(ctx context.Context, inputSteps ...[]referenceframe.Input) error {
+	for _, goal := range inputSteps {
+		if err := pt.MoveToJointPositions(ctx, pt.model.ProtobufFromInput(goal), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Geometries returns the geometries of the unit's kinematics, where it is now.
+func (pt *panTilt) Geometries(ctx context.Context, extra map[string]interface{}) ([]spatialmath.Geometry, error) {
+	inputs, err := pt.CurrentInputs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gif, err := pt.model.Geometries(inputs)
+	if err != nil {
+		return nil, err
+	}
+	return gif.Geometries(), nil
+}
@@ -0,0 +1,129 @@
+package pantilt
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	pb "go.viam.com/api/component/arm/v1"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/servo"
+	fakeservo "go.viam.com/rdk/components/servo/fake"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestValidate(t *testing.T) {
+	conf := &Config{PanServo: "pan"}
+	_, err := conf.Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "tilt_servo")
+
+	conf.TiltServo = "tilt"
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"pan", "tilt", framesystem.InternalServiceName.String()})
+
+	center := 200.
+	conf.TiltCenterDeg = &center
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+// aimsAt is whether the Z axis of the end of the unit points at the target.
+func aimsAt(t *testing.T, pt arm.Arm, target r3.Vector) bool {
+	t.Helper()
+	end, err := pt.EndPosition(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	ov := end.Orientation().OrientationVectorRadians()
+	toTarget := target.Sub(end.Point()).Normalize()
+	return toTarget.Sub(r3.Vector{X: ov.OX, Y: ov.OY, Z: ov.OZ}).Norm() < 0.02
+}
+
+func TestPanTilt(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	pan := &fakeservo.Servo{Named: servo.Named("pan").AsNamed()}
+	tilt := &fakeservo.Servo{Named: servo.Named("tilt").AsNamed()}
+	// the unit is 100mm above the world's origin
+	fs := inject.NewFrameSystemService("fs")
+	fs.TransformPoseFunc = func(
+		ctx context.Context, pose *referenceframe.PoseInFrame, dst string, _ []*referenceframe.LinkInFrame,
+	) (*referenceframe.PoseInFrame, error) {
+		test.That(t, pose.Parent(), test.ShouldEqual, referenceframe.World)
+		test.That(t, dst, test.ShouldEqual, "pt_origin")
+		return referenceframe.NewPoseInFrame(dst, spatialmath.NewPoseFromPoint(pose.Pose().Point().Sub(r3.Vector{Z: 100}))), nil
+	}
+	deps := resource.Dependencies{servo.Named("pan"): pan, servo.Named("tilt"): tilt, framesystem.InternalServiceName: fs}
+
+	tiltCenter := 45.
+	pt, err := newPanTilt(deps, arm.Named("pt"), &Config{
+		PanServo: "pan", TiltServo: "tilt", TiltCenterDeg: &tiltCenter, InvertPan: true, TiltHeightMM: 50, EndOffsetMM: 20,
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	limits := pt.ModelFrame().DoF()
+	test.That(t, limits, test.ShouldHaveLength, 2)
+	test.That(t, limits[0].Min, test.ShouldAlmostEqual, -math.Pi/2)
+	test.That(t, limits[0].Max, test.ShouldAlmostEqual, math.Pi/2)
+	test.That(t, limits[1].Min, test.ShouldAlmostEqual, -math.Pi/4)
+	test.That(t, limits[1].Max, test.ShouldAlmostEqual, 3*math.Pi/4)
+
+	test.That(t, pt.MoveToJointPositions(ctx, &pb.JointPositions{Values: []float64{0, 0}}, nil), test.ShouldBeNil)
+	angle, err := pan.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, angle, test.ShouldEqual, 90)
+	angle, err = tilt.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, angle, test.ShouldEqual, 45)
+	end, err := pt.EndPosition(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.R3VectorAlmostEqual(end.Point(), r3.Vector{X: 20, Z: 50}, 1e-6), test.ShouldBeTrue)
+	test.That(t, aimsAt(t, pt, r3.Vector{X: 1000, Z: 50}), test.ShouldBeTrue)
+
+	// the pan servo is inverted
+	test.That(t, pt.MoveToJointPositions(ctx, &pb.JointPositions{Values: []float64{30, 10}}, nil), test.ShouldBeNil)
+	angle, err = pan.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, angle, test.ShouldEqual, 60)
+	joints, err := pt.JointPositions(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, joints.Values, test.ShouldResemble, []float64{30, 10})
+
+	err = pt.MoveToJointPositions(ctx, &pb.JointPositions{Values: []float64{0, -60}}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	err = pt.MoveToJointPositions(ctx, &pb.JointPositions{Values: []float64{0}}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// 45 degrees to the left and 30 up of the tilt axis
+	target := r3.Vector{X: 100, Y: 100, Z: 50 + 100*math.Sqrt2*math.Tan(math.Pi/6)}
+	test.That(t, pt.MoveToPosition(ctx, spatialmath.NewPoseFromPoint(target), nil), test.ShouldBeNil)
+	joints, err = pt.JointPositions(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, joints.Values, test.ShouldResemble, []float64{45, 30})
+	test.That(t, aimsAt(t, pt, target), test.ShouldBeTrue)
+
+	resp, err := pt.DoCommand(ctx, map[string]interface{}{"command": "point_at", "x": 0., "y": -500., "z": 150.})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["pan_deg"], test.ShouldEqual, -90)
+	test.That(t, resp["tilt_deg"], test.ShouldEqual, 0)
+	test.That(t, aimsAt(t, pt, r3.Vector{Y: -500, Z: 50}), test.ShouldBeTrue)
+
+	_, err = pt.DoCommand(ctx, map[string]interface{}{"command": "point_at", "x": 0.})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = pt.DoCommand(ctx, map[string]interface{}{})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	inputs, err := pt.CurrentInputs(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, inputs[0].Value, test.ShouldAlmostEqual, -math.Pi/2)
+	moving, err := pt.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeFalse)
+}
@@ -8,6 +8,7 @@ import (
 
 	commonpb "go.viam.com/api/common/v1"
 	pb "go.viam.com/api/component/arm/v1"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"go.viam.com/rdk/operation"
 	"go.viam.com/rdk/protoutils"
@@ -157,5 +158,37 @@ func (s *serviceServer) DoCommand(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
-	return protoutils.DoFromResourceServer(ctx, arm, req)
+	cmd := req.GetCommand().AsMap()
+	extra, _ := cmd["extra"].(map[string]interface{})
+	switch cmd["command"] {
+	case setJointVelocitiesCommand:
+		velocities, err := jointVelocitiesFromCommand(cmd)
+		if err != nil {
+			return nil, err
+		}
+		if err := SetJointVelocities(ctx, arm, velocities, extra); err != nil {
+			return nil, err
+		}
+	case moveThroughJointPositionsCommand:
+		positions, options, err := moveThroughJointPositionsFromCommand(cmd)
+		if err != nil {
+			return nil, err
+		}
+		if err := MoveThroughJointPositions(ctx, arm, positions, options, extra); err != nil {
+			return nil, err
+		}
+	case getConfigurationMetricsCommand:
+		metrics, err := ConfigurationMetricsOf(ctx, arm, extra)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := structpb.NewStruct(configurationMetricsToCommand(metrics))
+		if err != nil {
+			return nil, err
+		}
+		return &commonpb.DoCommandResponse{Result: resp}, nil
+	default:
+		return protoutils.DoFromResourceServer(ctx, arm, req)
+	}
+	return &commonpb.DoCommandResponse{}, nil
 }
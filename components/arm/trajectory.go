@@ -0,0 +1,114 @@
+//go:build !no_cgo
+
+package arm
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	pb "go.viam.com/api/component/arm/v1"
+)
+
+// moveThroughJointPositionsCommand is the command which carries MoveThroughJointPositions over DoCommand, since the
+// arm service has no method for it.
+const moveThroughJointPositionsCommand = "move_through_joint_positions"
+
+// MoveOptions configures how an arm runs through a series of joint positions.
+type MoveOptions struct {
+	// BlendRadiusMM is how close in millimeters the end of the arm comes to each intermediate position before it
+	// starts turning toward the next one, so the arm does not stop there. Zero stops the arm at every position.
+	BlendRadiusMM float64
+}
+
+// A TrajectoryExecutor is an arm whose controller can run through a series of joint positions as one continuous
+// motion, blending the corners between them.
+type TrajectoryExecutor interface {
+	// MoveThroughJointPositions moves the arm through each of the joint positions in turn, blending between them as
+	// the options allow. It blocks until the arm reaches the last position or a new operation cancels this one.
+	//
+	//    myArm, err := arm.FromRobot(machine, "my_arm")
+	//    executor, ok := myArm.(arm.TrajectoryExecutor)
+	//    // Pass through the first position without stopping, within 10mm of it.
+	//    err = executor.MoveThroughJointPositions(context.Background(), []*componentpb.JointPositions{
+	//            {Values: []float64{0, -90, 90, 0, 90, 0}},
+	//            {Values: []float64{30, -90, 90, 0, 90, 0}},
+	//    }, &arm.MoveOptions{BlendRadiusMM: 10}, nil)
+	MoveThroughJointPositions(
+		ctx context.Context, positions []*pb.JointPositions, options *MoveOptions, extra map[string]interface{},
+	) error
+}
+
+// MoveThroughJointPositions moves the arm through each of the joint positions in turn. Arms which are not a
+// TrajectoryExecutor stop at every position.
+func MoveThroughJointPositions(
+	ctx context.Context, a Arm, positions []*pb.JointPositions, options *MoveOptions, extra map[string]interface{},
+) error {
+	if executor, ok := a.(TrajectoryExecutor); ok {
+		return executor.MoveThroughJointPositions(ctx, positions, options, extra)
+	}
+	for _, position := range positions {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := a.MoveToJointPositions(ctx, position, extra); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveThroughJointPositionsToCommand encodes the arguments of MoveThroughJointPositions as a DoCommand.
+func moveThroughJointPositionsToCommand(
+	positions []*pb.JointPositions, options *MoveOptions, extra map[string]interface{},
+) map[string]interface{} {
+	rawPositions := make([]interface{}, 0, len(positions))
+	for _, position := range positions {
+		values := make([]interface{}, 0, len(position.GetValues()))
+		for _, v := range position.GetValues() {
+			values = append(values, v)
+		}
+		rawPositions = append(rawPositions, values)
+	}
+	cmd := map[string]interface{}{
+		"command":       moveThroughJointPositionsCommand,
+		"positions_deg": rawPositions,
+		"extra":         extra,
+	}
+	if options != nil {
+		cmd["blend_radius_mm"] = options.BlendRadiusMM
+	}
+	return cmd
+}
+
+// moveThroughJointPositionsFromCommand parses the arguments of a move_through_joint_positions command.
+func moveThroughJointPositionsFromCommand(cmd map[string]interface{}) ([]*pb.JointPositions, *MoveOptions, error) {
+	rawPositions, ok := cmd["positions_deg"].([]interface{})
+	if !ok {
+		return nil, nil, errors.New("move_through_joint_positions requires a list of positions_deg")
+	}
+	positions := make([]*pb.JointPositions, 0, len(rawPositions))
+	for _, rawPosition := range rawPositions {
+		rawValues, ok := rawPosition.([]interface{})
+		if !ok {
+			return nil, nil, errors.Errorf("joint positions %v are not a list", rawPosition)
+		}
+		values := make([]float64, 0, len(rawValues))
+		for _, v := range rawValues {
+			value, ok := v.(float64)
+			if !ok {
+				return nil, nil, errors.Errorf("joint position %v is not a number", v)
+			}
+			values = append(values, value)
+		}
+		positions = append(positions, &pb.JointPositions{Values: values})
+	}
+	options := &MoveOptions{}
+	if raw, ok := cmd["blend_radius_mm"]; ok {
+		blendRadius, ok := raw.(float64)
+		if !ok || blendRadius < 0 {
+			return nil, nil, errors.New("blend_radius_mm must be a non-negative number")
+		}
+		options.BlendRadiusMM = blendRadius
+	}
+	return positions, options, nil
+}
@@ -0,0 +1,258 @@
+package universalrobots
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+)
+
+// Packet types of the Real-Time Data Exchange (RTDE) protocol.
+const (
+	rtdeRequestProtocolVersion = 'V'
+	rtdeTextMessage            = 'M'
+	rtdeDataPackage            = 'U'
+	rtdeSetupOutputs           = 'O'
+	rtdeStart                  = 'S'
+	rtdePause                  = 'P'
+)
+
+const (
+	rtdePort            = "30004"
+	rtdeProtocolVersion = 2
+	// defaultRTDEFrequencyHz is the rate e-Series controllers publish at, CB3 controllers only reach 125Hz.
+	defaultRTDEFrequencyHz = 500
+	maxRTDEFrequencyHz     = 500
+	rtdeHeaderSize         = 3
+	rtdeReplyTimeout       = 2 * time.Second
+)
+
+// Bits of the robot_status_bits and safety_status_bits outputs.
+const (
+	rtdeRobotPowerOn        = 1 << 0
+	rtdeRobotProgramRunning = 1 << 1
+
+	rtdeSafetyProtectiveStopped = 1 << 2
+	rtdeSafetySafeguardStopped  = 1 << 4
+	rtdeSafetySystemEStopped    = 1 << 5
+	rtdeSafetyRobotEStopped     = 1 << 6
+	rtdeSafetyEStopped          = 1 << 7
+	rtdeSafetyViolation         = 1 << 8
+	rtdeSafetyFault             = 1 << 9
+)
+
+// rtdeOutput is an output variable of the controller and the type RTDE reports it with.
+type rtdeOutput struct {
+	name     string
+	typeName string
+}
+
+// rtdeOutputs are the variables subscribed to, in the order they appear in every data package.
+var rtdeOutputs = []rtdeOutput{
+	{"timestamp", "DOUBLE"},
+	{"actual_q", "VECTOR6D"},
+	{"actual_qd", "VECTOR6D"},
+	{"actual_current", "VECTOR6D"},
+	{"target_q", "VECTOR6D"},
+	{"joint_temperatures", "VECTOR6D"},
+	{"actual_TCP_pose", "VECTOR6D"},
+	{"actual_TCP_force", "VECTOR6D"},
+	{"robot_mode", "INT32"},
+	{"safety_mode", "INT32"},
+	{"robot_status_bits", "UINT32"},
+	{"safety_status_bits", "UINT32"},
+	{"speed_scaling", "DOUBLE"},
+}
+
+// rtdeState is one data package of the outputs in rtdeOutputs.
+type rtdeState struct {
+	Timestamp         float64
+	ActualQ           [6]float64
+	ActualQd          [6]float64
+	ActualCurrent     [6]float64
+	TargetQ           [6]float64
+	JointTemperatures [6]float64
+	ActualTCPPose     [6]float64
+	ActualTCPForce    [6]float64
+	RobotMode         int32
+	SafetyMode        int32
+	RobotStatusBits   uint32
+	SafetyStatusBits  uint32
+	SpeedScaling      float64
+}
+
+// robotState converts the data package to the state the secondary interface reports, so that the rest of the driver
+// works the same on either.
+func (s rtdeState) robotState() robotState {
+	state := robotState{creationTime: time.Now()}
+	state.Joints = make([]jointData, 6)
+	for i := range state.Joints {
+		state.Joints[i] = jointData{
+			Qactual:  s.ActualQ[i],
+			Qtarget:  s.TargetQ[i],
+			QDactual: s.ActualQd[i],
+			Iactual:  float32(s.ActualCurrent[i]),
+			Tmotor:   float32(s.JointTemperatures[i]),
+		}
+	}
+	state.robotModeData = robotModeData{
+		Timestamp:           uint64(s.Timestamp * 1e6),
+		IsRobotPowerOn:      s.RobotStatusBits&rtdeRobotPowerOn != 0,
+		IsProgramRunning:    s.RobotStatusBits&rtdeRobotProgramRunning != 0,
+		IsEmergencyStopped:  s.SafetyStatusBits&(rtdeSafetySystemEStopped|rtdeSafetyRobotEStopped|rtdeSafetyEStopped) != 0,
+		IsProtectiveStopped: s.SafetyStatusBits&(rtdeSafetyProtectiveStopped|rtdeSafetySafeguardStopped) != 0,
+		RobotMode:           byte(s.RobotMode),
+		SpeedScaling:        s.SpeedScaling,
+	}
+	state.masterboardData.SafetyMode = byte(s.SafetyMode)
+	state.cartesianInfo = cartesianInfo{
+		X: s.ActualTCPPose[0], Y: s.ActualTCPPose[1], Z: s.ActualTCPPose[2],
+		Rx: s.ActualTCPPose[3], Ry: s.ActualTCPPose[4], Rz: s.ActualTCPPose[5],
+	}
+	state.forceModeData = forceModeData{
+		Fx: s.ActualTCPForce[0], Fy: s.ActualTCPForce[1], Fz: s.ActualTCPForce[2],
+		Frx: s.ActualTCPForce[3], Fry: s.ActualTCPForce[4], Frz: s.ActualTCPForce[5],
+	}
+	state.safetyStatusBits = s.SafetyStatusBits
+	return state
+}
+
+// rtdeConn reads the state of the arm through its RTDE interface.
+type rtdeConn struct {
+	conn     io.ReadWriter
+	recipeID byte
+}
+
+func rtdePacket(packetType byte, payload []byte) []byte {
+	packet := make([]byte, rtdeHeaderSize, rtdeHeaderSize+len(payload))
+	binary.BigEndian.PutUint16(packet, uint16(rtdeHeaderSize+len(payload)))
+	packet[2] = packetType
+	return append(packet, payload...)
+}
+
+func (c *rtdeConn) send(packetType byte, payload []byte) error {
+	_, err := c.conn.Write(rtdePacket(packetType, payload))
+	return err
+}
+
+// read returns the next packet, skipping text messages.
+func (c *rtdeConn) read(ctx context.Context) (byte, []byte, error) {
+	for {
+		header, err := goutils.ReadBytes(ctx, c.conn, rtdeHeaderSize)
+		if err != nil {
+			return 0, nil, err
+		}
+		size := int(binary.BigEndian.Uint16(header))
+		if size < rtdeHeaderSize {
+			return 0, nil, errors.Errorf("invalid rtde packet size %d", size)
+		}
+		payload, err := goutils.ReadBytes(ctx, c.conn, size-rtdeHeaderSize)
+		if err != nil {
+			return 0, nil, err
+		}
+		if header[2] == rtdeTextMessage {
+			continue
+		}
+		return header[2], payload, nil
+	}
+}
+
+// reply waits for the reply to a request, skipping data packages which may still be arriving.
+func (c *rtdeConn) reply(ctx context.Context, packetType byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, rtdeReplyTimeout)
+	defer cancel()
+	for {
+		got, payload, err := c.read(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed waiting for rtde reply %q", packetType)
+		}
+		if got == packetType {
+			return payload, nil
+		}
+	}
+}
+
+// setup negotiates the protocol version, subscribes to rtdeOutputs at the given frequency, and starts the stream.
+func (c *rtdeConn) setup(ctx context.Context, frequencyHz float64) error {
+	version := make([]byte, 2)
+	binary.BigEndian.PutUint16(version, rtdeProtocolVersion)
+	if err := c.send(rtdeRequestProtocolVersion, version); err != nil {
+		return err
+	}
+	reply, err := c.reply(ctx, rtdeRequestProtocolVersion)
+	if err != nil {
+		return err
+	}
+	if len(reply) < 1 || reply[0] != 1 {
+		return errors.Errorf("controller does not support rtde protocol version %d", rtdeProtocolVersion)
+	}
+
+	names := make([]string, 0, len(rtdeOutputs))
+	for _, output := range rtdeOutputs {
+		names = append(names, output.name)
+	}
+	setup := make([]byte, 8)
+	binary.BigEndian.PutUint64(setup, math.Float64bits(frequencyHz))
+	setup = append(setup, []byte(strings.Join(names, ","))...)
+	if err := c.send(rtdeSetupOutputs, setup); err != nil {
+		return err
+	}
+	reply, err = c.reply(ctx, rtdeSetupOutputs)
+	if err != nil {
+		return err
+	}
+	if len(reply) < 1 {
+		return errors.New("empty rtde output setup reply")
+	}
+	types := strings.Split(string(reply[1:]), ",")
+	if len(types) != len(rtdeOutputs) {
+		return errors.Errorf("controller set up %d rtde outputs but %d were requested", len(types), len(rtdeOutputs))
+	}
+	for i, typeName := range types {
+		if typeName != rtdeOutputs[i].typeName {
+			return errors.Errorf("rtde output %s is %s, expected %s", rtdeOutputs[i].name, typeName, rtdeOutputs[i].typeName)
+		}
+	}
+	c.recipeID = reply[0]
+
+	if err := c.send(rtdeStart, nil); err != nil {
+		return err
+	}
+	reply, err = c.reply(ctx, rtdeStart)
+	if err != nil {
+		return err
+	}
+	if len(reply) < 1 || reply[0] != 1 {
+		return errors.New("controller refused to start the rtde stream")
+	}
+	return nil
+}
+
+// readState returns the next data package.
+func (c *rtdeConn) readState(ctx context.Context) (rtdeState, error) {
+	for {
+		packetType, payload, err := c.read(ctx)
+		if err != nil {
+			return rtdeState{}, err
+		}
+		if packetType != rtdeDataPackage || len(payload) < 1 || payload[0] != c.recipeID {
+			continue
+		}
+		var state rtdeState
+		if err := binary.Read(bytes.NewReader(payload[1:]), binary.BigEndian, &state); err != nil {
+			return rtdeState{}, errors.Wrap(err, "malformed rtde data package")
+		}
+		return state, nil
+	}
+}
+
+// pause stops the stream, so the controller does not keep sending to a connection about to close.
+func (c *rtdeConn) pause() error {
+	return c.send(rtdePause, nil)
+}
@@ -0,0 +1,151 @@
+package universalrobots
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"net"
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/utils"
+)
+
+// fakeRTDEController answers the RTDE setup handshake and then sends the given states.
+func fakeRTDEController(t *testing.T, conn net.Conn, states []rtdeState) {
+	t.Helper()
+	c := &rtdeConn{conn: conn}
+	ctx := context.Background()
+
+	packetType, payload, err := c.read(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, packetType, test.ShouldEqual, rtdeRequestProtocolVersion)
+	test.That(t, binary.BigEndian.Uint16(payload), test.ShouldEqual, rtdeProtocolVersion)
+	test.That(t, c.send(rtdeTextMessage, []byte("hello")), test.ShouldBeNil)
+	test.That(t, c.send(rtdeRequestProtocolVersion, []byte{1}), test.ShouldBeNil)
+
+	packetType, payload, err = c.read(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, packetType, test.ShouldEqual, rtdeSetupOutputs)
+	test.That(t, math.Float64frombits(binary.BigEndian.Uint64(payload)), test.ShouldEqual, 125)
+	names := strings.Split(string(payload[8:]), ",")
+	types := make([]string, 0, len(names))
+	for i, name := range names {
+		test.That(t, name, test.ShouldEqual, rtdeOutputs[i].name)
+		types = append(types, rtdeOutputs[i].typeName)
+	}
+	test.That(t, c.send(rtdeSetupOutputs, append([]byte{7}, []byte(strings.Join(types, ","))...)), test.ShouldBeNil)
+
+	packetType, _, err = c.read(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, packetType, test.ShouldEqual, rtdeStart)
+	test.That(t, c.send(rtdeStart, []byte{1}), test.ShouldBeNil)
+
+	for _, state := range states {
+		var buf bytes.Buffer
+		buf.WriteByte(7)
+		test.That(t, binary.Write(&buf, binary.BigEndian, state), test.ShouldBeNil)
+		test.That(t, c.send(rtdeDataPackage, buf.Bytes()), test.ShouldBeNil)
+	}
+}
+
+func TestRTDE(t *testing.T) {
+	client, controller := net.Pipe()
+	defer func() {
+		goutils.UncheckedError(client.Close())
+		goutils.UncheckedError(controller.Close())
+	}()
+
+	want := rtdeState{
+		Timestamp:         12.5,
+		ActualQ:           [6]float64{math.Pi / 2, -math.Pi / 2, 0, 0.1, 0.2, 0.3},
+		ActualQd:          [6]float64{math.Pi, 0, 0, 0, 0, 0},
+		ActualCurrent:     [6]float64{1, 2, 3, 4, 5, 6},
+		JointTemperatures: [6]float64{30, 31, 32, 33, 34, 35},
+		ActualTCPForce:    [6]float64{1, 2, 3, 0.1, 0.2, 0.3},
+		RobotMode:         7,
+		SafetyMode:        3,
+		RobotStatusBits:   rtdeRobotPowerOn,
+		SafetyStatusBits:  rtdeSafetyProtectiveStopped | rtdeSafetyFault,
+		SpeedScaling:      0.5,
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fakeRTDEController(t, controller, []rtdeState{want})
+	}()
+
+	c := &rtdeConn{conn: client}
+	test.That(t, c.setup(context.Background(), 125), test.ShouldBeNil)
+	test.That(t, c.recipeID, test.ShouldEqual, 7)
+	got, err := c.readState(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, got, test.ShouldResemble, want)
+	<-done
+
+	state := got.robotState()
+	test.That(t, state.Joints, test.ShouldHaveLength, 6)
+	test.That(t, state.Joints[0].degrees(), test.ShouldAlmostEqual, 90)
+	test.That(t, state.Joints[1].degrees(), test.ShouldAlmostEqual, -90)
+	test.That(t, utils.RadToDeg(state.Joints[0].QDactual), test.ShouldAlmostEqual, 180)
+	test.That(t, state.Joints[5].Tmotor, test.ShouldEqual, 35)
+	test.That(t, state.robotModeData.IsRobotPowerOn, test.ShouldBeTrue)
+	test.That(t, state.robotModeData.IsProgramRunning, test.ShouldBeFalse)
+	test.That(t, state.robotModeData.IsProtectiveStopped, test.ShouldBeTrue)
+	test.That(t, state.robotModeData.IsEmergencyStopped, test.ShouldBeFalse)
+	test.That(t, state.robotModeData.SpeedScaling, test.ShouldEqual, 0.5)
+	test.That(t, state.forceModeData.Fz, test.ShouldEqual, 3)
+
+	ua := &urArm{}
+	ua.setState(state)
+	ua.rtdeActive.Store(true)
+	report, err := ua.DoCommand(context.Background(), map[string]interface{}{"command": "get_state"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, report["source"], test.ShouldEqual, "rtde")
+	test.That(t, report["protective_stopped"], test.ShouldBeTrue)
+	test.That(t, report["safety_fault"], test.ShouldBeTrue)
+	test.That(t, report["freedrive"], test.ShouldBeFalse)
+	test.That(t, report["tcp_force"].(map[string]interface{})["fz"], test.ShouldEqual, 3)
+
+	_, err = ua.DoCommand(context.Background(), map[string]interface{}{"command": "set_freedrive"})
+	test.That(t, err, test.ShouldBeError, "set_freedrive requires a boolean 'enabled' value")
+}
+
+func TestRTDESetupRejected(t *testing.T) {
+	client, controller := net.Pipe()
+	defer func() {
+		goutils.UncheckedError(client.Close())
+		goutils.UncheckedError(controller.Close())
+	}()
+
+	go func() {
+		c := &rtdeConn{conn: controller}
+		if _, _, err := c.read(context.Background()); err != nil {
+			return
+		}
+		goutils.UncheckedError(c.send(rtdeRequestProtocolVersion, []byte{0}))
+	}()
+
+	c := &rtdeConn{conn: client}
+	err := c.setup(context.Background(), 500)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "does not support rtde protocol version 2")
+}
+
+func TestRTDEFrequencyValidation(t *testing.T) {
+	cfg := Config{SpeedDegsPerSec: 30, Host: "localhost", RTDEFrequencyHz: 1000}
+	_, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	cfg.RTDEFrequencyHz = 125
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, rtdeFrequency(&cfg), test.ShouldEqual, 125)
+
+	cfg.RTDEFrequencyHz = 0
+	test.That(t, rtdeFrequency(&cfg), test.ShouldEqual, defaultRTDEFrequencyHz)
+}
@@ -14,6 +14,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -45,6 +46,11 @@ type Config struct {
 	SpeedDegsPerSec     float64 `json:"speed_degs_per_sec"`
 	Host                string  `json:"host"`
 	ArmHostedKinematics bool    `json:"arm_hosted_kinematics,omitempty"`
+	// RTDEFrequencyHz is how often the arm reports its state over RTDE. It defaults to 500, the rate of e-Series
+	// controllers, and CB3 controllers need it set to 125.
+	RTDEFrequencyHz float64 `json:"rtde_frequency_hz,omitempty"`
+	// DisableRTDE reads the state of the arm from the secondary interface instead, which updates at 10Hz.
+	DisableRTDE bool `json:"disable_rtde,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -55,6 +61,9 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 	if cfg.SpeedDegsPerSec > 180 || cfg.SpeedDegsPerSec < 3 {
 		return nil, errors.New("speed for universalrobots has to be between 3 and 180 degrees per second")
 	}
+	if cfg.RTDEFrequencyHz < 0 || cfg.RTDEFrequencyHz > maxRTDEFrequencyHz {
+		return nil, errors.Errorf("rtde_frequency_hz for universalrobots has to be between 0 and %d", maxRTDEFrequencyHz)
+	}
 	return []string{}, nil
 }
 
@@ -68,9 +77,23 @@ func init() {
 		) (arm.Arm, error) {
 			return urArmConnect(ctx, conf, logger)
 		},
+		ExtraParams: resource.ExtraParams{
+			"MoveToPosition": {{
+				Name:        "arm_hosted_kinematics",
+				Type:        resource.ExtraParamBool,
+				Description: "whether to plan the move with the kinematics hosted by the arm, overriding the config",
+			}},
+		},
 	})
 }
 
+func rtdeFrequency(conf *Config) float64 {
+	if conf.RTDEFrequencyHz == 0 {
+		return defaultRTDEFrequencyHz
+	}
+	return conf.RTDEFrequencyHz
+}
+
 // MakeModelFrame returns the kinematics model of the ur arm, also has all Frame information.
 func MakeModelFrame(name string) (referenceframe.Model, error) {
 	return referenceframe.UnmarshalModelJSON(ur5modeljson, name)
@@ -99,6 +122,17 @@ type urArm struct {
 	readRobotStateConnection net.Conn
 	host                     string
 	isConnected              bool
+
+	velocityMu       sync.Mutex
+	velocityWatchdog *arm.VelocityWatchdog
+
+	// rtdeActive is set while the state is read over RTDE, and the state reported by the secondary interface is
+	// ignored.
+	rtdeActive      atomic.Bool
+	rtdeConnection  net.Conn
+	rtdeFrequencyHz float64
+	rtdeDisabled    bool
+	freedrive       bool
 }
 
 const waitBackgroundWorkersDur = 5 * time.Second
@@ -120,15 +154,31 @@ func (ua *urArm) Reconfigure(ctx context.Context, deps resource.Dependencies, co
 		if ua.readRobotStateConnection != nil {
 			goutils.UncheckedError(ua.readRobotStateConnection.Close())
 		}
+		if ua.rtdeConnection != nil {
+			goutils.UncheckedError(ua.rtdeConnection.Close())
+		}
 		return nil
 	}
 	ua.speedRadPerSec = rdkutils.DegToRad(newConf.SpeedDegsPerSec)
 	ua.urHostedKinematics = newConf.ArmHostedKinematics
+	if frequency := rtdeFrequency(newConf); frequency != ua.rtdeFrequencyHz || newConf.DisableRTDE != ua.rtdeDisabled {
+		ua.rtdeFrequencyHz = frequency
+		ua.rtdeDisabled = newConf.DisableRTDE
+		// the stream is set up again with the new settings
+		if ua.rtdeConnection != nil {
+			goutils.UncheckedError(ua.rtdeConnection.Close())
+		}
+	}
 	return nil
 }
 
 // Close cleans up the UR arm.
 func (ua *urArm) Close(ctx context.Context) error {
+	ua.velocityMu.Lock()
+	if ua.velocityWatchdog != nil {
+		ua.velocityWatchdog.Close()
+	}
+	ua.velocityMu.Unlock()
 	ua.cancel()
 
 	closeConn := func() {
@@ -198,6 +248,8 @@ func urArmConnect(ctx context.Context, conf resource.Config, logger logging.Logg
 		model:                    model,
 		opMgr:                    operation.NewSingleOperationManager(),
 		urHostedKinematics:       newConf.ArmHostedKinematics,
+		rtdeFrequencyHz:          rtdeFrequency(newConf),
+		rtdeDisabled:             newConf.DisableRTDE,
 		inRemoteMode:             false,
 		readRobotStateConnection: connReadRobotState,
 		dashboardConnection:      connDashboard,
@@ -283,6 +335,15 @@ func urArmConnect(ctx context.Context, conf resource.Config, logger logging.Logg
 		}
 	}, newArm.activeBackgroundWorkers.Done)
 
+	newArm.activeBackgroundWorkers.Add(1)
+	goutils.ManagedGo(func() {
+		newArm.readRTDE(cancelCtx, func() {
+			onDataOnce.Do(func() {
+				close(onData)
+			})
+		})
+	}, newArm.activeBackgroundWorkers.Done)
+
 	respondTimeout := 2 * time.Second
 	timer := time.NewTimer(respondTimeout)
 	defer timer.Stop()
@@ -360,6 +421,9 @@ func (ua *urArm) MoveToPosition(ctx context.Context, pos spatialmath.Pose, extra
 	if !ua.inRemoteMode {
 		return errors.New("UR5 is in local mode; use the polyscope to switch it to remote control mode")
 	}
+	if ua.inFreedrive() {
+		return errFreedrive
+	}
 	ctx, done := ua.opMgr.New(ctx)
 	defer done()
 
@@ -377,6 +441,9 @@ func (ua *urArm) MoveToPosition(ctx context.Context, pos spatialmath.Pose, extra
 
 // MoveToJointPositions moves the UR arm to the specified joint positions.
 func (ua *urArm) MoveToJointPositions(ctx context.Context, joints *pb.JointPositions, extra map[string]interface{}) error {
+	if ua.inFreedrive() {
+		return errFreedrive
+	}
 	// check that joint positions are not out of bounds
 	inputs := ua.ModelFrame().InputFromProtobuf(joints)
 	if err := arm.CheckDesiredJointPositions(ctx, ua, inputs); err != nil {
@@ -392,37 +459,243 @@ func (ua *urArm) Stop(ctx context.Context, extra map[string]interface{}) error {
 	}
 	_, done := ua.opMgr.New(ctx)
 	defer done()
+	ua.endVelocityStream()
+	if ua.inFreedrive() {
+		// the stop command replaces the freedrive program on the controller
+		ua.mu.Lock()
+		ua.freedrive = false
+		ua.mu.Unlock()
+	}
 	cmd := fmt.Sprintf("stopj(a=%1.2f)\r\n", 5.0*ua.speedRadPerSec)
 
 	_, err := ua.connControl.Write([]byte(cmd))
 	return err
 }
 
-// IsMoving returns whether the arm is moving.
-func (ua *urArm) IsMoving(ctx context.Context) (bool, error) {
-	return ua.opMgr.OpRunning(), nil
+// freedriveProgram keeps the arm in freedrive mode until another program replaces it.
+const freedriveProgram = `def viam_freedrive():
+  freedrive_mode()
+  while (True):
+    sync()
+  end
+end
+`
+
+const endFreedriveProgram = `def viam_end_freedrive():
+  end_freedrive_mode()
+end
+`
+
+var errFreedrive = errors.New("UR arm is in freedrive mode; disable it with the set_freedrive command first")
+
+func (ua *urArm) inFreedrive() bool {
+	ua.mu.Lock()
+	defer ua.mu.Unlock()
+	return ua.freedrive
 }
 
-func (ua *urArm) moveToJointPositionRadians(ctx context.Context, radians []float64) error {
+// setFreedrive puts the arm in or out of freedrive mode, in which it can be moved by hand to teach it positions.
+func (ua *urArm) setFreedrive(ctx context.Context, enabled bool) error {
 	if !ua.inRemoteMode {
 		return errors.New("UR5 is in local mode; use the polyscope to switch it to remote control mode")
 	}
-	ctx, done := ua.opMgr.New(ctx)
-	defer done()
+	if ua.connControl == nil {
+		return errors.New("not connected to the UR arm's control interface")
+	}
+	if enabled {
+		// nothing else may drive the arm while it is moved by hand
+		ua.endVelocityStream()
+		ua.opMgr.CancelRunning(ctx)
+	}
 
-	ua.muMove.Lock()
-	defer ua.muMove.Unlock()
+	ua.mu.Lock()
+	defer ua.mu.Unlock()
+	program := endFreedriveProgram
+	if enabled {
+		program = freedriveProgram
+	}
+	if _, err := ua.connControl.Write([]byte(program)); err != nil {
+		return err
+	}
+	ua.freedrive = enabled
+	return nil
+}
 
-	if len(radians) != 6 {
-		return errors.New("need 6 joints")
+// DoCommand supports "set_freedrive", which puts the arm in or out of freedrive mode depending on "enabled", and
+// "get_state", which returns the state of the arm beyond what the arm API reports.
+func (ua *urArm) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
 	}
+	switch name {
+	case "set_freedrive":
+		enabled, ok := cmd["enabled"].(bool)
+		if !ok {
+			return nil, errors.New("set_freedrive requires a boolean 'enabled' value")
+		}
+		if err := ua.setFreedrive(ctx, enabled); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"freedrive": enabled}, nil
+	case "get_state":
+		return ua.stateReport()
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
 
+// stateReport returns the latest state of the arm as a DoCommand response.
+func (ua *urArm) stateReport() (map[string]interface{}, error) {
 	state, err := ua.getState()
 	if err != nil {
+		return nil, err
+	}
+	velocities := make([]interface{}, 0, len(state.Joints))
+	currents := make([]interface{}, 0, len(state.Joints))
+	temperatures := make([]interface{}, 0, len(state.Joints))
+	for _, j := range state.Joints {
+		velocities = append(velocities, rdkutils.RadToDeg(j.QDactual))
+		currents = append(currents, float64(j.Iactual))
+		temperatures = append(temperatures, float64(j.Tmotor))
+	}
+	source := "secondary_interface"
+	if ua.rtdeActive.Load() {
+		source = "rtde"
+	}
+	force := state.forceModeData
+	return map[string]interface{}{
+		"joint_velocities_degs_per_sec": velocities,
+		"joint_currents":                currents,
+		"joint_temperatures":            temperatures,
+		"tcp_force": map[string]interface{}{
+			"fx": force.Fx, "fy": force.Fy, "fz": force.Fz,
+			"frx": force.Frx, "fry": force.Fry, "frz": force.Frz,
+		},
+		"emergency_stopped":  state.robotModeData.IsEmergencyStopped,
+		"protective_stopped": state.robotModeData.IsProtectiveStopped,
+		"safety_fault":       state.safetyStatusBits&(rtdeSafetyViolation|rtdeSafetyFault) != 0,
+		"safety_mode":        float64(state.masterboardData.SafetyMode),
+		"robot_mode":         float64(state.robotModeData.RobotMode),
+		"speed_scaling":      state.robotModeData.SpeedScaling,
+		"freedrive":          ua.inFreedrive(),
+		"source":             source,
+	}, nil
+}
+
+// SetJointVelocities sets the velocity of every joint with speedj. Every command only runs for
+// arm.DefaultVelocityStreamTimeout, so the controller decelerates the arm on its own if the stream stalls, and the
+// arm is also stopped explicitly once that happens.
+func (ua *urArm) SetJointVelocities(ctx context.Context, velocitiesDegsPerSec []float64, extra map[string]interface{}) error {
+	if !ua.inRemoteMode {
+		return errors.New("UR5 is in local mode; use the polyscope to switch it to remote control mode")
+	}
+	if ua.inFreedrive() {
+		return errFreedrive
+	}
+	if len(velocitiesDegsPerSec) != 6 {
+		return errors.New("need 6 joint velocities")
+	}
+	radPerSec := make([]float64, 0, len(velocitiesDegsPerSec))
+	for i, v := range velocitiesDegsPerSec {
+		r := rdkutils.DegToRad(v)
+		if math.Abs(r) > ua.speedRadPerSec {
+			return errors.Errorf("velocity %.2f of joint %d exceeds the configured limit of %.2f degrees per second",
+				v, i, rdkutils.RadToDeg(ua.speedRadPerSec))
+		}
+		radPerSec = append(radPerSec, r)
+	}
+
+	ua.velocityMu.Lock()
+	defer ua.velocityMu.Unlock()
+	if ua.velocityWatchdog == nil {
+		ua.velocityWatchdog = arm.NewVelocityWatchdog(arm.DefaultVelocityStreamTimeout, ua.haltVelocityStream)
+	}
+	if !ua.velocityWatchdog.Armed() {
+		// a velocity stream takes over from any move in progress
+		ua.opMgr.CancelRunning(ctx)
+	}
+	if ua.connControl == nil {
+		return errors.New("not connected to the UR arm's control interface")
+	}
+
+	cmd := fmt.Sprintf("speedj([%f,%f,%f,%f,%f,%f], a=%1.2f, t=%1.3f)\r\n",
+		radPerSec[0],
+		radPerSec[1],
+		radPerSec[2],
+		radPerSec[3],
+		radPerSec[4],
+		radPerSec[5],
+		5.0*ua.speedRadPerSec,
+		ua.velocityWatchdog.Timeout().Seconds(),
+	)
+	if _, err := ua.connControl.Write([]byte(cmd)); err != nil {
 		return err
 	}
+	ua.velocityWatchdog.Feed()
+	return nil
+}
+
+// endVelocityStream disarms the watchdog of a joint velocity stream, for when another command takes over the arm.
+func (ua *urArm) endVelocityStream() {
+	ua.velocityMu.Lock()
+	defer ua.velocityMu.Unlock()
+	if ua.velocityWatchdog != nil {
+		ua.velocityWatchdog.Disarm()
+	}
+}
+
+// haltVelocityStream stops the arm after a joint velocity stream stalled.
+func (ua *urArm) haltVelocityStream() {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	ua.logger.CWarn(ctx, "joint velocity stream stalled, stopping the arm")
+	if err := ua.Stop(ctx, nil); err != nil {
+		ua.logger.CErrorw(ctx, "failed to stop the arm after its joint velocity stream stalled", "error", err)
+	}
+}
+
+// IsMoving returns whether the arm is moving, which it is while running a move or a joint velocity stream.
+func (ua *urArm) IsMoving(ctx context.Context) (bool, error) {
+	if ua.opMgr.OpRunning() {
+		return true, nil
+	}
+	ua.velocityMu.Lock()
+	defer ua.velocityMu.Unlock()
+	return ua.velocityWatchdog != nil && ua.velocityWatchdog.Armed(), nil
+}
+
+func (ua *urArm) moveToJointPositionRadians(ctx context.Context, radians []float64) error {
+	return ua.moveThroughJointPositionsRadians(ctx, [][]float64{radians}, 0)
+}
 
-	cmd := fmt.Sprintf("movej([%f,%f,%f,%f,%f,%f], a=%1.2f, v=%1.2f, r=0)\r\n",
+// MoveThroughJointPositions moves the UR arm through the joint positions with a single program of movej commands, which
+// blend into each other within the blend radius.
+func (ua *urArm) MoveThroughJointPositions(
+	ctx context.Context, positions []*pb.JointPositions, options *arm.MoveOptions, extra map[string]interface{},
+) error {
+	if ua.inFreedrive() {
+		return errFreedrive
+	}
+	waypoints := make([][]float64, 0, len(positions))
+	for _, joints := range positions {
+		// check that joint positions are not out of bounds
+		if err := arm.CheckDesiredJointPositions(ctx, ua, ua.ModelFrame().InputFromProtobuf(joints)); err != nil {
+			return err
+		}
+		waypoints = append(waypoints, referenceframe.JointPositionsToRadians(joints))
+	}
+	var blendRadiusMM float64
+	if options != nil {
+		blendRadiusMM = options.BlendRadiusMM
+	}
+	return ua.moveThroughJointPositionsRadians(ctx, waypoints, blendRadiusMM)
+}
+
+// moveJCommand returns a movej command to the joint positions which starts blending into the next command within the
+// blend radius.
+func (ua *urArm) moveJCommand(radians []float64, blendRadiusM float64) string {
+	return fmt.Sprintf("movej([%f,%f,%f,%f,%f,%f], a=%1.2f, v=%1.2f, r=%f)",
 		radians[0],
 		radians[1],
 		radians[2],
@@ -431,26 +704,84 @@ func (ua *urArm) moveToJointPositionRadians(ctx context.Context, radians []float
 		radians[5],
 		0.8*ua.speedRadPerSec,
 		ua.speedRadPerSec,
+		blendRadiusM,
 	)
+}
+
+func (ua *urArm) moveThroughJointPositionsRadians(ctx context.Context, waypoints [][]float64, blendRadiusMM float64) error {
+	if !ua.inRemoteMode {
+		return errors.New("UR5 is in local mode; use the polyscope to switch it to remote control mode")
+	}
+	if len(waypoints) == 0 {
+		return nil
+	}
+	ctx, done := ua.opMgr.New(ctx)
+	defer done()
+
+	ua.muMove.Lock()
+	defer ua.muMove.Unlock()
+	ua.endVelocityStream()
 
-	// calculate a timeout that corresponds to how fast the arm will move
-	maxAngle := 0.
+	for _, radians := range waypoints {
+		if len(radians) != 6 {
+			return errors.New("need 6 joints")
+		}
+	}
+
+	state, err := ua.getState()
+	if err != nil {
+		return err
+	}
+
+	var cmd string
+	if len(waypoints) == 1 {
+		cmd = ua.moveJCommand(waypoints[0], 0) + "\r\n"
+	} else {
+		// the commands of a program are planned together, which is what lets the controller blend them
+		var program strings.Builder
+		program.WriteString("def viam_move_through():\n")
+		for i, radians := range waypoints {
+			blendRadiusM := 0.001 * blendRadiusMM
+			if i == len(waypoints)-1 {
+				// the arm has to stop at the last position
+				blendRadiusM = 0
+			}
+			program.WriteString("  " + ua.moveJCommand(radians, blendRadiusM) + "\n")
+		}
+		program.WriteString("end\n")
+		cmd = program.String()
+	}
+
+	// calculate a timeout that corresponds to how fast the arm will move through all of the positions
+	totalAngle := 0.
+	from := make([]float64, 0, 6)
 	for i := 0; i < 6; i++ {
-		if diff := math.Abs(state.Joints[i].Qactual - radians[i]); diff > maxAngle {
-			maxAngle = diff
+		from = append(from, state.Joints[i].Qactual)
+	}
+	for _, radians := range waypoints {
+		maxAngle := 0.
+		for i := 0; i < 6; i++ {
+			if diff := math.Abs(from[i] - radians[i]); diff > maxAngle {
+				maxAngle = diff
+			}
 		}
+		totalAngle += maxAngle
+		from = radians
 	}
 
 	// make the timeout the max between the default and time calculated by slapping a 20% factor on the estimated time to complete
 	timeout := defaultTimeout
-	if estTime := time.Duration(1.2*maxAngle/ua.speedRadPerSec) * time.Second; estTime > timeout {
+	if estTime := time.Duration(1.2*totalAngle/ua.speedRadPerSec) * time.Second; estTime > timeout {
 		timeout = estTime
 	}
 
 	if _, err := ua.connControl.Write([]byte(cmd)); err != nil {
 		return err
 	}
+	estimate := time.Duration(totalAngle / ua.speedRadPerSec * float64(time.Second))
+	defer operation.TrackProgress(ctx, operation.EstimatedProgress(time.Now(), estimate))()
 
+	radians := waypoints[len(waypoints)-1]
 	now := time.Now()
 	for {
 		state, err := ua.getState()
@@ -625,7 +956,9 @@ func reader(ctx context.Context, conn net.Conn, ua *urArm, onHaveData func()) er
 			if err != nil {
 				return err
 			}
-			ua.setState(state)
+			if !ua.rtdeActive.Load() {
+				ua.setState(state)
+			}
 			onHaveData()
 			if ua.debug {
 				ua.logger.CDebugf(ctx, "isOn: %v stopped: %v joints: %f %f %f %f %f %f cartesian: %f %f %f %f %f %f\n",
@@ -674,7 +1007,87 @@ func reader(ctx context.Context, conn net.Conn, ua *urArm, onHaveData func()) er
 	}
 }
 
+// readRTDE keeps the state of the arm up to date from its RTDE interface, reconnecting whenever the connection
+// drops. The secondary interface is used until RTDE is up, so controllers without it keep working.
+func (ua *urArm) readRTDE(ctx context.Context, onHaveData func()) {
+	warned := false
+	for {
+		ua.mu.Lock()
+		disabled := ua.rtdeDisabled
+		ua.mu.Unlock()
+		if disabled {
+			if !goutils.SelectContextOrWait(ctx, 1*time.Second) {
+				return
+			}
+			continue
+		}
+
+		err := ua.streamRTDE(ctx, onHaveData)
+		ua.rtdeActive.Store(false)
+		if ctx.Err() != nil {
+			return
+		}
+		if !warned {
+			ua.logger.CWarnw(ctx, "cannot read ur arm state over rtde, falling back to the secondary interface", "error", err)
+			warned = true
+		} else {
+			ua.logger.CDebugw(ctx, "rtde connection to ur arm failed", "error", err)
+		}
+		if !goutils.SelectContextOrWait(ctx, 1*time.Second) {
+			return
+		}
+	}
+}
+
+func (ua *urArm) streamRTDE(ctx context.Context, onHaveData func()) error {
+	ua.mu.Lock()
+	host := ua.host
+	frequencyHz := ua.rtdeFrequencyHz
+	ua.mu.Unlock()
+
+	var d net.Dialer
+	dialCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	conn, err := d.DialContext(dialCtx, "tcp", net.JoinHostPort(host, rtdePort))
+	cancel()
+	if err != nil {
+		return err
+	}
+	ua.mu.Lock()
+	ua.rtdeConnection = conn
+	ua.mu.Unlock()
+	defer func() {
+		goutils.UncheckedError(conn.Close())
+	}()
+	// net.Conns do not honor contexts, so closing is what interrupts a pending read on shutdown
+	stop := context.AfterFunc(ctx, func() {
+		goutils.UncheckedError(conn.Close())
+	})
+	defer stop()
+
+	rtde := &rtdeConn{conn: conn}
+	if err := rtde.setup(ctx, frequencyHz); err != nil {
+		return err
+	}
+	defer func() {
+		goutils.UncheckedError(rtde.pause())
+	}()
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+			return err
+		}
+		state, err := rtde.readState(ctx)
+		if err != nil {
+			return err
+		}
+		ua.rtdeActive.Store(true)
+		ua.setState(state.robotState())
+		onHaveData()
+	}
+}
+
 func (ua *urArm) moveWithURHostedKinematics(ctx context.Context, pose spatialmath.Pose) error {
+	ua.endVelocityStream()
+
 	// UR5 arm takes R3 angle axis as input
 	pt := pose.Point()
 	aa := pose.Orientation().AxisAngles().ToR3()
@@ -22,6 +22,7 @@ import (
 
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/operation"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/spatialmath"
@@ -437,3 +438,40 @@ func TestReconfigure(t *testing.T) {
 	test.That(t, ur5e.speedRadPerSec, test.ShouldEqual, utils.DegToRad(0.5))
 	test.That(t, ur5e.host, test.ShouldEqual, "new")
 }
+
+func TestIsMovingDuringVelocityStream(t *testing.T) {
+	ctx := context.Background()
+	controller, conn := net.Pipe()
+	defer conn.Close()
+	defer controller.Close()
+	commands := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(controller)
+		for scanner.Scan() {
+			commands <- scanner.Text()
+		}
+	}()
+	ua := &urArm{
+		logger:         logging.NewTestLogger(t),
+		opMgr:          operation.NewSingleOperationManager(),
+		connControl:    conn,
+		inRemoteMode:   true,
+		speedRadPerSec: utils.DegToRad(60),
+	}
+
+	moving, err := ua.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeFalse)
+
+	// a speedj stream runs outside of the operation manager, but the arm is moving while it is being fed
+	test.That(t, ua.SetJointVelocities(ctx, []float64{10, 0, 0, 0, 0, 0}, nil), test.ShouldBeNil)
+	test.That(t, <-commands, test.ShouldStartWith, "speedj(")
+	moving, err = ua.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeTrue)
+
+	ua.endVelocityStream()
+	moving, err = ua.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeFalse)
+}
@@ -141,7 +141,9 @@ type robotState struct {
 	Kinematics []kinematicInfo
 	forceModeData
 	additionalInfo
-	creationTime time.Time
+	// safetyStatusBits are only reported over RTDE.
+	safetyStatusBits uint32
+	creationTime     time.Time
 }
 
 func readRobotStateMessage(ctx context.Context, buf []byte, logger logging.Logger) (robotState, error) {
@@ -0,0 +1,142 @@
+//go:build !no_cgo
+
+package arm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultVelocityStreamTimeout is how long an arm keeps moving at the last commanded joint velocities when no new
+// command arrives. Streams are expected to send commands at 100Hz or more, so this allows for a few late ones.
+const DefaultVelocityStreamTimeout = 200 * time.Millisecond
+
+// setJointVelocitiesCommand is the command which carries SetJointVelocities over DoCommand, since the arm service
+// has no method for it.
+const setJointVelocitiesCommand = "set_joint_velocities"
+
+// ErrJointVelocitiesUnsupported is returned when an arm cannot be driven by joint velocities.
+var ErrJointVelocitiesUnsupported = errors.New("arm does not support joint velocity control")
+
+// A JointVelocityController is an arm which can be jogged by streaming joint velocities, as is done for teleoperation
+// and visual servoing.
+type JointVelocityController interface {
+	// SetJointVelocities sets the velocity of every joint of the arm, in degrees per second. It returns as soon as the
+	// command is sent; the arm keeps moving until another command arrives, Stop is called, or the stream stalls for
+	// longer than the arm's watchdog timeout, after which the arm halts.
+	//
+	//    myArm, err := arm.FromRobot(machine, "my_arm")
+	//    controller, ok := myArm.(arm.JointVelocityController)
+	//    // Turn the first joint at 10 degrees per second, resending the command at least every 200ms.
+	//    err = controller.SetJointVelocities(context.Background(), []float64{10, 0, 0, 0, 0, 0}, nil)
+	SetJointVelocities(ctx context.Context, velocitiesDegsPerSec []float64, extra map[string]interface{}) error
+}
+
+// SetJointVelocities sets the joint velocities of the arm if it is a JointVelocityController.
+func SetJointVelocities(ctx context.Context, a Arm, velocitiesDegsPerSec []float64, extra map[string]interface{}) error {
+	controller, ok := a.(JointVelocityController)
+	if !ok {
+		return ErrJointVelocitiesUnsupported
+	}
+	return controller.SetJointVelocities(ctx, velocitiesDegsPerSec, extra)
+}
+
+// VelocityWatchdog halts an arm when a stream of joint velocity commands stalls. Drivers implementing
+// JointVelocityController feed it on every command and disarm it once the arm is stopped by other means.
+type VelocityWatchdog struct {
+	timeout time.Duration
+	halt    func()
+
+	mu    sync.Mutex
+	timer *time.Timer
+	// generation is bumped every time the watchdog is fed or disarmed, so a timer which fired just before that
+	// knows it is stale.
+	generation uint64
+	closed     bool
+}
+
+// NewVelocityWatchdog returns a disarmed watchdog which will call halt when it is not fed for timeout.
+func NewVelocityWatchdog(timeout time.Duration, halt func()) *VelocityWatchdog {
+	if timeout <= 0 {
+		timeout = DefaultVelocityStreamTimeout
+	}
+	return &VelocityWatchdog{timeout: timeout, halt: halt}
+}
+
+// Timeout returns how long the watchdog waits for the next command before halting the arm.
+func (w *VelocityWatchdog) Timeout() time.Duration {
+	return w.timeout
+}
+
+// Feed arms the watchdog, or restarts its countdown if it is already armed.
+func (w *VelocityWatchdog) Feed() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	w.stopTimer()
+	generation := w.generation
+	w.timer = time.AfterFunc(w.timeout, func() {
+		w.mu.Lock()
+		if w.generation != generation || w.closed {
+			w.mu.Unlock()
+			return
+		}
+		w.timer = nil
+		w.mu.Unlock()
+		w.halt()
+	})
+}
+
+// Disarm stops the countdown without halting the arm. It returns whether the watchdog was armed.
+func (w *VelocityWatchdog) Disarm() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stopTimer()
+}
+
+// Armed returns whether a velocity stream is being watched.
+func (w *VelocityWatchdog) Armed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.timer != nil
+}
+
+// Close disarms the watchdog for good.
+func (w *VelocityWatchdog) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopTimer()
+	w.closed = true
+}
+
+func (w *VelocityWatchdog) stopTimer() bool {
+	w.generation++
+	if w.timer == nil {
+		return false
+	}
+	w.timer.Stop()
+	w.timer = nil
+	return true
+}
+
+// jointVelocitiesFromCommand parses the velocities of a set_joint_velocities command.
+func jointVelocitiesFromCommand(cmd map[string]interface{}) ([]float64, error) {
+	raw, ok := cmd["velocities_degs_per_sec"].([]interface{})
+	if !ok {
+		return nil, errors.New("set_joint_velocities requires a list of velocities_degs_per_sec")
+	}
+	velocities := make([]float64, 0, len(raw))
+	for _, v := range raw {
+		value, ok := v.(float64)
+		if !ok {
+			return nil, errors.Errorf("joint velocity %v is not a number", v)
+		}
+		velocities = append(velocities, value)
+	}
+	return velocities, nil
+}
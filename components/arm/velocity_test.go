@@ -0,0 +1,65 @@
+package arm_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/arm"
+)
+
+func TestVelocityWatchdog(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+
+	t.Run("halts a stalled stream", func(t *testing.T) {
+		var halts atomic.Int32
+		w := arm.NewVelocityWatchdog(timeout, func() { halts.Add(1) })
+		defer w.Close()
+		test.That(t, w.Armed(), test.ShouldBeFalse)
+
+		w.Feed()
+		test.That(t, w.Armed(), test.ShouldBeTrue)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			test.That(tb, halts.Load(), test.ShouldEqual, 1)
+		})
+		test.That(t, w.Armed(), test.ShouldBeFalse)
+
+		// nothing more happens until the next stream starts
+		time.Sleep(2 * timeout)
+		test.That(t, halts.Load(), test.ShouldEqual, 1)
+	})
+
+	t.Run("keeps a fed stream going", func(t *testing.T) {
+		var halts atomic.Int32
+		w := arm.NewVelocityWatchdog(timeout, func() { halts.Add(1) })
+		defer w.Close()
+		for i := 0; i < 10; i++ {
+			w.Feed()
+			time.Sleep(timeout / 5)
+		}
+		test.That(t, halts.Load(), test.ShouldEqual, 0)
+		test.That(t, w.Disarm(), test.ShouldBeTrue)
+		time.Sleep(2 * timeout)
+		test.That(t, halts.Load(), test.ShouldEqual, 0)
+		test.That(t, w.Disarm(), test.ShouldBeFalse)
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		var halts atomic.Int32
+		w := arm.NewVelocityWatchdog(timeout, func() { halts.Add(1) })
+		w.Feed()
+		w.Close()
+		w.Feed()
+		time.Sleep(2 * timeout)
+		test.That(t, halts.Load(), test.ShouldEqual, 0)
+	})
+
+	t.Run("default timeout", func(t *testing.T) {
+		w := arm.NewVelocityWatchdog(0, func() {})
+		test.That(t, w.Timeout(), test.ShouldEqual, arm.DefaultVelocityStreamTimeout)
+	})
+}
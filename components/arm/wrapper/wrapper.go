@@ -138,6 +138,24 @@ func (wrapper *Arm) MoveToJointPositions(ctx context.Context, joints *pb.JointPo
 	return wrapper.actual.MoveToJointPositions(ctx, joints, extra)
 }
 
+// MoveThroughJointPositions moves the actual arm through the joints.
+func (wrapper *Arm) MoveThroughJointPositions(
+	ctx context.Context, positions []*pb.JointPositions, options *arm.MoveOptions, extra map[string]interface{},
+) error {
+	// check that joint positions are not out of bounds
+	for _, joints := range positions {
+		if err := arm.CheckDesiredJointPositions(ctx, wrapper, wrapper.model.InputFromProtobuf(joints)); err != nil {
+			return err
+		}
+	}
+	ctx, done := wrapper.opMgr.New(ctx)
+	defer done()
+
+	wrapper.mu.RLock()
+	defer wrapper.mu.RUnlock()
+	return arm.MoveThroughJointPositions(ctx, wrapper.actual, positions, options, extra)
+}
+
 // JointPositions returns the set joints.
 func (wrapper *Arm) JointPositions(ctx context.Context, extra map[string]interface{}) (*pb.JointPositions, error) {
 	wrapper.mu.RLock()
@@ -160,6 +178,15 @@ func (wrapper *Arm) Stop(ctx context.Context, extra map[string]interface{}) erro
 	return wrapper.actual.Stop(ctx, extra)
 }
 
+// SetJointVelocities sets the joint velocities of the actual arm.
+func (wrapper *Arm) SetJointVelocities(ctx context.Context, velocitiesDegsPerSec []float64, extra map[string]interface{}) error {
+	wrapper.opMgr.CancelRunning(ctx)
+
+	wrapper.mu.RLock()
+	defer wrapper.mu.RUnlock()
+	return arm.SetJointVelocities(ctx, wrapper.actual, velocitiesDegsPerSec, extra)
+}
+
 // IsMoving returns whether the arm is moving.
 func (wrapper *Arm) IsMoving(ctx context.Context) (bool, error) {
 	return wrapper.opMgr.OpRunning(), nil
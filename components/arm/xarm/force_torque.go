@@ -0,0 +1,319 @@
+package xarm
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	rutils "go.viam.com/rdk/utils"
+)
+
+// The applications of the force-torque sensor the control box can run.
+const (
+	forceModeNone      byte = 0
+	forceModeImpedance byte = 1
+	forceModeForce     byte = 2
+)
+
+var forceModeNames = map[byte]string{
+	forceModeNone:      "none",
+	forceModeImpedance: "impedance",
+	forceModeForce:     "force",
+}
+
+var (
+	// defaultImpedanceMass is the virtual mass of the end effector in kg for the linear axes and kg*m^2 for the
+	// rotational ones.
+	defaultImpedanceMass = []float64{0.06, 0.06, 0.06, 0.0006, 0.0006, 0.0006}
+	// defaultImpedanceStiffness is in N/m for the linear axes and Nm/rad for the rotational ones.
+	defaultImpedanceStiffness = []float64{300, 300, 300, 4, 4, 4}
+	// defaultImpedanceDamping of zero lets the control box compute critical damping from the mass and stiffness.
+	defaultImpedanceDamping = []float64{0, 0, 0, 0, 0, 0}
+	defaultCompliantAxes    = []bool{true, true, true, true, true, true}
+)
+
+// forceTorqueReadings returns the forces in newtons and torques in newton meters measured by the force-torque
+// sensor at the end of the arm.
+func (x *xArm) forceTorqueReadings(ctx context.Context) (map[string]interface{}, error) {
+	c := x.newCmd(regMap["GetFTData"])
+	ftData, err := x.send(ctx, c, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(ftData.params) < 1+6*4 {
+		return nil, errors.New("malformed force-torque data response")
+	}
+	readings := map[string]interface{}{}
+	for i, key := range []string{"fx", "fy", "fz", "tx", "ty", "tz"} {
+		idx := i*4 + 1
+		readings[key] = float64(rutils.Float32FromBytesLE(ftData.params[idx : idx+4]))
+	}
+	return readings, nil
+}
+
+// zeroForceTorqueSensor makes the current load read as zero force and torque.
+func (x *xArm) zeroForceTorqueSensor(ctx context.Context) error {
+	c := x.newCmd(regMap["FTSensorSetZero"])
+	_, err := x.send(ctx, c, true)
+	return err
+}
+
+// setForceMode enables the force-torque sensor and hands it to the given application, or disables both for
+// forceModeNone.
+func (x *xArm) setForceMode(ctx context.Context, mode byte) error {
+	var enable byte
+	if mode != forceModeNone {
+		enable = 1
+	}
+	c := x.newCmd(regMap["FTSensorEnable"])
+	c.params = append(c.params, enable)
+	if _, err := x.send(ctx, c, true); err != nil {
+		return err
+	}
+	c = x.newCmd(regMap["FTSensorSetApp"])
+	c.params = append(c.params, mode)
+	if _, err := x.send(ctx, c, true); err != nil {
+		return err
+	}
+	x.mu.Lock()
+	x.forceMode = mode
+	x.mu.Unlock()
+	return x.setMotionState(ctx, 0)
+}
+
+// setImpedance makes the arm behave like a spring-mass-damper along the compliant axes, so it yields to contact
+// instead of fighting it.
+func (x *xArm) setImpedance(ctx context.Context, toolFrame bool, axes []bool, mass, stiffness, damping []float64) error {
+	c := x.newCmd(regMap["ImpedanceMBK"])
+	for _, values := range [][]float64{mass, stiffness, damping} {
+		c.params = appendFloat32s(c.params, values)
+	}
+	if _, err := x.send(ctx, c, true); err != nil {
+		return err
+	}
+	c = x.newCmd(regMap["ImpedanceConfig"])
+	c.params = appendAxes(append(c.params, boolByte(toolFrame)), axes)
+	if _, err := x.send(ctx, c, true); err != nil {
+		return err
+	}
+	return x.setForceMode(ctx, forceModeImpedance)
+}
+
+// setForceControl makes the arm push with the target force along the compliant axes, moving no faster than the
+// limits while doing so.
+func (x *xArm) setForceControl(ctx context.Context, toolFrame bool, axes []bool, targetForce, limits []float64) error {
+	c := x.newCmd(regMap["ForceCtrlConfig"])
+	c.params = appendAxes(append(c.params, boolByte(toolFrame)), axes)
+	c.params = appendFloat32s(c.params, targetForce)
+	c.params = appendFloat32s(c.params, limits)
+	if _, err := x.send(ctx, c, true); err != nil {
+		return err
+	}
+	return x.setForceMode(ctx, forceModeForce)
+}
+
+func appendFloat32s(params []byte, values []float64) []byte {
+	floatBytes := make([]byte, 4)
+	for _, v := range values {
+		binary.LittleEndian.PutUint32(floatBytes, math.Float32bits(float32(v)))
+		params = append(params, floatBytes...)
+	}
+	return params
+}
+
+func appendAxes(params []byte, axes []bool) []byte {
+	for _, axis := range axes {
+		params = append(params, boolByte(axis))
+	}
+	return params
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// floatsArg returns the list of six floats under key in a DoCommand, or the defaults if it is missing.
+func floatsArg(cmd map[string]interface{}, key string, defaults []float64) ([]float64, error) {
+	raw, ok := cmd[key]
+	if !ok {
+		return defaults, nil
+	}
+	list, ok := raw.([]interface{})
+	if !ok || len(list) != 6 {
+		return nil, fmt.Errorf("'%s' must be a list of 6 numbers", key)
+	}
+	values := make([]float64, 0, len(list))
+	for _, v := range list {
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("'%s' must be a list of 6 numbers", key)
+		}
+		values = append(values, f)
+	}
+	return values, nil
+}
+
+// axesArg returns which of the x, y, z, rx, ry and rz axes a DoCommand makes compliant, all of them by default.
+func axesArg(cmd map[string]interface{}) ([]bool, error) {
+	raw, ok := cmd["axes"]
+	if !ok {
+		return defaultCompliantAxes, nil
+	}
+	list, ok := raw.([]interface{})
+	if !ok || len(list) != 6 {
+		return nil, errors.New("'axes' must be a list of 6 booleans")
+	}
+	axes := make([]bool, 0, len(list))
+	for _, v := range list {
+		b, ok := v.(bool)
+		if !ok {
+			return nil, errors.New("'axes' must be a list of 6 booleans")
+		}
+		axes = append(axes, b)
+	}
+	return axes, nil
+}
+
+// toolFrameArg returns whether a DoCommand gives its axes in the tool frame rather than the base frame.
+func toolFrameArg(cmd map[string]interface{}) (bool, error) {
+	switch cmd["frame"] {
+	case nil, "base":
+		return false, nil
+	case "tool":
+		return true, nil
+	default:
+		return false, errors.New("'frame' must be either \"base\" or \"tool\"")
+	}
+}
+
+// DoCommand supports "get_force_torque", which returns the readings of the force-torque sensor,
+// "zero_force_torque_sensor", "set_impedance" and "set_force_control", which hand the sensor to the control box's
+// compliant modes, and "disable_force_control".
+func (x *xArm) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case "get_force_torque":
+		return x.forceTorqueReadings(ctx)
+	case "zero_force_torque_sensor":
+		return map[string]interface{}{}, x.zeroForceTorqueSensor(ctx)
+	case "set_impedance":
+		toolFrame, err := toolFrameArg(cmd)
+		if err != nil {
+			return nil, err
+		}
+		axes, err := axesArg(cmd)
+		if err != nil {
+			return nil, err
+		}
+		mass, err := floatsArg(cmd, "mass", defaultImpedanceMass)
+		if err != nil {
+			return nil, err
+		}
+		stiffness, err := floatsArg(cmd, "stiffness", defaultImpedanceStiffness)
+		if err != nil {
+			return nil, err
+		}
+		damping, err := floatsArg(cmd, "damping", defaultImpedanceDamping)
+		if err != nil {
+			return nil, err
+		}
+		if err := x.setImpedance(ctx, toolFrame, axes, mass, stiffness, damping); err != nil {
+			return nil, err
+		}
+	case "set_force_control":
+		toolFrame, err := toolFrameArg(cmd)
+		if err != nil {
+			return nil, err
+		}
+		axes, err := axesArg(cmd)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := cmd["target_force"]; !ok {
+			return nil, errors.New("set_force_control requires a 'target_force' value")
+		}
+		targetForce, err := floatsArg(cmd, "target_force", nil)
+		if err != nil {
+			return nil, err
+		}
+		limits, err := floatsArg(cmd, "limits", make([]float64, 6))
+		if err != nil {
+			return nil, err
+		}
+		if err := x.setForceControl(ctx, toolFrame, axes, targetForce, limits); err != nil {
+			return nil, err
+		}
+	case "disable_force_control":
+		if err := x.setForceMode(ctx, forceModeNone); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return map[string]interface{}{"force_mode": forceModeNames[x.forceMode]}, nil
+}
+
+var forceTorqueModel = resource.DefaultModelFamily.WithModel("xArm-force-torque")
+
+// ForceTorqueConfig is used for converting the attributes of a force-torque sensor.
+type ForceTorqueConfig struct {
+	Arm string `json:"arm"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *ForceTorqueConfig) Validate(path string) ([]string, error) {
+	if cfg.Arm == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "arm")
+	}
+	return []string{cfg.Arm}, nil
+}
+
+func init() {
+	resource.RegisterComponent(sensor.API, forceTorqueModel, resource.Registration[sensor.Sensor, *ForceTorqueConfig]{
+		Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (sensor.Sensor, error) {
+			newConf, err := resource.NativeConfig[*ForceTorqueConfig](conf)
+			if err != nil {
+				return nil, err
+			}
+			a, err := arm.FromDependencies(deps, newConf.Arm)
+			if err != nil {
+				return nil, err
+			}
+			return &forceTorqueSensor{Named: conf.ResourceName().AsNamed(), arm: a}, nil
+		},
+	})
+}
+
+// forceTorqueSensor reports the readings of the force-torque sensor of an xArm. It asks the arm for them through
+// DoCommand, so the arm may be on a remote machine.
+type forceTorqueSensor struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+	arm arm.Arm
+}
+
+// Readings returns the forces in newtons as fx, fy and fz and the torques in newton meters as tx, ty and tz.
+func (s *forceTorqueSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return s.arm.DoCommand(ctx, map[string]interface{}{"command": "get_force_torque"})
+}
@@ -37,9 +37,10 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 }
 
 const (
-	defaultSpeed  = 20. // degrees per second
-	defaultPort   = "502"
-	defaultMoveHz = 100. // Don't change this
+	defaultSpeed        = 20. // degrees per second
+	defaultAcceleration = 50. // degrees per second per second
+	defaultPort         = "502"
+	defaultMoveHz       = 100. // Don't change this
 )
 
 type xArm struct {
@@ -56,6 +57,14 @@ type xArm struct {
 	mu    sync.RWMutex
 	conn  net.Conn
 	speed float32 // speed=max joint radians per second
+	// acceleration is the max joint radians per second per second of moves planned by the control box
+	acceleration float32
+	// forceMode is the application the control box runs the force-torque sensor for
+	forceMode byte
+
+	velocityMu       sync.Mutex
+	velocityMode     bool
+	velocityWatchdog *arm.VelocityWatchdog
 }
 
 //go:embed xarm6_kinematics.json
@@ -148,6 +157,14 @@ func (x *xArm) Reconfigure(ctx context.Context, deps resource.Dependencies, conf
 		return fmt.Errorf("given speed %f cannot be negative", speed)
 	}
 
+	acceleration := newConf.Acceleration
+	if acceleration == 0 {
+		acceleration = defaultAcceleration
+	}
+	if acceleration < 0 {
+		return fmt.Errorf("given acceleration %f cannot be negative", acceleration)
+	}
+
 	port := fmt.Sprintf("%d", newConf.Port)
 	if newConf.Port == 0 {
 		port = defaultPort
@@ -177,6 +194,7 @@ func (x *xArm) Reconfigure(ctx context.Context, deps resource.Dependencies, conf
 	}
 
 	x.speed = float32(utils.DegToRad(float64(speed)))
+	x.acceleration = float32(utils.DegToRad(float64(acceleration)))
 	return nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"math"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 
 	"go.viam.com/rdk/components/arm"
 	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/operation"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/spatialmath"
 	rutils "go.viam.com/rdk/utils"
@@ -105,6 +107,17 @@ var regMap = map[string]byte{
 	"EnableBound": 0x34,
 	"SetEEModel":  0x4E,
 	"ServoError":  0x6A,
+
+	"SetJointVelocity": 0x51,
+	"MoveJointsBlend":  0x2B,
+
+	"GetFTData":       0xC8,
+	"FTSensorEnable":  0xC9,
+	"FTSensorSetApp":  0xCA,
+	"FTSensorSetZero": 0xCE,
+	"ForceCtrlConfig": 0xD1,
+	"ImpedanceMBK":    0xD2,
+	"ImpedanceConfig": 0xD3,
 }
 
 type cmd struct {
@@ -258,10 +271,11 @@ func (x *xArm) setMotionState(ctx context.Context, state byte) error {
 }
 
 // setMotionMode sets the motion mode of the arm.
-// 0: Position Control Mode, i.e. "normal" mode
+// 0: Position Control Mode, i.e. "normal" mode. Moves are planned by the control box, which can blend them.
 // 1: Servoj mode. This mode will immediately execute joint positions at the fastest available speed and is intended
 // for streaming large numbers of joint positions to the arm.
 // 2: Joint teaching mode, not useful right now
+// 4: Joint velocity mode, used to stream joint velocities.
 func (x *xArm) setMotionMode(ctx context.Context, state byte) error {
 	c := x.newCmd(regMap["SetMode"])
 	c.params = append(c.params, state)
@@ -296,7 +310,21 @@ func (x *xArm) toggleBrake(ctx context.Context, disable bool) error {
 	return err
 }
 
+// endVelocityMode makes sure a velocity stream no longer halts the arm once another mode replaces joint velocity
+// mode.
+func (x *xArm) endVelocityMode() {
+	x.velocityMu.Lock()
+	if x.velocityWatchdog != nil {
+		x.velocityWatchdog.Disarm()
+	}
+	x.velocityMode = false
+	x.velocityMu.Unlock()
+}
+
 func (x *xArm) start(ctx context.Context) error {
+	// servoj mode replaces joint velocity mode
+	x.endVelocityMode()
+
 	err := x.toggleServos(ctx, true)
 	if err != nil {
 		return err
@@ -330,6 +358,19 @@ func (x *xArm) motionStopped(ctx context.Context) (bool, error) {
 
 // Close shuts down the arm servos and engages brakes.
 func (x *xArm) Close(ctx context.Context) error {
+	x.velocityMu.Lock()
+	if x.velocityWatchdog != nil {
+		x.velocityWatchdog.Close()
+	}
+	x.velocityMu.Unlock()
+	x.mu.RLock()
+	forceMode := x.forceMode
+	x.mu.RUnlock()
+	if forceMode != forceModeNone {
+		if err := x.setForceMode(ctx, forceModeNone); err != nil {
+			return err
+		}
+	}
 	if err := x.toggleBrake(ctx, false); err != nil {
 		return err
 	}
@@ -370,6 +411,9 @@ func (x *xArm) MoveToJointPositions(ctx context.Context, newPositions *pb.JointP
 	x.mu.RLock()
 	nSteps := int((diff / float64(x.speed)) * x.moveHZ)
 	x.mu.RUnlock()
+	// every step takes a period of the move rate
+	estimate := time.Duration(float64(nSteps) / x.moveHZ * float64(time.Second))
+	defer operation.TrackProgress(ctx, operation.EstimatedProgress(time.Now(), estimate))()
 
 	// convenience for structuring and sending individual joint steps
 	sendMoveJointsCmd := func(ctx context.Context, step []float64) error {
@@ -422,6 +466,75 @@ func (x *xArm) MoveToJointPositions(ctx context.Context, newPositions *pb.JointP
 	return nil
 }
 
+// MoveThroughJointPositions moves the arm through the joint positions. With a blend radius the control box plans the
+// moves in position control mode and joins them with arcs, otherwise the arm stops at every position.
+func (x *xArm) MoveThroughJointPositions(
+	ctx context.Context, positions []*pb.JointPositions, options *arm.MoveOptions, extra map[string]interface{},
+) error {
+	if options == nil || options.BlendRadiusMM == 0 || len(positions) < 2 {
+		for _, joints := range positions {
+			if err := x.MoveToJointPositions(ctx, joints, extra); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, joints := range positions {
+		// check that joint positions are not out of bounds
+		if err := arm.CheckDesiredJointPositions(ctx, x, x.model.InputFromProtobuf(joints)); err != nil {
+			return err
+		}
+	}
+
+	ctx, done := x.opMgr.New(ctx)
+	defer done()
+	x.endVelocityMode()
+	// servoj mode has to be restored for the next move, whether or not this one finishes
+	x.started = false
+	if err := x.setMotionMode(ctx, 0); err != nil {
+		return err
+	}
+	if err := x.setMotionState(ctx, 0); err != nil {
+		return err
+	}
+
+	x.mu.RLock()
+	speed, acceleration := x.speed, x.acceleration
+	x.mu.RUnlock()
+	for i, joints := range positions {
+		radius := options.BlendRadiusMM
+		if i == len(positions)-1 {
+			// the arm has to stop at the last position
+			radius = 0
+		}
+		c := x.newCmd(regMap["MoveJointsBlend"])
+		c.params = appendFloat32s(c.params, referenceframe.JointPositionsToRadians(joints))
+		// xarm 6 has 6 joints, but protocol needs 7- add 4 bytes for a blank 7th joint
+		for dof := x.dof; dof < 7; dof++ {
+			c.params = append(c.params, 0, 0, 0, 0)
+		}
+		c.params = appendFloat32s(c.params, []float64{float64(speed), float64(acceleration), radius})
+		if _, err := x.send(ctx, c, true); err != nil {
+			return err
+		}
+	}
+
+	final := x.model.InputFromProtobuf(positions[len(positions)-1])
+	if err := x.opMgr.WaitForSuccess(ctx, time.Millisecond*50, func(ctx context.Context) (bool, error) {
+		current, err := x.CurrentInputs(ctx)
+		if err != nil {
+			return false, err
+		}
+		if getMaxDiff(current, final) > 1e-2 {
+			return false, nil
+		}
+		return x.motionStopped(ctx)
+	}); err != nil {
+		return err
+	}
+	return x.start(ctx)
+}
+
 // EndPosition computes and returns the current cartesian position.
 func (x *xArm) EndPosition(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
 	joints, err := x.JointPositions(ctx, extra)
@@ -478,6 +591,72 @@ func (x *xArm) Stop(ctx context.Context, extra map[string]interface{}) error {
 	return x.start(ctx)
 }
 
+// SetJointVelocities switches the arm to joint velocity mode and sets the velocity of every joint. The arm is stopped
+// if the next command does not arrive within arm.DefaultVelocityStreamTimeout.
+func (x *xArm) SetJointVelocities(ctx context.Context, velocitiesDegsPerSec []float64, extra map[string]interface{}) error {
+	if len(velocitiesDegsPerSec) != x.dof {
+		return fmt.Errorf("xArm has %d joints but %d joint velocities were given", x.dof, len(velocitiesDegsPerSec))
+	}
+	x.mu.RLock()
+	maxSpeed := float64(x.speed)
+	x.mu.RUnlock()
+	for i, v := range velocitiesDegsPerSec {
+		if math.Abs(rutils.DegToRad(v)) > maxSpeed {
+			return fmt.Errorf("velocity %.2f of joint %d exceeds the configured limit of %.2f degrees per second",
+				v, i, rutils.RadToDeg(maxSpeed))
+		}
+	}
+	x.velocityMu.Lock()
+	defer x.velocityMu.Unlock()
+	if x.velocityWatchdog == nil {
+		x.velocityWatchdog = arm.NewVelocityWatchdog(arm.DefaultVelocityStreamTimeout, x.haltVelocityStream)
+	}
+	if !x.velocityMode {
+		// a velocity stream takes over from any move in progress
+		x.opMgr.CancelRunning(ctx)
+		if err := x.setMotionMode(ctx, 4); err != nil {
+			return err
+		}
+		if err := x.setMotionState(ctx, 0); err != nil {
+			return err
+		}
+		x.velocityMode = true
+		// moves must put the arm back into servoj mode first
+		x.started = false
+	}
+
+	c := x.newCmd(regMap["SetJointVelocity"])
+	jFloatBytes := make([]byte, 4)
+	for _, v := range velocitiesDegsPerSec {
+		binary.LittleEndian.PutUint32(jFloatBytes, math.Float32bits(float32(rutils.DegToRad(v))))
+		c.params = append(c.params, jFloatBytes...)
+	}
+	for dof := x.dof; dof < 7; dof++ {
+		c.params = append(c.params, 0, 0, 0, 0)
+	}
+	// joints are not synchronized, each reaches its own velocity as fast as it can
+	c.params = append(c.params, 0)
+	// the control box also zeroes the velocities once this duration passes without a new command, which covers
+	// the case where this process is what stalled
+	binary.LittleEndian.PutUint32(jFloatBytes, math.Float32bits(float32(x.velocityWatchdog.Timeout().Seconds())))
+	c.params = append(c.params, jFloatBytes...)
+	if _, err := x.send(ctx, c, true); err != nil {
+		return err
+	}
+	x.velocityWatchdog.Feed()
+	return nil
+}
+
+// haltVelocityStream stops the arm after a joint velocity stream stalled.
+func (x *xArm) haltVelocityStream() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	x.logger.CWarn(ctx, "joint velocity stream stalled, stopping the arm")
+	if err := x.Stop(ctx, nil); err != nil {
+		x.logger.CErrorw(ctx, "failed to stop the arm after its joint velocity stream stalled", "error", err)
+	}
+}
+
 // IsMoving returns whether the arm is moving.
 func (x *xArm) IsMoving(ctx context.Context) (bool, error) {
 	return x.opMgr.OpRunning(), nil
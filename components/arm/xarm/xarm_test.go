@@ -1,20 +1,30 @@
 package xarm
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
+	"io"
 	"net"
 	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/golang/geo/r3"
 	pb "go.viam.com/api/common/v1"
+	armpb "go.viam.com/api/component/arm/v1"
 	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
 
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/sensor"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/operation"
 	frame "go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
 	spatial "go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
 	"go.viam.com/rdk/utils"
 )
 
@@ -203,3 +213,212 @@ func TestReconfigure(t *testing.T) {
 	test.That(t, currentConn, test.ShouldNotEqual, conn1)
 	test.That(t, xArm.speed, test.ShouldEqual, float32(utils.DegToRad(float64(confNotReconnect.Speed))))
 }
+
+// fakeControlBox records the commands sent to it and acknowledges each of them with a clear state, followed by the
+// reply data set for the register if any.
+type fakeControlBox struct {
+	mu      sync.Mutex
+	cmds    []cmd
+	replies map[byte][]byte
+}
+
+func (f *fakeControlBox) serve(conn net.Conn) {
+	for {
+		header := make([]byte, 7)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		c := cmd{reg: header[6], params: make([]byte, binary.BigEndian.Uint16(header[4:6])-1)}
+		if _, err := io.ReadFull(conn, c.params); err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.cmds = append(f.cmds, c)
+		reply := cmd{tid: binary.BigEndian.Uint16(header[0:2]), prot: 2, reg: c.reg, params: append([]byte{0}, f.replies[c.reg]...)}
+		f.mu.Unlock()
+		if _, err := conn.Write(reply.bytes()); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeControlBox) registers() []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var regs []byte
+	for _, c := range f.cmds {
+		regs = append(regs, c.reg)
+	}
+	return regs
+}
+
+func (f *fakeControlBox) last() cmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.cmds[len(f.cmds)-1]
+}
+
+func TestSetJointVelocities(t *testing.T) {
+	conn, boxConn := net.Pipe()
+	defer boxConn.Close()
+	box := &fakeControlBox{}
+	go box.serve(boxConn)
+
+	x := &xArm{
+		dof:    6,
+		conn:   conn,
+		speed:  float32(utils.DegToRad(30)),
+		opMgr:  operation.NewSingleOperationManager(),
+		logger: logging.NewTestLogger(t),
+	}
+	ctx := context.Background()
+
+	err := x.SetJointVelocities(ctx, []float64{1, 2, 3}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "6 joints")
+	err = x.SetJointVelocities(ctx, []float64{45, 0, 0, 0, 0, 0}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "exceeds")
+
+	test.That(t, x.SetJointVelocities(ctx, []float64{10, -20, 0, 0, 0, 0}, nil), test.ShouldBeNil)
+	test.That(t, x.SetJointVelocities(ctx, []float64{15, -20, 0, 0, 0, 0}, nil), test.ShouldBeNil)
+	// the mode is switched to joint velocity mode only once
+	test.That(t, box.registers(), test.ShouldResemble, []byte{
+		regMap["SetMode"], regMap["SetState"], regMap["SetJointVelocity"], regMap["SetJointVelocity"],
+	})
+	last := box.last()
+	test.That(t, len(last.params), test.ShouldEqual, 7*4+1+4)
+	test.That(t, float64(utils.Float32FromBytesLE(last.params[0:4])), test.ShouldAlmostEqual, utils.DegToRad(15), 1e-6)
+	test.That(t, float64(utils.Float32FromBytesLE(last.params[4:8])), test.ShouldAlmostEqual, utils.DegToRad(-20), 1e-6)
+	test.That(t, float64(utils.Float32FromBytesLE(last.params[29:33])), test.ShouldAlmostEqual,
+		arm.DefaultVelocityStreamTimeout.Seconds(), 1e-6)
+
+	// once the stream stalls the arm is stopped and put back into servoj mode
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, box.last().reg, test.ShouldEqual, regMap["SetState"])
+		test.That(tb, len(box.registers()), test.ShouldEqual, 8)
+	})
+	test.That(t, box.registers()[4:], test.ShouldResemble, []byte{
+		regMap["SetState"], regMap["ToggleServo"], regMap["SetMode"], regMap["SetState"],
+	})
+	x.velocityMu.Lock()
+	test.That(t, x.velocityMode, test.ShouldBeFalse)
+	x.velocityMu.Unlock()
+}
+
+func TestForceTorque(t *testing.T) {
+	conn, boxConn := net.Pipe()
+	defer boxConn.Close()
+	var ftData []byte
+	for _, v := range []float64{1.5, -2, 10, 0.25, 0, -0.5} {
+		ftData = appendFloat32s(ftData, []float64{v})
+	}
+	box := &fakeControlBox{replies: map[byte][]byte{regMap["GetFTData"]: ftData}}
+	go box.serve(boxConn)
+
+	x := &xArm{
+		dof:    6,
+		conn:   conn,
+		opMgr:  operation.NewSingleOperationManager(),
+		logger: logging.NewTestLogger(t),
+	}
+	ctx := context.Background()
+
+	readings, err := x.DoCommand(ctx, map[string]interface{}{"command": "get_force_torque"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings, test.ShouldResemble, map[string]interface{}{
+		"fx": 1.5, "fy": -2.0, "fz": 10.0, "tx": 0.25, "ty": 0.0, "tz": -0.5,
+	})
+
+	_, err = x.DoCommand(ctx, map[string]interface{}{"command": "set_impedance", "stiffness": []interface{}{1.0, 2.0}})
+	test.That(t, err, test.ShouldBeError, "'stiffness' must be a list of 6 numbers")
+	_, err = x.DoCommand(ctx, map[string]interface{}{"command": "set_force_control"})
+	test.That(t, err, test.ShouldBeError, "set_force_control requires a 'target_force' value")
+
+	resp, err := x.DoCommand(ctx, map[string]interface{}{
+		"command":   "set_impedance",
+		"frame":     "tool",
+		"axes":      []interface{}{false, false, true, false, false, false},
+		"stiffness": []interface{}{100.0, 100.0, 50.0, 1.0, 1.0, 1.0},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp, test.ShouldResemble, map[string]interface{}{"force_mode": "impedance"})
+	test.That(t, box.registers()[1:], test.ShouldResemble, []byte{
+		regMap["ImpedanceMBK"], regMap["ImpedanceConfig"], regMap["FTSensorEnable"], regMap["FTSensorSetApp"], regMap["SetState"],
+	})
+	box.mu.Lock()
+	mbk := box.cmds[1].params
+	impedanceConfig := box.cmds[2].params
+	box.mu.Unlock()
+	test.That(t, len(mbk), test.ShouldEqual, 18*4)
+	test.That(t, float64(utils.Float32FromBytesLE(mbk[6*4+2*4:6*4+3*4])), test.ShouldEqual, 50)
+	test.That(t, impedanceConfig, test.ShouldResemble, []byte{1, 0, 0, 1, 0, 0, 0})
+
+	resp, err = x.DoCommand(ctx, map[string]interface{}{"command": "disable_force_control"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp, test.ShouldResemble, map[string]interface{}{"force_mode": "none"})
+	test.That(t, box.last().reg, test.ShouldEqual, regMap["SetState"])
+
+	// the sensor model reads through the arm's DoCommand
+	a := inject.NewArm("arm1")
+	a.DoFunc = x.DoCommand
+	s := &forceTorqueSensor{Named: resource.NewName(sensor.API, "ft").AsNamed(), arm: a}
+	readings, err = s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["fz"], test.ShouldEqual, 10.0)
+}
+
+func TestMoveThroughJointPositions(t *testing.T) {
+	conn, boxConn := net.Pipe()
+	defer boxConn.Close()
+	final := []float64{0, -10, 15, 0, 30, 0}
+	box := &fakeControlBox{replies: map[byte][]byte{
+		regMap["JointPos"]: appendFloat32s(nil, append(frame.JointPositionsToRadians(&armpb.JointPositions{Values: final}), 0)),
+		regMap["GetState"]: {2},
+	}}
+	go box.serve(boxConn)
+
+	model, err := MakeModelFrame("xArm6", ModelName6DOF)
+	test.That(t, err, test.ShouldBeNil)
+	x := &xArm{
+		dof:          6,
+		conn:         conn,
+		model:        model,
+		speed:        float32(utils.DegToRad(30)),
+		acceleration: float32(utils.DegToRad(100)),
+		opMgr:        operation.NewSingleOperationManager(),
+		logger:       logging.NewTestLogger(t),
+	}
+
+	err = x.MoveThroughJointPositions(context.Background(), []*armpb.JointPositions{
+		{Values: []float64{0, -10, 0, 0, 30, 0}},
+		{Values: final},
+	}, &arm.MoveOptions{BlendRadiusMM: 20}, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	box.mu.Lock()
+	var blended []cmd
+	for _, c := range box.cmds {
+		if c.reg == regMap["MoveJointsBlend"] {
+			blended = append(blended, c)
+		}
+	}
+	box.mu.Unlock()
+	test.That(t, blended, test.ShouldHaveLength, 2)
+	test.That(t, len(blended[0].params), test.ShouldEqual, 10*4)
+	test.That(t, float64(utils.Float32FromBytesLE(blended[0].params[2*4:3*4])), test.ShouldEqual, 0)
+	test.That(t, float64(utils.Float32FromBytesLE(blended[0].params[9*4:10*4])), test.ShouldEqual, 20)
+	test.That(t, float64(utils.Float32FromBytesLE(blended[1].params[2*4:3*4])), test.ShouldAlmostEqual, utils.DegToRad(15), 1e-6)
+	// the arm stops at the last position
+	test.That(t, float64(utils.Float32FromBytesLE(blended[1].params[9*4:10*4])), test.ShouldEqual, 0)
+
+	// the moves are planned in position control mode and the arm is put back into servoj mode afterwards
+	regs := box.registers()
+	first := bytes.IndexByte(regs, regMap["MoveJointsBlend"])
+	test.That(t, regs[first-2:first+2], test.ShouldResemble, []byte{
+		regMap["SetMode"], regMap["SetState"], regMap["MoveJointsBlend"], regMap["MoveJointsBlend"],
+	})
+	test.That(t, regs[len(regs)-3:], test.ShouldResemble, []byte{regMap["ToggleServo"], regMap["SetMode"], regMap["SetState"]})
+	test.That(t, x.started, test.ShouldBeTrue)
+}
@@ -0,0 +1,388 @@
+// Package bumper implements a base which slows and stops another base as obstacles come close, using distance
+// sensors and depth cameras as a virtual bumper. It sits between clients and the base, so it also applies to raw
+// SetVelocity and SetPower commands from teleop.
+package bumper
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("virtual_bumper")
+
+const (
+	defaultPollFrequencyHz = 10.
+	facingForward          = "forward"
+	facingBackward         = "backward"
+)
+
+// ErrObstacle is returned when a command would drive the base toward an obstacle inside its stopping zone.
+var ErrObstacle = errors.New("obstacle too close to move toward")
+
+// A SpeedZone limits the speed of the base once the nearest obstacle is within a distance.
+type SpeedZone struct {
+	DistanceM float64 `json:"distance_m"`
+	// SpeedFraction is the fraction of the commanded speed kept inside the zone, from 0 to stop to 1.
+	SpeedFraction float64 `json:"speed_fraction"`
+}
+
+// Config is used for converting config attributes.
+type Config struct {
+	Base string `json:"base"`
+	// DistanceSensors are sensors whose "distance" reading is the distance to the nearest obstacle in meters.
+	DistanceSensors []string `json:"distance_sensors,omitempty"`
+	// DepthCameras are cameras whose nearest point cloud point is taken as the nearest obstacle.
+	DepthCameras []string    `json:"depth_cameras,omitempty"`
+	SpeedZones   []SpeedZone `json:"speed_zones"`
+	// Facing is "forward" or "backward", the direction of travel the sensors watch. Motion the other way and turning
+	// in place are never limited, so the base can always back away. Defaults to forward.
+	Facing          string  `json:"facing,omitempty"`
+	PollFrequencyHz float64 `json:"poll_frequency_hz,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if cfg.Base == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "base")
+	}
+	if len(cfg.DistanceSensors) == 0 && len(cfg.DepthCameras) == 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("need at least one distance sensor or depth camera"))
+	}
+	if len(cfg.SpeedZones) == 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "speed_zones")
+	}
+	for i, zone := range cfg.SpeedZones {
+		if zone.DistanceM <= 0 {
+			return nil, resource.NewConfigValidationError(fmt.Sprintf("%s.speed_zones.%d", path, i),
+				errors.New("distance_m must be positive"))
+		}
+		if zone.SpeedFraction < 0 || zone.SpeedFraction > 1 {
+			return nil, resource.NewConfigValidationError(fmt.Sprintf("%s.speed_zones.%d", path, i),
+				errors.New("speed_fraction must be between 0 and 1"))
+		}
+	}
+	switch cfg.Facing {
+	case "", facingForward, facingBackward:
+	default:
+		return nil, resource.NewConfigValidationError(path,
+			errors.Errorf("facing must be %q or %q, not %q", facingForward, facingBackward, cfg.Facing))
+	}
+	if cfg.PollFrequencyHz < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("poll_frequency_hz cannot be negative"))
+	}
+	deps := []string{cfg.Base}
+	deps = append(deps, cfg.DistanceSensors...)
+	deps = append(deps, cfg.DepthCameras...)
+	return deps, nil
+}
+
+func init() {
+	resource.RegisterComponent(base.API, model, resource.Registration[base.Base, *Config]{
+		Constructor: NewBumperBase,
+	})
+}
+
+// commandMode is the kind of command the base is running.
+type commandMode int
+
+const (
+	commandNone commandMode = iota
+	commandVelocity
+	commandPower
+	commandPosition
+)
+
+type bumperBase struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger  logging.Logger
+	workers utils.StoppableWorkers
+
+	actual  base.Base
+	sensors map[string]sensor.Sensor
+	cameras map[string]camera.Camera
+	zones   []SpeedZone
+	forward float64
+
+	mu       sync.Mutex
+	nearestM float64
+	fraction float64
+	// the command clients last sent, which is scaled again whenever the speed fraction changes
+	mode            commandMode
+	linear, angular r3.Vector
+	extra           map[string]interface{}
+	// stopped says the base was stopped short of a MoveStraight because an obstacle came too close
+	stopped bool
+}
+
+// NewBumperBase returns a base which limits the speed of another base near obstacles.
+func NewBumperBase(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (base.Base, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	actual, err := base.FromDependencies(deps, newConf.Base)
+	if err != nil {
+		return nil, err
+	}
+	b := &bumperBase{
+		Named:   conf.ResourceName().AsNamed(),
+		logger:  logger,
+		actual:  actual,
+		sensors: map[string]sensor.Sensor{},
+		cameras: map[string]camera.Camera{},
+		zones:   append([]SpeedZone(nil), newConf.SpeedZones...),
+		forward: 1,
+	}
+	for _, name := range newConf.DistanceSensors {
+		if b.sensors[name], err = sensor.FromDependencies(deps, name); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range newConf.DepthCameras {
+		if b.cameras[name], err = camera.FromDependencies(deps, name); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(b.zones, func(i, j int) bool { return b.zones[i].DistanceM < b.zones[j].DistanceM })
+	if newConf.Facing == facingBackward {
+		b.forward = -1
+	}
+	pollFrequencyHz := newConf.PollFrequencyHz
+	if pollFrequencyHz == 0 {
+		pollFrequencyHz = defaultPollFrequencyHz
+	}
+
+	// the base is limited from the start, before the first poll
+	b.nearestM, b.fraction = b.measure(ctx)
+	period := time.Duration(float64(time.Second) / pollFrequencyHz)
+	b.workers = utils.NewStoppableWorkers(func(ctx context.Context) {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			b.update(ctx)
+		}
+	})
+	return b, nil
+}
+
+// measure returns the distance to the nearest obstacle and the speed fraction it allows. An obstacle is taken to be
+// touching the base when a sensor or camera cannot be read, so a failed sensor stops the base rather than letting it
+// drive blind.
+func (b *bumperBase) measure(ctx context.Context) (float64, float64) {
+	nearest := math.Inf(1)
+	for name, s := range b.sensors {
+		distance, err := sensorDistance(ctx, s)
+		if err != nil {
+			b.logger.CWarnw(ctx, "cannot read distance sensor, stopping the base", "sensor", name, "error", err)
+			return 0, b.zoneFraction(0)
+		}
+		nearest = math.Min(nearest, distance)
+	}
+	for name, cam := range b.cameras {
+		distance, err := cameraDistance(ctx, cam)
+		if err != nil {
+			b.logger.CWarnw(ctx, "cannot read depth camera, stopping the base", "camera", name, "error", err)
+			return 0, b.zoneFraction(0)
+		}
+		nearest = math.Min(nearest, distance)
+	}
+	return nearest, b.zoneFraction(nearest)
+}
+
+// zoneFraction returns the speed fraction of the innermost zone the distance is in.
+func (b *bumperBase) zoneFraction(distanceM float64) float64 {
+	for _, zone := range b.zones {
+		if distanceM <= zone.DistanceM {
+			return zone.SpeedFraction
+		}
+	}
+	return 1
+}
+
+// sensorDistance returns the "distance" reading of a sensor in meters.
+func sensorDistance(ctx context.Context, s sensor.Sensor) (float64, error) {
+	readings, err := s.Readings(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	distance, ok := readings["distance"].(float64)
+	if !ok {
+		return 0, errors.Errorf("no distance reading in %v", readings)
+	}
+	return distance, nil
+}
+
+// cameraDistance returns the distance in meters from a depth camera to the nearest point it sees.
+func cameraDistance(ctx context.Context, cam camera.Camera) (float64, error) {
+	pc, err := cam.NextPointCloud(ctx)
+	if err != nil {
+		return 0, err
+	}
+	nearestMM := math.Inf(1)
+	pc.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		// points at the origin are pixels without a depth
+		if norm := p.Norm(); norm > 0 && norm < nearestMM {
+			nearestMM = norm
+		}
+		return true
+	})
+	return nearestMM / 1000, nil
+}
+
+// update measures the nearest obstacle and applies its speed fraction to the running command.
+func (b *bumperBase) update(ctx context.Context) {
+	nearest, fraction := b.measure(ctx)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nearestM = nearest
+	if fraction == b.fraction {
+		return
+	}
+	b.logger.CDebugw(ctx, "speed limit changed", "nearest_m", nearest, "speed_fraction", fraction)
+	b.fraction = fraction
+
+	var err error
+	switch b.mode {
+	case commandVelocity:
+		err = b.actual.SetVelocity(ctx, b.limited(b.linear), b.angular, b.extra)
+	case commandPower:
+		err = b.actual.SetPower(ctx, b.limited(b.linear), b.angular, b.extra)
+	case commandPosition:
+		// a MoveStraight cannot change speed once started, so it only stops
+		if fraction == 0 && b.towardObstacle(b.linear.Y) {
+			b.stopped = true
+			err = b.actual.Stop(ctx, nil)
+		}
+	case commandNone:
+	}
+	if err != nil {
+		b.logger.CErrorw(ctx, "cannot limit the speed of the base", "error", err)
+	}
+}
+
+// towardObstacle says whether motion with the given forward component heads toward the watched side.
+func (b *bumperBase) towardObstacle(forward float64) bool {
+	return forward*b.forward > 0
+}
+
+// limited scales the forward component of a linear command by the speed fraction when it heads toward obstacles.
+func (b *bumperBase) limited(linear r3.Vector) r3.Vector {
+	if b.towardObstacle(linear.Y) {
+		linear.Y *= b.fraction
+	}
+	return linear
+}
+
+func (b *bumperBase) MoveStraight(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+	forward := float64(distanceMm) * mmPerSec
+	b.mu.Lock()
+	if b.towardObstacle(forward) {
+		if b.fraction == 0 {
+			b.mu.Unlock()
+			return ErrObstacle
+		}
+		mmPerSec *= b.fraction
+	}
+	b.mode = commandPosition
+	b.linear = r3.Vector{Y: forward}
+	b.angular = r3.Vector{}
+	b.stopped = false
+	b.mu.Unlock()
+
+	err := b.actual.MoveStraight(ctx, distanceMm, mmPerSec, extra)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stopped := b.stopped
+	if b.mode == commandPosition {
+		b.mode = commandNone
+	}
+	if stopped {
+		return ErrObstacle
+	}
+	return err
+}
+
+func (b *bumperBase) Spin(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
+	b.mu.Lock()
+	b.mode = commandNone
+	b.mu.Unlock()
+	return b.actual.Spin(ctx, angleDeg, degsPerSec, extra)
+}
+
+func (b *bumperBase) SetPower(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.mode, b.linear, b.angular, b.extra = commandPower, linear, angular, extra
+	return b.actual.SetPower(ctx, b.limited(linear), angular, extra)
+}
+
+func (b *bumperBase) SetVelocity(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.mode, b.linear, b.angular, b.extra = commandVelocity, linear, angular, extra
+	return b.actual.SetVelocity(ctx, b.limited(linear), angular, extra)
+}
+
+func (b *bumperBase) Stop(ctx context.Context, extra map[string]interface{}) error {
+	b.mu.Lock()
+	b.mode = commandNone
+	b.mu.Unlock()
+	return b.actual.Stop(ctx, extra)
+}
+
+func (b *bumperBase) IsMoving(ctx context.Context) (bool, error) {
+	return b.actual.IsMoving(ctx)
+}
+
+func (b *bumperBase) Properties(ctx context.Context, extra map[string]interface{}) (base.Properties, error) {
+	return b.actual.Properties(ctx, extra)
+}
+
+func (b *bumperBase) Geometries(ctx context.Context, extra map[string]interface{}) ([]spatialmath.Geometry, error) {
+	return b.actual.Geometries(ctx, extra)
+}
+
+// DoCommand returns the nearest obstacle and the speed fraction it allows for "bumper_status", passing any other
+// command on to the base.
+func (b *bumperBase) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if cmd["command"] != "bumper_status" {
+		return b.actual.DoCommand(ctx, cmd)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	resp := map[string]interface{}{"speed_fraction": b.fraction}
+	// JSON has no infinity, so the distance is left out when nothing is in sight
+	if !math.IsInf(b.nearestM, 1) {
+		resp["nearest_m"] = b.nearestM
+	}
+	return resp, nil
+}
+
+func (b *bumperBase) Close(ctx context.Context) error {
+	b.workers.Stop()
+	return nil
+}
@@ -0,0 +1,154 @@
+package bumper
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestValidate(t *testing.T) {
+	zones := []SpeedZone{{DistanceM: 0.3}, {DistanceM: 1, SpeedFraction: 0.5}}
+	deps, err := (&Config{
+		Base: "base", DistanceSensors: []string{"sonar"}, DepthCameras: []string{"depth"}, SpeedZones: zones,
+	}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"base", "sonar", "depth"})
+
+	_, err = (&Config{DistanceSensors: []string{"sonar"}, SpeedZones: zones}).Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "base"))
+	_, err = (&Config{Base: "base", SpeedZones: zones}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{Base: "base", DistanceSensors: []string{"sonar"}}).Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "speed_zones"))
+	_, err = (&Config{
+		Base: "base", DistanceSensors: []string{"sonar"}, SpeedZones: []SpeedZone{{DistanceM: 1, SpeedFraction: 2}},
+	}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{Base: "base", DistanceSensors: []string{"sonar"}, SpeedZones: zones, Facing: "up"}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestBumperBase(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	var mu sync.Mutex
+	distance := 2.
+	var sensorErr error
+	var velocities []r3.Vector
+	var moveSpeeds []float64
+	stops := 0
+
+	sonar := inject.NewSensor("sonar")
+	sonar.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return map[string]interface{}{"distance": distance}, sensorErr
+	}
+	actual := inject.NewBase("actual")
+	actual.SetVelocityFunc = func(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		velocities = append(velocities, linear)
+		return nil
+	}
+	actual.MoveStraightFunc = func(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		moveSpeeds = append(moveSpeeds, mmPerSec)
+		return nil
+	}
+	actual.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		stops++
+		return nil
+	}
+	setDistance := func(d float64, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		distance = d
+		sensorErr = err
+	}
+	lastVelocity := func() r3.Vector {
+		mu.Lock()
+		defer mu.Unlock()
+		return velocities[len(velocities)-1]
+	}
+
+	deps := resource.Dependencies{
+		base.Named("actual"):  actual,
+		sensor.Named("sonar"): sonar,
+	}
+	conf := resource.Config{
+		Name: "bumper",
+		ConvertedAttributes: &Config{
+			Base:            "actual",
+			DistanceSensors: []string{"sonar"},
+			SpeedZones:      []SpeedZone{{DistanceM: 1, SpeedFraction: 0.5}, {DistanceM: 0.3}},
+			PollFrequencyHz: 100,
+		},
+	}
+	b, err := NewBumperBase(ctx, deps, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer b.Close(ctx)
+
+	// nothing near, so commands pass through
+	test.That(t, b.SetVelocity(ctx, r3.Vector{Y: 100}, r3.Vector{Z: 10}, nil), test.ShouldBeNil)
+	test.That(t, lastVelocity(), test.ShouldResemble, r3.Vector{Y: 100})
+
+	// a held velocity slows down as an obstacle comes into the outer zone
+	setDistance(0.5, nil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, lastVelocity(), test.ShouldResemble, r3.Vector{Y: 50})
+	})
+	status, err := b.DoCommand(ctx, map[string]interface{}{"command": "bumper_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status, test.ShouldResemble, map[string]interface{}{"nearest_m": 0.5, "speed_fraction": 0.5})
+
+	// and stops inside the inner zone, while backing away is never limited
+	setDistance(0.1, nil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, lastVelocity(), test.ShouldResemble, r3.Vector{Y: 0})
+	})
+	test.That(t, b.SetVelocity(ctx, r3.Vector{Y: -100}, r3.Vector{}, nil), test.ShouldBeNil)
+	test.That(t, lastVelocity(), test.ShouldResemble, r3.Vector{Y: -100})
+
+	test.That(t, b.MoveStraight(ctx, 100, 50, nil), test.ShouldBeError, ErrObstacle)
+	test.That(t, b.MoveStraight(ctx, -100, 50, nil), test.ShouldBeNil)
+
+	setDistance(0.5, nil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, b.MoveStraight(ctx, 100, 50, nil), test.ShouldBeNil)
+	})
+	mu.Lock()
+	test.That(t, moveSpeeds, test.ShouldResemble, []float64{50, 25})
+	mu.Unlock()
+
+	// a sensor which cannot be read stops the base
+	test.That(t, b.SetVelocity(ctx, r3.Vector{Y: 100}, r3.Vector{}, nil), test.ShouldBeNil)
+	setDistance(2, errors.New("no echo"))
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, lastVelocity(), test.ShouldResemble, r3.Vector{Y: 0})
+	})
+
+	test.That(t, b.Stop(ctx, nil), test.ShouldBeNil)
+	mu.Lock()
+	test.That(t, stops, test.ShouldEqual, 1)
+	mu.Unlock()
+}
@@ -0,0 +1,244 @@
+// Package contactguard implements a base which stops another base as soon as a contact sensor, such as the switches
+// of a bumper, is touched. While anything is touched the base may only move straight away from the contacts touched,
+// so it can back off what it ran into.
+package contactguard
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/contactsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("contact_guard")
+
+// ErrInContact is returned by commands which would move the base into, or along, what it is touching.
+var ErrInContact = errors.New("base is in contact")
+
+// Config is used for converting config attributes.
+type Config struct {
+	Base string `json:"base"`
+	// ContactSensors are the sensors whose contacts stop the base. The locations of their contacts are taken to be in
+	// the frame of the base, with +Y forward.
+	ContactSensors []string `json:"contact_sensors"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if cfg.Base == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "base")
+	}
+	if len(cfg.ContactSensors) == 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "contact_sensors")
+	}
+	return append([]string{cfg.Base}, cfg.ContactSensors...), nil
+}
+
+func init() {
+	resource.RegisterComponent(base.API, model, resource.Registration[base.Base, *Config]{
+		Constructor: NewContactGuard,
+	})
+}
+
+type contactGuard struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger  logging.Logger
+	workers utils.StoppableWorkers
+
+	actual base.Base
+
+	mu sync.Mutex
+	// touched are the contacts in contact, by sensor and contact name
+	touched map[string]contactsensor.Contact
+}
+
+// NewContactGuard returns a base which stops another base when any of its contact sensors is touched.
+func NewContactGuard(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (base.Base, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	actual, err := base.FromDependencies(deps, newConf.Base)
+	if err != nil {
+		return nil, err
+	}
+	g := &contactGuard{
+		Named:   conf.ResourceName().AsNamed(),
+		logger:  logger,
+		actual:  actual,
+		touched: map[string]contactsensor.Contact{},
+	}
+
+	sensors := make(map[string]contactsensor.ContactSensor, len(newConf.ContactSensors))
+	for _, name := range newConf.ContactSensors {
+		sensor, err := contactsensor.FromDependencies(deps, name)
+		if err != nil {
+			return nil, err
+		}
+		// the base is guarded from the start, before the first change of a contact
+		contacts, err := sensor.Contacts(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, contact := range contacts {
+			g.update(ctx, name, contact)
+		}
+		sensors[name] = sensor
+	}
+
+	workers := make([]func(context.Context), 0, len(sensors))
+	for name, sensor := range sensors {
+		name, sensor := name, sensor
+		workers = append(workers, func(ctx context.Context) {
+			events, err := sensor.Events(ctx, nil)
+			if err != nil {
+				g.logger.CErrorw(ctx, "cannot watch the contacts of a contact sensor", "sensor", name, "error", err)
+				return
+			}
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case contact, ok := <-events:
+					if !ok {
+						return
+					}
+					g.update(ctx, name, contact)
+				}
+			}
+		})
+	}
+	g.workers = utils.NewStoppableWorkers(workers...)
+	return g, nil
+}
+
+// update records the state of a contact, stopping the base when it is touched.
+func (g *contactGuard) update(ctx context.Context, sensor string, contact contactsensor.Contact) {
+	key := sensor + "/" + contact.Name
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !contact.InContact {
+		if _, ok := g.touched[key]; ok {
+			delete(g.touched, key)
+			g.logger.CInfow(ctx, "contact released", "contact", key)
+		}
+		return
+	}
+	if _, ok := g.touched[key]; !ok {
+		g.logger.CWarnw(ctx, "stopping the base on contact", "contact", key, "location", contact.Location)
+	}
+	g.touched[key] = contact
+	if err := g.actual.Stop(ctx, nil); err != nil {
+		g.logger.CErrorw(ctx, "cannot stop the base on contact", "error", err)
+	}
+}
+
+// guard returns an error unless the base may move with the given linear and angular direction, which it may while
+// nothing is touched, or when it moves straight away from every contact touched. A contact with no location blocks
+// all motion.
+func (g *contactGuard) guard(linear, angular r3.Vector) error {
+	if len(g.touched) == 0 || (linear.Norm() == 0 && angular.Norm() == 0) {
+		return nil
+	}
+	blocking := []string{}
+	for key, contact := range g.touched {
+		if angular.Norm() != 0 || contact.Location.Norm() == 0 || linear.Dot(contact.Location) >= 0 {
+			blocking = append(blocking, key)
+		}
+	}
+	if len(blocking) == 0 {
+		return nil
+	}
+	sort.Strings(blocking)
+	return errors.Wrapf(ErrInContact, "touching %s", strings.Join(blocking, ", "))
+}
+
+// MoveStraight may back the base away from what it touches, though it is stopped again by any new contact.
+func (g *contactGuard) MoveStraight(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+	direction := r3.Vector{Y: float64(distanceMm) * mmPerSec}
+	g.mu.Lock()
+	err := g.guard(direction, r3.Vector{})
+	g.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return g.actual.MoveStraight(ctx, distanceMm, mmPerSec, extra)
+}
+
+func (g *contactGuard) Spin(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
+	g.mu.Lock()
+	err := g.guard(r3.Vector{}, r3.Vector{Z: angleDeg * degsPerSec})
+	g.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return g.actual.Spin(ctx, angleDeg, degsPerSec, extra)
+}
+
+// SetPower holds the lock while setting the power, so no contact can be touched between the check and the command.
+func (g *contactGuard) SetPower(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.guard(linear, angular); err != nil {
+		return err
+	}
+	return g.actual.SetPower(ctx, linear, angular, extra)
+}
+
+// SetVelocity holds the lock while setting the velocity, so no contact can be touched between the check and the
+// command.
+func (g *contactGuard) SetVelocity(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.guard(linear, angular); err != nil {
+		return err
+	}
+	return g.actual.SetVelocity(ctx, linear, angular, extra)
+}
+
+func (g *contactGuard) Stop(ctx context.Context, extra map[string]interface{}) error {
+	return g.actual.Stop(ctx, extra)
+}
+
+func (g *contactGuard) IsMoving(ctx context.Context) (bool, error) {
+	return g.actual.IsMoving(ctx)
+}
+
+func (g *contactGuard) Properties(ctx context.Context, extra map[string]interface{}) (base.Properties, error) {
+	return g.actual.Properties(ctx, extra)
+}
+
+func (g *contactGuard) Geometries(ctx context.Context, extra map[string]interface{}) ([]spatialmath.Geometry, error) {
+	return g.actual.Geometries(ctx, extra)
+}
+
+// DoCommand returns the contacts touched for "contact_status", passing any other command on to the base.
+func (g *contactGuard) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if cmd["command"] != "contact_status" {
+		return g.actual.DoCommand(ctx, cmd)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	touched := make([]string, 0, len(g.touched))
+	for key := range g.touched {
+		touched = append(touched, key)
+	}
+	sort.Strings(touched)
+	return map[string]interface{}{"in_contact": len(touched) > 0, "touched": touched}, nil
+}
+
+func (g *contactGuard) Close(ctx context.Context) error {
+	g.workers.Stop()
+	return nil
+}
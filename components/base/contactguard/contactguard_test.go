@@ -0,0 +1,123 @@
+package contactguard
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/contactsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestValidate(t *testing.T) {
+	deps, err := (&Config{Base: "base", ContactSensors: []string{"bumper", "pads"}}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"base", "bumper", "pads"})
+
+	_, err = (&Config{ContactSensors: []string{"bumper"}}).Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "base"))
+	_, err = (&Config{Base: "base"}).Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "contact_sensors"))
+}
+
+func TestContactGuard(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	var mu sync.Mutex
+	stops := 0
+	stopCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return stops
+	}
+
+	front := contactsensor.Contact{Name: "front", Location: r3.Vector{Y: 150}}
+	side := contactsensor.Contact{Name: "side"}
+	events := make(chan contactsensor.Contact, 4)
+	bumper := inject.NewContactSensor("bumper")
+	bumper.ContactsFunc = func(ctx context.Context, extra map[string]interface{}) ([]contactsensor.Contact, error) {
+		return []contactsensor.Contact{front, side}, nil
+	}
+	bumper.EventsFunc = func(ctx context.Context, extra map[string]interface{}) (<-chan contactsensor.Contact, error) {
+		return events, nil
+	}
+	actual := inject.NewBase("actual")
+	actual.SetVelocityFunc = func(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+		return nil
+	}
+	actual.MoveStraightFunc = func(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+		return nil
+	}
+	actual.SpinFunc = func(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
+		return nil
+	}
+	actual.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		stops++
+		return nil
+	}
+
+	deps := resource.Dependencies{
+		base.Named("actual"):          actual,
+		contactsensor.Named("bumper"): bumper,
+	}
+	conf := resource.Config{
+		Name:                "guard",
+		ConvertedAttributes: &Config{Base: "actual", ContactSensors: []string{"bumper"}},
+	}
+	b, err := NewContactGuard(ctx, deps, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer b.Close(ctx)
+
+	test.That(t, b.SetVelocity(ctx, r3.Vector{Y: 100}, r3.Vector{}, nil), test.ShouldBeNil)
+
+	// touching the front stops the base, which may then only back straight away
+	front.InContact = true
+	events <- front
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, stopCount(), test.ShouldEqual, 1)
+	})
+	err = b.SetVelocity(ctx, r3.Vector{Y: 100}, r3.Vector{}, nil)
+	test.That(t, errors.Is(err, ErrInContact), test.ShouldBeTrue)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "bumper/front")
+	test.That(t, errors.Is(b.Spin(ctx, 90, 45, nil), ErrInContact), test.ShouldBeTrue)
+	test.That(t, errors.Is(b.MoveStraight(ctx, 100, 100, nil), ErrInContact), test.ShouldBeTrue)
+	test.That(t, b.MoveStraight(ctx, -100, 100, nil), test.ShouldBeNil)
+	test.That(t, b.SetVelocity(ctx, r3.Vector{Y: -100}, r3.Vector{}, nil), test.ShouldBeNil)
+	test.That(t, b.SetVelocity(ctx, r3.Vector{}, r3.Vector{}, nil), test.ShouldBeNil)
+
+	status, err := b.DoCommand(ctx, map[string]interface{}{"command": "contact_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["in_contact"], test.ShouldBeTrue)
+	test.That(t, status["touched"], test.ShouldResemble, []string{"bumper/front"})
+
+	// a contact with no location blocks all motion
+	side.InContact = true
+	events <- side
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, stopCount(), test.ShouldEqual, 2)
+	})
+	test.That(t, errors.Is(b.MoveStraight(ctx, -100, 100, nil), ErrInContact), test.ShouldBeTrue)
+
+	// releasing both lets the base move freely again
+	front.InContact, side.InContact = false, false
+	events <- front
+	events <- side
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, b.SetVelocity(ctx, r3.Vector{Y: 100}, r3.Vector{}, nil), test.ShouldBeNil)
+	})
+	test.That(t, b.Spin(ctx, 90, 45, nil), test.ShouldBeNil)
+}
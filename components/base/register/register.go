@@ -3,7 +3,10 @@ package register
 
 import (
 	// register bases.
+	_ "go.viam.com/rdk/components/base/bumper"
+	_ "go.viam.com/rdk/components/base/contactguard"
 	_ "go.viam.com/rdk/components/base/fake"
 	_ "go.viam.com/rdk/components/base/sensorcontrolled"
+	_ "go.viam.com/rdk/components/base/tiltguard"
 	_ "go.viam.com/rdk/components/base/wheeled"
 )
@@ -0,0 +1,294 @@
+// Package tiltguard implements a base which stops another base when a movement sensor finds it rolled or pitched
+// past configured limits, so it does not drive on until it tips over on a ramp or curb.
+package tiltguard
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("tilt_guard")
+
+const (
+	defaultMaxTiltDeg      = 20.
+	defaultHysteresisDeg   = 2.
+	defaultPollFrequencyHz = 20.
+)
+
+// ErrTilted is returned by commands to the base while it is tilted past its limits.
+var ErrTilted = errors.New("base is tilted past its limits")
+
+// Config is used for converting config attributes.
+type Config struct {
+	Base           string `json:"base"`
+	MovementSensor string `json:"movement_sensor"`
+	// MaxRollDeg and MaxPitchDeg are the tilts, either way, past which the base is stopped. Both default to 20
+	// degrees.
+	MaxRollDeg  float64 `json:"max_roll_deg,omitempty"`
+	MaxPitchDeg float64 `json:"max_pitch_deg,omitempty"`
+	// HysteresisDeg is how far below the limits the base must come back before it may move again, so it does not
+	// start and stop on the edge of a limit. Defaults to 2 degrees.
+	HysteresisDeg   float64 `json:"hysteresis_deg,omitempty"`
+	PollFrequencyHz float64 `json:"poll_frequency_hz,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if cfg.Base == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "base")
+	}
+	if cfg.MovementSensor == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "movement_sensor")
+	}
+	if cfg.MaxRollDeg < 0 || cfg.MaxRollDeg >= 90 || cfg.MaxPitchDeg < 0 || cfg.MaxPitchDeg >= 90 {
+		return nil, resource.NewConfigValidationError(path, errors.New("max_roll_deg and max_pitch_deg must be between 0 and 90"))
+	}
+	if cfg.HysteresisDeg < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("hysteresis_deg cannot be negative"))
+	}
+	if cfg.PollFrequencyHz < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("poll_frequency_hz cannot be negative"))
+	}
+	return []string{cfg.Base, cfg.MovementSensor}, nil
+}
+
+func init() {
+	resource.RegisterComponent(base.API, model, resource.Registration[base.Base, *Config]{
+		Constructor: NewTiltGuard,
+	})
+}
+
+type tiltGuard struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger  logging.Logger
+	workers utils.StoppableWorkers
+
+	actual        base.Base
+	sensor        movementsensor.MovementSensor
+	maxRoll       float64
+	maxPitch      float64
+	hysteresisDeg float64
+
+	mu          sync.Mutex
+	roll, pitch float64
+	tilted      bool
+	// reason says why the base is stopped, which is the tilt or a sensor which cannot be read
+	reason string
+}
+
+// NewTiltGuard returns a base which stops another base when it tilts past its limits.
+func NewTiltGuard(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (base.Base, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	actual, err := base.FromDependencies(deps, newConf.Base)
+	if err != nil {
+		return nil, err
+	}
+	ms, err := movementsensor.FromDependencies(deps, newConf.MovementSensor)
+	if err != nil {
+		return nil, err
+	}
+	props, err := ms.Properties(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !props.OrientationSupported {
+		return nil, errors.Errorf("movement sensor %q does not report orientation", newConf.MovementSensor)
+	}
+
+	g := &tiltGuard{
+		Named:         conf.ResourceName().AsNamed(),
+		logger:        logger,
+		actual:        actual,
+		sensor:        ms,
+		maxRoll:       newConf.MaxRollDeg,
+		maxPitch:      newConf.MaxPitchDeg,
+		hysteresisDeg: newConf.HysteresisDeg,
+	}
+	if g.maxRoll == 0 {
+		g.maxRoll = defaultMaxTiltDeg
+	}
+	if g.maxPitch == 0 {
+		g.maxPitch = defaultMaxTiltDeg
+	}
+	if g.hysteresisDeg == 0 {
+		g.hysteresisDeg = defaultHysteresisDeg
+	}
+	pollFrequencyHz := newConf.PollFrequencyHz
+	if pollFrequencyHz == 0 {
+		pollFrequencyHz = defaultPollFrequencyHz
+	}
+
+	// the base is guarded from the start, before the first poll
+	g.check(ctx)
+	period := time.Duration(float64(time.Second) / pollFrequencyHz)
+	g.workers = utils.NewStoppableWorkers(func(ctx context.Context) {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			g.check(ctx)
+		}
+	})
+	return g, nil
+}
+
+// check reads the tilt of the base, stopping the base and raising an alert when it goes past the limits. A sensor
+// which cannot be read stops the base too, since the tilt is then unknown.
+func (g *tiltGuard) check(ctx context.Context) {
+	var roll, pitch float64
+	reason := ""
+	orientation, err := g.sensor.Orientation(ctx, nil)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		reason = "cannot read orientation: " + err.Error()
+	} else {
+		angles := orientation.EulerAngles()
+		roll, pitch = utils.RadToDeg(angles.Roll), utils.RadToDeg(angles.Pitch)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if reason == "" {
+		g.roll, g.pitch = roll, pitch
+	}
+	margin := 0.
+	if g.tilted {
+		margin = g.hysteresisDeg
+	}
+	if reason == "" && (math.Abs(roll) > g.maxRoll-margin || math.Abs(pitch) > g.maxPitch-margin) {
+		reason = "tilted past its limits"
+	}
+
+	switch {
+	case reason != "" && !g.tilted:
+		g.tilted = true
+		g.reason = reason
+		g.logger.CErrorw(ctx, "stopping the base to keep it from tipping over",
+			"reason", reason, "roll_deg", roll, "pitch_deg", pitch, "max_roll_deg", g.maxRoll, "max_pitch_deg", g.maxPitch)
+		if err := g.actual.Stop(ctx, nil); err != nil {
+			g.logger.CErrorw(ctx, "cannot stop the tilted base", "error", err)
+		}
+	case reason != "":
+		g.reason = reason
+		// a command which got past the guard just before the base tilted is stopped too
+		if moving, err := g.actual.IsMoving(ctx); err == nil && moving {
+			if err := g.actual.Stop(ctx, nil); err != nil {
+				g.logger.CErrorw(ctx, "cannot stop the tilted base", "error", err)
+			}
+		}
+	case g.tilted:
+		g.tilted = false
+		g.reason = ""
+		g.logger.CInfow(ctx, "base is level again and may move", "roll_deg", roll, "pitch_deg", pitch)
+	}
+}
+
+// guard returns an error when the base may not move.
+func (g *tiltGuard) guard() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.tilted {
+		return errors.Wrap(ErrTilted, g.reason)
+	}
+	return nil
+}
+
+// result returns the error of a command, or ErrTilted when the guard stopped the base during it.
+func (g *tiltGuard) result(err error) error {
+	if guardErr := g.guard(); guardErr != nil {
+		return guardErr
+	}
+	return err
+}
+
+func (g *tiltGuard) MoveStraight(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+	if err := g.guard(); err != nil {
+		return err
+	}
+	return g.result(g.actual.MoveStraight(ctx, distanceMm, mmPerSec, extra))
+}
+
+func (g *tiltGuard) Spin(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
+	if err := g.guard(); err != nil {
+		return err
+	}
+	return g.result(g.actual.Spin(ctx, angleDeg, degsPerSec, extra))
+}
+
+// SetPower holds the lock while setting the power, so the base cannot tilt between the check and the command.
+func (g *tiltGuard) SetPower(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.tilted {
+		return errors.Wrap(ErrTilted, g.reason)
+	}
+	return g.actual.SetPower(ctx, linear, angular, extra)
+}
+
+// SetVelocity holds the lock while setting the velocity, so the base cannot tilt between the check and the command.
+func (g *tiltGuard) SetVelocity(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.tilted {
+		return errors.Wrap(ErrTilted, g.reason)
+	}
+	return g.actual.SetVelocity(ctx, linear, angular, extra)
+}
+
+func (g *tiltGuard) Stop(ctx context.Context, extra map[string]interface{}) error {
+	return g.actual.Stop(ctx, extra)
+}
+
+func (g *tiltGuard) IsMoving(ctx context.Context) (bool, error) {
+	return g.actual.IsMoving(ctx)
+}
+
+func (g *tiltGuard) Properties(ctx context.Context, extra map[string]interface{}) (base.Properties, error) {
+	return g.actual.Properties(ctx, extra)
+}
+
+func (g *tiltGuard) Geometries(ctx context.Context, extra map[string]interface{}) ([]spatialmath.Geometry, error) {
+	return g.actual.Geometries(ctx, extra)
+}
+
+// DoCommand returns the tilt of the base and whether it is stopped for "tilt_status", passing any other command on
+// to the base.
+func (g *tiltGuard) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if cmd["command"] != "tilt_status" {
+		return g.actual.DoCommand(ctx, cmd)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	resp := map[string]interface{}{"roll_deg": g.roll, "pitch_deg": g.pitch, "tilted": g.tilted}
+	if g.reason != "" {
+		resp["reason"] = g.reason
+	}
+	return resp, nil
+}
+
+func (g *tiltGuard) Close(ctx context.Context) error {
+	g.workers.Stop()
+	return nil
+}
@@ -0,0 +1,141 @@
+package tiltguard
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+)
+
+func TestValidate(t *testing.T) {
+	deps, err := (&Config{Base: "base", MovementSensor: "imu", MaxRollDeg: 15}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"base", "imu"})
+
+	_, err = (&Config{MovementSensor: "imu"}).Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "base"))
+	_, err = (&Config{Base: "base"}).Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "movement_sensor"))
+	_, err = (&Config{Base: "base", MovementSensor: "imu", MaxPitchDeg: 95}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestTiltGuard(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	var mu sync.Mutex
+	var roll, pitch float64
+	var sensorErr error
+	stops := 0
+	setTilt := func(rollDeg, pitchDeg float64, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		roll, pitch, sensorErr = rollDeg, pitchDeg, err
+	}
+	stopCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return stops
+	}
+
+	imu := inject.NewMovementSensor("imu")
+	imu.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+		return &movementsensor.Properties{OrientationSupported: true}, nil
+	}
+	imu.OrientationFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return &spatialmath.EulerAngles{Roll: utils.DegToRad(roll), Pitch: utils.DegToRad(pitch)}, sensorErr
+	}
+	actual := inject.NewBase("actual")
+	actual.SetVelocityFunc = func(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+		return nil
+	}
+	actual.IsMovingFunc = func(ctx context.Context) (bool, error) {
+		return false, nil
+	}
+	actual.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		stops++
+		return nil
+	}
+
+	deps := resource.Dependencies{
+		base.Named("actual"):        actual,
+		movementsensor.Named("imu"): imu,
+	}
+	conf := resource.Config{
+		Name: "guard",
+		ConvertedAttributes: &Config{
+			Base: "actual", MovementSensor: "imu", MaxRollDeg: 10, MaxPitchDeg: 15, PollFrequencyHz: 100,
+		},
+	}
+	b, err := NewTiltGuard(ctx, deps, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer b.Close(ctx)
+
+	test.That(t, b.SetVelocity(ctx, r3.Vector{Y: 100}, r3.Vector{}, nil), test.ShouldBeNil)
+
+	// pitching past the limit stops the base and refuses commands
+	setTilt(0, 20, nil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, stopCount(), test.ShouldEqual, 1)
+	})
+	err = b.SetVelocity(ctx, r3.Vector{Y: 100}, r3.Vector{}, nil)
+	test.That(t, errors.Is(err, ErrTilted), test.ShouldBeTrue)
+	status, err := b.DoCommand(ctx, map[string]interface{}{"command": "tilt_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["tilted"], test.ShouldBeTrue)
+	test.That(t, status["pitch_deg"], test.ShouldAlmostEqual, 20)
+
+	// coming back inside the limit but not past the hysteresis keeps it stopped
+	setTilt(0, 14, nil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		status, err := b.DoCommand(ctx, map[string]interface{}{"command": "tilt_status"})
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, status["pitch_deg"], test.ShouldAlmostEqual, 14)
+	})
+	test.That(t, errors.Is(b.MoveStraight(ctx, 100, 100, nil), ErrTilted), test.ShouldBeTrue)
+
+	setTilt(-5, 5, nil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, b.SetVelocity(ctx, r3.Vector{Y: 100}, r3.Vector{}, nil), test.ShouldBeNil)
+	})
+
+	// rolling past its limit, and a sensor which cannot be read, stop it too
+	setTilt(-11, 0, nil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, stopCount(), test.ShouldEqual, 2)
+	})
+	setTilt(0, 0, nil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, b.SetVelocity(ctx, r3.Vector{Y: 100}, r3.Vector{}, nil), test.ShouldBeNil)
+	})
+	setTilt(0, 0, errors.New("imu unplugged"))
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, stopCount(), test.ShouldEqual, 3)
+	})
+	err = b.SetVelocity(ctx, r3.Vector{Y: 100}, r3.Vector{}, nil)
+	test.That(t, errors.Is(err, ErrTilted), test.ShouldBeTrue)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "imu unplugged")
+}
@@ -0,0 +1,161 @@
+package wheeled
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"go.viam.com/utils"
+)
+
+// rampInterval is how often a ramped command is stepped towards its target.
+const rampInterval = 20 * time.Millisecond
+
+// motionLimits are the optional velocity, power and acceleration limits of a wheeled base.
+// A zero value for any field disables that limit.
+type motionLimits struct {
+	maxLinearMMPerSec            float64
+	maxAngularDegsPerSec         float64
+	maxLinearAccelMMPerSecPerSec float64
+	maxAngularAccelDegsPerSecSec float64
+	maxPower                     float64
+	maxPowerAccelPerSec          float64
+}
+
+func newMotionLimits(conf *Config) motionLimits {
+	return motionLimits{
+		maxLinearMMPerSec:            conf.MaxLinearMMPerSec,
+		maxAngularDegsPerSec:         conf.MaxAngularDegsPerSec,
+		maxLinearAccelMMPerSecPerSec: conf.MaxLinearAccelMMPerSecPerSec,
+		maxAngularAccelDegsPerSecSec: conf.MaxAngularAccelDegsPerSecPerSec,
+		maxPower:                     conf.MaxPower,
+		maxPowerAccelPerSec:          conf.MaxPowerAccelPerSec,
+	}
+}
+
+// appliedCommand is the most recent command the wheeled base sent to its motors, after limits were enforced.
+type appliedCommand struct {
+	// isPower is true when the command came from SetPower, in which case only the power fields are meaningful.
+	isPower           bool
+	linearMMPerSec    float64
+	angularDegsPerSec float64
+	linearPower       float64
+	angularPower      float64
+}
+
+func (ac appliedCommand) toMap() map[string]interface{} {
+	if ac.isPower {
+		return map[string]interface{}{
+			"linear_power":  ac.linearPower,
+			"angular_power": ac.angularPower,
+		}
+	}
+	return map[string]interface{}{
+		"linear_mm_per_sec":    ac.linearMMPerSec,
+		"angular_degs_per_sec": ac.angularDegsPerSec,
+	}
+}
+
+// clampMagnitude limits the magnitude of val to limit, preserving its sign. A non-positive limit disables clamping.
+func clampMagnitude(val, limit float64) float64 {
+	if limit <= 0 {
+		return val
+	}
+	return math.Max(-limit, math.Min(val, limit))
+}
+
+// stepToward moves current towards target by no more than maxStep. A non-positive maxStep jumps directly to target.
+func stepToward(current, target, maxStep float64) float64 {
+	if maxStep <= 0 || math.Abs(target-current) <= maxStep {
+		return target
+	}
+	if target > current {
+		return current + maxStep
+	}
+	return current - maxStep
+}
+
+// ramp repeatedly calls apply with linear and angular values stepping from their starting values towards their targets,
+// changing by no more than the given accelerations (units per second) each rampInterval. The final call is always made
+// with the targets. Zero accelerations result in a single call with the targets.
+func ramp(
+	ctx context.Context,
+	fromLinear, fromAngular, toLinear, toAngular, linearAccel, angularAccel float64,
+	apply func(ctx context.Context, linear, angular float64) error,
+) error {
+	dt := rampInterval.Seconds()
+	linear, angular := fromLinear, fromAngular
+	for {
+		linear = stepToward(linear, toLinear, linearAccel*dt)
+		angular = stepToward(angular, toAngular, angularAccel*dt)
+		if err := apply(ctx, linear, angular); err != nil {
+			return err
+		}
+		if linear == toLinear && angular == toAngular {
+			return nil
+		}
+		if !utils.SelectContextOrWait(ctx, rampInterval) {
+			return ctx.Err()
+		}
+	}
+}
+
+// moveStep is one part of a move of a fixed distance, covered at a constant speed.
+type moveStep struct {
+	speed    float64
+	distance float64
+}
+
+// moveSteps splits a move of the given distance at the given speed into steps which accelerate from fromSpeed, and
+// decelerate to rest at the end of the move, by no more than accel (units per second) each rampInterval. Speeds and
+// distances are magnitudes, and the distances of the steps add up to the distance of the move. A move too short to
+// reach its speed accelerates only as far as it can still stop in. A non-positive accel results in a single step.
+func moveSteps(fromSpeed, speed, distance, accel float64) []moveStep {
+	dt := rampInterval.Seconds()
+	dv := accel * dt
+	if dv <= 0 {
+		return []moveStep{{speed: speed, distance: distance}}
+	}
+	stopping := func(v float64) ([]moveStep, float64) {
+		var steps []moveStep
+		var total float64
+		for v -= dv; v > 0; v -= dv {
+			steps = append(steps, moveStep{speed: v, distance: v * dt})
+			total += v * dt
+		}
+		return steps, total
+	}
+
+	var steps []moveStep
+	v := math.Max(0, math.Min(fromSpeed, speed))
+	for v < speed {
+		next := math.Min(v+dv, speed)
+		if _, stop := stopping(next); next*dt+stop > distance {
+			break
+		}
+		steps = append(steps, moveStep{speed: next, distance: next * dt})
+		distance -= next * dt
+		v = next
+	}
+	if v == 0 {
+		// too short to accelerate over more than a single step
+		return []moveStep{{speed: math.Min(dv, speed), distance: distance}}
+	}
+
+	down, stop := stopping(v)
+	if cruise := distance - stop; cruise > 0 {
+		steps = append(steps, moveStep{speed: v, distance: cruise})
+		distance -= cruise
+	}
+	// a move starting too fast to stop in its distance is cut short by its final steps
+	for _, step := range down {
+		if distance <= 0 {
+			break
+		}
+		step.distance = math.Min(step.distance, distance)
+		steps = append(steps, step)
+		distance -= step.distance
+	}
+	steps[len(steps)-1].distance += distance
+	return steps
+}
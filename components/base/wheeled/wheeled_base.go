@@ -15,6 +15,13 @@ package wheeled
 
    Configuring a base with a frame will create a kinematic base that can be used by Viam's motion service to plan paths
    when a SLAM service is also present. As of June 2023 This feature is experimental.
+
+   Optional velocity, power and acceleration limits bound every command sent to the base. Commanded velocities and powers
+   are clamped to the configured maximums, and SetVelocity/SetPower ramp from the previously applied command to the new one
+   no faster than the configured accelerations, so that an abrupt reversal cannot shock the drivetrain. A ramp runs in the
+   background until the next command or Stop interrupts it. MoveStraight and Spin likewise accelerate at the start of a
+   move and decelerate to rest at its end. The most recently applied command can be retrieved with the DoCommand
+   {"command": "get_applied_command"}.
    Example Config:
    {
      "name": "myBase",
@@ -26,6 +33,12 @@ package wheeled
        "spin_slip_factor": 1.76,
        "wheel_circumference_mm": 217,
        "width_mm": 260,
+       "max_linear_mm_per_sec": 500,
+       "max_angular_degs_per_sec": 90,
+       "max_linear_accel_mm_per_sec_per_sec": 250,
+       "max_angular_accel_degs_per_sec_per_sec": 180,
+       "max_power": 0.8,
+       "max_power_accel_per_sec": 0.5
      },
      "depends_on": ["left1", "left2", "right1", "right2", "local"],
    },
@@ -40,6 +53,7 @@ import (
 	"github.com/golang/geo/r3"
 	"github.com/pkg/errors"
 	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
 
 	"go.viam.com/rdk/components/base"
 	"go.viam.com/rdk/components/motor"
@@ -53,6 +67,9 @@ import (
 // Model is the name of the wheeled model of a base component.
 var Model = resource.DefaultModelFamily.WithModel("wheeled")
 
+// getAppliedCommand is the DoCommand which returns the most recently applied velocity or power command.
+const getAppliedCommand = "get_applied_command"
+
 // Config is how you configure a wheeled base.
 type Config struct {
 	WidthMM              int      `json:"width_mm"`
@@ -60,6 +77,16 @@ type Config struct {
 	SpinSlipFactor       float64  `json:"spin_slip_factor,omitempty"`
 	Left                 []string `json:"left"`
 	Right                []string `json:"right"`
+
+	// Optional limits, a value of zero disables the respective limit.
+	MaxLinearMMPerSec               float64 `json:"max_linear_mm_per_sec,omitempty"`
+	MaxAngularDegsPerSec            float64 `json:"max_angular_degs_per_sec,omitempty"`
+	MaxLinearAccelMMPerSecPerSec    float64 `json:"max_linear_accel_mm_per_sec_per_sec,omitempty"`
+	MaxAngularAccelDegsPerSecPerSec float64 `json:"max_angular_accel_degs_per_sec_per_sec,omitempty"`
+	// MaxPower bounds the magnitude of the linear and angular powers given to SetPower, within (0, 1].
+	MaxPower float64 `json:"max_power,omitempty"`
+	// MaxPowerAccelPerSec bounds how quickly the linear and angular powers may change, in fractions of full power per second.
+	MaxPowerAccelPerSec float64 `json:"max_power_accel_per_sec,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -87,6 +114,25 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 				len(cfg.Left), len(cfg.Right)))
 	}
 
+	for _, limit := range []struct {
+		name  string
+		value float64
+	}{
+		{"max_linear_mm_per_sec", cfg.MaxLinearMMPerSec},
+		{"max_angular_degs_per_sec", cfg.MaxAngularDegsPerSec},
+		{"max_linear_accel_mm_per_sec_per_sec", cfg.MaxLinearAccelMMPerSecPerSec},
+		{"max_angular_accel_degs_per_sec_per_sec", cfg.MaxAngularAccelDegsPerSecPerSec},
+		{"max_power", cfg.MaxPower},
+		{"max_power_accel_per_sec", cfg.MaxPowerAccelPerSec},
+	} {
+		if limit.value < 0 || math.IsNaN(limit.value) {
+			return nil, resource.NewConfigValidationError(path, fmt.Errorf("%s may not be negative", limit.name))
+		}
+	}
+	if cfg.MaxPower > 1 {
+		return nil, resource.NewConfigValidationError(path, fmt.Errorf("max_power must be at most 1, not %f", cfg.MaxPower))
+	}
+
 	deps = append(deps, cfg.Left...)
 	deps = append(deps, cfg.Right...)
 
@@ -103,6 +149,7 @@ type wheeledBase struct {
 	wheelCircumferenceMm int
 	spinSlipFactor       float64
 	geometries           []spatialmath.Geometry
+	limits               motionLimits
 
 	left      []motor.Motor
 	right     []motor.Motor
@@ -113,6 +160,14 @@ type wheeledBase struct {
 
 	mu   sync.Mutex
 	name string
+
+	appliedMu sync.Mutex
+	applied   appliedCommand
+
+	// rampCancel interrupts the ramp running in the background, which closes rampDone once it has stopped.
+	rampMu     sync.Mutex
+	rampCancel context.CancelFunc
+	rampDone   chan struct{}
 }
 
 // Reconfigure reconfigures the base atomically and in place.
@@ -202,6 +257,8 @@ func (wb *wheeledBase) Reconfigure(ctx context.Context, deps resource.Dependenci
 		wb.wheelCircumferenceMm = newConf.WheelCircumferenceMM
 	}
 
+	wb.limits = newMotionLimits(newConf)
+
 	return nil
 }
 
@@ -235,9 +292,12 @@ func createWheeledBase(
 }
 
 // Spin commands a base to turn about its center at a angular speed and for a specific angle.
+// If an angular acceleration limit is configured, the base accelerates from its previous angular velocity at the start
+// of the spin and decelerates to rest at its end.
 func (wb *wheeledBase) Spin(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
 	ctx, done := wb.opMgr.New(ctx)
 	defer done()
+	wb.waitForRamp()
 	wb.logger.CDebugf(ctx, "received a Spin with angleDeg:%.2f, degsPerSec:%.2f", angleDeg, degsPerSec)
 
 	if math.Abs(angleDeg) < 0.0001 {
@@ -253,13 +313,36 @@ func (wb *wheeledBase) Spin(ctx context.Context, angleDeg, degsPerSec float64, e
 		return err
 	}
 
+	limits := wb.currentLimits()
+	degsPerSec = clampMagnitude(degsPerSec, limits.maxAngularDegsPerSec)
+
 	// Spin math
-	rpm, revolutions := wb.spinMath(angleDeg, degsPerSec)
+	rpm, _ := wb.spinMath(angleDeg, degsPerSec)
 
-	return wb.runAllGoFor(ctx, -rpm, revolutions, rpm, revolutions)
+	wb.warnIfLowSpeed(ctx, -rpm, rpm)
+	// the base turns left when the angle and speed have the same sign
+	angularDegsPerSec := math.Abs(degsPerSec)
+	if (angleDeg < 0) != (degsPerSec < 0) {
+		angularDegsPerSec = -angularDegsPerSec
+	}
+	from := 0.
+	if prev := wb.lastApplied(); !prev.isPower {
+		from = math.Copysign(1, angularDegsPerSec) * prev.angularDegsPerSec
+	}
+	defer wb.setApplied(appliedCommand{})
+	for _, step := range moveSteps(from, math.Abs(degsPerSec), math.Abs(angleDeg), limits.maxAngularAccelDegsPerSecSec) {
+		rpm, revolutions := wb.spinMath(math.Copysign(step.distance, angleDeg), math.Copysign(step.speed, degsPerSec))
+		wb.setApplied(appliedCommand{angularDegsPerSec: math.Copysign(step.speed, angularDegsPerSec)})
+		if err := wb.runAllGoFor(ctx, -rpm, revolutions, rpm, revolutions); err != nil || ctx.Err() != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // MoveStraight commands a base to drive forward or backwards  at a linear speed and for a specific distance.
+// If a linear acceleration limit is configured, the base accelerates from its previous linear velocity at the start of
+// the move and decelerates to rest at its end.
 func (wb *wheeledBase) MoveStraight(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
 	wb.logger.CDebugf(ctx, "received a MoveStraight with distanceMM:%d, mmPerSec:%.2f", distanceMm, mmPerSec)
 
@@ -272,13 +355,62 @@ func (wb *wheeledBase) MoveStraight(ctx context.Context, distanceMm int, mmPerSe
 		return err
 	}
 
+	limits := wb.currentLimits()
+	mmPerSec = clampMagnitude(mmPerSec, limits.maxLinearMMPerSec)
+
 	// Straight math
-	rpm, rotations := wb.straightDistanceToMotorInputs(distanceMm, mmPerSec)
+	rpm, _ := wb.straightDistanceToMotorInputs(float64(distanceMm), mmPerSec)
 
 	// start new operation after all calculations are made
 	ctx, done := wb.opMgr.New(ctx)
 	defer done()
-	return wb.runAllGoFor(ctx, rpm, rotations, rpm, rotations)
+	wb.waitForRamp()
+	wb.warnIfLowSpeed(ctx, rpm, rpm)
+	// the base drives backwards when the distance and speed have opposite signs
+	linearMMPerSec := math.Abs(mmPerSec)
+	if (distanceMm < 0) != (mmPerSec < 0) {
+		linearMMPerSec = -linearMMPerSec
+	}
+	from := 0.
+	if prev := wb.lastApplied(); !prev.isPower {
+		from = math.Copysign(1, linearMMPerSec) * prev.linearMMPerSec
+	}
+	defer wb.setApplied(appliedCommand{})
+	for _, step := range moveSteps(from, math.Abs(mmPerSec), math.Abs(float64(distanceMm)), limits.maxLinearAccelMMPerSecPerSec) {
+		rpm, rotations := wb.straightDistanceToMotorInputs(math.Copysign(step.distance, float64(distanceMm)), math.Copysign(step.speed, mmPerSec))
+		wb.setApplied(appliedCommand{linearMMPerSec: math.Copysign(step.speed, linearMMPerSec)})
+		if err := wb.runAllGoFor(ctx, rpm, rotations, rpm, rotations); err != nil || ctx.Err() != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// warnIfLowSpeed warns if either side of the base is commanded at a speed too low for motors to reliably follow.
+func (wb *wheeledBase) warnIfLowSpeed(ctx context.Context, leftRPM, rightRPM float64) {
+	if math.Abs(leftRPM) <= 10 || math.Abs(rightRPM) <= 10 {
+		wb.logger.CWarn(ctx, "low motor speed detected, motors may not behave as expected")
+	}
+}
+
+// currentLimits returns the limits of the base, which may change during Reconfigure.
+func (wb *wheeledBase) currentLimits() motionLimits {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	return wb.limits
+}
+
+// lastApplied returns the most recent command sent to the motors.
+func (wb *wheeledBase) lastApplied() appliedCommand {
+	wb.appliedMu.Lock()
+	defer wb.appliedMu.Unlock()
+	return wb.applied
+}
+
+func (wb *wheeledBase) setApplied(ac appliedCommand) {
+	wb.appliedMu.Lock()
+	defer wb.appliedMu.Unlock()
+	wb.applied = ac
 }
 
 // runAllGoFor executes `motor.GoFor` commands in parallel for left and right motors,
@@ -286,9 +418,6 @@ func (wb *wheeledBase) MoveStraight(ctx context.Context, distanceMm int, mmPerSe
 // All callers must register an operation via `wb.opMgr.New` to ensure the left and right motors
 // receive consistent instructions.
 func (wb *wheeledBase) runAllGoFor(ctx context.Context, leftRPM, leftRotations, rightRPM, rightRotations float64) error {
-	if math.Abs(leftRPM) <= 10 || math.Abs(rightRPM) <= 10 {
-		wb.logger.CWarn(ctx, "low motor speed detected, motors may not behave as expected")
-	}
 	goForFuncs := func() []rdkutils.SimpleFunc {
 		ret := []rdkutils.SimpleFunc{}
 
@@ -308,7 +437,7 @@ func (wb *wheeledBase) runAllGoFor(ctx context.Context, leftRPM, leftRotations,
 	}()
 
 	if _, err := rdkutils.RunInParallel(ctx, goForFuncs); err != nil {
-		err := multierr.Combine(err, wb.Stop(ctx, nil))
+		err := multierr.Combine(err, wb.stopMotors(ctx, nil))
 		// Ignore the context canceled error - this occurs when the base is stopped by the user.
 		if !errors.Is(err, context.Canceled) {
 			return err
@@ -357,47 +486,155 @@ func (wb *wheeledBase) differentialDrive(forward, left float64) (float64, float6
 }
 
 // SetVelocity commands the base to move at the input linear and angular velocities.
+// The velocities are clamped to the configured maximums and, if acceleration limits are configured, ramped to in the
+// background from the previously applied velocities.
 func (wb *wheeledBase) SetVelocity(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
 	wb.logger.CDebugf(ctx,
 		"received a SetVelocity with linear.X: %.2f, linear.Y: %.2f linear.Z: %.2f(mmPerSec),"+
 			" angular.X: %.2f, angular.Y: %.2f, angular.Z: %.2f",
 		linear.X, linear.Y, linear.Z, angular.X, angular.Y, angular.Z)
 
-	// check if we're receiving a vector of magnitude zero (all components zero) from linear and angular velcoity
-	// and interpret that as a signal to stop the base
-	if linear.Norm() == 0 && angular.Norm() == 0 {
-		wb.logger.CDebug(ctx, "received a SetVelocity command of linear 0,0,0, and angular 0,0,0, stopping base")
-		return wb.Stop(ctx, nil)
+	limits := wb.currentLimits()
+	mmPerSec := clampMagnitude(linear.Y, limits.maxLinearMMPerSec)
+	degsPerSec := clampMagnitude(angular.Z, limits.maxAngularDegsPerSec)
+	if mmPerSec != linear.Y || degsPerSec != angular.Z {
+		wb.logger.CDebugf(ctx, "SetVelocity clamped to linear.Y: %.2f(mmPerSec), angular.Z: %.2f(degsPerSec)", mmPerSec, degsPerSec)
 	}
 
-	leftRPM, rightRPM := wb.velocityMath(linear.Y, angular.Z)
 	// Passing zero revolutions to `motor.GoFor` will have the motor run until
 	// interrupted. Moreover, `motor.GoFor` will return immediately when given zero revolutions.
 	const numRevolutions = 0
 
-	// start new operation after all calculations are made
-	ctx, done := wb.opMgr.New(ctx)
-	defer done()
-	return wb.runAllGoFor(ctx, leftRPM, numRevolutions, rightRPM, numRevolutions)
+	return wb.startRamp(false, mmPerSec, degsPerSec,
+		limits.maxLinearAccelMMPerSecPerSec, limits.maxAngularAccelDegsPerSecSec,
+		func(ctx context.Context, mmPerSec, degsPerSec float64) error {
+			// interpret a velocity of zero as a signal to stop the base
+			if mmPerSec == 0 && degsPerSec == 0 {
+				wb.logger.CDebug(ctx, "SetVelocity reached a linear and angular velocity of 0, stopping base")
+				return wb.stopMotors(ctx, nil)
+			}
+			leftRPM, rightRPM := wb.velocityMath(mmPerSec, degsPerSec)
+			if err := wb.runAllGoFor(ctx, leftRPM, numRevolutions, rightRPM, numRevolutions); err != nil {
+				return err
+			}
+			wb.setApplied(appliedCommand{linearMMPerSec: mmPerSec, angularDegsPerSec: degsPerSec})
+			return nil
+		})
 }
 
 // SetPower commands the base motors to run at powers corresponding to input linear and angular powers.
+// The powers are clamped to the configured maximum and, if a power acceleration limit is configured, ramped to in
+// the background from the previously applied powers.
 func (wb *wheeledBase) SetPower(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
-	wb.opMgr.CancelRunning(ctx)
-
 	wb.logger.CDebugf(ctx,
 		"received a SetPower with linear.X: %.2f, linear.Y: %.2f linear.Z: %.2f,"+
 			" angular.X: %.2f, angular.Y: %.2f, angular.Z: %.2f",
 		linear.X, linear.Y, linear.Z, angular.X, angular.Y, angular.Z)
 
-	// check if we're receiving a vector of magnitude zero (all components zero) from linear and angular velcoity
-	// and interpret that as a signal to stop the base
-	if linear.Norm() == 0 && angular.Norm() == 0 {
-		wb.logger.CDebug(ctx, "received a SetPower command of linear 0,0,0, and angular 0,0,0, stopping base")
-		return wb.Stop(ctx, nil)
+	limits := wb.currentLimits()
+	linearPower := clampMagnitude(linear.Y, limits.maxPower)
+	angularPower := clampMagnitude(angular.Z, limits.maxPower)
+	if linearPower != linear.Y || angularPower != angular.Z {
+		wb.logger.CDebugf(ctx, "SetPower clamped to linear.Y: %.2f, angular.Z: %.2f", linearPower, angularPower)
+	}
+
+	return wb.startRamp(true, linearPower, angularPower, limits.maxPowerAccelPerSec, limits.maxPowerAccelPerSec,
+		func(ctx context.Context, linearPower, angularPower float64) error {
+			// interpret a power of zero as a signal to stop the base
+			if linearPower == 0 && angularPower == 0 {
+				wb.logger.CDebug(ctx, "SetPower reached a linear and angular power of 0, stopping base")
+				return wb.stopMotors(ctx, nil)
+			}
+			if err := wb.setAllPower(ctx, linearPower, angularPower, extra); err != nil {
+				return err
+			}
+			wb.setApplied(appliedCommand{isPower: true, linearPower: linearPower, angularPower: angularPower})
+			return nil
+		})
+}
+
+// startRamp starts a new operation which ramps from the previously applied velocities, or powers, towards their
+// targets. The first step is applied before it returns, returning its error, and the rest of the ramp runs in the
+// background until it reaches the targets or the next command or Stop interrupts it.
+func (wb *wheeledBase) startRamp(
+	isPower bool,
+	toLinear, toAngular, linearAccel, angularAccel float64,
+	apply func(ctx context.Context, linear, angular float64) error,
+) error {
+	// the ramp outlives the call which starts it, so it only runs in the context of its operation
+	ctx, done := wb.opMgr.New(context.Background())
+	// the new operation interrupts the previous ramp, which must stop before this one can continue from its last step
+	wb.waitForRamp()
+	ctx, cancel := context.WithCancel(ctx)
+	rampDone := make(chan struct{})
+	finish := func() {
+		cancel()
+		done()
+		close(rampDone)
+	}
+	wb.rampMu.Lock()
+	wb.rampCancel, wb.rampDone = cancel, rampDone
+	wb.rampMu.Unlock()
+
+	// a ramp can only continue from a previous command of its kind, a velocity command has no known power and a power
+	// command no known velocity
+	var linear, angular float64
+	if prev := wb.lastApplied(); prev.isPower && isPower {
+		linear, angular = prev.linearPower, prev.angularPower
+	} else if !prev.isPower && !isPower {
+		linear, angular = prev.linearMMPerSec, prev.angularDegsPerSec
+	}
+	dt := rampInterval.Seconds()
+	linear = stepToward(linear, toLinear, linearAccel*dt)
+	angular = stepToward(angular, toAngular, angularAccel*dt)
+	if err := apply(ctx, linear, angular); err != nil || (linear == toLinear && angular == toAngular) {
+		finish()
+		// Ignore the context canceled error - this occurs when the ramp is interrupted by a new command or Stop.
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	}
+
+	goutils.PanicCapturingGo(func() {
+		defer finish()
+		if !goutils.SelectContextOrWait(ctx, rampInterval) {
+			return
+		}
+		err := ramp(ctx, linear, angular, toLinear, toAngular, linearAccel, angularAccel, apply)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			wb.logger.CErrorw(ctx, "failed to ramp the base", "error", err)
+		}
+	})
+	return nil
+}
+
+// cancelRamp interrupts the ramp running in the background, if any, and waits for it to stop. Stop calls it as well
+// as cancelling the running operation, which it does not do when called in the context of another operation.
+func (wb *wheeledBase) cancelRamp() {
+	wb.rampMu.Lock()
+	cancel, rampDone := wb.rampCancel, wb.rampDone
+	wb.rampMu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-rampDone
 	}
+}
 
-	lPower, rPower := wb.differentialDrive(linear.Y, angular.Z)
+// waitForRamp waits for the ramp running in the background, if any, to stop. Commands call it once their operation
+// has interrupted the ramp, so that they start from the last command the ramp applied.
+func (wb *wheeledBase) waitForRamp() {
+	wb.rampMu.Lock()
+	rampDone := wb.rampDone
+	wb.rampMu.Unlock()
+	if rampDone != nil {
+		<-rampDone
+	}
+}
+
+// setAllPower sets the powers of the left and right motors from linear and angular powers, stopping the base on error.
+func (wb *wheeledBase) setAllPower(ctx context.Context, linearPower, angularPower float64, extra map[string]interface{}) error {
+	lPower, rPower := wb.differentialDrive(linearPower, angularPower)
 
 	// Send motor commands
 	setPowerFuncs := func() []rdkutils.SimpleFunc {
@@ -418,7 +655,7 @@ func (wb *wheeledBase) SetPower(ctx context.Context, linear, angular r3.Vector,
 	}()
 
 	if _, err := rdkutils.RunInParallel(ctx, setPowerFuncs); err != nil {
-		return multierr.Combine(err, wb.Stop(ctx, nil))
+		return multierr.Combine(err, wb.stopMotors(ctx, nil))
 	}
 	return nil
 }
@@ -455,9 +692,9 @@ func (wb *wheeledBase) velocityMath(mmPerSec, degsPerSec float64) (float64, floa
 }
 
 // calculates the motor revolutions and speeds that correspond to the required distance and linear speeds.
-func (wb *wheeledBase) straightDistanceToMotorInputs(distanceMm int, mmPerSec float64) (float64, float64) {
+func (wb *wheeledBase) straightDistanceToMotorInputs(distanceMm, mmPerSec float64) (float64, float64) {
 	// takes in base speed and distance to calculate motor rpm and total rotations
-	rotations := float64(distanceMm) / float64(wb.wheelCircumferenceMm)
+	rotations := distanceMm / float64(wb.wheelCircumferenceMm)
 
 	rotationsPerSec := mmPerSec / float64(wb.wheelCircumferenceMm)
 	rpm := 60 * rotationsPerSec
@@ -465,8 +702,18 @@ func (wb *wheeledBase) straightDistanceToMotorInputs(distanceMm int, mmPerSec fl
 	return rpm, rotations
 }
 
-// Stop commands the base to stop moving.
+// Stop commands the base to stop moving, interrupting any command that is still being ramped.
 func (wb *wheeledBase) Stop(ctx context.Context, extra map[string]interface{}) error {
+	wb.cancelRamp()
+	wb.opMgr.CancelRunning(ctx)
+	return wb.stopMotors(ctx, extra)
+}
+
+// stopMotors stops every motor of the base without interrupting the running operation, for the operations stopping
+// the base themselves.
+func (wb *wheeledBase) stopMotors(ctx context.Context, extra map[string]interface{}) error {
+	wb.setApplied(appliedCommand{})
+
 	stopFuncs := func() []rdkutils.SimpleFunc {
 		ret := []rdkutils.SimpleFunc{}
 
@@ -490,6 +737,21 @@ func (wb *wheeledBase) Stop(ctx context.Context, extra map[string]interface{}) e
 	return nil
 }
 
+// DoCommand supports {"command": "get_applied_command"}, which returns the most recently applied velocity
+// (linear_mm_per_sec, angular_degs_per_sec) or power (linear_power, angular_power) after limits were enforced.
+func (wb *wheeledBase) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case getAppliedCommand:
+		return wb.lastApplied().toMap(), nil
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
 func (wb *wheeledBase) IsMoving(ctx context.Context) (bool, error) {
 	for _, m := range wb.allMotors {
 		isMoving, _, err := m.IsPowered(ctx, nil)
@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"math"
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/golang/geo/r3"
 	"go.viam.com/test"
 	"go.viam.com/utils"
+	"go.viam.com/utils/testutils"
 
 	"go.viam.com/rdk/components/base"
 	"go.viam.com/rdk/components/motor"
@@ -465,6 +467,217 @@ func TestValidate(t *testing.T) {
 	deps, err = cfg.Validate("path")
 	test.That(t, deps, test.ShouldResemble, []string{"fl-m", "bl-m", "fr-m", "br-m"})
 	test.That(t, err, test.ShouldBeNil)
+
+	cfg.MaxLinearAccelMMPerSecPerSec = -1
+	_, err = cfg.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "max_linear_accel_mm_per_sec_per_sec may not be negative")
+
+	cfg.MaxLinearAccelMMPerSecPerSec = 100
+	cfg.MaxPower = 1.5
+	_, err = cfg.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "max_power must be at most 1")
+
+	cfg.MaxPower = 0.5
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+}
+
+func TestWheeledBaseLimits(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	testCfg := newTestCfg()
+	conf := testCfg.ConvertedAttributes.(*Config)
+	conf.MaxLinearMMPerSec = 500
+	conf.MaxAngularDegsPerSec = 90
+	conf.MaxLinearAccelMMPerSecPerSec = 5000
+	conf.MaxPower = 0.8
+	conf.MaxPowerAccelPerSec = 8
+	deps, err := testCfg.Validate("path", resource.APITypeComponentName)
+	test.That(t, err, test.ShouldBeNil)
+	motorDeps := fakeMotorDependencies(t, deps)
+
+	newBase, err := createWheeledBase(ctx, motorDeps, testCfg, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer newBase.Close(ctx)
+
+	applied := func() map[string]interface{} {
+		resp, err := newBase.DoCommand(ctx, map[string]interface{}{"command": getAppliedCommand})
+		test.That(t, err, test.ShouldBeNil)
+		return resp
+	}
+
+	t.Run("velocities are clamped and ramped", func(t *testing.T) {
+		start := time.Now()
+		err := newBase.SetVelocity(ctx, r3.Vector{Y: 1000}, r3.Vector{Z: 180}, nil)
+		test.That(t, err, test.ShouldBeNil)
+		// the ramp continues in the background after its first step
+		test.That(t, applied()["linear_mm_per_sec"], test.ShouldEqual, 100.)
+		moving, err := newBase.IsMoving(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, moving, test.ShouldBeTrue)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			test.That(tb, applied(), test.ShouldResemble, map[string]interface{}{
+				"linear_mm_per_sec":    500.,
+				"angular_degs_per_sec": 90.,
+			})
+		})
+		// ramping from 0 to the clamped 500 mm/s at 5000 mm/s^2 takes at least 80ms after the first step
+		test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 80*time.Millisecond)
+
+		// reversing ramps through zero without stopping the ramp early
+		start = time.Now()
+		err = newBase.SetVelocity(ctx, r3.Vector{Y: -500}, r3.Vector{Z: 90}, nil)
+		test.That(t, err, test.ShouldBeNil)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			test.That(tb, applied()["linear_mm_per_sec"], test.ShouldEqual, -500.)
+		})
+		test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 160*time.Millisecond)
+	})
+
+	t.Run("clamping preserves sign and may be disabled", func(t *testing.T) {
+		test.That(t, clampMagnitude(-1000, 500), test.ShouldEqual, -500)
+		test.That(t, clampMagnitude(1000, 0), test.ShouldEqual, 1000)
+	})
+
+	t.Run("powers are clamped and ramped", func(t *testing.T) {
+		err := newBase.SetPower(ctx, r3.Vector{Y: 1}, r3.Vector{}, nil)
+		test.That(t, err, test.ShouldBeNil)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			test.That(tb, applied(), test.ShouldResemble, map[string]interface{}{
+				"linear_power":  0.8,
+				"angular_power": 0.,
+			})
+		})
+
+		// a full reversal at 8 power/s takes 200ms
+		start := time.Now()
+		err = newBase.SetPower(ctx, r3.Vector{Y: -0.8}, r3.Vector{}, nil)
+		test.That(t, err, test.ShouldBeNil)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			test.That(tb, applied()["linear_power"], test.ShouldEqual, -0.8)
+		})
+		test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 160*time.Millisecond)
+	})
+
+	t.Run("stop interrupts a ramp and resets the applied command", func(t *testing.T) {
+		test.That(t, newBase.SetPower(ctx, r3.Vector{Y: 0.8}, r3.Vector{}, nil), test.ShouldBeNil)
+		time.Sleep(50 * time.Millisecond)
+		test.That(t, newBase.Stop(ctx, nil), test.ShouldBeNil)
+		test.That(t, applied()["linear_mm_per_sec"], test.ShouldEqual, 0.)
+		isMoving, err := newBase.IsMoving(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, isMoving, test.ShouldBeFalse)
+	})
+
+	t.Run("moves report the velocity they are applying", func(t *testing.T) {
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- newBase.MoveStraight(ctx, -1000, 100, nil)
+		}()
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			test.That(tb, applied()["linear_mm_per_sec"], test.ShouldEqual, -100.)
+		})
+		test.That(t, newBase.Stop(ctx, nil), test.ShouldBeNil)
+		test.That(t, <-errCh, test.ShouldBeNil)
+		test.That(t, applied()["linear_mm_per_sec"], test.ShouldEqual, 0.)
+
+		go func() {
+			errCh <- newBase.Spin(ctx, -90, 45, nil)
+		}()
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			test.That(tb, applied()["angular_degs_per_sec"], test.ShouldEqual, -45.)
+		})
+		test.That(t, newBase.Stop(ctx, nil), test.ShouldBeNil)
+		test.That(t, <-errCh, test.ShouldBeNil)
+		test.That(t, applied()["angular_degs_per_sec"], test.ShouldEqual, 0.)
+	})
+
+	t.Run("moves accelerate at their start and decelerate at their end", func(t *testing.T) {
+		var speeds []float64
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for {
+				select {
+				case <-done:
+					return
+				case <-time.After(time.Millisecond):
+				}
+				speed := applied()["linear_mm_per_sec"].(float64)
+				if len(speeds) == 0 || speeds[len(speeds)-1] != speed {
+					speeds = append(speeds, speed)
+				}
+			}
+		}()
+		test.That(t, newBase.MoveStraight(ctx, 200, 500, nil), test.ShouldBeNil)
+		done <- struct{}{}
+		<-done
+		test.That(t, speeds, test.ShouldContain, 100.)
+		test.That(t, speeds, test.ShouldContain, 500.)
+		test.That(t, applied()["linear_mm_per_sec"], test.ShouldEqual, 0.)
+		peak := 0
+		for i, speed := range speeds {
+			if speed > speeds[peak] {
+				peak = i
+			}
+		}
+		test.That(t, sort.Float64sAreSorted(speeds[:peak+1]), test.ShouldBeTrue)
+		test.That(t, speeds[peak+1:], test.ShouldNotBeEmpty)
+		test.That(t, sort.IsSorted(sort.Reverse(sort.Float64Slice(speeds[peak:]))), test.ShouldBeTrue)
+	})
+
+	t.Run("unknown commands error", func(t *testing.T) {
+		_, err := newBase.DoCommand(ctx, map[string]interface{}{"command": "bogus"})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
+
+func TestRamp(t *testing.T) {
+	var linears, angulars []float64
+	apply := func(ctx context.Context, linear, angular float64) error {
+		linears = append(linears, linear)
+		angulars = append(angulars, angular)
+		return nil
+	}
+	err := ramp(context.Background(), 0, 10, 3, 10, 1/rampInterval.Seconds(), 0, apply)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, linears, test.ShouldResemble, []float64{1, 2, 3})
+	test.That(t, angulars, test.ShouldResemble, []float64{10, 10, 10})
+
+	linears = nil
+	err = ramp(context.Background(), 0, 0, 3, 5, 0, 0, apply)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, linears, test.ShouldResemble, []float64{3})
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = ramp(cancelCtx, 0, 0, 3, 0, 1/rampInterval.Seconds(), 0, apply)
+	test.That(t, err, test.ShouldBeError, context.Canceled)
+}
+
+func TestMoveSteps(t *testing.T) {
+	dt := rampInterval.Seconds()
+	check := func(steps []moveStep, speeds []float64, distance float64) {
+		t.Helper()
+		test.That(t, len(steps), test.ShouldEqual, len(speeds))
+		var total float64
+		for i, step := range steps {
+			test.That(t, step.speed, test.ShouldAlmostEqual, speeds[i])
+			total += step.distance
+		}
+		test.That(t, total, test.ShouldAlmostEqual, distance)
+	}
+
+	// an acceleration of 1 unit per second each rampInterval
+	accel := 1 / dt
+	check(moveSteps(0, 3, 100, 0), []float64{3}, 100)
+	check(moveSteps(0, 3, 100, accel), []float64{1, 2, 3, 3, 2, 1}, 100)
+	// a move which is too short to reach its speed
+	check(moveSteps(0, 3, 5*dt, accel), []float64{1, 2, 2, 1}, 5*dt)
+	check(moveSteps(0, 3, dt/2, accel), []float64{1}, dt/2)
+	// a move continuing from a previous velocity only decelerates
+	check(moveSteps(3, 3, 100, accel), []float64{3, 2, 1}, 100)
+	check(moveSteps(-3, 3, 100, accel), []float64{1, 2, 3, 3, 2, 1}, 100)
 }
 
 // waitForMotorsToStop polls all motors to see if they're on, used only for testing.
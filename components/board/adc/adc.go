@@ -0,0 +1,296 @@
+// Package adc is shared code for hooking external ADCs, such as the ADS1115 and the ADS1263, up to a board, whose
+// analogs then include the channels of the ADCs. Channels are either single-ended, measured against ground (or
+// AINCOM on the ADS1263), or differential, measured between two inputs such as the outputs of a load cell bridge.
+// It is used by the genericlinux boards, but does not implement a board directly.
+package adc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/grpc"
+	"go.viam.com/rdk/resource"
+)
+
+// The models of the supported ADCs.
+const (
+	ADS1115 = "ads1115"
+	ADS1263 = "ads1263"
+)
+
+// Config describes the configuration of an ADC on a board.
+type Config struct {
+	Name  string `json:"name"`
+	Model string `json:"model"`
+	// I2CBus and I2CAddress locate an ADS1115.
+	I2CBus     string `json:"i2c_bus,omitempty"`
+	I2CAddress int    `json:"i2c_address,omitempty"`
+	// SPIBus and ChipSelect locate an ADS1263.
+	SPIBus     string `json:"spi_bus,omitempty"`
+	ChipSelect string `json:"chip_select,omitempty"`
+	// ReferenceVolts is the voltage of the reference of an ADS1263, which defaults to its internal 2.5V reference.
+	ReferenceVolts float64         `json:"reference_volts,omitempty"`
+	Channels       []ChannelConfig `json:"channels"`
+}
+
+// ChannelConfig describes a channel of an ADC, which becomes an analog of the board.
+type ChannelConfig struct {
+	Name string `json:"name"`
+	// Pin is the input of the channel, such as "0", or the positive and negative inputs of a differential channel,
+	// such as "0-1".
+	Pin string `json:"pin"`
+	// Gain is the gain of the programmable gain amplifier, which divides the full scale range. The ADS1115 has gains
+	// of 0.667, 1, 2, 4, 8 and 16 for ranges of ±6.144V down to ±0.256V, and the ADS1263 gains of 1 to 32.
+	Gain float64 `json:"gain,omitempty"`
+	// DataRate is the rate of conversions of the ADC in samples per second, where slower rates are less noisy.
+	DataRate          float64 `json:"data_rate_sps,omitempty"`
+	AverageOverMillis int     `json:"average_over_ms,omitempty"`
+	SamplesPerSecond  int     `json:"samples_per_sec,omitempty"`
+}
+
+// A model describes the inputs, gains and data rates of a model of ADC.
+type model struct {
+	inputs int
+	// differential lists the pairs of inputs which can be differential channels, or is nil if any pair can.
+	differential    [][2]int
+	gains           []float64
+	dataRates       []float64
+	defaultDataRate float64
+}
+
+var models = map[string]model{
+	ADS1115: {
+		inputs:          4,
+		differential:    [][2]int{{0, 1}, {0, 3}, {1, 3}, {2, 3}},
+		gains:           ads1115Gains,
+		dataRates:       ads1115DataRates,
+		defaultDataRate: 128,
+	},
+	ADS1263: {
+		inputs:          10,
+		gains:           ads1263Gains,
+		dataRates:       ads1263DataRates,
+		defaultDataRate: 20,
+	},
+}
+
+// Validate ensures all parts of the config are valid.
+func (config *Config) Validate(path string) error {
+	if config.Name == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "name")
+	}
+	m, ok := models[config.Model]
+	if !ok {
+		return resource.NewConfigValidationError(path,
+			errors.Errorf("unsupported adc model %q, must be %q or %q", config.Model, ADS1115, ADS1263))
+	}
+	switch config.Model {
+	case ADS1115:
+		if config.I2CBus == "" {
+			return resource.NewConfigValidationFieldRequiredError(path, "i2c_bus")
+		}
+		if config.I2CAddress < 0 || config.I2CAddress > 0x7F {
+			return resource.NewConfigValidationError(path, errors.New("i2c_address must be a 7 bit address"))
+		}
+	case ADS1263:
+		if config.SPIBus == "" {
+			return resource.NewConfigValidationFieldRequiredError(path, "spi_bus")
+		}
+		if config.ChipSelect == "" {
+			return resource.NewConfigValidationFieldRequiredError(path, "chip_select")
+		}
+		if config.ReferenceVolts < 0 {
+			return resource.NewConfigValidationError(path, errors.New("reference_volts cannot be negative"))
+		}
+	}
+	if len(config.Channels) == 0 {
+		return resource.NewConfigValidationFieldRequiredError(path, "channels")
+	}
+	for idx, c := range config.Channels {
+		channelPath := fmt.Sprintf("%s.%s.%d", path, "channels", idx)
+		if c.Name == "" {
+			return resource.NewConfigValidationFieldRequiredError(channelPath, "name")
+		}
+		if _, _, err := m.parsePin(c.Pin); err != nil {
+			return resource.NewConfigValidationError(channelPath, err)
+		}
+		if c.Gain != 0 && m.gainIndex(c.Gain) < 0 {
+			return resource.NewConfigValidationError(channelPath,
+				errors.Errorf("%s has no gain of %v, it has gains of %v", config.Model, c.Gain, m.gains))
+		}
+		if c.DataRate != 0 && m.dataRateIndex(c.DataRate) < 0 {
+			return resource.NewConfigValidationError(channelPath,
+				errors.Errorf("%s has no data rate of %v, it has data rates of %v", config.Model, c.DataRate, m.dataRates))
+		}
+	}
+	return nil
+}
+
+// parsePin returns the positive and negative inputs of a channel, where a negative input of -1 is ground.
+func (m model) parsePin(pin string) (int, int, error) {
+	parseInput := func(s string) (int, error) {
+		input, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || input < 0 || input >= m.inputs {
+			return 0, errors.Errorf("bad adc input %q, must be from 0 to %d", s, m.inputs-1)
+		}
+		return input, nil
+	}
+	positive, negative, isDifferential := strings.Cut(pin, "-")
+	p, err := parseInput(positive)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !isDifferential {
+		return p, -1, nil
+	}
+	n, err := parseInput(negative)
+	if err != nil {
+		return 0, 0, err
+	}
+	if p == n {
+		return 0, 0, errors.Errorf("the inputs of differential pin %q must differ", pin)
+	}
+	if m.differential == nil {
+		return p, n, nil
+	}
+	for _, pair := range m.differential {
+		if pair == [2]int{p, n} {
+			return p, n, nil
+		}
+	}
+	return 0, 0, errors.Errorf("bad differential pin %q, must be one of %v", pin, m.differential)
+}
+
+// gainIndex returns the index of a gain, allowing for gains such as 2/3 which are rounded in configs.
+func (m model) gainIndex(gain float64) int {
+	for i, g := range m.gains {
+		if math.Abs(g-gain) < 0.01 {
+			return i
+		}
+	}
+	return -1
+}
+
+// dataRateIndex returns the index of a data rate, allowing for rates such as 16.6 which are rounded in configs.
+func (m model) dataRateIndex(rate float64) int {
+	for i, r := range m.dataRates {
+		if math.Abs(r-rate) < 0.5 {
+			return i
+		}
+	}
+	return -1
+}
+
+// A converter reads one channel at a time, configured with the indexes of its gain and data rate, returning the
+// reading with its full scale range in volts.
+type converter interface {
+	convert(ctx context.Context, positive, negative, gain, dataRate int) (int, float64, error)
+	// fullScaleCounts is the reading at the full scale range.
+	fullScaleCounts() float64
+}
+
+// NewAnalogs returns the analogs of the channels of an ADC by name, and the configs of how they are smoothed. An
+// ADS1115 needs the I2C bus and an ADS1263 the SPI bus the config locates it on.
+func NewAnalogs(
+	ctx context.Context, conf Config, i2c buses.I2C, spi buses.SPI,
+) (map[string]board.Analog, map[string]board.AnalogReaderConfig, error) {
+	m, ok := models[conf.Model]
+	if !ok {
+		return nil, nil, errors.Errorf("unsupported adc model %q", conf.Model)
+	}
+	var conv converter
+	switch conf.Model {
+	case ADS1115:
+		address := byte(ads1115DefaultAddress)
+		if conf.I2CAddress != 0 {
+			address = byte(conf.I2CAddress)
+		}
+		conv = &ads1115{bus: i2c, address: address}
+	case ADS1263:
+		reference := ads1263InternalReference
+		if conf.ReferenceVolts != 0 {
+			reference = conf.ReferenceVolts
+		}
+		dev := &ads1263{bus: spi, chipSelect: conf.ChipSelect, reference: reference}
+		if err := dev.reset(ctx); err != nil {
+			return nil, nil, errors.Wrapf(err, "cannot reset adc %q", conf.Name)
+		}
+		conv = dev
+	}
+
+	// the channels of an adc take turns converting
+	mu := &sync.Mutex{}
+	analogs := map[string]board.Analog{}
+	smoothing := map[string]board.AnalogReaderConfig{}
+	for _, c := range conf.Channels {
+		positive, negative, err := m.parsePin(c.Pin)
+		if err != nil {
+			return nil, nil, err
+		}
+		gain, rate := c.Gain, c.DataRate
+		if gain == 0 {
+			gain = 1
+		}
+		if rate == 0 {
+			rate = m.defaultDataRate
+		}
+		gainIndex, rateIndex := m.gainIndex(gain), m.dataRateIndex(rate)
+		if gainIndex < 0 || rateIndex < 0 {
+			return nil, nil, errors.Errorf("bad gain or data rate of adc channel %q", c.Name)
+		}
+		analogs[c.Name] = &channel{
+			mu:       mu,
+			conv:     conv,
+			positive: positive,
+			negative: negative,
+			gain:     gainIndex,
+			dataRate: rateIndex,
+		}
+		smoothing[c.Name] = board.AnalogReaderConfig{
+			Name:              c.Name,
+			Pin:               c.Pin,
+			AverageOverMillis: c.AverageOverMillis,
+			SamplesPerSecond:  c.SamplesPerSecond,
+		}
+	}
+	return analogs, smoothing, nil
+}
+
+// channel is a channel of an ADC. Its readings are in counts of StepSize volts, from Min to Max volts.
+type channel struct {
+	mu                 *sync.Mutex
+	conv               converter
+	positive, negative int
+	gain, dataRate     int
+}
+
+func (c *channel) Read(ctx context.Context, extra map[string]interface{}) (board.AnalogValue, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, fullScale, err := c.conv.convert(ctx, c.positive, c.negative, c.gain, c.dataRate)
+	if err != nil {
+		return board.AnalogValue{}, err
+	}
+	minVolts := -fullScale
+	if c.negative < 0 {
+		minVolts = 0
+	}
+	return board.AnalogValue{
+		Value:    value,
+		Min:      float32(minVolts),
+		Max:      float32(fullScale),
+		StepSize: float32(fullScale / c.conv.fullScaleCounts()),
+	}, nil
+}
+
+func (c *channel) Write(ctx context.Context, value int, extra map[string]interface{}) error {
+	return grpc.UnimplementedError
+}
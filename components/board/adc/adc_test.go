@@ -0,0 +1,171 @@
+package adc_test
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board/adc"
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// fakeSPI is an SPI bus which answers each transfer with the function it was made with.
+type fakeSPI struct {
+	xfer func(tx []byte) []byte
+}
+
+func (s *fakeSPI) OpenHandle() (buses.SPIHandle, error) {
+	return fakeSPIHandle{s}, nil
+}
+
+func (s *fakeSPI) Close(ctx context.Context) error {
+	return nil
+}
+
+type fakeSPIHandle struct {
+	bus *fakeSPI
+}
+
+func (h fakeSPIHandle) Xfer(ctx context.Context, baud uint, chipSelect string, mode uint, tx []byte) ([]byte, error) {
+	return h.bus.xfer(tx), nil
+}
+
+func (h fakeSPIHandle) Close() error {
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	conf := adc.Config{
+		Name:     "adc",
+		Model:    adc.ADS1115,
+		I2CBus:   "1",
+		Channels: []adc.ChannelConfig{{Name: "cell", Pin: "0-1", Gain: 0.667, DataRate: 860}, {Name: "pot", Pin: "2"}},
+	}
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+
+	conf.Channels[0].Pin = "1-2"
+	test.That(t, conf.Validate("path"), test.ShouldNotBeNil)
+	conf.Channels[0].Pin = "4"
+	test.That(t, conf.Validate("path"), test.ShouldNotBeNil)
+	conf.Channels[0].Pin = "0-1"
+	conf.Channels[0].Gain = 3
+	test.That(t, conf.Validate("path"), test.ShouldNotBeNil)
+	conf.Channels[0].Gain = 1
+	conf.Channels[0].DataRate = 100
+	test.That(t, conf.Validate("path"), test.ShouldNotBeNil)
+
+	// the ADS1263 can take any pair of its inputs, but sits on an SPI bus
+	conf.Model = adc.ADS1263
+	conf.Channels[0].Pin = "8-9"
+	test.That(t, conf.Validate("path"), test.ShouldNotBeNil)
+	conf.SPIBus = "0"
+	conf.ChipSelect = "24"
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+	conf.Channels[0].Pin = "8-8"
+	test.That(t, conf.Validate("path"), test.ShouldNotBeNil)
+}
+
+func TestADS1115(t *testing.T) {
+	ctx := context.Background()
+	var config []byte
+	conversions := map[uint16][]byte{0x85E3: {0xC0, 0x00}, 0xE383: {0x40, 0x00}}
+	handle := &inject.I2CHandle{
+		WriteBlockDataFunc: func(ctx context.Context, register byte, data []byte) error {
+			test.That(t, register, test.ShouldEqual, 0x01)
+			config = data
+			return nil
+		},
+		ReadBlockDataFunc: func(ctx context.Context, register byte, numBytes uint8) ([]byte, error) {
+			test.That(t, numBytes, test.ShouldEqual, 2)
+			if register == 0x01 {
+				return []byte{config[0] | 0x80, config[1]}, nil
+			}
+			return conversions[uint16(config[0])<<8|uint16(config[1])], nil
+		},
+		CloseFunc: func() error { return nil },
+	}
+	bus := &inject.I2C{OpenHandleFunc: func(addr byte) (buses.I2CHandle, error) {
+		test.That(t, addr, test.ShouldEqual, 0x49)
+		return handle, nil
+	}}
+
+	conf := adc.Config{
+		Name:       "adc",
+		Model:      adc.ADS1115,
+		I2CBus:     "1",
+		I2CAddress: 0x49,
+		Channels: []adc.ChannelConfig{
+			{Name: "cell", Pin: "0-1", Gain: 2, DataRate: 860, AverageOverMillis: 100, SamplesPerSecond: 50},
+			{Name: "pot", Pin: "2"},
+		},
+	}
+	analogs, smoothing, err := adc.NewAnalogs(ctx, conf, bus, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, analogs, test.ShouldHaveLength, 2)
+	test.That(t, smoothing["cell"].AverageOverMillis, test.ShouldEqual, 100)
+	test.That(t, smoothing["cell"].SamplesPerSecond, test.ShouldEqual, 50)
+
+	// differential channels read negative voltages too
+	val, err := analogs["cell"].Read(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, val.Value, test.ShouldEqual, -16384)
+	test.That(t, val.Min, test.ShouldAlmostEqual, -2.048, 1e-6)
+	test.That(t, val.Max, test.ShouldAlmostEqual, 2.048, 1e-6)
+	test.That(t, float64(val.StepSize), test.ShouldAlmostEqual, 2.048/32768)
+
+	val, err = analogs["pot"].Read(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, val.Value, test.ShouldEqual, 16384)
+	test.That(t, val.Min, test.ShouldEqual, 0)
+	test.That(t, val.Max, test.ShouldAlmostEqual, 4.096, 1e-6)
+}
+
+func TestADS1263(t *testing.T) {
+	ctx := context.Background()
+	var sent [][]byte
+	data := []byte{0x12, 0x34, 0x56, 0x78}
+	checksum := byte((0x12 + 0x34 + 0x56 + 0x78 + 0x9B) & 0xFF)
+	bus := &fakeSPI{xfer: func(tx []byte) []byte {
+		sent = append(sent, tx)
+		if tx[0] == 0x12 {
+			return append(append([]byte{0, 0x40}, data...), checksum)
+		}
+		return make([]byte, len(tx))
+	}}
+
+	conf := adc.Config{
+		Name:       "adc",
+		Model:      adc.ADS1263,
+		SPIBus:     "0",
+		ChipSelect: "24",
+		Channels: []adc.ChannelConfig{
+			{Name: "cell", Pin: "3-4", Gain: 4, DataRate: 38400},
+			{Name: "thermistor", Pin: "5", DataRate: 38400},
+		},
+	}
+	analogs, _, err := adc.NewAnalogs(ctx, conf, nil, bus)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, sent, test.ShouldResemble, [][]byte{{0x06}, {0x42, 1, 0x05, 0x40}})
+
+	sent = nil
+	val, err := analogs["cell"].Read(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, sent[0], test.ShouldResemble, []byte{0x45, 1, 0x2F, 0x34})
+	test.That(t, sent[1], test.ShouldResemble, []byte{0x08})
+	test.That(t, val.Value, test.ShouldEqual, 0x12345678)
+	test.That(t, val.Min, test.ShouldAlmostEqual, -0.625, 1e-6)
+	test.That(t, val.Max, test.ShouldAlmostEqual, 0.625, 1e-6)
+
+	// single-ended channels are measured against AINCOM
+	sent = nil
+	_, err = analogs["thermistor"].Read(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, sent[0], test.ShouldResemble, []byte{0x45, 1, 0x0F, 0x5A})
+
+	checksum++
+	_, err = analogs["cell"].Read(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "checksum")
+}
@@ -0,0 +1,118 @@
+package adc
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+)
+
+// ads1115DefaultAddress is the address of an ADS1115 with its ADDR pin tied to ground.
+const ads1115DefaultAddress = 0x48
+
+// Registers and bits of the ADS1115.
+const (
+	ads1115Conversion = 0x00
+	ads1115Config     = 0x01
+
+	// ads1115Start starts a conversion when written, and reads set once the conversion is done.
+	ads1115Start = 0x8000
+	// ads1115SingleShot powers down between conversions.
+	ads1115SingleShot = 0x0100
+	// ads1115NoComparator disables the comparator, which this does not use.
+	ads1115NoComparator = 0x0003
+)
+
+var (
+	// ads1115Gains are indexed by their PGA bits, which set full scale ranges of ±6.144V, ±4.096V and so on.
+	ads1115Gains = []float64{2. / 3, 1, 2, 4, 8, 16}
+	// ads1115DataRates are indexed by their DR bits.
+	ads1115DataRates = []float64{8, 16, 32, 64, 128, 250, 475, 860}
+)
+
+// ads1115FullScale is the full scale range at a gain of 1, in volts.
+const ads1115FullScale = 4.096
+
+// ads1115 is a 16 bit I2C ADC with 4 inputs, converting one at a time on request.
+type ads1115 struct {
+	bus     buses.I2C
+	address byte
+}
+
+// mux returns the MUX bits of a channel.
+func (adc *ads1115) mux(positive, negative int) uint16 {
+	if negative < 0 {
+		return uint16(0b100 + positive)
+	}
+	switch [2]int{positive, negative} {
+	case [2]int{0, 1}:
+		return 0b000
+	case [2]int{0, 3}:
+		return 0b001
+	case [2]int{1, 3}:
+		return 0b010
+	default: // 2-3, the only pair left after validation
+		return 0b011
+	}
+}
+
+func (adc *ads1115) convert(ctx context.Context, positive, negative, gain, dataRate int) (value int, fullScale float64, err error) {
+	config := ads1115Start | adc.mux(positive, negative)<<12 | uint16(gain)<<9 | ads1115SingleShot |
+		uint16(dataRate)<<5 | ads1115NoComparator
+	if err := adc.transact(ctx, func(h buses.I2CHandle) error {
+		return h.WriteBlockData(ctx, ads1115Config, []byte{byte(config >> 8), byte(config)})
+	}); err != nil {
+		return 0, 0, err
+	}
+
+	// wait out the conversion before polling for it to finish, without holding the bus meanwhile
+	conversionTime := time.Duration(float64(time.Second) / ads1115DataRates[dataRate])
+	if !goutils.SelectContextOrWait(ctx, conversionTime) {
+		return 0, 0, ctx.Err()
+	}
+	var data []byte
+	for attempt := 0; ; attempt++ {
+		done := false
+		if err := adc.transact(ctx, func(h buses.I2CHandle) error {
+			status, err := h.ReadBlockData(ctx, ads1115Config, 2)
+			if err != nil {
+				return err
+			}
+			if done = status[0]&(ads1115Start>>8) != 0; done {
+				data, err = h.ReadBlockData(ctx, ads1115Conversion, 2)
+			}
+			return err
+		}); err != nil {
+			return 0, 0, err
+		}
+		if done {
+			break
+		}
+		if attempt == 10 {
+			return 0, 0, errors.New("ads1115 conversion timed out")
+		}
+		if !goutils.SelectContextOrWait(ctx, conversionTime/10+time.Millisecond) {
+			return 0, 0, ctx.Err()
+		}
+	}
+	return int(int16(uint16(data[0])<<8 | uint16(data[1]))), ads1115FullScale / ads1115Gains[gain], nil
+}
+
+func (adc *ads1115) fullScaleCounts() float64 {
+	return 1 << 15
+}
+
+func (adc *ads1115) transact(ctx context.Context, f func(h buses.I2CHandle) error) (err error) {
+	h, err := adc.bus.OpenHandle(adc.address)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = multierr.Combine(err, h.Close())
+	}()
+	return f(h)
+}
@@ -0,0 +1,127 @@
+package adc
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+)
+
+// ads1263InternalReference is the voltage of the internal reference of the ADS1263.
+const ads1263InternalReference = 2.5
+
+// The SPI bus of the ADS1263 runs in mode 1, well below its 8MHz limit.
+const (
+	ads1263Baud = 1000000
+	ads1263Mode = 1
+)
+
+// Commands, registers and bits of the ADS1263, whose 32 bit ADC1 is the one used.
+const (
+	ads1263Reset = 0x06
+	ads1263Start = 0x08
+	ads1263Read  = 0x12
+	ads1263Write = 0x40
+
+	ads1263Interface = 0x02 // followed by MODE0, so that both are written at once
+	ads1263Mode2     = 0x05 // followed by INPMUX
+
+	// ads1263StatusAndChecksum prefixes conversions with a status byte and follows them with a checksum.
+	ads1263StatusAndChecksum = 0x05
+	// ads1263PulseMode converts once for each start command.
+	ads1263PulseMode = 0x40
+	// ads1263NewData is the bit of the status byte which is set when a conversion was not read yet.
+	ads1263NewData = 0x40
+	// ads1263Common is the input of INPMUX that single-ended channels are measured against.
+	ads1263Common = 0x0A
+	// ads1263ChecksumSeed is added to the sum of the bytes of a conversion to make its checksum.
+	ads1263ChecksumSeed = 0x9B
+)
+
+var (
+	// ads1263Gains are indexed by their GAIN bits.
+	ads1263Gains = []float64{1, 2, 4, 8, 16, 32}
+	// ads1263DataRates are indexed by their DR bits.
+	ads1263DataRates = []float64{2.5, 5, 10, 16.6, 20, 50, 60, 100, 400, 1200, 2400, 4800, 7200, 14400, 19200, 38400}
+)
+
+// ads1263 is a 32 bit SPI ADC with 10 inputs, converting one at a time on request.
+type ads1263 struct {
+	bus        buses.SPI
+	chipSelect string
+	reference  float64
+}
+
+func (adc *ads1263) reset(ctx context.Context) error {
+	if _, err := adc.xfer(ctx, []byte{ads1263Reset}); err != nil {
+		return err
+	}
+	// the chip is ready again after 2^16 clock periods of its 7.3728MHz oscillator
+	if !goutils.SelectContextOrWait(ctx, 10*time.Millisecond) {
+		return ctx.Err()
+	}
+	_, err := adc.xfer(ctx, []byte{ads1263Write | ads1263Interface, 1, ads1263StatusAndChecksum, ads1263PulseMode})
+	return err
+}
+
+func (adc *ads1263) convert(ctx context.Context, positive, negative, gain, dataRate int) (int, float64, error) {
+	muxNegative := ads1263Common
+	if negative >= 0 {
+		muxNegative = negative
+	}
+	mode2 := byte(gain<<4 | dataRate)
+	inpmux := byte(positive<<4 | muxNegative)
+	if _, err := adc.xfer(ctx, []byte{ads1263Write | ads1263Mode2, 1, mode2, inpmux}); err != nil {
+		return 0, 0, err
+	}
+	if _, err := adc.xfer(ctx, []byte{ads1263Start}); err != nil {
+		return 0, 0, err
+	}
+
+	// the digital filter takes a few periods to settle after the input changes, so poll until the conversion is
+	// ready, giving up after several periods
+	period := time.Duration(float64(time.Second) / ads1263DataRates[dataRate])
+	deadline := time.Now().Add(8*period + 10*time.Millisecond)
+	if !goutils.SelectContextOrWait(ctx, period) {
+		return 0, 0, ctx.Err()
+	}
+	for {
+		rx, err := adc.xfer(ctx, []byte{ads1263Read, 0, 0, 0, 0, 0, 0})
+		if err != nil {
+			return 0, 0, err
+		}
+		if rx[1]&ads1263NewData != 0 {
+			data := rx[2:6]
+			if sum := byte(int(data[0]) + int(data[1]) + int(data[2]) + int(data[3]) + ads1263ChecksumSeed); sum != rx[6] {
+				return 0, 0, errors.New("ads1263 conversion failed its checksum")
+			}
+			value := int32(uint32(data[0])<<24 | uint32(data[1])<<16 | uint32(data[2])<<8 | uint32(data[3]))
+			return int(value), adc.reference / ads1263Gains[gain], nil
+		}
+		if time.Now().After(deadline) {
+			return 0, 0, errors.New("ads1263 conversion timed out")
+		}
+		if !goutils.SelectContextOrWait(ctx, period/4+time.Millisecond) {
+			return 0, 0, ctx.Err()
+		}
+	}
+}
+
+func (adc *ads1263) fullScaleCounts() float64 {
+	return 1 << 31
+}
+
+func (adc *ads1263) xfer(ctx context.Context, tx []byte) (rx []byte, err error) {
+	h, err := adc.bus.OpenHandle()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = multierr.Combine(err, h.Close())
+	}()
+	return h.Xfer(ctx, ads1263Baud, adc.chipSelect, ads1263Mode, tx)
+}
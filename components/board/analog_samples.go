@@ -0,0 +1,165 @@
+package board
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+)
+
+// MaxAnalogSamples is the most samples one burst may read, which bounds the memory and the time it takes.
+const MaxAnalogSamples = 1000000
+
+// readAnalogSamplesCommand is the command which carries ReadAnalogSamples over DoCommand, since the board service
+// has no method for it.
+const readAnalogSamplesCommand = "read_analog_samples"
+
+// AnalogSamples is a burst of samples of an analog, read at a fixed rate.
+type AnalogSamples struct {
+	// Values are the readings in bits, and Timestamps are when each was sampled.
+	Values     []int
+	Timestamps []time.Time
+	// Min, Max and StepSize are as they are in an AnalogValue.
+	Min      float32
+	Max      float32
+	StepSize float32
+}
+
+// An AnalogSampler is an analog which can read a burst of samples at a fixed rate with steadier timing than polling
+// Read, such as by DMA or by holding the bus for the whole burst. Bursts are needed for vibration analysis and motor
+// commutation diagnostics, which look at a signal rather than a value.
+type AnalogSampler interface {
+	// ReadSamples reads count samples, rateHz times a second, returning once all are read.
+	//
+	//    myBoard, err := board.FromRobot(machine, "my_board")
+	//    analog, err := myBoard.AnalogByName("my_example_analog")
+	//    // Read a tenth of a second of a signal at 10kHz.
+	//    samples, err := board.ReadAnalogSamples(context.Background(), analog, 1000, 10000, nil)
+	ReadSamples(ctx context.Context, count int, rateHz float64, extra map[string]interface{}) (AnalogSamples, error)
+}
+
+// ValidateAnalogSampling returns an error if a burst of count samples at rateHz cannot be read, for AnalogSamplers to
+// check their arguments with.
+func ValidateAnalogSampling(count int, rateHz float64) error {
+	if count <= 0 || count > MaxAnalogSamples {
+		return errors.Errorf("sample count must be between 1 and %d, got %d", MaxAnalogSamples, count)
+	}
+	if rateHz <= 0 {
+		return errors.Errorf("sample rate must be positive, got %v", rateHz)
+	}
+	return nil
+}
+
+// ReadAnalogSamples reads a burst of samples of the analog, with its AnalogSampler if it is one or by polling
+// Read otherwise.
+func ReadAnalogSamples(
+	ctx context.Context, a Analog, count int, rateHz float64, extra map[string]interface{},
+) (AnalogSamples, error) {
+	if err := ValidateAnalogSampling(count, rateHz); err != nil {
+		return AnalogSamples{}, err
+	}
+	if sampler, ok := a.(AnalogSampler); ok {
+		return sampler.ReadSamples(ctx, count, rateHz, extra)
+	}
+	return PollAnalogSamples(ctx, a, count, rateHz, extra)
+}
+
+// PollAnalogSamples reads a burst of samples by calling Read at the rate, for analogs with no faster way. The
+// timing is only as steady as the scheduler, so each sample is timestamped when it is read rather than when it was
+// due, and samples due while a slow Read is still going are read late rather than skipped.
+func PollAnalogSamples(
+	ctx context.Context, a Analog, count int, rateHz float64, extra map[string]interface{},
+) (AnalogSamples, error) {
+	if err := ValidateAnalogSampling(count, rateHz); err != nil {
+		return AnalogSamples{}, err
+	}
+	samples := AnalogSamples{Values: make([]int, 0, count), Timestamps: make([]time.Time, 0, count)}
+	interval := time.Duration(float64(time.Second) / rateHz)
+	start := time.Now()
+	for i := 0; i < count; i++ {
+		if wait := time.Until(start.Add(time.Duration(i) * interval)); wait > 0 {
+			if !utils.SelectContextOrWait(ctx, wait) {
+				return AnalogSamples{}, ctx.Err()
+			}
+		}
+		value, err := a.Read(ctx, extra)
+		if err != nil {
+			return AnalogSamples{}, err
+		}
+		samples.Values = append(samples.Values, value.Value)
+		samples.Timestamps = append(samples.Timestamps, time.Now())
+		samples.Min, samples.Max, samples.StepSize = value.Min, value.Max, value.StepSize
+	}
+	return samples, nil
+}
+
+// analogSamplesToCommand and analogSamplesFromCommand carry samples over DoCommand. The timestamps are sent as
+// offsets in nanoseconds from the first, since whole timestamps in nanoseconds lose precision as JSON numbers.
+func analogSamplesToCommand(samples AnalogSamples) map[string]interface{} {
+	values := make([]interface{}, 0, len(samples.Values))
+	for _, v := range samples.Values {
+		values = append(values, v)
+	}
+	offsets := make([]interface{}, 0, len(samples.Timestamps))
+	var start time.Time
+	if len(samples.Timestamps) > 0 {
+		start = samples.Timestamps[0]
+	}
+	for _, ts := range samples.Timestamps {
+		offsets = append(offsets, ts.Sub(start).Nanoseconds())
+	}
+	return map[string]interface{}{
+		"values":     values,
+		"start":      start.Format(time.RFC3339Nano),
+		"offsets_ns": offsets,
+		"min":        samples.Min,
+		"max":        samples.Max,
+		"step_size":  samples.StepSize,
+	}
+}
+
+func analogSamplesFromCommand(resp map[string]interface{}) (AnalogSamples, error) {
+	values, okValues := resp["values"].([]interface{})
+	offsets, okOffsets := resp["offsets_ns"].([]interface{})
+	startStr, okStart := resp["start"].(string)
+	if !okValues || !okOffsets || !okStart || len(values) != len(offsets) {
+		return AnalogSamples{}, errors.New("malformed analog samples")
+	}
+	start, err := time.Parse(time.RFC3339Nano, startStr)
+	if err != nil {
+		return AnalogSamples{}, err
+	}
+	samples := AnalogSamples{Values: make([]int, 0, len(values)), Timestamps: make([]time.Time, 0, len(offsets))}
+	for i := range values {
+		value, okValue := values[i].(float64)
+		offset, okOffset := offsets[i].(float64)
+		if !okValue || !okOffset {
+			return AnalogSamples{}, errors.New("malformed analog samples")
+		}
+		samples.Values = append(samples.Values, int(value))
+		samples.Timestamps = append(samples.Timestamps, start.Add(time.Duration(offset)))
+	}
+	minRange, _ := resp["min"].(float64)
+	maxRange, _ := resp["max"].(float64)
+	stepSize, _ := resp["step_size"].(float64)
+	samples.Min, samples.Max, samples.StepSize = float32(minRange), float32(maxRange), float32(stepSize)
+	return samples, nil
+}
+
+// readAnalogSamplesFromCommand reads the samples a DoCommand asks for of the board's analogs.
+func readAnalogSamplesFromCommand(ctx context.Context, b Board, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, _ := cmd["analog"].(string)
+	count, _ := cmd["count"].(float64)
+	rateHz, _ := cmd["rate_hz"].(float64)
+	extra, _ := cmd["extra"].(map[string]interface{})
+	a, err := b.AnalogByName(name)
+	if err != nil {
+		return nil, err
+	}
+	samples, err := ReadAnalogSamples(ctx, a, int(count), rateHz, extra)
+	if err != nil {
+		return nil, err
+	}
+	return analogSamplesToCommand(samples), nil
+}
@@ -0,0 +1,63 @@
+package board_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestReadAnalogSamples(t *testing.T) {
+	reads := 0
+	analog := &inject.Analog{
+		ReadFunc: func(ctx context.Context, extra map[string]interface{}) (board.AnalogValue, error) {
+			reads++
+			return board.AnalogValue{Value: reads, Max: 1023, StepSize: 1}, nil
+		},
+	}
+
+	t.Run("polls an analog which cannot sample", func(t *testing.T) {
+		start := time.Now()
+		samples, err := board.ReadAnalogSamples(context.Background(), analog, 5, 100, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 40*time.Millisecond)
+		test.That(t, samples.Values, test.ShouldResemble, []int{1, 2, 3, 4, 5})
+		test.That(t, samples.Max, test.ShouldEqual, 1023)
+		test.That(t, samples.Timestamps, test.ShouldHaveLength, 5)
+		for i := 1; i < len(samples.Timestamps); i++ {
+			test.That(t, samples.Timestamps[i].After(samples.Timestamps[i-1]), test.ShouldBeTrue)
+		}
+	})
+
+	t.Run("uses the sampler of an analog which can", func(t *testing.T) {
+		analog.ReadSamplesFunc = func(
+			ctx context.Context, count int, rateHz float64, extra map[string]interface{},
+		) (board.AnalogSamples, error) {
+			return board.AnalogSamples{Values: make([]int, count), Timestamps: make([]time.Time, count)}, nil
+		}
+		defer func() { analog.ReadSamplesFunc = nil }()
+		samples, err := board.ReadAnalogSamples(context.Background(), analog, 1000, 1e6, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, samples.Values, test.ShouldHaveLength, 1000)
+	})
+
+	t.Run("bad arguments", func(t *testing.T) {
+		_, err := board.ReadAnalogSamples(context.Background(), analog, 0, 100, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = board.ReadAnalogSamples(context.Background(), analog, board.MaxAnalogSamples+1, 100, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = board.ReadAnalogSamples(context.Background(), analog, 10, 0, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := board.PollAnalogSamples(ctx, analog, 100, 10, nil)
+		test.That(t, err, test.ShouldBeError, context.DeadlineExceeded)
+	})
+}
@@ -31,6 +31,14 @@ func init() {
 		API:        API,
 		MethodName: gpios.String(),
 	}, newGPIOCollector)
+	data.RegisterCollectorParams(data.MethodMetadata{
+		API:        API,
+		MethodName: analogs.String(),
+	}, data.MethodParamSpec{Name: analogReaderNameKey, Required: true})
+	data.RegisterCollectorParams(data.MethodMetadata{
+		API:        API,
+		MethodName: gpios.String(),
+	}, data.MethodParamSpec{Name: gpioPinNameKey, Required: true})
 }
 
 // SubtypeName is a constant that identifies the component resource API string "board".
@@ -147,6 +147,22 @@ func (ac *analogClient) Read(ctx context.Context, extra map[string]interface{})
 	return AnalogValue{Value: int(resp.Value), Min: resp.MinRange, Max: resp.MaxRange, StepSize: resp.StepSize}, nil
 }
 
+func (ac *analogClient) ReadSamples(
+	ctx context.Context, count int, rateHz float64, extra map[string]interface{},
+) (AnalogSamples, error) {
+	resp, err := ac.client.DoCommand(ctx, map[string]interface{}{
+		"command": readAnalogSamplesCommand,
+		"analog":  ac.analogName,
+		"count":   count,
+		"rate_hz": rateHz,
+		"extra":   extra,
+	})
+	if err != nil {
+		return AnalogSamples{}, err
+	}
+	return analogSamplesFromCommand(resp)
+}
+
 func (ac *analogClient) Write(ctx context.Context, value int, extra map[string]interface{}) error {
 	ext, err := protoutils.StructToStructPb(extra)
 	if err != nil {
@@ -166,6 +166,34 @@ func TestWorkingClient(t *testing.T) {
 		test.That(t, actualExtra, test.ShouldResemble, expectedExtra)
 		actualExtra = nil
 
+		// Analog: ReadSamples
+		start := time.Now()
+		injectAnalog.ReadSamplesFunc = func(
+			ctx context.Context, count int, rateHz float64, extra map[string]interface{},
+		) (board.AnalogSamples, error) {
+			actualExtra = extra
+			samples := board.AnalogSamples{Max: 10, StepSize: 0.1}
+			for i := 0; i < count; i++ {
+				samples.Values = append(samples.Values, i)
+				samples.Timestamps = append(samples.Timestamps, start.Add(time.Duration(float64(i)/rateHz*float64(time.Second))))
+			}
+			return samples, nil
+		}
+		clientAnalog, err := client.AnalogByName("analog1")
+		test.That(t, err, test.ShouldBeNil)
+		samples, err := board.ReadAnalogSamples(context.Background(), clientAnalog, 3, 1000, expectedExtra)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, samples.Values, test.ShouldResemble, []int{0, 1, 2})
+		test.That(t, samples.Timestamps, test.ShouldHaveLength, 3)
+		test.That(t, samples.Timestamps[0].Equal(start), test.ShouldBeTrue)
+		test.That(t, samples.Timestamps[2].Sub(samples.Timestamps[0]), test.ShouldEqual, 2*time.Millisecond)
+		test.That(t, samples.Max, test.ShouldEqual, 10)
+		test.That(t, samples.StepSize, test.ShouldEqual, 0.1)
+		test.That(t, actualExtra, test.ShouldResemble, expectedExtra)
+		actualExtra = nil
+		_, err = board.ReadAnalogSamples(context.Background(), clientAnalog, 0, 1000, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+
 		// Digital Interrupt
 		injectDigitalInterrupt := &inject.DigitalInterrupt{}
 		injectBoard.DigitalInterruptByNameFunc = func(name string) (board.DigitalInterrupt, error) {
@@ -0,0 +1,131 @@
+package expander
+
+import (
+	"context"
+	"fmt"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+)
+
+// The models of the supported expanders.
+const (
+	MCP23017 = "mcp23017"
+	PCF8574  = "pcf8574"
+)
+
+// A chip speaks the protocol of a model of expander. Its pins are addressed by bit, in masks where the
+// lowest bit is the first pin.
+type chip interface {
+	// pinNames returns the names of the pins of the chip, as its datasheet labels them.
+	pinNames() []string
+	// write sets which pins are inputs, the levels of the outputs and which pins raise interrupts.
+	write(ctx context.Context, h buses.I2CHandle, inputs, latch, interrupts uint16) error
+	// read returns the levels of all pins, and clears any pending interrupt.
+	read(ctx context.Context, h buses.I2CHandle) (uint16, error)
+}
+
+// PinNames returns the names of the pins of a model of expander, as its datasheet labels them.
+func PinNames(model string) []string {
+	c := newChip(model)
+	if c == nil {
+		return nil
+	}
+	return c.pinNames()
+}
+
+func newChip(model string) chip {
+	switch model {
+	case MCP23017:
+		return &mcp23017{}
+	case PCF8574:
+		return &pcf8574{}
+	default:
+		return nil
+	}
+}
+
+// Registers of the MCP23017 in its default bank 0 layout, where the register of port B follows that of port A.
+const (
+	mcpIODIR   = 0x00
+	mcpGPINTEN = 0x04
+	mcpIOCON   = 0x0A
+	mcpGPIO    = 0x12
+	mcpOLAT    = 0x14
+
+	// mcpMirror ties the interrupt outputs of both ports together, so that one INT line serves all 16 pins.
+	mcpMirror = 0x40
+)
+
+// mcp23017 is a 16 pin expander whose pins are set by registers. It remembers what it last wrote so that
+// only registers which change are written again.
+type mcp23017 struct {
+	initialized bool
+	registers   map[byte]uint16
+}
+
+func (c *mcp23017) pinNames() []string {
+	names := make([]string, 0, 16)
+	for _, port := range []string{"gpa", "gpb"} {
+		for i := 0; i < 8; i++ {
+			names = append(names, fmt.Sprintf("%s%d", port, i))
+		}
+	}
+	return names
+}
+
+func (c *mcp23017) write(ctx context.Context, h buses.I2CHandle, inputs, latch, interrupts uint16) error {
+	if !c.initialized {
+		if err := h.WriteByteData(ctx, mcpIOCON, mcpMirror); err != nil {
+			return err
+		}
+		c.registers = map[byte]uint16{}
+		c.initialized = true
+	}
+	// the latch goes first so that pins which become outputs start at the right level
+	for _, reg := range []struct {
+		addr  byte
+		value uint16
+	}{{mcpOLAT, latch}, {mcpIODIR, inputs}, {mcpGPINTEN, interrupts}} {
+		if written, ok := c.registers[reg.addr]; ok && written == reg.value {
+			continue
+		}
+		if err := h.WriteBlockData(ctx, reg.addr, []byte{byte(reg.value), byte(reg.value >> 8)}); err != nil {
+			return err
+		}
+		c.registers[reg.addr] = reg.value
+	}
+	return nil
+}
+
+func (c *mcp23017) read(ctx context.Context, h buses.I2CHandle) (uint16, error) {
+	data, err := h.ReadBlockData(ctx, mcpGPIO, 2)
+	if err != nil {
+		return 0, err
+	}
+	return uint16(data[0]) | uint16(data[1])<<8, nil
+}
+
+// pcf8574 is an 8 pin expander without registers: a byte written sets its pins, and a byte read gets them.
+// Its pins are quasi-bidirectional, so inputs are pins written high, which a weak pull-up holds high unless
+// something drives them low. Every input raises interrupts.
+type pcf8574 struct{}
+
+func (c *pcf8574) pinNames() []string {
+	names := make([]string, 0, 8)
+	for i := 0; i < 8; i++ {
+		names = append(names, fmt.Sprintf("p%d", i))
+	}
+	return names
+}
+
+func (c *pcf8574) write(ctx context.Context, h buses.I2CHandle, inputs, latch, interrupts uint16) error {
+	return h.Write(ctx, []byte{byte(latch | inputs)})
+}
+
+func (c *pcf8574) read(ctx context.Context, h buses.I2CHandle) (uint16, error) {
+	data, err := h.Read(ctx, 1)
+	if err != nil {
+		return 0, err
+	}
+	return uint16(data[0]), nil
+}
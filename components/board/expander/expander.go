@@ -0,0 +1,372 @@
+// Package expander is shared code for hooking I2C GPIO expanders, such as the MCP23017 and the PCF8574, up to a
+// board, whose pins then appear among those of the board itself. It is used by the genericlinux boards, but does not
+// implement a board directly.
+package expander
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/components/board/pinwrappers"
+	"go.viam.com/rdk/resource"
+)
+
+// defaultAddress is the address of both models with their address pins tied low.
+const defaultAddress = 0x20
+
+// Config describes the configuration of an expander on a board. Its pins are named on the board by the name of the
+// expander followed by an underscore and the name of the pin on the chip, such as "io_gpa0" for the first pin of an
+// MCP23017 named "io", or "io_p0" for that of a PCF8574.
+type Config struct {
+	Name       string `json:"name"`
+	Model      string `json:"model"`
+	I2CBus     string `json:"i2c_bus"`
+	I2CAddress int    `json:"i2c_address,omitempty"`
+	// InterruptPin is the pin of the board the INT output of the expander is wired to. Digital interrupts on the pins
+	// of the expander need it.
+	InterruptPin string `json:"interrupt_pin,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (config *Config) Validate(path string) error {
+	if config.Name == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "name")
+	}
+	if config.Model == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "model")
+	}
+	if newChip(config.Model) == nil {
+		return resource.NewConfigValidationError(path,
+			errors.Errorf("unsupported expander model %q, must be %q or %q", config.Model, MCP23017, PCF8574))
+	}
+	if config.I2CBus == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "i2c_bus")
+	}
+	if config.I2CAddress < 0 || config.I2CAddress > 0x7F {
+		return resource.NewConfigValidationError(path, errors.New("i2c_address must be a 7 bit address"))
+	}
+	return nil
+}
+
+// PinName returns the name on the board of a pin of the expander.
+func (config *Config) PinName(pin string) string {
+	return config.Name + "_" + pin
+}
+
+// An Expander is a GPIO expander on an I2C bus of a board.
+type Expander struct {
+	conf    Config
+	bus     buses.I2C
+	address byte
+
+	mu   sync.Mutex
+	chip chip
+	pins map[string]int // the bits of the pins, by their names on the board
+	// masks of the pins which are inputs, of the levels of the outputs, of the pins which raise interrupts, and of
+	// the levels of all pins when they were last read.
+	inputs, latch, interruptMask, levels uint16
+	interrupts                           map[string]*digitalInterrupt
+}
+
+type digitalInterrupt struct {
+	bit       int
+	interrupt pinwrappers.ReconfigurableDigitalInterrupt
+}
+
+// New returns an expander on the given bus, with all of its pins inputs.
+func New(ctx context.Context, conf Config, bus buses.I2C) (*Expander, error) {
+	c := newChip(conf.Model)
+	if c == nil {
+		return nil, errors.Errorf("unsupported expander model %q", conf.Model)
+	}
+	address := byte(defaultAddress)
+	if conf.I2CAddress != 0 {
+		address = byte(conf.I2CAddress)
+	}
+	e := &Expander{
+		conf:       conf,
+		bus:        bus,
+		address:    address,
+		chip:       c,
+		pins:       map[string]int{},
+		interrupts: map[string]*digitalInterrupt{},
+	}
+	for bit, name := range c.pinNames() {
+		e.pins[conf.PinName(name)] = bit
+		e.inputs |= 1 << bit
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.sync(ctx); err != nil {
+		return nil, errors.Wrapf(err, "cannot initialize expander %q", conf.Name)
+	}
+	return e, nil
+}
+
+// Config returns the config the expander was created with.
+func (e *Expander) Config() Config {
+	return e.conf
+}
+
+// PinNames returns the names on the board of the pins of the expander.
+func (e *Expander) PinNames() []string {
+	names := make([]string, 0, len(e.pins))
+	for name := range e.pins {
+		names = append(names, name)
+	}
+	return names
+}
+
+// HasPin returns whether the expander has a pin of the given name on the board.
+func (e *Expander) HasPin(name string) bool {
+	_, ok := e.pins[name]
+	return ok
+}
+
+// GPIOPinByName returns a pin of the expander by its name on the board.
+func (e *Expander) GPIOPinByName(name string) (board.GPIOPin, error) {
+	bit, ok := e.pins[name]
+	if !ok {
+		return nil, errors.Errorf("expander %q has no pin %s", e.conf.Name, name)
+	}
+	return &gpioPin{expander: e, name: name, bit: bit}, nil
+}
+
+// DigitalInterruptByName returns a digital interrupt on a pin of the expander by the name of the interrupt.
+func (e *Expander) DigitalInterruptByName(name string) (pinwrappers.ReconfigurableDigitalInterrupt, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	di, ok := e.interrupts[name]
+	if !ok {
+		return nil, false
+	}
+	return di.interrupt, true
+}
+
+// DigitalInterruptNames returns the names of the digital interrupts on pins of the expander.
+func (e *Expander) DigitalInterruptNames() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	names := make([]string, 0, len(e.interrupts))
+	for name := range e.interrupts {
+		names = append(names, name)
+	}
+	return names
+}
+
+// AddDigitalInterrupt turns a pin of the expander into an input which raises a digital interrupt. If holder is not
+// nil, it is reconfigured and reused, so that anything subscribed to it stays subscribed.
+func (e *Expander) AddDigitalInterrupt(
+	ctx context.Context,
+	config board.DigitalInterruptConfig,
+	holder pinwrappers.ReconfigurableDigitalInterrupt,
+) (pinwrappers.ReconfigurableDigitalInterrupt, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	di, err := e.newDigitalInterrupt(config, holder)
+	if err != nil {
+		return nil, err
+	}
+	e.interrupts[config.Name] = di
+	if err := e.sync(ctx); err != nil {
+		return nil, err
+	}
+	return di.interrupt, nil
+}
+
+// ReconfigureDigitalInterrupts replaces the digital interrupts on pins of the expander with those configured. Those
+// which were created on the fly, named after their pin, are kept unless their name is now configured. Holders are
+// reused by name, so that anything subscribed to an interrupt stays subscribed, even if it moves between expanders.
+func (e *Expander) ReconfigureDigitalInterrupts(
+	ctx context.Context,
+	configs []board.DigitalInterruptConfig,
+	holders map[string]pinwrappers.ReconfigurableDigitalInterrupt,
+) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	interrupts := map[string]*digitalInterrupt{}
+	for _, config := range configs {
+		holder := holders[config.Name]
+		if old, ok := e.interrupts[config.Name]; ok {
+			holder = old.interrupt
+		}
+		di, err := e.newDigitalInterrupt(config, holder)
+		if err != nil {
+			return err
+		}
+		interrupts[config.Name] = di
+	}
+	for name, old := range e.interrupts {
+		if _, configured := interrupts[name]; !configured && name == old.interrupt.Name() && e.HasPin(name) {
+			interrupts[name] = old
+		}
+	}
+	e.interrupts = interrupts
+	return e.sync(ctx)
+}
+
+// This is a private helper function that should only be called when the mutex is locked.
+func (e *Expander) newDigitalInterrupt(
+	config board.DigitalInterruptConfig,
+	holder pinwrappers.ReconfigurableDigitalInterrupt,
+) (*digitalInterrupt, error) {
+	bit, ok := e.pins[config.Pin]
+	if !ok {
+		return nil, errors.Errorf("expander %q has no pin %s", e.conf.Name, config.Pin)
+	}
+	if e.conf.InterruptPin == "" {
+		return nil, errors.Errorf(
+			"expander %q needs an interrupt_pin for the digital interrupt on pin %s", e.conf.Name, config.Pin)
+	}
+	if holder == nil {
+		interrupt, err := pinwrappers.CreateDigitalInterrupt(config)
+		if err != nil {
+			return nil, err
+		}
+		return &digitalInterrupt{bit: bit, interrupt: interrupt}, nil
+	}
+	if err := holder.Reconfigure(config); err != nil {
+		return nil, err
+	}
+	return &digitalInterrupt{bit: bit, interrupt: holder}, nil
+}
+
+// HandleInterrupt reads the pins of the expander, and ticks the digital interrupts on those which changed since they
+// were last read. It is called whenever the INT output of the expander falls.
+func (e *Expander) HandleInterrupt(ctx context.Context, timestampNanosec uint64) error {
+	e.mu.Lock()
+	previous := e.levels
+	if err := e.read(ctx); err != nil {
+		e.mu.Unlock()
+		return err
+	}
+	changed := (previous ^ e.levels) & e.interruptMask
+	type tick struct {
+		interrupt *pinwrappers.BasicDigitalInterrupt
+		high      bool
+	}
+	var ticks []tick
+	for _, di := range e.interrupts {
+		if changed&(1<<di.bit) != 0 {
+			ticks = append(ticks, tick{di.interrupt.(*pinwrappers.BasicDigitalInterrupt), e.levels&(1<<di.bit) != 0})
+		}
+	}
+	e.mu.Unlock()
+
+	// ticking blocks until every subscriber receives it, so the expander must not be locked meanwhile
+	var err error
+	for _, t := range ticks {
+		err = multierr.Combine(err, pinwrappers.Tick(ctx, t.interrupt, t.high, timestampNanosec))
+	}
+	return err
+}
+
+// This is a private helper function that should only be called when the mutex is locked. It writes the state of the
+// pins to the chip, and reads back their levels.
+func (e *Expander) sync(ctx context.Context) (err error) {
+	e.interruptMask = 0
+	for _, di := range e.interrupts {
+		e.interruptMask |= 1 << di.bit
+		e.inputs |= 1 << di.bit
+	}
+	h, err := e.bus.OpenHandle(e.address)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = multierr.Combine(err, h.Close())
+	}()
+	if err := e.chip.write(ctx, h, e.inputs, e.latch, e.interruptMask); err != nil {
+		return err
+	}
+	levels, err := e.chip.read(ctx, h)
+	if err != nil {
+		return err
+	}
+	e.levels = levels
+	return nil
+}
+
+// This is a private helper function that should only be called when the mutex is locked.
+func (e *Expander) read(ctx context.Context) (err error) {
+	h, err := e.bus.OpenHandle(e.address)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = multierr.Combine(err, h.Close())
+	}()
+	levels, err := e.chip.read(ctx, h)
+	if err != nil {
+		return err
+	}
+	e.levels = levels
+	return nil
+}
+
+// gpioPin is a pin of an expander. Like the pins of the board itself, setting it makes it an output and getting it
+// makes it an input.
+type gpioPin struct {
+	expander *Expander
+	name     string
+	bit      int
+}
+
+func (pin *gpioPin) wrapError(err error) error {
+	return errors.Wrapf(err, "from pin %s of expander %q", pin.name, pin.expander.conf.Name)
+}
+
+func (pin *gpioPin) Set(ctx context.Context, high bool, extra map[string]interface{}) error {
+	e := pin.expander
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	mask := uint16(1) << pin.bit
+	if e.interruptMask&mask != 0 {
+		return fmt.Errorf("cannot set value of pin %s, which is a digital interrupt", pin.name)
+	}
+	e.inputs &^= mask
+	if high {
+		e.latch |= mask
+	} else {
+		e.latch &^= mask
+	}
+	return pin.wrapError(e.sync(ctx))
+}
+
+func (pin *gpioPin) Get(ctx context.Context, extra map[string]interface{}) (bool, error) {
+	e := pin.expander
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	mask := uint16(1) << pin.bit
+	e.inputs |= mask
+	if err := e.sync(ctx); err != nil {
+		return false, pin.wrapError(err)
+	}
+	return e.levels&mask != 0, nil
+}
+
+func (pin *gpioPin) PWM(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	return 0, errors.New("expander pins do not support PWM")
+}
+
+func (pin *gpioPin) SetPWM(ctx context.Context, dutyCyclePct float64, extra map[string]interface{}) error {
+	return errors.New("expander pins do not support PWM")
+}
+
+func (pin *gpioPin) PWMFreq(ctx context.Context, extra map[string]interface{}) (uint, error) {
+	return 0, errors.New("expander pins do not support PWM")
+}
+
+func (pin *gpioPin) SetPWMFreq(ctx context.Context, freqHz uint, extra map[string]interface{}) error {
+	return errors.New("expander pins do not support PWM")
+}
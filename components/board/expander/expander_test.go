@@ -0,0 +1,175 @@
+package expander_test
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/board/expander"
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// fakeMCP23017 simulates the registers of an MCP23017, whose inputs are at the levels of pins.
+func fakeMCP23017(t *testing.T) (*inject.I2C, map[byte]byte, *uint16) {
+	t.Helper()
+	registers := map[byte]byte{0x00: 0xFF, 0x01: 0xFF}
+	var pins uint16
+	handle := &inject.I2CHandle{
+		WriteByteDataFunc: func(ctx context.Context, register, data byte) error {
+			registers[register] = data
+			return nil
+		},
+		WriteBlockDataFunc: func(ctx context.Context, register byte, data []byte) error {
+			for i, b := range data {
+				registers[register+byte(i)] = b
+			}
+			return nil
+		},
+		ReadBlockDataFunc: func(ctx context.Context, register byte, numBytes uint8) ([]byte, error) {
+			test.That(t, register, test.ShouldEqual, 0x12)
+			dir := uint16(registers[0x00]) | uint16(registers[0x01])<<8
+			latch := uint16(registers[0x14]) | uint16(registers[0x15])<<8
+			levels := pins&dir | latch&^dir
+			return []byte{byte(levels), byte(levels >> 8)}, nil
+		},
+		CloseFunc: func() error { return nil },
+	}
+	bus := &inject.I2C{OpenHandleFunc: func(addr byte) (buses.I2CHandle, error) {
+		test.That(t, addr, test.ShouldEqual, 0x21)
+		return handle, nil
+	}}
+	return bus, registers, &pins
+}
+
+func TestValidate(t *testing.T) {
+	conf := expander.Config{Name: "io", Model: expander.MCP23017, I2CBus: "1"}
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+
+	conf.Model = "mcp23008"
+	test.That(t, conf.Validate("path"), test.ShouldNotBeNil)
+
+	conf.Model = expander.PCF8574
+	conf.I2CAddress = 0x80
+	test.That(t, conf.Validate("path"), test.ShouldNotBeNil)
+
+	conf.I2CAddress = 0x38
+	conf.I2CBus = ""
+	test.That(t, conf.Validate("path"), test.ShouldNotBeNil)
+}
+
+func TestMCP23017(t *testing.T) {
+	ctx := context.Background()
+	bus, registers, pins := fakeMCP23017(t)
+	conf := expander.Config{Name: "io", Model: expander.MCP23017, I2CBus: "1", I2CAddress: 0x21, InterruptPin: "7"}
+	e, err := expander.New(ctx, conf, bus)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, registers[0x0A], test.ShouldEqual, 0x40)
+	test.That(t, e.PinNames(), test.ShouldHaveLength, 16)
+	test.That(t, e.HasPin("io_gpb7"), test.ShouldBeTrue)
+	test.That(t, e.HasPin("io_p0"), test.ShouldBeFalse)
+
+	t.Run("outputs", func(t *testing.T) {
+		pin, err := e.GPIOPinByName("io_gpb1")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pin.Set(ctx, true, nil), test.ShouldBeNil)
+		test.That(t, registers[0x01], test.ShouldEqual, 0xFD)
+		test.That(t, registers[0x15], test.ShouldEqual, 0x02)
+
+		high, err := pin.Get(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, high, test.ShouldBeFalse)
+		test.That(t, registers[0x01], test.ShouldEqual, 0xFF)
+
+		_, err = e.GPIOPinByName("io_gpc0")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, pin.SetPWM(ctx, 0.5, nil), test.ShouldNotBeNil)
+	})
+
+	t.Run("inputs", func(t *testing.T) {
+		*pins = 0x0001
+		pin, err := e.GPIOPinByName("io_gpa0")
+		test.That(t, err, test.ShouldBeNil)
+		high, err := pin.Get(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, high, test.ShouldBeTrue)
+	})
+
+	t.Run("interrupts", func(t *testing.T) {
+		err := e.ReconfigureDigitalInterrupts(ctx,
+			[]board.DigitalInterruptConfig{{Name: "button", Pin: "io_gpa3"}}, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, registers[0x04], test.ShouldEqual, 0x08)
+		test.That(t, e.DigitalInterruptNames(), test.ShouldResemble, []string{"button"})
+
+		interrupt, ok := e.DigitalInterruptByName("button")
+		test.That(t, ok, test.ShouldBeTrue)
+		pin, err := e.GPIOPinByName("io_gpa3")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pin.Set(ctx, true, nil), test.ShouldNotBeNil)
+
+		// a change on a pin without an interrupt does not tick
+		*pins = 0x0000
+		test.That(t, e.HandleInterrupt(ctx, 1), test.ShouldBeNil)
+		*pins = 0x0008
+		test.That(t, e.HandleInterrupt(ctx, 2), test.ShouldBeNil)
+		*pins = 0x0000
+		test.That(t, e.HandleInterrupt(ctx, 3), test.ShouldBeNil)
+		count, err := interrupt.Value(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, count, test.ShouldEqual, 1)
+
+		// interrupts created on the fly are kept, and configured ones which are gone are removed
+		implicit, err := e.AddDigitalInterrupt(ctx, board.DigitalInterruptConfig{Name: "io_gpa4", Pin: "io_gpa4"}, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, registers[0x04], test.ShouldEqual, 0x18)
+		test.That(t, e.ReconfigureDigitalInterrupts(ctx, nil, nil), test.ShouldBeNil)
+		test.That(t, registers[0x04], test.ShouldEqual, 0x10)
+		kept, ok := e.DigitalInterruptByName("io_gpa4")
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, kept, test.ShouldEqual, implicit)
+	})
+}
+
+func TestPCF8574(t *testing.T) {
+	ctx := context.Background()
+	var written []byte
+	pins := byte(0xFF)
+	handle := &inject.I2CHandle{
+		WriteFunc: func(ctx context.Context, tx []byte) error {
+			written = tx
+			return nil
+		},
+		ReadFunc: func(ctx context.Context, count int) ([]byte, error) {
+			return []byte{pins & written[0]}, nil
+		},
+		CloseFunc: func() error { return nil },
+	}
+	bus := &inject.I2C{OpenHandleFunc: func(addr byte) (buses.I2CHandle, error) {
+		test.That(t, addr, test.ShouldEqual, 0x20)
+		return handle, nil
+	}}
+	e, err := expander.New(ctx, expander.Config{Name: "io", Model: expander.PCF8574, I2CBus: "1"}, bus)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, written, test.ShouldResemble, []byte{0xFF})
+	test.That(t, e.PinNames(), test.ShouldHaveLength, 8)
+
+	pin, err := e.GPIOPinByName("io_p2")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pin.Set(ctx, false, nil), test.ShouldBeNil)
+	test.That(t, written, test.ShouldResemble, []byte{0xFB})
+
+	pins = 0xFE
+	pin, err = e.GPIOPinByName("io_p0")
+	test.That(t, err, test.ShouldBeNil)
+	high, err := pin.Get(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, high, test.ShouldBeFalse)
+
+	// digital interrupts need the INT line
+	_, err = e.AddDigitalInterrupt(ctx, board.DigitalInterruptConfig{Name: "io_p3", Pin: "io_p3"}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "interrupt_pin")
+}
@@ -4,6 +4,7 @@ package fake
 import (
 	"context"
 	"fmt"
+	"math"
 	"math/rand"
 	"reflect"
 	"sync"
@@ -12,8 +13,10 @@ import (
 	"github.com/pkg/errors"
 	"go.uber.org/multierr"
 	pb "go.viam.com/api/component/board/v1"
+	"go.viam.com/utils"
 
 	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/fakeconditions"
 	"go.viam.com/rdk/grpc"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
@@ -34,6 +37,9 @@ type Config struct {
 	AnalogReaders     []board.AnalogReaderConfig     `json:"analogs,omitempty"`
 	DigitalInterrupts []board.DigitalInterruptConfig `json:"digital_interrupts,omitempty"`
 	FailNew           bool                           `json:"fail_new"`
+
+	// Conditions simulates the latency of every pin and the noise of analog readings.
+	fakeconditions.Config `json:",squash"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -52,6 +58,9 @@ func (conf *Config) Validate(path string) ([]string, error) {
 	if conf.FailNew {
 		return nil, errors.New("whoops")
 	}
+	if err := conf.Config.Validate(path); err != nil {
+		return nil, err
+	}
 
 	return nil, nil
 }
@@ -102,6 +111,7 @@ func (b *Board) processConfig(conf resource.Config) error {
 
 	// TODO(RSDK-2684): we dont configure pins so we just unset them here. not really great behavior.
 	b.GPIOPins = map[string]*GPIOPin{}
+	b.conditions = fakeconditions.New(newConf.Config)
 
 	stillExists := map[string]struct{}{}
 
@@ -111,9 +121,13 @@ func (b *Board) processConfig(conf resource.Config) error {
 			if curr.pin != c.Pin {
 				curr.reset(c.Pin)
 			}
+			curr.Mu.Lock()
+			curr.conditions = b.conditions
+			curr.Mu.Unlock()
 			continue
 		}
 		b.Analogs[c.Name] = newAnalogReader(c.Pin)
+		b.Analogs[c.Name].conditions = b.conditions
 	}
 	for name := range b.Analogs {
 		if _, ok := stillExists[name]; ok {
@@ -163,6 +177,7 @@ type Board struct {
 	GPIOPins   map[string]*GPIOPin
 	logger     logging.Logger
 	CloseCount int
+	conditions *fakeconditions.Conditions
 }
 
 // AnalogByName returns the analog pin by the given name if it exists.
@@ -193,7 +208,7 @@ func (b *Board) GPIOPinByName(name string) (board.GPIOPin, error) {
 	defer b.mu.Unlock()
 	p, ok := b.GPIOPins[name]
 	if !ok {
-		pin := &GPIOPin{}
+		pin := &GPIOPin{conditions: b.conditions}
 		b.GPIOPins[name] = pin
 		return pin, nil
 	}
@@ -276,6 +291,7 @@ type Analog struct {
 	CloseCount int
 	Mu         sync.RWMutex
 	fakeValue  int
+	conditions *fakeconditions.Conditions
 }
 
 func newAnalogReader(pin string) *Analog {
@@ -290,6 +306,12 @@ func (a *Analog) reset(pin string) {
 }
 
 func (a *Analog) Read(ctx context.Context, extra map[string]interface{}) (board.AnalogValue, error) {
+	a.Mu.RLock()
+	conditions := a.conditions
+	a.Mu.RUnlock()
+	if err := conditions.Wait(ctx); err != nil {
+		return board.AnalogValue{}, err
+	}
 	a.Mu.RLock()
 	defer a.Mu.RUnlock()
 	if a.pin != analogTestPin {
@@ -297,10 +319,52 @@ func (a *Analog) Read(ctx context.Context, extra map[string]interface{}) (board.
 		a.fakeValue %= 1001
 		a.Value = a.fakeValue
 	}
-	return board.AnalogValue{Value: a.Value, Min: 0, Max: 1000, StepSize: 1}, nil
+	value := int(math.Round(conditions.Noise(float64(a.Value))))
+	return board.AnalogValue{Value: value, Min: 0, Max: 1000, StepSize: 1}, nil
+}
+
+// ReadSamples reads a burst of the value at exactly the rate, taking as long as a real burst would.
+func (a *Analog) ReadSamples(
+	ctx context.Context, count int, rateHz float64, extra map[string]interface{},
+) (board.AnalogSamples, error) {
+	if err := board.ValidateAnalogSampling(count, rateHz); err != nil {
+		return board.AnalogSamples{}, err
+	}
+	a.Mu.RLock()
+	conditions := a.conditions
+	a.Mu.RUnlock()
+	if err := conditions.Wait(ctx); err != nil {
+		return board.AnalogSamples{}, err
+	}
+	interval := time.Duration(float64(time.Second) / rateHz)
+	start := time.Now()
+	if !utils.SelectContextOrWait(ctx, time.Duration(count-1)*interval) {
+		return board.AnalogSamples{}, ctx.Err()
+	}
+	a.Mu.RLock()
+	value := a.Value
+	a.Mu.RUnlock()
+	samples := board.AnalogSamples{
+		Values:     make([]int, 0, count),
+		Timestamps: make([]time.Time, 0, count),
+		Min:        0,
+		Max:        1000,
+		StepSize:   1,
+	}
+	for i := 0; i < count; i++ {
+		samples.Values = append(samples.Values, int(math.Round(conditions.Noise(float64(value)))))
+		samples.Timestamps = append(samples.Timestamps, start.Add(time.Duration(i)*interval))
+	}
+	return samples, nil
 }
 
 func (a *Analog) Write(ctx context.Context, value int, extra map[string]interface{}) error {
+	a.Mu.RLock()
+	conditions := a.conditions
+	a.Mu.RUnlock()
+	if err := conditions.Wait(ctx); err != nil {
+		return err
+	}
 	a.Set(value)
 	return nil
 }
@@ -319,10 +383,15 @@ type GPIOPin struct {
 	pwmFreq uint
 
 	mu sync.Mutex
+	// conditions is set when the board makes the pin, and never changed.
+	conditions *fakeconditions.Conditions
 }
 
 // Set sets the pin to either low or high.
 func (gp *GPIOPin) Set(ctx context.Context, high bool, extra map[string]interface{}) error {
+	if err := gp.conditions.Wait(ctx); err != nil {
+		return err
+	}
 	gp.mu.Lock()
 	defer gp.mu.Unlock()
 
@@ -334,6 +403,9 @@ func (gp *GPIOPin) Set(ctx context.Context, high bool, extra map[string]interfac
 
 // Get gets the high/low state of the pin.
 func (gp *GPIOPin) Get(ctx context.Context, extra map[string]interface{}) (bool, error) {
+	if err := gp.conditions.Wait(ctx); err != nil {
+		return false, err
+	}
 	gp.mu.Lock()
 	defer gp.mu.Unlock()
 
@@ -342,6 +414,9 @@ func (gp *GPIOPin) Get(ctx context.Context, extra map[string]interface{}) (bool,
 
 // PWM gets the pin's given duty cycle.
 func (gp *GPIOPin) PWM(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	if err := gp.conditions.Wait(ctx); err != nil {
+		return 0, err
+	}
 	gp.mu.Lock()
 	defer gp.mu.Unlock()
 
@@ -350,6 +425,9 @@ func (gp *GPIOPin) PWM(ctx context.Context, extra map[string]interface{}) (float
 
 // SetPWM sets the pin to the given duty cycle.
 func (gp *GPIOPin) SetPWM(ctx context.Context, dutyCyclePct float64, extra map[string]interface{}) error {
+	if err := gp.conditions.Wait(ctx); err != nil {
+		return err
+	}
 	gp.mu.Lock()
 	defer gp.mu.Unlock()
 
@@ -359,6 +437,9 @@ func (gp *GPIOPin) SetPWM(ctx context.Context, dutyCyclePct float64, extra map[s
 
 // PWMFreq gets the PWM frequency of the pin.
 func (gp *GPIOPin) PWMFreq(ctx context.Context, extra map[string]interface{}) (uint, error) {
+	if err := gp.conditions.Wait(ctx); err != nil {
+		return 0, err
+	}
 	gp.mu.Lock()
 	defer gp.mu.Unlock()
 
@@ -367,6 +448,9 @@ func (gp *GPIOPin) PWMFreq(ctx context.Context, extra map[string]interface{}) (u
 
 // SetPWMFreq sets the given pin to the given PWM frequency.
 func (gp *GPIOPin) SetPWMFreq(ctx context.Context, freqHz uint, extra map[string]interface{}) error {
+	if err := gp.conditions.Wait(ctx); err != nil {
+		return err
+	}
 	gp.mu.Lock()
 	defer gp.mu.Unlock()
 
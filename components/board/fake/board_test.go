@@ -3,6 +3,7 @@ package fake
 import (
 	"context"
 	"testing"
+	"time"
 
 	"go.viam.com/test"
 
@@ -71,3 +72,25 @@ func TestConfigValidate(t *testing.T) {
 	_, err = validConfig.Validate("path")
 	test.That(t, err, test.ShouldBeNil)
 }
+
+func TestAnalogSamples(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	cfg := resource.Config{Name: "board1", ConvertedAttributes: &Config{
+		AnalogReaders: []board.AnalogReaderConfig{{Name: "blue", Pin: analogTestPin}},
+	}}
+	b, err := NewBoard(context.Background(), cfg, logger)
+	test.That(t, err, test.ShouldBeNil)
+	a, err := b.AnalogByName("blue")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, a.Write(context.Background(), 512, nil), test.ShouldBeNil)
+
+	start := time.Now()
+	samples, err := board.ReadAnalogSamples(context.Background(), a, 50, 1000, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 49*time.Millisecond)
+	test.That(t, samples.Values, test.ShouldHaveLength, 50)
+	for _, v := range samples.Values {
+		test.That(t, v, test.ShouldEqual, 512)
+	}
+	test.That(t, samples.Timestamps[49].Sub(samples.Timestamps[0]), test.ShouldEqual, 49*time.Millisecond)
+}
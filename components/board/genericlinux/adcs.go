@@ -0,0 +1,47 @@
+//go:build linux
+
+// Package genericlinux is for Linux boards, and this particular file is for external ADCs on the
+// I2C and SPI buses of the board, whose channels appear among the analogs of the board itself.
+package genericlinux
+
+import (
+	"context"
+
+	"go.viam.com/rdk/components/board/adc"
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/components/board/pinwrappers"
+)
+
+// This helper function adds the channels of the ADCs of a config to the analogs of the board,
+// marking each of them in stillExists. Unlike the MCP3008 analogs, the channels are always
+// recreated, since a change to any part of their config changes how they are read.
+func (b *Board) reconfigureADCs(ctx context.Context, newConf *LinuxBoardConfig, stillExists map[string]struct{}) error {
+	for _, conf := range newConf.ADCs {
+		var i2c buses.I2C
+		var spi buses.SPI
+		switch conf.Model {
+		case adc.ADS1115:
+			var err error
+			if i2c, err = buses.NewI2cBus(conf.I2CBus); err != nil {
+				return err
+			}
+		case adc.ADS1263:
+			spi = buses.NewSpiBus(conf.SPIBus)
+		}
+		analogs, smoothing, err := adc.NewAnalogs(ctx, conf, i2c, spi)
+		if err != nil {
+			return err
+		}
+
+		for name, analog := range analogs {
+			stillExists[name] = struct{}{}
+			smoother := pinwrappers.SmoothAnalogReader(analog, smoothing[name], b.logger)
+			if curr, ok := b.analogReaders[name]; ok {
+				curr.reset(ctx, conf.ChipSelect, smoother)
+				continue
+			}
+			b.analogReaders[name] = newWrappedAnalogReader(ctx, conf.ChipSelect, smoother)
+		}
+	}
+	return nil
+}
@@ -35,6 +35,17 @@ func (a *wrappedAnalogReader) Read(ctx context.Context, extra map[string]interfa
 	return a.reader.Read(ctx, extra)
 }
 
+func (a *wrappedAnalogReader) ReadSamples(
+	ctx context.Context, count int, rateHz float64, extra map[string]interface{},
+) (board.AnalogSamples, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.reader == nil {
+		return board.AnalogSamples{}, errors.New("closed")
+	}
+	return a.reader.ReadSamples(ctx, count, rateHz, extra)
+}
+
 func (a *wrappedAnalogReader) Close(ctx context.Context) error {
 	return a.reader.Close(ctx)
 }
@@ -63,6 +63,7 @@ func NewBoard(
 		analogReaders: map[string]*wrappedAnalogReader{},
 		gpios:         map[string]*gpioPin{},
 		interrupts:    map[string]*digitalInterrupt{},
+		expanders:     map[string]*boardExpander{},
 	}
 
 	if err := b.Reconfigure(ctx, nil, conf); err != nil {
@@ -71,7 +72,7 @@ func NewBoard(
 	return b, nil
 }
 
-// Reconfigure reconfigures the board with interrupt pins, spi and i2c, and analogs.
+// Reconfigure reconfigures the board with interrupt pins, spi and i2c, analogs, and expanders.
 func (b *Board) Reconfigure(
 	ctx context.Context,
 	_ resource.Dependencies,
@@ -85,6 +86,11 @@ func (b *Board) Reconfigure(
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	// Interrupts on pins of expanders are handed to the expanders, after the board's own
+	// interrupts are set up so that they can listen to the INT lines of the expanders.
+	var expanderInterrupts []board.DigitalInterruptConfig
+	newConf.DigitalInterrupts, expanderInterrupts = splitExpanderInterrupts(newConf)
+
 	if err := b.reconfigureGpios(newConf); err != nil {
 		return err
 	}
@@ -94,6 +100,9 @@ func (b *Board) Reconfigure(
 	if err := b.reconfigureInterrupts(newConf); err != nil {
 		return err
 	}
+	if err := b.reconfigureExpanders(ctx, newConf, expanderInterrupts); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -223,6 +232,9 @@ func (b *Board) reconfigureAnalogReaders(ctx context.Context, newConf *LinuxBoar
 				AverageOverMillis: c.AverageOverMillis, SamplesPerSecond: c.SamplesPerSecond,
 			}, b.logger))
 	}
+	if err := b.reconfigureADCs(ctx, newConf, stillExists); err != nil {
+		return err
+	}
 
 	for name := range b.analogReaders {
 		if _, ok := stillExists[name]; ok {
@@ -365,6 +377,7 @@ type Board struct {
 
 	gpios      map[string]*gpioPin
 	interrupts map[string]*digitalInterrupt
+	expanders  map[string]*boardExpander
 
 	cancelCtx               context.Context
 	cancelFunc              func()
@@ -384,14 +397,26 @@ func (b *Board) AnalogByName(name string) (board.Analog, error) {
 func (b *Board) DigitalInterruptByName(name string) (board.DigitalInterrupt, error) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
+	return b.digitalInterruptByName(name)
+}
 
+// This is a private helper function that should only be called when the mutex is locked.
+func (b *Board) digitalInterruptByName(name string) (board.DigitalInterrupt, error) {
 	interrupt, ok := b.interrupts[name]
 	if ok {
 		return interrupt.interrupt, nil
 	}
+	for _, e := range b.expanders {
+		if interrupt, ok := e.DigitalInterruptByName(name); ok {
+			return interrupt, nil
+		}
+	}
 
 	// Otherwise, the name is not something we recognize yet. If it appears to be a GPIO pin, we'll
 	// remove its GPIO capabilities and turn it into a digital interrupt.
+	if e, ok := b.expanderWithPin(name); ok {
+		return e.AddDigitalInterrupt(b.cancelCtx, board.DigitalInterruptConfig{Name: name, Pin: name}, nil)
+	}
 	gpio, ok := b.gpios[name]
 	if !ok {
 		return nil, fmt.Errorf("cant find GPIO (%s)", name)
@@ -434,6 +459,9 @@ func (b *Board) DigitalInterruptNames() []string {
 	for name := range b.interrupts {
 		names = append(names, name)
 	}
+	for _, e := range b.expanders {
+		names = append(names, e.DigitalInterruptNames()...)
+	}
 	return names
 }
 
@@ -448,6 +476,10 @@ func (b *Board) GPIOPinByName(pinName string) (board.GPIOPin, error) {
 		return &gpioInterruptWrapperPin{*interrupt}, nil
 	}
 
+	if e, ok := b.expanderWithPin(pinName); ok {
+		return e.GPIOPinByName(pinName)
+	}
+
 	return nil, errors.Errorf("cannot find GPIO for unknown pin: %s", pinName)
 }
 
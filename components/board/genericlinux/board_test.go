@@ -13,6 +13,8 @@ import (
 	"go.viam.com/test"
 
 	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/board/adc"
+	"go.viam.com/rdk/components/board/expander"
 	"go.viam.com/rdk/components/board/mcp3008helper"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
@@ -93,6 +95,51 @@ func TestConfigValidate(t *testing.T) {
 	validConfig.DigitalInterrupts = []board.DigitalInterruptConfig{{Name: "bar", Pin: "3"}}
 	_, err = validConfig.Validate("path")
 	test.That(t, err, test.ShouldBeNil)
+
+	validConfig.Expanders = []expander.Config{{Name: "io", Model: expander.MCP23017}}
+	_, err = validConfig.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `path.expanders.0`)
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "i2c_bus")
+
+	validConfig.Expanders = []expander.Config{
+		{Name: "io", Model: expander.MCP23017, I2CBus: "1"},
+		{Name: "io", Model: expander.PCF8574, I2CBus: "1"},
+	}
+	_, err = validConfig.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `path.expanders.1`)
+
+	validConfig.Expanders[1].Name = "io2"
+	_, err = validConfig.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	validConfig.ADCs = []adc.Config{{
+		Name:     "adc",
+		Model:    adc.ADS1115,
+		I2CBus:   "1",
+		Channels: []adc.ChannelConfig{{Name: "cell", Pin: "0-2"}},
+	}}
+	_, err = validConfig.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `path.adcs.0.channels.0`)
+
+	validConfig.ADCs[0].Channels[0].Pin = "0-1"
+	_, err = validConfig.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+}
+
+func TestSplitExpanderInterrupts(t *testing.T) {
+	conf := &LinuxBoardConfig{
+		DigitalInterrupts: []board.DigitalInterruptConfig{
+			{Name: "a", Pin: "3"},
+			{Name: "b", Pin: "io_gpb0"},
+			{Name: "c", Pin: "io_p0"},
+		},
+		Expanders: []expander.Config{{Name: "io", Model: expander.MCP23017, I2CBus: "1"}},
+	}
+	onBoard, onExpanders := splitExpanderInterrupts(conf)
+	test.That(t, onBoard, test.ShouldResemble, []board.DigitalInterruptConfig{{Name: "a", Pin: "3"}, {Name: "c", Pin: "io_p0"}})
+	test.That(t, onExpanders, test.ShouldResemble, []board.DigitalInterruptConfig{{Name: "b", Pin: "io_gpb0"}})
 }
 
 func TestNewBoard(t *testing.T) {
@@ -3,7 +3,11 @@ package genericlinux
 import (
 	"fmt"
 
+	"github.com/pkg/errors"
+
 	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/board/adc"
+	"go.viam.com/rdk/components/board/expander"
 	"go.viam.com/rdk/components/board/mcp3008helper"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
@@ -13,6 +17,8 @@ import (
 type Config struct {
 	AnalogReaders     []mcp3008helper.MCP3008AnalogConfig `json:"analogs,omitempty"`
 	DigitalInterrupts []board.DigitalInterruptConfig      `json:"digital_interrupts,omitempty"`
+	Expanders         []expander.Config                   `json:"expanders,omitempty"`
+	ADCs              []adc.Config                        `json:"adcs,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -27,6 +33,35 @@ func (conf *Config) Validate(path string) ([]string, error) {
 			return nil, err
 		}
 	}
+	names := map[string]struct{}{}
+	for idx, c := range conf.Expanders {
+		expanderPath := fmt.Sprintf("%s.%s.%d", path, "expanders", idx)
+		if err := c.Validate(expanderPath); err != nil {
+			return nil, err
+		}
+		if _, ok := names[c.Name]; ok {
+			return nil, resource.NewConfigValidationError(expanderPath,
+				errors.Errorf("expander name %q is used more than once", c.Name))
+		}
+		names[c.Name] = struct{}{}
+	}
+	analogNames := map[string]struct{}{}
+	for _, c := range conf.AnalogReaders {
+		analogNames[c.Name] = struct{}{}
+	}
+	for idx, c := range conf.ADCs {
+		adcPath := fmt.Sprintf("%s.%s.%d", path, "adcs", idx)
+		if err := c.Validate(adcPath); err != nil {
+			return nil, err
+		}
+		for _, channel := range c.Channels {
+			if _, ok := analogNames[channel.Name]; ok {
+				return nil, resource.NewConfigValidationError(adcPath,
+					errors.Errorf("analog name %q is used more than once", channel.Name))
+			}
+			analogNames[channel.Name] = struct{}{}
+		}
+	}
 	return nil, nil
 }
 
@@ -40,6 +75,8 @@ func (conf *Config) Validate(path string) ([]string, error) {
 type LinuxBoardConfig struct {
 	AnalogReaders     []mcp3008helper.MCP3008AnalogConfig
 	DigitalInterrupts []board.DigitalInterruptConfig
+	Expanders         []expander.Config
+	ADCs              []adc.Config
 	GpioMappings      map[string]GPIOBoardMapping
 }
 
@@ -63,6 +100,8 @@ func ConstPinDefs(gpioMappings map[string]GPIOBoardMapping) ConfigConverter {
 		return &LinuxBoardConfig{
 			AnalogReaders:     newConf.AnalogReaders,
 			DigitalInterrupts: newConf.DigitalInterrupts,
+			Expanders:         newConf.Expanders,
+			ADCs:              newConf.ADCs,
 			GpioMappings:      gpioMappings,
 		}, nil
 	}
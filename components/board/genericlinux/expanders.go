@@ -0,0 +1,166 @@
+//go:build linux
+
+// Package genericlinux is for Linux boards, and this particular file is for GPIO expanders on I2C
+// buses of the board, whose pins appear among those of the board itself.
+package genericlinux
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/board/expander"
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/components/board/pinwrappers"
+)
+
+type boardExpander struct {
+	*expander.Expander
+	// stopWatching stops listening to the INT line of the expander. It is nil until the board starts listening.
+	stopWatching func()
+}
+
+// This helper function splits the digital interrupts of a config between those on pins of the
+// board and those on pins of its expanders.
+func splitExpanderInterrupts(newConf *LinuxBoardConfig) (onBoard, onExpanders []board.DigitalInterruptConfig) {
+	expanderPins := map[string]struct{}{}
+	for _, conf := range newConf.Expanders {
+		for _, pin := range expander.PinNames(conf.Model) {
+			expanderPins[conf.PinName(pin)] = struct{}{}
+		}
+	}
+	for _, conf := range newConf.DigitalInterrupts {
+		if _, ok := expanderPins[conf.Pin]; ok {
+			onExpanders = append(onExpanders, conf)
+		} else {
+			onBoard = append(onBoard, conf)
+		}
+	}
+	return onBoard, onExpanders
+}
+
+func (b *Board) reconfigureExpanders(
+	ctx context.Context, newConf *LinuxBoardConfig, interruptConfs []board.DigitalInterruptConfig,
+) error {
+	newConfs := map[string]expander.Config{}
+	for _, conf := range newConf.Expanders {
+		newConfs[conf.Name] = conf
+	}
+
+	// Expanders whose config changed are created anew, but anything subscribed to their digital
+	// interrupts stays subscribed to the new ones.
+	holders := map[string]pinwrappers.ReconfigurableDigitalInterrupt{}
+	for name, old := range b.expanders {
+		if conf, ok := newConfs[name]; ok && conf == old.Config() {
+			continue
+		}
+		if old.stopWatching != nil {
+			old.stopWatching()
+		}
+		for _, interruptName := range old.DigitalInterruptNames() {
+			holders[interruptName], _ = old.DigitalInterruptByName(interruptName)
+		}
+		delete(b.expanders, name)
+	}
+
+	for _, conf := range newConf.Expanders {
+		if _, ok := b.expanders[conf.Name]; ok {
+			continue
+		}
+		bus, err := buses.NewI2cBus(conf.I2CBus)
+		if err != nil {
+			return err
+		}
+		e, err := expander.New(ctx, conf, bus)
+		if err != nil {
+			return err
+		}
+		b.expanders[conf.Name] = &boardExpander{Expander: e}
+	}
+
+	for _, e := range b.expanders {
+		var confs []board.DigitalInterruptConfig
+		for _, conf := range interruptConfs {
+			if e.HasPin(conf.Pin) {
+				confs = append(confs, conf)
+			}
+		}
+		if err := e.ReconfigureDigitalInterrupts(ctx, confs, holders); err != nil {
+			return err
+		}
+		if e.Config().InterruptPin != "" && e.stopWatching == nil {
+			if err := b.watchExpander(e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// watchExpander listens to the INT line of an expander, on a digital interrupt of the board, and
+// has the expander tick its own digital interrupts whenever the line falls. This is a private
+// helper function that should only be called when the mutex is locked.
+func (b *Board) watchExpander(e *boardExpander) error {
+	interrupt, err := b.digitalInterruptByName(e.Config().InterruptPin)
+	if err != nil {
+		return err
+	}
+	basic, ok := interrupt.(*pinwrappers.BasicDigitalInterrupt)
+	if !ok {
+		return errors.Errorf("pin %s cannot be the interrupt_pin of an expander", e.Config().InterruptPin)
+	}
+	ticks := make(chan board.Tick, 16)
+	pinwrappers.AddCallback(basic, ticks)
+
+	cancelCtx, cancelFunc := context.WithCancel(b.cancelCtx)
+	e.stopWatching = cancelFunc
+	b.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		// The INT line stays low until the pins are read, so read them once in case it fell
+		// before we were listening.
+		if err := e.HandleInterrupt(cancelCtx, uint64(time.Now().UnixNano())); err != nil {
+			b.logger.Errorw("cannot read expander", "expander", e.Config().Name, "error", err)
+		}
+		for {
+			select {
+			case <-cancelCtx.Done():
+				// Keep draining the ticks while unsubscribing, because the interrupt holds its
+				// lock while it waits for us to receive one.
+				removed := make(chan struct{})
+				go func() {
+					pinwrappers.RemoveCallback(basic, ticks)
+					close(removed)
+				}()
+				for {
+					select {
+					case <-ticks:
+					case <-removed:
+						return
+					}
+				}
+			case tick := <-ticks:
+				if tick.High {
+					continue // The INT line is active low.
+				}
+				if err := e.HandleInterrupt(cancelCtx, tick.TimestampNanosec); err != nil {
+					b.logger.Errorw("cannot read expander", "expander", e.Config().Name, "error", err)
+				}
+			}
+		}
+	}, b.activeBackgroundWorkers.Done)
+	return nil
+}
+
+// This is a private helper function that should only be called when the mutex is locked. It
+// returns the expander with a pin of the given name, if any.
+func (b *Board) expanderWithPin(name string) (*boardExpander, bool) {
+	for _, e := range b.expanders {
+		if e.HasPin(name) {
+			return e, true
+		}
+	}
+	return nil, false
+}
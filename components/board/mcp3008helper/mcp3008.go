@@ -4,8 +4,10 @@ package mcp3008helper
 
 import (
 	"context"
+	"time"
 
 	"go.uber.org/multierr"
+	"go.viam.com/utils"
 
 	"go.viam.com/rdk/components/board"
 	"go.viam.com/rdk/components/board/genericlinux/buses"
@@ -41,11 +43,6 @@ func (config *MCP3008AnalogConfig) Validate(path string) error {
 func (mar *MCP3008AnalogReader) Read(ctx context.Context, extra map[string]interface{}) (
 	analogVal board.AnalogValue, err error,
 ) {
-	var tx [3]byte
-	tx[0] = 1                            // start bit
-	tx[1] = byte((8 + mar.Channel) << 4) // single-ended
-	tx[2] = 0                            // extra clocks to receive full 10 bits of data
-
 	bus, err := mar.Bus.OpenHandle()
 	if err != nil {
 		return board.AnalogValue{}, err
@@ -54,18 +51,64 @@ func (mar *MCP3008AnalogReader) Read(ctx context.Context, extra map[string]inter
 		err = multierr.Combine(err, bus.Close())
 	}()
 
-	rx, err := bus.Xfer(ctx, 1000000, mar.Chip, 0, tx[:])
+	val, err := mar.convert(ctx, bus)
 	if err != nil {
 		return board.AnalogValue{}, err
 	}
-	// Reassemble the 10-bit value. Do not include bits before the final 10, because they contain
-	// garbage and might be non-zero.
-	val := 0x03FF & ((int(rx[1]) << 8) | int(rx[2]))
-
 	// returning no analog range since mcp3008 will be removed soon.
 	return board.AnalogValue{Value: val}, nil
 }
 
+// ReadSamples reads a burst of samples while holding the bus, so no other transfer delays them.
+func (mar *MCP3008AnalogReader) ReadSamples(
+	ctx context.Context, count int, rateHz float64, extra map[string]interface{},
+) (samples board.AnalogSamples, err error) {
+	if err := board.ValidateAnalogSampling(count, rateHz); err != nil {
+		return board.AnalogSamples{}, err
+	}
+	bus, err := mar.Bus.OpenHandle()
+	if err != nil {
+		return board.AnalogSamples{}, err
+	}
+	defer func() {
+		err = multierr.Combine(err, bus.Close())
+	}()
+
+	samples = board.AnalogSamples{Values: make([]int, 0, count), Timestamps: make([]time.Time, 0, count)}
+	interval := time.Duration(float64(time.Second) / rateHz)
+	start := time.Now()
+	for i := 0; i < count; i++ {
+		if wait := time.Until(start.Add(time.Duration(i) * interval)); wait > 0 {
+			if !utils.SelectContextOrWait(ctx, wait) {
+				return board.AnalogSamples{}, ctx.Err()
+			}
+		}
+		val, err := mar.convert(ctx, bus)
+		if err != nil {
+			return board.AnalogSamples{}, err
+		}
+		samples.Values = append(samples.Values, val)
+		samples.Timestamps = append(samples.Timestamps, time.Now())
+	}
+	return samples, nil
+}
+
+// convert reads the channel once over an open handle of the bus.
+func (mar *MCP3008AnalogReader) convert(ctx context.Context, bus buses.SPIHandle) (int, error) {
+	var tx [3]byte
+	tx[0] = 1                            // start bit
+	tx[1] = byte((8 + mar.Channel) << 4) // single-ended
+	tx[2] = 0                            // extra clocks to receive full 10 bits of data
+
+	rx, err := bus.Xfer(ctx, 1000000, mar.Chip, 0, tx[:])
+	if err != nil {
+		return 0, err
+	}
+	// Reassemble the 10-bit value. Do not include bits before the final 10, because they contain
+	// garbage and might be non-zero.
+	return 0x03FF & ((int(rx[1]) << 8) | int(rx[2])), nil
+}
+
 // Close does nothing.
 func (mar *MCP3008AnalogReader) Close(ctx context.Context) error {
 	return nil
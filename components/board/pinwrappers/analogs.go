@@ -73,7 +73,7 @@ func (as *AnalogSmoother) Read(ctx context.Context, extra map[string]interface{}
 
 	if as.data == nil { // We're using raw data, and not averaging
 		analogVal.Value = as.lastData
-		return as.analogVal, nil
+		return analogVal, nil
 	}
 	avg := as.data.Average()
 	lastErr := as.lastError.Load()
@@ -88,6 +88,14 @@ func (as *AnalogSmoother) Read(ctx context.Context, extra map[string]interface{}
 	return analogVal, nil
 }
 
+// ReadSamples reads a burst of samples of the underlying reader, which are not smoothed since a burst is read to see
+// the signal itself.
+func (as *AnalogSmoother) ReadSamples(
+	ctx context.Context, count int, rateHz float64, extra map[string]interface{},
+) (board.AnalogSamples, error) {
+	return board.ReadAnalogSamples(ctx, as.Raw, count, rateHz, extra)
+}
+
 // Start begins the smoothing routine that reads from the underlying
 // analog reader.
 func (as *AnalogSmoother) Start() {
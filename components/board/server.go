@@ -6,6 +6,7 @@ import (
 
 	commonpb "go.viam.com/api/common/v1"
 	pb "go.viam.com/api/component/board/v1"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"go.viam.com/rdk/protoutils"
 	"go.viam.com/rdk/resource"
@@ -258,7 +259,19 @@ func (s *serviceServer) DoCommand(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
-	return protoutils.DoFromResourceServer(ctx, b, req)
+	cmd := req.GetCommand().AsMap()
+	if cmd["command"] != readAnalogSamplesCommand {
+		return protoutils.DoFromResourceServer(ctx, b, req)
+	}
+	resp, err := readAnalogSamplesFromCommand(ctx, b, cmd)
+	if err != nil {
+		return nil, err
+	}
+	pbResp, err := structpb.NewStruct(resp)
+	if err != nil {
+		return nil, err
+	}
+	return &commonpb.DoCommandResponse{Result: pbResp}, nil
 }
 
 func (s *serviceServer) SetPowerMode(ctx context.Context,
@@ -5,6 +5,7 @@ import (
 	"context"
 	"image"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pion/mediadevices/pkg/prop"
@@ -42,6 +43,10 @@ func init() {
 		API:        API,
 		MethodName: readImage.String(),
 	}, newReadImageCollector)
+	data.RegisterCollectorParams(data.MethodMetadata{
+		API:        API,
+		MethodName: readImage.String(),
+	}, data.MethodParamSpec{Name: readImageMimeTypeKey})
 	data.RegisterCollector(data.MethodMetadata{
 		API:        API,
 		MethodName: getImages.String(),
@@ -107,6 +112,8 @@ type VideoSource interface {
 
 	// NextPointCloud returns the next immediately available point cloud, not necessarily one
 	// a part of a sequence. In the future, there could be streaming of point clouds.
+	// Clients fetch point clouds LZ4 compressed when the camera's server supports it; a MIME type hint of
+	// utils.MimeTypePCD in the context via gostream.WithMIMETypeHint asks for them uncompressed.
 	//
 	//    myCamera, err := camera.FromRobot(machine, "my_camera")
 	//
@@ -134,6 +141,22 @@ func ReadImage(ctx context.Context, src gostream.VideoSource) (image.Image, func
 	return gostream.ReadImage(ctx, src)
 }
 
+// ReadImageWithMetadata reads an image from a camera with the metadata of its capture. The image of a camera with a
+// single imager comes from Images, with the metadata the camera gives it. The image of a camera with several, or
+// which cannot return Images, is read with ReadImage and stamped with the time it was read, as the camera gives no
+// better.
+func ReadImageWithMetadata(ctx context.Context, cam VideoSource) (image.Image, resource.ResponseMetadata, func(), error) {
+	imgs, md, err := cam.Images(ctx)
+	if err == nil && len(imgs) == 1 {
+		return imgs[0].Image, md, func() {}, nil
+	}
+	img, release, err := ReadImage(ctx, cam)
+	if err != nil {
+		return nil, resource.ResponseMetadata{}, nil, err
+	}
+	return img, resource.ResponseMetadata{CapturedAt: time.Now()}, release, nil
+}
+
 type projectorProvider interface {
 	Projector(ctx context.Context) (transform.Projector, error)
 }
@@ -324,6 +347,7 @@ type videoSource struct {
 	actualSource         interface{}
 	system               *transform.PinholeCameraModel
 	imageType            ImageType
+	frameSequence        atomic.Uint64
 }
 
 func (vs *videoSource) Stream(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error) {
@@ -332,24 +356,21 @@ func (vs *videoSource) Stream(ctx context.Context, errHandlers ...gostream.Error
 
 // Images is for getting simultaneous images from different sensors
 // If the underlying source did not specify an Images function, a default is applied.
-// The default returns a list of 1 image from ReadImage, and the current time.
+// The default returns a list of 1 image from ReadImage, the current time, and the number of images it has returned.
 func (vs *videoSource) Images(ctx context.Context) ([]NamedImage, resource.ResponseMetadata, error) {
 	ctx, span := trace.StartSpan(ctx, "camera::videoSource::Images")
 	defer span.End()
 	if c, ok := vs.actualSource.(ImagesSource); ok {
 		return c.Images(ctx)
 	}
-	img, release, err := ReadImage(ctx, vs.videoSource)
+	// the image outlives this call, so it is not released; a frame borrowed from a pool is left to the garbage
+	// collector rather than being reused while the caller still has it
+	img, _, err := ReadImage(ctx, vs.videoSource)
 	if err != nil {
 		return nil, resource.ResponseMetadata{}, errors.Wrap(err, "videoSource: call to get Images failed")
 	}
-	defer func() {
-		if release != nil {
-			release()
-		}
-	}()
 	ts := time.Now()
-	return []NamedImage{{img, ""}}, resource.ResponseMetadata{CapturedAt: ts}, nil
+	return []NamedImage{{img, ""}}, resource.ResponseMetadata{CapturedAt: ts, FrameSequence: vs.frameSequence.Add(1)}, nil
 }
 
 // NextPointCloud returns the next PointCloud from the camera, or will error if not supported.
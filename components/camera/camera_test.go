@@ -15,6 +15,7 @@ import (
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/rimage"
 	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/testutils/inject"
 	rutils "go.viam.com/rdk/utils"
 )
 
@@ -253,3 +254,40 @@ func TestCameraWithProjector(t *testing.T) {
 
 	test.That(t, cam2.Close(context.Background()), test.ShouldBeNil)
 }
+
+func TestReadImageWithMetadata(t *testing.T) {
+	ctx := context.Background()
+	reader := gostream.VideoReaderFunc(func(ctx context.Context) (image.Image, func(), error) {
+		return rimage.NewImage(4, 3), func() {}, nil
+	})
+	// a single imager, whose images are numbered
+	src, err := camera.NewVideoSourceFromReader(ctx, reader, nil, camera.ColorStream)
+	test.That(t, err, test.ShouldBeNil)
+	for seq := uint64(1); seq <= 2; seq++ {
+		img, md, release, err := camera.ReadImageWithMetadata(ctx, src)
+		test.That(t, err, test.ShouldBeNil)
+		release()
+		test.That(t, img.Bounds().Dx(), test.ShouldEqual, 4)
+		test.That(t, md.FrameSequence, test.ShouldEqual, seq)
+		test.That(t, md.CapturedAt.IsZero(), test.ShouldBeFalse)
+	}
+	test.That(t, src.Close(ctx), test.ShouldBeNil)
+
+	// several imagers, whose first image is read instead
+	cam := inject.NewCamera("rgbd")
+	cam.ImagesFunc = func(ctx context.Context) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+		return []camera.NamedImage{
+			{Image: rimage.NewImage(4, 3), SourceName: "color"},
+			{Image: rimage.NewEmptyDepthMap(4, 3), SourceName: "depth"},
+		}, resource.ResponseMetadata{FrameSequence: 9}, nil
+	}
+	cam.StreamFunc = func(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error) {
+		return gostream.NewEmbeddedVideoStreamFromReader(reader), nil
+	}
+	img, md, release, err := camera.ReadImageWithMetadata(ctx, cam)
+	test.That(t, err, test.ShouldBeNil)
+	release()
+	test.That(t, img, test.ShouldHaveSameTypeAs, &rimage.Image{})
+	test.That(t, md.FrameSequence, test.ShouldEqual, 0)
+	test.That(t, md.CapturedAt.IsZero(), test.ShouldBeFalse)
+}
@@ -18,6 +18,8 @@ import (
 	goutils "go.viam.com/utils"
 	goprotoutils "go.viam.com/utils/protoutils"
 	"go.viam.com/utils/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
 
@@ -236,9 +238,10 @@ func (c *client) Images(ctx context.Context) ([]NamedImage, resource.ResponseMet
 	ctx, span := trace.StartSpan(ctx, "camera::client::Images")
 	defer span.End()
 
+	var header metadata.MD
 	resp, err := c.client.GetImages(ctx, &pb.GetImagesRequest{
 		Name: c.name,
-	})
+	}, grpc.Header(&header))
 	if err != nil {
 		return nil, resource.ResponseMetadata{}, errors.Wrap(err, "camera client: could not gets images from the camera")
 	}
@@ -264,7 +267,11 @@ func (c *client) Images(ctx context.Context) ([]NamedImage, resource.ResponseMet
 		}
 		images = append(images, NamedImage{rdkImage, img.SourceName})
 	}
-	return images, resource.ResponseMetadataFromProto(resp.ResponseMetadata), nil
+	md := resource.ResponseMetadataFromProto(resp.ResponseMetadata)
+	if err := md.ReadGRPCHeader(header); err != nil {
+		return nil, resource.ResponseMetadata{}, err
+	}
+	return images, md, nil
 }
 
 func (c *client) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, error) {
@@ -278,9 +285,14 @@ func (c *client) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, err
 		return nil, err
 	}
 
+	// ask for a compressed point cloud unless told otherwise; servers which cannot compress send plain PCD
+	mimeType := gostream.MIMETypeHint(ctx, utils.MimeTypePCDLZ4)
+	if mimeType != utils.MimeTypePCD {
+		mimeType = utils.MimeTypePCDLZ4
+	}
 	resp, err := c.client.GetPointCloud(ctx, &pb.GetPointCloudRequest{
 		Name:     c.name,
-		MimeType: utils.MimeTypePCD,
+		MimeType: mimeType,
 		Extra:    ext,
 	})
 	getPcdSpan.End()
@@ -288,7 +300,13 @@ func (c *client) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, err
 		return nil, err
 	}
 
-	if resp.MimeType != utils.MimeTypePCD {
+	var read func(io.Reader) (pointcloud.PointCloud, error)
+	switch resp.MimeType {
+	case utils.MimeTypePCD:
+		read = pointcloud.ReadPCD
+	case utils.MimeTypePCDLZ4:
+		read = pointcloud.ReadLZ4PCD
+	default:
 		return nil, fmt.Errorf("unknown pc mime type %s", resp.MimeType)
 	}
 
@@ -296,7 +314,7 @@ func (c *client) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, err
 		_, span := trace.StartSpan(ctx, "camera::client::NextPointCloud::ReadPCD")
 		defer span.End()
 
-		return pointcloud.ReadPCD(bytes.NewReader(resp.PointCloud))
+		return read(bytes.NewReader(resp.PointCloud))
 	}()
 }
 
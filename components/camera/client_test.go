@@ -88,7 +88,7 @@ func TestClient(t *testing.T) {
 		images = append(images, camera.NamedImage{depth, "depth"})
 		// a timestamp of 12345
 		ts := time.UnixMilli(12345)
-		return images, resource.ResponseMetadata{CapturedAt: ts}, nil
+		return images, resource.ResponseMetadata{CapturedAt: ts, FrameSequence: 7, Exposure: 10 * time.Millisecond}, nil
 	}
 	injectCamera.StreamFunc = func(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error) {
 		return gostream.NewEmbeddedVideoStreamFromReader(gostream.VideoReaderFunc(func(ctx context.Context) (image.Image, func(), error) {
@@ -186,6 +186,11 @@ func TestClient(t *testing.T) {
 		_, got := pcB.At(5, 5, 5)
 		test.That(t, got, test.ShouldBeTrue)
 
+		pcB, err = camera1Client.NextPointCloud(gostream.WithMIMETypeHint(context.Background(), rutils.MimeTypePCD))
+		test.That(t, err, test.ShouldBeNil)
+		_, got = pcB.At(5, 5, 5)
+		test.That(t, got, test.ShouldBeTrue)
+
 		projB, err := camera1Client.Projector(context.Background())
 		test.That(t, err, test.ShouldBeNil)
 		test.That(t, projB, test.ShouldNotBeNil)
@@ -198,6 +203,8 @@ func TestClient(t *testing.T) {
 		images, meta, err := camera1Client.Images(context.Background())
 		test.That(t, err, test.ShouldBeNil)
 		test.That(t, meta.CapturedAt, test.ShouldEqual, time.UnixMilli(12345))
+		test.That(t, meta.FrameSequence, test.ShouldEqual, 7)
+		test.That(t, meta.Exposure, test.ShouldEqual, 10*time.Millisecond)
 		test.That(t, len(images), test.ShouldEqual, 2)
 		test.That(t, images[0].SourceName, test.ShouldEqual, "color")
 		test.That(t, images[0].Image.Bounds().Dx(), test.ShouldEqual, 40)
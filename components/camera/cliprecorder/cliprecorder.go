@@ -0,0 +1,366 @@
+// Package cliprecorder implements a camera which passes another camera through and records clips of it to MP4 files
+// on demand, optionally starting a few seconds before the request from a rolling buffer so the moments leading up to
+// an event are kept. Clips are written where the data manager syncs them from by default.
+package cliprecorder
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/gostream"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("clip_recorder")
+
+const (
+	defaultFrameRate      = 10.
+	defaultMaxDurationSec = 300.
+	maxPreTriggerSec      = 60.
+)
+
+// defaultOutputDir is the capture directory of the data manager, so clips are synced without further configuration.
+var defaultOutputDir = filepath.Join(os.Getenv("HOME"), ".viam", "capture", "clips")
+
+// Config is the attribute struct for clip recorders.
+type Config struct {
+	Source string `json:"source"`
+	// OutputDir is where clips are written, in a directory per camera. It defaults to a directory the data manager
+	// syncs.
+	OutputDir string  `json:"output_dir,omitempty"`
+	FrameRate float64 `json:"frame_rate,omitempty"`
+	// PreTriggerBufferSec is how many seconds of frames are kept from before each request. Keeping them means the
+	// source camera is read all the time rather than only while recording.
+	PreTriggerBufferSec float64 `json:"pre_trigger_buffer_sec,omitempty"`
+	MaxDurationSec      float64 `json:"max_duration_sec,omitempty"`
+	// Codec is the codec clips are encoded with, "h264" by default or "h265", which takes about half the space for
+	// the same quality and is encoded in hardware where there is an encoder for it. Without any H.265 encoder, clips
+	// are encoded with H.264.
+	Codec string `json:"codec,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if cfg.Source == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "source")
+	}
+	if cfg.FrameRate < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("frame_rate cannot be negative"))
+	}
+	if cfg.PreTriggerBufferSec < 0 || cfg.PreTriggerBufferSec > maxPreTriggerSec {
+		return nil, resource.NewConfigValidationError(path,
+			errors.Errorf("pre_trigger_buffer_sec must be between 0 and %v", maxPreTriggerSec))
+	}
+	if cfg.MaxDurationSec < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("max_duration_sec cannot be negative"))
+	}
+	if cfg.Codec != "" && cfg.Codec != codecH264 && cfg.Codec != codecH265 {
+		return nil, resource.NewConfigValidationError(path,
+			errors.Errorf("codec must be %q or %q, not %q", codecH264, codecH265, cfg.Codec))
+	}
+	return []string{cfg.Source}, nil
+}
+
+func init() {
+	resource.RegisterComponent(camera.API, model, resource.Registration[camera.Camera, *Config]{
+		Constructor: func(
+			ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
+		) (camera.Camera, error) {
+			newConf, err := resource.NativeConfig[*Config](conf)
+			if err != nil {
+				return nil, err
+			}
+			source, err := camera.FromDependencies(deps, newConf.Source)
+			if err != nil {
+				return nil, err
+			}
+			return newClipRecorder(conf.ResourceName(), source, newConf, logger), nil
+		},
+	})
+}
+
+// A frame is a JPEG captured from the source camera.
+type frame struct {
+	time time.Time
+	data []byte
+}
+
+// A recording is a clip being written.
+type recording struct {
+	writer    clipWriter
+	tmpPath   string
+	path      string
+	remaining int
+	err       error
+	done      chan struct{}
+}
+
+type clipRecorder struct {
+	resource.Named
+	resource.AlwaysRebuild
+	camera.VideoSource
+
+	logger         logging.Logger
+	source         camera.Camera
+	outputDir      string
+	frameRate      float64
+	bufferLen      time.Duration
+	maxDurationSec float64
+	videoCodec     string
+
+	workers   utils.StoppableWorkers
+	finishing sync.WaitGroup
+	wake      chan struct{}
+
+	mu        sync.Mutex
+	buffer    []frame
+	recording []*recording
+}
+
+func newClipRecorder(name resource.Name, source camera.Camera, conf *Config, logger logging.Logger) *clipRecorder {
+	r := &clipRecorder{
+		Named:          name.AsNamed(),
+		VideoSource:    source,
+		logger:         logger,
+		source:         source,
+		outputDir:      conf.OutputDir,
+		frameRate:      conf.FrameRate,
+		bufferLen:      time.Duration(conf.PreTriggerBufferSec * float64(time.Second)),
+		maxDurationSec: conf.MaxDurationSec,
+		videoCodec:     conf.Codec,
+		wake:           make(chan struct{}, 1),
+	}
+	if r.outputDir == "" {
+		r.outputDir = defaultOutputDir
+	}
+	if r.frameRate == 0 {
+		r.frameRate = defaultFrameRate
+	}
+	if r.maxDurationSec == 0 {
+		r.maxDurationSec = defaultMaxDurationSec
+	}
+	if r.videoCodec == "" {
+		r.videoCodec = codecH264
+	}
+	r.workers = utils.NewStoppableWorkers(r.captureLoop)
+	return r
+}
+
+// captureLoop reads the source camera at the frame rate while frames are buffered or recorded, and sleeps otherwise.
+func (r *clipRecorder) captureLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / r.frameRate))
+	defer ticker.Stop()
+	for {
+		r.mu.Lock()
+		idle := r.bufferLen == 0 && len(r.recording) == 0
+		r.mu.Unlock()
+		if idle {
+			select {
+			case <-ctx.Done():
+				return
+			case <-r.wake:
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		data, err := r.capture(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				r.logger.CDebugw(ctx, "cannot capture frame for clips", "error", err)
+			}
+			continue
+		}
+		r.addFrame(frame{time: time.Now(), data: data})
+	}
+}
+
+// capture reads a frame from the source camera as a JPEG.
+func (r *clipRecorder) capture(ctx context.Context) ([]byte, error) {
+	ctx = gostream.WithMIMETypeHint(ctx, utils.MimeTypeJPEG)
+	img, release, err := camera.ReadImage(ctx, r.source)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return rimage.EncodeImage(ctx, img, utils.MimeTypeJPEG)
+}
+
+// addFrame buffers a frame and writes it to the clips being recorded, finishing those which are long enough.
+func (r *clipRecorder) addFrame(f frame) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.bufferLen > 0 {
+		r.buffer = append(r.buffer, f)
+		oldest := 0
+		for oldest < len(r.buffer) && f.time.Sub(r.buffer[oldest].time) > r.bufferLen {
+			oldest++
+		}
+		r.buffer = r.buffer[oldest:]
+	}
+	active := r.recording[:0]
+	for _, rec := range r.recording {
+		rec.err = rec.writer.WriteFrame(f.data)
+		rec.remaining--
+		if rec.err != nil || rec.remaining <= 0 {
+			r.finish(rec)
+			continue
+		}
+		active = append(active, rec)
+	}
+	r.recording = active
+}
+
+// finish closes a clip and moves it to the output directory in the background.
+func (r *clipRecorder) finish(rec *recording) {
+	r.finishing.Add(1)
+	go func() {
+		defer r.finishing.Done()
+		defer close(rec.done)
+		if err := rec.writer.Close(); rec.err == nil {
+			rec.err = err
+		}
+		if rec.err == nil {
+			rec.err = moveFile(rec.tmpPath, rec.path)
+		}
+		if rec.err != nil {
+			r.logger.Errorw("failed to record clip", "file", rec.path, "error", rec.err)
+			//nolint:errcheck
+			os.Remove(rec.tmpPath)
+			return
+		}
+		r.logger.Infow("recorded clip", "file", rec.path)
+	}()
+}
+
+// moveFile renames a file, copying it when the paths are on different file systems.
+func moveFile(from, to string) error {
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+	//nolint:gosec
+	data, err := os.ReadFile(from)
+	if err != nil {
+		return err
+	}
+	// the clip only appears under its name once complete, so it is never synced half written
+	if err := os.WriteFile(to+".part", data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(to+".part", to); err != nil {
+		return err
+	}
+	return os.Remove(from)
+}
+
+// record starts recording a clip of the given length, beginning up to preTriggerSec before now with buffered frames.
+// It returns the recording and how many seconds of buffered frames it starts with.
+func (r *clipRecorder) record(durationSec, preTriggerSec float64) (*recording, float64, error) {
+	if durationSec <= 0 || durationSec > r.maxDurationSec {
+		return nil, 0, errors.Errorf("duration_sec must be above 0 and at most %v", r.maxDurationSec)
+	}
+	dir := filepath.Join(r.outputDir, r.Name().ShortName())
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, 0, err
+	}
+	now := time.Now()
+	fileName := fmt.Sprintf("%s_%s.mp4", r.Name().ShortName(), now.UTC().Format("2006-01-02T15_04_05.000Z"))
+	tmpPath := filepath.Join(os.TempDir(), fileName)
+	writer, err := newClipWriter(tmpPath, r.frameRate, r.videoCodec, r.logger)
+	if err != nil {
+		return nil, 0, err
+	}
+	rec := &recording{
+		writer:    writer,
+		tmpPath:   tmpPath,
+		path:      filepath.Join(dir, fileName),
+		remaining: int(durationSec * r.frameRate),
+		done:      make(chan struct{}),
+	}
+	if rec.remaining < 1 {
+		rec.remaining = 1
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	preTrigger := 0.
+	for _, f := range r.buffer {
+		if now.Sub(f.time).Seconds() > preTriggerSec {
+			continue
+		}
+		if preTrigger == 0 {
+			preTrigger = now.Sub(f.time).Seconds()
+		}
+		if rec.err = writer.WriteFrame(f.data); rec.err != nil {
+			r.finish(rec)
+			<-rec.done
+			return nil, 0, rec.err
+		}
+	}
+	r.recording = append(r.recording, rec)
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+	return rec, preTrigger, nil
+}
+
+// DoCommand handles "record_clip", which records duration_sec seconds of the camera to an MP4 and returns its path.
+// Up to pre_trigger_sec seconds from before the command are included, by default all of the buffer. The clip is
+// written in the background unless wait is true, in which case the command returns once the file is complete.
+func (r *clipRecorder) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if cmd["command"] != "record_clip" {
+		return nil, resource.ErrDoUnimplemented
+	}
+	durationSec, ok := cmd["duration_sec"].(float64)
+	if !ok {
+		return nil, errors.New("record_clip requires a duration_sec")
+	}
+	preTriggerSec := r.bufferLen.Seconds()
+	if raw, ok := cmd["pre_trigger_sec"]; ok {
+		if preTriggerSec, ok = raw.(float64); !ok || preTriggerSec < 0 {
+			return nil, errors.New("pre_trigger_sec must be a non-negative number")
+		}
+	}
+	rec, preTrigger, err := r.record(durationSec, preTriggerSec)
+	if err != nil {
+		return nil, err
+	}
+	if wait, _ := cmd["wait"].(bool); wait {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-rec.done:
+		}
+		if rec.err != nil {
+			return nil, rec.err
+		}
+	}
+	return map[string]interface{}{"file": rec.path, "pre_trigger_sec": preTrigger}, nil
+}
+
+// Close stops capturing and writes out the clips being recorded, cut short. The source camera is left open.
+func (r *clipRecorder) Close(ctx context.Context) error {
+	r.workers.Stop()
+	r.mu.Lock()
+	for _, rec := range r.recording {
+		r.finish(rec)
+	}
+	r.recording = nil
+	r.mu.Unlock()
+	r.finishing.Wait()
+	return nil
+}
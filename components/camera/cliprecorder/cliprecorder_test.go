@@ -0,0 +1,127 @@
+package cliprecorder
+
+import (
+	"context"
+	"image"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/gostream"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// countingWriter writes the number of frames it was given as the clip.
+type countingWriter struct {
+	path   string
+	mu     sync.Mutex
+	frames int
+}
+
+func (w *countingWriter) WriteFrame(jpeg []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.frames++
+	return nil
+}
+
+func (w *countingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return os.WriteFile(w.path, []byte(strconv.Itoa(w.frames)), 0o600)
+}
+
+func TestValidate(t *testing.T) {
+	deps, err := (&Config{Source: "cam", PreTriggerBufferSec: 5}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"cam"})
+
+	_, err = (&Config{}).Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "source"))
+	_, err = (&Config{Source: "cam", PreTriggerBufferSec: 120}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{Source: "cam", FrameRate: -1}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{Source: "cam", Codec: codecH265}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	_, err = (&Config{Source: "cam", Codec: "av1"}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestRecordClip(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	newClipWriter = func(path string, frameRate float64, videoCodec string, logger logging.Logger) (clipWriter, error) {
+		// clips are H.264 unless configured otherwise
+		test.That(t, videoCodec, test.ShouldEqual, codecH264)
+		return &countingWriter{path: path}, nil
+	}
+	defer func() {
+		newClipWriter = newFFMPEGClipWriter
+	}()
+
+	source := inject.NewCamera("source")
+	source.StreamFunc = func(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error) {
+		return gostream.NewEmbeddedVideoStreamFromReader(gostream.VideoReaderFunc(func(ctx context.Context) (image.Image, func(), error) {
+			return image.NewRGBA(image.Rect(0, 0, 4, 4)), func() {}, nil
+		})), nil
+	}
+	outputDir := t.TempDir()
+	rec := newClipRecorder(camera.Named("clips"), source, &Config{
+		Source:              "source",
+		OutputDir:           outputDir,
+		FrameRate:           50,
+		PreTriggerBufferSec: 0.2,
+		MaxDurationSec:      1,
+	}, logger)
+	defer func() {
+		test.That(t, rec.Close(ctx), test.ShouldBeNil)
+	}()
+
+	_, err := rec.DoCommand(ctx, map[string]interface{}{"command": "record_clip", "duration_sec": 2.})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = rec.DoCommand(ctx, map[string]interface{}{"command": "record_clip"})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = rec.DoCommand(ctx, map[string]interface{}{"command": "other"})
+	test.That(t, err, test.ShouldBeError, resource.ErrDoUnimplemented)
+
+	// wait for the buffer to fill
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		test.That(tb, len(rec.buffer), test.ShouldBeGreaterThanOrEqualTo, 5)
+	})
+
+	resp, err := rec.DoCommand(ctx, map[string]interface{}{"command": "record_clip", "duration_sec": 0.1, "wait": true})
+	test.That(t, err, test.ShouldBeNil)
+	path, ok := resp["file"].(string)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, filepath.Dir(path), test.ShouldEqual, filepath.Join(outputDir, "clips"))
+	test.That(t, filepath.Ext(path), test.ShouldEqual, ".mp4")
+	test.That(t, resp["pre_trigger_sec"], test.ShouldBeGreaterThan, 0)
+
+	data, err := os.ReadFile(path)
+	test.That(t, err, test.ShouldBeNil)
+	frames, err := strconv.Atoi(string(data))
+	test.That(t, err, test.ShouldBeNil)
+	// five frames recorded after the command, on top of those from the buffer
+	test.That(t, frames, test.ShouldBeGreaterThanOrEqualTo, 10)
+
+	// without pre-trigger frames only the recorded ones are written
+	resp, err = rec.DoCommand(ctx, map[string]interface{}{
+		"command": "record_clip", "duration_sec": 0.1, "pre_trigger_sec": 0., "wait": true,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	data, err = os.ReadFile(resp["file"].(string))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(data), test.ShouldEqual, "5")
+}
@@ -0,0 +1,114 @@
+package cliprecorder
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+)
+
+// A clipWriter encodes JPEG frames into a video file.
+type clipWriter interface {
+	WriteFrame(jpeg []byte) error
+	// Close finishes the file.
+	Close() error
+}
+
+// newClipWriter is swapped out in tests, which cannot count on ffmpeg being installed.
+var newClipWriter = newFFMPEGClipWriter
+
+// The codecs clips can be encoded with.
+const (
+	codecH264 = "h264"
+	codecH265 = "h265"
+)
+
+// hevcEncoders are the ffmpeg H.265 encoders in order of preference: the Jetson's NVMPI and V4L2 memory-to-memory
+// hardware encoders, NVENC on discrete NVIDIA GPUs, and the libx265 software encoder.
+var hevcEncoders = []string{"hevc_nvmpi", "hevc_v4l2m2m", "hevc_nvenc", "libx265"}
+
+// hevcEncoder returns the first of the hevcEncoders which can encode on this machine, or false if none can. ffmpeg
+// lists hardware encoders it was built with whether or not the hardware is there, so each is tried on a frame.
+var hevcEncoder = sync.OnceValues(func() (string, bool) {
+	for _, name := range hevcEncoders {
+		//nolint:gosec
+		probe := exec.Command("ffmpeg", "-hide_banner", "-loglevel", "error",
+			"-f", "lavfi", "-i", "color=size=256x256", "-frames:v", "1", "-c:v", name, "-f", "null", "-")
+		if probe.Run() == nil {
+			return name, true
+		}
+	}
+	return "", false
+})
+
+// encoderArgs returns the ffmpeg arguments encoding with the codec, falling back to H.264 when there is no H.265
+// encoder.
+func encoderArgs(videoCodec string, logger logging.Logger) []string {
+	if videoCodec == codecH265 {
+		name, ok := hevcEncoder()
+		switch {
+		case !ok:
+			logger.Warnw("no H.265 encoder found, recording clips as H.264", "encoders", hevcEncoders)
+		case name == "libx265":
+			// hvc1 is the tag players such as QuickTime and browsers need to play H.265 in an MP4
+			return []string{"-c:v", name, "-preset", "veryfast", "-tag:v", "hvc1"}
+		default:
+			return []string{"-c:v", name, "-tag:v", "hvc1"}
+		}
+	}
+	return []string{"-c:v", "libx264", "-preset", "veryfast"}
+}
+
+// ffmpegClipWriter pipes frames to an ffmpeg process which encodes them as H.264 or H.265 in an MP4.
+type ffmpegClipWriter struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stderr bytes.Buffer
+}
+
+func newFFMPEGClipWriter(path string, frameRate float64, videoCodec string, logger logging.Logger) (clipWriter, error) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return nil, errors.Wrap(err, "ffmpeg is needed to record clips")
+	}
+	w := &ffmpegClipWriter{}
+	args := []string{
+		"-y", "-loglevel", "error",
+		"-f", "image2pipe", "-c:v", "mjpeg", "-framerate", strconv.FormatFloat(frameRate, 'f', -1, 64), "-i", "-",
+		// yuv420p needs even dimensions
+		"-vf", "scale=trunc(iw/2)*2:trunc(ih/2)*2", "-pix_fmt", "yuv420p",
+	}
+	args = append(args, encoderArgs(videoCodec, logger)...)
+	args = append(args, "-movflags", "+faststart", path)
+	//nolint:gosec
+	w.cmd = exec.Command("ffmpeg", args...)
+	w.cmd.Stderr = &w.stderr
+	stdin, err := w.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	w.stdin = stdin
+	if err := w.cmd.Start(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *ffmpegClipWriter) WriteFrame(jpeg []byte) error {
+	_, err := w.stdin.Write(jpeg)
+	return err
+}
+
+func (w *ffmpegClipWriter) Close() error {
+	if err := w.stdin.Close(); err != nil {
+		return err
+	}
+	if err := w.cmd.Wait(); err != nil {
+		return errors.Wrapf(err, "ffmpeg failed: %s", bytes.TrimSpace(w.stderr.Bytes()))
+	}
+	return nil
+}
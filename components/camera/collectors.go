@@ -16,6 +16,9 @@ import (
 	"go.viam.com/rdk/utils"
 )
 
+// readImageMimeTypeKey is the additional_params key selecting the mime type ReadImage captures are encoded as.
+const readImageMimeTypeKey = "mime_type"
+
 type method int64
 
 const (
@@ -80,7 +83,7 @@ func newReadImageCollector(resource interface{}, params data.CollectorParams) (d
 		return nil, err
 	}
 	// choose the best/fastest representation
-	mimeType := params.MethodParams["mime_type"]
+	mimeType := params.MethodParams[readImageMimeTypeKey]
 	if mimeType == nil {
 		// TODO: Potentially log the actual mime type at collector instantiation or include in response.
 		strWrapper := wrapperspb.String(utils.MimeTypeRawRGBA)
@@ -96,7 +99,7 @@ func newReadImageCollector(resource interface{}, params data.CollectorParams) (d
 
 		ctx = context.WithValue(ctx, data.FromDMContextKey{}, true)
 
-		img, release, err := ReadImage(ctx, camera)
+		img, md, release, err := ReadImageWithMetadata(ctx, camera)
 		if err != nil {
 			// A modular filter component can be created to filter the readings from a component. The error ErrNoCaptureToStore
 			// is used in the datamanager to exclude readings from being captured and stored.
@@ -121,7 +124,7 @@ func newReadImageCollector(resource interface{}, params data.CollectorParams) (d
 		if err != nil {
 			return nil, err
 		}
-		return outBytes, nil
+		return data.TimestampedReading{Reading: outBytes, CapturedAt: md.CapturedAt}, nil
 	})
 	return data.NewCollector(cFunc, params)
 }
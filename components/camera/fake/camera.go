@@ -23,6 +23,7 @@ import (
 
 	"go.viam.com/rdk/components/camera"
 	"go.viam.com/rdk/components/camera/rtppassthrough"
+	"go.viam.com/rdk/components/fakeconditions"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/pointcloud"
 	"go.viam.com/rdk/resource"
@@ -78,6 +79,7 @@ func NewCamera(
 		Animated:       newConf.Animated,
 		RTPPassthrough: newConf.RTPPassthrough,
 		bufAndCBByID:   make(map[rtppassthrough.SubscriptionID]bufAndCB),
+		conditions:     fakeconditions.New(newConf.Config),
 		logger:         logger,
 	}
 	src, err := camera.NewVideoSourceFromReader(ctx, cam, resModel, camera.ColorStream)
@@ -112,6 +114,9 @@ type Config struct {
 	Height         int  `json:"height,omitempty"`
 	Animated       bool `json:"animated,omitempty"`
 	RTPPassthrough bool `json:"rtp_passthrough,omitempty"`
+
+	// Conditions simulates the latency of every read and the noise of each pixel of the images read.
+	fakeconditions.Config `json:",squash"`
 }
 
 // Validate checks that the config attributes are valid for a fake camera.
@@ -132,6 +137,10 @@ func (conf *Config) Validate(path string) ([]string, error) {
 		return nil, errors.Errorf("odd-number resolutions cannot be rendered, cannot use a width of %d", conf.Width)
 	}
 
+	if err := conf.Config.Validate(path); err != nil {
+		return nil, err
+	}
+
 	return nil, nil
 }
 
@@ -224,13 +233,22 @@ type Camera struct {
 	bufAndCBByID            map[rtppassthrough.SubscriptionID]bufAndCB
 	cacheImage              image.Image
 	cachePointCloud         pointcloud.PointCloud
+	conditions              *fakeconditions.Conditions
 	logger                  logging.Logger
 }
 
 // Read always returns the same image of a yellow to blue gradient.
 func (c *Camera) Read(ctx context.Context) (image.Image, func(), error) {
+	if err := c.conditions.Wait(ctx); err != nil {
+		return nil, nil, err
+	}
+	return c.conditions.NoiseImage(c.gradient()), func() {}, nil
+}
+
+// gradient returns the image of a yellow to blue gradient, without simulated noise.
+func (c *Camera) gradient() image.Image {
 	if c.cacheImage != nil {
-		return c.cacheImage, func() {}, nil
+		return c.cacheImage
 	}
 	width := float64(c.Width)
 	height := float64(c.Height)
@@ -259,11 +277,14 @@ func (c *Camera) Read(ctx context.Context) (image.Image, func(), error) {
 	if !c.Animated {
 		c.cacheImage = img
 	}
-	return rimage.ConvertImage(img), func() {}, nil
+	return rimage.ConvertImage(img)
 }
 
 // NextPointCloud always returns a pointcloud of a yellow to blue gradient, with the depth determined by the intensity of blue.
 func (c *Camera) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, error) {
+	if err := c.conditions.Wait(ctx); err != nil {
+		return nil, err
+	}
 	if c.cachePointCloud != nil {
 		return c.cachePointCloud, nil
 	}
@@ -3,12 +3,15 @@ package ffmpeg
 
 import (
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"image"
 	"image/jpeg"
 	"io"
+	"net/url"
 	"os/exec"
+	"strings"
 	"sync"
 	"sync/atomic"
 
@@ -19,6 +22,7 @@ import (
 	"go.viam.com/rdk/gostream"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
 	"go.viam.com/rdk/rimage/transform"
 )
 
@@ -31,6 +35,53 @@ type Config struct {
 	InputKWArgs          map[string]interface{}             `json:"input_kw_args,omitempty"`
 	Filters              []FilterConfig                     `json:"filters,omitempty"`
 	OutputKWArgs         map[string]interface{}             `json:"output_kw_args,omitempty"`
+	// Bayer passes the raw frames of a Bayer sensor through without debayering them, such as those of a V4L2
+	// device opened with an input_format of bayer_rggb8.
+	Bayer *BayerConfig `json:"bayer,omitempty"`
+}
+
+// BayerConfig describes the raw frames of a Bayer sensor.
+type BayerConfig struct {
+	Pattern string `json:"pattern"`
+	// BitDepth is 8 or 16, 8 when unset.
+	BitDepth int `json:"bit_depth,omitempty"`
+	Width    int `json:"width_px"`
+	Height   int `json:"height_px"`
+}
+
+// pixelFormat is the name ffmpeg gives the format of the raw frames.
+func (cfg *BayerConfig) pixelFormat() string {
+	if cfg.BitDepth == 16 {
+		return "bayer_" + strings.ToLower(cfg.Pattern) + "16le"
+	}
+	return "bayer_" + strings.ToLower(cfg.Pattern) + "8"
+}
+
+// readFrame reads a raw frame as ffmpeg writes it.
+func (cfg *BayerConfig) readFrame(r io.Reader) (image.Image, error) {
+	bitDepth := 8
+	if cfg.BitDepth == 16 {
+		bitDepth = 16
+	}
+	img, err := rimage.NewBayerImage(cfg.Width, cfg.Height, rimage.BayerPattern(cfg.Pattern), bitDepth)
+	if err != nil {
+		return nil, err
+	}
+	frame := make([]byte, cfg.Width*cfg.Height*bitDepth/8)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return nil, err
+	}
+	for y := 0; y < cfg.Height; y++ {
+		for x := 0; x < cfg.Width; x++ {
+			i := y*cfg.Width + x
+			if bitDepth == 8 {
+				img.SetRaw(x, y, uint16(frame[i]))
+			} else {
+				img.SetRaw(x, y, binary.LittleEndian.Uint16(frame[2*i:]))
+			}
+		}
+	}
+	return img, nil
 }
 
 // FilterConfig is a struct to used to configure ffmpeg filters.
@@ -49,6 +100,17 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 				cfg.CameraParameters.Width, cfg.CameraParameters.Height)
 		}
 	}
+	if cfg.Bayer != nil {
+		if err := rimage.BayerPattern(cfg.Bayer.Pattern).Validate(); err != nil {
+			return nil, resource.NewConfigValidationError(path, err)
+		}
+		if cfg.Bayer.BitDepth != 0 && cfg.Bayer.BitDepth != 8 && cfg.Bayer.BitDepth != 16 {
+			return nil, resource.NewConfigValidationError(path, fmt.Errorf("bayer bit_depth must be 8 or 16, got %d", cfg.Bayer.BitDepth))
+		}
+		if cfg.Bayer.Width <= 0 || cfg.Bayer.Height <= 0 {
+			return nil, resource.NewConfigValidationError(path, errors.New("bayer needs a positive width_px and height_px"))
+		}
+	}
 	return []string{}, nil
 }
 
@@ -105,6 +167,14 @@ func NewFFMPEGCamera(ctx context.Context, conf *Config, logger logging.Logger) (
 	}
 	outArgs["update"] = 1        // always interpret the filename as just a filename, not a pattern
 	outArgs["format"] = "image2" // select image file muxer, used to write video frames to image files
+	readFrame := jpeg.Decode
+	if conf.Bayer != nil {
+		// raw frames are written back to back, each the same size
+		delete(outArgs, "update")
+		outArgs["format"] = "rawvideo"
+		outArgs["pix_fmt"] = conf.Bayer.pixelFormat()
+		readFrame = conf.Bayer.readFrame
+	}
 
 	// instantiate camera with cancellable context that will be applied to all spawned processes
 	cancelableCtx, cancel := context.WithCancel(context.Background())
@@ -158,7 +228,7 @@ func NewFFMPEGCamera(ctx context.Context, conf *Config, logger logging.Logger) (
 			cmd := stream.WithOutput(out).WithErrorOutput(stderrWriter{
 				logger: logger,
 			}).Compile()
-			logger.Infow("Execing ffmpeg", "cmd", cmd.String())
+			logger.Infow("Execing ffmpeg", "cmd", redactedCommand(cmd.String(), conf.VideoPath))
 			err := cmd.Run()
 			logger.Debugw("ffmpeg exited", "err", err)
 		}
@@ -177,7 +247,7 @@ func NewFFMPEGCamera(ctx context.Context, conf *Config, logger logging.Logger) (
 			if cancelableCtx.Err() != nil {
 				return
 			}
-			img, err := jpeg.Decode(in)
+			img, err := readFrame(in)
 			if err != nil {
 				continue
 			}
@@ -213,6 +283,19 @@ func NewFFMPEGCamera(ctx context.Context, conf *Config, logger logging.Logger) (
 		camera.ColorStream)
 }
 
+// redactedCommand hides the password of a video path, such as an RTSP URL with credentials, in the command line of
+// ffmpeg so it can be logged.
+func redactedCommand(cmd, videoPath string) string {
+	u, err := url.Parse(videoPath)
+	if err != nil || u.User == nil {
+		return cmd
+	}
+	if _, hasPassword := u.User.Password(); !hasPassword {
+		return cmd
+	}
+	return strings.ReplaceAll(cmd, videoPath, u.Redacted())
+}
+
 func (fc *ffmpegCamera) Close(ctx context.Context) error {
 	fc.cancelFunc()
 	viamutils.UncheckedError(fc.inClose())
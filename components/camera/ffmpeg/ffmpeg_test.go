@@ -1,6 +1,7 @@
 package ffmpeg
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"testing"
@@ -9,6 +10,7 @@ import (
 	"go.viam.com/utils/artifact"
 
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/rimage"
 )
 
 func TestFFMPEGCamera(t *testing.T) {
@@ -36,3 +38,37 @@ func TestFFMPEGNotFound(t *testing.T) {
 	_, err := NewFFMPEGCamera(context.Background(), nil, nil)
 	test.That(t, err.Error(), test.ShouldContainSubstring, "not found")
 }
+
+func TestBayerConfig(t *testing.T) {
+	conf := &Config{VideoPath: "/dev/video0", Bayer: &BayerConfig{Pattern: "GRBG", Width: 4, Height: 2}}
+	_, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, conf.Bayer.pixelFormat(), test.ShouldEqual, "bayer_grbg8")
+
+	img, err := conf.Bayer.readFrame(bytes.NewReader([]byte{0, 1, 2, 3, 4, 5, 6, 7}))
+	test.That(t, err, test.ShouldBeNil)
+	raw := img.(*rimage.BayerImage)
+	test.That(t, raw.Pattern, test.ShouldEqual, rimage.BayerGRBG)
+	test.That(t, raw.Raw(1, 1), test.ShouldEqual, 5)
+	_, err = conf.Bayer.readFrame(bytes.NewReader([]byte{0, 1, 2}))
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.Bayer.BitDepth = 16
+	test.That(t, conf.Bayer.pixelFormat(), test.ShouldEqual, "bayer_grbg16le")
+	_, err = conf.Bayer.readFrame(bytes.NewReader(make([]byte, 15)))
+	test.That(t, err, test.ShouldNotBeNil)
+	frame := make([]byte, 16)
+	frame[14], frame[15] = 0x34, 0x12
+	img, err = conf.Bayer.readFrame(bytes.NewReader(frame))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, img.(*rimage.BayerImage).Raw(3, 1), test.ShouldEqual, 0x1234)
+
+	for _, bad := range []*BayerConfig{
+		{Pattern: "RGB", Width: 4, Height: 2},
+		{Pattern: "RGGB", BitDepth: 12, Width: 4, Height: 2},
+		{Pattern: "RGGB"},
+	} {
+		_, err := (&Config{Bayer: bad}).Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
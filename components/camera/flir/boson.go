@@ -0,0 +1,171 @@
+package flir
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"os/exec"
+	"sync"
+
+	"github.com/pkg/errors"
+	ffmpeg "github.com/u2takey/ffmpeg-go"
+	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var bosonModel = resource.DefaultModelFamily.WithModel("flir_boson")
+
+const (
+	defaultBosonWidth  = 640
+	defaultBosonHeight = 512
+)
+
+// BosonConfig is the attribute struct for FLIR Boson cores, read over USB. The core must be radiometric, with its
+// TLinear output turned on, and its 16-bit video is read from V4L2 with ffmpeg.
+type BosonConfig struct {
+	// VideoPath is the V4L2 device of the core, such as /dev/video0.
+	VideoPath string `json:"video_path"`
+	// Width and Height are the resolution of the core, 640x512 when unset, or 320x256.
+	Width  int `json:"width_px,omitempty"`
+	Height int `json:"height_px,omitempty"`
+	ThermalConfig
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *BosonConfig) Validate(path string) ([]string, error) {
+	if cfg.VideoPath == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "video_path")
+	}
+	if cfg.Width < 0 || cfg.Height < 0 || (cfg.Width == 0) != (cfg.Height == 0) {
+		return nil, resource.NewConfigValidationError(path, errors.New("width_px and height_px must both be positive or both unset"))
+	}
+	if err := cfg.ThermalConfig.Validate(path); err != nil {
+		return nil, err
+	}
+	return []string{}, nil
+}
+
+func init() {
+	resource.RegisterComponent(camera.API, bosonModel, resource.Registration[camera.Camera, *BosonConfig]{
+		Constructor: func(
+			ctx context.Context, _ resource.Dependencies, conf resource.Config, logger logging.Logger,
+		) (camera.Camera, error) {
+			newConf, err := resource.NativeConfig[*BosonConfig](conf)
+			if err != nil {
+				return nil, err
+			}
+			src, err := newBosonSource(newConf, logger)
+			if err != nil {
+				return nil, err
+			}
+			return newThermalCamera(ctx, conf.ResourceName(), src, &newConf.ThermalConfig, logger)
+		},
+	})
+}
+
+// bosonSource reads the frames of a Boson from an ffmpeg process, which is restarted whenever it exits.
+type bosonSource struct {
+	frames                  chan *image.Gray16
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+	inClose                 func() error
+	outClose                func() error
+}
+
+func newBosonSource(conf *BosonConfig, logger logging.Logger) (*bosonSource, error) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return nil, err
+	}
+	width, height := conf.Width, conf.Height
+	if width == 0 {
+		width, height = defaultBosonWidth, defaultBosonHeight
+	}
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	in, out := io.Pipe()
+	bs := &bosonSource{
+		frames:   make(chan *image.Gray16, 1),
+		cancel:   cancel,
+		inClose:  in.Close,
+		outClose: out.Close,
+	}
+
+	bs.activeBackgroundWorkers.Add(1)
+	goutils.ManagedGo(func() {
+		for cancelCtx.Err() == nil {
+			stream := ffmpeg.Input(conf.VideoPath, ffmpeg.KwArgs{
+				"f":            "v4l2",
+				"input_format": "y16",
+				"video_size":   fmt.Sprintf("%dx%d", width, height),
+			}).Output("pipe:", ffmpeg.KwArgs{
+				// big endian samples are laid out as the pixels of a Gray16 are, so frames are read without conversion
+				"format":  "rawvideo",
+				"pix_fmt": "gray16be",
+			})
+			stream.Context = cancelCtx
+			cmd := stream.WithOutput(out).WithErrorOutput(ffmpegLogWriter{logger}).Compile()
+			logger.Infow("execing ffmpeg for boson", "cmd", cmd.String())
+			err := cmd.Run()
+			logger.Debugw("ffmpeg exited", "err", err)
+			goutils.SelectContextOrWait(cancelCtx, readErrorBackoff)
+		}
+	}, bs.activeBackgroundWorkers.Done)
+
+	bs.activeBackgroundWorkers.Add(1)
+	goutils.ManagedGo(func() {
+		for cancelCtx.Err() == nil {
+			frame, err := readGray16Frame(in, width, height)
+			if err != nil {
+				continue
+			}
+			// only the latest frame is kept
+			select {
+			case <-bs.frames:
+			default:
+			}
+			bs.frames <- frame
+		}
+	}, bs.activeBackgroundWorkers.Done)
+	return bs, nil
+}
+
+// readGray16Frame reads a frame of big endian 16-bit pixels.
+func readGray16Frame(r io.Reader, width, height int) (*image.Gray16, error) {
+	frame := image.NewGray16(image.Rect(0, 0, width, height))
+	if _, err := io.ReadFull(r, frame.Pix); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+func (bs *bosonSource) nextFrame(ctx context.Context) (*image.Gray16, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case frame := <-bs.frames:
+		return frame, nil
+	}
+}
+
+func (bs *bosonSource) close(ctx context.Context) error {
+	bs.cancel()
+	// closing the pipe unblocks a read of it, and lets ffmpeg exit
+	err := multierr.Combine(bs.inClose(), bs.outClose())
+	bs.activeBackgroundWorkers.Wait()
+	return err
+}
+
+// ffmpegLogWriter logs the output of ffmpeg.
+type ffmpegLogWriter struct {
+	logger logging.Logger
+}
+
+func (w ffmpegLogWriter) Write(p []byte) (int, error) {
+	w.logger.Debug(string(p))
+	return len(p), nil
+}
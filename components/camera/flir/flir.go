@@ -0,0 +1,354 @@
+// Package flir implements cameras for FLIR Lepton and Boson thermal cores. Both are read in their radiometric mode,
+// where each pixel is a temperature, and stream a false color image of it. The temperatures themselves are the
+// "temperature" image of Images, a 16-bit image in hundredths of a kelvin, and can be queried through DoCommand.
+//
+// Automatic gain control, which spreads the temperatures of a scene over the colors of the palette, and emissivity
+// correction are done on the temperatures rather than by the cores, so both models are configured the same way.
+package flir
+
+import (
+	"context"
+	"image"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/gostream"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage/transform"
+)
+
+// The names of the images returned by Images.
+const (
+	ColorImageName       = "color"
+	TemperatureImageName = "temperature"
+)
+
+const (
+	zeroCelsiusK = 273.15
+	// centikelvin is the unit of the temperature image.
+	centikelvin = 0.01
+	// defaultReflectedTempC is the temperature of the surroundings reflected by the scene when it is not configured.
+	defaultReflectedTempC = 22.
+	// readErrorBackoff is how long the frames of a core stop being read after an error.
+	readErrorBackoff = 100 * time.Millisecond
+)
+
+// ThermalConfig are the attributes shared by the models of thermal cameras.
+type ThermalConfig struct {
+	// Emissivity is how much of the radiation of a black body at the same temperature the scene gives off, from 0 to
+	// 1. The cores measure as if it were 1, which makes shiny and metallic surfaces read colder than they are.
+	Emissivity *float64 `json:"emissivity,omitempty"`
+	// ReflectedTempC is the temperature of the surroundings the scene reflects, which makes up the rest of the
+	// radiation the core sees when the emissivity is below 1.
+	ReflectedTempC *float64 `json:"reflected_temp_c,omitempty"`
+	// TLinearResolutionK is the kelvin per count of the radiometric output of the core, 0.01 or 0.1. It defaults to
+	// 0.01, and 0.1 widens the range the core can measure, up to 6553K.
+	TLinearResolutionK float64 `json:"tlinear_resolution_k,omitempty"`
+	// AGC is how temperatures are spread over the palette: "linear" from the coldest to the hottest of each frame,
+	// "histogram" so that each color covers as many pixels, or "fixed" from MinTempC to MaxTempC.
+	AGC      string   `json:"agc,omitempty"`
+	MinTempC *float64 `json:"min_temp_c,omitempty"`
+	MaxTempC *float64 `json:"max_temp_c,omitempty"`
+	// Palette is the false color of the stream, one of "ironbow", "rainbow", "white_hot" and "black_hot".
+	Palette string `json:"palette,omitempty"`
+
+	CameraParameters *transform.PinholeCameraIntrinsics `json:"intrinsic_parameters,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *ThermalConfig) Validate(path string) error {
+	if cfg.Emissivity != nil && (*cfg.Emissivity <= 0 || *cfg.Emissivity > 1) {
+		return resource.NewConfigValidationError(path, errors.Errorf("emissivity must be above 0 and at most 1, got %v", *cfg.Emissivity))
+	}
+	if cfg.ReflectedTempC != nil && *cfg.ReflectedTempC <= -zeroCelsiusK {
+		return resource.NewConfigValidationError(path, errors.New("reflected_temp_c must be above absolute zero"))
+	}
+	switch cfg.TLinearResolutionK {
+	case 0, 0.01, 0.1:
+	default:
+		return resource.NewConfigValidationError(path,
+			errors.Errorf("tlinear_resolution_k must be 0.01 or 0.1, got %v", cfg.TLinearResolutionK))
+	}
+	switch cfg.AGC {
+	case "", agcLinear, agcHistogram:
+	case agcFixed:
+		if cfg.MinTempC == nil {
+			return resource.NewConfigValidationFieldRequiredError(path, "min_temp_c")
+		}
+		if cfg.MaxTempC == nil {
+			return resource.NewConfigValidationFieldRequiredError(path, "max_temp_c")
+		}
+		if *cfg.MinTempC >= *cfg.MaxTempC {
+			return resource.NewConfigValidationError(path, errors.New("min_temp_c must be below max_temp_c"))
+		}
+	default:
+		return resource.NewConfigValidationError(path,
+			errors.Errorf("agc must be %q, %q or %q, got %q", agcLinear, agcHistogram, agcFixed, cfg.AGC))
+	}
+	if _, ok := palettes[cfg.Palette]; !ok && cfg.Palette != "" {
+		return resource.NewConfigValidationError(path, errors.Errorf("unknown palette %q", cfg.Palette))
+	}
+	return nil
+}
+
+// tlinearResolution returns the kelvin per count of the radiometric output.
+func (cfg *ThermalConfig) tlinearResolution() float64 {
+	if cfg.TLinearResolutionK == 0 {
+		return centikelvin
+	}
+	return cfg.TLinearResolutionK
+}
+
+// A frameSource reads the radiometric frames of a core.
+type frameSource interface {
+	// nextFrame blocks until the next frame, whose pixels are counts of the TLinear resolution, or until the context
+	// is done.
+	nextFrame(ctx context.Context) (*image.Gray16, error)
+	close(ctx context.Context) error
+}
+
+// A thermometer is a frame source which can also measure the temperature of its sensor, in kelvin.
+type thermometer interface {
+	sensorTemperature(ctx context.Context) (float64, error)
+}
+
+// radiometry converts the counts of a core to temperatures in hundredths of a kelvin, correcting for emissivity.
+type radiometry struct {
+	// lut is the temperature of each count, nil when the counts already are the temperatures.
+	lut []uint16
+}
+
+func newRadiometry(cfg *ThermalConfig) *radiometry {
+	emissivity := 1.
+	if cfg.Emissivity != nil {
+		emissivity = *cfg.Emissivity
+	}
+	resolution := cfg.tlinearResolution()
+	if emissivity == 1 && resolution == centikelvin {
+		return &radiometry{}
+	}
+	reflectedK := defaultReflectedTempC + zeroCelsiusK
+	if cfg.ReflectedTempC != nil {
+		reflectedK = *cfg.ReflectedTempC + zeroCelsiusK
+	}
+	lut := make([]uint16, math.MaxUint16+1)
+	for count := range lut {
+		lut[count] = toCentikelvin(correctEmissivity(float64(count)*resolution, emissivity, reflectedK))
+	}
+	return &radiometry{lut: lut}
+}
+
+// correctEmissivity returns the temperature of a surface the core measured at measuredK, as the radiation of the
+// surface and that of its surroundings it reflects add up to the radiation the core saw, by the Stefan-Boltzmann law.
+func correctEmissivity(measuredK, emissivity, reflectedK float64) float64 {
+	if emissivity == 1 {
+		return measuredK
+	}
+	emitted := math.Pow(measuredK, 4) - (1-emissivity)*math.Pow(reflectedK, 4)
+	if emitted <= 0 {
+		return 0
+	}
+	return math.Pow(emitted/emissivity, 0.25)
+}
+
+func toCentikelvin(k float64) uint16 {
+	return uint16(math.Round(math.Max(0, math.Min(k/centikelvin, math.MaxUint16))))
+}
+
+// temperatures returns the temperatures of a frame of counts, which is the frame itself when no conversion is needed.
+func (r *radiometry) temperatures(counts *image.Gray16) *image.Gray16 {
+	if r.lut == nil {
+		return counts
+	}
+	temps := image.NewGray16(counts.Rect)
+	for i := 0; i+1 < len(counts.Pix); i += 2 {
+		t := r.lut[uint16(counts.Pix[i])<<8|uint16(counts.Pix[i+1])]
+		temps.Pix[i], temps.Pix[i+1] = uint8(t>>8), uint8(t)
+	}
+	return temps
+}
+
+// celsius returns a temperature of the temperature image in degrees Celsius.
+func celsius(ck uint16) float64 {
+	return float64(ck)*centikelvin - zeroCelsiusK
+}
+
+// thermalCamera streams the false color of the temperatures read from a core.
+type thermalCamera struct {
+	resource.Named
+	resource.AlwaysRebuild
+	camera.VideoSource
+
+	source     frameSource
+	radiometry *radiometry
+	colorizer  *colorizer
+	logger     logging.Logger
+
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+	gotFirstFrame           chan struct{}
+
+	mu         sync.Mutex
+	latest     *image.Gray16
+	capturedAt time.Time
+}
+
+// newThermalCamera starts reading the frames of a source, which it closes along with the camera.
+func newThermalCamera(
+	ctx context.Context, name resource.Name, source frameSource, conf *ThermalConfig, logger logging.Logger,
+) (camera.Camera, error) {
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	tc := &thermalCamera{
+		Named:         name.AsNamed(),
+		source:        source,
+		radiometry:    newRadiometry(conf),
+		colorizer:     newColorizer(conf),
+		logger:        logger,
+		cancel:        cancel,
+		gotFirstFrame: make(chan struct{}),
+	}
+	src, err := camera.NewVideoSourceFromReader(
+		ctx,
+		gostream.VideoReaderFunc(tc.read),
+		&transform.PinholeCameraModel{PinholeCameraIntrinsics: conf.CameraParameters},
+		camera.ColorStream,
+	)
+	if err != nil {
+		cancel()
+		return nil, multierr.Combine(err, source.close(ctx))
+	}
+	tc.VideoSource = src
+
+	tc.activeBackgroundWorkers.Add(1)
+	goutils.ManagedGo(func() { tc.readFrames(cancelCtx) }, tc.activeBackgroundWorkers.Done)
+	return tc, nil
+}
+
+// readFrames keeps the latest temperatures of the core. Cores are read all the time, since they lose the timing of
+// their frames when they are not.
+func (tc *thermalCamera) readFrames(ctx context.Context) {
+	first := true
+	for ctx.Err() == nil {
+		counts, err := tc.source.nextFrame(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				tc.logger.CDebugw(ctx, "cannot read thermal frame", "error", err)
+				goutils.SelectContextOrWait(ctx, readErrorBackoff)
+			}
+			continue
+		}
+		temps := tc.radiometry.temperatures(counts)
+		tc.mu.Lock()
+		tc.latest, tc.capturedAt = temps, time.Now()
+		tc.mu.Unlock()
+		if first {
+			close(tc.gotFirstFrame)
+			first = false
+		}
+	}
+}
+
+// temperatures returns the latest temperatures, waiting for the first.
+func (tc *thermalCamera) temperatures(ctx context.Context) (*image.Gray16, time.Time, error) {
+	select {
+	case <-ctx.Done():
+		return nil, time.Time{}, ctx.Err()
+	case <-tc.gotFirstFrame:
+	}
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.latest, tc.capturedAt, nil
+}
+
+func (tc *thermalCamera) read(ctx context.Context) (image.Image, func(), error) {
+	temps, _, err := tc.temperatures(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tc.colorizer.colorize(temps), func() {}, nil
+}
+
+// Images returns the false color image and the temperature image of the same frame.
+func (tc *thermalCamera) Images(ctx context.Context) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+	temps, capturedAt, err := tc.temperatures(ctx)
+	if err != nil {
+		return nil, resource.ResponseMetadata{}, err
+	}
+	return []camera.NamedImage{
+		{Image: tc.colorizer.colorize(temps), SourceName: ColorImageName},
+		{Image: temps, SourceName: TemperatureImageName},
+	}, resource.ResponseMetadata{CapturedAt: capturedAt}, nil
+}
+
+// DoCommand runs "temperatures", which returns the coldest, hottest, mean and center temperatures of the latest
+// frame in degrees Celsius, and "spot", which returns the temperature of the pixel at "x" and "y".
+func (tc *thermalCamera) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	switch cmd["command"] {
+	case "temperatures":
+		temps, capturedAt, err := tc.temperatures(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp := temperatureStats(temps)
+		resp["captured_at"] = capturedAt.Format(time.RFC3339Nano)
+		if th, ok := tc.source.(thermometer); ok {
+			sensorK, err := th.sensorTemperature(ctx)
+			if err != nil {
+				return nil, err
+			}
+			resp["sensor_temp_c"] = sensorK - zeroCelsiusK
+		}
+		return resp, nil
+	case "spot":
+		x, okX := cmd["x"].(float64)
+		y, okY := cmd["y"].(float64)
+		if !okX || !okY {
+			return nil, errors.New("spot needs the pixel as numbers x and y")
+		}
+		temps, _, err := tc.temperatures(ctx)
+		if err != nil {
+			return nil, err
+		}
+		pt := image.Pt(int(x), int(y))
+		if !pt.In(temps.Bounds()) {
+			return nil, errors.Errorf("pixel %v is outside of the image %v", pt, temps.Bounds())
+		}
+		return map[string]interface{}{"temp_c": celsius(temps.Gray16At(pt.X, pt.Y).Y)}, nil
+	default:
+		return nil, resource.ErrDoUnimplemented
+	}
+}
+
+// temperatureStats returns the coldest, hottest, mean and center temperatures of an image in degrees Celsius.
+func temperatureStats(temps *image.Gray16) map[string]interface{} {
+	b := temps.Bounds()
+	minCK, maxCK := uint16(math.MaxUint16), uint16(0)
+	var sum float64
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			t := temps.Gray16At(x, y).Y
+			minCK, maxCK = min(minCK, t), max(maxCK, t)
+			sum += float64(t)
+		}
+	}
+	center := temps.Gray16At((b.Min.X+b.Max.X)/2, (b.Min.Y+b.Max.Y)/2).Y
+	return map[string]interface{}{
+		"min_c":    celsius(minCK),
+		"max_c":    celsius(maxCK),
+		"mean_c":   sum/float64(b.Dx()*b.Dy())*centikelvin - zeroCelsiusK,
+		"center_c": celsius(center),
+	}
+}
+
+func (tc *thermalCamera) Close(ctx context.Context) error {
+	tc.cancel()
+	tc.activeBackgroundWorkers.Wait()
+	return multierr.Combine(tc.VideoSource.Close(ctx), tc.source.close(ctx))
+}
@@ -0,0 +1,83 @@
+package flir
+
+import (
+	"image"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestThermalConfigValidate(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+
+	test.That(t, (&ThermalConfig{}).Validate("path"), test.ShouldBeNil)
+	test.That(t, (&ThermalConfig{Emissivity: f(0.95), AGC: agcHistogram, Palette: "rainbow"}).Validate("path"), test.ShouldBeNil)
+
+	err := (&ThermalConfig{Emissivity: f(0)}).Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "emissivity")
+	err = (&ThermalConfig{TLinearResolutionK: 1}).Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "tlinear_resolution_k")
+	err = (&ThermalConfig{AGC: agcFixed, MinTempC: f(10)}).Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "max_temp_c")
+	err = (&ThermalConfig{AGC: agcFixed, MinTempC: f(10), MaxTempC: f(10)}).Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "below")
+	err = (&ThermalConfig{Palette: "sepia"}).Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "sepia")
+
+	_, err = (&LeptonConfig{ChipSelect: "0"}).Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "spi_bus")
+	_, err = (&BosonConfig{}).Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "video_path")
+	_, err = (&BosonConfig{VideoPath: "/dev/video0", Width: 320}).Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "height_px")
+}
+
+func TestRadiometry(t *testing.T) {
+	// a black body reads its own temperature
+	test.That(t, correctEmissivity(350, 1, 295), test.ShouldEqual, 350)
+	// a surface as warm as its surroundings reads their temperature whatever its emissivity
+	test.That(t, correctEmissivity(295, 0.5, 295), test.ShouldAlmostEqual, 295)
+	// a poor emitter warmer than its surroundings reads colder than it is
+	test.That(t, correctEmissivity(320, 0.5, 295), test.ShouldBeGreaterThan, 320)
+
+	counts := image.NewGray16(image.Rect(0, 0, 2, 1))
+	counts.Pix = []uint8{0x00, 0x01, 0x0b, 0xb8}
+
+	test.That(t, newRadiometry(&ThermalConfig{}).temperatures(counts), test.ShouldEqual, counts)
+
+	temps := newRadiometry(&ThermalConfig{TLinearResolutionK: 0.1}).temperatures(counts)
+	test.That(t, temps.Gray16At(0, 0).Y, test.ShouldEqual, 10)
+	test.That(t, temps.Gray16At(1, 0).Y, test.ShouldEqual, 30000)
+	test.That(t, celsius(temps.Gray16At(1, 0).Y), test.ShouldAlmostEqual, 26.85)
+}
+
+func TestColorizer(t *testing.T) {
+	temps := image.NewGray16(image.Rect(0, 0, 4, 1))
+	for x, ck := range []uint16{29000, 29000, 29100, 31000} {
+		temps.Pix[2*x], temps.Pix[2*x+1] = uint8(ck>>8), uint8(ck)
+	}
+	gray := palettes["white_hot"]
+	levels := func(c *colorizer) []uint8 {
+		img := c.colorize(temps)
+		var out []uint8
+		for x := 0; x < 4; x++ {
+			out = append(out, img.RGBAAt(x, 0).R)
+		}
+		return out
+	}
+
+	linear := newColorizer(&ThermalConfig{Palette: "white_hot"})
+	test.That(t, linear.palette, test.ShouldEqual, gray)
+	test.That(t, levels(linear), test.ShouldResemble, []uint8{0, 0, 12, 255})
+
+	// equalization spreads the colors over the pixels rather than over the temperatures
+	histogram := newColorizer(&ThermalConfig{Palette: "white_hot", AGC: agcHistogram})
+	test.That(t, levels(histogram), test.ShouldResemble, []uint8{0, 0, 128, 255})
+	test.That(t, levels(histogram), test.ShouldResemble, []uint8{0, 0, 128, 255})
+
+	minC, maxC := 290-zeroCelsiusK, 300-zeroCelsiusK
+	fixed := newColorizer(&ThermalConfig{Palette: "white_hot", AGC: agcFixed, MinTempC: &minC, MaxTempC: &maxC})
+	test.That(t, levels(fixed), test.ShouldResemble, []uint8{0, 0, 25, 255})
+
+	test.That(t, newColorizer(&ThermalConfig{}).palette, test.ShouldEqual, palettes["ironbow"])
+}
@@ -0,0 +1,436 @@
+package flir
+
+import (
+	"context"
+	"encoding/binary"
+	"image"
+	"time"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var leptonModel = resource.DefaultModelFamily.WithModel("flir_lepton")
+
+// The video over SPI (VoSPI) protocol of the Lepton. Frames are sent in packets of a row of 80 pixels, or half a row
+// on the Lepton 3, which splits each frame into 4 segments of 60 packets.
+const (
+	vospiPacketSize        = 164
+	vospiHeaderSize        = 4
+	vospiPacketPixels      = 80
+	vospiPacketsPerSegment = 60
+	// vospiSegmentPacket is the packet whose ID carries the segment number on the Lepton 3.
+	vospiSegmentPacket = 20
+	// vospiPacketsPerXfer is how many packets are read in one SPI transfer, which spidev limits to 4096 bytes.
+	vospiPacketsPerXfer = 24
+	// vospiResyncDelay is how long the chip select must stay high for the Lepton to start sending again from the
+	// first packet of a segment.
+	vospiResyncDelay = 200 * time.Millisecond
+	leptonSPIMode    = 3
+	// maxLeptonSPIBaudRate is the fastest clock of the Lepton, which is fast enough to read every segment.
+	maxLeptonSPIBaudRate = 20_000_000
+)
+
+var errOutOfSync = errors.New("lepton VoSPI packets are out of sync")
+
+// LeptonConfig is the attribute struct for FLIR Lepton cores, read over SPI.
+type LeptonConfig struct {
+	SPIBus      string `json:"spi_bus"`
+	ChipSelect  string `json:"chip_select"`
+	SPIBaudRate int    `json:"spi_baud_rate,omitempty"`
+	// I2CBus is the bus of the command interface of the core, through which its radiometric output is turned on.
+	// Without it the core must already be in radiometric mode with TLinear enabled.
+	I2CBus string `json:"i2c_bus,omitempty"`
+	// Version is the major version of the core, 3 for the 160x120 Lepton 3 and 3.5 and 2 for the 80x60 Lepton 2 and
+	// 2.5. It defaults to 3.
+	Version int `json:"version,omitempty"`
+	ThermalConfig
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *LeptonConfig) Validate(path string) ([]string, error) {
+	if cfg.SPIBus == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "spi_bus")
+	}
+	if cfg.ChipSelect == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "chip_select")
+	}
+	if cfg.SPIBaudRate < 0 || cfg.SPIBaudRate > maxLeptonSPIBaudRate {
+		return nil, resource.NewConfigValidationError(path,
+			errors.Errorf("spi_baud_rate must be at most %d, got %d", maxLeptonSPIBaudRate, cfg.SPIBaudRate))
+	}
+	if cfg.Version != 0 && cfg.Version != 2 && cfg.Version != 3 {
+		return nil, resource.NewConfigValidationError(path, errors.Errorf("version must be 2 or 3, got %d", cfg.Version))
+	}
+	if err := cfg.ThermalConfig.Validate(path); err != nil {
+		return nil, err
+	}
+	return []string{}, nil
+}
+
+func init() {
+	resource.RegisterComponent(camera.API, leptonModel, resource.Registration[camera.Camera, *LeptonConfig]{
+		Constructor: func(
+			ctx context.Context, _ resource.Dependencies, conf resource.Config, logger logging.Logger,
+		) (camera.Camera, error) {
+			newConf, err := resource.NativeConfig[*LeptonConfig](conf)
+			if err != nil {
+				return nil, err
+			}
+			spiBus, i2cBus, err := openLeptonBuses(newConf)
+			if err != nil {
+				return nil, err
+			}
+			return newLepton(ctx, conf.ResourceName(), newConf, spiBus, i2cBus, logger)
+		},
+	})
+}
+
+// newLepton returns a camera reading a Lepton over the given buses, the I2C bus being nil when it is not connected.
+func newLepton(
+	ctx context.Context, name resource.Name, conf *LeptonConfig, spiBus buses.SPI, i2cBus buses.I2C, logger logging.Logger,
+) (camera.Camera, error) {
+	src := &leptonSource{
+		spi:        spiBus,
+		chipSelect: conf.ChipSelect,
+		baud:       uint(conf.SPIBaudRate),
+		assembler:  newFrameAssembler(conf.Version),
+		tx:         make([]byte, vospiPacketsPerXfer*vospiPacketSize),
+	}
+	if src.baud == 0 {
+		src.baud = maxLeptonSPIBaudRate
+	}
+	if i2cBus != nil {
+		src.cci = &leptonCCI{bus: i2cBus}
+		if err := src.cci.enableRadiometry(ctx, conf.tlinearResolution()); err != nil {
+			return nil, errors.Wrap(err, "cannot turn on the radiometric output of the lepton")
+		}
+	}
+	return newThermalCamera(ctx, name, src, &conf.ThermalConfig, logger)
+}
+
+// leptonSource reads the frames of a Lepton over SPI.
+type leptonSource struct {
+	spi        buses.SPI
+	chipSelect string
+	baud       uint
+	cci        *leptonCCI
+	assembler  *frameAssembler
+	tx         []byte
+}
+
+func (ls *leptonSource) nextFrame(ctx context.Context) (*image.Gray16, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		frame, err := ls.readPackets(ctx)
+		if errors.Is(err, errOutOfSync) {
+			// the lepton starts again from the first packet of a segment once it has been deselected for long
+			// enough
+			ls.assembler.reset()
+			if !goutils.SelectContextOrWait(ctx, vospiResyncDelay) {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		if err != nil || frame != nil {
+			return frame, err
+		}
+	}
+}
+
+// readPackets reads a transfer of packets, returning a frame if it was completed by them.
+func (ls *leptonSource) readPackets(ctx context.Context) (*image.Gray16, error) {
+	hnd, err := ls.spi.OpenHandle()
+	if err != nil {
+		return nil, err
+	}
+	rx, err := hnd.Xfer(ctx, ls.baud, ls.chipSelect, leptonSPIMode, ls.tx)
+	if closeErr := hnd.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	var frame *image.Gray16
+	for i := 0; i+vospiPacketSize <= len(rx); i += vospiPacketSize {
+		// a frame completed in the middle of a transfer is kept, and the packets after it start the next one
+		completed, err := ls.assembler.add(rx[i : i+vospiPacketSize])
+		if err != nil {
+			return nil, err
+		}
+		if completed != nil {
+			frame = completed
+		}
+	}
+	return frame, nil
+}
+
+func (ls *leptonSource) sensorTemperature(ctx context.Context) (float64, error) {
+	if ls.cci == nil {
+		return 0, errors.New("the sensor temperature of the lepton needs its i2c_bus")
+	}
+	return ls.cci.fpaTemperature(ctx)
+}
+
+func (ls *leptonSource) close(ctx context.Context) error {
+	return ls.spi.Close(ctx)
+}
+
+// frameAssembler collects VoSPI packets into frames.
+type frameAssembler struct {
+	segments int
+	width    int
+	height   int
+
+	// segment holds the packets of the segment being read, and next is the number of the packet it expects.
+	segment []byte
+	next    int
+	// frame is the frame being assembled, and seen the bit mask of its segments read so far.
+	frame *image.Gray16
+	seen  int
+}
+
+func newFrameAssembler(version int) *frameAssembler {
+	a := &frameAssembler{
+		segments: 4,
+		width:    2 * vospiPacketPixels,
+		height:   4 * vospiPacketsPerSegment / 2,
+		segment:  make([]byte, vospiPacketsPerSegment*vospiPacketSize),
+	}
+	if version == 2 {
+		a.segments, a.width, a.height = 1, vospiPacketPixels, vospiPacketsPerSegment
+	}
+	return a
+}
+
+func (a *frameAssembler) reset() {
+	a.next, a.frame, a.seen = 0, nil, 0
+}
+
+// add adds a packet, returning the frame once its last segment has been read. It returns errOutOfSync when the
+// packets do not follow each other, after which the Lepton must be resynchronized.
+func (a *frameAssembler) add(pkt []byte) (*image.Gray16, error) {
+	if pkt[0]&0x0f == 0x0f {
+		// a discard packet, sent while the next segment is not ready
+		return nil, nil
+	}
+	number := int(binary.BigEndian.Uint16(pkt) & 0x0fff)
+	if number != a.next || !validVoSPICRC(pkt) {
+		return nil, errOutOfSync
+	}
+	copy(a.segment[number*vospiPacketSize:], pkt)
+	if a.next++; a.next < vospiPacketsPerSegment {
+		return nil, nil
+	}
+	a.next = 0
+
+	seg := 1
+	if a.segments > 1 {
+		seg = int(a.segment[vospiSegmentPacket*vospiPacketSize]>>4) & 0x7
+	}
+	switch {
+	case seg == 0 || seg > a.segments:
+		// segments numbered 0 are not part of a frame
+		return nil, nil
+	case seg == 1:
+		a.frame, a.seen = image.NewGray16(image.Rect(0, 0, a.width, a.height)), 0
+	case a.frame == nil || a.seen != 1<<(seg-1)-1:
+		// a segment of the frame was missed
+		a.frame = nil
+		return nil, nil
+	}
+	rowsPerSegment := a.height / a.segments
+	packetsPerRow := a.width / vospiPacketPixels
+	for p := 0; p < vospiPacketsPerSegment; p++ {
+		row := (seg-1)*rowsPerSegment + p/packetsPerRow
+		col := (p % packetsPerRow) * vospiPacketPixels
+		// the pixels of packets are big endian, as those of a Gray16 are
+		payload := a.segment[p*vospiPacketSize+vospiHeaderSize : (p+1)*vospiPacketSize]
+		copy(a.frame.Pix[a.frame.PixOffset(col, row):], payload)
+	}
+	a.seen |= 1 << (seg - 1)
+	if seg < a.segments {
+		return nil, nil
+	}
+	frame := a.frame
+	a.frame = nil
+	return frame, nil
+}
+
+// validVoSPICRC returns whether the CRC of a packet matches it. The CRC is the CCITT CRC-16 of the packet with the
+// segment number bits of its ID and the CRC itself zeroed.
+func validVoSPICRC(pkt []byte) bool {
+	return binary.BigEndian.Uint16(pkt[2:]) == vospiCRC(pkt)
+}
+
+func vospiCRC(pkt []byte) uint16 {
+	var crc uint16
+	for i, b := range pkt {
+		switch i {
+		case 0:
+			b &= 0x0f
+		case 2, 3:
+			b = 0
+		}
+		crc ^= uint16(b) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// The command and control interface (CCI) of the Lepton, a set of 16-bit registers on I2C through which commands of
+// the modules of the core are run.
+const (
+	cciAddress        = 0x2a
+	cciRegStatus      = 0x0002
+	cciRegCommand     = 0x0004
+	cciRegDataLength  = 0x0006
+	cciRegData        = 0x0008
+	cciStatusBusy     = 0x0001
+	cciTimeout        = time.Second
+	cciPollInterval   = time.Millisecond
+	cciCommandGet     = 0x0000
+	cciCommandSet     = 0x0001
+	cciAGCEnable      = 0x0100
+	cciSysFPATempK    = 0x0214
+	cciRadEnable      = 0x4e10
+	cciRadTLinear     = 0x4ec0
+	cciRadTLinearRes  = 0x4ec4
+	cciTLinearRes0p1  = 0
+	cciTLinearRes0p01 = 1
+)
+
+type leptonCCI struct {
+	bus buses.I2C
+}
+
+// enableRadiometry turns the automatic gain control of the core off and its TLinear output on, so that its pixels are
+// temperatures at the given resolution in kelvin.
+func (c *leptonCCI) enableRadiometry(ctx context.Context, resolution float64) error {
+	res := uint32(cciTLinearRes0p01)
+	if resolution != centikelvin {
+		res = cciTLinearRes0p1
+	}
+	for _, cmd := range []struct {
+		command uint16
+		value   uint32
+	}{
+		{cciAGCEnable, 0},
+		{cciRadEnable, 1},
+		{cciRadTLinear, 1},
+		{cciRadTLinearRes, res},
+	} {
+		if err := c.set(ctx, cmd.command, cmd.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fpaTemperature returns the temperature of the focal plane array of the core in kelvin.
+func (c *leptonCCI) fpaTemperature(ctx context.Context) (float64, error) {
+	words, err := c.run(ctx, cciSysFPATempK|cciCommandGet, nil, 1)
+	if err != nil {
+		return 0, err
+	}
+	return float64(words[0]) * centikelvin, nil
+}
+
+// set sets a 32-bit value, which the Lepton takes with its least significant word first.
+func (c *leptonCCI) set(ctx context.Context, command uint16, value uint32) error {
+	_, err := c.run(ctx, command|cciCommandSet, []uint16{uint16(value), uint16(value >> 16)}, 0)
+	return err
+}
+
+// run runs a command with the given data, returning the words of data it responded with.
+func (c *leptonCCI) run(ctx context.Context, command uint16, data []uint16, responseWords int) ([]uint16, error) {
+	hnd, err := c.bus.OpenHandle(cciAddress)
+	if err != nil {
+		return nil, err
+	}
+	defer goutils.UncheckedErrorFunc(hnd.Close)
+
+	if err := c.waitIdle(ctx, hnd); err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := writeCCIRegister(ctx, hnd, cciRegData, data...); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeCCIRegister(ctx, hnd, cciRegDataLength, uint16(max(len(data), responseWords))); err != nil {
+		return nil, err
+	}
+	if err := writeCCIRegister(ctx, hnd, cciRegCommand, command); err != nil {
+		return nil, err
+	}
+	if err := c.waitIdle(ctx, hnd); err != nil {
+		return nil, errors.Wrapf(err, "lepton command %#04x", command)
+	}
+	if responseWords == 0 {
+		return nil, nil
+	}
+	return readCCIRegister(ctx, hnd, cciRegData, responseWords)
+}
+
+// waitIdle waits for the core to finish the last command, returning the error it responded with.
+func (c *leptonCCI) waitIdle(ctx context.Context, hnd buses.I2CHandle) error {
+	deadline := time.Now().Add(cciTimeout)
+	for {
+		status, err := readCCIRegister(ctx, hnd, cciRegStatus, 1)
+		if err != nil {
+			return err
+		}
+		if status[0]&cciStatusBusy == 0 {
+			if code := int8(status[0] >> 8); code != 0 {
+				return errors.Errorf("lepton responded with error %d", code)
+			}
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.New("timed out waiting for the lepton to finish a command")
+		}
+		if !goutils.SelectContextOrWait(ctx, cciPollInterval) {
+			return ctx.Err()
+		}
+	}
+}
+
+func writeCCIRegister(ctx context.Context, hnd buses.I2CHandle, register uint16, words ...uint16) error {
+	tx := binary.BigEndian.AppendUint16(make([]byte, 0, 2+2*len(words)), register)
+	for _, w := range words {
+		tx = binary.BigEndian.AppendUint16(tx, w)
+	}
+	return hnd.Write(ctx, tx)
+}
+
+func readCCIRegister(ctx context.Context, hnd buses.I2CHandle, register uint16, words int) ([]uint16, error) {
+	if err := hnd.Write(ctx, binary.BigEndian.AppendUint16(nil, register)); err != nil {
+		return nil, err
+	}
+	rx, err := hnd.Read(ctx, 2*words)
+	if err != nil {
+		return nil, err
+	}
+	if len(rx) < 2*words {
+		return nil, errors.Errorf("expected %d bytes from lepton register %#04x, got %d", 2*words, register, len(rx))
+	}
+	out := make([]uint16, words)
+	for i := range out {
+		out[i] = binary.BigEndian.Uint16(rx[2*i:])
+	}
+	return out, nil
+}
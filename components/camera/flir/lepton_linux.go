@@ -0,0 +1,21 @@
+//go:build linux
+
+package flir
+
+import (
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+)
+
+// openLeptonBuses opens the SPI bus of a Lepton and the I2C bus of its command interface, if it has one.
+func openLeptonBuses(conf *LeptonConfig) (buses.SPI, buses.I2C, error) {
+	if conf.I2CBus == "" {
+		return buses.NewSpiBus(conf.SPIBus), nil, nil
+	}
+	i2cBus, err := buses.NewI2cBus(conf.I2CBus)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "cannot find i2c bus %q of the lepton", conf.I2CBus)
+	}
+	return buses.NewSpiBus(conf.SPIBus), i2cBus, nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package flir
+
+import (
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+)
+
+// openLeptonBuses fails, as SPI and I2C buses are only supported on Linux.
+func openLeptonBuses(conf *LeptonConfig) (buses.SPI, buses.I2C, error) {
+	return nil, nil, errors.New("the flir_lepton camera is only supported on Linux")
+}
@@ -0,0 +1,269 @@
+package flir
+
+import (
+	"context"
+	"encoding/binary"
+	"image"
+	"sync"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// vospiPacket returns a packet of the given number with pixels of the given value and a valid CRC.
+func vospiPacket(number, segment int, pixel func(i int) uint16) []byte {
+	pkt := make([]byte, vospiPacketSize)
+	binary.BigEndian.PutUint16(pkt, uint16(segment<<12|number))
+	for i := 0; i < vospiPacketPixels; i++ {
+		binary.BigEndian.PutUint16(pkt[vospiHeaderSize+2*i:], pixel(i))
+	}
+	binary.BigEndian.PutUint16(pkt[2:], vospiCRC(pkt))
+	return pkt
+}
+
+func discardPacket() []byte {
+	pkt := make([]byte, vospiPacketSize)
+	pkt[0], pkt[1] = 0x0f, 0xff
+	return pkt
+}
+
+// lepton3Frame returns the packets of a 160x120 frame whose pixels are value(x, y).
+func lepton3Frame(value func(x, y int) uint16) [][]byte {
+	var pkts [][]byte
+	for seg := 1; seg <= 4; seg++ {
+		for p := 0; p < vospiPacketsPerSegment; p++ {
+			segNumber := 0
+			if p == vospiSegmentPacket {
+				segNumber = seg
+			}
+			row := (seg-1)*30 + p/2
+			col := (p % 2) * vospiPacketPixels
+			pkts = append(pkts, vospiPacket(p, segNumber, func(i int) uint16 { return value(col+i, row) }))
+		}
+	}
+	return pkts
+}
+
+func TestVoSPICRC(t *testing.T) {
+	pkt := vospiPacket(3, 0, func(i int) uint16 { return uint16(i) })
+	test.That(t, validVoSPICRC(pkt), test.ShouldBeTrue)
+	// the segment number is not covered by the CRC
+	pkt[0] |= 0x30
+	test.That(t, validVoSPICRC(pkt), test.ShouldBeTrue)
+	pkt[100]++
+	test.That(t, validVoSPICRC(pkt), test.ShouldBeFalse)
+}
+
+func TestFrameAssembler(t *testing.T) {
+	value := func(x, y int) uint16 { return uint16(30000 + 200*y + x) }
+	frame := lepton3Frame(value)
+
+	t.Run("lepton 3", func(t *testing.T) {
+		a := newFrameAssembler(3)
+		add := func(pkts ...[]byte) *image.Gray16 {
+			var out *image.Gray16
+			for _, pkt := range pkts {
+				img, err := a.add(pkt)
+				test.That(t, err, test.ShouldBeNil)
+				if img != nil {
+					out = img
+				}
+			}
+			return out
+		}
+
+		// discard packets come between segments
+		test.That(t, add(discardPacket(), discardPacket()), test.ShouldBeNil)
+		test.That(t, add(frame[:120]...), test.ShouldBeNil)
+		test.That(t, add(discardPacket()), test.ShouldBeNil)
+		img := add(frame[120:]...)
+		test.That(t, img, test.ShouldNotBeNil)
+		test.That(t, img.Bounds(), test.ShouldResemble, image.Rect(0, 0, 160, 120))
+		for _, pt := range []image.Point{{0, 0}, {79, 0}, {80, 0}, {159, 29}, {3, 30}, {159, 119}} {
+			test.That(t, img.Gray16At(pt.X, pt.Y).Y, test.ShouldEqual, value(pt.X, pt.Y))
+		}
+
+		// a frame missing a segment is dropped, and the next frame is read whole
+		test.That(t, add(frame[:60]...), test.ShouldBeNil)
+		test.That(t, add(frame[120:]...), test.ShouldBeNil)
+		test.That(t, add(frame...), test.ShouldNotBeNil)
+
+		// segments numbered 0 are not part of a frame
+		invalid := lepton3Frame(value)
+		invalid[vospiSegmentPacket] = vospiPacket(vospiSegmentPacket, 0, func(int) uint16 { return 0 })
+		test.That(t, add(invalid[:60]...), test.ShouldBeNil)
+		test.That(t, add(frame[60:]...), test.ShouldBeNil)
+	})
+
+	t.Run("lepton 2", func(t *testing.T) {
+		a := newFrameAssembler(2)
+		var img *image.Gray16
+		for p := 0; p < vospiPacketsPerSegment; p++ {
+			var err error
+			img, err = a.add(vospiPacket(p, 0, func(i int) uint16 { return uint16(100*p + i) }))
+			test.That(t, err, test.ShouldBeNil)
+		}
+		test.That(t, img, test.ShouldNotBeNil)
+		test.That(t, img.Bounds(), test.ShouldResemble, image.Rect(0, 0, 80, 60))
+		test.That(t, img.Gray16At(7, 59).Y, test.ShouldEqual, 5907)
+	})
+
+	t.Run("out of sync", func(t *testing.T) {
+		a := newFrameAssembler(3)
+		_, err := a.add(frame[1])
+		test.That(t, err, test.ShouldBeError, errOutOfSync)
+
+		a.reset()
+		_, err = a.add(frame[0])
+		test.That(t, err, test.ShouldBeNil)
+		corrupt := append([]byte{}, frame[1]...)
+		corrupt[50]++
+		_, err = a.add(corrupt)
+		test.That(t, err, test.ShouldBeError, errOutOfSync)
+	})
+}
+
+// fakeLeptonSPI streams the packets of frames over and over, starting again from the first packet once it is not
+// read for long enough as a Lepton does.
+type fakeLeptonSPI struct {
+	mu       sync.Mutex
+	packets  [][]byte
+	next     int
+	lastXfer time.Time
+	closed   bool
+}
+
+func (f *fakeLeptonSPI) OpenHandle() (buses.SPIHandle, error) {
+	return fakeSPIHandle{f}, nil
+}
+
+func (f *fakeLeptonSPI) Close(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+type fakeSPIHandle struct {
+	spi *fakeLeptonSPI
+}
+
+func (h fakeSPIHandle) Xfer(ctx context.Context, baud uint, chipSelect string, mode uint, tx []byte) ([]byte, error) {
+	h.spi.mu.Lock()
+	defer h.spi.mu.Unlock()
+	if time.Since(h.spi.lastXfer) > vospiResyncDelay*3/4 {
+		h.spi.next = 0
+	}
+	h.spi.lastXfer = time.Now()
+	rx := make([]byte, 0, len(tx))
+	for len(rx) < len(tx) {
+		rx = append(rx, h.spi.packets[h.spi.next%len(h.spi.packets)]...)
+		h.spi.next++
+	}
+	return rx, nil
+}
+
+func (h fakeSPIHandle) Close() error {
+	return nil
+}
+
+// fakeCCI is the command interface of a Lepton, which records the commands set and answers the FPA temperature.
+func fakeCCI(t *testing.T) (*inject.I2C, map[uint16][]uint16) {
+	t.Helper()
+	var mu sync.Mutex
+	registers := map[uint16]uint16{}
+	set := map[uint16][]uint16{}
+	var readRegister uint16
+	handle := &inject.I2CHandle{
+		WriteFunc: func(ctx context.Context, tx []byte) error {
+			mu.Lock()
+			defer mu.Unlock()
+			reg := binary.BigEndian.Uint16(tx)
+			if len(tx) == 2 {
+				readRegister = reg
+				return nil
+			}
+			for i := 2; i+1 < len(tx); i += 2 {
+				registers[reg+uint16(i-2)] = binary.BigEndian.Uint16(tx[i:])
+			}
+			if reg == cciRegCommand {
+				cmd := registers[cciRegCommand]
+				switch {
+				case cmd&0x3 == cciCommandSet:
+					n := registers[cciRegDataLength]
+					words := make([]uint16, n)
+					for i := range words {
+						words[i] = registers[cciRegData+uint16(2*i)]
+					}
+					set[cmd&^0x3] = words
+				case cmd == cciSysFPATempK:
+					registers[cciRegData] = 30015
+				}
+			}
+			return nil
+		},
+		ReadFunc: func(ctx context.Context, count int) ([]byte, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			rx := make([]byte, 0, count)
+			for i := 0; i < count; i += 2 {
+				rx = binary.BigEndian.AppendUint16(rx, registers[readRegister+uint16(i)])
+			}
+			return rx, nil
+		},
+		CloseFunc: func() error { return nil },
+	}
+	bus := &inject.I2C{OpenHandleFunc: func(addr byte) (buses.I2CHandle, error) {
+		test.That(t, addr, test.ShouldEqual, cciAddress)
+		return handle, nil
+	}}
+	return bus, set
+}
+
+func TestLepton(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	// 25C everywhere but a 100C pixel
+	frame := lepton3Frame(func(x, y int) uint16 {
+		if x == 10 && y == 100 {
+			return 37315
+		}
+		return 29815
+	})
+	// the stream starts in the middle of a segment, which needs a resynchronization
+	spi := &fakeLeptonSPI{packets: append([][]byte{discardPacket()}, frame...), next: 31, lastXfer: time.Now()}
+	cci, set := fakeCCI(t)
+
+	conf := &LeptonConfig{SPIBus: "0", ChipSelect: "0"}
+	_, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	cam, err := newLepton(ctx, camera.Named("lepton"),
+		conf, spi, cci, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, set[cciAGCEnable], test.ShouldResemble, []uint16{0, 0})
+	test.That(t, set[cciRadEnable], test.ShouldResemble, []uint16{1, 0})
+	test.That(t, set[cciRadTLinear], test.ShouldResemble, []uint16{1, 0})
+	test.That(t, set[cciRadTLinearRes], test.ShouldResemble, []uint16{cciTLinearRes0p01, 0})
+
+	imgs, _, err := cam.Images(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, imgs, test.ShouldHaveLength, 2)
+	temps, ok := imgs[1].Image.(*image.Gray16)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, temps.Gray16At(10, 100).Y, test.ShouldEqual, 37315)
+
+	resp, err := cam.DoCommand(ctx, map[string]interface{}{"command": "temperatures"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["max_c"], test.ShouldAlmostEqual, 100)
+	test.That(t, resp["min_c"], test.ShouldAlmostEqual, 25)
+	test.That(t, resp["sensor_temp_c"], test.ShouldAlmostEqual, 27)
+
+	test.That(t, cam.Close(ctx), test.ShouldBeNil)
+	test.That(t, spi.closed, test.ShouldBeTrue)
+}
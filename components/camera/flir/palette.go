@@ -0,0 +1,178 @@
+package flir
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sync"
+)
+
+// The automatic gain controls of the false color image.
+const (
+	agcLinear    = "linear"
+	agcHistogram = "histogram"
+	agcFixed     = "fixed"
+)
+
+// A palette is the 256 colors of a false color image, from cold to hot.
+type palette [256]color.RGBA
+
+// paletteStop is a color of a palette at a position from 0 to 1, which the colors between stops blend.
+type paletteStop struct {
+	at  float64
+	rgb [3]float64
+}
+
+func newPalette(stops ...paletteStop) *palette {
+	var p palette
+	for i := range p {
+		at := float64(i) / float64(len(p)-1)
+		j := 1
+		for j < len(stops)-1 && stops[j].at < at {
+			j++
+		}
+		lo, hi := stops[j-1], stops[j]
+		f := (at - lo.at) / (hi.at - lo.at)
+		var rgb [3]uint8
+		for c := range rgb {
+			rgb[c] = uint8(math.Round(lo.rgb[c] + f*(hi.rgb[c]-lo.rgb[c])))
+		}
+		p[i] = color.RGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: 0xff}
+	}
+	return &p
+}
+
+var palettes = map[string]*palette{
+	"ironbow": newPalette(
+		paletteStop{0, [3]float64{0, 0, 0}},
+		paletteStop{0.15, [3]float64{30, 0, 100}},
+		paletteStop{0.35, [3]float64{150, 0, 150}},
+		paletteStop{0.55, [3]float64{230, 70, 30}},
+		paletteStop{0.75, [3]float64{255, 160, 0}},
+		paletteStop{0.9, [3]float64{255, 230, 60}},
+		paletteStop{1, [3]float64{255, 255, 255}},
+	),
+	"rainbow": newPalette(
+		paletteStop{0, [3]float64{0, 0, 128}},
+		paletteStop{0.2, [3]float64{0, 0, 255}},
+		paletteStop{0.4, [3]float64{0, 255, 255}},
+		paletteStop{0.6, [3]float64{0, 255, 0}},
+		paletteStop{0.8, [3]float64{255, 255, 0}},
+		paletteStop{1, [3]float64{255, 0, 0}},
+	),
+	"white_hot": newPalette(paletteStop{0, [3]float64{0, 0, 0}}, paletteStop{1, [3]float64{255, 255, 255}}),
+	"black_hot": newPalette(paletteStop{0, [3]float64{255, 255, 255}}, paletteStop{1, [3]float64{0, 0, 0}}),
+}
+
+// colorizer turns temperature images into false color images.
+type colorizer struct {
+	agc     string
+	palette *palette
+	// minCK and maxCK are the temperatures of the ends of the palette for fixed gain, in hundredths of a kelvin.
+	minCK, maxCK uint16
+
+	// histogram and levels are reused between the frames of histogram equalization.
+	mu        sync.Mutex
+	histogram []uint32
+	levels    []uint8
+}
+
+func newColorizer(cfg *ThermalConfig) *colorizer {
+	c := &colorizer{agc: cfg.AGC, palette: palettes[cfg.Palette]}
+	if c.agc == "" {
+		c.agc = agcLinear
+	}
+	if c.palette == nil {
+		c.palette = palettes["ironbow"]
+	}
+	if c.agc == agcFixed {
+		c.minCK = toCentikelvin(*cfg.MinTempC + zeroCelsiusK)
+		c.maxCK = toCentikelvin(*cfg.MaxTempC + zeroCelsiusK)
+	}
+	return c
+}
+
+// colorize returns the false color image of a temperature image.
+func (c *colorizer) colorize(temps *image.Gray16) *image.RGBA {
+	b := temps.Bounds()
+	img := image.NewRGBA(b)
+	if b.Empty() {
+		return img
+	}
+	var level func(ck uint16) uint8
+	switch c.agc {
+	case agcHistogram:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		level = c.equalize(temps)
+	case agcFixed:
+		level = linearLevel(c.minCK, c.maxCK)
+	default:
+		minCK, maxCK := uint16(math.MaxUint16), uint16(0)
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				t := temps.Gray16At(x, y).Y
+				minCK, maxCK = min(minCK, t), max(maxCK, t)
+			}
+		}
+		level = linearLevel(minCK, maxCK)
+	}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			img.SetRGBA(x, y, c.palette[level(temps.Gray16At(x, y).Y)])
+		}
+	}
+	return img
+}
+
+// linearLevel returns the color of a temperature on a palette spread evenly from minCK to maxCK.
+func linearLevel(minCK, maxCK uint16) func(uint16) uint8 {
+	if maxCK <= minCK {
+		return func(uint16) uint8 { return 0 }
+	}
+	span := float64(maxCK - minCK)
+	return func(ck uint16) uint8 {
+		switch {
+		case ck <= minCK:
+			return 0
+		case ck >= maxCK:
+			return 255
+		default:
+			return uint8(float64(ck-minCK) / span * 255)
+		}
+	}
+}
+
+// equalize returns the color of a temperature such that each color covers about as many pixels of the image, so
+// that a hot spot does not wash the rest of the scene out into a few colors.
+func (c *colorizer) equalize(temps *image.Gray16) func(uint16) uint8 {
+	if c.histogram == nil {
+		c.histogram = make([]uint32, math.MaxUint16+1)
+		c.levels = make([]uint8, math.MaxUint16+1)
+	}
+	b := temps.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c.histogram[temps.Gray16At(x, y).Y]++
+		}
+	}
+	// levels follow the cumulative histogram, starting from the coldest temperature of the image
+	total := uint32(b.Dx() * b.Dy())
+	var below, coldest uint32
+	for ck, count := range c.histogram {
+		if count == 0 {
+			continue
+		}
+		if coldest == 0 {
+			coldest = count
+		}
+		below += count
+		c.histogram[ck] = 0
+		if total > coldest {
+			c.levels[ck] = uint8(math.Round(float64(below-coldest) / float64(total-coldest) * 255))
+		} else {
+			c.levels[ck] = 0
+		}
+	}
+	return func(ck uint16) uint8 { return c.levels[ck] }
+}
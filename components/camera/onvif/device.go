@@ -0,0 +1,293 @@
+package onvif
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/camera"
+)
+
+const (
+	soapContentType = "application/soap+xml; charset=utf-8"
+	envelopeFormat  = `<?xml version="1.0" encoding="UTF-8"?>
+<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"
+ xmlns:tds="http://www.onvif.org/ver10/device/wsdl"
+ xmlns:trt="http://www.onvif.org/ver10/media/wsdl"
+ xmlns:tptz="http://www.onvif.org/ver20/ptz/wsdl"
+ xmlns:tt="http://www.onvif.org/ver10/schema">
+<s:Header>%s</s:Header>
+<s:Body>%s</s:Body>
+</s:Envelope>`
+	securityFormat = `<wsse:Security s:mustUnderstand="1"
+ xmlns:wsse="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd"
+ xmlns:wsu="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd">
+<wsse:UsernameToken>
+<wsse:Username>%s</wsse:Username>
+<wsse:Password Type="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordDigest">%s</wsse:Password>
+<wsse:Nonce EncodingType="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-soap-message-security-1.0#Base64Binary">%s</wsse:Nonce>
+<wsu:Created>%s</wsu:Created>
+</wsse:UsernameToken>
+</wsse:Security>`
+)
+
+// device is a client of the device, media and PTZ services of an ONVIF camera.
+type device struct {
+	client   *http.Client
+	username string
+	password string
+
+	deviceURL string
+	mediaURL  string
+	ptzURL    string
+}
+
+// A profile is a media profile of a camera, which pairs a video encoding with an optional PTZ configuration.
+type profile struct {
+	Token string `xml:"token,attr"`
+	Name  string `xml:"Name"`
+	PTZ   *struct {
+		Token string `xml:"token,attr"`
+	} `xml:"PTZConfiguration"`
+}
+
+// newDevice connects to the device service of a camera and looks up its other services.
+func newDevice(ctx context.Context, deviceURL, username, password string) (*device, error) {
+	d := &device{
+		client:    &http.Client{Timeout: 10 * time.Second},
+		username:  username,
+		password:  password,
+		deviceURL: deviceURL,
+	}
+	var resp struct {
+		Capabilities struct {
+			Media struct {
+				XAddr string `xml:"XAddr"`
+			} `xml:"Media"`
+			PTZ struct {
+				XAddr string `xml:"XAddr"`
+			} `xml:"PTZ"`
+		} `xml:"GetCapabilitiesResponse>Capabilities"`
+	}
+	if err := d.call(ctx, deviceURL,
+		`<tds:GetCapabilities><tds:Category>All</tds:Category></tds:GetCapabilities>`, &resp); err != nil {
+		return nil, errors.Wrapf(err, "cannot get capabilities of ONVIF camera at %s", deviceURL)
+	}
+	if resp.Capabilities.Media.XAddr == "" {
+		return nil, errors.Errorf("ONVIF camera at %s has no media service", deviceURL)
+	}
+	d.mediaURL = resp.Capabilities.Media.XAddr
+	d.ptzURL = resp.Capabilities.PTZ.XAddr
+	return d, nil
+}
+
+// profiles returns the media profiles of the camera.
+func (d *device) profiles(ctx context.Context) ([]profile, error) {
+	var resp struct {
+		Profiles []profile `xml:"GetProfilesResponse>Profiles"`
+	}
+	if err := d.call(ctx, d.mediaURL, `<trt:GetProfiles/>`, &resp); err != nil {
+		return nil, errors.Wrap(err, "cannot get media profiles")
+	}
+	return resp.Profiles, nil
+}
+
+// streamURI returns the RTSP URI of the video of a profile.
+func (d *device) streamURI(ctx context.Context, profileToken string) (string, error) {
+	var resp struct {
+		URI string `xml:"GetStreamUriResponse>MediaUri>Uri"`
+	}
+	body := fmt.Sprintf(`<trt:GetStreamUri>
+<trt:StreamSetup><tt:Stream>RTP-Unicast</tt:Stream><tt:Transport><tt:Protocol>RTSP</tt:Protocol></tt:Transport></trt:StreamSetup>
+<trt:ProfileToken>%s</trt:ProfileToken>
+</trt:GetStreamUri>`, escape(profileToken))
+	if err := d.call(ctx, d.mediaURL, body, &resp); err != nil {
+		return "", errors.Wrap(err, "cannot get stream URI")
+	}
+	if resp.URI == "" {
+		return "", errors.Errorf("profile %q has no stream URI", profileToken)
+	}
+	return resp.URI, nil
+}
+
+// ptzVector is the body of a PTZ position, velocity or translation.
+func ptzVector(element string, pan, tilt, zoom float64) string {
+	return fmt.Sprintf(`<tptz:%[1]s><tt:PanTilt x="%[2]g" y="%[3]g"/><tt:Zoom x="%[4]g"/></tptz:%[1]s>`, element, pan, tilt, zoom)
+}
+
+func (d *device) ptzCall(ctx context.Context, body string, resp interface{}) error {
+	if d.ptzURL == "" {
+		return camera.ErrPTZUnsupported
+	}
+	return d.call(ctx, d.ptzURL, body, resp)
+}
+
+func (d *device) move(ctx context.Context, profileToken string, move camera.PTZMove) error {
+	var body string
+	switch move.Mode {
+	case camera.PTZMoveContinuous:
+		body = fmt.Sprintf(`<tptz:ContinuousMove><tptz:ProfileToken>%s</tptz:ProfileToken>%s</tptz:ContinuousMove>`,
+			escape(profileToken), ptzVector("Velocity", move.Pan, move.Tilt, move.Zoom))
+	case camera.PTZMoveAbsolute:
+		body = fmt.Sprintf(`<tptz:AbsoluteMove><tptz:ProfileToken>%s</tptz:ProfileToken>%s</tptz:AbsoluteMove>`,
+			escape(profileToken), ptzVector("Position", move.Pan, move.Tilt, move.Zoom))
+	case camera.PTZMoveRelative:
+		body = fmt.Sprintf(`<tptz:RelativeMove><tptz:ProfileToken>%s</tptz:ProfileToken>%s</tptz:RelativeMove>`,
+			escape(profileToken), ptzVector("Translation", move.Pan, move.Tilt, move.Zoom))
+	default:
+		return errors.Errorf("unknown PTZ move mode %q", move.Mode)
+	}
+	return d.ptzCall(ctx, body, nil)
+}
+
+func (d *device) stop(ctx context.Context, profileToken string) error {
+	return d.ptzCall(ctx, fmt.Sprintf(`<tptz:Stop><tptz:ProfileToken>%s</tptz:ProfileToken>`+
+		`<tptz:PanTilt>true</tptz:PanTilt><tptz:Zoom>true</tptz:Zoom></tptz:Stop>`, escape(profileToken)), nil)
+}
+
+func (d *device) position(ctx context.Context, profileToken string) (camera.PTZPosition, error) {
+	var resp struct {
+		Position struct {
+			PanTilt struct {
+				X float64 `xml:"x,attr"`
+				Y float64 `xml:"y,attr"`
+			} `xml:"PanTilt"`
+			Zoom struct {
+				X float64 `xml:"x,attr"`
+			} `xml:"Zoom"`
+		} `xml:"GetStatusResponse>PTZStatus>Position"`
+	}
+	if err := d.ptzCall(ctx, fmt.Sprintf(`<tptz:GetStatus><tptz:ProfileToken>%s</tptz:ProfileToken></tptz:GetStatus>`,
+		escape(profileToken)), &resp); err != nil {
+		return camera.PTZPosition{}, err
+	}
+	return camera.PTZPosition{Pan: resp.Position.PanTilt.X, Tilt: resp.Position.PanTilt.Y, Zoom: resp.Position.Zoom.X}, nil
+}
+
+func (d *device) presets(ctx context.Context, profileToken string) ([]camera.PTZPreset, error) {
+	var resp struct {
+		Presets []struct {
+			Token string `xml:"token,attr"`
+			Name  string `xml:"Name"`
+		} `xml:"GetPresetsResponse>Preset"`
+	}
+	if err := d.ptzCall(ctx, fmt.Sprintf(`<tptz:GetPresets><tptz:ProfileToken>%s</tptz:ProfileToken></tptz:GetPresets>`,
+		escape(profileToken)), &resp); err != nil {
+		return nil, err
+	}
+	presets := make([]camera.PTZPreset, 0, len(resp.Presets))
+	for _, p := range resp.Presets {
+		presets = append(presets, camera.PTZPreset{Token: p.Token, Name: p.Name})
+	}
+	return presets, nil
+}
+
+func (d *device) goToPreset(ctx context.Context, profileToken, presetToken string) error {
+	return d.ptzCall(ctx, fmt.Sprintf(`<tptz:GotoPreset><tptz:ProfileToken>%s</tptz:ProfileToken>`+
+		`<tptz:PresetToken>%s</tptz:PresetToken></tptz:GotoPreset>`, escape(profileToken), escape(presetToken)), nil)
+}
+
+func (d *device) setPreset(ctx context.Context, profileToken, name string) (camera.PTZPreset, error) {
+	var resp struct {
+		Token string `xml:"SetPresetResponse>PresetToken"`
+	}
+	if err := d.ptzCall(ctx, fmt.Sprintf(`<tptz:SetPreset><tptz:ProfileToken>%s</tptz:ProfileToken>`+
+		`<tptz:PresetName>%s</tptz:PresetName></tptz:SetPreset>`, escape(profileToken), escape(name)), &resp); err != nil {
+		return camera.PTZPreset{}, err
+	}
+	return camera.PTZPreset{Token: resp.Token, Name: name}, nil
+}
+
+// call sends a SOAP request to a service of the camera and decodes the body of its response into resp.
+func (d *device) call(ctx context.Context, url, body string, resp interface{}) error {
+	header, err := d.securityHeader()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url,
+		strings.NewReader(fmt.Sprintf(envelopeFormat, header, body)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", soapContentType)
+	httpResp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		//nolint:errcheck
+		httpResp.Body.Close()
+	}()
+	data, err := io.ReadAll(io.LimitReader(httpResp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	var envelope struct {
+		Body struct {
+			Inner []byte `xml:",innerxml"`
+			Fault *struct {
+				Reason string `xml:"Reason>Text"`
+			} `xml:"Fault"`
+		} `xml:"Body"`
+	}
+	if err := xml.Unmarshal(data, &envelope); err != nil {
+		return errors.Wrapf(err, "cannot parse response (%s)", httpResp.Status)
+	}
+	if envelope.Body.Fault != nil {
+		return errors.Errorf("camera returned a fault: %s", envelope.Body.Fault.Reason)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return errors.Errorf("camera returned %s", httpResp.Status)
+	}
+	if resp == nil {
+		return nil
+	}
+	// the body is wrapped back up so response structs can address its children by path
+	return xml.Unmarshal(append(append([]byte("<Body>"), envelope.Body.Inner...), "</Body>"...), resp)
+}
+
+// securityHeader returns a WS-Security UsernameToken header with a password digest, or nothing without
+// credentials.
+func (d *device) securityHeader() (string, error) {
+	if d.username == "" {
+		return "", nil
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	created := time.Now().UTC().Format(time.RFC3339)
+	return fmt.Sprintf(securityFormat,
+		escape(d.username),
+		passwordDigest(nonce, created, d.password),
+		base64.StdEncoding.EncodeToString(nonce),
+		created,
+	), nil
+}
+
+// passwordDigest is the WS-Security password digest, Base64(SHA1(nonce + created + password)).
+func passwordDigest(nonce []byte, created, password string) string {
+	h := sha1.New() //nolint:gosec
+	h.Write(nonce)
+	h.Write([]byte(created))
+	h.Write([]byte(password))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func escape(s string) string {
+	var b bytes.Buffer
+	//nolint:errcheck
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,148 @@
+package onvif
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+)
+
+const (
+	// wsDiscoveryAddress is the multicast group WS-Discovery probes are sent to.
+	wsDiscoveryAddress = "239.255.255.250:3702"
+	// defaultDiscoveryTimeout is how long discovery waits for cameras to answer a probe.
+	defaultDiscoveryTimeout = 3 * time.Second
+
+	probeFormat = `<?xml version="1.0" encoding="UTF-8"?>
+<e:Envelope xmlns:e="http://www.w3.org/2003/05/soap-envelope"
+ xmlns:w="http://schemas.xmlsoap.org/ws/2004/08/addressing"
+ xmlns:d="http://schemas.xmlsoap.org/ws/2005/04/discovery"
+ xmlns:dn="http://www.onvif.org/ver10/network/wsdl">
+<e:Header>
+<w:MessageID>uuid:%s</w:MessageID>
+<w:To e:mustUnderstand="true">urn:schemas-xmlsoap-org:ws:2005:04:discovery</w:To>
+<w:Action e:mustUnderstand="true">http://schemas.xmlsoap.org/ws/2005/04/discovery/Probe</w:Action>
+</e:Header>
+<e:Body><d:Probe><d:Types>dn:NetworkVideoTransmitter</d:Types></d:Probe></e:Body>
+</e:Envelope>`
+)
+
+// A DiscoveredCamera is an ONVIF camera which answered a WS-Discovery probe.
+type DiscoveredCamera struct {
+	// Address is the URL of the device service of the camera, which is what the address of a camera config takes.
+	Address string `json:"address"`
+	// Name and Hardware are taken from the scopes the camera advertises.
+	Name     string   `json:"name,omitempty"`
+	Hardware string   `json:"hardware,omitempty"`
+	XAddrs   []string `json:"xaddrs"`
+	Scopes   []string `json:"scopes,omitempty"`
+}
+
+// Discover finds the ONVIF cameras on the local network with a WS-Discovery probe, waiting up to the timeout for
+// them to answer.
+func Discover(ctx context.Context, timeout time.Duration, logger logging.Logger) ([]DiscoveredCamera, error) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		//nolint:errcheck
+		conn.Close()
+	}()
+	group, err := net.ResolveUDPAddr("udp4", wsDiscoveryAddress)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.WriteTo([]byte(fmt.Sprintf(probeFormat, uuid.NewString())), group); err != nil {
+		return nil, errors.Wrap(err, "cannot send WS-Discovery probe")
+	}
+
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var cameras []DiscoveredCamera
+	buf := make([]byte, 64*1024)
+	for ctx.Err() == nil {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				break
+			}
+			return cameras, err
+		}
+		matches, err := parseProbeMatches(buf[:n])
+		if err != nil {
+			logger.Debugw("ignoring WS-Discovery reply", "from", from, "error", err)
+			continue
+		}
+		for _, match := range matches {
+			if seen[match.Address] {
+				continue
+			}
+			seen[match.Address] = true
+			cameras = append(cameras, match)
+		}
+	}
+	return cameras, ctx.Err()
+}
+
+// parseProbeMatches parses the cameras out of a WS-Discovery ProbeMatches message.
+func parseProbeMatches(data []byte) ([]DiscoveredCamera, error) {
+	var envelope struct {
+		Matches []struct {
+			Scopes string `xml:"Scopes"`
+			XAddrs string `xml:"XAddrs"`
+		} `xml:"Body>ProbeMatches>ProbeMatch"`
+	}
+	if err := xml.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+	cameras := make([]DiscoveredCamera, 0, len(envelope.Matches))
+	for _, match := range envelope.Matches {
+		xaddrs := strings.Fields(match.XAddrs)
+		if len(xaddrs) == 0 {
+			continue
+		}
+		discovered := DiscoveredCamera{
+			Address: xaddrs[0],
+			XAddrs:  xaddrs,
+			Scopes:  strings.Fields(match.Scopes),
+		}
+		for _, scope := range discovered.Scopes {
+			const prefix = "onvif://www.onvif.org/"
+			if !strings.HasPrefix(scope, prefix) {
+				continue
+			}
+			key, value, ok := strings.Cut(strings.TrimPrefix(scope, prefix), "/")
+			if !ok {
+				continue
+			}
+			if unescaped, err := url.PathUnescape(value); err == nil {
+				value = unescaped
+			}
+			switch key {
+			case "name":
+				discovered.Name = value
+			case "hardware":
+				discovered.Hardware = value
+			}
+		}
+		cameras = append(cameras, discovered)
+	}
+	return cameras, nil
+}
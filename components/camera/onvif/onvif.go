@@ -0,0 +1,240 @@
+// Package onvif implements a camera for ONVIF IP cameras. The RTSP stream of the camera is looked up through its
+// media service, so only the address of the camera is needed, or nothing at all when it can be found with
+// WS-Discovery. Cameras with a PTZ service can be panned, tilted and zoomed through camera.PTZController.
+package onvif
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/camera/ffmpeg"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage/transform"
+)
+
+var model = resource.DefaultModelFamily.WithModel("onvif")
+
+// Config is the attribute struct for ONVIF cameras.
+type Config struct {
+	// Address is the host, host:port or device service URL of the camera. The first camera found by WS-Discovery is
+	// used when it is empty.
+	Address  string `json:"address,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Profile is the name or token of the media profile to stream. The first profile is used when it is empty.
+	Profile string `json:"profile,omitempty"`
+	// RTSPTransport is "tcp" or "udp", defaulting to tcp which is more reliable across busy networks.
+	RTSPTransport string `json:"rtsp_transport,omitempty"`
+
+	CameraParameters     *transform.PinholeCameraIntrinsics `json:"intrinsic_parameters,omitempty"`
+	DistortionParameters *transform.BrownConrady            `json:"distortion_parameters,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	switch cfg.RTSPTransport {
+	case "", "tcp", "udp":
+	default:
+		return nil, resource.NewConfigValidationError(path, errors.Errorf("rtsp_transport must be tcp or udp, not %q", cfg.RTSPTransport))
+	}
+	if cfg.Password != "" && cfg.Username == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "username")
+	}
+	if _, err := deviceServiceURL(cfg.Address); err != nil {
+		return nil, resource.NewConfigValidationError(path, err)
+	}
+	return []string{}, nil
+}
+
+func init() {
+	resource.RegisterComponent(camera.API, model, resource.Registration[camera.Camera, *Config]{
+		Constructor: func(
+			ctx context.Context, _ resource.Dependencies, conf resource.Config, logger logging.Logger,
+		) (camera.Camera, error) {
+			newConf, err := resource.NativeConfig[*Config](conf)
+			if err != nil {
+				return nil, err
+			}
+			return newCamera(ctx, conf.ResourceName(), newConf, logger)
+		},
+		Discover: func(ctx context.Context, logger logging.Logger) (interface{}, error) {
+			return Discover(ctx, defaultDiscoveryTimeout, logger)
+		},
+	})
+}
+
+// deviceServiceURL returns the URL of the device service of a camera from its configured address.
+func deviceServiceURL(address string) (string, error) {
+	if address == "" {
+		return "", nil
+	}
+	if !strings.Contains(address, "://") {
+		address = "http://" + address + "/onvif/device_service"
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid address")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", errors.Errorf("address must be an http or https URL, not %q", address)
+	}
+	if u.Path == "" {
+		u.Path = "/onvif/device_service"
+	}
+	return u.String(), nil
+}
+
+type onvifCamera struct {
+	resource.Named
+	resource.AlwaysRebuild
+	camera.VideoSource
+
+	logger       logging.Logger
+	device       *device
+	profileToken string
+	streamURI    string
+}
+
+func newCamera(ctx context.Context, name resource.Name, conf *Config, logger logging.Logger) (camera.Camera, error) {
+	address, err := deviceServiceURL(conf.Address)
+	if err != nil {
+		return nil, err
+	}
+	if address == "" {
+		discovered, err := Discover(ctx, defaultDiscoveryTimeout, logger)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot discover ONVIF cameras")
+		}
+		if len(discovered) == 0 {
+			return nil, errors.New("no address given and no ONVIF camera found on the network")
+		}
+		address = discovered[0].Address
+		logger.CInfow(ctx, "using discovered ONVIF camera", "address", address, "name", discovered[0].Name)
+	}
+
+	dev, err := newDevice(ctx, address, conf.Username, conf.Password)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := dev.profiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prof, err := selectProfile(profiles, conf.Profile)
+	if err != nil {
+		return nil, err
+	}
+	if prof.PTZ == nil {
+		// the PTZ service only moves profiles with a PTZ configuration
+		dev.ptzURL = ""
+	}
+	streamURI, err := dev.streamURI(ctx, prof.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := conf.RTSPTransport
+	if transport == "" {
+		transport = "tcp"
+	}
+	videoPath, err := withCredentials(streamURI, conf.Username, conf.Password)
+	if err != nil {
+		return nil, err
+	}
+	src, err := ffmpeg.NewFFMPEGCamera(ctx, &ffmpeg.Config{
+		CameraParameters:     conf.CameraParameters,
+		DistortionParameters: conf.DistortionParameters,
+		VideoPath:            videoPath,
+		InputKWArgs:          map[string]interface{}{"rtsp_transport": transport},
+	}, logger)
+	if err != nil {
+		return nil, err
+	}
+	logger.CInfow(ctx, "streaming ONVIF camera", "profile", prof.Name, "uri", streamURI, "ptz", dev.ptzURL != "")
+	return &onvifCamera{
+		Named:        name.AsNamed(),
+		VideoSource:  src,
+		logger:       logger,
+		device:       dev,
+		profileToken: prof.Token,
+		streamURI:    streamURI,
+	}, nil
+}
+
+// selectProfile returns the profile with the given name or token, or the first profile when none is given.
+func selectProfile(profiles []profile, nameOrToken string) (profile, error) {
+	if len(profiles) == 0 {
+		return profile{}, errors.New("camera has no media profiles")
+	}
+	if nameOrToken == "" {
+		return profiles[0], nil
+	}
+	names := make([]string, 0, len(profiles))
+	for _, p := range profiles {
+		if p.Name == nameOrToken || p.Token == nameOrToken {
+			return p, nil
+		}
+		names = append(names, p.Name)
+	}
+	return profile{}, errors.Errorf("no profile %q, the camera has %v", nameOrToken, names)
+}
+
+// withCredentials adds the username and password to a stream URI which does not carry its own.
+func withCredentials(streamURI, username, password string) (string, error) {
+	if username == "" {
+		return streamURI, nil
+	}
+	u, err := url.Parse(streamURI)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid stream URI %q", streamURI)
+	}
+	if u.User == nil {
+		u.User = url.UserPassword(username, password)
+	}
+	return u.String(), nil
+}
+
+// DoCommand runs the PTZ commands of camera.PTZController and "stream_info", which returns the profile and RTSP URI
+// being streamed.
+func (c *onvifCamera) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if resp, handled, err := camera.DoPTZCommand(ctx, c, cmd); handled {
+		return resp, err
+	}
+	if cmd["command"] == "stream_info" {
+		return map[string]interface{}{
+			"profile":    c.profileToken,
+			"stream_uri": c.streamURI,
+			"ptz":        c.device.ptzURL != "",
+		}, nil
+	}
+	return nil, resource.ErrDoUnimplemented
+}
+
+func (c *onvifCamera) MovePTZ(ctx context.Context, move camera.PTZMove) error {
+	return c.device.move(ctx, c.profileToken, move)
+}
+
+func (c *onvifCamera) StopPTZ(ctx context.Context) error {
+	return c.device.stop(ctx, c.profileToken)
+}
+
+func (c *onvifCamera) PTZPosition(ctx context.Context) (camera.PTZPosition, error) {
+	return c.device.position(ctx, c.profileToken)
+}
+
+func (c *onvifCamera) PTZPresets(ctx context.Context) ([]camera.PTZPreset, error) {
+	return c.device.presets(ctx, c.profileToken)
+}
+
+func (c *onvifCamera) GoToPTZPreset(ctx context.Context, token string) error {
+	return c.device.goToPreset(ctx, c.profileToken, token)
+}
+
+func (c *onvifCamera) SetPTZPreset(ctx context.Context, name string) (camera.PTZPreset, error) {
+	return c.device.setPreset(ctx, c.profileToken, name)
+}
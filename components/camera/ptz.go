@@ -0,0 +1,219 @@
+package camera
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// The commands which carry PTZController over DoCommand, since the camera service has no methods for it.
+const (
+	ptzMoveCommand       = "ptz_move"
+	ptzStopCommand       = "ptz_stop"
+	ptzPositionCommand   = "ptz_position"
+	ptzPresetsCommand    = "ptz_presets"
+	ptzGoToPresetCommand = "ptz_go_to_preset"
+	ptzSetPresetCommand  = "ptz_set_preset"
+)
+
+// ErrPTZUnsupported is returned when a camera cannot pan, tilt or zoom.
+var ErrPTZUnsupported = errors.New("camera does not support pan, tilt and zoom")
+
+// PTZMoveMode says how the values of a PTZMove are taken.
+type PTZMoveMode string
+
+// The modes of a PTZMove.
+const (
+	// PTZMoveContinuous moves at the given velocities, from -1 to 1, until the camera is stopped.
+	PTZMoveContinuous = PTZMoveMode("continuous")
+	// PTZMoveAbsolute moves to the given position.
+	PTZMoveAbsolute = PTZMoveMode("absolute")
+	// PTZMoveRelative moves by the given amounts from the current position.
+	PTZMoveRelative = PTZMoveMode("relative")
+)
+
+// A PTZMove is a pan, tilt and zoom motion of a camera.
+type PTZMove struct {
+	Mode PTZMoveMode
+	Pan  float64
+	Tilt float64
+	Zoom float64
+}
+
+// A PTZPosition is where a camera points, with pan and tilt from -1 to 1 and zoom from 0 to 1 across the range of
+// the camera.
+type PTZPosition struct {
+	Pan  float64
+	Tilt float64
+	Zoom float64
+}
+
+// A PTZPreset is a position saved on a camera.
+type PTZPreset struct {
+	Token string
+	Name  string
+}
+
+// A PTZController is a camera which can pan, tilt and zoom.
+type PTZController interface {
+	// MovePTZ starts moving the camera. Absolute and relative moves return once the camera is told to move, not
+	// once it gets there.
+	//
+	//    myCamera, err := camera.FromRobot(machine, "my_camera")
+	//    controller, ok := myCamera.(camera.PTZController)
+	//    // Pan right at half speed until stopped.
+	//    err = controller.MovePTZ(context.Background(), camera.PTZMove{Mode: camera.PTZMoveContinuous, Pan: 0.5})
+	MovePTZ(ctx context.Context, move PTZMove) error
+	// StopPTZ stops any pan, tilt or zoom motion of the camera.
+	StopPTZ(ctx context.Context) error
+	// PTZPosition returns where the camera points.
+	PTZPosition(ctx context.Context) (PTZPosition, error)
+	// PTZPresets returns the positions saved on the camera.
+	PTZPresets(ctx context.Context) ([]PTZPreset, error)
+	// GoToPTZPreset moves the camera to a saved position.
+	GoToPTZPreset(ctx context.Context, token string) error
+	// SetPTZPreset saves the current position of the camera under a name, returning the new preset.
+	SetPTZPreset(ctx context.Context, name string) (PTZPreset, error)
+}
+
+// DoPTZCommand runs a PTZ command on a camera, saying whether the command was one. It lets cameras and the camera
+// service serve PTZController over DoCommand.
+func DoPTZCommand(ctx context.Context, cam interface{}, cmd map[string]interface{}) (map[string]interface{}, bool, error) {
+	name, _ := cmd["command"].(string)
+	switch name {
+	case ptzMoveCommand, ptzStopCommand, ptzPositionCommand, ptzPresetsCommand, ptzGoToPresetCommand, ptzSetPresetCommand:
+	default:
+		return nil, false, nil
+	}
+	controller, ok := cam.(PTZController)
+	if !ok {
+		return nil, true, ErrPTZUnsupported
+	}
+
+	switch name {
+	case ptzMoveCommand:
+		move, err := ptzMoveFromCommand(cmd)
+		if err != nil {
+			return nil, true, err
+		}
+		return map[string]interface{}{}, true, controller.MovePTZ(ctx, move)
+	case ptzStopCommand:
+		return map[string]interface{}{}, true, controller.StopPTZ(ctx)
+	case ptzPositionCommand:
+		position, err := controller.PTZPosition(ctx)
+		if err != nil {
+			return nil, true, err
+		}
+		return map[string]interface{}{"pan": position.Pan, "tilt": position.Tilt, "zoom": position.Zoom}, true, nil
+	case ptzPresetsCommand:
+		presets, err := controller.PTZPresets(ctx)
+		if err != nil {
+			return nil, true, err
+		}
+		rawPresets := make([]interface{}, 0, len(presets))
+		for _, preset := range presets {
+			rawPresets = append(rawPresets, map[string]interface{}{"token": preset.Token, "name": preset.Name})
+		}
+		return map[string]interface{}{"presets": rawPresets}, true, nil
+	case ptzGoToPresetCommand:
+		token, ok := cmd["token"].(string)
+		if !ok || token == "" {
+			return nil, true, errors.New("ptz_go_to_preset requires a preset token")
+		}
+		return map[string]interface{}{}, true, controller.GoToPTZPreset(ctx, token)
+	default:
+		presetName, ok := cmd["name"].(string)
+		if !ok || presetName == "" {
+			return nil, true, errors.New("ptz_set_preset requires a preset name")
+		}
+		preset, err := controller.SetPTZPreset(ctx, presetName)
+		if err != nil {
+			return nil, true, err
+		}
+		return map[string]interface{}{"token": preset.Token, "name": preset.Name}, true, nil
+	}
+}
+
+// ptzMoveToCommand encodes a PTZMove as a DoCommand.
+func ptzMoveToCommand(move PTZMove) map[string]interface{} {
+	return map[string]interface{}{
+		"command": ptzMoveCommand,
+		"mode":    string(move.Mode),
+		"pan":     move.Pan,
+		"tilt":    move.Tilt,
+		"zoom":    move.Zoom,
+	}
+}
+
+// ptzMoveFromCommand parses the arguments of a ptz_move command.
+func ptzMoveFromCommand(cmd map[string]interface{}) (PTZMove, error) {
+	mode, _ := cmd["mode"].(string)
+	move := PTZMove{Mode: PTZMoveMode(mode)}
+	switch move.Mode {
+	case PTZMoveContinuous, PTZMoveAbsolute, PTZMoveRelative:
+	default:
+		return PTZMove{}, errors.Errorf("ptz_move mode must be %q, %q or %q, not %q",
+			PTZMoveContinuous, PTZMoveAbsolute, PTZMoveRelative, mode)
+	}
+	for key, value := range map[string]*float64{"pan": &move.Pan, "tilt": &move.Tilt, "zoom": &move.Zoom} {
+		raw, ok := cmd[key]
+		if !ok {
+			continue
+		}
+		if *value, ok = raw.(float64); !ok {
+			return PTZMove{}, errors.Errorf("ptz_move %s must be a number", key)
+		}
+	}
+	return move, nil
+}
+
+func (c *client) MovePTZ(ctx context.Context, move PTZMove) error {
+	_, err := c.DoCommand(ctx, ptzMoveToCommand(move))
+	return err
+}
+
+func (c *client) StopPTZ(ctx context.Context) error {
+	_, err := c.DoCommand(ctx, map[string]interface{}{"command": ptzStopCommand})
+	return err
+}
+
+func (c *client) PTZPosition(ctx context.Context) (PTZPosition, error) {
+	resp, err := c.DoCommand(ctx, map[string]interface{}{"command": ptzPositionCommand})
+	if err != nil {
+		return PTZPosition{}, err
+	}
+	pan, _ := resp["pan"].(float64)
+	tilt, _ := resp["tilt"].(float64)
+	zoom, _ := resp["zoom"].(float64)
+	return PTZPosition{Pan: pan, Tilt: tilt, Zoom: zoom}, nil
+}
+
+func (c *client) PTZPresets(ctx context.Context) ([]PTZPreset, error) {
+	resp, err := c.DoCommand(ctx, map[string]interface{}{"command": ptzPresetsCommand})
+	if err != nil {
+		return nil, err
+	}
+	rawPresets, _ := resp["presets"].([]interface{})
+	presets := make([]PTZPreset, 0, len(rawPresets))
+	for _, raw := range rawPresets {
+		rawPreset, _ := raw.(map[string]interface{})
+		token, _ := rawPreset["token"].(string)
+		name, _ := rawPreset["name"].(string)
+		presets = append(presets, PTZPreset{Token: token, Name: name})
+	}
+	return presets, nil
+}
+
+func (c *client) GoToPTZPreset(ctx context.Context, token string) error {
+	_, err := c.DoCommand(ctx, map[string]interface{}{"command": ptzGoToPresetCommand, "token": token})
+	return err
+}
+
+func (c *client) SetPTZPreset(ctx context.Context, name string) (PTZPreset, error) {
+	resp, err := c.DoCommand(ctx, map[string]interface{}{"command": ptzSetPresetCommand, "name": name})
+	if err != nil {
+		return PTZPreset{}, err
+	}
+	token, _ := resp["token"].(string)
+	return PTZPreset{Token: token, Name: name}, nil
+}
@@ -0,0 +1,79 @@
+package camera
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+type fakePTZ struct {
+	moves  []PTZMove
+	preset string
+}
+
+func (f *fakePTZ) MovePTZ(ctx context.Context, move PTZMove) error {
+	f.moves = append(f.moves, move)
+	return nil
+}
+
+func (f *fakePTZ) StopPTZ(ctx context.Context) error {
+	return nil
+}
+
+func (f *fakePTZ) PTZPosition(ctx context.Context) (PTZPosition, error) {
+	return PTZPosition{Pan: 0.5, Tilt: -0.5, Zoom: 1}, nil
+}
+
+func (f *fakePTZ) PTZPresets(ctx context.Context) ([]PTZPreset, error) {
+	return []PTZPreset{{Token: "1", Name: "home"}}, nil
+}
+
+func (f *fakePTZ) GoToPTZPreset(ctx context.Context, token string) error {
+	f.preset = token
+	return nil
+}
+
+func (f *fakePTZ) SetPTZPreset(ctx context.Context, name string) (PTZPreset, error) {
+	return PTZPreset{Token: "2", Name: name}, nil
+}
+
+func TestDoPTZCommand(t *testing.T) {
+	ctx := context.Background()
+	ptz := &fakePTZ{}
+
+	_, handled, err := DoPTZCommand(ctx, ptz, map[string]interface{}{"command": "other"})
+	test.That(t, handled, test.ShouldBeFalse)
+	test.That(t, err, test.ShouldBeNil)
+
+	_, handled, err = DoPTZCommand(ctx, struct{}{}, map[string]interface{}{"command": ptzStopCommand})
+	test.That(t, handled, test.ShouldBeTrue)
+	test.That(t, err, test.ShouldBeError, ErrPTZUnsupported)
+
+	move := PTZMove{Mode: PTZMoveRelative, Pan: 0.1, Tilt: -0.2}
+	_, handled, err = DoPTZCommand(ctx, ptz, ptzMoveToCommand(move))
+	test.That(t, handled, test.ShouldBeTrue)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ptz.moves, test.ShouldResemble, []PTZMove{move})
+
+	_, _, err = DoPTZCommand(ctx, ptz, map[string]interface{}{"command": ptzMoveCommand, "mode": "sideways"})
+	test.That(t, err, test.ShouldBeError)
+	_, _, err = DoPTZCommand(ctx, ptz, map[string]interface{}{"command": ptzMoveCommand, "mode": "continuous", "pan": "fast"})
+	test.That(t, err, test.ShouldBeError)
+
+	resp, _, err := DoPTZCommand(ctx, ptz, map[string]interface{}{"command": ptzPositionCommand})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp, test.ShouldResemble, map[string]interface{}{"pan": 0.5, "tilt": -0.5, "zoom": 1.})
+
+	resp, _, err = DoPTZCommand(ctx, ptz, map[string]interface{}{"command": ptzPresetsCommand})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["presets"], test.ShouldResemble, []interface{}{map[string]interface{}{"token": "1", "name": "home"}})
+
+	_, _, err = DoPTZCommand(ctx, ptz, map[string]interface{}{"command": ptzGoToPresetCommand, "token": "1"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ptz.preset, test.ShouldEqual, "1")
+
+	resp, _, err = DoPTZCommand(ctx, ptz, map[string]interface{}{"command": ptzSetPresetCommand, "name": "door"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp, test.ShouldResemble, map[string]interface{}{"token": "2", "name": "door"})
+}
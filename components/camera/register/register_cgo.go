@@ -6,8 +6,12 @@ package register
 import (
 	// for cameras.
 	_ "go.viam.com/rdk/components/camera/align"
+	_ "go.viam.com/rdk/components/camera/cliprecorder"
 	_ "go.viam.com/rdk/components/camera/ffmpeg"
+	_ "go.viam.com/rdk/components/camera/flir"
+	_ "go.viam.com/rdk/components/camera/onvif"
 	_ "go.viam.com/rdk/components/camera/replaypcd"
+	_ "go.viam.com/rdk/components/camera/stereo"
 	_ "go.viam.com/rdk/components/camera/ultrasonic"
 	_ "go.viam.com/rdk/components/camera/velodyne"
 	_ "go.viam.com/rdk/components/camera/videosource"
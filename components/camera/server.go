@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"context"
 	"image"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.opencensus.io/trace"
 	commonpb "go.viam.com/api/common/v1"
 	pb "go.viam.com/api/component/camera/v1"
 	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"go.viam.com/rdk/gostream"
 	"go.viam.com/rdk/logging"
@@ -126,7 +129,14 @@ func (s *serviceServer) GetImages(
 		}
 		imagesMessage = append(imagesMessage, imgMes)
 	}
-	// right now the only metadata is timestamp
+	// the protobuf message only has the timestamp, the rest of the metadata goes in the response headers
+	headerMetadata := metadata
+	headerMetadata.CapturedAt = time.Time{}
+	if header := headerMetadata.GRPCHeader(); len(header) > 0 {
+		if err := grpc.SetHeader(ctx, header); err != nil {
+			return nil, err
+		}
+	}
 	resp := &pb.GetImagesResponse{
 		Images:           imagesMessage,
 		ResponseMetadata: metadata.AsProto(),
@@ -158,6 +168,13 @@ func encodeImageFromUnderlyingType(ctx context.Context, img image.Image) (pb.For
 			return pb.Format_FORMAT_UNSPECIFIED, nil, err
 		}
 		return format, outBytes, nil
+	case *rimage.BayerImage:
+		// raw bayer images have no format of their own, and are recognized by their header when decoded
+		outBytes, err := rimage.EncodeImage(ctx, v, utils.MimeTypeRawBayer)
+		if err != nil {
+			return pb.Format_FORMAT_UNSPECIFIED, nil, err
+		}
+		return pb.Format_FORMAT_UNSPECIFIED, outBytes, nil
 	case *image.Gray16:
 		format := pb.Format_FORMAT_PNG
 		outBytes, err := rimage.EncodeImage(ctx, v, utils.MimeTypePNG)
@@ -218,14 +235,21 @@ func (s *serviceServer) GetPointCloud(
 	var buf bytes.Buffer
 	buf.Grow(200 + (pc.Size() * 4 * 4)) // 4 numbers per point, each 4 bytes
 	_, pcdSpan := trace.StartSpan(ctx, "camera::server::NextPointCloud::ToPCD")
-	err = pointcloud.ToPCD(pc, &buf, pointcloud.PCDBinary)
+	// clients which can decompress point clouds ask for them compressed, everyone else gets plain PCD
+	mimeType := utils.MimeTypePCD
+	if req.MimeType == utils.MimeTypePCDLZ4 {
+		mimeType = utils.MimeTypePCDLZ4
+		err = pointcloud.ToLZ4PCD(pc, &buf)
+	} else {
+		err = pointcloud.ToPCD(pc, &buf, pointcloud.PCDBinary)
+	}
 	pcdSpan.End()
 	if err != nil {
 		return nil, err
 	}
 
 	return &pb.GetPointCloudResponse{
-		MimeType:   utils.MimeTypePCD,
+		MimeType:   mimeType,
 		PointCloud: buf.Bytes(),
 	}, nil
 }
@@ -274,5 +298,16 @@ func (s *serviceServer) DoCommand(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
-	return protoutils.DoFromResourceServer(ctx, camera, req)
+	resp, handled, err := DoPTZCommand(ctx, camera, req.GetCommand().AsMap())
+	if !handled {
+		return protoutils.DoFromResourceServer(ctx, camera, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result, err := structpb.NewStruct(resp)
+	if err != nil {
+		return nil, err
+	}
+	return &commonpb.DoCommandResponse{Result: result}, nil
 }
@@ -103,7 +103,7 @@ func TestServer(t *testing.T) {
 		images = append(images, camera.NamedImage{depth, "depth"})
 		// a timestamp of 12345
 		ts := time.UnixMilli(12345)
-		return images, resource.ResponseMetadata{ts}, nil
+		return images, resource.ResponseMetadata{CapturedAt: ts}, nil
 	}
 	injectCamera.ProjectorFunc = func(ctx context.Context) (transform.Projector, error) {
 		return projA, nil
@@ -377,10 +377,26 @@ func TestServer(t *testing.T) {
 		injectCamera.NextPointCloudFunc = func(ctx context.Context) (pointcloud.PointCloud, error) {
 			return pcA, nil
 		}
-		_, err = cameraServer.GetPointCloud(context.Background(), &pb.GetPointCloudRequest{
+		resp, err := cameraServer.GetPointCloud(context.Background(), &pb.GetPointCloudRequest{
 			Name: testCameraName,
 		})
 		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp.MimeType, test.ShouldEqual, utils.MimeTypePCD)
+		pcB, err := pointcloud.ReadPCD(bytes.NewReader(resp.PointCloud))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pcB.Size(), test.ShouldEqual, 1)
+
+		// compression is used only when asked for
+		resp, err = cameraServer.GetPointCloud(context.Background(), &pb.GetPointCloudRequest{
+			Name:     testCameraName,
+			MimeType: utils.MimeTypePCDLZ4,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp.MimeType, test.ShouldEqual, utils.MimeTypePCDLZ4)
+		pcB, err = pointcloud.ReadLZ4PCD(bytes.NewReader(resp.PointCloud))
+		test.That(t, err, test.ShouldBeNil)
+		_, got := pcB.At(5, 5, 5)
+		test.That(t, got, test.ShouldBeTrue)
 
 		_, err = cameraServer.GetPointCloud(context.Background(), &pb.GetPointCloudRequest{
 			Name: failCameraName,
@@ -0,0 +1,178 @@
+package stereo
+
+import (
+	"image"
+	"math"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// rectifier warps the images of a calibrated stereo pair so that they look like they were taken by two identical,
+// undistorted cameras pointing the same way and offset only along x. A point of the scene then appears on the same
+// row of both images, at a column in the right image which is its disparity less than in the left one.
+type rectifier struct {
+	// intrinsics are those of the rectified cameras, which are the same for both.
+	intrinsics *transform.PinholeCameraIntrinsics
+	// baselineMm is the distance between the rectified cameras.
+	baselineMm float64
+	// leftMap and rightMap hold, for each rectified pixel, the x and y at which it is sampled from the original image.
+	leftMap, rightMap []float32
+}
+
+// newRectifier computes the rectification of a stereo pair, as OpenCV's stereoRectify does: each camera is rotated
+// half of the way to the orientation of the other, then both are rotated together so that their x axes follow the
+// baseline. rotation and translation take points from the frame of the left camera to that of the right one.
+func newRectifier(
+	left, right *transform.PinholeCameraIntrinsics,
+	leftDistortion, rightDistortion *transform.BrownConrady,
+	rotation *spatialmath.RotationMatrix,
+	translation r3.Vector,
+) (*rectifier, error) {
+	baseline := translation.Norm()
+	if baseline == 0 {
+		return nil, errors.New("the cameras of a stereo pair cannot be at the same place")
+	}
+	if math.Abs(translation.X) < math.Abs(translation.Y) {
+		return nil, errors.New("only stereo pairs whose cameras are side by side are supported")
+	}
+	halfInverse := rotationFromVector(rotationToVector(rotation).Mul(-0.5))
+	t := halfInverse.Mul(translation)
+	toBaseline := t.Cross(r3.Vector{X: math.Copysign(1, t.X)})
+	if n := toBaseline.Norm(); n > 0 {
+		toBaseline = toBaseline.Mul(math.Acos(math.Abs(t.X)/t.Norm()) / n)
+	}
+	align := rotationFromVector(toBaseline)
+	leftRotation := spatialmath.MatMul(*align, *transpose(halfInverse))
+	rightRotation := spatialmath.MatMul(*align, *halfInverse)
+
+	// the rectified cameras share the shortest focal length of the pair so that no part of either image is stretched,
+	// and are centered on the images
+	f := math.Min(math.Min(left.Fx, left.Fy), math.Min(right.Fx, right.Fy))
+	intrinsics := &transform.PinholeCameraIntrinsics{
+		Width:  left.Width,
+		Height: left.Height,
+		Fx:     f,
+		Fy:     f,
+		Ppx:    float64(left.Width-1) / 2,
+		Ppy:    float64(left.Height-1) / 2,
+	}
+	return &rectifier{
+		intrinsics: intrinsics,
+		baselineMm: baseline,
+		leftMap:    rectificationMap(intrinsics, left, leftDistortion, leftRotation),
+		rightMap:   rectificationMap(intrinsics, right, rightDistortion, rightRotation),
+	}, nil
+}
+
+// rectificationMap returns where each pixel of a rectified image is found in the original image of a camera, where
+// rotation takes points from the frame of the camera to the rectified one.
+func rectificationMap(
+	rectified, original *transform.PinholeCameraIntrinsics,
+	distortion *transform.BrownConrady,
+	rotation *spatialmath.RotationMatrix,
+) []float32 {
+	inverse := transpose(rotation)
+	m := make([]float32, 2*rectified.Width*rectified.Height)
+	for y := 0; y < rectified.Height; y++ {
+		for x := 0; x < rectified.Width; x++ {
+			ray := inverse.Mul(r3.Vector{
+				X: (float64(x) - rectified.Ppx) / rectified.Fx,
+				Y: (float64(y) - rectified.Ppy) / rectified.Fy,
+				Z: 1,
+			})
+			k := 2 * (y*rectified.Width + x)
+			if ray.Z <= 0 {
+				m[k], m[k+1] = -1, -1
+				continue
+			}
+			u, v := distortion.Transform(ray.X/ray.Z, ray.Y/ray.Z)
+			m[k] = float32(u*original.Fx + original.Ppx)
+			m[k+1] = float32(v*original.Fy + original.Ppy)
+		}
+	}
+	return m
+}
+
+// rectify samples an image through a rectification map, returning the rectified image in color if withColor is set,
+// and in grayscale. Pixels which fall outside of the original image are black.
+func (r *rectifier) rectify(img image.Image, m []float32, withColor bool) (*rimage.Image, []uint8) {
+	src := rimage.ConvertImage(img)
+	width, height := r.intrinsics.Width, r.intrinsics.Height
+	var col *rimage.Image
+	if withColor {
+		col = rimage.NewImage(width, height)
+	}
+	gray := make([]uint8, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			k := y*width + x
+			red, green, blue, ok := bilinear(src, float64(m[2*k]), float64(m[2*k+1]))
+			if !ok {
+				continue
+			}
+			gray[k] = uint8((299*uint32(red) + 587*uint32(green) + 114*uint32(blue)) / 1000)
+			if withColor {
+				col.SetXY(x, y, rimage.NewColor(red, green, blue))
+			}
+		}
+	}
+	return col, gray
+}
+
+// bilinear interpolates the color of an image at a point, returning false if the point is outside of it.
+func bilinear(img *rimage.Image, x, y float64) (uint8, uint8, uint8, bool) {
+	if x < 0 || y < 0 || x > float64(img.Width()-1) || y > float64(img.Height()-1) {
+		return 0, 0, 0, false
+	}
+	x0, y0 := int(x), int(y)
+	x1, y1 := min(x0+1, img.Width()-1), min(y0+1, img.Height()-1)
+	fx, fy := x-float64(x0), y-float64(y0)
+	var out [3]float64
+	for _, c := range []struct {
+		x, y int
+		w    float64
+	}{
+		{x0, y0, (1 - fx) * (1 - fy)},
+		{x1, y0, fx * (1 - fy)},
+		{x0, y1, (1 - fx) * fy},
+		{x1, y1, fx * fy},
+	} {
+		red, green, blue := img.GetXY(c.x, c.y).RGB255()
+		out[0] += c.w * float64(red)
+		out[1] += c.w * float64(green)
+		out[2] += c.w * float64(blue)
+	}
+	return uint8(out[0] + 0.5), uint8(out[1] + 0.5), uint8(out[2] + 0.5), true
+}
+
+// rotationToVector returns the axis of a rotation scaled by its angle.
+func rotationToVector(rm *spatialmath.RotationMatrix) r3.Vector {
+	aa := rm.AxisAngles()
+	return r3.Vector{X: aa.RX, Y: aa.RY, Z: aa.RZ}.Mul(aa.Theta)
+}
+
+// rotationFromVector returns the rotation about an axis by the length of the axis.
+func rotationFromVector(v r3.Vector) *spatialmath.RotationMatrix {
+	theta := v.Norm()
+	if theta == 0 {
+		return (&spatialmath.R4AA{RX: 1}).RotationMatrix()
+	}
+	return (&spatialmath.R4AA{Theta: theta, RX: v.X / theta, RY: v.Y / theta, RZ: v.Z / theta}).RotationMatrix()
+}
+
+func transpose(rm *spatialmath.RotationMatrix) *spatialmath.RotationMatrix {
+	var m [9]float64
+	for r := 0; r < 3; r++ {
+		for c := 0; c < 3; c++ {
+			m[3*c+r] = rm.At(r, c)
+		}
+	}
+	//nolint:errcheck
+	t, _ := spatialmath.NewRotationMatrix(m[:])
+	return t
+}
@@ -0,0 +1,278 @@
+package stereo
+
+import (
+	"math"
+	"math/bits"
+)
+
+// invalidDisparity marks the pixels of a disparity map for which no disparity was found.
+const invalidDisparity = -1
+
+// censusRadius is the radius of the window over which the census transform of a pixel is computed.
+const censusRadius = 2
+
+// matchParams are the parameters of semi-global block matching.
+type matchParams struct {
+	minDisparity   int
+	numDisparities int
+	blockSize      int
+	// p1 and p2 are the penalties for a change of disparity between neighboring pixels of one, and of more than one.
+	p1, p2 int
+	// uniquenessRatio is the percentage by which the best disparity of a pixel must beat the others.
+	uniquenessRatio int
+	// maxLeftRightDiff is the largest difference allowed between the disparities found matching the left image to
+	// the right one and the right one to the left one, or negative to skip the check.
+	maxLeftRightDiff int
+	// eightPaths aggregates costs along the diagonals as well as the rows and columns.
+	eightPaths bool
+}
+
+// A matcher computes the disparity between rectified images with semi-global block matching. The cost of matching
+// two pixels is the Hamming distance between their census transforms summed over a block, which unlike differences
+// of intensities is not thrown off by cameras which expose differently. It keeps its buffers between images, and is
+// not safe for concurrent use.
+type matcher struct {
+	params        matchParams
+	width, height int
+	// cost and aggregated hold the matching cost and the cost aggregated over all paths, for each pixel and disparity.
+	cost       []uint16
+	aggregated []uint32
+}
+
+func newMatcher(params matchParams, width, height int) *matcher {
+	n := width * height * params.numDisparities
+	return &matcher{
+		params:     params,
+		width:      width,
+		height:     height,
+		cost:       make([]uint16, n),
+		aggregated: make([]uint32, n),
+	}
+}
+
+// disparity returns the disparity of each pixel of the left image in pixels, or invalidDisparity.
+func (m *matcher) disparity(left, right []uint8) []float32 {
+	m.matchingCost(census(left, m.width, m.height), census(right, m.width, m.height))
+	for i := range m.aggregated {
+		m.aggregated[i] = 0
+	}
+	directions := [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	if m.params.eightPaths {
+		directions = append(directions, [2]int{1, 1}, [2]int{-1, 1}, [2]int{1, -1}, [2]int{-1, -1})
+	}
+	for _, dir := range directions {
+		m.aggregate(dir[0], dir[1])
+	}
+	return m.selectDisparities()
+}
+
+// census returns the census transform of an image: a bit for each pixel of the window around a pixel, set if it is
+// darker than the pixel.
+func census(img []uint8, width, height int) []uint32 {
+	out := make([]uint32, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			center := img[y*width+x]
+			var c uint32
+			for dy := -censusRadius; dy <= censusRadius; dy++ {
+				yy := min(max(y+dy, 0), height-1)
+				for dx := -censusRadius; dx <= censusRadius; dx++ {
+					if dx == 0 && dy == 0 {
+						continue
+					}
+					xx := min(max(x+dx, 0), width-1)
+					c <<= 1
+					if img[yy*width+xx] < center {
+						c |= 1
+					}
+				}
+			}
+			out[y*width+x] = c
+		}
+	}
+	return out
+}
+
+// matchingCost fills in the cost of matching each pixel of the left image with the pixel of the right image at each
+// disparity, summing the distance of their census transforms over a block with a running box filter.
+func (m *matcher) matchingCost(left, right []uint32) {
+	w, h, nd := m.width, m.height, m.params.numDisparities
+	r := m.params.blockSize / 2
+	distances := make([]uint16, w*h)
+	columns := make([]uint32, w)
+	for di := 0; di < nd; di++ {
+		d := m.params.minDisparity + di
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				dist := uint16(2*censusRadius+1) * uint16(2*censusRadius+1)
+				if x-d >= 0 && x-d < w {
+					dist = uint16(bits.OnesCount32(left[y*w+x] ^ right[y*w+x-d]))
+				}
+				distances[y*w+x] = dist
+			}
+		}
+		// columns holds the sums over the rows of the block around y, which are then summed across the block
+		for x := range columns {
+			columns[x] = 0
+			for y := -r; y <= r; y++ {
+				columns[x] += uint32(distances[min(max(y, 0), h-1)*w+x])
+			}
+		}
+		for y := 0; y < h; y++ {
+			var sum uint32
+			for x := -r; x <= r; x++ {
+				sum += columns[min(max(x, 0), w-1)]
+			}
+			for x := 0; x < w; x++ {
+				m.cost[(y*w+x)*nd+di] = uint16(sum)
+				sum += columns[min(x+r+1, w-1)]
+				sum -= columns[max(x-r, 0)]
+			}
+			for x := range columns {
+				columns[x] += uint32(distances[min(y+r+1, h-1)*w+x])
+				columns[x] -= uint32(distances[max(y-r, 0)*w+x])
+			}
+		}
+	}
+}
+
+// aggregate adds the cost of each pixel and disparity aggregated along the path coming from direction (dx, dy): the
+// matching cost plus the cheapest way to reach the disparity from the previous pixel of the path, where changing
+// disparity is penalized.
+func (m *matcher) aggregate(dx, dy int) {
+	w, h, nd := m.width, m.height, m.params.numDisparities
+	p1, p2 := uint32(m.params.p1), uint32(m.params.p2)
+	// the path costs of the previous and current rows, or of the current row alone for horizontal paths
+	prev := make([]uint32, w*nd)
+	cur := make([]uint32, w*nd)
+	prevMin := make([]uint32, w)
+	curMin := make([]uint32, w)
+
+	y0, y1, ystep := 0, h, 1
+	if dy < 0 {
+		y0, y1, ystep = h-1, -1, -1
+	}
+	x0, x1, xstep := 0, w, 1
+	if dx < 0 {
+		x0, x1, xstep = w-1, -1, -1
+	}
+	for y := y0; y != y1; y += ystep {
+		for x := x0; x != x1; x += xstep {
+			px, py := x-dx, y-dy
+			costs := m.cost[(y*w+x)*nd : (y*w+x+1)*nd]
+			l := cur[x*nd : (x+1)*nd]
+			lowest := uint32(math.MaxUint32)
+			if px < 0 || px >= w || py < 0 || py >= h {
+				for d, c := range costs {
+					l[d] = uint32(c)
+					lowest = min(lowest, l[d])
+				}
+			} else {
+				previous, previousMin := prev[px*nd:(px+1)*nd], prevMin[px]
+				if dy == 0 {
+					previous, previousMin = cur[px*nd:(px+1)*nd], curMin[px]
+				}
+				for d, c := range costs {
+					best := min(previous[d], previousMin+p2)
+					if d > 0 {
+						best = min(best, previous[d-1]+p1)
+					}
+					if d < nd-1 {
+						best = min(best, previous[d+1]+p1)
+					}
+					l[d] = uint32(c) + best - previousMin
+					lowest = min(lowest, l[d])
+				}
+			}
+			curMin[x] = lowest
+			agg := m.aggregated[(y*w+x)*nd : (y*w+x+1)*nd]
+			for d := range agg {
+				agg[d] += l[d]
+			}
+		}
+		prev, cur = cur, prev
+		prevMin, curMin = curMin, prevMin
+	}
+}
+
+// selectDisparities picks the disparity of lowest aggregated cost for each pixel, refined to a fraction of a pixel,
+// and invalidates the pixels whose best disparity is not unique enough or does not survive the left-right check.
+func (m *matcher) selectDisparities() []float32 {
+	w, h, nd := m.width, m.height, m.params.numDisparities
+	out := make([]float32, w*h)
+	var rightBest []int
+	if m.params.maxLeftRightDiff >= 0 {
+		rightBest = m.rightDisparities()
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			agg := m.aggregated[(y*w+x)*nd : (y*w+x+1)*nd]
+			best := 0
+			for d := range agg {
+				if agg[d] < agg[best] {
+					best = d
+				}
+			}
+			out[y*w+x] = invalidDisparity
+			if !m.unique(agg, best) {
+				continue
+			}
+			if rightBest != nil {
+				xr := x - (m.params.minDisparity + best)
+				if xr < 0 || xr >= w || abs(rightBest[y*w+xr]-best) > m.params.maxLeftRightDiff {
+					continue
+				}
+			}
+			disparity := float64(m.params.minDisparity + best)
+			if best > 0 && best < nd-1 {
+				// the minimum of the parabola through the costs around the best disparity
+				before, at, after := float64(agg[best-1]), float64(agg[best]), float64(agg[best+1])
+				if denom := before + after - 2*at; denom > 0 {
+					disparity += (before - after) / (2 * denom)
+				}
+			}
+			out[y*w+x] = float32(disparity)
+		}
+	}
+	return out
+}
+
+// unique returns whether the cost of the best disparity beats all others but its neighbors by the uniqueness ratio.
+func (m *matcher) unique(agg []uint32, best int) bool {
+	for d, c := range agg {
+		if abs(d-best) > 1 && uint64(c)*uint64(100-m.params.uniquenessRatio) < uint64(agg[best])*100 {
+			return false
+		}
+	}
+	return true
+}
+
+// rightDisparities returns the index of the best disparity of each pixel of the right image, from the aggregated
+// costs of the pixels of the left image which match it, or -1 if it has none.
+func (m *matcher) rightDisparities() []int {
+	w, h, nd := m.width, m.height, m.params.numDisparities
+	out := make([]int, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			best, bestCost := -1, uint32(math.MaxUint32)
+			for d := 0; d < nd; d++ {
+				xl := x + m.params.minDisparity + d
+				if xl < 0 || xl >= w {
+					continue
+				}
+				if c := m.aggregated[(y*w+xl)*nd+d]; c < bestCost {
+					best, bestCost = d, c
+				}
+			}
+			out[y*w+x] = best
+		}
+	}
+	return out
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
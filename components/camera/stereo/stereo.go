@@ -0,0 +1,380 @@
+// Package stereo defines a camera model which computes depth from a pair of calibrated color cameras, such as two
+// webcams mounted side by side, with semi-global block matching.
+package stereo
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	"go.uber.org/multierr"
+	"golang.org/x/sync/errgroup"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/gostream"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+)
+
+var model = resource.DefaultModelFamily.WithModel("stereo_depth")
+
+const (
+	defaultNumDisparities   = 64
+	defaultBlockSize        = 5
+	defaultUniquenessRatio  = 10
+	defaultMaxLeftRightDiff = 1
+	// maxBlockSize keeps the matching cost of a block within 16 bits.
+	maxBlockSize = 31
+)
+
+func init() {
+	resource.RegisterComponent(camera.API, model,
+		resource.Registration[camera.Camera, *Config]{
+			Constructor: func(ctx context.Context, deps resource.Dependencies,
+				conf resource.Config, logger logging.Logger,
+			) (camera.Camera, error) {
+				newConf, err := resource.NativeConfig[*Config](conf)
+				if err != nil {
+					return nil, err
+				}
+				left, err := camera.FromDependencies(deps, newConf.LeftCamera)
+				if err != nil {
+					return nil, fmt.Errorf("no left camera (%s): %w", newConf.LeftCamera, err)
+				}
+				right, err := camera.FromDependencies(deps, newConf.RightCamera)
+				if err != nil {
+					return nil, fmt.Errorf("no right camera (%s): %w", newConf.RightCamera, err)
+				}
+				src, err := newStereoDepth(ctx, left, right, newConf, logger)
+				if err != nil {
+					return nil, err
+				}
+				return camera.FromVideoSource(conf.ResourceName(), src, logger), nil
+			},
+		})
+}
+
+// Calibration is the pose of the right camera of a stereo pair relative to the left one, in the form given by
+// OpenCV's stereoCalibrate: rotation and translation take points from the frame of the left camera to that of the
+// right one, so the translation of a right camera mounted 60mm to the right of the left one is (-60, 0, 0).
+type Calibration struct {
+	// RotationMatrix is row major, and the identity if unset.
+	RotationMatrix []float64 `json:"rotation_matrix,omitempty"`
+	TranslationMm  r3.Vector `json:"translation_mm"`
+}
+
+// Config is the attribute struct for a stereo depth camera. The intrinsics of the left and right cameras, at the
+// resolution they stream at, come from their properties.
+type Config struct {
+	LeftCamera  string      `json:"left_camera_name"`
+	RightCamera string      `json:"right_camera_name"`
+	Calibration Calibration `json:"stereo_calibration"`
+	// ImageType is depth by default, or color to stream the rectified image of the left camera.
+	ImageType string `json:"output_image_type,omitempty"`
+
+	// MinDisparity and NumDisparities are the range of disparities searched, in pixels. The closest point which can be
+	// seen is at a depth of focal length * baseline / (MinDisparity + NumDisparities - 1).
+	MinDisparity   int `json:"min_disparity,omitempty"`
+	NumDisparities int `json:"num_disparities,omitempty"`
+	// BlockSize is the odd width of the blocks which are matched.
+	BlockSize int `json:"block_size,omitempty"`
+	// P1 and P2 penalize changes of disparity between neighboring pixels of one, and of more than one. They default to
+	// 1 and 4 times the area of a block.
+	P1 int `json:"p1,omitempty"`
+	P2 int `json:"p2,omitempty"`
+	// UniquenessRatio is the percentage by which the best disparity of a pixel must beat the others for it to be kept.
+	UniquenessRatio *int `json:"uniqueness_ratio,omitempty"`
+	// MaxLeftRightDiff is the largest difference in pixels allowed between the disparities found matching the left
+	// image to the right one and the right one to the left one, or negative to skip the check.
+	MaxLeftRightDiff *int `json:"max_left_right_diff,omitempty"`
+	// EightPaths aggregates costs along the diagonals as well as the rows and columns, which is more accurate and slower.
+	EightPaths bool `json:"eight_paths,omitempty"`
+	// MaxDepthMm drops the points further than it, which are the least accurate.
+	MaxDepthMm int `json:"max_depth_mm,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if cfg.LeftCamera == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "left_camera_name")
+	}
+	if cfg.RightCamera == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "right_camera_name")
+	}
+	if cfg.LeftCamera == cfg.RightCamera {
+		return nil, resource.NewConfigValidationError(path, errors.New("left_camera_name and right_camera_name must differ"))
+	}
+	if n := len(cfg.Calibration.RotationMatrix); n != 0 && n != 9 {
+		return nil, resource.NewConfigValidationError(path,
+			errors.Errorf("stereo_calibration.rotation_matrix must have 9 elements, got %d", n))
+	}
+	if cfg.Calibration.TranslationMm.Norm() == 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "stereo_calibration.translation_mm")
+	}
+	switch camera.ImageType(cfg.ImageType) {
+	case camera.UnspecifiedStream, camera.DepthStream, camera.ColorStream:
+	default:
+		return nil, resource.NewConfigValidationError(path, camera.NewUnsupportedImageTypeError(camera.ImageType(cfg.ImageType)))
+	}
+	if cfg.MinDisparity < 0 || cfg.NumDisparities < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("min_disparity and num_disparities cannot be negative"))
+	}
+	if cfg.BlockSize != 0 && (cfg.BlockSize%2 == 0 || cfg.BlockSize < 0 || cfg.BlockSize > maxBlockSize) {
+		return nil, resource.NewConfigValidationError(path,
+			errors.Errorf("block_size must be odd and at most %d, got %d", maxBlockSize, cfg.BlockSize))
+	}
+	if cfg.P1 < 0 || cfg.P2 < 0 || (cfg.P2 != 0 && cfg.P2 <= cfg.P1) {
+		return nil, resource.NewConfigValidationError(path, errors.New("p1 and p2 must be positive and p2 greater than p1"))
+	}
+	if cfg.UniquenessRatio != nil && (*cfg.UniquenessRatio < 0 || *cfg.UniquenessRatio >= 100) {
+		return nil, resource.NewConfigValidationError(path,
+			errors.Errorf("uniqueness_ratio must be a percentage below 100, got %d", *cfg.UniquenessRatio))
+	}
+	if cfg.MaxDepthMm < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("max_depth_mm cannot be negative"))
+	}
+	return []string{cfg.LeftCamera, cfg.RightCamera}, nil
+}
+
+// matchParams returns the parameters of the matcher, with their defaults filled in.
+func (cfg *Config) matchParams() matchParams {
+	params := matchParams{
+		minDisparity:     cfg.MinDisparity,
+		numDisparities:   cfg.NumDisparities,
+		blockSize:        cfg.BlockSize,
+		p1:               cfg.P1,
+		p2:               cfg.P2,
+		uniquenessRatio:  defaultUniquenessRatio,
+		maxLeftRightDiff: defaultMaxLeftRightDiff,
+		eightPaths:       cfg.EightPaths,
+	}
+	if params.numDisparities == 0 {
+		params.numDisparities = defaultNumDisparities
+	}
+	if params.blockSize == 0 {
+		params.blockSize = defaultBlockSize
+	}
+	if params.p1 == 0 {
+		params.p1 = params.blockSize * params.blockSize
+	}
+	if params.p2 == 0 {
+		params.p2 = max(4*params.blockSize*params.blockSize, params.p1+1)
+	}
+	if cfg.UniquenessRatio != nil {
+		params.uniquenessRatio = *cfg.UniquenessRatio
+	}
+	if cfg.MaxLeftRightDiff != nil {
+		params.maxLeftRightDiff = *cfg.MaxLeftRightDiff
+	}
+	return params
+}
+
+// stereoDepth computes depth maps from the rectified images of a stereo pair.
+type stereoDepth struct {
+	left, right         gostream.VideoStream
+	leftName, rightName string
+	rectifier           *rectifier
+	maxDepthMm          float64
+	imageType           camera.ImageType
+	logger              logging.Logger
+
+	mu      sync.Mutex
+	matcher *matcher
+}
+
+func newStereoDepth(ctx context.Context, left, right camera.VideoSource, conf *Config, logger logging.Logger,
+) (camera.VideoSource, error) {
+	leftIntrinsics, leftDistortion, err := calibrationOf(ctx, left, conf.LeftCamera)
+	if err != nil {
+		return nil, err
+	}
+	rightIntrinsics, rightDistortion, err := calibrationOf(ctx, right, conf.RightCamera)
+	if err != nil {
+		return nil, err
+	}
+	rotation := rotationFromVector(r3.Vector{})
+	if len(conf.Calibration.RotationMatrix) > 0 {
+		if rotation, err = spatialmath.NewRotationMatrix(conf.Calibration.RotationMatrix); err != nil {
+			return nil, err
+		}
+	}
+	rect, err := newRectifier(leftIntrinsics, rightIntrinsics, leftDistortion, rightDistortion,
+		rotation, conf.Calibration.TranslationMm)
+	if err != nil {
+		return nil, err
+	}
+	imgType := camera.ImageType(conf.ImageType)
+	if imgType == camera.UnspecifiedStream {
+		imgType = camera.DepthStream
+	}
+	maxDepth := float64(math.MaxUint16)
+	if conf.MaxDepthMm > 0 {
+		maxDepth = math.Min(maxDepth, float64(conf.MaxDepthMm))
+	}
+	sd := &stereoDepth{
+		left:       gostream.NewEmbeddedVideoStream(left),
+		right:      gostream.NewEmbeddedVideoStream(right),
+		leftName:   conf.LeftCamera,
+		rightName:  conf.RightCamera,
+		rectifier:  rect,
+		maxDepthMm: maxDepth,
+		imageType:  imgType,
+		logger:     logger,
+		matcher:    newMatcher(conf.matchParams(), rect.intrinsics.Width, rect.intrinsics.Height),
+	}
+	cameraModel := camera.NewPinholeModelWithBrownConradyDistortion(rect.intrinsics, nil)
+	return camera.NewVideoSourceFromReader(ctx, sd, &cameraModel, imgType)
+}
+
+// calibrationOf returns the intrinsics and distortion of a camera of the pair from its properties.
+func calibrationOf(ctx context.Context, cam camera.VideoSource, name string,
+) (*transform.PinholeCameraIntrinsics, *transform.BrownConrady, error) {
+	props, err := cam.Properties(ctx)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "could not get the properties of camera %q", name)
+	}
+	if err := props.IntrinsicParams.CheckValid(); err != nil {
+		return nil, nil, errors.Wrapf(err, "camera %q must have intrinsic_parameters to be part of a stereo pair", name)
+	}
+	if props.DistortionParams == nil {
+		return props.IntrinsicParams, nil, nil
+	}
+	distortion, ok := props.DistortionParams.(*transform.BrownConrady)
+	if !ok {
+		return nil, nil, errors.Errorf("camera %q has %s distortion, only %s is supported",
+			name, props.DistortionParams.ModelType(), transform.BrownConradyDistortionType)
+	}
+	return props.IntrinsicParams, distortion, nil
+}
+
+// Read returns the next depth map, or the next rectified image of the left camera if the output image type is color.
+func (sd *stereoDepth) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "stereo::stereoDepth::Read")
+	defer span.End()
+	col, dm, err := sd.next(ctx, sd.imageType == camera.ColorStream)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sd.imageType == camera.ColorStream {
+		return col, func() {}, nil
+	}
+	return dm, func() {}, nil
+}
+
+// Images returns the rectified image of the left camera and the depth map aligned with it.
+func (sd *stereoDepth) Images(ctx context.Context) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+	ctx, span := trace.StartSpan(ctx, "stereo::stereoDepth::Images")
+	defer span.End()
+	col, dm, err := sd.next(ctx, true)
+	if err != nil {
+		return nil, resource.ResponseMetadata{}, err
+	}
+	return []camera.NamedImage{{Image: col, SourceName: "color"}, {Image: dm, SourceName: "depth"}},
+		resource.ResponseMetadata{CapturedAt: time.Now()}, nil
+}
+
+// NextPointCloud returns the points of the next depth map, colored by the rectified image of the left camera.
+func (sd *stereoDepth) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, error) {
+	ctx, span := trace.StartSpan(ctx, "stereo::stereoDepth::NextPointCloud")
+	defer span.End()
+	col, dm, err := sd.next(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	// unlike RGBDToPointCloud, the pixels without depth are dropped rather than projected to the origin
+	intrinsics := sd.rectifier.intrinsics
+	pc := pointcloud.New()
+	for y := 0; y < dm.Height(); y++ {
+		for x := 0; x < dm.Width(); x++ {
+			depth := dm.GetDepth(x, y)
+			if depth == 0 {
+				continue
+			}
+			px, py, pz := intrinsics.PixelToPoint(float64(x), float64(y), float64(depth))
+			r, g, b := col.GetXY(x, y).RGB255()
+			if err := pc.Set(pointcloud.NewVector(px, py, pz), pointcloud.NewColoredData(color.NRGBA{r, g, b, 255})); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return pc, nil
+}
+
+// next reads an image from both cameras, and returns the rectified left image if withColor is set and the depth map.
+func (sd *stereoDepth) next(ctx context.Context, withColor bool) (*rimage.Image, *rimage.DepthMap, error) {
+	var leftImg, rightImg image.Image
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.Go(func() error {
+		var err error
+		leftImg, err = sd.nextFrom(groupCtx, sd.left, sd.leftName)
+		return err
+	})
+	group.Go(func() error {
+		var err error
+		rightImg, err = sd.nextFrom(groupCtx, sd.right, sd.rightName)
+		return err
+	})
+	if err := group.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	col, leftGray := sd.rectifier.rectify(leftImg, sd.rectifier.leftMap, withColor)
+	_, rightGray := sd.rectifier.rectify(rightImg, sd.rectifier.rightMap, false)
+	sd.mu.Lock()
+	disparity := sd.matcher.disparity(leftGray, rightGray)
+	sd.mu.Unlock()
+	return col, sd.depthFromDisparity(disparity), nil
+}
+
+// nextFrom reads an image from a camera of the pair, which must be at the resolution it was calibrated at.
+func (sd *stereoDepth) nextFrom(ctx context.Context, stream gostream.VideoStream, name string) (image.Image, error) {
+	img, release, err := stream.Next(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get an image from camera %q", name)
+	}
+	// the image is converted before the stream reuses it
+	converted := rimage.ConvertImage(img)
+	if release != nil {
+		release()
+	}
+	if w, h := converted.Width(), converted.Height(); w != sd.rectifier.intrinsics.Width || h != sd.rectifier.intrinsics.Height {
+		return nil, errors.Errorf("camera %q streams %dx%d images but its intrinsic_parameters are for %dx%d",
+			name, w, h, sd.rectifier.intrinsics.Width, sd.rectifier.intrinsics.Height)
+	}
+	return converted, nil
+}
+
+// depthFromDisparity returns the depth of each pixel of a disparity map, which is the focal length times the baseline
+// over the disparity, or zero where it is unknown or too far.
+func (sd *stereoDepth) depthFromDisparity(disparity []float32) *rimage.DepthMap {
+	width, height := sd.rectifier.intrinsics.Width, sd.rectifier.intrinsics.Height
+	scale := sd.rectifier.intrinsics.Fx * sd.rectifier.baselineMm
+	dm := rimage.NewEmptyDepthMap(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			d := float64(disparity[y*width+x])
+			if d <= 0 {
+				continue
+			}
+			if depth := scale / d; depth <= sd.maxDepthMm {
+				dm.Set(x, y, rimage.Depth(depth+0.5))
+			}
+		}
+	}
+	return dm
+}
+
+func (sd *stereoDepth) Close(ctx context.Context) error {
+	return multierr.Combine(sd.left.Close(ctx), sd.right.Close(ctx))
+}
@@ -0,0 +1,203 @@
+package stereo
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/gostream"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+)
+
+const (
+	testWidth, testHeight = 80, 60
+	testFocal             = 50.
+	testShift             = 6
+)
+
+// texturedPair returns the images a stereo pair sees of a fronto-parallel plane covered in random texture, at the
+// depth where its disparity is testShift pixels.
+func texturedPair() (*image.Gray, *image.Gray) {
+	rng := rand.New(rand.NewSource(1))
+	stride := testWidth + 2*testShift
+	texture := make([]uint8, stride*testHeight)
+	for i := range texture {
+		texture[i] = uint8(rng.Intn(256))
+	}
+	left := image.NewGray(image.Rect(0, 0, testWidth, testHeight))
+	right := image.NewGray(image.Rect(0, 0, testWidth, testHeight))
+	for y := 0; y < testHeight; y++ {
+		for x := 0; x < testWidth; x++ {
+			left.SetGray(x, y, color.Gray{texture[y*stride+x+testShift]})
+			right.SetGray(x, y, color.Gray{texture[y*stride+x+2*testShift]})
+		}
+	}
+	return left, right
+}
+
+func TestConfigValidate(t *testing.T) {
+	conf := &Config{
+		LeftCamera:  "left",
+		RightCamera: "right",
+		Calibration: Calibration{TranslationMm: r3.Vector{X: -60}},
+	}
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"left", "right"})
+
+	params := conf.matchParams()
+	test.That(t, params.numDisparities, test.ShouldEqual, defaultNumDisparities)
+	test.That(t, params.blockSize, test.ShouldEqual, defaultBlockSize)
+	test.That(t, params.p2, test.ShouldBeGreaterThan, params.p1)
+
+	for _, tc := range []struct {
+		name   string
+		modify func(*Config)
+		errStr string
+	}{
+		{"no left camera", func(c *Config) { c.LeftCamera = "" }, "left_camera_name"},
+		{"same cameras", func(c *Config) { c.RightCamera = "left" }, "must differ"},
+		{"no translation", func(c *Config) { c.Calibration.TranslationMm = r3.Vector{} }, "translation_mm"},
+		{"short rotation", func(c *Config) { c.Calibration.RotationMatrix = []float64{1, 0, 0} }, "9 elements"},
+		{"even block", func(c *Config) { c.BlockSize = 4 }, "block_size"},
+		{"bad penalties", func(c *Config) { c.P1, c.P2 = 10, 5 }, "p2 greater than p1"},
+		{"bad image type", func(c *Config) { c.ImageType = "thermal" }, "thermal"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			bad := *conf
+			tc.modify(&bad)
+			_, err := bad.Validate("path")
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldContainSubstring, tc.errStr)
+		})
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	left, right := texturedPair()
+	conf := &Config{NumDisparities: 16}
+	m := newMatcher(conf.matchParams(), testWidth, testHeight)
+	disparity := m.disparity(left.Pix, right.Pix)
+
+	// the pixels of the left border have no match in the right image
+	var valid int
+	for y := 0; y < testHeight; y++ {
+		for x := testShift; x < testWidth; x++ {
+			d := disparity[y*testWidth+x]
+			if d == invalidDisparity {
+				continue
+			}
+			valid++
+			test.That(t, d, test.ShouldAlmostEqual, testShift, 0.5)
+		}
+	}
+	test.That(t, valid, test.ShouldBeGreaterThan, testHeight*(testWidth-testShift)*9/10)
+}
+
+func TestRectifier(t *testing.T) {
+	intrinsics := &transform.PinholeCameraIntrinsics{
+		Width: testWidth, Height: testHeight, Fx: testFocal, Fy: testFocal, Ppx: 40, Ppy: 30,
+	}
+	r, err := newRectifier(intrinsics, intrinsics, nil, nil, identity(), r3.Vector{X: -60})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, r.baselineMm, test.ShouldAlmostEqual, 60)
+	test.That(t, r.intrinsics.Fx, test.ShouldEqual, testFocal)
+	// cameras which are already rectified are only shifted to center their principal points
+	test.That(t, r.leftMap[0], test.ShouldAlmostEqual, 0.5, 1e-4)
+	test.That(t, r.leftMap[1], test.ShouldAlmostEqual, 0.5, 1e-4)
+	test.That(t, r.rightMap, test.ShouldResemble, r.leftMap)
+
+	// a right camera rotated about the vertical is rotated back
+	rotated := rotationFromVector(r3.Vector{Y: 0.1})
+	r, err = newRectifier(intrinsics, intrinsics, nil, nil, rotated, rotated.Mul(r3.Vector{X: -60}))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, r.baselineMm, test.ShouldAlmostEqual, 60)
+	center := 2 * (30*testWidth + 40)
+	test.That(t, math.Abs(float64(r.leftMap[center]-r.rightMap[center])), test.ShouldAlmostEqual, testFocal*0.1, 0.2)
+
+	_, err = newRectifier(intrinsics, intrinsics, nil, nil, identity(), r3.Vector{Y: -60})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestStereoDepth(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	left, right := texturedPair()
+	intrinsics := &transform.PinholeCameraIntrinsics{
+		Width: testWidth, Height: testHeight, Fx: testFocal, Fy: testFocal, Ppx: 39.5, Ppy: 29.5,
+	}
+	source := func(img image.Image) camera.VideoSource {
+		model := camera.NewPinholeModelWithBrownConradyDistortion(intrinsics, nil)
+		src, err := camera.NewVideoSourceFromReader(ctx, gostream.VideoReaderFunc(
+			func(ctx context.Context) (image.Image, func(), error) { return img, func() {}, nil },
+		), &model, camera.ColorStream)
+		test.That(t, err, test.ShouldBeNil)
+		return src
+	}
+	leftCam, rightCam := source(left), source(right)
+	defer leftCam.Close(ctx)
+	defer rightCam.Close(ctx)
+
+	conf := &Config{
+		LeftCamera:     "left",
+		RightCamera:    "right",
+		Calibration:    Calibration{TranslationMm: r3.Vector{X: -60}},
+		NumDisparities: 16,
+	}
+	cam, err := newStereoDepth(ctx, leftCam, rightCam, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer cam.Close(ctx)
+
+	props, err := cam.Properties(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.IntrinsicParams.Fx, test.ShouldEqual, testFocal)
+
+	img, _, err := camera.ReadImage(ctx, cam)
+	test.That(t, err, test.ShouldBeNil)
+	dm, err := rimage.ConvertImageToDepthMap(ctx, img)
+	test.That(t, err, test.ShouldBeNil)
+	var depths []int
+	for _, d := range dm.Data() {
+		if d != 0 {
+			depths = append(depths, int(d))
+		}
+	}
+	test.That(t, len(depths), test.ShouldBeGreaterThan, testWidth*testHeight/2)
+	sort.Ints(depths)
+	test.That(t, depths[len(depths)/2], test.ShouldAlmostEqual, testFocal*60/testShift, 5)
+
+	imgs, _, err := cam.Images(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, imgs, test.ShouldHaveLength, 2)
+	test.That(t, imgs[0].SourceName, test.ShouldEqual, "color")
+	test.That(t, imgs[1].SourceName, test.ShouldEqual, "depth")
+
+	pc, err := cam.NextPointCloud(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pc.Size(), test.ShouldEqual, len(depths))
+
+	// cameras without intrinsics cannot be rectified
+	bare, err := camera.NewVideoSourceFromReader(ctx, gostream.VideoReaderFunc(
+		func(ctx context.Context) (image.Image, func(), error) { return left, func() {}, nil },
+	), nil, camera.ColorStream)
+	test.That(t, err, test.ShouldBeNil)
+	defer bare.Close(ctx)
+	_, err = newStereoDepth(ctx, bare, rightCam, conf, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "intrinsic_parameters")
+}
+
+func identity() *spatialmath.RotationMatrix {
+	return rotationFromVector(r3.Vector{})
+}
@@ -0,0 +1,131 @@
+package transformpipeline
+
+import (
+	"context"
+	"image"
+
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/gostream"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/utils"
+)
+
+// debayerConfig are the attributes for a debayer transform.
+type debayerConfig struct {
+	// Pattern is the Bayer pattern of sources which send their raw mosaic as gray images. Raw Bayer images carry
+	// their own pattern, which this overrides.
+	Pattern string `json:"pattern,omitempty"`
+	// BitDepth is how many low bits of a 16 bit gray image hold the mosaic, 16 when unset.
+	BitDepth         int                  `json:"bit_depth,omitempty"`
+	WhiteBalance     *rimage.WhiteBalance `json:"white_balance,omitempty"`
+	AutoWhiteBalance bool                 `json:"auto_white_balance,omitempty"`
+}
+
+type debayerSource struct {
+	originalStream gostream.VideoStream
+	conf           *debayerConfig
+}
+
+// newDebayerTransform creates a transform which debayers raw images into color images.
+func newDebayerTransform(
+	ctx context.Context, source gostream.VideoSource, stream camera.ImageType, am utils.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	if stream != camera.ColorStream && stream != camera.UnspecifiedStream {
+		return nil, camera.UnspecifiedStream, errors.Errorf("source has stream type %s, debayer only supports color streams", stream)
+	}
+	conf, err := resource.TransformAttributeMap[*debayerConfig](am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, errors.Wrap(err, "cannot parse debayer attribute map")
+	}
+	if conf.Pattern != "" {
+		if err := rimage.BayerPattern(conf.Pattern).Validate(); err != nil {
+			return nil, camera.UnspecifiedStream, err
+		}
+	}
+	if conf.BitDepth < 0 || conf.BitDepth > 16 {
+		return nil, camera.UnspecifiedStream, errors.Errorf("debayer bit_depth must be from 1 to 16, got %d", conf.BitDepth)
+	}
+	if conf.WhiteBalance != nil && conf.AutoWhiteBalance {
+		return nil, camera.UnspecifiedStream, errors.New("debayer takes either white_balance or auto_white_balance, not both")
+	}
+
+	props, err := propsFromVideoSource(ctx, source)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	cameraModel := transform.PinholeCameraModel{PinholeCameraIntrinsics: props.IntrinsicParams}
+	if props.DistortionParams != nil {
+		cameraModel.Distortion = props.DistortionParams
+	}
+	reader := &debayerSource{gostream.NewEmbeddedVideoStream(source), conf}
+	src, err := camera.NewVideoSourceFromReader(ctx, reader, &cameraModel, camera.ColorStream)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	return src, camera.ColorStream, err
+}
+
+// Read debayers the raw image of the source.
+func (ds *debayerSource) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::debayer::Read")
+	defer span.End()
+	orig, release, err := ds.originalStream.Next(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if lazy, ok := orig.(*rimage.LazyEncodedImage); ok {
+		if orig, err = rimage.DecodeImage(ctx, lazy.RawData(), lazy.MIMEType()); err != nil {
+			return nil, nil, err
+		}
+	}
+	bayer, err := ds.bayerImage(orig)
+	if err != nil {
+		return nil, nil, err
+	}
+	wb := rimage.WhiteBalance{Red: 1, Green: 1, Blue: 1}
+	switch {
+	case ds.conf.WhiteBalance != nil:
+		wb = *ds.conf.WhiteBalance
+	case ds.conf.AutoWhiteBalance:
+		wb = bayer.GrayWorldWhiteBalance()
+	}
+	return bayer.Debayer(wb), release, nil
+}
+
+// bayerImage returns the raw Bayer image of a source image, read from a gray image with the configured pattern.
+func (ds *debayerSource) bayerImage(img image.Image) (*rimage.BayerImage, error) {
+	pattern := rimage.BayerPattern(ds.conf.Pattern)
+	switch raw := img.(type) {
+	case *rimage.BayerImage:
+		if pattern == "" || pattern == raw.Pattern {
+			return raw, nil
+		}
+		overridden := *raw
+		overridden.Pattern = pattern
+		return &overridden, nil
+	case *image.Gray, *image.Gray16:
+		if pattern == "" {
+			return nil, errors.New("debayer needs a pattern for sources which send gray images")
+		}
+		bitDepth := 8
+		if _, ok := raw.(*image.Gray16); ok {
+			bitDepth = 16
+			if ds.conf.BitDepth != 0 {
+				bitDepth = ds.conf.BitDepth
+			}
+		}
+		return rimage.BayerImageFromGray(raw, pattern, bitDepth)
+	default:
+		return nil, errors.Errorf("debayer needs raw bayer or gray images, got %T", img)
+	}
+}
+
+// Close closes the original stream.
+func (ds *debayerSource) Close(ctx context.Context) error {
+	return ds.originalStream.Close(ctx)
+}
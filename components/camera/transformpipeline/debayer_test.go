@@ -0,0 +1,82 @@
+package transformpipeline
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/pion/mediadevices/pkg/prop"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/camera/videosource"
+	"go.viam.com/rdk/gostream"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/utils"
+)
+
+func TestDebayer(t *testing.T) {
+	ctx := context.Background()
+	// an RGGB sensor looking at a surface which reflects twice as much green as red or blue
+	gray := image.NewGray(image.Rect(0, 0, 8, 6))
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 8; x++ {
+			v := uint8(100)
+			if (x+y)%2 == 1 {
+				v = 200
+			}
+			gray.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	source := gostream.NewVideoSource(&videosource.StaticSource{ColorImg: gray}, prop.Video{})
+	defer source.Close(ctx)
+
+	_, _, err := newDebayerTransform(ctx, source, camera.DepthStream, utils.AttributeMap{"pattern": "RGGB"})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = newDebayerTransform(ctx, source, camera.ColorStream, utils.AttributeMap{"pattern": "RGBG"})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = newDebayerTransform(ctx, source, camera.ColorStream, utils.AttributeMap{
+		"pattern":            "RGGB",
+		"white_balance":      map[string]interface{}{"red": 1, "green": 1, "blue": 1},
+		"auto_white_balance": true,
+	})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// gray images need a pattern
+	db, stream, err := newDebayerTransform(ctx, source, camera.ColorStream, utils.AttributeMap{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.ColorStream)
+	_, _, err = camera.ReadImage(ctx, db)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, db.Close(ctx), test.ShouldBeNil)
+
+	db, _, err = newDebayerTransform(ctx, source, camera.ColorStream, utils.AttributeMap{"pattern": "RGGB"})
+	test.That(t, err, test.ShouldBeNil)
+	out, _, err := camera.ReadImage(ctx, db)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, rimage.ConvertImage(out).GetXY(3, 3), test.ShouldResemble, rimage.NewColor(100, 200, 100))
+	test.That(t, db.Close(ctx), test.ShouldBeNil)
+
+	// the gray world white balance makes the surface gray
+	db, _, err = newDebayerTransform(ctx, source, camera.ColorStream, utils.AttributeMap{"pattern": "RGGB", "auto_white_balance": true})
+	test.That(t, err, test.ShouldBeNil)
+	out, _, err = camera.ReadImage(ctx, db)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, rimage.ConvertImage(out).GetXY(3, 3), test.ShouldResemble, rimage.NewColor(200, 200, 200))
+	test.That(t, db.Close(ctx), test.ShouldBeNil)
+
+	// raw bayer images carry their own pattern
+	raw, err := rimage.BayerImageFromGray(gray, rimage.BayerGRBG, 8)
+	test.That(t, err, test.ShouldBeNil)
+	rawSource := gostream.NewVideoSource(&videosource.StaticSource{ColorImg: raw}, prop.Video{})
+	defer rawSource.Close(ctx)
+	db, _, err = newDebayerTransform(ctx, rawSource, camera.ColorStream, utils.AttributeMap{
+		"white_balance": map[string]interface{}{"red": 1, "green": 0.5, "blue": 1},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	out, _, err = camera.ReadImage(ctx, db)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, rimage.ConvertImage(out).GetXY(3, 3), test.ShouldResemble, rimage.NewColor(200, 50, 200))
+	test.That(t, db.Close(ctx), test.ShouldBeNil)
+}
@@ -100,6 +100,7 @@ type resizeSource struct {
 	stream         camera.ImageType
 	height         int
 	width          int
+	frames         *gostream.FramePool
 }
 
 // newResizeTransform creates a new resize transform.
@@ -117,7 +118,7 @@ func newResizeTransform(
 		return nil, camera.UnspecifiedStream, errors.New("new height for resize transform cannot be 0")
 	}
 
-	reader := &resizeSource{gostream.NewEmbeddedVideoStream(source), stream, conf.Height, conf.Width}
+	reader := &resizeSource{gostream.NewEmbeddedVideoStream(source), stream, conf.Height, conf.Width, gostream.NewFramePool()}
 	src, err := camera.NewVideoSourceFromReader(ctx, reader, nil, stream)
 	if err != nil {
 		return nil, camera.UnspecifiedStream, err
@@ -135,9 +136,12 @@ func (rs *resizeSource) Read(ctx context.Context) (image.Image, func(), error) {
 	}
 	switch rs.stream {
 	case camera.ColorStream, camera.UnspecifiedStream:
-		dst := image.NewRGBA(image.Rect(0, 0, rs.width, rs.height))
-		draw.NearestNeighbor.Scale(dst, dst.Bounds(), orig, orig.Bounds(), draw.Over, nil)
-		return dst, release, nil
+		// the resized frame is a copy, so the original can go back to its source right away
+		if release != nil {
+			defer release()
+		}
+		dst, releaseDst := gostream.ResizeFrame(rs.frames, orig, rs.width, rs.height)
+		return dst, releaseDst, nil
 	case camera.DepthStream:
 		dm, err := rimage.ConvertImageToGray16(orig)
 		if err != nil {
@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"image"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.opencensus.io/trace"
@@ -84,6 +85,12 @@ func (cfg *transformConfig) Validate(path string) ([]string, error) {
 	}
 
 	deps = append(deps, cfg.Source)
+	for _, tr := range cfg.Pipeline {
+		// a registered depth map is aligned to another camera, which the pipeline depends on too
+		if colorCamera, ok := tr.Attributes["color_camera"].(string); ok && transformType(tr.Type) == transformTypeRegisterDepth {
+			deps = append(deps, colorCamera)
+		}
+	}
 	return deps, nil
 }
 
@@ -164,6 +171,20 @@ func (tp transformPipeline) NextPointCloud(ctx context.Context) (pointcloud.Poin
 	return nil, errors.New("function NextPointCloud not defined for last videosource in transform pipeline")
 }
 
+func (tp transformPipeline) Images(ctx context.Context) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::Images")
+	defer span.End()
+	if lastElem, ok := tp.pipeline[len(tp.pipeline)-1].(camera.ImagesSource); ok {
+		return lastElem.Images(ctx)
+	}
+	// the image outlives this call, so it is not released
+	img, _, err := tp.stream.Next(ctx)
+	if err != nil {
+		return nil, resource.ResponseMetadata{}, err
+	}
+	return []camera.NamedImage{{Image: img}}, resource.ResponseMetadata{CapturedAt: time.Now()}, nil
+}
+
 func (tp transformPipeline) Close(ctx context.Context) error {
 	var errs error
 	for _, src := range tp.pipeline {
@@ -0,0 +1,181 @@
+package transformpipeline
+
+import (
+	"context"
+	"image"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/gostream"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// registerDepthConfig are the attributes for a register_depth transform. The intrinsics default to those of the
+// cameras, and the pose of the depth camera in the frame of the color camera defaults to the cameras coinciding.
+type registerDepthConfig struct {
+	ColorCamera     string                             `json:"color_camera"`
+	DepthIntrinsics *transform.PinholeCameraIntrinsics `json:"depth_intrinsic_parameters,omitempty"`
+	ColorIntrinsics *transform.PinholeCameraIntrinsics `json:"color_intrinsic_parameters,omitempty"`
+	DepthToColor    *referenceframe.LinkConfig         `json:"depth_to_color,omitempty"`
+}
+
+// registerDepthSource reprojects the depth maps of its source onto the image plane of a color camera, so depth and
+// color line up pixel for pixel.
+type registerDepthSource struct {
+	stream          gostream.VideoStream
+	color           camera.Camera
+	depthIntrinsics *transform.PinholeCameraIntrinsics
+	colorIntrinsics *transform.PinholeCameraIntrinsics
+	depthToColor    spatialmath.Pose
+}
+
+func newRegisterDepthTransform(
+	ctx context.Context,
+	source gostream.VideoSource,
+	stream camera.ImageType,
+	r robot.Robot,
+	am utils.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	if stream != camera.DepthStream {
+		return nil, camera.UnspecifiedStream,
+			errors.Errorf("source has stream type %s, register_depth only supports depth stream inputs", stream)
+	}
+	conf, err := resource.TransformAttributeMap[*registerDepthConfig](am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	if conf.ColorCamera == "" {
+		return nil, camera.UnspecifiedStream, errors.New("register_depth needs a color_camera")
+	}
+	color, err := camera.FromRobot(r, conf.ColorCamera)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+
+	depthIntrinsics := conf.DepthIntrinsics
+	if depthIntrinsics == nil {
+		props, err := propsFromVideoSource(ctx, source)
+		if err != nil {
+			return nil, camera.UnspecifiedStream, err
+		}
+		depthIntrinsics = props.IntrinsicParams
+	}
+	colorIntrinsics := conf.ColorIntrinsics
+	if colorIntrinsics == nil {
+		props, err := color.Properties(ctx)
+		if err != nil {
+			return nil, camera.UnspecifiedStream, err
+		}
+		colorIntrinsics = props.IntrinsicParams
+	}
+	for _, intrinsics := range []*transform.PinholeCameraIntrinsics{depthIntrinsics, colorIntrinsics} {
+		if intrinsics == nil {
+			return nil, camera.UnspecifiedStream,
+				errors.Wrap(transform.ErrNoIntrinsics, "register_depth needs the intrinsics of the depth and color cameras")
+		}
+		if err := intrinsics.CheckValid(); err != nil {
+			return nil, camera.UnspecifiedStream, err
+		}
+	}
+	depthToColor := spatialmath.NewZeroPose()
+	if conf.DepthToColor != nil {
+		if depthToColor, err = conf.DepthToColor.Pose(); err != nil {
+			return nil, camera.UnspecifiedStream, err
+		}
+	}
+
+	reader := &registerDepthSource{
+		stream:          gostream.NewEmbeddedVideoStream(source),
+		color:           color,
+		depthIntrinsics: depthIntrinsics,
+		colorIntrinsics: colorIntrinsics,
+		depthToColor:    depthToColor,
+	}
+	cameraModel := transform.PinholeCameraModel{PinholeCameraIntrinsics: colorIntrinsics}
+	src, err := camera.NewVideoSourceFromReader(ctx, reader, &cameraModel, camera.DepthStream)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	return src, camera.DepthStream, err
+}
+
+// nextDepth returns the next depth map of the source, registered to the color camera.
+func (rds *registerDepthSource) nextDepth(ctx context.Context) (*rimage.DepthMap, error) {
+	img, release, err := rds.stream.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	dm, err := rimage.ConvertImageToDepthMap(ctx, img)
+	if err != nil {
+		return nil, errors.Wrap(err, "source camera does not make depth maps")
+	}
+	return transform.RegisterDepthMap(dm, rds.depthIntrinsics, rds.colorIntrinsics, rds.depthToColor)
+}
+
+// nextRGBD returns the next color image and the depth map registered to it.
+func (rds *registerDepthSource) nextRGBD(ctx context.Context) (*rimage.Image, *rimage.DepthMap, error) {
+	dm, err := rds.nextDepth(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	img, release, err := camera.ReadImage(gostream.WithMIMETypeHint(ctx, utils.MimeTypeRawRGBA), rds.color)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+	col := rimage.ConvertImage(img)
+	if col.Width() != rds.colorIntrinsics.Width || col.Height() != rds.colorIntrinsics.Height {
+		return nil, nil, errors.Errorf("color intrinsics expect an image of (%d, %d), got (%d, %d)",
+			rds.colorIntrinsics.Width, rds.colorIntrinsics.Height, col.Width(), col.Height())
+	}
+	return col, dm, nil
+}
+
+// Read returns the depth map registered to the color camera.
+func (rds *registerDepthSource) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::registerDepth::Read")
+	defer span.End()
+	dm, err := rds.nextDepth(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dm, func() {}, nil
+}
+
+// Images returns the color image and the depth map registered to it, as aligned RGB-D.
+func (rds *registerDepthSource) Images(ctx context.Context) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::registerDepth::Images")
+	defer span.End()
+	col, dm, err := rds.nextRGBD(ctx)
+	if err != nil {
+		return nil, resource.ResponseMetadata{}, err
+	}
+	return []camera.NamedImage{{Image: col, SourceName: "color"}, {Image: dm, SourceName: "depth"}},
+		resource.ResponseMetadata{CapturedAt: time.Now()}, nil
+}
+
+// NextPointCloud returns the points of the registered depth map, colored by the color camera.
+func (rds *registerDepthSource) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::registerDepth::NextPointCloud")
+	defer span.End()
+	col, dm, err := rds.nextRGBD(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return rds.colorIntrinsics.RGBDToPointCloud(col, dm)
+}
+
+func (rds *registerDepthSource) Close(ctx context.Context) error {
+	return rds.stream.Close(ctx)
+}
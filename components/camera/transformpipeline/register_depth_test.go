@@ -0,0 +1,109 @@
+package transformpipeline
+
+import (
+	"context"
+	"image"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"github.com/pion/mediadevices/pkg/prop"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/camera/videosource"
+	"go.viam.com/rdk/gostream"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+)
+
+func TestRegisterDepth(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	depthIntrinsics := &transform.PinholeCameraIntrinsics{Width: 8, Height: 6, Fx: 8, Fy: 8, Ppx: 4, Ppy: 3}
+	colorIntrinsics := &transform.PinholeCameraIntrinsics{Width: 16, Height: 12, Fx: 16, Fy: 16, Ppx: 8, Ppy: 6}
+	dm := rimage.NewEmptyDepthMap(8, 6)
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 8; x++ {
+			dm.Set(x, y, 1000)
+		}
+	}
+	colorImg := rimage.NewImage(16, 12)
+	for y := 0; y < 12; y++ {
+		for x := 0; x < 16; x++ {
+			colorImg.Set(image.Pt(x, y), rimage.NewColor(200, 10, 10))
+		}
+	}
+	colorModel := transform.PinholeCameraModel{PinholeCameraIntrinsics: colorIntrinsics}
+	colorSrc, err := camera.NewVideoSourceFromReader(ctx, &videosource.StaticSource{ColorImg: colorImg}, &colorModel, camera.ColorStream)
+	test.That(t, err, test.ShouldBeNil)
+	colorCam := camera.FromVideoSource(camera.Named("color"), colorSrc, logger)
+	r := &inject.Robot{}
+	r.ResourceByNameFunc = func(n resource.Name) (resource.Resource, error) {
+		if n.Name == "color" {
+			return colorCam, nil
+		}
+		return nil, resource.NewNotFoundError(n)
+	}
+
+	source := gostream.NewVideoSource(&videosource.StaticSource{DepthImg: dm}, prop.Video{})
+	am := utils.AttributeMap{"color_camera": "color"}
+
+	// color images cannot be registered
+	_, _, err = newRegisterDepthTransform(ctx, source, camera.ColorStream, r, am)
+	test.That(t, err, test.ShouldNotBeNil)
+	// the depth source has no intrinsics of its own
+	_, _, err = newRegisterDepthTransform(ctx, source, camera.DepthStream, r, am)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	am["depth_intrinsic_parameters"] = depthIntrinsics
+	am["depth_to_color"] = map[string]interface{}{"translation": map[string]interface{}{"x": 0, "y": 0, "z": 0}}
+	rd, stream, err := newRegisterDepthTransform(ctx, source, camera.DepthStream, r, am)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.DepthStream)
+
+	img, _, err := camera.ReadImage(ctx, rd)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, img.Bounds(), test.ShouldResemble, image.Rect(0, 0, 16, 12))
+	test.That(t, img.(*rimage.DepthMap).GetDepth(8, 6), test.ShouldEqual, rimage.Depth(1000))
+
+	// aligned RGB-D
+	imgs, _, err := rd.(camera.ImagesSource).Images(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, imgs, test.ShouldHaveLength, 2)
+	test.That(t, imgs[0].SourceName, test.ShouldEqual, "color")
+	test.That(t, imgs[1].SourceName, test.ShouldEqual, "depth")
+	test.That(t, imgs[1].Image.Bounds(), test.ShouldResemble, imgs[0].Image.Bounds())
+
+	pc, err := rd.(camera.PointCloudSource).NextPointCloud(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pc.Size(), test.ShouldBeGreaterThan, 0)
+	pc.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		test.That(t, p.Z, test.ShouldEqual, 1000)
+		test.That(t, d.HasColor(), test.ShouldBeTrue)
+		red, _, _, _ := d.Color().RGBA()
+		test.That(t, red>>8, test.ShouldEqual, 200)
+		return true
+	})
+
+	test.That(t, rd.Close(ctx), test.ShouldBeNil)
+	test.That(t, source.Close(ctx), test.ShouldBeNil)
+	test.That(t, colorCam.Close(ctx), test.ShouldBeNil)
+}
+
+func TestRegisterDepthDependencies(t *testing.T) {
+	conf := &transformConfig{
+		Source: "depth",
+		Pipeline: []Transformation{
+			{Type: "register_depth", Attributes: utils.AttributeMap{"color_camera": "color"}},
+		},
+	}
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"depth", "color"})
+}
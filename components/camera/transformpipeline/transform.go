@@ -30,6 +30,8 @@ const (
 	transformTypeSegmentations   = transformType("segmentations")
 	transformTypeDepthEdges      = transformType("depth_edges")
 	transformTypeDepthPreprocess = transformType("depth_preprocess")
+	transformTypeRegisterDepth   = transformType("register_depth")
+	transformTypeDebayer         = transformType("debayer")
 )
 
 // transformRegistration holds pertinent information regarding the available transforms.
@@ -83,7 +85,7 @@ var registeredTransformConfigs = map[transformType]*transformRegistration{
 	transformTypeUndistort: {
 		string(transformTypeUndistort),
 		&undistortConfig{},
-		"Uses intrinsics and modified Brown-Conrady parameters to undistort the source image.",
+		"Uses intrinsics and modified Brown-Conrady or fisheye (Kannala-Brandt) parameters to undistort the source image.",
 	},
 	transformTypeDetections: {
 		string(transformTypeDetections),
@@ -110,6 +112,16 @@ var registeredTransformConfigs = map[transformType]*transformRegistration{
 		&depthPreprocessConfig{},
 		"Applies some basic hole-filling and edge smoothing to a depth map.",
 	},
+	transformTypeRegisterDepth: {
+		string(transformTypeRegisterDepth),
+		&registerDepthConfig{},
+		"Reprojects a depth map onto the image of a color camera, producing aligned RGB-D images and colored point clouds.",
+	},
+	transformTypeDebayer: {
+		string(transformTypeDebayer),
+		&debayerConfig{},
+		"Interpolates the raw mosaic of a Bayer sensor into a color image, with an optional white balance.",
+	},
 }
 
 // Transformation states the type of transformation and the attributes that are specific to the given type.
@@ -167,6 +179,10 @@ func buildTransform(
 		return newDepthEdgesTransform(ctx, source, tr.Attributes)
 	case transformTypeDepthPreprocess:
 		return newDepthPreprocessTransform(ctx, source)
+	case transformTypeRegisterDepth:
+		return newRegisterDepthTransform(ctx, source, stream, r, tr.Attributes)
+	case transformTypeDebayer:
+		return newDebayerTransform(ctx, source, stream, tr.Attributes)
 	default:
 		return nil, camera.UnspecifiedStream, errors.Errorf("do not know camera transform of type %q", tr.Type)
 	}
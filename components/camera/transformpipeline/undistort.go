@@ -18,6 +18,9 @@ import (
 type undistortConfig struct {
 	CameraParams     *transform.PinholeCameraIntrinsics `json:"intrinsic_parameters"`
 	DistortionParams *transform.BrownConrady            `json:"distortion_parameters"`
+	// FisheyeDistortionParams are the Kannala-Brandt parameters of a wide-angle or fisheye lens, used in place of
+	// DistortionParams.
+	FisheyeDistortionParams *transform.KannalaBrandt `json:"fisheye_distortion_parameters,omitempty"`
 }
 
 // undistortSource will undistort the original image according to the Distortion parameters
@@ -38,7 +41,14 @@ func newUndistortTransform(
 	if conf.CameraParams == nil {
 		return nil, camera.UnspecifiedStream, errors.Wrapf(transform.ErrNoIntrinsics, "cannot create undistort transform")
 	}
+	if conf.DistortionParams != nil && conf.FisheyeDistortionParams != nil {
+		return nil, camera.UnspecifiedStream,
+			errors.New("undistort takes either distortion_parameters or fisheye_distortion_parameters, not both")
+	}
 	cameraModel := camera.NewPinholeModelWithBrownConradyDistortion(conf.CameraParams, conf.DistortionParams)
+	if conf.FisheyeDistortionParams != nil {
+		cameraModel.Distortion = conf.FisheyeDistortionParams
+	}
 	reader := &undistortSource{
 		gostream.NewEmbeddedVideoStream(source),
 		stream,
@@ -3,6 +3,7 @@ package transformpipeline
 import (
 	"context"
 	"errors"
+	"image"
 	"testing"
 
 	"github.com/pion/mediadevices/pkg/prop"
@@ -143,3 +144,33 @@ func TestUndistortDepthMap(t *testing.T) {
 	test.That(t, err.Error(), test.ShouldContainSubstring, "don't know how to make DepthMap")
 	test.That(t, us.Close(context.Background()), test.ShouldBeNil)
 }
+
+func TestUndistortFisheye(t *testing.T) {
+	img := rimage.NewImage(64, 48)
+	img.Set(image.Pt(32, 24), rimage.Red)
+	source := gostream.NewVideoSource(&videosource.StaticSource{ColorImg: img}, prop.Video{})
+	params := &transform.PinholeCameraIntrinsics{Width: 64, Height: 48, Fx: 30, Fy: 30, Ppx: 32, Ppy: 24}
+
+	am := utils.AttributeMap{
+		"intrinsic_parameters":          params,
+		"distortion_parameters":         undistortTestBC,
+		"fisheye_distortion_parameters": &transform.KannalaBrandt{K1: 0.05},
+	}
+	_, _, err := newUndistortTransform(context.Background(), source, camera.ColorStream, am)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	delete(am, "distortion_parameters")
+	us, _, err := newUndistortTransform(context.Background(), source, camera.ColorStream, am)
+	test.That(t, err, test.ShouldBeNil)
+	undistorted, _, err := camera.ReadImage(context.Background(), us)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, undistorted.Bounds(), test.ShouldResemble, img.Bounds())
+	// the center of the image is where it was
+	test.That(t, rimage.ConvertImage(undistorted).GetXY(32, 24), test.ShouldResemble, rimage.Red)
+	props, err := us.(camera.VideoSource).Properties(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.DistortionParams.ModelType(), test.ShouldEqual, transform.KannalaBrandtDistortionType)
+
+	test.That(t, us.Close(context.Background()), test.ShouldBeNil)
+	test.That(t, source.Close(context.Background()), test.ShouldBeNil)
+}
@@ -7,9 +7,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"image"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pion/mediadevices"
@@ -169,11 +171,20 @@ type WebcamConfig struct {
 	CameraParameters     *transform.PinholeCameraIntrinsics `json:"intrinsic_parameters,omitempty"`
 	DistortionParameters *transform.BrownConrady            `json:"distortion_parameters,omitempty"`
 	Debug                bool                               `json:"debug,omitempty"`
-	Format               string                             `json:"format,omitempty"`
-	Path                 string                             `json:"video_path"`
-	Width                int                                `json:"width_px,omitempty"`
-	Height               int                                `json:"height_px,omitempty"`
-	FrameRate            float32                            `json:"frame_rate,omitempty"`
+	// Format is the frame format to stream in, such as MJPEG or YUYV. The V4L2 Bayer formats BA81, GBRG, GRBG and RGGB,
+	// and their 16 bit counterparts BYR2, GB16, GR16 and RG16, stream the raw frames of the sensor, which a debayer
+	// transform turns into color images.
+	Format    string  `json:"format,omitempty"`
+	Path      string  `json:"video_path"`
+	Width     int     `json:"width_px,omitempty"`
+	Height    int     `json:"height_px,omitempty"`
+	FrameRate float32 `json:"frame_rate,omitempty"`
+	// Controls sets V4L2 controls of the webcam by name, such as exposure_time_absolute or white_balance_temperature.
+	// The get_controls DoCommand lists those the webcam has.
+	Controls map[string]int32 `json:"controls,omitempty"`
+	// CaptureLatencyMs is how long frames take to reach the robot once captured. It is taken off the time a frame is
+	// read at to stamp it with when it was captured.
+	CaptureLatencyMs float64 `json:"capture_latency_ms,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -183,6 +194,17 @@ func (c WebcamConfig) Validate(path string) ([]string, error) {
 			"got illegal negative dimensions for width_px and height_px (%d, %d) fields set for webcam camera",
 			c.Height, c.Width)
 	}
+	for name := range c.Controls {
+		if name == "" {
+			return nil, resource.NewConfigValidationError(path, errors.New("controls cannot have an empty name"))
+		}
+	}
+	if c.CaptureLatencyMs < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("capture_latency_ms cannot be negative"))
+	}
+	if _, ok := bayerFormats[c.Format]; ok && c.Path == "" {
+		return nil, resource.NewConfigValidationError(path, errors.Errorf("webcams streaming in %s need a video_path", c.Format))
+	}
 
 	return []string{}, nil
 }
@@ -245,6 +267,13 @@ func findAndMakeVideoSource(
 	label string,
 	logger logging.Logger,
 ) (gostream.VideoSource, string, error) {
+	if format, ok := bayerFormats[conf.Format]; ok {
+		source, err := newBayerSource(conf, format)
+		if err != nil {
+			return nil, "", errors.Wrap(err, "cannot open webcam")
+		}
+		return source, label, nil
+	}
 	mediadevicescamera.Initialize()
 	debug := conf.Debug
 	constraints := makeConstraints(conf, debug, logger)
@@ -364,9 +393,15 @@ func (c *monitoredWebcam) Reconfigure(
 	}
 	c.exposedProjector = projector
 
+	// controls set at runtime are forgotten on reconfiguration
+	c.controls = make(map[string]int32, len(newConf.Controls))
+	for name, value := range newConf.Controls {
+		c.controls[name] = value
+	}
+
 	if c.underlyingSource != nil && !needDriverReinit {
 		c.conf = *newConf
-		return nil
+		return writeControls(c.targetPath, c.controls)
 	}
 	c.logger.CDebug(ctx, "reinitializing driver")
 
@@ -379,7 +414,7 @@ func (c *monitoredWebcam) Reconfigure(
 
 	// only set once we're good
 	c.conf = *newConf
-	return nil
+	return writeControls(c.targetPath, c.controls)
 }
 
 // tryWebcamOpen uses getNamedVideoSource to try and find a video device (gostream.MediaSource).
@@ -446,6 +481,10 @@ type monitoredWebcam struct {
 	// treats it as a video path.
 	targetPath string
 	conf       WebcamConfig
+	// controls are the V4L2 controls set by config or DoCommand, which are set again when the webcam reconnects
+	controls map[string]int32
+	// frameSequence counts the frames returned by Images
+	frameSequence atomic.Uint64
 
 	cancelCtx               context.Context
 	cancel                  func()
@@ -474,6 +513,11 @@ func (c *monitoredWebcam) isCameraConnected() (bool, error) {
 	if c.underlyingSource == nil {
 		return true, errors.New("no configured camera")
 	}
+	if _, ok := bayerFormats[c.conf.Format]; ok {
+		// webcams streaming a bayer format have no driver, and are connected while their device exists
+		_, err := os.Stat(v4l2DevicePath(c.targetPath))
+		return !os.IsNotExist(err), nil
+	}
 	d, err := gostream.DriverFromMediaSource[image.Image, prop.Video](c.underlyingSource)
 	if err != nil {
 		return true, errors.Wrap(err, "cannot get driver from media source")
@@ -569,6 +613,9 @@ func (c *monitoredWebcam) Monitor() {
 							return true
 						}
 						c.logger.Infow("camera reconnected")
+						if err := writeControls(c.targetPath, c.controls); err != nil {
+							c.logger.Errorw("failed to set controls of reconnected camera", "error", err)
+						}
 						return false
 					}()
 					if cont {
@@ -594,16 +641,27 @@ func (c *monitoredWebcam) Images(ctx context.Context) ([]camera.NamedImage, reso
 	if c, ok := c.underlyingSource.(camera.ImagesSource); ok {
 		return c.Images(ctx)
 	}
-	img, release, err := camera.ReadImage(ctx, c.underlyingSource)
+	// the image outlives this call, so it is not released
+	img, _, err := camera.ReadImage(ctx, c.underlyingSource)
 	if err != nil {
 		return nil, resource.ResponseMetadata{}, errors.Wrap(err, "monitoredWebcam: call to get Images failed")
 	}
-	defer func() {
-		if release != nil {
-			release()
-		}
-	}()
-	return []camera.NamedImage{{img, c.Name().Name}}, resource.ResponseMetadata{time.Now()}, nil
+	return []camera.NamedImage{{img, c.Name().Name}}, c.frameMetadata(time.Now()), nil
+}
+
+// frameMetadata returns the metadata of the next frame, read at the given time.
+func (c *monitoredWebcam) frameMetadata(readAt time.Time) resource.ResponseMetadata {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	md := resource.ResponseMetadata{
+		CapturedAt:    readAt.Add(-time.Duration(c.conf.CaptureLatencyMs * float64(time.Millisecond))),
+		FrameSequence: c.frameSequence.Add(1),
+	}
+	// the exposure time is only known when it is set manually
+	if exposure, ok := c.controls[ControlExposureTime]; ok {
+		md.Exposure = time.Duration(exposure) * 100 * time.Microsecond
+	}
+	return md
 }
 
 func (c *monitoredWebcam) Stream(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error) {
@@ -630,6 +688,9 @@ func (c *monitoredWebcam) driverInfo() (driver.Info, error) {
 	if c.underlyingSource == nil {
 		return driver.Info{}, errors.New("no underlying source found in camera")
 	}
+	if _, ok := bayerFormats[c.conf.Format]; ok {
+		return driver.Info{Label: c.targetPath, DeviceType: driver.Camera}, nil
+	}
 	d, err := gostream.DriverFromMediaSource[image.Image, prop.Video](c.underlyingSource)
 	if err != nil {
 		return driver.Info{}, errors.Wrap(err, "cannot get driver from media source")
@@ -0,0 +1,121 @@
+package videosource
+
+import (
+	"context"
+	"encoding/binary"
+	"image"
+
+	"github.com/pion/mediadevices/pkg/frame"
+	"github.com/pion/mediadevices/pkg/prop"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/gostream"
+	"go.viam.com/rdk/rimage"
+)
+
+// bayerFormat is the layout of the raw frames of a V4L2 Bayer format.
+type bayerFormat struct {
+	pattern rimage.BayerPattern
+	// bitDepth is 8 for a byte per pixel, or 16 for 2 little endian bytes per pixel.
+	bitDepth int
+}
+
+// bayerFormats are the V4L2 Bayer formats webcams stream raw frames in, by their FourCC. The webcam library does not
+// support them, so webcams configured with one are streamed from V4L2 directly.
+var bayerFormats = map[string]bayerFormat{
+	"BA81": {rimage.BayerBGGR, 8},
+	"GBRG": {rimage.BayerGBRG, 8},
+	"GRBG": {rimage.BayerGRBG, 8},
+	"RGGB": {rimage.BayerRGGB, 8},
+	"BYR2": {rimage.BayerBGGR, 16},
+	"GB16": {rimage.BayerGBRG, 16},
+	"GR16": {rimage.BayerGRBG, 16},
+	"RG16": {rimage.BayerRGGB, 16},
+}
+
+// The resolution Bayer frames are requested at when the config has none, as it is for other formats.
+const (
+	defaultBayerWidth  = 640
+	defaultBayerHeight = 480
+)
+
+// decodeBayerFrame reads a raw frame of a Bayer format into a Bayer image. Rows may be padded beyond the width.
+func decodeBayerFrame(raw []byte, width, height int, format bayerFormat) (*rimage.BayerImage, error) {
+	bytesPerPixel := format.bitDepth / 8
+	if height <= 0 || len(raw) < width*height*bytesPerPixel {
+		return nil, errors.Errorf("bayer frame of %d bytes is too short for %dx%d pixels", len(raw), width, height)
+	}
+	img, err := rimage.NewBayerImage(width, height, format.pattern, format.bitDepth)
+	if err != nil {
+		return nil, err
+	}
+	stride := len(raw) / height
+	for y := 0; y < height; y++ {
+		row := raw[y*stride:]
+		for x := 0; x < width; x++ {
+			if bytesPerPixel == 1 {
+				img.SetRaw(x, y, uint16(row[x]))
+			} else {
+				img.SetRaw(x, y, binary.LittleEndian.Uint16(row[2*x:]))
+			}
+		}
+	}
+	return img, nil
+}
+
+// bayerDevice is a V4L2 device streaming raw Bayer frames.
+type bayerDevice interface {
+	// readFrame waits for the next frame and returns a copy of it.
+	readFrame(ctx context.Context) ([]byte, error)
+	close() error
+}
+
+// openBayerDevice opens the V4L2 device at a path and starts streaming in the format with a FourCC, returning the
+// resolution the device settled on, which is the closest it has to the one requested.
+var openBayerDevice = openV4L2BayerDevice
+
+// bayerReader reads raw Bayer images from a V4L2 device, which a debayer transform can turn into color images.
+type bayerReader struct {
+	dev           bayerDevice
+	format        bayerFormat
+	width, height int
+}
+
+// newBayerSource opens a webcam streaming a Bayer format.
+func newBayerSource(conf *WebcamConfig, format bayerFormat) (gostream.VideoSource, error) {
+	width, height := conf.Width, conf.Height
+	if width == 0 || height == 0 {
+		width, height = defaultBayerWidth, defaultBayerHeight
+	}
+	dev, width, height, err := openBayerDevice(v4l2DevicePath(conf.Path), conf.Format, width, height)
+	if err != nil {
+		return nil, err
+	}
+	if (conf.Width != 0 && conf.Width != width) || (conf.Height != 0 && conf.Height != height) {
+		return nil, multierr.Combine(
+			errors.Errorf("requested width and height (%dx%d) are not available for this webcam"+
+				" (closest resolution it supports in %s is %dx%d)", conf.Width, conf.Height, conf.Format, width, height),
+			dev.close())
+	}
+	reader := &bayerReader{dev: dev, format: format, width: width, height: height}
+	return gostream.NewVideoSource(reader, prop.Video{Width: width, Height: height, FrameFormat: frame.Format(conf.Format)}), nil
+}
+
+// Read returns the next frame as a raw Bayer image.
+func (r *bayerReader) Read(ctx context.Context) (image.Image, func(), error) {
+	raw, err := r.dev.readFrame(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	img, err := decodeBayerFrame(raw, r.width, r.height, r.format)
+	if err != nil {
+		return nil, nil, err
+	}
+	return img, func() {}, nil
+}
+
+// Close stops streaming and closes the device.
+func (r *bayerReader) Close(ctx context.Context) error {
+	return r.dev.close()
+}
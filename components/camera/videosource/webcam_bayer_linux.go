@@ -0,0 +1,69 @@
+package videosource
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/blackjack/webcam"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+// bayerFrameTimeoutMs is how long to wait for a frame before checking whether the read was cancelled.
+const bayerFrameTimeoutMs = 100
+
+// v4l2BayerDevice streams raw Bayer frames from a V4L2 device.
+type v4l2BayerDevice struct {
+	cam *webcam.Webcam
+}
+
+func openV4L2BayerDevice(path, fourCC string, width, height int) (bayerDevice, int, int, error) {
+	cam, err := webcam.Open(path)
+	if err != nil {
+		return nil, 0, 0, errors.Wrap(err, "cannot open webcam")
+	}
+	pixelFormat := webcam.PixelFormat(binary.LittleEndian.Uint32([]byte(fourCC)))
+	if _, ok := cam.GetSupportedFormats()[pixelFormat]; !ok {
+		return nil, 0, 0, multierr.Combine(errors.Errorf("webcam does not support the %s format", fourCC), cam.Close())
+	}
+	_, w, h, err := cam.SetImageFormat(pixelFormat, uint32(width), uint32(height))
+	if err != nil {
+		return nil, 0, 0, multierr.Combine(errors.Wrapf(err, "cannot set webcam format to %s", fourCC), cam.Close())
+	}
+	if err := cam.StartStreaming(); err != nil {
+		return nil, 0, 0, multierr.Combine(errors.Wrap(err, "cannot start streaming from webcam"), cam.Close())
+	}
+	return &v4l2BayerDevice{cam: cam}, int(w), int(h), nil
+}
+
+func (d *v4l2BayerDevice) readFrame(ctx context.Context) ([]byte, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		err := d.cam.WaitForFrame(bayerFrameTimeoutMs)
+		var timeout *webcam.Timeout
+		if errors.As(err, &timeout) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		raw, index, err := d.cam.GetFrame()
+		if err != nil {
+			return nil, err
+		}
+		// the buffer is reused by the device once it is released
+		frame := append([]byte{}, raw...)
+		if err := d.cam.ReleaseFrame(index); err != nil {
+			return nil, err
+		}
+		if len(frame) > 0 {
+			return frame, nil
+		}
+	}
+}
+
+func (d *v4l2BayerDevice) close() error {
+	return multierr.Combine(d.cam.StopStreaming(), d.cam.Close())
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package videosource
+
+import "github.com/pkg/errors"
+
+func openV4L2BayerDevice(path, fourCC string, width, height int) (bayerDevice, int, int, error) {
+	return nil, 0, 0, errors.New("bayer webcam formats are only supported on linux")
+}
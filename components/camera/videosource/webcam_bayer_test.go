@@ -0,0 +1,111 @@
+package videosource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/gostream"
+	"go.viam.com/rdk/rimage"
+)
+
+type fakeBayerDevice struct {
+	frame  []byte
+	closed bool
+}
+
+func (dev *fakeBayerDevice) readFrame(ctx context.Context) ([]byte, error) {
+	return dev.frame, nil
+}
+
+func (dev *fakeBayerDevice) close() error {
+	dev.closed = true
+	return nil
+}
+
+// grbgFrame is a 4x2 GRBG frame of a flat color with red 200, green 100 and blue 50, whose rows are padded to 6 bytes.
+var grbgFrame = []byte{
+	100, 200, 100, 200, 0, 0,
+	50, 100, 50, 100, 0, 0,
+}
+
+func TestDecodeBayerFrame(t *testing.T) {
+	img, err := decodeBayerFrame(grbgFrame, 4, 2, bayerFormats["GRBG"])
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, img.Pattern, test.ShouldEqual, rimage.BayerGRBG)
+	test.That(t, img.BitDepth, test.ShouldEqual, 8)
+	test.That(t, img.Bounds().Dx(), test.ShouldEqual, 4)
+	test.That(t, img.Bounds().Dy(), test.ShouldEqual, 2)
+	test.That(t, img.Raw(0, 0), test.ShouldEqual, 100)
+	test.That(t, img.Raw(1, 0), test.ShouldEqual, 200)
+	test.That(t, img.Raw(0, 1), test.ShouldEqual, 50)
+
+	// debayering, as the debayer transform does, recovers the flat color
+	color := img.Debayer(rimage.WhiteBalance{Red: 1, Green: 1, Blue: 1})
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			r, g, b := color.GetXY(x, y).RGB255()
+			test.That(t, []uint8{r, g, b}, test.ShouldResemble, []uint8{200, 100, 50})
+		}
+	}
+
+	// 16 bit frames are little endian
+	img, err = decodeBayerFrame([]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}, 2, 2, bayerFormats["RG16"])
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, img.Pattern, test.ShouldEqual, rimage.BayerRGGB)
+	test.That(t, img.BitDepth, test.ShouldEqual, 16)
+	test.That(t, img.Raw(0, 0), test.ShouldEqual, 0x0201)
+	test.That(t, img.Raw(1, 1), test.ShouldEqual, 0x0807)
+
+	_, err = decodeBayerFrame(grbgFrame[:7], 4, 2, bayerFormats["GRBG"])
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestBayerSource(t *testing.T) {
+	ctx := context.Background()
+	dev := &fakeBayerDevice{frame: grbgFrame}
+	var opened string
+	prevOpen := openBayerDevice
+	openBayerDevice = func(path, fourCC string, width, height int) (bayerDevice, int, int, error) {
+		opened = path
+		if fourCC != "GRBG" {
+			return nil, 0, 0, errors.New("unsupported format")
+		}
+		return dev, 4, 2, nil
+	}
+	defer func() { openBayerDevice = prevOpen }()
+
+	t.Run("frames are read as raw bayer images", func(t *testing.T) {
+		conf := &WebcamConfig{Path: "/dev/video0", Format: "GRBG"}
+		src, err := newBayerSource(conf, bayerFormats[conf.Format])
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, opened, test.ShouldEqual, "/dev/video0")
+
+		img, release, err := gostream.ReadImage(ctx, src)
+		test.That(t, err, test.ShouldBeNil)
+		defer release()
+		bayer, ok := img.(*rimage.BayerImage)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, bayer.Pattern, test.ShouldEqual, rimage.BayerGRBG)
+		test.That(t, bayer.Raw(1, 0), test.ShouldEqual, 200)
+
+		test.That(t, src.Close(ctx), test.ShouldBeNil)
+		test.That(t, dev.closed, test.ShouldBeTrue)
+	})
+
+	t.Run("unavailable resolutions close the device", func(t *testing.T) {
+		dev.closed = false
+		_, err := newBayerSource(&WebcamConfig{Path: "/dev/video0", Format: "GRBG", Width: 640, Height: 480}, bayerFormats["GRBG"])
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "closest resolution it supports in GRBG is 4x2")
+		test.That(t, dev.closed, test.ShouldBeTrue)
+	})
+
+	t.Run("bayer formats need a path", func(t *testing.T) {
+		_, err := WebcamConfig{Format: "BA81"}.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "need a video_path")
+	})
+}
@@ -0,0 +1,276 @@
+package videosource
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/resource"
+)
+
+// The DoCommands of a webcam.
+const (
+	// GetControlsCommand returns the V4L2 controls of a webcam with their current values, as a GetControlsResp.
+	GetControlsCommand = "get_controls"
+	// SetControlsCommand sets V4L2 controls of a webcam, with a SetControlsReq as its payload and a GetControlsResp as
+	// its response. The values are kept until the webcam is reconfigured, and set again if it reconnects.
+	SetControlsCommand = "set_controls"
+)
+
+// The names of the V4L2 controls most webcams have. The names of other controls are those their driver gives them,
+// lowercased with their spaces and punctuation replaced by underscores, as v4l2-ctl lists them.
+const (
+	// ControlAutoExposure is 1 for manual exposure and 3 for automatic exposure.
+	ControlAutoExposure = "auto_exposure"
+	// ControlExposureTime is the manual exposure time in units of 100µs.
+	ControlExposureTime = "exposure_time_absolute"
+	ControlGain         = "gain"
+	// ControlAutoWhiteBalance is 1 for automatic white balance and 0 for manual.
+	ControlAutoWhiteBalance = "white_balance_automatic"
+	// ControlWhiteBalanceTemperature is the manual white balance in kelvin.
+	ControlWhiteBalanceTemperature = "white_balance_temperature"
+	// ControlAutoFocus is 1 for continuous automatic focus and 0 for manual.
+	ControlAutoFocus = "focus_automatic_continuous"
+	ControlFocus     = "focus_absolute"
+	// ControlPowerLineFrequency is the frequency of the mains the flicker of lights is filtered at: 0 for none,
+	// 1 for 50Hz, 2 for 60Hz and 3 for automatic.
+	ControlPowerLineFrequency = "power_line_frequency"
+)
+
+// controlNames names controls by their V4L2 ID, since the names drivers give them vary between kernel versions.
+var controlNames = map[uint32]string{
+	0x00980900: "brightness",
+	0x00980901: "contrast",
+	0x00980902: "saturation",
+	0x00980903: "hue",
+	0x0098090c: ControlAutoWhiteBalance,
+	0x00980912: "gain_automatic",
+	0x00980913: ControlGain,
+	0x00980918: ControlPowerLineFrequency,
+	0x0098091a: ControlWhiteBalanceTemperature,
+	0x0098091b: "sharpness",
+	0x0098091c: "backlight_compensation",
+	0x009a0901: ControlAutoExposure,
+	0x009a0902: ControlExposureTime,
+	0x009a090a: ControlFocus,
+	0x009a090c: ControlAutoFocus,
+}
+
+// manualModes are the values which switch automatic controls to manual, by the manual control they give way to.
+// Drivers refuse to set a manual control while its automatic mode is on.
+var manualModes = map[string]struct {
+	auto  string
+	value int32
+}{
+	ControlExposureTime:            {ControlAutoExposure, 1},
+	ControlWhiteBalanceTemperature: {ControlAutoWhiteBalance, 0},
+	ControlFocus:                   {ControlAutoFocus, 0},
+	ControlGain:                    {"gain_automatic", 0},
+}
+
+// WebcamControl is a V4L2 control of a webcam.
+type WebcamControl struct {
+	Name string `json:"name"`
+	ID   uint32 `json:"id"`
+	// Type is int, bool or menu, whose values are the indexes of its items.
+	Type  string `json:"type"`
+	Min   int32  `json:"min"`
+	Max   int32  `json:"max"`
+	Step  int32  `json:"step"`
+	Value int32  `json:"value"`
+}
+
+// GetControlsReq is the payload of a GetControlsCommand.
+type GetControlsReq struct{}
+
+// GetControlsResp lists the controls of a webcam, by ID.
+type GetControlsResp struct {
+	Controls []WebcamControl `json:"controls"`
+}
+
+// SetControlsReq is the payload of a SetControlsCommand.
+type SetControlsReq struct {
+	// Controls are the values to set by control name. Setting a manual control, such as the exposure time, switches
+	// off its automatic mode unless that is set too.
+	Controls map[string]int32 `json:"controls"`
+}
+
+// Validate ensures some controls are set.
+func (req *SetControlsReq) Validate() error {
+	if len(req.Controls) == 0 {
+		return errors.New("no controls to set")
+	}
+	return nil
+}
+
+// controlDevice is a V4L2 device whose controls can be read and set.
+type controlDevice interface {
+	controls() ([]WebcamControl, error)
+	setControl(id uint32, value int32) error
+	close() error
+}
+
+// openControlDevice opens the V4L2 device at a path to access its controls.
+var openControlDevice = openV4L2Device
+
+// controlName returns the name of a control, from its ID if it is well known or else from the name its driver gives it.
+func controlName(id uint32, driverName string) string {
+	if name, ok := controlNames[id]; ok {
+		return name
+	}
+	var sb strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(driverName) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+			underscore = false
+		} else if !underscore && sb.Len() > 0 {
+			sb.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "_")
+}
+
+// v4l2DevicePath returns the path of the device of a webcam from its configured path or label. The labels of
+// webcams end with the name of their device, after a semicolon.
+func v4l2DevicePath(path string) string {
+	if i := strings.LastIndex(path, ";"); i >= 0 {
+		path = path[i+1:]
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join("/dev", path)
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// readControls returns the controls of the device at a path.
+func readControls(path string) ([]WebcamControl, error) {
+	dev, err := openControlDevice(v4l2DevicePath(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot open webcam to access its controls")
+	}
+	defer goutils.UncheckedErrorFunc(dev.close)
+	return dev.controls()
+}
+
+// writeControls sets controls of the device at a path by name. The automatic modes of manual controls which are set
+// are switched off first, unless they are set too.
+func writeControls(path string, values map[string]int32) error {
+	if len(values) == 0 {
+		return nil
+	}
+	dev, err := openControlDevice(v4l2DevicePath(path))
+	if err != nil {
+		return errors.Wrap(err, "cannot open webcam to set its controls")
+	}
+	defer goutils.UncheckedErrorFunc(dev.close)
+	supported, err := dev.controls()
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]WebcamControl, len(supported))
+	for _, ctrl := range supported {
+		byName[ctrl.Name] = ctrl
+	}
+
+	toSet := make(map[string]int32, len(values))
+	for name, value := range values {
+		toSet[name] = value
+		mode, ok := manualModes[name]
+		if _, isSet := values[mode.auto]; !ok || isSet {
+			continue
+		}
+		if _, supportsAuto := byName[mode.auto]; supportsAuto {
+			toSet[mode.auto] = mode.value
+		}
+	}
+	names := make([]string, 0, len(toSet))
+	for name := range toSet {
+		names = append(names, name)
+	}
+	// automatic modes go first so that the manual controls are writable by the time they are set
+	sort.Slice(names, func(i, j int) bool {
+		iAuto, jAuto := isAutoControl(names[i]), isAutoControl(names[j])
+		if iAuto != jAuto {
+			return iAuto
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		ctrl, ok := byName[name]
+		if !ok {
+			available := make([]string, 0, len(supported))
+			for _, c := range supported {
+				available = append(available, c.Name)
+			}
+			return errors.Errorf("webcam has no control %q, it has %q", name, available)
+		}
+		value := toSet[name]
+		if value < ctrl.Min || value > ctrl.Max {
+			return errors.Errorf("control %q must be between %d and %d, got %d", name, ctrl.Min, ctrl.Max, value)
+		}
+		if err := dev.setControl(ctrl.ID, value); err != nil {
+			return errors.Wrapf(err, "cannot set control %q to %d", name, value)
+		}
+	}
+	return nil
+}
+
+func isAutoControl(name string) bool {
+	for _, mode := range manualModes {
+		if mode.auto == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *monitoredWebcam) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return resource.CommandHandlers{
+		GetControlsCommand: resource.TypedCommandHandler(c.getControls),
+		SetControlsCommand: resource.TypedCommandHandler(c.setControls),
+	}.DoCommand(ctx, cmd)
+}
+
+func (c *monitoredWebcam) getControls(ctx context.Context, req GetControlsReq) (GetControlsResp, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if err := c.ensureActive(); err != nil {
+		return GetControlsResp{}, err
+	}
+	controls, err := readControls(c.targetPath)
+	if err != nil {
+		return GetControlsResp{}, err
+	}
+	return GetControlsResp{Controls: controls}, nil
+}
+
+func (c *monitoredWebcam) setControls(ctx context.Context, req SetControlsReq) (GetControlsResp, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.ensureActive(); err != nil {
+		return GetControlsResp{}, err
+	}
+	if err := writeControls(c.targetPath, req.Controls); err != nil {
+		return GetControlsResp{}, err
+	}
+	if c.controls == nil {
+		c.controls = map[string]int32{}
+	}
+	for name, value := range req.Controls {
+		c.controls[name] = value
+	}
+	controls, err := readControls(c.targetPath)
+	if err != nil {
+		return GetControlsResp{}, err
+	}
+	return GetControlsResp{Controls: controls}, nil
+}
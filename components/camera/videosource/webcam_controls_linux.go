@@ -0,0 +1,53 @@
+package videosource
+
+import (
+	"sort"
+
+	"github.com/blackjack/webcam"
+)
+
+// controlTypes names the types of controls the webcam package supports.
+var controlTypes = map[int32]string{0: "int", 1: "bool", 2: "menu"}
+
+// v4l2Device accesses the controls of a V4L2 device, which it can do while another process streams from it.
+type v4l2Device struct {
+	cam *webcam.Webcam
+}
+
+func openV4L2Device(path string) (controlDevice, error) {
+	cam, err := webcam.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &v4l2Device{cam: cam}, nil
+}
+
+func (d *v4l2Device) controls() ([]WebcamControl, error) {
+	var controls []WebcamControl
+	for id, ctrl := range d.cam.GetControls() {
+		value, err := d.cam.GetControl(id)
+		if err != nil {
+			// write only and inactive controls cannot be read
+			continue
+		}
+		controls = append(controls, WebcamControl{
+			Name:  controlName(uint32(id), ctrl.Name),
+			ID:    uint32(id),
+			Type:  controlTypes[ctrl.Type],
+			Min:   ctrl.Min,
+			Max:   ctrl.Max,
+			Step:  ctrl.Step,
+			Value: value,
+		})
+	}
+	sort.Slice(controls, func(i, j int) bool { return controls[i].ID < controls[j].ID })
+	return controls, nil
+}
+
+func (d *v4l2Device) setControl(id uint32, value int32) error {
+	return d.cam.SetControl(webcam.ControlID(id), value)
+}
+
+func (d *v4l2Device) close() error {
+	return d.cam.Close()
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package videosource
+
+import "github.com/pkg/errors"
+
+func openV4L2Device(path string) (controlDevice, error) {
+	return nil, errors.New("webcam controls are only supported on linux")
+}
@@ -0,0 +1,113 @@
+package videosource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+)
+
+type fakeControlDevice struct {
+	ctrls []WebcamControl
+	set   []string
+}
+
+func (dev *fakeControlDevice) controls() ([]WebcamControl, error) {
+	return dev.ctrls, nil
+}
+
+func (dev *fakeControlDevice) setControl(id uint32, value int32) error {
+	for i, ctrl := range dev.ctrls {
+		if ctrl.ID == id {
+			dev.ctrls[i].Value = value
+			dev.set = append(dev.set, ctrl.Name)
+			return nil
+		}
+	}
+	return errors.New("no such control")
+}
+
+func (dev *fakeControlDevice) close() error {
+	return nil
+}
+
+func TestWriteControls(t *testing.T) {
+	dev := &fakeControlDevice{ctrls: []WebcamControl{
+		{Name: ControlAutoExposure, ID: 0x009a0901, Type: "menu", Min: 0, Max: 3, Value: 3},
+		{Name: ControlExposureTime, ID: 0x009a0902, Type: "int", Min: 1, Max: 5000, Value: 150},
+		{Name: ControlGain, ID: 0x00980913, Type: "int", Min: 0, Max: 100, Value: 0},
+	}}
+	var opened string
+	prevOpen := openControlDevice
+	openControlDevice = func(path string) (controlDevice, error) {
+		opened = path
+		return dev, nil
+	}
+	defer func() { openControlDevice = prevOpen }()
+
+	t.Run("manual control switches off auto mode first", func(t *testing.T) {
+		dev.set = nil
+		err := writeControls("Integrated Camera;video0", map[string]int32{ControlExposureTime: 100, ControlGain: 10})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, opened, test.ShouldEqual, "/dev/video0")
+		test.That(t, dev.set, test.ShouldResemble, []string{ControlAutoExposure, ControlExposureTime, ControlGain})
+		test.That(t, dev.ctrls[0].Value, test.ShouldEqual, 1)
+		test.That(t, dev.ctrls[1].Value, test.ShouldEqual, 100)
+	})
+
+	t.Run("auto mode which is set is kept", func(t *testing.T) {
+		dev.set = nil
+		err := writeControls("/dev/video0", map[string]int32{ControlExposureTime: 200, ControlAutoExposure: 3})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, dev.set, test.ShouldResemble, []string{ControlAutoExposure, ControlExposureTime})
+		test.That(t, dev.ctrls[0].Value, test.ShouldEqual, 3)
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		err := writeControls("/dev/video0", map[string]int32{ControlGain: 101})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "between 0 and 100")
+	})
+
+	t.Run("unknown control", func(t *testing.T) {
+		err := writeControls("/dev/video0", map[string]int32{ControlFocus: 10})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "no control \"focus_absolute\"")
+		test.That(t, err.Error(), test.ShouldContainSubstring, ControlGain)
+	})
+
+	t.Run("nothing to set", func(t *testing.T) {
+		opened = ""
+		test.That(t, writeControls("/dev/video0", nil), test.ShouldBeNil)
+		test.That(t, opened, test.ShouldBeEmpty)
+	})
+}
+
+func TestControlName(t *testing.T) {
+	test.That(t, controlName(0x009a0902, "Exposure (Absolute)"), test.ShouldEqual, ControlExposureTime)
+	test.That(t, controlName(0x00980923, "Zoom, Absolute"), test.ShouldEqual, "zoom_absolute")
+	test.That(t, controlName(0x00980924, "LED1 Mode "), test.ShouldEqual, "led1_mode")
+}
+
+func TestSetControlsReq(t *testing.T) {
+	test.That(t, (&SetControlsReq{}).Validate(), test.ShouldNotBeNil)
+	test.That(t, (&SetControlsReq{Controls: map[string]int32{ControlGain: 1}}).Validate(), test.ShouldBeNil)
+}
+
+func TestFrameMetadata(t *testing.T) {
+	c := &monitoredWebcam{conf: WebcamConfig{CaptureLatencyMs: 30}, controls: map[string]int32{}}
+	readAt := time.Now()
+	md := c.frameMetadata(readAt)
+	test.That(t, md.CapturedAt, test.ShouldEqual, readAt.Add(-30*time.Millisecond))
+	test.That(t, md.FrameSequence, test.ShouldEqual, 1)
+	test.That(t, md.Exposure, test.ShouldEqual, 0)
+
+	c.controls[ControlExposureTime] = 156
+	md = c.frameMetadata(readAt)
+	test.That(t, md.FrameSequence, test.ShouldEqual, 2)
+	test.That(t, md.Exposure, test.ShouldEqual, 15600*time.Microsecond)
+
+	_, err := WebcamConfig{CaptureLatencyMs: -1}.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,347 @@
+// Package boardcontact implements contact sensors read through the pins of a board. The gpio model reads switches,
+// such as those of a bumper, from GPIO pins, and the analog model reads capacitive or resistive touch sensors from
+// analog readers, touched past a threshold.
+package boardcontact
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/contactsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
+)
+
+var (
+	// GPIOModel is the model of contact sensors read from GPIO pins.
+	GPIOModel = resource.DefaultModelFamily.WithModel("gpio")
+	// AnalogModel is the model of contact sensors read from analog readers.
+	AnalogModel = resource.DefaultModelFamily.WithModel("analog")
+)
+
+const (
+	defaultPollFrequencyHz = 100.
+	defaultDebounceMS      = 20
+	eventBufferSize        = 16
+)
+
+func init() {
+	resource.RegisterComponent(contactsensor.API, GPIOModel, resource.Registration[contactsensor.ContactSensor, *Config]{
+		Constructor: newGPIOContactSensor,
+	})
+	resource.RegisterComponent(contactsensor.API, AnalogModel, resource.Registration[contactsensor.ContactSensor, *Config]{
+		Constructor: newAnalogContactSensor,
+	})
+}
+
+// Location is where a contact is, in millimeters in the frame of the sensor.
+type Location struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// ContactConfig describes a contact.
+type ContactConfig struct {
+	Name string `json:"name"`
+	// Pin is the GPIO pin of a contact of the gpio model. Its contact is touched when the pin is high, or low when
+	// ActiveLow is set, as for a switch to ground with a pull-up.
+	Pin       string `json:"pin,omitempty"`
+	ActiveLow bool   `json:"active_low,omitempty"`
+	// Analog is the analog reader of a contact of the analog model. Its contact is touched when the reading is above
+	// Threshold, or below it when TouchedBelow is set, and released once the reading is back past the threshold by
+	// Hysteresis.
+	Analog       string `json:"analog,omitempty"`
+	Threshold    int    `json:"threshold,omitempty"`
+	TouchedBelow bool   `json:"touched_below,omitempty"`
+	Hysteresis   int    `json:"hysteresis,omitempty"`
+	// Location is where the contact is, which a guarded base uses to let the robot back away from what it touched.
+	Location *Location `json:"location,omitempty"`
+}
+
+// Config describes how to configure the sensor.
+type Config struct {
+	Board    string          `json:"board"`
+	Contacts []ContactConfig `json:"contacts"`
+	// PollFrequencyHz is how often the contacts are read, and defaults to 100.
+	PollFrequencyHz float64 `json:"poll_frequency_hz,omitempty"`
+	// DebounceMS is how long a contact must stay in a new state before it changes to it, and defaults to 20.
+	DebounceMS int `json:"debounce_ms,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.Board == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "board")
+	}
+	if len(conf.Contacts) == 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "contacts")
+	}
+	names := map[string]bool{}
+	for _, contact := range conf.Contacts {
+		if contact.Name == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "contacts.name")
+		}
+		if names[contact.Name] {
+			return nil, resource.NewConfigValidationError(path, errors.Errorf("contact name %q is used twice", contact.Name))
+		}
+		names[contact.Name] = true
+		if (contact.Pin == "") == (contact.Analog == "") {
+			return nil, resource.NewConfigValidationError(path,
+				errors.Errorf("contact %q needs either a pin or an analog reader", contact.Name))
+		}
+		if contact.Hysteresis < 0 {
+			return nil, resource.NewConfigValidationError(path, errors.New("hysteresis cannot be negative"))
+		}
+	}
+	if conf.PollFrequencyHz < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("poll_frequency_hz cannot be negative"))
+	}
+	if conf.DebounceMS < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("debounce_ms cannot be negative"))
+	}
+	return []string{conf.Board}, nil
+}
+
+// readContactFunc reads whether a contact is touched, given whether it is now.
+type readContactFunc func(ctx context.Context, inContact bool) (bool, error)
+
+func newGPIOContactSensor(
+	ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
+) (contactsensor.ContactSensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	b, err := board.FromDependencies(deps, newConf.Board)
+	if err != nil {
+		return nil, err
+	}
+	reads := make([]readContactFunc, 0, len(newConf.Contacts))
+	for _, contact := range newConf.Contacts {
+		if contact.Pin == "" {
+			return nil, errors.Errorf("contact %q of a gpio contact sensor needs a pin", contact.Name)
+		}
+		pin, err := b.GPIOPinByName(contact.Pin)
+		if err != nil {
+			return nil, err
+		}
+		activeLow := contact.ActiveLow
+		reads = append(reads, func(ctx context.Context, inContact bool) (bool, error) {
+			high, err := pin.Get(ctx, nil)
+			return high != activeLow, err
+		})
+	}
+	return newContactSensor(conf.ResourceName(), newConf, reads, logger), nil
+}
+
+func newAnalogContactSensor(
+	ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
+) (contactsensor.ContactSensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	b, err := board.FromDependencies(deps, newConf.Board)
+	if err != nil {
+		return nil, err
+	}
+	reads := make([]readContactFunc, 0, len(newConf.Contacts))
+	for _, contact := range newConf.Contacts {
+		if contact.Analog == "" {
+			return nil, errors.Errorf("contact %q of an analog contact sensor needs an analog reader", contact.Name)
+		}
+		analog, err := b.AnalogByName(contact.Analog)
+		if err != nil {
+			return nil, err
+		}
+		contact := contact
+		reads = append(reads, func(ctx context.Context, inContact bool) (bool, error) {
+			value, err := analog.Read(ctx, nil)
+			if err != nil {
+				return false, err
+			}
+			// a touched contact is released only once it is back past the threshold by the hysteresis
+			threshold := contact.Threshold
+			if inContact && contact.TouchedBelow {
+				threshold += contact.Hysteresis
+			} else if inContact {
+				threshold -= contact.Hysteresis
+			}
+			if contact.TouchedBelow {
+				return value.Value < threshold, nil
+			}
+			return value.Value > threshold, nil
+		})
+	}
+	return newContactSensor(conf.ResourceName(), newConf, reads, logger), nil
+}
+
+type contactSensor struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger   logging.Logger
+	workers  utils.StoppableWorkers
+	reads    []readContactFunc
+	debounce time.Duration
+	closed   chan struct{}
+
+	mu       sync.Mutex
+	contacts []contactsensor.Contact
+	// changing is when each contact was first read in the other state, and is zero while it reads as it is
+	changing    []time.Time
+	readErr     error
+	subscribers map[chan contactsensor.Contact]struct{}
+}
+
+func newContactSensor(
+	name resource.Name, conf *Config, reads []readContactFunc, logger logging.Logger,
+) *contactSensor {
+	s := &contactSensor{
+		Named:       name.AsNamed(),
+		logger:      logger,
+		reads:       reads,
+		debounce:    time.Duration(conf.DebounceMS) * time.Millisecond,
+		closed:      make(chan struct{}),
+		contacts:    make([]contactsensor.Contact, 0, len(conf.Contacts)),
+		changing:    make([]time.Time, len(conf.Contacts)),
+		subscribers: map[chan contactsensor.Contact]struct{}{},
+	}
+	if conf.DebounceMS == 0 {
+		s.debounce = defaultDebounceMS * time.Millisecond
+	}
+	for _, contact := range conf.Contacts {
+		state := contactsensor.Contact{Name: contact.Name}
+		if contact.Location != nil {
+			state.Location = r3.Vector{X: contact.Location.X, Y: contact.Location.Y, Z: contact.Location.Z}
+		}
+		s.contacts = append(s.contacts, state)
+	}
+	pollFrequencyHz := conf.PollFrequencyHz
+	if pollFrequencyHz == 0 {
+		pollFrequencyHz = defaultPollFrequencyHz
+	}
+	period := time.Duration(float64(time.Second) / pollFrequencyHz)
+	s.workers = utils.NewStoppableWorkers(func(ctx context.Context) {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			s.poll(ctx)
+		}
+	})
+	return s
+}
+
+// poll reads each contact, changing its state once it has read in the new state for the debounce time.
+func (s *contactSensor) poll(ctx context.Context) {
+	s.mu.Lock()
+	inContact := make([]bool, len(s.contacts))
+	for i, contact := range s.contacts {
+		inContact[i] = contact.InContact
+	}
+	s.mu.Unlock()
+
+	touched := make([]bool, len(s.reads))
+	var readErr error
+	for i, read := range s.reads {
+		var err error
+		if touched[i], err = read(ctx, inContact[i]); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			readErr = errors.Wrapf(err, "cannot read contact %q", s.contacts[i].Name)
+			touched[i] = inContact[i]
+		}
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if readErr != nil && s.readErr == nil {
+		s.logger.CWarnw(ctx, "cannot read the contacts", "error", readErr)
+	}
+	s.readErr = readErr
+	for i := range s.contacts {
+		if touched[i] == s.contacts[i].InContact {
+			s.changing[i] = time.Time{}
+			continue
+		}
+		if s.changing[i].IsZero() {
+			s.changing[i] = now
+		}
+		if now.Sub(s.changing[i]) < s.debounce {
+			continue
+		}
+		s.changing[i] = time.Time{}
+		s.contacts[i].InContact = touched[i]
+		s.contacts[i].Since = now
+		for events := range s.subscribers {
+			select {
+			case events <- s.contacts[i]:
+			default:
+			}
+		}
+	}
+}
+
+// Contacts returns the debounced state of each contact, or an error when the contacts could not be read last poll.
+func (s *contactSensor) Contacts(ctx context.Context, extra map[string]interface{}) ([]contactsensor.Contact, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.readErr != nil {
+		return nil, s.readErr
+	}
+	return append([]contactsensor.Contact{}, s.contacts...), nil
+}
+
+// Events returns a channel of the contacts as they change state.
+func (s *contactSensor) Events(ctx context.Context, extra map[string]interface{}) (<-chan contactsensor.Contact, error) {
+	events := make(chan contactsensor.Contact, eventBufferSize)
+	s.mu.Lock()
+	s.subscribers[events] = struct{}{}
+	s.mu.Unlock()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-s.closed:
+		}
+		s.mu.Lock()
+		delete(s.subscribers, events)
+		close(events)
+		s.mu.Unlock()
+	}()
+	return events, nil
+}
+
+// DoCommand returns the contacts for "contacts".
+func (s *contactSensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if cmd["command"] != "contacts" {
+		return nil, resource.ErrDoUnimplemented
+	}
+	contacts, err := s.Contacts(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]interface{}, 0, len(contacts))
+	for _, contact := range contacts {
+		resp = append(resp, map[string]interface{}{"name": contact.Name, "in_contact": contact.InContact})
+	}
+	return map[string]interface{}{"contacts": resp}, nil
+}
+
+func (s *contactSensor) Close(ctx context.Context) error {
+	s.workers.Stop()
+	close(s.closed)
+	return nil
+}
@@ -0,0 +1,195 @@
+package boardcontact
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestValidate(t *testing.T) {
+	deps, err := (&Config{Board: "pi", Contacts: []ContactConfig{{Name: "front", Pin: "11"}}}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"pi"})
+
+	_, err = (&Config{Contacts: []ContactConfig{{Name: "front", Pin: "11"}}}).Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "board"))
+	_, err = (&Config{Board: "pi"}).Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "contacts"))
+	_, err = (&Config{Board: "pi", Contacts: []ContactConfig{{Name: "front"}}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{Board: "pi", Contacts: []ContactConfig{{Name: "front", Pin: "11", Analog: "pad"}}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{Board: "pi", Contacts: []ContactConfig{{Name: "front", Pin: "11"}, {Name: "front", Pin: "12"}}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestGPIOContactSensor(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	var mu sync.Mutex
+	pins := map[string]bool{"11": false, "12": true}
+	var pinErr error
+	setPin := func(name string, high bool, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		pins[name], pinErr = high, err
+	}
+	b := inject.NewBoard("pi")
+	b.GPIOPinByNameFunc = func(name string) (board.GPIOPin, error) {
+		pin := &inject.GPIOPin{}
+		pin.GetFunc = func(ctx context.Context, extra map[string]interface{}) (bool, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return pins[name], pinErr
+		}
+		return pin, nil
+	}
+	conf := resource.Config{
+		Name:  "bumper",
+		Model: GPIOModel,
+		ConvertedAttributes: &Config{
+			Board: "pi",
+			Contacts: []ContactConfig{
+				{Name: "front", Pin: "11", Location: &Location{Y: 150}},
+				// a switch to ground with a pull-up, so pressed when low
+				{Name: "back", Pin: "12", ActiveLow: true, Location: &Location{Y: -150}},
+			},
+			PollFrequencyHz: 200,
+			DebounceMS:      10,
+		},
+	}
+	s, err := newGPIOContactSensor(ctx, resource.Dependencies{board.Named("pi"): b}, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer s.Close(ctx)
+
+	eventsCtx, cancel := context.WithCancel(ctx)
+	events, err := s.Events(eventsCtx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	contacts, err := s.Contacts(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, contacts, test.ShouldHaveLength, 2)
+	test.That(t, contacts[0].InContact, test.ShouldBeFalse)
+	test.That(t, contacts[1].InContact, test.ShouldBeFalse)
+
+	setPin("11", true, nil)
+	select {
+	case contact := <-events:
+		test.That(t, contact.Name, test.ShouldEqual, "front")
+		test.That(t, contact.InContact, test.ShouldBeTrue)
+		test.That(t, contact.Location, test.ShouldResemble, r3.Vector{Y: 150})
+		test.That(t, contact.Since.IsZero(), test.ShouldBeFalse)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event for the pressed contact")
+	}
+
+	setPin("12", false, nil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		contacts, err := s.Contacts(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, contacts[1].InContact, test.ShouldBeTrue)
+	})
+
+	// a pin which cannot be read is an error rather than a contact released
+	setPin("11", true, errors.New("board unplugged"))
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		_, err := s.Contacts(ctx, nil)
+		test.That(tb, fmt.Sprint(err), test.ShouldContainSubstring, "board unplugged")
+	})
+
+	// the events channel is closed with its context
+	cancel()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		_, open := <-events
+		test.That(tb, open, test.ShouldBeFalse)
+	})
+}
+
+func TestAnalogContactSensor(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	var mu sync.Mutex
+	reading := 100
+	setReading := func(value int) {
+		mu.Lock()
+		defer mu.Unlock()
+		reading = value
+	}
+	b := inject.NewBoard("pi")
+	b.AnalogByNameFunc = func(name string) (board.Analog, error) {
+		analog := &inject.Analog{}
+		analog.ReadFunc = func(ctx context.Context, extra map[string]interface{}) (board.AnalogValue, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return board.AnalogValue{Value: reading}, nil
+		}
+		return analog, nil
+	}
+	conf := resource.Config{
+		Name:  "pad",
+		Model: AnalogModel,
+		ConvertedAttributes: &Config{
+			Board:           "pi",
+			Contacts:        []ContactConfig{{Name: "pad", Analog: "a0", Threshold: 500, Hysteresis: 50}},
+			PollFrequencyHz: 200,
+			DebounceMS:      5,
+		},
+	}
+	s, err := newAnalogContactSensor(ctx, resource.Dependencies{board.Named("pi"): b}, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	inContact := func(tb testing.TB) bool {
+		tb.Helper()
+		contacts, err := s.Contacts(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		return contacts[0].InContact
+	}
+
+	setReading(600)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, inContact(tb), test.ShouldBeTrue)
+	})
+
+	// just below the threshold is within the hysteresis, so still touched
+	setReading(480)
+	time.Sleep(50 * time.Millisecond)
+	test.That(t, inContact(t), test.ShouldBeTrue)
+
+	setReading(400)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, inContact(tb), test.ShouldBeFalse)
+	})
+
+	resp, err := s.DoCommand(ctx, map[string]interface{}{"command": "contacts"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["contacts"], test.ShouldResemble, []interface{}{map[string]interface{}{"name": "pad", "in_contact": false}})
+
+	// events channels are closed with the sensor
+	events, err := s.Events(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, s.Close(ctx), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		_, open := <-events
+		test.That(tb, open, test.ShouldBeFalse)
+	})
+}
@@ -0,0 +1,81 @@
+// Package contactsensor defines a sensor of physical contact, such as the switches of a bumper or a capacitive or
+// resistive touch pad, which reports whether each of its contacts is touched and where on the robot it is.
+package contactsensor
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+func init() {
+	resource.RegisterAPI(API, resource.APIRegistration[ContactSensor]{})
+}
+
+// SubtypeName is a constant that identifies the component resource API string "contact_sensor".
+const SubtypeName = "contact_sensor"
+
+// API is a variable that identifies the component resource API.
+var API = resource.APINamespaceRDK.WithComponentType(SubtypeName)
+
+// Named is a helper for getting the named contact sensor's typed resource name.
+func Named(name string) resource.Name {
+	return resource.NewName(API, name)
+}
+
+// A Contact is the state of one contact of a contact sensor.
+type Contact struct {
+	Name      string `json:"name"`
+	InContact bool   `json:"in_contact"`
+	// Location is where the contact is, in millimeters in the frame of the sensor. It is zero when the sensor does not
+	// know where its contact is.
+	Location r3.Vector `json:"location"`
+	// Since is when the contact last changed state, and is zero before its first change.
+	Since time.Time `json:"since"`
+}
+
+// A ContactSensor reports whether each of its contacts is touched.
+//
+// Contacts example:
+//
+//	// Find out whether any part of the bumper is pressed.
+//	contacts, err := bumper.Contacts(context.Background(), nil)
+//
+// Events example:
+//
+//	// Stop as soon as the bumper is pressed.
+//	events, err := bumper.Events(ctx, nil)
+//	for contact := range events {
+//		if contact.InContact {
+//			err = myBase.Stop(ctx, nil)
+//		}
+//	}
+type ContactSensor interface {
+	resource.Resource
+
+	// Contacts returns the state of each contact of the sensor.
+	Contacts(ctx context.Context, extra map[string]interface{}) ([]Contact, error)
+
+	// Events returns a channel of the contacts of the sensor as they change state, which is closed when the context
+	// is done or the sensor is closed. Changes are dropped while the reader of the channel falls behind.
+	Events(ctx context.Context, extra map[string]interface{}) (<-chan Contact, error)
+}
+
+// FromDependencies is a helper for getting the named contact sensor from a collection of dependencies.
+func FromDependencies(deps resource.Dependencies, name string) (ContactSensor, error) {
+	return resource.FromDependencies[ContactSensor](deps, Named(name))
+}
+
+// FromRobot is a helper for getting the named contact sensor from the given Robot.
+func FromRobot(r robot.Robot, name string) (ContactSensor, error) {
+	return robot.ResourceFromRobot[ContactSensor](r, Named(name))
+}
+
+// NamesFromRobot is a helper for getting all contact sensor names from the given Robot.
+func NamesFromRobot(r robot.Robot) []string {
+	return robot.NamesByAPI(r, API)
+}
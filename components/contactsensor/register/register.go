@@ -0,0 +1,7 @@
+// Package register registers all relevant contact sensors
+package register
+
+import (
+	// for contact sensors.
+	_ "go.viam.com/rdk/components/contactsensor/boardcontact"
+)
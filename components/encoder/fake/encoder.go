@@ -107,6 +107,13 @@ func (e *fakeEncoder) Position(
 	return float64(e.position), e.positionType, nil
 }
 
+// ReadTicks returns the current position in ticks without allocating, for control loops in the same process.
+func (e *fakeEncoder) ReadTicks() (int64, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.position, nil
+}
+
 // Start starts a background thread to run the encoder.
 func (e *fakeEncoder) start(cancelCtx context.Context) {
 	e.activeBackgroundWorkers.Add(1)
@@ -315,6 +315,11 @@ func (e *Encoder) Properties(ctx context.Context, extra map[string]interface{})
 	}, nil
 }
 
+// ReadTicks returns the current position in ticks without allocating, for control loops in the same process.
+func (e *Encoder) ReadTicks() (int64, error) {
+	return atomic.LoadInt64(&e.position), nil
+}
+
 // RawPosition returns the raw position of the encoder.
 func (e *Encoder) RawPosition() int64 {
 	return atomic.LoadInt64(&e.pRaw)
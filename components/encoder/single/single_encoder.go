@@ -233,6 +233,11 @@ func (e *Encoder) Position(
 	return float64(res), e.positionType, nil
 }
 
+// ReadTicks returns the current position in ticks without allocating, for control loops in the same process.
+func (e *Encoder) ReadTicks() (int64, error) {
+	return atomic.LoadInt64(&e.position), nil
+}
+
 // ResetPosition sets the current position of the motor (adjusted by a given offset).
 func (e *Encoder) ResetPosition(ctx context.Context, extra map[string]interface{}) error {
 	offsetInt := int64(math.Round(0))
@@ -0,0 +1,141 @@
+// Package fakeconditions simulates the latency, jitter and reading noise of real hardware in fake models, so that
+// integration tests can dial in realistic conditions the same way for every model.
+package fakeconditions
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/resource"
+)
+
+// Config is the simulated conditions of a fake model, which fake models embed in their configs:
+//
+//	{"latency_ms": 20, "jitter_ms": 5, "noise_std_dev": 0.01, "seed": 1}
+type Config struct {
+	// LatencyMS delays each call to the model by this many milliseconds plus a random amount up to JitterMS, as the
+	// faults of the gRPC server delay calls.
+	LatencyMS int `json:"latency_ms,omitempty"`
+	JitterMS  int `json:"jitter_ms,omitempty"`
+	// NoiseStdDev is the standard deviation of the gaussian noise added to each reading, in the units of the reading.
+	// The noise of images is added to each channel of each pixel, out of 255.
+	NoiseStdDev float64 `json:"noise_std_dev,omitempty"`
+	// Seed makes the jitter and noise the same from run to run. They differ every run if it is 0.
+	Seed int64 `json:"seed,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) error {
+	if conf.LatencyMS < 0 || conf.JitterMS < 0 {
+		return resource.NewConfigValidationError(path, errors.New("latency_ms and jitter_ms cannot be negative"))
+	}
+	if conf.NoiseStdDev < 0 {
+		return resource.NewConfigValidationError(path, errors.New("noise_std_dev cannot be negative"))
+	}
+	return nil
+}
+
+// Conditions simulates the conditions of a config. A nil Conditions simulates none.
+type Conditions struct {
+	conf Config
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// New returns the conditions of the config.
+func New(conf Config) *Conditions {
+	seed := conf.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Conditions{conf: conf, rnd: rand.New(rand.NewSource(seed))} //nolint:gosec
+}
+
+// Wait waits out the latency of a call, or returns the error of the context if it is done first. It returns right
+// away if there is no latency, so calls behave just as they did without simulated conditions.
+func (c *Conditions) Wait(ctx context.Context) error {
+	if c == nil || (c.conf.LatencyMS == 0 && c.conf.JitterMS == 0) {
+		return nil
+	}
+	latency := time.Duration(c.conf.LatencyMS) * time.Millisecond
+	if c.conf.JitterMS > 0 {
+		c.mu.Lock()
+		latency += time.Duration(c.rnd.Intn(c.conf.JitterMS+1)) * time.Millisecond
+		c.mu.Unlock()
+	}
+	if !goutils.SelectContextOrWait(ctx, latency) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// Noise returns the reading with noise.
+func (c *Conditions) Noise(v float64) float64 {
+	if c == nil || c.conf.NoiseStdDev == 0 {
+		return v
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return v + c.rnd.NormFloat64()*c.conf.NoiseStdDev
+}
+
+// NoiseVector returns the reading with noise on each axis.
+func (c *Conditions) NoiseVector(v r3.Vector) r3.Vector {
+	return r3.Vector{X: c.Noise(v.X), Y: c.Noise(v.Y), Z: c.Noise(v.Z)}
+}
+
+// NoiseSlice returns a copy of the readings with noise.
+func (c *Conditions) NoiseSlice(vs []float64) []float64 {
+	noisy := make([]float64, len(vs))
+	for i, v := range vs {
+		noisy[i] = c.Noise(v)
+	}
+	return noisy
+}
+
+// NoiseReadings returns a copy of the readings with noise on the floating point ones. Other readings, such as
+// counts and names, are kept as they are.
+func (c *Conditions) NoiseReadings(readings map[string]interface{}) map[string]interface{} {
+	noisy := make(map[string]interface{}, len(readings))
+	for k, v := range readings {
+		switch v := v.(type) {
+		case float64:
+			noisy[k] = c.Noise(v)
+		case float32:
+			noisy[k] = float32(c.Noise(float64(v)))
+		default:
+			noisy[k] = v
+		}
+	}
+	return noisy
+}
+
+// NoiseImage returns a copy of the image with noise on each channel of each pixel.
+func (c *Conditions) NoiseImage(img image.Image) image.Image {
+	if c == nil || c.conf.NoiseStdDev == 0 {
+		return img
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	bounds := img.Bounds()
+	noisy := image.NewNRGBA(bounds)
+	channel := func(v uint8) uint8 {
+		return uint8(min(255, max(0, float64(v)+c.rnd.NormFloat64()*c.conf.NoiseStdDev+0.5)))
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			px := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			noisy.SetNRGBA(x, y, color.NRGBA{R: channel(px.R), G: channel(px.G), B: channel(px.B), A: px.A})
+		}
+	}
+	return noisy
+}
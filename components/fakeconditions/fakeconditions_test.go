@@ -0,0 +1,100 @@
+package fakeconditions
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+)
+
+func TestConfigValidate(t *testing.T) {
+	conf := &Config{LatencyMS: 10, JitterMS: 5, NoiseStdDev: 0.1}
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+
+	conf.JitterMS = -1
+	err := conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "jitter_ms")
+
+	conf = &Config{NoiseStdDev: -0.1}
+	err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "noise_std_dev")
+}
+
+func TestNoConditions(t *testing.T) {
+	for _, c := range []*Conditions{nil, New(Config{})} {
+		start := time.Now()
+		test.That(t, c.Wait(context.Background()), test.ShouldBeNil)
+		test.That(t, time.Since(start), test.ShouldBeLessThan, 10*time.Millisecond)
+		test.That(t, c.Noise(1.5), test.ShouldEqual, 1.5)
+		test.That(t, c.NoiseVector(r3.Vector{X: 1, Y: 2, Z: 3}), test.ShouldResemble, r3.Vector{X: 1, Y: 2, Z: 3})
+		img := image.NewGray(image.Rect(0, 0, 2, 2))
+		test.That(t, c.NoiseImage(img), test.ShouldEqual, img)
+	}
+
+	// without latency a call is not held up, even with a done context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var c *Conditions
+	test.That(t, c.Wait(ctx), test.ShouldBeNil)
+}
+
+func TestWait(t *testing.T) {
+	c := New(Config{LatencyMS: 20, JitterMS: 10})
+	start := time.Now()
+	test.That(t, c.Wait(context.Background()), test.ShouldBeNil)
+	test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 20*time.Millisecond)
+
+	// a done context ends the wait early
+	c = New(Config{LatencyMS: 10000})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	test.That(t, c.Wait(ctx), test.ShouldBeError, context.DeadlineExceeded)
+}
+
+func TestNoise(t *testing.T) {
+	c := New(Config{NoiseStdDev: 2, Seed: 1})
+	var sum, sumSq float64
+	const n = 10000
+	for i := 0; i < n; i++ {
+		v := c.Noise(10) - 10
+		sum += v
+		sumSq += v * v
+	}
+	mean := sum / n
+	test.That(t, mean, test.ShouldAlmostEqual, 0, 0.1)
+	test.That(t, math.Sqrt(sumSq/n-mean*mean), test.ShouldAlmostEqual, 2, 0.1)
+
+	// the same seed gives the same noise
+	test.That(t, New(Config{NoiseStdDev: 2, Seed: 1}).NoiseSlice([]float64{1, 2}), test.ShouldResemble,
+		New(Config{NoiseStdDev: 2, Seed: 1}).NoiseSlice([]float64{1, 2}))
+
+	readings := c.NoiseReadings(map[string]interface{}{"temp": 20.0, "count": 3, "name": "a"})
+	test.That(t, readings["temp"], test.ShouldNotEqual, 20.0)
+	test.That(t, readings["count"], test.ShouldEqual, 3)
+	test.That(t, readings["name"], test.ShouldEqual, "a")
+
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for i := range img.Pix {
+		img.Pix[i] = 128
+	}
+	noisy := c.NoiseImage(img)
+	test.That(t, noisy.Bounds(), test.ShouldResemble, img.Bounds())
+	changed := false
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			px := noisy.At(x, y).(color.NRGBA)
+			test.That(t, px.A, test.ShouldEqual, 128)
+			if px.R != 128 || px.G != 128 || px.B != 128 {
+				changed = true
+			}
+		}
+	}
+	test.That(t, changed, test.ShouldBeTrue)
+}
@@ -0,0 +1,176 @@
+package multiaxis
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+const (
+	// segmentDuration is how long each interpolated segment of an accelerating coordinated move lasts.
+	segmentDuration = 100 * time.Millisecond
+	// minSegmentSpeedMmPerSec keeps slow axes above the speed at which single-axis gantries refuse to move.
+	minSegmentSpeedMmPerSec = 1.
+)
+
+// profile is a trapezoidal velocity profile, normalized to travel a distance of 1 over duration seconds.
+type profile struct {
+	// cruiseVel is the normalized cruising velocity.
+	cruiseVel float64
+	// accel is the normalized acceleration, where zero means velocity changes instantly.
+	accel float64
+	// accelTime is how long it takes to reach cruiseVel.
+	accelTime float64
+	duration  float64
+}
+
+// newProfile builds the fastest normalized profile along which no axis exceeds its speed or acceleration limit.
+// The axis which takes longest to cover its distance dominates, and every other axis is scaled to finish with it.
+// A zero limit for an axis leaves it unconstrained. It returns false if no axis constrains the move.
+func newProfile(distances, speeds, accels []float64) (profile, bool) {
+	cruiseVel, accel := math.Inf(1), math.Inf(1)
+	for i, d := range distances {
+		if d == 0 {
+			continue
+		}
+		if speeds[i] > 0 {
+			cruiseVel = math.Min(cruiseVel, speeds[i]/d)
+		}
+		if accels[i] > 0 {
+			accel = math.Min(accel, accels[i]/d)
+		}
+	}
+	if math.IsInf(cruiseVel, 1) {
+		return profile{}, false
+	}
+	if math.IsInf(accel, 1) {
+		return profile{cruiseVel: cruiseVel, duration: 1 / cruiseVel}, true
+	}
+	// a triangular profile if cruising speed cannot be reached in time
+	if cruiseVel*cruiseVel/accel > 1 {
+		accelTime := math.Sqrt(1 / accel)
+		return profile{cruiseVel: accel * accelTime, accel: accel, accelTime: accelTime, duration: 2 * accelTime}, true
+	}
+	accelTime := cruiseVel / accel
+	return profile{cruiseVel: cruiseVel, accel: accel, accelTime: accelTime, duration: 1/cruiseVel + accelTime}, true
+}
+
+// fractionAt returns the fraction of the move completed t seconds after it starts.
+func (p profile) fractionAt(t float64) float64 {
+	switch {
+	case t <= 0:
+		return 0
+	case t >= p.duration:
+		return 1
+	case p.accel == 0:
+		return p.cruiseVel * t
+	case t < p.accelTime:
+		return 0.5 * p.accel * t * t
+	case t > p.duration-p.accelTime:
+		remaining := p.duration - t
+		return 1 - 0.5*p.accel*remaining*remaining
+	default:
+		return 0.5*p.accel*p.accelTime*p.accelTime + p.cruiseVel*(t-p.accelTime)
+	}
+}
+
+// segmentTimes returns the times at which each segment of the move ends. Moves without acceleration limits are linear
+// and need only a single segment.
+func (p profile) segmentTimes() []float64 {
+	if p.accel == 0 {
+		return []float64{p.duration}
+	}
+	n := int(math.Ceil(p.duration / segmentDuration.Seconds()))
+	times := make([]float64, 0, n)
+	for i := 1; i < n; i++ {
+		times = append(times, float64(i)*segmentDuration.Seconds())
+	}
+	return append(times, p.duration)
+}
+
+// moveCoordinated moves all axes simultaneously from start to target, scaled so that every axis arrives together.
+func (g *multiAxis) moveCoordinated(
+	ctx context.Context,
+	axisCounts []int,
+	start, target, speeds []float64,
+	extra map[string]interface{},
+) error {
+	distances := make([]float64, len(target))
+	for i := range target {
+		distances[i] = math.Abs(target[i] - start[i])
+	}
+	accels := g.maxAccelsMmPerSecPerSec
+	if len(accels) == 0 {
+		accels = make([]float64, len(target))
+	}
+
+	prof, ok := newProfile(distances, speeds, accels)
+	if !ok {
+		// without any speeds to scale by, leave each axis to its own default speed
+		g.setPlannedDuration(0)
+		return g.moveSubAxesTogether(ctx, axisCounts, distances, target, nil, extra)
+	}
+	g.setPlannedDuration(prof.duration)
+
+	prev, prevT := start, 0.
+	for _, t := range prof.segmentTimes() {
+		frac := prof.fractionAt(t)
+		next := make([]float64, len(target))
+		segmentSpeeds := make([]float64, len(target))
+		for i := range target {
+			next[i] = start[i] + (target[i]-start[i])*frac
+			// axes which are not moving still need a valid speed if they share a sub-axis with one that is
+			segmentSpeeds[i] = math.Max(math.Abs(next[i]-prev[i])/(t-prevT), minSegmentSpeedMmPerSec)
+		}
+		if err := g.moveSubAxesTogether(ctx, axisCounts, distances, next, segmentSpeeds, extra); err != nil {
+			return err
+		}
+		prev, prevT = next, t
+	}
+	return nil
+}
+
+// moveSubAxesTogether moves every sub-axis with a non-zero distance to travel in parallel, and waits for all of them
+// to arrive. A nil speeds leaves each sub-axis to use its default speed.
+func (g *multiAxis) moveSubAxesTogether(
+	ctx context.Context,
+	axisCounts []int,
+	distances, positions, speeds []float64,
+	extra map[string]interface{},
+) error {
+	fs := []rdkutils.SimpleFunc{}
+	idx := 0
+	for i, subAx := range g.subAxes {
+		numAxes := axisCounts[i]
+		pos := positions[idx : idx+numAxes]
+		speed := []float64{}
+		if speeds != nil {
+			speed = speeds[idx : idx+numAxes]
+		}
+		stationary := allZero(distances[idx : idx+numAxes])
+		idx += numAxes
+		if stationary {
+			continue
+		}
+
+		singleGantry := subAx
+		fs = append(fs, func(ctx context.Context) error { return singleGantry.MoveToPosition(ctx, pos, speed, extra) })
+	}
+	if _, err := rdkutils.RunInParallel(ctx, fs); err != nil {
+		return errors.Wrap(err, "coordinated move failed")
+	}
+	return nil
+}
+
+func allZero(vals []float64) bool {
+	for _, v := range vals {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -1,9 +1,19 @@
 // Package multiaxis implements a multi-axis gantry.
+//
+// When move_simultaneously is set, all axes are interpolated together along a trapezoidal velocity profile so that
+// they start and arrive at the same time, with the move duration set by whichever axis is slowest to reach its target
+// given its speed and acceleration limits. Otherwise, sub-axes are moved one at a time in the order they are listed.
+//
+// Sub-axes are homed one at a time, in homing_order followed by any sub-axes it leaves out. If one fails to home, the
+// next call to Home picks up from that sub-axis unless extra contains "restart_homing": true.
 package multiaxis
 
 import (
 	"context"
+	"math"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.uber.org/multierr"
@@ -14,15 +24,23 @@ import (
 	"go.viam.com/rdk/operation"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
-	rdkutils "go.viam.com/rdk/utils"
 )
 
 var model = resource.DefaultModelFamily.WithModel("multi-axis")
 
+// restartHomingKey is the Home extra key which rehomes sub-axes which were homed by a previously failed Home.
+const restartHomingKey = "restart_homing"
+
 // Config is used for converting multiAxis config attributes.
 type Config struct {
 	SubAxes            []string `json:"subaxes_list"`
 	MoveSimultaneously *bool    `json:"move_simultaneously,omitempty"`
+	// MaxSpeedsMmPerSec and MaxAccelsMmPerSecPerSec contain a limit for each axis of every sub-axis, in order.
+	// A limit of zero leaves that axis unlimited.
+	MaxSpeedsMmPerSec       []float64 `json:"max_speeds_mm_per_sec,omitempty"`
+	MaxAccelsMmPerSecPerSec []float64 `json:"max_accels_mm_per_sec_per_sec,omitempty"`
+	// HomingOrder lists sub-axes in the order they are homed. Unlisted sub-axes are homed afterwards in list order.
+	HomingOrder []string `json:"homing_order,omitempty"`
 }
 
 type multiAxis struct {
@@ -35,6 +53,25 @@ type multiAxis struct {
 	model              referenceframe.Model
 	opMgr              *operation.SingleOperationManager
 	workers            sync.WaitGroup
+
+	maxSpeedsMmPerSec       []float64
+	maxAccelsMmPerSecPerSec []float64
+
+	// homingOrder holds indices into subAxes. Sub-axes are homed in list order if it is empty.
+	homingOrder []int
+
+	mu       sync.Mutex
+	lastMove *moveProgress
+	// homedSubAxes records which sub-axes have been homed by an unfinished homing sequence.
+	homedSubAxes map[int]bool
+}
+
+// moveProgress records the most recent move, so that its progress can be reported.
+type moveProgress struct {
+	startMm         []float64
+	targetMm        []float64
+	startTime       time.Time
+	plannedDuration float64
 }
 
 // Validate ensures all parts of the config are valid.
@@ -45,6 +82,23 @@ func (conf *Config) Validate(path string) ([]string, error) {
 		return nil, resource.NewConfigValidationError(path, errors.New("need at least one axis"))
 	}
 
+	for _, limit := range append(append([]float64{}, conf.MaxSpeedsMmPerSec...), conf.MaxAccelsMmPerSecPerSec...) {
+		if limit < 0 {
+			return nil, resource.NewConfigValidationError(path, errors.New("speed and acceleration limits may not be negative"))
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, name := range conf.HomingOrder {
+		if !slices.Contains(conf.SubAxes, name) {
+			return nil, resource.NewConfigValidationError(path, errors.Errorf("homing_order axis %q is not in subaxes_list", name))
+		}
+		if seen[name] {
+			return nil, resource.NewConfigValidationError(path, errors.Errorf("homing_order lists axis %q more than once", name))
+		}
+		seen[name] = true
+	}
+
 	deps = append(deps, conf.SubAxes...)
 	return deps, nil
 }
@@ -80,6 +134,14 @@ func newMultiAxis(
 		}
 		mAx.subAxes = append(mAx.subAxes, subAx)
 	}
+	for _, name := range newConf.HomingOrder {
+		mAx.homingOrder = append(mAx.homingOrder, slices.Index(newConf.SubAxes, name))
+	}
+	for i, name := range newConf.SubAxes {
+		if !slices.Contains(newConf.HomingOrder, name) {
+			mAx.homingOrder = append(mAx.homingOrder, i)
+		}
+	}
 
 	mAx.moveSimultaneously = false
 	if newConf.MoveSimultaneously != nil {
@@ -91,23 +153,86 @@ func newMultiAxis(
 		return nil, err
 	}
 
+	for name, limits := range map[string][]float64{
+		"max_speeds_mm_per_sec":         newConf.MaxSpeedsMmPerSec,
+		"max_accels_mm_per_sec_per_sec": newConf.MaxAccelsMmPerSecPerSec,
+	} {
+		if len(limits) != 0 && len(limits) != len(mAx.lengthsMm) {
+			return nil, errors.Errorf("%s has %d limits but the gantry has %d axes", name, len(limits), len(mAx.lengthsMm))
+		}
+	}
+	mAx.maxSpeedsMmPerSec = newConf.MaxSpeedsMmPerSec
+	mAx.maxAccelsMmPerSecPerSec = newConf.MaxAccelsMmPerSecPerSec
+
 	return mAx, nil
 }
 
 // Home runs the homing sequence of the gantry and returns true once completed.
 func (g *multiAxis) Home(ctx context.Context, extra map[string]interface{}) (bool, error) {
-	for _, subAx := range g.subAxes {
-		homed, err := subAx.Home(ctx, nil)
+	g.mu.Lock()
+	if restart, _ := extra[restartHomingKey].(bool); restart || g.homedSubAxes == nil {
+		g.homedSubAxes = map[int]bool{}
+	}
+	g.mu.Unlock()
+
+	for _, i := range g.homingSequence() {
+		g.mu.Lock()
+		done := g.homedSubAxes[i]
+		g.mu.Unlock()
+		if done {
+			continue
+		}
+
+		homed, err := g.subAxes[i].Home(ctx, extra)
 		if err != nil {
-			return false, err
+			return false, errors.Wrapf(err, "homing sub-axis %q failed", g.subAxes[i].Name().ShortName())
 		}
 		if !homed {
 			return false, nil
 		}
+		g.mu.Lock()
+		g.homedSubAxes[i] = true
+		g.mu.Unlock()
 	}
+
+	g.mu.Lock()
+	g.homedSubAxes = nil
+	g.mu.Unlock()
 	return true, nil
 }
 
+// homingSequence returns the indices of the sub-axes in the order they are homed.
+func (g *multiAxis) homingSequence() []int {
+	if len(g.homingOrder) != 0 {
+		return g.homingOrder
+	}
+	sequence := make([]int, len(g.subAxes))
+	for i := range sequence {
+		sequence[i] = i
+	}
+	return sequence
+}
+
+// homingStatus reports which sub-axes an unfinished homing sequence has homed, and which remain in homing order.
+func (g *multiAxis) homingStatus() map[string]interface{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	homed, pending := []string{}, []string{}
+	for _, i := range g.homingSequence() {
+		name := g.subAxes[i].Name().ShortName()
+		if g.homedSubAxes[i] {
+			homed = append(homed, name)
+		} else {
+			pending = append(pending, name)
+		}
+	}
+	return map[string]interface{}{
+		"in_progress":      g.homedSubAxes != nil,
+		"homed_sub_axes":   homed,
+		"pending_sub_axes": pending,
+	}
+}
+
 // MoveToPosition moves along an axis using inputs in millimeters.
 func (g *multiAxis) MoveToPosition(ctx context.Context, positions, speeds []float64, extra map[string]interface{}) error {
 	ctx, done := g.opMgr.New(ctx)
@@ -124,40 +249,181 @@ func (g *multiAxis) MoveToPosition(ctx context.Context, positions, speeds []floa
 		)
 	}
 
-	fs := []rdkutils.SimpleFunc{}
-	idx := 0
+	if len(speeds) != 0 && len(speeds) != len(positions) {
+		return errors.Errorf("number of input speeds %v does not match total gantry axes count %v", len(speeds), len(positions))
+	}
+	speeds = g.limitSpeeds(speeds)
+
+	axisCounts := make([]int, 0, len(g.subAxes))
 	for _, subAx := range g.subAxes {
 		subAxNum, err := subAx.Lengths(ctx, extra)
 		if err != nil {
 			return err
 		}
+		axisCounts = append(axisCounts, len(subAxNum))
+	}
+
+	start, err := g.Position(ctx, nil)
+	if err != nil {
+		return err
+	}
+	g.startMove(start, positions)
+	defer operation.TrackProgress(ctx, g.operationProgress)()
+
+	if g.moveSimultaneously {
+		if err := g.moveCoordinated(ctx, axisCounts, start, positions, speeds, extra); err != nil {
+			return multierr.Combine(err, g.Stop(ctx, nil))
+		}
+		return nil
+	}
 
-		pos := positions[idx : idx+len(subAxNum)]
+	idx := 0
+	for i, subAx := range g.subAxes {
+		pos := positions[idx : idx+axisCounts[i]]
 		var speed []float64
-		// if speeds is an empty list, speed will be set to the default in the subAx MoveToPosition call
-		if len(speeds) == 0 {
+		// if a speed is unknown, speed will be set to the default in the subAx MoveToPosition call
+		if len(speeds) == 0 || !allNonZero(speeds[idx:idx+axisCounts[i]]) {
 			speed = []float64{}
 		} else {
-			speed = speeds[idx : idx+len(subAxNum)]
+			speed = speeds[idx : idx+axisCounts[i]]
 		}
-		idx += len(subAxNum)
+		idx += axisCounts[i]
 
-		if g.moveSimultaneously {
-			singleGantry := subAx
-			fs = append(fs, func(ctx context.Context) error { return singleGantry.MoveToPosition(ctx, pos, speed, nil) })
-		} else {
-			err = subAx.MoveToPosition(ctx, pos, speed, extra)
-			if err != nil && !errors.Is(err, context.Canceled) {
-				return err
-			}
+		err = subAx.MoveToPosition(ctx, pos, speed, extra)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return err
 		}
 	}
-	if g.moveSimultaneously {
-		if _, err := rdkutils.RunInParallel(ctx, fs); err != nil {
-			return multierr.Combine(err, g.Stop(ctx, nil))
+	return nil
+}
+
+// limitSpeeds returns the requested speeds with each axis limited to its maximum speed. Axes without a requested speed
+// default to their maximum speed, or zero if that is unlimited too.
+func (g *multiAxis) limitSpeeds(speeds []float64) []float64 {
+	if len(g.maxSpeedsMmPerSec) == 0 {
+		return speeds
+	}
+	limited := make([]float64, len(g.maxSpeedsMmPerSec))
+	for i, maxSpeed := range g.maxSpeedsMmPerSec {
+		requested := 0.
+		if len(speeds) != 0 {
+			requested = math.Abs(speeds[i])
+		}
+		switch {
+		case requested == 0:
+			limited[i] = maxSpeed
+		case maxSpeed == 0:
+			limited[i] = requested
+		default:
+			limited[i] = math.Min(requested, maxSpeed)
 		}
 	}
-	return nil
+	return limited
+}
+
+func allNonZero(vals []float64) bool {
+	for _, v := range vals {
+		if v == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *multiAxis) startMove(start, target []float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.lastMove = &moveProgress{
+		startMm:   start,
+		targetMm:  append([]float64{}, target...),
+		startTime: time.Now(),
+	}
+}
+
+func (g *multiAxis) setPlannedDuration(seconds float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.lastMove != nil {
+		g.lastMove.plannedDuration = seconds
+	}
+}
+
+// progress returns the fraction of the most recent move which has been completed, measured by the distance the axes
+// have covered together. It is 1 if there has been no move. The move returned is a copy taken under the lock, so
+// callers may read it while a later move updates its planned duration.
+func (g *multiAxis) progress(ctx context.Context) (float64, *moveProgress, error) {
+	g.mu.Lock()
+	if g.lastMove == nil {
+		g.mu.Unlock()
+		return 1, nil, nil
+	}
+	lastMove := &moveProgress{
+		startMm:         append([]float64{}, g.lastMove.startMm...),
+		targetMm:        append([]float64{}, g.lastMove.targetMm...),
+		startTime:       g.lastMove.startTime,
+		plannedDuration: g.lastMove.plannedDuration,
+	}
+	g.mu.Unlock()
+	current, err := g.Position(ctx, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	var total, remaining float64
+	for i := range lastMove.targetMm {
+		total += math.Pow(lastMove.targetMm[i]-lastMove.startMm[i], 2)
+		remaining += math.Pow(lastMove.targetMm[i]-current[i], 2)
+	}
+	if total == 0 {
+		return 1, lastMove, nil
+	}
+	return math.Max(0, math.Min(1, 1-math.Sqrt(remaining/total))), lastMove, nil
+}
+
+// operationProgress reports the progress of the current move to the operations API. The time remaining is what is
+// left of the planned duration of a coordinated move, and is otherwise estimated from the progress so far.
+func (g *multiAxis) operationProgress(ctx context.Context) (operation.Progress, error) {
+	fraction, lastMove, err := g.progress(ctx)
+	if err != nil || lastMove == nil {
+		return operation.Progress{Percent: 100 * fraction}, err
+	}
+	elapsed := time.Since(lastMove.startTime)
+	p := operation.ProgressFromFraction(fraction, elapsed)
+	if lastMove.plannedDuration > 0 && fraction < 1 {
+		p.Remaining = max(0, time.Duration(lastMove.plannedDuration*float64(time.Second))-elapsed)
+	}
+	return p, nil
+}
+
+// DoCommand supports reporting the progress of the current or most recent move with {"command": "get_move_progress"},
+// and of homing with {"command": "get_homing_status"}.
+func (g *multiAxis) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case "get_move_progress":
+		progress, lastMove, err := g.progress(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp := map[string]interface{}{
+			"progress":  progress,
+			"is_moving": g.opMgr.OpRunning(),
+		}
+		if lastMove != nil {
+			resp["target_positions_mm"] = lastMove.targetMm
+			resp["elapsed_sec"] = time.Since(lastMove.startTime).Seconds()
+			if lastMove.plannedDuration > 0 {
+				resp["planned_duration_sec"] = lastMove.plannedDuration
+			}
+		}
+		return resp, nil
+	case "get_homing_status":
+		return g.homingStatus(), nil
+	default:
+		return nil, errors.Errorf("no such command: %s", name)
+	}
 }
 
 // GoToInputs moves the gantry to a goal position in the Gantry frame.
@@ -2,8 +2,10 @@ package multiaxis
 
 import (
 	"context"
+	"math"
 	"testing"
 
+	"github.com/pkg/errors"
 	"go.viam.com/test"
 
 	"go.viam.com/rdk/components/gantry"
@@ -87,6 +89,18 @@ func TestValidate(t *testing.T) {
 	fakecfg = &Config{SubAxes: []string{"singleaxis"}}
 	_, err = fakecfg.Validate("path")
 	test.That(t, err, test.ShouldBeNil)
+
+	fakecfg = &Config{SubAxes: []string{"singleaxis"}, MaxSpeedsMmPerSec: []float64{-1}}
+	_, err = fakecfg.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "may not be negative")
+
+	fakecfg = &Config{SubAxes: []string{"x", "y"}, HomingOrder: []string{"z"}}
+	_, err = fakecfg.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, `homing_order axis "z" is not in subaxes_list`)
+
+	fakecfg = &Config{SubAxes: []string{"x", "y"}, HomingOrder: []string{"y", "y"}}
+	_, err = fakecfg.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "more than once")
 }
 
 func TestNewMultiAxis(t *testing.T) {
@@ -254,6 +268,73 @@ func TestHome(t *testing.T) {
 	test.That(t, homed, test.ShouldBeTrue)
 }
 
+func TestHomingOrder(t *testing.T) {
+	ctx := context.Background()
+	var homedAxes []string
+	failing := map[string]bool{}
+	homeErr := errors.New("limit switch not found")
+	createHomingAxis := func(name string) *inject.Gantry {
+		g := inject.NewGantry(name)
+		g.LengthsFunc = func(ctx context.Context, extra map[string]interface{}) ([]float64, error) { return []float64{1}, nil }
+		g.HomeFunc = func(ctx context.Context, extra map[string]interface{}) (bool, error) {
+			if failing[name] {
+				return false, homeErr
+			}
+			homedAxes = append(homedAxes, name)
+			return true, nil
+		}
+		return g
+	}
+	x, y, z := createHomingAxis("x"), createHomingAxis("y"), createHomingAxis("z")
+	fakemultiaxis := &multiAxis{
+		subAxes:     []gantry.Gantry{x, y, z},
+		homingOrder: []int{2, 0, 1},
+		opMgr:       operation.NewSingleOperationManager(),
+	}
+
+	homed, err := fakemultiaxis.Home(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, homed, test.ShouldBeTrue)
+	test.That(t, homedAxes, test.ShouldResemble, []string{"z", "x", "y"})
+
+	// a failure names the sub-axis and leaves the earlier sub-axes homed
+	homedAxes = nil
+	failing["x"] = true
+	homed, err = fakemultiaxis.Home(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, errors.Is(err, homeErr), test.ShouldBeTrue)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `homing sub-axis "x" failed`)
+	test.That(t, homed, test.ShouldBeFalse)
+	status, err := fakemultiaxis.DoCommand(ctx, map[string]interface{}{"command": "get_homing_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["in_progress"], test.ShouldBeTrue)
+	test.That(t, status["homed_sub_axes"], test.ShouldResemble, []string{"z"})
+	test.That(t, status["pending_sub_axes"], test.ShouldResemble, []string{"x", "y"})
+
+	// homing again resumes from the failed sub-axis
+	homedAxes = nil
+	failing["x"] = false
+	homed, err = fakemultiaxis.Home(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, homed, test.ShouldBeTrue)
+	test.That(t, homedAxes, test.ShouldResemble, []string{"x", "y"})
+	status, err = fakemultiaxis.DoCommand(ctx, map[string]interface{}{"command": "get_homing_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["in_progress"], test.ShouldBeFalse)
+
+	// unless asked to restart
+	homedAxes = nil
+	failing["y"] = true
+	_, err = fakemultiaxis.Home(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	failing["y"] = false
+	homedAxes = nil
+	homed, err = fakemultiaxis.Home(ctx, map[string]interface{}{"restart_homing": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, homed, test.ShouldBeTrue)
+	test.That(t, homedAxes, test.ShouldResemble, []string{"z", "x", "y"})
+}
+
 func TestStop(t *testing.T) {
 	ctx := context.Background()
 	fakemultiaxis := &multiAxis{
@@ -434,3 +515,155 @@ func TestComplexMultiAxis(t *testing.T) {
 			}
 		})
 }
+
+// createRecordingAxis creates a single axis gantry which moves instantly and records each move it is asked to make.
+func createRecordingAxis(name string, length float64, moves, speeds *[][]float64) *inject.Gantry {
+	position := []float64{0}
+	g := inject.NewGantry(name)
+	g.PositionFunc = func(ctx context.Context, extra map[string]interface{}) ([]float64, error) {
+		return position, nil
+	}
+	g.MoveToPositionFunc = func(ctx context.Context, pos, speed []float64, extra map[string]interface{}) error {
+		position = []float64{pos[0]}
+		*moves = append(*moves, pos)
+		*speeds = append(*speeds, speed)
+		return nil
+	}
+	g.LengthsFunc = func(ctx context.Context, extra map[string]interface{}) ([]float64, error) {
+		return []float64{length}, nil
+	}
+	g.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		return nil
+	}
+	return g
+}
+
+func TestProfile(t *testing.T) {
+	_, ok := newProfile([]float64{10, 20}, []float64{0, 0}, []float64{0, 0})
+	test.That(t, ok, test.ShouldBeFalse)
+
+	// the second axis dominates, taking 4 seconds
+	prof, ok := newProfile([]float64{10, 20}, []float64{10, 5}, []float64{0, 0})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, prof.duration, test.ShouldAlmostEqual, 4)
+	test.That(t, prof.fractionAt(2), test.ShouldAlmostEqual, 0.5)
+	test.That(t, prof.segmentTimes(), test.ShouldResemble, []float64{4})
+
+	// trapezoidal, accelerating for 1 second to cruise at 10mm/s over 20mm
+	prof, ok = newProfile([]float64{20}, []float64{10}, []float64{10})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, prof.duration, test.ShouldAlmostEqual, 3)
+	test.That(t, prof.fractionAt(1), test.ShouldAlmostEqual, 0.25)
+	test.That(t, prof.fractionAt(1.5), test.ShouldAlmostEqual, 0.5)
+	test.That(t, prof.fractionAt(2), test.ShouldAlmostEqual, 0.75)
+	test.That(t, prof.fractionAt(3), test.ShouldAlmostEqual, 1)
+	test.That(t, len(prof.segmentTimes()), test.ShouldEqual, 30)
+
+	// triangular, as cruising speed is never reached over 5mm
+	prof, ok = newProfile([]float64{5}, []float64{10}, []float64{10})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, prof.duration, test.ShouldAlmostEqual, 2*math.Sqrt(0.5))
+	test.That(t, prof.fractionAt(prof.duration/2), test.ShouldAlmostEqual, 0.5)
+}
+
+func TestCoordinatedMove(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	moveSimultaneously := true
+
+	var moves1, speeds1, moves2, speeds2 [][]float64
+	deps := make(resource.Dependencies)
+	axis1 := createRecordingAxis("1", 200, &moves1, &speeds1)
+	axis2 := createRecordingAxis("2", 200, &moves2, &speeds2)
+	deps[axis1.Name()] = axis1
+	deps[axis2.Name()] = axis2
+
+	cfg := resource.Config{
+		Name: "gantry",
+		ConvertedAttributes: &Config{
+			SubAxes:            []string{"1", "2"},
+			MoveSimultaneously: &moveSimultaneously,
+			MaxSpeedsMmPerSec:  []float64{20, 10},
+		},
+	}
+	g, err := newMultiAxis(ctx, deps, cfg, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	resp, err := g.DoCommand(ctx, map[string]interface{}{"command": "get_move_progress"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["progress"], test.ShouldEqual, 1.)
+
+	t.Run("axes are scaled to arrive together", func(t *testing.T) {
+		err = g.MoveToPosition(ctx, []float64{100, 20}, []float64{}, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, moves1, test.ShouldResemble, [][]float64{{100}})
+		test.That(t, moves2, test.ShouldResemble, [][]float64{{20}})
+		test.That(t, speeds1[0][0], test.ShouldAlmostEqual, 20)
+		test.That(t, speeds2[0][0], test.ShouldAlmostEqual, 4)
+
+		resp, err := g.DoCommand(ctx, map[string]interface{}{"command": "get_move_progress"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp["progress"], test.ShouldEqual, 1.)
+		test.That(t, resp["planned_duration_sec"], test.ShouldAlmostEqual, 5)
+		test.That(t, resp["target_positions_mm"], test.ShouldResemble, []float64{100, 20})
+
+		progress, err := g.(*multiAxis).operationProgress(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, progress, test.ShouldResemble, operation.Progress{Percent: 100})
+	})
+
+	t.Run("progress reports a copy of the last move", func(t *testing.T) {
+		_, lastMove, err := g.(*multiAxis).progress(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		g.(*multiAxis).setPlannedDuration(10)
+		test.That(t, lastMove.plannedDuration, test.ShouldAlmostEqual, 5)
+
+		resp, err := g.DoCommand(ctx, map[string]interface{}{"command": "get_move_progress"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp["planned_duration_sec"], test.ShouldAlmostEqual, 10)
+	})
+
+	t.Run("requested speeds are limited", func(t *testing.T) {
+		moves1, speeds1, moves2, speeds2 = nil, nil, nil, nil
+		// the second axis is limited to 10mm/s so dominates
+		err = g.MoveToPosition(ctx, []float64{120, 70}, []float64{100, 100}, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, speeds1[0][0], test.ShouldAlmostEqual, 4)
+		test.That(t, speeds2[0][0], test.ShouldAlmostEqual, 10)
+	})
+
+	t.Run("stationary axes are not moved", func(t *testing.T) {
+		moves1, speeds1, moves2, speeds2 = nil, nil, nil, nil
+		err = g.MoveToPosition(ctx, []float64{120, 80}, []float64{}, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, moves1, test.ShouldBeEmpty)
+		test.That(t, moves2, test.ShouldResemble, [][]float64{{80}})
+	})
+
+	t.Run("accelerating moves are interpolated", func(t *testing.T) {
+		moves1, speeds1, moves2, speeds2 = nil, nil, nil, nil
+		cfg.ConvertedAttributes.(*Config).MaxAccelsMmPerSecPerSec = []float64{20, 20}
+		g, err := newMultiAxis(ctx, deps, cfg, logger)
+		test.That(t, err, test.ShouldBeNil)
+		err = g.MoveToPosition(ctx, []float64{0, 0}, []float64{}, nil)
+		test.That(t, err, test.ShouldBeNil)
+
+		test.That(t, len(moves1), test.ShouldBeGreaterThan, 1)
+		test.That(t, len(moves1), test.ShouldEqual, len(moves2))
+		test.That(t, moves1[len(moves1)-1], test.ShouldResemble, []float64{0})
+		test.That(t, moves2[len(moves2)-1], test.ShouldResemble, []float64{0})
+		for i := range moves1 {
+			// both axes stay on the straight line between start and target
+			test.That(t, moves1[i][0]/120, test.ShouldAlmostEqual, moves2[i][0]/80)
+			test.That(t, speeds1[i][0], test.ShouldBeLessThanOrEqualTo, 20+1e-6)
+			test.That(t, speeds2[i][0], test.ShouldBeLessThanOrEqualTo, 10+1e-6)
+		}
+	})
+
+	t.Run("limits must match the number of axes", func(t *testing.T) {
+		cfg.ConvertedAttributes.(*Config).MaxAccelsMmPerSecPerSec = []float64{20}
+		_, err := newMultiAxis(ctx, deps, cfg, logger)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "max_accels_mm_per_sec_per_sec")
+	})
+}
@@ -0,0 +1,267 @@
+package singleaxis
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/resource"
+)
+
+// Supported homing strategies.
+const (
+	// homingStrategyLimitSwitch drives the axis until it triggers its limit switches.
+	homingStrategyLimitSwitch = "limit_switch"
+	// homingStrategyStall drives the axis until the motor stops making progress against the end of travel.
+	homingStrategyStall = "stall"
+	// homingStrategyEncoder takes the current position of the motor as the zero position.
+	homingStrategyEncoder = "encoder"
+)
+
+// Supported homing directions.
+const (
+	homingDirectionNegative = "negative"
+	homingDirectionPositive = "positive"
+)
+
+const (
+	defaultStallWindow      = 200 * time.Millisecond
+	defaultStallThresholdMm = 0.1
+	defaultStallRampTime    = 500 * time.Millisecond
+	// restartHomingKey is the Home extra key which discards the progress of a previously failed homing sequence.
+	restartHomingKey = "restart_homing"
+)
+
+// HomingConfig describes how a single-axis gantry finds the ends of its travel.
+type HomingConfig struct {
+	// Strategy defaults to limit_switch for gantries with limit pins and encoder otherwise.
+	Strategy string `json:"strategy,omitempty"`
+	// Direction is which end of the axis the home is found at, for stall homing or a single limit switch.
+	// With two limit switches, it is which switch is found first. Defaults to negative.
+	Direction string `json:"direction,omitempty"`
+	// BackoffMm is how far to move away from the end of travel after it is found.
+	BackoffMm float64 `json:"backoff_mm,omitempty"`
+	// SpeedMmPerSec is how fast to move while homing, defaulting to the gantry speed.
+	SpeedMmPerSec float64 `json:"speed_mm_per_sec,omitempty"`
+	// The axis is considered stalled when it moves less than StallThresholdMm over StallWindowMs.
+	StallWindowMs    int     `json:"stall_window_ms,omitempty"`
+	StallThresholdMm float64 `json:"stall_threshold_mm,omitempty"`
+	// StallRampMs is how long the motor takes to accelerate to the homing speed, during which it moves too slowly to
+	// tell from a stall, so stalls are only looked for once it has passed. Defaults to 500ms.
+	StallRampMs int `json:"stall_ramp_ms,omitempty"`
+}
+
+// Validate ensures all parts of the homing config are valid for a gantry with the given number of limit pins.
+func (hc *HomingConfig) Validate(path string, numLimitPins int) error {
+	switch hc.Strategy {
+	case "", homingStrategyEncoder, homingStrategyStall:
+	case homingStrategyLimitSwitch:
+		if numLimitPins == 0 {
+			return resource.NewConfigValidationError(path, errors.New("limit_switch homing requires limit_pins"))
+		}
+	default:
+		return resource.NewConfigValidationError(path, errors.Errorf("unknown homing strategy %q", hc.Strategy))
+	}
+	switch hc.Direction {
+	case "", homingDirectionNegative, homingDirectionPositive:
+	default:
+		return resource.NewConfigValidationError(path, errors.Errorf("unknown homing direction %q", hc.Direction))
+	}
+	if hc.BackoffMm < 0 || hc.SpeedMmPerSec < 0 || hc.StallWindowMs < 0 || hc.StallThresholdMm < 0 || hc.StallRampMs < 0 {
+		return resource.NewConfigValidationError(path, errors.New("homing distances, speeds and durations may not be negative"))
+	}
+	return nil
+}
+
+// homingSettings are the resolved homing settings of a gantry. The zero value homes towards the negative end without
+// backing off, at the gantry speed.
+type homingSettings struct {
+	strategy         string
+	positive         bool
+	backoffMm        float64
+	rpm              float64
+	stallWindow      time.Duration
+	stallThresholdMm float64
+	stallRamp        time.Duration
+}
+
+func newHomingSettings(conf *HomingConfig) homingSettings {
+	if conf == nil {
+		return homingSettings{}
+	}
+	return homingSettings{
+		strategy:         conf.Strategy,
+		positive:         conf.Direction == homingDirectionPositive,
+		backoffMm:        conf.BackoffMm,
+		stallWindow:      time.Duration(conf.StallWindowMs) * time.Millisecond,
+		stallThresholdMm: conf.StallThresholdMm,
+		stallRamp:        time.Duration(conf.StallRampMs) * time.Millisecond,
+	}
+}
+
+// homingProgress records how far a homing sequence got, so that a failed sequence can be resumed.
+type homingProgress struct {
+	// foundLimits holds the motor positions of the limit switches found so far, by pin index.
+	foundLimits map[int]float64
+	stage       string
+	err         error
+}
+
+// resolvedStrategy returns the homing strategy to use, defaulting based on the number of limit switches.
+func (g *singleAxis) resolvedStrategy() string {
+	if g.homing.strategy != "" {
+		return g.homing.strategy
+	}
+	if len(g.limitSwitchPins) > 0 {
+		return homingStrategyLimitSwitch
+	}
+	return homingStrategyEncoder
+}
+
+func (g *singleAxis) homingRPM() float64 {
+	if g.homing.rpm != 0 {
+		return g.homing.rpm
+	}
+	return g.rpm
+}
+
+func (g *singleAxis) setHomingStage(stage string) {
+	g.homingProgress.stage = stage
+}
+
+// limitOrder returns the limit pins in the order they are searched for.
+func (g *singleAxis) limitOrder() []int {
+	if len(g.limitSwitchPins) > 1 && g.homing.positive {
+		return []int{1, 0}
+	}
+	if len(g.limitSwitchPins) > 1 {
+		return []int{0, 1}
+	}
+	return []int{0}
+}
+
+// limitDirection returns the direction the motor turns to find the given limit pin.
+func (g *singleAxis) limitDirection(pin int) float64 {
+	if pin != 0 || (len(g.limitSwitchPins) == 1 && g.homing.positive) {
+		return 1
+	}
+	return -1
+}
+
+// backOff moves away from the end of travel which was found by moving in direction dir.
+func (g *singleAxis) backOff(ctx context.Context, dir float64) error {
+	if g.homing.backoffMm <= 0 || g.mmPerRevolution <= 0 {
+		return nil
+	}
+	g.setHomingStage("backing off")
+	return g.motor.GoFor(ctx, -dir*g.homingRPM(), g.homing.backoffMm/g.mmPerRevolution, nil)
+}
+
+// homeStall drives the motor towards the home direction until it stalls against the end of travel, and takes the
+// stalled position as that end.
+func (g *singleAxis) homeStall(ctx context.Context) error {
+	dir := -1.
+	if g.homing.positive {
+		dir = 1
+	}
+	g.setHomingStage("finding end of travel by stalling")
+	position, err := g.findStall(ctx, dir)
+	if err != nil {
+		return err
+	}
+	if err := g.backOff(ctx, dir); err != nil {
+		return err
+	}
+
+	revPerLength := g.lengthMm / g.mmPerRevolution
+	positionA, positionB := position, position+revPerLength
+	if g.homing.positive {
+		positionA, positionB = position-revPerLength, position
+	}
+	g.positionLimits = []float64{positionA, positionB}
+	g.positionRange = positionB - positionA
+
+	g.setHomingStage("moving to the middle of the axis")
+	return g.motor.GoTo(ctx, g.rpm, g.gantryToMotorPosition(0.5*g.lengthMm), nil)
+}
+
+// findStall turns the motor in direction dir until its position changes by less than the stall threshold over the
+// stall window, returning the position it stalled at. Stalls are only looked for once the motor has had the stall
+// ramp time to accelerate.
+func (g *singleAxis) findStall(ctx context.Context, dir float64) (float64, error) {
+	defer utils.UncheckedErrorFunc(func() error {
+		return g.motor.Stop(ctx, nil)
+	})
+	window := g.homing.stallWindow
+	if window == 0 {
+		window = defaultStallWindow
+	}
+	thresholdRevs := g.homing.stallThresholdMm
+	if thresholdRevs == 0 {
+		thresholdRevs = defaultStallThresholdMm
+	}
+	thresholdRevs /= g.mmPerRevolution
+	rampTime := g.homing.stallRamp
+	if rampTime == 0 {
+		rampTime = defaultStallRampTime
+	}
+
+	timeout := homingTimeout
+	if g.mmPerRevolution != 0 && g.homingRPM() != 0 && g.lengthMm != 0 {
+		timeout = time.Duration((1 / (g.homingRPM() / 60e9 * g.mmPerRevolution / g.lengthMm) * 5))
+	}
+	timeout += rampTime
+
+	if err := g.motor.GoFor(ctx, dir*g.homingRPM(), 0, nil); err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	if !utils.SelectContextOrWait(ctx, rampTime) {
+		return 0, ctx.Err()
+	}
+	last, err := g.motor.Position(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	for {
+		if !utils.SelectContextOrWait(ctx, window) {
+			return 0, ctx.Err()
+		}
+		current, err := g.motor.Position(ctx, nil)
+		if err != nil {
+			return 0, err
+		}
+		if math.Abs(current-last) < thresholdRevs {
+			if err := g.motor.Stop(ctx, nil); err != nil {
+				return 0, err
+			}
+			return current, nil
+		}
+		last = current
+		if time.Since(start) > timeout {
+			return 0, errors.Errorf("gantry timed out waiting to stall, timeout = %v", timeout)
+		}
+	}
+}
+
+func (g *singleAxis) homingStatus() map[string]interface{} {
+	status := map[string]interface{}{
+		"homed":    g.positionRange != 0,
+		"strategy": g.resolvedStrategy(),
+		"stage":    g.homingProgress.stage,
+	}
+	found := map[string]interface{}{}
+	for pin, position := range g.homingProgress.foundLimits {
+		found[g.limitSwitchPins[pin]] = position
+	}
+	if len(found) != 0 {
+		status["found_limits"] = found
+	}
+	if g.homingProgress.err != nil {
+		status["error"] = g.homingProgress.err.Error()
+	}
+	return status
+}
@@ -35,13 +35,14 @@ const limitErrorMargin = 0.25
 
 // Config is used for converting singleAxis config attributes.
 type Config struct {
-	Board           string   `json:"board,omitempty"` // used to read limit switch pins and control motor with gpio pins
-	Motor           string   `json:"motor"`
-	LimitSwitchPins []string `json:"limit_pins,omitempty"`
-	LimitPinEnabled *bool    `json:"limit_pin_enabled_high,omitempty"`
-	LengthMm        float64  `json:"length_mm"`
-	MmPerRevolution float64  `json:"mm_per_rev"`
-	GantryMmPerSec  float64  `json:"gantry_mm_per_sec,omitempty"`
+	Board           string        `json:"board,omitempty"` // used to read limit switch pins and control motor with gpio pins
+	Motor           string        `json:"motor"`
+	LimitSwitchPins []string      `json:"limit_pins,omitempty"`
+	LimitPinEnabled *bool         `json:"limit_pin_enabled_high,omitempty"`
+	LengthMm        float64       `json:"length_mm"`
+	MmPerRevolution float64       `json:"mm_per_rev"`
+	GantryMmPerSec  float64       `json:"gantry_mm_per_sec,omitempty"`
+	Homing          *HomingConfig `json:"homing,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -78,6 +79,12 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 	if len(cfg.LimitSwitchPins) > 0 && cfg.LimitPinEnabled == nil {
 		return nil, errors.New("limit pin enabled must be set to true or false")
 	}
+
+	if cfg.Homing != nil {
+		if err := cfg.Homing.Validate(path, len(cfg.LimitSwitchPins)); err != nil {
+			return nil, err
+		}
+	}
 	return deps, nil
 }
 
@@ -103,6 +110,9 @@ type singleAxis struct {
 	mmPerRevolution float64
 	rpm             float64
 
+	homing         homingSettings
+	homingProgress homingProgress
+
 	model referenceframe.Model
 	frame r3.Vector
 
@@ -169,6 +179,16 @@ func (g *singleAxis) Reconfigure(ctx context.Context, deps resource.Dependencies
 		g.rpm = 100
 	}
 
+	// Rerun homing if the way the gantry homes changes
+	homing := newHomingSettings(newConf.Homing)
+	if newConf.Homing != nil && g.mmPerRevolution > 0 {
+		homing.rpm = g.gantryToMotorSpeeds(newConf.Homing.SpeedMmPerSec)
+	}
+	if homing != g.homing {
+		g.homing = homing
+		needsToReHome = true
+	}
+
 	// Rerun homing if the board has changed
 	if newConf.Board != "" {
 		if g.board == nil || g.board.Name().ShortName() != newConf.Board {
@@ -222,6 +242,7 @@ func (g *singleAxis) Reconfigure(ctx context.Context, deps resource.Dependencies
 		g.logger.CInfof(ctx, "single-axis gantry '%v' needs to re-home", g.Named.Name().ShortName())
 		g.positionRange = 0
 		g.positionLimits = []float64{0, 0}
+		g.homingProgress = homingProgress{}
 	}
 	ctx, cancelFunc := context.WithCancel(context.Background())
 	g.cancelFunc = cancelFunc
@@ -231,6 +252,8 @@ func (g *singleAxis) Reconfigure(ctx context.Context, deps resource.Dependencies
 }
 
 // Home runs the homing sequence of the gantry, starts checkHit in the background, and returns true once completed.
+// If a previous homing sequence failed part way through, the ends of the axis it already found are reused unless
+// extra contains "restart_homing": true.
 func (g *singleAxis) Home(ctx context.Context, extra map[string]interface{}) (bool, error) {
 	if g.cancelFunc != nil {
 		g.cancelFunc()
@@ -238,6 +261,9 @@ func (g *singleAxis) Home(ctx context.Context, extra map[string]interface{}) (bo
 	}
 	g.mu.Lock()
 	defer g.mu.Unlock()
+	if restart, _ := extra[restartHomingKey].(bool); restart {
+		g.homingProgress = homingProgress{}
+	}
 	homed, err := g.doHome(ctx)
 	if err != nil {
 		return homed, err
@@ -294,10 +320,7 @@ func (g *singleAxis) checkHit(ctx context.Context) {
 // this function stops the motor, and reverses the direction of movement until the limit
 // switch is no longer activated.
 func (g *singleAxis) moveAway(ctx context.Context, pin int) error {
-	dir := 1.0
-	if pin != 0 {
-		dir = -1.0
-	}
+	dir := -g.limitDirection(pin)
 	if err := g.motor.GoFor(ctx, dir*g.rpm, 0, nil); err != nil {
 		return err
 	}
@@ -323,49 +346,71 @@ func (g *singleAxis) moveAway(ctx context.Context, pin int) error {
 
 // doHome is a helper function that runs the actual homing sequence.
 func (g *singleAxis) doHome(ctx context.Context) (bool, error) {
-	np := len(g.limitSwitchPins)
 	ctx, done := g.opMgr.New(ctx)
 	defer done()
 
-	switch np {
+	var err error
+	switch g.resolvedStrategy() {
 	// An axis with an encoder will encode the zero position, and add the second position limit
 	// based on the steps per length
-	case 0:
-		if err := g.homeEncoder(ctx); err != nil {
-			return false, err
-		}
+	case homingStrategyEncoder:
+		g.setHomingStage("reading encoder position")
+		err = g.homeEncoder(ctx)
 	// An axis with one limit switch will go till it hits the limit switch, encode that position as the
 	// zero position of the singleAxis, and adds a second position limit based on the steps per length.
 	// An axis with two limit switches will go till it hits the first limit switch, encode that position as the
 	// zero position of the singleAxis, then go till it hits the second limit switch, then encode that position as the
 	// at-length position of the singleAxis.
-	case 1, 2:
-		if err := g.homeLimSwitch(ctx); err != nil {
-			return false, err
-		}
+	case homingStrategyLimitSwitch:
+		err = g.homeLimSwitch(ctx)
+	// An axis homed by stalling will go till the motor stops moving against the end of travel, and treat it
+	// like a single limit switch.
+	case homingStrategyStall:
+		err = g.homeStall(ctx)
+	}
+	if err != nil {
+		g.homingProgress.err = err
+		return false, err
 	}
 
+	g.homingProgress = homingProgress{stage: "homed"}
 	return true, nil
 }
 
 func (g *singleAxis) homeLimSwitch(ctx context.Context) error {
-	var positionA, positionB float64
-	positionA, err := g.testLimit(ctx, 0)
-	if err != nil {
-		return err
+	if g.homingProgress.foundLimits == nil {
+		g.homingProgress.foundLimits = map[int]float64{}
 	}
-
-	if len(g.limitSwitchPins) > 1 {
-		// Multiple limit switches, get positionB from testLimit
-		positionB, err = g.testLimit(ctx, 1)
+	for _, pin := range g.limitOrder() {
+		if _, ok := g.homingProgress.foundLimits[pin]; ok {
+			continue
+		}
+		g.setHomingStage(fmt.Sprintf("finding limit switch %s", g.limitSwitchPins[pin]))
+		position, err := g.testLimit(ctx, pin)
 		if err != nil {
 			return err
 		}
+		g.homingProgress.foundLimits[pin] = position
+		if err := g.backOff(ctx, g.limitDirection(pin)); err != nil {
+			return err
+		}
+	}
+
+	var positionA, positionB float64
+	if len(g.limitSwitchPins) > 1 {
+		positionA, positionB = g.homingProgress.foundLimits[0], g.homingProgress.foundLimits[1]
 	} else {
-		// Only one limit switch, calculate positionB
+		// Only one limit switch, calculate the other limit
 		revPerLength := g.lengthMm / g.mmPerRevolution
-		positionB = positionA + revPerLength
+		if g.homing.positive {
+			positionB = g.homingProgress.foundLimits[0]
+			positionA = positionB - revPerLength
+		} else {
+			positionA = g.homingProgress.foundLimits[0]
+			positionB = positionA + revPerLength
+		}
 	}
+	g.homingProgress.foundLimits = nil
 
 	g.positionLimits = []float64{positionA, positionB}
 	g.positionRange = positionB - positionA
@@ -376,6 +421,7 @@ func (g *singleAxis) homeLimSwitch(ctx context.Context) error {
 	}
 
 	// Go to start position at the middle of the axis.
+	g.setHomingStage("moving to the middle of the axis")
 	x := g.gantryToMotorPosition(0.5 * g.lengthMm)
 	if err := g.motor.GoTo(ctx, g.rpm, x, nil); err != nil {
 		return err
@@ -398,6 +444,9 @@ func (g *singleAxis) homeEncoder(ctx context.Context) error {
 	positionB := positionA + revPerLength
 
 	g.positionLimits = []float64{positionA, positionB}
+	// like the other homing strategies, encoder homing sets the range of the axis, which marks it homed and which
+	// MoveToPosition and Position scale motor positions by
+	g.positionRange = revPerLength
 	return nil
 }
 
@@ -417,13 +466,12 @@ func (g *singleAxis) testLimit(ctx context.Context, pin int) (float64, error) {
 		return g.motor.Stop(ctx, nil)
 	})
 	wrongPin := 1
-	d := -1.0
 	if pin != 0 {
-		d = 1
 		wrongPin = 0
 	}
+	d := g.limitDirection(pin)
 
-	err := g.motor.GoFor(ctx, d*g.rpm, 0, nil)
+	err := g.motor.GoFor(ctx, d*g.homingRPM(), 0, nil)
 	if err != nil {
 		return 0, err
 	}
@@ -446,9 +494,12 @@ func (g *singleAxis) testLimit(ctx context.Context, pin int) (float64, error) {
 		}
 
 		// check if the wrong limit switch was hit
-		wrongHit, err := g.limitHit(ctx, wrongPin)
-		if err != nil {
-			return 0, err
+		wrongHit := false
+		if len(g.limitSwitchPins) > 1 {
+			wrongHit, err = g.limitHit(ctx, wrongPin)
+			if err != nil {
+				return 0, err
+			}
 		}
 		if wrongHit {
 			err = g.motor.Stop(ctx, nil)
@@ -464,8 +515,8 @@ func (g *singleAxis) testLimit(ctx context.Context, pin int) (float64, error) {
 		elapsed := time.Since(start)
 		// if the parameters checked are non-zero, calculate a timeout with a safety factor of
 		// 5 to complete the gantry's homing sequence to find the limit switches
-		if g.mmPerRevolution != 0 && g.rpm != 0 && g.lengthMm != 0 {
-			homingTimeout = time.Duration((1 / (g.rpm / 60e9 * g.mmPerRevolution / g.lengthMm) * 5))
+		if g.mmPerRevolution != 0 && g.homingRPM() != 0 && g.lengthMm != 0 {
+			homingTimeout = time.Duration((1 / (g.homingRPM() / 60e9 * g.mmPerRevolution / g.lengthMm) * 5))
 		}
 		if elapsed > (homingTimeout) {
 			return 0, errors.Errorf("gantry timed out testing limit, timeout = %v", homingTimeout)
@@ -560,6 +611,15 @@ func (g *singleAxis) MoveToPosition(ctx context.Context, positions, speeds []flo
 		}
 	}
 
+	// the start of the move is only needed to report its progress to the operations API
+	if operation.Get(ctx) != nil {
+		start, err := g.motor.Position(ctx, extra)
+		if err != nil {
+			return err
+		}
+		defer operation.TrackProgress(ctx, motorMoveProgress(g.motor, start, x, r))()
+	}
+
 	g.logger.CDebugf(ctx, "going to %.2f at speed %.2f", x, r)
 	if err := g.motor.GoTo(ctx, r, x, extra); err != nil {
 		return err
@@ -567,6 +627,25 @@ func (g *singleAxis) MoveToPosition(ctx context.Context, positions, speeds []flo
 	return nil
 }
 
+// motorMoveProgress reports the progress of a motor going from start to target at rpm, measured by its position.
+func motorMoveProgress(m motor.Motor, start, target, rpm float64) operation.ProgressFunc {
+	return func(ctx context.Context) (operation.Progress, error) {
+		pos, err := m.Position(ctx, nil)
+		if err != nil {
+			return operation.Progress{}, err
+		}
+		total, left := math.Abs(target-start), math.Abs(target-pos)
+		if total == 0 {
+			return operation.Progress{Percent: 100}, nil
+		}
+		p := operation.Progress{Percent: 100 * math.Max(0, math.Min(1, 1-left/total))}
+		if rpm != 0 {
+			p.Remaining = time.Duration(left / math.Abs(rpm) * float64(time.Minute))
+		}
+		return p, nil
+	}
+}
+
 // Stop stops the motor of the gantry.
 func (g *singleAxis) Stop(ctx context.Context, extra map[string]interface{}) error {
 	ctx, done := g.opMgr.New(ctx)
@@ -574,6 +653,22 @@ func (g *singleAxis) Stop(ctx context.Context, extra map[string]interface{}) err
 	return g.motor.Stop(ctx, extra)
 }
 
+// DoCommand supports reporting how far homing has got with {"command": "get_homing_status"}.
+func (g *singleAxis) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case "get_homing_status":
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		return g.homingStatus(), nil
+	default:
+		return nil, errors.Errorf("no such command: %s", name)
+	}
+}
+
 // Close calls stop.
 func (g *singleAxis) Close(ctx context.Context) error {
 	g.mu.Lock()
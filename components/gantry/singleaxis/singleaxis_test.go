@@ -4,6 +4,7 @@ import (
 	"context"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/golang/geo/r3"
 	"github.com/pkg/errors"
@@ -112,6 +113,26 @@ func TestValidate(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, deps, test.ShouldResemble, []string{fakecfg.Motor, fakecfg.Board})
 	test.That(t, fakecfg.GantryMmPerSec, test.ShouldEqual, float64(0))
+
+	fakecfg.Homing = &HomingConfig{Strategy: "bump"}
+	_, err = fakecfg.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, `unknown homing strategy "bump"`)
+
+	fakecfg.Homing = &HomingConfig{Strategy: homingStrategyStall, Direction: "up"}
+	_, err = fakecfg.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, `unknown homing direction "up"`)
+
+	fakecfg.Homing = &HomingConfig{Strategy: homingStrategyStall, BackoffMm: -1}
+	_, err = fakecfg.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "may not be negative")
+
+	fakecfg.Homing = &HomingConfig{Strategy: homingStrategyLimitSwitch, Direction: homingDirectionPositive, BackoffMm: 1}
+	_, err = fakecfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	fakecfg.LimitSwitchPins = nil
+	_, err = fakecfg.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "limit_switch homing requires limit_pins")
 }
 
 func TestNewSingleAxis(t *testing.T) {
@@ -526,9 +547,148 @@ func TestHomeEncoder(t *testing.T) {
 	err := fakegantry.homeEncoder(ctx)
 	test.That(t, err.Error(), test.ShouldContainSubstring, "get position")
 
-	injMotor.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) { return 0, nil }
+	fakegantry.lengthMm = 100
+	fakegantry.mmPerRevolution = 10
+	injMotor.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) { return 3, nil }
 	err = fakegantry.homeEncoder(ctx)
 	test.That(t, err, test.ShouldBeNil)
+	// the axis is homed with its range starting at the current position
+	test.That(t, fakegantry.positionLimits, test.ShouldResemble, []float64{3, 13})
+	test.That(t, fakegantry.positionRange, test.ShouldEqual, 10)
+	test.That(t, fakegantry.homingStatus()["homed"], test.ShouldBeTrue)
+}
+
+func TestHomeStall(t *testing.T) {
+	ctx := context.Background()
+	position := 0.
+	var goFors [][]float64
+	injMotor := &inject.Motor{
+		PositionFunc: func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+			// the motor stalls against the end of travel at 5 revolutions
+			if position < 5 {
+				position++
+			}
+			return position, nil
+		},
+		GoForFunc: func(ctx context.Context, rpm, revolutions float64, extra map[string]interface{}) error {
+			goFors = append(goFors, []float64{rpm, revolutions})
+			return nil
+		},
+		GoToFunc: func(ctx context.Context, rpm, position float64, extra map[string]interface{}) error { return nil },
+		StopFunc: func(ctx context.Context, extra map[string]interface{}) error { return nil },
+	}
+	fakegantry := &singleAxis{
+		motor:           injMotor,
+		rpm:             float64(300),
+		lengthMm:        float64(10),
+		mmPerRevolution: float64(1),
+		homing: homingSettings{
+			strategy:    homingStrategyStall,
+			positive:    true,
+			backoffMm:   2,
+			rpm:         60,
+			stallWindow: time.Millisecond,
+			stallRamp:   time.Millisecond,
+		},
+		opMgr: operation.NewSingleOperationManager(),
+	}
+
+	homed, err := fakegantry.doHome(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, homed, test.ShouldBeTrue)
+	test.That(t, fakegantry.positionLimits, test.ShouldResemble, []float64{-5, 5})
+	test.That(t, fakegantry.positionRange, test.ShouldEqual, 10)
+	// drives towards the positive end at the homing speed, then backs off 2mm
+	test.That(t, goFors, test.ShouldResemble, [][]float64{{60, 0}, {-60, 2}})
+	test.That(t, fakegantry.homingStatus()["stage"], test.ShouldEqual, "homed")
+
+	posErr := errors.New("failed to get position")
+	injMotor.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) { return 0, posErr }
+	homed, err = fakegantry.doHome(ctx)
+	test.That(t, err, test.ShouldBeError, posErr)
+	test.That(t, homed, test.ShouldBeFalse)
+	status := fakegantry.homingStatus()
+	test.That(t, status["stage"], test.ShouldEqual, "finding end of travel by stalling")
+	test.That(t, status["error"], test.ShouldEqual, posErr.Error())
+
+	// a motor which barely moves while it accelerates has not stalled
+	var started time.Time
+	position = 0
+	injMotor.GoForFunc = func(ctx context.Context, rpm, revolutions float64, extra map[string]interface{}) error {
+		started = time.Now()
+		return nil
+	}
+	injMotor.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+		if time.Since(started) > 20*time.Millisecond && position < 5 {
+			position++
+		}
+		return position, nil
+	}
+	fakegantry.homing.stallRamp = 50 * time.Millisecond
+	stalledAt, err := fakegantry.findStall(ctx, 1)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stalledAt, test.ShouldEqual, 5)
+}
+
+func TestHomeResume(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	pinErr := errors.New("pin read failed")
+	var failPin2 bool
+	var pinsRead []string
+	fakeBoard := &inject.Board{GPIOPinByNameFunc: func(pin string) (board.GPIOPin, error) {
+		return &inject.GPIOPin{GetFunc: func(ctx context.Context, extra map[string]interface{}) (bool, error) {
+			pinsRead = append(pinsRead, pin)
+			if pin == "2" && failPin2 {
+				return false, pinErr
+			}
+			return true, nil
+		}}, nil
+	}}
+	fakegantry := &singleAxis{
+		motor:           createFakeMotor(),
+		board:           fakeBoard,
+		limitHigh:       true,
+		logger:          logger,
+		rpm:             float64(300),
+		limitSwitchPins: []string{"1", "2"},
+		lengthMm:        float64(1),
+		mmPerRevolution: float64(.1),
+		homing:          homingSettings{positive: true},
+		opMgr:           operation.NewSingleOperationManager(),
+	}
+	test.That(t, fakegantry.limitOrder(), test.ShouldResemble, []int{1, 0})
+
+	// the negative limit is searched for first, so it is found before the positive one fails
+	fakegantry.homing.positive = false
+	failPin2 = true
+	homed, err := fakegantry.Home(ctx, nil)
+	test.That(t, err, test.ShouldBeError, pinErr)
+	test.That(t, homed, test.ShouldBeFalse)
+	status, err := fakegantry.DoCommand(ctx, map[string]interface{}{"command": "get_homing_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["homed"], test.ShouldBeFalse)
+	test.That(t, status["strategy"], test.ShouldEqual, homingStrategyLimitSwitch)
+	test.That(t, status["found_limits"], test.ShouldResemble, map[string]interface{}{"1": float64(1)})
+	test.That(t, status["error"], test.ShouldEqual, pinErr.Error())
+
+	// resuming only searches for the limit which was not found
+	failPin2 = false
+	pinsRead = nil
+	homed, err = fakegantry.Home(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, homed, test.ShouldBeTrue)
+	fakegantry.cancelFunc()
+	fakegantry.activeBackgroundWorkers.Wait()
+	test.That(t, pinsRead[0], test.ShouldEqual, "2")
+	test.That(t, fakegantry.positionLimits, test.ShouldResemble, []float64{1, 1})
+	status, err = fakegantry.DoCommand(ctx, map[string]interface{}{"command": "get_homing_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["stage"], test.ShouldEqual, "homed")
+	test.That(t, status["found_limits"], test.ShouldBeNil)
+
+	_, err = fakegantry.DoCommand(ctx, map[string]interface{}{"command": "bad"})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no such command")
 }
 
 func TestTestLimit(t *testing.T) {
@@ -645,6 +805,25 @@ func TestLengths(t *testing.T) {
 	test.ShouldHaveLength(t, fakelengths, test.ShouldEqual(float64(1.0)))
 }
 
+func TestMotorMoveProgress(t *testing.T) {
+	ctx := context.Background()
+	position := 4.
+	m := &inject.Motor{
+		PositionFunc: func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+			return position, nil
+		},
+	}
+	// a quarter of the way from 2 to 10 revolutions, with 6 left at 60 rpm
+	progress, err := motorMoveProgress(m, 2, 10, 60)(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, progress, test.ShouldResemble, operation.Progress{Percent: 25, Remaining: 6 * time.Second})
+
+	position = 10
+	progress, err = motorMoveProgress(m, 2, 10, 60)(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, progress, test.ShouldResemble, operation.Progress{Percent: 100})
+}
+
 func TestMoveToPosition(t *testing.T) {
 	ctx := context.Background()
 	logger := logging.NewTestLogger(t)
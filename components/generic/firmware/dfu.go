@@ -0,0 +1,241 @@
+package firmware
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+)
+
+// Requests of the USB DFU 1.1 class.
+const (
+	dfuDnload    = 1
+	dfuUpload    = 2
+	dfuGetStatus = 3
+	dfuClrStatus = 4
+	dfuAbort     = 6
+)
+
+// States of a DFU device.
+const (
+	dfuStateIdle       = 2
+	dfuStateDnloadBusy = 4
+	dfuStateDnloadIdle = 5
+	dfuStateManifest   = 7
+	dfuStateError      = 10
+)
+
+// Commands of the STM32 DfuSe extension, sent as downloads to block 0.
+const (
+	dfuseSetAddress = 0x21
+	dfuseErase      = 0x41
+)
+
+const (
+	// dfuDefaultTransferSize is the wTransferSize of the STM32 system bootloader.
+	dfuDefaultTransferSize = 2048
+	// dfuDefaultErasePageSize is the smallest flash page of any STM32. Parts with larger pages or sectors erase the
+	// same one more than once, which is slower but still correct.
+	dfuDefaultErasePageSize = 1024
+	dfuDefaultFlashAddress  = 0x08000000
+	dfuStatusTimeout        = 10 * time.Second
+)
+
+// dfuDevice carries DFU class requests to the DFU interface of a USB device.
+type dfuDevice interface {
+	controlOut(ctx context.Context, request uint8, value uint16, data []byte) error
+	controlIn(ctx context.Context, request uint8, value uint16, length int) ([]byte, error)
+	Close() error
+}
+
+type dfuStatus struct {
+	status      byte
+	pollTimeout time.Duration
+	state       byte
+}
+
+func getDFUStatus(ctx context.Context, dev dfuDevice) (dfuStatus, error) {
+	resp, err := dev.controlIn(ctx, dfuGetStatus, 0, 6)
+	if err != nil {
+		return dfuStatus{}, err
+	}
+	if len(resp) < 6 {
+		return dfuStatus{}, errors.New("dfu status response is too short")
+	}
+	poll := uint32(resp[1]) | uint32(resp[2])<<8 | uint32(resp[3])<<16
+	return dfuStatus{
+		status:      resp[0],
+		pollTimeout: time.Duration(poll) * time.Millisecond,
+		state:       resp[4],
+	}, nil
+}
+
+// dfuIdle brings the device back to the idle state from wherever a previous session left it.
+func dfuIdle(ctx context.Context, dev dfuDevice) error {
+	status, err := getDFUStatus(ctx, dev)
+	if err != nil {
+		return err
+	}
+	switch status.state {
+	case dfuStateIdle:
+		return nil
+	case dfuStateError:
+		if err := dev.controlOut(ctx, dfuClrStatus, 0, nil); err != nil {
+			return err
+		}
+	default:
+		if err := dev.controlOut(ctx, dfuAbort, 0, nil); err != nil {
+			return err
+		}
+	}
+	status, err = getDFUStatus(ctx, dev)
+	if err != nil {
+		return err
+	}
+	if status.state != dfuStateIdle {
+		return errors.Errorf("dfu device did not become idle, it is in state %d", status.state)
+	}
+	return nil
+}
+
+// dfuDownload sends a block to the device and waits until the device has finished with it.
+func dfuDownload(ctx context.Context, dev dfuDevice, block uint16, data []byte) error {
+	if err := dev.controlOut(ctx, dfuDnload, block, data); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(dfuStatusTimeout)
+	for {
+		// the first status request after a download is what starts the device working on it
+		status, err := getDFUStatus(ctx, dev)
+		if err != nil {
+			return err
+		}
+		if status.status != 0 {
+			return errors.Errorf("dfu device reported error status %d in state %d", status.status, status.state)
+		}
+		if status.state == dfuStateDnloadIdle || status.state == dfuStateIdle {
+			return nil
+		}
+		if status.state != dfuStateDnloadBusy && status.state != dfuStateManifest {
+			return errors.Errorf("unexpected dfu state %d", status.state)
+		}
+		if time.Now().After(deadline) {
+			return errors.New("timed out waiting for dfu device")
+		}
+		if !utils.SelectContextOrWait(ctx, status.pollTimeout) {
+			return ctx.Err()
+		}
+	}
+}
+
+func dfuseCommand(ctx context.Context, dev dfuDevice, command byte, address uint32) error {
+	data := make([]byte, 5)
+	data[0] = command
+	binary.LittleEndian.PutUint32(data[1:], address)
+	return dfuDownload(ctx, dev, 0, data)
+}
+
+// stm32Options are the settings used to flash an STM32.
+type stm32Options struct {
+	address       uint32
+	transferSize  int
+	erasePageSize int
+	verify        bool
+	// reboot leaves DFU mode to run the new firmware once flashing is done.
+	reboot bool
+}
+
+// flashSTM32 writes image to the flash of an STM32 at opts.address using the DfuSe protocol of its system bootloader,
+// then reads the flash back and compares it to the image if opts.verify is set.
+func flashSTM32(ctx context.Context, dev dfuDevice, image []byte, opts stm32Options, progress progressFunc) error {
+	progress(stageConnecting, 0, len(image))
+	if err := dfuIdle(ctx, dev); err != nil {
+		return errors.Wrap(err, "failed to reset dfu device")
+	}
+
+	// every page is erased before writing, since erasing a larger sector would otherwise undo earlier writes to it
+	progress(stageErasing, 0, len(image))
+	firstPage := opts.address - opts.address%uint32(opts.erasePageSize)
+	for page := firstPage; page < opts.address+uint32(len(image)); page += uint32(opts.erasePageSize) {
+		if err := dfuseCommand(ctx, dev, dfuseErase, page); err != nil {
+			return errors.Wrapf(err, "failed to erase page at %#x", page)
+		}
+		progress(stageErasing, min(int(page)-int(opts.address)+opts.erasePageSize, len(image)), len(image))
+	}
+
+	if err := dfuseCommand(ctx, dev, dfuseSetAddress, opts.address); err != nil {
+		return errors.Wrap(err, "failed to set dfu address")
+	}
+	for i := 0; i*opts.transferSize < len(image); i++ {
+		chunk := image[i*opts.transferSize : min((i+1)*opts.transferSize, len(image))]
+		// blocks 0 and 1 are reserved for DfuSe commands, so data starts at block 2
+		if err := dfuDownload(ctx, dev, uint16(i+2), chunk); err != nil {
+			return errors.Wrapf(err, "failed to write flash at %#x", opts.address+uint32(i*opts.transferSize))
+		}
+		progress(stageWriting, i*opts.transferSize+len(chunk), len(image))
+	}
+
+	if opts.verify {
+		progress(stageVerifying, 0, len(image))
+		if err := verifySTM32(ctx, dev, image, opts, progress); err != nil {
+			return err
+		}
+	}
+
+	if opts.reboot {
+		if err := dfuseCommand(ctx, dev, dfuseSetAddress, opts.address); err != nil {
+			return errors.Wrap(err, "failed to set dfu address")
+		}
+		// a zero length download tells the bootloader to leave DFU mode and jump to the address once it is asked
+		// for its status. The device resets while handling it, so it may not respond.
+		if err := dev.controlOut(ctx, dfuDnload, 2, nil); err == nil {
+			utils.UncheckedErrorFunc(func() error {
+				_, err := getDFUStatus(ctx, dev)
+				return err
+			})
+		}
+	}
+	return nil
+}
+
+func verifySTM32(ctx context.Context, dev dfuDevice, image []byte, opts stm32Options, progress progressFunc) error {
+	if err := dfuseCommand(ctx, dev, dfuseSetAddress, opts.address); err != nil {
+		return errors.Wrap(err, "failed to set dfu address")
+	}
+	// uploads can only start from the idle state
+	if err := dfuIdle(ctx, dev); err != nil {
+		return err
+	}
+	read := make([]byte, 0, len(image))
+	for i := 0; len(read) < len(image); i++ {
+		length := min(opts.transferSize, len(image)-len(read))
+		data, err := dev.controlIn(ctx, dfuUpload, uint16(i+2), length)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read back flash at %#x", opts.address+uint32(len(read)))
+		}
+		if len(data) == 0 {
+			return errors.Errorf("flash ended while reading back at %#x", opts.address+uint32(len(read)))
+		}
+		read = append(read, data...)
+		progress(stageVerifying, len(read), len(image))
+	}
+	if idx := firstDifference(read, image); idx != -1 {
+		return errors.Errorf("stm32 flash verification failed at %#x", opts.address+uint32(idx))
+	}
+	return dev.controlOut(ctx, dfuAbort, 0, nil)
+}
+
+func firstDifference(a, b []byte) int {
+	if bytes.Equal(a, b) {
+		return -1
+	}
+	for i := range a {
+		if i >= len(b) || a[i] != b[i] {
+			return i
+		}
+	}
+	return len(a)
+}
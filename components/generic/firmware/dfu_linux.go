@@ -0,0 +1,160 @@
+//go:build linux
+
+package firmware
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+	"golang.org/x/sys/unix"
+)
+
+// usbfsCtrlTransfer mirrors struct usbdevfs_ctrltransfer from linux/usbdevice_fs.h.
+type usbfsCtrlTransfer struct {
+	requestType uint8
+	request     uint8
+	value       uint16
+	index       uint16
+	length      uint16
+	timeoutMs   uint32
+	data        unsafe.Pointer
+}
+
+// ioctl numbers of usbfs, as built by the _IOR and _IOWR macros.
+const (
+	usbdevfsControl          = 0xC0005500 | uintptr(unsafe.Sizeof(usbfsCtrlTransfer{}))<<16
+	usbdevfsSetInterface     = 0x80085504
+	usbdevfsClaimInterface   = 0x8004550F
+	usbdevfsReleaseInterface = 0x80045510
+
+	usbRequestTypeClassOut = 0x21
+	usbRequestTypeClassIn  = 0xA1
+	usbControlTimeoutMs    = 5000
+)
+
+// usbfsDFUDevice talks to the DFU interface of a USB device through linux usbfs, so no USB library is required.
+type usbfsDFUDevice struct {
+	file      *os.File
+	iface     uint32
+	ifaceHeld bool
+}
+
+// openDFUDevice opens the first USB device with the given vendor and product id, and serial number if it is not
+// empty, and claims its DFU interface.
+func openDFUDevice(vendorID, productID uint16, serial string) (dfuDevice, error) {
+	devPath, err := findUSBDevice(vendorID, productID, serial)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(filepath.Clean(devPath), os.O_RDWR, 0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open usb device %s", devPath)
+	}
+	dev := &usbfsDFUDevice{file: file}
+	iface := dev.iface
+	if err := dev.ioctl(usbdevfsClaimInterface, unsafe.Pointer(&iface)); err != nil {
+		return nil, multierr.Combine(errors.Wrap(err, "failed to claim dfu interface"), dev.Close())
+	}
+	dev.ifaceHeld = true
+	// alternate setting 0 of the STM32 bootloader is its internal flash
+	setting := [2]uint32{dev.iface, 0}
+	if err := dev.ioctl(usbdevfsSetInterface, unsafe.Pointer(&setting)); err != nil {
+		return nil, multierr.Combine(errors.Wrap(err, "failed to select dfu alternate setting"), dev.Close())
+	}
+	return dev, nil
+}
+
+// findUSBDevice returns the usbfs path of a matching device by looking through sysfs.
+func findUSBDevice(vendorID, productID uint16, serial string) (string, error) {
+	devices, err := filepath.Glob("/sys/bus/usb/devices/*")
+	if err != nil {
+		return "", err
+	}
+	readAttr := func(dir, name string) string {
+		data, err := os.ReadFile(filepath.Clean(filepath.Join(dir, name)))
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(data))
+	}
+	for _, dir := range devices {
+		if readAttr(dir, "idVendor") != fmt.Sprintf("%04x", vendorID) ||
+			readAttr(dir, "idProduct") != fmt.Sprintf("%04x", productID) {
+			continue
+		}
+		if serial != "" && readAttr(dir, "serial") != serial {
+			continue
+		}
+		bus, err := strconv.Atoi(readAttr(dir, "busnum"))
+		if err != nil {
+			continue
+		}
+		dev, err := strconv.Atoi(readAttr(dir, "devnum"))
+		if err != nil {
+			continue
+		}
+		return fmt.Sprintf("/dev/bus/usb/%03d/%03d", bus, dev), nil
+	}
+	return "", errors.Errorf("no usb device %04x:%04x found, is it in dfu mode?", vendorID, productID)
+}
+
+func (d *usbfsDFUDevice) ioctl(req uintptr, arg unsafe.Pointer) error {
+	_, err := d.ioctlN(req, arg)
+	return err
+}
+
+func (d *usbfsDFUDevice) ioctlN(req uintptr, arg unsafe.Pointer) (int, error) {
+	n, _, errno := unix.Syscall(unix.SYS_IOCTL, d.file.Fd(), req, uintptr(arg))
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(n), nil
+}
+
+func (d *usbfsDFUDevice) control(requestType, request uint8, value uint16, data []byte) (int, error) {
+	ctrl := usbfsCtrlTransfer{
+		requestType: requestType,
+		request:     request,
+		value:       value,
+		index:       uint16(d.iface),
+		length:      uint16(len(data)),
+		timeoutMs:   usbControlTimeoutMs,
+	}
+	if len(data) > 0 {
+		ctrl.data = unsafe.Pointer(&data[0])
+	}
+	n, err := d.ioctlN(usbdevfsControl, unsafe.Pointer(&ctrl))
+	runtime.KeepAlive(data)
+	return n, err
+}
+
+func (d *usbfsDFUDevice) controlOut(ctx context.Context, request uint8, value uint16, data []byte) error {
+	_, err := d.control(usbRequestTypeClassOut, request, value, data)
+	return err
+}
+
+func (d *usbfsDFUDevice) controlIn(ctx context.Context, request uint8, value uint16, length int) ([]byte, error) {
+	data := make([]byte, length)
+	n, err := d.control(usbRequestTypeClassIn, request, value, data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (d *usbfsDFUDevice) Close() error {
+	if d.ifaceHeld {
+		iface := d.iface
+		utils.UncheckedError(d.ioctl(usbdevfsReleaseInterface, unsafe.Pointer(&iface)))
+	}
+	return d.file.Close()
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package firmware
+
+import "github.com/pkg/errors"
+
+func openDFUDevice(vendorID, productID uint16, serial string) (dfuDevice, error) {
+	return nil, errors.New("flashing over dfu is only supported on linux")
+}
@@ -0,0 +1,327 @@
+package firmware
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+)
+
+// Commands of the ESP32 ROM serial bootloader, as used by esptool.
+const (
+	espFlashBegin   = 0x02
+	espFlashData    = 0x03
+	espFlashEnd     = 0x04
+	espSync         = 0x08
+	espSPISetParams = 0x0B
+	espSPIAttach    = 0x0D
+	espSPIFlashMD5  = 0x13
+)
+
+const (
+	slipEnd    = 0xC0
+	slipEsc    = 0xDB
+	slipEscEnd = 0xDC
+	slipEscEsc = 0xDD
+
+	// espFlashBlockSize is the largest block the ROM bootloader accepts in a single FLASH_DATA command.
+	espFlashBlockSize = 0x400
+	// espStatusLen is the length of the status which ends every response of the ESP32 ROM bootloader.
+	espStatusLen   = 4
+	espChecksumKey = 0xEF
+
+	espDefaultTimeout = 3 * time.Second
+	espSyncTimeout    = 100 * time.Millisecond
+	espSyncAttempts   = 10
+	// erasing and hashing flash take time in proportion to the amount of flash involved.
+	espEraseTimeoutPerMB = 30 * time.Second
+	espMD5TimeoutPerMB   = 8 * time.Second
+)
+
+// espResponse is a response to a command sent to the bootloader.
+type espResponse struct {
+	command byte
+	data    []byte
+}
+
+// esptoolConn speaks the esptool serial protocol with an ESP32 in its ROM bootloader. Frames are read in the
+// background so that waiting for a response can time out even if reads from the port block.
+type esptoolConn struct {
+	port                    io.ReadWriteCloser
+	responses               chan espResponse
+	closed                  chan struct{}
+	readErr                 error
+	readErrMu               sync.Mutex
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+func newEsptoolConn(port io.ReadWriteCloser) *esptoolConn {
+	conn := &esptoolConn{port: port, responses: make(chan espResponse, 16), closed: make(chan struct{})}
+	conn.activeBackgroundWorkers.Add(1)
+	utils.PanicCapturingGo(func() {
+		defer conn.activeBackgroundWorkers.Done()
+		defer close(conn.responses)
+		conn.readFrames()
+	})
+	return conn
+}
+
+// readFrames decodes SLIP frames from the port until it fails, forwarding every well formed response.
+func (c *esptoolConn) readFrames() {
+	buf := make([]byte, 256)
+	var frame []byte
+	inFrame, escaped := false, false
+	for {
+		n, err := c.port.Read(buf)
+		for _, b := range buf[:n] {
+			switch {
+			case !inFrame:
+				// bytes between frames, like boot messages, are ignored
+				if b == slipEnd {
+					inFrame, frame = true, nil
+				}
+			case escaped:
+				escaped = false
+				switch b {
+				case slipEscEnd:
+					frame = append(frame, slipEnd)
+				case slipEscEsc:
+					frame = append(frame, slipEsc)
+				default:
+					inFrame = false
+				}
+			case b == slipEsc:
+				escaped = true
+			case b == slipEnd:
+				if len(frame) == 0 {
+					// two frame delimiters in a row, this one starts the next frame
+					continue
+				}
+				inFrame = false
+				if resp, ok := parseEspResponse(frame); ok {
+					// nothing is waiting on responses which arrive once the buffer is full
+					select {
+					case c.responses <- resp:
+					default:
+					}
+				}
+			default:
+				frame = append(frame, b)
+			}
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			c.readErrMu.Lock()
+			c.readErr = err
+			c.readErrMu.Unlock()
+			return
+		}
+		if errors.Is(err, io.EOF) && n == 0 {
+			// serial ports with a read timeout return EOF when nothing arrived in time
+			select {
+			case <-c.closed:
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}
+}
+
+func parseEspResponse(frame []byte) (espResponse, bool) {
+	if len(frame) < 8 || frame[0] != 0x01 {
+		return espResponse{}, false
+	}
+	size := int(binary.LittleEndian.Uint16(frame[2:4]))
+	if len(frame) < 8+size {
+		return espResponse{}, false
+	}
+	return espResponse{
+		command: frame[1],
+		data:    frame[8 : 8+size],
+	}, true
+}
+
+func slipEncode(packet []byte) []byte {
+	out := []byte{slipEnd}
+	for _, b := range packet {
+		switch b {
+		case slipEnd:
+			out = append(out, slipEsc, slipEscEnd)
+		case slipEsc:
+			out = append(out, slipEsc, slipEscEsc)
+		default:
+			out = append(out, b)
+		}
+	}
+	return append(out, slipEnd)
+}
+
+func espChecksum(data []byte) uint32 {
+	checksum := uint32(espChecksumKey)
+	for _, b := range data {
+		checksum ^= uint32(b)
+	}
+	return checksum
+}
+
+func packWords(words ...uint32) []byte {
+	out := make([]byte, 4*len(words))
+	for i, w := range words {
+		binary.LittleEndian.PutUint32(out[4*i:], w)
+	}
+	return out
+}
+
+// command sends a command to the bootloader and waits up to timeout for its response, returning the response data
+// without its status.
+func (c *esptoolConn) command(ctx context.Context, op byte, data []byte, checksum uint32, timeout time.Duration) ([]byte, error) {
+	packet := make([]byte, 8, 8+len(data))
+	packet[1] = op
+	binary.LittleEndian.PutUint16(packet[2:4], uint16(len(data)))
+	binary.LittleEndian.PutUint32(packet[4:8], checksum)
+	packet = append(packet, data...)
+	if _, err := c.port.Write(slipEncode(packet)); err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			return nil, errors.Errorf("timed out waiting for response to esp32 command %#x", op)
+		case resp, ok := <-c.responses:
+			if !ok {
+				c.readErrMu.Lock()
+				defer c.readErrMu.Unlock()
+				return nil, errors.Wrap(c.readErr, "esp32 serial port closed")
+			}
+			// responses to earlier commands, such as repeated syncs, are skipped
+			if resp.command != op {
+				continue
+			}
+			if len(resp.data) < espStatusLen {
+				return nil, errors.Errorf("esp32 response to command %#x is too short", op)
+			}
+			status := resp.data[len(resp.data)-espStatusLen:]
+			if status[0] != 0 {
+				return nil, errors.Errorf("esp32 command %#x failed with error %#x", op, status[1])
+			}
+			return resp.data[:len(resp.data)-espStatusLen], nil
+		}
+	}
+}
+
+// sync establishes communication with the bootloader, which also lets it detect the baud rate.
+func (c *esptoolConn) sync(ctx context.Context) error {
+	data := append([]byte{0x07, 0x07, 0x12, 0x20}, bytes.Repeat([]byte{0x55}, 32)...)
+	var err error
+	for i := 0; i < espSyncAttempts; i++ {
+		if _, err = c.command(ctx, espSync, data, 0, espSyncTimeout); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return errors.Wrap(err, "failed to sync with esp32 bootloader, is it in download mode?")
+}
+
+// Close closes the serial port and waits for the reader to stop.
+func (c *esptoolConn) Close() error {
+	close(c.closed)
+	err := c.port.Close()
+	c.activeBackgroundWorkers.Wait()
+	return err
+}
+
+// esp32Options are the settings used to flash an ESP32.
+type esp32Options struct {
+	offset         uint32
+	flashSizeBytes uint32
+	verify         bool
+	// reboot runs the new firmware once flashing is done.
+	reboot bool
+}
+
+func timeoutPerMB(perMB time.Duration, size int) time.Duration {
+	timeout := time.Duration(float64(perMB) * float64(size) / 1e6)
+	if timeout < espDefaultTimeout {
+		return espDefaultTimeout
+	}
+	return timeout
+}
+
+// flashESP32 writes image to the flash of an ESP32 at opts.offset, then checks the MD5 of the flashed region against
+// the image if opts.verify is set.
+func flashESP32(ctx context.Context, c *esptoolConn, image []byte, opts esp32Options, progress progressFunc) error {
+	progress(stageConnecting, 0, len(image))
+	if err := c.sync(ctx); err != nil {
+		return err
+	}
+	if _, err := c.command(ctx, espSPIAttach, make([]byte, 8), 0, espDefaultTimeout); err != nil {
+		return errors.Wrap(err, "failed to attach esp32 flash")
+	}
+	if _, err := c.command(ctx, espSPISetParams,
+		packWords(0, opts.flashSizeBytes, 64*1024, 4*1024, 256, 0xFFFF), 0, espDefaultTimeout); err != nil {
+		return errors.Wrap(err, "failed to set esp32 flash parameters")
+	}
+
+	progress(stageErasing, 0, len(image))
+	numBlocks := (len(image) + espFlashBlockSize - 1) / espFlashBlockSize
+	if _, err := c.command(ctx, espFlashBegin,
+		packWords(uint32(len(image)), uint32(numBlocks), espFlashBlockSize, opts.offset), 0,
+		timeoutPerMB(espEraseTimeoutPerMB, len(image))); err != nil {
+		return errors.Wrap(err, "failed to erase esp32 flash")
+	}
+
+	for seq := 0; seq < numBlocks; seq++ {
+		block := image[seq*espFlashBlockSize : min((seq+1)*espFlashBlockSize, len(image))]
+		// the last block is padded with erased flash
+		block = append(block[:len(block):len(block)], bytes.Repeat([]byte{0xFF}, espFlashBlockSize-len(block))...)
+		data := append(packWords(uint32(len(block)), uint32(seq), 0, 0), block...)
+		if _, err := c.command(ctx, espFlashData, data, espChecksum(block), espDefaultTimeout); err != nil {
+			return errors.Wrapf(err, "failed to write esp32 flash block %d of %d", seq+1, numBlocks)
+		}
+		progress(stageWriting, min((seq+1)*espFlashBlockSize, len(image)), len(image))
+	}
+
+	if opts.verify {
+		progress(stageVerifying, 0, len(image))
+		resp, err := c.command(ctx, espSPIFlashMD5, packWords(opts.offset, uint32(len(image)), 0, 0), 0,
+			timeoutPerMB(espMD5TimeoutPerMB, len(image)))
+		if err != nil {
+			return errors.Wrap(err, "failed to read back esp32 flash digest")
+		}
+		//nolint:gosec
+		expected := md5.Sum(image)
+		// the ROM reports the digest in hex, while the flasher stub reports it raw
+		actual := resp
+		if len(resp) == 2*md5.Size {
+			actual, err = hex.DecodeString(string(resp))
+			if err != nil {
+				return errors.Wrap(err, "invalid esp32 flash digest")
+			}
+		}
+		if !bytes.Equal(actual, expected[:]) {
+			return errors.Errorf("esp32 flash verification failed, expected md5 %x but flash has %x", expected, actual)
+		}
+		progress(stageVerifying, len(image), len(image))
+	}
+
+	if opts.reboot {
+		// a flag of 0 asks the bootloader to reboot into the new firmware
+		if _, err := c.command(ctx, espFlashEnd, packWords(0), 0, espDefaultTimeout); err != nil {
+			return errors.Wrap(err, "failed to reboot esp32")
+		}
+	}
+	return nil
+}
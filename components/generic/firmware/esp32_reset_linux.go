@@ -0,0 +1,50 @@
+//go:build linux
+
+package firmware
+
+import (
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// setModemLines asserts or deasserts the DTR and RTS lines of a serial port, which ESP32 development boards wire to
+// their EN and IO0 pins.
+func setModemLines(port io.ReadWriteCloser, dtr, rts bool) error {
+	f, ok := port.(interface{ Fd() uintptr })
+	if !ok {
+		return errors.New("serial port does not support setting control lines")
+	}
+	fd := int(f.Fd())
+	lines, err := unix.IoctlGetInt(fd, unix.TIOCMGET)
+	if err != nil {
+		return errors.Wrap(err, "failed to get serial control lines")
+	}
+	for line, assert := range map[int]bool{unix.TIOCM_DTR: dtr, unix.TIOCM_RTS: rts} {
+		if assert {
+			lines |= line
+		} else {
+			lines &^= line
+		}
+	}
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCMSET, lines); err != nil {
+		return errors.Wrap(err, "failed to set serial control lines")
+	}
+	return nil
+}
+
+// resetIntoBootloader resets the ESP32 while holding IO0 low, the same way esptool does, so that it starts in its
+// serial bootloader.
+func resetIntoBootloader(port io.ReadWriteCloser) error {
+	if err := setModemLines(port, false, true); err != nil {
+		return err
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := setModemLines(port, true, false); err != nil {
+		return err
+	}
+	time.Sleep(50 * time.Millisecond)
+	return setModemLines(port, false, false)
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package firmware
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+func resetIntoBootloader(port io.ReadWriteCloser) error {
+	return errors.New("resetting into the bootloader is only supported on linux")
+}
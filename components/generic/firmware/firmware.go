@@ -0,0 +1,532 @@
+// Package firmware implements a generic component which flashes firmware onto microcontrollers attached to the
+// machine, so that they can be updated in the field. ESP32s are flashed through their ROM serial bootloader using
+// the esptool protocol, and STM32s through the DfuSe protocol of their USB DFU system bootloader.
+//
+// Updates are started with DoCommand and run in the background:
+//
+//	{"command": "flash", "device": "esp", "path": "/home/user/firmware.bin"}
+//
+// Their progress is reported by {"command": "get_progress", "device": "esp"}, which can be streamed from the CLI
+// with `viam machines part run --stream 1s ... viam.component.generic.v1.GenericService.DoCommand`, and an update
+// is stopped with {"command": "cancel", "device": "esp"}. A flash command can instead be streamed with
+// resource.DoCommandStream, which sends the progress of the update as it goes and returns once it is done.
+// Images are copied to the machine beforehand, for example with `viam machines part cp`.
+package firmware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jacobsa/go-serial/serial"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// Model is the model of the firmware updater.
+var Model = resource.DefaultModelFamily.WithModel("mcu-firmware")
+
+// Supported microcontrollers.
+const (
+	deviceTypeESP32 = "esp32"
+	deviceTypeSTM32 = "stm32"
+)
+
+// Stages of a firmware update.
+const (
+	stageConnecting = "connecting"
+	stageErasing    = "erasing"
+	stageWriting    = "writing"
+	stageVerifying  = "verifying"
+	stageDone       = "done"
+	stageFailed     = "failed"
+)
+
+const (
+	defaultESP32BaudRate    = 115200
+	defaultESP32FlashSizeMB = 4
+	// defaultESP32AppOffset is where the application partition starts in the default ESP-IDF partition table.
+	defaultESP32AppOffset = 0x10000
+	defaultSTM32VendorID  = 0x0483
+	defaultSTM32ProductID = 0xdf11
+)
+
+// progressFunc is told how many of the total bytes of an image have been handled by the current stage.
+type progressFunc func(stage string, done, total int)
+
+// DeviceConfig describes a microcontroller which can be flashed.
+type DeviceConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// FlashAddress is where images are written, unless a flash command gives an address. It defaults to the
+	// application partition of an ESP32 and the start of flash of an STM32.
+	FlashAddress *int `json:"flash_address,omitempty"`
+
+	// ESP32s are connected over serial.
+	SerialPath     string `json:"serial_path,omitempty"`
+	SerialBaudRate int    `json:"serial_baud_rate,omitempty"`
+	FlashSizeMB    int    `json:"flash_size_mb,omitempty"`
+
+	// STM32s are found on USB while in DFU mode.
+	USBVendorID   int    `json:"usb_vendor_id,omitempty"`
+	USBProductID  int    `json:"usb_product_id,omitempty"`
+	USBSerial     string `json:"usb_serial,omitempty"`
+	ErasePageSize int    `json:"erase_page_size,omitempty"`
+}
+
+// Config is used for converting firmware updater config attributes.
+type Config struct {
+	Devices []DeviceConfig `json:"devices"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if len(cfg.Devices) == 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "devices")
+	}
+	names := map[string]bool{}
+	for i, dev := range cfg.Devices {
+		devPath := fmt.Sprintf("%s.devices.%d", path, i)
+		if dev.Name == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(devPath, "name")
+		}
+		if names[dev.Name] {
+			return nil, resource.NewConfigValidationError(devPath, errors.Errorf("duplicate device name %q", dev.Name))
+		}
+		names[dev.Name] = true
+		switch dev.Type {
+		case deviceTypeESP32:
+			if dev.SerialPath == "" {
+				return nil, resource.NewConfigValidationFieldRequiredError(devPath, "serial_path")
+			}
+		case deviceTypeSTM32:
+		default:
+			return nil, resource.NewConfigValidationError(devPath,
+				errors.Errorf("unsupported device type %q, must be %s or %s", dev.Type, deviceTypeESP32, deviceTypeSTM32))
+		}
+		if dev.FlashAddress != nil && *dev.FlashAddress < 0 {
+			return nil, resource.NewConfigValidationError(devPath, errors.New("flash_address may not be negative"))
+		}
+		if dev.SerialBaudRate < 0 || dev.FlashSizeMB < 0 || dev.ErasePageSize < 0 {
+			return nil, resource.NewConfigValidationError(devPath, errors.New("sizes and rates may not be negative"))
+		}
+	}
+	return nil, nil
+}
+
+func init() {
+	resource.RegisterComponent(generic.API, Model, resource.Registration[resource.Resource, *Config]{
+		Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (resource.Resource, error) {
+			newConf, err := resource.NativeConfig[*Config](conf)
+			if err != nil {
+				return nil, err
+			}
+			return newUpdater(conf.ResourceName(), newConf, openSerialPort, openConfiguredDFUDevice, logger), nil
+		},
+	})
+}
+
+func openSerialPort(conf DeviceConfig) (io.ReadWriteCloser, error) {
+	baudRate := conf.SerialBaudRate
+	if baudRate == 0 {
+		baudRate = defaultESP32BaudRate
+	}
+	return serial.Open(serial.OpenOptions{
+		PortName:   conf.SerialPath,
+		BaudRate:   uint(baudRate),
+		DataBits:   8,
+		StopBits:   1,
+		ParityMode: serial.PARITY_NONE,
+		// reads time out so that closing the port is not held up by a blocked read
+		InterCharacterTimeout: 100,
+		MinimumReadSize:       0,
+	})
+}
+
+func openConfiguredDFUDevice(conf DeviceConfig) (dfuDevice, error) {
+	vendorID, productID := conf.USBVendorID, conf.USBProductID
+	if vendorID == 0 {
+		vendorID = defaultSTM32VendorID
+	}
+	if productID == 0 {
+		productID = defaultSTM32ProductID
+	}
+	return openDFUDevice(uint16(vendorID), uint16(productID), conf.USBSerial)
+}
+
+// flashJob is a firmware update of a single device.
+type flashJob struct {
+	path     string
+	address  int
+	stage    string
+	done     int
+	total    int
+	started  time.Time
+	finished time.Time
+	err      error
+	cancel   func()
+}
+
+type updater struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	devices    map[string]DeviceConfig
+	openSerial func(DeviceConfig) (io.ReadWriteCloser, error)
+	openDFU    func(DeviceConfig) (dfuDevice, error)
+	logger     logging.Logger
+
+	// streams runs the flash commands which are streamed.
+	streams *resource.CommandStreams
+
+	mu                      sync.Mutex
+	jobs                    map[string]*flashJob
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+func newUpdater(
+	name resource.Name,
+	conf *Config,
+	openSerial func(DeviceConfig) (io.ReadWriteCloser, error),
+	openDFU func(DeviceConfig) (dfuDevice, error),
+	logger logging.Logger,
+) *updater {
+	u := &updater{
+		Named:      name.AsNamed(),
+		devices:    map[string]DeviceConfig{},
+		openSerial: openSerial,
+		openDFU:    openDFU,
+		logger:     logger,
+		jobs:       map[string]*flashJob{},
+	}
+	for _, dev := range conf.Devices {
+		u.devices[dev.Name] = dev
+	}
+	u.streams = resource.NewCommandStreams(map[string]resource.StreamingCommandHandler{"flash": u.streamFlash})
+	return u
+}
+
+// DoCommand starts, reports on and cancels firmware updates.
+func (u *updater) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	handlers := u.streams.Handlers()
+	streamFlash := handlers["flash"]
+	handlers["flash"] = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		// an update takes minutes, so unless it is streamed it runs in the background and is followed with get_progress
+		if stream, _ := cmd[resource.StreamKey].(bool); stream {
+			return streamFlash(ctx, cmd)
+		}
+		return u.startFlash(cmd)
+	}
+	handlers["get_progress"] = u.getProgress
+	handlers["cancel"] = u.cancel
+	return handlers.DoCommand(ctx, cmd)
+}
+
+// DoCommandStream runs a flash command, sending the progress of the update as it goes.
+func (u *updater) DoCommandStream(
+	ctx context.Context, cmd map[string]interface{}, send func(msg map[string]interface{}) error,
+) (map[string]interface{}, error) {
+	return u.streams.DoCommandStream(ctx, cmd, send)
+}
+
+func (u *updater) getProgress(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	dev, err := u.device(cmd)
+	if err != nil {
+		return nil, err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	job, ok := u.jobs[dev.Name]
+	if !ok {
+		return map[string]interface{}{"device": dev.Name, "stage": "idle"}, nil
+	}
+	return job.status(dev.Name), nil
+}
+
+func (u *updater) cancel(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	dev, err := u.device(cmd)
+	if err != nil {
+		return nil, err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	job, ok := u.jobs[dev.Name]
+	if !ok || !job.finished.IsZero() {
+		return nil, errors.Errorf("no firmware update of %q is running", dev.Name)
+	}
+	job.cancel()
+	return map[string]interface{}{"device": dev.Name, "cancelled": true}, nil
+}
+
+// device returns the device a command is for, which may be left out if there is only one.
+func (u *updater) device(cmd map[string]interface{}) (DeviceConfig, error) {
+	name, ok := cmd["device"].(string)
+	if !ok {
+		if len(u.devices) != 1 {
+			return DeviceConfig{}, errors.New("missing 'device' value")
+		}
+		for _, dev := range u.devices {
+			return dev, nil
+		}
+	}
+	dev, ok := u.devices[name]
+	if !ok {
+		return DeviceConfig{}, errors.Errorf("no device named %q", name)
+	}
+	return dev, nil
+}
+
+// flashRequest is a firmware update asked for by a flash command.
+type flashRequest struct {
+	dev            DeviceConfig
+	path           string
+	image          []byte
+	address        int
+	verify, reboot bool
+}
+
+func (u *updater) parseFlash(cmd map[string]interface{}) (flashRequest, error) {
+	dev, err := u.device(cmd)
+	if err != nil {
+		return flashRequest{}, err
+	}
+	path, ok := cmd["path"].(string)
+	if !ok || path == "" {
+		return flashRequest{}, errors.New("missing 'path' value")
+	}
+	image, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return flashRequest{}, errors.Wrap(err, "failed to read firmware image")
+	}
+	if len(image) == 0 {
+		return flashRequest{}, errors.Errorf("firmware image %s is empty", path)
+	}
+
+	req := flashRequest{dev: dev, path: path, image: image, address: defaultESP32AppOffset, verify: true, reboot: true}
+	if dev.Type == deviceTypeSTM32 {
+		req.address = dfuDefaultFlashAddress
+	}
+	if dev.FlashAddress != nil {
+		req.address = *dev.FlashAddress
+	}
+	if addr, ok := cmd["address"].(float64); ok {
+		if addr < 0 {
+			return flashRequest{}, errors.New("address may not be negative")
+		}
+		req.address = int(addr)
+	}
+	if v, ok := cmd["verify"].(bool); ok {
+		req.verify = v
+	}
+	if r, ok := cmd["reboot"].(bool); ok {
+		req.reboot = r
+	}
+	return req, nil
+}
+
+// newJob records the update of a device, unless one is already running, returning it and the context it runs in,
+// which is cancelled with the job.
+func (u *updater) newJob(ctx context.Context, req flashRequest) (*flashJob, context.Context, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if job, ok := u.jobs[req.dev.Name]; ok && job.finished.IsZero() {
+		return nil, nil, errors.Errorf("a firmware update of %q is already running", req.dev.Name)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	job := &flashJob{
+		path: req.path, address: req.address, stage: stageConnecting, total: len(req.image), started: time.Now(), cancel: cancel,
+	}
+	u.jobs[req.dev.Name] = job
+	return job, ctx, nil
+}
+
+// runJob flashes the device of a job, recording how it went in the job. notify, if set, is called with the status
+// of the job whenever its progress is logged.
+func (u *updater) runJob(ctx context.Context, req flashRequest, job *flashJob, notify func(status map[string]interface{})) error {
+	defer job.cancel()
+	err := u.flash(ctx, req.dev, req.image, req.address, req.verify, req.reboot, u.progressReporter(req.dev.Name, job, notify))
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	job.finished = time.Now()
+	if err != nil {
+		job.stage, job.err = stageFailed, err
+		u.logger.Errorw("firmware update failed", "device", req.dev.Name, "path", req.path, "error", err)
+		return err
+	}
+	job.stage, job.done = stageDone, job.total
+	u.logger.Infow("firmware update finished", "device", req.dev.Name, "path", req.path,
+		"duration", job.finished.Sub(job.started))
+	return nil
+}
+
+// startFlash starts flashing a device in the background, returning the status of the update.
+func (u *updater) startFlash(cmd map[string]interface{}) (map[string]interface{}, error) {
+	req, err := u.parseFlash(cmd)
+	if err != nil {
+		return nil, err
+	}
+	job, ctx, err := u.newJob(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.activeBackgroundWorkers.Add(1)
+	utils.PanicCapturingGo(func() {
+		defer u.activeBackgroundWorkers.Done()
+		//nolint:errcheck
+		u.runJob(ctx, req, job, nil)
+	})
+	return job.status(req.dev.Name), nil
+}
+
+// streamFlash flashes a device, sending the status of the update as it progresses, and returns its final status.
+func (u *updater) streamFlash(
+	ctx context.Context, cmd map[string]interface{}, send func(msg map[string]interface{}) error,
+) (map[string]interface{}, error) {
+	req, err := u.parseFlash(cmd)
+	if err != nil {
+		return nil, err
+	}
+	job, ctx, err := u.newJob(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	// a send fails once whoever is told has gone, so the update is cancelled with it
+	notify := func(status map[string]interface{}) {
+		if err := send(status); err != nil {
+			job.cancel()
+		}
+	}
+	if err := u.runJob(ctx, req, job, notify); err != nil {
+		return nil, err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return job.status(req.dev.Name), nil
+}
+
+// progressReporter returns a progressFunc which records the progress of job, logging every stage and every tenth of
+// the image written, and calling notify, if set, with the status of the job each time.
+func (u *updater) progressReporter(name string, job *flashJob, notify func(status map[string]interface{})) progressFunc {
+	lastLogged := -1
+	return func(stage string, done, total int) {
+		u.mu.Lock()
+		newStage := stage != job.stage
+		job.stage, job.done, job.total = stage, done, total
+		if newStage {
+			lastLogged = -1
+		}
+		tenth := 10 * done / max(total, 1)
+		if !newStage && tenth <= lastLogged {
+			u.mu.Unlock()
+			return
+		}
+		lastLogged = tenth
+		u.logger.Infow("firmware update progress", "device", name, "stage", stage, "percent", 10*tenth)
+		status := job.status(name)
+		u.mu.Unlock()
+		// notifying may block on whoever is told, so it is done without holding the lock
+		if notify != nil {
+			notify(status)
+		}
+	}
+}
+
+func (u *updater) flash(
+	ctx context.Context,
+	dev DeviceConfig,
+	image []byte,
+	address int,
+	verify, reboot bool,
+	progress progressFunc,
+) error {
+	switch dev.Type {
+	case deviceTypeESP32:
+		port, err := u.openSerial(dev)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open serial port %s", dev.SerialPath)
+		}
+		if err := resetIntoBootloader(port); err != nil {
+			u.logger.Warnw("could not reset into the bootloader, it must be in download mode already",
+				"device", dev.Name, "error", err)
+		}
+		conn := newEsptoolConn(port)
+		defer utils.UncheckedErrorFunc(conn.Close)
+		flashSize := dev.FlashSizeMB
+		if flashSize == 0 {
+			flashSize = defaultESP32FlashSizeMB
+		}
+		return flashESP32(ctx, conn, image, esp32Options{
+			offset:         uint32(address),
+			flashSizeBytes: uint32(flashSize) << 20,
+			verify:         verify,
+			reboot:         reboot,
+		}, progress)
+	case deviceTypeSTM32:
+		usbDev, err := u.openDFU(dev)
+		if err != nil {
+			return err
+		}
+		defer utils.UncheckedErrorFunc(usbDev.Close)
+		pageSize := dev.ErasePageSize
+		if pageSize == 0 {
+			pageSize = dfuDefaultErasePageSize
+		}
+		return flashSTM32(ctx, usbDev, image, stm32Options{
+			address:       uint32(address),
+			transferSize:  dfuDefaultTransferSize,
+			erasePageSize: pageSize,
+			verify:        verify,
+			reboot:        reboot,
+		}, progress)
+	default:
+		return errors.Errorf("unsupported device type %q", dev.Type)
+	}
+}
+
+func (j *flashJob) status(name string) map[string]interface{} {
+	status := map[string]interface{}{
+		"device":      name,
+		"path":        j.path,
+		"address":     j.address,
+		"stage":       j.stage,
+		"done_bytes":  j.done,
+		"total_bytes": j.total,
+		"percent":     100 * float64(j.done) / float64(max(j.total, 1)),
+		"running":     j.finished.IsZero(),
+	}
+	if !j.finished.IsZero() {
+		status["duration_sec"] = j.finished.Sub(j.started).Seconds()
+	}
+	if j.err != nil {
+		status["error"] = j.err.Error()
+	}
+	return status
+}
+
+// Close cancels any running firmware updates and waits for them to stop.
+func (u *updater) Close(ctx context.Context) error {
+	u.streams.Close()
+	u.mu.Lock()
+	for _, job := range u.jobs {
+		job.cancel()
+	}
+	u.mu.Unlock()
+	u.activeBackgroundWorkers.Wait()
+	return nil
+}
@@ -0,0 +1,502 @@
+package firmware
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+	"go.viam.com/utils"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// fakeESP32 emulates the ROM bootloader of an ESP32 on the other end of a serial port.
+type fakeESP32 struct {
+	mu         sync.Mutex
+	flash      []byte
+	writeAt    uint32
+	commands   []byte
+	badDigest  bool
+	unsynced   int
+	rebooted   bool
+	blockCount int
+}
+
+func (f *fakeESP32) serve(port net.Conn) {
+	buf := make([]byte, 4096)
+	var frame []byte
+	inFrame, escaped := false, false
+	for {
+		n, err := port.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, b := range buf[:n] {
+			switch {
+			case !inFrame:
+				inFrame, frame = b == slipEnd, nil
+			case escaped:
+				escaped = false
+				if b == slipEscEnd {
+					frame = append(frame, slipEnd)
+				} else {
+					frame = append(frame, slipEsc)
+				}
+			case b == slipEsc:
+				escaped = true
+			case b == slipEnd:
+				inFrame = false
+				if resp := f.handle(frame); resp != nil {
+					if _, err := port.Write(slipEncode(resp)); err != nil {
+						return
+					}
+				}
+			default:
+				frame = append(frame, b)
+			}
+		}
+	}
+}
+
+func (f *fakeESP32) handle(packet []byte) []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	op := packet[1]
+	checksum := binary.LittleEndian.Uint32(packet[4:8])
+	data := packet[8:]
+	f.commands = append(f.commands, op)
+	word := func(i int) uint32 { return binary.LittleEndian.Uint32(data[4*i:]) }
+
+	status := []byte{0, 0, 0, 0}
+	var body []byte
+	switch op {
+	case espSync:
+		if f.unsynced > 0 {
+			f.unsynced--
+			return nil
+		}
+	case espFlashBegin:
+		f.writeAt = word(3)
+		f.blockCount = int(word(1))
+	case espFlashData:
+		block := data[16 : 16+word(0)]
+		if espChecksum(block) != checksum {
+			status = []byte{1, 0x06, 0, 0}
+			break
+		}
+		offset := f.writeAt + word(1)*espFlashBlockSize
+		copy(f.flash[offset:], block)
+	case espSPIFlashMD5:
+		//nolint:gosec
+		sum := md5.Sum(f.flash[word(0) : word(0)+word(1)])
+		if f.badDigest {
+			sum[0]++
+		}
+		body = []byte(hex.EncodeToString(sum[:]))
+	case espFlashEnd:
+		f.rebooted = word(0) == 0
+	}
+	body = append(body, status...)
+	resp := make([]byte, 8, 8+len(body))
+	resp[0], resp[1] = 0x01, op
+	binary.LittleEndian.PutUint16(resp[2:], uint16(len(body)))
+	return append(resp, body...)
+}
+
+func newFakeESP32Conn(t *testing.T, fake *fakeESP32) *esptoolConn {
+	t.Helper()
+	client, device := net.Pipe()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	utils.PanicCapturingGo(func() {
+		defer wg.Done()
+		fake.serve(device)
+	})
+	t.Cleanup(func() {
+		test.That(t, device.Close(), test.ShouldBeNil)
+		wg.Wait()
+	})
+	return newEsptoolConn(client)
+}
+
+func TestSLIP(t *testing.T) {
+	packet := []byte{0x01, slipEnd, 0x02, slipEsc, 0x03}
+	encoded := slipEncode(packet)
+	test.That(t, encoded, test.ShouldResemble, []byte{
+		slipEnd, 0x01, slipEsc, slipEscEnd, 0x02, slipEsc, slipEscEsc, 0x03, slipEnd,
+	})
+	test.That(t, espChecksum([]byte{0x01, 0x02}), test.ShouldEqual, uint32(0xEF^0x01^0x02))
+}
+
+func TestFlashESP32(t *testing.T) {
+	ctx := context.Background()
+	// the image contains bytes which need escaping and does not fill its last block
+	image := bytes.Repeat([]byte{0x00, slipEnd, 0x11, slipEsc}, 700)
+	fake := &fakeESP32{flash: bytes.Repeat([]byte{0xFF}, 0x20000), unsynced: 2}
+	conn := newFakeESP32Conn(t, fake)
+	defer func() {
+		test.That(t, conn.Close(), test.ShouldBeNil)
+	}()
+
+	var stages []string
+	var lastDone int
+	err := flashESP32(ctx, conn, image, esp32Options{offset: 0x10000, flashSizeBytes: 4 << 20, verify: true, reboot: true},
+		func(stage string, done, total int) {
+			if len(stages) == 0 || stages[len(stages)-1] != stage {
+				stages = append(stages, stage)
+			}
+			test.That(t, total, test.ShouldEqual, len(image))
+			lastDone = done
+		})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stages, test.ShouldResemble, []string{stageConnecting, stageErasing, stageWriting, stageVerifying})
+	test.That(t, lastDone, test.ShouldEqual, len(image))
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	test.That(t, fake.flash[0x10000:0x10000+len(image)], test.ShouldResemble, image)
+	// the rest of the last block is left erased
+	test.That(t, fake.flash[0x10000+len(image)], test.ShouldEqual, byte(0xFF))
+	test.That(t, fake.blockCount, test.ShouldEqual, 3)
+	test.That(t, fake.rebooted, test.ShouldBeTrue)
+}
+
+func TestFlashESP32VerifyFailure(t *testing.T) {
+	fake := &fakeESP32{flash: make([]byte, 0x20000), badDigest: true}
+	conn := newFakeESP32Conn(t, fake)
+	defer func() {
+		test.That(t, conn.Close(), test.ShouldBeNil)
+	}()
+	err := flashESP32(context.Background(), conn, []byte{1, 2, 3}, esp32Options{verify: true, reboot: true},
+		func(string, int, int) {})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "verification failed")
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	test.That(t, fake.rebooted, test.ShouldBeFalse)
+}
+
+func TestFlashESP32NoBootloader(t *testing.T) {
+	fake := &fakeESP32{unsynced: espSyncAttempts}
+	conn := newFakeESP32Conn(t, fake)
+	defer func() {
+		test.That(t, conn.Close(), test.ShouldBeNil)
+	}()
+	err := flashESP32(context.Background(), conn, []byte{1}, esp32Options{}, func(string, int, int) {})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "download mode")
+}
+
+// fakeDFU emulates the DfuSe system bootloader of an STM32.
+type fakeDFU struct {
+	mu        sync.Mutex
+	base      uint32
+	flash     []byte
+	pageSize  int
+	pointer   uint32
+	state     byte
+	status    byte
+	erased    map[uint32]bool
+	corrupt   bool
+	left      bool
+	busyPolls int
+	busyLeft  int
+	closed    bool
+}
+
+func newFakeDFU(size int) *fakeDFU {
+	return &fakeDFU{
+		base:     dfuDefaultFlashAddress,
+		flash:    make([]byte, size),
+		pageSize: 1024,
+		// a previous session left the bootloader in an error state
+		state:  dfuStateError,
+		status: 1,
+		erased: map[uint32]bool{},
+	}
+}
+
+func (f *fakeDFU) controlOut(ctx context.Context, request uint8, value uint16, data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch request {
+	case dfuClrStatus:
+		f.state, f.status = dfuStateIdle, 0
+	case dfuAbort:
+		f.state = dfuStateIdle
+	case dfuDnload:
+		f.state, f.busyLeft = dfuStateDnloadBusy, f.busyPolls
+		switch {
+		case value == 0 && len(data) == 5:
+			addr := binary.LittleEndian.Uint32(data[1:])
+			switch data[0] {
+			case dfuseSetAddress:
+				f.pointer = addr
+			case dfuseErase:
+				page := addr - addr%uint32(f.pageSize)
+				f.erased[page] = true
+				for i := 0; i < f.pageSize; i++ {
+					f.flash[page-f.base+uint32(i)] = 0xFF
+				}
+			}
+		case value >= 2 && len(data) == 0:
+			f.left = true
+		case value >= 2:
+			addr := f.pointer + uint32(value-2)*dfuDefaultTransferSize
+			for i, b := range data {
+				page := (addr + uint32(i)) - (addr+uint32(i))%uint32(f.pageSize)
+				if !f.erased[page] {
+					return errors.Errorf("write to unerased page %#x", page)
+				}
+				f.flash[addr-f.base+uint32(i)] = b
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fakeDFU) controlIn(ctx context.Context, request uint8, value uint16, length int) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch request {
+	case dfuGetStatus:
+		if f.state == dfuStateDnloadBusy {
+			if f.busyLeft > 0 {
+				f.busyLeft--
+				return []byte{f.status, 1, 0, 0, f.state, 0}, nil
+			}
+			f.state = dfuStateDnloadIdle
+		}
+		return []byte{f.status, 0, 0, 0, f.state, 0}, nil
+	case dfuUpload:
+		if f.state != dfuStateIdle {
+			return nil, errors.New("upload outside of idle state")
+		}
+		start := f.pointer - f.base + uint32(value-2)*dfuDefaultTransferSize
+		data := append([]byte{}, f.flash[start:start+uint32(length)]...)
+		if f.corrupt {
+			data[0]++
+		}
+		return data, nil
+	}
+	return nil, errors.Errorf("unexpected request %d", request)
+}
+
+func (f *fakeDFU) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+func TestFlashSTM32(t *testing.T) {
+	ctx := context.Background()
+	image := make([]byte, 5000)
+	for i := range image {
+		image[i] = byte(i)
+	}
+	fake := newFakeDFU(0x4000)
+	fake.busyPolls = 1
+	opts := stm32Options{
+		address:       dfuDefaultFlashAddress + 0x200,
+		transferSize:  dfuDefaultTransferSize,
+		erasePageSize: 1024,
+		verify:        true,
+		reboot:        true,
+	}
+	var verified int
+	err := flashSTM32(ctx, fake, image, opts, func(stage string, done, total int) {
+		if stage == stageVerifying {
+			verified = done
+		}
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fake.flash[0x200:0x200+len(image)], test.ShouldResemble, image)
+	// pages covering the unaligned start and partial end were erased
+	test.That(t, fake.erased, test.ShouldHaveLength, 6)
+	test.That(t, verified, test.ShouldEqual, len(image))
+	test.That(t, fake.left, test.ShouldBeTrue)
+
+	fake = newFakeDFU(0x4000)
+	fake.corrupt = true
+	opts.reboot = false
+	err = flashSTM32(ctx, fake, image, opts, func(string, int, int) {})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "verification failed at 0x8000200")
+	test.That(t, fake.left, test.ShouldBeFalse)
+}
+
+func TestValidate(t *testing.T) {
+	cfg := &Config{}
+	_, err := cfg.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "devices")
+
+	cfg = &Config{Devices: []DeviceConfig{{Name: "esp", Type: deviceTypeESP32}}}
+	_, err = cfg.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "serial_path")
+
+	cfg = &Config{Devices: []DeviceConfig{{Name: "mcu", Type: "avr"}}}
+	_, err = cfg.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, `unsupported device type "avr"`)
+
+	cfg = &Config{Devices: []DeviceConfig{{Name: "stm", Type: deviceTypeSTM32}, {Name: "stm", Type: deviceTypeSTM32}}}
+	_, err = cfg.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "duplicate device name")
+
+	cfg = &Config{Devices: []DeviceConfig{
+		{Name: "esp", Type: deviceTypeESP32, SerialPath: "/dev/ttyUSB0"},
+		{Name: "stm", Type: deviceTypeSTM32},
+	}}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+}
+
+func TestDoCommand(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	path := filepath.Join(t.TempDir(), "firmware.bin")
+	image := bytes.Repeat([]byte{0xAB}, 3000)
+	test.That(t, os.WriteFile(path, image, 0o600), test.ShouldBeNil)
+
+	fake := newFakeDFU(0x4000)
+	conf := &Config{Devices: []DeviceConfig{
+		{Name: "stm", Type: deviceTypeSTM32},
+		{Name: "esp", Type: deviceTypeESP32, SerialPath: "/dev/null"},
+	}}
+	openSerial := func(DeviceConfig) (io.ReadWriteCloser, error) { return nil, errors.New("no serial port") }
+	openDFU := func(DeviceConfig) (dfuDevice, error) { return fake, nil }
+	u := newUpdater(generic.Named("firmware"), conf, openSerial, openDFU, logger)
+	defer func() {
+		test.That(t, u.Close(ctx), test.ShouldBeNil)
+	}()
+
+	_, err := u.DoCommand(ctx, map[string]interface{}{"command": "flash", "path": path})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "missing 'device' value")
+	_, err = u.DoCommand(ctx, map[string]interface{}{"command": "flash", "device": "stm"})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "missing 'path' value")
+	_, err = u.DoCommand(ctx, map[string]interface{}{"command": "bad", "device": "stm"})
+	test.That(t, errors.Is(err, resource.ErrDoUnimplemented), test.ShouldBeTrue)
+
+	resp, err := u.DoCommand(ctx, map[string]interface{}{"command": "get_progress", "device": "stm"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["stage"], test.ShouldEqual, "idle")
+
+	resp, err = u.DoCommand(ctx, map[string]interface{}{"command": "flash", "device": "stm", "path": path})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["total_bytes"], test.ShouldEqual, len(image))
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		resp, err := u.DoCommand(ctx, map[string]interface{}{"command": "get_progress", "device": "stm"})
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, resp["stage"], test.ShouldEqual, stageDone)
+		test.That(tb, resp["percent"], test.ShouldEqual, 100.)
+		test.That(tb, resp["running"], test.ShouldBeFalse)
+	})
+	fake.mu.Lock()
+	test.That(t, fake.flash[:len(image)], test.ShouldResemble, image)
+	test.That(t, fake.left, test.ShouldBeTrue)
+	test.That(t, fake.closed, test.ShouldBeTrue)
+	fake.mu.Unlock()
+
+	// failures are reported in the progress of the device
+	_, err = u.DoCommand(ctx, map[string]interface{}{"command": "flash", "device": "esp", "path": path})
+	test.That(t, err, test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		resp, err := u.DoCommand(ctx, map[string]interface{}{"command": "get_progress", "device": "esp"})
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, resp["stage"], test.ShouldEqual, stageFailed)
+		test.That(tb, resp["error"], test.ShouldContainSubstring, "no serial port")
+	})
+
+	_, err = u.DoCommand(ctx, map[string]interface{}{"command": "cancel", "device": "esp"})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no firmware update")
+}
+
+func TestFlashStream(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	path := filepath.Join(t.TempDir(), "firmware.bin")
+	image := bytes.Repeat([]byte{0xCD}, 3000)
+	test.That(t, os.WriteFile(path, image, 0o600), test.ShouldBeNil)
+
+	fake := newFakeDFU(0x4000)
+	conf := &Config{Devices: []DeviceConfig{{Name: "stm", Type: deviceTypeSTM32}}}
+	u := newUpdater(generic.Named("firmware"), conf, nil, func(DeviceConfig) (dfuDevice, error) { return fake, nil }, logger)
+	defer func() {
+		test.That(t, u.Close(ctx), test.ShouldBeNil)
+	}()
+
+	// a streamed flash sends its progress as it goes and returns once the update is done
+	var stages []interface{}
+	resp, err := resource.DoCommandStream(ctx, u, map[string]interface{}{"command": "flash", "path": path},
+		func(msg map[string]interface{}) error {
+			stages = append(stages, msg["stage"])
+			return nil
+		})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["stage"], test.ShouldEqual, stageDone)
+	test.That(t, resp["running"], test.ShouldBeFalse)
+	test.That(t, stages, test.ShouldContain, stageWriting)
+	test.That(t, stages, test.ShouldContain, stageVerifying)
+	fake.mu.Lock()
+	test.That(t, fake.flash[:len(image)], test.ShouldResemble, image)
+	fake.mu.Unlock()
+
+	resp, err = u.DoCommand(ctx, map[string]interface{}{"command": "get_progress"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["stage"], test.ShouldEqual, stageDone)
+
+	// clients which can only DoCommand, such as a remote one, stream through stream_next
+	stages = nil
+	client := struct{ resource.Resource }{u}
+	resp, err = resource.DoCommandStream(ctx, client, map[string]interface{}{"command": "flash", "path": path},
+		func(msg map[string]interface{}) error {
+			stages = append(stages, msg["stage"])
+			return nil
+		})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["stage"], test.ShouldEqual, stageDone)
+	test.That(t, stages, test.ShouldContain, stageWriting)
+}
+
+func TestCancel(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	path := filepath.Join(t.TempDir(), "firmware.bin")
+	test.That(t, os.WriteFile(path, []byte{1, 2, 3}, 0o600), test.ShouldBeNil)
+
+	// the bootloader never finishes erasing
+	fake := newFakeDFU(0x4000)
+	fake.busyPolls = 1 << 30
+	conf := &Config{Devices: []DeviceConfig{{Name: "stm", Type: deviceTypeSTM32}}}
+	u := newUpdater(generic.Named("firmware"), conf, nil, func(DeviceConfig) (dfuDevice, error) { return fake, nil }, logger)
+
+	_, err := u.DoCommand(ctx, map[string]interface{}{"command": "flash", "path": path})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = u.DoCommand(ctx, map[string]interface{}{"command": "flash", "path": path})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "already running")
+
+	resp, err := u.DoCommand(ctx, map[string]interface{}{"command": "cancel"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["cancelled"], test.ShouldBeTrue)
+	testutils.WaitForAssertionWithSleep(t, 10*time.Millisecond, 100, func(tb testing.TB) {
+		resp, err := u.DoCommand(ctx, map[string]interface{}{"command": "get_progress"})
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, resp["stage"], test.ShouldEqual, stageFailed)
+		test.That(tb, resp["error"], test.ShouldContainSubstring, "canceled")
+	})
+	test.That(t, u.Close(ctx), test.ShouldBeNil)
+}
@@ -4,6 +4,7 @@ package gripper
 import (
 	"context"
 
+	"github.com/pkg/errors"
 	commonpb "go.viam.com/api/common/v1"
 	pb "go.viam.com/api/component/gripper/v1"
 	"go.viam.com/utils/protoutils"
@@ -70,6 +71,19 @@ func (c *client) Grab(ctx context.Context, extra map[string]interface{}) (bool,
 	return resp.Success, nil
 }
 
+func (c *client) IsHoldingSomething(ctx context.Context, extra map[string]interface{}) (HoldingStatus, error) {
+	resp, err := c.DoCommand(ctx, map[string]interface{}{"command": isHoldingSomethingCommand, "extra": extra})
+	if err != nil {
+		return HoldingStatus{}, err
+	}
+	holding, ok := resp["is_holding_something"].(bool)
+	if !ok {
+		return HoldingStatus{}, errors.New("gripper did not report whether it is holding something")
+	}
+	meta, _ := resp["meta"].(map[string]interface{})
+	return HoldingStatus{IsHoldingSomething: holding, Meta: meta}, nil
+}
+
 func (c *client) Stop(ctx context.Context, extra map[string]interface{}) error {
 	ext, err := protoutils.StructToStructPb(extra)
 	if err != nil {
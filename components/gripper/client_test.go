@@ -47,6 +47,10 @@ func TestClient(t *testing.T) {
 	injectGripper.GeometriesFunc = func(ctx context.Context) ([]spatialmath.Geometry, error) {
 		return expectedGeometries, nil
 	}
+	injectGripper.IsHoldingSomethingFunc = func(ctx context.Context, extra map[string]interface{}) (gripper.HoldingStatus, error) {
+		extraOptions = extra
+		return gripper.HoldingStatus{IsHoldingSomething: true, Meta: map[string]interface{}{"width_mm": 12.}}, nil
+	}
 
 	injectGripper2 := &inject.Gripper{}
 	injectGripper2.OpenFunc = func(ctx context.Context, extra map[string]interface{}) error {
@@ -59,6 +63,9 @@ func TestClient(t *testing.T) {
 	injectGripper2.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
 		return errStopUnimplemented
 	}
+	injectGripper2.IsHoldingSomethingFunc = func(ctx context.Context, extra map[string]interface{}) (gripper.HoldingStatus, error) {
+		return gripper.HoldingStatus{}, errCantGrab
+	}
 
 	gripperSvc, err := resource.NewAPIResourceCollection(
 		gripper.API,
@@ -108,6 +115,13 @@ func TestClient(t *testing.T) {
 		test.That(t, extraOptions, test.ShouldResemble, extra)
 		test.That(t, grabbed, test.ShouldEqual, grabbed1)
 
+		extra = map[string]interface{}{"foo": "IsHoldingSomething"}
+		status, err := gripper1Client.IsHoldingSomething(context.Background(), extra)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, extraOptions, test.ShouldResemble, extra)
+		test.That(t, status.IsHoldingSomething, test.ShouldBeTrue)
+		test.That(t, status.Meta, test.ShouldResemble, map[string]interface{}{"width_mm": 12.})
+
 		extra = map[string]interface{}{"foo": "Stop"}
 		test.That(t, gripper1Client.Stop(context.Background(), extra), test.ShouldBeNil)
 		test.That(t, extraOptions, test.ShouldResemble, extra)
@@ -141,6 +155,10 @@ func TestClient(t *testing.T) {
 		test.That(t, err.Error(), test.ShouldContainSubstring, errCantGrab.Error())
 		test.That(t, grabbed, test.ShouldEqual, false)
 
+		_, err = client2.IsHoldingSomething(context.Background(), extra)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, errCantGrab.Error())
+
 		err = client2.Stop(context.Background(), extra)
 		test.That(t, err, test.ShouldNotBeNil)
 		test.That(t, err.Error(), test.ShouldContainSubstring, errStopUnimplemented.Error())
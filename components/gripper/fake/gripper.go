@@ -17,6 +17,8 @@ var model = resource.DefaultModelFamily.WithModel("fake")
 // Config is the config for a trossen gripper.
 type Config struct {
 	resource.TriviallyValidateConfig
+	// GrabsObject makes every Grab find an object to hold.
+	GrabsObject bool `json:"grabs_object,omitempty"`
 }
 
 func init() {
@@ -27,9 +29,11 @@ func init() {
 type Gripper struct {
 	resource.Named
 	resource.TriviallyCloseable
-	geometries []spatialmath.Geometry
-	mu         sync.Mutex
-	logger     logging.Logger
+	geometries  []spatialmath.Geometry
+	grabsObject bool
+	holding     bool
+	mu          sync.Mutex
+	logger      logging.Logger
 }
 
 // NewGripper instantiates a new gripper of the fake model type.
@@ -59,6 +63,13 @@ func (g *Gripper) Reconfigure(_ context.Context, _ resource.Dependencies, conf r
 		}
 		g.geometries = []spatialmath.Geometry{geometry}
 	}
+	if conf.ConvertedAttributes != nil {
+		newConf, err := resource.NativeConfig[*Config](conf)
+		if err != nil {
+			return err
+		}
+		g.grabsObject = newConf.GrabsObject
+	}
 	return nil
 }
 
@@ -67,14 +78,30 @@ func (g *Gripper) ModelFrame() referenceframe.Model {
 	return nil
 }
 
-// Open does nothing.
+// Open releases anything held.
 func (g *Gripper) Open(ctx context.Context, extra map[string]interface{}) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.holding = false
 	return nil
 }
 
-// Grab does nothing.
+// Grab holds an object if configured to find one.
 func (g *Gripper) Grab(ctx context.Context, extra map[string]interface{}) (bool, error) {
-	return false, nil
+	if _, _, err := gripper.GripForce(extra); err != nil {
+		return false, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.holding = g.grabsObject
+	return g.holding, nil
+}
+
+// IsHoldingSomething returns whether the last Grab found an object and it has not been released since.
+func (g *Gripper) IsHoldingSomething(ctx context.Context, extra map[string]interface{}) (gripper.HoldingStatus, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return gripper.HoldingStatus{IsHoldingSomething: g.holding}, nil
 }
 
 // Stop doesn't do anything for a fake gripper.
@@ -4,6 +4,7 @@ package gripper
 import (
 	"context"
 
+	"github.com/pkg/errors"
 	commonpb "go.viam.com/api/common/v1"
 	pb "go.viam.com/api/component/gripper/v1"
 
@@ -44,6 +45,16 @@ func Named(name string) resource.Name {
 //
 //	// Grab with the gripper.
 //	grabbed, err := myGripper.Grab(context.Background(), nil)
+//
+// Grab with half of the gripper's maximum force example:
+//
+//	grabbed, err := myGripper.Grab(context.Background(), map[string]interface{}{gripper.GripForceKey: 0.5})
+//
+// IsHoldingSomething example:
+//
+//	// Check whether the gripper is still holding what it grabbed.
+//	status, err := myGripper.IsHoldingSomething(context.Background(), nil)
+//	holding := status.IsHoldingSomething
 type Gripper interface {
 	resource.Resource
 	resource.Shaped
@@ -56,8 +67,46 @@ type Gripper interface {
 
 	// Grab makes the gripper grab.
 	// returns true if we grabbed something.
+	// The grip force may be given in extra under GripForceKey.
 	// This will block until done or a new operation cancels this one
 	Grab(ctx context.Context, extra map[string]interface{}) (bool, error)
+
+	// IsHoldingSomething returns whether the gripper is currently holding an object, so an empty grasp can be told
+	// apart from a successful one.
+	IsHoldingSomething(ctx context.Context, extra map[string]interface{}) (HoldingStatus, error)
+}
+
+// HoldingStatus is whether a gripper is holding an object, along with any model specific details of how that was
+// determined.
+type HoldingStatus struct {
+	IsHoldingSomething bool
+	Meta               map[string]interface{}
+}
+
+// GripForceKey is the key in the extra of Grab for the force to grip with, as a fraction of the gripper's maximum
+// force between 0 and 1.
+const GripForceKey = "grip_force"
+
+// GripForce returns the grip force requested in the extra of Grab and whether one was requested.
+func GripForce(extra map[string]interface{}) (float64, bool, error) {
+	val, ok := extra[GripForceKey]
+	if !ok {
+		return 0, false, nil
+	}
+	force, ok := val.(float64)
+	if !ok {
+		return 0, false, errors.Errorf("%s must be a number, got %v", GripForceKey, val)
+	}
+	if force < 0 || force > 1 {
+		return 0, false, errors.Errorf("%s must be between 0 and 1, got %v", GripForceKey, force)
+	}
+	return force, true, nil
+}
+
+// FromDependencies is a helper for getting the named gripper from a collection of
+// dependencies.
+func FromDependencies(deps resource.Dependencies, name string) (Gripper, error) {
+	return resource.FromDependencies[Gripper](deps, Named(name))
 }
 
 // FromRobot is a helper for getting the named Gripper from the given Robot.
@@ -74,3 +74,46 @@ func TestGetGeometries(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, geometries, test.ShouldResemble, []spatialmath.Geometry{expected})
 }
+
+func TestGripForce(t *testing.T) {
+	_, ok, err := gripper.GripForce(nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeFalse)
+
+	force, ok, err := gripper.GripForce(map[string]interface{}{gripper.GripForceKey: 0.25})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, force, test.ShouldEqual, 0.25)
+
+	_, _, err = gripper.GripForce(map[string]interface{}{gripper.GripForceKey: 2.})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = gripper.GripForce(map[string]interface{}{gripper.GripForceKey: "hard"})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestFakeIsHoldingSomething(t *testing.T) {
+	ctx := context.Background()
+	cfg := resource.Config{
+		Name:                "fakeGripper",
+		API:                 gripper.API,
+		ConvertedAttributes: &fake.Config{GrabsObject: true},
+	}
+	g, err := fake.NewGripper(ctx, nil, cfg, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	status, err := g.IsHoldingSomething(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status.IsHoldingSomething, test.ShouldBeFalse)
+
+	grabbed, err := g.Grab(ctx, map[string]interface{}{gripper.GripForceKey: 0.5})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, grabbed, test.ShouldBeTrue)
+	status, err = g.IsHoldingSomething(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status.IsHoldingSomething, test.ShouldBeTrue)
+
+	test.That(t, g.Open(ctx, nil), test.ShouldBeNil)
+	status, err = g.IsHoldingSomething(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status.IsHoldingSomething, test.ShouldBeFalse)
+}
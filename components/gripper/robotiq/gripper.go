@@ -6,8 +6,12 @@ package robotiq
 import (
 	"context"
 	"fmt"
+	"math"
 	"net"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -23,9 +27,18 @@ import (
 
 var model = resource.DefaultModelFamily.WithModel("robotiq")
 
+// Register values used when the config does not set them.
+const (
+	defaultForce = 200
+	defaultSpeed = 255
+)
+
 // Config is used for converting config attributes.
 type Config struct {
 	Host string `json:"host"`
+	// Speed and Force are the register values (0-255) used by moves which do not request their own.
+	Speed *int `json:"speed,omitempty"`
+	Force *int `json:"force,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -33,6 +46,12 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 	if cfg.Host == "" {
 		return nil, resource.NewConfigValidationFieldRequiredError(path, "host")
 	}
+	if cfg.Speed != nil && (*cfg.Speed < 0 || *cfg.Speed > 255) {
+		return nil, resource.NewConfigValidationError(path, errors.New("speed must be between 0 and 255"))
+	}
+	if cfg.Force != nil && (*cfg.Force < 0 || *cfg.Force > 255) {
+		return nil, resource.NewConfigValidationError(path, errors.New("force must be between 0 and 255"))
+	}
 	return nil, nil
 }
 
@@ -45,7 +64,7 @@ func init() {
 			if err != nil {
 				return nil, err
 			}
-			return newGripper(ctx, conf, newConf.Host, logger)
+			return newGripper(ctx, conf, newConf, logger)
 		},
 	})
 }
@@ -55,39 +74,62 @@ type robotiqGripper struct {
 	resource.Named
 	resource.AlwaysRebuild
 
-	conn net.Conn
+	// connMu keeps each command together with its reply, so that status can be read during a move.
+	connMu sync.Mutex
+	conn   net.Conn
 
 	openLimit  string
 	closeLimit string
 	logger     logging.Logger
 	opMgr      *operation.SingleOperationManager
 	geometries []spatialmath.Geometry
+
+	// defaultSpeed and defaultForce are used by moves which do not request their own, while speed and force are the
+	// values last written to the gripper.
+	defaultSpeed, defaultForce int
+	speed, force               int
+	// stopped is set when Stop has cleared the go to flag, which the next move needs to set again.
+	stopped atomic.Bool
 }
 
 // newGripper instantiates a new Gripper of robotiqGripper type.
-func newGripper(ctx context.Context, conf resource.Config, host string, logger logging.Logger) (gripper.Gripper, error) {
-	conn, err := net.Dial("tcp", host+":63352")
+func newGripper(ctx context.Context, conf resource.Config, newConf *Config, logger logging.Logger) (gripper.Gripper, error) {
+	conn, err := net.Dial("tcp", newConf.Host+":63352")
 	if err != nil {
 		return nil, err
 	}
+	return newGripperFromConn(ctx, conf, newConf, conn, logger)
+}
+
+func newGripperFromConn(
+	ctx context.Context, conf resource.Config, newConf *Config, conn net.Conn, logger logging.Logger,
+) (gripper.Gripper, error) {
 	g := &robotiqGripper{
-		conf.ResourceName().AsNamed(),
-		resource.AlwaysRebuild{},
-		conn,
-		"0",
-		"255",
-		logger,
-		operation.NewSingleOperationManager(),
-		[]spatialmath.Geometry{},
+		Named:        conf.ResourceName().AsNamed(),
+		conn:         conn,
+		openLimit:    "0",
+		closeLimit:   "255",
+		logger:       logger,
+		opMgr:        operation.NewSingleOperationManager(),
+		geometries:   []spatialmath.Geometry{},
+		defaultSpeed: defaultSpeed,
+		defaultForce: defaultForce,
+	}
+	if newConf.Speed != nil {
+		g.defaultSpeed = *newConf.Speed
+	}
+	if newConf.Force != nil {
+		g.defaultForce = *newConf.Force
 	}
+	g.speed, g.force = g.defaultSpeed, g.defaultForce
 
 	init := [][]string{
-		{"ACT", "1"},   // robot activate
-		{"GTO", "1"},   // gripper activate
-		{"FOR", "200"}, // force (0-255)
-		{"SPE", "255"}, // speed (0-255)
+		{"ACT", "1"},                          // robot activate
+		{"GTO", "1"},                          // gripper activate
+		{"FOR", strconv.Itoa(g.defaultForce)}, // force (0-255)
+		{"SPE", strconv.Itoa(g.defaultSpeed)}, // speed (0-255)
 	}
-	err = g.MultiSet(ctx, init)
+	err := g.MultiSet(ctx, init)
 	if err != nil {
 		return nil, err
 	}
@@ -133,6 +175,8 @@ func (g *robotiqGripper) MultiSet(ctx context.Context, cmds [][]string) error {
 
 // Send TODO.
 func (g *robotiqGripper) Send(msg string) (string, error) {
+	g.connMu.Lock()
+	defer g.connMu.Unlock()
 	_, err := g.conn.Write([]byte(msg))
 	if err != nil {
 		return "", err
@@ -163,6 +207,19 @@ func (g *robotiqGripper) Get(what string) (string, error) {
 	return g.Send(fmt.Sprintf("GET %s\r\n", what))
 }
 
+// GetInt returns the value of a register.
+func (g *robotiqGripper) GetInt(what string) (int, error) {
+	res, err := g.Get(what)
+	if err != nil {
+		return 0, err
+	}
+	val, err := strconv.Atoi(strings.TrimPrefix(res, what+" "))
+	if err != nil {
+		return 0, errors.Errorf("unexpected reply to GET %s [%s]", what, res)
+	}
+	return val, nil
+}
+
 func (g *robotiqGripper) read() (string, error) {
 	buf := make([]byte, 128)
 	x, err := g.conn.Read(buf)
@@ -184,6 +241,12 @@ func (g *robotiqGripper) SetPos(ctx context.Context, pos string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	if g.stopped.Load() {
+		if err := g.Set("GTO", "1"); err != nil {
+			return false, err
+		}
+		g.stopped.Store(false)
+	}
 
 	prev := ""
 	prevCount := 0
@@ -213,12 +276,81 @@ func (g *robotiqGripper) SetPos(ctx context.Context, pos string) (bool, error) {
 	}
 }
 
-// Open TODO.
+// moveSettings are the speed, force and position registers requested for a move.
+type moveSettings struct {
+	speed, force int
+	position     string
+}
+
+// registerFromExtra returns the register value (0-255) in extra under key, if there is one.
+func registerFromExtra(extra map[string]interface{}, key string) (int, bool, error) {
+	val, ok := extra[key]
+	if !ok {
+		return 0, false, nil
+	}
+	f, ok := val.(float64)
+	if !ok || f < 0 || f > 255 || f != math.Trunc(f) {
+		return 0, false, errors.Errorf("%s must be an integer between 0 and 255, got %v", key, val)
+	}
+	return int(f), true, nil
+}
+
+// moveSettings reads the speed, force and "position" registers requested in extra, falling back to the defaults and
+// the given position.
+func (g *robotiqGripper) moveSettings(extra map[string]interface{}, position string) (moveSettings, error) {
+	settings := moveSettings{speed: g.defaultSpeed, force: g.defaultForce, position: position}
+	if speed, ok, err := registerFromExtra(extra, "speed"); err != nil {
+		return moveSettings{}, err
+	} else if ok {
+		settings.speed = speed
+	}
+	if force, ok, err := registerFromExtra(extra, "force"); err != nil {
+		return moveSettings{}, err
+	} else if ok {
+		settings.force = force
+	}
+	if fraction, ok, err := gripper.GripForce(extra); err != nil {
+		return moveSettings{}, err
+	} else if ok {
+		settings.force = int(math.Round(fraction * 255))
+	}
+	if pos, ok, err := registerFromExtra(extra, "position"); err != nil {
+		return moveSettings{}, err
+	} else if ok {
+		settings.position = strconv.Itoa(pos)
+	}
+	return settings, nil
+}
+
+// move writes the speed and force of a move if they changed and then moves to its position, returning true iff the
+// position was reached.
+func (g *robotiqGripper) move(ctx context.Context, settings moveSettings) (bool, error) {
+	if settings.speed != g.speed {
+		if err := g.Set("SPE", strconv.Itoa(settings.speed)); err != nil {
+			return false, err
+		}
+		g.speed = settings.speed
+	}
+	if settings.force != g.force {
+		if err := g.Set("FOR", strconv.Itoa(settings.force)); err != nil {
+			return false, err
+		}
+		g.force = settings.force
+	}
+	return g.SetPos(ctx, settings.position)
+}
+
+// Open opens the gripper, or only partly if extra has a "position" register value. extra may also set the "speed"
+// and "force" registers.
 func (g *robotiqGripper) Open(ctx context.Context, extra map[string]interface{}) error {
 	ctx, done := g.opMgr.New(ctx)
 	defer done()
 
-	_, err := g.SetPos(ctx, g.openLimit)
+	settings, err := g.moveSettings(extra, g.openLimit)
+	if err != nil {
+		return err
+	}
+	_, err = g.move(ctx, settings)
 	return err
 }
 
@@ -231,12 +363,18 @@ func (g *robotiqGripper) Close(ctx context.Context) error {
 	return err
 }
 
-// Grab returns true iff grabbed something.
+// Grab returns true iff grabbed something. extra may set the "speed" and "force" registers.
 func (g *robotiqGripper) Grab(ctx context.Context, extra map[string]interface{}) (bool, error) {
 	ctx, done := g.opMgr.New(ctx)
 	defer done()
 
-	res, err := g.SetPos(ctx, g.closeLimit)
+	settings, err := g.moveSettings(extra, g.closeLimit)
+	if err != nil {
+		return false, err
+	}
+	// grabbing always closes fully, so that stalling on an object is what detects it
+	settings.position = g.closeLimit
+	res, err := g.move(ctx, settings)
 	if err != nil {
 		return false, err
 	}
@@ -253,6 +391,83 @@ func (g *robotiqGripper) Grab(ctx context.Context, extra map[string]interface{})
 	return val == "OBJ 2", nil
 }
 
+// IsHoldingSomething returns whether the gripper's object detection found an object, which it does when the fingers
+// stall before reaching their requested position.
+func (g *robotiqGripper) IsHoldingSomething(ctx context.Context, extra map[string]interface{}) (gripper.HoldingStatus, error) {
+	status, err := g.GetInt("OBJ")
+	if err != nil {
+		return gripper.HoldingStatus{}, err
+	}
+	return gripper.HoldingStatus{
+		IsHoldingSomething: objectDetected(status),
+		Meta:               map[string]interface{}{"object_detection_status": status},
+	}, nil
+}
+
+// objectDetected returns whether an object detection status is 1 or 2, for objects detected while opening and
+// closing. 0 is still moving and 3 is at the requested position without finding anything.
+func objectDetected(status int) bool {
+	return status == 1 || status == 2
+}
+
+// Status reads the registers of the gripper.
+func (g *robotiqGripper) Status(ctx context.Context) (map[string]interface{}, error) {
+	registers := map[string]int{}
+	for _, what := range []string{"ACT", "GTO", "STA", "OBJ", "FLT", "PRE", "POS", "SPE", "FOR"} {
+		val, err := g.GetInt(what)
+		if err != nil {
+			return nil, err
+		}
+		registers[what] = val
+	}
+	return map[string]interface{}{
+		// activation is complete at status 3
+		"activated":               registers["STA"] == 3,
+		"activation_status":       registers["STA"],
+		"go_to":                   registers["GTO"] == 1,
+		"object_detection_status": registers["OBJ"],
+		"object_detected":         objectDetected(registers["OBJ"]),
+		"fault":                   registers["FLT"],
+		"requested_position":      registers["PRE"],
+		"position":                registers["POS"],
+		"speed":                   registers["SPE"],
+		"force":                   registers["FOR"],
+		"open_limit":              g.openLimit,
+		"close_limit":             g.closeLimit,
+		"is_moving":               g.opMgr.OpRunning(),
+	}, nil
+}
+
+// DoCommand supports "get_status", which returns the registers of the gripper, and "set_position", which moves to the
+// given "position" register value with optional "speed" and "force" registers.
+func (g *robotiqGripper) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case "get_status":
+		return g.Status(ctx)
+	case "set_position":
+		if _, ok := cmd["position"]; !ok {
+			return nil, errors.New("missing 'position' value")
+		}
+		ctx, done := g.opMgr.New(ctx)
+		defer done()
+		settings, err := g.moveSettings(cmd, "")
+		if err != nil {
+			return nil, err
+		}
+		reached, err := g.move(ctx, settings)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"reached": reached}, nil
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
 // Calibrate TODO.
 func (g *robotiqGripper) Calibrate(ctx context.Context) error {
 	err := g.Open(ctx, map[string]interface{}{})
@@ -288,6 +503,7 @@ func (g *robotiqGripper) Stop(ctx context.Context, extra map[string]interface{})
 	if err != nil {
 		return err
 	}
+	g.stopped.Store(true)
 	return nil
 }
 
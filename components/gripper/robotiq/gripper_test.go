@@ -0,0 +1,185 @@
+package robotiq
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/gripper"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// fakeURCap emulates the socket interface of a robotiq gripper whose fingers stop on an object at objectAt.
+type fakeURCap struct {
+	mu        sync.Mutex
+	registers map[string]int
+	objectAt  int
+	sets      []string
+}
+
+func (f *fakeURCap) serve(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if _, err := conn.Write([]byte(f.handle(strings.Fields(line)) + "\n")); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeURCap) handle(fields []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(fields) == 3 && fields[0] == "SET" {
+		val, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return "nack"
+		}
+		f.sets = append(f.sets, fields[1]+" "+fields[2])
+		if fields[1] == "POS" {
+			f.registers["PRE"] = val
+			f.registers["OBJ"] = 0
+		} else {
+			f.registers[fields[1]] = val
+		}
+		if fields[1] == "ACT" {
+			f.registers["STA"] = 3
+		}
+		return "ack"
+	}
+	if len(fields) != 2 || fields[0] != "GET" {
+		return "nack"
+	}
+	if fields[1] == "POS" {
+		f.step()
+	}
+	return fmt.Sprintf("%s %d", fields[1], f.registers[fields[1]])
+}
+
+// step moves the fingers towards the requested position at a rate depending on the speed register.
+func (f *fakeURCap) step() {
+	if f.registers["GTO"] != 1 || f.registers["OBJ"] != 0 {
+		return
+	}
+	pos, target := f.registers["POS"], f.registers["PRE"]
+	delta := 1 + f.registers["SPE"]/4
+	switch {
+	case pos < target:
+		pos = min(pos+delta, target)
+		if f.objectAt > 0 && pos >= f.objectAt {
+			pos = f.objectAt
+			f.registers["OBJ"] = 2
+		}
+	case pos > target:
+		pos = max(pos-delta, target)
+	}
+	f.registers["POS"] = pos
+	if f.registers["OBJ"] == 0 && pos == target {
+		f.registers["OBJ"] = 3
+	}
+}
+
+func newTestGripper(t *testing.T, fake *fakeURCap, conf *Config) *robotiqGripper {
+	t.Helper()
+	client, server := net.Pipe()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	utils.PanicCapturingGo(func() {
+		defer wg.Done()
+		fake.serve(server)
+	})
+	t.Cleanup(func() {
+		test.That(t, client.Close(), test.ShouldBeNil)
+		wg.Wait()
+	})
+	g, err := newGripperFromConn(context.Background(), resource.Config{Name: "robotiq"}, conf, client, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return g.(*robotiqGripper)
+}
+
+func TestValidate(t *testing.T) {
+	_, err := (&Config{}).Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "host")
+	speed := 300
+	_, err = (&Config{Host: "gripper", Speed: &speed}).Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "speed must be between 0 and 255")
+	speed = 100
+	_, err = (&Config{Host: "gripper", Speed: &speed}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+}
+
+func TestGripper(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeURCap{registers: map[string]int{}}
+	force := 150
+	g := newTestGripper(t, fake, &Config{Host: "gripper", Force: &force})
+	test.That(t, g.openLimit, test.ShouldEqual, "0")
+	test.That(t, g.closeLimit, test.ShouldEqual, "255")
+	test.That(t, fake.registers["FOR"], test.ShouldEqual, force)
+
+	// partial open at half speed
+	test.That(t, g.Open(ctx, map[string]interface{}{"position": 100., "speed": 128.}), test.ShouldBeNil)
+	fake.mu.Lock()
+	test.That(t, fake.registers["POS"], test.ShouldEqual, 100)
+	test.That(t, fake.registers["SPE"], test.ShouldEqual, 128)
+	fake.mu.Unlock()
+
+	err := g.Open(ctx, map[string]interface{}{"position": 300.})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "position must be an integer between 0 and 255")
+
+	// grabbing nothing closes fully, and the speed returns to its default
+	grabbed, err := g.Grab(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, grabbed, test.ShouldBeFalse)
+	status, err := g.IsHoldingSomething(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status.IsHoldingSomething, test.ShouldBeFalse)
+
+	test.That(t, g.Open(ctx, nil), test.ShouldBeNil)
+	fake.mu.Lock()
+	fake.objectAt = 180
+	fake.mu.Unlock()
+	grabbed, err = g.Grab(ctx, map[string]interface{}{gripper.GripForceKey: 1.})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, grabbed, test.ShouldBeTrue)
+	status, err = g.IsHoldingSomething(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status.IsHoldingSomething, test.ShouldBeTrue)
+	test.That(t, status.Meta["object_detection_status"], test.ShouldEqual, 2)
+
+	resp, err := g.DoCommand(ctx, map[string]interface{}{"command": "get_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["activated"], test.ShouldBeTrue)
+	test.That(t, resp["object_detected"], test.ShouldBeTrue)
+	test.That(t, resp["position"], test.ShouldEqual, 180)
+	test.That(t, resp["requested_position"], test.ShouldEqual, 255)
+	test.That(t, resp["force"], test.ShouldEqual, 255)
+	test.That(t, resp["speed"], test.ShouldEqual, defaultSpeed)
+
+	// moves after a stop set the go to flag again
+	test.That(t, g.Stop(ctx, nil), test.ShouldBeNil)
+	resp, err = g.DoCommand(ctx, map[string]interface{}{"command": "set_position", "position": 50.})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["reached"], test.ShouldBeTrue)
+	fake.mu.Lock()
+	test.That(t, fake.registers["GTO"], test.ShouldEqual, 1)
+	test.That(t, fake.registers["FOR"], test.ShouldEqual, force)
+	fake.mu.Unlock()
+
+	_, err = g.DoCommand(ctx, map[string]interface{}{"command": "set_position"})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "missing 'position' value")
+	_, err = g.DoCommand(ctx, map[string]interface{}{"command": "bad"})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no such command")
+}
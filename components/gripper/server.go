@@ -6,6 +6,7 @@ import (
 
 	commonpb "go.viam.com/api/common/v1"
 	pb "go.viam.com/api/component/gripper/v1"
+	vprotoutils "go.viam.com/utils/protoutils"
 
 	"go.viam.com/rdk/operation"
 	"go.viam.com/rdk/protoutils"
@@ -70,6 +71,10 @@ func (s *serviceServer) IsMoving(ctx context.Context, req *pb.IsMovingRequest) (
 	return &pb.IsMovingResponse{IsMoving: moving}, nil
 }
 
+// isHoldingSomethingCommand is the command which carries IsHoldingSomething over DoCommand, since the gripper
+// service has no method for it.
+const isHoldingSomethingCommand = "is_holding_something"
+
 // DoCommand receives arbitrary commands.
 func (s *serviceServer) DoCommand(ctx context.Context,
 	req *commonpb.DoCommandRequest,
@@ -78,7 +83,23 @@ func (s *serviceServer) DoCommand(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
-	return protoutils.DoFromResourceServer(ctx, gripper, req)
+	cmd := req.GetCommand().AsMap()
+	if cmd["command"] != isHoldingSomethingCommand {
+		return protoutils.DoFromResourceServer(ctx, gripper, req)
+	}
+	extra, _ := cmd["extra"].(map[string]interface{})
+	status, err := gripper.IsHoldingSomething(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	result, err := vprotoutils.StructToStructPb(map[string]interface{}{
+		"is_holding_something": status.IsHoldingSomething,
+		"meta":                 status.Meta,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &commonpb.DoCommandResponse{Result: result}, nil
 }
 
 func (s *serviceServer) GetGeometries(ctx context.Context, req *commonpb.GetGeometriesRequest) (*commonpb.GetGeometriesResponse, error) {
@@ -221,6 +221,11 @@ func (g *softGripper) Grab(ctx context.Context, extra map[string]interface{}) (b
 	return false, g.Stop(ctx, extra)
 }
 
+// IsHoldingSomething is unimplemented for softGripper, since its pressure does not tell whether anything is held.
+func (g *softGripper) IsHoldingSomething(ctx context.Context, extra map[string]interface{}) (gripper.HoldingStatus, error) {
+	return gripper.HoldingStatus{}, errors.New("softrobotics gripper cannot detect whether it is holding something")
+}
+
 // IsMoving returns whether the gripper is moving.
 func (g *softGripper) IsMoving(ctx context.Context) (bool, error) {
 	return g.opMgr.OpRunning(), nil
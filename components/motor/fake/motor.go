@@ -13,6 +13,7 @@ import (
 	fakeboard "go.viam.com/rdk/components/board/fake"
 	"go.viam.com/rdk/components/encoder"
 	"go.viam.com/rdk/components/encoder/fake"
+	"go.viam.com/rdk/components/fakeconditions"
 	"go.viam.com/rdk/components/motor"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/operation"
@@ -49,6 +50,9 @@ type Config struct {
 	MaxRPM           float64   `json:"max_rpm,omitempty"`
 	TicksPerRotation int       `json:"ticks_per_rotation,omitempty"`
 	DirectionFlip    bool      `json:"direction_flip,omitempty"`
+
+	// Conditions simulates the latency of every call and the noise of positions, in revolutions.
+	fakeconditions.Config `json:",squash"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -63,6 +67,9 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 		}
 		deps = append(deps, cfg.Encoder)
 	}
+	if err := cfg.Config.Validate(path); err != nil {
+		return nil, err
+	}
 	return deps, nil
 }
 
@@ -90,6 +97,8 @@ type Motor struct {
 
 	OpMgr  *operation.SingleOperationManager
 	Logger logging.Logger
+
+	conditions *fakeconditions.Conditions
 }
 
 // NewMotor creates a new fake motor.
@@ -169,11 +178,34 @@ func (m *Motor) Reconfigure(ctx context.Context, deps resource.Dependencies, con
 	if newConf.DirectionFlip {
 		m.DirFlip = true
 	}
+	m.conditions = fakeconditions.New(newConf.Config)
 	return nil
 }
 
+// wait waits out the simulated latency of a call.
+func (m *Motor) wait(ctx context.Context) error {
+	m.mu.Lock()
+	conditions := m.conditions
+	m.mu.Unlock()
+	return conditions.Wait(ctx)
+}
+
 // Position returns motor position in rotations.
 func (m *Motor) Position(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	if err := m.wait(ctx); err != nil {
+		return 0, err
+	}
+	pos, err := m.position(ctx, extra)
+	if err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.conditions.Noise(pos), nil
+}
+
+// position returns the motor position in rotations, without simulated noise.
+func (m *Motor) position(ctx context.Context, extra map[string]interface{}) (float64, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -202,11 +234,26 @@ func (m *Motor) Properties(ctx context.Context, extra map[string]interface{}) (m
 
 // SetPower sets the given power percentage.
 func (m *Motor) SetPower(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
+	if err := m.wait(ctx); err != nil {
+		return err
+	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	m.OpMgr.CancelRunning(ctx)
 	m.Logger.CDebugf(ctx, "Motor SetPower %f", powerPct)
+	return m.writePower(ctx, powerPct)
+}
+
+// WritePower sets the power of the motor like SetPower, without canceling its running operation, for control loops in
+// the same process.
+func (m *Motor) WritePower(powerPct float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.writePower(context.Background(), powerPct)
+}
+
+func (m *Motor) writePower(ctx context.Context, powerPct float64) error {
 	m.setPowerPct(powerPct)
 
 	if m.Encoder != nil {
@@ -290,7 +337,7 @@ func (m *Motor) GoFor(ctx context.Context, rpm, revolutions float64, extra map[s
 
 	var finalPos float64
 	if m.Encoder != nil {
-		curPos, err := m.Position(ctx, nil)
+		curPos, err := m.position(ctx, nil)
 		if err != nil {
 			return err
 		}
@@ -333,7 +380,7 @@ func (m *Motor) GoTo(ctx context.Context, rpm, pos float64, extra map[string]int
 	default:
 	}
 
-	curPos, err := m.Position(ctx, nil)
+	curPos, err := m.position(ctx, nil)
 	if err != nil {
 		return err
 	}
@@ -373,6 +420,9 @@ func (m *Motor) SetRPM(ctx context.Context, rpm float64, extra map[string]interf
 
 // Stop has the motor pretend to be off.
 func (m *Motor) Stop(ctx context.Context, extra map[string]interface{}) error {
+	if err := m.wait(ctx); err != nil {
+		return err
+	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -407,6 +457,9 @@ func (m *Motor) ResetZeroPosition(ctx context.Context, offset float64, extra map
 
 // IsPowered returns if the motor is pretending to be on or not, and its power level.
 func (m *Motor) IsPowered(ctx context.Context, extra map[string]interface{}) (bool, float64, error) {
+	if err := m.wait(ctx); err != nil {
+		return false, 0, err
+	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	return math.Abs(m.powerPct) >= 0.005, m.powerPct, nil
@@ -414,6 +467,9 @@ func (m *Motor) IsPowered(ctx context.Context, extra map[string]interface{}) (bo
 
 // IsMoving returns if the motor is pretending to be moving or not.
 func (m *Motor) IsMoving(ctx context.Context) (bool, error) {
+	if err := m.wait(ctx); err != nil {
+		return false, err
+	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	return math.Abs(m.powerPct) >= 0.005, nil
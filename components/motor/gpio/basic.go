@@ -228,13 +228,25 @@ func (m *Motor) setPWM(ctx context.Context, powerPct float64, extra map[string]i
 // indicates direction.
 func (m *Motor) SetPower(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
 	m.opMgr.CancelRunning(ctx)
-	if math.Abs(powerPct) <= 0.01 {
-		return m.Stop(ctx, extra)
-	}
-	// Stop locks/unlocks the mutex as well so in the case that the power ~= 0
-	// we want to simply rely on the mutex use in Stop
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	return m.setPower(ctx, powerPct, extra)
+}
+
+// WritePower sets the power of the motor like SetPower, without canceling its running operation, for control loops in
+// the same process.
+func (m *Motor) WritePower(powerPct float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.setPower(context.Background(), powerPct, nil)
+}
+
+// setPower sets the direction pins and the power of the motor.
+// Anything calling setPower MUST lock the motor's mutex prior.
+func (m *Motor) setPower(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
+	if math.Abs(powerPct) <= 0.01 {
+		return m.setPWM(ctx, 0, extra)
+	}
 
 	switch m.motorType {
 	case DirectionPwm:
@@ -106,6 +106,14 @@ func TestMotorABPWM(t *testing.T) {
 		test.That(t, err, test.ShouldBeNil)
 		test.That(t, on, test.ShouldBeFalse)
 		test.That(t, powerPct, test.ShouldEqual, 0)
+		// the fast path of control loops sets the same pins
+		test.That(t, gpioMotor.WritePower(0.43), test.ShouldBeNil)
+		test.That(t, mustGetGPIOPinByName(b, "1").Get(context.Background()), test.ShouldEqual, true)
+		test.That(t, mustGetGPIOPinByName(b, "3").PWM(context.Background()), test.ShouldEqual, .43)
+		test.That(t, gpioMotor.WritePower(0), test.ShouldBeNil)
+		on, _, err = m.IsPowered(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, on, test.ShouldBeFalse)
 	})
 
 	t.Run("motor (A/B/PWM) GoFor testing", func(t *testing.T) {
@@ -57,6 +57,7 @@ func (cm *controlledMotor) setupControlLoop(conf *Config) error {
 	options := control.Options{
 		PositionControlUsingTrapz: true,
 		LoopFrequency:             100.0,
+		Realtime:                  conf.Realtime,
 	}
 
 	// convert the motor config ControlParameters to the control.PIDConfig structure for use in setup_control.go
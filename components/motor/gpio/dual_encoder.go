@@ -0,0 +1,252 @@
+package gpio
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/components/encoder"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/logging"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+const (
+	// dualEncoderCheckInterval is how often the two encoders are compared.
+	dualEncoderCheckInterval = 50 * time.Millisecond
+	// maxSecondaryReadFailures is how many times in a row either encoder can fail to be read before the motor faults,
+	// since an axis which cannot be checked is no safer than one whose encoders disagree.
+	maxSecondaryReadFailures = 5
+)
+
+// dualEncoderMotor checks the position of a motor against a secondary encoder on the same axis, and faults when the two
+// disagree by more than a threshold, as they do when a belt slips or a coupling fails. A faulted motor is stopped and
+// refuses to move until the fault is cleared, once the axis has been checked:
+//
+//	{"command": "encoder_status"}
+//	{"command": "clear_fault"}
+type dualEncoderMotor struct {
+	motor.Motor
+	secondary                 encoder.Encoder
+	secondaryTicksPerRotation float64
+	threshold                 float64
+	logger                    logging.Logger
+	workers                   rdkutils.StoppableWorkers
+
+	// mu makes a move and a fault atomic with respect to each other, so that no move starts after the motor faults.
+	mu sync.Mutex
+	// offset is the difference of the positions when the encoders were last taken to agree.
+	offset       float64
+	primary      float64
+	secondaryPos float64
+	disagreement float64
+	readFailures int
+	fault        error
+}
+
+func newDualEncoderMotor(
+	ctx context.Context,
+	m motor.Motor,
+	secondary encoder.Encoder,
+	conf Config,
+	logger logging.Logger,
+) (*dualEncoderMotor, error) {
+	ticksPerRotation := conf.SecondaryTicksPerRotation
+	if ticksPerRotation == 0 {
+		ticksPerRotation = conf.TicksPerRotation
+	}
+	dm := &dualEncoderMotor{
+		Motor:                     m,
+		secondary:                 secondary,
+		secondaryTicksPerRotation: float64(ticksPerRotation),
+		threshold:                 conf.DisagreementThresholdRevolutions,
+		logger:                    logger,
+	}
+	if err := dm.resync(ctx); err != nil {
+		return nil, errors.Wrap(err, "cannot read the encoders of the motor")
+	}
+	dm.workers = rdkutils.NewStoppableWorkers(func(ctx context.Context) {
+		ticker := time.NewTicker(dualEncoderCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			dm.check(ctx)
+		}
+	})
+	return dm, nil
+}
+
+// positions returns the positions of the motor and of the secondary encoder in revolutions.
+func (dm *dualEncoderMotor) positions(ctx context.Context) (float64, float64, error) {
+	primary, err := dm.Motor.Position(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	value, positionType, err := dm.secondary.Position(ctx, encoder.PositionTypeUnspecified, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	if positionType == encoder.PositionTypeDegrees {
+		return primary, value / 360, nil
+	}
+	return primary, value / dm.secondaryTicksPerRotation, nil
+}
+
+// resync takes the encoders to agree where they are now.
+func (dm *dualEncoderMotor) resync(ctx context.Context) error {
+	primary, secondary, err := dm.positions(ctx)
+	if err != nil {
+		return err
+	}
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.offset = primary - secondary
+	dm.primary, dm.secondaryPos, dm.disagreement = primary, secondary, 0
+	dm.readFailures = 0
+	return nil
+}
+
+// check compares the encoders, and faults and stops the motor when they disagree.
+func (dm *dualEncoderMotor) check(ctx context.Context) {
+	primary, secondary, err := dm.positions(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+
+	dm.mu.Lock()
+	if dm.fault != nil {
+		dm.mu.Unlock()
+		return
+	}
+	if err != nil {
+		dm.readFailures++
+		if dm.readFailures < maxSecondaryReadFailures {
+			dm.mu.Unlock()
+			return
+		}
+		dm.fault = errors.Wrapf(err, "encoders of motor %q cannot be read", dm.Name().ShortName())
+	} else {
+		dm.readFailures = 0
+		dm.primary, dm.secondaryPos = primary, secondary
+		dm.disagreement = primary - secondary - dm.offset
+		if math.Abs(dm.disagreement) > dm.threshold {
+			dm.fault = errors.Errorf("encoders of motor %q disagree by %.3f revolutions, more than %.3f",
+				dm.Name().ShortName(), dm.disagreement, dm.threshold)
+		}
+	}
+	fault := dm.fault
+	dm.mu.Unlock()
+	if fault == nil {
+		return
+	}
+
+	dm.logger.CErrorw(ctx, "motor faulted, stopping it", "error", fault)
+	if err := dm.Motor.Stop(ctx, nil); err != nil {
+		dm.logger.CErrorw(ctx, "cannot stop faulted motor", "error", err)
+	}
+}
+
+// faulted returns an error when the motor is faulted and cannot move.
+func (dm *dualEncoderMotor) faulted() error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	if dm.fault == nil {
+		return nil
+	}
+	return errors.Wrap(dm.fault, "motor is faulted, send clear_fault once the axis has been checked")
+}
+
+// SetPower sets the power of the motor unless it is faulted.
+func (dm *dualEncoderMotor) SetPower(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
+	if err := dm.faulted(); err != nil {
+		return err
+	}
+	return dm.Motor.SetPower(ctx, powerPct, extra)
+}
+
+// GoFor moves the motor unless it is faulted.
+func (dm *dualEncoderMotor) GoFor(ctx context.Context, rpm, revolutions float64, extra map[string]interface{}) error {
+	if err := dm.faulted(); err != nil {
+		return err
+	}
+	if err := dm.Motor.GoFor(ctx, rpm, revolutions, extra); err != nil {
+		return err
+	}
+	// a fault stops the motor, which ends the move early
+	return dm.faulted()
+}
+
+// GoTo moves the motor unless it is faulted.
+func (dm *dualEncoderMotor) GoTo(ctx context.Context, rpm, positionRevolutions float64, extra map[string]interface{}) error {
+	if err := dm.faulted(); err != nil {
+		return err
+	}
+	if err := dm.Motor.GoTo(ctx, rpm, positionRevolutions, extra); err != nil {
+		return err
+	}
+	return dm.faulted()
+}
+
+// SetRPM runs the motor unless it is faulted.
+func (dm *dualEncoderMotor) SetRPM(ctx context.Context, rpm float64, extra map[string]interface{}) error {
+	if err := dm.faulted(); err != nil {
+		return err
+	}
+	return dm.Motor.SetRPM(ctx, rpm, extra)
+}
+
+// ResetZeroPosition resets the position of the motor, and the encoders are taken to agree at the new position.
+func (dm *dualEncoderMotor) ResetZeroPosition(ctx context.Context, offset float64, extra map[string]interface{}) error {
+	if err := dm.Motor.ResetZeroPosition(ctx, offset, extra); err != nil {
+		return err
+	}
+	return dm.resync(ctx)
+}
+
+// DoCommand returns the state of the encoders for "encoder_status", clears a fault for "clear_fault", and passes any
+// other command on to the motor.
+func (dm *dualEncoderMotor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	switch cmd["command"] {
+	case "encoder_status":
+		dm.mu.Lock()
+		defer dm.mu.Unlock()
+		status := map[string]interface{}{
+			"primary_revolutions":      dm.primary,
+			"secondary_revolutions":    dm.secondaryPos,
+			"disagreement_revolutions": dm.disagreement,
+			"threshold_revolutions":    dm.threshold,
+			"faulted":                  dm.fault != nil,
+		}
+		if dm.fault != nil {
+			status["fault"] = dm.fault.Error()
+		}
+		return status, nil
+	case "clear_fault":
+		if err := dm.resync(ctx); err != nil {
+			return nil, errors.Wrap(err, "cannot read the encoders of the motor")
+		}
+		dm.mu.Lock()
+		defer dm.mu.Unlock()
+		if dm.fault != nil {
+			dm.logger.CInfow(ctx, "motor fault cleared", "fault", dm.fault)
+		}
+		dm.fault = nil
+		return map[string]interface{}{}, nil
+	default:
+		return dm.Motor.DoCommand(ctx, cmd)
+	}
+}
+
+// Close stops comparing the encoders and closes the motor.
+func (dm *dualEncoderMotor) Close(ctx context.Context) error {
+	dm.workers.Stop()
+	return multierr.Combine(dm.Motor.Stop(ctx, nil), dm.Motor.Close(ctx))
+}
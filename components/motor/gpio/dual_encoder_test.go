@@ -0,0 +1,137 @@
+package gpio
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/encoder"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestDualEncoderValidate(t *testing.T) {
+	conf := Config{
+		BoardName:        boardName,
+		Pins:             PinConfig{A: "1", B: "2"},
+		Encoder:          encoderName,
+		TicksPerRotation: 100,
+		SecondaryEncoder: "secondary",
+	}
+	_, err := conf.Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "disagreement_threshold_revolutions")
+
+	conf.DisagreementThresholdRevolutions = 0.5
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{boardName, encoderName, "secondary"})
+
+	conf.Encoder = ""
+	conf.MaxRPM = 100
+	_, err = conf.Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "encoder")
+}
+
+func TestDualEncoderMotor(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	var mu sync.Mutex
+	primary, secondaryTicks := 2.0, 0.0
+	var readErr error
+	stopped := 0
+	setPosition := func(p, ticks float64) {
+		mu.Lock()
+		defer mu.Unlock()
+		primary, secondaryTicks = p, ticks
+	}
+	stops := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return stopped
+	}
+
+	m := inject.NewMotor(motorName)
+	m.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return primary, nil
+	}
+	m.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		stopped++
+		return nil
+	}
+	m.SetPowerFunc = func(ctx context.Context, powerPct float64, extra map[string]interface{}) error { return nil }
+	m.GoForFunc = func(ctx context.Context, rpm, revolutions float64, extra map[string]interface{}) error { return nil }
+	m.ResetZeroPositionFunc = func(ctx context.Context, offset float64, extra map[string]interface{}) error {
+		setPosition(offset, secondaryTicks)
+		return nil
+	}
+	secondary := inject.NewEncoder("secondary")
+	secondary.PositionFunc = func(
+		ctx context.Context, positionType encoder.PositionType, extra map[string]interface{},
+	) (float64, encoder.PositionType, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return secondaryTicks, encoder.PositionTypeTicks, readErr
+	}
+
+	conf := Config{TicksPerRotation: 100, SecondaryTicksPerRotation: 400, DisagreementThresholdRevolutions: 0.25}
+	dm, err := newDualEncoderMotor(ctx, m, secondary, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer dm.workers.Stop()
+
+	// the encoders agree when they move together from where they started
+	setPosition(3, 400)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		status, err := dm.DoCommand(ctx, map[string]interface{}{"command": "encoder_status"})
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, status["primary_revolutions"], test.ShouldEqual, 3)
+		test.That(tb, status["secondary_revolutions"], test.ShouldEqual, 1)
+		test.That(tb, status["disagreement_revolutions"], test.ShouldEqual, 0)
+		test.That(tb, status["faulted"], test.ShouldBeFalse)
+	})
+	test.That(t, dm.SetPower(ctx, 0.5, nil), test.ShouldBeNil)
+
+	// the belt slips, so the motor turns without the load
+	setPosition(4, 400)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, stops(), test.ShouldEqual, 1)
+	})
+	status, err := dm.DoCommand(ctx, map[string]interface{}{"command": "encoder_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["faulted"], test.ShouldBeTrue)
+	test.That(t, status["fault"], test.ShouldContainSubstring, "disagree by 1.000 revolutions")
+	err = dm.SetPower(ctx, 0.5, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "motor is faulted")
+	test.That(t, dm.GoFor(ctx, 10, 1, nil), test.ShouldNotBeNil)
+
+	// clearing the fault takes the encoders to agree where they are now
+	_, err = dm.DoCommand(ctx, map[string]interface{}{"command": "clear_fault"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dm.GoFor(ctx, 10, 1, nil), test.ShouldBeNil)
+	test.That(t, dm.ResetZeroPosition(ctx, 0, nil), test.ShouldBeNil)
+	status, err = dm.DoCommand(ctx, map[string]interface{}{"command": "encoder_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["disagreement_revolutions"], test.ShouldEqual, 0)
+	test.That(t, status["faulted"], test.ShouldBeFalse)
+
+	// an encoder which cannot be read faults the motor too
+	mu.Lock()
+	readErr = errors.New("disconnected")
+	mu.Unlock()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, stops(), test.ShouldEqual, 2)
+	})
+	err = dm.SetPower(ctx, 0.5, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "disconnected")
+}
@@ -18,6 +18,7 @@ import (
 	"go.viam.com/rdk/operation"
 	"go.viam.com/rdk/resource"
 	rdkutils "go.viam.com/rdk/utils"
+	"go.viam.com/rdk/utils/realtime"
 )
 
 // WrapMotorWithEncoder takes a motor and adds an encoder onto it in order to understand its odometry.
@@ -113,6 +114,8 @@ type EncodedMotor struct {
 
 	mu             sync.RWMutex
 	rpmMonitorDone func()
+	// realtimeWarning warns once that the real-time scheduling hints cannot be applied
+	realtimeWarning sync.Once
 
 	// how fast as we increase power do we do so
 	// valid numbers are (0, 1]
@@ -317,6 +320,9 @@ func (m *EncodedMotor) goForInternal(rpm, goalPos, direction float64) error {
 	m.activeBackgroundWorkers.Add(1)
 	go func() {
 		defer m.activeBackgroundWorkers.Done()
+		if err := realtime.Enter(m.cfg.Realtime); err != nil {
+			m.realtimeWarning.Do(func() { m.logger.Warn(err) })
+		}
 		if err := m.real.SetPower(rpmCtx, 0.2*direction, nil); err != nil {
 			m.logger.Error(err)
 			return
@@ -11,6 +11,7 @@ import (
 	"go.viam.com/rdk/components/motor"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils/realtime"
 )
 
 var model = resource.DefaultModelFamily.WithModel("gpio")
@@ -120,6 +121,13 @@ type Config struct {
 	MaxRPM            float64         `json:"max_rpm,omitempty"`
 	TicksPerRotation  int             `json:"ticks_per_rotation,omitempty"`
 	ControlParameters *motorPIDConfig `json:"control_parameters,omitempty"`
+	// Realtime applies real-time scheduling hints to the loop controlling the motor, when it has an encoder
+	Realtime *realtime.Config `json:"realtime,omitempty"`
+	// SecondaryEncoder is an encoder on the load side of the axis, checked against the encoder of the motor, which
+	// faults and stops the motor when the two disagree by more than DisagreementThresholdRevolutions
+	SecondaryEncoder                 string  `json:"secondary_encoder,omitempty"`
+	SecondaryTicksPerRotation        int     `json:"secondary_ticks_per_rotation,omitempty"` // ticks_per_rotation by default
+	DisagreementThresholdRevolutions float64 `json:"disagreement_threshold_revolutions,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -146,6 +154,21 @@ func (conf *Config) Validate(path string) ([]string, error) {
 	} else if conf.MaxRPM <= 0 {
 		return nil, resource.NewConfigValidationFieldRequiredError(path, "max_rpm")
 	}
+	if conf.SecondaryEncoder != "" {
+		if conf.Encoder == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "encoder")
+		}
+		if conf.SecondaryTicksPerRotation < 0 {
+			return nil, resource.NewConfigValidationError(path, errors.New("secondary_ticks_per_rotation cannot be negative"))
+		}
+		if conf.DisagreementThresholdRevolutions <= 0 {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "disagreement_threshold_revolutions")
+		}
+		deps = append(deps, conf.SecondaryEncoder)
+	}
+	if err := conf.Realtime.Validate(path); err != nil {
+		return nil, err
+	}
 	return deps, nil
 }
 
@@ -220,6 +243,17 @@ func createNewMotor(
 		}
 	}
 
+	if motorConfig.SecondaryEncoder != "" {
+		secondary, err := encoder.FromDependencies(deps, motorConfig.SecondaryEncoder)
+		if err != nil {
+			return nil, err
+		}
+		m, err = newDualEncoderMotor(ctx, m, secondary, *motorConfig, logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	err = m.Stop(ctx, nil)
 	if err != nil {
 		return nil, err
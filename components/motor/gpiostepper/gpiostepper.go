@@ -44,6 +44,7 @@ import (
 	"go.viam.com/rdk/operation"
 	"go.viam.com/rdk/resource"
 	rdkutils "go.viam.com/rdk/utils"
+	"go.viam.com/rdk/utils/realtime"
 )
 
 var model = resource.DefaultModelFamily.WithModel("gpiostepper")
@@ -62,6 +63,8 @@ type Config struct {
 	BoardName        string    `json:"board"`
 	StepperDelay     int       `json:"stepper_delay_usec,omitempty"` // When using stepper motors, the time to remain high
 	TicksPerRotation int       `json:"ticks_per_rotation"`
+	// Realtime applies real-time scheduling hints to the thread which steps the motor
+	Realtime *realtime.Config `json:"realtime,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -79,6 +82,9 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 	if cfg.Pins.Step == "" {
 		return nil, resource.NewConfigValidationFieldRequiredError(path, "step")
 	}
+	if err := cfg.Realtime.Validate(path); err != nil {
+		return nil, err
+	}
 	deps = append(deps, cfg.BoardName)
 	return deps, nil
 }
@@ -135,6 +141,7 @@ func newGPIOStepper(
 		Named:            name.AsNamed(),
 		theBoard:         b,
 		stepsPerRotation: mc.TicksPerRotation,
+		realtime:         mc.Realtime,
 		logger:           logger,
 		opMgr:            operation.NewSingleOperationManager(),
 	}
@@ -191,6 +198,7 @@ type gpioStepper struct {
 	minDelay                    time.Duration
 	enablePinHigh, enablePinLow board.GPIOPin
 	stepPin, dirPin             board.GPIOPin
+	realtime                    *realtime.Config
 	logger                      logging.Logger
 
 	// state
@@ -246,6 +254,9 @@ func (m *gpioStepper) startThread() {
 	m.waitGroup.Add(1)
 	go func() {
 		defer m.waitGroup.Done()
+		if err := realtime.Enter(m.realtime); err != nil {
+			m.logger.Warnf("stepping thread for motor (%s) is not realtime: %s", m.Name().Name, err.Error())
+		}
 		for {
 			sleep, err := m.doCycle(ctxWG)
 			if err != nil {
@@ -0,0 +1,669 @@
+//go:build linux
+
+// Package bno055 implements the movementsensor interface for a Bosch BNO055 9-axis IMU over I2C. A
+// datasheet for this chip is at
+// https://www.bosch-sensortec.com/media/boschsensortec/downloads/datasheets/bst-bno055-ds000.pdf
+//
+// The chip fuses its accelerometer, gyroscope and magnetometer itself, so we report its fused
+// orientation and compass heading alongside the raw angular velocity and linear acceleration. It
+// calibrates itself continuously as it is moved, but forgets its calibration whenever it loses
+// power. The save_calibration DoCommand reads the calibration offsets once the chip is calibrated,
+// and they are restored at startup from the calibration_file or calibration attributes:
+//
+//	{"command": "calibration_status"}
+//	{"command": "save_calibration"}
+//	{"command": "restore_calibration", "calibration": {...}}
+//
+// The BNO08x chips speak the SHTP protocol rather than the register map of the BNO055, and are
+// supported by the imu-bno08x model instead.
+//
+// The chip has two possible I2C addresses, which can be selected by wiring the COM3 pin:
+//   - if COM3 is wired to ground, it uses the default I2C address of 0x28
+//   - if COM3 is wired to hot, it uses the alternate I2C address of 0x29
+package bno055
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("imu-bno055")
+
+const (
+	defaultAddress   = 0x28
+	alternateAddress = 0x29
+	expectedChipID   = 0xA0
+
+	// registers of page 0 of the register map
+	chipIDRegister      = 0x00
+	dataRegister        = 0x08 // the start of the accelerometer, magnetometer, gyroscope, euler, quaternion data
+	calibStatusRegister = 0x35
+	unitSelectRegister  = 0x3B
+	opModeRegister      = 0x3D
+	powerModeRegister   = 0x3E
+	sysTriggerRegister  = 0x3F
+	pageIDRegister      = 0x07
+	offsetsRegister     = 0x55
+
+	// dataLength is the length of the data from dataRegister up to and including the calibration status.
+	dataLength    = calibStatusRegister - dataRegister + 1
+	offsetsLength = 22
+
+	configMode = 0x00
+	imuMode    = 0x08 // fusion of the accelerometer and gyroscope
+	ndofMode   = 0x0C // fusion of all three sensors
+
+	suspendMode     = 0x02
+	externalCrystal = 0x80
+
+	// The chip takes 19ms to switch to config mode, and 7ms to switch to any other.
+	modeSwitchTime = 25 * time.Millisecond
+	// The chip fuses its sensors at 100Hz.
+	pollInterval = 10 * time.Millisecond
+
+	// scales of the data registers, in least significant bits per unit
+	accelScale = 100.0   // m/s^2
+	gyroScale  = 16.0    // degrees per second
+	eulerScale = 16.0    // degrees
+	quatScale  = 1 << 14 // unit quaternion
+)
+
+// Calibration is the calibration offsets of the chip, as the save_calibration DoCommand returns them.
+type Calibration struct {
+	AccelerometerOffset [3]int16 `json:"accelerometer_offset"`
+	MagnetometerOffset  [3]int16 `json:"magnetometer_offset"`
+	GyroscopeOffset     [3]int16 `json:"gyroscope_offset"`
+	AccelerometerRadius int16    `json:"accelerometer_radius"`
+	MagnetometerRadius  int16    `json:"magnetometer_radius"`
+}
+
+func (c *Calibration) toBytes() []byte {
+	values := []int16{
+		c.AccelerometerOffset[0], c.AccelerometerOffset[1], c.AccelerometerOffset[2],
+		c.MagnetometerOffset[0], c.MagnetometerOffset[1], c.MagnetometerOffset[2],
+		c.GyroscopeOffset[0], c.GyroscopeOffset[1], c.GyroscopeOffset[2],
+		c.AccelerometerRadius, c.MagnetometerRadius,
+	}
+	data := make([]byte, 0, offsetsLength)
+	for _, v := range values {
+		data = append(data, byte(v), byte(uint16(v)>>8))
+	}
+	return data
+}
+
+func calibrationFromBytes(data []byte) Calibration {
+	v := func(i int) int16 { return utils.Int16FromBytesLE(data[2*i : 2*i+2]) }
+	return Calibration{
+		AccelerometerOffset: [3]int16{v(0), v(1), v(2)},
+		MagnetometerOffset:  [3]int16{v(3), v(4), v(5)},
+		GyroscopeOffset:     [3]int16{v(6), v(7), v(8)},
+		AccelerometerRadius: v(9),
+		MagnetometerRadius:  v(10),
+	}
+}
+
+// CalibrationStatus is how well calibrated each subsystem of the chip is, from 0 for uncalibrated to 3 for
+// fully calibrated.
+type CalibrationStatus struct {
+	System        int `json:"system"`
+	Gyroscope     int `json:"gyroscope"`
+	Accelerometer int `json:"accelerometer"`
+	Magnetometer  int `json:"magnetometer"`
+}
+
+func calibrationStatusFromByte(b byte) CalibrationStatus {
+	return CalibrationStatus{
+		System:        int(b >> 6 & 0x03),
+		Gyroscope:     int(b >> 4 & 0x03),
+		Accelerometer: int(b >> 2 & 0x03),
+		Magnetometer:  int(b & 0x03),
+	}
+}
+
+func (s CalibrationStatus) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"system":        s.System,
+		"gyroscope":     s.Gyroscope,
+		"accelerometer": s.Accelerometer,
+		"magnetometer":  s.Magnetometer,
+	}
+}
+
+// Config is used to configure the attributes of the chip.
+type Config struct {
+	I2cBus                 string `json:"i2c_bus"`
+	UseAlternateI2CAddress bool   `json:"use_alt_i2c_address,omitempty"`
+	// DisableMagnetometer fuses the accelerometer and gyroscope alone, for where magnetic interference makes the
+	// magnetometer worse than useless. There is no compass heading without it.
+	DisableMagnetometer bool `json:"disable_magnetometer,omitempty"`
+	UseExternalCrystal  bool `json:"use_external_crystal,omitempty"`
+	// CalibrationFile is where save_calibration saves the calibration, which is restored from it at startup.
+	// Calibration is restored at startup instead until the calibration file has been saved.
+	CalibrationFile string       `json:"calibration_file,omitempty"`
+	Calibration     *Calibration `json:"calibration,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid, and then returns the list of things we
+// depend on.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.I2cBus == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "i2c_bus")
+	}
+
+	var deps []string
+	return deps, nil
+}
+
+func init() {
+	resource.RegisterComponent(movementsensor.API, model, resource.Registration[movementsensor.MovementSensor, *Config]{
+		Constructor: newBno055,
+	})
+}
+
+type bno055 struct {
+	resource.Named
+	resource.AlwaysRebuild
+	bus             buses.I2C
+	i2cAddress      byte
+	fusionMode      byte
+	calibrationFile string
+	// busMu keeps the background goroutine from reading while the chip is in config mode.
+	busMu sync.Mutex
+	mu    sync.Mutex
+
+	// lock the mutex before reading or writing these.
+	angularVelocity    spatialmath.AngularVelocity
+	linearAcceleration r3.Vector
+	orientation        spatialmath.Orientation
+	heading            float64
+	temperature        float64
+	calibrationStatus  CalibrationStatus
+	// Stores the most recent error from the background goroutine
+	err movementsensor.LastError
+	// samples streams every reading of the background goroutine.
+	samples movementsensor.SampleBroadcaster
+
+	workers utils.StoppableWorkers
+	logger  logging.Logger
+}
+
+func addressReadError(err error, address byte, bus string) error {
+	msg := fmt.Sprintf("can't read from I2C address %d on bus %s", address, bus)
+	return errors.Wrap(err, msg)
+}
+
+func unexpectedDeviceError(address, chipID byte) error {
+	return errors.Errorf("unexpected non-BNO055 device at address %d: chip ID '%d'", address, chipID)
+}
+
+// newBno055 constructs a new BNO055 object.
+func newBno055(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (movementsensor.MovementSensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	bus, err := buses.NewI2cBus(newConf.I2cBus)
+	if err != nil {
+		return nil, err
+	}
+	return makeBno055(ctx, deps, conf, logger, bus)
+}
+
+// This function is separated from newBno055 solely so you can inject a mock I2C bus in tests.
+func makeBno055(
+	ctx context.Context,
+	_ resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+	bus buses.I2C,
+) (movementsensor.MovementSensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	address := byte(defaultAddress)
+	if newConf.UseAlternateI2CAddress {
+		address = alternateAddress
+	}
+	fusionMode := byte(ndofMode)
+	if newConf.DisableMagnetometer {
+		fusionMode = imuMode
+	}
+
+	sensor := &bno055{
+		Named:           conf.ResourceName().AsNamed(),
+		bus:             bus,
+		i2cAddress:      address,
+		fusionMode:      fusionMode,
+		calibrationFile: newConf.CalibrationFile,
+		orientation:     spatialmath.NewZeroOrientation(),
+		logger:          logger,
+		// On overloaded boards, the I2C bus can become flaky. Only report errors if at least 5 of
+		// the last 10 attempts to talk to the device have failed.
+		err: movementsensor.NewLastError(10, 5),
+	}
+
+	chipID, err := sensor.readByte(ctx, chipIDRegister)
+	if err != nil {
+		return nil, addressReadError(err, address, newConf.I2cBus)
+	}
+	if chipID != expectedChipID {
+		return nil, unexpectedDeviceError(address, chipID)
+	}
+
+	// The chip can only be set up in config mode.
+	sysTrigger := byte(0)
+	if newConf.UseExternalCrystal {
+		sysTrigger = externalCrystal
+	}
+	if err := sensor.setMode(ctx, configMode); err != nil {
+		return nil, errors.Wrap(err, "unable to put BNO055 into config mode")
+	}
+	for _, w := range []struct{ register, value byte }{
+		{pageIDRegister, 0},
+		{powerModeRegister, 0},
+		{sysTriggerRegister, sysTrigger},
+		// m/s^2, degrees per second, degrees and Celsius
+		{unitSelectRegister, 0},
+	} {
+		if err := sensor.writeByte(ctx, w.register, w.value); err != nil {
+			return nil, errors.Wrap(err, "unable to set up BNO055")
+		}
+	}
+
+	calibration, err := sensor.startupCalibration(newConf)
+	if err != nil {
+		return nil, err
+	}
+	if calibration != nil {
+		if err := sensor.writeBlock(ctx, offsetsRegister, calibration.toBytes()); err != nil {
+			return nil, errors.Wrap(err, "unable to restore BNO055 calibration")
+		}
+	}
+
+	if err := sensor.setMode(ctx, fusionMode); err != nil {
+		return nil, errors.Wrap(err, "unable to start BNO055 fusion")
+	}
+
+	// Now, turn on the background goroutine that constantly reads from the chip and stores data in
+	// the object we created.
+	sensor.workers = utils.NewStoppableWorkers(func(cancelCtx context.Context) {
+		timer := time.NewTicker(pollInterval)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-timer.C:
+				sensor.busMu.Lock()
+				rawData, err := sensor.readBlock(cancelCtx, dataRegister, dataLength)
+				sensor.busMu.Unlock()
+				// Record `err` no matter what: even if it's nil, that's useful information.
+				sensor.err.Set(err)
+				if err != nil {
+					sensor.logger.CErrorf(ctx, "error reading BNO055 sensor: '%s'", err)
+					continue
+				}
+				sensor.update(rawData)
+			case <-cancelCtx.Done():
+				return
+			}
+		}
+	})
+
+	return sensor, nil
+}
+
+// startupCalibration returns the calibration to restore at startup, from the calibration file if it has been
+// saved, or else from the config.
+func (imu *bno055) startupCalibration(conf *Config) (*Calibration, error) {
+	if imu.calibrationFile != "" {
+		data, err := os.ReadFile(filepath.Clean(imu.calibrationFile))
+		switch {
+		case err == nil:
+			var calibration Calibration
+			if err := json.Unmarshal(data, &calibration); err != nil {
+				return nil, errors.Wrapf(err, "unable to parse calibration file %s", imu.calibrationFile)
+			}
+			return &calibration, nil
+		case !os.IsNotExist(err):
+			return nil, err
+		}
+	}
+	return conf.Calibration, nil
+}
+
+// update parses the data registers, from the accelerometer data to the calibration status.
+func (imu *bno055) update(data []byte) {
+	vector := func(offset int, scale float64) r3.Vector {
+		return r3.Vector{
+			X: float64(utils.Int16FromBytesLE(data[offset:offset+2])) / scale,
+			Y: float64(utils.Int16FromBytesLE(data[offset+2:offset+4])) / scale,
+			Z: float64(utils.Int16FromBytesLE(data[offset+4:offset+6])) / scale,
+		}
+	}
+	value := func(offset int, scale float64) float64 {
+		return float64(utils.Int16FromBytesLE(data[offset:offset+2])) / scale
+	}
+
+	linearAcceleration := vector(0x08-dataRegister, accelScale)
+	gyro := vector(0x14-dataRegister, gyroScale)
+	heading := value(0x1A-dataRegister, eulerScale)
+	quat := &spatialmath.Quaternion{
+		Real: value(0x20-dataRegister, quatScale),
+		Imag: value(0x22-dataRegister, quatScale),
+		Jmag: value(0x24-dataRegister, quatScale),
+		Kmag: value(0x26-dataRegister, quatScale),
+	}
+	temperature := float64(int8(data[0x34-dataRegister]))
+	status := calibrationStatusFromByte(data[calibStatusRegister-dataRegister])
+
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	imu.linearAcceleration = linearAcceleration
+	imu.angularVelocity = spatialmath.AngularVelocity{X: gyro.X, Y: gyro.Y, Z: gyro.Z}
+	imu.heading = heading
+	// the quaternion is all zeros until the chip has fused its first reading
+	if quat.Real != 0 || quat.Imag != 0 || quat.Jmag != 0 || quat.Kmag != 0 {
+		imu.orientation = quat
+	}
+	imu.temperature = temperature
+	imu.calibrationStatus = status
+	imu.samples.Publish(movementsensor.Sample{
+		Time:               time.Now(),
+		LinearAcceleration: imu.linearAcceleration,
+		AngularVelocity:    imu.angularVelocity,
+		Orientation:        imu.orientation,
+	})
+}
+
+func (imu *bno055) setMode(ctx context.Context, mode byte) error {
+	if err := imu.writeByte(ctx, opModeRegister, mode); err != nil {
+		return err
+	}
+	if !goutils.SelectContextOrWait(ctx, modeSwitchTime) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// inConfigMode runs f with the chip in config mode, which the calibration offsets can only be read and written
+// in, and then returns the chip to fusing its sensors.
+func (imu *bno055) inConfigMode(ctx context.Context, f func() error) error {
+	imu.busMu.Lock()
+	defer imu.busMu.Unlock()
+	if err := imu.setMode(ctx, configMode); err != nil {
+		return err
+	}
+	fErr := f()
+	// Switch back even if f failed, so the chip is not left in config mode.
+	if err := imu.setMode(ctx, imu.fusionMode); err != nil {
+		return errors.Wrap(err, "unable to restart BNO055 fusion")
+	}
+	return fErr
+}
+
+func (imu *bno055) readCalibration(ctx context.Context) (Calibration, error) {
+	var calibration Calibration
+	err := imu.inConfigMode(ctx, func() error {
+		data, err := imu.readBlock(ctx, offsetsRegister, offsetsLength)
+		if err != nil {
+			return err
+		}
+		if len(data) != offsetsLength {
+			return errors.Errorf("read %d bytes of calibration offsets, expected %d", len(data), offsetsLength)
+		}
+		calibration = calibrationFromBytes(data)
+		return nil
+	})
+	return calibration, err
+}
+
+func (imu *bno055) writeCalibration(ctx context.Context, calibration Calibration) error {
+	return imu.inConfigMode(ctx, func() error {
+		return imu.writeBlock(ctx, offsetsRegister, calibration.toBytes())
+	})
+}
+
+func (imu *bno055) readByte(ctx context.Context, register byte) (byte, error) {
+	result, err := imu.readBlock(ctx, register, 1)
+	if err != nil {
+		return 0, err
+	}
+	if len(result) != 1 {
+		return 0, errors.Errorf("read %d bytes from register %d, expected 1", len(result), register)
+	}
+	return result[0], nil
+}
+
+func (imu *bno055) readBlock(ctx context.Context, register byte, length uint8) ([]byte, error) {
+	handle, err := imu.bus.OpenHandle(imu.i2cAddress)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := handle.Close()
+		if err != nil {
+			imu.logger.CError(ctx, err)
+		}
+	}()
+
+	return handle.ReadBlockData(ctx, register, length)
+}
+
+func (imu *bno055) writeByte(ctx context.Context, register, value byte) error {
+	handle, err := imu.bus.OpenHandle(imu.i2cAddress)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := handle.Close()
+		if err != nil {
+			imu.logger.CError(ctx, err)
+		}
+	}()
+
+	return handle.WriteByteData(ctx, register, value)
+}
+
+func (imu *bno055) writeBlock(ctx context.Context, register byte, data []byte) error {
+	handle, err := imu.bus.OpenHandle(imu.i2cAddress)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := handle.Close()
+		if err != nil {
+			imu.logger.CError(ctx, err)
+		}
+	}()
+
+	return handle.WriteBlockData(ctx, register, data)
+}
+
+func (imu *bno055) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	return imu.angularVelocity, imu.err.Get()
+}
+
+func (imu *bno055) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	return r3.Vector{}, movementsensor.ErrMethodUnimplementedLinearVelocity
+}
+
+func (imu *bno055) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	return imu.linearAcceleration, imu.err.Get()
+}
+
+// Orientation returns the orientation the chip fused from its sensors.
+func (imu *bno055) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	return imu.orientation, imu.err.Get()
+}
+
+// CompassHeading returns the heading clockwise from magnetic north the chip fused from its sensors.
+func (imu *bno055) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	if imu.fusionMode != ndofMode {
+		return 0, movementsensor.ErrMethodUnimplementedCompassHeading
+	}
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	return imu.heading, imu.err.Get()
+}
+
+func (imu *bno055) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	return geo.NewPoint(0, 0), 0, movementsensor.ErrMethodUnimplementedPosition
+}
+
+// Accuracy returns the calibration status of each subsystem, from 0 for uncalibrated to 3 for fully calibrated.
+func (imu *bno055) Accuracy(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	acc := movementsensor.UnimplementedOptionalAccuracies()
+	acc.AccuracyMap = map[string]float32{
+		"calibration_system":        float32(imu.calibrationStatus.System),
+		"calibration_gyroscope":     float32(imu.calibrationStatus.Gyroscope),
+		"calibration_accelerometer": float32(imu.calibrationStatus.Accelerometer),
+		"calibration_magnetometer":  float32(imu.calibrationStatus.Magnetometer),
+	}
+	return acc, imu.err.Get()
+}
+
+func (imu *bno055) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	readings, err := movementsensor.DefaultAPIReadings(ctx, imu, extra)
+	if err != nil {
+		return nil, err
+	}
+
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	readings["temperature_celsius"] = imu.temperature
+	readings["calibration"] = imu.calibrationStatus.toMap()
+	return readings, nil
+}
+
+func (imu *bno055) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	return &movementsensor.Properties{
+		AngularVelocitySupported:    true,
+		LinearAccelerationSupported: true,
+		OrientationSupported:        true,
+		CompassHeadingSupported:     imu.fusionMode == ndofMode,
+	}, nil
+}
+
+// DoCommand reports the calibration status, and saves and restores the calibration offsets.
+func (imu *bno055) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	switch cmd["command"] {
+	case "calibration_status":
+		imu.mu.Lock()
+		defer imu.mu.Unlock()
+		return imu.calibrationStatus.toMap(), nil
+	case "save_calibration":
+		imu.mu.Lock()
+		status := imu.calibrationStatus
+		imu.mu.Unlock()
+		if status.System < 3 {
+			imu.logger.CWarnf(ctx, "saving the calibration of a BNO055 which is not fully calibrated: %+v", status)
+		}
+		calibration, err := imu.readCalibration(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if imu.calibrationFile != "" {
+			data, err := json.Marshal(calibration)
+			if err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(imu.calibrationFile, data, 0o600); err != nil {
+				return nil, err
+			}
+		}
+		return toMap(calibration)
+	case "restore_calibration":
+		var calibration *Calibration
+		if c, ok := cmd["calibration"]; ok {
+			data, err := json.Marshal(c)
+			if err != nil {
+				return nil, err
+			}
+			calibration = &Calibration{}
+			if err := json.Unmarshal(data, calibration); err != nil {
+				return nil, errors.Wrap(err, "invalid calibration")
+			}
+		} else {
+			var err error
+			if calibration, err = imu.startupCalibration(&Config{}); err != nil {
+				return nil, err
+			}
+			if calibration == nil {
+				return nil, errors.New("no calibration given and no calibration file has been saved")
+			}
+		}
+		if err := imu.writeCalibration(ctx, *calibration); err != nil {
+			return nil, err
+		}
+		return toMap(*calibration)
+	default:
+		return nil, resource.ErrDoUnimplemented
+	}
+}
+
+func toMap(calibration Calibration) (map[string]interface{}, error) {
+	data, err := json.Marshal(calibration)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// StreamSamples streams every reading of the chip, at the rate the background goroutine reads it.
+func (imu *bno055) StreamSamples(ctx context.Context, extra map[string]interface{}) (<-chan movementsensor.Sample, error) {
+	return imu.samples.Subscribe(ctx), nil
+}
+
+func (imu *bno055) Close(ctx context.Context) error {
+	imu.workers.Stop()
+	imu.samples.Close()
+
+	imu.busMu.Lock()
+	defer imu.busMu.Unlock()
+	// Suspend the chip, which can only be done in config mode.
+	err := imu.setMode(ctx, configMode)
+	if err == nil {
+		err = imu.writeByte(ctx, powerModeRegister, suspendMode)
+	}
+	if err != nil {
+		imu.logger.CError(ctx, err)
+	}
+	return err
+}
@@ -0,0 +1,2 @@
+// Package bno055 is only implemented for Linux systems.
+package bno055
@@ -0,0 +1,280 @@
+//go:build linux
+
+package bno055
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+const i2cName = "i2c"
+
+// fakeChip is the register map of a BNO055, whose calibration offsets can only be accessed in config mode.
+type fakeChip struct {
+	mu        sync.Mutex
+	registers [0x80]byte
+	// offsetsWrittenInConfigMode is false if the offsets were ever written outside of config mode.
+	offsetsWrittenInConfigMode bool
+	offsetsWritten             bool
+}
+
+func newFakeChip() *fakeChip {
+	c := &fakeChip{offsetsWrittenInConfigMode: true}
+	c.registers[chipIDRegister] = expectedChipID
+	return c
+}
+
+func (c *fakeChip) set(register byte, data ...byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	copy(c.registers[register:], data)
+}
+
+func (c *fakeChip) get(register byte) byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.registers[register]
+}
+
+func (c *fakeChip) bus() buses.I2C {
+	handle := &inject.I2CHandle{}
+	handle.ReadBlockDataFunc = func(ctx context.Context, register byte, numBytes uint8) ([]byte, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if register >= offsetsRegister && c.registers[opModeRegister] != configMode {
+			return make([]byte, numBytes), nil
+		}
+		return append([]byte(nil), c.registers[register:int(register)+int(numBytes)]...), nil
+	}
+	handle.WriteByteDataFunc = func(ctx context.Context, register, data byte) error {
+		c.set(register, data)
+		return nil
+	}
+	handle.WriteBlockDataFunc = func(ctx context.Context, register byte, data []byte) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if register == offsetsRegister {
+			c.offsetsWritten = true
+			c.offsetsWrittenInConfigMode = c.offsetsWrittenInConfigMode && c.registers[opModeRegister] == configMode
+		}
+		copy(c.registers[register:], data)
+		return nil
+	}
+	handle.CloseFunc = func() error { return nil }
+	i2c := &inject.I2C{}
+	i2c.OpenHandleFunc = func(addr byte) (buses.I2CHandle, error) {
+		return handle, nil
+	}
+	return i2c
+}
+
+func makeConfig(attrs *Config) resource.Config {
+	return resource.Config{
+		Name:                "movementsensor",
+		Model:               model,
+		API:                 movementsensor.API,
+		ConvertedAttributes: attrs,
+	}
+}
+
+var testCalibration = Calibration{
+	AccelerometerOffset: [3]int16{-12, 34, -5},
+	MagnetometerOffset:  [3]int16{150, -320, 401},
+	GyroscopeOffset:     [3]int16{-1, 0, 2},
+	AccelerometerRadius: 1000,
+	MagnetometerRadius:  712,
+}
+
+func TestValidateConfig(t *testing.T) {
+	cfg := Config{}
+	deps, err := cfg.Validate("path")
+	expectedErr := resource.NewConfigValidationFieldRequiredError("path", "i2c_bus")
+	test.That(t, err, test.ShouldBeError, expectedErr)
+	test.That(t, deps, test.ShouldBeEmpty)
+}
+
+func TestInitializationFailure(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	t.Run("fails on read error", func(t *testing.T) {
+		i2cHandle := &inject.I2CHandle{}
+		readErr := errors.New("read error")
+		i2cHandle.ReadBlockDataFunc = func(ctx context.Context, register byte, numBytes uint8) ([]byte, error) {
+			return nil, readErr
+		}
+		i2cHandle.CloseFunc = func() error { return nil }
+		i2c := &inject.I2C{}
+		i2c.OpenHandleFunc = func(addr byte) (buses.I2CHandle, error) {
+			return i2cHandle, nil
+		}
+
+		sensor, err := makeBno055(context.Background(), nil, makeConfig(&Config{I2cBus: i2cName}), logger, i2c)
+		test.That(t, err, test.ShouldBeError, addressReadError(readErr, defaultAddress, i2cName))
+		test.That(t, sensor, test.ShouldBeNil)
+	})
+
+	t.Run("fails on unexpected chip", func(t *testing.T) {
+		chip := newFakeChip()
+		chip.set(chipIDRegister, 0x68)
+		conf := makeConfig(&Config{I2cBus: i2cName, UseAlternateI2CAddress: true})
+		sensor, err := makeBno055(context.Background(), nil, conf, logger, chip.bus())
+		test.That(t, err, test.ShouldBeError, unexpectedDeviceError(alternateAddress, 0x68))
+		test.That(t, sensor, test.ShouldBeNil)
+	})
+}
+
+func TestReadings(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	chip := newFakeChip()
+	// 9.81 m/s^2 of gravity, 90 degrees per second of yaw, a heading of 45 degrees and a quaternion of a quarter
+	// turn about z.
+	chip.set(0x08, 0, 0, 0, 0, 0xD5, 0x03)
+	chip.set(0x14, 0, 0, 0, 0, 0xA0, 0x05)
+	chip.set(0x1A, 0xD0, 0x02)
+	chip.set(0x20, 0x4F, 0x2D, 0, 0, 0, 0, 0x4F, 0x2D)
+	chip.set(0x34, 25, 0xF3)
+
+	sensor, err := makeBno055(ctx, nil, makeConfig(&Config{I2cBus: i2cName}), logger, chip.bus())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, chip.get(opModeRegister), test.ShouldEqual, ndofMode)
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		acc, err := sensor.LinearAcceleration(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, acc.Z, test.ShouldAlmostEqual, 9.81)
+	})
+	angVel, err := sensor.AngularVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, angVel.Z, test.ShouldAlmostEqual, 90)
+	heading, err := sensor.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldAlmostEqual, 45)
+	ori, err := sensor.Orientation(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.OrientationAlmostEqual(ori, &spatialmath.EulerAngles{Yaw: 1.5708}), test.ShouldBeTrue)
+
+	acc, err := sensor.Accuracy(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, acc.AccuracyMap, test.ShouldResemble, map[string]float32{
+		"calibration_system":        3,
+		"calibration_gyroscope":     3,
+		"calibration_accelerometer": 0,
+		"calibration_magnetometer":  3,
+	})
+	readings, err := sensor.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["temperature_celsius"], test.ShouldEqual, 25)
+	test.That(t, readings["calibration"], test.ShouldResemble, map[string]interface{}{
+		"system": 3, "gyroscope": 3, "accelerometer": 0, "magnetometer": 3,
+	})
+
+	props, err := sensor.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.CompassHeadingSupported, test.ShouldBeTrue)
+
+	// every reading of the chip is streamed, until the sensor closes
+	samples, err := movementsensor.StreamSamples(ctx, sensor, 0, nil)
+	test.That(t, err, test.ShouldBeNil)
+	sample := <-samples
+	test.That(t, sample.LinearAcceleration.Z, test.ShouldAlmostEqual, 9.81)
+	test.That(t, sample.AngularVelocity.Z, test.ShouldAlmostEqual, 90)
+	test.That(t, sample.Orientation, test.ShouldNotBeNil)
+
+	test.That(t, sensor.Close(ctx), test.ShouldBeNil)
+	test.That(t, chip.get(powerModeRegister), test.ShouldEqual, suspendMode)
+	for range samples {
+	}
+
+	t.Run("without the magnetometer", func(t *testing.T) {
+		chip := newFakeChip()
+		conf := makeConfig(&Config{I2cBus: i2cName, DisableMagnetometer: true})
+		sensor, err := makeBno055(ctx, nil, conf, logger, chip.bus())
+		test.That(t, err, test.ShouldBeNil)
+		defer sensor.Close(ctx)
+		test.That(t, chip.get(opModeRegister), test.ShouldEqual, imuMode)
+		_, err = sensor.CompassHeading(ctx, nil)
+		test.That(t, err, test.ShouldBeError, movementsensor.ErrMethodUnimplementedCompassHeading)
+	})
+}
+
+func TestCalibration(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	calibrationFile := filepath.Join(t.TempDir(), "calibration.json")
+
+	t.Run("restored from the config", func(t *testing.T) {
+		chip := newFakeChip()
+		conf := makeConfig(&Config{I2cBus: i2cName, CalibrationFile: calibrationFile, Calibration: &testCalibration})
+		sensor, err := makeBno055(ctx, nil, conf, logger, chip.bus())
+		test.That(t, err, test.ShouldBeNil)
+		defer sensor.Close(ctx)
+		test.That(t, chip.offsetsWritten, test.ShouldBeTrue)
+		test.That(t, chip.offsetsWrittenInConfigMode, test.ShouldBeTrue)
+
+		// saving reads the offsets in config mode, and then carries on fusing
+		resp, err := sensor.DoCommand(ctx, map[string]interface{}{"command": "save_calibration"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, chip.get(opModeRegister), test.ShouldEqual, ndofMode)
+		test.That(t, resp["magnetometer_radius"], test.ShouldEqual, 712)
+		data, err := os.ReadFile(calibrationFile)
+		test.That(t, err, test.ShouldBeNil)
+		var saved Calibration
+		test.That(t, json.Unmarshal(data, &saved), test.ShouldBeNil)
+		test.That(t, saved, test.ShouldResemble, testCalibration)
+	})
+
+	t.Run("restored from the calibration file", func(t *testing.T) {
+		chip := newFakeChip()
+		conf := makeConfig(&Config{I2cBus: i2cName, CalibrationFile: calibrationFile})
+		sensor, err := makeBno055(ctx, nil, conf, logger, chip.bus())
+		test.That(t, err, test.ShouldBeNil)
+		defer sensor.Close(ctx)
+		cal, err := sensor.(*bno055).readCalibration(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cal, test.ShouldResemble, testCalibration)
+	})
+
+	t.Run("restored by command", func(t *testing.T) {
+		chip := newFakeChip()
+		sensor, err := makeBno055(ctx, nil, makeConfig(&Config{I2cBus: i2cName}), logger, chip.bus())
+		test.That(t, err, test.ShouldBeNil)
+		defer sensor.Close(ctx)
+		test.That(t, chip.offsetsWritten, test.ShouldBeFalse)
+
+		_, err = sensor.DoCommand(ctx, map[string]interface{}{"command": "restore_calibration"})
+		test.That(t, err, test.ShouldNotBeNil)
+
+		calMap, err := toMap(testCalibration)
+		test.That(t, err, test.ShouldBeNil)
+		_, err = sensor.DoCommand(ctx, map[string]interface{}{"command": "restore_calibration", "calibration": calMap})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, chip.offsetsWrittenInConfigMode, test.ShouldBeTrue)
+		test.That(t, chip.get(opModeRegister), test.ShouldEqual, ndofMode)
+		cal, err := sensor.(*bno055).readCalibration(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cal, test.ShouldResemble, testCalibration)
+
+		status, err := sensor.DoCommand(ctx, map[string]interface{}{"command": "calibration_status"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, status, test.ShouldContainKey, "system")
+		_, err = sensor.DoCommand(ctx, map[string]interface{}{"command": "bogus"})
+		test.That(t, err, test.ShouldBeError, resource.ErrDoUnimplemented)
+	})
+}
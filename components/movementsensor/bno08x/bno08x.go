@@ -0,0 +1,599 @@
+//go:build linux
+
+// Package bno08x implements the movementsensor interface for the CEVA/Bosch BNO08x (BNO080, BNO085 and
+// BNO086) 9-axis IMUs over I2C or SPI. The datasheet is at
+// https://www.ceva-ip.com/wp-content/uploads/2019/10/BNO080_085-Datasheet.pdf
+// and the SH-2 reference manual describing its reports is at
+// https://www.ceva-ip.com/wp-content/uploads/2019/10/SH-2-Reference-Manual.pdf
+//
+// Unlike the BNO055, the chip has no register map: everything is sent to and from its sensor hub as SHTP
+// packets. We enable the accelerometer, gyroscope, magnetometer and rotation vector reports and report the
+// orientation and compass heading the hub fuses, in the East-North-Up frame it uses by default. Each report
+// carries how well calibrated its sensor is, from 0 for unreliable to 3 for high accuracy.
+//
+// The hub calibrates itself continuously as it is moved. The save_calibration DoCommand saves its dynamic
+// calibration data to its flash, which it restores from whenever it resets, and restore_calibration resets it to
+// discard the calibration it has learned since:
+//
+//	{"command": "calibration_status"}
+//	{"command": "save_calibration"}
+//	{"command": "restore_calibration"}
+//
+// Over I2C the chip has two possible addresses, which can be selected by wiring the SA0 pin:
+//   - if SA0 is wired to ground, it uses the default I2C address of 0x4A
+//   - if SA0 is wired to hot, it uses the alternate I2C address of 0x4B
+//
+// Over SPI the hub is polled instead of waiting on its interrupt pin.
+package bno08x
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("imu-bno08x")
+
+const (
+	defaultAddress   = 0x4A
+	alternateAddress = 0x4B
+
+	// commands on the executable channel, and its response once the hub has reset
+	execReset         = 1
+	execSleep         = 3
+	execResetComplete = 1
+
+	// reports on the control channel
+	reportCommandResponse   = 0xF1
+	reportCommandRequest    = 0xF2
+	reportProductIDResponse = 0xF8
+	reportProductIDRequest  = 0xF9
+	reportSetFeature        = 0xFD
+
+	commandSaveDCD = 0x06
+
+	// reports on the input report channel
+	reportAccelerometer      = 0x01
+	reportGyroscope          = 0x02
+	reportMagnetometer       = 0x03
+	reportRotationVector     = 0x05
+	reportGameRotationVector = 0x08
+	reportTimestampRebase    = 0xFA
+	reportBaseTimestamp      = 0xFB
+
+	commandRequestLength  = 12
+	commandResponseLength = 16
+	setFeatureLength      = 17
+
+	// reportInterval is how often the hub sends each report.
+	reportInterval = 10 * time.Millisecond
+	// The hub is polled twice as often as it sends reports, so they are not held up waiting to be read.
+	pollInterval = 5 * time.Millisecond
+	// responseTimeout is how long the hub has to reset or respond to a request.
+	responseTimeout = time.Second
+	// maxPacketsPerPoll bounds how many packets are read at once, so a chatty hub can't starve commands.
+	maxPacketsPerPoll = 8
+
+	// Q points of the reports, which are fixed point numbers scaled by 2^Q
+	accelQ           = 8  // m/s^2
+	gyroQ            = 9  // radians per second
+	rotationQ        = 14 // unit quaternion
+	rotationAccuracy = 12 // radians
+)
+
+// reportLengths is the length of each input report, which are packed back to back.
+var reportLengths = map[byte]int{
+	reportAccelerometer:      10,
+	reportGyroscope:          10,
+	reportMagnetometer:       10,
+	reportRotationVector:     14,
+	reportGameRotationVector: 12,
+	reportTimestampRebase:    5,
+	reportBaseTimestamp:      5,
+}
+
+// CalibrationStatus is how well calibrated each subsystem of the chip is, from 0 for unreliable to 3 for high
+// accuracy. System is the accuracy of the fused orientation.
+type CalibrationStatus struct {
+	System        int `json:"system"`
+	Gyroscope     int `json:"gyroscope"`
+	Accelerometer int `json:"accelerometer"`
+	Magnetometer  int `json:"magnetometer"`
+}
+
+func (s CalibrationStatus) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"system":        s.System,
+		"gyroscope":     s.Gyroscope,
+		"accelerometer": s.Accelerometer,
+		"magnetometer":  s.Magnetometer,
+	}
+}
+
+// Config is used to configure the attributes of the chip, which is on either an I2C or an SPI bus.
+type Config struct {
+	I2cBus                 string `json:"i2c_bus,omitempty"`
+	UseAlternateI2CAddress bool   `json:"use_alt_i2c_address,omitempty"`
+	SPIBus                 string `json:"spi_bus,omitempty"`
+	ChipSelect             string `json:"chip_select,omitempty"`
+	// DisableMagnetometer fuses the accelerometer and gyroscope alone, for where magnetic interference makes the
+	// magnetometer worse than useless. There is no compass heading without it.
+	DisableMagnetometer bool `json:"disable_magnetometer,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid, and then returns the list of things we
+// depend on.
+func (conf *Config) Validate(path string) ([]string, error) {
+	switch {
+	case conf.I2cBus != "" && conf.SPIBus != "":
+		return nil, resource.NewConfigValidationError(path, errors.New("only one of i2c_bus and spi_bus can be set"))
+	case conf.SPIBus != "":
+		if conf.ChipSelect == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "chip_select")
+		}
+	case conf.I2cBus == "":
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "i2c_bus")
+	}
+
+	var deps []string
+	return deps, nil
+}
+
+func init() {
+	resource.RegisterComponent(movementsensor.API, model, resource.Registration[movementsensor.MovementSensor, *Config]{
+		Constructor: newBno08x,
+	})
+}
+
+type bno08x struct {
+	resource.Named
+	resource.AlwaysRebuild
+	hub            *shtp
+	rotationReport byte
+	// busMu keeps the background goroutine from reading while a request is sent or the hub is reset.
+	busMu sync.Mutex
+	// commandMu lets one command at a time wait for its response.
+	commandMu        sync.Mutex
+	commandSequence  byte
+	commandResponses chan []byte
+	mu               sync.Mutex
+
+	// lock the mutex before reading or writing these.
+	angularVelocity    spatialmath.AngularVelocity
+	linearAcceleration r3.Vector
+	orientation        spatialmath.Orientation
+	heading            float64
+	headingError       float64
+	calibrationStatus  CalibrationStatus
+	// Stores the most recent error from the background goroutine
+	err movementsensor.LastError
+	// samples streams every orientation the hub reports.
+	samples movementsensor.SampleBroadcaster
+
+	workers utils.StoppableWorkers
+	logger  logging.Logger
+}
+
+func noResponseError(err error, conf *Config) error {
+	if conf.SPIBus != "" {
+		return errors.Wrapf(err, "no BNO08x responding on SPI bus %s chip select %s", conf.SPIBus, conf.ChipSelect)
+	}
+	return errors.Wrapf(err, "no BNO08x responding on I2C bus %s", conf.I2cBus)
+}
+
+// newBno08x constructs a new BNO08x object.
+func newBno08x(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (movementsensor.MovementSensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if newConf.SPIBus != "" {
+		bus := buses.NewSpiBus(newConf.SPIBus)
+		return makeBno08x(ctx, deps, conf, logger, &spiTransport{bus: bus, chipSelect: newConf.ChipSelect, logger: logger})
+	}
+	bus, err := buses.NewI2cBus(newConf.I2cBus)
+	if err != nil {
+		return nil, err
+	}
+	address := byte(defaultAddress)
+	if newConf.UseAlternateI2CAddress {
+		address = alternateAddress
+	}
+	return makeBno08x(ctx, deps, conf, logger, &i2cTransport{bus: bus, address: address, logger: logger})
+}
+
+// This function is separated from newBno08x solely so you can inject a mock bus in tests.
+func makeBno08x(
+	ctx context.Context,
+	_ resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+	t transport,
+) (movementsensor.MovementSensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	rotationReport := byte(reportRotationVector)
+	if newConf.DisableMagnetometer {
+		rotationReport = reportGameRotationVector
+	}
+
+	sensor := &bno08x{
+		Named:            conf.ResourceName().AsNamed(),
+		hub:              &shtp{transport: t},
+		rotationReport:   rotationReport,
+		commandResponses: make(chan []byte, 1),
+		orientation:      spatialmath.NewZeroOrientation(),
+		headingError:     math.NaN(),
+		logger:           logger,
+		// On overloaded boards, the bus can become flaky. Only report errors if at least 5 of
+		// the last 10 attempts to talk to the device have failed.
+		err: movementsensor.NewLastError(10, 5),
+	}
+
+	if err := sensor.reset(ctx); err != nil {
+		return nil, noResponseError(err, newConf)
+	}
+	if err := sensor.hub.send(ctx, channelControl, []byte{reportProductIDRequest, 0}); err != nil {
+		return nil, noResponseError(err, newConf)
+	}
+	product, err := sensor.await(ctx, func(channel byte, payload []byte) bool {
+		return channel == channelControl && len(payload) >= 4 && payload[0] == reportProductIDResponse
+	})
+	if err != nil {
+		return nil, noResponseError(err, newConf)
+	}
+	logger.CDebugf(ctx, "BNO08x software version %d.%d", product[2], product[3])
+
+	// Now, turn on the background goroutine that constantly reads the reports of the hub and stores them in
+	// the object we created.
+	sensor.workers = utils.NewStoppableWorkers(func(cancelCtx context.Context) {
+		timer := time.NewTicker(pollInterval)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-timer.C:
+				sensor.busMu.Lock()
+				err := sensor.poll(cancelCtx)
+				sensor.busMu.Unlock()
+				// Record `err` no matter what: even if it's nil, that's useful information.
+				sensor.err.Set(err)
+				if err != nil {
+					sensor.logger.CErrorf(ctx, "error reading BNO08x sensor: '%s'", err)
+				}
+			case <-cancelCtx.Done():
+				return
+			}
+		}
+	})
+
+	return sensor, nil
+}
+
+// reset resets the hub, which restores the calibration saved in its flash, and then enables our reports,
+// which the hub forgets whenever it resets.
+func (imu *bno08x) reset(ctx context.Context) error {
+	if err := imu.hub.send(ctx, channelExecutable, []byte{execReset}); err != nil {
+		return err
+	}
+	if _, err := imu.await(ctx, func(channel byte, payload []byte) bool {
+		return channel == channelExecutable && len(payload) > 0 && payload[0] == execResetComplete
+	}); err != nil {
+		return errors.Wrap(err, "BNO08x did not finish resetting")
+	}
+	return imu.enableReports(ctx)
+}
+
+func (imu *bno08x) enableReports(ctx context.Context) error {
+	reports := []byte{reportAccelerometer, reportGyroscope, imu.rotationReport}
+	if imu.rotationReport == reportRotationVector {
+		reports = append(reports, reportMagnetometer)
+	}
+	for _, report := range reports {
+		payload := make([]byte, setFeatureLength)
+		payload[0] = reportSetFeature
+		payload[1] = report
+		binary.LittleEndian.PutUint32(payload[5:], uint32(reportInterval/time.Microsecond))
+		if err := imu.hub.send(ctx, channelControl, payload); err != nil {
+			return errors.Wrapf(err, "unable to enable BNO08x report %#x", report)
+		}
+	}
+	return nil
+}
+
+// await reads packets from the hub, ignoring the rest, until one matches.
+func (imu *bno08x) await(ctx context.Context, match func(channel byte, payload []byte) bool) ([]byte, error) {
+	deadline := time.Now().Add(responseTimeout)
+	for time.Now().Before(deadline) {
+		channel, payload, ok, err := imu.hub.receive(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			if match(channel, payload) {
+				return payload, nil
+			}
+			continue
+		}
+		if !goutils.SelectContextOrWait(ctx, pollInterval) {
+			return nil, ctx.Err()
+		}
+	}
+	return nil, errors.New("timed out waiting for the BNO08x to respond")
+}
+
+// poll reads the packets the hub has sent since it was last polled.
+func (imu *bno08x) poll(ctx context.Context) error {
+	for i := 0; i < maxPacketsPerPoll; i++ {
+		channel, payload, ok, err := imu.hub.receive(ctx)
+		if err != nil || !ok {
+			return err
+		}
+		switch {
+		case channel == channelReports:
+			imu.update(payload)
+		case channel == channelControl && len(payload) >= commandResponseLength && payload[0] == reportCommandResponse:
+			select {
+			case imu.commandResponses <- payload:
+			default:
+			}
+		case channel == channelExecutable && len(payload) > 0 && payload[0] == execResetComplete:
+			imu.logger.CWarn(ctx, "BNO08x reset itself, re-enabling its reports")
+			if err := imu.enableReports(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// update parses a packet of input reports, each of which starts with its report ID.
+func (imu *bno08x) update(payload []byte) {
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+
+	for len(payload) > 0 {
+		length, ok := reportLengths[payload[0]]
+		if !ok || length > len(payload) {
+			// We can't tell where the next report starts after one we don't know.
+			return
+		}
+		report := payload[:length]
+		payload = payload[length:]
+
+		// Sensor reports are their ID, a sequence number, their status, a delay and then their data.
+		if report[0] == reportTimestampRebase || report[0] == reportBaseTimestamp {
+			continue
+		}
+		accuracy := int(report[2] & 0x03)
+		value := func(i, q int) float64 {
+			return float64(utils.Int16FromBytesLE(report[4+2*i:6+2*i])) / float64(int(1)<<q)
+		}
+		vector := func(q int) r3.Vector {
+			return r3.Vector{X: value(0, q), Y: value(1, q), Z: value(2, q)}
+		}
+
+		switch report[0] {
+		case reportAccelerometer:
+			imu.linearAcceleration = vector(accelQ)
+			imu.calibrationStatus.Accelerometer = accuracy
+		case reportGyroscope:
+			gyro := vector(gyroQ)
+			imu.angularVelocity = spatialmath.AngularVelocity{
+				X: utils.RadToDeg(gyro.X),
+				Y: utils.RadToDeg(gyro.Y),
+				Z: utils.RadToDeg(gyro.Z),
+			}
+			imu.calibrationStatus.Gyroscope = accuracy
+		case reportMagnetometer:
+			imu.calibrationStatus.Magnetometer = accuracy
+		case reportRotationVector, reportGameRotationVector:
+			quat := &spatialmath.Quaternion{
+				Imag: value(0, rotationQ),
+				Jmag: value(1, rotationQ),
+				Kmag: value(2, rotationQ),
+				Real: value(3, rotationQ),
+			}
+			imu.orientation = quat
+			imu.calibrationStatus.System = accuracy
+			if report[0] == reportRotationVector {
+				// The yaw is counterclockwise from east, and the heading clockwise from north.
+				imu.heading = math.Mod(450-utils.RadToDeg(quat.EulerAngles().Yaw), 360)
+				imu.headingError = utils.RadToDeg(value(4, rotationAccuracy))
+			}
+			imu.samples.Publish(movementsensor.Sample{
+				Time:               time.Now(),
+				LinearAcceleration: imu.linearAcceleration,
+				AngularVelocity:    imu.angularVelocity,
+				Orientation:        imu.orientation,
+			})
+		}
+	}
+}
+
+// command sends a command request to the hub, and returns the results of its response.
+func (imu *bno08x) command(ctx context.Context, command byte, params ...byte) ([]byte, error) {
+	imu.commandMu.Lock()
+	defer imu.commandMu.Unlock()
+
+	sequence := imu.commandSequence
+	imu.commandSequence++
+	payload := make([]byte, commandRequestLength)
+	payload[0] = reportCommandRequest
+	payload[1] = sequence
+	payload[2] = command
+	copy(payload[3:], params)
+	imu.busMu.Lock()
+	err := imu.hub.send(ctx, channelControl, payload)
+	imu.busMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := time.NewTimer(responseTimeout)
+	defer timeout.Stop()
+	for {
+		select {
+		case response := <-imu.commandResponses:
+			// Skip the responses to earlier commands which timed out.
+			if response[2] != command || response[3] != sequence {
+				continue
+			}
+			return response[5:], nil
+		case <-timeout.C:
+			return nil, errors.Errorf("timed out waiting for the BNO08x to respond to command %#x", command)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (imu *bno08x) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	return imu.angularVelocity, imu.err.Get()
+}
+
+func (imu *bno08x) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	return r3.Vector{}, movementsensor.ErrMethodUnimplementedLinearVelocity
+}
+
+func (imu *bno08x) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	return imu.linearAcceleration, imu.err.Get()
+}
+
+// Orientation returns the orientation the hub fused from its sensors.
+func (imu *bno08x) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	return imu.orientation, imu.err.Get()
+}
+
+// CompassHeading returns the heading clockwise from magnetic north the hub fused from its sensors.
+func (imu *bno08x) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	if imu.rotationReport != reportRotationVector {
+		return 0, movementsensor.ErrMethodUnimplementedCompassHeading
+	}
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	return imu.heading, imu.err.Get()
+}
+
+func (imu *bno08x) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	return geo.NewPoint(0, 0), 0, movementsensor.ErrMethodUnimplementedPosition
+}
+
+// Accuracy returns the calibration status of each subsystem, from 0 for unreliable to 3 for high accuracy, and
+// the error of the compass heading the hub estimates.
+func (imu *bno08x) Accuracy(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	acc := movementsensor.UnimplementedOptionalAccuracies()
+	acc.AccuracyMap = map[string]float32{
+		"calibration_system":        float32(imu.calibrationStatus.System),
+		"calibration_gyroscope":     float32(imu.calibrationStatus.Gyroscope),
+		"calibration_accelerometer": float32(imu.calibrationStatus.Accelerometer),
+		"calibration_magnetometer":  float32(imu.calibrationStatus.Magnetometer),
+	}
+	acc.CompassDegreeError = float32(imu.headingError)
+	return acc, imu.err.Get()
+}
+
+func (imu *bno08x) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	readings, err := movementsensor.DefaultAPIReadings(ctx, imu, extra)
+	if err != nil {
+		return nil, err
+	}
+
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	readings["calibration"] = imu.calibrationStatus.toMap()
+	return readings, nil
+}
+
+func (imu *bno08x) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	return &movementsensor.Properties{
+		AngularVelocitySupported:    true,
+		LinearAccelerationSupported: true,
+		OrientationSupported:        true,
+		CompassHeadingSupported:     imu.rotationReport == reportRotationVector,
+	}, nil
+}
+
+// DoCommand reports the calibration status, saves the calibration to the flash of the hub and restores it.
+func (imu *bno08x) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	switch cmd["command"] {
+	case "calibration_status":
+		imu.mu.Lock()
+		defer imu.mu.Unlock()
+		return imu.calibrationStatus.toMap(), nil
+	case "save_calibration":
+		imu.mu.Lock()
+		status := imu.calibrationStatus
+		imu.mu.Unlock()
+		if status.System < 3 {
+			imu.logger.CWarnf(ctx, "saving the calibration of a BNO08x which is not fully calibrated: %+v", status)
+		}
+		result, err := imu.command(ctx, commandSaveDCD)
+		if err != nil {
+			return nil, err
+		}
+		if result[0] != 0 {
+			return nil, errors.Errorf("BNO08x failed to save its calibration: status %d", result[0])
+		}
+		return status.toMap(), nil
+	case "restore_calibration":
+		imu.busMu.Lock()
+		defer imu.busMu.Unlock()
+		if err := imu.reset(ctx); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{}, nil
+	default:
+		return nil, resource.ErrDoUnimplemented
+	}
+}
+
+// StreamSamples streams every orientation the hub reports, along with its latest acceleration and angular
+// velocity.
+func (imu *bno08x) StreamSamples(ctx context.Context, extra map[string]interface{}) (<-chan movementsensor.Sample, error) {
+	return imu.samples.Subscribe(ctx), nil
+}
+
+func (imu *bno08x) Close(ctx context.Context) error {
+	imu.workers.Stop()
+	imu.samples.Close()
+
+	imu.busMu.Lock()
+	defer imu.busMu.Unlock()
+	err := imu.hub.send(ctx, channelExecutable, []byte{execSleep})
+	if err != nil {
+		imu.logger.CError(ctx, err)
+	}
+	return err
+}
@@ -0,0 +1,2 @@
+// Package bno08x is only implemented for Linux systems.
+package bno08x
@@ -0,0 +1,392 @@
+//go:build linux
+
+package bno08x
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// fakeHub is the sensor hub of a BNO08x, which answers the requests it is sent with packets it queues to be read.
+type fakeHub struct {
+	mu       sync.Mutex
+	outgoing [][]byte
+	sequence [numChannels]byte
+	// enabled is the interval in microseconds of each report that has been enabled since the hub last reset.
+	enabled    map[byte]uint32
+	resets     int
+	commands   []byte
+	saveStatus byte
+	asleep     bool
+}
+
+func newFakeHub() *fakeHub {
+	return &fakeHub{enabled: map[byte]uint32{}}
+}
+
+func (h *fakeHub) queue(channel byte, payload ...byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.queueLocked(channel, payload...)
+}
+
+func (h *fakeHub) queueLocked(channel byte, payload ...byte) {
+	packet := make([]byte, headerLength, headerLength+len(payload))
+	binary.LittleEndian.PutUint16(packet, uint16(headerLength+len(payload)))
+	packet[2] = channel
+	packet[3] = h.sequence[channel]
+	h.sequence[channel]++
+	h.outgoing = append(h.outgoing, append(packet, payload...))
+}
+
+// handle handles a packet sent to the hub.
+func (h *fakeHub) handle(packet []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	channel, payload := packet[2], packet[headerLength:]
+	switch {
+	case channel == channelExecutable && payload[0] == execReset:
+		h.resets++
+		h.enabled = map[byte]uint32{}
+		h.queueLocked(channelExecutable, execResetComplete)
+	case channel == channelExecutable && payload[0] == execSleep:
+		h.asleep = true
+	case channel == channelControl && payload[0] == reportProductIDRequest:
+		response := make([]byte, 16)
+		response[0], response[2], response[3] = reportProductIDResponse, 3, 2
+		h.queueLocked(channelControl, response...)
+	case channel == channelControl && payload[0] == reportSetFeature:
+		h.enabled[payload[1]] = binary.LittleEndian.Uint32(payload[5:])
+	case channel == channelControl && payload[0] == reportCommandRequest:
+		h.commands = append(h.commands, payload[2])
+		response := make([]byte, commandResponseLength)
+		response[0], response[2], response[3], response[5] = reportCommandResponse, payload[2], payload[1], h.saveStatus
+		h.queueLocked(channelControl, response...)
+	}
+}
+
+// read returns the first count bytes of the next packet, which is only taken off the queue once it is read whole.
+func (h *fakeHub) read(count int) []byte {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	data := make([]byte, count)
+	if len(h.outgoing) == 0 {
+		return data
+	}
+	copy(data, h.outgoing[0])
+	if count >= len(h.outgoing[0]) {
+		h.outgoing = h.outgoing[1:]
+	}
+	return data
+}
+
+func (h *fakeHub) enabledReports() map[byte]uint32 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	enabled := map[byte]uint32{}
+	for report, interval := range h.enabled {
+		enabled[report] = interval
+	}
+	return enabled
+}
+
+func (h *fakeHub) i2c(logger logging.Logger) transport {
+	handle := &inject.I2CHandle{}
+	handle.WriteFunc = func(ctx context.Context, tx []byte) error {
+		h.handle(tx)
+		return nil
+	}
+	handle.ReadFunc = func(ctx context.Context, count int) ([]byte, error) {
+		return h.read(count), nil
+	}
+	handle.CloseFunc = func() error { return nil }
+	i2c := &inject.I2C{}
+	i2c.OpenHandleFunc = func(addr byte) (buses.I2CHandle, error) {
+		return handle, nil
+	}
+	return &i2cTransport{bus: i2c, address: defaultAddress, logger: logger}
+}
+
+type fakeSPIHandle struct {
+	hub *fakeHub
+}
+
+// Xfer sends the hub any packet in tx, while reading its next packet.
+func (h *fakeSPIHandle) Xfer(ctx context.Context, baud uint, chipSelect string, mode uint, tx []byte) ([]byte, error) {
+	if length, ok := packetLength(tx); ok {
+		h.hub.handle(tx[:length])
+	}
+	return h.hub.read(len(tx)), nil
+}
+
+func (h *fakeSPIHandle) Close() error {
+	return nil
+}
+
+func (h *fakeHub) spi(logger logging.Logger) transport {
+	spi := &inject.SPI{}
+	spi.OpenHandleFunc = func() (buses.SPIHandle, error) {
+		return &fakeSPIHandle{hub: h}, nil
+	}
+	return &spiTransport{bus: spi, chipSelect: "0", logger: logger}
+}
+
+func sensorReport(id, status byte, values ...int16) []byte {
+	report := []byte{id, 0, status, 0}
+	for _, v := range values {
+		report = binary.LittleEndian.AppendUint16(report, uint16(v))
+	}
+	return report
+}
+
+// queueReports queues a packet of reports of 9.81 m/s^2 of gravity, 90 degrees per second of yaw and a quaternion of
+// an eighth of a turn about z, which is a heading of 45 degrees.
+func (h *fakeHub) queueReports(rotationReport byte) {
+	payload := []byte{reportBaseTimestamp, 0, 0, 0, 0}
+	payload = append(payload, sensorReport(reportAccelerometer, 1, 0, 0, 2511)...)
+	payload = append(payload, sensorReport(reportGyroscope, 3, 0, 0, 804)...)
+	payload = append(payload, sensorReport(reportMagnetometer, 2, 100, -200, 300)...)
+	rotation := []int16{0, 0, 6270, 15137}
+	if rotationReport == reportRotationVector {
+		rotation = append(rotation, 410)
+	}
+	payload = append(payload, sensorReport(rotationReport, 3, rotation...)...)
+	h.queue(channelReports, payload...)
+}
+
+func makeConfig(attrs *Config) resource.Config {
+	return resource.Config{
+		Name:                "movementsensor",
+		Model:               model,
+		API:                 movementsensor.API,
+		ConvertedAttributes: attrs,
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	cfg := Config{}
+	deps, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "i2c_bus"))
+	test.That(t, deps, test.ShouldBeEmpty)
+
+	cfg = Config{SPIBus: "0"}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "chip_select"))
+
+	cfg = Config{SPIBus: "0", ChipSelect: "0", I2cBus: "1"}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	for _, cfg := range []Config{{I2cBus: "1"}, {SPIBus: "0", ChipSelect: "0"}} {
+		_, err = cfg.Validate("path")
+		test.That(t, err, test.ShouldBeNil)
+	}
+}
+
+func TestInitializationFailure(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	conf := &Config{I2cBus: "1"}
+
+	t.Run("fails on write error", func(t *testing.T) {
+		handle := &inject.I2CHandle{}
+		writeErr := errors.New("write error")
+		handle.WriteFunc = func(ctx context.Context, tx []byte) error {
+			return writeErr
+		}
+		handle.CloseFunc = func() error { return nil }
+		i2c := &inject.I2C{}
+		i2c.OpenHandleFunc = func(addr byte) (buses.I2CHandle, error) {
+			return handle, nil
+		}
+
+		sensor, err := makeBno08x(context.Background(), nil, makeConfig(conf), logger,
+			&i2cTransport{bus: i2c, address: defaultAddress, logger: logger})
+		test.That(t, err, test.ShouldBeError, noResponseError(writeErr, conf))
+		test.That(t, sensor, test.ShouldBeNil)
+	})
+
+	t.Run("fails when nothing responds", func(t *testing.T) {
+		handle := &inject.I2CHandle{}
+		handle.WriteFunc = func(ctx context.Context, tx []byte) error { return nil }
+		handle.ReadFunc = func(ctx context.Context, count int) ([]byte, error) {
+			return make([]byte, count), nil
+		}
+		handle.CloseFunc = func() error { return nil }
+		i2c := &inject.I2C{}
+		i2c.OpenHandleFunc = func(addr byte) (buses.I2CHandle, error) {
+			return handle, nil
+		}
+
+		sensor, err := makeBno08x(context.Background(), nil, makeConfig(conf), logger,
+			&i2cTransport{bus: i2c, address: defaultAddress, logger: logger})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "did not finish resetting")
+		test.That(t, sensor, test.ShouldBeNil)
+	})
+}
+
+func TestReadings(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	hub := newFakeHub()
+
+	sensor, err := makeBno08x(ctx, nil, makeConfig(&Config{I2cBus: "1"}), logger, hub.i2c(logger))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, hub.resets, test.ShouldEqual, 1)
+	test.That(t, hub.enabledReports(), test.ShouldResemble, map[byte]uint32{
+		reportAccelerometer:  10000,
+		reportGyroscope:      10000,
+		reportMagnetometer:   10000,
+		reportRotationVector: 10000,
+	})
+
+	samples, err := movementsensor.StreamSamples(ctx, sensor, 0, nil)
+	test.That(t, err, test.ShouldBeNil)
+	hub.queueReports(reportRotationVector)
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		acc, err := sensor.LinearAcceleration(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, acc.Z, test.ShouldAlmostEqual, 9.81, 0.01)
+	})
+	angVel, err := sensor.AngularVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, angVel.Z, test.ShouldAlmostEqual, 90, 0.1)
+	ori, err := sensor.Orientation(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.OrientationAlmostEqualEps(ori, &spatialmath.EulerAngles{Yaw: 0.7854}, 0.001), test.ShouldBeTrue)
+	heading, err := sensor.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldAlmostEqual, 45, 0.1)
+
+	acc, err := sensor.Accuracy(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, acc.AccuracyMap, test.ShouldResemble, map[string]float32{
+		"calibration_system":        3,
+		"calibration_gyroscope":     3,
+		"calibration_accelerometer": 1,
+		"calibration_magnetometer":  2,
+	})
+	test.That(t, acc.CompassDegreeError, test.ShouldAlmostEqual, 5.73, 0.01)
+	readings, err := sensor.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["calibration"], test.ShouldResemble, map[string]interface{}{
+		"system": 3, "gyroscope": 3, "accelerometer": 1, "magnetometer": 2,
+	})
+
+	props, err := sensor.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.CompassHeadingSupported, test.ShouldBeTrue)
+
+	// every orientation the hub reports is streamed, until the sensor closes
+	sample := <-samples
+	test.That(t, sample.LinearAcceleration.Z, test.ShouldAlmostEqual, 9.81, 0.01)
+	test.That(t, sample.Orientation, test.ShouldNotBeNil)
+
+	test.That(t, sensor.Close(ctx), test.ShouldBeNil)
+	test.That(t, hub.asleep, test.ShouldBeTrue)
+	for range samples {
+	}
+
+	t.Run("without the magnetometer", func(t *testing.T) {
+		hub := newFakeHub()
+		conf := makeConfig(&Config{I2cBus: "1", DisableMagnetometer: true})
+		sensor, err := makeBno08x(ctx, nil, conf, logger, hub.i2c(logger))
+		test.That(t, err, test.ShouldBeNil)
+		defer sensor.Close(ctx)
+		test.That(t, hub.enabledReports(), test.ShouldResemble, map[byte]uint32{
+			reportAccelerometer:      10000,
+			reportGyroscope:          10000,
+			reportGameRotationVector: 10000,
+		})
+
+		hub.queueReports(reportGameRotationVector)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			ori, err := sensor.Orientation(ctx, nil)
+			test.That(tb, err, test.ShouldBeNil)
+			test.That(tb, spatialmath.OrientationAlmostEqualEps(ori, &spatialmath.EulerAngles{Yaw: 0.7854}, 0.001), test.ShouldBeTrue)
+		})
+		_, err = sensor.CompassHeading(ctx, nil)
+		test.That(t, err, test.ShouldBeError, movementsensor.ErrMethodUnimplementedCompassHeading)
+	})
+
+	t.Run("over SPI", func(t *testing.T) {
+		hub := newFakeHub()
+		conf := makeConfig(&Config{SPIBus: "0", ChipSelect: "0"})
+		sensor, err := makeBno08x(ctx, nil, conf, logger, hub.spi(logger))
+		test.That(t, err, test.ShouldBeNil)
+		defer sensor.Close(ctx)
+		test.That(t, hub.enabledReports(), test.ShouldContainKey, byte(reportRotationVector))
+
+		hub.queueReports(reportRotationVector)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			heading, err := sensor.CompassHeading(ctx, nil)
+			test.That(tb, err, test.ShouldBeNil)
+			test.That(tb, heading, test.ShouldAlmostEqual, 45, 0.1)
+		})
+	})
+}
+
+func TestCalibration(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	hub := newFakeHub()
+	sensor, err := makeBno08x(ctx, nil, makeConfig(&Config{I2cBus: "1"}), logger, hub.i2c(logger))
+	test.That(t, err, test.ShouldBeNil)
+	defer sensor.Close(ctx)
+
+	// saving saves the dynamic calibration data to the flash of the hub
+	hub.queueReports(reportRotationVector)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		status, err := sensor.DoCommand(ctx, map[string]interface{}{"command": "calibration_status"})
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, status["system"], test.ShouldEqual, 3)
+	})
+	resp, err := sensor.DoCommand(ctx, map[string]interface{}{"command": "save_calibration"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["magnetometer"], test.ShouldEqual, 2)
+	hub.mu.Lock()
+	test.That(t, hub.commands, test.ShouldResemble, []byte{commandSaveDCD})
+	hub.saveStatus = 1
+	hub.mu.Unlock()
+	_, err = sensor.DoCommand(ctx, map[string]interface{}{"command": "save_calibration"})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// restoring resets the hub, after which the reports are enabled again
+	_, err = sensor.DoCommand(ctx, map[string]interface{}{"command": "restore_calibration"})
+	test.That(t, err, test.ShouldBeNil)
+	hub.mu.Lock()
+	test.That(t, hub.resets, test.ShouldEqual, 2)
+	hub.mu.Unlock()
+	test.That(t, hub.enabledReports(), test.ShouldContainKey, byte(reportRotationVector))
+
+	// so are they if the hub resets itself
+	hub.mu.Lock()
+	hub.enabled = map[byte]uint32{}
+	hub.mu.Unlock()
+	hub.queue(channelExecutable, execResetComplete)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, hub.enabledReports(), test.ShouldContainKey, byte(reportRotationVector))
+	})
+
+	_, err = sensor.DoCommand(ctx, map[string]interface{}{"command": "bogus"})
+	test.That(t, err, test.ShouldBeError, resource.ErrDoUnimplemented)
+}
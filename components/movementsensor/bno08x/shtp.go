@@ -0,0 +1,193 @@
+//go:build linux
+
+package bno08x
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/logging"
+)
+
+const (
+	// SHTP channels
+	channelExecutable = 1
+	channelControl    = 2
+	channelReports    = 3
+	numChannels       = 6
+
+	headerLength = 4
+	// continuationBit is set in the length of a packet which continues a packet too long for one transfer.
+	continuationBit = 0x8000
+
+	spiBaud = 3000000
+	spiMode = 3
+	// spiTransferLength is how much of each packet is read over SPI, where the length of a packet can't be
+	// known until it is being read. Longer packets are only sent as advertisements at startup, and are dropped.
+	spiTransferLength = 256
+)
+
+// transport moves whole SHTP packets, header included, between us and the hub.
+type transport interface {
+	// write sends a packet to the hub, and returns any packet the hub sent at the same time.
+	write(ctx context.Context, packet []byte) ([]byte, error)
+	// read returns the next packet from the hub, or nil if it has nothing to send.
+	read(ctx context.Context) ([]byte, error)
+}
+
+type i2cTransport struct {
+	bus     buses.I2C
+	address byte
+	logger  logging.Logger
+}
+
+func (t *i2cTransport) open() (buses.I2CHandle, func(context.Context), error) {
+	handle, err := t.bus.OpenHandle(t.address)
+	if err != nil {
+		return nil, nil, err
+	}
+	return handle, func(ctx context.Context) {
+		if err := handle.Close(); err != nil {
+			t.logger.CError(ctx, err)
+		}
+	}, nil
+}
+
+func (t *i2cTransport) write(ctx context.Context, packet []byte) ([]byte, error) {
+	handle, closeHandle, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+	defer closeHandle(ctx)
+	return nil, handle.Write(ctx, packet)
+}
+
+// read reads the header of the next packet to learn its length, and then reads the whole packet, which the
+// hub sends from the start of its header again.
+func (t *i2cTransport) read(ctx context.Context) ([]byte, error) {
+	handle, closeHandle, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+	defer closeHandle(ctx)
+
+	header, err := handle.Read(ctx, headerLength)
+	if err != nil {
+		return nil, err
+	}
+	length, ok := packetLength(header)
+	if !ok {
+		return nil, nil
+	}
+	if length == headerLength {
+		return header, nil
+	}
+	return handle.Read(ctx, length)
+}
+
+type spiTransport struct {
+	bus        buses.SPI
+	chipSelect string
+	logger     logging.Logger
+}
+
+func (t *spiTransport) transfer(ctx context.Context, tx []byte) ([]byte, error) {
+	handle, err := t.bus.OpenHandle()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := handle.Close(); err != nil {
+			t.logger.CError(ctx, err)
+		}
+	}()
+	rx, err := handle.Xfer(ctx, spiBaud, t.chipSelect, spiMode, tx)
+	if err != nil {
+		return nil, err
+	}
+	// Drop the start of packets too long to read in one transfer, whose continuations are dropped too.
+	if length, ok := packetLength(rx); ok && length > len(rx) {
+		return nil, nil
+	}
+	return rx, nil
+}
+
+// write sends the packet padded out to a full transfer, so that a packet the hub sends at the same time is
+// read whole.
+func (t *spiTransport) write(ctx context.Context, packet []byte) ([]byte, error) {
+	tx := make([]byte, max(len(packet), spiTransferLength))
+	copy(tx, packet)
+	return t.transfer(ctx, tx)
+}
+
+func (t *spiTransport) read(ctx context.Context) ([]byte, error) {
+	return t.transfer(ctx, make([]byte, spiTransferLength))
+}
+
+// packetLength returns the length of the packet a header starts, and false if there is no packet or it
+// continues a packet we did not read.
+func packetLength(header []byte) (int, bool) {
+	if len(header) < headerLength {
+		return 0, false
+	}
+	length := binary.LittleEndian.Uint16(header)
+	// An idle SPI bus reads as all ones.
+	if length == 0 || length == 0xFFFF || length&continuationBit != 0 {
+		return 0, false
+	}
+	return int(length), true
+}
+
+// shtp frames the payloads of each channel into SHTP packets.
+type shtp struct {
+	transport transport
+	// sequence is the sequence number of the next packet we send on each channel.
+	sequence [numChannels]byte
+	// received holds a packet the hub sent while we were writing, until it is read.
+	received []byte
+}
+
+func (s *shtp) send(ctx context.Context, channel byte, payload []byte) error {
+	packet := make([]byte, headerLength, headerLength+len(payload))
+	binary.LittleEndian.PutUint16(packet, uint16(headerLength+len(payload)))
+	packet[2] = channel
+	packet[3] = s.sequence[channel]
+	s.sequence[channel]++
+	packet = append(packet, payload...)
+
+	rx, err := s.transport.write(ctx, packet)
+	if err != nil {
+		return err
+	}
+	if _, ok := packetLength(rx); ok {
+		s.received = rx
+	}
+	return nil
+}
+
+// receive returns the channel and payload of the next packet from the hub, and false if it has nothing to send.
+func (s *shtp) receive(ctx context.Context) (byte, []byte, bool, error) {
+	packet := s.received
+	s.received = nil
+	if packet == nil {
+		var err error
+		if packet, err = s.transport.read(ctx); err != nil {
+			return 0, nil, false, err
+		}
+	}
+	length, ok := packetLength(packet)
+	if !ok {
+		return 0, nil, false, nil
+	}
+	if length > len(packet) {
+		return 0, nil, false, errors.Errorf("read %d bytes of a %d byte SHTP packet", len(packet), length)
+	}
+	channel := packet[2]
+	if int(channel) >= numChannels {
+		return 0, nil, false, errors.Errorf("SHTP packet on unknown channel %d", channel)
+	}
+	return channel, packet[headerLength:length], true, nil
+}
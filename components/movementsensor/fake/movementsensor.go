@@ -3,10 +3,12 @@ package fake
 
 import (
 	"context"
+	"math"
 
 	"github.com/golang/geo/r3"
 	geo "github.com/kellydunn/golang-geo"
 
+	"go.viam.com/rdk/components/fakeconditions"
 	"go.viam.com/rdk/components/movementsensor"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
@@ -17,7 +19,17 @@ var model = resource.DefaultModelFamily.WithModel("fake")
 
 // Config is used for converting fake movementsensor attributes.
 type Config struct {
-	resource.TriviallyValidateConfig
+	// Conditions simulates the latency of every call and the noise of the readings, in the units of each reading.
+	// The noise of positions is in meters.
+	fakeconditions.Config `json:",squash"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if err := conf.Config.Validate(path); err != nil {
+		return nil, err
+	}
+	return nil, nil
 }
 
 func init() {
@@ -30,9 +42,14 @@ func init() {
 // NewMovementSensor makes a new fake movement sensor.
 func NewMovementSensor(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
 ) (movementsensor.MovementSensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
 	return &MovementSensor{
-		Named:  conf.ResourceName().AsNamed(),
-		logger: logger,
+		Named:      conf.ResourceName().AsNamed(),
+		conditions: fakeconditions.New(newConf.Config),
+		logger:     logger,
 	}, nil
 }
 
@@ -40,38 +57,72 @@ func NewMovementSensor(ctx context.Context, deps resource.Dependencies, conf res
 type MovementSensor struct {
 	resource.Named
 	resource.AlwaysRebuild
-	logger logging.Logger
+	conditions *fakeconditions.Conditions
+	logger     logging.Logger
 }
 
 // Position gets the position of a fake movementsensor.
 func (f *MovementSensor) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	if err := f.conditions.Wait(ctx); err != nil {
+		return nil, 0, err
+	}
 	p := geo.NewPoint(40.7, -73.98)
-	return p, 50.5, nil
+	// the position is off by noise meters east and north
+	offset := f.conditions.NoiseVector(r3.Vector{})
+	if offset.X != 0 || offset.Y != 0 {
+		bearing := 90 - math.Atan2(offset.Y, offset.X)*180/math.Pi
+		p = p.PointAtDistanceAndBearing(math.Hypot(offset.X, offset.Y)/1000, bearing)
+	}
+	return p, 50.5 + offset.Z, nil
 }
 
 // LinearVelocity gets the linear velocity of a fake movementsensor.
 func (f *MovementSensor) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
-	return r3.Vector{Y: 5.4}, nil
+	if err := f.conditions.Wait(ctx); err != nil {
+		return r3.Vector{}, err
+	}
+	return f.conditions.NoiseVector(r3.Vector{Y: 5.4}), nil
 }
 
 // LinearAcceleration gets the linear acceleration of a fake movementsensor.
 func (f *MovementSensor) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
-	return r3.Vector{X: 2.2, Y: 4.5, Z: 2}, nil
+	if err := f.conditions.Wait(ctx); err != nil {
+		return r3.Vector{}, err
+	}
+	return f.conditions.NoiseVector(r3.Vector{X: 2.2, Y: 4.5, Z: 2}), nil
 }
 
 // AngularVelocity gets the angular velocity of a fake movementsensor.
 func (f *MovementSensor) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
-	return spatialmath.AngularVelocity{Z: 1}, nil
+	if err := f.conditions.Wait(ctx); err != nil {
+		return spatialmath.AngularVelocity{}, err
+	}
+	return spatialmath.AngularVelocity(f.conditions.NoiseVector(r3.Vector{Z: 1})), nil
 }
 
 // CompassHeading gets the compass headings of a fake movementsensor.
 func (f *MovementSensor) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
-	return 25, nil
+	if err := f.conditions.Wait(ctx); err != nil {
+		return 0, err
+	}
+	return f.conditions.Noise(25), nil
 }
 
 // Orientation gets the orientation of a fake movementsensor.
 func (f *MovementSensor) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
-	return spatialmath.NewZeroOrientation(), nil
+	if err := f.conditions.Wait(ctx); err != nil {
+		return nil, err
+	}
+	// the noise of the orientation is in degrees about each axis
+	noise := f.conditions.NoiseVector(r3.Vector{})
+	if noise == (r3.Vector{}) {
+		return spatialmath.NewZeroOrientation(), nil
+	}
+	return &spatialmath.EulerAngles{
+		Roll:  noise.X * math.Pi / 180,
+		Pitch: noise.Y * math.Pi / 180,
+		Yaw:   noise.Z * math.Pi / 180,
+	}, nil
 }
 
 // DoCommand uses a map string to run custom functionality of a fake movementsensor.
@@ -0,0 +1,120 @@
+package fusion
+
+import (
+	"math"
+)
+
+// The state of the filter is the planar pose and velocity of a ground robot moving forward along its heading.
+const (
+	// stateEast and stateNorth are the position in meters from the origin.
+	stateEast = iota
+	stateNorth
+	// stateHeading is the heading in radians clockwise from north, as compass headings are.
+	stateHeading
+	// stateSpeed is the forward speed in meters per second.
+	stateSpeed
+	// stateYawRate is the rate of change of the heading in radians per second, clockwise.
+	stateYawRate
+	stateDims
+)
+
+// ekf is an extended Kalman filter of the state of a robot moving with constant speed and yaw rate between
+// measurements, which every measurement observes a part of directly.
+type ekf struct {
+	x [stateDims]float64
+	p [stateDims][stateDims]float64
+
+	// accelNoise and yawAccelNoise are the standard deviations of the changes in speed, in meters per second per
+	// second, and yaw rate, in radians per second per second, which the model does not predict.
+	accelNoise    float64
+	yawAccelNoise float64
+}
+
+// newEKF returns a filter which knows nothing of the state until it is measured.
+func newEKF(accelNoise, yawAccelNoise float64) *ekf {
+	f := &ekf{accelNoise: accelNoise, yawAccelNoise: yawAccelNoise}
+	f.p[stateEast][stateEast] = 1e6
+	f.p[stateNorth][stateNorth] = 1e6
+	f.p[stateHeading][stateHeading] = math.Pi * math.Pi
+	f.p[stateSpeed][stateSpeed] = 100
+	f.p[stateYawRate][stateYawRate] = 10
+	return f
+}
+
+// predict moves the state dt seconds forward.
+func (f *ekf) predict(dt float64) {
+	if dt <= 0 {
+		return
+	}
+	heading, speed := f.x[stateHeading], f.x[stateSpeed]
+	sin, cos := math.Sincos(heading)
+	f.x[stateEast] += speed * dt * sin
+	f.x[stateNorth] += speed * dt * cos
+	f.x[stateHeading] = wrapAngle(heading + f.x[stateYawRate]*dt)
+
+	// the jacobian of the motion, which is the identity but for these
+	var jac [stateDims][stateDims]float64
+	for i := range jac {
+		jac[i][i] = 1
+	}
+	jac[stateEast][stateHeading] = speed * dt * cos
+	jac[stateEast][stateSpeed] = dt * sin
+	jac[stateNorth][stateHeading] = -speed * dt * sin
+	jac[stateNorth][stateSpeed] = dt * cos
+	jac[stateHeading][stateYawRate] = dt
+
+	var jp, p [stateDims][stateDims]float64
+	for i := 0; i < stateDims; i++ {
+		for j := 0; j < stateDims; j++ {
+			for k := 0; k < stateDims; k++ {
+				jp[i][j] += jac[i][k] * f.p[k][j]
+			}
+		}
+	}
+	for i := 0; i < stateDims; i++ {
+		for j := 0; j < stateDims; j++ {
+			for k := 0; k < stateDims; k++ {
+				p[i][j] += jp[i][k] * jac[j][k]
+			}
+		}
+	}
+	p[stateSpeed][stateSpeed] += f.accelNoise * f.accelNoise * dt * dt
+	p[stateYawRate][stateYawRate] += f.yawAccelNoise * f.yawAccelNoise * dt * dt
+	f.p = p
+}
+
+// update corrects the state with a measurement z of one part of it, whose variance is variance.
+func (f *ekf) update(part int, z, variance float64) {
+	innovation := z - f.x[part]
+	if part == stateHeading {
+		innovation = wrapAngle(innovation)
+	}
+	s := f.p[part][part] + variance
+	if s <= 0 {
+		return
+	}
+	var gain [stateDims]float64
+	for i := range gain {
+		gain[i] = f.p[i][part] / s
+	}
+	for i := range f.x {
+		f.x[i] += gain[i] * innovation
+	}
+	f.x[stateHeading] = wrapAngle(f.x[stateHeading])
+
+	row := f.p[part]
+	for i := 0; i < stateDims; i++ {
+		for j := 0; j < stateDims; j++ {
+			f.p[i][j] -= gain[i] * row[j]
+		}
+	}
+}
+
+// wrapAngle returns the angle in radians from -pi up to pi.
+func wrapAngle(angle float64) float64 {
+	angle = math.Mod(angle+math.Pi, 2*math.Pi)
+	if angle < 0 {
+		angle += 2 * math.Pi
+	}
+	return angle - math.Pi
+}
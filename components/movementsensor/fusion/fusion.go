@@ -0,0 +1,434 @@
+// Package fusion implements a movementsensor fusing an IMU, a GPS and wheel odometry with an extended Kalman filter
+package fusion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("fusion")
+
+const (
+	defaultUpdateRateHz            = 20.
+	defaultGPSStdDevMeters         = 2.5
+	defaultHeadingStdDevDegs       = 5.
+	defaultSpeedStdDevMetersPerSec = 0.1
+	defaultYawRateStdDevDegsPerSec = 2.
+	// accelNoise and yawAccelNoise are how quickly the speed, in meters per second per second, and the yaw rate, in
+	// radians per second per second, of a typical ground robot change.
+	accelNoise    = 1.
+	yawAccelNoise = 1.
+)
+
+// The keys of the covariance of the fused state in the accuracy map.
+const (
+	// AccuracyEastVariance, AccuracyNorthVariance and AccuracyEastNorthCovariance are the covariance of the position,
+	// in square meters.
+	AccuracyEastVariance        = "position_east_variance"
+	AccuracyNorthVariance       = "position_north_variance"
+	AccuracyEastNorthCovariance = "position_east_north_covariance"
+	// AccuracyHeadingVariance is the variance of the compass heading, in square degrees.
+	AccuracyHeadingVariance = "heading_variance"
+	// AccuracyLinearVelocityVariance is the variance of the forward speed, in square meters per second.
+	AccuracyLinearVelocityVariance = "linear_velocity_variance"
+	// AccuracyAngularVelocityVariance is the variance of the yaw rate, in square degrees per second.
+	AccuracyAngularVelocityVariance = "angular_velocity_variance"
+)
+
+var errNoFix = errors.New("no position fix yet")
+
+// Config is the config of the fusion movement_sensor model. Each sensor is optional, but one is needed:
+//   - the IMU measures the heading by its compass heading and the yaw rate by its angular velocity, and gives the
+//     fused orientation its roll and pitch.
+//   - the GPS measures the position, which also lets the heading and speed be estimated as the robot moves.
+//   - the odometry measures the forward speed by the Y of its linear velocity and the yaw rate by its angular velocity.
+//
+// The standard deviations of the measurements default to those of typical sensors.
+type Config struct {
+	IMU      string `json:"imu,omitempty"`
+	GPS      string `json:"gps,omitempty"`
+	Odometry string `json:"odometry,omitempty"`
+
+	UpdateRateHz float64 `json:"update_rate_hz,omitempty"`
+
+	GPSStdDevMeters         float64 `json:"gps_std_dev_m,omitempty"`
+	HeadingStdDevDegs       float64 `json:"heading_std_dev_degs,omitempty"`
+	SpeedStdDevMetersPerSec float64 `json:"speed_std_dev_m_per_sec,omitempty"`
+	YawRateStdDevDegsPerSec float64 `json:"yaw_rate_std_dev_degs_per_sec,omitempty"`
+}
+
+// Validate validates the fusion model's configuration.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	var deps []string
+	for _, name := range []string{cfg.IMU, cfg.GPS, cfg.Odometry} {
+		if name != "" {
+			deps = append(deps, name)
+		}
+	}
+	if len(deps) == 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("one of imu, gps or odometry is required"))
+	}
+	for field, value := range map[string]float64{
+		"update_rate_hz":                cfg.UpdateRateHz,
+		"gps_std_dev_m":                 cfg.GPSStdDevMeters,
+		"heading_std_dev_degs":          cfg.HeadingStdDevDegs,
+		"speed_std_dev_m_per_sec":       cfg.SpeedStdDevMetersPerSec,
+		"yaw_rate_std_dev_degs_per_sec": cfg.YawRateStdDevDegsPerSec,
+	} {
+		if value < 0 {
+			return nil, resource.NewConfigValidationError(path, fmt.Errorf("%s cannot be negative", field))
+		}
+	}
+	return deps, nil
+}
+
+func init() {
+	resource.RegisterComponent(
+		movementsensor.API, model,
+		resource.Registration[movementsensor.MovementSensor, *Config]{
+			Constructor: newFusionModel,
+		})
+}
+
+// A sensor is a dependency of the fusion and what it supports.
+type sensor struct {
+	movementsensor.MovementSensor
+	props movementsensor.Properties
+}
+
+type fusion struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger logging.Logger
+
+	imu, gps, odometry *sensor
+
+	// the variances of the measurements, in meters and radians
+	positionVariance, headingVariance, speedVariance, yawRateVariance float64
+
+	mu       sync.Mutex
+	filter   *ekf
+	lastStep time.Time
+	// origin is the frame the filter's position is in, tangent at the first position fix.
+	origin         *spatialmath.LocalTangentPlane
+	altitude       float64
+	imuOrientation spatialmath.Orientation
+
+	workers utils.StoppableWorkers
+}
+
+func newFusionModel(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (
+	movementsensor.MovementSensor, error,
+) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	f, err := newFusion(ctx, deps, conf.ResourceName(), newConf, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := newConf.UpdateRateHz
+	if rate == 0 {
+		rate = defaultUpdateRateHz
+	}
+	f.workers = utils.NewStoppableWorkers(func(ctx context.Context) {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				f.step(ctx, now)
+			}
+		}
+	})
+	return f, nil
+}
+
+// newFusion returns the fusion of the sensors of the config, which is only updated when stepped.
+func newFusion(
+	ctx context.Context, deps resource.Dependencies, name resource.Name, conf *Config, logger logging.Logger,
+) (*fusion, error) {
+	f := &fusion{
+		Named:            name.AsNamed(),
+		logger:           logger,
+		filter:           newEKF(accelNoise, yawAccelNoise),
+		positionVariance: square(orDefault(conf.GPSStdDevMeters, defaultGPSStdDevMeters)),
+		headingVariance:  square(utils.DegToRad(orDefault(conf.HeadingStdDevDegs, defaultHeadingStdDevDegs))),
+		speedVariance:    square(orDefault(conf.SpeedStdDevMetersPerSec, defaultSpeedStdDevMetersPerSec)),
+		yawRateVariance:  square(utils.DegToRad(orDefault(conf.YawRateStdDevDegsPerSec, defaultYawRateStdDevDegsPerSec))),
+	}
+	var err error
+	if f.imu, err = sensorFromDependencies(ctx, deps, conf.IMU); err != nil {
+		return nil, err
+	}
+	if f.gps, err = sensorFromDependencies(ctx, deps, conf.GPS); err != nil {
+		return nil, err
+	}
+	if f.odometry, err = sensorFromDependencies(ctx, deps, conf.Odometry); err != nil {
+		return nil, err
+	}
+	if f.gps != nil && !f.gps.props.PositionSupported {
+		return nil, fmt.Errorf("gps %q does not support position", conf.GPS)
+	}
+	return f, nil
+}
+
+func sensorFromDependencies(ctx context.Context, deps resource.Dependencies, name string) (*sensor, error) {
+	if name == "" {
+		return nil, nil
+	}
+	ms, err := movementsensor.FromDependencies(deps, name)
+	if err != nil {
+		return nil, err
+	}
+	props, err := ms.Properties(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &sensor{MovementSensor: ms, props: *props}, nil
+}
+
+// step moves the filter forward to now and corrects it with whatever the sensors measure.
+func (f *fusion) step(ctx context.Context, now time.Time) {
+	type measurement struct {
+		part     int
+		z        float64
+		variance float64
+	}
+	var measurements []measurement
+	var position *geo.Point
+	var altitude float64
+	var imuOrientation spatialmath.Orientation
+
+	if f.imu != nil {
+		if f.imu.props.CompassHeadingSupported {
+			if heading, err := f.imu.CompassHeading(ctx, nil); f.usable(ctx, "imu compass heading", err, heading) {
+				measurements = append(measurements, measurement{stateHeading, utils.DegToRad(heading), f.headingVariance})
+			}
+		}
+		if f.imu.props.AngularVelocitySupported {
+			if angVel, err := f.imu.AngularVelocity(ctx, nil); f.usable(ctx, "imu angular velocity", err, angVel.Z) {
+				// angular velocities are counterclockwise, and headings clockwise
+				measurements = append(measurements, measurement{stateYawRate, -utils.DegToRad(angVel.Z), f.yawRateVariance})
+			}
+		}
+		if f.imu.props.OrientationSupported {
+			if ori, err := f.imu.Orientation(ctx, nil); err == nil {
+				imuOrientation = ori
+			} else {
+				f.logger.CDebugw(ctx, "cannot get the imu orientation", "error", err)
+			}
+		}
+	}
+	if f.gps != nil {
+		p, alt, err := f.gps.Position(ctx, nil)
+		if err == nil && p != nil && !movementsensor.IsPositionNaN(p) {
+			position, altitude = p, alt
+		} else if err != nil {
+			f.logger.CDebugw(ctx, "cannot get the gps position", "error", err)
+		}
+	}
+	if f.odometry != nil {
+		if f.odometry.props.LinearVelocitySupported {
+			if linVel, err := f.odometry.LinearVelocity(ctx, nil); f.usable(ctx, "odometry linear velocity", err, linVel.Y) {
+				measurements = append(measurements, measurement{stateSpeed, linVel.Y, f.speedVariance})
+			}
+		}
+		if f.odometry.props.AngularVelocitySupported {
+			if angVel, err := f.odometry.AngularVelocity(ctx, nil); f.usable(ctx, "odometry angular velocity", err, angVel.Z) {
+				measurements = append(measurements, measurement{stateYawRate, -utils.DegToRad(angVel.Z), f.yawRateVariance})
+			}
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.lastStep.IsZero() {
+		f.filter.predict(now.Sub(f.lastStep).Seconds())
+	}
+	f.lastStep = now
+	for _, m := range measurements {
+		f.filter.update(m.part, m.z, m.variance)
+	}
+	if position != nil {
+		if f.origin == nil {
+			f.origin = spatialmath.NewLocalTangentPlane(position, altitude)
+		}
+		// the frame is in millimeters
+		pt := f.origin.GeoPointToPoint(position, altitude).Mul(1e-3)
+		f.filter.update(stateEast, pt.X, f.positionVariance)
+		f.filter.update(stateNorth, pt.Y, f.positionVariance)
+		f.altitude = altitude
+	}
+	if imuOrientation != nil {
+		f.imuOrientation = imuOrientation
+	}
+}
+
+// usable returns whether a measurement can be used, logging why not.
+func (f *fusion) usable(ctx context.Context, what string, err error, value float64) bool {
+	if err != nil {
+		f.logger.CDebugw(ctx, "cannot get the "+what, "error", err)
+		return false
+	}
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+// headingSupported is whether the heading is measured, directly by a compass or as the GPS position moves.
+func (f *fusion) headingSupported() bool {
+	return f.gps != nil || (f.imu != nil && f.imu.props.CompassHeadingSupported)
+}
+
+func (f *fusion) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	if f.gps == nil {
+		return geo.NewPoint(math.NaN(), math.NaN()), math.NaN(), movementsensor.ErrMethodUnimplementedPosition
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.origin == nil {
+		return geo.NewPoint(math.NaN(), math.NaN()), math.NaN(), errNoFix
+	}
+	p, _ := f.origin.PointToGeoPoint(r3.Vector{X: f.filter.x[stateEast] * 1e3, Y: f.filter.x[stateNorth] * 1e3})
+	return p, f.altitude, nil
+}
+
+func (f *fusion) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	if !f.headingSupported() {
+		return nil, movementsensor.ErrMethodUnimplementedOrientation
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ori := &spatialmath.EulerAngles{}
+	if f.imuOrientation != nil {
+		ori = f.imuOrientation.EulerAngles()
+	}
+	// headings are left handed from north, and orientations right handed
+	return &spatialmath.EulerAngles{Roll: ori.Roll, Pitch: ori.Pitch, Yaw: -f.filter.x[stateHeading]}, nil
+}
+
+func (f *fusion) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	if !f.headingSupported() {
+		return math.NaN(), movementsensor.ErrMethodUnimplementedCompassHeading
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return math.Mod(utils.RadToDeg(f.filter.x[stateHeading])+360, 360), nil
+}
+
+func (f *fusion) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	if f.gps == nil && f.odometry == nil {
+		return r3.Vector{X: math.NaN(), Y: math.NaN(), Z: math.NaN()}, movementsensor.ErrMethodUnimplementedLinearVelocity
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return r3.Vector{Y: f.filter.x[stateSpeed]}, nil
+}
+
+func (f *fusion) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	if !f.yawRateSupported() {
+		return spatialmath.AngularVelocity{X: math.NaN(), Y: math.NaN(), Z: math.NaN()},
+			movementsensor.ErrMethodUnimplementedAngularVelocity
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return spatialmath.AngularVelocity{Z: -utils.RadToDeg(f.filter.x[stateYawRate])}, nil
+}
+
+func (f *fusion) yawRateSupported() bool {
+	return (f.imu != nil && f.imu.props.AngularVelocitySupported) ||
+		(f.odometry != nil && f.odometry.props.AngularVelocitySupported)
+}
+
+func (f *fusion) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	if f.imu == nil || !f.imu.props.LinearAccelerationSupported {
+		return r3.Vector{X: math.NaN(), Y: math.NaN(), Z: math.NaN()}, movementsensor.ErrMethodUnimplementedLinearAcceleration
+	}
+	return f.imu.LinearAcceleration(ctx, extra)
+}
+
+// Accuracy returns the covariance of the fused state, and the dilutions of precision and fix of the GPS.
+func (f *fusion) Accuracy(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+	acc := movementsensor.UnimplementedOptionalAccuracies()
+	if f.gps != nil {
+		gpsAcc, err := f.gps.Accuracy(ctx, extra)
+		if err != nil {
+			return nil, err
+		}
+		if gpsAcc != nil {
+			acc.Hdop, acc.Vdop, acc.NmeaFix = gpsAcc.Hdop, gpsAcc.Vdop, gpsAcc.NmeaFix
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p := f.filter.p
+	degs := square(utils.RadToDeg(1))
+	acc.AccuracyMap = map[string]float32{}
+	if f.gps != nil {
+		acc.AccuracyMap[AccuracyEastVariance] = float32(p[stateEast][stateEast])
+		acc.AccuracyMap[AccuracyNorthVariance] = float32(p[stateNorth][stateNorth])
+		acc.AccuracyMap[AccuracyEastNorthCovariance] = float32(p[stateEast][stateNorth])
+	}
+	if f.headingSupported() {
+		acc.AccuracyMap[AccuracyHeadingVariance] = float32(p[stateHeading][stateHeading] * degs)
+		acc.CompassDegreeError = float32(math.Sqrt(p[stateHeading][stateHeading] * degs))
+	}
+	if f.gps != nil || f.odometry != nil {
+		acc.AccuracyMap[AccuracyLinearVelocityVariance] = float32(p[stateSpeed][stateSpeed])
+	}
+	if f.yawRateSupported() {
+		acc.AccuracyMap[AccuracyAngularVelocityVariance] = float32(p[stateYawRate][stateYawRate] * degs)
+	}
+	return acc, nil
+}
+
+func (f *fusion) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	return &movementsensor.Properties{
+		PositionSupported:           f.gps != nil,
+		OrientationSupported:        f.headingSupported(),
+		CompassHeadingSupported:     f.headingSupported(),
+		LinearVelocitySupported:     f.gps != nil || f.odometry != nil,
+		AngularVelocitySupported:    f.yawRateSupported(),
+		LinearAccelerationSupported: f.imu != nil && f.imu.props.LinearAccelerationSupported,
+	}, nil
+}
+
+func (f *fusion) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return movementsensor.DefaultAPIReadings(ctx, f, extra)
+}
+
+func (f *fusion) Close(context.Context) error {
+	// we do not try to Close the movement sensors that this driver depends on
+	if f.workers != nil {
+		f.workers.Stop()
+	}
+	return nil
+}
+
+func orDefault(value, def float64) float64 {
+	if value == 0 {
+		return def
+	}
+	return value
+}
+
+func square(x float64) float64 {
+	return x * x
+}
@@ -0,0 +1,219 @@
+package fusion
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestConfigValidate(t *testing.T) {
+	cfg := &Config{}
+	_, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "one of imu, gps or odometry is required")
+
+	cfg = &Config{IMU: "imu", Odometry: "odom"}
+	deps, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"imu", "odom"})
+
+	cfg.GPSStdDevMeters = -1
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "gps_std_dev_m")
+}
+
+// robot is the true state of a robot driving east at a steady speed, which the sensors measure with noise.
+type robot struct {
+	origin *spatialmath.LocalTangentPlane
+	rnd    *rand.Rand
+	east   float64
+	speed  float64
+}
+
+func (r *robot) noise(stdDev float64) float64 {
+	return r.rnd.NormFloat64() * stdDev
+}
+
+func (r *robot) sensors() resource.Dependencies {
+	imu := inject.NewMovementSensor("imu")
+	imu.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+		return &movementsensor.Properties{CompassHeadingSupported: true, AngularVelocitySupported: true}, nil
+	}
+	imu.CompassHeadingFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+		return 90 + r.noise(3), nil
+	}
+	imu.AngularVelocityFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+		return spatialmath.AngularVelocity{Z: r.noise(1)}, nil
+	}
+
+	gps := inject.NewMovementSensor("gps")
+	gps.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+		return &movementsensor.Properties{PositionSupported: true}, nil
+	}
+	gps.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+		p, _ := r.origin.PointToGeoPoint(r3.Vector{X: (r.east + r.noise(1)) * 1e3, Y: r.noise(1) * 1e3})
+		return p, 10, nil
+	}
+	gps.AccuracyFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+		return &movementsensor.Accuracy{Hdop: 0.9, Vdop: 1.2, NmeaFix: 1}, nil
+	}
+
+	odometry := inject.NewMovementSensor("odom")
+	odometry.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+		return &movementsensor.Properties{LinearVelocitySupported: true, AngularVelocitySupported: true}, nil
+	}
+	odometry.LinearVelocityFunc = func(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+		return r3.Vector{Y: r.speed + r.noise(0.05)}, nil
+	}
+	odometry.AngularVelocityFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+		return spatialmath.AngularVelocity{Z: r.noise(2)}, nil
+	}
+
+	return resource.Dependencies{imu.Name(): imu, gps.Name(): gps, odometry.Name(): odometry}
+}
+
+// drive drives the robot for the duration, stepping the fusion ten times a second.
+func (r *robot) drive(ctx context.Context, f *fusion, duration time.Duration) {
+	now := time.Now()
+	for elapsed := time.Duration(0); elapsed < duration; elapsed += 100 * time.Millisecond {
+		now = now.Add(100 * time.Millisecond)
+		r.east += r.speed * 0.1
+		f.step(ctx, now)
+	}
+}
+
+func TestFusion(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	origin := geo.NewPoint(40.7, -74)
+	r := &robot{origin: spatialmath.NewLocalTangentPlane(origin, 10), rnd: rand.New(rand.NewSource(1)), speed: 1}
+	deps := r.sensors()
+
+	t.Run("imu, gps and odometry", func(t *testing.T) {
+		f, err := newFusion(ctx, deps, movementsensor.Named("fused"), &Config{IMU: "imu", GPS: "gps", Odometry: "odom"}, logger)
+		test.That(t, err, test.ShouldBeNil)
+
+		_, _, err = f.Position(ctx, nil)
+		test.That(t, err, test.ShouldBeError, errNoFix)
+
+		props, err := f.Properties(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, props, test.ShouldResemble, &movementsensor.Properties{
+			PositionSupported:        true,
+			OrientationSupported:     true,
+			CompassHeadingSupported:  true,
+			LinearVelocitySupported:  true,
+			AngularVelocitySupported: true,
+		})
+
+		r.east = 0
+		r.drive(ctx, f, 20*time.Second)
+
+		p, alt, err := f.Position(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, alt, test.ShouldEqual, 10)
+		pt := r.origin.GeoPointToPoint(p, 10).Mul(1e-3)
+		test.That(t, pt.X, test.ShouldAlmostEqual, r.east, 0.5)
+		test.That(t, pt.Y, test.ShouldAlmostEqual, 0, 0.5)
+
+		heading, err := f.CompassHeading(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, heading, test.ShouldAlmostEqual, 90, 2)
+		ori, err := f.Orientation(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, ori.EulerAngles().Yaw, test.ShouldAlmostEqual, -math.Pi/2, 0.04)
+
+		linVel, err := f.LinearVelocity(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, linVel.Y, test.ShouldAlmostEqual, 1, 0.05)
+		angVel, err := f.AngularVelocity(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, angVel.Z, test.ShouldAlmostEqual, 0, 3)
+
+		// the covariance shrinks to within what the sensors measure
+		acc, err := f.Accuracy(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, acc.Hdop, test.ShouldEqual, 0.9)
+		test.That(t, acc.AccuracyMap[AccuracyEastVariance], test.ShouldBeBetween, 0, 1)
+		test.That(t, acc.AccuracyMap[AccuracyNorthVariance], test.ShouldBeBetween, 0, 1)
+		test.That(t, acc.AccuracyMap[AccuracyHeadingVariance], test.ShouldBeBetween, 0, 9)
+		test.That(t, acc.CompassDegreeError, test.ShouldBeBetween, 0, 3)
+		test.That(t, acc.AccuracyMap[AccuracyLinearVelocityVariance], test.ShouldBeBetween, 0, 0.01)
+		test.That(t, acc.AccuracyMap, test.ShouldContainKey, AccuracyAngularVelocityVariance)
+
+		_, err = f.LinearAcceleration(ctx, nil)
+		test.That(t, err, test.ShouldBeError, movementsensor.ErrMethodUnimplementedLinearAcceleration)
+	})
+
+	t.Run("heading from gps and odometry", func(t *testing.T) {
+		f, err := newFusion(ctx, deps, movementsensor.Named("fused"), &Config{GPS: "gps", Odometry: "odom"}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		r.east = 0
+		r.drive(ctx, f, 30*time.Second)
+
+		heading, err := f.CompassHeading(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, heading, test.ShouldAlmostEqual, 90, 5)
+	})
+
+	t.Run("odometry alone", func(t *testing.T) {
+		f, err := newFusion(ctx, deps, movementsensor.Named("fused"), &Config{Odometry: "odom"}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		props, err := f.Properties(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, props, test.ShouldResemble, &movementsensor.Properties{
+			LinearVelocitySupported:  true,
+			AngularVelocitySupported: true,
+		})
+		r.drive(ctx, f, time.Second)
+
+		_, _, err = f.Position(ctx, nil)
+		test.That(t, err, test.ShouldBeError, movementsensor.ErrMethodUnimplementedPosition)
+		_, err = f.CompassHeading(ctx, nil)
+		test.That(t, err, test.ShouldBeError, movementsensor.ErrMethodUnimplementedCompassHeading)
+		linVel, err := f.LinearVelocity(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, linVel.Y, test.ShouldAlmostEqual, 1, 0.1)
+	})
+
+	t.Run("model", func(t *testing.T) {
+		conf := resource.Config{
+			Name:                "fused",
+			API:                 movementsensor.API,
+			Model:               model,
+			ConvertedAttributes: &Config{IMU: "imu", UpdateRateHz: 100},
+		}
+		ms, err := newFusionModel(ctx, deps, conf, logger)
+		test.That(t, err, test.ShouldBeNil)
+		time.Sleep(50 * time.Millisecond)
+		heading, err := ms.CompassHeading(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, heading, test.ShouldAlmostEqual, 90, 10)
+		test.That(t, ms.Close(ctx), test.ShouldBeNil)
+	})
+
+	t.Run("gps without position", func(t *testing.T) {
+		_, err := newFusion(ctx, deps, movementsensor.Named("fused"), &Config{GPS: "imu"}, logger)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
+
+func TestWrapAngle(t *testing.T) {
+	test.That(t, wrapAngle(0), test.ShouldEqual, 0)
+	test.That(t, wrapAngle(3*math.Pi/2), test.ShouldAlmostEqual, -math.Pi/2)
+	test.That(t, wrapAngle(-3*math.Pi/2), test.ShouldAlmostEqual, math.Pi/2)
+	test.That(t, wrapAngle(5*math.Pi), test.ShouldAlmostEqual, -math.Pi)
+}
@@ -0,0 +1,81 @@
+package geomag
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DeclinationConfig configures how magnetic compass headings are corrected to true north, either by a fixed
+// declination or by one computed from a magnetic model at the sensor's position.
+type DeclinationConfig struct {
+	// MagneticDeclinationDegs is the declination east of true north in degrees, such as a chart gives.
+	MagneticDeclinationDegs *float64 `json:"magnetic_declination_degs,omitempty"`
+	// MagneticModelFile is the path of a World Magnetic Model coefficient file, such as WMM.COF from NOAA, to compute
+	// the declination from.
+	MagneticModelFile string `json:"magnetic_model_file,omitempty"`
+}
+
+// Validate ensures at most one source of declination is configured.
+func (cfg *DeclinationConfig) Validate(path string) error {
+	if cfg.MagneticDeclinationDegs != nil && cfg.MagneticModelFile != "" {
+		return errors.Errorf("%s: only one of magnetic_declination_degs and magnetic_model_file can be set", path)
+	}
+	if d := cfg.MagneticDeclinationDegs; d != nil && (math.IsNaN(*d) || math.Abs(*d) > 180) {
+		return errors.Errorf("%s: magnetic_declination_degs must be from -180 to 180, got %v", path, *d)
+	}
+	return nil
+}
+
+// Declination corrects magnetic compass headings to true north.
+type Declination struct {
+	fixed *float64
+	model *Model
+}
+
+// NewDeclination returns the declination of a config, loading its magnetic model if it has one, or nil if the
+// config has neither.
+func NewDeclination(cfg DeclinationConfig) (*Declination, error) {
+	switch {
+	case cfg.MagneticDeclinationDegs != nil:
+		fixed := *cfg.MagneticDeclinationDegs
+		return &Declination{fixed: &fixed}, nil
+	case cfg.MagneticModelFile != "":
+		model, err := LoadCOF(cfg.MagneticModelFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot load magnetic model")
+		}
+		return &Declination{model: model}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// NeedsPosition returns whether the declination depends on where the sensor is.
+func (d *Declination) NeedsPosition() bool {
+	return d.model != nil
+}
+
+// Model returns the magnetic model the declination is computed from, or nil if it is fixed.
+func (d *Declination) Model() *Model {
+	return d.model
+}
+
+// At returns the declination in degrees at a latitude and longitude in degrees and height in meters at a time.
+func (d *Declination) At(lat, lng, heightMeters float64, t time.Time) float64 {
+	if d.fixed != nil {
+		return *d.fixed
+	}
+	return d.model.Field(lat, lng, heightMeters, t).Declination
+}
+
+// TrueHeading corrects a magnetic heading in degrees by a declination east of true north, returning a heading from
+// 0 up to 360 degrees.
+func TrueHeading(magneticHeading, declination float64) float64 {
+	heading := math.Mod(magneticHeading+declination, 360)
+	if heading < 0 {
+		heading += 360
+	}
+	return heading
+}
@@ -0,0 +1,208 @@
+// Package geomag computes the Earth's magnetic field from a spherical harmonic model such as the World Magnetic Model,
+// so that magnetic compass headings can be corrected to true north.
+package geomag
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+)
+
+// the WGS84 ellipsoid and the reference radius of the model, in km.
+const (
+	wgs84A          = 6378.137
+	wgs84F          = 1 / 298.257223563
+	referenceRadius = 6371.2
+)
+
+// maxDegree is the highest degree of the spherical harmonics a model can have.
+const maxDegree = 12
+
+// validYears is how long after its epoch a model is meant to be used.
+const validYears = 5
+
+// A Model is a spherical harmonic model of the main magnetic field, with a linear secular variation from its epoch.
+type Model struct {
+	Name string
+	// Epoch is the decimal year the coefficients are for.
+	Epoch  float64
+	degree int
+	// Schmidt semi-normalized Gauss coefficients in nT, and their rates in nT per year, by degree and order.
+	g, h, gDot, hDot [maxDegree + 1][maxDegree + 1]float64
+}
+
+// ReadCOF reads a model from the coefficient file format the World Magnetic Model is published in, a header line
+// with the epoch and name followed by lines of degree, order, g, h and their rates, ending with a line of 9s.
+func ReadCOF(r io.Reader) (*Model, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("empty magnetic model coefficient file")
+	}
+	header := strings.Fields(scanner.Text())
+	if len(header) < 2 {
+		return nil, errors.Errorf("invalid magnetic model header %q", scanner.Text())
+	}
+	epoch, err := strconv.ParseFloat(header[0], 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid magnetic model epoch")
+	}
+	m := &Model{Name: header[1], Epoch: epoch}
+	line := 1
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(text, "9999") {
+			break
+		}
+		if text == "" {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) < 6 {
+			return nil, errors.Errorf("line %d of magnetic model needs 6 fields, got %q", line, text)
+		}
+		n, errN := strconv.Atoi(fields[0])
+		order, errM := strconv.Atoi(fields[1])
+		if errN != nil || errM != nil || n < 1 || n > maxDegree || order < 0 || order > n {
+			return nil, errors.Errorf("line %d of magnetic model has invalid degree and order %q", line, text)
+		}
+		var values [4]float64
+		for i := range values {
+			if values[i], err = strconv.ParseFloat(fields[2+i], 64); err != nil {
+				return nil, errors.Wrapf(err, "line %d of magnetic model", line)
+			}
+		}
+		m.g[n][order], m.h[n][order], m.gDot[n][order], m.hDot[n][order] = values[0], values[1], values[2], values[3]
+		m.degree = max(m.degree, n)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if m.degree == 0 {
+		return nil, errors.New("magnetic model has no coefficients")
+	}
+	return m, nil
+}
+
+// LoadCOF reads a model from a coefficient file on disk.
+func LoadCOF(path string) (*Model, error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.UncheckedErrorFunc(f.Close)
+	return ReadCOF(f)
+}
+
+// Field is the magnetic field at a place and time.
+type Field struct {
+	// North, East and Down are the components of the field in nT.
+	North, East, Down float64
+	// Declination is the angle of the horizontal field east of true north, and Inclination is the angle of the field
+	// below the horizontal, both in degrees.
+	Declination, Inclination float64
+}
+
+// Horizontal returns the strength of the horizontal field in nT.
+func (f Field) Horizontal() float64 {
+	return math.Hypot(f.North, f.East)
+}
+
+// ValidAt returns whether the model is meant to be used at the given time, which is within five years of its epoch.
+func (m *Model) ValidAt(t time.Time) bool {
+	year := decimalYear(t)
+	return year >= m.Epoch && year <= m.Epoch+validYears
+}
+
+// Field returns the magnetic field at a geodetic latitude and longitude in degrees and a height in meters above the
+// WGS84 ellipsoid, at the given time.
+func (m *Model) Field(lat, lng, heightMeters float64, t time.Time) Field {
+	dt := decimalYear(t) - m.Epoch
+	phi := lat * math.Pi / 180
+	lambda := lng * math.Pi / 180
+	heightKm := heightMeters / 1000
+
+	// geodetic to geocentric spherical coordinates
+	e2 := wgs84F * (2 - wgs84F)
+	sinPhi, cosPhi := math.Sincos(phi)
+	rc := wgs84A / math.Sqrt(1-e2*sinPhi*sinPhi)
+	p := (rc + heightKm) * cosPhi
+	z := (rc*(1-e2) + heightKm) * sinPhi
+	r := math.Hypot(p, z)
+	phiC := math.Asin(z / r)
+
+	// associated Legendre functions of the cosine of the colatitude and their derivatives by colatitude
+	x, s := math.Sin(phiC), math.Cos(phiC)
+	if s < 1e-10 {
+		// the east component is undefined at the poles
+		s = 1e-10
+	}
+	var pnm, dpnm [maxDegree + 1][maxDegree + 1]float64
+	pnm[0][0] = 1
+	for n := 1; n <= m.degree; n++ {
+		if n == 1 {
+			pnm[1][1], dpnm[1][1] = s, x
+		} else {
+			k := math.Sqrt(float64(2*n-1) / float64(2*n))
+			pnm[n][n] = k * s * pnm[n-1][n-1]
+			dpnm[n][n] = k * (s*dpnm[n-1][n-1] + x*pnm[n-1][n-1])
+		}
+		for order := 0; order < n; order++ {
+			a := float64(2*n - 1)
+			b := math.Sqrt(float64((n-1)*(n-1) - order*order))
+			c := math.Sqrt(float64(n*n - order*order))
+			var p2, dp2 float64
+			if n >= 2 {
+				p2, dp2 = pnm[n-2][order], dpnm[n-2][order]
+			}
+			pnm[n][order] = (a*x*pnm[n-1][order] - b*p2) / c
+			dpnm[n][order] = (a*(x*dpnm[n-1][order]-s*pnm[n-1][order]) - b*dp2) / c
+		}
+	}
+
+	// the field in geocentric north, east and down
+	var north, east, down float64
+	ratio := referenceRadius / r
+	scale := ratio * ratio
+	for n := 1; n <= m.degree; n++ {
+		scale *= ratio
+		for order := 0; order <= n; order++ {
+			g := m.g[n][order] + dt*m.gDot[n][order]
+			h := m.h[n][order] + dt*m.hDot[n][order]
+			sinM, cosM := math.Sincos(float64(order) * lambda)
+			north += scale * (g*cosM + h*sinM) * dpnm[n][order]
+			east += scale * float64(order) * (g*sinM - h*cosM) * pnm[n][order] / s
+			down -= scale * float64(n+1) * (g*cosM + h*sinM) * pnm[n][order]
+		}
+	}
+
+	// rotate to geodetic north and down
+	sinPsi, cosPsi := math.Sincos(phiC - phi)
+	f := Field{
+		North: north*cosPsi - down*sinPsi,
+		East:  east,
+		Down:  north*sinPsi + down*cosPsi,
+	}
+	f.Declination = math.Atan2(f.East, f.North) * 180 / math.Pi
+	f.Inclination = math.Atan2(f.Down, f.Horizontal()) * 180 / math.Pi
+	return f
+}
+
+// decimalYear returns a time as a year with a fraction, as model epochs are given.
+func decimalYear(t time.Time) float64 {
+	t = t.UTC()
+	start := time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+	return float64(t.Year()) + t.Sub(start).Seconds()/end.Sub(start).Seconds()
+}
@@ -0,0 +1,108 @@
+package geomag
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+)
+
+// dipole is a model of only the axial dipole, whose field at the equator points north.
+const dipole = `    2020.0            DIPOLE        12/10/2019
+  1  0  -30000.0       0.0       10.0        0.0
+999999999999999999999999999999999999999999999999
+999999999999999999999999999999999999999999999999
+`
+
+// tilted adds an equatorial dipole to the axial one.
+const tilted = `    2020.0            TILTED        12/10/2019
+  1  0  -30000.0       0.0        0.0        0.0
+  1  1   -2000.0    5000.0        0.0        0.0
+999999999999999999999999999999999999999999999999
+`
+
+func TestReadCOF(t *testing.T) {
+	m, err := ReadCOF(strings.NewReader(dipole))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, m.Name, test.ShouldEqual, "DIPOLE")
+	test.That(t, m.Epoch, test.ShouldEqual, 2020.0)
+	test.That(t, m.ValidAt(time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)), test.ShouldBeTrue)
+	test.That(t, m.ValidAt(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)), test.ShouldBeFalse)
+
+	for _, bad := range []string{
+		"",
+		"2020.0\n",
+		"epoch WMM\n",
+		"2020.0 WMM\n9999\n",
+		"2020.0 WMM\n 1 0 -30000.0 0.0\n",
+		"2020.0 WMM\n 1 2 -30000.0 0.0 0.0 0.0\n",
+		"2020.0 WMM\n 13 0 -30000.0 0.0 0.0 0.0\n",
+		"2020.0 WMM\n 1 0 -30000.0 x 0.0 0.0\n",
+	} {
+		_, err := ReadCOF(strings.NewReader(bad))
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
+
+func TestField(t *testing.T) {
+	m, err := ReadCOF(strings.NewReader(dipole))
+	test.That(t, err, test.ShouldBeNil)
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// at the equator the field of the axial dipole is horizontal and points north
+	f := m.Field(0, 0, 0, epoch)
+	test.That(t, f.North, test.ShouldAlmostEqual, 30000*math.Pow(referenceRadius/wgs84A, 3), 1e-6)
+	test.That(t, f.East, test.ShouldAlmostEqual, 0, 1e-9)
+	test.That(t, f.Down, test.ShouldAlmostEqual, 0, 1e-9)
+	test.That(t, f.Declination, test.ShouldAlmostEqual, 0, 1e-9)
+	test.That(t, f.Inclination, test.ShouldAlmostEqual, 0, 1e-9)
+
+	// it points down in the north, and the same everywhere along a parallel
+	f = m.Field(45, 120, 1000, epoch)
+	test.That(t, f.Down, test.ShouldBeGreaterThan, 0)
+	test.That(t, f.Inclination, test.ShouldBeBetween, 45, 90)
+	test.That(t, f.Declination, test.ShouldAlmostEqual, 0, 1e-9)
+	test.That(t, m.Field(45, -60, 1000, epoch), test.ShouldResemble, f)
+
+	// the coefficients change by their rates over the years
+	later := m.Field(0, 0, 0, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	test.That(t, later.North, test.ShouldAlmostEqual, 29990*math.Pow(referenceRadius/wgs84A, 3), 1e-6)
+
+	// the equatorial dipole turns the field east or west depending on the longitude
+	m, err = ReadCOF(strings.NewReader(tilted))
+	test.That(t, err, test.ShouldBeNil)
+	f = m.Field(0, 90, 0, epoch)
+	test.That(t, f.Declination, test.ShouldAlmostEqual, math.Atan2(-2000, 30000)*180/math.Pi, 1e-9)
+	f = m.Field(0, 0, 0, epoch)
+	test.That(t, f.Declination, test.ShouldAlmostEqual, math.Atan2(-5000, 30000)*180/math.Pi, 1e-9)
+	f = m.Field(0, 180, 0, epoch)
+	test.That(t, f.Declination, test.ShouldAlmostEqual, math.Atan2(5000, 30000)*180/math.Pi, 1e-9)
+}
+
+func TestDeclination(t *testing.T) {
+	fixed := 12.5
+	cfg := DeclinationConfig{MagneticDeclinationDegs: &fixed, MagneticModelFile: "WMM.COF"}
+	test.That(t, cfg.Validate("path"), test.ShouldNotBeNil)
+	cfg.MagneticModelFile = ""
+	test.That(t, cfg.Validate("path"), test.ShouldBeNil)
+	tooFar := 200.0
+	test.That(t, (&DeclinationConfig{MagneticDeclinationDegs: &tooFar}).Validate("path"), test.ShouldNotBeNil)
+
+	d, err := NewDeclination(DeclinationConfig{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, d, test.ShouldBeNil)
+
+	d, err = NewDeclination(cfg)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, d.NeedsPosition(), test.ShouldBeFalse)
+	test.That(t, d.At(40, -74, 0, time.Now()), test.ShouldEqual, 12.5)
+
+	_, err = NewDeclination(DeclinationConfig{MagneticModelFile: "/does/not/exist.COF"})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	test.That(t, TrueHeading(350, 12.5), test.ShouldAlmostEqual, 2.5)
+	test.That(t, TrueHeading(5, -12.5), test.ShouldAlmostEqual, 352.5)
+	test.That(t, TrueHeading(90, 0), test.ShouldEqual, 90)
+}
@@ -24,13 +24,14 @@ type NMEAMovementSensor struct {
 
 // newNMEAMovementSensor creates a new movement sensor.
 func newNMEAMovementSensor(
-	_ context.Context, name resource.Name, dev gpsutils.DataReader, logger logging.Logger,
+	_ context.Context, name resource.Name, dev gpsutils.DataReader, datum string, logger logging.Logger,
 ) (NmeaMovementSensor, error) {
 	g := &NMEAMovementSensor{
 		Named:      name.AsNamed(),
 		logger:     logger,
 		cachedData: gpsutils.NewCachedData(dev, logger),
 	}
+	g.cachedData.SetDatum(datum)
 
 	return g, nil
 }
@@ -115,10 +116,18 @@ func (g *NMEAMovementSensor) Readings(
 	}
 	readings["fix"] = fix
 	readings["satellites_in_view"] = satsInView
+	for k, v := range g.cachedData.UBXReadings() {
+		readings[k] = v
+	}
 
 	return readings, nil
 }
 
+// DoCommand configures survey-in on a u-blox receiver connected over serial, so it can be a base station.
+func (g *NMEAMovementSensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return g.cachedData.DoCommand(ctx, cmd)
+}
+
 // Properties returns what movement sensor capabilities we have.
 func (g *NMEAMovementSensor) Properties(
 	ctx context.Context, extra map[string]interface{},
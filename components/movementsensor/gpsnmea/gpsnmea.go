@@ -10,6 +10,9 @@ package gpsnmea
 	Example GPS NMEA chip datasheet:
 	https://content.u-blox.com/sites/default/files/NEO-M9N-00B_DataSheet_UBX-19014285.pdf
 
+	Over serial, u-blox receivers may send UBX as well as NMEA, which adds the RTK status, the position
+	covariance and the heading of a dual antenna receiver, and lets DoCommand survey in a base station.
+
 */
 
 import (
@@ -34,6 +37,8 @@ func connectionTypeError(connType, serialConn, i2cConn string) error {
 // Config is used for converting NMEA Movement Sensor attibutes.
 type Config struct {
 	ConnectionType string `json:"connection_type"`
+	// Datum is the datum positions are reported in, WGS84 by default.
+	Datum string `json:"datum,omitempty"`
 
 	*gpsutils.SerialConfig `json:"serial_attributes,omitempty"`
 	*gpsutils.I2CConfig    `json:"i2c_attributes,omitempty"`
@@ -44,6 +49,9 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 	if cfg.ConnectionType == "" {
 		return nil, resource.NewConfigValidationFieldRequiredError(path, "connection_type")
 	}
+	if err := gpsutils.ValidateDatum(path, cfg.Datum); err != nil {
+		return nil, err
+	}
 
 	switch strings.ToLower(cfg.ConnectionType) {
 	case i2cStr:
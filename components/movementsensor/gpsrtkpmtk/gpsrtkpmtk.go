@@ -25,7 +25,10 @@ package gpsrtkpmtk
 			"ntrip_mountpoint": "MNTPT",
 			"ntrip_password": "pass",
 			"ntrip_url": "http://ntrip/url",
-			"ntrip_username": "usr"
+			"ntrip_username": "usr",
+			"ntrip_gga_interval_sec": 10,
+			"ntrip_timeout_sec": 30,
+			"ntrip_max_backoff_sec": 60
 		},
 		"depends_on": [],
 	}
@@ -33,19 +36,16 @@ package gpsrtkpmtk
 */
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"math"
-	"strings"
 	"sync"
 
-	"github.com/go-gnss/rtcm/rtcm3"
 	"github.com/golang/geo/r3"
 	geo "github.com/kellydunn/golang-geo"
-	"go.viam.com/utils"
+	"go.uber.org/multierr"
 
 	"go.viam.com/rdk/components/board/genericlinux/buses"
 	"go.viam.com/rdk/components/movementsensor"
@@ -68,6 +68,15 @@ type Config struct {
 	NtripMountpoint      string `json:"ntrip_mountpoint,omitempty"`
 	NtripPass            string `json:"ntrip_password,omitempty"`
 	NtripUser            string `json:"ntrip_username,omitempty"`
+	// NtripCredentialsFile, NtripGGAIntervalSec, NtripTimeoutSec and NtripMaxBackoffSec are as they are
+	// in gpsutils.NtripConfig.
+	NtripCredentialsFile string  `json:"ntrip_credentials_file,omitempty"`
+	NtripGGAIntervalSec  float64 `json:"ntrip_gga_interval_sec,omitempty"`
+	NtripTimeoutSec      float64 `json:"ntrip_timeout_sec,omitempty"`
+	NtripMaxBackoffSec   float64 `json:"ntrip_max_backoff_sec,omitempty"`
+
+	// Datum is the datum positions are reported in, WGS84 by default.
+	Datum string `json:"datum,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -82,6 +91,10 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 		return nil, err
 	}
 
+	if err := gpsutils.ValidateDatum(path, cfg.Datum); err != nil {
+		return nil, err
+	}
+
 	return []string{}, nil
 }
 
@@ -121,11 +134,9 @@ type rtkI2C struct {
 	cancelCtx  context.Context
 	cancelFunc func()
 
-	activeBackgroundWorkers sync.WaitGroup
-
 	mu          sync.Mutex
 	ntripClient *gpsutils.NtripInfo
-	ntripStatus bool
+	corrections *gpsutils.CorrectionSource
 
 	err          movementsensor.LastError
 	lastposition movementsensor.LastPosition
@@ -134,13 +145,15 @@ type rtkI2C struct {
 	correctionWriter io.ReadWriteCloser
 
 	bus     buses.I2C
+	handle  buses.I2CHandle
 	mockI2c buses.I2C // Will be nil unless we're in a unit test
 	wbaud   int
 	addr    byte
 }
 
-// Reconfigure reconfigures attributes.
-func (g *rtkI2C) Reconfigure(ctx context.Context, deps resource.Dependencies, conf resource.Config) error {
+// configure sets the attributes of a new sensor. The sensor is rebuilt whenever they change instead of
+// being reconfigured, since its corrections keep the NTRIP client they were started with.
+func (g *rtkI2C) configure(ctx context.Context, conf resource.Config) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	newConf, err := resource.NativeConfig[*Config](conf)
@@ -172,6 +185,10 @@ func (g *rtkI2C) Reconfigure(ctx context.Context, deps resource.Dependencies, co
 		NtripPass:            newConf.NtripPass,
 		NtripMountpoint:      newConf.NtripMountpoint,
 		NtripConnectAttempts: newConf.NtripConnectAttempts,
+		NtripCredentialsFile: newConf.NtripCredentialsFile,
+		NtripGGAIntervalSec:  newConf.NtripGGAIntervalSec,
+		NtripTimeoutSec:      newConf.NtripTimeoutSec,
+		NtripMaxBackoffSec:   newConf.NtripMaxBackoffSec,
 	}
 
 	// Init ntripInfo from attributes
@@ -180,16 +197,9 @@ func (g *rtkI2C) Reconfigure(ctx context.Context, deps resource.Dependencies, co
 		return err
 	}
 
-	if g.ntripClient == nil {
-		g.ntripClient = tempNtripClient
-	} else {
-		tempNtripClient.Client = g.ntripClient.Client
-		tempNtripClient.Stream = g.ntripClient.Stream
-
-		g.ntripClient = tempNtripClient
-	}
+	g.ntripClient = tempNtripClient
 
-	g.logger.CDebug(ctx, "done reconfiguring")
+	g.logger.CDebug(ctx, "done configuring")
 
 	return nil
 }
@@ -228,7 +238,7 @@ func makeRTKI2C(
 		mockI2c:      mockI2c,
 	}
 
-	if err = g.Reconfigure(ctx, deps, conf); err != nil {
+	if err = g.configure(ctx, conf); err != nil {
 		return nil, err
 	}
 
@@ -248,6 +258,7 @@ func makeRTKI2C(
 		return nil, err
 	}
 	g.cachedData = gpsutils.NewCachedData(dev, logger)
+	g.cachedData.SetDatum(newConf.Datum)
 
 	if err := g.start(); err != nil {
 		return nil, err
@@ -255,84 +266,17 @@ func makeRTKI2C(
 	return g, g.err.Get()
 }
 
-// Start begins NTRIP receiver with i2c protocol and begins reading/updating MovementSensor measurements.
+// Start sets the MovementSensor up to output NMEA over I2C, and starts streaming corrections to it from the NTRIP
+// caster, which carries on reconnecting in the background whenever the stream is lost.
 func (g *rtkI2C) start() error {
-	g.activeBackgroundWorkers.Add(1)
-	utils.PanicCapturingGo(func() { g.receiveAndWriteI2C(g.cancelCtx) })
-
-	return g.err.Get()
-}
-
-// getStream attempts to connect to ntrip stream until successful connection or timeout.
-func (g *rtkI2C) getStream(mountPoint string, maxAttempts int) error {
-	success := false
-	attempts := 0
-
-	var rc io.ReadCloser
-	var err error
-
-	g.logger.Debug("Getting NTRIP stream")
-
-	for !success && attempts < maxAttempts {
-		select {
-		case <-g.cancelCtx.Done():
-			return errors.New("Canceled")
-		default:
-		}
-
-		rc, err = func() (io.ReadCloser, error) {
-			g.mu.Lock()
-			defer g.mu.Unlock()
-			return g.ntripClient.Client.GetStream(mountPoint)
-		}()
-		if err == nil {
-			success = true
-		}
-		attempts++
-	}
-
-	if err != nil {
-		// if the error is related to ICY, we log it as warning.
-		if strings.Contains(err.Error(), "ICY") {
-			g.logger.Warnf("Detected old HTTP protocol: %s", err)
-		} else {
-			g.logger.Errorf("Can't connect to NTRIP stream: %s", err)
-			return err
-		}
-	}
-
-	g.logger.Debug("Connected to stream")
-	g.mu.Lock()
-	defer g.mu.Unlock()
-
-	g.ntripClient.Stream = rc
-	return g.err.Get()
-}
-
-// receiveAndWriteI2C connects to NTRIP receiver and sends correction stream to the MovementSensor through I2C protocol.
-func (g *rtkI2C) receiveAndWriteI2C(ctx context.Context) {
-	defer g.activeBackgroundWorkers.Done()
-	if err := g.cancelCtx.Err(); err != nil {
-		return
-	}
-	err := g.ntripClient.Connect(g.cancelCtx, g.logger)
-	if err != nil {
-		g.err.Set(err)
-		return
-	}
-
-	if !g.ntripClient.Client.IsCasterAlive() {
-		g.logger.CInfof(ctx, "caster %s seems to be down", g.ntripClient.URL)
-	}
+	ctx := g.cancelCtx
 
 	// establish I2C connection
 	handle, err := g.bus.OpenHandle(g.addr)
 	if err != nil {
 		g.logger.CErrorf(ctx, "can't open gps i2c %s", err)
-		g.err.Set(err)
-		return
+		return err
 	}
-	defer utils.UncheckedErrorFunc(handle.Close)
 
 	// Send GLL, RMC, VTG, GGA, GSA, and GSV sentences each 1000ms
 	baudcmd := fmt.Sprintf("PMTK251,%d", g.wbaud)
@@ -348,110 +292,41 @@ func (g *rtkI2C) receiveAndWriteI2C(ctx context.Context) {
 	err = handle.Write(ctx, cmd314)
 	if err != nil {
 		g.logger.CDebug(ctx, "failed to set NMEA output")
-		g.err.Set(err)
-		return
+		return multierr.Combine(err, handle.Close())
 	}
 
 	err = handle.Write(ctx, cmd220)
 	if err != nil {
 		g.logger.CDebug(ctx, "failed to set NMEA update rate")
-		g.err.Set(err)
-		return
+		return multierr.Combine(err, handle.Close())
 	}
 
-	err = g.getStream(g.ntripClient.MountPoint, g.ntripClient.MaxConnectAttempts)
-	if err != nil {
-		g.err.Set(err)
-		return
-	}
-
-	// create a buffer
-	w := &bytes.Buffer{}
-	r := io.TeeReader(g.ntripClient.Stream, w)
-
-	buf := make([]byte, 1100)
-	n, err := g.ntripClient.Stream.Read(buf)
-	if err != nil {
-		g.err.Set(err)
-		return
-	}
-
-	wI2C := movementsensor.PMTKAddChk(buf[:n])
-
-	// port still open
-	err = handle.Write(ctx, wI2C)
-	if err != nil {
-		g.logger.CErrorf(ctx, "i2c handle write failed %s", err)
-		g.err.Set(err)
-		return
-	}
-
-	scanner := rtcm3.NewScanner(r)
-
 	g.mu.Lock()
-	g.ntripStatus = true
+	g.handle = handle
 	g.mu.Unlock()
+	g.corrections = gpsutils.NewCorrectionSource(g.ntripClient, g.writeCorrections, g.cachedData.LastGGA, g.logger)
+	return g.err.Get()
+}
 
-	// It's okay to skip the mutex on this next line: g.ntripStatus can only be mutated by this
-	// goroutine itself.
-	for g.ntripStatus {
-		select {
-		case <-g.cancelCtx.Done():
-			g.err.Set(err)
-			return
-		default:
-		}
-
-		msg, err := scanner.NextMessage()
-		if err != nil {
-			g.mu.Lock()
-			g.ntripStatus = false
-			g.mu.Unlock()
-
-			if msg == nil {
-				g.logger.CDebug(ctx, "No message... reconnecting to stream...")
-				err = g.getStream(g.ntripClient.MountPoint, g.ntripClient.MaxConnectAttempts)
-				if err != nil {
-					g.err.Set(err)
-					return
-				}
-
-				w = &bytes.Buffer{}
-				r = io.TeeReader(g.ntripClient.Stream, w)
-
-				buf = make([]byte, 1100)
-				n, err := g.ntripClient.Stream.Read(buf)
-				if err != nil {
-					g.err.Set(err)
-					return
-				}
-				wI2C := movementsensor.PMTKAddChk(buf[:n])
-
-				err = handle.Write(ctx, wI2C)
-
-				if err != nil {
-					g.logger.CErrorf(ctx, "i2c handle write failed %s", err)
-					g.err.Set(err)
-					return
-				}
-
-				scanner = rtcm3.NewScanner(r)
-				g.mu.Lock()
-				g.ntripStatus = true
-				g.mu.Unlock()
-				continue
-			}
-		}
+// writeCorrections sends an RTCM frame to the MovementSensor through I2C protocol.
+func (g *rtkI2C) writeCorrections(ctx context.Context, frame []byte) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.handle == nil {
+		return errors.New("i2c handle is closed")
 	}
+	if err := g.handle.Write(ctx, movementsensor.PMTKAddChk(frame)); err != nil {
+		g.logger.CErrorf(ctx, "i2c handle write failed %s", err)
+		return err
+	}
+	return nil
 }
 
 // getNtripConnectionStatus returns true if connection to NTRIP stream is OK, false if not
 //
 //nolint:all
 func (g *rtkI2C) getNtripConnectionStatus() (bool, error) {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-	return g.ntripStatus, g.err.Get()
+	return g.corrections.Status().Connected, g.err.Get()
 }
 
 // Position returns the current geographic location of the MOVEMENTSENSOR.
@@ -612,45 +487,45 @@ func (g *rtkI2C) Readings(ctx context.Context, extra map[string]interface{}) (ma
 
 	readings["fix"] = fix
 	readings["satellites_in_view"] = satsInView
+	for k, v := range g.corrections.Readings() {
+		readings[k] = v
+	}
 
 	return readings, nil
 }
 
 // Close shuts down the rtkI2C.
 func (g *rtkI2C) Close(ctx context.Context) error {
-	g.mu.Lock()
 	g.cancelFunc()
+	// the corrections are stopped first, since writing them takes the lock
+	g.corrections.Close()
 
+	g.mu.Lock()
+	defer g.mu.Unlock()
 	if err := g.cachedData.Close(ctx); err != nil {
-		g.mu.Unlock()
 		return err
 	}
 
 	// close ntrip writer
 	if g.correctionWriter != nil {
 		if err := g.correctionWriter.Close(); err != nil {
-			g.mu.Unlock()
 			return err
 		}
 		g.correctionWriter = nil
 	}
 
-	// close ntrip client and stream
-	if g.ntripClient.Client != nil {
-		g.ntripClient.Client.CloseIdleConnections()
-		g.ntripClient.Client = nil
-	}
-
-	if g.ntripClient.Stream != nil {
-		if err := g.ntripClient.Stream.Close(); err != nil {
-			g.mu.Unlock()
+	if g.handle != nil {
+		if err := g.handle.Close(); err != nil {
 			return err
 		}
-		g.ntripClient.Stream = nil
+		g.handle = nil
 	}
 
-	g.mu.Unlock()
-	g.activeBackgroundWorkers.Wait()
+	// close ntrip client
+	if g.ntripClient.Client != nil {
+		g.ntripClient.Client.CloseIdleConnections()
+		g.ntripClient.Client = nil
+	}
 
 	if err := g.err.Get(); err != nil && !errors.Is(err, context.Canceled) {
 		return err
@@ -84,7 +84,7 @@ func TestValidateRTK(t *testing.T) {
 	})
 }
 
-func TestReconfigure(t *testing.T) {
+func TestConfigure(t *testing.T) {
 	mockI2c := inject.I2C{}
 	g := &rtkI2C{
 		wbaud:   9600,
@@ -105,7 +105,11 @@ func TestReconfigure(t *testing.T) {
 			I2CBaudRate:          115200,
 		},
 	}
+	// the sensor is rebuilt rather than reconfigured, so its corrections never keep a stale NTRIP client
 	err := g.Reconfigure(context.Background(), nil, conf)
+	test.That(t, err, test.ShouldBeError, resource.NewMustRebuildError(conf.ResourceName()))
+
+	err = g.configure(context.Background(), conf)
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, g.wbaud, test.ShouldEqual, 115200)
 	test.That(t, g.addr, test.ShouldEqual, byte(44))
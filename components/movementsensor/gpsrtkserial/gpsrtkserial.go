@@ -23,6 +23,9 @@ package gpsrtkserial
         "ntrip_connect_attempts": 10,
         "ntrip_mountpoint": "MTPT",
         "ntrip_password": "pwd",
+        "ntrip_gga_interval_sec": 10,
+        "ntrip_timeout_sec": 30,
+        "ntrip_max_backoff_sec": 60,
 		"serial_baud_rate": 115200,
         "serial_path": "serial-path"
       },
@@ -32,20 +35,15 @@ package gpsrtkserial
 */
 
 import (
-	"bufio"
 	"context"
 	"errors"
-	"fmt"
 	"io"
 	"math"
-	"strings"
 	"sync"
 
-	"github.com/go-gnss/rtcm/rtcm3"
 	"github.com/golang/geo/r3"
 	slib "github.com/jacobsa/go-serial/serial"
 	geo "github.com/kellydunn/golang-geo"
-	"go.viam.com/utils"
 
 	"go.viam.com/rdk/components/movementsensor"
 	"go.viam.com/rdk/components/movementsensor/gpsutils"
@@ -71,6 +69,15 @@ type Config struct {
 	NtripMountpoint      string `json:"ntrip_mountpoint,omitempty"`
 	NtripPass            string `json:"ntrip_password,omitempty"`
 	NtripUser            string `json:"ntrip_username,omitempty"`
+	// NtripCredentialsFile, NtripGGAIntervalSec, NtripTimeoutSec and NtripMaxBackoffSec are as they are
+	// in gpsutils.NtripConfig.
+	NtripCredentialsFile string  `json:"ntrip_credentials_file,omitempty"`
+	NtripGGAIntervalSec  float64 `json:"ntrip_gga_interval_sec,omitempty"`
+	NtripTimeoutSec      float64 `json:"ntrip_timeout_sec,omitempty"`
+	NtripMaxBackoffSec   float64 `json:"ntrip_max_backoff_sec,omitempty"`
+
+	// Datum is the datum positions are reported in, WGS84 by default.
+	Datum string `json:"datum,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -83,6 +90,10 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 		return nil, resource.NewConfigValidationFieldRequiredError(path, "ntrip_url")
 	}
 
+	if err := gpsutils.ValidateDatum(path, cfg.Datum); err != nil {
+		return nil, err
+	}
+
 	return nil, nil
 }
 
@@ -103,31 +114,27 @@ type rtkSerial struct {
 	cancelCtx  context.Context
 	cancelFunc func()
 
-	activeBackgroundWorkers sync.WaitGroup
-
 	err                movementsensor.LastError
 	lastposition       movementsensor.LastPosition
 	lastcompassheading movementsensor.LastCompassHeading
 	InputProtocol      string
-	isClosed           bool
+
+	// ntripClient is used by corrections alone once they are started.
+	ntripClient *gpsutils.NtripInfo
+	corrections *gpsutils.CorrectionSource
 
 	mu sync.Mutex
 
 	// everything below this comment is protected by mu
-	isConnectedToNtrip bool
-	ntripClient        *gpsutils.NtripInfo
-	cachedData         *gpsutils.CachedData
-	correctionWriter   io.ReadWriteCloser
-	writePath          string
-	wbaud              int
-	isVirtualBase      bool
-	readerWriter       *bufio.ReadWriter
-	writer             io.Writer
-	reader             io.Reader
+	cachedData       *gpsutils.CachedData
+	correctionWriter io.ReadWriteCloser
+	writePath        string
+	wbaud            int
 }
 
-// Reconfigure reconfigures attributes.
-func (g *rtkSerial) Reconfigure(ctx context.Context, deps resource.Dependencies, conf resource.Config) error {
+// configure sets the attributes of a new sensor. The sensor is rebuilt whenever they change instead of
+// being reconfigured, since its corrections keep the NTRIP client they were started with.
+func (g *rtkSerial) configure(ctx context.Context, conf resource.Config) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
@@ -155,6 +162,10 @@ func (g *rtkSerial) Reconfigure(ctx context.Context, deps resource.Dependencies,
 		NtripPass:            newConf.NtripPass,
 		NtripMountpoint:      newConf.NtripMountpoint,
 		NtripConnectAttempts: newConf.NtripConnectAttempts,
+		NtripCredentialsFile: newConf.NtripCredentialsFile,
+		NtripGGAIntervalSec:  newConf.NtripGGAIntervalSec,
+		NtripTimeoutSec:      newConf.NtripTimeoutSec,
+		NtripMaxBackoffSec:   newConf.NtripMaxBackoffSec,
 	}
 
 	// Init ntripInfo from attributes
@@ -163,14 +174,9 @@ func (g *rtkSerial) Reconfigure(ctx context.Context, deps resource.Dependencies,
 		return err
 	}
 
-	if g.ntripClient != nil { // Copy over the old state
-		tempNtripClient.Client = g.ntripClient.Client
-		tempNtripClient.Stream = g.ntripClient.Stream
-	}
-
 	g.ntripClient = tempNtripClient
 
-	g.logger.Debug("done reconfiguring")
+	g.logger.Debug("done configuring")
 	return nil
 }
 
@@ -196,7 +202,7 @@ func newRTKSerial(
 		lastcompassheading: movementsensor.NewLastCompassHeading(),
 	}
 
-	if err := g.Reconfigure(ctx, deps, conf); err != nil {
+	if err := g.configure(ctx, conf); err != nil {
 		return nil, err
 	}
 
@@ -211,6 +217,7 @@ func newRTKSerial(
 		return nil, err
 	}
 	g.cachedData = gpsutils.NewCachedData(dev, logger)
+	g.cachedData.SetDatum(newConf.Datum)
 
 	if err := g.start(); err != nil {
 		return nil, err
@@ -218,52 +225,15 @@ func newRTKSerial(
 	return g, g.err.Get()
 }
 
+// start opens the serial port for corrections and starts streaming them from the NTRIP caster, which carries
+// on reconnecting in the background whenever the stream is lost.
 func (g *rtkSerial) start() error {
-	err := g.connectToNTRIP()
-	if err != nil {
-		return err
-	}
-	g.activeBackgroundWorkers.Add(1)
-	utils.PanicCapturingGo(g.receiveAndWriteSerial)
-	return g.err.Get()
-}
-
-// getStream attempts to connect to ntrip stream. We give up after maxAttempts unsuccessful tries.
-func (g *rtkSerial) getStream(mountPoint string, maxAttempts int) error {
-	success := false
-	attempts := 0
-
-	var rc io.ReadCloser
-	var err error
-
-	g.logger.Debug("Getting NTRIP stream")
-
-	for !success && attempts < maxAttempts {
-		select {
-		case <-g.cancelCtx.Done():
-			return errors.New("Canceled")
-		default:
-		}
-
-		rc, err = func() (io.ReadCloser, error) {
-			return g.ntripClient.Client.GetStream(mountPoint)
-		}()
-		if err == nil {
-			success = true
-		}
-		attempts++
-	}
-
-	if err != nil {
-		g.logger.Errorf("Can't connect to NTRIP stream: %s", err)
-		return err
-	}
-	g.logger.Debug("Connected to stream")
-
 	g.mu.Lock()
 	defer g.mu.Unlock()
-
-	g.ntripClient.Stream = rc
+	if err := g.openPort(); err != nil {
+		return err
+	}
+	g.corrections = gpsutils.NewCorrectionSource(g.ntripClient, g.writeCorrections, g.cachedData.LastGGA, g.logger)
 	return g.err.Get()
 }
 
@@ -291,172 +261,15 @@ func (g *rtkSerial) openPort() error {
 	return nil
 }
 
-// closePort closes the serial port.
-func (g *rtkSerial) closePort() {
+// writeCorrections sends an RTCM frame to the MovementSensor through serial.
+func (g *rtkSerial) writeCorrections(ctx context.Context, frame []byte) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
-
-	if g.correctionWriter != nil {
-		err := g.correctionWriter.Close()
-		if err != nil {
-			g.logger.Errorf("Error closing port: %v", err)
-		}
-	}
-}
-
-// connectAndParseSourceTable connects to the NTRIP caster, gets and parses source table
-// from the caster.
-func (g *rtkSerial) connectAndParseSourceTable() error {
-	if err := g.cancelCtx.Err(); err != nil {
-		return g.err.Get()
-	}
-
-	err := g.ntripClient.Connect(g.cancelCtx, g.logger)
-	if err != nil {
-		g.err.Set(err)
-		return g.err.Get()
-	}
-
-	if !g.ntripClient.Client.IsCasterAlive() {
-		g.logger.Infof("caster %s seems to be down, retrying", g.ntripClient.URL)
-		attempts := 0
-		// we will try to connect to the caster five times if it's down.
-		for attempts < 5 {
-			if !g.ntripClient.Client.IsCasterAlive() {
-				attempts++
-				g.logger.Debugf("attempt(s) to connect to caster: %v ", attempts)
-			} else {
-				break
-			}
-		}
-		if attempts == 5 {
-			return fmt.Errorf("caster %s is down", g.ntripClient.URL)
-		}
-	}
-
-	g.logger.Debug("getting source table")
-
-	srcTable, err := g.ntripClient.ParseSourcetable(g.logger)
-	if err != nil {
-		g.logger.Errorf("failed to get source table: %v", err)
-		return err
-	}
-	g.logger.Debugf("sourceTable is: %v\n", srcTable)
-
-	g.logger.Debug("got sourcetable, parsing it...")
-	g.isVirtualBase, err = gpsutils.HasVRSStream(srcTable, g.ntripClient.MountPoint)
-	if err != nil {
-		g.logger.Errorf("can't find mountpoint in source table, found err %v\n", err)
-		return err
-	}
-
-	return nil
-}
-
-// connectToNTRIP connects to NTRIP stream.
-func (g *rtkSerial) connectToNTRIP() error {
-	select {
-	case <-g.cancelCtx.Done():
-		return errors.New("context canceled")
-	default:
-	}
-	err := g.connectAndParseSourceTable()
-	if err != nil {
-		return err
-	}
-
-	err = g.openPort()
-	if err != nil {
-		return err
-	}
-
-	if g.isVirtualBase {
-		g.logger.Debug("connecting to a Virtual Reference Station")
-		err = g.getNtripFromVRS()
-		if err != nil {
-			return err
-		}
-	} else {
-		g.logger.Debug("connecting to NTRIP stream........")
-		g.writer = bufio.NewWriter(g.correctionWriter)
-		err = g.getStream(g.ntripClient.MountPoint, g.ntripClient.MaxConnectAttempts)
-		if err != nil {
-			return err
-		}
-
-		g.reader = io.TeeReader(g.ntripClient.Stream, g.writer)
-	}
-
-	return nil
-}
-
-// receiveAndWriteSerial connects to NTRIP receiver and sends correction stream to the MovementSensor through serial.
-func (g *rtkSerial) receiveAndWriteSerial() {
-	defer g.activeBackgroundWorkers.Done()
-	defer g.closePort()
-
-	var scanner rtcm3.Scanner
-
-	if g.isVirtualBase {
-		scanner = rtcm3.NewScanner(g.readerWriter)
-	} else {
-		scanner = rtcm3.NewScanner(g.reader)
-	}
-
-	g.mu.Lock()
-	g.isConnectedToNtrip = true
-	g.mu.Unlock()
-
-	// It's okay to skip the mutex on this next line: g.isConnectedToNtrip can only be mutated by this
-	// goroutine itself
-	for g.isConnectedToNtrip && !g.isClosed {
-		select {
-		case <-g.cancelCtx.Done():
-			return
-		default:
-		}
-
-		msg, err := scanner.NextMessage()
-		if err != nil {
-			g.mu.Lock()
-			g.isConnectedToNtrip = false
-			g.mu.Unlock()
-
-			if msg == nil {
-				if g.isClosed {
-					return
-				}
-
-				if g.isVirtualBase {
-					g.logger.Debug("reconnecting to the Virtual Reference Station")
-
-					err = g.getNtripFromVRS()
-					if err != nil && !errors.Is(err, io.EOF) {
-						g.err.Set(err)
-						return
-					}
-
-					scanner = rtcm3.NewScanner(g.readerWriter)
-				} else {
-					g.logger.Debug("No message... reconnecting to stream...")
-
-					err = g.getStream(g.ntripClient.MountPoint, g.ntripClient.MaxConnectAttempts)
-					if err != nil {
-						g.err.Set(err)
-						return
-					}
-					g.reader = io.TeeReader(g.ntripClient.Stream, g.writer)
-					scanner = rtcm3.NewScanner(g.reader)
-				}
-
-				g.mu.Lock()
-				g.isConnectedToNtrip = true
-				g.mu.Unlock()
-
-				continue
-			}
-		}
+	if g.correctionWriter == nil {
+		return errors.New("serial port is closed")
 	}
+	_, err := g.correctionWriter.Write(frame)
+	return err
 }
 
 // Most of the movementsensor functions here don't have mutex locks since g.cachedData is protected by
@@ -597,48 +410,48 @@ func (g *rtkSerial) Readings(ctx context.Context, extra map[string]interface{})
 
 	readings["fix"] = fix
 	readings["satellites_in_view"] = satsInView
+	for k, v := range g.cachedData.UBXReadings() {
+		readings[k] = v
+	}
+	for k, v := range g.corrections.Readings() {
+		readings[k] = v
+	}
 
 	return readings, nil
 }
 
+// DoCommand configures survey-in on the u-blox receiver, so it can be a base station.
+func (g *rtkSerial) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return g.cachedData.DoCommand(ctx, cmd)
+}
+
 // Close shuts down the rtkSerial.
 func (g *rtkSerial) Close(ctx context.Context) error {
-	g.mu.Lock()
 	g.cancelFunc()
-
 	g.logger.Debug("Closing GPS RTK Serial")
+	// the corrections are stopped first, since writing them takes the lock
+	g.corrections.Close()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
 	if err := g.cachedData.Close(ctx); err != nil {
-		g.mu.Unlock()
 		return err
 	}
 
 	// close ntrip writer
 	if g.correctionWriter != nil {
 		if err := g.correctionWriter.Close(); err != nil {
-			g.isClosed = true
-			g.mu.Unlock()
 			return err
 		}
 		g.correctionWriter = nil
 	}
 
-	// close ntrip client and stream
+	// close ntrip client
 	if g.ntripClient.Client != nil {
 		g.ntripClient.Client.CloseIdleConnections()
 		g.ntripClient.Client = nil
 	}
 
-	if g.ntripClient.Stream != nil {
-		if err := g.ntripClient.Stream.Close(); err != nil {
-			g.mu.Unlock()
-			return err
-		}
-		g.ntripClient.Stream = nil
-	}
-
-	g.mu.Unlock()
-	g.activeBackgroundWorkers.Wait()
-
 	if err := g.err.Get(); err != nil && !errors.Is(err, context.Canceled) {
 		return err
 	}
@@ -646,62 +459,3 @@ func (g *rtkSerial) Close(ctx context.Context) error {
 	g.logger.Debug("GPS RTK Serial is closed")
 	return nil
 }
-
-// getNtripFromVRS sends GGA messages to the NTRIP Caster over a TCP connection
-// to get the NTRIP steam when the mount point is a Virtual Reference Station.
-func (g *rtkSerial) getNtripFromVRS() error {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-
-	var err error
-	g.readerWriter, err = gpsutils.ConnectToVirtualBase(g.ntripClient, g.logger)
-	if err != nil {
-		return err
-	}
-
-	// read from the socket until we know if a successful connection has been
-	// established.
-	for {
-		line, _, err := g.readerWriter.ReadLine()
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				g.readerWriter = nil
-				return err
-			}
-			g.logger.Error("Failed to read server response:", err)
-			return err
-		}
-
-		if strings.HasPrefix(string(line), "HTTP/1.1 ") {
-			if strings.Contains(string(line), "200 OK") {
-				break
-			}
-			g.logger.Errorf("Bad HTTP response: %v", string(line))
-			return err
-		}
-	}
-
-	ggaMessage, err := gpsutils.GetGGAMessage(g.correctionWriter, g.logger)
-	if err != nil {
-		g.logger.Error("Failed to get GGA message")
-		return err
-	}
-
-	g.logger.Debugf("Writing GGA message: %v\n", string(ggaMessage))
-
-	_, err = g.readerWriter.WriteString(string(ggaMessage))
-	if err != nil {
-		g.logger.Error("Failed to send NMEA data:", err)
-		return err
-	}
-
-	err = g.readerWriter.Flush()
-	if err != nil {
-		g.logger.Error("failed to write to buffer: ", err)
-		return err
-	}
-
-	g.logger.Debug("GGA message sent successfully.")
-
-	return nil
-}
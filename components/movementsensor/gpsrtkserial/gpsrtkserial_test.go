@@ -59,7 +59,7 @@ func TestValidateRTK(t *testing.T) {
 	})
 }
 
-func TestReconfigure(t *testing.T) {
+func TestConfigure(t *testing.T) {
 	g := &rtkSerial{
 		writePath: "/dev/ttyUSB0",
 		wbaud:     9600,
@@ -79,7 +79,11 @@ func TestReconfigure(t *testing.T) {
 		},
 	}
 
+	// the sensor is rebuilt rather than reconfigured, so its corrections never keep a stale NTRIP client
 	err := g.Reconfigure(context.Background(), nil, conf)
+	test.That(t, err, test.ShouldBeError, resource.NewMustRebuildError(conf.ResourceName()))
+
+	err = g.configure(context.Background(), conf)
 
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, g.writePath, test.ShouldResemble, "/dev/ttyUSB1")
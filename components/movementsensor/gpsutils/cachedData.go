@@ -2,8 +2,12 @@ package gpsutils
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"math"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/golang/geo/r3"
 	geo "github.com/kellydunn/golang-geo"
@@ -28,11 +32,17 @@ type DataReader interface {
 type CachedData struct {
 	mu       sync.RWMutex
 	nmeaData NmeaParser
+	ubxData  UbxParser
+	// lastGGA is the last GGA sentence with a fix, which virtual reference stations need the position of.
+	lastGGA string
 
 	err                movementsensor.LastError
 	lastPosition       movementsensor.LastPosition
 	lastCompassHeading movementsensor.LastCompassHeading
 
+	// datum is the name of the datum positions are converted to from the WGS84 the device reports.
+	datum string
+
 	dev    DataReader
 	logger logging.Logger
 
@@ -52,6 +62,13 @@ func NewCachedData(dev DataReader, logger logging.Logger) *CachedData {
 	return &g
 }
 
+// SetDatum converts the positions returned after it is called to the named datum, which ValidateDatum accepts.
+func (g *CachedData) SetDatum(datum string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.datum = datum
+}
+
 // start begins reading nmea messages from dev and updates gps data.
 func (g *CachedData) start(cancelCtx context.Context) {
 	messages := g.dev.Messages()
@@ -72,7 +89,7 @@ func (g *CachedData) start(cancelCtx context.Context) {
 			// Update our struct's gps data in-place
 			err := g.ParseAndUpdate(message)
 			if err != nil {
-				g.logger.CWarnf(cancelCtx, "can't parse nmea sentence: %#v", err)
+				g.logger.CWarnf(cancelCtx, "can't parse gps message: %#v", err)
 				g.logger.Debug("Check: GPS requires clear sky view." +
 					"Ensure the antenna is outdoors if signal is weak or unavailable indoors.")
 			}
@@ -81,11 +98,28 @@ func (g *CachedData) start(cancelCtx context.Context) {
 }
 
 // ParseAndUpdate passes the provided message into the inner NmeaParser object, which parses the
-// NMEA message and updates its state to match.
+// NMEA message and updates its state to match. UBX frames are passed to the UbxParser instead.
 func (g *CachedData) ParseAndUpdate(line string) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
-	return g.nmeaData.ParseAndUpdate(line)
+	if IsUBXFrame(line) {
+		return g.ubxData.ParseAndUpdate([]byte(line), &g.nmeaData)
+	}
+	if err := g.nmeaData.ParseAndUpdate(line); err != nil {
+		return err
+	}
+	ind := strings.Index(line, "$G")
+	if ind != -1 && len(line) >= ind+6 && line[ind+3:ind+6] == "GGA" && g.nmeaData.FixQuality > 0 {
+		g.lastGGA = strings.TrimSpace(line[ind:])
+	}
+	return nil
+}
+
+// LastGGA returns the last GGA sentence the device sent with a fix, or "" if there has not been one.
+func (g *CachedData) LastGGA() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.lastGGA
 }
 
 // Position returns the position and altitide of the sensor, or an error.
@@ -95,6 +129,20 @@ func (g *CachedData) Position(
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 
+	position, alt, err := g.wgs84Position()
+	// the zero and NaN positions mean there is no fix, whatever the datum
+	if position == nil || movementsensor.IsZeroPosition(position) || movementsensor.IsPositionNaN(position) {
+		return position, alt, err
+	}
+	converted, convErr := ConvertFromWGS84(g.datum, position)
+	if convErr != nil {
+		return nil, 0, convErr
+	}
+	return converted, alt, err
+}
+
+// wgs84Position returns the position as the device reports it.
+func (g *CachedData) wgs84Position() (*geo.Point, float64, error) {
 	lastPosition := g.lastPosition.GetLastPosition()
 	currentPosition := g.nmeaData.Location
 
@@ -123,6 +171,9 @@ func (g *CachedData) Accuracy(
 	defer g.mu.RUnlock()
 
 	compassDegreeError := g.calculateCompassDegreeError(g.lastPosition.GetLastPosition(), g.nmeaData.Location)
+	if g.ubxData.HeadingValid {
+		compassDegreeError = g.ubxData.HeadingAccuracyDegs
+	}
 
 	acc := movementsensor.Accuracy{
 		AccuracyMap: map[string]float32{
@@ -134,6 +185,19 @@ func (g *CachedData) Accuracy(
 		NmeaFix:            int32(g.nmeaData.FixQuality),
 		CompassDegreeError: float32(compassDegreeError),
 	}
+	if g.ubxData.Received {
+		acc.AccuracyMap[AccuracyHorizontalMeters] = float32(g.ubxData.HorizontalAccuracyM)
+		acc.AccuracyMap[AccuracyVerticalMeters] = float32(g.ubxData.VerticalAccuracyM)
+		if g.ubxData.CovarianceValid {
+			acc.AccuracyMap[AccuracyCovarianceNN] = float32(g.ubxData.CovarianceNN)
+			acc.AccuracyMap[AccuracyCovarianceNE] = float32(g.ubxData.CovarianceNE)
+			acc.AccuracyMap[AccuracyCovarianceEE] = float32(g.ubxData.CovarianceEE)
+			acc.AccuracyMap[AccuracyCovarianceDD] = float32(g.ubxData.CovarianceDD)
+		}
+		if g.ubxData.HeadingValid {
+			acc.AccuracyMap[AccuracyHeadingDegs] = float32(g.ubxData.HeadingAccuracyDegs)
+		}
+	}
 	return &acc, g.err.Get()
 }
 
@@ -213,6 +277,95 @@ func (g *CachedData) ReadSatsInView(ctx context.Context) (int, error) {
 	return g.nmeaData.SatsInView, nil
 }
 
+// UBXReadings returns the readings which only a receiver sending UBX reports, the fix type and RTK
+// status, and the survey-in status of a base station. It is empty if the receiver has sent no UBX.
+func (g *CachedData) UBXReadings() map[string]interface{} {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	readings := map[string]interface{}{}
+	if !g.ubxData.Received {
+		return readings
+	}
+	readings["fix_type"] = g.ubxData.FixType
+	readings["rtk_status"] = g.ubxData.RTKStatus
+	if g.ubxData.SurveyInReceived {
+		readings["survey_in"] = surveyInStatusMap(g.ubxData.SurveyIn)
+	}
+	return readings
+}
+
+// The DoCommand keys which configure a u-blox receiver as a base station.
+const (
+	// StartSurveyInCommand has the receiver survey in its position, with the arguments
+	// {"min_duration_sec": 60, "accuracy_limit_m": 2}.
+	StartSurveyInCommand = "start_survey_in"
+	// StopSurveyInCommand has the receiver stop surveying in its position.
+	StopSurveyInCommand = "stop_survey_in"
+	// SurveyInStatusCommand returns the progress of the survey-in.
+	SurveyInStatusCommand = "survey_in_status"
+
+	defaultSurveyInMinDurationSec = 60
+	defaultSurveyInAccuracyLimitM = 2.0
+)
+
+// DoCommand configures survey-in on a u-blox receiver, which can then be a base station sending RTK
+// corrections once it knows its own position.
+func (g *CachedData) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if _, ok := cmd[SurveyInStatusCommand]; ok {
+		g.mu.RLock()
+		defer g.mu.RUnlock()
+		if !g.ubxData.SurveyInReceived {
+			return nil, errors.New("the receiver has not sent a UBX NAV-SVIN message, so the survey-in status is unknown")
+		}
+		return map[string]interface{}{SurveyInStatusCommand: surveyInStatusMap(g.ubxData.SurveyIn)}, nil
+	}
+
+	var frame []byte
+	switch {
+	case cmd[StartSurveyInCommand] != nil:
+		args, ok := cmd[StartSurveyInCommand].(map[string]interface{})
+		if !ok {
+			args = map[string]interface{}{}
+		}
+		minDurationSec := float64(defaultSurveyInMinDurationSec)
+		if v, ok := args["min_duration_sec"].(float64); ok {
+			minDurationSec = v
+		}
+		accuracyLimitM := defaultSurveyInAccuracyLimitM
+		if v, ok := args["accuracy_limit_m"].(float64); ok {
+			accuracyLimitM = v
+		}
+		if minDurationSec <= 0 || accuracyLimitM <= 0 {
+			return nil, errors.New("min_duration_sec and accuracy_limit_m must be positive")
+		}
+		frame = SurveyInFrame(time.Duration(minDurationSec*float64(time.Second)), accuracyLimitM)
+	case cmd[StopSurveyInCommand] != nil:
+		frame = SurveyInFrame(0, 0)
+	default:
+		return nil, fmt.Errorf("unknown command, expected one of %q, %q or %q",
+			StartSurveyInCommand, StopSurveyInCommand, SurveyInStatusCommand)
+	}
+
+	writer, ok := g.dev.(io.Writer)
+	if !ok {
+		return nil, errors.New("the gps cannot be written to, so cannot be configured")
+	}
+	if _, err := writer.Write(frame); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{}, nil
+}
+
+func surveyInStatusMap(status SurveyInStatus) map[string]interface{} {
+	return map[string]interface{}{
+		"active":          status.Active,
+		"valid":           status.Valid,
+		"duration_sec":    status.DurationSec,
+		"observations":    status.Observations,
+		"mean_accuracy_m": status.MeanAccuracyM,
+	}
+}
+
 // Properties returns what movement sensor capabilities we have.
 func (g *CachedData) Properties(
 	ctx context.Context, extra map[string]interface{},
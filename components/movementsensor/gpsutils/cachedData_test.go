@@ -52,6 +52,14 @@ func TestReadingsSerial(t *testing.T) {
 	test.That(t, loc1, test.ShouldEqual, loc)
 	test.That(t, alt1, test.ShouldEqual, alt)
 
+	g.SetDatum("ED50")
+	loc2, alt2, err := g.Position(ctx, make(map[string]interface{}))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, loc2.Lat(), test.ShouldNotEqual, loc.Lat())
+	test.That(t, loc2.GreatCircleDistance(loc), test.ShouldBeLessThan, 0.5)
+	test.That(t, alt2, test.ShouldEqual, alt)
+	g.SetDatum("")
+
 	speed1, err := g.LinearVelocity(ctx, make(map[string]interface{}))
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, speed1.Y, test.ShouldEqual, speed)
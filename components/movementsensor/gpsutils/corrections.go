@@ -0,0 +1,266 @@
+package gpsutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-gnss/rtcm/rtcm3"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/utils"
+)
+
+const minBackoff = time.Second
+
+var (
+	errCorrectionTimeout = errors.New("no corrections received before the timeout")
+	errNoGGA             = errors.New("no position to send to the virtual reference station yet")
+)
+
+// CorrectionWriter writes an RTCM frame of corrections to the receiver.
+type CorrectionWriter func(ctx context.Context, frame []byte) error
+
+// CorrectionSource streams RTCM corrections from an NTRIP caster to a receiver. It keeps reconnecting, with
+// backoff, when the stream drops or stalls, so that the RTK fix survives flaky links. The position is sent to
+// virtual reference station mountpoints every GGAInterval.
+type CorrectionSource struct {
+	info   *NtripInfo
+	write  CorrectionWriter
+	gga    func() string
+	logger logging.Logger
+
+	mu             sync.Mutex
+	connected      bool
+	virtualBase    bool
+	lastCorrection time.Time
+	reconnects     int
+
+	workers utils.StoppableWorkers
+}
+
+// NewCorrectionSource starts streaming corrections of the mountpoint to write. gga returns the latest GGA
+// sentence of the receiver, such as CachedData.LastGGA, for virtual reference stations.
+func NewCorrectionSource(
+	info *NtripInfo, write CorrectionWriter, gga func() string, logger logging.Logger,
+) *CorrectionSource {
+	s := &CorrectionSource{info: info, write: write, gga: gga, logger: logger}
+	s.workers = utils.NewStoppableWorkers(s.run)
+	return s
+}
+
+// run streams corrections until it is stopped, reconnecting whenever a session ends.
+func (s *CorrectionSource) run(ctx context.Context) {
+	b := backoff{max: s.info.MaxBackoff}
+	for {
+		received, err := s.session(ctx)
+		s.mu.Lock()
+		s.connected = false
+		s.mu.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+		if received {
+			b.reset()
+		}
+		wait := b.next()
+		s.logger.CWarnf(ctx, "lost NTRIP corrections from %s, reconnecting in %v: %v", s.info.URL, wait, err)
+		if !goutils.SelectContextOrWait(ctx, wait) {
+			return
+		}
+		s.mu.Lock()
+		s.reconnects++
+		s.mu.Unlock()
+	}
+}
+
+// session connects to the mountpoint and writes its corrections until the stream fails, returning whether any
+// corrections were received.
+func (s *CorrectionSource) session(ctx context.Context) (bool, error) {
+	stream, virtualBase, err := s.open(ctx)
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	s.connected = true
+	s.virtualBase = virtualBase
+	s.mu.Unlock()
+	s.logger.CInfof(ctx, "streaming NTRIP corrections from %s/%s", s.info.URL, s.info.MountPoint)
+
+	// The stream is closed when the session ends or stalls, which unblocks reading it.
+	var lastFrame atomic.Int64
+	lastFrame.Store(time.Now().UnixNano())
+	var timedOut atomic.Bool
+	var closeOnce sync.Once
+	closeStream := func() {
+		closeOnce.Do(func() { goutils.UncheckedError(stream.Close()) })
+	}
+	defer closeStream()
+
+	sessionCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+	wg.Add(1)
+	goutils.PanicCapturingGo(func() {
+		defer wg.Done()
+		defer closeStream()
+		for goutils.SelectContextOrWait(sessionCtx, s.info.Timeout/4) {
+			if time.Since(time.Unix(0, lastFrame.Load())) > s.info.Timeout {
+				timedOut.Store(true)
+				return
+			}
+		}
+	})
+	if w, ok := stream.(io.Writer); ok && virtualBase {
+		wg.Add(1)
+		goutils.PanicCapturingGo(func() {
+			defer wg.Done()
+			for goutils.SelectContextOrWait(sessionCtx, s.info.GGAInterval) {
+				if err := s.sendGGA(w); err != nil {
+					s.logger.CDebugf(sessionCtx, "failed to send position to the virtual reference station: %v", err)
+				}
+			}
+		})
+	}
+
+	scanner := rtcm3.NewScanner(stream)
+	received := false
+	for {
+		frame, err := scanner.NextFrame()
+		if err != nil {
+			if timedOut.Load() {
+				return received, errCorrectionTimeout
+			}
+			return received, err
+		}
+		if err := s.write(ctx, frame.Serialize()); err != nil {
+			return received, fmt.Errorf("failed to write corrections to the receiver: %w", err)
+		}
+		received = true
+		now := time.Now()
+		lastFrame.Store(now.UnixNano())
+		s.mu.Lock()
+		s.lastCorrection = now
+		s.mu.Unlock()
+	}
+}
+
+// open connects to the caster and opens the stream of the mountpoint, returning whether it is a virtual reference
+// station.
+func (s *CorrectionSource) open(ctx context.Context) (io.ReadCloser, bool, error) {
+	if err := s.info.Connect(ctx, s.logger); err != nil {
+		return nil, false, err
+	}
+	srcTable, err := s.info.ParseSourcetable(s.logger)
+	if err != nil {
+		return nil, false, err
+	}
+	virtualBase, err := HasVRSStream(srcTable, s.info.MountPoint)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !virtualBase {
+		stream, err := s.info.Client.GetStream(s.info.MountPoint)
+		if err != nil {
+			if stream != nil {
+				goutils.UncheckedError(stream.Close())
+			}
+			return nil, false, err
+		}
+		return stream, false, nil
+	}
+
+	gga := s.gga()
+	if gga == "" {
+		return nil, true, errNoGGA
+	}
+	stream, err := ConnectToVirtualBase(ctx, s.info, gga, s.logger)
+	if err != nil {
+		return nil, true, err
+	}
+	return stream, true, nil
+}
+
+// sendGGA sends the latest position to the virtual reference station, so that the corrections follow the
+// receiver as it moves.
+func (s *CorrectionSource) sendGGA(w io.Writer) error {
+	gga := s.gga()
+	if gga == "" {
+		return errNoGGA
+	}
+	_, err := w.Write([]byte(gga + "\r\n"))
+	return err
+}
+
+// CorrectionStatus is the state of the stream of corrections.
+type CorrectionStatus struct {
+	Connected   bool
+	VirtualBase bool
+	// CorrectionAge is how long ago the last correction was received, or negative if none has been.
+	CorrectionAge time.Duration
+	Reconnects    int
+}
+
+// Status returns the state of the stream of corrections.
+func (s *CorrectionSource) Status() CorrectionStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := CorrectionStatus{
+		Connected:     s.connected,
+		VirtualBase:   s.virtualBase,
+		CorrectionAge: -1,
+		Reconnects:    s.reconnects,
+	}
+	if !s.lastCorrection.IsZero() {
+		status.CorrectionAge = time.Since(s.lastCorrection)
+	}
+	return status
+}
+
+// Readings returns the state of the stream of corrections, to be added to the readings of a movement sensor.
+func (s *CorrectionSource) Readings() map[string]interface{} {
+	status := s.Status()
+	readings := map[string]interface{}{
+		"ntrip_connected":    status.Connected,
+		"ntrip_virtual_base": status.VirtualBase,
+		"ntrip_reconnects":   status.Reconnects,
+	}
+	if status.CorrectionAge >= 0 {
+		readings["correction_age_sec"] = status.CorrectionAge.Seconds()
+	}
+	return readings
+}
+
+// Close stops streaming corrections.
+func (s *CorrectionSource) Close() {
+	s.workers.Stop()
+}
+
+// backoff is the exponentially growing wait between reconnections, from a second up to max. Each wait is
+// jittered so that many receivers losing the same caster do not reconnect at once.
+type backoff struct {
+	max      time.Duration
+	attempts int
+}
+
+func (b *backoff) next() time.Duration {
+	wait := b.max
+	if b.attempts < 32 {
+		wait = min(b.max, minBackoff<<b.attempts)
+	}
+	b.attempts++
+	//nolint:gosec
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+func (b *backoff) reset() {
+	b.attempts = 0
+}
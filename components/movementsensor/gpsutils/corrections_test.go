@@ -0,0 +1,231 @@
+package gpsutils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-gnss/rtcm/rtcm3"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/logging"
+)
+
+const testGGA = "$GNGGA,203755.00,4046.43133,N,07358.90383,W,4,18,0.75,12.3,M,-34.2,M,1.0,0000*67"
+
+// fakeCaster is an NTRIP caster with a mountpoint MP, which sends a frame and then stalls, and a virtual reference
+// station VRS, which sends a frame for each GGA sentence it is sent.
+type fakeCaster struct {
+	mu       sync.Mutex
+	streams  int
+	ggas     []string
+	username string
+}
+
+func (c *fakeCaster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	frame := rtcm3.EncapsulateByteArray([]byte{0x3e, 0xd0, 0x01, 0x02}).Serialize()
+	switch r.URL.Path {
+	case "/MP":
+		c.mu.Lock()
+		c.streams++
+		c.username, _, _ = r.BasicAuth()
+		c.mu.Unlock()
+		w.Header().Set("Content-Type", "gnss/data")
+		_, _ = w.Write(frame)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	case "/VRS":
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		c.mu.Lock()
+		c.streams++
+		c.ggas = append(c.ggas, r.Header.Get("Ntrip-GGA"))
+		c.mu.Unlock()
+		_, _ = rw.WriteString("ICY 200 OK\r\n")
+		for {
+			_ = rw.Flush()
+			line, err := rw.ReadString('\n')
+			if err != nil {
+				return
+			}
+			c.mu.Lock()
+			c.ggas = append(c.ggas, strings.TrimSpace(line))
+			c.mu.Unlock()
+			_, _ = rw.Write(frame)
+		}
+	default:
+		w.Header().Set("Content-Type", "gnss/sourcetable")
+		_, _ = w.Write([]byte(
+			"STR;MP;City;RTCM 3.2;1005(10);2;GPS+GLO;NET;USA;40.00;-74.00;0;0;sNTRIP;none;B;N;9600;misc\r\n" +
+				"STR;VRS;City;RTCM 3.2;1005(10);2;GPS+GLO;NET;USA;40.00;-74.00;1;1;sNTRIP;none;B;N;9600;misc\r\n" +
+				"ENDSOURCETABLE\r\n"))
+	}
+}
+
+func (c *fakeCaster) stats() (int, []string, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.streams, append([]string(nil), c.ggas...), c.username
+}
+
+type frameRecorder struct {
+	mu     sync.Mutex
+	frames [][]byte
+}
+
+func (r *frameRecorder) write(ctx context.Context, frame []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.frames = append(r.frames, frame)
+	return nil
+}
+
+func (r *frameRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.frames)
+}
+
+func TestCorrectionSource(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	caster := &fakeCaster{}
+	server := httptest.NewServer(caster)
+	defer server.Close()
+
+	credentials := filepath.Join(t.TempDir(), "credentials")
+	test.That(t, os.WriteFile(credentials, []byte("first:pwd\n"), 0o600), test.ShouldBeNil)
+
+	t.Run("reconnects when the stream stalls", func(t *testing.T) {
+		info, err := NewNtripInfo(&NtripConfig{
+			NtripURL:             server.URL,
+			NtripMountpoint:      "MP",
+			NtripCredentialsFile: credentials,
+			NtripTimeoutSec:      0.2,
+			NtripMaxBackoffSec:   0.1,
+		}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		var rec frameRecorder
+		source := NewCorrectionSource(info, rec.write, func() string { return "" }, logger)
+		defer source.Close()
+
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			test.That(tb, rec.count(), test.ShouldBeGreaterThanOrEqualTo, 1)
+		})
+		status := source.Status()
+		test.That(t, status.Connected, test.ShouldBeTrue)
+		test.That(t, status.VirtualBase, test.ShouldBeFalse)
+		test.That(t, status.CorrectionAge, test.ShouldBeBetween, 0, time.Second)
+		rec.mu.Lock()
+		test.That(t, rec.frames[0][0], test.ShouldEqual, rtcm3.FramePreamble)
+		rec.mu.Unlock()
+		_, _, username := caster.stats()
+		test.That(t, username, test.ShouldEqual, "first")
+
+		// the rotated credentials are used from the next connection on
+		test.That(t, os.WriteFile(credentials, []byte("second:pwd\n"), 0o600), test.ShouldBeNil)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			test.That(tb, rec.count(), test.ShouldBeGreaterThanOrEqualTo, 2)
+		})
+		test.That(t, source.Status().Reconnects, test.ShouldBeGreaterThanOrEqualTo, 1)
+		_, _, username = caster.stats()
+		test.That(t, username, test.ShouldEqual, "second")
+		test.That(t, source.Readings(), test.ShouldContainKey, "correction_age_sec")
+	})
+
+	t.Run("sends the position to a virtual reference station", func(t *testing.T) {
+		info, err := NewNtripInfo(&NtripConfig{
+			NtripURL:            server.URL,
+			NtripMountpoint:     "VRS",
+			NtripGGAIntervalSec: 0.05,
+		}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		var rec frameRecorder
+		var mu sync.Mutex
+		gga := ""
+		source := NewCorrectionSource(info, rec.write, func() string {
+			mu.Lock()
+			defer mu.Unlock()
+			return gga
+		}, logger)
+		defer source.Close()
+
+		// there is no position to send until the receiver has a fix
+		time.Sleep(100 * time.Millisecond)
+		test.That(t, source.Status().Connected, test.ShouldBeFalse)
+		mu.Lock()
+		gga = testGGA
+		mu.Unlock()
+
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			test.That(tb, rec.count(), test.ShouldBeGreaterThanOrEqualTo, 3)
+		})
+		test.That(t, source.Status().VirtualBase, test.ShouldBeTrue)
+		_, ggas, _ := caster.stats()
+		test.That(t, len(ggas), test.ShouldBeGreaterThanOrEqualTo, 4)
+		for _, sentence := range ggas {
+			test.That(t, sentence, test.ShouldEqual, testGGA)
+		}
+	})
+}
+
+func TestCredentials(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	info, err := NewNtripInfo(&NtripConfig{NtripURL: "http://fakeurl", NtripUser: "user", NtripPass: "pwd"}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	username, password, err := info.Credentials()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, username, test.ShouldEqual, "user")
+	test.That(t, password, test.ShouldEqual, "pwd")
+	test.That(t, info.GGAInterval, test.ShouldEqual, defaultGGAInterval)
+	test.That(t, info.Timeout, test.ShouldEqual, defaultTimeout)
+	test.That(t, info.MaxBackoff, test.ShouldEqual, defaultMaxBackoff)
+
+	credentials := filepath.Join(t.TempDir(), "credentials")
+	info, err = NewNtripInfo(&NtripConfig{NtripURL: "http://fakeurl", NtripCredentialsFile: credentials}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	_, _, err = info.Credentials()
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, os.WriteFile(credentials, []byte("no password"), 0o600), test.ShouldBeNil)
+	_, _, err = info.Credentials()
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, os.WriteFile(credentials, []byte("user:pass:word\n"), 0o600), test.ShouldBeNil)
+	username, password, err = info.Credentials()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, username, test.ShouldEqual, "user")
+	test.That(t, password, test.ShouldEqual, "pass:word")
+}
+
+func TestBackoff(t *testing.T) {
+	b := backoff{max: 10 * time.Second}
+	for _, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second} {
+		wait := b.next()
+		test.That(t, wait, test.ShouldBeBetweenOrEqual, expected/2, expected)
+	}
+	test.That(t, b.next(), test.ShouldBeBetweenOrEqual, 5*time.Second, 10*time.Second)
+	b.reset()
+	test.That(t, b.next(), test.ShouldBeBetweenOrEqual, 500*time.Millisecond, time.Second)
+}
+
+func TestLastGGA(t *testing.T) {
+	g := NewCachedData(&mockDataReader{}, logging.NewTestLogger(t))
+	defer g.Close(context.Background())
+	test.That(t, g.LastGGA(), test.ShouldEqual, "")
+	noFix := "$GNGGA,203755.00,,,,,0,00,99.99,,,,,,*7E\r\n"
+	_ = g.ParseAndUpdate(noFix)
+	test.That(t, g.LastGGA(), test.ShouldEqual, "")
+	test.That(t, g.ParseAndUpdate(testGGA+"\r\n"), test.ShouldBeNil)
+	test.That(t, g.LastGGA(), test.ShouldEqual, testGGA)
+}
@@ -0,0 +1,120 @@
+package gpsutils
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+)
+
+// arcSecond is an arc second in radians.
+const arcSecond = math.Pi / 180 / 3600
+
+// ellipsoid is a reference ellipsoid by its semi-major axis in meters and flattening.
+type ellipsoid struct {
+	a, f float64
+}
+
+var (
+	wgs84Ellipsoid             = ellipsoid{a: 6378137, f: 1 / 298.257223563}
+	airy1830Ellipsoid          = ellipsoid{a: 6377563.396, f: 1 / 299.3249646}
+	international1924Ellipsoid = ellipsoid{a: 6378388, f: 1 / 297.0}
+)
+
+// helmert is a seven parameter transformation of earth centered coordinates from WGS84, with translations in meters,
+// rotations in arc seconds and scale in parts per million.
+type helmert struct {
+	tx, ty, tz float64
+	rx, ry, rz float64
+	s          float64
+}
+
+// apply transforms earth centered coordinates by the small angle approximation of the rotations.
+func (h helmert) apply(x, y, z float64) (float64, float64, float64) {
+	s := 1 + h.s*1e-6
+	rx, ry, rz := h.rx*arcSecond, h.ry*arcSecond, h.rz*arcSecond
+	return h.tx + s*x - rz*y + ry*z,
+		h.ty + rz*x + s*y - rx*z,
+		h.tz - ry*x + rx*y + s*z
+}
+
+// datum is a geodetic datum a GPS position can be reported in.
+type datum struct {
+	ellipsoid ellipsoid
+	fromWGS84 helmert
+}
+
+// datums are the datums positions can be converted to, by name. GPS receivers report WGS84, which NAD83 agrees with
+// to within the accuracy of most receivers.
+var datums = map[string]*datum{
+	"WGS84": nil,
+	"NAD83": nil,
+	"OSGB36": {
+		ellipsoid: airy1830Ellipsoid,
+		fromWGS84: helmert{tx: -446.448, ty: 125.157, tz: -542.060, rx: -0.1502, ry: -0.2470, rz: -0.8421, s: 20.4894},
+	},
+	"ED50": {
+		ellipsoid: international1924Ellipsoid,
+		fromWGS84: helmert{tx: 87, ty: 98, tz: 121},
+	},
+}
+
+// ValidateDatum returns an error if positions cannot be converted to the named datum. An empty name is WGS84.
+func ValidateDatum(path, name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, ok := datums[strings.ToUpper(name)]; !ok {
+		names := make([]string, 0, len(datums))
+		for n := range datums {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return errors.Errorf("%s: unknown datum %q, must be one of %v", path, name, names)
+	}
+	return nil
+}
+
+// ConvertFromWGS84 converts a WGS84 position to the named datum. The altitude a GPS reports is above mean sea level,
+// so it is left as is.
+func ConvertFromWGS84(name string, point *geo.Point) (*geo.Point, error) {
+	if point == nil || name == "" {
+		return point, nil
+	}
+	d, ok := datums[strings.ToUpper(name)]
+	if !ok {
+		return nil, errors.Errorf("unknown datum %q", name)
+	}
+	if d == nil {
+		return point, nil
+	}
+	x, y, z := wgs84Ellipsoid.toECEF(point.Lat(), point.Lng())
+	x, y, z = d.fromWGS84.apply(x, y, z)
+	lat, lng := d.ellipsoid.fromECEF(x, y, z)
+	return geo.NewPoint(lat, lng), nil
+}
+
+// toECEF returns the earth centered coordinates in meters of a latitude and longitude in degrees on the ellipsoid.
+func (e ellipsoid) toECEF(lat, lng float64) (float64, float64, float64) {
+	e2 := e.f * (2 - e.f)
+	sinPhi, cosPhi := math.Sincos(lat * math.Pi / 180)
+	sinLambda, cosLambda := math.Sincos(lng * math.Pi / 180)
+	n := e.a / math.Sqrt(1-e2*sinPhi*sinPhi)
+	return n * cosPhi * cosLambda, n * cosPhi * sinLambda, n * (1 - e2) * sinPhi
+}
+
+// fromECEF returns the latitude and longitude in degrees on the ellipsoid of earth centered coordinates in meters.
+func (e ellipsoid) fromECEF(x, y, z float64) (float64, float64) {
+	e2 := e.f * (2 - e.f)
+	p := math.Hypot(x, y)
+	phi := math.Atan2(z, p*(1-e2))
+	// a few iterations converge to well under a millimeter near the surface
+	for i := 0; i < 5; i++ {
+		sinPhi := math.Sin(phi)
+		n := e.a / math.Sqrt(1-e2*sinPhi*sinPhi)
+		phi = math.Atan2(z+e2*n*sinPhi, p)
+	}
+	return phi * 180 / math.Pi, math.Atan2(y, x) * 180 / math.Pi
+}
@@ -0,0 +1,39 @@
+package gpsutils
+
+import (
+	"testing"
+
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+)
+
+func TestConvertFromWGS84(t *testing.T) {
+	test.That(t, ValidateDatum("path", ""), test.ShouldBeNil)
+	test.That(t, ValidateDatum("path", "osgb36"), test.ShouldBeNil)
+	test.That(t, ValidateDatum("path", "NAD27"), test.ShouldNotBeNil)
+
+	point := geo.NewPoint(51.4778, -0.0015)
+	for _, name := range []string{"", "WGS84", "nad83"} {
+		converted, err := ConvertFromWGS84(name, point)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, converted, test.ShouldResemble, point)
+	}
+
+	// the prime meridian at Greenwich is drawn on the OSGB36 datum, about 100m east of the WGS84 meridian
+	converted, err := ConvertFromWGS84("OSGB36", point)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, converted.Lng(), test.ShouldAlmostEqual, 0, 3e-4)
+	test.That(t, converted.Lat(), test.ShouldAlmostEqual, 51.4773, 3e-4)
+
+	// ED50 positions in Europe are north east of WGS84 ones by around a hundred meters
+	converted, err = ConvertFromWGS84("ED50", geo.NewPoint(48.8584, 2.2945))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, converted.Lat(), test.ShouldBeBetween, 48.8584, 48.8604)
+	test.That(t, converted.Lng(), test.ShouldBeBetween, 2.2945, 2.2975)
+
+	converted, err = ConvertFromWGS84("OSGB36", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, converted, test.ShouldBeNil)
+	_, err = ConvertFromWGS84("NAD27", point)
+	test.That(t, err, test.ShouldNotBeNil)
+}
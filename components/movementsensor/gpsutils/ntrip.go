@@ -7,8 +7,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
+	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/de-bkg/gognss/pkg/ntrip"
 
@@ -40,6 +44,11 @@ const (
 	misc          = 18
 	floatbitsize  = 32
 	streamSize    = 200
+
+	defaultGGAInterval = 10 * time.Second
+	defaultTimeout     = 30 * time.Second
+	defaultMaxBackoff  = time.Minute
+	connectTimeout     = 15 * time.Second
 )
 
 // NtripInfo contains the information necessary to connect to a mountpoint.
@@ -47,10 +56,17 @@ type NtripInfo struct {
 	URL                string
 	username           string
 	password           string
+	credentialsFile    string
 	MountPoint         string
 	Client             *ntrip.Client
 	Stream             io.ReadCloser
 	MaxConnectAttempts int
+	// GGAInterval is how often the position is sent to a virtual reference station, Timeout is how long the
+	// stream may go without corrections before it is reconnected, and MaxBackoff is the longest wait between
+	// reconnections.
+	GGAInterval time.Duration
+	Timeout     time.Duration
+	MaxBackoff  time.Duration
 }
 
 // NtripConfig is used for converting attributes for a correction source.
@@ -60,6 +76,12 @@ type NtripConfig struct {
 	NtripMountpoint      string `json:"ntrip_mountpoint,omitempty"`
 	NtripUser            string `json:"ntrip_username,omitempty"`
 	NtripPass            string `json:"ntrip_password,omitempty"`
+	// NtripCredentialsFile holds "username:password", and is read again on every connection so that the
+	// credentials can be rotated without reconfiguring. It takes the place of ntrip_username and ntrip_password.
+	NtripCredentialsFile string  `json:"ntrip_credentials_file,omitempty"`
+	NtripGGAIntervalSec  float64 `json:"ntrip_gga_interval_sec,omitempty"`
+	NtripTimeoutSec      float64 `json:"ntrip_timeout_sec,omitempty"`
+	NtripMaxBackoffSec   float64 `json:"ntrip_max_backoff_sec,omitempty"`
 }
 
 // Sourcetable struct contains the stream.
@@ -100,12 +122,15 @@ func NewNtripInfo(cfg *NtripConfig, logger logging.Logger) (*NtripInfo, error) {
 		return nil, fmt.Errorf("NTRIP expected non-empty string for %q", cfg.NtripURL)
 	}
 	n.username = cfg.NtripUser
-	if n.username == "" {
-		logger.Info("ntrip_username set to empty")
-	}
 	n.password = cfg.NtripPass
-	if n.password == "" {
-		logger.Info("ntrip_password set to empty")
+	n.credentialsFile = cfg.NtripCredentialsFile
+	if n.credentialsFile == "" {
+		if n.username == "" {
+			logger.Info("ntrip_username set to empty")
+		}
+		if n.password == "" {
+			logger.Info("ntrip_password set to empty")
+		}
 	}
 	n.MountPoint = cfg.NtripMountpoint
 	if n.MountPoint == "" {
@@ -116,11 +141,39 @@ func NewNtripInfo(cfg *NtripConfig, logger logging.Logger) (*NtripInfo, error) {
 		logger.Info("ntrip_connect_attempts using default 10")
 		n.MaxConnectAttempts = 10
 	}
+	n.GGAInterval = secondsOrDefault(cfg.NtripGGAIntervalSec, defaultGGAInterval)
+	n.Timeout = secondsOrDefault(cfg.NtripTimeoutSec, defaultTimeout)
+	n.MaxBackoff = secondsOrDefault(cfg.NtripMaxBackoffSec, defaultMaxBackoff)
 
 	logger.Debug("Returning n")
 	return n, nil
 }
 
+func secondsOrDefault(sec float64, def time.Duration) time.Duration {
+	if sec <= 0 {
+		return def
+	}
+	return time.Duration(sec * float64(time.Second))
+}
+
+// Credentials returns the username and password to connect with, reading them from the credentials file if
+// there is one.
+func (n *NtripInfo) Credentials() (string, string, error) {
+	if n.credentialsFile == "" {
+		return n.username, n.password, nil
+	}
+	//nolint:gosec
+	data, err := os.ReadFile(n.credentialsFile)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read NTRIP credentials: %w", err)
+	}
+	username, password, ok := strings.Cut(strings.TrimSpace(string(data)), ":")
+	if !ok {
+		return "", "", fmt.Errorf("NTRIP credentials file %s must hold username:password", n.credentialsFile)
+	}
+	return username, password, nil
+}
+
 // ParseSourcetable gets the sourcetable and parses it.
 func (n *NtripInfo) ParseSourcetable(logger logging.Logger) (*Sourcetable, error) {
 	reader, err := n.Client.GetSourcetable()
@@ -224,11 +277,17 @@ func parseStream(line string) (Stream, error) {
 }
 
 // Connect attempts to initialize a new ntrip client. If we're unable to connect after multiple
-// attempts, we return the last error.
+// attempts, we return the last error. The client reads the credentials again, and may connect over
+// TLS if the URL is https.
 func (n *NtripInfo) Connect(ctx context.Context, logger logging.Logger) error {
 	var c *ntrip.Client
 	var err error
 
+	username, password, err := n.Credentials()
+	if err != nil {
+		return err
+	}
+
 	logger.Debug("Connecting to NTRIP caster")
 	for attempts := 0; attempts < n.MaxConnectAttempts; attempts++ {
 		select {
@@ -237,8 +296,17 @@ func (n *NtripInfo) Connect(ctx context.Context, logger logging.Logger) error {
 		default:
 		}
 
-		c, err = ntrip.NewClient(n.URL, ntrip.Options{Username: n.username, Password: n.password})
+		c, err = ntrip.NewClient(n.URL, ntrip.Options{Username: username, Password: password})
 		if err == nil { // Success!
+			// The timeout of the client includes reading the whole body, which would cut off every stream.
+			// Only connecting is timed out here, and streams which stall are found by their correction age.
+			c.Client.Timeout = 0
+			c.Client.Transport = &http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				DialContext:           (&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}).DialContext,
+				TLSHandshakeTimeout:   connectTimeout,
+				ResponseHeaderTimeout: connectTimeout,
+			}
 			logger.Info("Connected to NTRIP caster")
 			n.Client = c
 			return nil
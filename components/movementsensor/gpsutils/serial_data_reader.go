@@ -82,7 +82,7 @@ func (dr *SerialDataReader) start() {
 			default:
 			}
 
-			line, err := r.ReadString('\n')
+			line, err := readMessage(r)
 			if err != nil {
 				dr.logger.CErrorf(dr.cancelCtx, "can't read gps serial %s", err)
 				continue // The line has bogus data; don't put it in the channel.
@@ -92,12 +92,17 @@ func (dr *SerialDataReader) start() {
 	})
 }
 
-// Messages returns the channel of complete NMEA sentences we have read off of the device. It's part
-// of the DataReader interface.
+// Messages returns the channel of complete NMEA sentences and UBX frames we have read off of the
+// device. It's part of the DataReader interface.
 func (dr *SerialDataReader) Messages() chan string {
 	return dr.data
 }
 
+// Write sends data, such as a UBX configuration frame, to the device.
+func (dr *SerialDataReader) Write(p []byte) (int, error) {
+	return dr.dev.Write(p)
+}
+
 // Close is part of the DataReader interface. It shuts everything down, including our connection to
 // the serial port.
 func (dr *SerialDataReader) Close() error {
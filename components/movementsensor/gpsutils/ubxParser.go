@@ -0,0 +1,340 @@
+package gpsutils
+
+/*
+	UBX is the binary protocol of u-blox receivers, which they send alongside NMEA. It carries what NMEA
+	cannot, such as the RTK status, the position covariance and the heading between two antennas.
+
+	UBX protocol reference of the F9P:
+	https://content.u-blox.com/sites/default/files/u-blox-F9-HPG-1.32_InterfaceDescription_UBX-22008968.pdf
+*/
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+)
+
+const (
+	ubxSync1 = 0xB5
+	ubxSync2 = 0x62
+	// ubxHeaderLen is the length of the sync bytes, class, id and payload length which begin every frame, and
+	// ubxChecksumLen the length of the checksum which ends it.
+	ubxHeaderLen   = 6
+	ubxChecksumLen = 2
+	// ubxMaxPayloadLen is longer than the payload of any message we parse, so a corrupt length is not read as one.
+	ubxMaxPayloadLen = 1024
+
+	ubxClassNAV        = 0x01
+	ubxClassCFG        = 0x06
+	ubxIDNavDOP        = 0x04
+	ubxIDNavPVT        = 0x07
+	ubxIDNavCOV        = 0x36
+	ubxIDNavSVIN       = 0x3B
+	ubxIDNavRELPOSNED  = 0x3C
+	ubxIDCfgVALSET     = 0x8A
+	ubxCfgLayerRAM     = 0x01
+	ubxCfgTmodeMode    = 0x20030001
+	ubxCfgTmodeSvinDur = 0x40030010
+	ubxCfgTmodeSvinAcc = 0x40030011
+	ubxTmodeDisabled   = 0
+	ubxTmodeSurveyIn   = 1
+)
+
+// The RTK statuses of a UBX solution.
+const (
+	RTKStatusNone  = "none"
+	RTKStatusFloat = "float"
+	RTKStatusFixed = "fixed"
+)
+
+// The keys of the accuracy map which a receiver sending UBX fills in besides the DOPs.
+const (
+	AccuracyHorizontalMeters = "horizontal_accuracy_m"
+	AccuracyVerticalMeters   = "vertical_accuracy_m"
+	AccuracyCovarianceNN     = "position_covariance_nn"
+	AccuracyCovarianceNE     = "position_covariance_ne"
+	AccuracyCovarianceEE     = "position_covariance_ee"
+	AccuracyCovarianceDD     = "position_covariance_dd"
+	AccuracyHeadingDegs      = "heading_accuracy_degs"
+)
+
+// ubxFixTypes are the names of the fix types of NAV-PVT, by their value.
+var ubxFixTypes = []string{"no fix", "dead reckoning", "2d", "3d", "gnss and dead reckoning", "time only"}
+
+// SurveyInStatus is the progress of a base station surveying in its own position, from NAV-SVIN.
+type SurveyInStatus struct {
+	Active        bool
+	Valid         bool
+	DurationSec   int
+	Observations  int
+	MeanAccuracyM float64
+}
+
+// UbxParser keeps the state of a u-blox receiver which NMEA sentences do not carry. The parts of the solution
+// which they do carry, such as the location, update the NmeaParser instead, so either protocol can be used.
+type UbxParser struct {
+	// Received is whether any UBX message has been parsed.
+	Received bool
+
+	FixType   string
+	RTKStatus string
+	// HorizontalAccuracyM and VerticalAccuracyM are the estimated accuracy of the position, in meters.
+	HorizontalAccuracyM float64
+	VerticalAccuracyM   float64
+
+	// CovarianceValid is whether the north, east and down covariance of the position, in square meters, is known.
+	CovarianceValid bool
+	CovarianceNN    float64
+	CovarianceNE    float64
+	CovarianceEE    float64
+	CovarianceDD    float64
+
+	// HeadingValid is whether the heading between the two antennas of a moving base is known.
+	HeadingValid        bool
+	Heading             float64
+	HeadingAccuracyDegs float64
+
+	SurveyInReceived bool
+	SurveyIn         SurveyInStatus
+}
+
+// IsUBXFrame returns whether a message read off of a device is a UBX frame rather than an NMEA sentence.
+func IsUBXFrame(message string) bool {
+	return len(message) >= 2 && message[0] == ubxSync1 && message[1] == ubxSync2
+}
+
+// ubxChecksum returns the checksum of the class, id, length and payload of a frame.
+func ubxChecksum(body []byte) (byte, byte) {
+	var a, b byte
+	for _, c := range body {
+		a += c
+		b += a
+	}
+	return a, b
+}
+
+// NewUBXFrame returns the frame of a UBX message.
+func NewUBXFrame(class, id byte, payload []byte) []byte {
+	frame := make([]byte, 0, ubxHeaderLen+len(payload)+ubxChecksumLen)
+	frame = append(frame, ubxSync1, ubxSync2, class, id)
+	frame = binary.LittleEndian.AppendUint16(frame, uint16(len(payload)))
+	frame = append(frame, payload...)
+	a, b := ubxChecksum(frame[2:])
+	return append(frame, a, b)
+}
+
+// SurveyInFrame returns the frame which has a receiver survey in its position as a base station, until it has
+// done so for at least minDuration and to within accuracyLimitM meters. A zero minDuration stops any survey-in
+// instead. The configuration is kept until the receiver is power cycled.
+func SurveyInFrame(minDuration time.Duration, accuracyLimitM float64) []byte {
+	payload := []byte{0, ubxCfgLayerRAM, 0, 0}
+	payload = binary.LittleEndian.AppendUint32(payload, ubxCfgTmodeMode)
+	if minDuration <= 0 {
+		return NewUBXFrame(ubxClassCFG, ubxIDCfgVALSET, append(payload, ubxTmodeDisabled))
+	}
+	payload = append(payload, ubxTmodeSurveyIn)
+	payload = binary.LittleEndian.AppendUint32(payload, ubxCfgTmodeSvinDur)
+	payload = binary.LittleEndian.AppendUint32(payload, uint32(minDuration.Seconds()))
+	payload = binary.LittleEndian.AppendUint32(payload, ubxCfgTmodeSvinAcc)
+	// the accuracy limit is in tenths of a millimeter
+	payload = binary.LittleEndian.AppendUint32(payload, uint32(math.Round(accuracyLimitM*1e4)))
+	return NewUBXFrame(ubxClassCFG, ubxIDCfgVALSET, payload)
+}
+
+// readMessage reads the next NMEA sentence or UBX frame off of a device which may send both.
+func readMessage(r *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		if next, err := r.Peek(2); err == nil && next[0] == ubxSync1 && next[1] == ubxSync2 {
+			if len(line) > 0 {
+				// a sentence cut short by a frame, which will not parse
+				return string(line), nil
+			}
+			return readUBXFrame(r)
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		line = append(line, b)
+		if b == '\n' {
+			return string(line), nil
+		}
+	}
+}
+
+// readUBXFrame reads the UBX frame which the reader is at the start of.
+func readUBXFrame(r *bufio.Reader) (string, error) {
+	frame := make([]byte, ubxHeaderLen)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return "", err
+	}
+	payloadLen := int(binary.LittleEndian.Uint16(frame[4:]))
+	if payloadLen > ubxMaxPayloadLen {
+		// the sync bytes were part of something else, so the reader carries on past them
+		return "", errors.Errorf("UBX payload length %d is too long", payloadLen)
+	}
+	frame = append(frame, make([]byte, payloadLen+ubxChecksumLen)...)
+	if _, err := io.ReadFull(r, frame[ubxHeaderLen:]); err != nil {
+		return "", err
+	}
+	return string(frame), nil
+}
+
+// ParseAndUpdate parses a UBX frame and updates the parser, and the NMEA data with the parts of the solution which
+// NMEA sentences carry too. Frames of messages which are not parsed are ignored.
+func (u *UbxParser) ParseAndUpdate(frame []byte, nmeaData *NmeaParser) error {
+	if len(frame) < ubxHeaderLen+ubxChecksumLen || !IsUBXFrame(string(frame[:2])) {
+		return errors.New("not a UBX frame")
+	}
+	payloadLen := int(binary.LittleEndian.Uint16(frame[4:]))
+	if len(frame) != ubxHeaderLen+payloadLen+ubxChecksumLen {
+		return errors.Errorf("UBX frame is %d bytes but its payload is %d", len(frame), payloadLen)
+	}
+	a, b := ubxChecksum(frame[2 : ubxHeaderLen+payloadLen])
+	if a != frame[len(frame)-2] || b != frame[len(frame)-1] {
+		return errors.New("UBX frame checksum does not match")
+	}
+	class, id, payload := frame[2], frame[3], frame[ubxHeaderLen:ubxHeaderLen+payloadLen]
+	if class != ubxClassNAV {
+		return nil
+	}
+
+	var minLen int
+	switch id {
+	case ubxIDNavPVT:
+		minLen = 92
+	case ubxIDNavDOP:
+		minLen = 18
+	case ubxIDNavCOV, ubxIDNavRELPOSNED:
+		minLen = 64
+	case ubxIDNavSVIN:
+		minLen = 40
+	default:
+		return nil
+	}
+	if len(payload) < minLen {
+		return errors.Errorf("UBX NAV message 0x%02x is %d bytes, want %d", id, len(payload), minLen)
+	}
+	u.Received = true
+
+	switch id {
+	case ubxIDNavPVT:
+		return u.updatePVT(payload, nmeaData)
+	case ubxIDNavDOP:
+		nmeaData.VDOP = float64(binary.LittleEndian.Uint16(payload[10:])) / 100
+		nmeaData.HDOP = float64(binary.LittleEndian.Uint16(payload[12:])) / 100
+	case ubxIDNavCOV:
+		u.updateCOV(payload)
+	case ubxIDNavRELPOSNED:
+		u.updateRELPOSNED(payload, nmeaData)
+	case ubxIDNavSVIN:
+		u.SurveyInReceived = true
+		u.SurveyIn = SurveyInStatus{
+			DurationSec:   int(binary.LittleEndian.Uint32(payload[8:])),
+			MeanAccuracyM: float64(binary.LittleEndian.Uint32(payload[28:])) / 1e4,
+			Observations:  int(binary.LittleEndian.Uint32(payload[32:])),
+			Valid:         payload[36] == 1,
+			Active:        payload[37] == 1,
+		}
+	}
+	return nil
+}
+
+// updatePVT updates the solution from NAV-PVT, the position, velocity and time of the receiver.
+func (u *UbxParser) updatePVT(payload []byte, nmeaData *NmeaParser) error {
+	fixType := int(payload[20])
+	flags := payload[21]
+	gnssFixOK := flags&0x01 != 0
+	diffSoln := flags&0x02 != 0
+	carrSoln := flags >> 6
+
+	u.FixType = "unknown"
+	if fixType < len(ubxFixTypes) {
+		u.FixType = ubxFixTypes[fixType]
+	}
+	switch carrSoln {
+	case 1:
+		u.RTKStatus = RTKStatusFloat
+	case 2:
+		u.RTKStatus = RTKStatusFixed
+	default:
+		u.RTKStatus = RTKStatusNone
+	}
+
+	if !gnssFixOK || fixType == 0 || fixType == 5 {
+		nmeaData.valid = false
+		nmeaData.FixQuality = 0
+		return errInvalidFix("NAV-PVT", strconv.Itoa(fixType), "1 to 4")
+	}
+
+	// the fix quality is the one a GGA sentence would report
+	switch {
+	case fixType == 1:
+		nmeaData.FixQuality = 6
+	case u.RTKStatus == RTKStatusFixed:
+		nmeaData.FixQuality = 4
+	case u.RTKStatus == RTKStatusFloat:
+		nmeaData.FixQuality = 5
+	case diffSoln:
+		nmeaData.FixQuality = 2
+	default:
+		nmeaData.FixQuality = 1
+	}
+
+	nmeaData.valid = true
+	nmeaData.SatsInUse = int(payload[23])
+	lon := float64(int32(binary.LittleEndian.Uint32(payload[24:]))) * 1e-7
+	lat := float64(int32(binary.LittleEndian.Uint32(payload[28:]))) * 1e-7
+	nmeaData.Location = geo.NewPoint(lat, lon)
+	nmeaData.Alt = float64(int32(binary.LittleEndian.Uint32(payload[36:]))) / 1e3
+	u.HorizontalAccuracyM = float64(binary.LittleEndian.Uint32(payload[40:])) / 1e3
+	u.VerticalAccuracyM = float64(binary.LittleEndian.Uint32(payload[44:])) / 1e3
+
+	groundSpeed := float64(int32(binary.LittleEndian.Uint32(payload[60:]))) / 1e3
+	nmeaData.Speed = groundSpeed
+	// the heading between two antennas is better than the heading of motion, which is only known when moving
+	switch {
+	case u.HeadingValid:
+	case groundSpeed > 0:
+		nmeaData.CompassHeading = float64(int32(binary.LittleEndian.Uint32(payload[64:]))) * 1e-5
+	default:
+		nmeaData.CompassHeading = math.NaN()
+	}
+	return nil
+}
+
+// updateCOV updates the covariance of the position from NAV-COV.
+func (u *UbxParser) updateCOV(payload []byte) {
+	u.CovarianceValid = payload[5] == 1
+	if !u.CovarianceValid {
+		return
+	}
+	float := func(offset int) float64 {
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(payload[offset:])))
+	}
+	u.CovarianceNN = float(16)
+	u.CovarianceNE = float(20)
+	u.CovarianceEE = float(28)
+	u.CovarianceDD = float(36)
+}
+
+// updateRELPOSNED updates the heading from NAV-RELPOSNED, the position of the rover antenna relative to the
+// moving base antenna of a dual antenna receiver.
+func (u *UbxParser) updateRELPOSNED(payload []byte, nmeaData *NmeaParser) {
+	flags := binary.LittleEndian.Uint32(payload[60:])
+	// relPosHeadingValid
+	u.HeadingValid = flags&(1<<8) != 0
+	if !u.HeadingValid {
+		return
+	}
+	u.Heading = float64(int32(binary.LittleEndian.Uint32(payload[24:]))) * 1e-5
+	u.HeadingAccuracyDegs = float64(binary.LittleEndian.Uint32(payload[52:])) * 1e-5
+	nmeaData.CompassHeading = u.Heading
+}
@@ -0,0 +1,199 @@
+package gpsutils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+// navPVT returns a NAV-PVT frame of a solution 1.5 meters per second due east.
+func navPVT(fixType, flags byte) []byte {
+	payload := make([]byte, 92)
+	payload[20] = fixType
+	payload[21] = flags
+	payload[23] = 18
+	lon, lat := int32(-739817305), int32(407738555)
+	binary.LittleEndian.PutUint32(payload[24:], uint32(lon))
+	binary.LittleEndian.PutUint32(payload[28:], uint32(lat))
+	binary.LittleEndian.PutUint32(payload[36:], 12345)
+	binary.LittleEndian.PutUint32(payload[40:], 14)
+	binary.LittleEndian.PutUint32(payload[44:], 21)
+	binary.LittleEndian.PutUint32(payload[60:], 1500)
+	binary.LittleEndian.PutUint32(payload[64:], 9000000)
+	return NewUBXFrame(ubxClassNAV, ubxIDNavPVT, payload)
+}
+
+func TestParseUBX(t *testing.T) {
+	var nmeaData NmeaParser
+	var ubxData UbxParser
+
+	// gnssFixOK with an RTK fixed solution
+	err := ubxData.ParseAndUpdate(navPVT(3, 0x01|0x02|0x80), &nmeaData)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ubxData.Received, test.ShouldBeTrue)
+	test.That(t, ubxData.FixType, test.ShouldEqual, "3d")
+	test.That(t, ubxData.RTKStatus, test.ShouldEqual, RTKStatusFixed)
+	test.That(t, ubxData.HorizontalAccuracyM, test.ShouldAlmostEqual, 0.014)
+	test.That(t, ubxData.VerticalAccuracyM, test.ShouldAlmostEqual, 0.021)
+	test.That(t, nmeaData.FixQuality, test.ShouldEqual, 4)
+	test.That(t, nmeaData.SatsInUse, test.ShouldEqual, 18)
+	test.That(t, nmeaData.Location.Lat(), test.ShouldAlmostEqual, 40.7738555, 1e-7)
+	test.That(t, nmeaData.Location.Lng(), test.ShouldAlmostEqual, -73.9817305, 1e-7)
+	test.That(t, nmeaData.Alt, test.ShouldAlmostEqual, 12.345)
+	test.That(t, nmeaData.Speed, test.ShouldAlmostEqual, 1.5)
+	test.That(t, nmeaData.CompassHeading, test.ShouldAlmostEqual, 90)
+
+	// a float solution
+	test.That(t, ubxData.ParseAndUpdate(navPVT(3, 0x01|0x02|0x40), &nmeaData), test.ShouldBeNil)
+	test.That(t, ubxData.RTKStatus, test.ShouldEqual, RTKStatusFloat)
+	test.That(t, nmeaData.FixQuality, test.ShouldEqual, 5)
+
+	// no fix
+	err = ubxData.ParseAndUpdate(navPVT(0, 0), &nmeaData)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, ubxData.FixType, test.ShouldEqual, "no fix")
+	test.That(t, ubxData.RTKStatus, test.ShouldEqual, RTKStatusNone)
+	test.That(t, nmeaData.FixQuality, test.ShouldEqual, 0)
+
+	// a corrupt frame
+	frame := navPVT(3, 0x01)
+	frame[30]++
+	test.That(t, ubxData.ParseAndUpdate(frame, &nmeaData), test.ShouldBeError, "UBX frame checksum does not match")
+
+	dop := make([]byte, 18)
+	binary.LittleEndian.PutUint16(dop[10:], 120)
+	binary.LittleEndian.PutUint16(dop[12:], 75)
+	test.That(t, ubxData.ParseAndUpdate(NewUBXFrame(ubxClassNAV, ubxIDNavDOP, dop), &nmeaData), test.ShouldBeNil)
+	test.That(t, nmeaData.VDOP, test.ShouldAlmostEqual, 1.2)
+	test.That(t, nmeaData.HDOP, test.ShouldAlmostEqual, 0.75)
+
+	cov := make([]byte, 64)
+	cov[5] = 1
+	binary.LittleEndian.PutUint32(cov[16:], math.Float32bits(0.0004))
+	binary.LittleEndian.PutUint32(cov[28:], math.Float32bits(0.0009))
+	test.That(t, ubxData.ParseAndUpdate(NewUBXFrame(ubxClassNAV, ubxIDNavCOV, cov), &nmeaData), test.ShouldBeNil)
+	test.That(t, ubxData.CovarianceValid, test.ShouldBeTrue)
+	test.That(t, ubxData.CovarianceNN, test.ShouldAlmostEqual, 0.0004, 1e-9)
+	test.That(t, ubxData.CovarianceEE, test.ShouldAlmostEqual, 0.0009, 1e-9)
+
+	// the heading between two antennas takes the place of the heading of motion
+	relPos := make([]byte, 64)
+	relPos[0] = 1
+	binary.LittleEndian.PutUint32(relPos[24:], 4512345)
+	binary.LittleEndian.PutUint32(relPos[52:], 30000)
+	binary.LittleEndian.PutUint32(relPos[60:], 1<<8)
+	test.That(t, ubxData.ParseAndUpdate(NewUBXFrame(ubxClassNAV, ubxIDNavRELPOSNED, relPos), &nmeaData), test.ShouldBeNil)
+	test.That(t, ubxData.HeadingValid, test.ShouldBeTrue)
+	test.That(t, ubxData.HeadingAccuracyDegs, test.ShouldAlmostEqual, 0.3)
+	test.That(t, nmeaData.CompassHeading, test.ShouldAlmostEqual, 45.12345)
+	test.That(t, ubxData.ParseAndUpdate(navPVT(3, 0x01), &nmeaData), test.ShouldBeNil)
+	test.That(t, nmeaData.CompassHeading, test.ShouldAlmostEqual, 45.12345)
+
+	svin := make([]byte, 40)
+	binary.LittleEndian.PutUint32(svin[8:], 75)
+	binary.LittleEndian.PutUint32(svin[28:], 15000)
+	binary.LittleEndian.PutUint32(svin[32:], 80)
+	svin[36] = 1
+	test.That(t, ubxData.ParseAndUpdate(NewUBXFrame(ubxClassNAV, ubxIDNavSVIN, svin), &nmeaData), test.ShouldBeNil)
+	test.That(t, ubxData.SurveyIn, test.ShouldResemble, SurveyInStatus{
+		Valid: true, DurationSec: 75, Observations: 80, MeanAccuracyM: 1.5,
+	})
+
+	// messages which are not parsed are ignored
+	test.That(t, ubxData.ParseAndUpdate(NewUBXFrame(0x05, 0x01, []byte{ubxClassCFG, ubxIDCfgVALSET}), &nmeaData),
+		test.ShouldBeNil)
+}
+
+func TestReadMessage(t *testing.T) {
+	pvt := navPVT(3, 0x01)
+	var stream bytes.Buffer
+	stream.WriteString("$GNGLL,4046.43133,N,07358.90383,W,203755.00,A,A*6B\r\n")
+	stream.Write(pvt)
+	stream.WriteString("$GNGLL,4046.43133,N,07358.90383,W,203755.00,A,A*6B\r\n")
+	r := bufio.NewReader(&stream)
+
+	msg, err := readMessage(r)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, IsUBXFrame(msg), test.ShouldBeFalse)
+	test.That(t, msg, test.ShouldStartWith, "$GNGLL")
+	msg, err = readMessage(r)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, IsUBXFrame(msg), test.ShouldBeTrue)
+	test.That(t, []byte(msg), test.ShouldResemble, pvt)
+	msg, err = readMessage(r)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, msg, test.ShouldStartWith, "$GNGLL")
+}
+
+type mockWriterDataReader struct {
+	mockDataReader
+	written [][]byte
+}
+
+func (d *mockWriterDataReader) Write(p []byte) (int, error) {
+	d.written = append(d.written, p)
+	return len(p), nil
+}
+
+func TestSurveyIn(t *testing.T) {
+	frame := SurveyInFrame(90*time.Second, 1.5)
+	test.That(t, frame[:4], test.ShouldResemble, []byte{ubxSync1, ubxSync2, ubxClassCFG, ubxIDCfgVALSET})
+	payload := frame[ubxHeaderLen : len(frame)-ubxChecksumLen]
+	test.That(t, binary.LittleEndian.Uint32(payload[4:]), test.ShouldEqual, ubxCfgTmodeMode)
+	test.That(t, payload[8], test.ShouldEqual, ubxTmodeSurveyIn)
+	test.That(t, binary.LittleEndian.Uint32(payload[13:]), test.ShouldEqual, 90)
+	test.That(t, binary.LittleEndian.Uint32(payload[21:]), test.ShouldEqual, 15000)
+
+	ctx := context.Background()
+	dev := &mockWriterDataReader{}
+	g := NewCachedData(dev, logging.NewTestLogger(t))
+	defer g.Close(ctx)
+
+	_, err := g.DoCommand(ctx, map[string]interface{}{SurveyInStatusCommand: true})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = g.DoCommand(ctx, map[string]interface{}{
+		StartSurveyInCommand: map[string]interface{}{"min_duration_sec": 90.0, "accuracy_limit_m": 1.5},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = g.DoCommand(ctx, map[string]interface{}{StopSurveyInCommand: true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dev.written, test.ShouldResemble, [][]byte{frame, SurveyInFrame(0, 0)})
+
+	svin := make([]byte, 40)
+	svin[37] = 1
+	test.That(t, g.ParseAndUpdate(string(NewUBXFrame(ubxClassNAV, ubxIDNavSVIN, svin))), test.ShouldBeNil)
+	resp, err := g.DoCommand(ctx, map[string]interface{}{SurveyInStatusCommand: true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp[SurveyInStatusCommand].(map[string]interface{})["active"], test.ShouldBeTrue)
+	test.That(t, g.UBXReadings(), test.ShouldContainKey, "survey_in")
+
+	// a device which cannot be written to cannot be configured
+	g2 := NewCachedData(&mockDataReader{}, logging.NewTestLogger(t))
+	defer g2.Close(ctx)
+	_, err = g2.DoCommand(ctx, map[string]interface{}{StopSurveyInCommand: true})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestUBXAccuracy(t *testing.T) {
+	ctx := context.Background()
+	g := NewCachedData(&mockDataReader{}, logging.NewTestLogger(t))
+	defer g.Close(ctx)
+	test.That(t, g.UBXReadings(), test.ShouldBeEmpty)
+
+	test.That(t, g.ParseAndUpdate(string(navPVT(3, 0x01|0x80))), test.ShouldBeNil)
+	acc, err := g.Accuracy(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, acc.NmeaFix, test.ShouldEqual, 4)
+	test.That(t, acc.AccuracyMap[AccuracyHorizontalMeters], test.ShouldAlmostEqual, 0.014, 1e-6)
+	test.That(t, acc.AccuracyMap, test.ShouldNotContainKey, AccuracyCovarianceNN)
+	test.That(t, g.UBXReadings(), test.ShouldResemble, map[string]interface{}{"fix_type": "3d", "rtk_status": RTKStatusFixed})
+}
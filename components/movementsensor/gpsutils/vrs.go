@@ -3,6 +3,8 @@ package gpsutils
 
 import (
 	"bufio"
+	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -10,17 +12,26 @@ import (
 	"net"
 	"net/url"
 	"strings"
+	"time"
+
+	"go.uber.org/multierr"
 
 	"go.viam.com/rdk/logging"
 )
 
 // ConnectToVirtualBase is responsible for establishing a connection to
-// a virtual base station using the NTRIP protocol.
-func ConnectToVirtualBase(ntripInfo *NtripInfo,
+// a virtual base station using the NTRIP protocol, over TLS if the URL is https.
+// The caster generates corrections for the position of the GGA sentence, which
+// should be sent again periodically by writing to the returned connection.
+func ConnectToVirtualBase(ctx context.Context, ntripInfo *NtripInfo, gga string,
 	logger logging.Logger,
-) (*bufio.ReadWriter, error) {
+) (io.ReadWriteCloser, error) {
 	mp := "/" + ntripInfo.MountPoint
-	credentials := ntripInfo.username + ":" + ntripInfo.password
+	username, password, err := ntripInfo.Credentials()
+	if err != nil {
+		return nil, err
+	}
+	credentials := username + ":" + password
 	credentialsBase64 := base64.StdEncoding.EncodeToString([]byte(credentials))
 
 	// Process the server URL
@@ -28,35 +39,79 @@ func ConnectToVirtualBase(ntripInfo *NtripInfo,
 	if err != nil {
 		return nil, err
 	}
+	host := serverAddr.Host
+	if serverAddr.Port() == "" {
+		if serverAddr.Scheme == "https" {
+			host = net.JoinHostPort(serverAddr.Hostname(), "443")
+		} else {
+			host = net.JoinHostPort(serverAddr.Hostname(), "80")
+		}
+	}
 
-	conn, err := net.Dial("tcp", serverAddr.Host)
+	dialer := &net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}
+	var conn net.Conn
+	if serverAddr.Scheme == "https" {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{
+			ServerName: serverAddr.Hostname(),
+			MinVersion: tls.VersionTLS12,
+		}}).DialContext(ctx, "tcp", host)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", host)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
-
 	// Construct HTTP headers with CRLF line endings
 	httpHeaders := "GET " + mp + " HTTP/1.1\r\n" +
 		"Host: " + serverAddr.Host + "\r\n" +
 		"Authorization: Basic " + credentialsBase64 + "\r\n" +
 		"Accept: */*\r\n" +
 		"Ntrip-Version: Ntrip/2.0\r\n" +
+		"Ntrip-GGA: " + strings.TrimSpace(gga) + "\r\n" +
 		"User-Agent: NTRIP viam\r\n\r\n"
 
-	// Send HTTP headers over the TCP connection
-	_, err = rw.Write([]byte(httpHeaders))
-	if err != nil {
-		return nil, fmt.Errorf("failed to send HTTP headers: %w", err)
+	// Send HTTP headers over the TCP connection, followed by the position for casters which
+	// do not read it from the Ntrip-GGA header.
+	if err := conn.SetDeadline(time.Now().Add(connectTimeout)); err != nil {
+		return nil, multierr.Combine(err, conn.Close())
+	}
+	if _, err = conn.Write([]byte(httpHeaders + strings.TrimSpace(gga) + "\r\n")); err != nil {
+		return nil, multierr.Combine(fmt.Errorf("failed to send HTTP headers: %w", err), conn.Close())
 	}
-	err = rw.Flush()
+	logger.Debugf("requested mountpoint %s from %s", mp, serverAddr.Host)
+
+	// read the response until its headers end. NTRIP 1 casters respond with "ICY 200 OK" and no headers.
+	r := bufio.NewReader(conn)
+	status, err := r.ReadString('\n')
 	if err != nil {
-		return nil, fmt.Errorf("failed to write to buffer: %w", err)
+		return nil, multierr.Combine(fmt.Errorf("failed to read server response: %w", err), conn.Close())
+	}
+	if !strings.Contains(status, " 200") {
+		return nil, multierr.Combine(fmt.Errorf("bad response from virtual reference station: %s", strings.TrimSpace(status)),
+			conn.Close())
+	}
+	for line := status; !strings.HasPrefix(status, "ICY") && strings.TrimSpace(line) != ""; {
+		if line, err = r.ReadString('\n'); err != nil {
+			return nil, multierr.Combine(fmt.Errorf("failed to read server response: %w", err), conn.Close())
+		}
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return nil, multierr.Combine(err, conn.Close())
 	}
 
-	logger.Debugf("request header: %v\n", httpHeaders)
-	logger.Debug("HTTP headers sent successfully.")
-	return rw, nil
+	logger.Debug("connected to virtual reference station")
+	return &virtualBaseConn{Reader: r, Conn: conn}, nil
+}
+
+// virtualBaseConn reads what is left of the response buffered by its Reader.
+type virtualBaseConn struct {
+	*bufio.Reader
+	net.Conn
+}
+
+func (c *virtualBaseConn) Read(p []byte) (int, error) {
+	return c.Reader.Read(p)
 }
 
 // GetGGAMessage checks if a GGA message exists in the buffer and returns it.
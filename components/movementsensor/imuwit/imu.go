@@ -39,6 +39,7 @@ import (
 	"go.viam.com/utils"
 
 	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/movementsensor/geomag"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/spatialmath"
@@ -62,6 +63,9 @@ var maxTiltInRad = rutils.DegToRad(45)
 type Config struct {
 	Port     string `json:"serial_path"`
 	BaudRate uint   `json:"serial_baud_rate,omitempty"`
+	// MagneticDeclinationDegs corrects the compass heading to true north. A magnetic model needs a position, so
+	// merge this sensor with a GPS to compute the declination from one.
+	MagneticDeclinationDegs *float64 `json:"magnetic_declination_degs,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -76,6 +80,11 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 		return nil, resource.NewConfigValidationError(path, errors.Errorf("Baud rate is not in %v", baudRateList))
 	}
 
+	declination := geomag.DeclinationConfig{MagneticDeclinationDegs: cfg.MagneticDeclinationDegs}
+	if err := declination.Validate(path); err != nil {
+		return nil, err
+	}
+
 	return nil, nil
 }
 
@@ -93,6 +102,7 @@ type wit struct {
 	acceleration    r3.Vector
 	magnetometer    r3.Vector
 	compassheading  float64
+	declination     float64
 	numBadReadings  uint32
 	err             movementsensor.LastError
 	hasMagnetometer bool
@@ -116,10 +126,20 @@ func (imu *wit) Reconfigure(ctx context.Context, deps resource.Dependencies, con
 
 	imu.baudRate = newConf.BaudRate
 	imu.serialPath = newConf.Port
+	imu.setDeclination(newConf)
 
 	return nil
 }
 
+func (imu *wit) setDeclination(conf *Config) {
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	imu.declination = 0
+	if conf.MagneticDeclinationDegs != nil {
+		imu.declination = *conf.MagneticDeclinationDegs
+	}
+}
+
 func (imu *wit) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
 	imu.mu.Lock()
 	defer imu.mu.Unlock()
@@ -160,7 +180,7 @@ func (imu *wit) CompassHeading(ctx context.Context, extra map[string]interface{}
 
 	// this will compensate for a tilted IMU if the tilt is less than 45 degrees
 	// do not let the imu near permanent magnets or things that make a strong magnetic field
-	imu.compassheading = imu.calculateCompassHeading()
+	imu.compassheading = geomag.TrueHeading(imu.calculateCompassHeading(), imu.declination)
 
 	return imu.compassheading, err
 }
@@ -284,6 +304,7 @@ func newWit(
 		logger: logger,
 		err:    movementsensor.NewLastError(1, 1),
 	}
+	i.setDeclination(newConf)
 	logger.CDebugf(ctx, "initializing wit serial connection with parameters: %+v", options)
 	i.port, err = slib.Open(options)
 	if err != nil {
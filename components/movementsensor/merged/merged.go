@@ -6,13 +6,16 @@ import (
 	"fmt"
 	"math"
 	"sync"
+	"time"
 
 	"github.com/golang/geo/r3"
 	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
 	"go.uber.org/multierr"
 	"golang.org/x/exp/maps"
 
 	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/movementsensor/geomag"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/spatialmath"
@@ -30,6 +33,11 @@ type Config struct {
 	LinearVelocity     []string `json:"linear_velocity,omitempty"`
 	AngularVelocity    []string `json:"angular_velocity,omitempty"`
 	LinearAcceleration []string `json:"linear_acceleration,omitempty"`
+
+	// DeclinationConfig corrects the magnetic compass heading to true north, by a fixed declination or by one
+	// computed from a magnetic model at the merged position. Leave it out when the compass heading sensor already
+	// reports true headings, as GPS courses are.
+	geomag.DeclinationConfig `json:",squash"`
 }
 
 // Validate validates the merged model's configuration.
@@ -41,6 +49,12 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 	deps = append(deps, cfg.LinearVelocity...)
 	deps = append(deps, cfg.AngularVelocity...)
 	deps = append(deps, cfg.LinearAcceleration...)
+	if err := cfg.DeclinationConfig.Validate(path); err != nil {
+		return nil, err
+	}
+	if cfg.MagneticModelFile != "" && len(cfg.Position) == 0 {
+		return nil, errors.Errorf("%s: magnetic_model_file needs a position sensor", path)
+	}
 	return deps, nil
 }
 
@@ -56,6 +70,8 @@ type merged struct {
 	linVel  movementsensor.MovementSensor
 	angVel  movementsensor.MovementSensor
 	linAcc  movementsensor.MovementSensor
+
+	declination *geomag.Declination
 }
 
 func init() {
@@ -189,6 +205,16 @@ func (m *merged) Reconfigure(ctx context.Context, deps resource.Dependencies, co
 		return err
 	}
 
+	m.declination, err = geomag.NewDeclination(newConf.DeclinationConfig)
+	if err != nil {
+		return err
+	}
+	if m.declination != nil && m.declination.NeedsPosition() {
+		if model := m.declination.Model(); !model.ValidAt(time.Now()) {
+			m.logger.CWarnf(ctx, "magnetic model %s of %v is out of date, declinations may be off", model.Name, model.Epoch)
+		}
+	}
+
 	return nil
 }
 
@@ -227,7 +253,22 @@ func (m *merged) CompassHeading(ctx context.Context, extra map[string]interface{
 		return math.NaN(),
 			movementsensor.ErrMethodUnimplementedCompassHeading
 	}
-	return m.compass.CompassHeading(ctx, extra)
+	heading, err := m.compass.CompassHeading(ctx, extra)
+	if err != nil || m.declination == nil {
+		return heading, err
+	}
+	if !m.declination.NeedsPosition() {
+		return geomag.TrueHeading(heading, m.declination.At(0, 0, 0, time.Now())), nil
+	}
+
+	pos, alt, err := m.pos.Position(ctx, extra)
+	if err != nil {
+		return math.NaN(), errors.Wrap(err, "cannot get the position to correct the compass heading for declination")
+	}
+	if pos == nil || movementsensor.IsPositionNaN(pos) {
+		return math.NaN(), errors.New("no position to correct the compass heading for declination")
+	}
+	return geomag.TrueHeading(heading, m.declination.At(pos.Lat(), pos.Lng(), alt, time.Now())), nil
 }
 
 func (m *merged) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
@@ -4,18 +4,23 @@ import (
 	"context"
 	"errors"
 	"math"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/geo/r3"
 	geo "github.com/kellydunn/golang-geo"
 	"go.viam.com/test"
 
 	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/movementsensor/geomag"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/spatialmath"
 	"go.viam.com/rdk/testutils/inject"
+	rutils "go.viam.com/rdk/utils"
 )
 
 const (
@@ -160,6 +165,72 @@ func TestValidate(t *testing.T) {
 	implicits, err = conf.Validate("somepath", movementsensor.API.Type.Name)
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, implicits, test.ShouldResemble, append(linvelSensors, angvelSensors...))
+
+	declination := 12.5
+	cfg := &Config{CompassHeading: compassSensors, Position: posSensors}
+	cfg.MagneticDeclinationDegs = &declination
+	cfg.MagneticModelFile = "WMM.COF"
+	_, err = cfg.Validate("somepath")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "only one of")
+
+	// the declination of a magnetic model depends on the position
+	cfg = &Config{CompassHeading: compassSensors}
+	cfg.MagneticModelFile = "WMM.COF"
+	_, err = cfg.Validate("somepath")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "needs a position sensor")
+}
+
+func TestDeclination(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	depmap := map[string]movementsensor.Properties{
+		posSensors[0]:     posProps,
+		compassSensors[1]: compassProps,
+	}
+	deps := setupDependencies(t, depmap, false, false)
+	conf := setUpCfg(emptySensors, posSensors[:1], compassSensors[1:2], emptySensors, emptySensors, emptySensors)
+
+	declination := -80.0
+	conf.ConvertedAttributes.(*Config).MagneticDeclinationDegs = &declination
+	ms, err := newMergedModel(ctx, deps, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	compass, err := ms.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, compass, test.ShouldAlmostEqual, 355)
+
+	// a model of only the axial dipole has no declination, and adding an equatorial one turns the field
+	cof := filepath.Join(t.TempDir(), "TEST.COF")
+	test.That(t, os.WriteFile(cof, []byte(`    2020.0            TEST        12/10/2019
+  1  0  -30000.0       0.0        0.0        0.0
+  1  1   -2000.0    5000.0        0.0        0.0
+999999999999999999999999999999999999999999999999
+`), 0o600), test.ShouldBeNil)
+	conf.ConvertedAttributes.(*Config).MagneticDeclinationDegs = nil
+	conf.ConvertedAttributes.(*Config).MagneticModelFile = cof
+	test.That(t, ms.Reconfigure(ctx, deps, conf), test.ShouldBeNil)
+	model, err := geomag.LoadCOF(cof)
+	test.That(t, err, test.ShouldBeNil)
+	want := model.Field(testgeopoint.Lat(), testgeopoint.Lng(), testalt, time.Now()).Declination
+	test.That(t, want, test.ShouldNotAlmostEqual, 0)
+	compass, err = ms.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, compass, test.ShouldAlmostEqual, testcompass+want, 1e-6)
+
+	conf.ConvertedAttributes.(*Config).MagneticModelFile = "/does/not/exist.COF"
+	test.That(t, ms.Reconfigure(ctx, deps, conf), test.ShouldNotBeNil)
+	test.That(t, ms.Close(ctx), test.ShouldBeNil)
+}
+
+func TestDeclinationAttributes(t *testing.T) {
+	conf, err := resource.TransformAttributeMap[*Config](rutils.AttributeMap{
+		"compass_heading":           []interface{}{"compass"},
+		"magnetic_declination_degs": -12.5,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, conf.CompassHeading, test.ShouldResemble, []string{"compass"})
+	test.That(t, conf.MagneticDeclinationDegs, test.ShouldNotBeNil)
+	test.That(t, *conf.MagneticDeclinationDegs, test.ShouldEqual, -12.5)
 }
 
 func TestCreation(t *testing.T) {
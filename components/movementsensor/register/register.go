@@ -4,8 +4,11 @@ package register
 import (
 	// Load all movementsensors.
 	_ "go.viam.com/rdk/components/movementsensor/adxl345"
+	_ "go.viam.com/rdk/components/movementsensor/bno055"
+	_ "go.viam.com/rdk/components/movementsensor/bno08x"
 	_ "go.viam.com/rdk/components/movementsensor/dualgps"
 	_ "go.viam.com/rdk/components/movementsensor/fake"
+	_ "go.viam.com/rdk/components/movementsensor/fusion"
 	_ "go.viam.com/rdk/components/movementsensor/gpsnmea"
 	_ "go.viam.com/rdk/components/movementsensor/gpsrtkpmtk"
 	_ "go.viam.com/rdk/components/movementsensor/gpsrtkserial"
@@ -14,5 +17,6 @@ import (
 	_ "go.viam.com/rdk/components/movementsensor/merged"
 	_ "go.viam.com/rdk/components/movementsensor/mpu6050"
 	_ "go.viam.com/rdk/components/movementsensor/replay"
+	_ "go.viam.com/rdk/components/movementsensor/simulated"
 	_ "go.viam.com/rdk/components/movementsensor/wheeledodometry"
 )
@@ -0,0 +1,173 @@
+package movementsensor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+const (
+	// DefaultSampleRateHz is how often StreamSamples polls movement sensors which cannot stream their samples.
+	DefaultSampleRateHz = 100.
+	// MaxSampleRateHz is the fastest StreamSamples polls a movement sensor.
+	MaxSampleRateHz = 1000.
+
+	sampleSubscriberCapacity = 64
+)
+
+// A Sample is what a movement sensor measured at a moment, for control loops and SLAM which need every reading of an
+// IMU rather than the latest one. Measurements the sensor does not make are left zero, and Orientation nil.
+type Sample struct {
+	Time               time.Time
+	LinearAcceleration r3.Vector
+	AngularVelocity    spatialmath.AngularVelocity
+	Orientation        spatialmath.Orientation
+}
+
+// A SampleStreamer is a movement sensor which can stream its samples at the rate it measures them, such as an IMU
+// driver reading its chip in the background.
+type SampleStreamer interface {
+	// StreamSamples sends every sample the sensor measures to the returned channel until ctx is done, after which
+	// the channel is closed. Samples are dropped rather than queued when the receiver falls behind.
+	//
+	//    myIMU, err := movementsensor.FromRobot(machine, "my_imu")
+	//    // Receive the samples of the IMU, at 100Hz if it cannot stream them.
+	//    samples, err := movementsensor.StreamSamples(ctx, myIMU, 100, nil)
+	//    for sample := range samples {
+	//            logger.Info(sample.Time, sample.AngularVelocity)
+	//    }
+	StreamSamples(ctx context.Context, extra map[string]interface{}) (<-chan Sample, error)
+}
+
+// StreamSamples streams the samples of the movement sensor until ctx is done. Sensors which are SampleStreamers send
+// every sample they measure, and others are polled rateHz times a second, defaulting to DefaultSampleRateHz.
+func StreamSamples(ctx context.Context, ms MovementSensor, rateHz float64, extra map[string]interface{}) (<-chan Sample, error) {
+	if streamer, ok := ms.(SampleStreamer); ok {
+		return streamer.StreamSamples(ctx, extra)
+	}
+	if rateHz == 0 {
+		rateHz = DefaultSampleRateHz
+	}
+	if rateHz < 0 || rateHz > MaxSampleRateHz {
+		return nil, errors.Errorf("sample rate must be above 0 and at most %v Hz, got %v", MaxSampleRateHz, rateHz)
+	}
+	props, err := ms.Properties(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	if !props.LinearAccelerationSupported && !props.AngularVelocitySupported && !props.OrientationSupported {
+		return nil, errors.New("movement sensor measures none of linear acceleration, angular velocity or orientation")
+	}
+
+	samples := make(chan Sample, sampleSubscriberCapacity)
+	goutils.PanicCapturingGo(func() {
+		defer close(samples)
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / rateHz))
+		defer ticker.Stop()
+		for {
+			sample, err := readSample(ctx, ms, props, extra)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+			} else {
+				select {
+				case samples <- sample:
+				default:
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+	return samples, nil
+}
+
+// readSample polls the measurements of the movement sensor which it supports.
+func readSample(ctx context.Context, ms MovementSensor, props *Properties, extra map[string]interface{}) (Sample, error) {
+	var sample Sample
+	var err error
+	if props.LinearAccelerationSupported {
+		if sample.LinearAcceleration, err = ms.LinearAcceleration(ctx, extra); err != nil {
+			return Sample{}, err
+		}
+	}
+	if props.AngularVelocitySupported {
+		if sample.AngularVelocity, err = ms.AngularVelocity(ctx, extra); err != nil {
+			return Sample{}, err
+		}
+	}
+	if props.OrientationSupported {
+		if sample.Orientation, err = ms.Orientation(ctx, extra); err != nil {
+			return Sample{}, err
+		}
+	}
+	sample.Time = time.Now()
+	return sample, nil
+}
+
+// SampleBroadcaster fans the samples a driver measures out to the streams of its subscribers, for drivers
+// implementing SampleStreamer. The zero value is ready to use.
+type SampleBroadcaster struct {
+	mu          sync.Mutex
+	subscribers map[chan Sample]struct{}
+	closed      bool
+}
+
+// Publish sends the sample to every subscriber which has room for it, without ever blocking the driver.
+func (b *SampleBroadcaster) Publish(sample Sample) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subscribers {
+		select {
+		case sub <- sample:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a stream of the samples published until ctx is done or the broadcaster is closed, after which
+// it is closed.
+func (b *SampleBroadcaster) Subscribe(ctx context.Context) <-chan Sample {
+	sub := make(chan Sample, sampleSubscriberCapacity)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(sub)
+		return sub
+	}
+	if b.subscribers == nil {
+		b.subscribers = map[chan Sample]struct{}{}
+	}
+	b.subscribers[sub] = struct{}{}
+	goutils.PanicCapturingGo(func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[sub]; ok {
+			delete(b.subscribers, sub)
+			close(sub)
+		}
+	})
+	return sub
+}
+
+// Close ends the streams of every subscriber, for when the driver closes.
+func (b *SampleBroadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		close(sub)
+	}
+}
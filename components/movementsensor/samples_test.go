@@ -0,0 +1,92 @@
+package movementsensor_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// streamingSensor is a movement sensor which streams its samples.
+type streamingSensor struct {
+	*inject.MovementSensor
+	samples movementsensor.SampleBroadcaster
+}
+
+func (s *streamingSensor) StreamSamples(ctx context.Context, extra map[string]interface{}) (<-chan movementsensor.Sample, error) {
+	return s.samples.Subscribe(ctx), nil
+}
+
+func TestStreamSamples(t *testing.T) {
+	t.Run("polls sensors which cannot stream", func(t *testing.T) {
+		var reads atomic.Int32
+		ms := inject.NewMovementSensor("imu")
+		ms.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+			return &movementsensor.Properties{LinearAccelerationSupported: true, AngularVelocitySupported: true}, nil
+		}
+		ms.LinearAccelerationFunc = func(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+			return r3.Vector{Z: float64(reads.Add(1))}, nil
+		}
+		ms.AngularVelocityFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+			return spatialmath.AngularVelocity{X: 1}, nil
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		samples, err := movementsensor.StreamSamples(ctx, ms, 200, nil)
+		test.That(t, err, test.ShouldBeNil)
+		var previous movementsensor.Sample
+		for i := 1; i <= 3; i++ {
+			sample := <-samples
+			test.That(t, sample.LinearAcceleration.Z, test.ShouldEqual, i)
+			test.That(t, sample.AngularVelocity.X, test.ShouldEqual, 1)
+			test.That(t, sample.Orientation, test.ShouldBeNil)
+			test.That(t, sample.Time.After(previous.Time), test.ShouldBeTrue)
+			previous = sample
+		}
+		cancel()
+		for range samples {
+		}
+
+		_, err = movementsensor.StreamSamples(context.Background(), ms, 5000, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+
+		gps := inject.NewMovementSensor("gps")
+		gps.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+			return &movementsensor.Properties{PositionSupported: true}, nil
+		}
+		_, err = movementsensor.StreamSamples(context.Background(), gps, 0, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("streams the samples of sensors which can", func(t *testing.T) {
+		ms := &streamingSensor{MovementSensor: inject.NewMovementSensor("imu")}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		samples, err := movementsensor.StreamSamples(ctx, ms, 0, nil)
+		test.That(t, err, test.ShouldBeNil)
+		other, err := movementsensor.StreamSamples(ctx, ms, 0, nil)
+		test.That(t, err, test.ShouldBeNil)
+
+		now := time.Now()
+		ms.samples.Publish(movementsensor.Sample{Time: now, LinearAcceleration: r3.Vector{X: 2}})
+		for _, stream := range []<-chan movementsensor.Sample{samples, other} {
+			sample := <-stream
+			test.That(t, sample.Time, test.ShouldEqual, now)
+			test.That(t, sample.LinearAcceleration.X, test.ShouldEqual, 2)
+		}
+
+		// closing the driver ends the streams
+		ms.samples.Close()
+		_, ok := <-samples
+		test.That(t, ok, test.ShouldBeFalse)
+		_, ok = <-ms.samples.Subscribe(ctx)
+		test.That(t, ok, test.ShouldBeFalse)
+	})
+}
@@ -0,0 +1,413 @@
+// Package simulated implements a movement sensor which follows a configured trajectory and reports GPS and/or IMU
+// readings with configurable noise, drift and dropout so that navigation and fusion logic can be tested deterministically.
+package simulated
+
+/*
+   Example Config:
+   {
+     "name": "sim-gps",
+     "type": "movement_sensor",
+     "model": "simulated",
+     "attributes": {
+       "sensor_type": "gps",
+       "trajectory": {
+         "type": "circle",
+         "origin": {"latitude": 40.7, "longitude": -73.98},
+         "radius_m": 20,
+         "speed_m_per_sec": 1.5
+       },
+       "noise": {
+         "position_std_dev_m": 0.5,
+         "heading_std_dev_degs": 2,
+         "heading_drift_degs_per_sec": 0.01,
+         "dropout_probability": 0.05,
+         "seed": 7
+       }
+     }
+   }
+*/
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("simulated")
+
+// Supported sensor types, which determine the properties the simulated sensor reports.
+const (
+	sensorTypeGPS    = "gps"
+	sensorTypeIMU    = "imu"
+	sensorTypeGPSIMU = "gps_imu"
+)
+
+// finiteDifferenceSec is the step used to numerically differentiate trajectories.
+const finiteDifferenceSec = 0.01
+
+// minSpeedForHeading is the speed below which the heading of travel is considered undefined.
+const minSpeedForHeading = 1e-6
+
+// ErrSimulatedDropout is returned when a reading is dropped to simulate a loss of signal.
+var ErrSimulatedDropout = errors.New("simulated movement sensor dropout")
+
+// NoiseConfig describes the imperfections applied to the ideal readings of the trajectory.
+type NoiseConfig struct {
+	PositionStdDevM                       float64 `json:"position_std_dev_m,omitempty"`
+	AltitudeStdDevM                       float64 `json:"altitude_std_dev_m,omitempty"`
+	HeadingStdDevDegs                     float64 `json:"heading_std_dev_degs,omitempty"`
+	LinearVelocityStdDevMPerSec           float64 `json:"linear_velocity_std_dev_m_per_sec,omitempty"`
+	AngularVelocityStdDevDegsPerSec       float64 `json:"angular_velocity_std_dev_degs_per_sec,omitempty"`
+	LinearAccelerationStdDevMPerSecPerSec float64 `json:"linear_acceleration_std_dev_m_per_sec_per_sec,omitempty"`
+	// PositionRandomWalkMPerSqrtSec grows a random walk position bias, as satellite geometry changes would.
+	PositionRandomWalkMPerSqrtSec float64 `json:"position_random_walk_m_per_sqrt_sec,omitempty"`
+	// HeadingDriftDegsPerSec grows a constant heading bias, as an uncorrected gyro would.
+	HeadingDriftDegsPerSec float64 `json:"heading_drift_degs_per_sec,omitempty"`
+	// DropoutProbability is the probability in [0, 1] that any single reading fails.
+	DropoutProbability float64 `json:"dropout_probability,omitempty"`
+	// Seed makes the noise reproducible across runs.
+	Seed int64 `json:"seed,omitempty"`
+}
+
+// Config is used for converting simulated movementsensor attributes.
+type Config struct {
+	SensorType string            `json:"sensor_type,omitempty"`
+	Trajectory *TrajectoryConfig `json:"trajectory"`
+	Noise      NoiseConfig       `json:"noise,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	switch cfg.SensorType {
+	case "", sensorTypeGPS, sensorTypeIMU, sensorTypeGPSIMU:
+	default:
+		return nil, resource.NewConfigValidationError(path, errors.Errorf("unknown sensor_type %q", cfg.SensorType))
+	}
+	if cfg.Trajectory == nil {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "trajectory")
+	}
+	if err := cfg.Trajectory.Validate(path); err != nil {
+		return nil, err
+	}
+	if cfg.Noise.DropoutProbability < 0 || cfg.Noise.DropoutProbability > 1 {
+		return nil, resource.NewConfigValidationError(path, errors.New("dropout_probability must be within [0, 1]"))
+	}
+	for _, stdDev := range []float64{
+		cfg.Noise.PositionStdDevM,
+		cfg.Noise.AltitudeStdDevM,
+		cfg.Noise.HeadingStdDevDegs,
+		cfg.Noise.LinearVelocityStdDevMPerSec,
+		cfg.Noise.AngularVelocityStdDevDegsPerSec,
+		cfg.Noise.LinearAccelerationStdDevMPerSecPerSec,
+		cfg.Noise.PositionRandomWalkMPerSqrtSec,
+	} {
+		if stdDev < 0 {
+			return nil, resource.NewConfigValidationError(path, errors.New("noise standard deviations may not be negative"))
+		}
+	}
+	return nil, nil
+}
+
+func init() {
+	resource.RegisterComponent(
+		movementsensor.API,
+		model,
+		resource.Registration[movementsensor.MovementSensor, *Config]{Constructor: newSimulated})
+}
+
+func newSimulated(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (movementsensor.MovementSensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	return newSimulatedWithClock(conf.ResourceName(), newConf, clock.New(), logger)
+}
+
+// newSimulatedWithClock creates a simulated movement sensor whose trajectory starts at the current time of the given clock.
+func newSimulatedWithClock(
+	name resource.Name,
+	conf *Config,
+	clk clock.Clock,
+	logger logging.Logger,
+) (*simulated, error) {
+	traj, err := newTrajectory(conf.Trajectory)
+	if err != nil {
+		return nil, err
+	}
+	sensorType := conf.SensorType
+	if sensorType == "" {
+		sensorType = sensorTypeGPSIMU
+	}
+	now := clk.Now()
+	return &simulated{
+		Named:          name.AsNamed(),
+		logger:         logger,
+		sensorType:     sensorType,
+		traj:           traj,
+		noise:          conf.Noise,
+		defaultHeading: conf.Trajectory.HeadingDegs,
+		clock:          clk,
+		start:          now,
+		lastWalk:       now,
+		rand:           rand.New(rand.NewSource(conf.Noise.Seed)),
+	}, nil
+}
+
+type simulated struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+	logger logging.Logger
+
+	sensorType     string
+	traj           trajectory
+	noise          NoiseConfig
+	defaultHeading float64
+	clock          clock.Clock
+	start          time.Time
+
+	mu           sync.Mutex
+	rand         *rand.Rand
+	positionBias r3.Vector
+	lastWalk     time.Time
+}
+
+// state is the ideal, noise free, kinematic state of the trajectory at an instant.
+type state struct {
+	position r3.Vector
+	// heading is a left handed compass heading in degrees
+	heading float64
+	// velocity is in the body frame, +Y forwards
+	velocity r3.Vector
+	// yawRate is right handed, in degrees per second
+	yawRate float64
+	// acceleration is in the body frame, +Y forwards and +X to the right, excluding gravity
+	acceleration r3.Vector
+}
+
+func (s *simulated) stateAt(t float64) state {
+	h := finiteDifferenceSec
+	prev, curr, next := s.traj.positionAt(t-h), s.traj.positionAt(t), s.traj.positionAt(t+h)
+	worldVel := next.Sub(prev).Mul(1 / (2 * h))
+	worldAcc := next.Add(prev).Sub(curr.Mul(2)).Mul(1 / (h * h))
+
+	headingAt := func(vel r3.Vector) float64 {
+		if math.Hypot(vel.X, vel.Y) < minSpeedForHeading {
+			return s.defaultHeading
+		}
+		return normalizeDegrees(rdkutils.RadToDeg(math.Atan2(vel.X, vel.Y)))
+	}
+	heading := headingAt(worldVel)
+	headingBefore := headingAt(curr.Sub(prev).Mul(1 / h))
+	headingAfter := headingAt(next.Sub(curr).Mul(1 / h))
+	// compass headings increase clockwise while yaw rates are right handed
+	yawRate := -wrapDegrees(headingAfter-headingBefore) / h
+
+	headingRad := rdkutils.DegToRad(heading)
+	forward := r3.Vector{X: math.Sin(headingRad), Y: math.Cos(headingRad)}
+	right := r3.Vector{X: math.Cos(headingRad), Y: -math.Sin(headingRad)}
+	return state{
+		position: curr,
+		heading:  heading,
+		velocity: r3.Vector{Y: math.Hypot(worldVel.X, worldVel.Y), Z: worldVel.Z},
+		yawRate:  yawRate,
+		acceleration: r3.Vector{
+			X: worldAcc.X*right.X + worldAcc.Y*right.Y,
+			Y: worldAcc.X*forward.X + worldAcc.Y*forward.Y,
+			Z: worldAcc.Z,
+		},
+	}
+}
+
+// sample returns the ideal state at the current time, or an error if the reading is dropped.
+func (s *simulated) sample() (state, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.noise.DropoutProbability > 0 && s.rand.Float64() < s.noise.DropoutProbability {
+		return state{}, ErrSimulatedDropout
+	}
+	now := s.clock.Now()
+	return s.stateAt(now.Sub(s.start).Seconds()), nil
+}
+
+// gaussian returns a normally distributed sample with the given standard deviation. Must be called with s.mu held.
+func (s *simulated) gaussian(stdDev float64) float64 {
+	if stdDev == 0 {
+		return 0
+	}
+	return s.rand.NormFloat64() * stdDev
+}
+
+func (s *simulated) noisy(stdDev float64) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gaussian(stdDev)
+}
+
+// noisyVector adds independent noise of the given standard deviation to each component of v.
+func (s *simulated) noisyVector(v r3.Vector, stdDev float64) r3.Vector {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return r3.Vector{X: v.X + s.gaussian(stdDev), Y: v.Y + s.gaussian(stdDev), Z: v.Z + s.gaussian(stdDev)}
+}
+
+// heading returns the noisy, drifting compass heading of the state.
+func (s *simulated) heading(st state) float64 {
+	drift := s.noise.HeadingDriftDegsPerSec * s.clock.Since(s.start).Seconds()
+	return normalizeDegrees(st.heading + drift + s.noisy(s.noise.HeadingStdDevDegs))
+}
+
+func (s *simulated) supportsGPS() bool {
+	return s.sensorType == sensorTypeGPS || s.sensorType == sensorTypeGPSIMU
+}
+
+func (s *simulated) supportsIMU() bool {
+	return s.sensorType == sensorTypeIMU || s.sensorType == sensorTypeGPSIMU
+}
+
+// Position returns the noisy position of the simulated sensor along its trajectory.
+func (s *simulated) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	if !s.supportsGPS() {
+		return nil, 0, movementsensor.ErrMethodUnimplementedPosition
+	}
+	st, err := s.sample()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s.mu.Lock()
+	if walk := s.noise.PositionRandomWalkMPerSqrtSec; walk > 0 {
+		now := s.clock.Now()
+		scale := walk * math.Sqrt(now.Sub(s.lastWalk).Seconds())
+		s.positionBias = s.positionBias.Add(r3.Vector{X: s.gaussian(scale), Y: s.gaussian(scale)})
+		s.lastWalk = now
+	}
+	offset := s.positionBias.Add(r3.Vector{
+		X: s.gaussian(s.noise.PositionStdDevM),
+		Y: s.gaussian(s.noise.PositionStdDevM),
+	})
+	altitude := st.position.Z + s.gaussian(s.noise.AltitudeStdDevM)
+	s.mu.Unlock()
+
+	return toGeo(st.position.Add(offset), s.traj.origin()), altitude, nil
+}
+
+// LinearVelocity returns the noisy body frame linear velocity of the simulated sensor.
+func (s *simulated) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	st, err := s.sample()
+	if err != nil {
+		return r3.Vector{}, err
+	}
+	return s.noisyVector(st.velocity, s.noise.LinearVelocityStdDevMPerSec), nil
+}
+
+// AngularVelocity returns the noisy angular velocity of the simulated sensor.
+func (s *simulated) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	if !s.supportsIMU() {
+		return spatialmath.AngularVelocity{}, movementsensor.ErrMethodUnimplementedAngularVelocity
+	}
+	st, err := s.sample()
+	if err != nil {
+		return spatialmath.AngularVelocity{}, err
+	}
+	v := s.noisyVector(r3.Vector{Z: st.yawRate}, s.noise.AngularVelocityStdDevDegsPerSec)
+	return spatialmath.AngularVelocity{X: v.X, Y: v.Y, Z: v.Z}, nil
+}
+
+// LinearAcceleration returns the noisy body frame linear acceleration, excluding gravity, of the simulated sensor.
+func (s *simulated) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	if !s.supportsIMU() {
+		return r3.Vector{}, movementsensor.ErrMethodUnimplementedLinearAcceleration
+	}
+	st, err := s.sample()
+	if err != nil {
+		return r3.Vector{}, err
+	}
+	return s.noisyVector(st.acceleration, s.noise.LinearAccelerationStdDevMPerSecPerSec), nil
+}
+
+// CompassHeading returns the noisy compass heading of the simulated sensor.
+func (s *simulated) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	st, err := s.sample()
+	if err != nil {
+		return 0, err
+	}
+	return s.heading(st), nil
+}
+
+// Orientation returns the noisy orientation of the simulated sensor, which is level and faces its heading.
+func (s *simulated) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	if !s.supportsIMU() {
+		return nil, movementsensor.ErrMethodUnimplementedOrientation
+	}
+	st, err := s.sample()
+	if err != nil {
+		return nil, err
+	}
+	// CompassHeading is a left-handed value. Convert to be right-handed.
+	return &spatialmath.OrientationVectorDegrees{OZ: 1, Theta: normalizeDegrees(360 - s.heading(st))}, nil
+}
+
+// Accuracy reports the configured noise of the simulated sensor.
+func (s *simulated) Accuracy(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+	fix := int32(4)
+	if s.noise.PositionStdDevM >= 0.1 {
+		fix = 1
+	}
+	return &movementsensor.Accuracy{
+		AccuracyMap:        map[string]float32{},
+		Hdop:               float32(s.noise.PositionStdDevM),
+		Vdop:               float32(s.noise.AltitudeStdDevM),
+		NmeaFix:            fix,
+		CompassDegreeError: float32(s.noise.HeadingStdDevDegs),
+	}, nil
+}
+
+// Readings gets the readings of the simulated sensor.
+func (s *simulated) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return movementsensor.DefaultAPIReadings(ctx, s, extra)
+}
+
+// Properties returns the properties of the simulated sensor, which depend on its sensor type.
+func (s *simulated) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	return &movementsensor.Properties{
+		PositionSupported:           s.supportsGPS(),
+		LinearVelocitySupported:     true,
+		CompassHeadingSupported:     true,
+		OrientationSupported:        s.supportsIMU(),
+		AngularVelocitySupported:    s.supportsIMU(),
+		LinearAccelerationSupported: s.supportsIMU(),
+	}, nil
+}
+
+// normalizeDegrees returns an equivalent angle in the domain [0,360).
+func normalizeDegrees(degrees float64) float64 {
+	normalized := math.Mod(degrees, 360)
+	if normalized < 0 {
+		normalized += 360
+	}
+	return normalized
+}
+
+// wrapDegrees returns an equivalent angle in the domain [-180,180).
+func wrapDegrees(degrees float64) float64 {
+	return normalizeDegrees(degrees+180) - 180
+}
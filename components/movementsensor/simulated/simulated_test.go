@@ -0,0 +1,248 @@
+package simulated
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	geo "github.com/kellydunn/golang-geo"
+	commonpb "go.viam.com/api/common/v1"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/spatialmath"
+)
+
+var testOrigin = &commonpb.GeoPoint{Latitude: 40.7, Longitude: -73.98}
+
+func newTestSensor(t *testing.T, conf *Config) (*simulated, *clock.Mock) {
+	t.Helper()
+	clk := clock.NewMock()
+	s, err := newSimulatedWithClock(movementsensor.Named("sim"), conf, clk, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return s, clk
+}
+
+func TestValidateConfig(t *testing.T) {
+	_, err := (&Config{}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "trajectory")
+
+	_, err = (&Config{SensorType: "lidar", Trajectory: &TrajectoryConfig{Origin: testOrigin}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "sensor_type")
+
+	_, err = (&Config{Trajectory: &TrajectoryConfig{Type: trajectoryCircle, Origin: testOrigin, RadiusM: 10}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "speed_m_per_sec")
+
+	_, err = (&Config{
+		Trajectory: &TrajectoryConfig{Origin: testOrigin},
+		Noise:      NoiseConfig{DropoutProbability: 1.5},
+	}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "dropout_probability")
+
+	_, err = (&Config{Trajectory: &TrajectoryConfig{Origin: testOrigin}, Noise: NoiseConfig{PositionStdDevM: -1}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = (&Config{
+		SensorType: sensorTypeGPS,
+		Trajectory: &TrajectoryConfig{Type: trajectoryWaypoints, Waypoints: []*commonpb.GeoPoint{testOrigin, testOrigin}, SpeedMPerSec: 1},
+	}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+}
+
+func TestCircle(t *testing.T) {
+	ctx := context.Background()
+	s, clk := newTestSensor(t, &Config{
+		Trajectory: &TrajectoryConfig{Type: trajectoryCircle, Origin: testOrigin, RadiusM: 10, SpeedMPerSec: 2},
+	})
+	origin := geo.NewPoint(testOrigin.Latitude, testOrigin.Longitude)
+
+	pos, _, err := s.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, origin.GreatCircleDistance(pos)*1000, test.ShouldAlmostEqual, 10, 0.01)
+	// starting due east and travelling counterclockwise means heading due north
+	heading, err := s.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldAlmostEqual, 0, 0.01)
+
+	vel, err := s.LinearVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, vel.Y, test.ShouldAlmostEqual, 2, 0.001)
+
+	angVel, err := s.AngularVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, angVel.Z, test.ShouldAlmostEqual, 11.459, 0.01)
+
+	// centripetal acceleration points to the left of travel, towards the center
+	acc, err := s.LinearAcceleration(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, acc.X, test.ShouldAlmostEqual, -0.4, 0.001)
+	test.That(t, acc.Y, test.ShouldAlmostEqual, 0, 0.001)
+
+	// a quarter of the way around the circle is due north of the center, heading west
+	quarterSec := math.Pi * 10 / 2 / 2
+	clk.Add(time.Duration(quarterSec * float64(time.Second)))
+	pos, _, err = s.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, origin.BearingTo(pos), test.ShouldAlmostEqual, 0, 0.1)
+	heading, err = s.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldAlmostEqual, 270, 0.01)
+
+	o, err := s.Orientation(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, o.OrientationVectorDegrees().Theta, test.ShouldAlmostEqual, 90, 0.01)
+}
+
+func TestWaypoints(t *testing.T) {
+	ctx := context.Background()
+	origin := geo.NewPoint(testOrigin.Latitude, testOrigin.Longitude)
+	north := origin.PointAtDistanceAndBearing(0.1, 0)
+	s, clk := newTestSensor(t, &Config{
+		SensorType: sensorTypeGPS,
+		Trajectory: &TrajectoryConfig{
+			Type:         trajectoryWaypoints,
+			Waypoints:    []*commonpb.GeoPoint{testOrigin, {Latitude: north.Lat(), Longitude: north.Lng()}},
+			SpeedMPerSec: 10,
+			Loop:         true,
+		},
+	})
+
+	clk.Add(5 * time.Second)
+	pos, _, err := s.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, origin.GreatCircleDistance(pos)*1000, test.ShouldAlmostEqual, 50, 0.1)
+	heading, err := s.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldAlmostEqual, 0, 0.01)
+
+	// on the way back to the first waypoint
+	clk.Add(10 * time.Second)
+	pos, _, err = s.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, origin.GreatCircleDistance(pos)*1000, test.ShouldAlmostEqual, 50, 0.1)
+	heading, err = s.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldAlmostEqual, 180, 0.01)
+
+	_, err = s.Orientation(ctx, nil)
+	test.That(t, err, test.ShouldBeError, movementsensor.ErrMethodUnimplementedOrientation)
+	props, err := s.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.PositionSupported, test.ShouldBeTrue)
+	test.That(t, props.OrientationSupported, test.ShouldBeFalse)
+}
+
+func TestFileTrajectory(t *testing.T) {
+	traj, err := newFileTrajectory(strings.NewReader("# t,lat,lng,alt\n0,40.7,-73.98,10\n10,40.7001,-73.98,20\n"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, traj.origin().Lat(), test.ShouldEqual, 40.7)
+	mid := traj.positionAt(5)
+	test.That(t, mid.X, test.ShouldAlmostEqual, 0, 0.001)
+	test.That(t, mid.Y, test.ShouldAlmostEqual, 5.56, 0.01)
+	test.That(t, mid.Z, test.ShouldAlmostEqual, 15)
+	test.That(t, traj.positionAt(20), test.ShouldResemble, traj.positionAt(10))
+
+	_, err = newFileTrajectory(strings.NewReader("0,40.7,-73.98\n0,40.7001,-73.98\n"))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "ascending")
+
+	_, err = newFileTrajectory(strings.NewReader("0,40.7\n"))
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = newFileTrajectory(strings.NewReader(""))
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestNoise(t *testing.T) {
+	ctx := context.Background()
+	conf := &Config{
+		Trajectory: &TrajectoryConfig{Origin: testOrigin, HeadingDegs: 90},
+		Noise:      NoiseConfig{PositionStdDevM: 1, HeadingStdDevDegs: 1, Seed: 3},
+	}
+	s1, _ := newTestSensor(t, conf)
+	s2, _ := newTestSensor(t, conf)
+	origin := geo.NewPoint(testOrigin.Latitude, testOrigin.Longitude)
+
+	var sumSq float64
+	for i := 0; i < 100; i++ {
+		pos1, _, err := s1.Position(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		pos2, _, err := s2.Position(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pos1, test.ShouldResemble, pos2)
+		dist := origin.GreatCircleDistance(pos1) * 1000
+		sumSq += dist * dist
+	}
+	// the distance from the true position of 2D gaussian noise has a mean square of 2 sigma^2
+	test.That(t, sumSq/100, test.ShouldAlmostEqual, 2, 0.75)
+
+	heading, err := s1.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldAlmostEqual, 90, 5)
+
+	acc, err := s1.Accuracy(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, acc.Hdop, test.ShouldEqual, 1)
+	test.That(t, acc.CompassDegreeError, test.ShouldEqual, 1)
+}
+
+func TestDrift(t *testing.T) {
+	ctx := context.Background()
+	s, clk := newTestSensor(t, &Config{
+		Trajectory: &TrajectoryConfig{Origin: testOrigin},
+		Noise:      NoiseConfig{HeadingDriftDegsPerSec: 0.5, PositionRandomWalkMPerSqrtSec: 1},
+	})
+	clk.Add(10 * time.Second)
+	heading, err := s.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldAlmostEqual, 5)
+
+	pos, _, err := s.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos.Lat(), test.ShouldNotEqual, testOrigin.Latitude)
+	test.That(t, s.positionBias.Norm(), test.ShouldBeGreaterThan, 0)
+}
+
+func TestDropout(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestSensor(t, &Config{
+		Trajectory: &TrajectoryConfig{Origin: testOrigin},
+		Noise:      NoiseConfig{DropoutProbability: 1},
+	})
+	_, _, err := s.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeError, ErrSimulatedDropout)
+	_, err = s.Orientation(ctx, nil)
+	test.That(t, err, test.ShouldBeError, ErrSimulatedDropout)
+
+	s, _ = newTestSensor(t, &Config{
+		Trajectory: &TrajectoryConfig{Origin: testOrigin},
+		Noise:      NoiseConfig{DropoutProbability: 0.5, Seed: 1},
+	})
+	var dropped int
+	for i := 0; i < 200; i++ {
+		if _, err := s.CompassHeading(ctx, nil); err != nil {
+			dropped++
+		}
+	}
+	test.That(t, dropped, test.ShouldBeBetween, 70, 130)
+}
+
+func TestStationaryOrientation(t *testing.T) {
+	s, _ := newTestSensor(t, &Config{
+		SensorType: sensorTypeIMU,
+		Trajectory: &TrajectoryConfig{Origin: testOrigin, HeadingDegs: 30},
+	})
+	o, err := s.Orientation(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.OrientationAlmostEqual(o, &spatialmath.OrientationVectorDegrees{OZ: 1, Theta: 330}), test.ShouldBeTrue)
+	_, _, err = s.Position(context.Background(), nil)
+	test.That(t, err, test.ShouldBeError, movementsensor.ErrMethodUnimplementedPosition)
+}
@@ -0,0 +1,246 @@
+package simulated
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	commonpb "go.viam.com/api/common/v1"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+// Supported trajectory types.
+const (
+	trajectoryStationary = "stationary"
+	trajectoryCircle     = "circle"
+	trajectoryWaypoints  = "waypoints"
+	trajectoryFile       = "file"
+)
+
+// TrajectoryConfig describes the path the simulated sensor travels along.
+//
+// A circle is centered on the origin. A waypoint path starts at the first waypoint and visits the rest in order at a
+// constant speed, returning to the first waypoint if looping. A file is a CSV of `seconds,latitude,longitude[,altitude_m]`
+// rows with ascending timestamps which are linearly interpolated.
+type TrajectoryConfig struct {
+	Type         string               `json:"type"`
+	Origin       *commonpb.GeoPoint   `json:"origin,omitempty"`
+	AltitudeM    float64              `json:"altitude_m,omitempty"`
+	RadiusM      float64              `json:"radius_m,omitempty"`
+	SpeedMPerSec float64              `json:"speed_m_per_sec,omitempty"`
+	Clockwise    bool                 `json:"clockwise,omitempty"`
+	Waypoints    []*commonpb.GeoPoint `json:"waypoints,omitempty"`
+	Loop         bool                 `json:"loop,omitempty"`
+	FilePath     string               `json:"file_path,omitempty"`
+	HeadingDegs  float64              `json:"heading_degs,omitempty"`
+}
+
+// Validate ensures all parts of the trajectory config are valid.
+func (cfg *TrajectoryConfig) Validate(path string) error {
+	switch cfg.Type {
+	case "", trajectoryStationary:
+		if cfg.Origin == nil {
+			return errors.Errorf("%s: origin is required for a %s trajectory", path, trajectoryStationary)
+		}
+	case trajectoryCircle:
+		if cfg.Origin == nil {
+			return errors.Errorf("%s: origin is required for a %s trajectory", path, trajectoryCircle)
+		}
+		if cfg.RadiusM <= 0 {
+			return errors.Errorf("%s: radius_m must be positive for a %s trajectory", path, trajectoryCircle)
+		}
+		if cfg.SpeedMPerSec <= 0 {
+			return errors.Errorf("%s: speed_m_per_sec must be positive for a %s trajectory", path, trajectoryCircle)
+		}
+	case trajectoryWaypoints:
+		if len(cfg.Waypoints) < 2 {
+			return errors.Errorf("%s: at least 2 waypoints are required for a %s trajectory", path, trajectoryWaypoints)
+		}
+		if cfg.SpeedMPerSec <= 0 {
+			return errors.Errorf("%s: speed_m_per_sec must be positive for a %s trajectory", path, trajectoryWaypoints)
+		}
+	case trajectoryFile:
+		if cfg.FilePath == "" {
+			return errors.Errorf("%s: file_path is required for a %s trajectory", path, trajectoryFile)
+		}
+	default:
+		return errors.Errorf("%s: unknown trajectory type %q", path, cfg.Type)
+	}
+	return nil
+}
+
+// trajectory is a path through a local east-north-up frame, in meters, as a function of time in seconds.
+type trajectory interface {
+	// origin is the geo point which corresponds to the origin of the local frame.
+	origin() *geo.Point
+	positionAt(t float64) r3.Vector
+}
+
+func newTrajectory(cfg *TrajectoryConfig) (trajectory, error) {
+	switch cfg.Type {
+	case "", trajectoryStationary:
+		return &stationaryTrajectory{
+			geoOrigin: geo.NewPoint(cfg.Origin.Latitude, cfg.Origin.Longitude),
+			altitude:  cfg.AltitudeM,
+		}, nil
+	case trajectoryCircle:
+		direction := 1.
+		if cfg.Clockwise {
+			direction = -1
+		}
+		return &circleTrajectory{
+			geoOrigin:      geo.NewPoint(cfg.Origin.Latitude, cfg.Origin.Longitude),
+			altitude:       cfg.AltitudeM,
+			radius:         cfg.RadiusM,
+			angularRadsSec: direction * cfg.SpeedMPerSec / cfg.RadiusM,
+		}, nil
+	case trajectoryWaypoints:
+		return newWaypointTrajectory(cfg)
+	case trajectoryFile:
+		f, err := os.Open(cfg.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		//nolint:errcheck
+		defer f.Close()
+		return newFileTrajectory(f)
+	default:
+		return nil, errors.Errorf("unknown trajectory type %q", cfg.Type)
+	}
+}
+
+type stationaryTrajectory struct {
+	geoOrigin *geo.Point
+	altitude  float64
+}
+
+func (st *stationaryTrajectory) origin() *geo.Point { return st.geoOrigin }
+
+func (st *stationaryTrajectory) positionAt(t float64) r3.Vector { return r3.Vector{Z: st.altitude} }
+
+// circleTrajectory starts due east of its center and travels counterclockwise for a positive angular velocity.
+type circleTrajectory struct {
+	geoOrigin      *geo.Point
+	altitude       float64
+	radius         float64
+	angularRadsSec float64
+}
+
+func (ct *circleTrajectory) origin() *geo.Point { return ct.geoOrigin }
+
+func (ct *circleTrajectory) positionAt(t float64) r3.Vector {
+	theta := ct.angularRadsSec * t
+	return r3.Vector{X: ct.radius * math.Cos(theta), Y: ct.radius * math.Sin(theta), Z: ct.altitude}
+}
+
+// polylineTrajectory linearly interpolates between timestamped points, holding the final point once it is reached
+// unless it loops.
+type polylineTrajectory struct {
+	geoOrigin *geo.Point
+	times     []float64
+	points    []r3.Vector
+	loop      bool
+}
+
+func (pt *polylineTrajectory) origin() *geo.Point { return pt.geoOrigin }
+
+func (pt *polylineTrajectory) positionAt(t float64) r3.Vector {
+	last := len(pt.times) - 1
+	duration := pt.times[last] - pt.times[0]
+	if pt.loop && duration > 0 {
+		t = pt.times[0] + math.Mod(t-pt.times[0], duration)
+		if t < pt.times[0] {
+			t += duration
+		}
+	}
+	if t <= pt.times[0] {
+		return pt.points[0]
+	}
+	if t >= pt.times[last] {
+		return pt.points[last]
+	}
+	i := sort.SearchFloat64s(pt.times, t)
+	frac := (t - pt.times[i-1]) / (pt.times[i] - pt.times[i-1])
+	return pt.points[i-1].Add(pt.points[i].Sub(pt.points[i-1]).Mul(frac))
+}
+
+func newWaypointTrajectory(cfg *TrajectoryConfig) (trajectory, error) {
+	geoOrigin := geo.NewPoint(cfg.Waypoints[0].Latitude, cfg.Waypoints[0].Longitude)
+	waypoints := cfg.Waypoints
+	if cfg.Loop {
+		waypoints = append(waypoints, cfg.Waypoints[0])
+	}
+	pt := &polylineTrajectory{geoOrigin: geoOrigin, loop: cfg.Loop}
+	elapsed := 0.
+	for i, wp := range waypoints {
+		point := toLocal(geo.NewPoint(wp.Latitude, wp.Longitude), geoOrigin)
+		point.Z = cfg.AltitudeM
+		if i > 0 {
+			elapsed += point.Sub(pt.points[i-1]).Norm() / cfg.SpeedMPerSec
+		}
+		pt.times = append(pt.times, elapsed)
+		pt.points = append(pt.points, point)
+	}
+	return pt, nil
+}
+
+func newFileTrajectory(r io.Reader) (trajectory, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	pt := &polylineTrajectory{}
+	for i, record := range records {
+		if len(record) < 3 {
+			return nil, errors.Errorf("trajectory file row %d must contain at least seconds, latitude and longitude", i)
+		}
+		values := make([]float64, 0, len(record))
+		for _, field := range record {
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "trajectory file row %d", i)
+			}
+			values = append(values, v)
+		}
+		if i > 0 && values[0] <= pt.times[i-1] {
+			return nil, errors.Errorf("trajectory file row %d timestamps must be ascending", i)
+		}
+		gp := geo.NewPoint(values[1], values[2])
+		if pt.geoOrigin == nil {
+			pt.geoOrigin = gp
+		}
+		point := toLocal(gp, pt.geoOrigin)
+		if len(values) > 3 {
+			point.Z = values[3]
+		}
+		pt.times = append(pt.times, values[0])
+		pt.points = append(pt.points, point)
+	}
+	if len(pt.points) == 0 {
+		return nil, errors.New("trajectory file contains no points")
+	}
+	return pt, nil
+}
+
+// toLocal converts a geo point into meters east and north of the origin.
+func toLocal(gp, origin *geo.Point) r3.Vector {
+	return spatialmath.GeoPointToPoint(gp, origin).Mul(1e-3)
+}
+
+// toGeo converts a point in meters east and north of the origin into a geo point.
+func toGeo(local r3.Vector, origin *geo.Point) *geo.Point {
+	local.Z = 0
+	return spatialmath.PoseToGeoPose(
+		spatialmath.NewGeoPose(origin, 0), spatialmath.NewPoseFromPoint(local.Mul(1e3)),
+	).Location()
+}
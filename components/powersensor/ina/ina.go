@@ -16,12 +16,18 @@
 // The calibration register is programmed to measure current and power properly.
 // The calibration register is set to: calibratescale / (currentLSB * senseResistor)
 
+// The INA226 can raise an alert on one limit at a time, so either an over current or an under voltage
+// alert can be configured. An over current limit is programmed as the shunt voltage limit, in 2.5 uV
+// steps, and an under voltage limit as the bus voltage limit, in 1.25 mV steps. The alert flag is
+// reported in the readings as over_current_alert or under_voltage_alert.
+
 package ina
 
 import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"sync"
 
@@ -44,6 +50,16 @@ const (
 	powerRegister        = 0x03
 	currentRegister      = 0x04
 	calibrationRegister  = 0x05
+	maskEnableRegister   = 0x06
+	alertLimitRegister   = 0x07
+
+	// functions of the mask/enable register of the ina226
+	shuntOverLimit  = 1 << 15
+	busUnderLimit   = 1 << 12
+	alertFlag       = 1 << 4
+	shuntVoltageLSB = 2.5e-6  // volts
+	busVoltageLSB   = 1.25e-3 // volts
+	maxAlertLimit   = 0x7FFF
 )
 
 // values for inas in nano units so need to convert.
@@ -67,6 +83,9 @@ type Config struct {
 	I2cAddr         int     `json:"i2c_addr,omitempty"`
 	MaxCurrent      float64 `json:"max_current_amps,omitempty"`
 	ShuntResistance float64 `json:"shunt_resistance,omitempty"`
+	// Only one of the alerts can be set, and only on the ina226.
+	OverCurrentAlertAmps   float64 `json:"over_current_alert_amps,omitempty"`
+	UnderVoltageAlertVolts float64 `json:"under_voltage_alert_volts,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -80,6 +99,13 @@ func (conf *Config) Validate(path string) ([]string, error) {
 	if _, err := strconv.Atoi(conf.I2CBus); err != nil {
 		return nil, fmt.Errorf("i2c_bus must be numeric, not '%s': %w", conf.I2CBus, err)
 	}
+	if conf.OverCurrentAlertAmps < 0 || conf.UnderVoltageAlertVolts < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("alert limits cannot be negative"))
+	}
+	if conf.OverCurrentAlertAmps > 0 && conf.UnderVoltageAlertVolts > 0 {
+		return nil, resource.NewConfigValidationError(path,
+			errors.New("only one of over_current_alert_amps and under_voltage_alert_volts can be set"))
+	}
 	return deps, nil
 }
 
@@ -147,14 +173,35 @@ func newINA(
 		return nil, fmt.Errorf("non-numeric I2C bus number '%s': %w", conf.I2CBus, err)
 	}
 
+	var alertMask, alertLimit uint16
+	var alertReading string
+	if conf.OverCurrentAlertAmps > 0 || conf.UnderVoltageAlertVolts > 0 {
+		if modelName != modelName226 {
+			return nil, fmt.Errorf("alerts are only supported by the %s", modelName226)
+		}
+		limit := conf.UnderVoltageAlertVolts / busVoltageLSB
+		alertMask, alertReading = busUnderLimit, "under_voltage_alert"
+		if conf.OverCurrentAlertAmps > 0 {
+			limit = conf.OverCurrentAlertAmps * fromNano(float64(resistance)) / shuntVoltageLSB
+			alertMask, alertReading = shuntOverLimit, "over_current_alert"
+		}
+		if limit > maxAlertLimit {
+			return nil, fmt.Errorf("alert limit is above what the %s can measure", modelName226)
+		}
+		alertLimit = uint16(math.Round(limit))
+	}
+
 	s := &ina{
-		Named:      name.AsNamed(),
-		logger:     logger,
-		model:      modelName,
-		bus:        busNumber,
-		addr:       byte(addr),
-		maxCurrent: maxCurrent,
-		resistance: resistance,
+		Named:        name.AsNamed(),
+		logger:       logger,
+		model:        modelName,
+		bus:          busNumber,
+		addr:         byte(addr),
+		maxCurrent:   maxCurrent,
+		resistance:   resistance,
+		alertMask:    alertMask,
+		alertLimit:   alertLimit,
+		alertReading: alertReading,
 	}
 
 	err = s.setCalibrationScale(modelName)
@@ -187,6 +234,10 @@ type ina struct {
 	cal        uint16
 	maxCurrent int64
 	resistance int64
+	// alertMask selects the function of the alert of the ina226, with none when 0.
+	alertMask    uint16
+	alertLimit   uint16
+	alertReading string
 }
 
 func (d *ina) setCalibrationScale(modelName string) error {
@@ -235,6 +286,17 @@ func (d *ina) calibrate() error {
 	if err != nil {
 		return err
 	}
+
+	if d.alertMask != 0 {
+		err = handle.WriteRegU16BE(alertLimitRegister, d.alertLimit)
+		if err != nil {
+			return err
+		}
+		err = handle.WriteRegU16BE(maskEnableRegister, d.alertMask)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -336,12 +398,38 @@ func (d *ina) Readings(ctx context.Context, extra map[string]interface{}) (map[s
 	if err != nil {
 		d.logger.CErrorf(ctx, "failed to get power reading: %s", err.Error())
 	}
-	return map[string]interface{}{
+	readings := map[string]interface{}{
 		"volts": volts,
 		"amps":  amps,
 		"is_ac": isAC,
 		"watts": watts,
-	}, nil
+	}
+	if d.alertMask != 0 {
+		alert, err := d.alert(ctx)
+		if err != nil {
+			d.logger.CErrorf(ctx, "failed to get alert reading: %s", err.Error())
+		}
+		readings[d.alertReading] = alert
+	}
+	return readings, nil
+}
+
+// alert returns whether the alert function of the ina226 has been triggered.
+func (d *ina) alert(ctx context.Context) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	handle, err := i2c.NewI2C(d.addr, d.bus)
+	if err != nil {
+		d.logger.CErrorf(ctx, "can't open ina i2c handle: %s", err)
+		return false, err
+	}
+	defer utils.UncheckedErrorFunc(handle.Close)
+
+	mask, err := handle.ReadRegU16BE(maskEnableRegister)
+	if err != nil {
+		return false, err
+	}
+	return mask&alertFlag != 0, nil
 }
 
 func toNano(value float64) int64 {
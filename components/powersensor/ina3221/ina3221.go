@@ -0,0 +1,381 @@
+//go:build linux
+
+// Package ina3221 implements a power sensor for the TI INA3221, which measures the bus voltage and the shunt
+// voltage of three channels. A datasheet for this chip is at https://www.ti.com/lit/ds/symlink/ina3221.pdf
+//
+// Each configured channel is reported under its name, as <name>_volts, <name>_amps and <name>_watts, and
+// Voltage, Current and Power report the first configured channel.
+//
+// The chip raises an alert on its own when the current through a channel is above its over_current_alert_amps,
+// which is programmed as the critical limit of the channel and is reported as <name>_over_current_alert. When
+// under_voltage_alert_volts is set, the power valid limits of the chip are programmed so that it reports
+// under_voltage_alert once the bus voltage of any channel drops below it. The power valid function watches all
+// three channels, so the bus of an unused channel should be tied to a monitored rail when it is in use.
+package ina3221
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/components/powersensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var model = resource.DefaultModelFamily.WithModel("ina3221")
+
+const (
+	defaultI2CAddr         = 0x40
+	defaultShuntResistance = 0.1 // ohms
+	numChannels            = 3
+
+	configRegister          = 0x00
+	shuntVoltageRegister    = 0x01 // of channel 1, each following channel is two registers on
+	busVoltageRegister      = 0x02 // of channel 1, each following channel is two registers on
+	criticalLimitRegister   = 0x07 // of channel 1, each following channel is two registers on
+	maskEnableRegister      = 0x0F
+	powerValidUpperRegister = 0x10
+	powerValidLowerRegister = 0x11
+	manufacturerIDRegister  = 0xFE
+	dieIDRegister           = 0xFF
+
+	expectedManufacturerID = 0x5449 // "TI"
+	expectedDieID          = 0x3220
+
+	// the default configuration: all channels enabled, no averaging, 1.1ms conversions, continuous shunt and bus
+	// voltage measurements. Bits 14 to 12 enable channels 1 to 3.
+	defaultConfig     = 0x7127
+	channelEnableBits = 0x7000
+
+	// the critical alert flag of channel 1 in the mask/enable register, each following channel is a bit lower
+	criticalFlagChannel1 = 1 << 9
+	powerValidFlag       = 1 << 2
+
+	// voltages are the top 13 bits of their registers
+	shuntVoltageLSB = 40e-6 // volts
+	busVoltageLSB   = 8e-3  // volts
+	maxLimit        = 0x0FFF
+
+	// powerValidHysteresis is how far the bus voltages have to rise above the under voltage alert for the power to
+	// become valid again, so that the alert does not flicker around the limit.
+	powerValidHysteresis = 0.25 // volts
+)
+
+// ChannelConfig is the configuration of one channel of the chip.
+type ChannelConfig struct {
+	// Channel is the number of the channel, from 1 to 3.
+	Channel int `json:"channel"`
+	// Name is the prefix of the readings of the channel, channel_<number> by default.
+	Name            string  `json:"name,omitempty"`
+	ShuntResistance float64 `json:"shunt_resistance,omitempty"`
+	// OverCurrentAlertAmps is the current above which the channel raises an alert, with none by default.
+	OverCurrentAlertAmps float64 `json:"over_current_alert_amps,omitempty"`
+}
+
+// Config is used for converting config attributes.
+type Config struct {
+	I2CBus   string          `json:"i2c_bus"`
+	I2CAddr  int             `json:"i2c_addr,omitempty"`
+	Channels []ChannelConfig `json:"channels"`
+	// UnderVoltageAlertVolts is the bus voltage below which the chip raises an alert, with none by default.
+	UnderVoltageAlertVolts float64 `json:"under_voltage_alert_volts,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.I2CBus == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "i2c_bus")
+	}
+	if len(conf.Channels) == 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "channels")
+	}
+	channels := map[int]bool{}
+	names := map[string]bool{}
+	for _, c := range conf.Channels {
+		if c.Channel < 1 || c.Channel > numChannels {
+			return nil, resource.NewConfigValidationError(path, errors.Errorf("channel must be 1, 2 or 3, not %d", c.Channel))
+		}
+		if channels[c.Channel] {
+			return nil, resource.NewConfigValidationError(path, errors.Errorf("channel %d is configured more than once", c.Channel))
+		}
+		channels[c.Channel] = true
+		name := c.name()
+		if names[name] {
+			return nil, resource.NewConfigValidationError(path, errors.Errorf("channel name %q is used more than once", name))
+		}
+		names[name] = true
+		if c.ShuntResistance < 0 {
+			return nil, resource.NewConfigValidationError(path, errors.New("shunt_resistance cannot be negative"))
+		}
+		if c.OverCurrentAlertAmps < 0 {
+			return nil, resource.NewConfigValidationError(path, errors.New("over_current_alert_amps cannot be negative"))
+		}
+		if limit := c.OverCurrentAlertAmps * c.shuntResistance(); limit/shuntVoltageLSB > maxLimit {
+			return nil, resource.NewConfigValidationError(path, errors.Errorf(
+				"over_current_alert_amps of channel %d is above the %.3gA the chip can measure across its shunt",
+				c.Channel, maxLimit*shuntVoltageLSB/c.shuntResistance()))
+		}
+	}
+	if conf.UnderVoltageAlertVolts < 0 || (conf.UnderVoltageAlertVolts+powerValidHysteresis)/busVoltageLSB > maxLimit {
+		return nil, resource.NewConfigValidationError(path, errors.New("under_voltage_alert_volts must be between 0 and 32.5"))
+	}
+	return nil, nil
+}
+
+func (c ChannelConfig) name() string {
+	if c.Name == "" {
+		return fmt.Sprintf("channel_%d", c.Channel)
+	}
+	return c.Name
+}
+
+func (c ChannelConfig) shuntResistance() float64 {
+	if c.ShuntResistance == 0 {
+		return defaultShuntResistance
+	}
+	return c.ShuntResistance
+}
+
+func init() {
+	resource.RegisterComponent(
+		powersensor.API,
+		model,
+		resource.Registration[powersensor.PowerSensor, *Config]{
+			Constructor: newINA3221,
+		})
+}
+
+func newINA3221(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (powersensor.PowerSensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	bus, err := buses.NewI2cBus(newConf.I2CBus)
+	if err != nil {
+		return nil, err
+	}
+	return makeINA3221(ctx, conf.ResourceName(), newConf, logger, bus)
+}
+
+// This function is separated from newINA3221 solely so you can inject a mock I2C bus in tests.
+func makeINA3221(
+	ctx context.Context,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+	bus buses.I2C,
+) (powersensor.PowerSensor, error) {
+	addr := conf.I2CAddr
+	if addr == 0 {
+		addr = defaultI2CAddr
+	}
+	s := &ina3221{
+		Named:             name.AsNamed(),
+		logger:            logger,
+		bus:               bus,
+		addr:              byte(addr),
+		underVoltageAlert: conf.UnderVoltageAlertVolts > 0,
+	}
+	for _, c := range conf.Channels {
+		s.channels = append(s.channels, channel{
+			number:           c.Channel,
+			name:             c.name(),
+			shuntResistance:  c.shuntResistance(),
+			overCurrentAlert: c.OverCurrentAlertAmps > 0,
+		})
+	}
+
+	manufacturerID, err := s.readRegister(ctx, manufacturerIDRegister)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't read from I2C address %d on bus %s", addr, conf.I2CBus)
+	}
+	dieID, err := s.readRegister(ctx, dieIDRegister)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't read from I2C address %d on bus %s", addr, conf.I2CBus)
+	}
+	if manufacturerID != expectedManufacturerID || dieID != expectedDieID {
+		return nil, unexpectedDeviceError(byte(addr), manufacturerID, dieID)
+	}
+
+	config := uint16(defaultConfig &^ channelEnableBits)
+	for _, c := range s.channels {
+		config |= 1 << (15 - c.number)
+	}
+	if err := s.writeRegister(ctx, configRegister, config); err != nil {
+		return nil, errors.Wrap(err, "unable to configure INA3221")
+	}
+	for i, c := range s.channels {
+		// the critical limit of a channel with no alert is left at its maximum, where it never triggers
+		limit := uint16(maxLimit << 3)
+		if c.overCurrentAlert {
+			limit = toLimit(conf.Channels[i].OverCurrentAlertAmps*c.shuntResistance, shuntVoltageLSB)
+		}
+		if err := s.writeRegister(ctx, criticalLimitRegister+byte(2*(c.number-1)), limit); err != nil {
+			return nil, errors.Wrapf(err, "unable to set the over current alert of channel %d", c.number)
+		}
+	}
+	if s.underVoltageAlert {
+		for _, w := range []struct {
+			register byte
+			volts    float64
+		}{
+			{powerValidUpperRegister, conf.UnderVoltageAlertVolts + powerValidHysteresis},
+			{powerValidLowerRegister, conf.UnderVoltageAlertVolts},
+		} {
+			if err := s.writeRegister(ctx, w.register, toLimit(w.volts, busVoltageLSB)); err != nil {
+				return nil, errors.Wrap(err, "unable to set the under voltage alert")
+			}
+		}
+	}
+	return s, nil
+}
+
+func unexpectedDeviceError(address byte, manufacturerID, dieID uint16) error {
+	return errors.Errorf("unexpected non-INA3221 device at address %d: manufacturer ID %#x, die ID %#x", address, manufacturerID, dieID)
+}
+
+// toLimit converts a limit to the top 13 bits of a limit register.
+func toLimit(value, lsb float64) uint16 {
+	return uint16(math.Min(math.Round(value/lsb), maxLimit)) << 3
+}
+
+type channel struct {
+	number           int
+	name             string
+	shuntResistance  float64
+	overCurrentAlert bool
+}
+
+type measurement struct {
+	volts, amps float64
+}
+
+// ina3221 is an i2c sensor device that reports the voltage, current and power of up to three channels.
+type ina3221 struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+
+	// mu makes the reads of the registers of a channel atomic with respect to each other.
+	mu sync.Mutex
+
+	logger            logging.Logger
+	bus               buses.I2C
+	addr              byte
+	channels          []channel
+	underVoltageAlert bool
+}
+
+func (d *ina3221) measure(ctx context.Context, c channel) (measurement, error) {
+	shunt, err := d.readRegister(ctx, shuntVoltageRegister+byte(2*(c.number-1)))
+	if err != nil {
+		return measurement{}, err
+	}
+	bus, err := d.readRegister(ctx, busVoltageRegister+byte(2*(c.number-1)))
+	if err != nil {
+		return measurement{}, err
+	}
+	return measurement{
+		volts: float64(int16(bus)>>3) * busVoltageLSB,
+		amps:  float64(int16(shunt)>>3) * shuntVoltageLSB / c.shuntResistance,
+	}, nil
+}
+
+func (d *ina3221) measureFirst(ctx context.Context) (measurement, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.measure(ctx, d.channels[0])
+}
+
+// Voltage returns the bus voltage of the first configured channel.
+func (d *ina3221) Voltage(ctx context.Context, extra map[string]interface{}) (float64, bool, error) {
+	m, err := d.measureFirst(ctx)
+	return m.volts, false, err
+}
+
+// Current returns the current through the first configured channel.
+func (d *ina3221) Current(ctx context.Context, extra map[string]interface{}) (float64, bool, error) {
+	m, err := d.measureFirst(ctx)
+	return m.amps, false, err
+}
+
+// Power returns the power through the first configured channel.
+func (d *ina3221) Power(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	m, err := d.measureFirst(ctx)
+	return m.volts * m.amps, err
+}
+
+// Readings returns the voltage, current and power of each channel, and its alerts.
+func (d *ina3221) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	readings := map[string]interface{}{"is_ac": false}
+	for _, c := range d.channels {
+		m, err := d.measure(ctx, c)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read channel %d", c.number)
+		}
+		readings[c.name+"_volts"] = m.volts
+		readings[c.name+"_amps"] = m.amps
+		readings[c.name+"_watts"] = m.volts * m.amps
+	}
+
+	flags, err := d.readRegister(ctx, maskEnableRegister)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the alerts")
+	}
+	for _, c := range d.channels {
+		if c.overCurrentAlert {
+			readings[c.name+"_over_current_alert"] = flags&(criticalFlagChannel1>>(c.number-1)) != 0
+		}
+	}
+	if d.underVoltageAlert {
+		readings["under_voltage_alert"] = flags&powerValidFlag == 0
+	}
+	return readings, nil
+}
+
+func (d *ina3221) readRegister(ctx context.Context, register byte) (uint16, error) {
+	handle, err := d.bus.OpenHandle(d.addr)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := handle.Close(); err != nil {
+			d.logger.CError(ctx, err)
+		}
+	}()
+
+	data, err := handle.ReadBlockData(ctx, register, 2)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 2 {
+		return 0, errors.Errorf("read %d bytes from register %d, expected 2", len(data), register)
+	}
+	return uint16(data[0])<<8 | uint16(data[1]), nil
+}
+
+func (d *ina3221) writeRegister(ctx context.Context, register byte, value uint16) error {
+	handle, err := d.bus.OpenHandle(d.addr)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := handle.Close(); err != nil {
+			d.logger.CError(ctx, err)
+		}
+	}()
+
+	return handle.WriteBlockData(ctx, register, []byte{byte(value >> 8), byte(value)})
+}
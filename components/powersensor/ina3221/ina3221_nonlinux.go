@@ -0,0 +1,2 @@
+// Package ina3221 is only implemented for Linux systems.
+package ina3221
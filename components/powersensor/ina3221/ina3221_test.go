@@ -0,0 +1,164 @@
+//go:build linux
+
+package ina3221
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/components/powersensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// fakeChip is the register map of an INA3221.
+type fakeChip struct {
+	mu        sync.Mutex
+	registers map[byte]uint16
+}
+
+func newFakeChip() *fakeChip {
+	return &fakeChip{registers: map[byte]uint16{
+		configRegister:          defaultConfig,
+		maskEnableRegister:      powerValidFlag,
+		manufacturerIDRegister:  expectedManufacturerID,
+		dieIDRegister:           expectedDieID,
+		powerValidUpperRegister: 0x2710,
+		powerValidLowerRegister: 0x2328,
+	}}
+}
+
+func (c *fakeChip) set(register byte, value uint16) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.registers[register] = value
+}
+
+func (c *fakeChip) get(register byte) uint16 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.registers[register]
+}
+
+func (c *fakeChip) bus() buses.I2C {
+	handle := &inject.I2CHandle{}
+	handle.ReadBlockDataFunc = func(ctx context.Context, register byte, numBytes uint8) ([]byte, error) {
+		v := c.get(register)
+		return []byte{byte(v >> 8), byte(v)}, nil
+	}
+	handle.WriteBlockDataFunc = func(ctx context.Context, register byte, data []byte) error {
+		c.set(register, uint16(data[0])<<8|uint16(data[1]))
+		return nil
+	}
+	handle.CloseFunc = func() error { return nil }
+	i2c := &inject.I2C{}
+	i2c.OpenHandleFunc = func(addr byte) (buses.I2CHandle, error) {
+		return handle, nil
+	}
+	return i2c
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		conf  Config
+		field string
+		err   string
+	}{
+		{conf: Config{Channels: []ChannelConfig{{Channel: 1}}}, field: "i2c_bus"},
+		{conf: Config{I2CBus: "1"}, field: "channels"},
+		{conf: Config{I2CBus: "1", Channels: []ChannelConfig{{Channel: 4}}}, err: "channel must be 1, 2 or 3"},
+		{conf: Config{I2CBus: "1", Channels: []ChannelConfig{{Channel: 1}, {Channel: 1}}}, err: "more than once"},
+		{conf: Config{I2CBus: "1", Channels: []ChannelConfig{{Channel: 1, Name: "a"}, {Channel: 2, Name: "a"}}}, err: `"a" is used`},
+		{conf: Config{I2CBus: "1", Channels: []ChannelConfig{{Channel: 1, OverCurrentAlertAmps: 2}}}, err: "above the 1.64A"},
+		{conf: Config{I2CBus: "1", Channels: []ChannelConfig{{Channel: 1}}, UnderVoltageAlertVolts: 40}, err: "under_voltage_alert_volts"},
+		{conf: Config{I2CBus: "1", Channels: []ChannelConfig{{Channel: 1, OverCurrentAlertAmps: 1}}, UnderVoltageAlertVolts: 11}},
+	} {
+		_, err := tc.conf.Validate("path")
+		switch {
+		case tc.field != "":
+			test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, tc.field)
+		case tc.err != "":
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldContainSubstring, tc.err)
+		default:
+			test.That(t, err, test.ShouldBeNil)
+		}
+	}
+}
+
+func TestINA3221(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	name := powersensor.Named("power")
+
+	t.Run("fails on an unexpected chip", func(t *testing.T) {
+		chip := newFakeChip()
+		chip.set(dieIDRegister, 0x2260)
+		_, err := makeINA3221(ctx, name, &Config{I2CBus: "1", Channels: []ChannelConfig{{Channel: 1}}}, logger, chip.bus())
+		test.That(t, err, test.ShouldBeError, unexpectedDeviceError(defaultI2CAddr, expectedManufacturerID, 0x2260))
+	})
+
+	chip := newFakeChip()
+	conf := &Config{
+		I2CBus: "1",
+		Channels: []ChannelConfig{
+			{Channel: 3, Name: "motors", ShuntResistance: 0.01, OverCurrentAlertAmps: 10},
+			{Channel: 1},
+		},
+		UnderVoltageAlertVolts: 11,
+	}
+	s, err := makeINA3221(ctx, name, conf, logger, chip.bus())
+	test.That(t, err, test.ShouldBeNil)
+
+	// only channels 1 and 3 are enabled
+	test.That(t, chip.get(configRegister), test.ShouldEqual, 0x5127)
+	// 10A through 0.01 ohms is 100mV, or 2500 steps of 40uV, and channel 1 has no alert
+	test.That(t, chip.get(criticalLimitRegister+4), test.ShouldEqual, 2500<<3)
+	test.That(t, chip.get(criticalLimitRegister), test.ShouldEqual, maxLimit<<3)
+	// 11V and 11.25V in steps of 8mV
+	test.That(t, chip.get(powerValidLowerRegister), test.ShouldEqual, 1375<<3)
+	test.That(t, chip.get(powerValidUpperRegister), test.ShouldEqual, 1406<<3)
+
+	// 12V over 2A through channel 3, and 5V over -0.1A through channel 1
+	chip.set(busVoltageRegister+4, 1500<<3)
+	chip.set(shuntVoltageRegister+4, 500<<3)
+	chip.set(busVoltageRegister, 625<<3)
+	negative := int16(-250 << 3)
+	chip.set(shuntVoltageRegister, uint16(negative))
+
+	volts, isAC, err := s.Voltage(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, volts, test.ShouldAlmostEqual, 12)
+	test.That(t, isAC, test.ShouldBeFalse)
+	amps, _, err := s.Current(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, amps, test.ShouldAlmostEqual, 2)
+	watts, err := s.Power(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, watts, test.ShouldAlmostEqual, 24)
+
+	readings, err := s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["motors_volts"], test.ShouldAlmostEqual, 12)
+	test.That(t, readings["motors_amps"], test.ShouldAlmostEqual, 2)
+	test.That(t, readings["motors_watts"], test.ShouldAlmostEqual, 24)
+	test.That(t, readings["motors_over_current_alert"], test.ShouldBeFalse)
+	test.That(t, readings["channel_1_volts"], test.ShouldAlmostEqual, 5)
+	test.That(t, readings["channel_1_amps"], test.ShouldAlmostEqual, -0.1)
+	test.That(t, readings["channel_1_watts"], test.ShouldAlmostEqual, -0.5)
+	test.That(t, readings, test.ShouldNotContainKey, "channel_1_over_current_alert")
+	test.That(t, readings["under_voltage_alert"], test.ShouldBeFalse)
+	test.That(t, readings["is_ac"], test.ShouldBeFalse)
+
+	// the critical flag of channel 3 is raised, and the power is no longer valid
+	chip.set(maskEnableRegister, criticalFlagChannel1>>2)
+	readings, err = s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["motors_over_current_alert"], test.ShouldBeTrue)
+	test.That(t, readings["under_voltage_alert"], test.ShouldBeTrue)
+}
@@ -5,5 +5,6 @@ import (
 	// register all powersensors.
 	_ "go.viam.com/rdk/components/powersensor/fake"
 	_ "go.viam.com/rdk/components/powersensor/ina"
+	_ "go.viam.com/rdk/components/powersensor/ina3221"
 	_ "go.viam.com/rdk/components/powersensor/renogy"
 )
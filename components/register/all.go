@@ -5,6 +5,7 @@ import (
 	// register components.
 	_ "go.viam.com/rdk/components/board/register"
 	_ "go.viam.com/rdk/components/camera/register"
+	_ "go.viam.com/rdk/components/contactsensor/register"
 	_ "go.viam.com/rdk/components/encoder/register"
 	_ "go.viam.com/rdk/components/gantry/register"
 	_ "go.viam.com/rdk/components/generic/register"
@@ -16,5 +17,6 @@ import (
 	_ "go.viam.com/rdk/components/posetracker"
 	_ "go.viam.com/rdk/components/powersensor/register"
 	_ "go.viam.com/rdk/components/sensor/register"
+	_ "go.viam.com/rdk/components/serial/register"
 	_ "go.viam.com/rdk/components/servo/register"
 )
@@ -0,0 +1,432 @@
+// Package ble implements a sensor which reads a Bluetooth LE peripheral, such as a battery powered environmental
+// sensor or a beacon around the robot. The GATT characteristics of a connected peripheral are mapped to readings by
+// config, and are read in the background so that a slow or sleeping peripheral doesn't hold up its readings. A
+// peripheral is found by scanning for its address or name, and is reconnected to whenever its connection is lost.
+//
+// A peripheral with no characteristics configured is treated as a beacon, which is never connected to and whose
+// readings are the signal strength and manufacturer data of its advertisements.
+package ble
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("ble")
+
+const (
+	defaultScanTimeoutSec       = 10
+	defaultPollIntervalSec      = 10
+	defaultReconnectIntervalSec = 5
+)
+
+// The types a characteristic value can be decoded as.
+const (
+	typeUint8   = "uint8"
+	typeInt8    = "int8"
+	typeUint16  = "uint16"
+	typeInt16   = "int16"
+	typeUint32  = "uint32"
+	typeInt32   = "int32"
+	typeFloat32 = "float32"
+	typeString  = "string"
+	typeBytes   = "bytes"
+)
+
+var valueSizes = map[string]int{
+	typeUint8: 1, typeInt8: 1, typeUint16: 2, typeInt16: 2, typeUint32: 4, typeInt32: 4, typeFloat32: 4, typeString: 0, typeBytes: 0,
+}
+
+// uuidPattern matches 16 bit UUIDs of the Bluetooth SIG, such as 2a19, and full 128 bit UUIDs.
+var uuidPattern = regexp.MustCompile(`^([0-9a-fA-F]{4}|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})$`)
+
+// macPattern matches a Bluetooth address.
+var macPattern = regexp.MustCompile(`^([0-9a-fA-F]{2}:){5}[0-9a-fA-F]{2}$`)
+
+// CharacteristicConfig maps a GATT characteristic to a reading.
+type CharacteristicConfig struct {
+	// Name is the name of the reading.
+	Name string `json:"name"`
+	// ServiceUUID and CharacteristicUUID are 16 bit UUIDs such as 180f and 2a19, or full 128 bit UUIDs.
+	ServiceUUID        string `json:"service_uuid"`
+	CharacteristicUUID string `json:"characteristic_uuid"`
+	// Type is how the value is decoded, uint8 by default, or one of int8, uint16, int16, uint32, int32, float32,
+	// string and bytes, which is read as hex.
+	Type string `json:"type,omitempty"`
+	// Offset is where in the value the number starts.
+	Offset int `json:"offset,omitempty"`
+	// BigEndian decodes numbers big endian rather than little endian, as the GATT specification has them.
+	BigEndian bool `json:"big_endian,omitempty"`
+	// Scale multiplies numbers, such as 0.01 for the temperature characteristic, which is in hundredths of a degree.
+	Scale float64 `json:"scale,omitempty"`
+	// Notify subscribes to the characteristic, so that its reading is updated whenever the peripheral changes it
+	// rather than only when it is polled.
+	Notify bool `json:"notify,omitempty"`
+}
+
+// Config is used for converting config attributes.
+type Config struct {
+	// Address is the Bluetooth address of the peripheral, such as A4:C1:38:12:34:56, or Name is the name it
+	// advertises.
+	Address         string                 `json:"address,omitempty"`
+	Name            string                 `json:"name,omitempty"`
+	Characteristics []CharacteristicConfig `json:"characteristics,omitempty"`
+	// ScanTimeoutSec is how long to scan for the peripheral before trying again, 10 seconds by default.
+	ScanTimeoutSec float64 `json:"scan_timeout_sec,omitempty"`
+	// PollIntervalSec is how often the characteristics are read, or a beacon is scanned for, 10 seconds by default.
+	PollIntervalSec float64 `json:"poll_interval_sec,omitempty"`
+	// ReconnectIntervalSec is how long to wait after losing the peripheral before looking for it again, 5 seconds by
+	// default.
+	ReconnectIntervalSec float64 `json:"reconnect_interval_sec,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.Address == "" && conf.Name == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "address")
+	}
+	if conf.Address != "" && !macPattern.MatchString(conf.Address) {
+		return nil, resource.NewConfigValidationError(path,
+			errors.Errorf("address must be a Bluetooth address such as A4:C1:38:12:34:56, got %q", conf.Address))
+	}
+	if conf.ScanTimeoutSec < 0 || conf.PollIntervalSec < 0 || conf.ReconnectIntervalSec < 0 {
+		return nil, resource.NewConfigValidationError(path,
+			errors.New("scan_timeout_sec, poll_interval_sec and reconnect_interval_sec cannot be negative"))
+	}
+	names := map[string]bool{}
+	for i, char := range conf.Characteristics {
+		charPath := fmt.Sprintf("%s.characteristics.%d", path, i)
+		if char.Name == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(charPath, "name")
+		}
+		if names[char.Name] || char.Name == readingConnected || char.Name == readingRSSI {
+			return nil, resource.NewConfigValidationError(charPath, errors.Errorf("reading name %q is used more than once", char.Name))
+		}
+		names[char.Name] = true
+		if !uuidPattern.MatchString(char.ServiceUUID) || !uuidPattern.MatchString(char.CharacteristicUUID) {
+			return nil, resource.NewConfigValidationError(charPath,
+				errors.New("service_uuid and characteristic_uuid must be 16 bit UUIDs such as 2a19 or 128 bit UUIDs"))
+		}
+		if _, ok := valueSizes[char.valueType()]; !ok {
+			return nil, resource.NewConfigValidationError(charPath, errors.Errorf("unknown type %q", char.Type))
+		}
+		if char.Offset < 0 {
+			return nil, resource.NewConfigValidationError(charPath, errors.New("offset cannot be negative"))
+		}
+	}
+	return nil, nil
+}
+
+func (char *CharacteristicConfig) valueType() string {
+	if char.Type == "" {
+		return typeUint8
+	}
+	return char.Type
+}
+
+// decode returns the reading of a value of the characteristic.
+func (char *CharacteristicConfig) decode(value []byte) (interface{}, error) {
+	valueType := char.valueType()
+	if char.Offset > len(value) {
+		return nil, errors.Errorf("value of %d bytes is shorter than its offset of %d", len(value), char.Offset)
+	}
+	value = value[char.Offset:]
+	switch valueType {
+	case typeString:
+		return strings.TrimRight(string(value), "\x00"), nil
+	case typeBytes:
+		return hex.EncodeToString(value), nil
+	}
+	size := valueSizes[valueType]
+	if len(value) < size {
+		return nil, errors.Errorf("value of %d bytes is too short for a %s", len(value), valueType)
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if char.BigEndian {
+		order = binary.BigEndian
+	}
+	var number float64
+	switch valueType {
+	case typeUint8:
+		number = float64(value[0])
+	case typeInt8:
+		number = float64(int8(value[0]))
+	case typeUint16:
+		number = float64(order.Uint16(value))
+	case typeInt16:
+		number = float64(int16(order.Uint16(value)))
+	case typeUint32:
+		number = float64(order.Uint32(value))
+	case typeInt32:
+		number = float64(int32(order.Uint32(value)))
+	case typeFloat32:
+		number = float64(math.Float32frombits(order.Uint32(value)))
+	}
+	if char.Scale != 0 {
+		number *= char.Scale
+	}
+	return number, nil
+}
+
+// The readings of every peripheral.
+const (
+	readingConnected = "connected"
+	readingRSSI      = "rssi"
+)
+
+// advertisement is a peripheral found by a scan.
+type advertisement struct {
+	address          string
+	rssi             int
+	manufacturerData map[uint16][]byte
+}
+
+// central scans for and connects to peripherals.
+type central interface {
+	// scan returns the advertisement of the first peripheral with the address, or with the name when there is no
+	// address, scanning until the context is done.
+	scan(ctx context.Context, address, name string) (advertisement, error)
+	connect(ctx context.Context, address string) (peripheral, error)
+}
+
+// peripheral is a connected peripheral.
+type peripheral interface {
+	characteristic(serviceUUID, characteristicUUID string) (characteristic, error)
+	disconnect() error
+}
+
+// characteristic is a GATT characteristic of a connected peripheral.
+type characteristic interface {
+	read() ([]byte, error)
+	notify(func(value []byte)) error
+}
+
+func init() {
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, *Config]{
+			Constructor: newBLESensor,
+		})
+}
+
+type bleSensor struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger  logging.Logger
+	conf    *Config
+	central central
+	workers rdkutils.StoppableWorkers
+
+	scanTimeout       time.Duration
+	pollInterval      time.Duration
+	reconnectInterval time.Duration
+
+	mu        sync.Mutex
+	readings  map[string]interface{}
+	connected bool
+	lastErr   error
+}
+
+func newBLESensor(
+	ctx context.Context,
+	_ resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (sensor.Sensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	c, err := defaultCentral()
+	if err != nil {
+		return nil, err
+	}
+	return makeBLESensor(conf.ResourceName(), newConf, c, logger), nil
+}
+
+func makeBLESensor(name resource.Name, conf *Config, c central, logger logging.Logger) *bleSensor {
+	s := &bleSensor{
+		Named:             name.AsNamed(),
+		logger:            logger,
+		conf:              conf,
+		central:           c,
+		scanTimeout:       secondsOr(conf.ScanTimeoutSec, defaultScanTimeoutSec),
+		pollInterval:      secondsOr(conf.PollIntervalSec, defaultPollIntervalSec),
+		reconnectInterval: secondsOr(conf.ReconnectIntervalSec, defaultReconnectIntervalSec),
+		readings:          map[string]interface{}{},
+		lastErr:           errors.New("peripheral not found yet"),
+	}
+	if len(conf.Characteristics) == 0 {
+		s.workers = rdkutils.NewStoppableWorkers(s.watchBeacon)
+	} else {
+		s.workers = rdkutils.NewStoppableWorkers(s.watchPeripheral)
+	}
+	return s
+}
+
+func secondsOr(seconds, defaultSeconds float64) time.Duration {
+	if seconds == 0 {
+		seconds = defaultSeconds
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// find scans for the peripheral, recording its signal strength.
+func (s *bleSensor) find(ctx context.Context) (advertisement, error) {
+	scanCtx, cancel := context.WithTimeout(ctx, s.scanTimeout)
+	defer cancel()
+	adv, err := s.central.scan(scanCtx, s.conf.Address, s.conf.Name)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = errors.Errorf("peripheral not found within %v", s.scanTimeout)
+		}
+		return advertisement{}, err
+	}
+	s.mu.Lock()
+	s.readings[readingRSSI] = adv.rssi
+	s.mu.Unlock()
+	return adv, nil
+}
+
+// watchBeacon scans for the advertisements of a beacon every poll interval.
+func (s *bleSensor) watchBeacon(ctx context.Context) {
+	for {
+		adv, err := s.find(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		s.mu.Lock()
+		s.lastErr = err
+		if err == nil {
+			for company, data := range adv.manufacturerData {
+				s.readings[fmt.Sprintf("manufacturer_data_%04x", company)] = hex.EncodeToString(data)
+			}
+		}
+		s.mu.Unlock()
+		if !goutils.SelectContextOrWait(ctx, s.pollInterval) {
+			return
+		}
+	}
+}
+
+// watchPeripheral connects to the peripheral and reads its characteristics until it is closed, reconnecting
+// whenever the connection is lost.
+func (s *bleSensor) watchPeripheral(ctx context.Context) {
+	for {
+		err := s.connectAndPoll(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		s.mu.Lock()
+		s.connected, s.lastErr = false, err
+		s.mu.Unlock()
+		s.logger.CWarnw(ctx, "lost BLE peripheral, reconnecting", "error", err, "in", s.reconnectInterval)
+		if !goutils.SelectContextOrWait(ctx, s.reconnectInterval) {
+			return
+		}
+	}
+}
+
+func (s *bleSensor) connectAndPoll(ctx context.Context) error {
+	adv, err := s.find(ctx)
+	if err != nil {
+		return err
+	}
+	p, err := s.central.connect(ctx, adv.address)
+	if err != nil {
+		return errors.Wrapf(err, "cannot connect to peripheral %s", adv.address)
+	}
+	defer func() {
+		if err := p.disconnect(); err != nil {
+			s.logger.CDebugw(ctx, "cannot disconnect from BLE peripheral", "error", err)
+		}
+	}()
+
+	chars := make([]characteristic, len(s.conf.Characteristics))
+	for i, conf := range s.conf.Characteristics {
+		chars[i], err = p.characteristic(conf.ServiceUUID, conf.CharacteristicUUID)
+		if err != nil {
+			return errors.Wrapf(err, "cannot find characteristic %s of service %s", conf.CharacteristicUUID, conf.ServiceUUID)
+		}
+		if conf.Notify {
+			conf := conf
+			if err := chars[i].notify(func(value []byte) { s.update(ctx, &conf, value) }); err != nil {
+				return errors.Wrapf(err, "cannot subscribe to characteristic %s", conf.CharacteristicUUID)
+			}
+		}
+	}
+	s.mu.Lock()
+	s.connected = true
+	s.mu.Unlock()
+	s.logger.CInfow(ctx, "connected to BLE peripheral", "address", adv.address)
+
+	// every characteristic is polled, those subscribed to included, since a failed read is how a lost connection is
+	// noticed
+	for {
+		for i := range chars {
+			value, err := chars[i].read()
+			if err != nil {
+				return errors.Wrapf(err, "cannot read characteristic %s", s.conf.Characteristics[i].CharacteristicUUID)
+			}
+			s.update(ctx, &s.conf.Characteristics[i], value)
+		}
+		s.mu.Lock()
+		s.lastErr = nil
+		s.mu.Unlock()
+		if !goutils.SelectContextOrWait(ctx, s.pollInterval) {
+			return ctx.Err()
+		}
+	}
+}
+
+// update sets the reading of a characteristic from its value.
+func (s *bleSensor) update(ctx context.Context, conf *CharacteristicConfig, value []byte) {
+	reading, err := conf.decode(value)
+	if err != nil {
+		s.logger.CWarnw(ctx, "cannot decode BLE characteristic", "name", conf.Name, "error", err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readings[conf.Name] = reading
+}
+
+// Readings returns the latest readings of the peripheral, and whether it is connected, or why it cannot be read
+// when it has not been read at all.
+func (s *bleSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.readings) == 0 && s.lastErr != nil {
+		return nil, s.lastErr
+	}
+	readings := make(map[string]interface{}, len(s.readings)+1)
+	for name, reading := range s.readings {
+		readings[name] = reading
+	}
+	if len(s.conf.Characteristics) > 0 {
+		readings[readingConnected] = s.connected
+	}
+	return readings, nil
+}
+
+// Close stops watching the peripheral and disconnects from it.
+func (s *bleSensor) Close(ctx context.Context) error {
+	s.workers.Stop()
+	return nil
+}
@@ -0,0 +1,195 @@
+package ble
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// fakeCentral is a central which finds one peripheral, with a battery level and a notified temperature.
+type fakeCentral struct {
+	mu          sync.Mutex
+	battery     []byte
+	readErr     error
+	connections int
+	notify      func([]byte)
+}
+
+func (c *fakeCentral) scan(ctx context.Context, address, name string) (advertisement, error) {
+	if name != "thermometer" {
+		<-ctx.Done()
+		return advertisement{}, ctx.Err()
+	}
+	return advertisement{address: "A4:C1:38:12:34:56", rssi: -60, manufacturerData: map[uint16][]byte{0x004c: {0x02, 0x15}}}, nil
+}
+
+func (c *fakeCentral) connect(ctx context.Context, address string) (peripheral, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.connections++
+	return c, nil
+}
+
+func (c *fakeCentral) characteristic(serviceUUID, characteristicUUID string) (characteristic, error) {
+	return &fakeCharacteristic{central: c, uuid: characteristicUUID}, nil
+}
+
+func (c *fakeCentral) disconnect() error {
+	return nil
+}
+
+func (c *fakeCentral) set(battery []byte, readErr error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.battery, c.readErr = battery, readErr
+}
+
+func (c *fakeCentral) connectionCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.connections
+}
+
+type fakeCharacteristic struct {
+	central *fakeCentral
+	uuid    string
+}
+
+func (c *fakeCharacteristic) read() ([]byte, error) {
+	c.central.mu.Lock()
+	defer c.central.mu.Unlock()
+	if c.uuid == "2a6e" {
+		return []byte{0x34, 0x08}, c.central.readErr
+	}
+	return c.central.battery, c.central.readErr
+}
+
+func (c *fakeCharacteristic) notify(callback func([]byte)) error {
+	c.central.mu.Lock()
+	defer c.central.mu.Unlock()
+	c.central.notify = callback
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	_, err := (&Config{}).Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "address")
+	_, err = (&Config{Address: "A4-C1-38"}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{Name: "thermometer", Characteristics: []CharacteristicConfig{
+		{Name: "battery", ServiceUUID: "180f", CharacteristicUUID: "battery"},
+	}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "16 bit UUIDs")
+	_, err = (&Config{Name: "thermometer", Characteristics: []CharacteristicConfig{
+		{Name: "rssi", ServiceUUID: "180f", CharacteristicUUID: "2a19"},
+	}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "more than once")
+	_, err = (&Config{Address: "A4:C1:38:12:34:56", Characteristics: []CharacteristicConfig{
+		{Name: "temperature", ServiceUUID: "0000181a-0000-1000-8000-00805f9b34fb", CharacteristicUUID: "2A6E", Type: "int16"},
+	}}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+}
+
+func TestDecode(t *testing.T) {
+	for _, tc := range []struct {
+		char  CharacteristicConfig
+		value []byte
+		want  interface{}
+	}{
+		{CharacteristicConfig{}, []byte{87}, 87.},
+		{CharacteristicConfig{Type: typeInt16, Scale: 0.01}, []byte{0x30, 0xf8}, -20.},
+		{CharacteristicConfig{Type: typeUint16, BigEndian: true, Offset: 1}, []byte{0xff, 0x01, 0x02}, 258.},
+		{CharacteristicConfig{Type: typeFloat32}, []byte{0x00, 0x00, 0xc0, 0x3f}, 1.5},
+		{CharacteristicConfig{Type: typeString}, []byte("v1.2\x00"), "v1.2"},
+		{CharacteristicConfig{Type: typeBytes}, []byte{0xbe, 0xef}, "beef"},
+	} {
+		reading, err := tc.char.decode(tc.value)
+		test.That(t, err, test.ShouldBeNil)
+		if number, ok := tc.want.(float64); ok {
+			test.That(t, reading, test.ShouldAlmostEqual, number)
+		} else {
+			test.That(t, reading, test.ShouldEqual, tc.want)
+		}
+	}
+	_, err := (&CharacteristicConfig{Type: typeUint32}).decode([]byte{1, 2})
+	test.That(t, err, test.ShouldBeError, "value of 2 bytes is too short for a uint32")
+}
+
+func TestBLESensor(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	t.Run("peripheral", func(t *testing.T) {
+		c := &fakeCentral{battery: []byte{87}}
+		s := makeBLESensor(sensor.Named("ble"), &Config{
+			Name: "thermometer",
+			Characteristics: []CharacteristicConfig{
+				{Name: "battery", ServiceUUID: "180f", CharacteristicUUID: "2a19"},
+				{Name: "temperature", ServiceUUID: "181a", CharacteristicUUID: "2a6e", Type: typeInt16, Scale: 0.01, Notify: true},
+			},
+			PollIntervalSec:      0.01,
+			ReconnectIntervalSec: 0.01,
+		}, c, logger)
+		defer s.Close(ctx)
+
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			readings, err := s.Readings(ctx, nil)
+			test.That(tb, err, test.ShouldBeNil)
+			test.That(tb, readings["battery"], test.ShouldEqual, 87)
+			test.That(tb, readings["temperature"], test.ShouldAlmostEqual, 21)
+			test.That(tb, readings["rssi"], test.ShouldEqual, -60)
+			test.That(tb, readings["connected"], test.ShouldBeTrue)
+		})
+
+		// notifications update a reading between polls
+		c.mu.Lock()
+		notify := c.notify
+		c.mu.Unlock()
+		notify([]byte{0x98, 0x08})
+		readings, err := s.Readings(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readings["temperature"], test.ShouldAlmostEqual, 22)
+
+		// a failed read loses the peripheral, which is reconnected to
+		c.set([]byte{86}, errors.New("not connected"))
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			test.That(tb, c.connectionCount(), test.ShouldBeGreaterThan, 1)
+		})
+		c.set([]byte{86}, nil)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			readings, err := s.Readings(ctx, nil)
+			test.That(tb, err, test.ShouldBeNil)
+			test.That(tb, readings["battery"], test.ShouldEqual, 86)
+			test.That(tb, readings["connected"], test.ShouldBeTrue)
+		})
+	})
+
+	t.Run("beacon", func(t *testing.T) {
+		s := makeBLESensor(sensor.Named("ble"), &Config{Name: "thermometer", PollIntervalSec: 0.01}, &fakeCentral{}, logger)
+		defer s.Close(ctx)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			readings, err := s.Readings(ctx, nil)
+			test.That(tb, err, test.ShouldBeNil)
+			test.That(tb, readings, test.ShouldResemble, map[string]interface{}{"rssi": -60, "manufacturer_data_004c": "0215"})
+		})
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		s := makeBLESensor(sensor.Named("ble"), &Config{Name: "hygrometer", ScanTimeoutSec: 0.01}, &fakeCentral{}, logger)
+		defer s.Close(ctx)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			_, err := s.Readings(ctx, nil)
+			test.That(tb, err, test.ShouldBeError, "peripheral not found within 10ms")
+		})
+	})
+}
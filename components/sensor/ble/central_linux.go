@@ -0,0 +1,161 @@
+//go:build linux
+
+package ble
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"tinygo.org/x/bluetooth"
+)
+
+var (
+	enableOnce sync.Once
+	enableErr  error
+	// scanMu serializes scans, since the adapter can only run one at a time.
+	scanMu sync.Mutex
+)
+
+// bluezCentral scans for and connects to peripherals with the default adapter, through BlueZ.
+type bluezCentral struct {
+	adapter *bluetooth.Adapter
+}
+
+func defaultCentral() (central, error) {
+	enableOnce.Do(func() {
+		enableErr = bluetooth.DefaultAdapter.Enable()
+	})
+	if enableErr != nil {
+		return nil, errors.Wrap(enableErr, "cannot enable the Bluetooth adapter")
+	}
+	return &bluezCentral{adapter: bluetooth.DefaultAdapter}, nil
+}
+
+func (c *bluezCentral) scan(ctx context.Context, address, name string) (advertisement, error) {
+	scanMu.Lock()
+	defer scanMu.Unlock()
+
+	found := make(chan advertisement, 1)
+	scanErr := make(chan error, 1)
+	go func() {
+		scanErr <- c.adapter.Scan(func(adapter *bluetooth.Adapter, result bluetooth.ScanResult) {
+			if address != "" && !strings.EqualFold(result.Address.String(), address) {
+				return
+			}
+			if address == "" && result.LocalName() != name {
+				return
+			}
+			adv := advertisement{
+				address:          result.Address.String(),
+				rssi:             int(result.RSSI),
+				manufacturerData: map[uint16][]byte{},
+			}
+			for _, element := range result.ManufacturerData() {
+				adv.manufacturerData[element.CompanyID] = append([]byte(nil), element.Data...)
+			}
+			select {
+			case found <- adv:
+				//nolint:errcheck
+				adapter.StopScan()
+			default:
+			}
+		})
+	}()
+
+	select {
+	case adv := <-found:
+		<-scanErr
+		return adv, nil
+	case err := <-scanErr:
+		if err == nil {
+			err = errors.New("scan stopped")
+		}
+		return advertisement{}, err
+	case <-ctx.Done():
+		//nolint:errcheck
+		c.adapter.StopScan()
+		<-scanErr
+		return advertisement{}, ctx.Err()
+	}
+}
+
+func (c *bluezCentral) connect(ctx context.Context, address string) (peripheral, error) {
+	mac, err := bluetooth.ParseMAC(address)
+	if err != nil {
+		return nil, err
+	}
+	device, err := c.adapter.Connect(bluetooth.Address{MACAddress: bluetooth.MACAddress{MAC: mac}}, bluetooth.ConnectionParams{})
+	if err != nil {
+		return nil, err
+	}
+	return &bluezPeripheral{device: device}, nil
+}
+
+type bluezPeripheral struct {
+	device bluetooth.Device
+}
+
+func (p *bluezPeripheral) characteristic(serviceUUID, characteristicUUID string) (characteristic, error) {
+	service, err := parseUUID(serviceUUID)
+	if err != nil {
+		return nil, err
+	}
+	char, err := parseUUID(characteristicUUID)
+	if err != nil {
+		return nil, err
+	}
+	services, err := p.device.DiscoverServices([]bluetooth.UUID{service})
+	if err != nil {
+		return nil, err
+	}
+	if len(services) == 0 {
+		return nil, errors.Errorf("peripheral has no service %s", serviceUUID)
+	}
+	chars, err := services[0].DiscoverCharacteristics([]bluetooth.UUID{char})
+	if err != nil {
+		return nil, err
+	}
+	if len(chars) == 0 {
+		return nil, errors.Errorf("service %s has no characteristic %s", serviceUUID, characteristicUUID)
+	}
+	return &bluezCharacteristic{char: chars[0]}, nil
+}
+
+func (p *bluezPeripheral) disconnect() error {
+	return p.device.Disconnect()
+}
+
+type bluezCharacteristic struct {
+	char bluetooth.DeviceCharacteristic
+}
+
+func (c *bluezCharacteristic) read() ([]byte, error) {
+	// a characteristic value is at most 512 bytes
+	value := make([]byte, 512)
+	n, err := c.char.Read(value)
+	if err != nil {
+		return nil, err
+	}
+	return value[:n], nil
+}
+
+func (c *bluezCharacteristic) notify(callback func(value []byte)) error {
+	return c.char.EnableNotifications(func(value []byte) {
+		callback(append([]byte(nil), value...))
+	})
+}
+
+// parseUUID parses a 16 bit UUID of the Bluetooth SIG, such as 2a19, or a full 128 bit UUID.
+func parseUUID(uuid string) (bluetooth.UUID, error) {
+	if len(uuid) == 4 {
+		short, err := strconv.ParseUint(uuid, 16, 16)
+		if err != nil {
+			return bluetooth.UUID{}, err
+		}
+		return bluetooth.New16BitUUID(uint16(short)), nil
+	}
+	return bluetooth.ParseUUID(strings.ToLower(uuid))
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package ble
+
+import "github.com/pkg/errors"
+
+func defaultCentral() (central, error) {
+	return nil, errors.New("BLE sensors are only supported on linux")
+}
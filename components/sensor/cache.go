@@ -0,0 +1,148 @@
+package sensor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.viam.com/rdk/data"
+)
+
+// ReadingsCacheConfig configures a ReadingsCache.
+type ReadingsCacheConfig struct {
+	// MaxAgeSec is how old a reading can be and still be returned instead of reading the sensor again.
+	MaxAgeSec float64 `json:"max_age_sec,omitempty"`
+	// MinIntervalSec is the least time between two reads of the sensor, however old the last reading is, to rate
+	// limit a sensor whose reads are slow or wear it.
+	MinIntervalSec float64 `json:"min_interval_sec,omitempty"`
+}
+
+// ReadingsCacheStats counts how the readings asked of a ReadingsCache were returned, for tuning it.
+type ReadingsCacheStats struct {
+	// Hits are readings returned from the cache.
+	Hits uint64 `json:"hits"`
+	// Misses are readings which read the sensor.
+	Misses uint64 `json:"misses"`
+	// Coalesced are readings which waited on a read of the sensor another caller started.
+	Coalesced uint64 `json:"coalesced"`
+	// Bypassed are readings with extra parameters, which always read the sensor.
+	Bypassed uint64 `json:"bypassed"`
+}
+
+// ReadingsCache caches the readings of a sensor, so that many concurrent clients and the data manager asking for
+// readings share the reads of a slow sensor rather than each reading it. Readings asked for while a read is in flight
+// wait for that read, and a failed read is never cached.
+type ReadingsCache struct {
+	read        func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error)
+	maxAge      time.Duration
+	minInterval time.Duration
+
+	mu       sync.Mutex
+	readings map[string]interface{}
+	readAt   time.Time
+	inFlight *cachedRead
+	stats    ReadingsCacheStats
+}
+
+// cachedRead is a read of the sensor which callers can wait on.
+type cachedRead struct {
+	done     chan struct{}
+	readings map[string]interface{}
+	err      error
+}
+
+// NewReadingsCache returns a cache of the readings returned by read.
+func NewReadingsCache(
+	conf ReadingsCacheConfig,
+	read func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error),
+) *ReadingsCache {
+	return &ReadingsCache{
+		read:        read,
+		maxAge:      time.Duration(conf.MaxAgeSec * float64(time.Second)),
+		minInterval: time.Duration(conf.MinIntervalSec * float64(time.Second)),
+	}
+}
+
+// Readings returns the cached readings when they are fresh enough or the sensor was read too recently to read it
+// again, and otherwise reads the sensor. Readings asked for with extra parameters, other than the marker the data
+// manager adds, always read the sensor since the parameters may change what is read. The sensor is read apart from the
+// context of the caller which started the read, since other callers may be waiting on it, and each caller gets its own
+// copy of the readings.
+func (c *ReadingsCache) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	if !cacheable(extra) {
+		c.mu.Lock()
+		c.stats.Bypassed++
+		c.mu.Unlock()
+		return c.read(ctx, extra)
+	}
+
+	c.mu.Lock()
+	if c.readings != nil {
+		age := time.Since(c.readAt)
+		if age <= c.maxAge || age < c.minInterval {
+			c.stats.Hits++
+			readings := copyReadings(c.readings)
+			c.mu.Unlock()
+			return readings, nil
+		}
+	}
+	inFlight := c.inFlight
+	if inFlight != nil {
+		c.stats.Coalesced++
+	} else {
+		c.stats.Misses++
+		inFlight = &cachedRead{done: make(chan struct{})}
+		c.inFlight = inFlight
+		go c.readInto(context.WithoutCancel(ctx), extra, inFlight)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-inFlight.done:
+		if inFlight.err != nil {
+			return nil, inFlight.err
+		}
+		return copyReadings(inFlight.readings), nil
+	}
+}
+
+// readInto reads the sensor for the callers waiting on inFlight, caching the readings if the read succeeds.
+func (c *ReadingsCache) readInto(ctx context.Context, extra map[string]interface{}, inFlight *cachedRead) {
+	inFlight.readings, inFlight.err = c.read(ctx, extra)
+
+	c.mu.Lock()
+	c.inFlight = nil
+	if inFlight.err == nil {
+		c.readings, c.readAt = inFlight.readings, time.Now()
+	}
+	c.mu.Unlock()
+	close(inFlight.done)
+}
+
+// Stats returns the counts of how readings were returned.
+func (c *ReadingsCache) Stats() ReadingsCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+func cacheable(extra map[string]interface{}) bool {
+	for key := range extra {
+		if key != data.FromDMString {
+			return false
+		}
+	}
+	return true
+}
+
+// copyReadings returns a shallow copy of readings, so callers changing the readings they get don't change those of
+// other callers.
+func copyReadings(readings map[string]interface{}) map[string]interface{} {
+	readingsCopy := make(map[string]interface{}, len(readings))
+	for key, value := range readings {
+		readingsCopy[key] = value
+	}
+	return readingsCopy
+}
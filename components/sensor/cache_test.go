@@ -0,0 +1,153 @@
+package sensor_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/data"
+)
+
+func TestReadingsCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("max age", func(t *testing.T) {
+		var reads atomic.Int64
+		cache := sensor.NewReadingsCache(sensor.ReadingsCacheConfig{MaxAgeSec: 0.05},
+			func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+				return map[string]interface{}{"read": reads.Add(1)}, nil
+			})
+
+		for i := 0; i < 3; i++ {
+			readings, err := cache.Readings(ctx, data.FromDMExtraMap)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, readings["read"], test.ShouldEqual, 1)
+		}
+		time.Sleep(60 * time.Millisecond)
+		readings, err := cache.Readings(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readings["read"], test.ShouldEqual, 2)
+
+		// extra parameters may change what is read, so they always read the sensor
+		readings, err = cache.Readings(ctx, map[string]interface{}{"channel": 1})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readings["read"], test.ShouldEqual, 3)
+
+		test.That(t, cache.Stats(), test.ShouldResemble, sensor.ReadingsCacheStats{Hits: 2, Misses: 2, Bypassed: 1})
+	})
+
+	t.Run("min interval", func(t *testing.T) {
+		var reads atomic.Int64
+		cache := sensor.NewReadingsCache(sensor.ReadingsCacheConfig{MinIntervalSec: 10},
+			func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+				return map[string]interface{}{"read": reads.Add(1)}, nil
+			})
+		for i := 0; i < 3; i++ {
+			readings, err := cache.Readings(ctx, nil)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, readings["read"], test.ShouldEqual, 1)
+		}
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		fail := true
+		cache := sensor.NewReadingsCache(sensor.ReadingsCacheConfig{MaxAgeSec: 10},
+			func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+				if fail {
+					return nil, errReadingsFailed
+				}
+				return map[string]interface{}{"a": 1}, nil
+			})
+		_, err := cache.Readings(ctx, nil)
+		test.That(t, err, test.ShouldBeError, errReadingsFailed)
+		fail = false
+		readings, err := cache.Readings(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readings, test.ShouldResemble, map[string]interface{}{"a": 1})
+	})
+
+	t.Run("concurrent readings share a read", func(t *testing.T) {
+		var reads atomic.Int64
+		release := make(chan struct{})
+		cache := sensor.NewReadingsCache(sensor.ReadingsCacheConfig{MaxAgeSec: 10},
+			func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+				<-release
+				return map[string]interface{}{"read": reads.Add(1)}, nil
+			})
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 5)
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				readings, err := cache.Readings(ctx, nil)
+				if err == nil && readings["read"] != int64(1) {
+					err = errors.New("readings were not shared")
+				}
+				errs <- err
+			}()
+		}
+		for cache.Stats().Coalesced < 4 {
+			time.Sleep(time.Millisecond)
+		}
+		close(release)
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			test.That(t, err, test.ShouldBeNil)
+		}
+		test.That(t, cache.Stats(), test.ShouldResemble, sensor.ReadingsCacheStats{Misses: 1, Coalesced: 4})
+	})
+	t.Run("a cancelled caller does not fail the others", func(t *testing.T) {
+		release := make(chan struct{})
+		cache := sensor.NewReadingsCache(sensor.ReadingsCacheConfig{MaxAgeSec: 10},
+			func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-release:
+					return map[string]interface{}{"a": 1}, nil
+				}
+			})
+
+		cancelCtx, cancel := context.WithCancel(ctx)
+		started := make(chan error, 1)
+		go func() {
+			_, err := cache.Readings(cancelCtx, nil)
+			started <- err
+		}()
+		for cache.Stats().Misses < 1 {
+			time.Sleep(time.Millisecond)
+		}
+		waited := make(chan error, 1)
+		go func() {
+			readings, err := cache.Readings(ctx, nil)
+			if err == nil && readings["a"] != 1 {
+				err = errors.New("readings were not shared")
+			}
+			waited <- err
+		}()
+		for cache.Stats().Coalesced < 1 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+		test.That(t, <-started, test.ShouldBeError, context.Canceled)
+		close(release)
+		test.That(t, <-waited, test.ShouldBeNil)
+
+		// each caller gets its own copy of the readings
+		readings, err := cache.Readings(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		readings["a"] = 2
+		readings, err = cache.Readings(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readings["a"], test.ShouldEqual, 1)
+	})
+}
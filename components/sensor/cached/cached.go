@@ -0,0 +1,101 @@
+// Package cached implements a sensor which caches and rate limits the readings of another sensor, so that many
+// concurrent clients and the data manager asking a slow sensor for readings don't each read it.
+package cached
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var model = resource.DefaultModelFamily.WithModel("cached")
+
+const statsCommand = "cache_stats"
+
+// Config is used for converting config attributes.
+type Config struct {
+	// Sensor is the name of the sensor whose readings are cached.
+	Sensor                     string `json:"sensor"`
+	sensor.ReadingsCacheConfig `json:",squash"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.Sensor == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "sensor")
+	}
+	if conf.MaxAgeSec < 0 || conf.MinIntervalSec < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("max_age_sec and min_interval_sec cannot be negative"))
+	}
+	if conf.MaxAgeSec == 0 && conf.MinIntervalSec == 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("one of max_age_sec and min_interval_sec must be set"))
+	}
+	return []string{conf.Sensor}, nil
+}
+
+func init() {
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, *Config]{
+			Constructor: newCachedSensor,
+		})
+}
+
+type cachedSensor struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+	sensor sensor.Sensor
+	cache  *sensor.ReadingsCache
+}
+
+func newCachedSensor(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (sensor.Sensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	s, err := sensor.FromDependencies(deps, newConf.Sensor)
+	if err != nil {
+		return nil, err
+	}
+	return &cachedSensor{
+		Named:  conf.ResourceName().AsNamed(),
+		sensor: s,
+		cache:  sensor.NewReadingsCache(newConf.ReadingsCacheConfig, s.Readings),
+	}, nil
+}
+
+// Readings returns the readings of the sensor, from the cache when they are fresh enough.
+func (s *cachedSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return s.cache.Readings(ctx, extra)
+}
+
+// DoCommand returns the counts of cache hits and misses for {"command": "cache_stats"}, and passes any other command
+// on to the sensor.
+func (s *cachedSensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if cmd["command"] != statsCommand {
+		return s.sensor.DoCommand(ctx, cmd)
+	}
+	stats := s.cache.Stats()
+	hitRatio := 0.
+	if total := stats.Hits + stats.Misses + stats.Coalesced; total > 0 {
+		hitRatio = float64(stats.Hits+stats.Coalesced) / float64(total)
+	}
+	return map[string]interface{}{
+		"hits":      stats.Hits,
+		"misses":    stats.Misses,
+		"coalesced": stats.Coalesced,
+		"bypassed":  stats.Bypassed,
+		"hit_ratio": hitRatio,
+	}, nil
+}
@@ -0,0 +1,83 @@
+package cached
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+)
+
+func TestValidate(t *testing.T) {
+	_, err := (&Config{ReadingsCacheConfig: sensor.ReadingsCacheConfig{MaxAgeSec: 1}}).Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "sensor")
+
+	_, err = (&Config{Sensor: "slow"}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "must be set")
+
+	deps, err := (&Config{Sensor: "slow", ReadingsCacheConfig: sensor.ReadingsCacheConfig{MinIntervalSec: 1}}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"slow"})
+}
+
+func TestConfigAttributes(t *testing.T) {
+	conf, err := resource.TransformAttributeMap[*Config](utils.AttributeMap{
+		"sensor":           "slow",
+		"max_age_sec":      5,
+		"min_interval_sec": 0.5,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, conf.Sensor, test.ShouldEqual, "slow")
+	test.That(t, conf.MaxAgeSec, test.ShouldEqual, 5)
+	test.That(t, conf.MinIntervalSec, test.ShouldEqual, 0.5)
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+}
+
+func TestCachedSensor(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	reads := 0
+	slow := inject.NewSensor("slow")
+	slow.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		reads++
+		return map[string]interface{}{"temperature": 21.5}, nil
+	}
+	slow.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		return cmd, nil
+	}
+	deps := resource.Dependencies{slow.Name(): slow}
+	conf := resource.Config{
+		Name:                "cached",
+		API:                 sensor.API,
+		Model:               model,
+		ConvertedAttributes: &Config{Sensor: "slow", ReadingsCacheConfig: sensor.ReadingsCacheConfig{MaxAgeSec: 10}},
+	}
+	s, err := newCachedSensor(ctx, deps, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer s.Close(ctx)
+
+	for i := 0; i < 4; i++ {
+		readings, err := s.Readings(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readings, test.ShouldResemble, map[string]interface{}{"temperature": 21.5})
+	}
+	test.That(t, reads, test.ShouldEqual, 1)
+
+	stats, err := s.DoCommand(ctx, map[string]interface{}{"command": statsCommand})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stats["hits"], test.ShouldEqual, 3)
+	test.That(t, stats["misses"], test.ShouldEqual, 1)
+	test.That(t, stats["hit_ratio"], test.ShouldEqual, 0.75)
+
+	resp, err := s.DoCommand(ctx, map[string]interface{}{"command": "calibrate"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp, test.ShouldResemble, map[string]interface{}{"command": "calibrate"})
+}
@@ -5,29 +5,49 @@ import (
 	"context"
 	"sync"
 
+	"go.viam.com/rdk/components/fakeconditions"
 	"go.viam.com/rdk/components/sensor"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
 )
 
+// Config is used for converting fake sensor attributes.
+type Config struct {
+	// Conditions simulates the latency of every call and the noise of the floating point readings.
+	fakeconditions.Config `json:",squash"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if err := conf.Config.Validate(path); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
 func init() {
 	resource.RegisterComponent(
 		sensor.API,
 		resource.DefaultModelFamily.WithModel("fake"),
-		resource.Registration[sensor.Sensor, resource.NoNativeConfig]{Constructor: func(
+		resource.Registration[sensor.Sensor, *Config]{Constructor: func(
 			ctx context.Context,
 			deps resource.Dependencies,
 			conf resource.Config,
 			logger logging.Logger,
 		) (sensor.Sensor, error) {
-			return newSensor(conf.ResourceName(), logger), nil
+			newConf, err := resource.NativeConfig[*Config](conf)
+			if err != nil {
+				return nil, err
+			}
+			return newSensor(conf.ResourceName(), newConf, logger), nil
 		}})
 }
 
-func newSensor(name resource.Name, logger logging.Logger) sensor.Sensor {
+func newSensor(name resource.Name, conf *Config, logger logging.Logger) sensor.Sensor {
 	return &Sensor{
-		Named:  name.AsNamed(),
-		logger: logger,
+		Named:      name.AsNamed(),
+		conditions: fakeconditions.New(conf.Config),
+		logger:     logger,
 	}
 }
 
@@ -35,14 +55,18 @@ func newSensor(name resource.Name, logger logging.Logger) sensor.Sensor {
 type Sensor struct {
 	mu sync.Mutex
 	resource.Named
-	resource.TriviallyReconfigurable
+	resource.AlwaysRebuild
 	resource.TriviallyCloseable
-	logger logging.Logger
+	conditions *fakeconditions.Conditions
+	logger     logging.Logger
 }
 
 // Readings always returns the set values.
 func (s *Sensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	if err := s.conditions.Wait(ctx); err != nil {
+		return nil, err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return map[string]interface{}{"a": 1, "b": 2, "c": 3}, nil
+	return s.conditions.NoiseReadings(map[string]interface{}{"a": 1, "b": 2, "c": 3}), nil
 }
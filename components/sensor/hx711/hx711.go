@@ -0,0 +1,274 @@
+// Package hx711 implements a scale sensor: a load cell read by an HX711 24 bit ADC through two GPIO pins of a board.
+// The scale is tared and calibrated with DoCommand, whose results can be kept in the config:
+//
+//	{"command": "tare"} zeroes the scale with nothing on it, returning the tare_offset.
+//	{"command": "calibrate", "known_weight": 500} calibrates the scale with a weight on it, given in the configured
+//	units, returning the counts_per_unit.
+package hx711
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var model = resource.DefaultModelFamily.WithModel("hx711")
+
+const (
+	defaultGain  = 128
+	defaultUnits = "g"
+	// tareSamples is how many readings taring and calibrating average over.
+	tareSamples = 10
+	// readyTimeout is how long to wait for a reading, which the HX711 makes 10 or 80 times a second.
+	readyTimeout = time.Second
+	readyPoll    = time.Millisecond
+)
+
+// gainPulses are the clock pulses after the 24 bits of a reading which select the channel and gain of the next:
+// channel A has gains of 128 and 64, and channel B a gain of 32.
+var gainPulses = map[int]int{128: 1, 64: 3, 32: 2}
+
+// Config is used for converting config attributes.
+type Config struct {
+	Board    string `json:"board"`
+	DataPin  string `json:"data_pin"`
+	ClockPin string `json:"clock_pin"`
+	Gain     int    `json:"gain,omitempty"`
+	// Samples is how many readings each of the sensor's readings averages over.
+	Samples int `json:"samples,omitempty"`
+	// TareOffset is the reading of the empty scale, and CountsPerUnit the reading of one unit of weight beyond it.
+	TareOffset    float64 `json:"tare_offset,omitempty"`
+	CountsPerUnit float64 `json:"counts_per_unit,omitempty"`
+	Units         string  `json:"units,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.Board == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "board")
+	}
+	if conf.DataPin == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "data_pin")
+	}
+	if conf.ClockPin == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "clock_pin")
+	}
+	if _, ok := gainPulses[conf.Gain]; !ok && conf.Gain != 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("gain must be 128 or 64 for channel A, or 32 for channel B"))
+	}
+	if conf.Samples < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("samples cannot be negative"))
+	}
+	return []string{conf.Board}, nil
+}
+
+func init() {
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, *Config]{
+			Constructor: func(
+				ctx context.Context,
+				deps resource.Dependencies,
+				conf resource.Config,
+				logger logging.Logger,
+			) (sensor.Sensor, error) {
+				newConf, err := resource.NativeConfig[*Config](conf)
+				if err != nil {
+					return nil, err
+				}
+				return newScale(ctx, deps, conf.ResourceName(), newConf, logger)
+			},
+		})
+}
+
+type scale struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger logging.Logger
+
+	mu            sync.Mutex
+	data, clock   board.GPIOPin
+	pulses        int
+	samples       int
+	units         string
+	tareOffset    float64
+	countsPerUnit float64
+}
+
+func newScale(ctx context.Context, deps resource.Dependencies, name resource.Name, conf *Config, logger logging.Logger,
+) (sensor.Sensor, error) {
+	b, err := board.FromDependencies(deps, conf.Board)
+	if err != nil {
+		return nil, err
+	}
+	data, err := b.GPIOPinByName(conf.DataPin)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get data pin %q", conf.DataPin)
+	}
+	clock, err := b.GPIOPinByName(conf.ClockPin)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get clock pin %q", conf.ClockPin)
+	}
+	s := &scale{
+		Named:         name.AsNamed(),
+		logger:        logger,
+		data:          data,
+		clock:         clock,
+		pulses:        gainPulses[defaultGain],
+		samples:       1,
+		units:         defaultUnits,
+		tareOffset:    conf.TareOffset,
+		countsPerUnit: 1,
+	}
+	if conf.Gain != 0 {
+		s.pulses = gainPulses[conf.Gain]
+	}
+	if conf.Samples != 0 {
+		s.samples = conf.Samples
+	}
+	if conf.Units != "" {
+		s.units = conf.Units
+	}
+	if conf.CountsPerUnit != 0 {
+		s.countsPerUnit = conf.CountsPerUnit
+	}
+
+	// a low clock wakes the HX711 up, and the first reading selects the gain of the ones after it
+	if err := clock.Set(ctx, false, nil); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.read(ctx); err != nil {
+		return nil, errors.Wrap(err, "cannot read the hx711")
+	}
+	return s, nil
+}
+
+// read shifts a reading out of the HX711 once it has one ready, which it signals by pulling its data pin low.
+func (s *scale) read(ctx context.Context) (int, error) {
+	deadline := time.Now().Add(readyTimeout)
+	for {
+		high, err := s.data.Get(ctx, nil)
+		if err != nil {
+			return 0, err
+		}
+		if !high {
+			break
+		}
+		if time.Now().After(deadline) {
+			return 0, errors.New("timed out waiting for the hx711 to be ready")
+		}
+		if !goutils.SelectContextOrWait(ctx, readyPoll) {
+			return 0, ctx.Err()
+		}
+	}
+
+	// each bit is ready on the rising edge of the clock, most significant first. The clock must not stay high for
+	// more than 60µs, or the HX711 powers down.
+	var value int
+	for i := 0; i < 24+s.pulses; i++ {
+		if err := s.clock.Set(ctx, true, nil); err != nil {
+			return 0, err
+		}
+		bit, err := s.data.Get(ctx, nil)
+		if err != nil {
+			return 0, multierr.Combine(err, s.clock.Set(ctx, false, nil))
+		}
+		if err := s.clock.Set(ctx, false, nil); err != nil {
+			return 0, err
+		}
+		if i < 24 {
+			value <<= 1
+			if bit {
+				value |= 1
+			}
+		}
+	}
+	// the reading is two's complement
+	if value&(1<<23) != 0 {
+		value -= 1 << 24
+	}
+	return value, nil
+}
+
+// average is the average of n readings.
+func (s *scale) average(ctx context.Context, n int) (float64, error) {
+	var sum float64
+	for i := 0; i < n; i++ {
+		value, err := s.read(ctx)
+		if err != nil {
+			return 0, err
+		}
+		sum += float64(value)
+	}
+	return sum / float64(n), nil
+}
+
+// Readings returns the weight on the scale in the configured units, and the raw reading it comes from.
+func (s *scale) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	raw, err := s.average(ctx, s.samples)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"weight": (raw - s.tareOffset) / s.countsPerUnit,
+		"units":  s.units,
+		"raw":    raw,
+	}, nil
+}
+
+// DoCommand tares and calibrates the scale.
+func (s *scale) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch name {
+	case "tare":
+		offset, err := s.average(ctx, tareSamples)
+		if err != nil {
+			return nil, err
+		}
+		s.tareOffset = offset
+		return map[string]interface{}{"tare_offset": offset}, nil
+	case "calibrate":
+		known, ok := cmd["known_weight"].(float64)
+		if !ok || known <= 0 {
+			return nil, errors.New("calibrate needs a positive known_weight")
+		}
+		raw, err := s.average(ctx, tareSamples)
+		if err != nil {
+			return nil, err
+		}
+		countsPerUnit := (raw - s.tareOffset) / known
+		if countsPerUnit == 0 {
+			return nil, errors.New("the known weight does not change the reading of the scale, tare it without the weight first")
+		}
+		s.countsPerUnit = countsPerUnit
+		return map[string]interface{}{"counts_per_unit": countsPerUnit}, nil
+	default:
+		return nil, errors.Errorf("unknown command %q", name)
+	}
+}
+
+// Close powers the HX711 down by holding its clock high.
+func (s *scale) Close(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.clock.Set(ctx, true, nil)
+}
@@ -0,0 +1,110 @@
+package hx711
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// fakeHX711 shifts out its value on the data pin as the clock pin pulses, counting the pulses of each reading.
+type fakeHX711 struct {
+	value     int
+	clockHigh bool
+	pulses    int
+	lastCount int
+}
+
+func (f *fakeHX711) board() *inject.Board {
+	data := &inject.GPIOPin{GetFunc: func(ctx context.Context, extra map[string]interface{}) (bool, error) {
+		if !f.clockHigh {
+			// ready once the pulses of the last reading are done
+			if f.pulses >= 25 {
+				f.lastCount, f.pulses = f.pulses, 0
+			}
+			return false, nil
+		}
+		if f.pulses > 24 {
+			return true, nil
+		}
+		return (f.value>>(24-f.pulses))&1 == 1, nil
+	}}
+	clock := &inject.GPIOPin{SetFunc: func(ctx context.Context, high bool, extra map[string]interface{}) error {
+		if high && !f.clockHigh {
+			f.pulses++
+		}
+		f.clockHigh = high
+		return nil
+	}}
+	b := inject.NewBoard("board")
+	b.GPIOPinByNameFunc = func(name string) (board.GPIOPin, error) {
+		if name == "data" {
+			return data, nil
+		}
+		return clock, nil
+	}
+	return b
+}
+
+func TestValidate(t *testing.T) {
+	conf := &Config{Board: "board", DataPin: "data"}
+	_, err := conf.Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "clock_pin")
+
+	conf.ClockPin = "clock"
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"board"})
+
+	conf.Gain = 16
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestScale(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	hx := &fakeHX711{value: 0xFFFF38} // -200
+	b := hx.board()
+	deps := resource.Dependencies{board.Named("board"): b}
+
+	s, err := newScale(ctx, deps, sensor.Named("scale"),
+		&Config{Board: "board", DataPin: "data", ClockPin: "clock", Gain: 64, Units: "kg"}, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	readings, err := s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["raw"], test.ShouldEqual, -200)
+	test.That(t, readings["weight"], test.ShouldEqual, -200)
+	test.That(t, readings["units"], test.ShouldEqual, "kg")
+	// 24 bits and 3 more pulses for a gain of 64
+	test.That(t, hx.lastCount, test.ShouldEqual, 27)
+
+	resp, err := s.DoCommand(ctx, map[string]interface{}{"command": "tare"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["tare_offset"], test.ShouldEqual, -200)
+
+	hx.value = 1800
+	_, err = s.DoCommand(ctx, map[string]interface{}{"command": "calibrate"})
+	test.That(t, err, test.ShouldNotBeNil)
+	resp, err = s.DoCommand(ctx, map[string]interface{}{"command": "calibrate", "known_weight": 4.})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["counts_per_unit"], test.ShouldEqual, 500)
+
+	hx.value = 800
+	readings, err = s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["weight"], test.ShouldEqual, 2)
+
+	_, err = s.DoCommand(ctx, map[string]interface{}{"command": "weigh"})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	test.That(t, s.Close(ctx), test.ShouldBeNil)
+	test.That(t, hx.clockHigh, test.ShouldBeTrue)
+}
@@ -0,0 +1,411 @@
+// Package modbus implements a sensor which reads the registers of any Modbus device, over TCP or RTU over a serial
+// port, from a register map in its config, so that industrial gear does not each need a driver of its own.
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	mb "github.com/goburrow/modbus"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var model = resource.DefaultModelFamily.WithModel("modbus")
+
+// Protocols a device can be reached over.
+const (
+	ProtocolTCP = "tcp"
+	ProtocolRTU = "rtu"
+)
+
+// Tables a register can be read from.
+const (
+	TableHolding       = "holding"
+	TableInput         = "input"
+	TableCoil          = "coil"
+	TableDiscreteInput = "discrete_input"
+)
+
+// Types a register can be decoded as.
+const (
+	TypeUint16  = "uint16"
+	TypeInt16   = "int16"
+	TypeUint32  = "uint32"
+	TypeInt32   = "int32"
+	TypeFloat32 = "float32"
+	TypeUint64  = "uint64"
+	TypeInt64   = "int64"
+	TypeFloat64 = "float64"
+	TypeBool    = "bool"
+)
+
+const (
+	defaultTCPPort   = "502"
+	defaultBaudRate  = 9600
+	defaultUnitID    = 1
+	defaultTimeoutMs = 1000
+
+	// maxRegistersPerRead and maxBitsPerRead are the most a single Modbus request may read.
+	maxRegistersPerRead = 125
+	maxBitsPerRead      = 2000
+)
+
+// typeWords is how many 16 bit registers each type spans.
+var typeWords = map[string]int{
+	TypeUint16:  1,
+	TypeInt16:   1,
+	TypeUint32:  2,
+	TypeInt32:   2,
+	TypeFloat32: 2,
+	TypeUint64:  4,
+	TypeInt64:   4,
+	TypeFloat64: 4,
+	TypeBool:    1,
+}
+
+// Register is a value of the device returned as a reading.
+type Register struct {
+	Name    string `json:"name"`
+	Address uint16 `json:"address"`
+	// Table is the table the register is in, defaulting to holding registers.
+	Table string `json:"table,omitempty"`
+	// Type is how the register, or the registers starting at it, are decoded, defaulting to uint16. Coils and
+	// discrete inputs are always bools.
+	Type string `json:"type,omitempty"`
+	// Scale and Offset turn the decoded value into the reading, as value*scale+offset. Scale defaults to 1.
+	Scale  *float64 `json:"scale,omitempty"`
+	Offset float64  `json:"offset,omitempty"`
+	// WordSwap reads values spanning several registers with their least significant register first, as some
+	// devices order them.
+	WordSwap bool `json:"word_swap,omitempty"`
+}
+
+// Config is used for converting config attributes.
+type Config struct {
+	// Protocol is either tcp or rtu.
+	Protocol string `json:"protocol"`
+	// Address is the host and port of a device reached over TCP, with the port defaulting to 502.
+	Address string `json:"address,omitempty"`
+	// SerialPath and the serial settings are for devices reached over RTU, defaulting to 9600 baud, 8 data bits, no
+	// parity and 1 stop bit.
+	SerialPath     string `json:"serial_path,omitempty"`
+	SerialBaudRate int    `json:"serial_baud_rate,omitempty"`
+	SerialDataBits int    `json:"serial_data_bits,omitempty"`
+	SerialParity   string `json:"serial_parity,omitempty"`
+	SerialStopBits int    `json:"serial_stop_bits,omitempty"`
+	// UnitID is the Modbus address of the device, defaulting to 1.
+	UnitID    *byte      `json:"unit_id,omitempty"`
+	TimeoutMs int        `json:"timeout_ms,omitempty"`
+	Registers []Register `json:"registers"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	switch conf.Protocol {
+	case ProtocolTCP:
+		if conf.Address == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "address")
+		}
+	case ProtocolRTU:
+		if conf.SerialPath == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "serial_path")
+		}
+		switch conf.SerialParity {
+		case "", "N", "E", "O":
+		default:
+			return nil, resource.NewConfigValidationError(path,
+				errors.Errorf("serial_parity must be N, E or O, got %q", conf.SerialParity))
+		}
+	case "":
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "protocol")
+	default:
+		return nil, resource.NewConfigValidationError(path,
+			errors.Errorf("protocol must be %q or %q, got %q", ProtocolTCP, ProtocolRTU, conf.Protocol))
+	}
+	if conf.TimeoutMs < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("timeout_ms cannot be negative"))
+	}
+	if len(conf.Registers) == 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "registers")
+	}
+	names := map[string]bool{}
+	for i, reg := range conf.Registers {
+		regPath := fmt.Sprintf("%s.registers.%d", path, i)
+		if reg.Name == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(regPath, "name")
+		}
+		if names[reg.Name] {
+			return nil, resource.NewConfigValidationError(path, errors.Errorf("register %q is configured more than once", reg.Name))
+		}
+		names[reg.Name] = true
+		switch reg.Table {
+		case "", TableHolding, TableInput:
+			if reg.Type == "" {
+				break
+			}
+			words, ok := typeWords[reg.Type]
+			if !ok {
+				return nil, resource.NewConfigValidationError(regPath, errors.Errorf("unknown register type %q", reg.Type))
+			}
+			if int(reg.Address)+words > math.MaxUint16+1 {
+				return nil, resource.NewConfigValidationError(regPath, errors.New("register runs past the last address"))
+			}
+		case TableCoil, TableDiscreteInput:
+			if reg.Type != "" && reg.Type != TypeBool {
+				return nil, resource.NewConfigValidationError(regPath,
+					errors.Errorf("%s registers are bools, not %s", reg.Table, reg.Type))
+			}
+		default:
+			return nil, resource.NewConfigValidationError(regPath, errors.Errorf("unknown register table %q", reg.Table))
+		}
+	}
+	return nil, nil
+}
+
+func init() {
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, *Config]{
+			Constructor: newModbusSensor,
+		})
+}
+
+// register is a configured register with its defaults filled in.
+type register struct {
+	Register
+	words int
+}
+
+func (r register) bits() bool {
+	return r.Table == TableCoil || r.Table == TableDiscreteInput
+}
+
+// span is a block of contiguous addresses of a table read in one request.
+type span struct {
+	table     string
+	start     uint16
+	quantity  uint16
+	registers []register
+}
+
+type modbusSensor struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger logging.Logger
+
+	mu      sync.Mutex
+	client  mb.Client
+	handler io.Closer
+	spans   []span
+}
+
+func newModbusSensor(
+	ctx context.Context,
+	_ resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (sensor.Sensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	unitID := byte(defaultUnitID)
+	if newConf.UnitID != nil {
+		unitID = *newConf.UnitID
+	}
+	timeout := defaultTimeoutMs * time.Millisecond
+	if newConf.TimeoutMs != 0 {
+		timeout = time.Duration(newConf.TimeoutMs) * time.Millisecond
+	}
+
+	var handler mb.ClientHandler
+	var closer io.Closer
+	switch newConf.Protocol {
+	case ProtocolTCP:
+		address := newConf.Address
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			address = net.JoinHostPort(address, defaultTCPPort)
+		}
+		h := mb.NewTCPClientHandler(address)
+		h.SlaveId = unitID
+		h.Timeout = timeout
+		handler, closer = h, h
+	case ProtocolRTU:
+		h := mb.NewRTUClientHandler(newConf.SerialPath)
+		h.SlaveId = unitID
+		h.Timeout = timeout
+		h.BaudRate = defaultBaudRate
+		if newConf.SerialBaudRate != 0 {
+			h.BaudRate = newConf.SerialBaudRate
+		}
+		h.DataBits = 8
+		if newConf.SerialDataBits != 0 {
+			h.DataBits = newConf.SerialDataBits
+		}
+		h.Parity = "N"
+		if newConf.SerialParity != "" {
+			h.Parity = newConf.SerialParity
+		}
+		h.StopBits = 1
+		if newConf.SerialStopBits != 0 {
+			h.StopBits = newConf.SerialStopBits
+		}
+		handler, closer = h, h
+	default:
+		return nil, errors.Errorf("unknown protocol %q", newConf.Protocol)
+	}
+
+	return &modbusSensor{
+		Named:   conf.ResourceName().AsNamed(),
+		logger:  logger,
+		client:  mb.NewClient(handler),
+		handler: closer,
+		spans:   planSpans(newConf.Registers),
+	}, nil
+}
+
+// planSpans groups the registers into as few reads as possible, merging registers of a table whose addresses are
+// contiguous or overlap, up to the most a request may read. Registers with gaps between them are read separately,
+// since devices often refuse reads of addresses they do not map.
+func planSpans(configured []Register) []span {
+	byTable := map[string][]register{}
+	for _, reg := range configured {
+		r := register{Register: reg, words: 1}
+		if r.Table == "" {
+			r.Table = TableHolding
+		}
+		if r.bits() {
+			r.Type = TypeBool
+		} else {
+			if r.Type == "" {
+				r.Type = TypeUint16
+			}
+			r.words = typeWords[r.Type]
+		}
+		byTable[r.Table] = append(byTable[r.Table], r)
+	}
+
+	tables := make([]string, 0, len(byTable))
+	for table := range byTable {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	var spans []span
+	for _, table := range tables {
+		regs := byTable[table]
+		sort.SliceStable(regs, func(i, j int) bool { return regs[i].Address < regs[j].Address })
+		limit := maxRegistersPerRead
+		if regs[0].bits() {
+			limit = maxBitsPerRead
+		}
+		var current *span
+		for _, r := range regs {
+			end := int(r.Address) + r.words
+			if current != nil && int(r.Address) <= int(current.start)+int(current.quantity) &&
+				end-int(current.start) <= limit {
+				current.quantity = uint16(max(int(current.quantity), end-int(current.start)))
+				current.registers = append(current.registers, r)
+				continue
+			}
+			spans = append(spans, span{table: table, start: r.Address, quantity: uint16(r.words), registers: []register{r}})
+			current = &spans[len(spans)-1]
+		}
+	}
+	return spans
+}
+
+// Readings returns the value of every register. A register which cannot be read fails the whole reading, so that a
+// device which went away is noticed rather than returning partial readings.
+func (s *modbusSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	readings := map[string]interface{}{}
+	for _, sp := range s.spans {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		data, err := s.read(sp)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %d %s registers from address %d", sp.quantity, sp.table, sp.start)
+		}
+		for _, r := range sp.registers {
+			readings[r.Name] = decode(r, data, int(r.Address-sp.start))
+		}
+	}
+	return readings, nil
+}
+
+func (s *modbusSensor) read(sp span) ([]byte, error) {
+	switch sp.table {
+	case TableInput:
+		return s.client.ReadInputRegisters(sp.start, sp.quantity)
+	case TableCoil:
+		return s.client.ReadCoils(sp.start, sp.quantity)
+	case TableDiscreteInput:
+		return s.client.ReadDiscreteInputs(sp.start, sp.quantity)
+	default:
+		return s.client.ReadHoldingRegisters(sp.start, sp.quantity)
+	}
+}
+
+// decode returns the reading of a register at the offset, in registers or bits, into the data of its span.
+func decode(r register, data []byte, offset int) interface{} {
+	if r.bits() {
+		return data[offset/8]&(1<<(offset%8)) != 0
+	}
+	raw := make([]byte, 0, 2*r.words)
+	for i := 0; i < r.words; i++ {
+		word := i
+		if r.WordSwap {
+			word = r.words - 1 - i
+		}
+		raw = append(raw, data[2*(offset+word):2*(offset+word)+2]...)
+	}
+
+	var value float64
+	switch r.Type {
+	case TypeBool:
+		return binary.BigEndian.Uint16(raw) != 0
+	case TypeInt16:
+		value = float64(int16(binary.BigEndian.Uint16(raw)))
+	case TypeUint32:
+		value = float64(binary.BigEndian.Uint32(raw))
+	case TypeInt32:
+		value = float64(int32(binary.BigEndian.Uint32(raw)))
+	case TypeFloat32:
+		value = float64(math.Float32frombits(binary.BigEndian.Uint32(raw)))
+	case TypeUint64:
+		value = float64(binary.BigEndian.Uint64(raw))
+	case TypeInt64:
+		value = float64(int64(binary.BigEndian.Uint64(raw)))
+	case TypeFloat64:
+		value = math.Float64frombits(binary.BigEndian.Uint64(raw))
+	default:
+		value = float64(binary.BigEndian.Uint16(raw))
+	}
+	if r.Scale != nil {
+		value *= *r.Scale
+	}
+	return value + r.Offset
+}
+
+// Close closes the connection to the device.
+func (s *modbusSensor) Close(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.handler.Close()
+}
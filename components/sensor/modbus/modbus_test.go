@@ -0,0 +1,290 @@
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// fakeDevice is a Modbus TCP server with a holding register table, an input register table and coils, which
+// refuses reads of addresses it does not map.
+type fakeDevice struct {
+	listener net.Listener
+	unitID   byte
+
+	mu       sync.Mutex
+	holding  map[uint16]uint16
+	input    map[uint16]uint16
+	coils    map[uint16]bool
+	requests int
+}
+
+func newFakeDevice(t *testing.T) *fakeDevice {
+	t.Helper()
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	d := &fakeDevice{
+		listener: listener,
+		unitID:   7,
+		holding:  map[uint16]uint16{},
+		input:    map[uint16]uint16{},
+		coils:    map[uint16]bool{},
+	}
+	go d.serve()
+	t.Cleanup(func() { test.That(t, listener.Close(), test.ShouldBeNil) })
+	return d
+}
+
+func (d *fakeDevice) serve() {
+	for {
+		conn, err := d.listener.Accept()
+		if err != nil {
+			return
+		}
+		go d.handle(conn)
+	}
+}
+
+func (d *fakeDevice) handle(conn net.Conn) {
+	defer conn.Close()
+	for {
+		header := make([]byte, 7)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		pdu := make([]byte, binary.BigEndian.Uint16(header[4:6])-1)
+		if _, err := io.ReadFull(conn, pdu); err != nil {
+			return
+		}
+		resp := d.respond(header[6], pdu)
+		out := append([]byte(nil), header[:4]...)
+		out = binary.BigEndian.AppendUint16(out, uint16(len(resp)+1))
+		out = append(out, header[6])
+		if _, err := conn.Write(append(out, resp...)); err != nil {
+			return
+		}
+	}
+}
+
+func (d *fakeDevice) respond(unitID byte, pdu []byte) []byte {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests++
+	function, start, quantity := pdu[0], binary.BigEndian.Uint16(pdu[1:3]), binary.BigEndian.Uint16(pdu[3:5])
+	// illegal data address
+	exception := []byte{function | 0x80, 0x02}
+	if unitID != d.unitID {
+		return []byte{function | 0x80, 0x0B}
+	}
+	switch function {
+	case 0x01:
+		data := make([]byte, (quantity+7)/8)
+		for i := uint16(0); i < quantity; i++ {
+			value, ok := d.coils[start+i]
+			if !ok {
+				return exception
+			}
+			if value {
+				data[i/8] |= 1 << (i % 8)
+			}
+		}
+		return append([]byte{function, byte(len(data))}, data...)
+	case 0x03, 0x04:
+		table := d.holding
+		if function == 0x04 {
+			table = d.input
+		}
+		data := make([]byte, 0, 2*quantity)
+		for i := uint16(0); i < quantity; i++ {
+			value, ok := table[start+i]
+			if !ok {
+				return exception
+			}
+			data = binary.BigEndian.AppendUint16(data, value)
+		}
+		return append([]byte{function, byte(len(data))}, data...)
+	default:
+		return []byte{function | 0x80, 0x01}
+	}
+}
+
+func (d *fakeDevice) setWords(table map[uint16]uint16, address uint16, words ...uint16) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, w := range words {
+		table[address+uint16(i)] = w
+	}
+}
+
+func (d *fakeDevice) requestCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.requests
+}
+
+func TestValidate(t *testing.T) {
+	registers := []Register{{Name: "temperature", Address: 1}}
+	for _, tc := range []struct {
+		conf  Config
+		field string
+		err   string
+	}{
+		{conf: Config{Registers: registers}, field: "protocol"},
+		{conf: Config{Protocol: "ascii", Registers: registers}, err: "protocol must be"},
+		{conf: Config{Protocol: ProtocolTCP, Registers: registers}, field: "address"},
+		{conf: Config{Protocol: ProtocolRTU, Registers: registers}, field: "serial_path"},
+		{conf: Config{Protocol: ProtocolRTU, SerialPath: "/dev/ttyUSB0", SerialParity: "X", Registers: registers}, err: "serial_parity"},
+		{conf: Config{Protocol: ProtocolTCP, Address: "plc"}, field: "registers"},
+		{conf: Config{Protocol: ProtocolTCP, Address: "plc", Registers: []Register{{Address: 1}}}, field: "name"},
+		{
+			conf: Config{Protocol: ProtocolTCP, Address: "plc", Registers: []Register{{Name: "a"}, {Name: "a", Address: 1}}},
+			err:  "more than once",
+		},
+		{
+			conf: Config{Protocol: ProtocolTCP, Address: "plc", Registers: []Register{{Name: "a", Type: "int128"}}},
+			err:  "unknown register type",
+		},
+		{
+			conf: Config{Protocol: ProtocolTCP, Address: "plc", Registers: []Register{{Name: "a", Table: "coil", Type: TypeFloat32}}},
+			err:  "bools",
+		},
+		{
+			conf: Config{Protocol: ProtocolTCP, Address: "plc", Registers: []Register{{Name: "a", Address: 65535, Type: TypeUint32}}},
+			err:  "past the last address",
+		},
+		{conf: Config{Protocol: ProtocolTCP, Address: "plc", Registers: []Register{{Name: "a", Table: "fifo"}}}, err: "unknown register table"},
+		{conf: Config{Protocol: ProtocolTCP, Address: "plc", Registers: registers}},
+	} {
+		_, err := tc.conf.Validate("path")
+		switch {
+		case tc.field != "":
+			test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, tc.field)
+		case tc.err != "":
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldContainSubstring, tc.err)
+		default:
+			test.That(t, err, test.ShouldBeNil)
+		}
+	}
+}
+
+func TestPlanSpans(t *testing.T) {
+	spans := planSpans([]Register{
+		{Name: "c", Address: 12, Type: TypeFloat32},
+		{Name: "a", Address: 10},
+		{Name: "b", Address: 11},
+		{Name: "far", Address: 100},
+		{Name: "input", Address: 10, Table: TableInput},
+		{Name: "alarm", Address: 3, Table: TableCoil},
+		{Name: "running", Address: 4, Table: TableCoil},
+	})
+	type summary struct {
+		table           string
+		start, quantity uint16
+		registers       int
+	}
+	var got []summary
+	for _, sp := range spans {
+		got = append(got, summary{sp.table, sp.start, sp.quantity, len(sp.registers)})
+	}
+	test.That(t, got, test.ShouldResemble, []summary{
+		{TableCoil, 3, 2, 2},
+		{TableHolding, 10, 4, 3},
+		{TableHolding, 100, 1, 1},
+		{TableInput, 10, 1, 1},
+	})
+
+	// reads are split at the most a request may read
+	var many []Register
+	for i := 0; i < 130; i++ {
+		many = append(many, Register{Name: string(rune('a' + i)), Address: uint16(i)})
+	}
+	spans = planSpans(many)
+	test.That(t, spans, test.ShouldHaveLength, 2)
+	test.That(t, spans[0].quantity, test.ShouldEqual, maxRegistersPerRead)
+	test.That(t, spans[1].quantity, test.ShouldEqual, 5)
+}
+
+func TestReadings(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	device := newFakeDevice(t)
+
+	device.setWords(device.holding, 10, 215)
+	device.setWords(device.holding, 11, 0xFF38) // -200
+	f := math.Float32bits(3.5)
+	device.setWords(device.holding, 12, uint16(f>>16), uint16(f))
+	device.setWords(device.holding, 14, uint16(f), uint16(f>>16))
+	device.setWords(device.holding, 16, 0x0001, 0x0002)
+	device.setWords(device.input, 0, 1)
+	device.mu.Lock()
+	device.coils[5] = false
+	device.coils[6] = true
+	device.mu.Unlock()
+
+	tenth := 0.1
+	unitID := device.unitID
+	conf := resource.Config{
+		Name:  "plc",
+		Model: model,
+		API:   sensor.API,
+		ConvertedAttributes: &Config{
+			Protocol: ProtocolTCP,
+			Address:  device.listener.Addr().String(),
+			UnitID:   &unitID,
+			Registers: []Register{
+				{Name: "temperature", Address: 10, Scale: &tenth},
+				{Name: "setpoint", Address: 11, Type: TypeInt16, Offset: 1000},
+				{Name: "flow", Address: 12, Type: TypeFloat32},
+				{Name: "pressure", Address: 14, Type: TypeFloat32, WordSwap: true},
+				{Name: "count", Address: 16, Type: TypeUint32},
+				{Name: "running", Address: 0, Table: TableInput, Type: TypeBool},
+				{Name: "alarm", Address: 5, Table: TableCoil},
+				{Name: "ready", Address: 6, Table: TableCoil},
+			},
+		},
+	}
+	s, err := newModbusSensor(ctx, nil, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, s.Close(ctx), test.ShouldBeNil)
+	}()
+
+	readings, err := s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["temperature"], test.ShouldAlmostEqual, 21.5)
+	test.That(t, readings["setpoint"], test.ShouldEqual, 800)
+	test.That(t, readings["flow"], test.ShouldEqual, 3.5)
+	test.That(t, readings["pressure"], test.ShouldEqual, 3.5)
+	test.That(t, readings["count"], test.ShouldEqual, 65538)
+	test.That(t, readings["running"], test.ShouldEqual, true)
+	test.That(t, readings["alarm"], test.ShouldEqual, false)
+	test.That(t, readings["ready"], test.ShouldEqual, true)
+	// one read of each table
+	test.That(t, device.requestCount(), test.ShouldEqual, 3)
+
+	t.Run("fails when a register cannot be read", func(t *testing.T) {
+		conf := conf
+		attrs := *conf.ConvertedAttributes.(*Config)
+		attrs.Registers = []Register{{Name: "unmapped", Address: 500}}
+		conf.ConvertedAttributes = &attrs
+		s, err := newModbusSensor(ctx, nil, conf, logger)
+		test.That(t, err, test.ShouldBeNil)
+		defer func() {
+			test.That(t, s.Close(ctx), test.ShouldBeNil)
+		}()
+		_, err = s.Readings(ctx, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "failed to read 1 holding registers from address 500")
+	})
+}
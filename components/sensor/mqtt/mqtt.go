@@ -0,0 +1,246 @@
+// Package mqtt implements a sensor which subscribes to MQTT topics and reads as the latest payload of each, and which
+// publishes the payloads given to DoCommand, so that a site's existing IoT devices can feed and trigger robot logic
+// without a bridge of their own.
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var model = resource.DefaultModelFamily.WithModel("mqtt")
+
+const (
+	defaultTimeoutMs  = 5000
+	disconnectQuiesce = 250 // ms
+	publishCommand    = "publish"
+)
+
+// Config is used for converting config attributes.
+type Config struct {
+	// Broker is the URL of the broker, such as tcp://broker.local:1883, ssl://broker.local:8883 or
+	// ws://broker.local:8080/mqtt.
+	Broker string `json:"broker"`
+	// ClientID defaults to the name of the sensor with a random suffix, since brokers disconnect a client when another
+	// connects with its ID.
+	ClientID string `json:"client_id,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Topics are the topic filters subscribed to, which may have + and # wildcards.
+	Topics []string `json:"topics"`
+	// QoS is the quality of service of subscriptions and publishes, from 0 to 2.
+	QoS byte `json:"qos,omitempty"`
+	// PublishTopic is where DoCommand publishes payloads which do not name a topic.
+	PublishTopic string `json:"publish_topic,omitempty"`
+	// TimeoutMs is how long connecting and publishing may take, defaulting to 5 seconds.
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.Broker == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "broker")
+	}
+	broker, err := url.Parse(conf.Broker)
+	if err != nil || broker.Scheme == "" || broker.Host == "" {
+		return nil, resource.NewConfigValidationError(path,
+			errors.Errorf("broker must be a URL such as tcp://broker.local:1883, got %q", conf.Broker))
+	}
+	if len(conf.Topics) == 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "topics")
+	}
+	for _, topic := range conf.Topics {
+		if topic == "" {
+			return nil, resource.NewConfigValidationError(path, errors.New("topics cannot be empty"))
+		}
+	}
+	if conf.QoS > 2 {
+		return nil, resource.NewConfigValidationError(path, errors.Errorf("qos must be 0, 1 or 2, got %d", conf.QoS))
+	}
+	if strings.ContainsAny(conf.PublishTopic, "+#") {
+		return nil, resource.NewConfigValidationError(path, errors.New("publish_topic cannot have wildcards"))
+	}
+	if conf.TimeoutMs < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("timeout_ms cannot be negative"))
+	}
+	return nil, nil
+}
+
+func init() {
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, *Config]{
+			Constructor: newMQTTSensor,
+		})
+}
+
+type mqttSensor struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger logging.Logger
+
+	client       paho.Client
+	qos          byte
+	publishTopic string
+	timeout      time.Duration
+
+	mu       sync.Mutex
+	payloads map[string]interface{}
+}
+
+func newMQTTSensor(
+	ctx context.Context,
+	_ resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (sensor.Sensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &mqttSensor{
+		Named:        conf.ResourceName().AsNamed(),
+		logger:       logger,
+		qos:          newConf.QoS,
+		publishTopic: newConf.PublishTopic,
+		timeout:      defaultTimeoutMs * time.Millisecond,
+		payloads:     map[string]interface{}{},
+	}
+	if newConf.TimeoutMs != 0 {
+		s.timeout = time.Duration(newConf.TimeoutMs) * time.Millisecond
+	}
+	clientID := newConf.ClientID
+	if clientID == "" {
+		clientID = fmt.Sprintf("%s-%s", conf.ResourceName().ShortName(), uuid.NewString()[:8])
+	}
+	filters := map[string]byte{}
+	for _, topic := range newConf.Topics {
+		filters[topic] = newConf.QoS
+	}
+
+	opts := paho.NewClientOptions().
+		AddBroker(newConf.Broker).
+		SetClientID(clientID).
+		SetUsername(newConf.Username).
+		SetPassword(newConf.Password).
+		SetConnectTimeout(s.timeout).
+		SetWriteTimeout(s.timeout).
+		// the broker may be down when the sensor is built, and go down later, so both are retried in the background
+		SetConnectRetry(true).
+		SetAutoReconnect(true).
+		SetOrderMatters(false).
+		// sessions are clean, so the subscriptions are made again on every connection
+		SetOnConnectHandler(func(client paho.Client) {
+			token := client.SubscribeMultiple(filters, s.handleMessage)
+			if !token.WaitTimeout(s.timeout) {
+				logger.Warnw("timed out subscribing to MQTT topics", "topics", newConf.Topics)
+			} else if err := token.Error(); err != nil {
+				logger.Warnw("failed to subscribe to MQTT topics", "topics", newConf.Topics, "error", err)
+			}
+		}).
+		SetConnectionLostHandler(func(_ paho.Client, err error) {
+			logger.Warnw("lost connection to the MQTT broker, reconnecting", "broker", newConf.Broker, "error", err)
+		})
+	s.client = paho.NewClient(opts)
+	s.client.Connect()
+	return s, nil
+}
+
+// handleMessage keeps the payload as the latest of its topic, decoded if it is JSON and as a string otherwise.
+func (s *mqttSensor) handleMessage(_ paho.Client, msg paho.Message) {
+	var payload interface{}
+	if err := json.Unmarshal(msg.Payload(), &payload); err != nil {
+		payload = string(msg.Payload())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.payloads[msg.Topic()] = payload
+}
+
+// Readings returns the latest payload of each topic a message has arrived on, keyed by the topic. Topics matched by
+// a wildcard are each keyed by their own name.
+func (s *mqttSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	if !s.client.IsConnectionOpen() {
+		return nil, errors.New("not connected to the MQTT broker")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	readings := make(map[string]interface{}, len(s.payloads))
+	for topic, payload := range s.payloads {
+		readings[topic] = payload
+	}
+	return readings, nil
+}
+
+// DoCommand publishes a payload, to the topic it names or to publish_topic. Strings are published as they are and
+// anything else as JSON.
+//
+//	{"command": "publish", "topic": "site/conveyor/stop", "payload": {"reason": "obstacle"}, "retain": false}
+func (s *mqttSensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	if name != publishCommand {
+		return nil, errors.Errorf("unknown command %q", name)
+	}
+
+	topic := s.publishTopic
+	if t, ok := cmd["topic"].(string); ok {
+		topic = t
+	}
+	if topic == "" {
+		return nil, errors.New("publish needs a topic, or publish_topic configured")
+	}
+	if strings.ContainsAny(topic, "+#") {
+		return nil, errors.Errorf("cannot publish to topic %q with wildcards", topic)
+	}
+	raw, ok := cmd["payload"]
+	if !ok {
+		return nil, errors.New("publish needs a payload")
+	}
+	var payload []byte
+	if str, isString := raw.(string); isString {
+		payload = []byte(str)
+	} else {
+		var err error
+		if payload, err = json.Marshal(raw); err != nil {
+			return nil, errors.Wrap(err, "failed to encode the payload as JSON")
+		}
+	}
+	retain, _ := cmd["retain"].(bool)
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	token := s.client.Publish(topic, s.qos, retain, payload)
+	select {
+	case <-token.Done():
+	case <-ctx.Done():
+		return nil, errors.Wrapf(ctx.Err(), "failed to publish to %q", topic)
+	}
+	if err := token.Error(); err != nil {
+		return nil, errors.Wrapf(err, "failed to publish to %q", topic)
+	}
+	return map[string]interface{}{"topic": topic}, nil
+}
+
+// Close disconnects from the broker, giving publishes in flight a moment to finish.
+func (s *mqttSensor) Close(ctx context.Context) error {
+	s.client.Disconnect(disconnectQuiesce)
+	return nil
+}
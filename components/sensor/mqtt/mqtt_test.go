@@ -0,0 +1,271 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// fakeBroker is an MQTT 3.1.1 broker with just enough of the protocol to connect, subscribe and pass QoS 0 messages
+// between its clients and the test.
+type fakeBroker struct {
+	listener net.Listener
+
+	mu          sync.Mutex
+	subscribers map[net.Conn][]string
+	published   []publication
+}
+
+type publication struct {
+	topic   string
+	payload string
+	retain  bool
+}
+
+func newFakeBroker(t *testing.T) *fakeBroker {
+	t.Helper()
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	b := &fakeBroker{listener: listener, subscribers: map[net.Conn][]string{}}
+	go b.serve()
+	t.Cleanup(func() { test.That(t, listener.Close(), test.ShouldBeNil) })
+	return b
+}
+
+func (b *fakeBroker) url() string {
+	return "tcp://" + b.listener.Addr().String()
+}
+
+func (b *fakeBroker) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go b.handle(conn)
+	}
+}
+
+func (b *fakeBroker) handle(conn net.Conn) {
+	defer func() {
+		b.mu.Lock()
+		delete(b.subscribers, conn)
+		b.mu.Unlock()
+		conn.Close()
+	}()
+	r := bufio.NewReader(conn)
+	for {
+		header, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+		length, err := binary.ReadUvarint(r)
+		if err != nil {
+			return
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return
+		}
+		switch header >> 4 {
+		case 1: // CONNECT
+			if _, err := conn.Write([]byte{0x20, 2, 0, 0}); err != nil {
+				return
+			}
+		case 3: // PUBLISH
+			topic, rest := readString(body)
+			b.mu.Lock()
+			b.published = append(b.published, publication{topic: topic, payload: string(rest), retain: header&1 != 0})
+			b.mu.Unlock()
+			b.publish(topic, string(rest))
+		case 8: // SUBSCRIBE
+			packetID, rest := body[:2], body[2:]
+			var filters []string
+			for len(rest) > 0 {
+				var filter string
+				filter, rest = readString(rest)
+				filters = append(filters, filter)
+				rest = rest[1:]
+			}
+			b.mu.Lock()
+			b.subscribers[conn] = append(b.subscribers[conn], filters...)
+			b.mu.Unlock()
+			// grant QoS 0 to every filter
+			ack := append([]byte{0x90, byte(2 + len(filters))}, packetID...)
+			if _, err := conn.Write(append(ack, make([]byte, len(filters))...)); err != nil {
+				return
+			}
+		case 12: // PINGREQ
+			if _, err := conn.Write([]byte{0xD0, 0}); err != nil {
+				return
+			}
+		case 14: // DISCONNECT
+			return
+		}
+	}
+}
+
+// publish sends a message to every client subscribed to a filter matching the topic.
+func (b *fakeBroker) publish(topic, payload string) {
+	body := binary.BigEndian.AppendUint16(nil, uint16(len(topic)))
+	body = append(append(body, topic...), payload...)
+	packet := binary.AppendUvarint([]byte{0x30}, uint64(len(body)))
+	packet = append(packet, body...)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for conn, filters := range b.subscribers {
+		for _, filter := range filters {
+			if topicMatches(filter, topic) {
+				//nolint:errcheck
+				conn.Write(packet)
+				break
+			}
+		}
+	}
+}
+
+func (b *fakeBroker) subscribed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers) > 0
+}
+
+func (b *fakeBroker) publications() []publication {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]publication(nil), b.published...)
+}
+
+func readString(data []byte) (string, []byte) {
+	n := binary.BigEndian.Uint16(data)
+	return string(data[2 : 2+n]), data[2+n:]
+}
+
+func topicMatches(filter, topic string) bool {
+	filterLevels, topicLevels := strings.Split(filter, "/"), strings.Split(topic, "/")
+	for i, level := range filterLevels {
+		if level == "#" {
+			return true
+		}
+		if i >= len(topicLevels) || (level != "+" && level != topicLevels[i]) {
+			return false
+		}
+	}
+	return len(filterLevels) == len(topicLevels)
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		conf  Config
+		field string
+		err   string
+	}{
+		{conf: Config{Topics: []string{"a"}}, field: "broker"},
+		{conf: Config{Broker: "broker.local:1883", Topics: []string{"a"}}, err: "broker must be a URL"},
+		{conf: Config{Broker: "tcp://broker.local:1883"}, field: "topics"},
+		{conf: Config{Broker: "tcp://broker.local:1883", Topics: []string{""}}, err: "topics cannot be empty"},
+		{conf: Config{Broker: "tcp://broker.local:1883", Topics: []string{"a"}, QoS: 3}, err: "qos must be"},
+		{conf: Config{Broker: "tcp://broker.local:1883", Topics: []string{"a"}, PublishTopic: "a/#"}, err: "wildcards"},
+		{conf: Config{Broker: "tcp://broker.local:1883", Topics: []string{"a/+/temperature"}, PublishTopic: "a/b"}},
+	} {
+		_, err := tc.conf.Validate("path")
+		switch {
+		case tc.field != "":
+			test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, tc.field)
+		case tc.err != "":
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldContainSubstring, tc.err)
+		default:
+			test.That(t, err, test.ShouldBeNil)
+		}
+	}
+}
+
+func TestMQTTSensor(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	broker := newFakeBroker(t)
+
+	s, err := newMQTTSensor(ctx, nil, resource.Config{
+		Name:  "site",
+		Model: model,
+		API:   sensor.API,
+		ConvertedAttributes: &Config{
+			Broker:       broker.url(),
+			Topics:       []string{"site/+/temperature", "site/door"},
+			PublishTopic: "site/robot",
+		},
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, s.Close(ctx), test.ShouldBeNil)
+	}()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, broker.subscribed(), test.ShouldBeTrue)
+	})
+
+	readings, err := s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings, test.ShouldBeEmpty)
+
+	broker.publish("site/hall/temperature", `{"celsius": 21.5}`)
+	broker.publish("site/door", "open")
+	broker.publish("site/hall/humidity", "40")
+	broker.publish("site/lab/temperature", "19")
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		readings, err := s.Readings(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, readings, test.ShouldResemble, map[string]interface{}{
+			"site/hall/temperature": map[string]interface{}{"celsius": 21.5},
+			"site/lab/temperature":  19.,
+			"site/door":             "open",
+		})
+	})
+
+	// the latest payload replaces the one before it
+	broker.publish("site/door", "closed")
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		readings, err := s.Readings(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, readings["site/door"], test.ShouldEqual, "closed")
+	})
+
+	t.Run("publish", func(t *testing.T) {
+		resp, err := s.DoCommand(ctx, map[string]interface{}{"command": "publish", "payload": map[string]interface{}{"state": "idle"}})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp, test.ShouldResemble, map[string]interface{}{"topic": "site/robot"})
+		_, err = s.DoCommand(ctx, map[string]interface{}{
+			"command": "publish", "topic": "site/conveyor/stop", "payload": "now", "retain": true,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			test.That(tb, broker.publications(), test.ShouldResemble, []publication{
+				{topic: "site/robot", payload: `{"state":"idle"}`},
+				{topic: "site/conveyor/stop", payload: "now", retain: true},
+			})
+		})
+
+		_, err = s.DoCommand(ctx, map[string]interface{}{"command": "publish", "topic": "site/#", "payload": "now"})
+		test.That(t, err, test.ShouldBeError, `cannot publish to topic "site/#" with wildcards`)
+		_, err = s.DoCommand(ctx, map[string]interface{}{"command": "publish"})
+		test.That(t, err, test.ShouldBeError, "publish needs a payload")
+		_, err = s.DoCommand(ctx, map[string]interface{}{"command": "subscribe"})
+		test.That(t, err, test.ShouldBeError, `unknown command "subscribe"`)
+	})
+}
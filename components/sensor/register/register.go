@@ -3,9 +3,16 @@ package register
 
 import (
 	// for Sensors.
+	_ "go.viam.com/rdk/components/sensor/ble"
 	_ "go.viam.com/rdk/components/sensor/bme280"
+	_ "go.viam.com/rdk/components/sensor/cached"
 	_ "go.viam.com/rdk/components/sensor/ds18b20"
 	_ "go.viam.com/rdk/components/sensor/fake"
+	_ "go.viam.com/rdk/components/sensor/hx711"
+	_ "go.viam.com/rdk/components/sensor/modbus"
+	_ "go.viam.com/rdk/components/sensor/mqtt"
 	_ "go.viam.com/rdk/components/sensor/sht3xd"
+	_ "go.viam.com/rdk/components/sensor/smartdevice"
 	_ "go.viam.com/rdk/components/sensor/ultrasonic"
+	_ "go.viam.com/rdk/components/sensor/vibration"
 )
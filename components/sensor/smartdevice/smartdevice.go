@@ -0,0 +1,101 @@
+// Package smartdevice implements a sensor which reads a Zigbee or Z-Wave device paired with a gateway service, and
+// switches it on and off if it is a switch:
+//
+//	{"command": "set", "on": true}
+package smartdevice
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/services/generic/gateway"
+)
+
+var model = resource.DefaultModelFamily.WithModel("smart_device")
+
+// Config is used for converting config attributes.
+type Config struct {
+	// Gateway is the name of the gateway service the device is paired with.
+	Gateway string `json:"gateway"`
+	// Device is the name or ID of the device.
+	Device string `json:"device"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.Gateway == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "gateway")
+	}
+	if conf.Device == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "device")
+	}
+	return []string{conf.Gateway}, nil
+}
+
+func init() {
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, *Config]{
+			Constructor: newSmartDevice,
+		})
+}
+
+type smartDevice struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+	gateway gateway.Gateway
+	device  string
+}
+
+func newSmartDevice(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (sensor.Sensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	gw, err := resource.FromDependencies[gateway.Gateway](deps, generic.Named(newConf.Gateway))
+	if err != nil {
+		return nil, err
+	}
+	// a device which is yet to pair is read once it has, so it need not be known now
+	return &smartDevice{
+		Named:   conf.ResourceName().AsNamed(),
+		gateway: gw,
+		device:  newConf.Device,
+	}, nil
+}
+
+// Readings returns the latest values the device reported.
+func (s *smartDevice) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	device, err := s.gateway.Device(s.device)
+	if err != nil {
+		return nil, err
+	}
+	return device.Readings, nil
+}
+
+// DoCommand switches the device on or off for {"command": "set", "on": true}.
+func (s *smartDevice) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if cmd["command"] != "set" {
+		return nil, resource.ErrDoUnimplemented
+	}
+	on, ok := cmd["on"].(bool)
+	if !ok {
+		return nil, errors.New("set needs on to be true or false")
+	}
+	if err := s.gateway.SetOn(ctx, s.device, on); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{}, nil
+}
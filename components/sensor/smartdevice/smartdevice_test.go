@@ -0,0 +1,90 @@
+package smartdevice
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/services/generic/gateway"
+)
+
+// fakeGateway has a single lamp paired with it.
+type fakeGateway struct {
+	resource.Named
+	resource.TriviallyReconfigurable
+	resource.TriviallyCloseable
+	on bool
+}
+
+func (g *fakeGateway) Devices() []gateway.Device {
+	lamp, _ := g.Device("lamp")
+	return []gateway.Device{lamp}
+}
+
+func (g *fakeGateway) Device(nameOrID string) (gateway.Device, error) {
+	if nameOrID != "lamp" {
+		return gateway.Device{}, errors.Errorf("no device %q", nameOrID)
+	}
+	return gateway.Device{ID: "node_5", Name: "lamp", Protocol: "zwave", Switch: true, Readings: map[string]interface{}{"on": g.on}}, nil
+}
+
+func (g *fakeGateway) PermitJoin(ctx context.Context, duration time.Duration) error {
+	return nil
+}
+
+func (g *fakeGateway) SetOn(ctx context.Context, nameOrID string, on bool) error {
+	g.on = on
+	return nil
+}
+
+func (g *fakeGateway) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return nil, resource.ErrDoUnimplemented
+}
+
+func TestValidate(t *testing.T) {
+	_, err := (&Config{Device: "lamp"}).Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "gateway")
+	_, err = (&Config{Gateway: "zwave"}).Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "device")
+	deps, err := (&Config{Gateway: "zwave", Device: "lamp"}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"zwave"})
+}
+
+func TestSmartDevice(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	gw := &fakeGateway{Named: generic.Named("zwave").AsNamed()}
+	deps := resource.Dependencies{gw.Name(): gw}
+	conf := resource.Config{
+		Name:                "lamp",
+		API:                 sensor.API,
+		Model:               model,
+		ConvertedAttributes: &Config{Gateway: "zwave", Device: "lamp"},
+	}
+	s, err := newSmartDevice(ctx, deps, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	_, err = s.DoCommand(ctx, map[string]interface{}{"command": "set", "on": true})
+	test.That(t, err, test.ShouldBeNil)
+	readings, err := s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings, test.ShouldResemble, map[string]interface{}{"on": true})
+
+	_, err = s.DoCommand(ctx, map[string]interface{}{"command": "set"})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.ConvertedAttributes = &Config{Gateway: "zwave", Device: "fan"}
+	s, err = newSmartDevice(ctx, deps, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	_, err = s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+}
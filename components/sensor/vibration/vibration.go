@@ -0,0 +1,329 @@
+// Package vibration implements a sensor which analyzes the vibration measured by the accelerometer of a movement
+// sensor, for predictive maintenance of motors and spindles. It samples the linear acceleration at a fixed rate and
+// publishes the RMS of each axis, the frequency of its strongest vibration and the RMS within configured frequency
+// bands, such as the bands of a bearing's fault frequencies.
+package vibration
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+	"gonum.org/v1/gonum/dsp/fourier"
+	"gonum.org/v1/gonum/dsp/window"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("vibration")
+
+const (
+	defaultSampleRateHz = 1000.
+	defaultWindowSize   = 1024
+	minWindowSize       = 16
+	maxWindowSize       = 65536
+)
+
+var axes = []string{"x", "y", "z"}
+
+// Band is a range of frequencies whose vibration is published.
+type Band struct {
+	Name  string  `json:"name"`
+	MinHz float64 `json:"min_hz"`
+	MaxHz float64 `json:"max_hz"`
+}
+
+// Config is used for converting config attributes.
+type Config struct {
+	MovementSensor string `json:"movement_sensor"`
+	// SampleRateHz is how often the linear acceleration is read. Only frequencies below half of it can be measured.
+	SampleRateHz float64 `json:"sample_rate_hz,omitempty"`
+	// WindowSize is how many samples each analysis covers, which sets its frequency resolution to
+	// SampleRateHz/WindowSize.
+	WindowSize int    `json:"window_size,omitempty"`
+	Bands      []Band `json:"bands,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.MovementSensor == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "movement_sensor")
+	}
+	if conf.SampleRateHz < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("sample_rate_hz cannot be negative"))
+	}
+	if conf.WindowSize != 0 && (conf.WindowSize < minWindowSize || conf.WindowSize > maxWindowSize) {
+		return nil, resource.NewConfigValidationError(path,
+			errors.Errorf("window_size must be between %d and %d, got %d", minWindowSize, maxWindowSize, conf.WindowSize))
+	}
+	nyquist := conf.sampleRateHz() / 2
+	names := map[string]bool{}
+	for i, band := range conf.Bands {
+		if band.Name == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(fmt.Sprintf("%s.bands.%d", path, i), "name")
+		}
+		if names[band.Name] {
+			return nil, resource.NewConfigValidationError(path, errors.Errorf("band %q is configured more than once", band.Name))
+		}
+		names[band.Name] = true
+		if band.MinHz < 0 || band.MaxHz <= band.MinHz {
+			return nil, resource.NewConfigValidationError(path,
+				errors.Errorf("band %q must have 0 <= min_hz < max_hz", band.Name))
+		}
+		if band.MinHz >= nyquist {
+			return nil, resource.NewConfigValidationError(path,
+				errors.Errorf("band %q starts above %v Hz, the highest frequency sampling at %v Hz can measure",
+					band.Name, nyquist, conf.sampleRateHz()))
+		}
+	}
+	return []string{conf.MovementSensor}, nil
+}
+
+func (conf *Config) sampleRateHz() float64 {
+	if conf.SampleRateHz == 0 {
+		return defaultSampleRateHz
+	}
+	return conf.SampleRateHz
+}
+
+func (conf *Config) windowSize() int {
+	if conf.WindowSize == 0 {
+		return defaultWindowSize
+	}
+	return conf.WindowSize
+}
+
+func init() {
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, *Config]{
+			Constructor: newSensor,
+		})
+}
+
+type vibrationSensor struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger logging.Logger
+
+	accelerometer movementsensor.MovementSensor
+	rateHz        float64
+	windowSize    int
+	bands         []Band
+
+	mu      sync.Mutex
+	samples []r3.Vector
+	times   []time.Time
+	// next is where the next sample goes in samples, which is a ring buffer once it is full.
+	next    int
+	lastErr error
+
+	workers utils.StoppableWorkers
+}
+
+func newSensor(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (sensor.Sensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	accelerometer, err := movementsensor.FromDependencies(deps, newConf.MovementSensor)
+	if err != nil {
+		return nil, err
+	}
+	props, err := accelerometer.Properties(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !props.LinearAccelerationSupported {
+		return nil, errors.Errorf("movement sensor %q does not measure linear acceleration", newConf.MovementSensor)
+	}
+
+	s := &vibrationSensor{
+		Named:         conf.ResourceName().AsNamed(),
+		logger:        logger,
+		accelerometer: accelerometer,
+		rateHz:        newConf.sampleRateHz(),
+		windowSize:    newConf.windowSize(),
+		bands:         newConf.Bands,
+	}
+	s.samples = make([]r3.Vector, 0, s.windowSize)
+	s.times = make([]time.Time, 0, s.windowSize)
+	s.workers = utils.NewStoppableWorkers(s.sample)
+	return s, nil
+}
+
+// sample reads the linear acceleration at the sample rate until it is stopped. Samples due while a slow read is
+// still going are read late rather than skipped, and a failed read starts the window over, since a gap in the
+// samples would show up as vibration.
+func (s *vibrationSensor) sample(ctx context.Context) {
+	interval := time.Duration(float64(time.Second) / s.rateHz)
+	due := time.Now()
+	for {
+		if !goutils.SelectContextOrWait(ctx, time.Until(due)) {
+			return
+		}
+		acc, err := s.accelerometer.LinearAcceleration(ctx, nil)
+		now := time.Now()
+		s.mu.Lock()
+		if err != nil {
+			if ctx.Err() == nil {
+				s.lastErr = err
+			}
+			s.samples, s.times, s.next = s.samples[:0], s.times[:0], 0
+		} else {
+			s.lastErr = nil
+			s.add(acc, now)
+		}
+		s.mu.Unlock()
+		due = due.Add(interval)
+		if now.Sub(due) > time.Duration(s.windowSize)*interval {
+			// After a long stall, such as the host suspending, start sampling again from now rather than reading
+			// every missed sample back to back.
+			due = now
+		}
+	}
+}
+
+// add adds a sample to the window, replacing the oldest once it is full. It must be called with mu held.
+func (s *vibrationSensor) add(acc r3.Vector, ts time.Time) {
+	if len(s.samples) < s.windowSize {
+		s.samples = append(s.samples, acc)
+		s.times = append(s.times, ts)
+		return
+	}
+	s.samples[s.next] = acc
+	s.times[s.next] = ts
+	s.next = (s.next + 1) % s.windowSize
+}
+
+// window returns the samples of the window in the order they were read, and the rate they were read at.
+func (s *vibrationSensor) window() ([]r3.Vector, float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.samples) < s.windowSize {
+		if s.lastErr != nil {
+			return nil, 0, errors.Wrap(s.lastErr, "failed to read the linear acceleration")
+		}
+		return nil, 0, errors.Errorf("only %d of the %d samples of a window have been read yet", len(s.samples), s.windowSize)
+	}
+	samples := append(append(make([]r3.Vector, 0, s.windowSize), s.samples[s.next:]...), s.samples[:s.next]...)
+	first := s.times[s.next]
+	last := s.times[(s.next+s.windowSize-1)%s.windowSize]
+	// The frequencies are measured against the rate the samples were actually read at, which falls behind the
+	// configured rate when the reads are slow.
+	rateHz := s.rateHz
+	if elapsed := last.Sub(first).Seconds(); elapsed > 0 {
+		rateHz = float64(s.windowSize-1) / elapsed
+	}
+	return samples, rateHz, nil
+}
+
+// Readings returns the vibration of each axis over the latest window of samples.
+func (s *vibrationSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	samples, rateHz, err := s.window()
+	if err != nil {
+		return nil, err
+	}
+	readings := map[string]interface{}{"sample_rate_hz": rateHz}
+	signal := make([]float64, len(samples))
+	for i, axis := range axes {
+		for j, sample := range samples {
+			signal[j] = [3]float64{sample.X, sample.Y, sample.Z}[i]
+		}
+		analysis := analyze(signal, rateHz, s.bands)
+		readings[axis+"_rms"] = analysis.RMS
+		readings[axis+"_peak_hz"] = analysis.PeakHz
+		for _, band := range s.bands {
+			readings[axis+"_"+band.Name+"_rms"] = analysis.BandRMS[band.Name]
+		}
+	}
+	return readings, nil
+}
+
+// Close stops sampling.
+func (s *vibrationSensor) Close(ctx context.Context) error {
+	s.workers.Stop()
+	return nil
+}
+
+// analysis is the vibration of a signal.
+type analysis struct {
+	// RMS is the RMS of the signal about its mean, so that gravity and other constant accelerations are left out.
+	RMS float64
+	// PeakHz is the frequency with the most energy.
+	PeakHz float64
+	// BandRMS is the RMS of the signal within each band, by name.
+	BandRMS map[string]float64
+}
+
+// analyze returns the vibration of a signal sampled at rateHz. The signal is Hann windowed before its spectrum is
+// taken, which keeps the energy of strong vibrations from leaking into far away bands, and the band RMS values are
+// scaled back up for the energy the window takes out, so that they add up to the RMS of the whole signal.
+func analyze(signal []float64, rateHz float64, bands []Band) analysis {
+	n := len(signal)
+	var mean float64
+	for _, v := range signal {
+		mean += v
+	}
+	mean /= float64(n)
+	centered := make([]float64, n)
+	var sumSquares float64
+	for i, v := range signal {
+		centered[i] = v - mean
+		sumSquares += centered[i] * centered[i]
+	}
+	result := analysis{RMS: math.Sqrt(sumSquares / float64(n)), BandRMS: map[string]float64{}}
+
+	ones := make([]float64, n)
+	for i := range ones {
+		ones[i] = 1
+	}
+	var windowPower float64
+	for _, w := range window.Hann(ones) {
+		windowPower += w * w
+	}
+	coeffs := fourier.NewFFT(n).Coefficients(nil, window.Hann(centered))
+
+	// By Parseval's theorem, the mean square of a real signal is the sum over the positive frequencies of twice
+	// their energy, apart from the zero and Nyquist frequencies, which have no negative counterpart.
+	power := make([]float64, len(coeffs))
+	peak := 0
+	for k, c := range coeffs {
+		energy := cmplx.Abs(c)
+		power[k] = energy * energy / (float64(n) * windowPower)
+		if k != 0 && (n%2 == 1 || k != n/2) {
+			power[k] *= 2
+		}
+		if power[k] > power[peak] {
+			peak = k
+		}
+	}
+	binHz := rateHz / float64(n)
+	result.PeakHz = float64(peak) * binHz
+	for _, band := range bands {
+		var bandPower float64
+		for k := range power {
+			if hz := float64(k) * binHz; hz >= band.MinHz && hz < band.MaxHz {
+				bandPower += power[k]
+			}
+		}
+		result.BandRMS[band.Name] = math.Sqrt(bandPower)
+	}
+	return result
+}
@@ -0,0 +1,142 @@
+package vibration
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+const accelerometerName = "imu"
+
+func TestValidate(t *testing.T) {
+	conf := &Config{}
+	_, err := conf.Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "movement_sensor")
+
+	conf.MovementSensor = accelerometerName
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{accelerometerName})
+
+	conf.WindowSize = 4
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	conf.WindowSize = 0
+
+	conf.Bands = []Band{{Name: "bearing", MinHz: 100, MaxHz: 50}}
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	conf.Bands = []Band{{Name: "bearing", MinHz: 600, MaxHz: 700}}
+	_, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "500 Hz")
+	conf.Bands = []Band{{Name: "bearing", MinHz: 100, MaxHz: 200}, {Name: "bearing", MinHz: 200, MaxHz: 300}}
+	_, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "more than once")
+	conf.Bands = []Band{{MinHz: 100, MaxHz: 200}}
+	_, err = conf.Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "name")
+}
+
+func TestAnalyze(t *testing.T) {
+	const rateHz = 1000.
+	const n = 1000
+	// 1 m/s^2 at 50Hz and 0.5 m/s^2 at 200Hz, on top of gravity
+	signal := make([]float64, n)
+	for i := range signal {
+		ts := float64(i) / rateHz
+		signal[i] = 9.81 + math.Sin(2*math.Pi*50*ts) + 0.5*math.Sin(2*math.Pi*200*ts)
+	}
+	bands := []Band{
+		{Name: "low", MinHz: 40, MaxHz: 60},
+		{Name: "high", MinHz: 150, MaxHz: 250},
+		{Name: "quiet", MinHz: 300, MaxHz: 400},
+	}
+
+	result := analyze(signal, rateHz, bands)
+	test.That(t, result.RMS, test.ShouldAlmostEqual, math.Sqrt(0.5+0.125), 1e-6)
+	test.That(t, result.PeakHz, test.ShouldAlmostEqual, 50)
+	test.That(t, result.BandRMS["low"], test.ShouldAlmostEqual, math.Sqrt(0.5), 0.01)
+	test.That(t, result.BandRMS["high"], test.ShouldAlmostEqual, math.Sqrt(0.125), 0.01)
+	test.That(t, result.BandRMS["quiet"], test.ShouldBeLessThan, 0.001)
+}
+
+func TestReadings(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	// The accelerometer vibrates along y at 20Hz, measured against when it is read.
+	start := time.Now()
+	var fail atomic.Bool
+	accelerometer := inject.NewMovementSensor(accelerometerName)
+	accelerometer.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+		return &movementsensor.Properties{LinearAccelerationSupported: true}, nil
+	}
+	accelerometer.LinearAccelerationFunc = func(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+		if fail.Load() {
+			return r3.Vector{}, errors.New("bus error")
+		}
+		return r3.Vector{Y: math.Sin(2 * math.Pi * 20 * time.Since(start).Seconds()), Z: 9.81}, nil
+	}
+	deps := resource.Dependencies{movementsensor.Named(accelerometerName): accelerometer}
+	conf := resource.Config{
+		Name:  "vibration",
+		Model: model,
+		API:   sensor.API,
+		ConvertedAttributes: &Config{
+			MovementSensor: accelerometerName,
+			SampleRateHz:   200,
+			WindowSize:     64,
+			Bands:          []Band{{Name: "motor", MinHz: 10, MaxHz: 30}},
+		},
+	}
+
+	s, err := newSensor(ctx, deps, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer s.Close(ctx)
+
+	_, err = s.Readings(ctx, nil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "of the 64 samples")
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		readings, err := s.Readings(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, readings["x_rms"], test.ShouldAlmostEqual, 0)
+		test.That(tb, readings["z_rms"], test.ShouldAlmostEqual, 0)
+		test.That(tb, readings["y_rms"], test.ShouldAlmostEqual, math.Sqrt(0.5), 0.1)
+		test.That(tb, readings["y_peak_hz"], test.ShouldAlmostEqual, 20, 5)
+		test.That(tb, readings["y_motor_rms"], test.ShouldAlmostEqual, math.Sqrt(0.5), 0.1)
+		test.That(tb, readings["sample_rate_hz"], test.ShouldBeGreaterThan, 0)
+	})
+
+	// a failed read starts the window over
+	fail.Store(true)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		_, err := s.Readings(ctx, nil)
+		test.That(tb, err, test.ShouldBeError, "failed to read the linear acceleration: bus error")
+	})
+
+	t.Run("requires linear acceleration", func(t *testing.T) {
+		gps := inject.NewMovementSensor(accelerometerName)
+		gps.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+			return &movementsensor.Properties{PositionSupported: true}, nil
+		}
+		deps := resource.Dependencies{movementsensor.Named(accelerometerName): gps}
+		_, err := newSensor(ctx, deps, conf, logger)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "does not measure linear acceleration")
+	})
+}
@@ -0,0 +1,160 @@
+package serial
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// The types of framing.
+const (
+	// FramingLine ends each frame with a newline, and strips a carriage return before it when reading.
+	FramingLine = "line"
+	// FramingDelimiter ends each frame with the delimiter bytes.
+	FramingDelimiter = "delimiter"
+	// FramingLengthPrefixed starts each frame with its length.
+	FramingLengthPrefixed = "length_prefixed"
+)
+
+// DefaultMaxFrameBytes is the longest frame read unless a Framing says otherwise.
+const DefaultMaxFrameBytes = 4096
+
+// Framing configures how payloads are split into frames on the wire.
+type Framing struct {
+	// Type is one of FramingLine, the default, FramingDelimiter and FramingLengthPrefixed.
+	Type string `json:"type,omitempty"`
+	// Delimiter ends each frame of the delimiter framing, and may use escapes such as "\r\n" or "\u0003".
+	Delimiter string `json:"delimiter,omitempty"`
+	// LengthBytes is the size of the length prefix, 1, 2 or 4 bytes, and 2 by default.
+	LengthBytes int `json:"length_bytes,omitempty"`
+	// LittleEndian reads and writes the length prefix little endian rather than big endian.
+	LittleEndian bool `json:"little_endian,omitempty"`
+	// MaxFrameBytes is the longest payload read, beyond which the device is taken to be out of sync.
+	MaxFrameBytes int `json:"max_frame_bytes,omitempty"`
+}
+
+// Validate ensures the framing can frame payloads.
+func (f *Framing) Validate() error {
+	switch f.Type {
+	case "", FramingLine, FramingLengthPrefixed:
+	case FramingDelimiter:
+		if f.Delimiter == "" {
+			return errors.New("delimiter framing needs a delimiter")
+		}
+	default:
+		return errors.Errorf("unknown framing type %q, expected one of %q, %q and %q",
+			f.Type, FramingLine, FramingDelimiter, FramingLengthPrefixed)
+	}
+	switch f.LengthBytes {
+	case 0, 1, 2, 4:
+	default:
+		return errors.New("length_bytes must be 1, 2 or 4")
+	}
+	if f.MaxFrameBytes < 0 {
+		return errors.New("max_frame_bytes cannot be negative")
+	}
+	return nil
+}
+
+func (f *Framing) delimiter() []byte {
+	if f.Type == FramingDelimiter {
+		return []byte(f.Delimiter)
+	}
+	return []byte("\n")
+}
+
+func (f *Framing) lengthBytes() int {
+	if f.LengthBytes == 0 {
+		return 2
+	}
+	return f.LengthBytes
+}
+
+func (f *Framing) maxFrameBytes() int {
+	if f.MaxFrameBytes == 0 {
+		return DefaultMaxFrameBytes
+	}
+	return f.MaxFrameBytes
+}
+
+func (f *Framing) byteOrder() binary.ByteOrder {
+	if f.LittleEndian {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
+
+// Encode returns the frame of a payload.
+func (f *Framing) Encode(payload []byte) ([]byte, error) {
+	if f.Type != FramingLengthPrefixed {
+		return append(append([]byte(nil), payload...), f.delimiter()...), nil
+	}
+	n := f.lengthBytes()
+	if uint64(len(payload)) >= 1<<(8*n) {
+		return nil, errors.Errorf("a payload of %d bytes is too long for a %d byte length prefix", len(payload), n)
+	}
+	frame := make([]byte, 8, 8+len(payload))
+	f.byteOrder().PutUint64(frame, uint64(len(payload)))
+	if f.LittleEndian {
+		frame = frame[:n]
+	} else {
+		frame = frame[8-n:]
+	}
+	return append(frame, payload...), nil
+}
+
+// Decode reads a frame and returns its payload.
+func (f *Framing) Decode(r *bufio.Reader) ([]byte, error) {
+	if f.Type == FramingLengthPrefixed {
+		return f.decodeLengthPrefixed(r)
+	}
+	delimiter := f.delimiter()
+	last := delimiter[len(delimiter)-1]
+	var frame []byte
+	for {
+		chunk, err := r.ReadSlice(last)
+		frame = append(frame, chunk...)
+		if len(frame) > f.maxFrameBytes()+len(delimiter) {
+			return nil, errors.Errorf("frame is longer than %d bytes", f.maxFrameBytes())
+		}
+		switch {
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case err != nil:
+			return nil, err
+		}
+		if len(frame) >= len(delimiter) && string(frame[len(frame)-len(delimiter):]) == string(delimiter) {
+			break
+		}
+	}
+	frame = frame[:len(frame)-len(delimiter)]
+	if f.Type != FramingDelimiter && len(frame) > 0 && frame[len(frame)-1] == '\r' {
+		frame = frame[:len(frame)-1]
+	}
+	return frame, nil
+}
+
+func (f *Framing) decodeLengthPrefixed(r *bufio.Reader) ([]byte, error) {
+	n := f.lengthBytes()
+	prefix := make([]byte, 8)
+	var err error
+	if f.LittleEndian {
+		_, err = io.ReadFull(r, prefix[:n])
+	} else {
+		_, err = io.ReadFull(r, prefix[8-n:])
+	}
+	if err != nil {
+		return nil, err
+	}
+	length := f.byteOrder().Uint64(prefix)
+	if length > uint64(f.maxFrameBytes()) {
+		return nil, errors.Errorf("frame of %d bytes is longer than %d bytes", length, f.maxFrameBytes())
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
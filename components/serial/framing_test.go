@@ -0,0 +1,69 @@
+package serial
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestFramingValidate(t *testing.T) {
+	test.That(t, (&Framing{}).Validate(), test.ShouldBeNil)
+	test.That(t, (&Framing{Type: FramingDelimiter}).Validate(), test.ShouldBeError, "delimiter framing needs a delimiter")
+	test.That(t, (&Framing{Type: FramingLengthPrefixed, LengthBytes: 3}).Validate(), test.ShouldBeError,
+		"length_bytes must be 1, 2 or 4")
+	err := (&Framing{Type: "slip"}).Validate()
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "unknown framing type")
+}
+
+func TestFraming(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		framing Framing
+		wire    []byte
+	}{
+		{"line", Framing{}, []byte("MEAS?\n")},
+		{"delimiter", Framing{Type: FramingDelimiter, Delimiter: "\r\n"}, []byte("MEAS?\r\n")},
+		{"length prefixed", Framing{Type: FramingLengthPrefixed}, []byte("\x00\x05MEAS?")},
+		{"little endian", Framing{Type: FramingLengthPrefixed, LengthBytes: 4, LittleEndian: true}, []byte("\x05\x00\x00\x00MEAS?")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			wire, err := tc.framing.Encode([]byte("MEAS?"))
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, wire, test.ShouldResemble, tc.wire)
+
+			reader := bufio.NewReader(bytes.NewReader(append(append([]byte(nil), tc.wire...), tc.wire...)))
+			for i := 0; i < 2; i++ {
+				payload, err := tc.framing.Decode(reader)
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, payload, test.ShouldResemble, []byte("MEAS?"))
+			}
+		})
+	}
+
+	t.Run("line strips a carriage return", func(t *testing.T) {
+		framing := Framing{}
+		payload, err := framing.Decode(bufio.NewReader(bytes.NewReader([]byte("OK\r\n"))))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, payload, test.ShouldResemble, []byte("OK"))
+	})
+
+	t.Run("delimiter across a partial match", func(t *testing.T) {
+		framing := Framing{Type: FramingDelimiter, Delimiter: "\r\n"}
+		payload, err := framing.Decode(bufio.NewReader(bytes.NewReader([]byte("a\nb\r\n"))))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, payload, test.ShouldResemble, []byte("a\nb"))
+	})
+
+	t.Run("frames too long", func(t *testing.T) {
+		framing := Framing{Type: FramingLengthPrefixed, LengthBytes: 1}
+		_, err := framing.Encode(make([]byte, 256))
+		test.That(t, err, test.ShouldNotBeNil)
+
+		framing = Framing{MaxFrameBytes: 4}
+		_, err = framing.Decode(bufio.NewReader(bytes.NewReader([]byte("too long\n"))))
+		test.That(t, err, test.ShouldBeError, "frame is longer than 4 bytes")
+	})
+}
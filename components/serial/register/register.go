@@ -0,0 +1,7 @@
+// Package register registers all relevant serial ports
+package register
+
+import (
+	// for serial ports.
+	_ "go.viam.com/rdk/components/serial/tty"
+)
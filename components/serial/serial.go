@@ -0,0 +1,71 @@
+// Package serial defines a serial port which frames what is written to and read from a serial device, so that the
+// components and modules of a machine share managed access to a device rather than each opening its path.
+package serial
+
+import (
+	"context"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+func init() {
+	resource.RegisterAPI(API, resource.APIRegistration[Serial]{})
+}
+
+// SubtypeName is a constant that identifies the component resource API string "serial".
+const SubtypeName = "serial"
+
+// API is a variable that identifies the component resource API.
+var API = resource.APINamespaceRDK.WithComponentType(SubtypeName)
+
+// Named is a helper for getting the named serial port's typed resource name.
+func Named(name string) resource.Name {
+	return resource.NewName(API, name)
+}
+
+// A Serial is a serial port which reads and writes whole frames, framed as the port is configured.
+//
+// Write example:
+//
+//	// Write a line to the device.
+//	err := port.Write(context.Background(), []byte("MEAS?"), nil)
+//
+// Read example:
+//
+//	// Wait for the next frame from the device.
+//	frame, err := port.Read(context.Background(), nil)
+//
+// Transact example:
+//
+//	// Ask the device for a measurement and wait for the reply, with no other client's frames in between.
+//	reply, err := port.Transact(context.Background(), []byte("MEAS?"), nil)
+type Serial interface {
+	resource.Resource
+
+	// Write frames a payload and writes it to the device.
+	Write(ctx context.Context, payload []byte, extra map[string]interface{}) error
+
+	// Read returns the payload of the next frame read from the device, waiting for one until the context is done.
+	// Each frame is returned by one Read only.
+	Read(ctx context.Context, extra map[string]interface{}) ([]byte, error)
+
+	// Transact writes a request and returns the payload of the first frame read after it, holding the port so that
+	// no other client's request comes between them.
+	Transact(ctx context.Context, request []byte, extra map[string]interface{}) ([]byte, error)
+}
+
+// FromDependencies is a helper for getting the named serial port from a collection of dependencies.
+func FromDependencies(deps resource.Dependencies, name string) (Serial, error) {
+	return resource.FromDependencies[Serial](deps, Named(name))
+}
+
+// FromRobot is a helper for getting the named serial port from the given Robot.
+func FromRobot(r robot.Robot, name string) (Serial, error) {
+	return robot.ResourceFromRobot[Serial](r, Named(name))
+}
+
+// NamesFromRobot is a helper for getting all serial port names from the given Robot.
+func NamesFromRobot(r robot.Robot) []string {
+	return robot.NamesByAPI(r, API)
+}
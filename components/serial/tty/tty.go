@@ -0,0 +1,302 @@
+// Package tty implements a serial port on a serial device of the machine, such as /dev/ttyUSB0, which reads the frames
+// of the device in the background so that frames are not lost between the reads of its clients.
+package tty
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/jacobsa/go-serial/serial"
+	"github.com/pkg/errors"
+
+	rdkserial "go.viam.com/rdk/components/serial"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("tty")
+
+const (
+	defaultBaudRate  = 115200
+	defaultTimeoutMs = 1000
+	// frameBuffer is how many frames are kept for Read before the oldest is dropped.
+	frameBuffer = 64
+)
+
+// Config is used for converting config attributes.
+type Config struct {
+	SerialPath string `json:"serial_path"`
+	// BaudRate defaults to 115200.
+	BaudRate int `json:"baud_rate,omitempty"`
+	// DataBits defaults to 8 and StopBits to 1.
+	DataBits int `json:"data_bits,omitempty"`
+	StopBits int `json:"stop_bits,omitempty"`
+	// Parity is none, the default, odd or even.
+	Parity            string `json:"parity,omitempty"`
+	RTSCTSFlowControl bool   `json:"rts_cts_flow_control,omitempty"`
+	// Framing is how payloads are framed, one per line by default.
+	Framing rdkserial.Framing `json:"framing,omitempty"`
+	// TimeoutMs is how long Transact waits for a reply, 1 second by default.
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.SerialPath == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "serial_path")
+	}
+	if conf.BaudRate < 0 || conf.TimeoutMs < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("baud_rate and timeout_ms cannot be negative"))
+	}
+	if conf.DataBits != 0 && (conf.DataBits < 5 || conf.DataBits > 8) {
+		return nil, resource.NewConfigValidationError(path, errors.New("data_bits must be between 5 and 8"))
+	}
+	if conf.StopBits != 0 && conf.StopBits != 1 && conf.StopBits != 2 {
+		return nil, resource.NewConfigValidationError(path, errors.New("stop_bits must be 1 or 2"))
+	}
+	if _, err := parity(conf.Parity); err != nil {
+		return nil, resource.NewConfigValidationError(path, err)
+	}
+	if err := conf.Framing.Validate(); err != nil {
+		return nil, resource.NewConfigValidationError(path, err)
+	}
+	return nil, nil
+}
+
+func parity(name string) (serial.ParityMode, error) {
+	switch name {
+	case "", "none":
+		return serial.PARITY_NONE, nil
+	case "odd":
+		return serial.PARITY_ODD, nil
+	case "even":
+		return serial.PARITY_EVEN, nil
+	default:
+		return serial.PARITY_NONE, errors.Errorf("unknown parity %q, expected none, odd or even", name)
+	}
+}
+
+func init() {
+	resource.RegisterComponent(
+		rdkserial.API,
+		model,
+		resource.Registration[rdkserial.Serial, *Config]{
+			Constructor: newTTY,
+		})
+}
+
+// openPort opens the serial device, and is replaced in tests.
+var openPort = func(conf *Config) (io.ReadWriteCloser, error) {
+	p, err := parity(conf.Parity)
+	if err != nil {
+		return nil, err
+	}
+	options := serial.OpenOptions{
+		PortName:          conf.SerialPath,
+		BaudRate:          uint(conf.BaudRate),
+		DataBits:          uint(conf.DataBits),
+		StopBits:          uint(conf.StopBits),
+		ParityMode:        p,
+		RTSCTSFlowControl: conf.RTSCTSFlowControl,
+		MinimumReadSize:   1,
+	}
+	if options.BaudRate == 0 {
+		options.BaudRate = defaultBaudRate
+	}
+	if options.DataBits == 0 {
+		options.DataBits = 8
+	}
+	if options.StopBits == 0 {
+		options.StopBits = 1
+	}
+	return serial.Open(options)
+}
+
+type tty struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger  logging.Logger
+	port    io.ReadWriteCloser
+	framing rdkserial.Framing
+	timeout time.Duration
+	workers rdkutils.StoppableWorkers
+
+	// transactMu holds the port through a transaction, and writeMu through a write.
+	transactMu sync.Mutex
+	writeMu    sync.Mutex
+	frames     chan []byte
+	readErr    chan error
+}
+
+func newTTY(
+	ctx context.Context,
+	_ resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (rdkserial.Serial, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	port, err := openPort(newConf)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open serial port %q", newConf.SerialPath)
+	}
+	t := &tty{
+		Named:   conf.ResourceName().AsNamed(),
+		logger:  logger,
+		port:    port,
+		framing: newConf.Framing,
+		timeout: defaultTimeoutMs * time.Millisecond,
+		frames:  make(chan []byte, frameBuffer),
+		readErr: make(chan error, 1),
+	}
+	if newConf.TimeoutMs != 0 {
+		t.timeout = time.Duration(newConf.TimeoutMs) * time.Millisecond
+	}
+	t.workers = rdkutils.NewStoppableWorkers(t.readFrames)
+	return t, nil
+}
+
+// readFrames reads frames from the device until it is closed, keeping the latest frameBuffer of them for Read.
+func (t *tty) readFrames(ctx context.Context) {
+	reader := bufio.NewReader(t.port)
+	for {
+		frame, err := t.framing.Decode(reader)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) {
+				t.readErr <- errors.Wrap(err, "serial port closed")
+				return
+			}
+			// a frame which cannot be decoded is dropped, and reading starts over at the next
+			t.logger.CWarnw(ctx, "dropping serial frame", "error", err)
+			continue
+		}
+		for {
+			select {
+			case t.frames <- frame:
+			default:
+				select {
+				case dropped := <-t.frames:
+					t.logger.CDebugw(ctx, "serial frame buffer full, dropping oldest frame", "frame", dropped)
+				default:
+				}
+				continue
+			}
+			break
+		}
+	}
+}
+
+// Write frames a payload and writes it to the device.
+func (t *tty) Write(ctx context.Context, payload []byte, extra map[string]interface{}) error {
+	t.transactMu.Lock()
+	defer t.transactMu.Unlock()
+	return t.write(payload)
+}
+
+func (t *tty) write(payload []byte) error {
+	frame, err := t.framing.Encode(payload)
+	if err != nil {
+		return err
+	}
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	_, err = t.port.Write(frame)
+	return err
+}
+
+// Read returns the payload of the next frame read from the device.
+func (t *tty) Read(ctx context.Context, extra map[string]interface{}) ([]byte, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case frame := <-t.frames:
+		return frame, nil
+	case err := <-t.readErr:
+		t.readErr <- err
+		return nil, err
+	}
+}
+
+// Transact writes a request and returns the payload of the first frame read after it.
+func (t *tty) Transact(ctx context.Context, request []byte, extra map[string]interface{}) ([]byte, error) {
+	t.transactMu.Lock()
+	defer t.transactMu.Unlock()
+	// frames read before the request cannot be its reply
+	for drained := false; !drained; {
+		select {
+		case <-t.frames:
+		default:
+			drained = true
+		}
+	}
+	if err := t.write(request); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	reply, err := t.Read(ctx, extra)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, errors.Errorf("no reply from the serial device within %v", t.timeout)
+	}
+	return reply, err
+}
+
+// DoCommand writes, reads and transacts payloads, given as text or as base64 data, and returns payloads as both:
+//
+//	{"command": "write", "text": "MEAS?"}
+//	{"command": "read"}
+//	{"command": "transact", "data": "TUVBUz8="}
+func (t *tty) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, _ := cmd["command"].(string)
+	var payload []byte
+	if name == "write" || name == "transact" {
+		var err error
+		if payload, err = commandPayload(cmd); err != nil {
+			return nil, err
+		}
+	}
+	var reply []byte
+	var err error
+	switch name {
+	case "write":
+		return map[string]interface{}{}, t.Write(ctx, payload, nil)
+	case "read":
+		reply, err = t.Read(ctx, nil)
+	case "transact":
+		reply, err = t.Transact(ctx, payload, nil)
+	default:
+		return nil, errors.Errorf("unknown command %q", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"text": string(reply), "data": base64.StdEncoding.EncodeToString(reply)}, nil
+}
+
+func commandPayload(cmd map[string]interface{}) ([]byte, error) {
+	if text, ok := cmd["text"].(string); ok {
+		return []byte(text), nil
+	}
+	data, ok := cmd["data"].(string)
+	if !ok {
+		return nil, errors.New("expected the payload as text or base64 data")
+	}
+	return base64.StdEncoding.DecodeString(data)
+}
+
+// Close stops reading from the device and closes it.
+func (t *tty) Close(ctx context.Context) error {
+	err := t.port.Close()
+	t.workers.Stop()
+	return err
+}
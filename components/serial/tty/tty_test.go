@@ -0,0 +1,97 @@
+package tty
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	rdkserial "go.viam.com/rdk/components/serial"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// fakeDevice is a serial port whose device is at the other ends of its pipes.
+type fakeDevice struct {
+	io.Reader
+	io.WriteCloser
+}
+
+func (d *fakeDevice) Close() error {
+	return d.WriteCloser.Close()
+}
+
+func TestValidate(t *testing.T) {
+	_, err := (&Config{}).Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "serial_path")
+	_, err = (&Config{SerialPath: "/dev/ttyUSB0", Parity: "mark"}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "unknown parity")
+	_, err = (&Config{SerialPath: "/dev/ttyUSB0", Framing: rdkserial.Framing{Type: "slip"}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{SerialPath: "/dev/ttyUSB0", Parity: "even", StopBits: 2}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+}
+
+func TestTTY(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	// the port reads what the device writes, and the device reads what the port writes
+	portReader, deviceWriter := io.Pipe()
+	deviceReader, portWriter := io.Pipe()
+	realOpenPort := openPort
+	openPort = func(conf *Config) (io.ReadWriteCloser, error) {
+		return &fakeDevice{Reader: portReader, WriteCloser: portWriter}, nil
+	}
+	defer func() {
+		openPort = realOpenPort
+	}()
+	go func() {
+		lines := bufio.NewScanner(deviceReader)
+		for lines.Scan() {
+			if lines.Text() == "MEAS?" {
+				deviceWriter.Write([]byte("21.5\r\n"))
+			}
+		}
+	}()
+
+	conf := resource.Config{
+		Name:                "port",
+		API:                 rdkserial.API,
+		Model:               model,
+		ConvertedAttributes: &Config{SerialPath: "/dev/ttyUSB0", TimeoutMs: 100},
+	}
+	port, err := newTTY(ctx, nil, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	reply, err := port.Transact(ctx, []byte("MEAS?"), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reply, test.ShouldResemble, []byte("21.5"))
+
+	// a request without a reply times out
+	_, err = port.Transact(ctx, []byte("RESET"), nil)
+	test.That(t, err, test.ShouldBeError, "no reply from the serial device within 100ms")
+
+	// frames sent by the device on its own are read in order
+	go deviceWriter.Write([]byte("ALARM 1\nALARM 2\n"))
+	for _, want := range []string{"ALARM 1", "ALARM 2"} {
+		readCtx, cancel := context.WithTimeout(ctx, time.Second)
+		frame, err := port.Read(readCtx, nil)
+		cancel()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, string(frame), test.ShouldEqual, want)
+	}
+
+	resp, err := port.DoCommand(ctx, map[string]interface{}{"command": "transact", "text": "MEAS?"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp, test.ShouldResemble, map[string]interface{}{"text": "21.5", "data": "MjEuNQ=="})
+	_, err = port.DoCommand(ctx, map[string]interface{}{"command": "write"})
+	test.That(t, err, test.ShouldBeError, "expected the payload as text or base64 data")
+
+	deviceWriter.Close()
+	test.That(t, port.Close(ctx), test.ShouldBeNil)
+}
@@ -1,4 +1,5 @@
-// Package gpio implements a pin based servo
+// Package gpio implements a pin based servo, driven by a PWM pin of a board such as the pins of a PCA9685 hat.
+// Its moves interpolate to their targets in software when given servo.MoveOptions.
 package gpio
 
 import (
@@ -47,6 +48,10 @@ type servoConfig struct {
 	MinWidthUs *uint `json:"min_width_us,omitempty"`
 	// MaxWidthUs overrides the safe maximum PWM width in microseconds.
 	MaxWidthUs *uint `json:"max_width_us,omitempty"`
+	// MoveDurationMs and MaxVelocityDegsPerSec are the defaults of moves which don't give their own, and make the
+	// servo move to its target smoothly.
+	MoveDurationMs        float64 `json:"move_duration_ms,omitempty"`
+	MaxVelocityDegsPerSec float64 `json:"max_velocity_degs_per_sec,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -84,6 +89,12 @@ func (config *servoConfig) Validate(path string) ([]string, error) {
 	if config.MaxWidthUs != nil && *config.MaxWidthUs > maxWidthUs {
 		return nil, resource.NewConfigValidationError(path, errors.Errorf("max_width_us cannot be higher than %d", maxWidthUs))
 	}
+	if config.MoveDurationMs < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("move_duration_ms cannot be negative"))
+	}
+	if config.MaxVelocityDegsPerSec < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("max_velocity_degs_per_sec cannot be negative"))
+	}
 	return deps, nil
 }
 
@@ -109,6 +120,8 @@ type servoGPIO struct {
 	maxUs     uint
 	pwmRes    uint
 	currPct   float64
+	currDeg   float64
+	moveOpts  servo.MoveOptions
 	mu        sync.Mutex
 }
 
@@ -175,6 +188,11 @@ func (s *servoGPIO) Reconfigure(ctx context.Context, deps resource.Dependencies,
 		s.maxUs = *newConf.MaxWidthUs
 	}
 
+	s.moveOpts = servo.MoveOptions{
+		Duration:              time.Duration(newConf.MoveDurationMs * float64(time.Millisecond)),
+		MaxVelocityDegsPerSec: newConf.MaxVelocityDegsPerSec,
+	}
+
 	// If the frequency isn't specified in the config, we'll use whatever it's currently set to
 	// instead. If it's currently set to 0, we'll default to using 300 Hz.
 	s.frequency, err = s.pin.PWMFreq(ctx, nil)
@@ -209,8 +227,8 @@ func (s *servoGPIO) Reconfigure(ctx context.Context, deps resource.Dependencies,
 		return errors.Wrap(err, "error setting servo pin frequency")
 	}
 
-	// Try to detect the PWM resolution.
-	if err := s.Move(ctx, uint32(startPos), nil); err != nil {
+	// Try to detect the PWM resolution. The servo jumps to its start position, as where it was is unknown.
+	if err := s.setAngle(ctx, float64(uint32(startPos))); err != nil {
 		return errors.Wrap(err, "couldn't move servo to start position")
 	}
 
@@ -218,7 +236,7 @@ func (s *servoGPIO) Reconfigure(ctx context.Context, deps resource.Dependencies,
 		return errors.Wrap(err, "failed to guess the pwm resolution")
 	}
 
-	if err := s.Move(ctx, uint32(startPos), nil); err != nil {
+	if err := s.setAngle(ctx, float64(uint32(startPos))); err != nil {
 		return errors.Wrap(err, "couldn't move servo back to start position")
 	}
 
@@ -323,12 +341,17 @@ func (s *servoGPIO) findPWMResolution(ctx context.Context) error {
 	return nil
 }
 
-// Move moves the servo to the given angle (0-180 degrees)
+// Move moves the servo to the given angle (0-180 degrees), smoothly if the extra or config has servo.MoveOptions.
 // This will block until done or a new operation cancels this one.
 func (s *servoGPIO) Move(ctx context.Context, ang uint32, extra map[string]interface{}) error {
 	ctx, done := s.opMgr.New(ctx)
 	defer done()
 
+	opts, err := servo.MoveOptionsFromExtra(extra, s.moveOpts)
+	if err != nil {
+		return err
+	}
+
 	angle := float64(ang)
 
 	if angle < s.minDeg {
@@ -338,6 +361,11 @@ func (s *servoGPIO) Move(ctx context.Context, ang uint32, extra map[string]inter
 		angle = s.maxDeg
 	}
 
+	return servo.Interpolate(ctx, s.currDeg, angle, opts, s.setAngle)
+}
+
+// setAngle sets the duty cycle of the pin for the given angle, as near as its resolution allows.
+func (s *servoGPIO) setAngle(ctx context.Context, angle float64) error {
 	pct := mapDegToDutyCylePct(s.minUs, s.maxUs, s.minDeg, s.maxDeg, angle, s.frequency)
 	if s.pwmRes != 0 {
 		realTick := math.Round(pct * float64(s.pwmRes))
@@ -349,6 +377,7 @@ func (s *servoGPIO) Move(ctx context.Context, ang uint32, extra map[string]inter
 	}
 
 	s.currPct = pct
+	s.currDeg = angle
 	return nil
 }
 
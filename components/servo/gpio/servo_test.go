@@ -4,11 +4,13 @@ package gpio
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.viam.com/test"
 
 	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/servo"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/testutils/inject"
@@ -205,3 +207,49 @@ func TestServoMove(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, pos, test.ShouldEqual, 63)
 }
+
+func TestServoSmoothMove(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	deps := setupDependencies(t)
+	ctx := context.Background()
+
+	conf := servoConfig{
+		Pin:            "1",
+		Board:          "mock",
+		StartPos:       ptr(0.0),
+		MoveDurationMs: 100,
+	}
+	_, err := conf.Validate("test")
+	test.That(t, err, test.ShouldBeNil)
+	s, err := newGPIOServo(ctx, deps, resource.Config{ConvertedAttributes: &conf}, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	start := time.Now()
+	test.That(t, s.Move(ctx, 63, nil), test.ShouldBeNil)
+	test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 80*time.Millisecond)
+	pos, err := s.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos, test.ShouldEqual, 63)
+
+	// the extra overrides the config, here to move as fast as possible
+	start = time.Now()
+	test.That(t, s.Move(ctx, 10, map[string]interface{}{servo.MoveDurationKey: 0.}), test.ShouldBeNil)
+	test.That(t, time.Since(start), test.ShouldBeLessThan, 80*time.Millisecond)
+	pos, err = s.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos, test.ShouldEqual, 10)
+
+	// a cancelled move stops part way
+	cancelCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	err = s.Move(cancelCtx, 170, map[string]interface{}{servo.MaxVelocityKey: 400.})
+	test.That(t, err, test.ShouldBeError, context.DeadlineExceeded)
+	pos, err = s.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos, test.ShouldBeGreaterThan, 10)
+	test.That(t, pos, test.ShouldBeLessThan, 170)
+
+	conf.MaxVelocityDegsPerSec = -1
+	_, err = conf.Validate("test")
+	test.That(t, err, test.ShouldNotBeNil)
+}
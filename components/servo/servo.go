@@ -50,12 +50,19 @@ var API = resource.APINamespaceRDK.WithComponentType(SubtypeName)
 //
 //	logger.Info("Position 1: ", pos1)
 //	logger.Info("Position 2: ", pos2)
+//
+// Smooth Move example:
+//
+//	// Move the servo to 90 degrees over two seconds.
+//	opts := servo.MoveOptions{Duration: 2 * time.Second}
+//	myServoComponent.Move(context.Background(), 90, opts.ToExtra(nil))
 type Servo interface {
 	resource.Resource
 	resource.Actuator
 
 	// Move moves the servo to the given angle (0-180 degrees)
-	// This will block until done or a new operation cancels this one
+	// This will block until done or a new operation cancels this one.
+	// Servos which support it move smoothly given MoveOptions in the extra.
 	Move(ctx context.Context, angleDeg uint32, extra map[string]interface{}) error
 
 	// Position returns the current set angle (degrees) of the servo.
@@ -67,6 +74,12 @@ func Named(name string) resource.Name {
 	return resource.NewName(API, name)
 }
 
+// FromDependencies is a helper for getting the named servo from a collection of
+// dependencies.
+func FromDependencies(deps resource.Dependencies, name string) (Servo, error) {
+	return resource.FromDependencies[Servo](deps, Named(name))
+}
+
 // FromRobot is a helper for getting the named servo from the given Robot.
 func FromRobot(r robot.Robot, name string) (Servo, error) {
 	return robot.ResourceFromRobot[Servo](r, Named(name))
@@ -0,0 +1,116 @@
+package servo
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+)
+
+const (
+	// MoveDurationKey is the key of the extra of Move giving how many milliseconds the move should take.
+	MoveDurationKey = "move_duration_ms"
+	// MaxVelocityKey is the key of the extra of Move giving the fastest the servo should move, in degrees per second.
+	MaxVelocityKey = "max_velocity_degs_per_sec"
+)
+
+// InterpolationPeriod is how often a servo moving smoothly is given a new angle, about as often as the pulses that
+// drive most servos.
+const InterpolationPeriod = 20 * time.Millisecond
+
+// MoveOptions make a servo move to its target smoothly rather than as fast as it can. A move takes at least
+// Duration, and is slow enough that the servo turns no faster than MaxVelocityDegsPerSec, if given.
+//
+// They are passed to Move in its extra, which ToExtra fills in.
+type MoveOptions struct {
+	Duration              time.Duration
+	MaxVelocityDegsPerSec float64
+}
+
+// ToExtra returns a copy of the extra of a Move with the options in it.
+func (opts MoveOptions) ToExtra(extra map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(extra)+2)
+	for k, v := range extra {
+		out[k] = v
+	}
+	if opts.Duration > 0 {
+		out[MoveDurationKey] = float64(opts.Duration.Milliseconds())
+	}
+	if opts.MaxVelocityDegsPerSec > 0 {
+		out[MaxVelocityKey] = opts.MaxVelocityDegsPerSec
+	}
+	return out
+}
+
+// MoveOptionsFromExtra returns the options in the extra of a Move, falling back to the defaults for the ones it
+// doesn't have.
+func MoveOptionsFromExtra(extra map[string]interface{}, defaults MoveOptions) (MoveOptions, error) {
+	opts := defaults
+	if v, ok := extra[MoveDurationKey]; ok {
+		ms, err := nonNegativeNumber(v)
+		if err != nil {
+			return MoveOptions{}, errors.Wrap(err, MoveDurationKey)
+		}
+		opts.Duration = time.Duration(ms * float64(time.Millisecond))
+	}
+	if v, ok := extra[MaxVelocityKey]; ok {
+		vel, err := nonNegativeNumber(v)
+		if err != nil {
+			return MoveOptions{}, errors.Wrap(err, MaxVelocityKey)
+		}
+		opts.MaxVelocityDegsPerSec = vel
+	}
+	return opts, nil
+}
+
+// nonNegativeNumber is a number from an extra, which are float64s once they have been sent over the network.
+func nonNegativeNumber(v interface{}) (float64, error) {
+	var f float64
+	switch n := v.(type) {
+	case float64:
+		f = n
+	case float32:
+		f = float64(n)
+	case int:
+		f = float64(n)
+	case int64:
+		f = float64(n)
+	case uint32:
+		f = float64(n)
+	default:
+		return 0, errors.Errorf("expected a number, got %T", v)
+	}
+	if f < 0 || math.IsNaN(f) {
+		return 0, errors.Errorf("cannot be negative, got %v", f)
+	}
+	return f, nil
+}
+
+// MoveDuration is how long a move between two angles takes with the options.
+func (opts MoveOptions) MoveDuration(fromDeg, toDeg float64) time.Duration {
+	duration := opts.Duration
+	if opts.MaxVelocityDegsPerSec > 0 {
+		duration = max(duration, time.Duration(math.Abs(toDeg-fromDeg)/opts.MaxVelocityDegsPerSec*float64(time.Second)))
+	}
+	return duration
+}
+
+// Interpolate moves a servo from one angle to another with the options, calling set with the angles in between at
+// each InterpolationPeriod and finally with the target. Without options, it calls set with the target alone. It
+// returns early if the context is canceled, such as by a new move, having last set the servo to where it stopped.
+func Interpolate(
+	ctx context.Context, fromDeg, toDeg float64, opts MoveOptions, set func(ctx context.Context, deg float64) error,
+) error {
+	steps := int(math.Ceil(float64(opts.MoveDuration(fromDeg, toDeg)) / float64(InterpolationPeriod)))
+	for i := 1; i < steps; i++ {
+		if err := set(ctx, fromDeg+(toDeg-fromDeg)*float64(i)/float64(steps)); err != nil {
+			return err
+		}
+		if !goutils.SelectContextOrWait(ctx, InterpolationPeriod) {
+			return ctx.Err()
+		}
+	}
+	return set(ctx, toDeg)
+}
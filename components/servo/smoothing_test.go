@@ -0,0 +1,61 @@
+package servo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/servo"
+)
+
+func TestMoveOptionsFromExtra(t *testing.T) {
+	defaults := servo.MoveOptions{Duration: time.Second}
+	opts, err := servo.MoveOptionsFromExtra(nil, defaults)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, opts, test.ShouldResemble, defaults)
+
+	extra := servo.MoveOptions{Duration: 1500 * time.Millisecond, MaxVelocityDegsPerSec: 30}.ToExtra(map[string]interface{}{"foo": "bar"})
+	test.That(t, extra["foo"], test.ShouldEqual, "bar")
+	opts, err = servo.MoveOptionsFromExtra(extra, defaults)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, opts, test.ShouldResemble, servo.MoveOptions{Duration: 1500 * time.Millisecond, MaxVelocityDegsPerSec: 30})
+
+	_, err = servo.MoveOptionsFromExtra(map[string]interface{}{servo.MoveDurationKey: "soon"}, defaults)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = servo.MoveOptionsFromExtra(map[string]interface{}{servo.MaxVelocityKey: -1.}, defaults)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestMoveDuration(t *testing.T) {
+	test.That(t, servo.MoveOptions{}.MoveDuration(0, 90), test.ShouldEqual, 0)
+	test.That(t, servo.MoveOptions{Duration: time.Second}.MoveDuration(0, 90), test.ShouldEqual, time.Second)
+	test.That(t, servo.MoveOptions{MaxVelocityDegsPerSec: 45}.MoveDuration(90, 0), test.ShouldEqual, 2*time.Second)
+	// the slower of the two wins
+	test.That(t, servo.MoveOptions{Duration: 3 * time.Second, MaxVelocityDegsPerSec: 45}.MoveDuration(0, 90),
+		test.ShouldEqual, 3*time.Second)
+}
+
+func TestInterpolate(t *testing.T) {
+	ctx := context.Background()
+	var angles []float64
+	set := func(ctx context.Context, deg float64) error {
+		angles = append(angles, deg)
+		return nil
+	}
+
+	test.That(t, servo.Interpolate(ctx, 0, 90, servo.MoveOptions{}, set), test.ShouldBeNil)
+	test.That(t, angles, test.ShouldResemble, []float64{90})
+
+	angles = nil
+	test.That(t, servo.Interpolate(ctx, 100, 60, servo.MoveOptions{Duration: 4 * servo.InterpolationPeriod}, set), test.ShouldBeNil)
+	test.That(t, angles, test.ShouldResemble, []float64{90, 80, 70, 60})
+
+	angles = nil
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	err := servo.Interpolate(cancelCtx, 0, 90, servo.MoveOptions{Duration: time.Second}, set)
+	test.That(t, err, test.ShouldBeError, context.Canceled)
+	test.That(t, angles, test.ShouldHaveLength, 1)
+}
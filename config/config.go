@@ -38,6 +38,8 @@ type Config struct {
 	Auth            AuthConfig
 	Debug           bool
 	GlobalLogConfig []GlobalLogConfig
+	StartupActions  []StartupAction
+	ShutdownActions []ShutdownAction
 
 	ConfigFilePath string
 
@@ -82,6 +84,8 @@ type configData struct {
 	DisablePartialStart bool                  `json:"disable_partial_start"`
 	EnableWebProfile    bool                  `json:"enable_web_profile"`
 	GlobalLogConfig     []GlobalLogConfig     `json:"global_log_configuration"`
+	StartupActions      []StartupAction       `json:"startup_actions,omitempty"`
+	ShutdownActions     []ShutdownAction      `json:"shutdown_actions,omitempty"`
 }
 
 // AppValidationStatus refers to the.
@@ -227,6 +231,24 @@ func (c *Config) Ensure(fromCloud bool, logger logging.Logger) error {
 		}
 	}
 
+	for idx, action := range c.StartupActions {
+		if err := action.Validate(fmt.Sprintf("startup_actions.%d", idx)); err != nil {
+			if c.DisablePartialStart {
+				return err
+			}
+			logger.Errorw("startup action config error; starting robot without startup action", "action", action.String(), "error", err)
+		}
+	}
+
+	for idx, action := range c.ShutdownActions {
+		if err := action.Validate(fmt.Sprintf("shutdown_actions.%d", idx)); err != nil {
+			if c.DisablePartialStart {
+				return err
+			}
+			logger.Errorw("shutdown action config error; starting robot without shutdown action", "action", action.String(), "error", err)
+		}
+	}
+
 	return nil
 }
 
@@ -270,6 +292,8 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 	c.DisablePartialStart = conf.DisablePartialStart
 	c.EnableWebProfile = conf.EnableWebProfile
 	c.GlobalLogConfig = conf.GlobalLogConfig
+	c.StartupActions = conf.StartupActions
+	c.ShutdownActions = conf.ShutdownActions
 
 	return nil
 }
@@ -300,6 +324,8 @@ func (c Config) MarshalJSON() ([]byte, error) {
 		DisablePartialStart: c.DisablePartialStart,
 		EnableWebProfile:    c.EnableWebProfile,
 		GlobalLogConfig:     c.GlobalLogConfig,
+		StartupActions:      c.StartupActions,
+		ShutdownActions:     c.ShutdownActions,
 	})
 }
 
@@ -335,6 +361,9 @@ type Remote struct {
 	ReconnectInterval         time.Duration
 	AssociatedResourceConfigs []resource.AssociatedResourceConfig
 
+	// Constrained is set when the remote is served by a constrained peer, like a micro-RDK device.
+	Constrained *ConstrainedPeer
+
 	// Secret is a helper for a robot location secret.
 	Secret string
 
@@ -353,6 +382,7 @@ type remoteData struct {
 	ConnectionCheckInterval   string                              `json:"connection_check_interval,omitempty"`
 	ReconnectInterval         string                              `json:"reconnect_interval,omitempty"`
 	AssociatedResourceConfigs []resource.AssociatedResourceConfig `json:"service_configs"`
+	Constrained               *ConstrainedPeer                    `json:"constrained,omitempty"`
 
 	// Secret is a helper for a robot location secret.
 	Secret string `json:"secret"`
@@ -368,6 +398,21 @@ func (conf Remote) Equals(other Remote) bool {
 	return reflect.DeepEqual(conf, other)
 }
 
+// ConnectionIntervals returns how often to check the connection to the remote and how often to try reconnecting
+// once it is lost. Constrained peers default to checking less often, and zero leaves the choice to the client.
+func (conf Remote) ConnectionIntervals() (checkEvery, reconnectEvery time.Duration) {
+	checkEvery, reconnectEvery = conf.ConnectionCheckInterval, conf.ReconnectInterval
+	if conf.Constrained != nil {
+		if checkEvery == 0 {
+			checkEvery = DefaultConstrainedConnectionCheckInterval
+		}
+		if reconnectEvery == 0 {
+			reconnectEvery = DefaultConstrainedReconnectInterval
+		}
+	}
+	return checkEvery, reconnectEvery
+}
+
 // UnmarshalJSON unmarshals JSON data into this config.
 func (conf *Remote) UnmarshalJSON(data []byte) error {
 	var temp remoteData
@@ -382,6 +427,7 @@ func (conf *Remote) UnmarshalJSON(data []byte) error {
 		ManagedBy:                 temp.ManagedBy,
 		Insecure:                  temp.Insecure,
 		AssociatedResourceConfigs: temp.AssociatedResourceConfigs,
+		Constrained:               temp.Constrained,
 		Secret:                    temp.Secret,
 	}
 	if temp.ConnectionCheckInterval != "" {
@@ -411,6 +457,7 @@ func (conf Remote) MarshalJSON() ([]byte, error) {
 		ManagedBy:                 conf.ManagedBy,
 		Insecure:                  conf.Insecure,
 		AssociatedResourceConfigs: conf.AssociatedResourceConfigs,
+		Constrained:               conf.Constrained,
 		Secret:                    conf.Secret,
 	}
 	if conf.ConnectionCheckInterval != 0 {
@@ -422,6 +469,118 @@ func (conf Remote) MarshalJSON() ([]byte, error) {
 	return json.Marshal(temp)
 }
 
+// A ConstrainedPeer describes a remote served by a constrained device, such as an ESP32 running micro-RDK on flaky
+// Wi-Fi, which may only implement part of the API and should not be kept busy with connection upkeep.
+// Zero values are replaced by defaults suited to such devices.
+type ConstrainedPeer struct {
+	// APIs limits the resources used from the peer to those of the given APIs. All of them are used when empty.
+	APIs []resource.API
+	// RefreshInterval is how often the resources of the peer are refreshed.
+	RefreshInterval time.Duration
+	// SessionHeartbeatInterval is how often session heartbeats are sent. It is capped to half of the
+	// heartbeat window of the peer so that sessions do not expire.
+	SessionHeartbeatInterval time.Duration
+	// Retries is how many times a read is retried when the peer is unavailable. Other calls are never retried.
+	Retries int
+	// CacheTTL is how old the last response to a read of slowly changing state, such as sensor readings, can be and
+	// still be served while the peer is unavailable.
+	CacheTTL time.Duration
+}
+
+// Defaults used for constrained peers.
+const (
+	DefaultConstrainedConnectionCheckInterval = 30 * time.Second
+	DefaultConstrainedReconnectInterval       = 5 * time.Second
+	DefaultConstrainedRefreshInterval         = time.Minute
+	DefaultConstrainedRetries                 = 3
+	DefaultConstrainedCacheTTL                = 10 * time.Second
+)
+
+// Note: keep this in sync with ConstrainedPeer.
+type constrainedPeerData struct {
+	APIs                     []resource.API `json:"apis,omitempty"`
+	RefreshInterval          string         `json:"refresh_interval,omitempty"`
+	SessionHeartbeatInterval string         `json:"session_heartbeat_interval,omitempty"`
+	Retries                  int            `json:"retries,omitempty"`
+	CacheTTL                 string         `json:"cache_ttl,omitempty"`
+}
+
+// UnmarshalJSON unmarshals JSON data into this config.
+func (conf *ConstrainedPeer) UnmarshalJSON(data []byte) error {
+	var temp constrainedPeerData
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+	*conf = ConstrainedPeer{APIs: temp.APIs, Retries: temp.Retries}
+	for _, field := range []struct {
+		value string
+		dest  *time.Duration
+	}{
+		{temp.RefreshInterval, &conf.RefreshInterval},
+		{temp.SessionHeartbeatInterval, &conf.SessionHeartbeatInterval},
+		{temp.CacheTTL, &conf.CacheTTL},
+	} {
+		if field.value == "" {
+			continue
+		}
+		dur, err := time.ParseDuration(field.value)
+		if err != nil {
+			return err
+		}
+		*field.dest = dur
+	}
+	return nil
+}
+
+// MarshalJSON marshals out this config.
+func (conf ConstrainedPeer) MarshalJSON() ([]byte, error) {
+	temp := constrainedPeerData{APIs: conf.APIs, Retries: conf.Retries}
+	if conf.RefreshInterval != 0 {
+		temp.RefreshInterval = conf.RefreshInterval.String()
+	}
+	if conf.SessionHeartbeatInterval != 0 {
+		temp.SessionHeartbeatInterval = conf.SessionHeartbeatInterval.String()
+	}
+	if conf.CacheTTL != 0 {
+		temp.CacheTTL = conf.CacheTTL.String()
+	}
+	return json.Marshal(temp)
+}
+
+// WithDefaults returns the config with zero values replaced by their defaults.
+func (conf ConstrainedPeer) WithDefaults() ConstrainedPeer {
+	if conf.RefreshInterval == 0 {
+		conf.RefreshInterval = DefaultConstrainedRefreshInterval
+	}
+	if conf.Retries == 0 {
+		conf.Retries = DefaultConstrainedRetries
+	}
+	if conf.CacheTTL == 0 {
+		conf.CacheTTL = DefaultConstrainedCacheTTL
+	}
+	return conf
+}
+
+// AllowsAPI returns whether resources of the given API are used from the peer.
+func (conf *ConstrainedPeer) AllowsAPI(api resource.API) bool {
+	return len(conf.APIs) == 0 || slices.Contains(conf.APIs, api)
+}
+
+func (conf *ConstrainedPeer) validate(path string) error {
+	for idx, api := range conf.APIs {
+		if err := api.Validate(); err != nil {
+			return resource.NewConfigValidationError(fmt.Sprintf("%s.apis.%d", path, idx), err)
+		}
+	}
+	if conf.RefreshInterval < 0 || conf.SessionHeartbeatInterval < 0 || conf.CacheTTL < 0 {
+		return resource.NewConfigValidationError(path, errors.New("intervals must not be negative"))
+	}
+	if conf.Retries < 0 {
+		return resource.NewConfigValidationError(path, errors.New("retries must not be negative"))
+	}
+	return nil
+}
+
 // RemoteAuth specifies how to authenticate against a remote. If no credentials are
 // specified, authentication does not happen. If an entity is specified, the
 // authentication request will specify it.
@@ -475,6 +634,11 @@ func (conf *Remote) validate(path string) error {
 			return resource.NewConfigValidationFieldRequiredError(path, "frame.parent")
 		}
 	}
+	if conf.Constrained != nil {
+		if err := conf.Constrained.validate(path + ".constrained"); err != nil {
+			return err
+		}
+	}
 
 	if conf.Secret != "" {
 		conf.Auth = RemoteAuth{
@@ -676,6 +840,33 @@ type NetworkConfigData struct {
 
 	// Sessions configures session management.
 	Sessions SessionsConfig `json:"sessions"`
+
+	// DisableRESTGateway turns off the REST/JSON gateway to the resource APIs served under /api, along with its
+	// OpenAPI document at /api/openapi.json. gRPC and gRPC-Web are served either way.
+	DisableRESTGateway bool `json:"disable_rest_gateway,omitempty"`
+
+	// EnableEventFeed serves a WebSocket feed of machine events as JSON at /events. Requests are authenticated
+	// like MJPEG streams.
+	EnableEventFeed bool `json:"enable_event_feed,omitempty"`
+
+	// EventFeedOrigins lists the host patterns of other origins whose pages may subscribe to the event feed.
+	EventFeedOrigins []string `json:"event_feed_origins,omitempty"`
+
+	// EnableMJPEG serves every camera as a multipart MJPEG stream at /mjpeg/{camera} and a single JPEG frame at
+	// /mjpeg/{camera}/snapshot, for tools which cannot speak WebRTC or gRPC. When the machine has auth handlers,
+	// requests need one of its API keys as HTTP basic auth or the api_key_id and api_key query parameters.
+	EnableMJPEG bool `json:"enable_mjpeg,omitempty"`
+
+	// EnableMovementSensorStreams serves the samples of every movement sensor as a WebSocket feed of JSON at
+	// /movement_sensor/{name}/samples, for control loops and SLAM which need IMU samples faster than polling over
+	// gRPC allows. Requests are authenticated like MJPEG streams.
+	EnableMovementSensorStreams bool `json:"enable_movement_sensor_streams,omitempty"`
+
+	// EnableDebugControl serves the debug routes which change the running machine: POST /debug/simulated swaps
+	// components for their fakes, POST /debug/tagged/stop stops the resources with a tag and PUT /debug/faults
+	// injects faults into the gRPC calls the machine handles. They take their arguments as a JSON body and are
+	// authenticated like MJPEG streams.
+	EnableDebugControl bool `json:"enable_debug_control,omitempty"`
 }
 
 // MarshalJSON marshals out this config.
@@ -695,6 +695,68 @@ func TestRemoteValidate(t *testing.T) {
 			"must start with a letter or number and must only contain letters, numbers, dashes, and underscores",
 		)
 	})
+
+	t.Run("constrained peer", func(t *testing.T) {
+		remote := config.Remote{
+			Name:        "esp32",
+			Address:     "address",
+			Constrained: &config.ConstrainedPeer{Retries: -1},
+		}
+		_, err := remote.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "retries must not be negative")
+
+		remote = config.Remote{
+			Name:        "esp32",
+			Address:     "address",
+			Constrained: &config.ConstrainedPeer{APIs: []resource.API{{}}},
+		}
+		_, err = remote.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "path.constrained.apis.0")
+	})
+}
+
+func TestConstrainedPeer(t *testing.T) {
+	var remote config.Remote
+	err := json.Unmarshal([]byte(`{
+		"name": "esp32",
+		"address": "esp32.local:8080",
+		"reconnect_interval": "2s",
+		"constrained": {
+			"apis": ["rdk:component:board", "rdk:component:camera"],
+			"refresh_interval": "5m",
+			"session_heartbeat_interval": "4s",
+			"cache_ttl": "1m"
+		}
+	}`), &remote)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, remote.Constrained, test.ShouldResemble, &config.ConstrainedPeer{
+		APIs:                     []resource.API{board.API, camera.API},
+		RefreshInterval:          5 * time.Minute,
+		SessionHeartbeatInterval: 4 * time.Second,
+		CacheTTL:                 time.Minute,
+	})
+	test.That(t, remote.Constrained.AllowsAPI(board.API), test.ShouldBeTrue)
+	test.That(t, remote.Constrained.AllowsAPI(arm.API), test.ShouldBeFalse)
+	test.That(t, (&config.ConstrainedPeer{}).AllowsAPI(arm.API), test.ShouldBeTrue)
+
+	defaults := remote.Constrained.WithDefaults()
+	test.That(t, defaults.Retries, test.ShouldEqual, config.DefaultConstrainedRetries)
+	test.That(t, defaults.RefreshInterval, test.ShouldEqual, 5*time.Minute)
+
+	checkEvery, reconnectEvery := remote.ConnectionIntervals()
+	test.That(t, checkEvery, test.ShouldEqual, config.DefaultConstrainedConnectionCheckInterval)
+	test.That(t, reconnectEvery, test.ShouldEqual, 2*time.Second)
+	checkEvery, reconnectEvery = config.Remote{}.ConnectionIntervals()
+	test.That(t, checkEvery, test.ShouldBeZeroValue)
+	test.That(t, reconnectEvery, test.ShouldBeZeroValue)
+
+	data, err := json.Marshal(remote)
+	test.That(t, err, test.ShouldBeNil)
+	var roundTripped config.Remote
+	test.That(t, json.Unmarshal(data, &roundTripped), test.ShouldBeNil)
+	test.That(t, roundTripped.Constrained, test.ShouldResemble, remote.Constrained)
 }
 
 func TestCopyOnlyPublicFields(t *testing.T) {
@@ -1226,3 +1288,99 @@ func TestConfigRobotWebProfile(t *testing.T) {
 
 	test.That(t, cfg.EnableWebProfile, test.ShouldBeTrue)
 }
+
+func TestStartupActionValidate(t *testing.T) {
+	action := config.StartupAction{}
+	err := action.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "resource"))
+
+	action.Resource = "arm1"
+	err = action.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "method"))
+
+	action.Method = "stow"
+	err = action.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `unknown method "stow"`)
+
+	action.Method = config.StartupActionMoveToJointPositions
+	err = action.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "joint_positions_degs"))
+
+	action.JointPositionsDegs = []float64{0, -90, 90, 0, 0, 0}
+	test.That(t, action.Validate("path"), test.ShouldBeNil)
+	test.That(t, action.String(), test.ShouldEqual, "move_to_joint_positions arm1")
+
+	action.OnFailure = "ignore"
+	err = action.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `unknown on_failure "ignore"`)
+
+	action.OnFailure = config.StartupActionStop
+	action.Retries = -1
+	test.That(t, action.Validate("path"), test.ShouldNotBeNil)
+
+	action = config.StartupAction{Resource: "board1", Method: config.StartupActionSetGPIO}
+	err = action.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "pin"))
+
+	action = config.StartupAction{Resource: "relay", Method: config.StartupActionDoCommand}
+	err = action.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "command"))
+}
+
+func TestStartupActionsConfig(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	actions := []config.StartupAction{
+		{Resource: "gantry1", Method: config.StartupActionHome, TimeoutSec: 30},
+		{Name: "relay on", Resource: "board1", Method: config.StartupActionSetGPIO, Pin: "37", High: true, OnFailure: "stop"},
+	}
+	cfg := config.Config{StartupActions: actions}
+	md, err := json.Marshal(&cfg)
+	test.That(t, err, test.ShouldBeNil)
+	var unmarshaled config.Config
+	test.That(t, json.Unmarshal(md, &unmarshaled), test.ShouldBeNil)
+	test.That(t, unmarshaled.StartupActions, test.ShouldResemble, actions)
+
+	cfg.StartupActions = append(cfg.StartupActions, config.StartupAction{Resource: "arm1"})
+	test.That(t, cfg.Ensure(false, logger), test.ShouldBeNil)
+
+	cfg.DisablePartialStart = true
+	err = cfg.Ensure(false, logger)
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("startup_actions.2", "method"))
+}
+
+func TestShutdownActionsConfig(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	action := config.ShutdownAction{Resource: "arm1", Method: config.ShutdownActionMoveToJointPositions}
+	err := action.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "joint_positions_degs"))
+	action.JointPositionsDegs = []float64{0, -90, 90, 0, 0, 0}
+	test.That(t, action.Validate("path"), test.ShouldBeNil)
+	action.TimeoutSec = -1
+	test.That(t, action.Validate("path"), test.ShouldNotBeNil)
+
+	action = config.ShutdownAction{Resource: "motor1", Method: "brake"}
+	err = action.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `unknown method "brake"`)
+
+	actions := []config.ShutdownAction{
+		{Resource: "arm1", Method: config.ShutdownActionMoveToJointPositions, JointPositionsDegs: []float64{0, -90}},
+		{Resource: "gantry1", Method: config.ShutdownActionHome, TimeoutSec: 30},
+		{Resource: "motor1", Method: config.ShutdownActionStop},
+	}
+	cfg := config.Config{ShutdownActions: actions}
+	md, err := json.Marshal(&cfg)
+	test.That(t, err, test.ShouldBeNil)
+	var unmarshaled config.Config
+	test.That(t, json.Unmarshal(md, &unmarshaled), test.ShouldBeNil)
+	test.That(t, unmarshaled.ShutdownActions, test.ShouldResemble, actions)
+
+	cfg.ShutdownActions = append(cfg.ShutdownActions, config.ShutdownAction{Method: config.ShutdownActionStop})
+	test.That(t, cfg.Ensure(false, logger), test.ShouldBeNil)
+
+	cfg.DisablePartialStart = true
+	err = cfg.Ensure(false, logger)
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("shutdown_actions.3", "resource"))
+}
@@ -388,6 +388,16 @@ func FromReader(
 	return fromReader(ctx, originalPath, r, logger, true)
 }
 
+// LocalFromReader reads a config from the given reader but does not fetch any config from the remote servers.
+func LocalFromReader(
+	ctx context.Context,
+	originalPath string,
+	r io.Reader,
+	logger logging.Logger,
+) (*Config, error) {
+	return fromReader(ctx, originalPath, r, logger, false)
+}
+
 // FromReader reads a config from the given reader and specifies
 // where, if applicable, the file the reader originated from.
 func fromReader(
@@ -584,6 +594,26 @@ func processConfig(unprocessedConfig *Config, fromCloud bool, logger logging.Log
 		return nil, err
 	}
 
+	// link resources which act on every resource with a given tag to the tagged resources
+	for idx := range cfg.Services {
+		linker, ok := cfg.Services[idx].ConvertedAttributes.(resource.TagLinker)
+		if !ok {
+			continue
+		}
+		if tags := linker.LinkedTags(); fromCloud && len(tags) > 0 {
+			// cloud configs are converted from the config proto, which has no field for tags
+			logger.Warnw("resources can only be tagged in local configs, so no resources in this cloud config have tags",
+				"resource", cfg.Services[idx].ResourceName(), "tags", tags)
+		}
+		for _, confs := range [][]resource.Config{cfg.Components, cfg.Services} {
+			for taggedIdx := range confs {
+				if len(confs[taggedIdx].Tags) > 0 {
+					linker.LinkTagged(&cfg.Services[idx], &confs[taggedIdx])
+				}
+			}
+		}
+	}
+
 	// associated configs can be put on resources in remotes as well, so check remote configs
 	for _, c := range cfg.Remotes {
 		if err := convertAndAssociateResourceConfigs(nil, &c.Name, c.AssociatedResourceConfigs); err != nil {
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+)
+
+// The methods of shutdown actions.
+const (
+	// ShutdownActionHome homes a gantry.
+	ShutdownActionHome = "home"
+	// ShutdownActionMoveToJointPositions moves an arm to the joint positions of the action, such as its stowed pose.
+	ShutdownActionMoveToJointPositions = "move_to_joint_positions"
+	// ShutdownActionStop stops any actuator, such as a motor, which brakes if it can.
+	ShutdownActionStop = "stop"
+	// ShutdownActionDoCommand sends the command of the action to the DoCommand of any resource.
+	ShutdownActionDoCommand = "do_command"
+)
+
+// A ShutdownAction parks an actuator when the robot shuts down, such as when its server is stopped or the power is
+// about to be lost, so that it is not left in an unsafe pose. Shutdown actions are taken in order before any
+// resource is closed:
+//
+//	{"resource": "arm", "method": "move_to_joint_positions", "joint_positions_degs": [0, -90, 90, 0, 0, 0]}
+//	{"resource": "gantry", "method": "home", "timeout_sec": 30}
+//	{"resource": "wheel", "method": "stop"}
+type ShutdownAction struct {
+	// Resource is the name of the resource the action is taken on.
+	Resource string `json:"resource"`
+	Method   string `json:"method"`
+
+	JointPositionsDegs []float64              `json:"joint_positions_degs,omitempty"`
+	Command            map[string]interface{} `json:"command,omitempty"`
+
+	// TimeoutSec limits how long the action takes, so a stuck actuator cannot hold up the shutdown. It defaults to
+	// 10 seconds if 0.
+	TimeoutSec float64 `json:"timeout_sec,omitempty"`
+}
+
+// String names the action.
+func (a ShutdownAction) String() string {
+	return fmt.Sprintf("%s %s", a.Method, a.Resource)
+}
+
+// Validate ensures all parts of the shutdown action are valid.
+func (a ShutdownAction) Validate(path string) error {
+	if a.Resource == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "resource")
+	}
+	switch a.Method {
+	case ShutdownActionHome, ShutdownActionStop:
+	case ShutdownActionMoveToJointPositions:
+		if len(a.JointPositionsDegs) == 0 {
+			return resource.NewConfigValidationFieldRequiredError(path, "joint_positions_degs")
+		}
+	case ShutdownActionDoCommand:
+		if len(a.Command) == 0 {
+			return resource.NewConfigValidationFieldRequiredError(path, "command")
+		}
+	case "":
+		return resource.NewConfigValidationFieldRequiredError(path, "method")
+	default:
+		return resource.NewConfigValidationError(path, errors.Errorf("unknown method %q, expected one of %q, %q, %q or %q",
+			a.Method, ShutdownActionHome, ShutdownActionMoveToJointPositions, ShutdownActionStop, ShutdownActionDoCommand))
+	}
+	if a.TimeoutSec < 0 {
+		return resource.NewConfigValidationError(path, errors.New("timeout_sec cannot be negative"))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+)
+
+// The methods of startup actions.
+const (
+	// StartupActionHome homes a gantry.
+	StartupActionHome = "home"
+	// StartupActionMoveToJointPositions moves an arm to the joint positions of the action, such as its stowed pose.
+	StartupActionMoveToJointPositions = "move_to_joint_positions"
+	// StartupActionSetGPIO sets a GPIO pin of a board high or low, such as to enable a relay.
+	StartupActionSetGPIO = "set_gpio"
+	// StartupActionDoCommand sends the command of the action to the DoCommand of any resource.
+	StartupActionDoCommand = "do_command"
+)
+
+// The failure policies of startup actions.
+const (
+	// StartupActionContinue goes on to the next startup action after an action fails. It is the default.
+	StartupActionContinue = "continue"
+	// StartupActionStop skips the remaining startup actions after an action fails.
+	StartupActionStop = "stop"
+)
+
+// A StartupAction is taken on a resource once the robot has started and the resource is ready, so that the robot
+// reaches a known state on boot. Startup actions are taken in order, each waiting for the one before it:
+//
+//	{"resource": "gantry", "method": "home"}
+//	{"resource": "arm", "method": "move_to_joint_positions", "joint_positions_degs": [0, -90, 90, 0, 0, 0]}
+//	{"resource": "board", "method": "set_gpio", "pin": "37", "high": true, "on_failure": "stop"}
+type StartupAction struct {
+	// Name names the action in logs, and defaults to its resource and method.
+	Name string `json:"name,omitempty"`
+	// Resource is the name of the resource the action is taken on.
+	Resource string `json:"resource"`
+	Method   string `json:"method"`
+
+	JointPositionsDegs []float64              `json:"joint_positions_degs,omitempty"`
+	Pin                string                 `json:"pin,omitempty"`
+	High               bool                   `json:"high,omitempty"`
+	Command            map[string]interface{} `json:"command,omitempty"`
+
+	// Retries is how many more times a failed action is tried.
+	Retries int `json:"retries,omitempty"`
+	// TimeoutSec limits how long each try of the action takes, once its resource is ready. It is unlimited if 0.
+	TimeoutSec float64 `json:"timeout_sec,omitempty"`
+	// OnFailure is what happens after the action fails every try, either "continue" or "stop".
+	OnFailure string `json:"on_failure,omitempty"`
+}
+
+// String names the action.
+func (a StartupAction) String() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return fmt.Sprintf("%s %s", a.Method, a.Resource)
+}
+
+// Validate ensures all parts of the startup action are valid.
+func (a StartupAction) Validate(path string) error {
+	if a.Resource == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "resource")
+	}
+	switch a.Method {
+	case StartupActionHome:
+	case StartupActionMoveToJointPositions:
+		if len(a.JointPositionsDegs) == 0 {
+			return resource.NewConfigValidationFieldRequiredError(path, "joint_positions_degs")
+		}
+	case StartupActionSetGPIO:
+		if a.Pin == "" {
+			return resource.NewConfigValidationFieldRequiredError(path, "pin")
+		}
+	case StartupActionDoCommand:
+		if len(a.Command) == 0 {
+			return resource.NewConfigValidationFieldRequiredError(path, "command")
+		}
+	case "":
+		return resource.NewConfigValidationFieldRequiredError(path, "method")
+	default:
+		return resource.NewConfigValidationError(path, errors.Errorf("unknown method %q, expected one of %q, %q, %q or %q",
+			a.Method, StartupActionHome, StartupActionMoveToJointPositions, StartupActionSetGPIO, StartupActionDoCommand))
+	}
+	if a.Retries < 0 {
+		return resource.NewConfigValidationError(path, errors.New("retries cannot be negative"))
+	}
+	if a.TimeoutSec < 0 {
+		return resource.NewConfigValidationError(path, errors.New("timeout_sec cannot be negative"))
+	}
+	switch a.OnFailure {
+	case "", StartupActionContinue, StartupActionStop:
+	default:
+		return resource.NewConfigValidationError(path, errors.Errorf("unknown on_failure %q, expected %q or %q",
+			a.OnFailure, StartupActionContinue, StartupActionStop))
+	}
+	return nil
+}
@@ -4,6 +4,8 @@ package control
 
 import (
 	"context"
+
+	"go.viam.com/rdk/utils/realtime"
 )
 
 // Controllable controllable type for a DC motor.
@@ -18,6 +20,8 @@ type Controllable interface {
 type Config struct {
 	Blocks    []BlockConfig `json:"blocks"`    // Blocks Control Block Config
 	Frequency float64       `json:"frequency"` // Frequency loop Frequency
+	// Realtime applies real-time scheduling hints to the goroutines of the loop
+	Realtime *realtime.Config `json:"realtime,omitempty"`
 }
 
 // Control control interface can be used to interfact with a control loop to query signals, change config, start/stop the loop etc...
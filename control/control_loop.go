@@ -11,6 +11,7 @@ import (
 	"go.viam.com/utils"
 
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/utils/realtime"
 )
 
 // controlBlockInternal Holds internal variables to control the flow of data between blocks.
@@ -41,6 +42,7 @@ type Loop struct {
 	cancel                  context.CancelFunc
 	running                 atomic.Bool
 	pidBlocks               []*basicPID
+	realtimeWarning         sync.Once
 }
 
 // NewLoop construct a new control loop for a specific endpoint.
@@ -61,6 +63,9 @@ func createLoop(logger logging.Logger, cfg Config, m Controllable) (*Loop, error
 	if l.cfg.Frequency == 0.0 || l.cfg.Frequency > 200 {
 		return nil, errors.New("loop frequency shouldn't be 0 or above 200Hz")
 	}
+	if err := l.cfg.Realtime.Validate(""); err != nil {
+		return nil, err
+	}
 	l.dt = time.Duration(float64(time.Second) * (1.0 / (l.cfg.Frequency)))
 	for _, bcfg := range cfg.Blocks {
 		blk, err := l.createBlock(bcfg, logger)
@@ -88,6 +93,7 @@ func createLoop(logger logging.Logger, cfg Config, m Controllable) (*Loop, error
 			l.ts = append(l.ts, make(chan time.Time, 1))
 			l.activeBackgroundWorkers.Add(1)
 			utils.ManagedGo(func() {
+				l.enterRealtime()
 				t := l.ts[len(l.ts)-1]
 				b := b
 				close(waitCh)
@@ -114,6 +120,7 @@ func createLoop(logger logging.Logger, cfg Config, m Controllable) (*Loop, error
 			waitCh := make(chan struct{})
 			l.activeBackgroundWorkers.Add(1)
 			utils.ManagedGo(func() {
+				l.enterRealtime()
 				b := b
 				close(waitCh)
 				for {
@@ -161,6 +168,14 @@ func createLoop(logger logging.Logger, cfg Config, m Controllable) (*Loop, error
 	return &l, nil
 }
 
+// enterRealtime applies the real-time scheduling hints of the loop to the calling goroutine, warning once if they
+// cannot be applied.
+func (l *Loop) enterRealtime() {
+	if err := realtime.Enter(l.cfg.Realtime); err != nil {
+		l.realtimeWarning.Do(func() { l.logger.Warn(err) })
+	}
+}
+
 // OutputAt returns the Signal at the block name, error when the block doesn't exist.
 func (l *Loop) OutputAt(ctx context.Context, name string) ([]*Signal, error) {
 	blk, ok := l.blocks[name]
@@ -226,6 +241,7 @@ func (l *Loop) Start() error {
 	waitCh := make(chan struct{})
 	l.activeBackgroundWorkers.Add(1)
 	utils.ManagedGo(func() {
+		l.enterRealtime()
 		ct := l.ct
 		ts := l.ts
 		close(waitCh)
@@ -0,0 +1,63 @@
+// Package fastpath lets control code running in the same process as its components, such as a balancing controller
+// built into the same module, read encoders and command motors at kilohertz rates. Components which support it are
+// called directly through Go interfaces whose methods take no context or extra parameters and do not allocate, rather
+// than through their gRPC API.
+// This is an Experimental package
+package fastpath
+
+import (
+	"context"
+
+	"go.viam.com/rdk/components/encoder"
+	"go.viam.com/rdk/components/motor"
+)
+
+// An Encoder is an encoder whose position can be read from a control loop without allocating.
+type Encoder interface {
+	// ReadTicks returns the relative position of the encoder in ticks.
+	ReadTicks() (int64, error)
+}
+
+// A Motor is a motor whose power can be set from a control loop without allocating.
+type Motor interface {
+	// WritePower sets the power of the motor from -1 to 1, where the sign is the direction. Unlike SetPower it does
+	// not cancel the running operation of the motor, such as a GoFor, so the control loop must own the motor.
+	WritePower(powerPct float64) error
+}
+
+// NewEncoder returns the fast path of an encoder, and whether the encoder supports it. An encoder which does not, such
+// as one on a remote part, is read through its Position instead, which works the same but is slower and allocates.
+func NewEncoder(enc encoder.Encoder) (Encoder, bool) {
+	if fast, ok := enc.(Encoder); ok {
+		return fast, true
+	}
+	return &positionEncoder{enc: enc}, false
+}
+
+// NewMotor returns the fast path of a motor, and whether the motor supports it. A motor which does not, such as one
+// on a remote part, is commanded through its SetPower instead, which works the same but is slower and allocates.
+func NewMotor(m motor.Motor) (Motor, bool) {
+	if fast, ok := m.(Motor); ok {
+		return fast, true
+	}
+	return &powerMotor{m: m}, false
+}
+
+// positionEncoder reads an encoder through its API.
+type positionEncoder struct {
+	enc encoder.Encoder
+}
+
+func (e *positionEncoder) ReadTicks() (int64, error) {
+	ticks, _, err := e.enc.Position(context.Background(), encoder.PositionTypeTicks, nil)
+	return int64(ticks), err
+}
+
+// powerMotor commands a motor through its API.
+type powerMotor struct {
+	m motor.Motor
+}
+
+func (m *powerMotor) WritePower(powerPct float64) error {
+	return m.m.SetPower(context.Background(), powerPct, nil)
+}
@@ -0,0 +1,144 @@
+package fastpath
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/encoder"
+	fakeencoder "go.viam.com/rdk/components/encoder/fake"
+	"go.viam.com/rdk/components/motor"
+	fakemotor "go.viam.com/rdk/components/motor/fake"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/operation"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestFastPaths(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	cancelCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	enc, err := fakeencoder.NewEncoder(cancelCtx, resource.Config{
+		Name:                "enc",
+		ConvertedAttributes: &fakeencoder.Config{UpdateRate: 1000},
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	m := &fakemotor.Motor{
+		Named:            motor.Named("motor").AsNamed(),
+		Encoder:          enc.(fakeencoder.Encoder),
+		MaxRPM:           60,
+		TicksPerRotation: 100,
+		OpMgr:            operation.NewSingleOperationManager(),
+		Logger:           logger,
+	}
+
+	fastEncoder, ok := NewEncoder(enc)
+	test.That(t, ok, test.ShouldBeTrue)
+	fastMotor, ok := NewMotor(m)
+	test.That(t, ok, test.ShouldBeTrue)
+
+	test.That(t, enc.(fakeencoder.Encoder).SetPosition(ctx, 42), test.ShouldBeNil)
+	ticks, err := fastEncoder.ReadTicks()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ticks, test.ShouldEqual, 42)
+	test.That(t, fastMotor.WritePower(-0.5), test.ShouldBeNil)
+	test.That(t, m.PowerPct(), test.ShouldEqual, -0.5)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		ticks, _ := fastEncoder.ReadTicks()
+		_ = fastMotor.WritePower(float64(ticks) / 100)
+	})
+	test.That(t, allocs, test.ShouldEqual, 0)
+
+	// components without a fast path go through their API
+	injectEncoder := &inject.Encoder{}
+	injectEncoder.PositionFunc = func(
+		ctx context.Context, positionType encoder.PositionType, extra map[string]interface{},
+	) (float64, encoder.PositionType, error) {
+		return 7, encoder.PositionTypeTicks, nil
+	}
+	var power float64
+	injectMotor := &inject.Motor{}
+	injectMotor.SetPowerFunc = func(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
+		power = powerPct
+		return nil
+	}
+	slowEncoder, ok := NewEncoder(injectEncoder)
+	test.That(t, ok, test.ShouldBeFalse)
+	slowMotor, ok := NewMotor(injectMotor)
+	test.That(t, ok, test.ShouldBeFalse)
+	ticks, err = slowEncoder.ReadTicks()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ticks, test.ShouldEqual, 7)
+	test.That(t, slowMotor.WritePower(0.25), test.ShouldBeNil)
+	test.That(t, power, test.ShouldEqual, 0.25)
+}
+
+// fakeClock is a clock whose time only moves when it sleeps or is advanced.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	if d > 0 {
+		c.now = c.now.Add(d)
+	}
+}
+
+func TestRunLoop(t *testing.T) {
+	_, err := RunLoop(context.Background(), 0, func(time.Duration) error { return nil })
+	test.That(t, err, test.ShouldNotBeNil)
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var total time.Duration
+	var calls int
+	stats, err := runLoop(ctx, time.Millisecond, func(dt time.Duration) error {
+		total += dt
+		// steps taking part of their period do not make the loop drift
+		clock.Sleep(300 * time.Microsecond)
+		if calls++; calls == 100 {
+			cancel()
+		}
+		return nil
+	}, clock.Now, clock.Sleep)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stats.Iterations, test.ShouldEqual, 100)
+	test.That(t, stats.Overruns, test.ShouldEqual, 0)
+	test.That(t, total, test.ShouldEqual, 99*time.Millisecond)
+
+	// a slow step overruns its period, and the periods it misses are skipped
+	clock = &fakeClock{now: time.Unix(0, 0)}
+	errStop := errors.New("stop")
+	var dts []time.Duration
+	stats, err = runLoop(context.Background(), time.Millisecond, func(dt time.Duration) error {
+		dts = append(dts, dt)
+		switch len(dts) {
+		case 1:
+			clock.Sleep(5 * time.Millisecond)
+		case 3:
+			return errStop
+		}
+		return nil
+	}, clock.Now, clock.Sleep)
+	test.That(t, err, test.ShouldBeError, errStop)
+	test.That(t, stats.Iterations, test.ShouldEqual, 2)
+	test.That(t, stats.Overruns, test.ShouldEqual, 1)
+	test.That(t, stats.MaxLateness, test.ShouldEqual, 4*time.Millisecond)
+	test.That(t, dts, test.ShouldResemble, []time.Duration{0, 5 * time.Millisecond, time.Millisecond})
+
+	// on the real clock the loop runs until its context is done
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	stats, err = RunLoop(ctx, time.Millisecond, func(time.Duration) error { return nil })
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stats.Iterations, test.ShouldBeGreaterThan, 0)
+}
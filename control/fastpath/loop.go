@@ -0,0 +1,65 @@
+package fastpath
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// LoopStats describes how well a loop kept to its period.
+type LoopStats struct {
+	Iterations uint64
+	// Overruns counts the iterations which started a period or more late. The periods they missed are skipped
+	// rather than run back to back.
+	Overruns uint64
+	// MaxLateness is the longest an iteration started after it was due.
+	MaxLateness time.Duration
+}
+
+// RunLoop calls step once every period until the context is done, returning nil, or until step returns an error,
+// which it returns. dt is the time since the previous call of step. The loop runs on the calling goroutine, locked to
+// its OS thread, and schedules each call from the start of the loop so that the period does not drift with the time
+// step takes. Neither the loop nor the fast paths allocate, so a step which does not either runs without garbage
+// collection pauses of its own making.
+func RunLoop(ctx context.Context, period time.Duration, step func(dt time.Duration) error) (LoopStats, error) {
+	return runLoop(ctx, period, step, time.Now, time.Sleep)
+}
+
+// runLoop is RunLoop keeping time with now and sleep, which tests replace with a fake clock.
+func runLoop(
+	ctx context.Context,
+	period time.Duration,
+	step func(dt time.Duration) error,
+	now func() time.Time,
+	sleep func(time.Duration),
+) (LoopStats, error) {
+	var stats LoopStats
+	if period <= 0 {
+		return stats, errors.New("loop period must be positive")
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	last := now()
+	next := last
+	for ctx.Err() == nil {
+		start := now()
+		if late := start.Sub(next); late > 0 {
+			stats.MaxLateness = max(stats.MaxLateness, late)
+			if late >= period {
+				stats.Overruns++
+				next = next.Add(late / period * period)
+			}
+		}
+		if err := step(start.Sub(last)); err != nil {
+			return stats, err
+		}
+		stats.Iterations++
+		last = start
+		next = next.Add(period)
+		sleep(next.Sub(now()))
+	}
+	return stats, nil
+}
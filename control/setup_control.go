@@ -9,6 +9,7 @@ import (
 
 	"go.viam.com/rdk/logging"
 	rdkutils "go.viam.com/rdk/utils"
+	"go.viam.com/rdk/utils/realtime"
 )
 
 // BlockNameEndpoint, BlockNameConstant, and BlockNameTrapezoidal
@@ -86,6 +87,10 @@ type Options struct {
 	// ControllableType is the type of component the control loop will be set up for,
 	// currently a base or motor
 	ControllableType string
+
+	// Realtime applies real-time scheduling hints to the goroutines of the control loop,
+	// overriding those of a custom config
+	Realtime *realtime.Config
 }
 
 // SetupPIDControlConfig creates a control config.
@@ -116,6 +121,9 @@ func SetupPIDControlConfig(
 	} else {
 		pidLoop.createControlLoopConfig(pidVals, componentName)
 	}
+	if options.Realtime != nil {
+		pidLoop.ControlConf.Realtime = options.Realtime
+	}
 
 	// auto tune the control loop if needed
 	if options.NeedsAutoTuning {
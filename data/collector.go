@@ -34,6 +34,14 @@ var sleepCaptureCutoff = 2 * time.Millisecond
 // CaptureFunc allows the creation of simple Capturers with anonymous functions.
 type CaptureFunc func(ctx context.Context, params map[string]*anypb.Any) (interface{}, error)
 
+// TimestampedReading is a reading a CaptureFunc can return with the time it was captured at, such as an image with
+// the time its camera captured it. It is stored as requested and received at that time, like the images of
+// GetImages are when uploaded, rather than when the collector asked for it.
+type TimestampedReading struct {
+	Reading    interface{}
+	CapturedAt time.Time
+}
+
 // FromDMContextKey is used to check whether the context is from data management.
 // Deprecated: use a camera.Extra with camera.NewContext instead.
 type FromDMContextKey struct{}
@@ -226,6 +234,13 @@ func (c *collector) getAndPushNextReading() {
 		c.captureErrors <- errors.Wrap(err, "error while capturing data")
 		return
 	}
+	if timestamped, ok := reading.(TimestampedReading); ok {
+		reading = timestamped.Reading
+		if !timestamped.CapturedAt.IsZero() {
+			timeRequested = timestamppb.New(timestamped.CapturedAt.UTC())
+			timeReceived = timeRequested
+		}
+	}
 
 	var msg v1.SensorData
 	switch v := reading.(type) {
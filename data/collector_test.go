@@ -373,3 +373,21 @@ func (b *signalingBuffer) Flush() error {
 func (b *signalingBuffer) Path() string {
 	return b.bw.Path()
 }
+
+func TestTimestampedReading(t *testing.T) {
+	capturedAt := time.UnixMilli(12345)
+	c := &collector{
+		clock:          clock.NewMock(),
+		captureResults: make(chan *v1.SensorData, 1),
+		captureErrors:  make(chan error, 1),
+		cancelCtx:      context.Background(),
+		captureFunc: CaptureFunc(func(ctx context.Context, _ map[string]*anypb.Any) (interface{}, error) {
+			return TimestampedReading{Reading: dummyBytesReading, CapturedAt: capturedAt}, nil
+		}),
+	}
+	c.getAndPushNextReading()
+	msg := <-c.captureResults
+	test.That(t, msg.GetBinary(), test.ShouldResemble, dummyBytesReading)
+	test.That(t, msg.GetMetadata().GetTimeRequested().AsTime().Equal(capturedAt), test.ShouldBeTrue)
+	test.That(t, msg.GetMetadata().GetTimeReceived().AsTime().Equal(capturedAt), test.ShouldBeTrue)
+}
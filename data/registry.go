@@ -2,6 +2,9 @@ package data
 
 import (
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -82,3 +85,84 @@ func RegisteredCollectors() map[MethodMetadata]CollectorConstructor {
 	}
 	return copied.(map[MethodMetadata]CollectorConstructor)
 }
+
+// MethodParamSpec describes an entry of additional_params that a collector understands.
+type MethodParamSpec struct {
+	Name     string
+	Required bool
+	// Default is used when the parameter is not configured. It is ignored for required parameters.
+	Default string
+	// Allowed lists the accepted values of the parameter. Any value is accepted if it is empty.
+	Allowed []string
+}
+
+var collectorParamsRegistry = map[MethodMetadata][]MethodParamSpec{}
+
+// RegisterCollectorParams registers the additional parameters accepted by the Collector for the given MethodMetadata.
+// Once registered, configuring any other parameter for the method is an error.
+func RegisterCollectorParams(method MethodMetadata, specs ...MethodParamSpec) {
+	if _, old := collectorParamsRegistry[method]; old {
+		panic(errors.Errorf("trying to register params twice for the same method on the same component: "+
+			"component %s, method %s", method.API, method.MethodName))
+	}
+	collectorParamsRegistry[method] = specs
+}
+
+// ValidateMethodParams checks that a collector is registered for the method and that params satisfy the parameters
+// registered for it, returning a copy of params with defaults filled in. Methods of APIs which have no collectors
+// registered at all are not checked, as their collectors may be registered in another process such as a module.
+// Params of methods which have not registered their parameters are returned as is.
+func ValidateMethodParams(method MethodMetadata, params map[string]string) (map[string]string, error) {
+	if _, ok := collectorRegistry[method]; !ok {
+		var known []string
+		for registered := range collectorRegistry {
+			if registered.API == method.API {
+				known = append(known, registered.MethodName)
+			}
+		}
+		if len(known) == 0 {
+			return params, nil
+		}
+		sort.Strings(known)
+		return nil, errors.Errorf("no capture method %q for %s, must be one of [%s]",
+			method.MethodName, method.API, strings.Join(known, ", "))
+	}
+
+	specs, ok := collectorParamsRegistry[method]
+	if !ok {
+		return params, nil
+	}
+	withDefaults := make(map[string]string, len(specs))
+	for key, val := range params {
+		idx := slices.IndexFunc(specs, func(spec MethodParamSpec) bool { return spec.Name == key })
+		if idx == -1 {
+			return nil, errors.Errorf("unknown additional_params key %q for %s, must be one of [%s]",
+				key, method, strings.Join(paramNames(specs), ", "))
+		}
+		if allowed := specs[idx].Allowed; len(allowed) != 0 && !slices.Contains(allowed, val) {
+			return nil, errors.Errorf("invalid value %q of additional_params key %q for %s, must be one of [%s]",
+				val, key, method, strings.Join(allowed, ", "))
+		}
+		withDefaults[key] = val
+	}
+	for _, spec := range specs {
+		if _, ok := withDefaults[spec.Name]; ok {
+			continue
+		}
+		if spec.Required {
+			return nil, errors.Errorf("failed to validate additional_params for %s, must supply %s", method, spec.Name)
+		}
+		if spec.Default != "" {
+			withDefaults[spec.Name] = spec.Default
+		}
+	}
+	return withDefaults, nil
+}
+
+func paramNames(specs []MethodParamSpec) []string {
+	names := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		names = append(names, spec.Name)
+	}
+	return names
+}
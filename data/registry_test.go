@@ -46,3 +46,60 @@ func TestRegister(t *testing.T) {
 	// Panic if try to register same thing twice.
 	test.That(t, func() { RegisterCollector(md, dummyCollectorConstructor) }, test.ShouldPanic)
 }
+
+func TestValidateMethodParams(t *testing.T) {
+	defer func() {
+		for k := range collectorRegistry {
+			delete(collectorRegistry, k)
+		}
+		for k := range collectorParamsRegistry {
+			delete(collectorParamsRegistry, k)
+		}
+	}()
+	api := resource.APINamespaceRDK.WithComponentType("type")
+	withParams := MethodMetadata{API: api, MethodName: "WithParams"}
+	withoutParams := MethodMetadata{API: api, MethodName: "WithoutParams"}
+	RegisterCollector(withParams, dummyCollectorConstructor)
+	RegisterCollector(withoutParams, dummyCollectorConstructor)
+	RegisterCollectorParams(withParams,
+		MethodParamSpec{Name: "pin", Required: true},
+		MethodParamSpec{Name: "mode", Default: "fast", Allowed: []string{"fast", "slow"}},
+	)
+	test.That(t, func() { RegisterCollectorParams(withParams) }, test.ShouldPanic)
+
+	params, err := ValidateMethodParams(withParams, map[string]string{"pin": "1"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, params, test.ShouldResemble, map[string]string{"pin": "1", "mode": "fast"})
+
+	params, err = ValidateMethodParams(withParams, map[string]string{"pin": "1", "mode": "slow"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, params, test.ShouldResemble, map[string]string{"pin": "1", "mode": "slow"})
+
+	_, err = ValidateMethodParams(withParams, map[string]string{"mode": "slow"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "must supply pin")
+
+	_, err = ValidateMethodParams(withParams, map[string]string{"pin": "1", "mdoe": "slow"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `unknown additional_params key "mdoe"`)
+
+	_, err = ValidateMethodParams(withParams, map[string]string{"pin": "1", "mode": "medium"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "must be one of [fast, slow]")
+
+	// methods without registered params accept anything
+	params, err = ValidateMethodParams(withoutParams, map[string]string{"anything": "goes"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, params, test.ShouldResemble, map[string]string{"anything": "goes"})
+
+	// a typo in the method name of a known API
+	_, err = ValidateMethodParams(MethodMetadata{API: api, MethodName: "WithParam"}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "must be one of [WithParams, WithoutParams]")
+
+	// APIs without any collectors in this process are not checked
+	unknown := MethodMetadata{API: resource.APINamespaceRDK.WithComponentType("other"), MethodName: "Method"}
+	params, err = ValidateMethodParams(unknown, map[string]string{"key": "value"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, params, test.ShouldResemble, map[string]string{"key": "value"})
+}
@@ -0,0 +1,38 @@
+//go:build cgo && linux && !android
+
+package h265
+
+import (
+	"github.com/edaniels/golog"
+
+	"go.viam.com/rdk/gostream"
+	"go.viam.com/rdk/gostream/codec"
+)
+
+// DefaultStreamConfig configures h265 as the encoder for a stream.
+var DefaultStreamConfig gostream.StreamConfig
+
+func init() {
+	DefaultStreamConfig.VideoEncoderFactory = NewEncoderFactory()
+}
+
+// NewEncoderFactory returns an h265 encoder factory.
+func NewEncoderFactory() codec.VideoEncoderFactory {
+	return &factory{}
+}
+
+// Available returns whether this machine has any of the Encoders.
+func Available() bool {
+	_, ok := availableEncoder()
+	return ok
+}
+
+type factory struct{}
+
+func (f *factory) New(width, height, keyFrameInterval int, logger golog.Logger) (codec.VideoEncoder, error) {
+	return NewEncoder(width, height, keyFrameInterval, logger)
+}
+
+func (f *factory) MIMEType() string {
+	return "video/H265"
+}
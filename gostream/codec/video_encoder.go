@@ -3,6 +3,7 @@ package codec
 
 import (
 	"context"
+	"errors"
 	"image"
 
 	"github.com/edaniels/golog"
@@ -21,6 +22,16 @@ type VideoEncoder interface {
 	Close() error
 }
 
+// A BitrateController is a VideoEncoder whose target bitrate can be changed while encoding, such as to follow the
+// bandwidth of the network the video is sent over. SetBitrate returns ErrBitrateUnsupported if the underlying codec
+// cannot change it.
+type BitrateController interface {
+	SetBitrate(bitsPerSecond int) error
+}
+
+// ErrBitrateUnsupported is returned by a BitrateController whose codec cannot change its bitrate while encoding.
+var ErrBitrateUnsupported = errors.New("codec cannot change its bitrate while encoding")
+
 // A VideoEncoderFactory produces VideoEncoders and provides information about the underlying encoder itself.
 type VideoEncoderFactory interface {
 	New(height, width, keyFrameInterval int, logger golog.Logger) (VideoEncoder, error)
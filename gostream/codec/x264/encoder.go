@@ -65,6 +65,16 @@ func (v *encoder) Encode(_ context.Context, img image.Image) ([]byte, error) {
 	return dataCopy, err
 }
 
+// SetBitrate changes the target bitrate of the frames encoded from now on, if the codec can change it while
+// encoding.
+func (v *encoder) SetBitrate(bitsPerSecond int) error {
+	controller, ok := v.codec.Controller().(codec.BitRateController)
+	if !ok {
+		return ourcodec.ErrBitrateUnsupported
+	}
+	return controller.SetBitRate(bitsPerSecond)
+}
+
 // Close closes the encoder.
 func (v *encoder) Close() error {
 	return v.codec.Close()
@@ -0,0 +1,163 @@
+package gostream
+
+import (
+	"image"
+	"sync"
+
+	"go.viam.com/utils"
+	"golang.org/x/image/draw"
+)
+
+// maxFreeFrames is how many unused buffers of each size a FramePool keeps. A frame is usually held by a source, the
+// stream encoding it and a few readers at once, so a handful is enough to stop allocating once streaming is steady.
+const maxFreeFrames = 6
+
+// A FramePool lends out the pixel buffers of video frames, so that a source producing frames of the same size over
+// and over, such as a resize or the scaling before an encoder, reuses them instead of allocating one per frame. The
+// pixels of a borrowed frame are left over from its last use and must be overwritten.
+type FramePool struct {
+	mu   sync.Mutex
+	free map[int][][]uint8
+}
+
+// NewFramePool returns an empty pool.
+func NewFramePool() *FramePool {
+	return &FramePool{free: map[int][][]uint8{}}
+}
+
+func (p *FramePool) get(n int) []uint8 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	bufs := p.free[n]
+	if len(bufs) == 0 {
+		return make([]uint8, n)
+	}
+	buf := bufs[len(bufs)-1]
+	p.free[n] = bufs[:len(bufs)-1]
+	return buf
+}
+
+func (p *FramePool) put(buf []uint8) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.free[len(buf)]) < maxFreeFrames {
+		p.free[len(buf)] = append(p.free[len(buf)], buf)
+	}
+}
+
+// A FrameRef counts the holders of a frame borrowed from a FramePool. The frame can be handed from its source to any
+// number of consumers without being copied, and its buffer goes back to the pool once each of them has released it.
+type FrameRef struct {
+	ref  utils.RefCountedValue
+	once sync.Once
+	free func()
+}
+
+func newFrameRef(free func()) *FrameRef {
+	fr := &FrameRef{ref: utils.NewRefCountedValue(nil), free: free}
+	fr.ref.Ref()
+	return fr
+}
+
+// Ref adds a holder of the frame and returns the function with which it releases the frame. It panics if every
+// holder has already released the frame, since its buffer may be in use by another frame by then.
+func (fr *FrameRef) Ref() func() {
+	fr.ref.Ref()
+	var once sync.Once
+	return func() { once.Do(fr.deref) }
+}
+
+// Release releases the frame on behalf of whoever borrowed it from the pool. It is safe to call more than once.
+func (fr *FrameRef) Release() {
+	fr.once.Do(fr.deref)
+}
+
+func (fr *FrameRef) deref() {
+	if fr.ref.Deref() {
+		fr.free()
+	}
+}
+
+// NewYCbCr borrows a YCbCr frame of the given bounds and subsample ratio, laid out as image.NewYCbCr would.
+func (p *FramePool) NewYCbCr(r image.Rectangle, ratio image.YCbCrSubsampleRatio) (*image.YCbCr, *FrameRef) {
+	w, h := r.Dx(), r.Dy()
+	cw, ch := chromaSize(r, ratio)
+	yLen, cLen := w*h, cw*ch
+	buf := p.get(yLen + 2*cLen)
+	return &image.YCbCr{
+		Y:              buf[:yLen:yLen],
+		Cb:             buf[yLen : yLen+cLen : yLen+cLen],
+		Cr:             buf[yLen+cLen : yLen+2*cLen : yLen+2*cLen],
+		SubsampleRatio: ratio,
+		YStride:        w,
+		CStride:        cw,
+		Rect:           r,
+	}, newFrameRef(func() { p.put(buf) })
+}
+
+// NewRGBA borrows an RGBA frame of the given bounds.
+func (p *FramePool) NewRGBA(r image.Rectangle) (*image.RGBA, *FrameRef) {
+	buf := p.get(4 * r.Dx() * r.Dy())
+	return &image.RGBA{Pix: buf, Stride: 4 * r.Dx(), Rect: r}, newFrameRef(func() { p.put(buf) })
+}
+
+// ResizeFrame scales a frame to the given size by nearest neighbor into a frame borrowed from the pool, returning it
+// with the function to release it. YCbCr frames, which most cameras and every encoder use, are scaled plane by plane
+// and stay YCbCr, rather than being converted to RGB and back.
+func ResizeFrame(pool *FramePool, img image.Image, width, height int) (image.Image, func()) {
+	r := image.Rect(0, 0, width, height)
+	if src, ok := img.(*image.YCbCr); ok {
+		dst, ref := pool.NewYCbCr(r, src.SubsampleRatio)
+		srcW, srcH := chromaSize(src.Rect, src.SubsampleRatio)
+		dstW, dstH := chromaSize(r, src.SubsampleRatio)
+		yOff := src.YOffset(src.Rect.Min.X, src.Rect.Min.Y)
+		cOff := src.COffset(src.Rect.Min.X, src.Rect.Min.Y)
+		scalePlane(dst.Y, width, height, dst.YStride, src.Y[yOff:], src.Rect.Dx(), src.Rect.Dy(), src.YStride)
+		scalePlane(dst.Cb, dstW, dstH, dst.CStride, src.Cb[cOff:], srcW, srcH, src.CStride)
+		scalePlane(dst.Cr, dstW, dstH, dst.CStride, src.Cr[cOff:], srcW, srcH, src.CStride)
+		return dst, ref.Release
+	}
+	dst, ref := pool.NewRGBA(r)
+	draw.NearestNeighbor.Scale(dst, r, img, img.Bounds(), draw.Src, nil)
+	return dst, ref.Release
+}
+
+// scalePlane scales one plane of samples by nearest neighbor.
+func scalePlane(dst []uint8, dstW, dstH, dstStride int, src []uint8, srcW, srcH, srcStride int) {
+	if dstW <= 0 || dstH <= 0 || srcW <= 0 || srcH <= 0 {
+		return
+	}
+	xs := make([]int, dstW)
+	for x := range xs {
+		xs[x] = x * srcW / dstW
+	}
+	for y := 0; y < dstH; y++ {
+		srcRow := src[(y*srcH/dstH)*srcStride:]
+		dstRow := dst[y*dstStride : y*dstStride+dstW]
+		for x, sx := range xs {
+			dstRow[x] = srcRow[sx]
+		}
+	}
+}
+
+// chromaSize returns the width and height of the chroma planes of a YCbCr image, as image.NewYCbCr lays them out.
+func chromaSize(r image.Rectangle, ratio image.YCbCrSubsampleRatio) (int, int) {
+	w, h := r.Dx(), r.Dy()
+	halfW := (r.Max.X+1)/2 - r.Min.X/2
+	halfH := (r.Max.Y+1)/2 - r.Min.Y/2
+	quarterW := (r.Max.X+3)/4 - r.Min.X/4
+	switch ratio {
+	case image.YCbCrSubsampleRatio422:
+		return halfW, h
+	case image.YCbCrSubsampleRatio420:
+		return halfW, halfH
+	case image.YCbCrSubsampleRatio440:
+		return w, halfH
+	case image.YCbCrSubsampleRatio411:
+		return quarterW, h
+	case image.YCbCrSubsampleRatio410:
+		return quarterW, halfH
+	default:
+		return w, h
+	}
+}
@@ -0,0 +1,93 @@
+package gostream
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestFramePoolReuse(t *testing.T) {
+	pool := NewFramePool()
+	r := image.Rect(0, 0, 64, 48)
+
+	first, ref := pool.NewYCbCr(r, image.YCbCrSubsampleRatio420)
+	release := ref.Ref()
+	ref.Release()
+	ref.Release()
+
+	// still held by the second holder
+	second, ref2 := pool.NewYCbCr(r, image.YCbCrSubsampleRatio420)
+	test.That(t, &second.Y[0], test.ShouldNotEqual, &first.Y[0])
+
+	release()
+	release()
+	third, ref3 := pool.NewYCbCr(r, image.YCbCrSubsampleRatio420)
+	test.That(t, &third.Y[0], test.ShouldEqual, &first.Y[0])
+	test.That(t, func() { ref.Ref() }, test.ShouldPanic)
+
+	// buffers are shared by size, whatever the layout
+	ref2.Release()
+	rgba, _ := pool.NewRGBA(image.Rect(0, 0, 32, 36))
+	test.That(t, &rgba.Pix[0], test.ShouldEqual, &second.Y[0])
+	ref3.Release()
+}
+
+func TestFramePoolLayout(t *testing.T) {
+	pool := NewFramePool()
+	for _, ratio := range []image.YCbCrSubsampleRatio{
+		image.YCbCrSubsampleRatio444,
+		image.YCbCrSubsampleRatio422,
+		image.YCbCrSubsampleRatio420,
+		image.YCbCrSubsampleRatio440,
+		image.YCbCrSubsampleRatio411,
+		image.YCbCrSubsampleRatio410,
+	} {
+		for _, r := range []image.Rectangle{image.Rect(0, 0, 64, 48), image.Rect(1, 3, 10, 8)} {
+			expected := image.NewYCbCr(r, ratio)
+			img, ref := pool.NewYCbCr(r, ratio)
+			test.That(t, img.YStride, test.ShouldEqual, expected.YStride)
+			test.That(t, img.CStride, test.ShouldEqual, expected.CStride)
+			test.That(t, len(img.Y), test.ShouldEqual, len(expected.Y))
+			test.That(t, len(img.Cb), test.ShouldEqual, len(expected.Cb))
+			test.That(t, len(img.Cr), test.ShouldEqual, len(expected.Cr))
+			ref.Release()
+		}
+	}
+}
+
+func TestResizeFrame(t *testing.T) {
+	pool := NewFramePool()
+
+	src := image.NewYCbCr(image.Rect(0, 0, 8, 4), image.YCbCrSubsampleRatio420)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 8; x++ {
+			src.Y[src.YOffset(x, y)] = uint8(10*y + x)
+			src.Cb[src.COffset(x, y)] = uint8(100 + x/2)
+			src.Cr[src.COffset(x, y)] = uint8(200 + y/2)
+		}
+	}
+	resized, release := ResizeFrame(pool, src, 4, 2)
+	dst, ok := resized.(*image.YCbCr)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, dst.Bounds(), test.ShouldResemble, image.Rect(0, 0, 4, 2))
+	test.That(t, dst.SubsampleRatio, test.ShouldEqual, image.YCbCrSubsampleRatio420)
+	test.That(t, dst.YCbCrAt(1, 1), test.ShouldResemble, color.YCbCr{Y: 22, Cb: 100, Cr: 200})
+	test.That(t, dst.YCbCrAt(3, 0), test.ShouldResemble, color.YCbCr{Y: 6, Cb: 102, Cr: 200})
+	release()
+
+	// sub images are scaled from their own bounds
+	sub := src.SubImage(image.Rect(2, 2, 6, 4)).(*image.YCbCr)
+	resized, release = ResizeFrame(pool, sub, 2, 1)
+	test.That(t, resized.(*image.YCbCr).YCbCrAt(1, 0), test.ShouldResemble, color.YCbCr{Y: 24, Cb: 101, Cr: 201})
+	release()
+
+	rgba := image.NewRGBA(image.Rect(0, 0, 6, 6))
+	rgba.Set(5, 5, color.RGBA{R: 255, A: 255})
+	resized, release = ResizeFrame(pool, rgba, 3, 3)
+	test.That(t, resized, test.ShouldHaveSameTypeAs, &image.RGBA{})
+	test.That(t, resized.At(2, 2), test.ShouldResemble, color.RGBA{R: 255, A: 255})
+	test.That(t, resized.At(0, 0), test.ShouldResemble, color.RGBA{})
+	release()
+}
@@ -0,0 +1,79 @@
+package gostream
+
+import "bytes"
+
+const (
+	h265NALUHeaderSize          = 2
+	h265FragmentationUnitType   = 49
+	h265FragmentationHeaderSize = 1
+)
+
+// h265Payloader payloads H.265 Annex B bytestreams into RTP packets as described by RFC 7798, which pion does not
+// implement yet. NAL units which fit the MTU are sent as single NAL unit packets and the rest as fragmentation units.
+type h265Payloader struct{}
+
+// Payload fragments an H.265 Annex B bytestream across one or more byte arrays.
+func (p *h265Payloader) Payload(mtu uint16, payload []byte) [][]byte {
+	var payloads [][]byte
+	if int(mtu) <= h265NALUHeaderSize+h265FragmentationHeaderSize {
+		return payloads
+	}
+	for _, nalu := range splitAnnexB(payload) {
+		if len(nalu) < h265NALUHeaderSize {
+			continue
+		}
+		if len(nalu) <= int(mtu) {
+			payloads = append(payloads, append([]byte(nil), nalu...))
+			continue
+		}
+
+		// the payload header keeps the F bit, layer ID and temporal ID of the NAL unit, and its type moves into the
+		// fragmentation unit header
+		naluType := (nalu[0] >> 1) & 0x3f
+		header := []byte{(nalu[0] & 0x81) | (h265FragmentationUnitType << 1), nalu[1]}
+		maxFragmentSize := int(mtu) - h265NALUHeaderSize - h265FragmentationHeaderSize
+		data := nalu[h265NALUHeaderSize:]
+		for offset := 0; offset < len(data); offset += maxFragmentSize {
+			end := offset + maxFragmentSize
+			if end > len(data) {
+				end = len(data)
+			}
+			fuHeader := naluType
+			if offset == 0 {
+				fuHeader |= 0x80
+			}
+			if end == len(data) {
+				fuHeader |= 0x40
+			}
+			out := make([]byte, 0, h265NALUHeaderSize+h265FragmentationHeaderSize+end-offset)
+			out = append(out, header...)
+			out = append(out, fuHeader)
+			out = append(out, data[offset:end]...)
+			payloads = append(payloads, out)
+		}
+	}
+	return payloads
+}
+
+// splitAnnexB returns the NAL units of an Annex B bytestream, without their start codes.
+func splitAnnexB(stream []byte) [][]byte {
+	startCode := []byte{0, 0, 1}
+	var nalus [][]byte
+	start := bytes.Index(stream, startCode)
+	if start == -1 {
+		// not Annex B, so take it as a single NAL unit
+		return [][]byte{stream}
+	}
+	start += len(startCode)
+	for {
+		next := bytes.Index(stream[start:], startCode)
+		if next == -1 {
+			nalus = append(nalus, stream[start:])
+			return nalus
+		}
+		end := start + next
+		// the zero before a three byte start code belongs to a four byte start code
+		nalus = append(nalus, bytes.TrimRight(stream[start:end], "\x00"))
+		start = end + len(startCode)
+	}
+}
@@ -0,0 +1,41 @@
+package gostream
+
+import (
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestSplitAnnexB(t *testing.T) {
+	stream := []byte{0, 0, 0, 1, 0x40, 0x01, 0xaa, 0, 0, 1, 0x42, 0x01, 0xbb, 0xcc}
+	test.That(t, splitAnnexB(stream), test.ShouldResemble, [][]byte{
+		{0x40, 0x01, 0xaa},
+		{0x42, 0x01, 0xbb, 0xcc},
+	})
+
+	test.That(t, splitAnnexB([]byte{0x26, 0x01, 0xdd}), test.ShouldResemble, [][]byte{{0x26, 0x01, 0xdd}})
+}
+
+func TestH265Payloader(t *testing.T) {
+	var p h265Payloader
+
+	t.Run("single NAL unit", func(t *testing.T) {
+		payloads := p.Payload(1200, []byte{0, 0, 1, 0x40, 0x01, 0xaa, 0xbb})
+		test.That(t, payloads, test.ShouldResemble, [][]byte{{0x40, 0x01, 0xaa, 0xbb}})
+	})
+
+	t.Run("fragmentation units", func(t *testing.T) {
+		// an IDR_W_RADL slice, type 19
+		nalu := []byte{19 << 1, 0x01, 1, 2, 3, 4, 5, 6, 7}
+		payloads := p.Payload(6, append([]byte{0, 0, 1}, nalu...))
+		test.That(t, payloads, test.ShouldResemble, [][]byte{
+			{h265FragmentationUnitType << 1, 0x01, 0x80 | 19, 1, 2, 3},
+			{h265FragmentationUnitType << 1, 0x01, 19, 4, 5, 6},
+			{h265FragmentationUnitType << 1, 0x01, 0x40 | 19, 7},
+		})
+	})
+
+	t.Run("mtu too small", func(t *testing.T) {
+		test.That(t, p.Payload(3, []byte{0, 0, 1, 0x40, 0x01, 0xaa}), test.ShouldBeEmpty)
+	})
+}
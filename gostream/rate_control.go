@@ -0,0 +1,223 @@
+package gostream
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+// The defaults of RateControlConfig.
+const (
+	DefaultMinBitrate   = 150_000
+	DefaultMaxBitrate   = 3_200_000
+	DefaultStartBitrate = 3_200_000
+)
+
+// The loss based rate control of congestion control for real time media (draft-ietf-rmcat-gcc): the rate grows by
+// increasePerSecond while peers lose little, holds while they lose some, and backs off in proportion to heavy loss at
+// most once per decreaseInterval, as feedback comes far more often than the rate takes effect.
+const (
+	lowLossFraction   = 0.02
+	highLossFraction  = 0.1
+	increasePerSecond = 1.08
+	decreaseInterval  = 300 * time.Millisecond
+)
+
+// resolutionScales are the scales video is encoded at, from the largest down, and the fraction of the max bitrate
+// under which each smaller scale is used. A scale is left when the rate rises a quarter above its threshold again, so
+// that small swings do not restart the encoder on every report.
+var resolutionScales = []struct {
+	scale        float64
+	belowBitrate float64
+}{
+	{scale: 1},
+	{scale: 0.75, belowBitrate: 0.4},
+	{scale: 0.5, belowBitrate: 0.2},
+	{scale: 0.25, belowBitrate: 0.08},
+}
+
+const resolutionHysteresis = 1.25
+
+// A RateControlConfig bounds the bitrate video is encoded at as it adapts to the peers it is sent to.
+type RateControlConfig struct {
+	// MinBitrate and MaxBitrate bound the bitrate in bits per second. Setting them equal fixes the bitrate and the
+	// resolution.
+	MinBitrate int
+	MaxBitrate int
+	// StartBitrate is the bitrate before any peer has reported on its network.
+	StartBitrate int
+}
+
+// withDefaults returns the config with unset fields defaulted and the start bitrate within bounds.
+func (cfg RateControlConfig) withDefaults() RateControlConfig {
+	if cfg.MinBitrate <= 0 {
+		cfg.MinBitrate = DefaultMinBitrate
+	}
+	if cfg.MaxBitrate <= 0 {
+		cfg.MaxBitrate = DefaultMaxBitrate
+	}
+	if cfg.MaxBitrate < cfg.MinBitrate {
+		cfg.MaxBitrate = cfg.MinBitrate
+	}
+	if cfg.StartBitrate <= 0 {
+		cfg.StartBitrate = DefaultStartBitrate
+	}
+	cfg.StartBitrate = min(max(cfg.StartBitrate, cfg.MinBitrate), cfg.MaxBitrate)
+	return cfg
+}
+
+// subscriberRate is the bitrate the network of one peer can take, and the scale of the resolution its video is
+// encoded at.
+type subscriberRate struct {
+	bitrate float64
+	// remb is the most the peer estimated it can receive, zero until it does.
+	remb         float64
+	scaleIdx     int
+	lastFeedback time.Time
+	lastDecrease time.Time
+}
+
+// rateController estimates the bitrate the network of each peer a video is sent to can take from their RTCP
+// feedback. Each peer has its own encoding of the video, so a slow peer does not hold back the others.
+type rateController struct {
+	mu  sync.Mutex
+	cfg RateControlConfig
+	// subscribers are the peers by the SSRC their video is sent with.
+	subscribers map[uint32]*subscriberRate
+	now         func() time.Time
+}
+
+func newRateController(cfg RateControlConfig) *rateController {
+	return &rateController{
+		cfg:         cfg.withDefaults(),
+		subscribers: map[uint32]*subscriberRate{},
+		now:         time.Now,
+	}
+}
+
+// handleRTCP updates the rate of the peer the video with the given SSRC is sent to from its feedback.
+func (rc *rateController) handleRTCP(ssrc uint32, pkts []rtcp.Packet) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	sub := rc.subscriberLocked(ssrc)
+	for _, pkt := range pkts {
+		switch pkt := pkt.(type) {
+		case *rtcp.ReceiverReport:
+			for _, report := range pkt.Reports {
+				if report.SSRC == ssrc {
+					rc.onLossLocked(sub, float64(report.FractionLost)/256)
+				}
+			}
+		case *rtcp.TransportLayerCC:
+			if pkt.MediaSSRC == ssrc {
+				if loss, ok := transportCCLoss(pkt); ok {
+					rc.onLossLocked(sub, loss)
+				}
+			}
+		case *rtcp.ReceiverEstimatedMaximumBitrate:
+			for _, s := range pkt.SSRCs {
+				if s == ssrc {
+					sub.remb = float64(pkt.Bitrate)
+					sub.bitrate = rc.clampLocked(sub, sub.bitrate)
+					sub.lastFeedback = rc.now()
+				}
+			}
+		}
+	}
+}
+
+func (rc *rateController) subscriberLocked(ssrc uint32) *subscriberRate {
+	sub, ok := rc.subscribers[ssrc]
+	if !ok {
+		sub = &subscriberRate{bitrate: float64(rc.cfg.StartBitrate), lastFeedback: rc.now()}
+		rc.subscribers[ssrc] = sub
+	}
+	return sub
+}
+
+func (rc *rateController) onLossLocked(sub *subscriberRate, loss float64) {
+	now := rc.now()
+	switch {
+	case loss > highLossFraction:
+		if now.Sub(sub.lastDecrease) >= decreaseInterval {
+			sub.bitrate *= 1 - loss/2
+			sub.lastDecrease = now
+		}
+	case loss < lowLossFraction:
+		elapsed := math.Min(now.Sub(sub.lastFeedback).Seconds(), 1)
+		sub.bitrate *= math.Pow(increasePerSecond, elapsed)
+	}
+	sub.bitrate = rc.clampLocked(sub, sub.bitrate)
+	sub.lastFeedback = now
+}
+
+func (rc *rateController) clampLocked(sub *subscriberRate, bitrate float64) float64 {
+	upper := float64(rc.cfg.MaxBitrate)
+	if sub.remb > 0 {
+		upper = math.Min(upper, sub.remb)
+	}
+	return math.Max(float64(rc.cfg.MinBitrate), math.Min(bitrate, upper))
+}
+
+// removeSubscriber forgets the peer the video with the given SSRC is no longer sent to.
+func (rc *rateController) removeSubscriber(ssrc uint32) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	delete(rc.subscribers, ssrc)
+}
+
+// target returns the bitrate to encode the video sent with the given SSRC at and the scale of the resolution to
+// encode it at.
+func (rc *rateController) target(ssrc uint32) (int, float64) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	sub := rc.subscriberLocked(ssrc)
+
+	fraction := sub.bitrate / float64(rc.cfg.MaxBitrate)
+	for sub.scaleIdx+1 < len(resolutionScales) && fraction < resolutionScales[sub.scaleIdx+1].belowBitrate {
+		sub.scaleIdx++
+	}
+	for sub.scaleIdx > 0 && fraction >= resolutionScales[sub.scaleIdx].belowBitrate*resolutionHysteresis {
+		sub.scaleIdx--
+	}
+	return int(sub.bitrate), resolutionScales[sub.scaleIdx].scale
+}
+
+// transportCCLoss returns the fraction of the packets a transport wide congestion control feedback reports on which
+// were lost.
+func transportCCLoss(pkt *rtcp.TransportLayerCC) (float64, bool) {
+	var received, lost int
+	count := func(symbol uint16, n int) {
+		if symbol == rtcp.TypeTCCPacketNotReceived {
+			lost += n
+		} else {
+			received += n
+		}
+	}
+	remaining := int(pkt.PacketStatusCount)
+	for _, chunk := range pkt.PacketChunks {
+		if remaining <= 0 {
+			break
+		}
+		switch chunk := chunk.(type) {
+		case *rtcp.RunLengthChunk:
+			n := min(int(chunk.RunLength), remaining)
+			count(chunk.PacketStatusSymbol, n)
+			remaining -= n
+		case *rtcp.StatusVectorChunk:
+			for _, symbol := range chunk.SymbolList {
+				if remaining <= 0 {
+					break
+				}
+				count(symbol, 1)
+				remaining--
+			}
+		}
+	}
+	if received+lost == 0 {
+		return 0, false
+	}
+	return float64(lost) / float64(received+lost), true
+}
@@ -0,0 +1,223 @@
+package gostream
+
+import (
+	"context"
+	"image"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pion/mediadevices/pkg/prop"
+	"github.com/pion/rtcp"
+	"github.com/pion/webrtc/v3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/gostream/codec"
+)
+
+const testSSRC = 1234
+
+func lossReport(fraction uint8) []rtcp.Packet {
+	return []rtcp.Packet{&rtcp.ReceiverReport{Reports: []rtcp.ReceptionReport{
+		{SSRC: 99, FractionLost: 255},
+		{SSRC: testSSRC, FractionLost: fraction},
+	}}}
+}
+
+func TestRateController(t *testing.T) {
+	now := time.Now()
+	rc := newRateController(RateControlConfig{MinBitrate: 100_000, MaxBitrate: 2_000_000, StartBitrate: 1_000_000})
+	rc.now = func() time.Time { return now }
+
+	bitrate, scale := rc.target(testSSRC)
+	test.That(t, bitrate, test.ShouldEqual, 1_000_000)
+	test.That(t, scale, test.ShouldEqual, 1)
+
+	// little loss grows the rate over time
+	rc.handleRTCP(testSSRC, lossReport(0))
+	now = now.Add(time.Second)
+	rc.handleRTCP(testSSRC, lossReport(2))
+	bitrate, _ = rc.target(testSSRC)
+	test.That(t, bitrate, test.ShouldEqual, 1_080_000)
+
+	// some loss holds it
+	now = now.Add(time.Second)
+	rc.handleRTCP(testSSRC, lossReport(12))
+	bitrate, _ = rc.target(testSSRC)
+	test.That(t, bitrate, test.ShouldEqual, 1_080_000)
+
+	// heavy loss backs off in proportion, at most once per interval
+	now = now.Add(time.Second)
+	rc.handleRTCP(testSSRC, lossReport(128))
+	rc.handleRTCP(testSSRC, lossReport(128))
+	bitrate, _ = rc.target(testSSRC)
+	test.That(t, bitrate, test.ShouldEqual, 810_000)
+
+	// the estimate of a peer caps its own rate, and leaves the rate of other peers alone
+	const otherSSRC = 99
+	rc.handleRTCP(otherSSRC, []rtcp.Packet{
+		&rtcp.ReceiverEstimatedMaximumBitrate{Bitrate: 300_000, SSRCs: []uint32{otherSSRC}},
+	})
+	bitrate, scale = rc.target(otherSSRC)
+	test.That(t, bitrate, test.ShouldEqual, 300_000)
+	test.That(t, scale, test.ShouldEqual, 0.5)
+	bitrate, scale = rc.target(testSSRC)
+	test.That(t, bitrate, test.ShouldEqual, 810_000)
+	test.That(t, scale, test.ShouldEqual, 1)
+
+	// the resolution only grows back once the rate is well above where it shrank
+	rc.handleRTCP(otherSSRC, []rtcp.Packet{
+		&rtcp.ReceiverEstimatedMaximumBitrate{Bitrate: 2_000_000, SSRCs: []uint32{otherSSRC}},
+	})
+	for i := 0; i < 5; i++ {
+		now = now.Add(time.Second)
+		rc.handleRTCP(otherSSRC, []rtcp.Packet{&rtcp.ReceiverReport{Reports: []rtcp.ReceptionReport{{SSRC: otherSSRC}}}})
+	}
+	bitrate, scale = rc.target(otherSSRC)
+	test.That(t, bitrate, test.ShouldBeBetween, 400_000, 500_000)
+	test.That(t, scale, test.ShouldEqual, 0.5)
+	for i := 0; i < 2; i++ {
+		now = now.Add(time.Second)
+		rc.handleRTCP(otherSSRC, []rtcp.Packet{&rtcp.ReceiverReport{Reports: []rtcp.ReceptionReport{{SSRC: otherSSRC}}}})
+	}
+	bitrate, scale = rc.target(otherSSRC)
+	test.That(t, bitrate, test.ShouldBeGreaterThan, 500_000)
+	test.That(t, scale, test.ShouldEqual, 0.75)
+
+	// peers which leave start over if they come back
+	rc.removeSubscriber(otherSSRC)
+	bitrate, scale = rc.target(otherSSRC)
+	test.That(t, bitrate, test.ShouldEqual, 1_000_000)
+	test.That(t, scale, test.ShouldEqual, 1)
+
+	// the rate never leaves its bounds
+	for i := 0; i < 100; i++ {
+		now = now.Add(time.Second)
+		rc.handleRTCP(testSSRC, lossReport(255))
+	}
+	bitrate, scale = rc.target(testSSRC)
+	test.That(t, bitrate, test.ShouldEqual, 100_000)
+	test.That(t, scale, test.ShouldEqual, 0.25)
+}
+
+func TestRateControlConfigDefaults(t *testing.T) {
+	cfg := RateControlConfig{}.withDefaults()
+	test.That(t, cfg, test.ShouldResemble, RateControlConfig{
+		MinBitrate: DefaultMinBitrate, MaxBitrate: DefaultMaxBitrate, StartBitrate: DefaultStartBitrate,
+	})
+	cfg = RateControlConfig{MinBitrate: 500_000, MaxBitrate: 500_000}.withDefaults()
+	test.That(t, cfg.StartBitrate, test.ShouldEqual, 500_000)
+}
+
+func TestTransportCCLoss(t *testing.T) {
+	loss, ok := transportCCLoss(&rtcp.TransportLayerCC{
+		PacketStatusCount: 10,
+		PacketChunks: []rtcp.PacketStatusChunk{
+			&rtcp.RunLengthChunk{PacketStatusSymbol: rtcp.TypeTCCPacketReceivedSmallDelta, RunLength: 6},
+			&rtcp.StatusVectorChunk{SymbolList: []uint16{
+				rtcp.TypeTCCPacketNotReceived, rtcp.TypeTCCPacketReceivedSmallDelta,
+				rtcp.TypeTCCPacketNotReceived, rtcp.TypeTCCPacketReceivedSmallDelta,
+				// padding past the status count is not counted
+				rtcp.TypeTCCPacketNotReceived, rtcp.TypeTCCPacketNotReceived,
+			}},
+		},
+	})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, loss, test.ShouldAlmostEqual, 0.2)
+
+	_, ok = transportCCLoss(&rtcp.TransportLayerCC{})
+	test.That(t, ok, test.ShouldBeFalse)
+}
+
+func TestScaleFrame(t *testing.T) {
+	pool := NewFramePool()
+	img := image.NewRGBA(image.Rect(0, 0, 641, 480))
+	scaled, release := scaleFrame(pool, img, 1)
+	test.That(t, scaled, test.ShouldEqual, img)
+	release()
+	scaled, release = scaleFrame(pool, img, 0.5)
+	test.That(t, scaled.Bounds(), test.ShouldResemble, image.Rect(0, 0, 320, 240))
+	release()
+	scaled, release = scaleFrame(pool, img, 0.001)
+	test.That(t, scaled.Bounds(), test.ShouldResemble, image.Rect(0, 0, 2, 2))
+	release()
+}
+
+type fakeVideoEncoder struct {
+	width, height int
+	bitrate       int
+}
+
+func (e *fakeVideoEncoder) Encode(ctx context.Context, img image.Image) ([]byte, error) {
+	return []byte{0, 0, 0, 1, 0x65, 0xaa}, nil
+}
+
+func (e *fakeVideoEncoder) SetBitrate(bitsPerSecond int) error {
+	e.bitrate = bitsPerSecond
+	return nil
+}
+
+func (e *fakeVideoEncoder) Close() error { return nil }
+
+type fakeVideoEncoderFactory struct {
+	mu       sync.Mutex
+	encoders []*fakeVideoEncoder
+}
+
+func (f *fakeVideoEncoderFactory) New(width, height, keyFrameInterval int, logger golog.Logger) (codec.VideoEncoder, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	encoder := &fakeVideoEncoder{width: width, height: height}
+	f.encoders = append(f.encoders, encoder)
+	return encoder, nil
+}
+
+func (f *fakeVideoEncoderFactory) MIMEType() string { return webrtc.MimeTypeH264 }
+
+func TestStreamEncodesPerSubscriber(t *testing.T) {
+	factory := &fakeVideoEncoderFactory{}
+	s, err := NewStream(StreamConfig{
+		VideoEncoderFactory: factory,
+		RateControl:         RateControlConfig{MinBitrate: 100_000, MaxBitrate: 2_000_000, StartBitrate: 1_000_000},
+		TargetFrameRate:     100,
+		Logger:              golog.NewTestLogger(t),
+	})
+	test.That(t, err, test.ShouldBeNil)
+	bs := s.(*basicStream)
+
+	h264 := webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeH264, ClockRate: 90000},
+		PayloadType:        102,
+	}
+	for _, ssrc := range []webrtc.SSRC{1, 2} {
+		_, err := bs.videoTrackLocal.Bind(&fakeTrackLocalContext{
+			ssrc: ssrc, codecs: []webrtc.RTPCodecParameters{h264}, writer: &fakeTrackLocalWriter{},
+		})
+		test.That(t, err, test.ShouldBeNil)
+	}
+	// the second peer is on a slow network
+	bs.rateController.handleRTCP(2, []rtcp.Packet{
+		&rtcp.ReceiverEstimatedMaximumBitrate{Bitrate: 300_000, SSRCs: []uint32{2}},
+	})
+
+	s.Start()
+	frames, err := s.InputVideoFrames(prop.Video{})
+	test.That(t, err, test.ShouldBeNil)
+	// a frame is only taken once the one before it is encoded for every peer
+	for i := 0; i < 3; i++ {
+		frames <- MediaReleasePair[image.Image]{Media: image.NewRGBA(image.Rect(0, 0, 640, 480))}
+	}
+	s.Stop()
+
+	// each peer has its own encoder, at the rate and resolution of its own network
+	factory.mu.Lock()
+	defer factory.mu.Unlock()
+	test.That(t, factory.encoders, test.ShouldHaveLength, 2)
+	byWidth := map[int]*fakeVideoEncoder{}
+	for _, encoder := range factory.encoders {
+		byWidth[encoder.width] = encoder
+	}
+	test.That(t, byWidth[640], test.ShouldResemble, &fakeVideoEncoder{width: 640, height: 480, bitrate: 1_000_000})
+	test.That(t, byWidth[320], test.ShouldResemble, &fakeVideoEncoder{width: 320, height: 240, bitrate: 300_000})
+}
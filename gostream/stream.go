@@ -5,6 +5,8 @@ import (
 	"context"
 	"errors"
 	"image"
+	"math"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,7 +20,6 @@ import (
 
 	"go.viam.com/rdk/gostream/codec"
 	"go.viam.com/rdk/rimage"
-	utils2 "go.viam.com/rdk/utils"
 )
 
 // A Stream is sink that accepts any image frames for the purpose
@@ -50,6 +51,14 @@ type internalStream interface {
 	AudioTrackLocal() (webrtc.TrackLocal, bool)
 }
 
+// A FeedbackStream is a Stream whose video adapts its bitrate and resolution to the RTCP feedback of the peers it is
+// sent to.
+type FeedbackStream interface {
+	Stream
+	// ReadRTCP reads the feedback of the peer the video track is sent to by the sender, until the sender stops.
+	ReadRTCP(sender *webrtc.RTPSender)
+}
+
 // MediaReleasePair associates a media with a corresponding
 // function to release its resources once the receiver of a
 // pair is finished with the media.
@@ -84,6 +93,9 @@ func NewStream(config StreamConfig) (Stream, error) {
 			"video",
 			name,
 		)
+		if config.FallbackVideoEncoderFactory != nil {
+			trackLocal.rtpTrack.fallbackCodec = &webrtc.RTPCodecCapability{MimeType: config.FallbackVideoEncoderFactory.MIMEType()}
+		}
 	}
 
 	var audioTrackLocal *trackLocalStaticSample
@@ -103,7 +115,8 @@ func NewStream(config StreamConfig) (Stream, error) {
 
 		videoTrackLocal: trackLocal,
 		inputImageChan:  make(chan MediaReleasePair[image.Image]),
-		outputVideoChan: make(chan []byte),
+		outputVideoChan: make(chan encodedVideo),
+		videoEncodings:  map[webrtc.SSRC]*videoEncoding{},
 
 		audioTrackLocal: audioTrackLocal,
 		inputAudioChan:  make(chan MediaReleasePair[wave.Audio]),
@@ -113,6 +126,10 @@ func NewStream(config StreamConfig) (Stream, error) {
 		shutdownCtx:       ctx,
 		shutdownCtxCancel: cancelFunc,
 	}
+	if trackLocal != nil {
+		bs.rateController = newRateController(config.RateControl)
+		bs.scaledFrames = NewFramePool()
+	}
 
 	return bs, nil
 }
@@ -126,8 +143,14 @@ type basicStream struct {
 
 	videoTrackLocal *trackLocalStaticSample
 	inputImageChan  chan MediaReleasePair[image.Image]
-	outputVideoChan chan []byte
-	videoEncoder    codec.VideoEncoder
+	outputVideoChan chan encodedVideo
+	// videoEncodings encode the video for each peer, by the SSRC it is bound with.
+	videoEncodings map[webrtc.SSRC]*videoEncoding
+
+	// rateController picks the bitrate and resolution of the video sent to each peer from its feedback.
+	rateController *rateController
+	// scaledFrames lends the frames that scaled down video is encoded from.
+	scaledFrames *FramePool
 
 	audioTrackLocal *trackLocalStaticSample
 	inputAudioChan  chan MediaReleasePair[wave.Audio]
@@ -183,14 +206,15 @@ func (bs *basicStream) Stop() {
 	if bs.audioEncoder != nil {
 		bs.audioEncoder.Close()
 	}
-	if bs.videoEncoder != nil {
-		if err := bs.videoEncoder.Close(); err != nil {
+	for ssrc, encoding := range bs.videoEncodings {
+		if err := encoding.encoder.Close(); err != nil {
 			bs.logger.Error(err)
 		}
+		delete(bs.videoEncodings, ssrc)
 	}
 
 	// reset
-	bs.outputVideoChan = make(chan []byte)
+	bs.outputVideoChan = make(chan encodedVideo)
 	bs.outputAudioChan = make(chan []byte)
 	ctx, cancelFunc := context.WithCancel(context.Background())
 	bs.shutdownCtx = ctx
@@ -233,10 +257,53 @@ func (bs *basicStream) AudioTrackLocal() (webrtc.TrackLocal, bool) {
 	return bs.audioTrackLocal, bs.audioTrackLocal != nil
 }
 
+func (bs *basicStream) ReadRTCP(sender *webrtc.RTPSender) {
+	if bs.rateController == nil {
+		return
+	}
+	encodings := sender.GetParameters().Encodings
+	if len(encodings) == 0 {
+		return
+	}
+	ssrc := uint32(encodings[0].SSRC)
+	defer bs.rateController.removeSubscriber(ssrc)
+	for {
+		pkts, _, err := sender.ReadRTCP()
+		if err != nil {
+			return
+		}
+		bs.rateController.handleRTCP(ssrc, pkts)
+	}
+}
+
+// A videoEncoding encodes the video sent to one peer.
+type videoEncoding struct {
+	mimeType string
+	encoder  codec.VideoEncoder
+	dx, dy   int
+	// bitrate is the bitrate the encoder was last set to, zero if it has not been set, and fixedBitrate is whether
+	// it cannot be set.
+	bitrate      int
+	fixedBitrate bool
+}
+
+// encodedVideo is a frame of video encoded for the peer bound with the SSRC.
+type encodedVideo struct {
+	ssrc webrtc.SSRC
+	data []byte
+}
+
+// videoEncoderFactory returns the factory of the codec of the MIME type.
+func (bs *basicStream) videoEncoderFactory(mimeType string) codec.VideoEncoderFactory {
+	if fallback := bs.config.FallbackVideoEncoderFactory; fallback != nil && strings.EqualFold(fallback.MIMEType(), mimeType) {
+		return fallback
+	}
+	return bs.config.VideoEncoderFactory
+}
+
 func (bs *basicStream) processInputFrames() {
 	frameLimiterDur := time.Second / time.Duration(bs.config.TargetFrameRate)
 	defer close(bs.outputVideoChan)
-	var dx, dy int
 	ticker := time.NewTicker(frameLimiterDur)
 	defer ticker.Stop()
 	for {
@@ -265,38 +332,46 @@ func (bs *basicStream) processInputFrames() {
 				defer framePair.Release()
 			}
 
-			var encodedFrame []byte
-
-			if frame, ok := framePair.Media.(*rimage.LazyEncodedImage); ok && frame.MIMEType() == utils2.MimeTypeH264 {
-				encodedFrame = frame.RawData() // nothing to do; already encoded
-			} else {
-				bounds := framePair.Media.Bounds()
-				newDx, newDy := bounds.Dx(), bounds.Dy()
-				if bs.videoEncoder == nil || dx != newDx || dy != newDy {
-					dx, dy = newDx, newDy
-					bs.logger.Infow("detected new image bounds", "width", dx, "height", dy)
-
-					if err := bs.initVideoCodec(dx, dy); err != nil {
+			// every peer gets its own encoding, at the codec it is bound with and the rate its network can take
+			peers := bs.videoTrackLocal.rtpTrack.boundPeers()
+			bs.closeUnusedVideoEncodings(peers)
+			scaled := map[float64]image.Image{}
+			for ssrc, mimeType := range peers {
+				var encodedFrame []byte
+				frame, isLazy := framePair.Media.(*rimage.LazyEncodedImage)
+				if isLazy && strings.EqualFold(frame.MIMEType(), mimeType) {
+					encodedFrame = frame.RawData() // nothing to do; already encoded
+				} else {
+					bitrate, scale := bs.rateController.target(uint32(ssrc))
+					img, ok := scaled[scale]
+					if !ok {
+						var releaseScaled func()
+						img, releaseScaled = scaleFrame(bs.scaledFrames, framePair.Media, scale)
+						defer releaseScaled()
+						scaled[scale] = img
+					}
+					encoding, err := bs.videoEncoding(ssrc, mimeType, img.Bounds())
+					if err != nil {
 						bs.logger.Error(err)
 						initErr = true
 						return
 					}
-				}
+					bs.adaptVideoBitrate(encoding, bitrate)
 
-				// thread-safe because the size is static
-				var err error
-				encodedFrame, err = bs.videoEncoder.Encode(bs.shutdownCtx, framePair.Media)
-				if err != nil {
-					bs.logger.Error(err)
-					return
+					// thread-safe because the size is static
+					encodedFrame, err = encoding.encoder.Encode(bs.shutdownCtx, img)
+					if err != nil {
+						bs.logger.Error(err)
+						continue
+					}
 				}
-			}
 
-			if encodedFrame != nil {
-				select {
-				case <-bs.shutdownCtx.Done():
-					return
-				case bs.outputVideoChan <- encodedFrame:
+				if encodedFrame != nil {
+					select {
+					case <-bs.shutdownCtx.Done():
+						return
+					case bs.outputVideoChan <- encodedVideo{ssrc: ssrc, data: encodedFrame}:
+					}
 				}
 			}
 		}()
@@ -372,7 +447,7 @@ func (bs *basicStream) processOutputFrames() {
 		default:
 		}
 		now := time.Now()
-		if err := bs.videoTrackLocal.WriteData(outputFrame); err != nil {
+		if err := bs.videoTrackLocal.WriteBindingData(outputFrame.ssrc, outputFrame.data); err != nil {
 			bs.logger.Errorw("error writing frame", "error", err)
 		}
 		framesSent++
@@ -401,10 +476,83 @@ func (bs *basicStream) processOutputAudioChunks() {
 	}
 }
 
-func (bs *basicStream) initVideoCodec(width, height int) error {
-	var err error
-	bs.videoEncoder, err = bs.config.VideoEncoderFactory.New(width, height, bs.config.TargetFrameRate, bs.logger)
-	return err
+// videoEncoding returns the encoding of the video sent to the peer bound with the SSRC, initializing it for frames of
+// the given bounds if it is new or the bounds changed.
+func (bs *basicStream) videoEncoding(ssrc webrtc.SSRC, mimeType string, bounds image.Rectangle) (*videoEncoding, error) {
+	encoding, ok := bs.videoEncodings[ssrc]
+	dx, dy := bounds.Dx(), bounds.Dy()
+	if ok && encoding.dx == dx && encoding.dy == dy {
+		return encoding, nil
+	}
+	bs.logger.Infow("detected new image bounds", "width", dx, "height", dy, "codec", mimeType, "ssrc", ssrc)
+	if ok {
+		if err := encoding.encoder.Close(); err != nil {
+			bs.logger.Error(err)
+		}
+		delete(bs.videoEncodings, ssrc)
+	}
+	encoder, err := bs.videoEncoderFactory(mimeType).New(dx, dy, bs.config.TargetFrameRate, bs.logger)
+	if err != nil {
+		return nil, err
+	}
+	encoding = &videoEncoding{mimeType: mimeType, encoder: encoder, dx: dx, dy: dy}
+	bs.videoEncodings[ssrc] = encoding
+	return encoding, nil
+}
+
+// closeUnusedVideoEncodings closes the encodings of the peers which are no longer bound.
+func (bs *basicStream) closeUnusedVideoEncodings(peers map[webrtc.SSRC]string) {
+	for ssrc, encoding := range bs.videoEncodings {
+		if _, ok := peers[ssrc]; ok {
+			continue
+		}
+		if err := encoding.encoder.Close(); err != nil {
+			bs.logger.Error(err)
+		}
+		delete(bs.videoEncodings, ssrc)
+		bs.rateController.removeSubscriber(uint32(ssrc))
+	}
+}
+
+// minBitrateChange is the smallest relative change of the target bitrate which is passed on to the video encoder,
+// so that the encoder is not retuned on every report of a peer.
+const minBitrateChange = 0.1
+
+// adaptVideoBitrate sets the bitrate of the video encoder, if it can be set, when the target has moved far enough.
+// Encoders whose codec cannot change it keep encoding at their own bitrate, and only adapt their resolution.
+func (bs *basicStream) adaptVideoBitrate(encoding *videoEncoding, bitrate int) {
+	controller, ok := encoding.encoder.(codec.BitrateController)
+	if !ok || encoding.fixedBitrate {
+		return
+	}
+	if encoding.bitrate != 0 && math.Abs(float64(bitrate-encoding.bitrate)) < minBitrateChange*float64(encoding.bitrate) {
+		return
+	}
+	if err := controller.SetBitrate(bitrate); err != nil {
+		if errors.Is(err, codec.ErrBitrateUnsupported) {
+			encoding.fixedBitrate = true
+			bs.logger.Debugw("video encoder cannot change its bitrate", "codec", encoding.mimeType)
+			return
+		}
+		bs.logger.Errorw("error setting video bitrate", "bitrate", bitrate, "error", err)
+		return
+	}
+	if Debug {
+		bs.logger.Debugw("set video bitrate", "bitrate", bitrate, "previous", encoding.bitrate)
+	}
+	encoding.bitrate = bitrate
+}
+
+// scaleFrame returns a frame scaled down to encode at a lower bitrate, keeping its sides even as encoders need, and
+// the function to release it once encoded.
+func scaleFrame(pool *FramePool, img image.Image, scale float64) (image.Image, func()) {
+	if scale >= 1 {
+		return img, func() {}
+	}
+	bounds := img.Bounds()
+	width := max(2, int(float64(bounds.Dx())*scale)&^1)
+	height := max(2, int(float64(bounds.Dy())*scale)&^1)
+	return ResizeFrame(pool, img, width, height)
 }
 
 func (bs *basicStream) initAudioCodec(sampleRate, channelCount int) error {
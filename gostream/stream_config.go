@@ -12,6 +12,14 @@ type StreamConfig struct {
 	VideoEncoderFactory codec.VideoEncoderFactory
 	AudioEncoderFactory codec.AudioEncoderFactory
 
+	// FallbackVideoEncoderFactory, if set, encodes the video for the peers which cannot decode the codec of
+	// VideoEncoderFactory, such as browsers without H.265 support, while the other peers still get the first choice.
+	FallbackVideoEncoderFactory codec.VideoEncoderFactory
+
+	// RateControl bounds the bitrate and resolution the video adapts to as the networks of the peers it is sent to
+	// congest. Its zero value uses the defaults.
+	RateControl RateControlConfig
+
 	// TargetFrameRate will hint to the stream to try to maintain this frame rate.
 	TargetFrameRate int
 
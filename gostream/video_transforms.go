@@ -4,7 +4,6 @@ import (
 	"context"
 	"image"
 
-	"github.com/disintegration/imaging"
 	"github.com/pion/mediadevices/pkg/prop"
 	"go.uber.org/multierr"
 )
@@ -13,6 +12,7 @@ type resizeVideoSource struct {
 	src           VideoSource
 	stream        VideoStream
 	width, height int
+	frames        *FramePool
 }
 
 // NewResizeVideoSource returns a source that resizes images to the set dimensions.
@@ -22,6 +22,7 @@ func NewResizeVideoSource(src VideoSource, width, height int) VideoSource {
 		stream: NewEmbeddedVideoStream(src),
 		width:  width,
 		height: height,
+		frames: NewFramePool(),
 	}
 	return NewVideoSource(rvs, prop.Video{
 		Width:  rvs.width,
@@ -39,7 +40,8 @@ func (rvs resizeVideoSource) Read(ctx context.Context) (image.Image, func(), err
 		defer release()
 	}
 
-	return imaging.Resize(img, rvs.width, rvs.height, imaging.NearestNeighbor), func() {}, nil
+	resized, releaseResized := ResizeFrame(rvs.frames, img, rvs.width, rvs.height)
+	return resized, releaseResized, nil
 }
 
 // Close closes the underlying source.
@@ -25,6 +25,7 @@ type trackBinding struct {
 	ssrc        webrtc.SSRC
 	payloadType webrtc.PayloadType
 	writeStream webrtc.TrackLocalWriter
+	codec       webrtc.RTPCodecCapability
 }
 
 // trackLocalStaticRTP  is a TrackLocal that has a pre-set codec and accepts RTP Packets.
@@ -34,6 +35,9 @@ type trackLocalStaticRTP struct {
 	bindings          []trackBinding
 	codec             webrtc.RTPCodecCapability
 	id, rid, streamID string
+
+	// fallbackCodec, if set, is bound instead of codec for the peers which do not support codec.
+	fallbackCodec *webrtc.RTPCodecCapability
 }
 
 // newtrackLocalStaticRTP returns a trackLocalStaticRTP.
@@ -53,20 +57,39 @@ func (s *trackLocalStaticRTP) Bind(t webrtc.TrackLocalContext) (webrtc.RTPCodecP
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	parameters := webrtc.RTPCodecParameters{RTPCodecCapability: s.codec}
-	if codec, err := codecParametersFuzzySearch(parameters, t.CodecParameters()); err == nil {
-		s.bindings = append(s.bindings, trackBinding{
-			ssrc:        t.SSRC(),
-			payloadType: codec.PayloadType,
-			writeStream: t.WriteStream(),
-			id:          t.ID(),
-		})
-		return codec, nil
+	candidates := []webrtc.RTPCodecCapability{s.codec}
+	if s.fallbackCodec != nil {
+		candidates = append(candidates, *s.fallbackCodec)
+	}
+	for _, candidate := range candidates {
+		parameters := webrtc.RTPCodecParameters{RTPCodecCapability: candidate}
+		if codec, err := codecParametersFuzzySearch(parameters, t.CodecParameters()); err == nil {
+			s.bindings = append(s.bindings, trackBinding{
+				ssrc:        t.SSRC(),
+				payloadType: codec.PayloadType,
+				writeStream: t.WriteStream(),
+				id:          t.ID(),
+				codec:       candidate,
+			})
+			return codec, nil
+		}
 	}
 
 	return webrtc.RTPCodecParameters{}, webrtc.ErrUnsupportedCodec
 }
 
+// boundPeers returns the SSRC of each peer bound, by the MIME type of the codec it is bound with.
+func (s *trackLocalStaticRTP) boundPeers() map[webrtc.SSRC]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	peers := make(map[webrtc.SSRC]string, len(s.bindings))
+	for _, b := range s.bindings {
+		peers[b.ssrc] = b.codec.MimeType
+	}
+	return peers
+}
+
 // Unbind implements the teardown logic when the track is no longer needed. This happens
 // because a track has been stopped.
 func (s *trackLocalStaticRTP) Unbind(t webrtc.TrackLocalContext) error {
@@ -109,6 +132,8 @@ func (s *trackLocalStaticRTP) Kind() webrtc.RTPCodecType {
 
 // Codec gets the Codec of the track.
 func (s *trackLocalStaticRTP) Codec() webrtc.RTPCodecCapability {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.codec
 }
 
@@ -117,6 +142,15 @@ func (s *trackLocalStaticRTP) Codec() webrtc.RTPCodecCapability {
 // all PeerConnections. The error message will contain the ID of the failed
 // PeerConnections so you can remove them.
 func (s *trackLocalStaticRTP) WriteRTP(p *rtp.Packet) error {
+	return s.writeRTP(p, func(trackBinding) bool { return true })
+}
+
+// writeBindingRTP writes a RTP Packet to the PeerConnection bound with the given SSRC.
+func (s *trackLocalStaticRTP) writeBindingRTP(p *rtp.Packet, ssrc webrtc.SSRC) error {
+	return s.writeRTP(p, func(b trackBinding) bool { return b.ssrc == ssrc })
+}
+
+func (s *trackLocalStaticRTP) writeRTP(p *rtp.Packet, include func(trackBinding) bool) error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -124,6 +158,9 @@ func (s *trackLocalStaticRTP) WriteRTP(p *rtp.Packet) error {
 	outboundPacket := *p
 
 	for _, b := range s.bindings {
+		if !include(b) {
+			continue
+		}
 		outboundPacket.Header.SSRC = uint32(b.ssrc)
 		outboundPacket.Header.PayloadType = uint8(b.payloadType)
 		if _, err := b.writeStream.WriteRTP(&outboundPacket.Header, outboundPacket.Payload); err != nil {
@@ -150,18 +187,25 @@ func (s *trackLocalStaticRTP) Write(b []byte) (n int, err error) {
 // trackLocalStaticSample is a TrackLocal that has a pre-set codec and accepts Samples.
 // If you wish to send a RTP Packet use trackLocalStaticRTP.
 type trackLocalStaticSample struct {
-	packetizer   rtp.Packetizer
+	// packetizers packetize the samples sent to each peer, by the SSRC it is bound with.
+	packetizers  map[webrtc.SSRC]*samplePacketizer
 	rtpTrack     *trackLocalStaticRTP
-	sampler      samplerFunc
 	isAudio      bool
-	clockRate    uint32
 	audioLatency time.Duration
 }
 
+// samplePacketizer packetizes the samples sent to one peer.
+type samplePacketizer struct {
+	packetizer rtp.Packetizer
+	sampler    samplerFunc
+	clockRate  uint32
+}
+
 // newVideoTrackLocalStaticSample returns a trackLocalStaticSample for video.
 func newVideoTrackLocalStaticSample(c webrtc.RTPCodecCapability, id, streamID string) *trackLocalStaticSample {
 	return &trackLocalStaticSample{
-		rtpTrack: newtrackLocalStaticRTP(c, id, streamID),
+		packetizers: map[webrtc.SSRC]*samplePacketizer{},
+		rtpTrack:    newtrackLocalStaticRTP(c, id, streamID),
 	}
 }
 
@@ -171,8 +215,9 @@ func newAudioTrackLocalStaticSample(
 	id, streamID string,
 ) *trackLocalStaticSample {
 	return &trackLocalStaticSample{
-		rtpTrack: newtrackLocalStaticRTP(c, id, streamID),
-		isAudio:  true,
+		packetizers: map[webrtc.SSRC]*samplePacketizer{},
+		rtpTrack:    newtrackLocalStaticRTP(c, id, streamID),
+		isAudio:     true,
 	}
 }
 
@@ -209,28 +254,23 @@ func (s *trackLocalStaticSample) Bind(t webrtc.TrackLocalContext) (webrtc.RTPCod
 	s.rtpTrack.mu.Lock()
 	defer s.rtpTrack.mu.Unlock()
 
-	// We only need one packetizer. But isn't that confusing with other clock rates
-	// from other codecs?
-	if s.packetizer != nil {
-		return codec, nil
-	}
-
+	// every peer gets its own packetizer, as each may be sent a different encoding of the media
 	payloader, err := payloaderForCodec(codec.RTPCodecCapability)
 	if err != nil {
 		return codec, err
 	}
 
-	// TODO(erd): I think we need to do this for each bind
-	s.packetizer = rtp.NewPacketizer(
-		rtpOutboundMTU,
-		uint8(codec.PayloadType),
-		uint32(t.SSRC()),
-		payloader,
-		rtp.NewRandomSequencer(),
-		codec.ClockRate,
-	)
-
-	s.clockRate = codec.RTPCodecCapability.ClockRate
+	s.packetizers[t.SSRC()] = &samplePacketizer{
+		packetizer: rtp.NewPacketizer(
+			rtpOutboundMTU,
+			uint8(codec.PayloadType),
+			uint32(t.SSRC()),
+			payloader,
+			rtp.NewRandomSequencer(),
+			codec.ClockRate,
+		),
+		clockRate: codec.RTPCodecCapability.ClockRate,
+	}
 	return codec, nil
 }
 
@@ -243,7 +283,14 @@ func (s *trackLocalStaticSample) setAudioLatency(latency time.Duration) {
 // Unbind implements the teardown logic when the track is no longer needed. This happens
 // because a track has been stopped.
 func (s *trackLocalStaticSample) Unbind(t webrtc.TrackLocalContext) error {
-	return s.rtpTrack.Unbind(t)
+	if err := s.rtpTrack.Unbind(t); err != nil {
+		return err
+	}
+
+	s.rtpTrack.mu.Lock()
+	defer s.rtpTrack.mu.Unlock()
+	delete(s.packetizers, t.SSRC())
+	return nil
 }
 
 // WriteData writes already encoded data to the trackLocalStaticSample
@@ -251,35 +298,50 @@ func (s *trackLocalStaticSample) Unbind(t webrtc.TrackLocalContext) error {
 // all PeerConnections. The error message will contain the ID of the failed
 // PeerConnections so you can remove them.
 func (s *trackLocalStaticSample) WriteData(frame []byte) error {
+	s.rtpTrack.mu.RLock()
+	ssrcs := make([]webrtc.SSRC, 0, len(s.packetizers))
+	for ssrc := range s.packetizers {
+		ssrcs = append(ssrcs, ssrc)
+	}
+	s.rtpTrack.mu.RUnlock()
+
+	writeErrs := []error{}
+	for _, ssrc := range ssrcs {
+		if err := s.WriteBindingData(ssrc, frame); err != nil {
+			writeErrs = append(writeErrs, err)
+		}
+	}
+	return multierr.Combine(writeErrs...)
+}
+
+// WriteBindingData writes already encoded data to the PeerConnection bound with the given SSRC, which must have
+// been encoded for the codec that peer is bound with.
+func (s *trackLocalStaticSample) WriteBindingData(ssrc webrtc.SSRC, frame []byte) error {
 	s.rtpTrack.mu.Lock()
-	p := s.packetizer
-	if p == nil {
+	p, ok := s.packetizers[ssrc]
+	if !ok {
 		s.rtpTrack.mu.Unlock()
 		return nil
 	}
 	if s.isAudio && s.audioLatency == 0 {
+		s.rtpTrack.mu.Unlock()
 		return nil
 	}
-	sampler := s.sampler
-	if sampler == nil {
+	if p.sampler == nil {
 		if s.isAudio {
-			s.sampler = newAudioSampler(s.clockRate, s.audioLatency)
+			p.sampler = newAudioSampler(p.clockRate, s.audioLatency)
 		} else {
-			s.sampler = newVideoSampler(s.clockRate)
+			p.sampler = newVideoSampler(p.clockRate)
 		}
 	}
-
 	s.rtpTrack.mu.Unlock()
 
-	if s.sampler == nil {
-		return nil
-	}
-	samples := s.sampler()
-	packets := p.Packetize(frame, samples)
+	samples := p.sampler()
+	packets := p.packetizer.Packetize(frame, samples)
 
 	writeErrs := []error{}
-	for _, p := range packets {
-		if err := s.rtpTrack.WriteRTP(p); err != nil {
+	for _, packet := range packets {
+		if err := s.rtpTrack.writeBindingRTP(packet, ssrc); err != nil {
 			writeErrs = append(writeErrs, err)
 		}
 	}
@@ -312,6 +374,8 @@ func payloaderForCodec(codec webrtc.RTPCodecCapability) (rtp.Payloader, error) {
 	switch strings.ToLower(codec.MimeType) {
 	case strings.ToLower(webrtc.MimeTypeH264):
 		return &codecs.H264Payloader{}, nil
+	case strings.ToLower(webrtc.MimeTypeH265):
+		return &h265Payloader{}, nil
 	case strings.ToLower(webrtc.MimeTypeOpus):
 		return &codecs.OpusPayloader{}, nil
 	case strings.ToLower(webrtc.MimeTypeVP8):
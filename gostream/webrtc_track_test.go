@@ -0,0 +1,86 @@
+package gostream
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+	"go.viam.com/test"
+)
+
+type fakeTrackLocalContext struct {
+	webrtc.TrackLocalContext
+	id     string
+	ssrc   webrtc.SSRC
+	codecs []webrtc.RTPCodecParameters
+	writer *fakeTrackLocalWriter
+}
+
+func (t *fakeTrackLocalContext) ID() string                                   { return t.id }
+func (t *fakeTrackLocalContext) SSRC() webrtc.SSRC                            { return t.ssrc }
+func (t *fakeTrackLocalContext) CodecParameters() []webrtc.RTPCodecParameters { return t.codecs }
+func (t *fakeTrackLocalContext) WriteStream() webrtc.TrackLocalWriter         { return t.writer }
+
+type fakeTrackLocalWriter struct {
+	webrtc.TrackLocalWriter
+	headers []rtp.Header
+}
+
+func (w *fakeTrackLocalWriter) WriteRTP(header *rtp.Header, payload []byte) (int, error) {
+	w.headers = append(w.headers, *header)
+	return len(payload), nil
+}
+
+func TestTrackLocalStaticSampleFallback(t *testing.T) {
+	h264 := webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeH264, ClockRate: 90000},
+		PayloadType:        102,
+	}
+	h265 := webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeH265, ClockRate: 90000},
+		PayloadType:        104,
+	}
+	vp8 := webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000},
+		PayloadType:        96,
+	}
+	track := newVideoTrackLocalStaticSample(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeH265}, "video", "stream")
+	track.rtpTrack.fallbackCodec = &webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeH264}
+
+	// the first peer decodes H.265 and a later one only H.264, so each gets the codec it can decode
+	hevcPeer := &fakeTrackLocalContext{id: "a", ssrc: 1, codecs: []webrtc.RTPCodecParameters{h264, h265}, writer: &fakeTrackLocalWriter{}}
+	codec, err := track.Bind(hevcPeer)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, codec.MimeType, test.ShouldEqual, webrtc.MimeTypeH265)
+	avcPeer := &fakeTrackLocalContext{id: "b", ssrc: 2, codecs: []webrtc.RTPCodecParameters{h264}, writer: &fakeTrackLocalWriter{}}
+	codec, err = track.Bind(avcPeer)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, codec.MimeType, test.ShouldEqual, webrtc.MimeTypeH264)
+	_, err = track.Bind(&fakeTrackLocalContext{id: "c", ssrc: 3, codecs: []webrtc.RTPCodecParameters{vp8}})
+	test.That(t, err, test.ShouldBeError, webrtc.ErrUnsupportedCodec)
+	test.That(t, track.rtpTrack.boundPeers(), test.ShouldResemble, map[webrtc.SSRC]string{
+		1: webrtc.MimeTypeH265,
+		2: webrtc.MimeTypeH264,
+	})
+
+	// data encoded for a peer only goes to that peer
+	test.That(t, track.WriteBindingData(2, []byte{0, 0, 0, 1, 0x65, 0xaa}), test.ShouldBeNil)
+	test.That(t, hevcPeer.writer.headers, test.ShouldBeEmpty)
+	test.That(t, avcPeer.writer.headers, test.ShouldHaveLength, 1)
+	test.That(t, avcPeer.writer.headers[0].SSRC, test.ShouldEqual, 2)
+	test.That(t, avcPeer.writer.headers[0].PayloadType, test.ShouldEqual, 102)
+	test.That(t, track.WriteBindingData(1, []byte{0, 0, 0, 1, 0x26, 0x01, 0xaa}), test.ShouldBeNil)
+	test.That(t, hevcPeer.writer.headers, test.ShouldHaveLength, 1)
+	test.That(t, hevcPeer.writer.headers[0].SSRC, test.ShouldEqual, 1)
+	test.That(t, hevcPeer.writer.headers[0].PayloadType, test.ShouldEqual, 104)
+	test.That(t, avcPeer.writer.headers, test.ShouldHaveLength, 1)
+
+	// data for every peer is packetized for each of them
+	test.That(t, track.WriteData([]byte{0, 0, 0, 1, 0x65, 0xbb}), test.ShouldBeNil)
+	test.That(t, hevcPeer.writer.headers, test.ShouldHaveLength, 2)
+	test.That(t, avcPeer.writer.headers, test.ShouldHaveLength, 2)
+
+	// once the H.265 peer leaves, only H.264 is encoded
+	test.That(t, track.Unbind(hevcPeer), test.ShouldBeNil)
+	test.That(t, track.rtpTrack.boundPeers(), test.ShouldResemble, map[webrtc.SSRC]string{2: webrtc.MimeTypeH264})
+}
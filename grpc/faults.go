@@ -0,0 +1,223 @@
+package grpc
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// A Fault is a degraded condition injected into the gRPC calls it matches, for testing how clients cope with slow,
+// failing and unreliable connections.
+type Fault struct {
+	// Method matches calls to a method, either by its full name, such as
+	// "/viam.component.arm.v1.ArmService/MoveToPosition", or by its short name, such as "MoveToPosition". Empty
+	// matches every method.
+	Method string `json:"method,omitempty"`
+	// Resource matches calls whose request names the resource. Empty matches every call.
+	Resource string `json:"resource,omitempty"`
+
+	// LatencyMS delays each matching call, and each message sent on a matching stream, by this many milliseconds
+	// plus a random amount up to JitterMS.
+	LatencyMS int `json:"latency_ms,omitempty"`
+	JitterMS  int `json:"jitter_ms,omitempty"`
+
+	// ErrorRate is the chance, from 0 to 1, that a matching call fails with ErrorCode instead of running.
+	// ErrorCode defaults to Unavailable.
+	ErrorRate float64    `json:"error_rate,omitempty"`
+	ErrorCode codes.Code `json:"error_code,omitempty"`
+
+	// DropStreamAfterMessages ends a matching stream as unavailable once it has sent this many messages.
+	DropStreamAfterMessages int `json:"drop_stream_after_messages,omitempty"`
+}
+
+// Validate ensures all parts of the fault are valid.
+func (f *Fault) Validate() error {
+	if f.LatencyMS < 0 || f.JitterMS < 0 {
+		return errors.New("latency_ms and jitter_ms cannot be negative")
+	}
+	if f.ErrorRate < 0 || f.ErrorRate > 1 {
+		return errors.New("error_rate must be between 0 and 1")
+	}
+	if f.DropStreamAfterMessages < 0 {
+		return errors.New("drop_stream_after_messages cannot be negative")
+	}
+	return nil
+}
+
+func (f *Fault) matches(method, resourceName string) bool {
+	if f.Method != "" && f.Method != method && !strings.HasSuffix(method, "/"+f.Method) {
+		return false
+	}
+	return f.Resource == "" || f.Resource == resourceName
+}
+
+func (f *Fault) delay(ctx context.Context) error {
+	latency := time.Duration(f.LatencyMS) * time.Millisecond
+	if f.JitterMS > 0 {
+		latency += time.Duration(rand.Intn(f.JitterMS+1)) * time.Millisecond //nolint:gosec
+	}
+	if latency == 0 {
+		return nil
+	}
+	if !utils.SelectContextOrWait(ctx, latency) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (f *Fault) err() error {
+	if f.ErrorRate == 0 || rand.Float64() >= f.ErrorRate { //nolint:gosec
+		return nil
+	}
+	code := f.ErrorCode
+	if code == codes.OK {
+		code = codes.Unavailable
+	}
+	return status.Error(code, "injected fault")
+}
+
+// A FaultInjector injects faults into the calls a server handles. It is meant for test and development servers;
+// with no faults set its interceptors pass every call straight through.
+type FaultInjector struct {
+	mu     sync.RWMutex
+	faults []Fault
+}
+
+// NewFaultInjector returns a FaultInjector which injects the given faults.
+func NewFaultInjector(faults ...Fault) *FaultInjector {
+	return &FaultInjector{faults: faults}
+}
+
+// SetFaults replaces the faults being injected.
+func (fi *FaultInjector) SetFaults(faults []Fault) error {
+	for i := range faults {
+		if err := faults[i].Validate(); err != nil {
+			return errors.Wrapf(err, "fault %d", i)
+		}
+	}
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	fi.faults = append([]Fault(nil), faults...)
+	return nil
+}
+
+// Faults returns the faults being injected.
+func (fi *FaultInjector) Faults() []Fault {
+	fi.mu.RLock()
+	defer fi.mu.RUnlock()
+	return append([]Fault(nil), fi.faults...)
+}
+
+func (fi *FaultInjector) matching(method, resourceName string) []Fault {
+	fi.mu.RLock()
+	defer fi.mu.RUnlock()
+	var matched []Fault
+	for _, f := range fi.faults {
+		if f.matches(method, resourceName) {
+			matched = append(matched, f)
+		}
+	}
+	return matched
+}
+
+// UnaryServerInterceptor delays or fails the calls matched by a fault.
+func (fi *FaultInjector) UnaryServerInterceptor(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	for _, f := range fi.matching(info.FullMethod, requestResourceName(req)) {
+		if err := f.delay(ctx); err != nil {
+			return nil, err
+		}
+		if err := f.err(); err != nil {
+			return nil, err
+		}
+	}
+	return handler(ctx, req)
+}
+
+// StreamServerInterceptor delays, fails or drops the streams matched by a fault. A stream matches a fault on a
+// resource once the client sends a request naming it.
+func (fi *FaultInjector) StreamServerInterceptor(srv interface{}, ss grpc.ServerStream,
+	info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+) error {
+	fi.mu.RLock()
+	numFaults := len(fi.faults)
+	fi.mu.RUnlock()
+	if numFaults == 0 {
+		return handler(srv, ss)
+	}
+	return handler(srv, &faultServerStream{ServerStream: ss, injector: fi, method: info.FullMethod})
+}
+
+type faultServerStream struct {
+	grpc.ServerStream
+	injector *FaultInjector
+	method   string
+
+	mu      sync.Mutex
+	faults  []Fault
+	matched bool
+	sent    int
+}
+
+// match settles the faults of the stream from the first request it receives, or with no resource if it sends
+// before receiving anything, and fails the stream if one of them says to.
+func (s *faultServerStream) match(m interface{}) ([]Fault, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.matched {
+		return s.faults, nil
+	}
+	s.matched = true
+	s.faults = s.injector.matching(s.method, requestResourceName(m))
+	for _, f := range s.faults {
+		if err := f.err(); err != nil {
+			return nil, err
+		}
+	}
+	return s.faults, nil
+}
+
+func (s *faultServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	_, err := s.match(m)
+	return err
+}
+
+func (s *faultServerStream) SendMsg(m interface{}) error {
+	faults, err := s.match(nil)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.sent++
+	sent := s.sent
+	s.mu.Unlock()
+	for _, f := range faults {
+		if f.DropStreamAfterMessages > 0 && sent > f.DropStreamAfterMessages {
+			return status.Error(codes.Unavailable, "injected fault: stream dropped")
+		}
+		if err := f.delay(s.Context()); err != nil {
+			return err
+		}
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+// requestResourceName returns the name of the resource a request is for, if it names one.
+func requestResourceName(req interface{}) string {
+	if named, ok := req.(interface{ GetName() string }); ok {
+		return named.GetName()
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	armpb "go.viam.com/api/component/arm/v1"
+	"go.viam.com/test"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFaultInjector(t *testing.T) {
+	injector := NewFaultInjector()
+	handled := 0
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		handled++
+		return &armpb.StopResponse{}, nil
+	}
+	stopInfo := &grpc.UnaryServerInfo{FullMethod: "/viam.component.arm.v1.ArmService/Stop"}
+
+	_, err := injector.UnaryServerInterceptor(context.Background(), &armpb.StopRequest{Name: "arm1"}, stopInfo, handler)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, handled, test.ShouldEqual, 1)
+
+	err = injector.SetFaults([]Fault{{ErrorRate: 2}})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "error_rate")
+
+	test.That(t, injector.SetFaults([]Fault{
+		{Method: "Stop", Resource: "arm1", ErrorRate: 1, ErrorCode: codes.ResourceExhausted},
+		{Method: "/viam.component.arm.v1.ArmService/GetEndPosition", LatencyMS: 50},
+	}), test.ShouldBeNil)
+	test.That(t, injector.Faults(), test.ShouldHaveLength, 2)
+
+	_, err = injector.UnaryServerInterceptor(context.Background(), &armpb.StopRequest{Name: "arm1"}, stopInfo, handler)
+	test.That(t, status.Code(err), test.ShouldEqual, codes.ResourceExhausted)
+	test.That(t, handled, test.ShouldEqual, 1)
+
+	// other resources are left alone
+	_, err = injector.UnaryServerInterceptor(context.Background(), &armpb.StopRequest{Name: "arm2"}, stopInfo, handler)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, handled, test.ShouldEqual, 2)
+
+	start := time.Now()
+	_, err = injector.UnaryServerInterceptor(context.Background(), &armpb.GetEndPositionRequest{Name: "arm2"},
+		&grpc.UnaryServerInfo{FullMethod: "/viam.component.arm.v1.ArmService/GetEndPosition"}, handler)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 50*time.Millisecond)
+
+	// latency gives up with the caller
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = injector.UnaryServerInterceptor(ctx, &armpb.GetEndPositionRequest{Name: "arm2"},
+		&grpc.UnaryServerInfo{FullMethod: "/viam.component.arm.v1.ArmService/GetEndPosition"}, handler)
+	test.That(t, err, test.ShouldBeError, context.Canceled)
+}
+
+func TestFaultInjectorStreams(t *testing.T) {
+	injector := NewFaultInjector(Fault{Method: "StreamStatus", DropStreamAfterMessages: 2})
+	info := &grpc.StreamServerInfo{FullMethod: "/viam.robot.v1.RobotService/StreamStatus"}
+	stream := &fakeServerStream{ctx: context.Background()}
+	err := injector.StreamServerInterceptor(nil, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
+		for {
+			if err := ss.SendMsg(&armpb.StopResponse{}); err != nil {
+				return err
+			}
+		}
+	})
+	test.That(t, status.Code(err), test.ShouldEqual, codes.Unavailable)
+	test.That(t, stream.sent, test.ShouldHaveLength, 2)
+
+	// streams of other methods are untouched
+	stream = &fakeServerStream{ctx: context.Background()}
+	err = injector.StreamServerInterceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/viam.robot.v1.RobotService/Other"},
+		func(srv interface{}, ss grpc.ServerStream) error {
+			for i := 0; i < 3; i++ {
+				if err := ss.SendMsg(&armpb.StopResponse{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream.sent, test.ShouldHaveLength, 3)
+}
@@ -0,0 +1,287 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// FieldMaskMetadataKey is the metadata key carrying the comma separated field paths a client wants in a response.
+// REST clients send it through the gateway as the Grpc-Metadata-Viam-Field-Mask header.
+const FieldMaskMetadataKey = "viam-field-mask"
+
+// WithFieldMask returns a context which asks the server to leave every field out of the responses of calls made
+// with it except the given dotted paths, such as "status.name" or "status.status.position". Paths select
+// fields of every element of repeated fields and the keys of google.protobuf.Struct values, so large status
+// payloads can be trimmed to what a client polls for.
+func WithFieldMask(ctx context.Context, paths ...string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, FieldMaskMetadataKey, strings.Join(paths, ","))
+}
+
+// FieldMaskUnaryServerInterceptor applies the field mask requested by the client, if any, to the response. A mask
+// naming fields the response of the method does not have is refused before the call is handled, so that a bad mask
+// never fails a call which has already acted.
+func FieldMaskUnaryServerInterceptor(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	paths := fieldMaskFromIncomingContext(ctx)
+	if len(paths) == 0 {
+		return handler(ctx, req)
+	}
+	if err := validateFieldMaskForMethod(info.FullMethod, paths); err != nil {
+		return nil, err
+	}
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+	if msg, ok := resp.(proto.Message); ok {
+		if err := ApplyFieldMask(msg, paths); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	return resp, nil
+}
+
+// FieldMaskStreamServerInterceptor applies the field mask requested by the client, if any, to every message
+// sent on the stream. A mask naming fields the messages of the method do not have is refused before the stream is
+// handled.
+func FieldMaskStreamServerInterceptor(srv interface{}, ss grpc.ServerStream,
+	info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+) error {
+	paths := fieldMaskFromIncomingContext(ss.Context())
+	if len(paths) == 0 {
+		return handler(srv, ss)
+	}
+	if err := validateFieldMaskForMethod(info.FullMethod, paths); err != nil {
+		return err
+	}
+	return handler(srv, &fieldMaskServerStream{ServerStream: ss, paths: paths})
+}
+
+// validateFieldMaskForMethod checks the paths against the response of a method, given by its full name such as
+// "/viam.robot.v1.RobotService/GetStatus". Paths of methods whose descriptors are not registered are checked once
+// the response is masked.
+func validateFieldMaskForMethod(fullMethod string, paths []string) error {
+	serviceName, methodName, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return nil
+	}
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil
+	}
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return nil
+	}
+	if err := ValidateFieldMask(method.Output(), paths); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+type fieldMaskServerStream struct {
+	grpc.ServerStream
+	paths []string
+}
+
+func (s *fieldMaskServerStream) SendMsg(m interface{}) error {
+	if msg, ok := m.(proto.Message); ok {
+		if err := ApplyFieldMask(msg, s.paths); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+func fieldMaskFromIncomingContext(ctx context.Context) []string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+	var paths []string
+	for _, value := range md.Get(FieldMaskMetadataKey) {
+		for _, path := range strings.Split(value, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// fieldMaskTree holds the selected paths by their segments. A node without children keeps its field whole.
+type fieldMaskTree map[string]fieldMaskTree
+
+func (t fieldMaskTree) add(segments []string) {
+	node := t
+	for i, segment := range segments {
+		child, ok := node[segment]
+		if ok && len(child) == 0 {
+			// the whole field is already selected
+			return
+		}
+		if i == len(segments)-1 {
+			node[segment] = fieldMaskTree{}
+			return
+		}
+		if !ok {
+			child = fieldMaskTree{}
+			node[segment] = child
+		}
+		node = child
+	}
+}
+
+// ApplyFieldMask clears every field of msg except those selected by the dotted paths. It returns an error if a
+// path names a field the message does not have.
+func ApplyFieldMask(msg proto.Message, paths []string) error {
+	tree := fieldMaskTree{}
+	for _, path := range paths {
+		tree.add(strings.Split(path, "."))
+	}
+	if len(tree) == 0 {
+		return nil
+	}
+	return pruneMessage(msg.ProtoReflect(), tree, "")
+}
+
+// ValidateFieldMask returns an error if a dotted path names a field messages of the given type do not have, the
+// same error ApplyFieldMask would return for such a message.
+func ValidateFieldMask(desc protoreflect.MessageDescriptor, paths []string) error {
+	tree := fieldMaskTree{}
+	for _, path := range paths {
+		tree.add(strings.Split(path, "."))
+	}
+	return validateMessage(desc, tree, "")
+}
+
+func validateMessage(desc protoreflect.MessageDescriptor, tree fieldMaskTree, prefix string) error {
+	if desc.FullName() == (&structpb.Struct{}).ProtoReflect().Descriptor().FullName() {
+		return nil
+	}
+	fields := desc.Fields()
+	for name, sub := range tree {
+		fd := fields.ByName(protoreflect.Name(name))
+		if fd == nil {
+			fd = fields.ByJSONName(name)
+		}
+		if fd == nil {
+			return fmt.Errorf("%s has no field %q", desc.FullName(), prefix+name)
+		}
+		if len(sub) == 0 {
+			continue
+		}
+		var fieldDesc protoreflect.MessageDescriptor
+		if fd.IsMap() {
+			fieldDesc = fd.MapValue().Message()
+		} else {
+			fieldDesc = fd.Message()
+		}
+		if fieldDesc == nil {
+			return fmt.Errorf("cannot select fields of %s", prefix+string(fd.Name()))
+		}
+		if err := validateMessage(fieldDesc, sub, prefix+string(fd.Name())+"."); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func pruneMessage(m protoreflect.Message, tree fieldMaskTree, prefix string) error {
+	if s, ok := m.Interface().(*structpb.Struct); ok {
+		pruneStruct(s, tree)
+		return nil
+	}
+
+	fields := m.Descriptor().Fields()
+	selected := map[protoreflect.FieldNumber]fieldMaskTree{}
+	for name, sub := range tree {
+		fd := fields.ByName(protoreflect.Name(name))
+		if fd == nil {
+			fd = fields.ByJSONName(name)
+		}
+		if fd == nil {
+			return fmt.Errorf("%s has no field %q", m.Descriptor().FullName(), prefix+name)
+		}
+		selected[fd.Number()] = sub
+	}
+
+	var cleared []protoreflect.FieldDescriptor
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		sub, ok := selected[fd.Number()]
+		if !ok {
+			cleared = append(cleared, fd)
+			return true
+		}
+		if len(sub) == 0 {
+			return true
+		}
+		path := prefix + string(fd.Name()) + "."
+		switch {
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := 0; i < list.Len() && err == nil; i++ {
+				err = pruneMessage(list.Get(i).Message(), sub, path)
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, value protoreflect.Value) bool {
+				err = pruneMessage(value.Message(), sub, path)
+				return err == nil
+			})
+		case fd.Message() != nil && !fd.IsList() && !fd.IsMap():
+			err = pruneMessage(v.Message(), sub, path)
+		default:
+			err = fmt.Errorf("cannot select fields of %s", prefix+string(fd.Name()))
+		}
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, fd := range cleared {
+		m.Clear(fd)
+	}
+	return nil
+}
+
+// pruneStruct keeps the selected keys of a struct. Selecting fields of a value which is not a struct, or a list of
+// them, keeps it whole since its shape is not known ahead of time.
+func pruneStruct(s *structpb.Struct, tree fieldMaskTree) {
+	for key, value := range s.GetFields() {
+		sub, ok := tree[key]
+		if !ok {
+			delete(s.Fields, key)
+			continue
+		}
+		if len(sub) != 0 {
+			pruneValue(value, sub)
+		}
+	}
+}
+
+func pruneValue(v *structpb.Value, tree fieldMaskTree) {
+	switch kind := v.GetKind().(type) {
+	case *structpb.Value_StructValue:
+		pruneStruct(kind.StructValue, tree)
+	case *structpb.Value_ListValue:
+		for _, elem := range kind.ListValue.GetValues() {
+			pruneValue(elem, tree)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	commonpb "go.viam.com/api/common/v1"
+	pb "go.viam.com/api/robot/v1"
+	"go.viam.com/test"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func statusResponse(t *testing.T) *pb.GetStatusResponse {
+	t.Helper()
+	armStatus, err := structpb.NewStruct(map[string]interface{}{
+		"is_moving": true,
+		"end_position": map[string]interface{}{
+			"x": 1, "y": 2, "z": 3,
+		},
+		"joint_positions": map[string]interface{}{"values": []interface{}{0, 1, 2}},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	return &pb.GetStatusResponse{Status: []*pb.Status{
+		{
+			Name:             &commonpb.ResourceName{Namespace: "rdk", Type: "component", Subtype: "arm", Name: "arm1"},
+			Status:           armStatus,
+			LastReconfigured: timestamppb.Now(),
+		},
+		{
+			Name:             &commonpb.ResourceName{Namespace: "rdk", Type: "component", Subtype: "arm", Name: "arm2"},
+			Status:           proto.Clone(armStatus).(*structpb.Struct),
+			LastReconfigured: timestamppb.Now(),
+		},
+	}}
+}
+
+func TestApplyFieldMask(t *testing.T) {
+	resp := statusResponse(t)
+	test.That(t, ApplyFieldMask(resp, []string{"status.name.name", "status.status.end_position.x", "status.status.is_moving"}),
+		test.ShouldBeNil)
+	test.That(t, resp.Status, test.ShouldHaveLength, 2)
+	for i, s := range resp.Status {
+		test.That(t, s.LastReconfigured, test.ShouldBeNil)
+		test.That(t, s.Name.Subtype, test.ShouldEqual, "")
+		test.That(t, s.Status.AsMap(), test.ShouldResemble, map[string]interface{}{
+			"is_moving":    true,
+			"end_position": map[string]interface{}{"x": 1.0},
+		})
+		test.That(t, s.Name.Name, test.ShouldEqual, []string{"arm1", "arm2"}[i])
+	}
+
+	// selecting a field whole wins over selecting some of its fields
+	resp = statusResponse(t)
+	test.That(t, ApplyFieldMask(resp, []string{"status.status.end_position.x", "status.status"}),
+		test.ShouldBeNil)
+	test.That(t, resp.Status[0].Name, test.ShouldBeNil)
+	test.That(t, resp.Status[0].Status.Fields, test.ShouldHaveLength, 3)
+
+	// json names work too
+	resp = statusResponse(t)
+	test.That(t, ApplyFieldMask(resp, []string{"status.lastReconfigured"}), test.ShouldBeNil)
+	test.That(t, resp.Status[0].LastReconfigured, test.ShouldNotBeNil)
+	test.That(t, resp.Status[0].Status, test.ShouldBeNil)
+
+	test.That(t, ApplyFieldMask(resp, nil), test.ShouldBeNil)
+	test.That(t, resp.Status[0].LastReconfigured, test.ShouldNotBeNil)
+
+	err := ApplyFieldMask(statusResponse(t), []string{"status.nope"})
+	test.That(t, err, test.ShouldBeError, `viam.robot.v1.Status has no field "status.nope"`)
+	err = ApplyFieldMask(statusResponse(t), []string{"status.name.name.first"})
+	test.That(t, err, test.ShouldBeError, "cannot select fields of status.name.name")
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []interface{}
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeServerStream) SendMsg(m interface{}) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+func TestFieldMaskInterceptors(t *testing.T) {
+	outgoing, _ := metadata.FromOutgoingContext(WithFieldMask(context.Background(), "status.name", "status.status.is_moving"))
+	ctx := metadata.NewIncomingContext(context.Background(), outgoing)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return statusResponse(t), nil
+	}
+	resp, err := FieldMaskUnaryServerInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	test.That(t, err, test.ShouldBeNil)
+	statuses := resp.(*pb.GetStatusResponse).Status
+	test.That(t, statuses[1].Name.Name, test.ShouldEqual, "arm2")
+	test.That(t, statuses[1].LastReconfigured, test.ShouldBeNil)
+	test.That(t, statuses[1].Status.AsMap(), test.ShouldResemble, map[string]interface{}{"is_moving": true})
+
+	// without a mask the response is untouched
+	resp, err = FieldMaskUnaryServerInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp.(*pb.GetStatusResponse).Status[1].LastReconfigured, test.ShouldNotBeNil)
+
+	badCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(FieldMaskMetadataKey, "bad"))
+	_, err = FieldMaskUnaryServerInterceptor(badCtx, nil, &grpc.UnaryServerInfo{}, handler)
+	test.That(t, status.Code(err), test.ShouldEqual, codes.InvalidArgument)
+
+	// a bad mask is refused before a known method is handled, so the call never acts
+	handled := false
+	_, err = FieldMaskUnaryServerInterceptor(badCtx, nil,
+		&grpc.UnaryServerInfo{FullMethod: "/viam.robot.v1.RobotService/StopAll"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			handled = true
+			return &pb.StopAllResponse{}, nil
+		})
+	test.That(t, status.Code(err), test.ShouldEqual, codes.InvalidArgument)
+	test.That(t, handled, test.ShouldBeFalse)
+
+	stream := &fakeServerStream{ctx: ctx}
+	err = FieldMaskStreamServerInterceptor(nil, stream, &grpc.StreamServerInfo{}, func(srv interface{}, ss grpc.ServerStream) error {
+		return ss.SendMsg(&pb.StreamStatusResponse{Status: statusResponse(t).Status})
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream.sent, test.ShouldHaveLength, 1)
+	sent := stream.sent[0].(*pb.StreamStatusResponse)
+	test.That(t, sent.Status[0].LastReconfigured, test.ShouldBeNil)
+	test.That(t, sent.Status[0].Name.Name, test.ShouldEqual, "arm1")
+}
+
+func TestValidateFieldMask(t *testing.T) {
+	desc := (&pb.GetStatusResponse{}).ProtoReflect().Descriptor()
+	test.That(t, ValidateFieldMask(desc, []string{"status.name.subtype", "status.status.is_moving"}), test.ShouldBeNil)
+	test.That(t, ValidateFieldMask(desc, []string{"status.lastReconfigured"}), test.ShouldBeNil)
+
+	err := ValidateFieldMask(desc, []string{"status.nope"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldEqual, ApplyFieldMask(statusResponse(t), []string{"status.nope"}).Error())
+
+	err = ValidateFieldMask(desc, []string{"status.name.name.first"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "cannot select fields of status.name.name")
+}
@@ -0,0 +1,461 @@
+package motionplan
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	pb "go.viam.com/api/service/motion/v1"
+
+	"go.viam.com/rdk/logging"
+	frame "go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+)
+
+const (
+	// defaultCoordinationResolutionMM is how far the end of a chain moves between the states at which it is checked
+	// against the other chains.
+	defaultCoordinationResolutionMM = 10.
+	// maxCoordinationStates bounds the states searched for a coordination of the chains.
+	maxCoordinationStates = 1 << 20
+)
+
+// ChainGoal is the goal of one kinematic chain of a CoordinatedPlanRequest.
+type ChainGoal struct {
+	Frame           frame.Frame
+	Goal            *frame.PoseInFrame
+	ConstraintSpecs *pb.Constraints
+}
+
+// CoordinatedPlanRequest is a request to plan the motion of several kinematic chains which share a workspace, such as
+// two arms, together. The chains must not move each other.
+type CoordinatedPlanRequest struct {
+	Logger             logging.Logger
+	FrameSystem        frame.FrameSystem
+	Chains             []ChainGoal
+	StartConfiguration map[string][]frame.Input
+	WorldState         *frame.WorldState
+	// Options are the options of each chain's plan, along with "coordination_resolution_mm", how far the end of a
+	// chain moves between the states at which it is checked against the other chains, 10mm by default.
+	Options map[string]interface{}
+}
+
+// validate ensures CoordinatedPlanRequests are not malformed, returning the names of the frames moved by each chain.
+func (req *CoordinatedPlanRequest) validate() ([]map[string]bool, error) {
+	if req == nil {
+		return nil, errors.New("CoordinatedPlanRequest cannot be nil")
+	}
+	if req.Logger == nil {
+		return nil, errors.New("CoordinatedPlanRequest cannot have nil logger")
+	}
+	if req.FrameSystem == nil {
+		return nil, errors.New("CoordinatedPlanRequest cannot have nil framesystem")
+	}
+	if len(req.Chains) == 0 {
+		return nil, errors.New("CoordinatedPlanRequest must have at least one chain")
+	}
+	moved := make([]map[string]bool, 0, len(req.Chains))
+	for _, chain := range req.Chains {
+		if chain.Frame == nil {
+			return nil, errors.New("CoordinatedPlanRequest cannot have a chain with nil frame")
+		}
+		name := chain.Frame.Name()
+		if req.FrameSystem.Frame(name) == nil {
+			return nil, frame.NewFrameMissingError(name)
+		}
+		if chain.Goal == nil {
+			return nil, errors.Errorf("chain %q cannot have nil goal", name)
+		}
+		if req.FrameSystem.Frame(chain.Goal.Parent()) == nil {
+			return nil, frame.NewParentFrameMissingError(chain.Goal.Name(), chain.Goal.Parent())
+		}
+		if _, ok := req.StartConfiguration[name]; !ok {
+			return nil, errors.Errorf("chain %q has no start configuration", name)
+		}
+		dof := make([]float64, len(chain.Frame.DoF()))
+		chainMoved, err := framesMovedBy(req.FrameSystem, map[string][]frame.Input{name: frame.FloatsToInputs(dof)})
+		if err != nil {
+			return nil, err
+		}
+		// the chain's own frame is counted as moved even if it has no degrees of freedom
+		chainMoved[name] = true
+		for i, other := range moved {
+			otherName := req.Chains[i].Frame.Name()
+			if other[name] || chainMoved[otherName] {
+				return nil, errors.Errorf("chains %q and %q cannot be coordinated when one moves the other", otherName, name)
+			}
+		}
+		moved = append(moved, chainMoved)
+	}
+	for i, chain := range req.Chains {
+		for j, other := range moved {
+			if i != j && other[chain.Goal.Parent()] {
+				return nil, errors.Errorf("the goal of chain %q cannot be in frame %q, which chain %q moves",
+					chain.Frame.Name(), chain.Goal.Parent(), req.Chains[j].Frame.Name())
+			}
+		}
+	}
+	return moved, nil
+}
+
+// PlanCoordinatedMotion plans the motion of several kinematic chains sharing a workspace, such as two arms, to their
+// goals at the same time, so that they avoid each other as they move rather than each treating the others as static
+// obstacles. The returned Plan's Trajectory is synchronized: each of its steps has the inputs of every chain, and the
+// chains move from one step to the next together.
+//
+// Each chain is first planned on its own, avoiding the obstacles of the world but not the other chains. The paths
+// are then coordinated: the chains are stepped along them together, each waiting where it would otherwise collide
+// with another, in the fewest steps in which none of them collide.
+func PlanCoordinatedMotion(ctx context.Context, req *CoordinatedPlanRequest) (Plan, error) {
+	moved, err := req.validate()
+	if err != nil {
+		return nil, err
+	}
+	resolution := defaultCoordinationResolutionMM
+	if raw, ok := req.Options["coordination_resolution_mm"]; ok {
+		if resolution, ok = raw.(float64); !ok || resolution <= 0 {
+			return nil, errors.New("coordination_resolution_mm must be a positive number")
+		}
+	}
+	collisionBufferMM := defaultCollisionBufferMM
+	if raw, ok := req.Options["collision_buffer_mm"]; ok {
+		if collisionBufferMM, ok = raw.(float64); !ok || collisionBufferMM < 0 {
+			return nil, errors.New("collision_buffer_mm must be a non-negative number")
+		}
+	}
+
+	start := frame.StartPositions(req.FrameSystem)
+	for name, inputs := range req.StartConfiguration {
+		start[name] = inputs
+	}
+	startGeometries, err := frame.FrameSystemGeometries(req.FrameSystem, start)
+	if err != nil {
+		return nil, err
+	}
+
+	chains := make([]*coordinatedChain, 0, len(req.Chains))
+	for i, chain := range req.Chains {
+		name := chain.Frame.Name()
+		// the chain may pass through the others, whose motion is coordinated with it afterwards
+		var allows []*pb.CollisionSpecification_AllowedFrameCollisions
+		for j, other := range moved {
+			if i == j {
+				continue
+			}
+			for frame1 := range moved[i] {
+				for frame2 := range other {
+					_, ok1 := startGeometries[frame1]
+					_, ok2 := startGeometries[frame2]
+					if ok1 && ok2 {
+						allows = append(allows, &pb.CollisionSpecification_AllowedFrameCollisions{Frame1: frame1, Frame2: frame2})
+					}
+				}
+			}
+		}
+		plan, err := PlanMotion(ctx, &PlanRequest{
+			Logger:             req.Logger,
+			Goal:               chain.Goal,
+			Frame:              chain.Frame,
+			FrameSystem:        req.FrameSystem,
+			StartConfiguration: req.StartConfiguration,
+			WorldState:         req.WorldState,
+			ConstraintSpecs:    withAllowedCollisions(chain.ConstraintSpecs, allows),
+			Options:            req.Options,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot plan chain %q", name)
+		}
+		path, err := plan.Trajectory().GetFrameInputs(name)
+		if err != nil {
+			return nil, err
+		}
+		coordinated, err := newCoordinatedChain(req.FrameSystem, name, path, moved[i], start, resolution)
+		if err != nil {
+			return nil, err
+		}
+		chains = append(chains, coordinated)
+	}
+
+	traj, err := coordinateChains(chains, collisionBufferMM)
+	if err != nil {
+		return nil, err
+	}
+	path := make(Path, 0, len(traj))
+	for _, step := range traj {
+		inputs := make(map[string][]frame.Input, len(start))
+		for name, frameInputs := range start {
+			inputs[name] = frameInputs
+		}
+		for name, frameInputs := range step {
+			inputs[name] = frameInputs
+		}
+		poses := make(PathStep, len(step))
+		for name := range step {
+			tf, err := req.FrameSystem.Transform(inputs, frame.NewPoseInFrame(name, spatialmath.NewZeroPose()), frame.World)
+			if err != nil {
+				return nil, err
+			}
+			pose, ok := tf.(*frame.PoseInFrame)
+			if !ok {
+				return nil, errors.New("pose not transformable")
+			}
+			poses[name] = pose
+		}
+		path = append(path, poses)
+	}
+	return NewSimplePlan(path, traj), nil
+}
+
+// withAllowedCollisions returns constraints which also allow the given collisions.
+func withAllowedCollisions(constraints *pb.Constraints, allows []*pb.CollisionSpecification_AllowedFrameCollisions) *pb.Constraints {
+	if len(allows) == 0 {
+		return constraints
+	}
+	withAllows := &pb.Constraints{}
+	if constraints != nil {
+		withAllows.LinearConstraint = constraints.LinearConstraint
+		withAllows.OrientationConstraint = constraints.OrientationConstraint
+		withAllows.CollisionSpecification = append(withAllows.CollisionSpecification, constraints.CollisionSpecification...)
+	}
+	withAllows.CollisionSpecification = append(withAllows.CollisionSpecification, &pb.CollisionSpecification{Allows: allows})
+	return withAllows
+}
+
+// coordinatedChain is the path of a chain stepped along finely enough to check it against the other chains.
+type coordinatedChain struct {
+	name   string
+	inputs [][]frame.Input
+	// waypoint marks the steps which are waypoints of the chain's plan, where the chain may change direction
+	waypoint   []bool
+	geometries [][]spatialmath.Geometry
+}
+
+// newCoordinatedChain steps a chain along its path so that its end moves no more than resolution between steps,
+// placing the geometries of the frames it moves, with the rest of the frame system at the given inputs, at each step.
+func newCoordinatedChain(
+	fs frame.FrameSystem,
+	name string,
+	path [][]frame.Input,
+	moved map[string]bool,
+	start map[string][]frame.Input,
+	resolution float64,
+) (*coordinatedChain, error) {
+	if len(path) == 0 {
+		return nil, errors.Errorf("chain %q has an empty path", name)
+	}
+	f := fs.Frame(name)
+	chain := &coordinatedChain{name: name, inputs: [][]frame.Input{path[0]}, waypoint: []bool{true}}
+	for i := 1; i < len(path); i++ {
+		fromPose, err := f.Transform(path[i-1])
+		if err != nil {
+			return nil, err
+		}
+		toPose, err := f.Transform(path[i])
+		if err != nil {
+			return nil, err
+		}
+		steps := PathStepCount(fromPose, toPose, resolution)
+		for step := 1; step <= steps; step++ {
+			interpolated, err := f.Interpolate(path[i-1], path[i], float64(step)/float64(steps))
+			if err != nil {
+				return nil, err
+			}
+			chain.inputs = append(chain.inputs, interpolated)
+			chain.waypoint = append(chain.waypoint, step == steps)
+		}
+	}
+
+	inputs := make(map[string][]frame.Input, len(start))
+	for frameName, frameInputs := range start {
+		inputs[frameName] = frameInputs
+	}
+	for _, stepInputs := range chain.inputs {
+		inputs[name] = stepInputs
+		geometries, err := frame.FrameSystemGeometries(fs, inputs)
+		if err != nil {
+			return nil, err
+		}
+		var stepGeometries []spatialmath.Geometry
+		for frameName, gif := range geometries {
+			if moved[frameName] {
+				stepGeometries = append(stepGeometries, gif.Geometries()...)
+			}
+		}
+		chain.geometries = append(chain.geometries, stepGeometries)
+	}
+	return chain, nil
+}
+
+// coordinateChains finds the fewest synchronized steps taking every chain along its path without any two colliding,
+// where at each step each chain either waits or moves to the next step of its path. It searches the grid of the
+// steps of the chains' paths breadth first, letting chains move together only if they do not collide when any of
+// them have moved without the others. It returns a Trajectory of the steps at which any chain reaches a waypoint or
+// starts or stops waiting, between which the chains move linearly together.
+func coordinateChains(chains []*coordinatedChain, collisionBufferMM float64) (Trajectory, error) {
+	states := 1
+	for _, chain := range chains {
+		states *= len(chain.inputs)
+		if states > maxCoordinationStates {
+			return nil, errors.New("too many steps to coordinate the chains, try a larger coordination_resolution_mm")
+		}
+	}
+	decode := func(state int) []int {
+		indices := make([]int, len(chains))
+		for i := len(chains) - 1; i >= 0; i-- {
+			indices[i] = state % len(chains[i].inputs)
+			state /= len(chains[i].inputs)
+		}
+		return indices
+	}
+	encode := func(indices []int) int {
+		state := 0
+		for i, index := range indices {
+			state = state*len(chains[i].inputs) + index
+		}
+		return state
+	}
+
+	type stepPair struct{ a, i, b, j int }
+	pairCollides := map[stepPair]bool{}
+	collides := func(indices []int) (bool, error) {
+		for a := 0; a < len(chains); a++ {
+			for b := a + 1; b < len(chains); b++ {
+				key := stepPair{a, indices[a], b, indices[b]}
+				collision, ok := pairCollides[key]
+				if !ok {
+					for _, x := range chains[a].geometries[indices[a]] {
+						for _, y := range chains[b].geometries[indices[b]] {
+							c, err := x.CollidesWith(y, collisionBufferMM)
+							if err != nil {
+								return false, err
+							}
+							if c {
+								collision = true
+								break
+							}
+						}
+						if collision {
+							break
+						}
+					}
+					pairCollides[key] = collision
+				}
+				if collision {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	}
+
+	startIndices := make([]int, len(chains))
+	if collision, err := collides(startIndices); err != nil {
+		return nil, err
+	} else if collision {
+		return nil, errors.New("the chains collide with each other at the start")
+	}
+	goal := states - 1
+	parent := make([]int32, states)
+	for i := range parent {
+		parent[i] = -1
+	}
+	parent[0] = 0
+	queue := []int{0}
+	for len(queue) > 0 && parent[goal] < 0 {
+		state := queue[0]
+		queue = queue[1:]
+		indices := decode(state)
+		// each non-empty subset of the chains may move
+		for moving := 1; moving < 1<<len(chains); moving++ {
+			next := make([]int, len(indices))
+			copy(next, indices)
+			valid := true
+			for i := range chains {
+				if moving&(1<<i) == 0 {
+					continue
+				}
+				if next[i]++; next[i] >= len(chains[i].inputs) {
+					valid = false
+					break
+				}
+			}
+			if !valid {
+				continue
+			}
+			nextState := encode(next)
+			if parent[nextState] >= 0 {
+				continue
+			}
+			collision, err := collides(next)
+			if err != nil {
+				return nil, err
+			}
+			if collision {
+				// mark the state so it is not checked again
+				parent[nextState] = int32(nextState)
+				continue
+			}
+			// chains moving together must not collide part way, where only some of them have moved
+			for partly := (moving - 1) & moving; partly > 0 && !collision; partly = (partly - 1) & moving {
+				between := make([]int, len(indices))
+				for i := range indices {
+					between[i] = indices[i]
+					if partly&(1<<i) != 0 {
+						between[i]++
+					}
+				}
+				if collision, err = collides(between); err != nil {
+					return nil, err
+				}
+			}
+			if collision {
+				continue
+			}
+			parent[nextState] = int32(state)
+			queue = append(queue, nextState)
+		}
+	}
+	if parent[goal] < 0 || parent[goal] == int32(goal) {
+		return nil, errors.New("the chains cannot reach their goals without colliding with each other")
+	}
+
+	var reversed [][]int
+	for state := goal; state != 0; state = int(parent[state]) {
+		reversed = append(reversed, decode(state))
+	}
+	reversed = append(reversed, startIndices)
+	cells := make([][]int, 0, len(reversed))
+	for i := len(reversed) - 1; i >= 0; i-- {
+		cells = append(cells, reversed[i])
+	}
+
+	movingMask := func(from, to []int) int {
+		mask := 0
+		for i := range from {
+			if to[i] != from[i] {
+				mask |= 1 << i
+			}
+		}
+		return mask
+	}
+	traj := Trajectory{}
+	for k, cell := range cells {
+		keep := k == 0 || k == len(cells)-1
+		if !keep {
+			keep = movingMask(cells[k-1], cell) != movingMask(cell, cells[k+1])
+			for i, index := range cell {
+				if index != cells[k-1][i] && chains[i].waypoint[index] {
+					keep = true
+				}
+			}
+		}
+		if !keep {
+			continue
+		}
+		step := make(map[string][]frame.Input, len(chains))
+		for i, chain := range chains {
+			step[chain.name] = chain.inputs[cell[i]]
+		}
+		traj = append(traj, step)
+	}
+	return traj, nil
+}
@@ -0,0 +1,133 @@
+package motionplan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	frame "go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// crossingGantries returns a frame system of two gantries, along x and along y, whose heads meet at the origin.
+func crossingGantries(t *testing.T) frame.FrameSystem {
+	t.Helper()
+	fs := frame.NewEmptyFrameSystem("")
+	for _, gantry := range []struct {
+		name string
+		axis r3.Vector
+	}{
+		{"gantry_x", r3.Vector{X: 1}},
+		{"gantry_y", r3.Vector{Y: 1}},
+	} {
+		f, err := frame.NewTranslationalFrame(gantry.name, gantry.axis, frame.Limit{Min: -500, Max: 500})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, fs.AddFrame(f, fs.World()), test.ShouldBeNil)
+		box, err := spatialmath.NewBox(spatialmath.NewZeroPose(), r3.Vector{20, 20, 20}, gantry.name+"_head")
+		test.That(t, err, test.ShouldBeNil)
+		head, err := frame.NewStaticFrameWithGeometry(gantry.name+"_head", spatialmath.NewZeroPose(), box)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, fs.AddFrame(head, f), test.ShouldBeNil)
+	}
+	return fs
+}
+
+func TestCoordinateChains(t *testing.T) {
+	fs := crossingGantries(t)
+	start := frame.StartPositions(fs)
+	chain := func(name string, path ...float64) *coordinatedChain {
+		t.Helper()
+		inputs := make([][]frame.Input, 0, len(path))
+		for _, p := range path {
+			inputs = append(inputs, []frame.Input{{p}})
+		}
+		moved := map[string]bool{name: true, name + "_head": true}
+		c, err := newCoordinatedChain(fs, name, inputs, moved, start, 10)
+		test.That(t, err, test.ShouldBeNil)
+		return c
+	}
+
+	t.Run("crossing paths", func(t *testing.T) {
+		// moving together, the heads would meet at the origin
+		traj, err := coordinateChains([]*coordinatedChain{chain("gantry_x", -100, 100), chain("gantry_y", -100, 100)}, 0)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, traj[0], test.ShouldResemble, map[string][]frame.Input{"gantry_x": {{-100}}, "gantry_y": {{-100}}})
+		test.That(t, traj[len(traj)-1], test.ShouldResemble, map[string][]frame.Input{"gantry_x": {{100}}, "gantry_y": {{100}}})
+		// one gantry waits for the other, so the trajectory has more than its start and end
+		test.That(t, len(traj), test.ShouldBeGreaterThan, 2)
+
+		// the heads never collide as the gantries move together between the steps
+		for i := 1; i < len(traj); i++ {
+			for by := 0.; by <= 1; by += 0.05 {
+				inputs := map[string][]frame.Input{}
+				for name, to := range traj[i] {
+					interpolated, err := fs.Frame(name).Interpolate(traj[i-1][name], to, by)
+					test.That(t, err, test.ShouldBeNil)
+					inputs[name] = interpolated
+				}
+				geometries, err := frame.FrameSystemGeometries(fs, inputs)
+				test.That(t, err, test.ShouldBeNil)
+				collides, err := geometries["gantry_x_head"].Geometries()[0].CollidesWith(
+					geometries["gantry_y_head"].Geometries()[0], 0)
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, collides, test.ShouldBeFalse)
+			}
+		}
+	})
+
+	t.Run("paths which do not meet", func(t *testing.T) {
+		// the gantries move together in a single step
+		traj, err := coordinateChains([]*coordinatedChain{chain("gantry_x", 100, 300), chain("gantry_y", -100, -300)}, 0)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, traj, test.ShouldResemble, Trajectory{
+			{"gantry_x": {{100}}, "gantry_y": {{-100}}},
+			{"gantry_x": {{300}}, "gantry_y": {{-300}}},
+		})
+	})
+
+	t.Run("a head parked in the way", func(t *testing.T) {
+		_, err := coordinateChains([]*coordinatedChain{chain("gantry_x", -100, 100), chain("gantry_y", 0)}, 0)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "cannot reach their goals without colliding")
+
+		_, err = coordinateChains([]*coordinatedChain{chain("gantry_x", 0, 100), chain("gantry_y", 0)}, 0)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "collide with each other at the start")
+	})
+}
+
+func TestCoordinatedPlanRequestValidation(t *testing.T) {
+	fs := crossingGantries(t)
+	// a third gantry carried by the first
+	carried, err := frame.NewTranslationalFrame("carried", r3.Vector{Z: 1}, frame.Limit{Min: -500, Max: 500})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(carried, fs.Frame("gantry_x")), test.ShouldBeNil)
+
+	start := map[string][]frame.Input{"gantry_x": {{0}}, "gantry_y": {{0}}, "carried": {{0}}}
+	goal := frame.NewPoseInFrame(frame.World, spatialmath.NewPoseFromPoint(r3.Vector{X: 100}))
+	plan := func(chains ...ChainGoal) error {
+		_, err := PlanCoordinatedMotion(context.Background(), &CoordinatedPlanRequest{
+			Logger:             logging.NewTestLogger(t),
+			FrameSystem:        fs,
+			Chains:             chains,
+			StartConfiguration: start,
+		})
+		return err
+	}
+
+	err = plan()
+	test.That(t, err, test.ShouldBeError, "CoordinatedPlanRequest must have at least one chain")
+	err = plan(ChainGoal{Frame: fs.Frame("gantry_x"), Goal: goal}, ChainGoal{Frame: fs.Frame("carried"), Goal: goal})
+	test.That(t, err, test.ShouldBeError, `chains "gantry_x" and "carried" cannot be coordinated when one moves the other`)
+	err = plan(
+		ChainGoal{Frame: fs.Frame("gantry_x"), Goal: goal},
+		ChainGoal{Frame: fs.Frame("gantry_y"), Goal: frame.NewPoseInFrame("gantry_x_head", spatialmath.NewZeroPose())},
+	)
+	test.That(t, err, test.ShouldBeError, `the goal of chain "gantry_y" cannot be in frame "gantry_x_head", which chain "gantry_x" moves`)
+	delete(start, "gantry_y")
+	err = plan(ChainGoal{Frame: fs.Frame("gantry_x"), Goal: goal}, ChainGoal{Frame: fs.Frame("gantry_y"), Goal: goal})
+	test.That(t, err, test.ShouldBeError, `chain "gantry_y" has no start configuration`)
+}
@@ -24,6 +24,53 @@ var (
 	errTooManyVals = errors.New("passed in too many joint positions")
 )
 
+// NloptOption configures an NloptIK.
+type NloptOption interface {
+	apply(*NloptIK)
+}
+
+// funcNloptOption wraps a function that modifies an NloptIK into an implementation of the NloptOption interface.
+type funcNloptOption struct {
+	f func(*NloptIK)
+}
+
+func (fno *funcNloptOption) apply(ik *NloptIK) {
+	fno.f(ik)
+}
+
+func newFuncNloptOption(f func(*NloptIK)) *funcNloptOption {
+	return &funcNloptOption{f: f}
+}
+
+// WithJointWeights returns an NloptOption which weights each joint by how much it should be kept from moving. Heavier
+// joints are first searched closer to the seed, and ranked solutions moving heavier joints less are preferred.
+func WithJointWeights(weights []float64) NloptOption {
+	return newFuncNloptOption(func(ik *NloptIK) {
+		ik.jointWeights = weights
+	})
+}
+
+// WithNullspaceObjective returns an NloptOption which adds a secondary objective, weighted by weight against the
+// others. Each solution reaching the goal is moved among the configurations reaching it to lower the objectives.
+func WithNullspaceObjective(objective NullspaceObjective, weight float64) NloptOption {
+	return newFuncNloptOption(func(ik *NloptIK) {
+		ik.nullspace = append(ik.nullspace, weightedObjective{objective: objective, weight: weight})
+	})
+}
+
+// WithRestartStrategy returns an NloptOption which restarts the optimization as the strategy says, returning the
+// solutions found ranked once all the restarts are done.
+func WithRestartStrategy(strategy RestartStrategy) NloptOption {
+	return newFuncNloptOption(func(ik *NloptIK) {
+		ik.restarts = &strategy
+	})
+}
+
+type weightedObjective struct {
+	objective NullspaceObjective
+	weight    float64
+}
+
 const (
 	constrainedTries  = 30
 	nloptStepsPerIter = 4001
@@ -48,6 +95,11 @@ type NloptIK struct {
 	// If true, this will terminate solving when nlopt alg iterations change the distance to goal by less than some proportion of calculated
 	// distance. This can cause premature terminations when the distances are large.
 	useRelTol bool
+
+	jointWeights []float64
+	nullspace    []weightedObjective
+	// If restarts is set, solutions are collected across restarts and sent ranked once they are done.
+	restarts *RestartStrategy
 }
 
 type optimizeReturn struct {
@@ -58,8 +110,14 @@ type optimizeReturn struct {
 
 // CreateNloptIKSolver creates an nloptIK object that can perform gradient descent on metrics for Frames. The parameters are the Frame on
 // which Transform() will be called, a logger, and the number of iterations to run. If the iteration count is less than 1, it will be set
-// to the default of 5000.
-func CreateNloptIKSolver(mdl referenceframe.Frame, logger logging.Logger, iter int, exact, useRelTol bool) (*NloptIK, error) {
+// to the default of 5000. Options may weight the joints, add secondary objectives, and restart the solver to rank its solutions.
+func CreateNloptIKSolver(
+	mdl referenceframe.Frame,
+	logger logging.Logger,
+	iter int,
+	exact, useRelTol bool,
+	opts ...NloptOption,
+) (*NloptIK, error) {
 	ik := &NloptIK{logger: logger}
 
 	ik.model = mdl
@@ -77,6 +135,23 @@ func CreateNloptIKSolver(mdl referenceframe.Frame, logger logging.Logger, iter i
 	ik.exact = exact
 	ik.useRelTol = useRelTol
 
+	for _, opt := range opts {
+		opt.apply(ik)
+	}
+	if ik.jointWeights != nil {
+		if len(ik.jointWeights) != len(mdl.DoF()) {
+			return nil, errors.Errorf("need a joint weight for each of the %d joints, not %d", len(mdl.DoF()), len(ik.jointWeights))
+		}
+		for _, weight := range ik.jointWeights {
+			if weight <= 0 {
+				return nil, errors.New("joint weights must be positive")
+			}
+		}
+	}
+	if ik.restarts != nil && ik.restarts.Restarts < 1 {
+		return nil, errors.New("a restart strategy must optimize at least once")
+	}
+
 	return ik, nil
 }
 
@@ -102,6 +177,7 @@ func (ik *NloptIK) Solve(ctx context.Context,
 	iterations := 0
 	solutionsFound := 0
 	startingPos := seed
+	var found []*Solution
 
 	opt, err := nlopt.NewNLopt(nlopt.LD_SLSQP, uint(len(ik.model.DoF())))
 	defer opt.Destroy()
@@ -241,20 +317,38 @@ func (ik *NloptIK) Solve(ctx context.Context,
 		}
 
 		if result < ik.epsilon || (solutionRaw != nil && !ik.exact) {
-			select {
-			case <-ctx.Done():
-				return err
-			default:
-			}
-			solutionChan <- &Solution{
+			solution := &Solution{
 				Configuration: referenceframe.FloatsToInputs(solutionRaw),
 				Score:         result,
 				Exact:         result < ik.epsilon,
 			}
-			solutionsFound++
+			if solution.Exact && len(ik.nullspace) > 0 {
+				solution.Configuration = ik.refineInNullspace(seed, solution.Configuration, solveMetric, jump)
+			}
+			if ik.restarts != nil {
+				found = append(found, solution)
+			} else {
+				select {
+				case <-ctx.Done():
+					return err
+				default:
+				}
+				solutionChan <- solution
+				solutionsFound++
+			}
 		}
 		tries++
-		if ik.id > 0 && tries < constrainedTries {
+		if ik.restarts != nil {
+			if tries > ik.restarts.Restarts {
+				break
+			}
+			err = multierr.Combine(
+				err,
+				opt.SetLowerBounds(ik.lowerBound),
+				opt.SetUpperBounds(ik.upperBound),
+			)
+			startingPos = ik.restartPosition(seed, randSeed)
+		} else if ik.id > 0 && tries < constrainedTries {
 			err = ik.updateBounds(seed, tries, opt)
 			if err != nil {
 				return err
@@ -270,12 +364,119 @@ func (ik *NloptIK) Solve(ctx context.Context,
 			startingPos = ik.GenerateRandomPositions(randSeed)
 		}
 	}
+	if ik.restarts != nil {
+		for _, solution := range rankSolutions(found, ik.rankCost(seed), ik.restarts.MaxSolutions) {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case solutionChan <- solution:
+			}
+			solutionsFound++
+		}
+	}
 	if solutionsFound > 0 {
 		return nil
 	}
 	return multierr.Combine(err, errNoSolve)
 }
 
+// restartPosition returns the position to restart the optimization from, near the seed if the restart strategy
+// perturbs it, and otherwise anywhere within the limits.
+func (ik *NloptIK) restartPosition(seed []referenceframe.Input, randSeed *rand.Rand) []referenceframe.Input {
+	if ik.restarts.Perturbation <= 0 || len(seed) != len(ik.lowerBound) {
+		return ik.GenerateRandomPositions(randSeed)
+	}
+	random := ik.GenerateRandomPositions(randSeed)
+	pos := make([]referenceframe.Input, len(seed))
+	for i, s := range seed {
+		// move the seed toward the random position by the perturbation, which stays within the limits
+		pos[i] = referenceframe.Input{Value: s.Value + ik.restarts.Perturbation*(random[i].Value-s.Value)}
+	}
+	return pos
+}
+
+// nullspaceCost returns the weighted sum of the secondary objectives at a state.
+func (ik *NloptIK) nullspaceCost(seed []referenceframe.Input, state *State) float64 {
+	cost := 0.
+	for _, o := range ik.nullspace {
+		cost += o.weight * o.objective(seed, state)
+	}
+	return cost
+}
+
+// rankCost returns the cost by which solutions are ranked, the secondary objectives if there are any, and otherwise
+// the weighted distance of the solution from the seed.
+func (ik *NloptIK) rankCost(seed []referenceframe.Input) func(*Solution) float64 {
+	return func(solution *Solution) float64 {
+		if len(ik.nullspace) == 0 {
+			return weightedSquaredDistance(seed, solution.Configuration, ik.jointWeights)
+		}
+		pos, err := ik.model.Transform(solution.Configuration)
+		if pos == nil || (err != nil && !strings.Contains(err.Error(), referenceframe.OOBErrString)) {
+			return math.Inf(1)
+		}
+		return ik.nullspaceCost(seed, &State{Frame: ik.model, Configuration: solution.Configuration, Position: pos})
+	}
+}
+
+// refineInNullspace lowers the secondary objectives at a solution while keeping it at the goal, returning the solution
+// unchanged if it cannot.
+func (ik *NloptIK) refineInNullspace(
+	seed, solution []referenceframe.Input,
+	solveMetric StateMetric,
+	jump []float64,
+) []referenceframe.Input {
+	opt, err := nlopt.NewNLopt(nlopt.LD_SLSQP, uint(len(ik.model.DoF())))
+	if err != nil {
+		return solution
+	}
+	defer opt.Destroy()
+
+	state := &State{Frame: ik.model}
+	evaluate := func(x []float64, metric StateMetric) float64 {
+		inputs := referenceframe.FloatsToInputs(x)
+		pos, err := ik.model.Transform(inputs)
+		if pos == nil || (err != nil && !strings.Contains(err.Error(), referenceframe.OOBErrString)) {
+			return math.Inf(1)
+		}
+		state.Configuration = inputs
+		state.Position = pos
+		return metric(state)
+	}
+	// withGradient makes a function for nlopt which estimates its gradient by finite differences.
+	withGradient := func(metric StateMetric) nlopt.Func {
+		return func(x, gradient []float64) float64 {
+			value := evaluate(x, metric)
+			moved := append([]float64{}, x...)
+			for i := range gradient {
+				moved[i] += jump[i]
+				gradient[i] = (evaluate(moved, metric) - value) / jump[i]
+				moved[i] = x[i]
+			}
+			return value
+		}
+	}
+	objective := func(state *State) float64 { return ik.nullspaceCost(seed, state) }
+	offGoal := func(state *State) float64 { return solveMetric(state) - ik.epsilon }
+
+	err = multierr.Combine(
+		opt.SetLowerBounds(ik.lowerBound),
+		opt.SetUpperBounds(ik.upperBound),
+		opt.SetMinObjective(withGradient(objective)),
+		opt.AddInequalityConstraint(withGradient(offGoal), ik.epsilon),
+		opt.SetXtolAbs1(ik.epsilon),
+		opt.SetMaxEval(nloptStepsPerIter),
+	)
+	if err != nil {
+		return solution
+	}
+	refined, _, err := opt.Optimize(referenceframe.InputsToFloats(solution))
+	if err != nil || len(refined) != len(solution) || evaluate(refined, solveMetric) >= ik.epsilon {
+		return solution
+	}
+	return referenceframe.FloatsToInputs(refined)
+}
+
 // GenerateRandomPositions generates a random set of positions within the limits of this solver.
 func (ik *NloptIK) GenerateRandomPositions(randSeed *rand.Rand) []referenceframe.Input {
 	pos := make([]referenceframe.Input, len(ik.model.DoF()))
@@ -316,8 +517,13 @@ func (ik *NloptIK) updateBounds(seed []referenceframe.Input, tries int, opt *nlo
 	newUpper := make([]float64, len(ik.upperBound))
 
 	for i, pos := range seed {
-		newLower[i] = math.Max(ik.lowerBound[i], pos.Value-(rangeStep*float64(tries*(i+1))))
-		newUpper[i] = math.Min(ik.upperBound[i], pos.Value+(rangeStep*float64(tries*(i+1))))
+		step := rangeStep * float64(tries*(i+1))
+		if ik.jointWeights != nil {
+			// heavier joints are searched closer to the seed
+			step /= ik.jointWeights[i]
+		}
+		newLower[i] = math.Max(ik.lowerBound[i], pos.Value-step)
+		newUpper[i] = math.Min(ik.upperBound[i], pos.Value+step)
 
 		// Allow full freedom of movement for the two most distal joints
 		if i > len(seed)-2 {
@@ -2,6 +2,8 @@ package ik
 
 import (
 	"context"
+	"math"
+	"math/rand"
 	"testing"
 
 	"github.com/golang/geo/r3"
@@ -38,3 +40,43 @@ func TestCreateNloptIKSolver(t *testing.T) {
 	_, err = solveTest(context.Background(), ik, pos, seed)
 	test.That(t, err, test.ShouldBeNil)
 }
+
+func TestCreateNloptIKSolverOptions(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	m, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/xarm/xarm6_kinematics.json"), "")
+	test.That(t, err, test.ShouldBeNil)
+
+	weights := []float64{4, 4, 2, 1, 1, 1}
+	strategy := RestartStrategy{Restarts: 5, Perturbation: 0.1, MaxSolutions: 3}
+	ik, err := CreateNloptIKSolver(m, logger, -1, true, true,
+		WithJointWeights(weights),
+		WithNullspaceObjective(NewStayNearSeedObjective(weights), 1),
+		WithNullspaceObjective(NewManipulabilityObjective(), 1e-6),
+		WithRestartStrategy(strategy),
+	)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ik.jointWeights, test.ShouldResemble, weights)
+	test.That(t, ik.nullspace, test.ShouldHaveLength, 2)
+	test.That(t, *ik.restarts, test.ShouldResemble, strategy)
+
+	// restarts stay within the limits, and near the seed
+	seed := referenceframe.FloatsToInputs([]float64{0, 0.5, -1, 0, 1, 0})
+	//nolint:gosec
+	pos := ik.restartPosition(seed, rand.New(rand.NewSource(1)))
+	for i, limit := range m.DoF() {
+		test.That(t, pos[i].Value, test.ShouldBeBetweenOrEqual, limit.Min, limit.Max)
+		test.That(t, math.Abs(pos[i].Value-seed[i].Value), test.ShouldBeLessThanOrEqualTo, 0.1*(limit.Max-limit.Min))
+	}
+
+	// solutions moving a heavier joint rank lower
+	weighted := ik.rankCost(seed)(&Solution{Configuration: referenceframe.FloatsToInputs([]float64{0.1, 0.5, -1, 0, 1, 0})})
+	light := ik.rankCost(seed)(&Solution{Configuration: referenceframe.FloatsToInputs([]float64{0, 0.5, -1, 0.1, 1, 0})})
+	test.That(t, weighted, test.ShouldBeGreaterThan, light)
+
+	_, err = CreateNloptIKSolver(m, logger, -1, true, true, WithJointWeights([]float64{1, 1}))
+	test.That(t, err, test.ShouldBeError, "need a joint weight for each of the 6 joints, not 2")
+	_, err = CreateNloptIKSolver(m, logger, -1, true, true, WithJointWeights([]float64{1, 1, 1, 0, 1, 1}))
+	test.That(t, err, test.ShouldBeError, "joint weights must be positive")
+	_, err = CreateNloptIKSolver(m, logger, -1, true, true, WithRestartStrategy(RestartStrategy{}))
+	test.That(t, err, test.ShouldBeError, "a restart strategy must optimize at least once")
+}
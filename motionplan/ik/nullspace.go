@@ -0,0 +1,164 @@
+package ik
+
+import (
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/referenceframe"
+	spatial "go.viam.com/rdk/spatialmath"
+)
+
+const (
+	// jacobianStep is the step by which each input is moved to estimate the Jacobian of a frame.
+	jacobianStep = 1e-6
+	// duplicateSolutionTolerance is how close every input of two solutions must be for them to be the same solution.
+	duplicateSolutionTolerance = 1e-4
+)
+
+// A NullspaceObjective scores a configuration which reaches the goal of a solve, lower being better. Given the seed the
+// solve started from, it is a secondary objective: solutions are moved among the configurations reaching the goal to
+// lower it, and ranked by it.
+type NullspaceObjective func(seed []referenceframe.Input, state *State) float64
+
+// RestartStrategy configures how a solver restarts its optimization from new starting positions, collecting the
+// solutions found from each and ranking them, rather than returning each local optimum as it is found.
+type RestartStrategy struct {
+	// Restarts is how many times to optimize, the first from the seed and the rest from new starting positions.
+	Restarts int
+	// Perturbation, when positive, starts each restart from the seed moved randomly by up to this fraction of the range
+	// of each input, to find solutions near the seed. Otherwise restarts start anywhere within the limits.
+	Perturbation float64
+	// MaxSolutions is how many of the best solutions to return, or all of them when zero.
+	MaxSolutions int
+}
+
+// NewStayNearSeedObjective returns an objective which is the squared distance of a configuration from the seed, the
+// distance of each input scaled by its weight, or by 1 when weights is nil.
+func NewStayNearSeedObjective(weights []float64) NullspaceObjective {
+	return func(seed []referenceframe.Input, state *State) float64 {
+		return weightedSquaredDistance(seed, state.Configuration, weights)
+	}
+}
+
+// NewManipulabilityObjective returns an objective which is the negative of the manipulability of the frame at a
+// configuration, the product of the singular values of its Jacobian, so that configurations far from singularities,
+// where the frame can move freely in every direction, score lower.
+func NewManipulabilityObjective() NullspaceObjective {
+	return func(seed []referenceframe.Input, state *State) float64 {
+		j, err := jacobian(state.Frame, state.Configuration)
+		if err != nil {
+			return math.Inf(1)
+		}
+		var svd mat.SVD
+		if !svd.Factorize(j, mat.SVDNone) {
+			return 0
+		}
+		manipulability := 1.
+		for _, value := range svd.Values(nil) {
+			manipulability *= value
+		}
+		return -manipulability
+	}
+}
+
+// jacobian estimates the Jacobian of a frame at a configuration by finite differences, with a row for each of the
+// translation, in mm, and rotation, in radians, of its pose, and a column for each input.
+func jacobian(frame referenceframe.Frame, config []referenceframe.Input) (*mat.Dense, error) {
+	pose, err := frame.Transform(config)
+	if err != nil {
+		return nil, err
+	}
+	j := mat.NewDense(6, len(config), nil)
+	moved := append([]referenceframe.Input{}, config...)
+	for i := range config {
+		moved[i].Value += jacobianStep
+		movedPose, err := frame.Transform(moved)
+		if err != nil {
+			return nil, err
+		}
+		moved[i].Value = config[i].Value
+		delta := spatial.PoseBetween(pose, movedPose)
+		// for so small a rotation, its axis scaled by its angle is twice the vector part of its quaternion
+		q := delta.Orientation().Quaternion()
+		if q.Real < 0 {
+			q.Imag, q.Jmag, q.Kmag = -q.Imag, -q.Jmag, -q.Kmag
+		}
+		for row, value := range []float64{
+			delta.Point().X, delta.Point().Y, delta.Point().Z, 2 * q.Imag, 2 * q.Jmag, 2 * q.Kmag,
+		} {
+			j.Set(row, i, value/jacobianStep)
+		}
+	}
+	return j, nil
+}
+
+// weightedSquaredDistance returns the squared distance between two configurations, the distance of each input scaled
+// by its weight, or by 1 when weights is nil.
+func weightedSquaredDistance(from, to []referenceframe.Input, weights []float64) float64 {
+	dist := 0.
+	for i := range from {
+		if i >= len(to) {
+			break
+		}
+		weight := 1.
+		if weights != nil {
+			weight = weights[i]
+		}
+		diff := to[i].Value - from[i].Value
+		dist += weight * diff * diff
+	}
+	return dist
+}
+
+// rankSolutions sorts solutions best first, those reaching the goal before those which do not, then by their cost, and
+// for those not reaching the goal by their score before their cost. Solutions the same as a better one are dropped,
+// and no more than maxSolutions are returned unless it is zero.
+func rankSolutions(solutions []*Solution, cost func(*Solution) float64, maxSolutions int) []*Solution {
+	costs := make(map[*Solution]float64, len(solutions))
+	for _, solution := range solutions {
+		costs[solution] = cost(solution)
+	}
+	sort.SliceStable(solutions, func(i, j int) bool {
+		a, b := solutions[i], solutions[j]
+		if a.Exact != b.Exact {
+			return a.Exact
+		}
+		if !a.Exact && a.Score != b.Score {
+			return a.Score < b.Score
+		}
+		return costs[a] < costs[b]
+	})
+
+	ranked := make([]*Solution, 0, len(solutions))
+	for _, solution := range solutions {
+		duplicate := false
+		for _, better := range ranked {
+			if sameConfiguration(solution.Configuration, better.Configuration) {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		ranked = append(ranked, solution)
+		if maxSolutions > 0 && len(ranked) == maxSolutions {
+			break
+		}
+	}
+	return ranked
+}
+
+func sameConfiguration(a, b []referenceframe.Input) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i].Value-b[i].Value) > duplicateSolutionTolerance {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package ik
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/utils"
+)
+
+func TestStayNearSeedObjective(t *testing.T) {
+	seed := referenceframe.FloatsToInputs([]float64{0, 0})
+	state := &State{Configuration: referenceframe.FloatsToInputs([]float64{1, 2})}
+	test.That(t, NewStayNearSeedObjective(nil)(seed, state), test.ShouldAlmostEqual, 5)
+	test.That(t, NewStayNearSeedObjective([]float64{3, 0.5})(seed, state), test.ShouldAlmostEqual, 5)
+}
+
+func TestManipulabilityObjective(t *testing.T) {
+	objective := NewManipulabilityObjective()
+
+	gantry, err := referenceframe.NewTranslationalFrame("gantry", r3.Vector{X: 1}, referenceframe.Limit{Min: -500, Max: 500})
+	test.That(t, err, test.ShouldBeNil)
+	state := &State{Frame: gantry, Configuration: referenceframe.FloatsToInputs([]float64{10})}
+	test.That(t, objective(nil, state), test.ShouldAlmostEqual, -1, 1e-6)
+
+	m, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/xarm/xarm6_kinematics.json"), "")
+	test.That(t, err, test.ShouldBeNil)
+	// near a straight fifth joint the fourth and sixth joints turn about nearly the same axis, a wrist singularity
+	nearSingular := objective(nil, &State{Frame: m, Configuration: referenceframe.FloatsToInputs([]float64{0, 0.5, -1, 0, 0, 0})})
+	bent := objective(nil, &State{Frame: m, Configuration: referenceframe.FloatsToInputs([]float64{0, 0.5, -1, 0, 1, 0})})
+	test.That(t, bent, test.ShouldBeLessThan, 0)
+	test.That(t, nearSingular, test.ShouldBeGreaterThan, bent/5)
+}
+
+func TestRankSolutions(t *testing.T) {
+	solution := func(exact bool, score float64, config ...float64) *Solution {
+		return &Solution{Configuration: referenceframe.FloatsToInputs(config), Score: score, Exact: exact}
+	}
+	far := solution(true, 0, 3)
+	near := solution(true, 0, 1)
+	nearAgain := solution(true, 0, 1+duplicateSolutionTolerance/2)
+	closeMiss := solution(false, 0.1, 0)
+	wideMiss := solution(false, 0.5, 2)
+	cost := func(s *Solution) float64 { return math.Abs(s.Configuration[0].Value) }
+
+	ranked := rankSolutions([]*Solution{wideMiss, far, closeMiss, nearAgain, near}, cost, 0)
+	test.That(t, ranked, test.ShouldResemble, []*Solution{near, far, closeMiss, wideMiss})
+
+	ranked = rankSolutions([]*Solution{wideMiss, far, closeMiss, near}, cost, 2)
+	test.That(t, ranked, test.ShouldResemble, []*Solution{near, far})
+}
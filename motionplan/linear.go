@@ -0,0 +1,184 @@
+//go:build !no_cgo
+
+package motionplan
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	pb "go.viam.com/api/service/motion/v1"
+	"google.golang.org/protobuf/proto"
+
+	"go.viam.com/rdk/motionplan/ik"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// LinearConstraint requires the end effector to move in a straight line from where it starts to its goal. The end effector must
+// stay within a tube around the line no wider than LineToleranceMm, with an orientation no further than OrientationToleranceDegs
+// from the orientation slerped between its start and goal orientations. A tolerance of 0 is the default tolerance.
+//
+// A linear move is planned in steps of "path_step_size" mm along the line. When no path within the tube can be found, planning
+// fails with a *LinearMoveError reporting how much of the line was planned and whether the goal can be reached by a free move.
+type LinearConstraint struct {
+	LineToleranceMm          float64
+	OrientationToleranceDegs float64
+}
+
+// NewLinearConstraintFromProtobuf converts a protobuf linear constraint into a LinearConstraint.
+func NewLinearConstraintFromProtobuf(pbConstraint *pb.LinearConstraint) *LinearConstraint {
+	return &LinearConstraint{
+		LineToleranceMm:          float64(pbConstraint.GetLineToleranceMm()),
+		OrientationToleranceDegs: float64(pbConstraint.GetOrientationToleranceDegs()),
+	}
+}
+
+// ToProtobuf converts a LinearConstraint into its protobuf representation.
+func (c *LinearConstraint) ToProtobuf() *pb.LinearConstraint {
+	lineTol := float32(c.LineToleranceMm)
+	orientTol := float32(c.OrientationToleranceDegs)
+	return &pb.LinearConstraint{LineToleranceMm: &lineTol, OrientationToleranceDegs: &orientTol}
+}
+
+// withDefaults returns the constraint with the default tolerance in place of any tolerance of 0.
+func (c LinearConstraint) withDefaults() LinearConstraint {
+	if c.LineToleranceMm == 0 {
+		c.LineToleranceMm = defaultLinearDeviation
+	}
+	if c.OrientationToleranceDegs == 0 {
+		c.OrientationToleranceDegs = defaultOrientationDeviation
+	}
+	return c
+}
+
+// addTo constrains the planner options to the tube around the line from `from` to `to`.
+func (c *LinearConstraint) addTo(opt *plannerOptions, from, to spatialmath.Pose) {
+	tube := c.withDefaults()
+	constraint, pathMetric := NewAbsoluteLinearInterpolatingConstraint(from, to, tube.LineToleranceMm, tube.OrientationToleranceDegs)
+	opt.AddStateConstraint(defaultLinearConstraintDesc, constraint)
+	opt.pathMetric = ik.CombineMetrics(opt.pathMetric, pathMetric)
+}
+
+// linearConstraintFromOptions returns the linear constraint asked for by the linear motion profile, whose tolerances are given by
+// the "line_tolerance" and "orient_tolerance" options, or nil if the options ask for another motion profile.
+func linearConstraintFromOptions(planningOpts map[string]interface{}) *LinearConstraint {
+	if profile, ok := planningOpts["motion_profile"]; !ok || profile != LinearMotionProfile {
+		return nil
+	}
+	linTol, _ := planningOpts["line_tolerance"].(float64)
+	orientTol, _ := planningOpts["orient_tolerance"].(float64)
+	return &LinearConstraint{LineToleranceMm: linTol, OrientationToleranceDegs: orientTol}
+}
+
+// withLinearConstraint returns the request with its LinearConstraint added to its constraint specs, so that it is planned for the
+// same way as a linear constraint sent over the API.
+func (req *PlanRequest) withLinearConstraint() *PlanRequest {
+	if req.LinearConstraint == nil {
+		return req
+	}
+	constraints := &pb.Constraints{}
+	if req.ConstraintSpecs != nil {
+		constraints = proto.Clone(req.ConstraintSpecs).(*pb.Constraints)
+	}
+	constraints.LinearConstraint = append(constraints.LinearConstraint, req.LinearConstraint.ToProtobuf())
+	withLinear := *req
+	withLinear.ConstraintSpecs = constraints
+	return &withLinear
+}
+
+// linearConstraint returns the linear constraint a request is planned with, whether given as a constraint spec or by the linear
+// motion profile, or nil when its motion need not be linear. Where there are several, the first is reported.
+func (req *PlanRequest) linearConstraint() *LinearConstraint {
+	if linearConstraints := req.ConstraintSpecs.GetLinearConstraint(); len(linearConstraints) > 0 {
+		return NewLinearConstraintFromProtobuf(linearConstraints[0])
+	}
+	return linearConstraintFromOptions(req.Options)
+}
+
+// LinearMoveError is returned when no path can be found which keeps the end effector within the tube of a LinearConstraint.
+type LinearMoveError struct {
+	// Constraint is the constraint which could not be met, with the default tolerances filled in.
+	Constraint LinearConstraint
+	// Progress is how much of the line, from 0 to 1, a path within the tube was found for before planning failed.
+	Progress float64
+	// Reached is the furthest pose along the line a path within the tube was found to.
+	Reached spatialmath.Pose
+	// GoalReachable is whether the goal can be reached when the end effector need not move in a straight line, so that a free move
+	// to it could be planned instead.
+	GoalReachable bool
+	// Err is why planning failed.
+	Err error
+}
+
+func (e *LinearMoveError) Error() string {
+	reachable := "the goal cannot be reached by a free move either"
+	if e.GoalReachable {
+		reachable = "the goal can be reached by a free move"
+	}
+	return fmt.Sprintf(
+		"no path stays within %.3fmm and %.2f degrees of the straight line to the goal, a path was found for %.0f%% of it "+
+			"and %s: %v",
+		e.Constraint.LineToleranceMm, e.Constraint.OrientationToleranceDegs, 100*e.Progress, reachable, e.Err,
+	)
+}
+
+func (e *LinearMoveError) Unwrap() error {
+	return e.Err
+}
+
+// newLinearMoveError reports on the failure to plan a linear move along the waypoints in goals, the first failed of which could
+// not be planned to.
+func (pm *planManager) newLinearMoveError(
+	ctx context.Context,
+	request *PlanRequest,
+	linear *LinearConstraint,
+	startPose spatialmath.Pose,
+	goals []spatialmath.Pose,
+	failed int,
+	seed []referenceframe.Input,
+	err error,
+) error {
+	reached := startPose
+	if failed > 0 {
+		reached = goals[failed-1]
+	}
+	return &LinearMoveError{
+		Constraint:    linear.withDefaults(),
+		Progress:      float64(failed) / float64(len(goals)),
+		Reached:       reached,
+		GoalReachable: pm.goalReachable(ctx, request, startPose, goals[len(goals)-1], seed),
+		Err:           err,
+	}
+}
+
+// goalReachable returns whether the goal of a request can be solved for, free of collisions, when the motion to it is not
+// constrained to a path.
+func (pm *planManager) goalReachable(
+	ctx context.Context,
+	request *PlanRequest,
+	startPose, goal spatialmath.Pose,
+	seed []referenceframe.Input,
+) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	planningOpts := deepAtomicCopyMap(request.Options)
+	delete(planningOpts, "motion_profile")
+	constraints := &pb.Constraints{CollisionSpecification: request.ConstraintSpecs.GetCollisionSpecification()}
+	opt, err := pm.plannerSetupFromMoveRequest(startPose, goal, request.StartConfiguration, request.WorldState, constraints, planningOpts)
+	if err != nil {
+		return false
+	}
+	opt.SetGoal(goal)
+	//nolint: gosec
+	pathPlanner, err := opt.PlannerConstructor(pm.frame, rand.New(rand.NewSource(int64(pm.randseed.Int()))), pm.logger, opt)
+	if err != nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(defaultFallbackTimeout*float64(time.Second)))
+	defer cancel()
+	_, err = pathPlanner.getSolutions(ctx, seed)
+	return err == nil
+}
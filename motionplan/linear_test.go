@@ -0,0 +1,98 @@
+package motionplan
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	frame "go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+)
+
+func TestLinearConstraintProtobuf(t *testing.T) {
+	linear := &LinearConstraint{LineToleranceMm: 0.5, OrientationToleranceDegs: 3}
+	test.That(t, NewLinearConstraintFromProtobuf(linear.ToProtobuf()), test.ShouldResemble, linear)
+
+	defaults := NewLinearConstraintFromProtobuf(nil).withDefaults()
+	test.That(t, defaults.LineToleranceMm, test.ShouldEqual, defaultLinearDeviation)
+	test.That(t, defaults.OrientationToleranceDegs, test.ShouldEqual, defaultOrientationDeviation)
+
+	test.That(t, linearConstraintFromOptions(map[string]interface{}{"motion_profile": FreeMotionProfile}), test.ShouldBeNil)
+	fromOptions := linearConstraintFromOptions(map[string]interface{}{"motion_profile": LinearMotionProfile, "line_tolerance": 0.5})
+	test.That(t, fromOptions, test.ShouldResemble, &LinearConstraint{LineToleranceMm: 0.5})
+}
+
+func TestLinearMove(t *testing.T) {
+	fs := frame.NewEmptyFrameSystem("")
+	gantryX, err := frame.NewTranslationalFrame("gantryX", r3.Vector{1, 0, 0}, frame.Limit{-500, 500})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(gantryX, fs.World()), test.ShouldBeNil)
+	gantryY, err := frame.NewTranslationalFrame("gantryY", r3.Vector{0, 1, 0}, frame.Limit{-500, 500})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(gantryY, gantryX), test.ShouldBeNil)
+	box, err := spatialmath.NewBox(spatialmath.NewZeroPose(), r3.Vector{10, 10, 10}, "")
+	test.That(t, err, test.ShouldBeNil)
+	head, err := frame.NewStaticFrameWithGeometry("head", spatialmath.NewZeroPose(), box)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(head, gantryY), test.ShouldBeNil)
+
+	start := frame.StartPositions(fs)
+	startPose := spatialmath.NewZeroPose()
+	goal := spatialmath.NewPoseFromPoint(r3.Vector{X: 100, Y: 50})
+	linear := &LinearConstraint{LineToleranceMm: 1}
+	// a gantry has only one solution for a pose, so there is no use looking for more
+	options := map[string]interface{}{"max_ik_solutions": 1, "smooth_iter": 5}
+
+	t.Run("stays in the tube", func(t *testing.T) {
+		plan, err := PlanMotion(context.Background(), &PlanRequest{
+			Logger:             logger,
+			Goal:               frame.NewPoseInFrame(frame.World, goal),
+			Frame:              head,
+			StartConfiguration: start,
+			FrameSystem:        fs,
+			LinearConstraint:   linear,
+			Options:            options,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		for _, step := range plan.Trajectory() {
+			pose, err := fs.Transform(step, frame.NewPoseInFrame(head.Name(), spatialmath.NewZeroPose()), frame.World)
+			test.That(t, err, test.ShouldBeNil)
+			point := pose.(*frame.PoseInFrame).Pose().Point()
+			closest := spatialmath.ClosestPointSegmentPoint(startPose.Point(), goal.Point(), point)
+			test.That(t, point.Distance(closest), test.ShouldBeLessThanOrEqualTo, linear.LineToleranceMm)
+		}
+	})
+
+	t.Run("reports where the line is blocked", func(t *testing.T) {
+		midpoint := spatialmath.Interpolate(startPose, goal, 0.5).Point()
+		obstacle, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(midpoint), r3.Vector{20, 20, 20}, "")
+		test.That(t, err, test.ShouldBeNil)
+		worldState, err := frame.NewWorldState(
+			[]*frame.GeometriesInFrame{frame.NewGeometriesInFrame(frame.World, []spatialmath.Geometry{obstacle})},
+			nil,
+		)
+		test.That(t, err, test.ShouldBeNil)
+
+		_, err = PlanMotion(context.Background(), &PlanRequest{
+			Logger:             logger,
+			Goal:               frame.NewPoseInFrame(frame.World, goal),
+			Frame:              head,
+			StartConfiguration: start,
+			FrameSystem:        fs,
+			WorldState:         worldState,
+			LinearConstraint:   linear,
+			Options:            options,
+		})
+		var linearErr *LinearMoveError
+		test.That(t, errors.As(err, &linearErr), test.ShouldBeTrue)
+		test.That(t, linearErr.Constraint.LineToleranceMm, test.ShouldEqual, 1)
+		test.That(t, linearErr.Constraint.OrientationToleranceDegs, test.ShouldEqual, defaultOrientationDeviation)
+		test.That(t, linearErr.Progress, test.ShouldBeGreaterThan, 0)
+		test.That(t, linearErr.Progress, test.ShouldBeLessThan, 0.5)
+		test.That(t, linearErr.GoalReachable, test.ShouldBeTrue)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "the goal can be reached by a free move")
+	})
+}
@@ -50,7 +50,10 @@ type PlanRequest struct {
 	StartConfiguration map[string][]frame.Input
 	WorldState         *frame.WorldState
 	ConstraintSpecs    *pb.Constraints
-	Options            map[string]interface{}
+	// LinearConstraint, when set, requires the end effector to move in a straight line to the goal. It is planned for the same way
+	// as a linear constraint in ConstraintSpecs.
+	LinearConstraint *LinearConstraint
+	Options          map[string]interface{}
 }
 
 // validatePlanRequest ensures PlanRequests are not malformed.
@@ -139,6 +142,7 @@ func Replan(ctx context.Context, request *PlanRequest, currentPlan Plan, replanC
 	if err := request.validatePlanRequest(); err != nil {
 		return nil, err
 	}
+	request = request.withLinearConstraint()
 
 	// Create a frame to solve for, and an IK solver with that frame.
 	sf, err := newSolverFrame(request.FrameSystem, request.Frame.Name(), request.Goal.Parent(), request.StartConfiguration)
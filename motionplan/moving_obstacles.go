@@ -0,0 +1,143 @@
+package motionplan
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/referenceframe"
+)
+
+// defaultMovingObstacleResolution is how often a trajectory is checked against moving obstacles between its steps.
+const defaultMovingObstacleResolution = 50 * time.Millisecond
+
+// CheckMovingObstacles checks the frames moved along a trajectory against the moving obstacles of a world state, with
+// the steps of the trajectory reached at the given times after it begins. Between steps the inputs are interpolated
+// and checked every resolution, or every 50ms when it is zero, against where the obstacles are predicted to be at
+// that time. Static obstacles are not checked, as the planner has already avoided them. The first collision found is
+// returned as an error naming the geometries in collision and when they collide.
+func CheckMovingObstacles(
+	fs referenceframe.FrameSystem,
+	traj Trajectory,
+	times []time.Duration,
+	worldState *referenceframe.WorldState,
+	resolution time.Duration,
+	collisionBufferMM float64,
+) error {
+	if len(traj) != len(times) {
+		return errors.Errorf("a trajectory of %d steps needs as many times, not %d", len(traj), len(times))
+	}
+	if len(traj) == 0 || len(worldState.MovingObstacles()) == 0 {
+		return nil
+	}
+	if resolution <= 0 {
+		resolution = defaultMovingObstacleResolution
+	}
+	moving, err := framesMovedBy(fs, traj[0])
+	if err != nil {
+		return err
+	}
+
+	check := func(inputs map[string][]referenceframe.Input, t time.Duration) error {
+		obstacles, err := worldState.MovingObstaclesInWorldFrame(fs, inputs, t)
+		if err != nil {
+			return err
+		}
+		geometries, err := referenceframe.FrameSystemGeometries(fs, inputs)
+		if err != nil {
+			return err
+		}
+		for name, gif := range geometries {
+			if !moving[name] {
+				continue
+			}
+			for _, geometry := range gif.Geometries() {
+				for _, obstacle := range obstacles.Geometries() {
+					collides, err := geometry.CollidesWith(obstacle, collisionBufferMM)
+					if err != nil {
+						return err
+					}
+					if collides {
+						return errors.Errorf("%q collides with moving obstacle %q %s after the trajectory begins",
+							geometry.Label(), obstacle.Label(), t)
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	inputs := referenceframe.StartPositions(fs)
+	stepInputs := func(step map[string][]referenceframe.Input) map[string][]referenceframe.Input {
+		for name, frameInputs := range step {
+			inputs[name] = frameInputs
+		}
+		return inputs
+	}
+	if err := check(stepInputs(traj[0]), times[0]); err != nil {
+		return err
+	}
+	for i := 1; i < len(traj); i++ {
+		from, to := traj[i-1], traj[i]
+		span := times[i] - times[i-1]
+		if span < 0 {
+			return errors.New("the times of a trajectory cannot go backwards")
+		}
+		for t := resolution; t < span; t += resolution {
+			by := float64(t) / float64(span)
+			for name, toInputs := range to {
+				fromInputs, ok := from[name]
+				if !ok {
+					continue
+				}
+				interpolated, err := fs.Frame(name).Interpolate(fromInputs, toInputs, by)
+				if err != nil {
+					return err
+				}
+				inputs[name] = interpolated
+			}
+			if err := check(inputs, times[i-1]+t); err != nil {
+				return err
+			}
+		}
+		if err := check(stepInputs(to), times[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// framesMovedBy returns the names of the frames moved by the inputs of a step of a trajectory, being those with
+// inputs and the frames attached to them.
+func framesMovedBy(fs referenceframe.FrameSystem, step map[string][]referenceframe.Input) (map[string]bool, error) {
+	moving := map[string]bool{}
+	for _, name := range fs.FrameNames() {
+		frame := fs.Frame(name)
+		if frame == nil {
+			continue
+		}
+		parents, err := fs.TracebackFrame(frame)
+		if err != nil {
+			return nil, err
+		}
+		for _, parent := range parents {
+			if len(step[parent.Name()]) > 0 {
+				moving[name] = true
+				break
+			}
+		}
+	}
+	return moving, nil
+}
+
+// Steps returns the inputs of a frame along the trajectory as a Trajectory, and the times they are reached, so that
+// the timed trajectory can be checked against moving obstacles.
+func (traj TimedTrajectory) Steps(frameName string) (Trajectory, []time.Duration) {
+	steps := make(Trajectory, 0, len(traj))
+	times := make([]time.Duration, 0, len(traj))
+	for _, waypoint := range traj {
+		steps = append(steps, map[string][]referenceframe.Input{frameName: waypoint.Inputs})
+		times = append(times, waypoint.Time)
+	}
+	return steps, times
+}
@@ -0,0 +1,78 @@
+package motionplan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	frame "go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+)
+
+func TestCheckMovingObstacles(t *testing.T) {
+	fs := frame.NewEmptyFrameSystem("")
+	gantry, err := frame.NewTranslationalFrame("gantry", r3.Vector{1, 0, 0}, frame.Limit{-500, 500})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(gantry, fs.World()), test.ShouldBeNil)
+	headBox, err := spatialmath.NewBox(spatialmath.NewZeroPose(), r3.Vector{20, 20, 20}, "head")
+	test.That(t, err, test.ShouldBeNil)
+	head, err := frame.NewStaticFrameWithGeometry("head", spatialmath.NewZeroPose(), headBox)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(head, gantry), test.ShouldBeNil)
+	// a fixture which does not move with the gantry
+	fixtureBox, err := spatialmath.NewBox(spatialmath.NewZeroPose(), r3.Vector{20, 20, 20}, "fixture")
+	test.That(t, err, test.ShouldBeNil)
+	fixture, err := frame.NewStaticFrameWithGeometry("fixture", spatialmath.NewPoseFromPoint(r3.Vector{X: 200, Y: 100}), fixtureBox)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(fixture, fs.World()), test.ShouldBeNil)
+
+	// the head moves along x from 0 to 400 in 4s
+	traj := Trajectory{{"gantry": {{0}}}, {"gantry": {{400}}}}
+	times := []time.Duration{0, 4 * time.Second}
+
+	part, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(r3.Vector{X: 200, Y: -200}), r3.Vector{20, 20, 20}, "part")
+	test.That(t, err, test.ShouldBeNil)
+
+	t.Run("a part crossing the path as the head passes", func(t *testing.T) {
+		// the part crosses y=0 at x=200 after 2s, when the head is there
+		ws, err := frame.NewEmptyWorldState().WithMovingObstacles(frame.NewMovingObstacle(frame.World, part, r3.Vector{Y: 100}))
+		test.That(t, err, test.ShouldBeNil)
+		err = CheckMovingObstacles(fs, traj, times, ws, 0, 0)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `"head" collides with moving obstacle "part"`)
+
+		// the part crossing the path twice as fast has passed by then
+		ws, err = frame.NewEmptyWorldState().WithMovingObstacles(frame.NewMovingObstacle(frame.World, part, r3.Vector{Y: 200}))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, CheckMovingObstacles(fs, traj, times, ws, 0, 0), test.ShouldBeNil)
+	})
+
+	t.Run("a part crossing the path after the head has passed", func(t *testing.T) {
+		// the part waits until 3s, when the head is at x=300, then crosses the path, and the fixture, by 4s
+		crossing, err := frame.NewTimedObstacle(frame.World, part, []frame.TimedPose{
+			{Time: 3 * time.Second, Pose: spatialmath.NewPoseFromPoint(r3.Vector{X: 200, Y: -200})},
+			{Time: 4 * time.Second, Pose: spatialmath.NewPoseFromPoint(r3.Vector{X: 200, Y: 200})},
+		})
+		test.That(t, err, test.ShouldBeNil)
+		ws, err := frame.NewEmptyWorldState().WithMovingObstacles(crossing)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, CheckMovingObstacles(fs, traj, times, ws, 10*time.Millisecond, 0), test.ShouldBeNil)
+
+		// but not with a buffer wider than the gap between them
+		err = CheckMovingObstacles(fs, traj, times, ws, 10*time.Millisecond, 150)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("a timed trajectory", func(t *testing.T) {
+		timed := TimedTrajectory{
+			{Time: 0, Inputs: []frame.Input{{0}}},
+			{Time: 4 * time.Second, Inputs: []frame.Input{{400}}},
+		}
+		steps, stepTimes := timed.Steps("gantry")
+		test.That(t, steps, test.ShouldResemble, traj)
+		test.That(t, stepTimes, test.ShouldResemble, times)
+		test.That(t, CheckMovingObstacles(fs, steps, stepTimes[:1], nil, 0, 0), test.ShouldNotBeNil)
+	})
+}
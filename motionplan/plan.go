@@ -209,12 +209,13 @@ func PathStepFromProto(ps *pb.PlanStep) (PathStep, error) {
 // A Point with X as the longitude and Y as the latitude
 // An orientation using the heading as the theta in an OrientationVector with Z=1.
 func NewGeoPlan(plan Plan, pt *geo.Point) Plan {
+	plane := spatialmath.NewLocalTangentPlane(pt, 0)
 	newPath := make([]PathStep, 0, len(plan.Path()))
 	for _, step := range plan.Path() {
 		newStep := make(PathStep)
 		for frame, pif := range step {
 			pose := pif.Pose()
-			geoPose := spatialmath.PoseToGeoPose(spatialmath.NewGeoPose(pt, 0), pose)
+			geoPose, _ := plane.PoseToGeoPose(pose)
 			heading := math.Mod(math.Abs(geoPose.Heading()-360), 360)
 			o := &spatialmath.OrientationVectorDegrees{OZ: 1, Theta: heading}
 			smuggledGeoPose := spatialmath.NewPose(r3.Vector{X: geoPose.Location().Lng(), Y: geoPose.Location().Lat()}, o)
@@ -103,22 +103,16 @@ func (pm *planManager) PlanSingleWaypoint(ctx context.Context, request *PlanRequ
 	var goals []spatialmath.Pose
 	var opts []*plannerOptions
 
-	subWaypoints := false
-
-	// linear motion profile has known intermediate points, so solving can be broken up and sped up
-	if profile, ok := request.Options["motion_profile"]; ok && profile == LinearMotionProfile {
-		subWaypoints = true
-	}
-
-	if len(request.ConstraintSpecs.GetLinearConstraint()) > 0 {
-		subWaypoints = true
-	}
+	// linear motion has known intermediate points, so solving can be broken up and sped up
+	linear := request.linearConstraint()
+	subWaypoints := linear != nil
 
 	// If we are seeding off of a pre-existing plan, we don't need the speedup of subwaypoints
 	if seedPlan != nil {
 		subWaypoints = false
 	}
 
+	lineStart := startPose
 	if subWaypoints {
 		pathStepSize, ok := request.Options["path_step_size"].(float64)
 		if !ok {
@@ -187,6 +181,9 @@ func (pm *planManager) PlanSingleWaypoint(ctx context.Context, request *PlanRequ
 		// Viability check; ensure that the waypoint is not impossible to reach
 		_, err = planners[0].getSolutions(ctx, seed)
 		if err != nil {
+			if linear != nil {
+				return nil, pm.newLinearMoveError(ctx, request, linear, lineStart, goals, 0, seed, err)
+			}
 			return nil, err
 		}
 	}
@@ -194,6 +191,14 @@ func (pm *planManager) PlanSingleWaypoint(ctx context.Context, request *PlanRequ
 	plan, err := pm.planAtomicWaypoints(ctx, goals, seed, planners, seedPlan)
 	pm.activeBackgroundWorkers.Wait()
 	if err != nil {
+		failed := 0
+		var waypointErr *waypointError
+		if errors.As(err, &waypointErr) {
+			failed, err = waypointErr.index, waypointErr.err
+		}
+		if linear != nil {
+			return nil, pm.newLinearMoveError(ctx, request, linear, lineStart, goals, failed, seed, err)
+		}
 		if len(goals) > 1 {
 			err = fmt.Errorf("failed to plan path for valid goal: %w", err)
 		}
@@ -202,6 +207,20 @@ func (pm *planManager) PlanSingleWaypoint(ctx context.Context, request *PlanRequ
 	return plan, nil
 }
 
+// waypointError is an error planning to one of the waypoints of a motion, recording which one.
+type waypointError struct {
+	index int
+	err   error
+}
+
+func (e *waypointError) Error() string {
+	return e.err.Error()
+}
+
+func (e *waypointError) Unwrap() error {
+	return e.err
+}
+
 // planAtomicWaypoints will plan a single motion, which may be composed of one or more waypoints. Waypoints are here used to begin planning
 // the next motion as soon as its starting point is known. This is responsible for repeatedly calling planSingleAtomicWaypoint for each
 // intermediate waypoint. Waypoints here refer to points that the software has generated to.
@@ -238,7 +257,7 @@ func (pm *planManager) planAtomicWaypoints(
 		// Plan the single waypoint, and accumulate objects which will be used to constrauct the plan after all planning has finished
 		newseed, future, err := pm.planSingleAtomicWaypoint(ctx, goal, seed, pathPlanner, maps)
 		if err != nil {
-			return nil, err
+			return nil, &waypointError{index: i, err: err}
 		}
 		seed = newseed
 		resultPromises = append(resultPromises, future)
@@ -246,10 +265,10 @@ func (pm *planManager) planAtomicWaypoints(
 
 	// All goals have been submitted for solving. Reconstruct in order
 	resultSlices := []node{}
-	for _, future := range resultPromises {
+	for i, future := range resultPromises {
 		steps, err := future.result()
 		if err != nil {
-			return nil, err
+			return nil, &waypointError{index: i, err: err}
 		}
 		resultSlices = append(resultSlices, steps...)
 	}
@@ -599,6 +618,19 @@ func (pm *planManager) plannerSetupFromMoveRequest(
 			opt.PlannerConstructor = newRRTStarConnectMotionPlanner
 			// TODO(pl): more logic for RRT*?
 			return opt, nil
+		case "prm", "lazyprm":
+			// a roadmap is valid for free motion among the obstacles it was built for, not for the constraints of a single motion
+			if profile, ok := planningOpts["motion_profile"]; hasTopoConstraint || (ok && profile != FreeMotionProfile) {
+				return nil, fmt.Errorf("%s can only plan free motion, without a motion profile or constraints", planAlg)
+			}
+			opt.PlannerConstructor = newPRMMotionPlanner
+			opt.roadmapKey, err = roadmapKey(
+				pm.frame, staticRobotGeometries, worldGeometries.Geometries(), allowedCollisions, collisionBufferMM,
+			)
+			if err != nil {
+				return nil, err
+			}
+			return opt, nil
 		default:
 			// use default, already set
 		}
@@ -619,20 +651,7 @@ func (pm *planManager) plannerSetupFromMoveRequest(
 	switch motionProfile {
 	case LinearMotionProfile:
 		opt.profile = LinearMotionProfile
-		// Linear constraints
-		linTol, ok := planningOpts["line_tolerance"].(float64)
-		if !ok {
-			// Default
-			linTol = defaultLinearDeviation
-		}
-		orientTol, ok := planningOpts["orient_tolerance"].(float64)
-		if !ok {
-			// Default
-			orientTol = defaultOrientationDeviation
-		}
-		constraint, pathMetric := NewAbsoluteLinearInterpolatingConstraint(from, to, linTol, orientTol)
-		opt.AddStateConstraint(defaultLinearConstraintDesc, constraint)
-		opt.pathMetric = pathMetric
+		linearConstraintFromOptions(planningOpts).addTo(opt, from, to)
 	case PseudolinearMotionProfile:
 		opt.profile = PseudolinearMotionProfile
 		tolerance, ok := planningOpts["tolerance"].(float64)
@@ -191,6 +191,10 @@ type plannerOptions struct {
 	// relativeInputs is a flag that is set by the planning algorithm describing if the solutions it generates are
 	// relative as in each step in the solution builds off a previous one, as opposed to being asolute with respect to some reference frame.
 	relativeInputs bool
+
+	// roadmapKey identifies the frame system and obstacles a roadmap planner plans among, so that its roadmap is rebuilt when they
+	// change.
+	roadmapKey string
 }
 
 // SetMetric sets the distance metric for the solver.
@@ -229,20 +233,7 @@ func (p *plannerOptions) addPbTopoConstraints(from, to spatialmath.Pose, constra
 }
 
 func (p *plannerOptions) addPbLinearConstraints(from, to spatialmath.Pose, pbConstraint *pb.LinearConstraint) {
-	// Linear constraints
-	linTol := pbConstraint.GetLineToleranceMm()
-	if linTol == 0 {
-		// Default
-		linTol = defaultLinearDeviation
-	}
-	orientTol := pbConstraint.GetOrientationToleranceDegs()
-	if orientTol == 0 {
-		orientTol = defaultOrientationDeviation
-	}
-	constraint, pathDist := NewAbsoluteLinearInterpolatingConstraint(from, to, float64(linTol), float64(orientTol))
-	p.AddStateConstraint(defaultLinearConstraintDesc, constraint)
-
-	p.pathMetric = ik.CombineMetrics(p.pathMetric, pathDist)
+	NewLinearConstraintFromProtobuf(pbConstraint).addTo(p, from, to)
 }
 
 func (p *plannerOptions) addPbOrientationConstraints(from, to spatialmath.Pose, pbConstraint *pb.OrientationConstraint) {
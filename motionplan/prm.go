@@ -0,0 +1,619 @@
+//go:build !no_cgo
+
+package motionplan
+
+import (
+	"container/heap"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan/ik"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+)
+
+const (
+	// Number of valid configurations sampled to build a roadmap, and to grow it by when a query cannot be connected through it.
+	defaultRoadmapSamples = 500
+
+	// Number of nearest configurations each configuration in a roadmap is connected to.
+	defaultRoadmapNeighbors = 8
+
+	// Number of times a roadmap is grown before a query through it fails.
+	defaultRoadmapGrowths = 2
+
+	// Number of samples which may be drawn for each valid configuration before a roadmap stops growing.
+	maxRoadmapSamplesPerNode = 20
+
+	// Number of roadmaps kept in memory, the oldest of which is dropped when another is built or loaded.
+	maxCachedRoadmaps = 4
+)
+
+type prmOptions struct {
+	// File to save the roadmap to and load it from, so that processes planning in the same workcell can share it.
+	RoadmapFile string `json:"roadmap_file"`
+
+	// Number of valid configurations to build the roadmap from
+	RoadmapSamples int `json:"roadmap_samples"`
+
+	// Number of nearest configurations to connect each configuration to
+	RoadmapNeighbors int `json:"roadmap_neighbors"`
+
+	// "lazyprm" leaves the edges of the roadmap unchecked until a query's path runs along them
+	PlanningAlg string `json:"planning_alg"`
+}
+
+// newPRMOptions creates a struct controlling the running of a single invocation of the algorithm.
+// All values are pre-set to reasonable defaults, but can be tweaked if needed.
+func newPRMOptions(planOpts *plannerOptions) (*prmOptions, error) {
+	algOpts := &prmOptions{
+		RoadmapSamples:   defaultRoadmapSamples,
+		RoadmapNeighbors: defaultRoadmapNeighbors,
+	}
+	// convert map to json
+	jsonString, err := json.Marshal(planOpts.extra)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(jsonString, algOpts)
+	if err != nil {
+		return nil, err
+	}
+	if algOpts.RoadmapSamples <= 0 || algOpts.RoadmapNeighbors <= 0 {
+		return nil, errors.New("roadmap_samples and roadmap_neighbors must be positive")
+	}
+	return algOpts, nil
+}
+
+func (o *prmOptions) lazy() bool {
+	return o.PlanningAlg == "lazyprm"
+}
+
+// prmMotionPlanner plans through a probabilistic roadmap of valid configurations, Kavraki et al 1996
+// https://ieeexplore.ieee.org/document/508439
+// The roadmap is built once for a frame system and set of obstacles, and reused by every plan for them, in memory and, given a
+// roadmap_file, by other processes. Lazy PRM, Bohlin and Kavraki 2000, defers collision checking the edges of the roadmap until a
+// path is found along them.
+// https://ieeexplore.ieee.org/document/844107
+type prmMotionPlanner struct {
+	*planner
+	algOpts *prmOptions
+}
+
+// newPRMMotionPlanner creates a prmMotionPlanner object with a user specified random seed.
+func newPRMMotionPlanner(
+	frame referenceframe.Frame,
+	seed *rand.Rand,
+	logger logging.Logger,
+	opt *plannerOptions,
+) (motionPlanner, error) {
+	if opt == nil {
+		return nil, errNoPlannerOptions
+	}
+	if opt.roadmapKey == "" {
+		return nil, errors.New("a roadmap can only be planned through for the frame system it was built for")
+	}
+	mp, err := newPlanner(frame, seed, logger, opt)
+	if err != nil {
+		return nil, err
+	}
+	algOpts, err := newPRMOptions(opt)
+	if err != nil {
+		return nil, err
+	}
+	return &prmMotionPlanner{mp, algOpts}, nil
+}
+
+func (mp *prmMotionPlanner) plan(ctx context.Context, goal spatialmath.Pose, seed []referenceframe.Input) ([]node, error) {
+	mp.planOpts.SetGoal(goal)
+	mp.start = time.Now()
+
+	solutions, err := mp.getSolutions(ctx, seed)
+	if err != nil {
+		return nil, err
+	}
+	goals := make([][]referenceframe.Input, 0, len(solutions))
+	for _, solution := range solutions {
+		goals = append(goals, solution.Q())
+	}
+
+	rm, err := mp.roadmap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mp.planThrough(ctx, rm, seed, goals)
+}
+
+// planThrough finds a path from the seed to the nearest of the goals through the roadmap, growing the roadmap when there is none.
+func (mp *prmMotionPlanner) planThrough(
+	ctx context.Context,
+	rm *roadmap,
+	seed []referenceframe.Input,
+	goals [][]referenceframe.Input,
+) ([]node, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	// edges checked along the way are worth keeping whether or not a path is found
+	defer mp.save(ctx, rm)
+
+	for growth := 0; ; growth++ {
+		path, err := mp.query(ctx, rm, seed, goals)
+		if err != nil {
+			return nil, err
+		}
+		if path != nil {
+			mp.logger.CDebugf(ctx, "PRM found a path of %d steps through a roadmap of %d configurations", len(path), len(rm.Nodes))
+			return path, nil
+		}
+		if growth == defaultRoadmapGrowths {
+			return nil, errPlannerFailed
+		}
+		mp.logger.CDebugf(ctx, "PRM found no path, growing the roadmap by %d configurations", mp.algOpts.RoadmapSamples)
+		if err := mp.grow(ctx, rm, mp.algOpts.RoadmapSamples); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// roadmap returns the roadmap for the frame system and obstacles being planned for, loading or building it if it is not in memory.
+func (mp *prmMotionPlanner) roadmap(ctx context.Context) (*roadmap, error) {
+	roadmaps.mu.Lock()
+	defer roadmaps.mu.Unlock()
+	key := mp.planOpts.roadmapKey
+	if rm, ok := roadmaps.byKey[key]; ok {
+		return rm, nil
+	}
+	if file := mp.algOpts.RoadmapFile; file != "" {
+		rm, err := loadRoadmap(file)
+		switch {
+		case err == nil && rm.Key == key && len(rm.Nodes) > 0 && len(rm.Nodes[0]) == len(mp.frame.DoF()):
+			mp.logger.CDebugf(ctx, "loaded roadmap of %d configurations from %q", len(rm.Nodes), file)
+			roadmaps.add(rm)
+			return rm, nil
+		case err == nil:
+			mp.logger.CInfof(ctx, "roadmap in %q was built for another frame system or set of obstacles, rebuilding it", file)
+		case !errors.Is(err, os.ErrNotExist):
+			mp.logger.CWarnw(ctx, "cannot load roadmap, rebuilding it", "file", file, "error", err)
+		}
+	}
+
+	rm := &roadmap{Key: key}
+	mp.logger.CDebugf(ctx, "building roadmap of %d configurations", mp.algOpts.RoadmapSamples)
+	if err := mp.grow(ctx, rm, mp.algOpts.RoadmapSamples); err != nil {
+		return nil, err
+	}
+	roadmaps.add(rm)
+	mp.save(ctx, rm)
+	return rm, nil
+}
+
+// grow adds n valid configurations to the roadmap, connecting each to its nearest neighbors.
+func (mp *prmMotionPlanner) grow(ctx context.Context, rm *roadmap, n int) error {
+	for added, sampled := 0, 0; added < n && sampled < n*maxRoadmapSamplesPerNode; sampled++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		q := referenceframe.RandomFrameInputs(mp.frame, mp.randseed)
+		if !mp.checkInputs(q) {
+			continue
+		}
+		neighbors := rm.nearest(mp.planOpts, q, mp.algOpts.RoadmapNeighbors)
+		i := rm.add(q)
+		for _, j := range neighbors {
+			switch {
+			case mp.algOpts.lazy():
+				rm.connect(i, j, false)
+			case mp.checkPath(q, rm.inputs(j)):
+				rm.connect(i, j, true)
+			}
+		}
+		added++
+	}
+	return nil
+}
+
+// query returns the shortest path through the roadmap from the seed to one of the goals, or nil if there is none. The seed and goals
+// are connected to their nearest neighbors for the query only, and any edge of the path found which is invalid is removed from the
+// roadmap and another path looked for.
+func (mp *prmMotionPlanner) query(
+	ctx context.Context,
+	rm *roadmap,
+	seed []referenceframe.Input,
+	goals [][]referenceframe.Input,
+) ([]node, error) {
+	g := newQueryGraph(rm, seed, goals)
+	for _, v := range append([]int{g.start}, g.goals...) {
+		for _, j := range rm.nearest(mp.planOpts, g.inputs(v), mp.algOpts.RoadmapNeighbors) {
+			g.connect(v, j)
+		}
+	}
+	for _, goal := range g.goals {
+		g.connect(g.start, goal)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		path := g.shortestPath(mp.planOpts.DistanceFunc)
+		if path == nil {
+			return nil, nil
+		}
+		valid := true
+		for k := 1; k < len(path) && valid; k++ {
+			u, v := path[k-1], path[k]
+			if g.checked(u, v) {
+				continue
+			}
+			if valid = mp.checkPath(g.inputs(u), g.inputs(v)); valid {
+				g.check(u, v)
+			} else {
+				g.disconnect(u, v)
+			}
+		}
+		if !valid {
+			continue
+		}
+		steps := make([]node, 0, len(path))
+		cost := 0.
+		for k, v := range path {
+			if k > 0 {
+				cost += mp.planOpts.DistanceFunc(&ik.Segment{StartConfiguration: g.inputs(path[k-1]), EndConfiguration: g.inputs(v)})
+			}
+			steps = append(steps, &basicNode{q: g.inputs(v), cost: cost})
+		}
+		return steps, nil
+	}
+}
+
+// save writes the roadmap to the roadmap file, if there is one.
+func (mp *prmMotionPlanner) save(ctx context.Context, rm *roadmap) {
+	if mp.algOpts.RoadmapFile == "" {
+		return
+	}
+	if err := rm.save(mp.algOpts.RoadmapFile); err != nil {
+		mp.logger.CWarnw(ctx, "cannot save roadmap", "file", mp.algOpts.RoadmapFile, "error", err)
+	}
+}
+
+// roadmaps holds the roadmaps built or loaded by this process.
+var roadmaps = &roadmapCache{byKey: map[string]*roadmap{}}
+
+// roadmapCache holds roadmaps by the key of the frame system and obstacles they were built for.
+type roadmapCache struct {
+	mu    sync.Mutex
+	byKey map[string]*roadmap
+	// keys are the keys of the roadmaps, oldest first
+	keys []string
+}
+
+// add adds a roadmap to the cache, dropping the oldest if the cache is full. The cache must be locked.
+func (c *roadmapCache) add(rm *roadmap) {
+	if len(c.keys) == maxCachedRoadmaps {
+		delete(c.byKey, c.keys[0])
+		c.keys = c.keys[1:]
+	}
+	c.byKey[rm.Key] = rm
+	c.keys = append(c.keys, rm.Key)
+}
+
+// A roadmap is a graph of valid configurations, whose edges are motions between them.
+type roadmap struct {
+	mu sync.Mutex
+	// Key is the roadmapKey of the frame system and obstacles the roadmap was built for.
+	Key   string      `json:"key"`
+	Nodes [][]float64 `json:"nodes"`
+	// Edges are only filled in to be saved; adj holds the edges of each node, and whether each has been checked to be valid.
+	Edges []roadmapEdge `json:"edges"`
+	adj   []map[int]bool
+}
+
+type roadmapEdge struct {
+	From    int  `json:"from"`
+	To      int  `json:"to"`
+	Checked bool `json:"checked"`
+}
+
+func (rm *roadmap) add(q []referenceframe.Input) int {
+	rm.Nodes = append(rm.Nodes, referenceframe.InputsToFloats(q))
+	rm.adj = append(rm.adj, map[int]bool{})
+	return len(rm.Nodes) - 1
+}
+
+func (rm *roadmap) inputs(i int) []referenceframe.Input {
+	return referenceframe.FloatsToInputs(rm.Nodes[i])
+}
+
+func (rm *roadmap) connect(i, j int, checked bool) {
+	rm.adj[i][j] = checked
+	rm.adj[j][i] = checked
+}
+
+// nearest returns the indices of the k nodes nearest to q.
+func (rm *roadmap) nearest(opt *plannerOptions, q []referenceframe.Input, k int) []int {
+	type neighbor struct {
+		index int
+		dist  float64
+	}
+	neighbors := make([]neighbor, 0, len(rm.Nodes))
+	for i := range rm.Nodes {
+		dist := opt.DistanceFunc(&ik.Segment{StartConfiguration: q, EndConfiguration: rm.inputs(i)})
+		neighbors = append(neighbors, neighbor{i, dist})
+	}
+	sort.Slice(neighbors, func(a, b int) bool { return neighbors[a].dist < neighbors[b].dist })
+	if len(neighbors) > k {
+		neighbors = neighbors[:k]
+	}
+	indices := make([]int, 0, len(neighbors))
+	for _, n := range neighbors {
+		indices = append(indices, n.index)
+	}
+	return indices
+}
+
+// save writes the roadmap to a file, replacing it whole so that a process loading it never reads part of it.
+func (rm *roadmap) save(file string) error {
+	rm.Edges = rm.Edges[:0]
+	for i, edges := range rm.adj {
+		for j, checked := range edges {
+			if i < j {
+				rm.Edges = append(rm.Edges, roadmapEdge{From: i, To: j, Checked: checked})
+			}
+		}
+	}
+	sort.Slice(rm.Edges, func(a, b int) bool {
+		if rm.Edges[a].From != rm.Edges[b].From {
+			return rm.Edges[a].From < rm.Edges[b].From
+		}
+		return rm.Edges[a].To < rm.Edges[b].To
+	})
+	data, err := json.Marshal(rm)
+	rm.Edges = nil
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		//nolint:errcheck,gosec
+		tmp.Close()
+		//nolint:errcheck,gosec
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		//nolint:errcheck,gosec
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+func loadRoadmap(file string) (*roadmap, error) {
+	//nolint:gosec
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	rm := &roadmap{}
+	if err := json.Unmarshal(data, rm); err != nil {
+		return nil, err
+	}
+	rm.adj = make([]map[int]bool, len(rm.Nodes))
+	for i := range rm.adj {
+		rm.adj[i] = map[int]bool{}
+	}
+	for _, edge := range rm.Edges {
+		if edge.From < 0 || edge.From >= len(rm.Nodes) || edge.To < 0 || edge.To >= len(rm.Nodes) {
+			return nil, errors.Errorf("roadmap edge from %d to %d is not between its %d configurations", edge.From, edge.To, len(rm.Nodes))
+		}
+		rm.connect(edge.From, edge.To, edge.Checked)
+	}
+	rm.Edges = nil
+	return rm, nil
+}
+
+// queryGraph is a roadmap with the seed and goals of a query added to it, without changing the roadmap but for removing the edges of
+// it found to be invalid, and marking those found to be valid.
+type queryGraph struct {
+	rm    *roadmap
+	start int
+	goals []int
+	// extra holds the configurations of the seed and goals, which follow those of the roadmap, and adj their edges.
+	extra [][]referenceframe.Input
+	adj   map[int]map[int]bool
+}
+
+func newQueryGraph(rm *roadmap, seed []referenceframe.Input, goals [][]referenceframe.Input) *queryGraph {
+	g := &queryGraph{rm: rm, start: len(rm.Nodes), extra: append([][]referenceframe.Input{seed}, goals...), adj: map[int]map[int]bool{}}
+	for i := range goals {
+		g.goals = append(g.goals, g.start+1+i)
+	}
+	return g
+}
+
+func (g *queryGraph) inputs(v int) []referenceframe.Input {
+	if v >= g.start {
+		return g.extra[v-g.start]
+	}
+	return g.rm.inputs(v)
+}
+
+func (g *queryGraph) connect(u, v int) {
+	for _, edge := range [][2]int{{u, v}, {v, u}} {
+		if g.adj[edge[0]] == nil {
+			g.adj[edge[0]] = map[int]bool{}
+		}
+		g.adj[edge[0]][edge[1]] = false
+	}
+}
+
+func (g *queryGraph) inRoadmap(u, v int) bool {
+	return u < g.start && v < g.start
+}
+
+func (g *queryGraph) checked(u, v int) bool {
+	if g.inRoadmap(u, v) {
+		return g.rm.adj[u][v]
+	}
+	return g.adj[u][v]
+}
+
+func (g *queryGraph) check(u, v int) {
+	if g.inRoadmap(u, v) {
+		g.rm.connect(u, v, true)
+		return
+	}
+	g.adj[u][v] = true
+	g.adj[v][u] = true
+}
+
+func (g *queryGraph) disconnect(u, v int) {
+	if g.inRoadmap(u, v) {
+		delete(g.rm.adj[u], v)
+		delete(g.rm.adj[v], u)
+		return
+	}
+	delete(g.adj[u], v)
+	delete(g.adj[v], u)
+}
+
+func (g *queryGraph) neighbors(u int) []int {
+	neighbors := make([]int, 0)
+	if u < g.start {
+		for v := range g.rm.adj[u] {
+			neighbors = append(neighbors, v)
+		}
+	}
+	for v := range g.adj[u] {
+		neighbors = append(neighbors, v)
+	}
+	return neighbors
+}
+
+// shortestPath returns the shortest path from the start to any goal by Dijkstra's algorithm, or nil if there is none.
+func (g *queryGraph) shortestPath(distance ik.SegmentMetric) []int {
+	isGoal := map[int]bool{}
+	for _, goal := range g.goals {
+		isGoal[goal] = true
+	}
+	costs := map[int]float64{g.start: 0}
+	parents := map[int]int{}
+	queue := &prmQueue{{index: g.start}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(prmQueueItem)
+		if item.cost > costs[item.index] {
+			continue
+		}
+		if isGoal[item.index] {
+			path := []int{item.index}
+			for v := item.index; v != g.start; {
+				v = parents[v]
+				path = append([]int{v}, path...)
+			}
+			return path
+		}
+		for _, v := range g.neighbors(item.index) {
+			cost := item.cost + distance(&ik.Segment{StartConfiguration: g.inputs(item.index), EndConfiguration: g.inputs(v)})
+			if old, ok := costs[v]; ok && old <= cost {
+				continue
+			}
+			costs[v] = cost
+			parents[v] = item.index
+			heap.Push(queue, prmQueueItem{index: v, cost: cost})
+		}
+	}
+	return nil
+}
+
+type prmQueueItem struct {
+	index int
+	cost  float64
+}
+
+// prmQueue is a min-heap of nodes by the cost of reaching them.
+type prmQueue []prmQueueItem
+
+func (q prmQueue) Len() int           { return len(q) }
+func (q prmQueue) Less(i, j int) bool { return q[i].cost < q[j].cost }
+func (q prmQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *prmQueue) Push(x interface{}) {
+	*q = append(*q, x.(prmQueueItem))
+}
+
+func (q *prmQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// roadmapKey fingerprints everything the validity of a roadmap depends on: the frames of the frame system, which of them are planned
+// for, the geometries which do not move, the obstacles, the collisions allowed between them and the collision buffer. A roadmap built
+// for one key is rebuilt rather than reused for another.
+func roadmapKey(
+	sf *solverFrame,
+	staticGeometries, obstacles []spatialmath.Geometry,
+	allowedCollisions []*Collision,
+	collisionBufferMM float64,
+) (string, error) {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %v %v\n", sf.Name(), sf.DoF(), collisionBufferMM)
+
+	names := sf.fss.FrameNames()
+	sort.Strings(names)
+	for _, name := range names {
+		f := sf.fss.Frame(name)
+		if name == referenceframe.World {
+			continue
+		}
+		parent, err := sf.fss.Parent(f)
+		if err != nil {
+			return "", err
+		}
+		data, err := f.MarshalJSON()
+		if err != nil {
+			return "", errors.Wrapf(err, "cannot fingerprint frame %q for a roadmap", name)
+		}
+		fmt.Fprintf(hash, "%s %s %t %s\n", name, parent.Name(), sf.movingFrame(name), data)
+	}
+
+	for _, geometries := range [][]spatialmath.Geometry{staticGeometries, obstacles} {
+		encoded := make([]string, 0, len(geometries))
+		for _, geometry := range geometries {
+			data, err := proto.MarshalOptions{Deterministic: true}.Marshal(geometry.ToProtobuf())
+			if err != nil {
+				return "", err
+			}
+			encoded = append(encoded, hex.EncodeToString(data))
+		}
+		sort.Strings(encoded)
+		fmt.Fprintln(hash, encoded)
+	}
+
+	collisions := make([]string, 0, len(allowedCollisions))
+	for _, collision := range allowedCollisions {
+		names := []string{collision.name1, collision.name2}
+		sort.Strings(names)
+		collisions = append(collisions, fmt.Sprintf("%s %s", names[0], names[1]))
+	}
+	sort.Strings(collisions)
+	fmt.Fprintln(hash, collisions)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
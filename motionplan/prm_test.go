@@ -0,0 +1,150 @@
+package motionplan
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	frame "go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+)
+
+func TestPRM(t *testing.T) {
+	ctx := context.Background()
+	roadmapFile := filepath.Join(t.TempDir(), "roadmap.json")
+
+	fs := frame.NewEmptyFrameSystem("")
+	gantryX, err := frame.NewTranslationalFrame("gantryX", r3.Vector{1, 0, 0}, frame.Limit{-300, 300})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(gantryX, fs.World()), test.ShouldBeNil)
+	gantryY, err := frame.NewTranslationalFrame("gantryY", r3.Vector{0, 1, 0}, frame.Limit{-300, 300})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(gantryY, gantryX), test.ShouldBeNil)
+	box, err := spatialmath.NewBox(spatialmath.NewZeroPose(), r3.Vector{10, 10, 10}, "")
+	test.That(t, err, test.ShouldBeNil)
+	head, err := frame.NewStaticFrameWithGeometry("head", spatialmath.NewZeroPose(), box)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(head, gantryY), test.ShouldBeNil)
+
+	// collisions at the start are ignored, so the head starts clear of the wall
+	seedMap := map[string][]frame.Input{"gantryX": {{-100}}, "gantryY": {{0}}, "head": {}}
+	sf, err := newSolverFrame(fs, head.Name(), frame.World, seedMap)
+	test.That(t, err, test.ShouldBeNil)
+	start, err := sf.mapToSlice(seedMap)
+	test.That(t, err, test.ShouldBeNil)
+	goal, err := sf.mapToSlice(map[string][]frame.Input{"gantryX": {{100}}, "gantryY": {{0}}, "head": {}})
+	test.That(t, err, test.ShouldBeNil)
+
+	// a wall between the start and goal, which the head must go around
+	wall := func(y float64) *frame.WorldState {
+		geometry, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(r3.Vector{Y: y}), r3.Vector{10, 200, 50}, "wall")
+		test.That(t, err, test.ShouldBeNil)
+		worldState, err := frame.NewWorldState(
+			[]*frame.GeometriesInFrame{frame.NewGeometriesInFrame(frame.World, []spatialmath.Geometry{geometry})},
+			nil,
+		)
+		test.That(t, err, test.ShouldBeNil)
+		return worldState
+	}
+
+	newPRM := func(t *testing.T, worldState *frame.WorldState, options map[string]interface{}) *prmMotionPlanner {
+		t.Helper()
+		pm, err := newPlanManager(sf, logger, 1)
+		test.That(t, err, test.ShouldBeNil)
+		opt, err := pm.plannerSetupFromMoveRequest(
+			spatialmath.NewZeroPose(), spatialmath.NewZeroPose(), seedMap, worldState, nil, options,
+		)
+		test.That(t, err, test.ShouldBeNil)
+		mp, err := opt.PlannerConstructor(sf, rand.New(rand.NewSource(1)), logger, opt)
+		test.That(t, err, test.ShouldBeNil)
+		prm, ok := mp.(*prmMotionPlanner)
+		test.That(t, ok, test.ShouldBeTrue)
+		return prm
+	}
+
+	planAround := func(t *testing.T, prm *prmMotionPlanner, wallY float64) {
+		t.Helper()
+		rm, err := prm.roadmap(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		path, err := prm.planThrough(ctx, rm, start, [][]frame.Input{goal})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, path[0].Q(), test.ShouldResemble, start)
+		test.That(t, path[len(path)-1].Q(), test.ShouldResemble, goal)
+		aroundWall := false
+		for i := 1; i < len(path); i++ {
+			test.That(t, prm.checkPath(path[i-1].Q(), path[i].Q()), test.ShouldBeTrue)
+			pose, err := sf.Transform(path[i].Q())
+			test.That(t, err, test.ShouldBeNil)
+			aroundWall = aroundWall || math.Abs(pose.Point().Y-wallY) > 100
+		}
+		test.That(t, aroundWall, test.ShouldBeTrue)
+	}
+
+	// the wall is thinner than the default resolution collisions are checked at
+	options := map[string]interface{}{"planning_alg": "prm", "roadmap_file": roadmapFile, "roadmap_samples": 200, "resolution": 2.}
+
+	t.Run("builds and reuses a roadmap", func(t *testing.T) {
+		prm := newPRM(t, wall(0), options)
+		planAround(t, prm, 0)
+		rm, err := prm.roadmap(ctx)
+		test.That(t, err, test.ShouldBeNil)
+
+		// another process loads the roadmap from its file rather than building it again
+		roadmaps = &roadmapCache{byKey: map[string]*roadmap{}}
+		prm = newPRM(t, wall(0), options)
+		loaded, err := prm.roadmap(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, loaded, test.ShouldNotEqual, rm)
+		test.That(t, loaded.Key, test.ShouldEqual, rm.Key)
+		test.That(t, loaded.Nodes, test.ShouldResemble, rm.Nodes)
+		test.That(t, loaded.adj, test.ShouldResemble, rm.adj)
+		planAround(t, prm, 0)
+	})
+
+	t.Run("rebuilds the roadmap when the obstacles change", func(t *testing.T) {
+		roadmaps = &roadmapCache{byKey: map[string]*roadmap{}}
+		old, err := loadRoadmap(roadmapFile)
+		test.That(t, err, test.ShouldBeNil)
+		prm := newPRM(t, wall(20), options)
+		test.That(t, prm.planOpts.roadmapKey, test.ShouldNotEqual, old.Key)
+		planAround(t, prm, 20)
+		rebuilt, err := loadRoadmap(roadmapFile)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, rebuilt.Key, test.ShouldEqual, prm.planOpts.roadmapKey)
+	})
+
+	t.Run("lazy PRM checks only the edges it plans along", func(t *testing.T) {
+		lazyOptions := map[string]interface{}{"planning_alg": "lazyprm", "roadmap_samples": 200, "resolution": 2.}
+		prm := newPRM(t, wall(-20), lazyOptions)
+		planAround(t, prm, -20)
+		rm, err := prm.roadmap(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		edges, checked := 0, 0
+		for _, neighbors := range rm.adj {
+			for _, isChecked := range neighbors {
+				edges++
+				if isChecked {
+					checked++
+				}
+			}
+		}
+		test.That(t, checked, test.ShouldBeGreaterThan, 0)
+		test.That(t, checked, test.ShouldBeLessThan, edges)
+	})
+
+	t.Run("plans free motion only", func(t *testing.T) {
+		pm, err := newPlanManager(sf, logger, 1)
+		test.That(t, err, test.ShouldBeNil)
+		_, err = pm.plannerSetupFromMoveRequest(
+			spatialmath.NewZeroPose(), spatialmath.NewZeroPose(), seedMap, wall(0), nil,
+			map[string]interface{}{"planning_alg": "prm", "motion_profile": LinearMotionProfile},
+		)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "can only plan free motion")
+	})
+}
@@ -0,0 +1,416 @@
+package motionplan
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/referenceframe"
+)
+
+const (
+	// The path is timed on a grid of this many points per waypoint, within these bounds.
+	timingPointsPerWaypoint = 20
+	minTimingPoints         = 100
+	maxTimingPoints         = 2000
+	// maxJerkIterations is how many times the velocity is lowered where the jerk is too high before the whole
+	// trajectory is slowed down instead.
+	maxJerkIterations = 20
+	timingTolerance   = 1e-9
+)
+
+// TimedWaypoint is a state of a TimedTrajectory.
+type TimedWaypoint struct {
+	Time   time.Duration
+	Inputs []referenceframe.Input
+	// Velocities and Accelerations are in the units of the inputs per second and per second squared.
+	Velocities    []float64
+	Accelerations []float64
+}
+
+// TimedTrajectory is a path through joint space timed to respect the dynamic limits of the joints, starting and
+// ending at rest, which an arm driver can execute by following its states in time.
+type TimedTrajectory []TimedWaypoint
+
+// Duration returns how long the trajectory takes.
+func (traj TimedTrajectory) Duration() time.Duration {
+	if len(traj) == 0 {
+		return 0
+	}
+	return traj[len(traj)-1].Time
+}
+
+// TimeTrajectory times the inputs of a frame along a Trajectory with the dynamic limits of its kinematics model.
+func TimeTrajectory(traj Trajectory, frameName string, model referenceframe.Model) (TimedTrajectory, error) {
+	waypoints, err := traj.GetFrameInputs(frameName)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := referenceframe.DynamicLimits(model)
+	if err != nil {
+		return nil, err
+	}
+	return TimeParameterize(waypoints, limits)
+}
+
+// TimeParameterize times a path of waypoints so that it is as fast as the velocity and acceleration limits of each
+// degree of freedom allow, and, for those with a jerk limit, slows it down until their jerk is within their limit.
+//
+// The path is a cubic spline through the waypoints, timed by time-optimal path parameterization by reachability
+// analysis (TOPP-RA): the greatest speed along the path from which the end can still be reached at rest is found
+// backwards from the end, and the path is then followed forwards accelerating as hard as that speed allows.
+func TimeParameterize(waypoints [][]referenceframe.Input, limits []referenceframe.DynamicLimit) (TimedTrajectory, error) {
+	if len(waypoints) == 0 {
+		return nil, errors.New("cannot time a path without waypoints")
+	}
+	dof := len(limits)
+	for i, waypoint := range waypoints {
+		if len(waypoint) != dof {
+			return nil, errors.Errorf("waypoint %d has %d inputs, but there are limits for %d", i, len(waypoint), dof)
+		}
+	}
+	for i, limit := range limits {
+		if limit.Velocity <= 0 || limit.Acceleration <= 0 || limit.Jerk < 0 {
+			return nil, errors.Errorf("degree of freedom %d needs positive velocity and acceleration limits to be timed", i)
+		}
+	}
+
+	path := newJointSpline(waypoints)
+	if path == nil {
+		// the waypoints are all the same, so there is nowhere to go
+		return TimedTrajectory{{
+			Inputs:        waypoints[0],
+			Velocities:    make([]float64, dof),
+			Accelerations: make([]float64, dof),
+		}}, nil
+	}
+
+	points := int(math.Min(math.Max(float64(timingPointsPerWaypoint*len(waypoints)), minTimingPoints), maxTimingPoints))
+	grid := newTimingGrid(path, limits, points)
+	for iteration := 0; ; iteration++ {
+		if err := grid.parameterize(); err != nil {
+			return nil, err
+		}
+		traj := grid.trajectory()
+		if iteration == maxJerkIterations {
+			return slowToJerkLimits(traj, limits), nil
+		}
+		if !grid.capJerk(traj) {
+			return traj, nil
+		}
+	}
+}
+
+// timingGrid is a path discretized for TOPP-RA. The speed along the path is given as x, the square of the rate of
+// change of the path parameter s, and the acceleration along it as u, the second derivative of s, so that the joint
+// velocities are q'(s)·√x and the joint accelerations are q'(s)·u + q”(s)·x, and x(s_i+1) = x(s_i) + 2·Δ·u(s_i).
+type timingGrid struct {
+	path   *jointSpline
+	limits []referenceframe.DynamicLimit
+	delta  float64
+	s      []float64
+	dq     [][]float64
+	ddq    [][]float64
+	// xCap is the greatest speed at each point, from the velocity limits and lowered to meet the jerk limits.
+	xCap []float64
+	// xMax is the greatest speed at each point from which the end of the path can be reached.
+	xMax []float64
+	x    []float64
+	u    []float64
+}
+
+func newTimingGrid(path *jointSpline, limits []referenceframe.DynamicLimit, points int) *timingGrid {
+	g := &timingGrid{
+		path:   path,
+		limits: limits,
+		delta:  path.length / float64(points-1),
+		s:      make([]float64, points),
+		dq:     make([][]float64, points),
+		ddq:    make([][]float64, points),
+		xCap:   make([]float64, points),
+		xMax:   make([]float64, points),
+		x:      make([]float64, points),
+		u:      make([]float64, points),
+	}
+	for i := range g.s {
+		g.s[i] = float64(i) * g.delta
+		_, g.dq[i], g.ddq[i] = path.evaluate(g.s[i])
+		g.xCap[i] = math.Inf(1)
+		for j, limit := range limits {
+			if math.Abs(g.dq[i][j]) > timingTolerance {
+				g.xCap[i] = math.Min(g.xCap[i], math.Pow(limit.Velocity/g.dq[i][j], 2))
+			}
+		}
+	}
+	return g
+}
+
+// constraints returns the acceleration limits at a point as the half planes a·x + b·u <= c.
+func (g *timingGrid) constraints(i int) []halfPlane {
+	planes := make([]halfPlane, 0, 2*len(g.limits))
+	for j, limit := range g.limits {
+		planes = append(planes,
+			halfPlane{a: g.ddq[i][j], b: g.dq[i][j], c: limit.Acceleration},
+			halfPlane{a: -g.ddq[i][j], b: -g.dq[i][j], c: limit.Acceleration})
+	}
+	return planes
+}
+
+// parameterize finds the fastest speeds along the path.
+func (g *timingGrid) parameterize() error {
+	last := len(g.s) - 1
+	// the path ends at rest, and at each point before the end the speed is the greatest from which some allowed
+	// acceleration reaches a speed at the next point from which the end can be reached
+	g.xMax[last] = 0
+	for i := last - 1; i >= 0; i-- {
+		planes := append(g.constraints(i),
+			halfPlane{a: 1, b: 2 * g.delta, c: g.xMax[i+1]},
+			halfPlane{a: -1, b: -2 * g.delta, c: 0},
+		)
+		x, ok := maximizeX(planes, g.xCap[i])
+		if !ok {
+			return errors.Errorf("the path cannot be timed at %.3f of its length", g.s[i]/g.path.length)
+		}
+		g.xMax[i] = x
+	}
+
+	// the path starts at rest, and at each point accelerates as hard as it can without going too fast to stop
+	g.x[0] = 0
+	for i := 0; i < last; i++ {
+		lower, upper := g.accelerationBounds(i, -g.x[i]/(2*g.delta), (g.xMax[i+1]-g.x[i])/(2*g.delta))
+		// the bounds can only cross by rounding, since the speed is within the reachable speeds
+		g.u[i] = math.Max(upper, lower)
+		g.x[i+1] = math.Min(math.Max(g.x[i]+2*g.delta*g.u[i], 0), g.xMax[i+1])
+		if g.x[i+1] == 0 && g.x[i] == 0 {
+			return errors.Errorf("the path cannot be timed at %.3f of its length", g.s[i]/g.path.length)
+		}
+	}
+	// the path comes to rest at the end, decelerating no harder than the joints can there
+	lower, upper := g.accelerationBounds(last, math.Inf(-1), math.Inf(1))
+	g.u[last] = math.Min(math.Max(g.u[last-1], lower), upper)
+	return nil
+}
+
+// accelerationBounds narrows bounds on the acceleration along the path at a point to those the joints allow.
+func (g *timingGrid) accelerationBounds(i int, lower, upper float64) (float64, float64) {
+	for _, plane := range g.constraints(i) {
+		bound := (plane.c - plane.a*g.x[i]) / plane.b
+		switch {
+		case plane.b > timingTolerance:
+			upper = math.Min(upper, bound)
+		case plane.b < -timingTolerance:
+			lower = math.Max(lower, bound)
+		}
+	}
+	return lower, upper
+}
+
+// trajectory returns the states of the path at the points of the grid.
+func (g *timingGrid) trajectory() TimedTrajectory {
+	traj := make(TimedTrajectory, len(g.s))
+	var t float64
+	for i := range g.s {
+		if i > 0 {
+			t += 2 * g.delta / (math.Sqrt(g.x[i-1]) + math.Sqrt(g.x[i]))
+		}
+		q, _, _ := g.path.evaluate(g.s[i])
+		speed := math.Sqrt(g.x[i])
+		waypoint := TimedWaypoint{
+			Time:          time.Duration(t * float64(time.Second)),
+			Inputs:        referenceframe.FloatsToInputs(q),
+			Velocities:    make([]float64, len(q)),
+			Accelerations: make([]float64, len(q)),
+		}
+		for j := range q {
+			waypoint.Velocities[j] = g.dq[i][j] * speed
+			waypoint.Accelerations[j] = g.dq[i][j]*g.u[i] + g.ddq[i][j]*g.x[i]
+		}
+		traj[i] = waypoint
+	}
+	return traj
+}
+
+// capJerk lowers the greatest speed where the jerk of a joint is over its limit, returning whether it did. Jerk grows
+// with the cube of the speed for a given path, so the square of the speed is lowered by the two thirds power of how
+// far over its limit the jerk is.
+func (g *timingGrid) capJerk(traj TimedTrajectory) bool {
+	capped := false
+	for i := 1; i < len(traj); i++ {
+		scale := 1.
+		for j, limit := range g.limits {
+			if jerk := jerkBetween(traj[i-1], traj[i], j); limit.Jerk > 0 && jerk > limit.Jerk*(1+1e-6) {
+				scale = math.Min(scale, math.Pow(limit.Jerk/jerk, 2./3))
+			}
+		}
+		if scale < 1 {
+			capped = true
+			g.xCap[i-1] = math.Min(g.xCap[i-1], g.x[i-1]*scale)
+			g.xCap[i] = math.Min(g.xCap[i], g.x[i]*scale)
+		}
+	}
+	return capped
+}
+
+// slowToJerkLimits slows the whole trajectory down until the jerk of every joint is within its limit.
+func slowToJerkLimits(traj TimedTrajectory, limits []referenceframe.DynamicLimit) TimedTrajectory {
+	k := 1.
+	for i := 1; i < len(traj); i++ {
+		for j, limit := range limits {
+			if jerk := jerkBetween(traj[i-1], traj[i], j); limit.Jerk > 0 && jerk > limit.Jerk {
+				k = math.Min(k, math.Cbrt(limit.Jerk/jerk))
+			}
+		}
+	}
+	for i := range traj {
+		traj[i].Time = time.Duration(float64(traj[i].Time) / k)
+		for j := range traj[i].Velocities {
+			traj[i].Velocities[j] *= k
+			traj[i].Accelerations[j] *= k * k
+		}
+	}
+	return traj
+}
+
+func jerkBetween(from, to TimedWaypoint, joint int) float64 {
+	dt := (to.Time - from.Time).Seconds()
+	if dt <= 0 {
+		return 0
+	}
+	return math.Abs(to.Accelerations[joint]-from.Accelerations[joint]) / dt
+}
+
+// halfPlane is the constraint a·x + b·u <= c.
+type halfPlane struct {
+	a, b, c float64
+}
+
+// maximizeX returns the greatest x with 0 <= x <= xCap for which some u meets all the constraints, by checking every
+// vertex of the region they bound, which is few enough for the handful of constraints of a point of the path.
+func maximizeX(planes []halfPlane, xCap float64) (float64, bool) {
+	// bounding u keeps the region bounded where the path does not move some joints
+	const uBound = 1e12
+	planes = append(planes,
+		halfPlane{a: -1, c: 0},
+		halfPlane{b: 1, c: uBound},
+		halfPlane{b: -1, c: uBound},
+	)
+	if !math.IsInf(xCap, 1) {
+		planes = append(planes, halfPlane{a: 1, c: xCap})
+	}
+	best, found := 0., false
+	for i := range planes {
+		for j := i + 1; j < len(planes); j++ {
+			p, q := planes[i], planes[j]
+			det := p.a*q.b - p.b*q.a
+			if math.Abs(det) < timingTolerance {
+				continue
+			}
+			x := (p.c*q.b - p.b*q.c) / det
+			u := (p.a*q.c - p.c*q.a) / det
+			if (!found || x > best) && feasible(planes, x, u) {
+				best, found = x, true
+			}
+		}
+	}
+	return math.Max(best, 0), found
+}
+
+func feasible(planes []halfPlane, x, u float64) bool {
+	for _, plane := range planes {
+		scale := math.Max(1, math.Abs(plane.c)+math.Abs(plane.a*x)+math.Abs(plane.b*u))
+		if plane.a*x+plane.b*u-plane.c > 1e-9*scale {
+			return false
+		}
+	}
+	return true
+}
+
+// jointSpline is a natural cubic spline through waypoints in joint space, parameterized by the distance between them.
+type jointSpline struct {
+	knots  []float64
+	points [][]float64
+	// second are the second derivatives of each joint at each knot.
+	second [][]float64
+	length float64
+}
+
+// newJointSpline returns the spline through the waypoints, or nil when they are all the same.
+func newJointSpline(waypoints [][]referenceframe.Input) *jointSpline {
+	sp := &jointSpline{}
+	for _, waypoint := range waypoints {
+		point := referenceframe.InputsToFloats(waypoint)
+		if len(sp.points) > 0 {
+			dist := distance(sp.points[len(sp.points)-1], point)
+			if dist < timingTolerance {
+				continue
+			}
+			sp.length += dist
+		}
+		sp.knots = append(sp.knots, sp.length)
+		sp.points = append(sp.points, point)
+	}
+	if len(sp.points) < 2 {
+		return nil
+	}
+
+	n := len(sp.points)
+	dof := len(sp.points[0])
+	sp.second = make([][]float64, n)
+	for k := range sp.second {
+		sp.second[k] = make([]float64, dof)
+	}
+	// the second derivatives solve a tridiagonal system, with none at the ends of a natural spline
+	for j := 0; j < dof; j++ {
+		diag := make([]float64, n)
+		rhs := make([]float64, n)
+		for k := 1; k < n-1; k++ {
+			h0, h1 := sp.knots[k]-sp.knots[k-1], sp.knots[k+1]-sp.knots[k]
+			diag[k] = 2 * (h0 + h1)
+			rhs[k] = 6 * ((sp.points[k+1][j]-sp.points[k][j])/h1 - (sp.points[k][j]-sp.points[k-1][j])/h0)
+			if k > 1 {
+				// eliminate the sub diagonal h0 with the row above
+				factor := h0 / diag[k-1]
+				diag[k] -= factor * h0
+				rhs[k] -= factor * rhs[k-1]
+			}
+		}
+		for k := n - 2; k >= 1; k-- {
+			m := rhs[k]
+			if k < n-2 {
+				m -= (sp.knots[k+1] - sp.knots[k]) * sp.second[k+1][j]
+			}
+			sp.second[k][j] = m / diag[k]
+		}
+	}
+	return sp
+}
+
+// evaluate returns the position and its first and second derivatives at a distance along the spline.
+func (sp *jointSpline) evaluate(s float64) ([]float64, []float64, []float64) {
+	k := 0
+	for k < len(sp.knots)-2 && s > sp.knots[k+1] {
+		k++
+	}
+	h := sp.knots[k+1] - sp.knots[k]
+	a := (sp.knots[k+1] - s) / h
+	b := (s - sp.knots[k]) / h
+	dof := len(sp.points[k])
+	q, dq, ddq := make([]float64, dof), make([]float64, dof), make([]float64, dof)
+	for j := 0; j < dof; j++ {
+		y0, y1 := sp.points[k][j], sp.points[k+1][j]
+		m0, m1 := sp.second[k][j], sp.second[k+1][j]
+		q[j] = a*y0 + b*y1 + ((a*a*a-a)*m0+(b*b*b-b)*m1)*h*h/6
+		dq[j] = (y1-y0)/h - (3*a*a-1)*h*m0/6 + (3*b*b-1)*h*m1/6
+		ddq[j] = a*m0 + b*m1
+	}
+	return q, dq, ddq
+}
+
+func distance(from, to []float64) float64 {
+	var sum float64
+	for j := range from {
+		sum += (to[j] - from[j]) * (to[j] - from[j])
+	}
+	return math.Sqrt(sum)
+}
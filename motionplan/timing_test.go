@@ -0,0 +1,100 @@
+package motionplan
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/referenceframe"
+)
+
+// checkLimits checks that a timed trajectory starts and ends at rest and stays within its limits.
+func checkLimits(t *testing.T, traj TimedTrajectory, limits []referenceframe.DynamicLimit) {
+	t.Helper()
+	const tolerance = 1.01
+	for j := range limits {
+		test.That(t, traj[0].Velocities[j], test.ShouldAlmostEqual, 0)
+		test.That(t, traj[len(traj)-1].Velocities[j], test.ShouldAlmostEqual, 0)
+	}
+	for i, waypoint := range traj {
+		if i > 0 {
+			test.That(t, waypoint.Time, test.ShouldBeGreaterThan, traj[i-1].Time)
+		}
+		for j, limit := range limits {
+			test.That(t, math.Abs(waypoint.Velocities[j]), test.ShouldBeLessThanOrEqualTo, limit.Velocity*tolerance)
+			test.That(t, math.Abs(waypoint.Accelerations[j]), test.ShouldBeLessThanOrEqualTo, limit.Acceleration*tolerance)
+			if limit.Jerk > 0 && i > 0 {
+				test.That(t, jerkBetween(traj[i-1], waypoint, j), test.ShouldBeLessThanOrEqualTo, limit.Jerk*tolerance)
+			}
+		}
+	}
+}
+
+func TestTimeParameterize(t *testing.T) {
+	oneJoint := []referenceframe.DynamicLimit{{Velocity: 1, Acceleration: 1}}
+
+	t.Run("accelerates and decelerates as hard as it can", func(t *testing.T) {
+		// a move of 1 reaches the velocity limit of 1 halfway, after accelerating for 1 second
+		traj, err := TimeParameterize([][]referenceframe.Input{{{0}}, {{1}}}, oneJoint)
+		test.That(t, err, test.ShouldBeNil)
+		checkLimits(t, traj, oneJoint)
+		test.That(t, traj.Duration().Seconds(), test.ShouldAlmostEqual, 2, 0.05)
+		test.That(t, traj[0].Inputs[0].Value, test.ShouldEqual, 0)
+		test.That(t, traj[len(traj)-1].Inputs[0].Value, test.ShouldAlmostEqual, 1)
+	})
+
+	t.Run("cruises at the velocity limit", func(t *testing.T) {
+		// 1 second accelerating, 3 cruising and 1 decelerating
+		traj, err := TimeParameterize([][]referenceframe.Input{{{0}}, {{4}}}, oneJoint)
+		test.That(t, err, test.ShouldBeNil)
+		checkLimits(t, traj, oneJoint)
+		test.That(t, traj.Duration().Seconds(), test.ShouldAlmostEqual, 5, 0.05)
+	})
+
+	t.Run("limits each joint", func(t *testing.T) {
+		limits := []referenceframe.DynamicLimit{
+			{Velocity: 1, Acceleration: 2},
+			{Velocity: 0.5, Acceleration: 0.5},
+			{Velocity: 3, Acceleration: 10},
+		}
+		waypoints := [][]referenceframe.Input{
+			referenceframe.FloatsToInputs([]float64{0, 0, 0}),
+			referenceframe.FloatsToInputs([]float64{0.5, 0.2, -1}),
+			referenceframe.FloatsToInputs([]float64{0.5, 0.2, -1}),
+			referenceframe.FloatsToInputs([]float64{1, -0.3, 0.5}),
+			referenceframe.FloatsToInputs([]float64{1.2, 0, 1}),
+		}
+		traj, err := TimeParameterize(waypoints, limits)
+		test.That(t, err, test.ShouldBeNil)
+		checkLimits(t, traj, limits)
+		// the path passes through its waypoints
+		test.That(t, referenceframe.InputsToFloats(traj[len(traj)-1].Inputs), test.ShouldResemble,
+			referenceframe.InputsToFloats(waypoints[len(waypoints)-1]))
+
+		// a jerk limit makes the trajectory smoother and slower
+		jerkLimited := append([]referenceframe.DynamicLimit(nil), limits...)
+		for i := range jerkLimited {
+			jerkLimited[i].Jerk = 5
+		}
+		smooth, err := TimeParameterize(waypoints, jerkLimited)
+		test.That(t, err, test.ShouldBeNil)
+		checkLimits(t, smooth, jerkLimited)
+		test.That(t, smooth.Duration(), test.ShouldBeGreaterThan, traj.Duration())
+	})
+
+	t.Run("staying put", func(t *testing.T) {
+		traj, err := TimeParameterize([][]referenceframe.Input{{{1}}, {{1}}}, oneJoint)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, traj, test.ShouldHaveLength, 1)
+		test.That(t, traj.Duration(), test.ShouldEqual, time.Duration(0))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := TimeParameterize([][]referenceframe.Input{{{0}}, {{1}}}, []referenceframe.DynamicLimit{{Velocity: 1}})
+		test.That(t, err, test.ShouldBeError, "degree of freedom 0 needs positive velocity and acceleration limits to be timed")
+		_, err = TimeParameterize([][]referenceframe.Input{{{0}, {1}}}, oneJoint)
+		test.That(t, err, test.ShouldBeError, "waypoint 0 has 2 inputs, but there are limits for 1")
+	})
+}
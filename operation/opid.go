@@ -34,6 +34,9 @@ type Operation struct {
 	myManager *Manager
 	cancel    context.CancelFunc
 	labels    []string
+
+	progressMu sync.Mutex
+	progress   ProgressFunc
 }
 
 // Cancel cancel the context associated with an operation.
@@ -72,26 +75,66 @@ func (o *Operation) cleanup() {
 
 // NewManager creates a new manager for holding Operations.
 func NewManager(logger logging.Logger) *Manager {
-	return &Manager{ops: map[string]*Operation{}, logger: logger}
+	return &Manager{ops: map[string]*Operation{}, subscribers: map[int]func(Event){}, logger: logger}
 }
 
 // Manager holds Operations.
 type Manager struct {
-	ops    map[string]*Operation
-	lock   sync.Mutex
-	logger logging.Logger
+	ops              map[string]*Operation
+	subscribers      map[int]func(Event)
+	nextSubscriberID int
+	lock             sync.Mutex
+	logger           logging.Logger
 }
 
-func (m *Manager) remove(id uuid.UUID) {
+// Event reports that an operation started or finished.
+type Event struct {
+	Operation *Operation
+	Finished  bool
+}
+
+// Subscribe calls fn whenever an operation starts or finishes, until the returned function is called. fn is called
+// synchronously as the operation starts and finishes, so it must not block.
+func (m *Manager) Subscribe(fn func(Event)) func() {
 	m.lock.Lock()
 	defer m.lock.Unlock()
+	id := m.nextSubscriberID
+	m.nextSubscriberID++
+	m.subscribers[id] = fn
+	return func() {
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		delete(m.subscribers, id)
+	}
+}
+
+func (m *Manager) notify(event Event) {
+	m.lock.Lock()
+	subscribers := make([]func(Event), 0, len(m.subscribers))
+	for _, fn := range m.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	m.lock.Unlock()
+	for _, fn := range subscribers {
+		fn(event)
+	}
+}
+
+func (m *Manager) remove(id uuid.UUID) {
+	m.lock.Lock()
+	op, ok := m.ops[id.String()]
 	delete(m.ops, id.String())
+	m.lock.Unlock()
+	if ok {
+		m.notify(Event{Operation: op, Finished: true})
+	}
 }
 
 func (m *Manager) add(op *Operation) {
 	m.lock.Lock()
-	defer m.lock.Unlock()
 	m.ops[op.ID.String()] = op
+	m.lock.Unlock()
+	m.notify(Event{Operation: op})
 }
 
 // All returns all running operations.
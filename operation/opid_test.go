@@ -115,3 +115,31 @@ func TestCreateDuplicate(t *testing.T) {
 	cleanup()
 	test.That(t, op3Ctx.Err(), test.ShouldBeError, context.Canceled)
 }
+
+func TestSubscribe(t *testing.T) {
+	ctx := context.Background()
+
+	logger := logging.NewTestLogger(t)
+	manager := NewManager(logger)
+
+	var events []Event
+	unsubscribe := manager.Subscribe(func(event Event) {
+		events = append(events, event)
+	})
+
+	opCtx, cleanup := manager.Create(ctx, "foo", nil)
+	op := Get(opCtx)
+	cleanup()
+	cleanup()
+	test.That(t, events, test.ShouldResemble, []Event{{Operation: op}, {Operation: op, Finished: true}})
+
+	// filtered methods are not operations
+	_, cleanup = manager.Create(ctx, "/viam.robot.v1.RobotService/StreamStatus", nil)
+	cleanup()
+	test.That(t, events, test.ShouldHaveLength, 2)
+
+	unsubscribe()
+	_, cleanup = manager.Create(ctx, "bar", nil)
+	cleanup()
+	test.That(t, events, test.ShouldHaveLength, 2)
+}
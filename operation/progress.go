@@ -0,0 +1,82 @@
+package operation
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// ProgressKey is the key of the progress of an operation in its arguments as the operations API lists them, since
+// its operations have no field for it. The progress is a struct of percent_complete and, when it can be estimated,
+// remaining_sec.
+const ProgressKey = "progress"
+
+// Progress is how far a long-running operation, such as the move of an arm or gantry, has got.
+type Progress struct {
+	// Percent is how much of the operation is complete, from 0 to 100.
+	Percent float64
+	// Remaining is the estimated time until the operation finishes, or zero if it cannot be estimated.
+	Remaining time.Duration
+}
+
+// A ProgressFunc works out the progress of an operation whenever it is asked for.
+type ProgressFunc func(ctx context.Context) (Progress, error)
+
+// ProgressFromFraction returns the progress of an operation which has completed the fraction of its work in the time
+// elapsed, estimating the time remaining by assuming the rest goes as fast.
+func ProgressFromFraction(fraction float64, elapsed time.Duration) Progress {
+	fraction = math.Max(0, math.Min(1, fraction))
+	p := Progress{Percent: 100 * fraction}
+	if fraction > 0 {
+		p.Remaining = time.Duration(float64(elapsed) * (1 - fraction) / fraction)
+	}
+	return p
+}
+
+// EstimatedProgress reports the progress of an operation which started at start and is expected to take duration.
+// The operation is never reported complete before it finishes, however long it takes.
+func EstimatedProgress(start time.Time, duration time.Duration) ProgressFunc {
+	return func(ctx context.Context) (Progress, error) {
+		elapsed := time.Since(start)
+		if duration <= 0 || elapsed >= duration {
+			return Progress{Percent: 99}, nil
+		}
+		return Progress{Percent: math.Min(99, 100*float64(elapsed)/float64(duration)), Remaining: duration - elapsed}, nil
+	}
+}
+
+// TrackProgress reports the progress of the operation on the context with fn until the returned function is called.
+// It does nothing without an operation. An operation which is already tracked, such as the move of a multi-axis
+// gantry moving each of its axes, keeps reporting the progress it was first given.
+func TrackProgress(ctx context.Context, fn ProgressFunc) func() {
+	o := Get(ctx)
+	if o == nil {
+		return func() {}
+	}
+	o.progressMu.Lock()
+	defer o.progressMu.Unlock()
+	if o.progress != nil {
+		return func() {}
+	}
+	o.progress = fn
+	return func() {
+		o.progressMu.Lock()
+		defer o.progressMu.Unlock()
+		o.progress = nil
+	}
+}
+
+// Progress returns the progress of the operation, and false if it is not tracked.
+func (o *Operation) Progress(ctx context.Context) (Progress, bool, error) {
+	o.progressMu.Lock()
+	fn := o.progress
+	o.progressMu.Unlock()
+	if fn == nil {
+		return Progress{}, false, nil
+	}
+	p, err := fn(ctx)
+	if err != nil {
+		return Progress{}, false, err
+	}
+	return p, true, nil
+}
@@ -0,0 +1,74 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+func TestProgress(t *testing.T) {
+	ctx := context.Background()
+	h := NewManager(logging.NewTestLogger(t))
+
+	// without an operation there is nothing to track
+	TrackProgress(ctx, func(ctx context.Context) (Progress, error) { return Progress{}, nil })()
+
+	ctx, cleanup := h.Create(ctx, "move", nil)
+	defer cleanup()
+	op := Get(ctx)
+	_, ok, err := op.Progress(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeFalse)
+
+	untrack := TrackProgress(ctx, func(ctx context.Context) (Progress, error) {
+		return Progress{Percent: 25, Remaining: 3 * time.Second}, nil
+	})
+	// the outermost progress is kept
+	untrackNested := TrackProgress(ctx, func(ctx context.Context) (Progress, error) {
+		return Progress{}, errors.New("nested")
+	})
+	p, ok, err := op.Progress(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, p, test.ShouldResemble, Progress{Percent: 25, Remaining: 3 * time.Second})
+	untrackNested()
+	_, ok, _ = op.Progress(ctx)
+	test.That(t, ok, test.ShouldBeTrue)
+
+	untrack()
+	_, ok, err = op.Progress(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeFalse)
+
+	untrack = TrackProgress(ctx, func(ctx context.Context) (Progress, error) {
+		return Progress{}, errors.New("no position")
+	})
+	defer untrack()
+	_, _, err = op.Progress(ctx)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestProgressFromFraction(t *testing.T) {
+	test.That(t, ProgressFromFraction(0.25, 3*time.Second), test.ShouldResemble,
+		Progress{Percent: 25, Remaining: 9 * time.Second})
+	test.That(t, ProgressFromFraction(0, time.Second), test.ShouldResemble, Progress{})
+	test.That(t, ProgressFromFraction(1.5, time.Second), test.ShouldResemble, Progress{Percent: 100})
+}
+
+func TestEstimatedProgress(t *testing.T) {
+	ctx := context.Background()
+	p, err := EstimatedProgress(time.Now().Add(-time.Second), 4*time.Second)(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, p.Percent, test.ShouldAlmostEqual, 25, 1)
+	test.That(t, p.Remaining, test.ShouldBeBetween, 2900*time.Millisecond, 3*time.Second)
+
+	// a move which takes longer than expected is never done until it finishes
+	p, err = EstimatedProgress(time.Now().Add(-time.Minute), 4*time.Second)(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, p, test.ShouldResemble, Progress{Percent: 99})
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/edaniels/lidario"
 	"github.com/golang/geo/r3"
+	"github.com/pierrec/lz4"
 	"github.com/pkg/errors"
 	"go.uber.org/multierr"
 	"go.viam.com/utils"
@@ -305,6 +306,16 @@ func ToPCD(cloud PointCloud, out io.Writer, outputType PCDType) error {
 	return nil
 }
 
+// ToLZ4PCD writes out a point cloud to a binary PCD file compressed as an LZ4 frame, which is fast enough to
+// compress large frames before sending them over a slow link.
+func ToLZ4PCD(cloud PointCloud, out io.Writer) error {
+	zw := lz4.NewWriter(out)
+	if err := ToPCD(cloud, zw, PCDBinary); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
 func writePCDData(cloud PointCloud, out io.Writer, pcdtype PCDType) error {
 	cloud.Iterate(0, 0, func(pos r3.Vector, d Data) bool {
 		var err error
@@ -519,6 +530,11 @@ func ReadPCD(inRaw io.Reader) (PointCloud, error) {
 	return readPCDHelper(inRaw, BasicType)
 }
 
+// ReadLZ4PCD reads a PCD file compressed as an LZ4 frame into a pointcloud.
+func ReadLZ4PCD(inRaw io.Reader) (PointCloud, error) {
+	return readPCDHelper(lz4.NewReader(inRaw), BasicType)
+}
+
 // ReadPCDToKDTree reads a PCD file into a KD Tree pointcloud.
 func ReadPCDToKDTree(inRaw io.Reader) (*KDTree, error) {
 	cloud, err := readPCDHelper(inRaw, KDTreeType)
@@ -365,6 +365,25 @@ func TestPCDColor(t *testing.T) {
 	test.That(t, c, test.ShouldResemble, c2)
 }
 
+func TestLZ4PCD(t *testing.T) {
+	cloud := newBigPC()
+	var plain, compressed bytes.Buffer
+	test.That(t, ToPCD(cloud, &plain, PCDBinary), test.ShouldBeNil)
+	test.That(t, ToLZ4PCD(cloud, &compressed), test.ShouldBeNil)
+	test.That(t, compressed.Len(), test.ShouldBeLessThan, plain.Len()/2)
+
+	cloud2, err := ReadLZ4PCD(&compressed)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cloud2.Size(), test.ShouldEqual, cloud.Size())
+	data, got := cloud2.At(10, 20, 30)
+	test.That(t, got, test.ShouldBeTrue)
+	r, g, b := data.RGB255()
+	test.That(t, []uint8{r, g, b}, test.ShouldResemble, []uint8{255, 1, 2})
+
+	_, err = ReadLZ4PCD(&plain)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
 func newBigPC() PointCloud {
 	cloud := New()
 	for x := 10.0; x <= 50; x++ {
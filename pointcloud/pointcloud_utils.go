@@ -5,6 +5,7 @@ import (
 
 	"github.com/golang/geo/r3"
 	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/gonum/stat"
 
 	"go.viam.com/rdk/spatialmath"
@@ -33,6 +34,61 @@ func BoundingBoxFromPointCloudWithLabel(cloud PointCloud, label string) (spatial
 	return spatialmath.NewBox(spatialmath.NewPoseFromPoint(mean), dims, label)
 }
 
+// OrientedBoundingBoxFromPointCloudWithLabel returns the box along the principal axes of the points in the given
+// point cloud that encompasses all of them, which fits objects turned away from the axes of the cloud more tightly
+// than BoundingBoxFromPointCloudWithLabel's box does. The box's X, Y, and Z axes are the directions in which the points
+// spread the most to the least. Clouds of fewer than 3 points, or whose points lie on a line, get the axis-aligned box.
+func OrientedBoundingBoxFromPointCloudWithLabel(cloud PointCloud, label string) (spatialmath.Geometry, error) {
+	if cloud.Size() < 3 {
+		return BoundingBoxFromPointCloudWithLabel(cloud, label)
+	}
+	points := mat.NewDense(cloud.Size(), 3, nil)
+	i := 0
+	cloud.Iterate(0, 0, func(p r3.Vector, d Data) bool {
+		points.SetRow(i, []float64{p.X, p.Y, p.Z})
+		i++
+		return true
+	})
+	var pc stat.PC
+	if !pc.PrincipalComponents(points, nil) {
+		return BoundingBoxFromPointCloudWithLabel(cloud, label)
+	}
+	if vars := pc.VarsTo(nil); vars[1] < 1e-9*vars[0] {
+		return BoundingBoxFromPointCloudWithLabel(cloud, label)
+	}
+	var vecs mat.Dense
+	pc.VectorsTo(&vecs)
+	axes := [3]r3.Vector{}
+	for j := 0; j < 2; j++ {
+		axes[j] = r3.Vector{X: vecs.At(0, j), Y: vecs.At(1, j), Z: vecs.At(2, j)}.Normalize()
+	}
+	// the third axis makes the axes right-handed, so they are a rotation
+	axes[2] = axes[0].Cross(axes[1]).Normalize()
+
+	// extents of the points along each axis
+	minExt := r3.Vector{X: math.Inf(1), Y: math.Inf(1), Z: math.Inf(1)}
+	maxExt := r3.Vector{X: math.Inf(-1), Y: math.Inf(-1), Z: math.Inf(-1)}
+	cloud.Iterate(0, 0, func(p r3.Vector, d Data) bool {
+		local := r3.Vector{X: p.Dot(axes[0]), Y: p.Dot(axes[1]), Z: p.Dot(axes[2])}
+		minExt = r3.Vector{X: math.Min(minExt.X, local.X), Y: math.Min(minExt.Y, local.Y), Z: math.Min(minExt.Z, local.Z)}
+		maxExt = r3.Vector{X: math.Max(maxExt.X, local.X), Y: math.Max(maxExt.Y, local.Y), Z: math.Max(maxExt.Z, local.Z)}
+		return true
+	})
+	localCenter := minExt.Add(maxExt).Mul(0.5)
+	center := axes[0].Mul(localCenter.X).Add(axes[1].Mul(localCenter.Y)).Add(axes[2].Mul(localCenter.Z))
+
+	// the rotation's columns are the axes, in the column-major order RotationMatrix takes
+	orientation, err := spatialmath.NewRotationMatrix([]float64{
+		axes[0].X, axes[0].Y, axes[0].Z,
+		axes[1].X, axes[1].Y, axes[1].Z,
+		axes[2].X, axes[2].Y, axes[2].Z,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return spatialmath.NewBox(spatialmath.NewPose(center, orientation), maxExt.Sub(minExt), label)
+}
+
 // PrunePointClouds removes point clouds from a slice if the point cloud has less than nMin points.
 func PrunePointClouds(clouds []PointCloud, nMin int) []PointCloud {
 	pruned := make([]PointCloud, 0, len(clouds))
@@ -91,3 +147,43 @@ func StatisticalOutlierFilter(meanK int, stdDevThresh float64) (func(PointCloud)
 	}
 	return filterFunc, nil
 }
+
+// VoxelDownsample returns a point cloud with one point for each cubic voxel of the given size which holds points of
+// the cloud, at the centroid of those points and with the data of the first of them. It thins out dense parts of a
+// cloud so they cost no more to process than its sparse ones.
+func VoxelDownsample(cloud PointCloud, voxelSize float64) (PointCloud, error) {
+	if voxelSize <= 0 {
+		return nil, errors.Errorf("argument voxelSize must be a positive float, got %.2f", voxelSize)
+	}
+	type voxel struct {
+		sum r3.Vector
+		n   int
+		d   Data
+	}
+	voxels := make(map[[3]int64]*voxel)
+	order := make([][3]int64, 0)
+	cloud.Iterate(0, 0, func(p r3.Vector, d Data) bool {
+		key := [3]int64{
+			int64(math.Floor(p.X / voxelSize)),
+			int64(math.Floor(p.Y / voxelSize)),
+			int64(math.Floor(p.Z / voxelSize)),
+		}
+		v, ok := voxels[key]
+		if !ok {
+			v = &voxel{d: d}
+			voxels[key] = v
+			order = append(order, key)
+		}
+		v.sum = v.sum.Add(p)
+		v.n++
+		return true
+	})
+	downsampled := NewWithPrealloc(len(order))
+	for _, key := range order {
+		v := voxels[key]
+		if err := downsampled.Set(v.sum.Mul(1/float64(v.n)), v.d); err != nil {
+			return nil, err
+		}
+	}
+	return downsampled, nil
+}
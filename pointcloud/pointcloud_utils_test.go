@@ -1,6 +1,8 @@
 package pointcloud
 
 import (
+	"image/color"
+	"math"
 	"testing"
 
 	"github.com/golang/geo/r3"
@@ -59,6 +61,48 @@ func TestBoundingBoxFromPointCloud(t *testing.T) {
 	}
 }
 
+func TestOrientedBoundingBoxFromPointCloud(t *testing.T) {
+	// a 100 by 40 by 10 cuboid of points turned 30 degrees about Z, centered at 500, 200, 50
+	turn := &spatialmath.R4AA{Theta: math.Pi / 6, RZ: 1}
+	center := r3.Vector{X: 500, Y: 200, Z: 50}
+	cloud := New()
+	for x := -50.; x <= 50; x += 5 {
+		for y := -20.; y <= 20; y += 5 {
+			for z := -5.; z <= 5; z += 5 {
+				p := spatialmath.Compose(spatialmath.NewPose(center, turn), spatialmath.NewPoseFromPoint(r3.Vector{X: x, Y: y, Z: z}))
+				test.That(t, cloud.Set(p.Point(), nil), test.ShouldBeNil)
+			}
+		}
+	}
+	box, err := OrientedBoundingBoxFromPointCloudWithLabel(cloud, "box")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, box.Label(), test.ShouldEqual, "box")
+	test.That(t, spatialmath.R3VectorAlmostEqual(box.Pose().Point(), center, 1e-6), test.ShouldBeTrue)
+	// the longest side is along the box's X axis, whichever way it points
+	xAxis := spatialmath.Compose(box.Pose(), spatialmath.NewPoseFromPoint(r3.Vector{X: 1})).Point().Sub(center)
+	test.That(t, math.Abs(xAxis.Dot(r3.Vector{X: math.Cos(math.Pi / 6), Y: math.Sin(math.Pi / 6)})), test.ShouldAlmostEqual, 1, 1e-6)
+	dims := box.ToProtobuf().GetBox().GetDimsMm()
+	test.That(t, dims.X, test.ShouldAlmostEqual, 100, 1e-6)
+	test.That(t, dims.Y, test.ShouldAlmostEqual, 40, 1e-6)
+	test.That(t, dims.Z, test.ShouldAlmostEqual, 10, 1e-6)
+	// much tighter than the axis-aligned box
+	aligned, err := BoundingBoxFromPointCloud(cloud)
+	test.That(t, err, test.ShouldBeNil)
+	alignedDims := aligned.ToProtobuf().GetBox().GetDimsMm()
+	test.That(t, alignedDims.X*alignedDims.Y, test.ShouldBeGreaterThan, 2*dims.X*dims.Y)
+
+	// points on a line get the axis-aligned box
+	line := New()
+	for i := 0.; i < 5; i++ {
+		test.That(t, line.Set(r3.Vector{X: i, Y: 2 * i}, nil), test.ShouldBeNil)
+	}
+	box, err = OrientedBoundingBoxFromPointCloudWithLabel(line, "")
+	test.That(t, err, test.ShouldBeNil)
+	aligned, err = BoundingBoxFromPointCloud(line)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.GeometriesAlmostEqual(box, aligned), test.ShouldBeTrue)
+}
+
 func TestPrune(t *testing.T) {
 	clouds := makeClouds(t)
 	// before prune
@@ -70,3 +114,22 @@ func TestPrune(t *testing.T) {
 	test.That(t, len(clouds), test.ShouldEqual, 1)
 	test.That(t, clouds[0].Size(), test.ShouldEqual, 5)
 }
+
+func TestVoxelDownsample(t *testing.T) {
+	_, err := VoxelDownsample(New(), 0)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	cloud := New()
+	// four points in one 10mm voxel, one in the next
+	for _, p := range []r3.Vector{NewVector(1, 1, 1), NewVector(3, 1, 1), NewVector(1, 3, 1), NewVector(3, 3, 5), NewVector(15, 1, 1)} {
+		test.That(t, cloud.Set(p, NewColoredData(color.NRGBA{255, 0, 0, 255})), test.ShouldBeNil)
+	}
+	downsampled, err := VoxelDownsample(cloud, 10)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, downsampled.Size(), test.ShouldEqual, 2)
+	d, ok := downsampled.At(2, 2, 2)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, d.HasColor(), test.ShouldBeTrue)
+	_, ok = downsampled.At(15, 1, 1)
+	test.That(t, ok, test.ShouldBeTrue)
+}
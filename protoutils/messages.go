@@ -33,8 +33,8 @@ func ResourceNameToProto(name resource.Name) *commonpb.ResourceName {
 // ResourceNameFromProto converts a proto ResourceName to its rdk counterpart.
 func ResourceNameFromProto(name *commonpb.ResourceName) resource.Name {
 	return resource.NewName(
-		resource.APINamespace(name.Namespace).WithType(name.Type).WithSubtype(name.Subtype),
-		name.Name,
+		resource.APINamespace(name.GetNamespace()).WithType(name.GetType()).WithSubtype(name.GetSubtype()),
+		name.GetName(),
 	)
 }
 
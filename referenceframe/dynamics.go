@@ -0,0 +1,50 @@
+package referenceframe
+
+import (
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/utils"
+)
+
+// DynamicLimit is how fast a degree of freedom can move, in the units of its inputs, radians or mm, per second, per
+// second squared and per second cubed. A limit of 0 is no limit.
+type DynamicLimit struct {
+	Velocity     float64
+	Acceleration float64
+	Jerk         float64
+}
+
+func (cfg DynamicLimitConfig) toDynamicLimit(revolute bool) DynamicLimit {
+	limit := DynamicLimit{Velocity: cfg.MaxVelocity, Acceleration: cfg.MaxAcceleration, Jerk: cfg.MaxJerk}
+	if revolute {
+		limit.Velocity = utils.DegToRad(limit.Velocity)
+		limit.Acceleration = utils.DegToRad(limit.Acceleration)
+		limit.Jerk = utils.DegToRad(limit.Jerk)
+	}
+	return limit
+}
+
+// DynamicLimits returns the dynamic limits of each degree of freedom of a model, in the order of its inputs, from the
+// joints of the config it was parsed from.
+func DynamicLimits(m Model) ([]DynamicLimit, error) {
+	simple, ok := m.(*SimpleModel)
+	if !ok || simple.ModelConfig() == nil {
+		return nil, errors.Errorf("model %q has no joint config to read dynamic limits from", m.Name())
+	}
+	cfg := simple.ModelConfig()
+	joints := map[string]DynamicLimit{}
+	for _, joint := range cfg.Joints {
+		joints[joint.ID] = joint.DynamicLimitConfig.toDynamicLimit(joint.Type == RevoluteJoint)
+	}
+	for _, dh := range cfg.DHParams {
+		joints[dh.ID+"_j"] = dh.DynamicLimitConfig.toDynamicLimit(true)
+	}
+
+	limits := make([]DynamicLimit, 0, len(m.DoF()))
+	for _, transform := range simple.OrdTransforms {
+		for range transform.DoF() {
+			limits = append(limits, joints[transform.Name()])
+		}
+	}
+	return limits, nil
+}
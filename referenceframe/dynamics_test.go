@@ -0,0 +1,37 @@
+package referenceframe
+
+import (
+	"math"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestDynamicLimits(t *testing.T) {
+	model, err := UnmarshalModelJSON([]byte(`{
+		"name": "gantry_arm",
+		"links": [{"id": "base", "parent": "world"}],
+		"joints": [
+			{"id": "rail", "type": "prismatic", "parent": "base", "axis": {"x": 1}, "min": 0, "max": 1000,
+				"max_velocity": 500, "max_acceleration": 2000},
+			{"id": "wrist", "type": "revolute", "parent": "rail", "axis": {"z": 1}, "min": -180, "max": 180,
+				"max_velocity": 90, "max_acceleration": 180, "max_jerk": 720}
+		]
+	}`), "")
+	test.That(t, err, test.ShouldBeNil)
+
+	limits, err := DynamicLimits(model)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, limits, test.ShouldHaveLength, 2)
+	// the limits are in the order of the inputs, and revolute joints are limited in radians
+	byVelocity := map[float64]DynamicLimit{}
+	for _, limit := range limits {
+		byVelocity[limit.Velocity] = limit
+	}
+	test.That(t, byVelocity[500], test.ShouldResemble, DynamicLimit{Velocity: 500, Acceleration: 2000})
+	test.That(t, byVelocity[math.Pi/2].Acceleration, test.ShouldAlmostEqual, math.Pi)
+	test.That(t, byVelocity[math.Pi/2].Jerk, test.ShouldAlmostEqual, 4*math.Pi)
+
+	_, err = DynamicLimits(NewSimpleModel("empty"))
+	test.That(t, err, test.ShouldBeError, `model "empty" has no joint config to read dynamic limits from`)
+}
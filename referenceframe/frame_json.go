@@ -33,6 +33,7 @@ type JointConfig struct {
 	Max      float64                 `json:"max"`                // in mm or degs
 	Min      float64                 `json:"min"`                // in mm or degs
 	Geometry *spatial.GeometryConfig `json:"geometry,omitempty"` // only valid for prismatic/translational joints
+	DynamicLimitConfig
 }
 
 // DHParamConfig is a revolute and static frame combined in a set of Denavit Hartenberg parameters.
@@ -45,6 +46,15 @@ type DHParamConfig struct {
 	Max      float64                 `json:"max"` // in mm or degs
 	Min      float64                 `json:"min"` // in mm or degs
 	Geometry *spatial.GeometryConfig `json:"geometry,omitempty"`
+	DynamicLimitConfig
+}
+
+// DynamicLimitConfig is how fast a joint can move, in mm or degs per second, per second squared and per second cubed.
+// A limit of 0 is no limit.
+type DynamicLimitConfig struct {
+	MaxVelocity     float64 `json:"max_velocity,omitempty"`
+	MaxAcceleration float64 `json:"max_acceleration,omitempty"`
+	MaxJerk         float64 `json:"max_jerk,omitempty"`
 }
 
 // NewLinkConfig constructs a config from a Frame.
@@ -0,0 +1,86 @@
+package referenceframe
+
+import (
+	"sort"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+// A TimedPose is where a moving obstacle is at a time after a plan begins.
+type TimedPose struct {
+	Time time.Duration
+	Pose spatialmath.Pose
+}
+
+// A MovingObstacle is an obstacle whose motion while a plan is carried out is predicted, such as a part on a conveyor,
+// a person walking through a cell or another robot following its own plan. Its motion is either a constant velocity
+// from where its geometry is when the plan begins, or a trajectory of poses at times which it moves between in
+// straight lines, staying at the first pose before its time and at the last after.
+type MovingObstacle struct {
+	frame     string
+	geometry  spatialmath.Geometry
+	velocity  r3.Vector
+	waypoints []TimedPose
+}
+
+// NewMovingObstacle returns an obstacle moving at a constant velocity, in mm/s in the given frame, from the pose of
+// its geometry in that frame.
+func NewMovingObstacle(frame string, geometry spatialmath.Geometry, velocity r3.Vector) *MovingObstacle {
+	return &MovingObstacle{frame: frame, geometry: geometry, velocity: velocity}
+}
+
+// NewTimedObstacle returns an obstacle following a trajectory of poses of its geometry in the given frame.
+func NewTimedObstacle(frame string, geometry spatialmath.Geometry, waypoints []TimedPose) (*MovingObstacle, error) {
+	if len(waypoints) == 0 {
+		return nil, errors.New("a timed obstacle needs at least one pose")
+	}
+	sorted := append([]TimedPose{}, waypoints...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time < sorted[j].Time })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Time == sorted[i-1].Time {
+			return nil, errors.Errorf("timed obstacle %q has two poses at %s", geometry.Label(), sorted[i].Time)
+		}
+	}
+	return &MovingObstacle{frame: frame, geometry: geometry, waypoints: sorted}, nil
+}
+
+// Name returns the label of the geometry of the obstacle.
+func (mo *MovingObstacle) Name() string {
+	return mo.geometry.Label()
+}
+
+// Parent returns the frame the motion of the obstacle is in.
+func (mo *MovingObstacle) Parent() string {
+	return mo.frame
+}
+
+// At returns the geometry of the obstacle where it is predicted to be at a time after the plan begins.
+func (mo *MovingObstacle) At(t time.Duration) *GeometriesInFrame {
+	return NewGeometriesInFrame(mo.frame, []spatialmath.Geometry{mo.geometry.Transform(mo.transformAt(t))})
+}
+
+// transformAt returns the transform which moves the geometry of the obstacle to where it is at a time.
+func (mo *MovingObstacle) transformAt(t time.Duration) spatialmath.Pose {
+	if len(mo.waypoints) == 0 {
+		return spatialmath.NewPoseFromPoint(mo.velocity.Mul(t.Seconds()))
+	}
+	pose := mo.waypoints[len(mo.waypoints)-1].Pose
+	for i, waypoint := range mo.waypoints {
+		if t > waypoint.Time {
+			continue
+		}
+		pose = waypoint.Pose
+		if i > 0 {
+			previous := mo.waypoints[i-1]
+			by := float64(t-previous.Time) / float64(waypoint.Time-previous.Time)
+			pose = spatialmath.Interpolate(previous.Pose, waypoint.Pose, by)
+		}
+		break
+	}
+	// the geometry is moved from its own pose to the one it has at the time
+	return spatialmath.Compose(pose, spatialmath.PoseInverse(mo.geometry.Pose()))
+}
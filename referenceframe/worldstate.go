@@ -3,8 +3,10 @@ package referenceframe
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/pkg/errors"
 	commonpb "go.viam.com/api/common/v1"
 
 	"go.viam.com/rdk/spatialmath"
@@ -14,9 +16,10 @@ const unnamedWorldStateGeometryPrefix = "unnamedWorldStateGeometry_"
 
 // WorldState is a struct to store the data representation of the robot's environment.
 type WorldState struct {
-	obstacleNames map[string]bool
-	obstacles     []*GeometriesInFrame
-	transforms    []*LinkInFrame
+	obstacleNames   map[string]bool
+	obstacles       []*GeometriesInFrame
+	movingObstacles []*MovingObstacle
+	transforms      []*LinkInFrame
 }
 
 // NewEmptyWorldState is a constructor for a WorldState object that has no obstacles or transforms.
@@ -79,7 +82,43 @@ func WorldStateFromProtobuf(proto *commonpb.WorldState) (*WorldState, error) {
 	return NewWorldState(allGeometries, transforms)
 }
 
-// ToProtobuf takes an rdk WorldState and converts it to the protobuf definition of a WorldState.
+// WithMovingObstacles returns a copy of the WorldState with the given moving obstacles added. Their names must be
+// unique among all of its obstacles.
+func (ws *WorldState) WithMovingObstacles(obstacles ...*MovingObstacle) (*WorldState, error) {
+	if ws == nil {
+		ws = NewEmptyWorldState()
+	}
+	names := map[string]bool{}
+	for _, mo := range ws.movingObstacles {
+		names[mo.Name()] = true
+	}
+	for _, mo := range obstacles {
+		if mo.Name() == "" {
+			return nil, errors.New("moving obstacles must be named")
+		}
+		if ws.obstacleNames[mo.Name()] || names[mo.Name()] {
+			return nil, NewDuplicateGeometryNameError(mo.Name())
+		}
+		names[mo.Name()] = true
+	}
+	return &WorldState{
+		obstacleNames:   ws.obstacleNames,
+		obstacles:       ws.obstacles,
+		movingObstacles: append(append([]*MovingObstacle{}, ws.movingObstacles...), obstacles...),
+		transforms:      ws.transforms,
+	}, nil
+}
+
+// MovingObstacles returns the moving obstacles that have been added to the WorldState.
+func (ws *WorldState) MovingObstacles() []*MovingObstacle {
+	if ws == nil {
+		return []*MovingObstacle{}
+	}
+	return ws.movingObstacles
+}
+
+// ToProtobuf takes an rdk WorldState and converts it to the protobuf definition of a WorldState. The protobuf has no
+// moving obstacles, so they are left out.
 func (ws *WorldState) ToProtobuf() (*commonpb.WorldState, error) {
 	if ws == nil {
 		return &commonpb.WorldState{}, nil
@@ -121,6 +160,13 @@ func (ws *WorldState) String() string {
 			})
 		}
 	}
+	for _, mo := range ws.movingObstacles {
+		t.AppendRow([]interface{}{
+			mo.Name(),
+			fmt.Sprint(mo.geometry),
+			mo.frame + " (moving)",
+		})
+	}
 	return t.Render()
 }
 
@@ -162,3 +208,22 @@ func (ws *WorldState) ObstaclesInWorldFrame(fs FrameSystem, inputs map[string][]
 	}
 	return NewGeometriesInFrame(World, allGeometries), nil
 }
+
+// MovingObstaclesInWorldFrame takes a frame system and a set of inputs for that frame system and returns the moving
+// obstacles in the WorldState where they are predicted to be at a time after a plan begins, in the frame system's
+// World reference frame.
+func (ws *WorldState) MovingObstaclesInWorldFrame(
+	fs FrameSystem,
+	inputs map[string][]Input,
+	t time.Duration,
+) (*GeometriesInFrame, error) {
+	allGeometries := make([]spatialmath.Geometry, 0, len(ws.MovingObstacles()))
+	for _, mo := range ws.MovingObstacles() {
+		tf, err := fs.Transform(inputs, mo.At(t), World)
+		if err != nil {
+			return nil, err
+		}
+		allGeometries = append(allGeometries, tf.(*GeometriesInFrame).Geometries()...)
+	}
+	return NewGeometriesInFrame(World, allGeometries), nil
+}
@@ -3,7 +3,9 @@ package referenceframe
 import (
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/golang/geo/r3"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"go.viam.com/test"
 
@@ -44,6 +46,68 @@ func TestWorldStateConstruction(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 }
 
+func TestMovingObstacles(t *testing.T) {
+	box, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(r3.Vector{X: 100}), r3.Vector{10, 10, 10}, "part")
+	test.That(t, err, test.ShouldBeNil)
+	person, err := spatialmath.NewSphere(spatialmath.NewZeroPose(), 300, "person")
+	test.That(t, err, test.ShouldBeNil)
+
+	// a part on a conveyor moves at a constant velocity from where it starts
+	part := NewMovingObstacle(World, box, r3.Vector{Y: 50})
+	test.That(t, part.At(0).Geometries()[0].Pose().Point(), test.ShouldResemble, r3.Vector{X: 100})
+	test.That(t, part.At(2 * time.Second).Geometries()[0].Pose().Point(), test.ShouldResemble, r3.Vector{X: 100, Y: 100})
+	test.That(t, part.At(2 * time.Second).Geometries()[0].Label(), test.ShouldEqual, "part")
+
+	// a person walks between poses, staying at the first before it and the last after it
+	walking, err := NewTimedObstacle("cell", person, []TimedPose{
+		{Time: 3 * time.Second, Pose: spatialmath.NewPoseFromPoint(r3.Vector{X: 1000})},
+		{Time: time.Second, Pose: spatialmath.NewPoseFromPoint(r3.Vector{Y: 1000})},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	for _, tc := range []struct {
+		t     time.Duration
+		point r3.Vector
+	}{
+		{0, r3.Vector{Y: 1000}},
+		{time.Second, r3.Vector{Y: 1000}},
+		{2 * time.Second, r3.Vector{X: 500, Y: 500}},
+		{10 * time.Second, r3.Vector{X: 1000}},
+	} {
+		point := walking.At(tc.t).Geometries()[0].Pose().Point()
+		test.That(t, spatialmath.R3VectorAlmostEqual(point, tc.point, 1e-6), test.ShouldBeTrue)
+	}
+	_, err = NewTimedObstacle("cell", person, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	ws, err := NewWorldState([]*GeometriesInFrame{NewGeometriesInFrame(World, []spatialmath.Geometry{person})}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	_, err = ws.WithMovingObstacles(walking)
+	test.That(t, err.Error(), test.ShouldResemble, NewDuplicateGeometryNameError("person").Error())
+	withPart, err := ws.WithMovingObstacles(part)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, withPart.MovingObstacles(), test.ShouldResemble, []*MovingObstacle{part})
+	test.That(t, ws.MovingObstacles(), test.ShouldBeEmpty)
+	_, err = withPart.WithMovingObstacles(part)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// the moving obstacles are moved into the world with the frames they move in
+	fs := NewEmptyFrameSystem("")
+	base, err := NewStaticFrame("base", spatialmath.NewPoseFromPoint(r3.Vector{Z: 100}))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(base, fs.World()), test.ShouldBeNil)
+	cell, err := NewStaticFrame("cell", spatialmath.NewZeroPose())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(cell, base), test.ShouldBeNil)
+	var empty *WorldState
+	withBoth, err := empty.WithMovingObstacles(part, walking)
+	test.That(t, err, test.ShouldBeNil)
+	gif, err := withBoth.MovingObstaclesInWorldFrame(fs, StartPositions(fs), 2*time.Second)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, gif.Geometries(), test.ShouldHaveLength, 2)
+	test.That(t, spatialmath.R3VectorAlmostEqual(gif.Geometries()[1].Pose().Point(), r3.Vector{X: 500, Y: 500, Z: 100}, 1e-6),
+		test.ShouldBeTrue)
+}
+
 func TestString(t *testing.T) {
 	foo, err := spatialmath.NewSphere(spatialmath.NewZeroPose(), 10, "foo")
 	test.That(t, err, test.ShouldBeNil)
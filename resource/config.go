@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/go-viper/mapstructure/v2"
@@ -23,6 +24,9 @@ type Config struct {
 	DependsOn        []string
 	LogConfiguration LogConfig
 	Attributes       utils.AttributeMap
+	// Tags address the resource as part of a group, such as every actuator tagged "drive". They are read from
+	// JSON configs only, as the config proto has no field for them.
+	Tags []string
 
 	AssociatedResourceConfigs []AssociatedResourceConfig
 	AssociatedAttributes      map[Name]AssociatedConfig
@@ -50,6 +54,7 @@ type typeSpecificConfigData struct {
 	LogConfiguration          LogConfig                  `json:"log_configuration"`
 	AssociatedResourceConfigs []AssociatedResourceConfig `json:"service_configs,omitempty"`
 	Attributes                utils.AttributeMap         `json:"attributes,omitempty"`
+	Tags                      []string                   `json:"tags,omitempty"`
 }
 
 // NOTE: This data must be maintained with what is in Config.
@@ -62,6 +67,7 @@ type configData struct {
 	LogConfiguration          LogConfig                  `json:"log_configuration"`
 	AssociatedResourceConfigs []AssociatedResourceConfig `json:"service_configs,omitempty"`
 	Attributes                utils.AttributeMap         `json:"attributes,omitempty"`
+	Tags                      []string                   `json:"tags,omitempty"`
 }
 
 // UnmarshalJSON unmarshals JSON into the config.
@@ -83,6 +89,7 @@ func (conf *Config) UnmarshalJSON(data []byte) error {
 		conf.LogConfiguration = confData.LogConfiguration
 		conf.AssociatedResourceConfigs = confData.AssociatedResourceConfigs
 		conf.Attributes = confData.Attributes
+		conf.Tags = confData.Tags
 		return nil
 	}
 
@@ -99,6 +106,7 @@ func (conf *Config) UnmarshalJSON(data []byte) error {
 	conf.LogConfiguration = typeSpecificConf.LogConfiguration
 	conf.AssociatedResourceConfigs = typeSpecificConf.AssociatedResourceConfigs
 	conf.Attributes = typeSpecificConf.Attributes
+	conf.Tags = typeSpecificConf.Tags
 	return nil
 }
 
@@ -113,6 +121,7 @@ func (conf Config) MarshalJSON() ([]byte, error) {
 		LogConfiguration:          conf.LogConfiguration,
 		AssociatedResourceConfigs: conf.AssociatedResourceConfigs,
 		Attributes:                conf.Attributes,
+		Tags:                      conf.Tags,
 	})
 }
 
@@ -208,6 +217,16 @@ func (conf Config) Equals(other Config) bool {
 	return reflect.DeepEqual(conf, other)
 }
 
+// HasTag returns whether the resource is tagged with the given tag.
+func (conf *Config) HasTag(tag string) bool {
+	for _, t := range conf.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
 // Dependencies returns the deduplicated union of user-defined and implicit dependencies.
 func (conf *Config) Dependencies() []string {
 	result := make([]string, 0, len(conf.DependsOn)+len(conf.ImplicitDependsOn))
@@ -303,6 +322,17 @@ func (conf *Config) validate(path, defaultAPIType string) ([]string, error) {
 		return nil, err
 	}
 
+	seenTags := make(map[string]struct{}, len(conf.Tags))
+	for idx, tag := range conf.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return nil, NewConfigValidationError(path, errors.Errorf("tag %d must not be empty", idx))
+		}
+		if _, ok := seenTags[tag]; ok {
+			return nil, NewConfigValidationError(path, errors.Errorf("tag %q is repeated", tag))
+		}
+		seenTags[tag] = struct{}{}
+	}
+
 	// this effectively checks reserved characters and the rest for namespace and type
 	if err := conf.API.Validate(); err != nil {
 		return nil, err
@@ -314,6 +344,22 @@ func (conf *Config) validate(path, defaultAPIType string) ([]string, error) {
 		}
 		deps = append(deps, validatedDeps...)
 	}
+
+	// validate in a stable order so that the same error is reported each time
+	associatedNames := make([]Name, 0, len(conf.AssociatedAttributes))
+	for name := range conf.AssociatedAttributes {
+		associatedNames = append(associatedNames, name)
+	}
+	sort.Slice(associatedNames, func(i, j int) bool { return associatedNames[i].String() < associatedNames[j].String() })
+	for _, name := range associatedNames {
+		validator, ok := conf.AssociatedAttributes[name].(AssociatedConfigValidator)
+		if !ok {
+			continue
+		}
+		if err := validator.Validate(path); err != nil {
+			return nil, NewConfigValidationError(path, errors.Wrapf(err, "associated config for %s", name))
+		}
+	}
 	return deps, nil
 }
 
@@ -1,6 +1,7 @@
 package resource_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"go.viam.com/test"
@@ -605,6 +606,55 @@ func TestServiceResourceName(t *testing.T) {
 	}
 }
 
+func TestTags(t *testing.T) {
+	var conf resource.Config
+	err := json.Unmarshal([]byte(`{"name": "left", "type": "motor", "model": "fake", "tags": ["drive", "telemetry"]}`), &conf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, conf.Tags, test.ShouldResemble, []string{"drive", "telemetry"})
+	test.That(t, conf.HasTag("drive"), test.ShouldBeTrue)
+	test.That(t, conf.HasTag("lift"), test.ShouldBeFalse)
+	_, err = conf.Validate("path", resource.APITypeComponentName)
+	test.That(t, err, test.ShouldBeNil)
+
+	md, err := json.Marshal(conf)
+	test.That(t, err, test.ShouldBeNil)
+	var roundTripped resource.Config
+	test.That(t, json.Unmarshal(md, &roundTripped), test.ShouldBeNil)
+	test.That(t, roundTripped.Tags, test.ShouldResemble, conf.Tags)
+
+	for _, tags := range [][]string{{"drive", " "}, {"drive", "drive"}} {
+		conf := resource.Config{Name: "left", API: arm.API, Model: fakeModel, Tags: tags}
+		_, err := conf.Validate("path", resource.APITypeComponentName)
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
+
+func TestValidateAssociatedConfigs(t *testing.T) {
+	newConf := func(assocConfs map[resource.Name]resource.AssociatedConfig) resource.Config {
+		return resource.Config{
+			Name:                 "foo",
+			API:                  arm.API,
+			Model:                resource.DefaultModelFamily.WithModel("fake"),
+			AssociatedAttributes: assocConfs,
+		}
+	}
+	conf := newConf(map[resource.Name]resource.AssociatedConfig{
+		arm.Named("foo"): &mockAssociatedConfig{},
+		arm.Named("bar"): &validatingAssociatedConfig{mockAssociatedConfig{Field1: "set"}},
+	})
+	_, err := conf.Validate("path", resource.APITypeComponentName)
+	test.That(t, err, test.ShouldBeNil)
+
+	conf = newConf(map[resource.Name]resource.AssociatedConfig{
+		arm.Named("foo"): &mockAssociatedConfig{},
+		arm.Named("baz"): &validatingAssociatedConfig{},
+	})
+	_, err = conf.Validate("path", resource.APITypeComponentName)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "associated config for rdk:component:arm/baz")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "field1")
+}
+
 func TestEqual(t *testing.T) {
 	t.Run("test associated config equality", func(t *testing.T) {
 		assocConfA := &mockAssociatedConfig{Field1: "foo", capName: arm.Named("foo")}
@@ -0,0 +1,171 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/pkg/errors"
+)
+
+// CommandKey is the key of a DoCommand payload which names the command to run.
+const CommandKey = "command"
+
+// CommandName returns the name of the command in a DoCommand payload.
+func CommandName(cmd map[string]interface{}) (string, error) {
+	raw, ok := cmd[CommandKey]
+	if !ok {
+		return "", errors.Errorf("DoCommand payload has no %q", CommandKey)
+	}
+	name, ok := raw.(string)
+	if !ok {
+		return "", errors.Errorf("DoCommand %q must be a string but got %T", CommandKey, raw)
+	}
+	return name, nil
+}
+
+// A CommandValidator is a DoCommand request or response which can check itself once decoded.
+type CommandValidator interface {
+	Validate() error
+}
+
+// DecodeCommand decodes a DoCommand payload into a struct by its json tags. Unlike a type assertion on each key, a
+// value of the wrong type, a fractional number for an integer field or a key the struct does not have is an error
+// naming the key, so a mistyped command fails rather than being silently ignored. The command key is only decoded
+// when the struct has a field for it. The struct is then validated when it is a CommandValidator.
+func DecodeCommand[T any](cmd map[string]interface{}) (T, error) {
+	var out T
+	var md mapstructure.Metadata
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		TagName:    "json",
+		Result:     &out,
+		Metadata:   &md,
+		DecodeHook: exactNumberHook,
+	})
+	if err != nil {
+		return out, err
+	}
+	if err := decoder.Decode(cmd); err != nil {
+		return out, errors.Wrap(err, "invalid DoCommand payload")
+	}
+	var unknown []string
+	for _, key := range md.Unused {
+		if key != CommandKey {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return out, errors.Errorf("invalid DoCommand payload: unknown keys %q", unknown)
+	}
+	if validator, ok := any(&out).(CommandValidator); ok {
+		if err := validator.Validate(); err != nil {
+			return out, errors.Wrap(err, "invalid DoCommand payload")
+		}
+	}
+	return out, nil
+}
+
+// exactNumberHook refuses to decode numbers into integer fields they do not fit exactly, which mapstructure would
+// otherwise truncate. Numbers arrive as float64 since that is how JSON and protobuf structs carry them.
+func exactNumberHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.Float32 && from.Kind() != reflect.Float64 {
+		return data, nil
+	}
+	value := reflect.ValueOf(data).Float()
+	switch to.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value != math.Trunc(value) || reflect.Zero(to).OverflowInt(int64(value)) {
+			return nil, errors.Errorf("%v is not a %s", value, to)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if value != math.Trunc(value) || value < 0 || reflect.Zero(to).OverflowUint(uint64(value)) {
+			return nil, errors.Errorf("%v is not a %s", value, to)
+		}
+	default:
+	}
+	return data, nil
+}
+
+// EncodeCommand encodes a struct into a DoCommand payload by its json tags, with the command key set to name
+// unless name is empty. The payload holds the same types it would after being sent to a resource, with every
+// number a float64, so it can be passed to a resource in the same process or a remote one alike.
+func EncodeCommand(name string, v interface{}) (map[string]interface{}, error) {
+	if validator, ok := v.(CommandValidator); ok {
+		if err := validator.Validate(); err != nil {
+			return nil, errors.Wrap(err, "invalid DoCommand payload")
+		}
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	cmd := map[string]interface{}{}
+	if string(data) != "null" {
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			return nil, errors.Errorf("DoCommand payload must encode to an object, not %s", data)
+		}
+	}
+	if name != "" {
+		cmd[CommandKey] = name
+	}
+	return cmd, nil
+}
+
+// DoTypedCommand sends a command with a typed request to a resource and decodes its response.
+func DoTypedCommand[Resp, Req any](ctx context.Context, res Resource, name string, req Req) (Resp, error) {
+	var zero Resp
+	cmd, err := EncodeCommand(name, req)
+	if err != nil {
+		return zero, err
+	}
+	resp, err := res.DoCommand(ctx, cmd)
+	if err != nil {
+		return zero, err
+	}
+	return DecodeCommand[Resp](resp)
+}
+
+// A CommandHandler runs one DoCommand.
+type CommandHandler func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error)
+
+// TypedCommandHandler makes a CommandHandler of a function of a typed request and response, decoding the request
+// from the payload and encoding the response.
+func TypedCommandHandler[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) CommandHandler {
+	return func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		req, err := DecodeCommand[Req](cmd)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := fn(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return EncodeCommand("", resp)
+	}
+}
+
+// CommandHandlers runs DoCommands by the handler of their name. A resource can implement DoCommand with it:
+//
+//	func (s *mySensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+//		return resource.CommandHandlers{
+//			"set_rate": resource.TypedCommandHandler(s.setRate),
+//		}.DoCommand(ctx, cmd)
+//	}
+type CommandHandlers map[string]CommandHandler
+
+// DoCommand runs the handler named by the command, returning ErrDoUnimplemented for a command without one.
+func (h CommandHandlers) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, err := CommandName(cmd)
+	if err != nil {
+		return nil, err
+	}
+	handler, ok := h[name]
+	if !ok {
+		return nil, errors.Wrapf(ErrDoUnimplemented, "unknown command %q", name)
+	}
+	return handler(ctx, cmd)
+}
@@ -0,0 +1,333 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// DoCommand streams let a long-running DoCommand, such as flashing firmware or a calibration routine, send progress and
+// partial results as it goes rather than block a unary call for minutes. The DoCommand API has no streaming call, so
+// over the network a stream is carried by unary DoCommands: a command sent with StreamKey set starts in the background
+// and returns the ID of its stream, which is then polled with StreamNextCommand until it is done, or cancelled with
+// StreamCancelCommand. DoCommandStream does this for the caller, and calls DoCommandStream directly on a resource in
+// the same process which is a CommandStreamer.
+const (
+	// StreamKey is the key of a DoCommand payload which asks for the command to be streamed.
+	StreamKey = "stream"
+	// StreamIDKey is the key of the ID of a stream in the response starting it and in the commands polling it.
+	StreamIDKey = "stream_id"
+	// StreamNextCommand polls a stream for the messages sent since it was last polled, waiting up to "wait_sec", 1
+	// second by default, for one to be sent or for the command to finish.
+	StreamNextCommand = "stream_next"
+	// StreamCancelCommand cancels a stream.
+	StreamCancelCommand = "stream_cancel"
+)
+
+const (
+	defaultStreamWait    = time.Second
+	maxStreamWait        = 30 * time.Second
+	maxStreamQueued      = 256
+	streamAbandonTimeout = time.Minute
+)
+
+// A StreamingCommandHandler runs one DoCommand, sending progress and partial results with send as it goes and returning
+// its final result. Send blocks while the messages already sent have not been read, and returns an error once the
+// stream is cancelled.
+type StreamingCommandHandler func(
+	ctx context.Context, cmd map[string]interface{}, send func(msg map[string]interface{}) error,
+) (map[string]interface{}, error)
+
+// A CommandStreamer is a resource which can stream the responses of DoCommands in the same process.
+type CommandStreamer interface {
+	DoCommandStream(
+		ctx context.Context, cmd map[string]interface{}, send func(msg map[string]interface{}) error,
+	) (map[string]interface{}, error)
+}
+
+// DoCommandStream runs a command on a resource, calling fn with each message it streams, and returns its final
+// result. A resource which does not stream the command runs it as a unary DoCommand, returning its response as the
+// result without any messages. An error from fn cancels the command.
+func DoCommandStream(
+	ctx context.Context, res Resource, cmd map[string]interface{}, fn func(msg map[string]interface{}) error,
+) (map[string]interface{}, error) {
+	if streamer, ok := res.(CommandStreamer); ok {
+		return streamer.DoCommandStream(ctx, cmd, fn)
+	}
+
+	streamCmd := make(map[string]interface{}, len(cmd)+1)
+	for key, value := range cmd {
+		streamCmd[key] = value
+	}
+	streamCmd[StreamKey] = true
+	resp, err := res.DoCommand(ctx, streamCmd)
+	if err != nil {
+		return nil, err
+	}
+	id, ok := resp[StreamIDKey].(string)
+	if !ok {
+		return resp, nil
+	}
+
+	cancel := func() {
+		cancelCtx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelFn()
+		//nolint:errcheck
+		res.DoCommand(cancelCtx, map[string]interface{}{CommandKey: StreamCancelCommand, StreamIDKey: id})
+	}
+	for {
+		next, err := res.DoCommand(ctx, map[string]interface{}{CommandKey: StreamNextCommand, StreamIDKey: id})
+		if err != nil {
+			if ctx.Err() != nil {
+				cancel()
+			}
+			return nil, err
+		}
+		messages, _ := next["messages"].([]interface{})
+		for _, raw := range messages {
+			msg, ok := raw.(map[string]interface{})
+			if !ok {
+				cancel()
+				return nil, errors.Errorf("stream message must be an object, not %T", raw)
+			}
+			if err := fn(msg); err != nil {
+				cancel()
+				return nil, err
+			}
+		}
+		if done, _ := next["done"].(bool); !done {
+			continue
+		}
+		if errMsg, ok := next["error"].(string); ok {
+			return nil, errors.New(errMsg)
+		}
+		result, _ := next["result"].(map[string]interface{})
+		return result, nil
+	}
+}
+
+// CommandStreams runs streaming DoCommands by the handler of their name, both in the same process and over the
+// network. A resource can stream its commands with it:
+//
+//	func (f *flasher) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+//		return f.streams.DoCommand(ctx, cmd)
+//	}
+//
+//	func (f *flasher) DoCommandStream(
+//		ctx context.Context, cmd map[string]interface{}, send func(msg map[string]interface{}) error,
+//	) (map[string]interface{}, error) {
+//		return f.streams.DoCommandStream(ctx, cmd, send)
+//	}
+//
+// and must Close it when it closes, which cancels the commands still streaming.
+type CommandStreams struct {
+	handlers  map[string]StreamingCommandHandler
+	cancelCtx context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+
+	mu      sync.Mutex
+	streams map[string]*commandStream
+}
+
+// NewCommandStreams returns CommandStreams running the given handlers.
+func NewCommandStreams(handlers map[string]StreamingCommandHandler) *CommandStreams {
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	return &CommandStreams{
+		handlers:  handlers,
+		cancelCtx: cancelCtx,
+		cancel:    cancel,
+		streams:   map[string]*commandStream{},
+	}
+}
+
+// Handlers returns unary handlers of the streaming commands and of the commands polling and cancelling streams, so
+// unary commands can be added to them. A streaming command sent without StreamKey runs to completion, its messages
+// discarded, so callers which do not stream still get its result.
+func (s *CommandStreams) Handlers() CommandHandlers {
+	handlers := CommandHandlers{
+		StreamNextCommand:   s.next,
+		StreamCancelCommand: s.cancelStream,
+	}
+	for name, handler := range s.handlers {
+		handler := handler
+		handlers[name] = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+			if stream, _ := cmd[StreamKey].(bool); stream {
+				return s.start(cmd, handler)
+			}
+			return handler(ctx, cmd, func(map[string]interface{}) error { return nil })
+		}
+	}
+	return handlers
+}
+
+// DoCommand runs the handler named by the command, returning ErrDoUnimplemented for a command without one.
+func (s *CommandStreams) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return s.Handlers().DoCommand(ctx, cmd)
+}
+
+// DoCommandStream runs the streaming handler named by the command in the same process, calling send with each message.
+func (s *CommandStreams) DoCommandStream(
+	ctx context.Context, cmd map[string]interface{}, send func(msg map[string]interface{}) error,
+) (map[string]interface{}, error) {
+	name, err := CommandName(cmd)
+	if err != nil {
+		return nil, err
+	}
+	handler, ok := s.handlers[name]
+	if !ok {
+		return nil, errors.Wrapf(ErrDoUnimplemented, "unknown streaming command %q", name)
+	}
+	return handler(ctx, cmd, send)
+}
+
+// Close cancels the commands still streaming and waits for them to return.
+func (s *CommandStreams) Close() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// commandStream is a command running in the background, whose messages are held until they are polled.
+type commandStream struct {
+	cancel context.CancelFunc
+	// changed is closed and replaced whenever a message is sent or the command finishes
+	changed  chan struct{}
+	messages []interface{}
+	done     bool
+	result   map[string]interface{}
+	err      error
+	polled   time.Time
+}
+
+// start runs a command in the background, returning the ID of its stream.
+func (s *CommandStreams) start(cmd map[string]interface{}, handler StreamingCommandHandler) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purgeAbandoned()
+	if s.cancelCtx.Err() != nil {
+		return nil, errors.New("command streams are closed")
+	}
+	ctx, cancel := context.WithCancel(s.cancelCtx)
+	id := uuid.NewString()
+	stream := &commandStream{cancel: cancel, changed: make(chan struct{}), polled: time.Now()}
+	s.streams[id] = stream
+
+	send := func(msg map[string]interface{}) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		// the command waits for the messages already sent to be read rather than hold them without bound
+		for len(stream.messages) >= maxStreamQueued {
+			changed := stream.changed
+			s.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				s.mu.Lock()
+				return ctx.Err()
+			case <-changed:
+			}
+			s.mu.Lock()
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		stream.messages = append(stream.messages, msg)
+		stream.notify()
+		return nil
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+		result, err := handler(ctx, cmd, send)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		stream.done = true
+		stream.result, stream.err = result, err
+		stream.notify()
+	}()
+	return map[string]interface{}{StreamIDKey: id}, nil
+}
+
+// next returns the messages sent on a stream since it was last polled, once there are any or its command is done,
+// or after waiting for them for a while. A stream is forgotten once its result is returned.
+func (s *CommandStreams) next(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	id, _ := cmd[StreamIDKey].(string)
+	wait := defaultStreamWait
+	if waitSec, ok := cmd["wait_sec"].(float64); ok && waitSec >= 0 {
+		wait = time.Duration(waitSec * float64(time.Second))
+		if wait > maxStreamWait {
+			wait = maxStreamWait
+		}
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		stream, ok := s.streams[id]
+		if !ok {
+			return nil, errors.Errorf("no stream %q", id)
+		}
+		stream.polled = time.Now()
+		if len(stream.messages) > 0 || stream.done {
+			resp := map[string]interface{}{"messages": stream.messages, "done": stream.done}
+			stream.messages = nil
+			stream.notify()
+			if stream.done {
+				delete(s.streams, id)
+				if stream.err != nil {
+					resp["error"] = stream.err.Error()
+				} else if stream.result != nil {
+					resp["result"] = stream.result
+				}
+			}
+			return resp, nil
+		}
+		changed := stream.changed
+		s.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			s.mu.Lock()
+			return nil, ctx.Err()
+		case <-timer.C:
+			s.mu.Lock()
+			return map[string]interface{}{"messages": []interface{}{}, "done": false}, nil
+		case <-changed:
+			s.mu.Lock()
+		}
+	}
+}
+
+// cancelStream cancels the command of a stream and forgets it.
+func (s *CommandStreams) cancelStream(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	id, _ := cmd[StreamIDKey].(string)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stream, ok := s.streams[id]
+	if !ok {
+		return nil, errors.Errorf("no stream %q", id)
+	}
+	stream.cancel()
+	delete(s.streams, id)
+	return map[string]interface{}{}, nil
+}
+
+// purgeAbandoned cancels and forgets the streams which have not been polled for a while, whose callers have gone.
+func (s *CommandStreams) purgeAbandoned() {
+	for id, stream := range s.streams {
+		if time.Since(stream.polled) > streamAbandonTimeout {
+			stream.cancel()
+			delete(s.streams, id)
+		}
+	}
+}
+
+// notify wakes whoever waits for the stream to change.
+func (cs *commandStream) notify() {
+	close(cs.changed)
+	cs.changed = make(chan struct{})
+}
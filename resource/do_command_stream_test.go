@@ -0,0 +1,145 @@
+package resource_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+)
+
+type streamingResource struct {
+	resource.Named
+	resource.TriviallyReconfigurable
+	streams *resource.CommandStreams
+}
+
+func (r *streamingResource) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return r.streams.DoCommand(ctx, cmd)
+}
+
+func (r *streamingResource) DoCommandStream(
+	ctx context.Context, cmd map[string]interface{}, send func(msg map[string]interface{}) error,
+) (map[string]interface{}, error) {
+	return r.streams.DoCommandStream(ctx, cmd, send)
+}
+
+func (r *streamingResource) Close(ctx context.Context) error {
+	r.streams.Close()
+	return nil
+}
+
+func TestDoCommandStream(t *testing.T) {
+	ctx := context.Background()
+	name := resource.NewName(resource.APINamespaceRDK.WithComponentType("board"), "board1").AsNamed()
+
+	started := make(chan struct{}, 1)
+	cancelled := make(chan struct{}, 1)
+	streams := resource.NewCommandStreams(map[string]resource.StreamingCommandHandler{
+		"flash": func(
+			ctx context.Context, cmd map[string]interface{}, send func(msg map[string]interface{}) error,
+		) (map[string]interface{}, error) {
+			// more messages than are queued, so sending waits for them to be read
+			for i := 0; i < 300; i++ {
+				if err := send(map[string]interface{}{"percent": float64(i)}); err != nil {
+					return nil, err
+				}
+			}
+			return map[string]interface{}{"version": cmd["version"]}, nil
+		},
+		"fail": func(
+			ctx context.Context, cmd map[string]interface{}, send func(msg map[string]interface{}) error,
+		) (map[string]interface{}, error) {
+			if err := send(map[string]interface{}{"step": "erase"}); err != nil {
+				return nil, err
+			}
+			return nil, errors.New("flash failed")
+		},
+		"calibrate": func(
+			ctx context.Context, cmd map[string]interface{}, send func(msg map[string]interface{}) error,
+		) (map[string]interface{}, error) {
+			started <- struct{}{}
+			<-ctx.Done()
+			cancelled <- struct{}{}
+			return nil, ctx.Err()
+		},
+	})
+	local := &streamingResource{Named: name, streams: streams}
+	defer local.Close(ctx)
+	// a resource over the network has only its unary DoCommand
+	remote := &commandResource{Named: name, handlers: streams.Handlers()}
+
+	for _, tc := range []struct {
+		name string
+		res  resource.Resource
+	}{
+		{"local", local},
+		{"remote", remote},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var percents []float64
+			result, err := resource.DoCommandStream(ctx, tc.res,
+				map[string]interface{}{"command": "flash", "version": "1.2.0"},
+				func(msg map[string]interface{}) error {
+					percents = append(percents, msg["percent"].(float64))
+					return nil
+				})
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, result, test.ShouldResemble, map[string]interface{}{"version": "1.2.0"})
+			test.That(t, percents, test.ShouldHaveLength, 300)
+			test.That(t, percents[299], test.ShouldEqual, 299.)
+
+			var steps []interface{}
+			_, err = resource.DoCommandStream(ctx, tc.res, map[string]interface{}{"command": "fail"},
+				func(msg map[string]interface{}) error {
+					steps = append(steps, msg["step"])
+					return nil
+				})
+			test.That(t, err, test.ShouldBeError, errors.New("flash failed"))
+			test.That(t, steps, test.ShouldResemble, []interface{}{"erase"})
+
+			_, err = resource.DoCommandStream(ctx, tc.res, map[string]interface{}{"command": "nope"},
+				func(msg map[string]interface{}) error { return nil })
+			test.That(t, errors.Is(err, resource.ErrDoUnimplemented), test.ShouldBeTrue)
+
+			// cancelling the caller cancels the command
+			cancelCtx, cancel := context.WithCancel(ctx)
+			go func() {
+				<-started
+				cancel()
+			}()
+			_, err = resource.DoCommandStream(cancelCtx, tc.res, map[string]interface{}{"command": "calibrate"},
+				func(msg map[string]interface{}) error { return nil })
+			test.That(t, errors.Is(err, context.Canceled), test.ShouldBeTrue)
+			<-cancelled
+		})
+	}
+
+	t.Run("unary", func(t *testing.T) {
+		// a streaming command sent as a plain DoCommand runs to completion
+		resp, err := remote.DoCommand(ctx, map[string]interface{}{"command": "flash", "version": "2.0.0"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp, test.ShouldResemble, map[string]interface{}{"version": "2.0.0"})
+
+		// and a unary command is returned as the result of a stream
+		res := &commandResource{Named: name, handlers: resource.CommandHandlers{
+			"status": func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+				return map[string]interface{}{"ok": true}, nil
+			},
+		}}
+		result, err := resource.DoCommandStream(ctx, res, map[string]interface{}{"command": "status"},
+			func(msg map[string]interface{}) error { return nil })
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, result, test.ShouldResemble, map[string]interface{}{"ok": true})
+	})
+
+	t.Run("callback error", func(t *testing.T) {
+		// an error from the callback stops the stream and cancels the command
+		stop := errors.New("seen enough")
+		_, err := resource.DoCommandStream(ctx, remote, map[string]interface{}{"command": "flash"},
+			func(msg map[string]interface{}) error { return stop })
+		test.That(t, err, test.ShouldEqual, stop)
+	})
+}
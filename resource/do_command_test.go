@@ -0,0 +1,140 @@
+package resource_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+)
+
+type setRateRequest struct {
+	RateHz  float64           `json:"rate_hz"`
+	Samples int               `json:"samples,omitempty"`
+	Pins    []string          `json:"pins,omitempty"`
+	Window  *setRateWindow    `json:"window,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+type setRateWindow struct {
+	StartMS uint `json:"start_ms"`
+	EndMS   uint `json:"end_ms"`
+}
+
+func (r *setRateRequest) Validate() error {
+	if r.RateHz <= 0 {
+		return errors.New("rate_hz must be positive")
+	}
+	return nil
+}
+
+type setRateResponse struct {
+	PreviousHz float64 `json:"previous_hz"`
+}
+
+func TestDecodeCommand(t *testing.T) {
+	req, err := resource.DecodeCommand[setRateRequest](map[string]interface{}{
+		"command": "set_rate",
+		"rate_hz": 10.,
+		"samples": 3.,
+		"pins":    []interface{}{"11", "13"},
+		"window":  map[string]interface{}{"start_ms": 0., "end_ms": 500.},
+		"labels":  map[string]interface{}{"site": "lab"},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, req, test.ShouldResemble, setRateRequest{
+		RateHz:  10,
+		Samples: 3,
+		Pins:    []string{"11", "13"},
+		Window:  &setRateWindow{StartMS: 0, EndMS: 500},
+		Labels:  map[string]string{"site": "lab"},
+	})
+
+	for _, bad := range []map[string]interface{}{
+		{"rate_hz": "fast"},
+		{"rate_hz": 10., "samples": 2.5},
+		{"rate_hz": 10., "window": map[string]interface{}{"start_ms": -1.}},
+		{"rate_hz": 10., "sampels": 3.},
+		{"rate_hz": 10., "window": map[string]interface{}{"start": 1.}},
+		{"rate_hz": 10., "pins": []interface{}{11.}},
+		{"rate_hz": -1.},
+	} {
+		_, err := resource.DecodeCommand[setRateRequest](bad)
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+	_, err = resource.DecodeCommand[setRateRequest](map[string]interface{}{"rate_hz": 10., "sampels": 3., "extra": true})
+	test.That(t, err.Error(), test.ShouldContainSubstring, `unknown keys ["extra" "sampels"]`)
+	_, err = resource.DecodeCommand[setRateRequest](map[string]interface{}{"rate_hz": 10., "samples": 2.5})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "samples")
+}
+
+func TestEncodeCommand(t *testing.T) {
+	cmd, err := resource.EncodeCommand("set_rate", setRateRequest{RateHz: 5, Samples: 2, Window: &setRateWindow{EndMS: 100}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cmd, test.ShouldResemble, map[string]interface{}{
+		"command": "set_rate",
+		"rate_hz": 5.,
+		"samples": 2.,
+		"window":  map[string]interface{}{"start_ms": 0., "end_ms": 100.},
+	})
+	name, err := resource.CommandName(cmd)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, name, test.ShouldEqual, "set_rate")
+
+	_, err = resource.EncodeCommand("set_rate", &setRateRequest{})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = resource.EncodeCommand("set_rate", []int{1})
+	test.That(t, err, test.ShouldNotBeNil)
+	cmd, err = resource.EncodeCommand("status", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cmd, test.ShouldResemble, map[string]interface{}{"command": "status"})
+
+	_, err = resource.CommandName(map[string]interface{}{})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = resource.CommandName(map[string]interface{}{"command": 1.})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestCommandHandlers(t *testing.T) {
+	ctx := context.Background()
+	rate := 1.
+	handlers := resource.CommandHandlers{
+		"set_rate": resource.TypedCommandHandler(func(ctx context.Context, req setRateRequest) (setRateResponse, error) {
+			previous := rate
+			rate = req.RateHz
+			return setRateResponse{PreviousHz: previous}, nil
+		}),
+	}
+	res := &commandResource{
+		Named:    resource.NewName(resource.APINamespaceRDK.WithComponentType("sensor"), "sensor1").AsNamed(),
+		handlers: handlers,
+	}
+
+	resp, err := resource.DoTypedCommand[setRateResponse](ctx, res, "set_rate", setRateRequest{RateHz: 20})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp.PreviousHz, test.ShouldEqual, 1.)
+	test.That(t, rate, test.ShouldEqual, 20.)
+
+	raw, err := res.DoCommand(ctx, map[string]interface{}{"command": "set_rate", "rate_hz": 30.})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, raw, test.ShouldResemble, map[string]interface{}{"previous_hz": 20.})
+
+	_, err = res.DoCommand(ctx, map[string]interface{}{"command": "set_rate", "rate": 30.})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, rate, test.ShouldEqual, 30.)
+	_, err = res.DoCommand(ctx, map[string]interface{}{"command": "reboot"})
+	test.That(t, errors.Is(err, resource.ErrDoUnimplemented), test.ShouldBeTrue)
+}
+
+type commandResource struct {
+	resource.Named
+	resource.TriviallyReconfigurable
+	resource.TriviallyCloseable
+	handlers resource.CommandHandlers
+}
+
+func (r *commandResource) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return r.handlers.DoCommand(ctx, cmd)
+}
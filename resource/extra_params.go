@@ -0,0 +1,160 @@
+package resource
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ExtraParamType is the JSON type of the value of an extra parameter.
+type ExtraParamType string
+
+// The types of extra parameters.
+const (
+	ExtraParamString = ExtraParamType("string")
+	ExtraParamNumber = ExtraParamType("number")
+	ExtraParamBool   = ExtraParamType("bool")
+	ExtraParamObject = ExtraParamType("object")
+	ExtraParamList   = ExtraParamType("list")
+)
+
+// ExtraParam describes a key of the extra argument of a method that a model understands.
+type ExtraParam struct {
+	Name        string         `json:"name"`
+	Type        ExtraParamType `json:"type"`
+	Required    bool           `json:"required,omitempty"`
+	Description string         `json:"description,omitempty"`
+	// Default is used when the parameter is not passed. It is ignored for required parameters and leaving it nil
+	// passes nothing.
+	Default interface{} `json:"default,omitempty"`
+}
+
+// checkValue returns an error if v, as decoded from JSON or a protobuf struct, is not of the type of the parameter.
+func (p ExtraParam) checkValue(v interface{}) error {
+	var ok bool
+	switch p.Type {
+	case ExtraParamString:
+		_, ok = v.(string)
+	case ExtraParamNumber:
+		switch v.(type) {
+		case float64, float32, int, int32, int64, uint, uint32, uint64:
+			ok = true
+		}
+	case ExtraParamBool:
+		_, ok = v.(bool)
+	case ExtraParamObject:
+		_, ok = v.(map[string]interface{})
+	case ExtraParamList:
+		_, ok = v.([]interface{})
+	default:
+		return errors.Errorf("extra param %q has unknown type %q", p.Name, p.Type)
+	}
+	if !ok {
+		return errors.Errorf("extra param %q must be a %s but got %T", p.Name, p.Type, v)
+	}
+	return nil
+}
+
+// ExtraParams are the extra parameters a model understands, by the name of the API method which takes them, such as
+// "MoveToPosition". Methods which are not listed take any extra.
+type ExtraParams map[string][]ExtraParam
+
+// validate checks that every parameter has a known type and a default of that type.
+func (ep ExtraParams) validate() error {
+	for method, params := range ep {
+		seen := map[string]bool{}
+		for _, param := range params {
+			if param.Name == "" {
+				return errors.Errorf("extra param of %s has no name", method)
+			}
+			if seen[param.Name] {
+				return errors.Errorf("extra param %q of %s is declared twice", param.Name, method)
+			}
+			seen[param.Name] = true
+			switch param.Type {
+			case ExtraParamString, ExtraParamNumber, ExtraParamBool, ExtraParamObject, ExtraParamList:
+			default:
+				return errors.Errorf("extra param %q of %s has unknown type %q", param.Name, method, param.Type)
+			}
+			if param.Default != nil {
+				if err := param.checkValue(param.Default); err != nil {
+					return errors.Wrapf(err, "default of %s", method)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateExtra checks the extra passed to a method against the parameters declared for it, returning a copy of
+// extra with defaults filled in. Unknown keys, values of the wrong type and missing required parameters are errors
+// naming the accepted parameters, so that a misspelled key fails instead of being silently ignored.
+func ValidateExtra(method string, params []ExtraParam, extra map[string]interface{}) (map[string]interface{}, error) {
+	byName := make(map[string]ExtraParam, len(params))
+	for _, param := range params {
+		byName[param.Name] = param
+	}
+	withDefaults := make(map[string]interface{}, len(extra)+len(params))
+	var unknown []string
+	for key, val := range extra {
+		param, ok := byName[key]
+		if !ok {
+			unknown = append(unknown, key)
+			continue
+		}
+		if err := param.checkValue(val); err != nil {
+			return nil, errors.Wrapf(err, "invalid extra for %s", method)
+		}
+		withDefaults[key] = val
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, errors.Errorf("unknown extra keys %q for %s, must be one of [%s]",
+			unknown, method, strings.Join(extraParamNames(params), ", "))
+	}
+	for _, param := range params {
+		if _, ok := withDefaults[param.Name]; ok {
+			continue
+		}
+		if param.Required {
+			return nil, errors.Errorf("invalid extra for %s, must supply %q", method, param.Name)
+		}
+		if param.Default != nil {
+			withDefaults[param.Name] = param.Default
+		}
+	}
+	return withDefaults, nil
+}
+
+func extraParamNames(params []ExtraParam) []string {
+	names := make([]string, 0, len(params))
+	for _, param := range params {
+		names = append(names, param.Name)
+	}
+	return names
+}
+
+// LookupExtraParams returns the extra parameters the given model declared for a method of its API. It returns false
+// if the model is not registered in this process or takes any extra for the method.
+func LookupExtraParams(api API, model Model, method string) ([]ExtraParam, bool) {
+	reg, ok := LookupRegistration(api, model)
+	if !ok {
+		return nil, false
+	}
+	params, ok := reg.ExtraParams[method]
+	return params, ok
+}
+
+// HasExtraParams returns whether any model of the API declared extra parameters for the method, so that callers can
+// skip looking up the model of a resource when none did.
+func HasExtraParams(api API, method string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for apiModel, reg := range registry {
+		if _, ok := reg.ExtraParams[method]; ok && apiModel.API == api {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package resource_test
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+func TestValidateExtra(t *testing.T) {
+	params := []resource.ExtraParam{
+		{Name: "speed", Type: resource.ExtraParamNumber, Default: 10.},
+		{Name: "mode", Type: resource.ExtraParamString, Required: true},
+		{Name: "dry_run", Type: resource.ExtraParamBool},
+		{Name: "waypoints", Type: resource.ExtraParamList},
+	}
+
+	extra, err := resource.ValidateExtra("Move", params, map[string]interface{}{"mode": "fast"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, extra, test.ShouldResemble, map[string]interface{}{"mode": "fast", "speed": 10.})
+
+	extra, err = resource.ValidateExtra("Move", params, map[string]interface{}{
+		"mode": "slow", "speed": 2., "dry_run": true, "waypoints": []interface{}{1., 2.},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, extra, test.ShouldResemble, map[string]interface{}{
+		"mode": "slow", "speed": 2., "dry_run": true, "waypoints": []interface{}{1., 2.},
+	})
+
+	_, err = resource.ValidateExtra("Move", params, map[string]interface{}{"mode": "fast", "sped": 2.})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `unknown extra keys ["sped"] for Move`)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "speed, mode, dry_run, waypoints")
+	_, err = resource.ValidateExtra("Move", params, map[string]interface{}{"mode": "fast", "speed": "2"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"speed" must be a number`)
+	_, err = resource.ValidateExtra("Move", params, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `must supply "mode"`)
+}
+
+func TestRegisterExtraParams(t *testing.T) {
+	api := resource.APINamespace("acme").WithComponentType("extra")
+	model := resource.DefaultModelFamily.WithModel("extra")
+	constructor := func(
+		ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
+	) (resource.Resource, error) {
+		return nil, nil
+	}
+
+	test.That(t, func() {
+		resource.RegisterComponent(api, model, resource.Registration[resource.Resource, resource.NoNativeConfig]{
+			Constructor: constructor,
+			ExtraParams: resource.ExtraParams{"Move": {{Name: "speed", Type: "float"}}},
+		})
+	}, test.ShouldPanic)
+	test.That(t, func() {
+		resource.RegisterComponent(api, model, resource.Registration[resource.Resource, resource.NoNativeConfig]{
+			Constructor: constructor,
+			ExtraParams: resource.ExtraParams{"Move": {{Name: "speed", Type: resource.ExtraParamNumber, Default: "fast"}}},
+		})
+	}, test.ShouldPanic)
+	test.That(t, resource.HasExtraParams(api, "Move"), test.ShouldBeFalse)
+
+	resource.RegisterComponent(api, model, resource.Registration[resource.Resource, resource.NoNativeConfig]{
+		Constructor: constructor,
+		ExtraParams: resource.ExtraParams{"Move": {{Name: "speed", Type: resource.ExtraParamNumber}}},
+	})
+	defer resource.Deregister(api, model)
+	test.That(t, resource.HasExtraParams(api, "Move"), test.ShouldBeTrue)
+	test.That(t, resource.HasExtraParams(api, "Stop"), test.ShouldBeFalse)
+	params, ok := resource.LookupExtraParams(api, model, "Move")
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, params, test.ShouldResemble, []resource.ExtraParam{{Name: "speed", Type: resource.ExtraParamNumber}})
+	_, ok = resource.LookupExtraParams(api, model, "Stop")
+	test.That(t, ok, test.ShouldBeFalse)
+	_, ok = resource.LookupExtraParams(api, resource.DefaultModelFamily.WithModel("other"), "Move")
+	test.That(t, ok, test.ShouldBeFalse)
+}
@@ -231,6 +231,14 @@ func (w *GraphNode) LogAndSetLastError(err error, args ...any) {
 	}
 }
 
+// LastError returns the error set by LogAndSetLastError, which is cleared once the resource is built or
+// reconfigured successfully.
+func (w *GraphNode) LastError() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.lastErr
+}
+
 // Config returns the current config that this resource is using.
 // This value should only be assumed to be associated with the current
 // resource.
@@ -203,6 +203,15 @@ func (a AlwaysRebuild) Reconfigure(ctx context.Context, deps Dependencies, conf
 	return NewMustRebuildError(conf.ResourceName())
 }
 
+func (a AlwaysRebuild) alwaysRebuild() {}
+
+// AlwaysRebuilds returns whether a resource embeds AlwaysRebuild, and so is rebuilt rather than reconfigured when its
+// config changes.
+func AlwaysRebuilds(res Resource) bool {
+	_, ok := res.(interface{ alwaysRebuild() })
+	return ok
+}
+
 // Named is to be embedded by any resource that just needs to return a name.
 type Named interface {
 	Name() Name
@@ -130,6 +130,10 @@ type Registration[ResourceT Resource, ConfigT any] struct {
 	// Discover looks around for information about this specific model.
 	Discover DiscoveryFunc
 
+	// ExtraParams declares the keys of extra that this model understands for methods of its API. Calls made through
+	// the API with other keys, or values of the wrong type, are refused.
+	ExtraParams ExtraParams
+
 	// configType can be used to dynamically inspect the resource config type.
 	configType reflect.Type
 
@@ -189,6 +193,22 @@ type AssociatedConfig interface {
 	Link(conf *Config)
 }
 
+// AssociatedConfigValidator is implemented by an AssociatedConfig that can validate itself once it has been linked to
+// a resource config, so that mistakes are reported as config errors of that resource.
+type AssociatedConfigValidator interface {
+	AssociatedConfig
+	Validate(path string) error
+}
+
+// A TagLinker is implemented by the converted attributes of a resource which associates itself with every resource
+// carrying one of its tags (e.g. data capture of every resource tagged "telemetry"). LinkTagged is called with the
+// config of the resource implementing it and the config of each tagged resource.
+type TagLinker interface {
+	// LinkedTags returns the tags the resource acts on.
+	LinkedTags() []string
+	LinkTagged(conf *Config, tagged *Config)
+}
+
 // An AssociatedConfigRegistration describes how to convert all attributes
 // for a type of resource associated with another resource (e.g. data capture on a resource).
 type AssociatedConfigRegistration[AssocT AssociatedConfig] struct {
@@ -278,6 +298,9 @@ func Register[ResourceT Resource, ConfigT ConfigValidator](
 	if reg.Constructor != nil && reg.DeprecatedRobotConstructor != nil {
 		panic(errors.Errorf("can only register one kind of constructor for api: %q, model: %q", api, model))
 	}
+	if err := reg.ExtraParams.validate(); err != nil {
+		panic(errors.Wrapf(err, "invalid extra params for api: %q, model: %q", api, model))
+	}
 	var zero ConfigT
 	zeroT := reflect.TypeOf(zero)
 	if reg.AttributeMapConverter == nil {
@@ -300,6 +323,7 @@ func makeGenericResourceRegistration[ResourceT Resource, ConfigT ConfigValidator
 		// NOTE: any fields added to Registration must be copied/adapted here.
 		WeakDependencies: typed.WeakDependencies,
 		Discover:         typed.Discover,
+		ExtraParams:      typed.ExtraParams,
 		isDefault:        typed.isDefault,
 		api:              typed.api,
 		configType:       typed.configType,
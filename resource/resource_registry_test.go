@@ -210,6 +210,18 @@ func (st *mockAssociatedConfig) Link(conf *resource.Config) {
 	conf.AssociatedAttributes[st.capName] = copySt
 }
 
+// validatingAssociatedConfig is a mockAssociatedConfig which requires field1 to be set.
+type validatingAssociatedConfig struct {
+	mockAssociatedConfig
+}
+
+func (st *validatingAssociatedConfig) Validate(path string) error {
+	if st.Field1 == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "field1")
+	}
+	return nil
+}
+
 func TestResourceAPIRegistryWithAssociation(t *testing.T) {
 	statf := func(context.Context, arm.Arm) (interface{}, error) {
 		return nil, errors.New("one")
@@ -1,15 +1,34 @@
 package resource
 
 import (
+	"strconv"
 	"time"
 
+	"github.com/pkg/errors"
 	commonpb "go.viam.com/api/common/v1"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// The metadata keys of the response headers carrying a ResponseMetadata, for the parts its protobuf message has no
+// fields for, and for responses without one.
+const (
+	// CapturedAtMetadataKey is the response header with the capture time, in RFC 3339 format.
+	CapturedAtMetadataKey = "viam-captured-at"
+	// FrameSequenceMetadataKey is the response header with the frame sequence number of a capture.
+	FrameSequenceMetadataKey = "viam-frame-sequence"
+	// ExposureMetadataKey is the response header with the exposure time of a capture, in microseconds.
+	ExposureMetadataKey = "viam-exposure-us"
+)
+
 // ResponseMetadata contains extra info associated with a Resource's standard response.
 type ResponseMetadata struct {
 	CapturedAt time.Time
+	// FrameSequence numbers the frames of a source in the order it captured them, so frames which were dropped or
+	// returned twice can be told apart. It is 0 when unknown.
+	FrameSequence uint64
+	// Exposure is how long the sensor was exposed for the capture. It is 0 when unknown.
+	Exposure time.Duration
 }
 
 // AsProto turns the ResponseMetadata struct into a protobuf message.
@@ -25,3 +44,44 @@ func ResponseMetadataFromProto(proto *commonpb.ResponseMetadata) ResponseMetadat
 	metadata.CapturedAt = proto.CapturedAt.AsTime()
 	return metadata
 }
+
+// GRPCHeader returns the response headers carrying the known parts of the metadata.
+func (rm ResponseMetadata) GRPCHeader() metadata.MD {
+	md := metadata.MD{}
+	if !rm.CapturedAt.IsZero() {
+		md.Set(CapturedAtMetadataKey, rm.CapturedAt.UTC().Format(time.RFC3339Nano))
+	}
+	if rm.FrameSequence != 0 {
+		md.Set(FrameSequenceMetadataKey, strconv.FormatUint(rm.FrameSequence, 10))
+	}
+	if rm.Exposure != 0 {
+		md.Set(ExposureMetadataKey, strconv.FormatInt(rm.Exposure.Microseconds(), 10))
+	}
+	return md
+}
+
+// ReadGRPCHeader fills in the parts of the metadata carried by response headers, leaving those without a header.
+func (rm *ResponseMetadata) ReadGRPCHeader(header metadata.MD) error {
+	if values := header.Get(CapturedAtMetadataKey); len(values) > 0 {
+		capturedAt, err := time.Parse(time.RFC3339Nano, values[0])
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s", CapturedAtMetadataKey)
+		}
+		rm.CapturedAt = capturedAt
+	}
+	if values := header.Get(FrameSequenceMetadataKey); len(values) > 0 {
+		seq, err := strconv.ParseUint(values[0], 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s", FrameSequenceMetadataKey)
+		}
+		rm.FrameSequence = seq
+	}
+	if values := header.Get(ExposureMetadataKey); len(values) > 0 {
+		us, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s", ExposureMetadataKey)
+		}
+		rm.Exposure = time.Duration(us) * time.Microsecond
+	}
+	return nil
+}
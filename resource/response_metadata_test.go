@@ -22,3 +22,20 @@ func TestResponseFromProto(t *testing.T) {
 	metadata := ResponseMetadataFromProto(proto)
 	test.That(t, metadata.CapturedAt, test.ShouldEqual, time.UnixMilli(12345))
 }
+
+func TestResponseGRPCHeader(t *testing.T) {
+	test.That(t, ResponseMetadata{}.GRPCHeader(), test.ShouldBeEmpty)
+
+	metadata := ResponseMetadata{CapturedAt: time.UnixMilli(12345).UTC(), FrameSequence: 42, Exposure: 8 * time.Millisecond}
+	header := metadata.GRPCHeader()
+	test.That(t, header.Get(CapturedAtMetadataKey), test.ShouldResemble, []string{"1970-01-01T00:00:12.345Z"})
+	test.That(t, header.Get(FrameSequenceMetadataKey), test.ShouldResemble, []string{"42"})
+	test.That(t, header.Get(ExposureMetadataKey), test.ShouldResemble, []string{"8000"})
+
+	var read ResponseMetadata
+	test.That(t, read.ReadGRPCHeader(header), test.ShouldBeNil)
+	test.That(t, read, test.ShouldResemble, metadata)
+
+	header.Set(FrameSequenceMetadataKey, "-1")
+	test.That(t, read.ReadGRPCHeader(header), test.ShouldNotBeNil)
+}
@@ -0,0 +1,282 @@
+package rimage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// BayerPattern is the order of the color filters over the top left two by two pixels of a Bayer sensor, read left
+// to right and then top to bottom.
+type BayerPattern string
+
+// The Bayer patterns of common sensors.
+const (
+	BayerRGGB = BayerPattern("RGGB")
+	BayerBGGR = BayerPattern("BGGR")
+	BayerGRBG = BayerPattern("GRBG")
+	BayerGBRG = BayerPattern("GBRG")
+)
+
+// Validate returns an error if the pattern is not one of the known Bayer patterns.
+func (p BayerPattern) Validate() error {
+	switch p {
+	case BayerRGGB, BayerBGGR, BayerGRBG, BayerGBRG:
+		return nil
+	default:
+		return errors.Errorf("unknown bayer pattern %q, expected one of RGGB, BGGR, GRBG or GBRG", string(p))
+	}
+}
+
+// the channels of a pixel under a color filter
+const (
+	bayerRed = iota
+	bayerGreen
+	bayerBlue
+)
+
+// channel is the color of the filter over a pixel.
+func (p BayerPattern) channel(x, y int) int {
+	switch p[2*(y&1)+(x&1)] {
+	case 'R':
+		return bayerRed
+	case 'B':
+		return bayerBlue
+	default:
+		return bayerGreen
+	}
+}
+
+// BayerImageMagicNumber is the magic number of the header of raw Bayer images, which is followed by the width and
+// height as 4 byte big endian integers, the 4 letter pattern and the bit depth as a single byte. The pixels follow,
+// row by row, as a byte each for a bit depth of 8 or less and as 2 big endian bytes each otherwise.
+var BayerImageMagicNumber = []byte("BAYR")
+
+// RawBayerHeaderLength is the length of the header of raw Bayer images in bytes.
+const RawBayerHeaderLength = 17
+
+// A BayerImage is the raw mosaic of a Bayer sensor, before it is debayered into a color image. Each pixel is the
+// intensity of the light under a single color filter, with BitDepth significant bits. As an image.Image it is the
+// mosaic in gray.
+type BayerImage struct {
+	Pattern  BayerPattern
+	BitDepth int
+
+	width, height int
+	pix           []uint16
+}
+
+// NewBayerImage returns a black raw Bayer image.
+func NewBayerImage(width, height int, pattern BayerPattern, bitDepth int) (*BayerImage, error) {
+	if err := pattern.Validate(); err != nil {
+		return nil, err
+	}
+	if bitDepth < 1 || bitDepth > 16 {
+		return nil, errors.Errorf("bayer bit depth must be from 1 to 16, got %d", bitDepth)
+	}
+	if width < 0 || height < 0 {
+		return nil, errors.Errorf("bayer image cannot be %dx%d", width, height)
+	}
+	return &BayerImage{Pattern: pattern, BitDepth: bitDepth, width: width, height: height, pix: make([]uint16, width*height)}, nil
+}
+
+// BayerImageFromGray reads the mosaic of a Bayer sensor from a gray image, as sensors whose raw output is sent as an
+// 8 or 16 bit gray image provide it. A 16 bit image holds values of bitDepth bits in its low bits.
+func BayerImageFromGray(img image.Image, pattern BayerPattern, bitDepth int) (*BayerImage, error) {
+	bounds := img.Bounds()
+	b, err := NewBayerImage(bounds.Dx(), bounds.Dy(), pattern, bitDepth)
+	if err != nil {
+		return nil, err
+	}
+	var at func(x, y int) uint16
+	switch gray := img.(type) {
+	case *image.Gray:
+		at = func(x, y int) uint16 { return uint16(gray.GrayAt(x, y).Y) }
+	case *image.Gray16:
+		at = func(x, y int) uint16 { return gray.Gray16At(x, y).Y }
+	default:
+		return nil, errors.Errorf("bayer images are read from gray images, not %T", img)
+	}
+	maxValue := uint16(1<<bitDepth - 1)
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; x++ {
+			b.pix[y*b.width+x] = min(at(bounds.Min.X+x, bounds.Min.Y+y), maxValue)
+		}
+	}
+	return b, nil
+}
+
+// ColorModel returns the gray model of the mosaic.
+func (b *BayerImage) ColorModel() color.Model {
+	return color.Gray16Model
+}
+
+// Bounds returns the rectangle dimensions of the image.
+func (b *BayerImage) Bounds() image.Rectangle {
+	return image.Rect(0, 0, b.width, b.height)
+}
+
+// At returns the intensity of a pixel of the mosaic as gray, scaled to 16 bits.
+func (b *BayerImage) At(x, y int) color.Color {
+	if !(image.Point{x, y}.In(b.Bounds())) {
+		return color.Gray16{}
+	}
+	return color.Gray16{Y: uint16(uint32(b.Raw(x, y)) * math.MaxUint16 / (1<<b.BitDepth - 1))}
+}
+
+// Raw returns the intensity of a pixel as the sensor measured it.
+func (b *BayerImage) Raw(x, y int) uint16 {
+	return b.pix[y*b.width+x]
+}
+
+// SetRaw sets the intensity of a pixel as the sensor measured it.
+func (b *BayerImage) SetRaw(x, y int, v uint16) {
+	b.pix[y*b.width+x] = v
+}
+
+// WhiteBalance is the gain applied to each channel of a Bayer image as it is debayered.
+type WhiteBalance struct {
+	Red   float64 `json:"red"`
+	Green float64 `json:"green"`
+	Blue  float64 `json:"blue"`
+}
+
+// GrayWorldWhiteBalance returns the white balance which makes the average color of the image gray, scaling the red
+// and blue channels to match the green.
+func (b *BayerImage) GrayWorldWhiteBalance() WhiteBalance {
+	var sums [3]float64
+	var counts [3]int
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; x++ {
+			c := b.Pattern.channel(x, y)
+			sums[c] += float64(b.Raw(x, y))
+			counts[c]++
+		}
+	}
+	var means [3]float64
+	for c := range means {
+		if counts[c] > 0 {
+			means[c] = sums[c] / float64(counts[c])
+		}
+	}
+	gain := func(mean float64) float64 {
+		if mean == 0 {
+			return 1
+		}
+		return means[bayerGreen] / mean
+	}
+	if means[bayerGreen] == 0 {
+		return WhiteBalance{1, 1, 1}
+	}
+	return WhiteBalance{Red: gain(means[bayerRed]), Green: 1, Blue: gain(means[bayerBlue])}
+}
+
+// Debayer interpolates the two channels missing at each pixel of the mosaic from the neighboring pixels which have
+// them, applying the white balance, to produce an 8 bit color image. Each missing channel is the average of the
+// pixels around it with that channel, which is bilinear interpolation for a Bayer mosaic.
+func (b *BayerImage) Debayer(wb WhiteBalance) *Image {
+	img := NewImage(b.width, b.height)
+	gains := [3]float64{wb.Red, wb.Green, wb.Blue}
+	scale := 255. / float64(int(1)<<b.BitDepth-1)
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; x++ {
+			var sums [3]float64
+			var counts [3]int
+			own := b.Pattern.channel(x, y)
+			sums[own], counts[own] = float64(b.Raw(x, y)), 1
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= b.width || ny >= b.height {
+						continue
+					}
+					if c := b.Pattern.channel(nx, ny); c != own {
+						sums[c] += float64(b.Raw(nx, ny))
+						counts[c]++
+					}
+				}
+			}
+			var rgb [3]uint8
+			for c := range rgb {
+				if counts[c] > 0 {
+					v := sums[c] / float64(counts[c]) * gains[c] * scale
+					rgb[c] = uint8(math.Round(math.Max(0, math.Min(255, v))))
+				}
+			}
+			img.SetXY(x, y, NewColor(rgb[0], rgb[1], rgb[2]))
+		}
+	}
+	return img
+}
+
+// WriteRawBayerTo writes a Bayer image with the raw Bayer header.
+func WriteRawBayerTo(b *BayerImage, w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	buf.Write(BayerImageMagicNumber)
+	var size [8]byte
+	binary.BigEndian.PutUint32(size[0:4], uint32(b.width))
+	binary.BigEndian.PutUint32(size[4:8], uint32(b.height))
+	buf.Write(size[:])
+	buf.WriteString(string(b.Pattern))
+	buf.WriteByte(byte(b.BitDepth))
+	for _, v := range b.pix {
+		if b.BitDepth <= 8 {
+			buf.WriteByte(byte(v))
+			continue
+		}
+		var pixel [2]byte
+		binary.BigEndian.PutUint16(pixel[:], v)
+		buf.Write(pixel[:])
+	}
+	return buf.WriteTo(w)
+}
+
+// ReadRawBayer reads a Bayer image with the raw Bayer header.
+func ReadRawBayer(r io.Reader) (*BayerImage, error) {
+	header := make([]byte, RawBayerHeaderLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, errors.Wrap(err, "cannot read raw bayer header")
+	}
+	if !bytes.Equal(header[:4], BayerImageMagicNumber) {
+		return nil, errors.New("not a raw bayer image")
+	}
+	width := int(binary.BigEndian.Uint32(header[4:8]))
+	height := int(binary.BigEndian.Uint32(header[8:12]))
+	b, err := NewBayerImage(width, height, BayerPattern(header[12:16]), int(header[16]))
+	if err != nil {
+		return nil, err
+	}
+	bytesPerPixel := 1
+	if b.BitDepth > 8 {
+		bytesPerPixel = 2
+	}
+	data := make([]byte, bytesPerPixel*width*height)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, errors.Wrap(err, "cannot read raw bayer pixels")
+	}
+	for i := range b.pix {
+		if bytesPerPixel == 1 {
+			b.pix[i] = uint16(data[i])
+		} else {
+			b.pix[i] = binary.BigEndian.Uint16(data[2*i:])
+		}
+	}
+	return b, nil
+}
+
+func readRawBayerConfig(r io.Reader) (image.Config, error) {
+	header := make([]byte, RawBayerHeaderLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{
+		ColorModel: color.Gray16Model,
+		Width:      int(binary.BigEndian.Uint32(header[4:8])),
+		Height:     int(binary.BigEndian.Uint32(header[8:12])),
+	}, nil
+}
@@ -0,0 +1,101 @@
+package rimage
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/utils"
+)
+
+// mosaic makes the raw image a Bayer sensor with the pattern takes of a flat color.
+func mosaic(t *testing.T, pattern BayerPattern, bitDepth int, rgb [3]uint16) *BayerImage {
+	t.Helper()
+	b, err := NewBayerImage(6, 4, pattern, bitDepth)
+	test.That(t, err, test.ShouldBeNil)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 6; x++ {
+			b.SetRaw(x, y, rgb[pattern.channel(x, y)])
+		}
+	}
+	return b
+}
+
+func TestBayerPattern(t *testing.T) {
+	test.That(t, BayerRGGB.Validate(), test.ShouldBeNil)
+	test.That(t, BayerPattern("RGBG").Validate(), test.ShouldNotBeNil)
+	test.That(t, BayerPattern("").Validate(), test.ShouldNotBeNil)
+
+	test.That(t, BayerRGGB.channel(0, 0), test.ShouldEqual, bayerRed)
+	test.That(t, BayerRGGB.channel(1, 1), test.ShouldEqual, bayerBlue)
+	test.That(t, BayerBGGR.channel(2, 0), test.ShouldEqual, bayerBlue)
+	test.That(t, BayerGRBG.channel(3, 0), test.ShouldEqual, bayerRed)
+	test.That(t, BayerGBRG.channel(0, 1), test.ShouldEqual, bayerRed)
+
+	_, err := NewBayerImage(4, 4, BayerRGGB, 17)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestDebayer(t *testing.T) {
+	for _, pattern := range []BayerPattern{BayerRGGB, BayerBGGR, BayerGRBG, BayerGBRG} {
+		img := mosaic(t, pattern, 8, [3]uint16{200, 100, 50}).Debayer(WhiteBalance{1, 1, 1})
+		test.That(t, img.Bounds(), test.ShouldResemble, image.Rect(0, 0, 6, 4))
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 6; x++ {
+				test.That(t, img.GetXY(x, y), test.ShouldResemble, NewColor(200, 100, 50))
+			}
+		}
+	}
+
+	// 12 bit values are scaled to 8 bits, and the white balance scales each channel
+	raw := mosaic(t, BayerRGGB, 12, [3]uint16{1000, 2000, 4000})
+	test.That(t, raw.Debayer(WhiteBalance{2, 1, 0.5}).GetXY(2, 2), test.ShouldResemble, NewColor(125, 125, 125))
+	wb := raw.GrayWorldWhiteBalance()
+	test.That(t, wb, test.ShouldResemble, WhiteBalance{Red: 2, Green: 1, Blue: 0.5})
+	test.That(t, raw.At(1, 0), test.ShouldResemble, color.Gray16{Y: 2000 * 65535 / 4095})
+}
+
+func TestBayerImageFromGray(t *testing.T) {
+	gray := image.NewGray(image.Rect(0, 0, 4, 2))
+	gray.SetGray(1, 0, color.Gray{Y: 7})
+	b, err := BayerImageFromGray(gray, BayerGRBG, 8)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, b.Raw(1, 0), test.ShouldEqual, 7)
+	test.That(t, b.Pattern, test.ShouldEqual, BayerGRBG)
+
+	gray16 := image.NewGray16(image.Rect(0, 0, 4, 2))
+	gray16.SetGray16(0, 1, color.Gray16{Y: 5000})
+	b, err = BayerImageFromGray(gray16, BayerRGGB, 10)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, b.Raw(0, 1), test.ShouldEqual, 1023)
+
+	_, err = BayerImageFromGray(NewImage(4, 2), BayerRGGB, 8)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestRawBayerEncoding(t *testing.T) {
+	ctx := context.Background()
+	for _, bitDepth := range []int{8, 12} {
+		b := mosaic(t, BayerBGGR, bitDepth, [3]uint16{1, 200, 255})
+		b.SetRaw(5, 3, uint16(1<<bitDepth-1))
+		encoded, err := EncodeImage(ctx, b, utils.MimeTypeRawBayer)
+		test.That(t, err, test.ShouldBeNil)
+
+		decoded, err := DecodeImage(ctx, encoded, utils.MimeTypeRawBayer)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, decoded, test.ShouldResemble, b)
+		// the header is recognized without the MIME type
+		decoded, err = DecodeImage(ctx, encoded, "")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, decoded, test.ShouldResemble, b)
+
+		lazy := NewLazyEncodedImage(encoded, utils.MimeTypeRawBayer)
+		test.That(t, lazy.Bounds(), test.ShouldResemble, b.Bounds())
+	}
+
+	_, err := EncodeImage(ctx, NewImage(2, 2), utils.MimeTypeRawBayer)
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -107,6 +107,15 @@ func init() {
 			}, nil
 		},
 	)
+
+	// Here we register our format for raw Bayer images so that we can use
+	// image.Decode as long as we have the appropriate header
+	image.RegisterFormat("vnd.viam.bayer", string(BayerImageMagicNumber),
+		func(r io.Reader) (image.Image, error) {
+			return ReadRawBayer(r)
+		},
+		readRawBayerConfig,
+	)
 } // end of init
 
 // readImageFromFile extracts the RGB, Z16, or raw depth data from an image file.
@@ -314,6 +323,14 @@ func EncodeImage(ctx context.Context, img image.Image, mimeType string) ([]byte,
 		imgStruct := image.NewNRGBA(bounds)
 		draw.Draw(imgStruct, bounds, img, bounds.Min, draw.Src)
 		buf.Write(imgStruct.Pix)
+	case ut.MimeTypeRawBayer:
+		bayer, ok := img.(*BayerImage)
+		if !ok {
+			return nil, errors.Errorf("only raw bayer images can be encoded as %q, not %T", actualOutMIME, img)
+		}
+		if _, err := WriteRawBayerTo(bayer, &buf); err != nil {
+			return nil, err
+		}
 	case ut.MimeTypePNG:
 		if err := png.Encode(&buf, img); err != nil {
 			return nil, err
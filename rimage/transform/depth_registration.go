@@ -0,0 +1,64 @@
+package transform
+
+import (
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// RegisterDepthMap reprojects a depth map from a depth camera onto the image plane of a color camera, so that each
+// pixel of the returned depth map, which is the size of the color image, is the depth of the same pixel of the color
+// image. depthToColor is the pose of the depth camera in the frame of the color camera, in millimeters. Where several
+// depth pixels land on the same color pixel the nearest is kept, and color pixels no depth pixel lands on are left at
+// zero depth.
+func RegisterDepthMap(
+	dm *rimage.DepthMap,
+	depthIntrinsics, colorIntrinsics *PinholeCameraIntrinsics,
+	depthToColor spatialmath.Pose,
+) (*rimage.DepthMap, error) {
+	if depthIntrinsics == nil || colorIntrinsics == nil {
+		return nil, NewNoIntrinsicsError("depth registration needs the intrinsics of both cameras")
+	}
+	if dm.Width() != depthIntrinsics.Width || dm.Height() != depthIntrinsics.Height {
+		return nil, errors.Errorf("depth intrinsics expect a depth map of (%d, %d), got (%d, %d)",
+			depthIntrinsics.Width, depthIntrinsics.Height, dm.Width(), dm.Height())
+	}
+	rotation := depthToColor.Orientation().RotationMatrix()
+	translation := depthToColor.Point()
+	toColorPixel := func(x, y float64, depth rimage.Depth) (int, int, float64) {
+		px, py, pz := depthIntrinsics.PixelToPoint(x, y, float64(depth))
+		pt := rotation.Mul(r3.Vector{X: px, Y: py, Z: pz}).Add(translation)
+		cx, cy := colorIntrinsics.PointToPixel(pt.X, pt.Y, pt.Z)
+		return int(cx + 0.5), int(cy + 0.5), pt.Z
+	}
+
+	out := rimage.NewEmptyDepthMap(colorIntrinsics.Width, colorIntrinsics.Height)
+	for dy := 0; dy < dm.Height(); dy++ {
+		for dx := 0; dx < dm.Width(); dx++ {
+			depth := dm.GetDepth(dx, dy)
+			if depth == 0 {
+				continue
+			}
+			// a depth pixel can cover several color pixels, so fill in all of them to avoid a grid of holes
+			cx0, cy0, cz0 := toColorPixel(float64(dx)-0.5, float64(dy)-0.5, depth)
+			cx1, cy1, cz1 := toColorPixel(float64(dx)+0.5, float64(dy)+0.5, depth)
+			if cx0 < 0 || cy0 < 0 || cx1 > colorIntrinsics.Width-1 || cy1 > colorIntrinsics.Height-1 {
+				continue
+			}
+			z := rimage.Depth((cz0 + cz1) / 2)
+			if z == 0 {
+				continue
+			}
+			for y := cy0; y <= cy1; y++ {
+				for x := cx0; x <= cx1; x++ {
+					if current := out.GetDepth(x, y); current == 0 || z < current {
+						out.Set(x, y, z)
+					}
+				}
+			}
+		}
+	}
+	return out, nil
+}
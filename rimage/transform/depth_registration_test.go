@@ -0,0 +1,50 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/spatialmath"
+)
+
+func TestRegisterDepthMap(t *testing.T) {
+	intrinsics := &PinholeCameraIntrinsics{Width: 8, Height: 6, Fx: 8, Fy: 8, Ppx: 4, Ppy: 3}
+	dm := rimage.NewEmptyDepthMap(8, 6)
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 8; x++ {
+			dm.Set(x, y, 1000)
+		}
+	}
+	dm.Set(4, 3, 500)
+
+	// the cameras coincide
+	out, err := RegisterDepthMap(dm, intrinsics, intrinsics, spatialmath.NewZeroPose())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out.Width(), test.ShouldEqual, 8)
+	test.That(t, out.GetDepth(2, 1), test.ShouldEqual, rimage.Depth(1000))
+	test.That(t, out.GetDepth(4, 3), test.ShouldEqual, rimage.Depth(500))
+
+	// the depth camera is 125mm along x of the color camera, which is a pixel at 1m and two at 0.5m
+	out, err = RegisterDepthMap(dm, intrinsics, intrinsics, spatialmath.NewPoseFromPoint(r3.Vector{X: 125}))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out.GetDepth(0, 3), test.ShouldEqual, rimage.Depth(0))
+	test.That(t, out.GetDepth(2, 1), test.ShouldEqual, rimage.Depth(1000))
+	// the nearer point is kept where it lands on the farther ones
+	test.That(t, out.GetDepth(6, 3), test.ShouldEqual, rimage.Depth(500))
+
+	// onto a color camera of a different size
+	colorIntrinsics := &PinholeCameraIntrinsics{Width: 16, Height: 12, Fx: 16, Fy: 16, Ppx: 8, Ppy: 6}
+	out, err = RegisterDepthMap(dm, intrinsics, colorIntrinsics, spatialmath.NewZeroPose())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out.Width(), test.ShouldEqual, 16)
+	test.That(t, out.Height(), test.ShouldEqual, 12)
+	test.That(t, out.GetDepth(4, 2), test.ShouldEqual, rimage.Depth(1000))
+
+	_, err = RegisterDepthMap(dm, colorIntrinsics, intrinsics, spatialmath.NewZeroPose())
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = RegisterDepthMap(dm, nil, intrinsics, spatialmath.NewZeroPose())
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,95 @@
+package transform
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// KannalaBrandt is a struct for the terms of the Kannala-Brandt model of distortion, which models wide-angle and
+// fisheye lenses as a polynomial in the angle of the incoming ray rather than in its distance from the center. It is
+// the fisheye model of OpenCV.
+type KannalaBrandt struct {
+	K1 float64 `json:"k1"`
+	K2 float64 `json:"k2"`
+	K3 float64 `json:"k3"`
+	K4 float64 `json:"k4"`
+}
+
+// CheckValid checks if the fields for KannalaBrandt have valid inputs.
+func (kb *KannalaBrandt) CheckValid() error {
+	if kb == nil {
+		return InvalidDistortionError("KannalaBrandt shaped distortion_parameters not provided")
+	}
+	return nil
+}
+
+// NewKannalaBrandt takes in a slice of floats that will be passed into the struct in order.
+func NewKannalaBrandt(inp []float64) (*KannalaBrandt, error) {
+	if len(inp) > 4 {
+		return nil, errors.Errorf("list of parameters too long, expected max 4, got %d", len(inp))
+	}
+	for i := len(inp); i < 4; i++ { // fill missing values with 0.0
+		inp = append(inp, 0.0)
+	}
+	return &KannalaBrandt{inp[0], inp[1], inp[2], inp[3]}, nil
+}
+
+// ModelType returns the type of distortion model.
+func (kb *KannalaBrandt) ModelType() DistortionType {
+	return KannalaBrandtDistortionType
+}
+
+// Parameters returns the parameters of the distortion model as a list of floats.
+func (kb *KannalaBrandt) Parameters() []float64 {
+	if kb == nil {
+		return []float64{}
+	}
+	return []float64{kb.K1, kb.K2, kb.K3, kb.K4}
+}
+
+// distortAngle returns the distorted angle of a ray at angle theta from the optical axis.
+func (kb *KannalaBrandt) distortAngle(theta float64) float64 {
+	theta2 := theta * theta
+	return theta * (1 + theta2*(kb.K1+theta2*(kb.K2+theta2*(kb.K3+theta2*kb.K4))))
+}
+
+// Transform distorts the input points x,y according to the Kannala-Brandt model as described by OpenCV
+// https://docs.opencv.org/4.x/db/d58/group__calib3d__fisheye.html
+func (kb *KannalaBrandt) Transform(x, y float64) (float64, float64) {
+	if kb == nil {
+		return x, y
+	}
+	r := math.Hypot(x, y)
+	if r == 0 {
+		return x, y
+	}
+	scale := kb.distortAngle(math.Atan(r)) / r
+	return x * scale, y * scale
+}
+
+// Undistort is the inverse of Transform, returning the undistorted points whose distortion is x,y. The angle of the
+// ray is found with Newton's method.
+func (kb *KannalaBrandt) Undistort(x, y float64) (float64, float64) {
+	if kb == nil {
+		return x, y
+	}
+	thetaD := math.Hypot(x, y)
+	if thetaD == 0 {
+		return x, y
+	}
+	theta := thetaD
+	for i := 0; i < 20; i++ {
+		theta2 := theta * theta
+		derivative := 1 + theta2*(3*kb.K1+theta2*(5*kb.K2+theta2*(7*kb.K3+theta2*9*kb.K4)))
+		step := (kb.distortAngle(theta) - thetaD) / derivative
+		theta -= step
+		if math.Abs(step) < 1e-10 {
+			break
+		}
+	}
+	// rays at or beyond a right angle to the optical axis do not reach the image plane
+	theta = math.Min(math.Max(theta, 0), math.Pi/2-1e-6)
+	scale := math.Tan(theta) / thetaD
+	return x * scale, y * scale
+}
@@ -0,0 +1,47 @@
+package transform
+
+import (
+	"math"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestKannalaBrandt(t *testing.T) {
+	t.Run("nil &KannalaBrandt{} are invalid", func(t *testing.T) {
+		var nilKannalaBrandtPtr *KannalaBrandt
+		err := nilKannalaBrandtPtr.CheckValid()
+		expected := "KannalaBrandt shaped distortion_parameters not provided: invalid distortion_parameters"
+		test.That(t, err.Error(), test.ShouldContainSubstring, expected)
+	})
+
+	t.Run("from a distortion type and parameters", func(t *testing.T) {
+		distorter, err := NewDistorter(KannalaBrandtDistortionType, []float64{0.1, -0.02})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, distorter.ModelType(), test.ShouldEqual, KannalaBrandtDistortionType)
+		test.That(t, distorter.Parameters(), test.ShouldResemble, []float64{0.1, -0.02, 0, 0})
+		_, err = NewKannalaBrandt([]float64{1, 2, 3, 4, 5})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("equidistant projection without coefficients", func(t *testing.T) {
+		kb := &KannalaBrandt{}
+		// a ray at 45 degrees lands at pi/4 from the center rather than at 1
+		x, y := kb.Transform(1, 0)
+		test.That(t, x, test.ShouldAlmostEqual, math.Pi/4)
+		test.That(t, y, test.ShouldAlmostEqual, 0)
+		x, y = kb.Transform(0, 0)
+		test.That(t, x, test.ShouldEqual, 0)
+		test.That(t, y, test.ShouldEqual, 0)
+	})
+
+	t.Run("undistort inverts transform", func(t *testing.T) {
+		kb := &KannalaBrandt{K1: 0.05, K2: -0.01, K3: 0.002, K4: -0.0003}
+		for _, pt := range [][2]float64{{0.1, 0.2}, {-1.5, 0.7}, {3, -2}} {
+			dx, dy := kb.Transform(pt[0], pt[1])
+			ux, uy := kb.Undistort(dx, dy)
+			test.That(t, ux, test.ShouldAlmostEqual, pt[0], 1e-6)
+			test.That(t, uy, test.ShouldAlmostEqual, pt[1], 1e-6)
+		}
+	})
+}
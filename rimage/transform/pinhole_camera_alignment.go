@@ -135,30 +135,11 @@ func (dcie *DepthColorIntrinsicsExtrinsics) TransformDepthCoordToColorCoord(
 			errors.Errorf("camera matrices expected depth image of (%#v,%#v), got (%#v, %#v)",
 				dcie.DepthCamera.Width, dcie.DepthCamera.Height, dep.Width(), dep.Height())
 	}
-	outmap := rimage.NewEmptyDepthMap(dcie.ColorCamera.Width, dcie.ColorCamera.Height)
-	for dy := 0; dy < dcie.DepthCamera.Height; dy++ {
-		for dx := 0; dx < dcie.DepthCamera.Width; dx++ {
-			dz := dep.GetDepth(dx, dy)
-			if dz == 0 {
-				continue
-			}
-			// if depth pixels are bigger than color pixel, will cause a grid effect. Take into account size of pixel
-			// get top-left corner of depth pixel
-			cx, cy, cz0 := dcie.DepthPixelToColorPixel(float64(dx)-0.5, float64(dy)-0.5, float64(dz))
-			cx0, cy0 := int(cx+0.5), int(cy+0.5)
-			// get bottom-right corner of depth pixel
-			cx, cy, cz1 := dcie.DepthPixelToColorPixel(float64(dx)+0.5, float64(dy)+0.5, float64(dz))
-			cx1, cy1 := int(cx+0.5), int(cy+0.5)
-			if cx0 < 0 || cy0 < 0 || cx1 > dcie.ColorCamera.Width-1 || cy1 > dcie.ColorCamera.Height-1 {
-				continue
-			}
-			z := rimage.Depth((cz0 + cz1) / 2.0) // average of depth within color pixel
-			for y := cy0; y <= cy1; y++ {
-				for x := cx0; x <= cx1; x++ {
-					outmap.Set(x, y, z)
-				}
-			}
-		}
+	// the extrinsics are in meters, while registration works in the millimeters of the depth map
+	extrinsics := spatialmath.NewPose(dcie.ExtrinsicD2C.Point().Mul(1000), dcie.ExtrinsicD2C.Orientation())
+	outmap, err := RegisterDepthMap(dep, &dcie.DepthCamera, &dcie.ColorCamera, extrinsics)
+	if err != nil {
+		return nil, nil, err
 	}
 	return col, outmap, nil
 }
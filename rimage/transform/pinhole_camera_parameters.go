@@ -108,6 +108,40 @@ func (params *PinholeCameraModel) UndistortDepthMap(dm *rimage.DepthMap) (*rimag
 	return undistortedDm, nil
 }
 
+// PixelToPoint transforms a pixel of the distorted image the camera takes, with depth, to a 3D point.
+func (params *PinholeCameraModel) PixelToPoint(x, y, z float64) (float64, float64, float64) {
+	if params.PinholeCameraIntrinsics == nil || params.Distortion == nil {
+		return params.PinholeCameraIntrinsics.PixelToPoint(x, y, z)
+	}
+	xn, yn := undistortPoint(params.Distortion, (x-params.Ppx)/params.Fx, (y-params.Ppy)/params.Fy)
+	return xn * z, yn * z, z
+}
+
+// PointToPixel projects a 3D point to a pixel of the distorted image the camera takes.
+func (params *PinholeCameraModel) PointToPixel(x, y, z float64) (float64, float64) {
+	if params.Distortion == nil || z == 0 {
+		return params.PinholeCameraIntrinsics.PointToPixel(x, y, z)
+	}
+	xd, yd := params.Distortion.Transform(x/z, y/z)
+	return math.Round(xd*params.Fx + params.Ppx), math.Round(yd*params.Fy + params.Ppy)
+}
+
+// undistortPoint inverts a distortion of normalized image coordinates, with the inverse of the model when it has one
+// and by fixed-point iteration otherwise, which converges for the mild distortion of narrow lenses.
+func undistortPoint(distortion Distorter, x, y float64) (float64, float64) {
+	if inverse, ok := distortion.(interface {
+		Undistort(x, y float64) (float64, float64)
+	}); ok {
+		return inverse.Undistort(x, y)
+	}
+	ux, uy := x, y
+	for i := 0; i < 20; i++ {
+		dx, dy := distortion.Transform(ux, uy)
+		ux, uy = ux+x-dx, uy+y-dy
+	}
+	return ux, uy
+}
+
 // PinholeCameraIntrinsics holds the parameters necessary to do a perspective projection of a 3D scene to the 2D plane.
 type PinholeCameraIntrinsics struct {
 	Width  int     `json:"width_px"`
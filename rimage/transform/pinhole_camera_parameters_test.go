@@ -3,6 +3,7 @@ package transform
 import (
 	"context"
 	"image"
+	"math"
 	"testing"
 
 	"github.com/golang/geo/r3"
@@ -216,3 +217,28 @@ func TestNilIntrinsics(t *testing.T) {
 	test.That(t, func() { nilIntrinsics.RGBDToPointCloud(&rimage.Image{}, &rimage.DepthMap{}) }, test.ShouldNotPanic)
 	test.That(t, func() { nilIntrinsics.PointCloudToRGBD(pointcloud.PointCloud(nil)) }, test.ShouldNotPanic)
 }
+
+func TestPinholeCameraModelProjection(t *testing.T) {
+	intrinsics := &PinholeCameraIntrinsics{Width: 640, Height: 480, Fx: 300, Fy: 300, Ppx: 320, Ppy: 240}
+	for _, distortion := range []Distorter{
+		nil,
+		&BrownConrady{RadialK1: -0.1, RadialK2: 0.01, TangentialP1: 0.001},
+		&KannalaBrandt{K1: 0.05, K2: -0.01},
+	} {
+		model := &PinholeCameraModel{PinholeCameraIntrinsics: intrinsics, Distortion: distortion}
+		px, py := model.PointToPixel(150, -100, 1000)
+		x, y, z := model.PixelToPoint(px, py, 1000)
+		// pixels are rounded, so the point comes back to within a pixel at that depth
+		test.That(t, x, test.ShouldAlmostEqual, 150, 1000/intrinsics.Fx)
+		test.That(t, y, test.ShouldAlmostEqual, -100, 1000/intrinsics.Fy)
+		test.That(t, z, test.ShouldEqual, 1000)
+	}
+
+	// a fisheye sees a point far off axis nearer the center than a pinhole does
+	pinhole := &PinholeCameraModel{PinholeCameraIntrinsics: intrinsics}
+	fisheye := &PinholeCameraModel{PinholeCameraIntrinsics: intrinsics, Distortion: &KannalaBrandt{}}
+	pinholeX, _ := pinhole.PointToPixel(1000, 0, 1000)
+	fisheyeX, _ := fisheye.PointToPixel(1000, 0, 1000)
+	test.That(t, fisheyeX, test.ShouldBeLessThan, pinholeX)
+	test.That(t, fisheyeX, test.ShouldEqual, math.Round(320+300*math.Pi/4))
+}
@@ -58,6 +58,9 @@ var (
 
 	// defaultResourcesTimeout is the default timeout for getting resources.
 	defaultResourcesTimeout = 5 * time.Second
+
+	// unavailableRetryBackoff is the backoff before the first retry of a call to an unavailable robot.
+	unavailableRetryBackoff = 100 * time.Millisecond
 )
 
 // RobotClient satisfies the robot.Robot interface through a gRPC based
@@ -93,6 +96,8 @@ type RobotClient struct {
 	sessionsSupported        *bool // when nil, we have not yet checked
 	currentSessionID         string
 	sessionHeartbeatInterval time.Duration
+	// requestedHeartbeatInterval overrides the heartbeat interval derived from the heartbeat window when set.
+	requestedHeartbeatInterval time.Duration
 
 	heartbeatWorkers   sync.WaitGroup
 	heartbeatCtx       context.Context
@@ -231,17 +236,36 @@ func New(ctx context.Context, address string, clientLogger logging.ZapCompatible
 		sessionsDisabled:    rOpts.disableSessions,
 		heartbeatCtx:        heartbeatCtx,
 		heartbeatCtxCancel:  heartbeatCtxCancel,
+
+		requestedHeartbeatInterval: rOpts.sessionHeartbeatInterval,
+	}
+
+	var retryOpts []grpc_retry.CallOption
+	if rOpts.unavailableRetries > 0 {
+		retryOpts = append(retryOpts,
+			grpc_retry.WithMax(rOpts.unavailableRetries),
+			grpc_retry.WithCodes(codes.Unavailable),
+			grpc_retry.WithBackoff(grpc_retry.BackoffExponential(unavailableRetryBackoff)),
+		)
 	}
 
 	// interceptors are applied in order from first to last
 	rc.dialOptions = append(
 		rc.dialOptions,
 		rpc.WithUnaryClientInterceptor(contextutils.ContextWithMetadataUnaryClientInterceptor),
+	)
+	if rOpts.responseCacheTTL > 0 {
+		// the cache goes before error handling so that it also covers calls made while disconnected
+		cache := newResponseCache(rOpts.responseCacheTTL, logger)
+		rc.dialOptions = append(rc.dialOptions, rpc.WithUnaryClientInterceptor(cache.unaryClientInterceptor))
+	}
+	rc.dialOptions = append(
+		rc.dialOptions,
 		// error handling
 		rpc.WithUnaryClientInterceptor(rc.handleUnaryDisconnect),
 		rpc.WithStreamClientInterceptor(rc.handleStreamDisconnect),
 		// sessions
-		rpc.WithUnaryClientInterceptor(grpc_retry.UnaryClientInterceptor()),
+		rpc.WithUnaryClientInterceptor(readsOnly(grpc_retry.UnaryClientInterceptor(retryOpts...))),
 		rpc.WithStreamClientInterceptor(grpc_retry.StreamClientInterceptor()),
 		rpc.WithUnaryClientInterceptor(rc.sessionUnaryClientInterceptor),
 		rpc.WithStreamClientInterceptor(rc.sessionStreamClientInterceptor),
@@ -878,6 +902,7 @@ func (rc *RobotClient) TransformPointCloud(ctx context.Context, srcpc pointcloud
 
 // Status returns the status of the resources on the machine. You can provide a list of ResourceNames for which you want
 // statuses. If no names are passed in, the status of every resource available on the machine is returned.
+// To poll only part of each status, pass a context from grpc.WithFieldMask; the fields left out are zero.
 //
 //	status, err := machine.Status(ctx.Background())
 func (rc *RobotClient) Status(ctx context.Context, resourceNames []resource.Name) ([]robot.Status, error) {
@@ -990,3 +1015,21 @@ func (rc *RobotClient) RestartModule(ctx context.Context, req robot.RestartModul
 	}
 	return nil
 }
+
+// readsOnly applies an interceptor only to calls which read state, so that retrying a call which timed out after the
+// robot received it never repeats an action such as a move.
+func readsOnly(interceptor googlegrpc.UnaryClientInterceptor) googlegrpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *googlegrpc.ClientConn,
+		invoker googlegrpc.UnaryInvoker,
+		opts ...googlegrpc.CallOption,
+	) error {
+		if !isReadMethod(method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		return interceptor(ctx, method, req, reply, cc, invoker, opts...)
+	}
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"go.viam.com/rdk/logging"
+)
+
+// maxCachedResponses bounds how many responses a responseCache keeps.
+const maxCachedResponses = 1024
+
+// cachedMethods are the reads whose last response is still meaningful a little while after it was received. Reads of
+// safety state, such as whether an actuator is moving or powered, are left out so that a disconnected robot is never
+// reported as stopped.
+var cachedMethods = map[string]bool{
+	"/viam.robot.v1.RobotService/GetCloudMetadata":                              true,
+	"/viam.component.sensor.v1.SensorService/GetReadings":                       true,
+	"/viam.component.powersensor.v1.PowerSensorService/GetVoltage":              true,
+	"/viam.component.powersensor.v1.PowerSensorService/GetCurrent":              true,
+	"/viam.component.powersensor.v1.PowerSensorService/GetPower":                true,
+	"/viam.component.movementsensor.v1.MovementSensorService/GetPosition":       true,
+	"/viam.component.movementsensor.v1.MovementSensorService/GetProperties":     true,
+	"/viam.component.movementsensor.v1.MovementSensorService/GetAccuracy":       true,
+	"/viam.component.movementsensor.v1.MovementSensorService/GetOrientation":    true,
+	"/viam.component.movementsensor.v1.MovementSensorService/GetCompassHeading": true,
+	"/viam.component.encoder.v1.EncoderService/GetProperties":                   true,
+	"/viam.component.motor.v1.MotorService/GetProperties":                       true,
+	"/viam.component.camera.v1.CameraService/GetProperties":                     true,
+	"/viam.component.base.v1.BaseService/GetProperties":                         true,
+	"/viam.component.arm.v1.ArmService/GetKinematics":                           true,
+	"/viam.component.gantry.v1.GantryService/GetLengths":                        true,
+	"/viam.component.board.v1.BoardService/ReadAnalogReader":                    true,
+}
+
+// responseCache keeps the last response to each of the cachedMethods so that it can be served while the robot is
+// unavailable, which hides brief drops of peers on flaky networks.
+type responseCache struct {
+	ttl    time.Duration
+	logger logging.Logger
+
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+}
+
+type cachedResponse struct {
+	reply    proto.Message
+	received time.Time
+}
+
+func newResponseCache(ttl time.Duration, logger logging.Logger) *responseCache {
+	return &responseCache{ttl: ttl, logger: logger, entries: map[string]cachedResponse{}}
+}
+
+// isReadMethod returns whether a method only reads state, so it can be retried.
+func isReadMethod(method string) bool {
+	name := method[strings.LastIndex(method, "/")+1:]
+	return strings.HasPrefix(name, "Get") || strings.HasPrefix(name, "Is") || strings.HasPrefix(name, "Read")
+}
+
+func (c *responseCache) unaryClientInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply interface{},
+	cc *googlegrpc.ClientConn,
+	invoker googlegrpc.UnaryInvoker,
+	opts ...googlegrpc.CallOption,
+) error {
+	reqMsg, reqOK := req.(proto.Message)
+	replyMsg, replyOK := reply.(proto.Message)
+	if !cachedMethods[method] || !reqOK || !replyOK {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	encoded, err := proto.MarshalOptions{Deterministic: true}.Marshal(reqMsg)
+	if err != nil {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	key := method + "\x00" + string(encoded)
+
+	err = invoker(ctx, method, req, reply, cc, opts...)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		c.store(key, replyMsg)
+		return nil
+	}
+	if status.Code(err) != codes.Unavailable {
+		return err
+	}
+	cached, ok := c.entries[key]
+	if !ok || time.Since(cached.received) > c.ttl {
+		return err
+	}
+	c.logger.CDebugw(ctx, "robot is unavailable, serving cached response",
+		"method", method, "age", time.Since(cached.received), "error", err)
+	proto.Reset(replyMsg)
+	proto.Merge(replyMsg, cached.reply)
+	return nil
+}
+
+// store keeps a response, making room for it by dropping expired responses and then the oldest ones.
+func (c *responseCache) store(key string, reply proto.Message) {
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= maxCachedResponses {
+		var oldestKey string
+		var oldest time.Time
+		for entryKey, entry := range c.entries {
+			if time.Since(entry.received) > c.ttl {
+				delete(c.entries, entryKey)
+				continue
+			}
+			if oldestKey == "" || entry.received.Before(oldest) {
+				oldestKey, oldest = entryKey, entry.received
+			}
+		}
+		if len(c.entries) >= maxCachedResponses {
+			delete(c.entries, oldestKey)
+		}
+	}
+	c.entries[key] = cachedResponse{reply: proto.Clone(reply), received: time.Now()}
+}
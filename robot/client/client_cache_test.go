@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	pb "go.viam.com/api/robot/v1"
+	"go.viam.com/test"
+	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/logging"
+)
+
+func TestResponseCache(t *testing.T) {
+	cache := newResponseCache(time.Minute, logging.NewTestLogger(t))
+	ctx := context.Background()
+
+	var invokeErr error
+	var served string
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *googlegrpc.ClientConn,
+		opts ...googlegrpc.CallOption,
+	) error {
+		if invokeErr != nil {
+			return invokeErr
+		}
+		reply.(*pb.GetCloudMetadataResponse).RobotPartId = served
+		return nil
+	}
+	call := func(method string) (*pb.GetCloudMetadataResponse, error) {
+		reply := &pb.GetCloudMetadataResponse{}
+		err := cache.unaryClientInterceptor(ctx, method, &pb.GetCloudMetadataRequest{}, reply, nil, invoker)
+		return reply, err
+	}
+	const method = "/viam.robot.v1.RobotService/GetCloudMetadata"
+
+	served = "first"
+	reply, err := call(method)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reply.RobotPartId, test.ShouldEqual, "first")
+
+	// the last response is served while the robot is unavailable
+	invokeErr = status.Error(codes.Unavailable, "not connected")
+	reply, err = call(method)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reply.RobotPartId, test.ShouldEqual, "first")
+
+	// other failures are not hidden
+	invokeErr = errors.New("bad request")
+	_, err = call(method)
+	test.That(t, err, test.ShouldBeError, invokeErr)
+
+	// nor are failures of calls which are not reads
+	invokeErr = status.Error(codes.Unavailable, "not connected")
+	_, err = call("/viam.robot.v1.RobotService/StopAll")
+	test.That(t, err, test.ShouldBeError, invokeErr)
+
+	// nor are failures of reads of safety state
+	_, err = call("/viam.component.arm.v1.ArmService/IsMoving")
+	test.That(t, err, test.ShouldBeError, invokeErr)
+
+	// responses older than the ttl are dropped
+	cache.ttl = 0
+	time.Sleep(time.Millisecond)
+	_, err = call(method)
+	test.That(t, err, test.ShouldBeError, invokeErr)
+}
+
+func TestResponseCacheBound(t *testing.T) {
+	cache := newResponseCache(time.Minute, logging.NewTestLogger(t))
+	for i := 0; i < maxCachedResponses+10; i++ {
+		cache.store(fmt.Sprint(i), &pb.GetCloudMetadataResponse{})
+	}
+	test.That(t, cache.entries, test.ShouldHaveLength, maxCachedResponses)
+	// the oldest responses make room for the newest
+	test.That(t, cache.entries, test.ShouldNotContainKey, "0")
+	test.That(t, cache.entries, test.ShouldContainKey, fmt.Sprint(maxCachedResponses+9))
+}
+
+func TestReadsOnly(t *testing.T) {
+	var intercepted []string
+	interceptor := readsOnly(func(ctx context.Context, method string, req, reply interface{}, cc *googlegrpc.ClientConn,
+		invoker googlegrpc.UnaryInvoker, opts ...googlegrpc.CallOption,
+	) error {
+		intercepted = append(intercepted, method)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	})
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *googlegrpc.ClientConn,
+		opts ...googlegrpc.CallOption,
+	) error {
+		return nil
+	}
+	for _, method := range []string{
+		"/viam.component.arm.v1.ArmService/GetJointPositions",
+		"/viam.component.arm.v1.ArmService/MoveToPosition",
+		"/viam.component.motor.v1.MotorService/SetPower",
+	} {
+		test.That(t, interceptor(context.Background(), method, nil, nil, nil, invoker), test.ShouldBeNil)
+	}
+	test.That(t, intercepted, test.ShouldResemble, []string{"/viam.component.arm.v1.ArmService/GetJointPositions"})
+}
+
+func TestIsReadMethod(t *testing.T) {
+	test.That(t, isReadMethod("/viam.component.sensor.v1.SensorService/GetReadings"), test.ShouldBeTrue)
+	test.That(t, isReadMethod("/viam.component.motor.v1.MotorService/IsPowered"), test.ShouldBeTrue)
+	test.That(t, isReadMethod("/viam.component.board.v1.BoardService/ReadAnalogReader"), test.ShouldBeTrue)
+	test.That(t, isReadMethod("/viam.component.motor.v1.MotorService/SetPower"), test.ShouldBeFalse)
+}
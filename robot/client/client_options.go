@@ -32,6 +32,18 @@ type robotClientOpts struct {
 
 	// controls whether or not sessions are disabled.
 	disableSessions bool
+
+	// sessionHeartbeatInterval is how often to send session heartbeats. If unset,
+	// heartbeats are sent five times per heartbeat window of the server.
+	sessionHeartbeatInterval time.Duration
+
+	// unavailableRetries is how many times to retry a call failing because the
+	// robot is unavailable.
+	unavailableRetries uint
+
+	// responseCacheTTL is how long the last response to a read can be served for
+	// while the robot is unavailable. If <=0, responses are not cached.
+	responseCacheTTL time.Duration
 }
 
 // RobotClientOption configures how we set up the connection.
@@ -92,6 +104,33 @@ func WithDisableSessions() RobotClientOption {
 	})
 }
 
+// WithSessionHeartbeatInterval returns a RobotClientOption for how often to send session heartbeats,
+// for robots which cannot keep up with the default rate. The interval is capped to half of the
+// heartbeat window of the robot.
+func WithSessionHeartbeatInterval(interval time.Duration) RobotClientOption {
+	return newFuncRobotClientOption(func(o *robotClientOpts) {
+		o.sessionHeartbeatInterval = interval
+	})
+}
+
+// WithUnavailableRetries returns a RobotClientOption which retries reads (Get, Is and Read methods)
+// failing because the robot is unavailable up to the given number of times, with exponential backoff.
+// Other calls are never retried, since they may have been carried out before the robot became unavailable.
+func WithUnavailableRetries(retries uint) RobotClientOption {
+	return newFuncRobotClientOption(func(o *robotClientOpts) {
+		o.unavailableRetries = retries
+	})
+}
+
+// WithResponseCache returns a RobotClientOption which serves the last response to a read of slowly
+// changing state, such as sensor readings and properties, while the robot is unavailable, as long as
+// that response is no older than ttl. Safety state, such as whether an actuator is moving, is never cached.
+func WithResponseCache(ttl time.Duration) RobotClientOption {
+	return newFuncRobotClientOption(func(o *robotClientOpts) {
+		o.responseCacheTTL = ttl
+	})
+}
+
 // WithDialOptions returns a RobotClientOption which sets the options for making
 // gRPC connections to other servers.
 func WithDialOptions(opts ...rpc.DialOption) RobotClientOption {
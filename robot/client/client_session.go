@@ -141,6 +141,10 @@ func (rc *RobotClient) sessionMetadata(ctx context.Context, method string) (cont
 		rc.logger.CInfow(ctx, "session heartbeat window invalid; will not try again", "heartbeat_window", heartbeatWindow)
 		return ctx, nil
 	}
+	if rc.requestedHeartbeatInterval > 0 {
+		// beating less than twice per window risks the session expiring between heartbeats
+		sessionHeartbeatInterval = min(rc.requestedHeartbeatInterval, heartbeatWindow/2)
+	}
 
 	trueVal := true
 	rc.sessionsSupported = &trueVal
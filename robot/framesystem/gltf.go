@@ -0,0 +1,235 @@
+package framesystem
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+// The glTF constants a scene is written with.
+const (
+	gltfFloat         = 5126
+	gltfUnsignedInt   = 5125
+	gltfArrayBuffer   = 34962
+	gltfElementBuffer = 34963
+	gltfTriangles     = 4
+)
+
+// The number of segments around and rings from pole to pole which spheres and the caps of capsules are drawn with.
+const (
+	sphereSegments = 24
+	sphereRings    = 12
+)
+
+// zUpToYUp is the rotation, as a glTF quaternion, from the machine's world, where Z is up, to glTF's, where Y is.
+var zUpToYUp = []float64{-math.Sqrt2 / 2, 0, 0, math.Sqrt2 / 2}
+
+type gltfDocument struct {
+	Asset       gltfAsset        `json:"asset"`
+	Scene       int              `json:"scene"`
+	Scenes      []gltfScene      `json:"scenes"`
+	Nodes       []gltfNode       `json:"nodes"`
+	Meshes      []gltfMesh       `json:"meshes,omitempty"`
+	Accessors   []gltfAccessor   `json:"accessors,omitempty"`
+	BufferViews []gltfBufferView `json:"bufferViews,omitempty"`
+	Buffers     []gltfBuffer     `json:"buffers,omitempty"`
+}
+
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator"`
+}
+
+type gltfScene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type gltfNode struct {
+	Name        string    `json:"name,omitempty"`
+	Children    []int     `json:"children,omitempty"`
+	Mesh        *int      `json:"mesh,omitempty"`
+	Translation []float64 `json:"translation,omitempty"`
+	Rotation    []float64 `json:"rotation,omitempty"`
+	Scale       []float64 `json:"scale,omitempty"`
+}
+
+type gltfMesh struct {
+	Name       string          `json:"name,omitempty"`
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    int            `json:"indices"`
+	Mode       int            `json:"mode"`
+}
+
+type gltfAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float64 `json:"min,omitempty"`
+	Max           []float64 `json:"max,omitempty"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	Target     int `json:"target"`
+}
+
+type gltfBuffer struct {
+	ByteLength int    `json:"byteLength"`
+	URI        string `json:"uri"`
+}
+
+// GLTF returns the scene as a glTF 2.0 document, with its data embedded, for viewers which render glTF. Each frame is
+// an empty node posed in the world, and each geometry a node with a triangle mesh already in the world, all under a
+// root node which turns the machine's millimeters and Z up into glTF's meters and Y up. Points have no mesh, so
+// their nodes are only posed.
+func (s *Scene) GLTF() ([]byte, error) {
+	doc := gltfDocument{
+		Asset:  gltfAsset{Version: "2.0", Generator: "rdk"},
+		Scenes: []gltfScene{{Nodes: []int{0}}},
+		Nodes:  []gltfNode{{Name: "world", Rotation: zUpToYUp, Scale: []float64{1e-3, 1e-3, 1e-3}}},
+	}
+	var buf []byte
+	addChild := func(node gltfNode) {
+		doc.Nodes[0].Children = append(doc.Nodes[0].Children, len(doc.Nodes))
+		doc.Nodes = append(doc.Nodes, node)
+	}
+
+	for _, f := range s.Frames {
+		addChild(gltfNode{
+			Name:        f.Name,
+			Translation: []float64{f.Translation.X, f.Translation.Y, f.Translation.Z},
+			Rotation:    []float64{f.Orientation.I, f.Orientation.J, f.Orientation.K, f.Orientation.Real},
+		})
+	}
+
+	for _, g := range s.Geometries {
+		name := g.Label
+		if name == "" {
+			name = g.Frame
+		}
+		vertices, indices := tessellate(g.GeometryConfig)
+		if len(indices) == 0 {
+			pt := g.geometry.Pose().Point()
+			addChild(gltfNode{Name: name, Translation: []float64{pt.X, pt.Y, pt.Z}})
+			continue
+		}
+
+		pose := g.geometry.Pose()
+		minimum := []float64{math.Inf(1), math.Inf(1), math.Inf(1)}
+		maximum := []float64{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+		positionsOffset := len(buf)
+		for _, v := range vertices {
+			v = transformPoint(pose, v)
+			for i, c := range []float64{v.X, v.Y, v.Z} {
+				// the bounds must be those of the float32s written
+				c = float64(float32(c))
+				minimum[i] = math.Min(minimum[i], c)
+				maximum[i] = math.Max(maximum[i], c)
+				buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(c)))
+			}
+		}
+		indicesOffset := len(buf)
+		for _, i := range indices {
+			buf = binary.LittleEndian.AppendUint32(buf, i)
+		}
+
+		positions := len(doc.Accessors)
+		doc.BufferViews = append(doc.BufferViews,
+			gltfBufferView{ByteOffset: positionsOffset, ByteLength: indicesOffset - positionsOffset, Target: gltfArrayBuffer},
+			gltfBufferView{ByteOffset: indicesOffset, ByteLength: len(buf) - indicesOffset, Target: gltfElementBuffer},
+		)
+		doc.Accessors = append(doc.Accessors,
+			gltfAccessor{
+				BufferView: positions, ComponentType: gltfFloat, Count: len(vertices), Type: "VEC3", Min: minimum, Max: maximum,
+			},
+			gltfAccessor{BufferView: positions + 1, ComponentType: gltfUnsignedInt, Count: len(indices), Type: "SCALAR"},
+		)
+		mesh := len(doc.Meshes)
+		doc.Meshes = append(doc.Meshes, gltfMesh{Name: name, Primitives: []gltfPrimitive{{
+			Attributes: map[string]int{"POSITION": positions},
+			Indices:    positions + 1,
+			Mode:       gltfTriangles,
+		}}})
+		addChild(gltfNode{Name: name, Mesh: &mesh})
+	}
+
+	if len(buf) > 0 {
+		doc.Buffers = []gltfBuffer{{
+			ByteLength: len(buf),
+			URI:        "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(buf),
+		}}
+	}
+	return json.Marshal(doc)
+}
+
+// tessellate returns the vertices of a triangle mesh of the geometry, centered on its origin, and the indices of the
+// vertices of each triangle, wound counterclockwise seen from outside. Points have no mesh.
+func tessellate(config *spatialmath.GeometryConfig) ([]r3.Vector, []uint32) {
+	switch config.Type {
+	case spatialmath.BoxType:
+		vertices := make([]r3.Vector, 8)
+		for i := range vertices {
+			vertices[i] = boxCorner(i, config)
+		}
+		var indices []uint32
+		for _, face := range [][4]uint32{{0, 4, 6, 2}, {1, 3, 7, 5}, {0, 1, 5, 4}, {2, 6, 7, 3}, {0, 2, 3, 1}, {4, 5, 7, 6}} {
+			indices = append(indices, face[0], face[1], face[2], face[0], face[2], face[3])
+		}
+		return vertices, indices
+	case spatialmath.SphereType:
+		return tessellateCapsule(config.R, 0)
+	case spatialmath.CapsuleType:
+		return tessellateCapsule(config.R, math.Max(config.L/2-config.R, 0))
+	default:
+		return nil, nil
+	}
+}
+
+// tessellateCapsule returns the mesh of a capsule along Z with the radius and half the length of its segment, which
+// is a sphere when the segment has no length. Its rings run from the top pole to the bottom one, with the upper
+// hemisphere raised by the half length and the lower one lowered.
+func tessellateCapsule(radius, halfSegment float64) ([]r3.Vector, []uint32) {
+	var vertices []r3.Vector
+	addRing := func(ring int, offset float64) {
+		polar := math.Pi * float64(ring) / sphereRings
+		for segment := 0; segment < sphereSegments; segment++ {
+			azimuth := 2 * math.Pi * float64(segment) / sphereSegments
+			vertices = append(vertices, r3.Vector{
+				X: radius * math.Sin(polar) * math.Cos(azimuth),
+				Y: radius * math.Sin(polar) * math.Sin(azimuth),
+				Z: radius*math.Cos(polar) + offset,
+			})
+		}
+	}
+	for ring := 0; ring <= sphereRings/2; ring++ {
+		addRing(ring, halfSegment)
+	}
+	// the equator is repeated, lowered, so that the band between the hemispheres is the side of the capsule
+	for ring := sphereRings / 2; ring <= sphereRings; ring++ {
+		addRing(ring, -halfSegment)
+	}
+
+	var indices []uint32
+	rows := len(vertices) / sphereSegments
+	for row := 0; row < rows-1; row++ {
+		for segment := 0; segment < sphereSegments; segment++ {
+			next := (segment + 1) % sphereSegments
+			a, b := uint32(row*sphereSegments+segment), uint32(row*sphereSegments+next)
+			c, d := uint32((row+1)*sphereSegments+segment), uint32((row+1)*sphereSegments+next)
+			indices = append(indices, a, c, d, a, d, b)
+		}
+	}
+	return vertices, indices
+}
@@ -0,0 +1,157 @@
+package framesystem
+
+import (
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// A Scene is a snapshot of a machine's world for viewers to render: the pose of every frame of its frame system and
+// the geometries attached to them, all in the world frame, and the bounds of the space they take up. Distances are
+// in millimeters.
+type Scene struct {
+	Frames     []SceneFrame    `json:"frames"`
+	Geometries []SceneGeometry `json:"geometries"`
+	// Bounds is the axis aligned box which contains every frame and geometry, or nil for an empty scene.
+	Bounds *SceneBounds `json:"bounds,omitempty"`
+}
+
+// A SceneFrame is a frame of a Scene and where it is in the world.
+type SceneFrame struct {
+	Name        string          `json:"name"`
+	Parent      string          `json:"parent"`
+	Translation r3.Vector       `json:"translation"`
+	Orientation SceneQuaternion `json:"orientation"`
+}
+
+// A SceneQuaternion is an orientation in a Scene.
+type SceneQuaternion struct {
+	Real float64 `json:"real"`
+	I    float64 `json:"i"`
+	J    float64 `json:"j"`
+	K    float64 `json:"k"`
+}
+
+// A SceneGeometry is a geometry of a Scene, in the same form geometries are configured in, posed in the world.
+type SceneGeometry struct {
+	Frame string `json:"frame"`
+	*spatialmath.GeometryConfig
+
+	geometry spatialmath.Geometry
+}
+
+// SceneBounds are the corners of the box a Scene fits in.
+type SceneBounds struct {
+	Min r3.Vector `json:"min"`
+	Max r3.Vector `json:"max"`
+}
+
+// NewScene returns the Scene of the frame system with its frames at the inputs.
+func NewScene(fs referenceframe.FrameSystem, inputs map[string][]referenceframe.Input) (*Scene, error) {
+	scene := &Scene{Frames: []SceneFrame{}, Geometries: []SceneGeometry{}}
+	names := fs.FrameNames()
+	sort.Strings(names)
+	for _, name := range names {
+		parent, err := fs.Parent(fs.Frame(name))
+		if err != nil {
+			return nil, err
+		}
+		tf, err := fs.Transform(inputs, referenceframe.NewPoseInFrame(name, spatialmath.NewZeroPose()), referenceframe.World)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to place frame %q in the world", name)
+		}
+		pose := tf.(*referenceframe.PoseInFrame).Pose()
+		q := pose.Orientation().Quaternion()
+		scene.Frames = append(scene.Frames, SceneFrame{
+			Name:        name,
+			Parent:      parent.Name(),
+			Translation: pose.Point(),
+			Orientation: SceneQuaternion{Real: q.Real, I: q.Imag, J: q.Jmag, K: q.Kmag},
+		})
+		scene.Bounds = scene.Bounds.include(pose.Point())
+	}
+
+	geometries, err := referenceframe.FrameSystemGeometries(fs, inputs)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		gif, ok := geometries[name]
+		if !ok {
+			continue
+		}
+		for _, g := range gif.Geometries() {
+			config, err := spatialmath.NewGeometryConfig(g)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to describe a geometry of frame %q", name)
+			}
+			scene.Geometries = append(scene.Geometries, SceneGeometry{Frame: name, GeometryConfig: config, geometry: g})
+			for _, pt := range extremePoints(config, g.Pose()) {
+				scene.Bounds = scene.Bounds.include(pt)
+			}
+		}
+	}
+	return scene, nil
+}
+
+// include returns the bounds grown to contain the point, which are new bounds around it if b is nil.
+func (b *SceneBounds) include(pt r3.Vector) *SceneBounds {
+	if b == nil {
+		return &SceneBounds{Min: pt, Max: pt}
+	}
+	return &SceneBounds{
+		Min: r3.Vector{X: math.Min(b.Min.X, pt.X), Y: math.Min(b.Min.Y, pt.Y), Z: math.Min(b.Min.Z, pt.Z)},
+		Max: r3.Vector{X: math.Max(b.Max.X, pt.X), Y: math.Max(b.Max.Y, pt.Y), Z: math.Max(b.Max.Z, pt.Z)},
+	}
+}
+
+// extremePoints returns points whose bounds are the bounds of the geometry at the pose: the corners of boxes, and
+// the points a radius away along each axis from the center of spheres and the ends of the segment of capsules.
+func extremePoints(config *spatialmath.GeometryConfig, pose spatialmath.Pose) []r3.Vector {
+	var points []r3.Vector
+	switch config.Type {
+	case spatialmath.BoxType:
+		for i := 0; i < 8; i++ {
+			points = append(points, transformPoint(pose, boxCorner(i, config)))
+		}
+		return points
+	case spatialmath.SphereType:
+		points = []r3.Vector{pose.Point()}
+	case spatialmath.CapsuleType:
+		halfSegment := r3.Vector{Z: math.Max(config.L/2-config.R, 0)}
+		points = []r3.Vector{transformPoint(pose, halfSegment), transformPoint(pose, halfSegment.Mul(-1))}
+	default:
+		return []r3.Vector{pose.Point()}
+	}
+	var grown []r3.Vector
+	for _, pt := range points {
+		for _, axis := range []r3.Vector{{X: 1}, {Y: 1}, {Z: 1}} {
+			grown = append(grown, pt.Add(axis.Mul(config.R)), pt.Sub(axis.Mul(config.R)))
+		}
+	}
+	return grown
+}
+
+// boxCorner returns corner i of the box, whose bits 0, 1 and 2 pick the positive side along X, Y and Z.
+func boxCorner(i int, config *spatialmath.GeometryConfig) r3.Vector {
+	corner := r3.Vector{X: -config.X / 2, Y: -config.Y / 2, Z: -config.Z / 2}
+	if i&1 != 0 {
+		corner.X = config.X / 2
+	}
+	if i&2 != 0 {
+		corner.Y = config.Y / 2
+	}
+	if i&4 != 0 {
+		corner.Z = config.Z / 2
+	}
+	return corner
+}
+
+func transformPoint(pose spatialmath.Pose, pt r3.Vector) r3.Vector {
+	return spatialmath.Compose(pose, spatialmath.NewPoseFromPoint(pt)).Point()
+}
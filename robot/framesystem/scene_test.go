@@ -0,0 +1,128 @@
+package framesystem_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/spatialmath"
+)
+
+func TestScene(t *testing.T) {
+	link := func(cfg *referenceframe.LinkConfig) *referenceframe.FrameSystemPart {
+		lif, err := cfg.ParseConfig()
+		test.That(t, err, test.ShouldBeNil)
+		return &referenceframe.FrameSystemPart{FrameConfig: lif}
+	}
+	parts := []*referenceframe.FrameSystemPart{
+		link(&referenceframe.LinkConfig{
+			ID:          "table",
+			Parent:      referenceframe.World,
+			Translation: r3.Vector{Z: -10},
+			Geometry:    &spatialmath.GeometryConfig{Type: spatialmath.BoxType, X: 1000, Y: 500, Z: 20},
+		}),
+		link(&referenceframe.LinkConfig{
+			ID:          "ball",
+			Parent:      "table",
+			Translation: r3.Vector{X: 100, Z: 60},
+			Geometry:    &spatialmath.GeometryConfig{Type: spatialmath.SphereType, R: 50},
+		}),
+		link(&referenceframe.LinkConfig{
+			ID:          "pole",
+			Parent:      referenceframe.World,
+			Translation: r3.Vector{X: 600},
+			Geometry:    &spatialmath.GeometryConfig{Type: spatialmath.CapsuleType, R: 10, L: 400, TranslationOffset: r3.Vector{Z: 200}},
+		}),
+	}
+	fs, err := referenceframe.NewFrameSystem("test", parts, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	scene, err := framesystem.NewScene(fs, referenceframe.StartPositions(fs))
+	test.That(t, err, test.ShouldBeNil)
+
+	frames := map[string]framesystem.SceneFrame{}
+	for _, f := range scene.Frames {
+		frames[f.Name] = f
+	}
+	test.That(t, frames, test.ShouldHaveLength, 6)
+	test.That(t, frames["ball"].Parent, test.ShouldEqual, "ball_origin")
+	test.That(t, frames["ball"].Translation, test.ShouldResemble, r3.Vector{X: 100, Z: 50})
+	test.That(t, frames["ball"].Orientation, test.ShouldResemble, framesystem.SceneQuaternion{Real: 1})
+
+	test.That(t, scene.Geometries, test.ShouldHaveLength, 3)
+	geometries := map[string]framesystem.SceneGeometry{}
+	for _, g := range scene.Geometries {
+		geometries[g.Frame] = g
+	}
+	test.That(t, geometries["table_origin"].Type, test.ShouldEqual, spatialmath.BoxType)
+	test.That(t, geometries["table_origin"].TranslationOffset, test.ShouldResemble, r3.Vector{Z: -10})
+	test.That(t, geometries["ball_origin"].TranslationOffset, test.ShouldResemble, r3.Vector{X: 100, Z: 50})
+	test.That(t, geometries["pole_origin"].TranslationOffset, test.ShouldResemble, r3.Vector{X: 600, Z: 200})
+
+	// the table spans X and Y, and the pole Z
+	test.That(t, scene.Bounds, test.ShouldResemble, &framesystem.SceneBounds{
+		Min: r3.Vector{X: -500, Y: -250, Z: -20},
+		Max: r3.Vector{X: 610, Y: 250, Z: 400},
+	})
+
+	_, err = json.Marshal(scene)
+	test.That(t, err, test.ShouldBeNil)
+
+	t.Run("glTF", func(t *testing.T) {
+		data, err := scene.GLTF()
+		test.That(t, err, test.ShouldBeNil)
+		var doc struct {
+			Asset struct {
+				Version string `json:"version"`
+			} `json:"asset"`
+			Nodes []struct {
+				Name     string `json:"name"`
+				Children []int  `json:"children"`
+				Mesh     *int   `json:"mesh"`
+			} `json:"nodes"`
+			Meshes    []json.RawMessage `json:"meshes"`
+			Accessors []struct {
+				Count int       `json:"count"`
+				Min   []float64 `json:"min"`
+				Max   []float64 `json:"max"`
+			} `json:"accessors"`
+			Buffers []struct {
+				ByteLength int    `json:"byteLength"`
+				URI        string `json:"uri"`
+			} `json:"buffers"`
+		}
+		test.That(t, json.Unmarshal(data, &doc), test.ShouldBeNil)
+		test.That(t, doc.Asset.Version, test.ShouldEqual, "2.0")
+		// the world, its six frames and three geometries
+		test.That(t, doc.Nodes, test.ShouldHaveLength, 10)
+		test.That(t, doc.Nodes[0].Children, test.ShouldHaveLength, 9)
+		test.That(t, doc.Meshes, test.ShouldHaveLength, 3)
+		test.That(t, doc.Accessors, test.ShouldHaveLength, 6)
+		test.That(t, doc.Buffers, test.ShouldHaveLength, 1)
+
+		var meshNames []string
+		for _, n := range doc.Nodes {
+			if n.Mesh != nil {
+				meshNames = append(meshNames, n.Name)
+			}
+		}
+		test.That(t, meshNames, test.ShouldResemble, []string{"ball_origin", "pole_origin", "table_origin"})
+
+		// geometries are in the order of the names of their frames
+		sphere := doc.Accessors[0]
+		test.That(t, sphere.Min[0], test.ShouldAlmostEqual, 50, 1e-3)
+		test.That(t, sphere.Max[0], test.ShouldAlmostEqual, 150, 1e-3)
+		test.That(t, sphere.Max[2], test.ShouldAlmostEqual, 100, 1e-3)
+		table := doc.Accessors[4]
+		test.That(t, table.Count, test.ShouldEqual, 8)
+		test.That(t, table.Min, test.ShouldResemble, []float64{-500, -250, -20})
+		test.That(t, table.Max, test.ShouldResemble, []float64{500, 250, 0})
+		test.That(t, doc.Accessors[5].Count, test.ShouldEqual, 36)
+		test.That(t, math.Mod(float64(doc.Accessors[1].Count), 3), test.ShouldEqual, 0)
+	})
+}
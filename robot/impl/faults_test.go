@@ -0,0 +1,65 @@
+package robotimpl
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.viam.com/test"
+	"go.viam.com/utils"
+	"go.viam.com/utils/rpc"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/testutils/robottestutils"
+	rutils "go.viam.com/rdk/utils"
+)
+
+func TestFaultsRoute(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	apiKeyID := uuid.New().String()
+	apiKey := utils.RandomAlphaString(32)
+	authHandlers := []config.AuthHandlerConfig{
+		{
+			Type:   rpc.CredentialsTypeAPIKey,
+			Config: rutils.AttributeMap{apiKeyID: apiKey, "keys": []string{apiKeyID}},
+		},
+	}
+	request := func(addr, method string, withKey bool) int {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, method, "http://"+addr+"/debug/faults",
+			strings.NewReader(`{"faults": [{"method": "GetReadings", "latency_ms": 10}]}`))
+		test.That(t, err, test.ShouldBeNil)
+		req.Header.Set("Content-Type", "application/json")
+		if withKey {
+			req.SetBasicAuth(apiKeyID, apiKey)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp.Body.Close(), test.ShouldBeNil)
+		return resp.StatusCode
+	}
+
+	// a debug server does not inject faults unless debug control is enabled
+	r := setupLocalRobot(t, ctx, &config.Config{}, logger)
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	options.Debug = true
+	options.Auth.Handlers = authHandlers
+	test.That(t, r.StartWeb(ctx, options), test.ShouldBeNil)
+	test.That(t, request(addr, http.MethodGet, true), test.ShouldNotEqual, http.StatusOK)
+	test.That(t, request(addr, http.MethodPut, true), test.ShouldNotEqual, http.StatusOK)
+
+	r = setupLocalRobot(t, ctx, &config.Config{}, logger)
+	options, _, addr = robottestutils.CreateBaseOptionsAndListener(t)
+	options.Network.EnableDebugControl = true
+	options.Auth.Handlers = authHandlers
+	test.That(t, r.StartWeb(ctx, options), test.ShouldBeNil)
+	test.That(t, request(addr, http.MethodGet, false), test.ShouldEqual, http.StatusUnauthorized)
+	test.That(t, request(addr, http.MethodPut, false), test.ShouldEqual, http.StatusUnauthorized)
+	test.That(t, request(addr, http.MethodGet, true), test.ShouldEqual, http.StatusOK)
+	test.That(t, request(addr, http.MethodPut, true), test.ShouldEqual, http.StatusOK)
+}
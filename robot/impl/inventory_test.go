@@ -0,0 +1,81 @@
+package robotimpl
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.viam.com/test"
+	"go.viam.com/utils"
+	"go.viam.com/utils/rpc"
+
+	"go.viam.com/rdk/components/sensor"
+	_ "go.viam.com/rdk/components/sensor/fake"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/robot/inventory"
+	"go.viam.com/rdk/testutils/robottestutils"
+	rutils "go.viam.com/rdk/utils"
+)
+
+func TestInventoryRoutes(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	cfg, err := config.FromReader(ctx, "", strings.NewReader(`{
+    "components": [{"name": "lidar", "type": "sensor", "model": "fake"}]
+}`), logger)
+	test.That(t, err, test.ShouldBeNil)
+	r := setupLocalRobot(t, ctx, cfg, logger)
+	test.That(t, r.Inventory().SetMachine(inventory.Metadata{SerialNumber: "machine-1"}), test.ShouldBeNil)
+	test.That(t, r.Inventory().SetResource(sensor.Named("lidar"), inventory.Metadata{SerialNumber: "lidar-1"}), test.ShouldBeNil)
+
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	apiKeyID := uuid.New().String()
+	apiKey := utils.RandomAlphaString(32)
+	options.Auth.Handlers = []config.AuthHandlerConfig{
+		{
+			Type:   rpc.CredentialsTypeAPIKey,
+			Config: rutils.AttributeMap{apiKeyID: apiKey, "keys": []string{apiKeyID}},
+		},
+	}
+	test.That(t, r.StartWeb(ctx, options), test.ShouldBeNil)
+
+	get := func(path string, withKey bool, body interface{}) int {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+path, nil)
+		test.That(t, err, test.ShouldBeNil)
+		if withKey {
+			req.SetBasicAuth(apiKeyID, apiKey)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		test.That(t, err, test.ShouldBeNil)
+		defer func() {
+			test.That(t, resp.Body.Close(), test.ShouldBeNil)
+		}()
+		if resp.StatusCode == http.StatusOK {
+			test.That(t, json.NewDecoder(resp.Body).Decode(body), test.ShouldBeNil)
+		}
+		return resp.StatusCode
+	}
+
+	var snapshot inventory.Snapshot
+	test.That(t, get("/debug/inventory", false, &snapshot), test.ShouldEqual, http.StatusUnauthorized)
+	test.That(t, get("/debug/inventory", true, &snapshot), test.ShouldEqual, http.StatusOK)
+	test.That(t, snapshot.Machine.SerialNumber, test.ShouldEqual, "machine-1")
+	test.That(t, snapshot.Resources["rdk:component:sensor/lidar"].SerialNumber, test.ShouldEqual, "lidar-1")
+
+	// the diagnostics of the machine include its inventory
+	var diagnostics struct {
+		Resources []string           `json:"resources"`
+		Inventory inventory.Snapshot `json:"inventory"`
+	}
+	test.That(t, get("/debug/diagnostics", false, &diagnostics), test.ShouldEqual, http.StatusUnauthorized)
+	test.That(t, get("/debug/diagnostics", true, &diagnostics), test.ShouldEqual, http.StatusOK)
+	test.That(t, diagnostics.Resources, test.ShouldContain, "rdk:component:sensor/lidar")
+	test.That(t, diagnostics.Inventory.Machine.SerialNumber, test.ShouldEqual, "machine-1")
+	test.That(t, diagnostics.Inventory.Resources["rdk:component:sensor/lidar"].SerialNumber, test.ShouldEqual, "lidar-1")
+}
@@ -7,6 +7,7 @@ package robotimpl
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -19,6 +20,8 @@ import (
 	goutils "go.viam.com/utils"
 	"go.viam.com/utils/pexec"
 	"go.viam.com/utils/rpc"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
 
 	"go.viam.com/rdk/cloud"
 	"go.viam.com/rdk/config"
@@ -31,6 +34,7 @@ import (
 	"go.viam.com/rdk/robot"
 	"go.viam.com/rdk/robot/client"
 	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/robot/inventory"
 	"go.viam.com/rdk/robot/packages"
 	"go.viam.com/rdk/robot/web"
 	weboptions "go.viam.com/rdk/robot/web/options"
@@ -73,6 +77,22 @@ type localRobot struct {
 	// internal services that are in the graph but we also hold onto
 	webSvc   web.Service
 	frameSvc framesystem.Service
+
+	inventory *inventory.Store
+
+	// alertedErrors holds the errors of the resources which failed to build or reconfigure, which were already
+	// published as alerts.
+	alertedErrorsMu sync.Mutex
+	alertedErrors   map[resource.Name]string
+
+	// simulated holds the real configs of the components which are swapped for their fakes. A config is nil until
+	// the swap is made.
+	simulatedMu sync.Mutex
+	simulated   map[resource.Name]*resource.Config
+
+	// shutdownActions are the shutdown actions of the most recent config, which park the actuators on Close.
+	shutdownActionsMu sync.Mutex
+	shutdownActions   []config.ShutdownAction
 }
 
 // ExportResourcesAsDot exports the resource graph as a DOT representation for
@@ -82,6 +102,11 @@ func (r *localRobot) ExportResourcesAsDot(index int) (resource.GetSnapshotInfo,
 	return r.manager.ExportDot(index)
 }
 
+// Inventory returns the asset metadata of the robot and its resources.
+func (r *localRobot) Inventory() *inventory.Store {
+	return r.inventory
+}
+
 // RemoteByName returns a remote robot by name. If it does not exist
 // nil is returned.
 func (r *localRobot) RemoteByName(name string) (robot.Robot, bool) {
@@ -147,6 +172,8 @@ func (r *localRobot) Close(ctx context.Context) error {
 		}
 	}
 	r.activeBackgroundWorkers.Wait()
+	// actuators are parked while every resource is still open
+	r.runShutdownActions(ctx)
 	r.sessionManager.Close()
 
 	var err error
@@ -172,9 +199,22 @@ func (r *localRobot) StopAll(ctx context.Context, extra map[resource.Name]map[st
 		op.Cancel()
 	}
 
-	// Stop all stoppable resources
+	return r.stopResources(ctx, r.ResourceNames(), extra)
+}
+
+// StopTagged stops every actuator tagged with the given tag.
+func (r *localRobot) StopTagged(ctx context.Context, tag string, extra map[resource.Name]map[string]interface{}) error {
+	names := robot.NamesByTag(r, tag)
+	if len(names) == 0 {
+		return errors.Errorf("no resources are tagged %q", tag)
+	}
+	return r.stopResources(ctx, names, extra)
+}
+
+// stopResources stops those of the named resources which are actuators.
+func (r *localRobot) stopResources(ctx context.Context, names []resource.Name, extra map[resource.Name]map[string]interface{}) error {
 	resourceErrs := []string{}
-	for _, name := range r.ResourceNames() {
+	for _, name := range names {
 		res, err := r.ResourceByName(name)
 		if err != nil {
 			resourceErrs = append(resourceErrs, name.Name)
@@ -298,6 +338,11 @@ func (r *localRobot) Status(ctx context.Context, resourceNames []resource.Name)
 		// Request status of resources associated with the remote from the remote.
 		remoteResourceStatuses, err := remote.Status(ctx, remoteResourceNames)
 		if err != nil {
+			if r.isUnimplementedByConstrainedPeer(remoteName, err) {
+				// the status of each resource is then asked of the resource itself below
+				r.Logger().CDebugw(ctx, "constrained remote does not report status", "remote", remoteName)
+				continue
+			}
 			return nil, err
 		}
 		for _, remoteResourceStatus := range remoteResourceStatuses {
@@ -475,6 +520,17 @@ func newWithResources(
 	if rOpts.viamHomeDir != "" {
 		homeDir = rOpts.viamHomeDir
 	}
+
+	inventoryPath := filepath.Join(homeDir, "inventory.json")
+	if cloudID != "" {
+		inventoryPath = filepath.Join(homeDir, fmt.Sprintf("inventory_%s.json", cloudID))
+	}
+	r.inventory, err = inventory.NewStore(inventoryPath)
+	if err != nil {
+		// a damaged inventory should not keep the machine from starting
+		logger.CErrorw(ctx, "failed to load inventory, starting with an empty one", "error", err)
+		r.inventory, _ = inventory.NewStore("")
+	}
 	// Once web service is started, start module manager
 	r.manager.startModuleManager(
 		closeCtx,
@@ -511,6 +567,7 @@ func newWithResources(
 			if r.manager.anyResourcesNotConfigured() {
 				anyChanges = true
 				r.manager.completeConfig(closeCtx, r, false)
+				r.publishResourceAlerts()
 			}
 			if anyChanges {
 				r.updateWeakDependents(ctx)
@@ -534,6 +591,14 @@ func newWithResources(
 		r.updateWeakDependents(ctx)
 	}
 
+	// startup actions are only taken on boot, not when the config changes
+	if actions := append([]config.StartupAction(nil), cfg.StartupActions...); len(actions) != 0 {
+		r.activeBackgroundWorkers.Add(1)
+		goutils.ManagedGo(func() {
+			r.runStartupActions(closeCtx, actions)
+		}, r.activeBackgroundWorkers.Done)
+	}
+
 	successful = true
 	return r, nil
 }
@@ -897,6 +962,20 @@ func (r *localRobot) getLocalFrameSystemParts() ([]*referenceframe.FrameSystemPa
 	return parts, nil
 }
 
+// isUnimplementedByConstrainedPeer returns whether err comes from a constrained remote only serving part of the API,
+// in which case the missing part is skipped instead of failing the request.
+func (r *localRobot) isUnimplementedByConstrainedPeer(remoteName string, err error) bool {
+	if grpcstatus.Code(err) != codes.Unimplemented {
+		return false
+	}
+	for _, remoteCfg := range r.Config().Remotes {
+		if remoteCfg.Name == remoteName {
+			return remoteCfg.Constrained != nil
+		}
+	}
+	return false
+}
+
 func (r *localRobot) getRemoteFrameSystemParts(ctx context.Context) ([]*referenceframe.FrameSystemPart, error) {
 	cfg := r.Config()
 
@@ -945,6 +1024,11 @@ func (r *localRobot) getRemoteFrameSystemParts(ctx context.Context) ([]*referenc
 		// get the parts from the remote itself
 		remoteFsCfg, err := remote.FrameSystemConfig(ctx)
 		if err != nil {
+			if r.isUnimplementedByConstrainedPeer(remoteCfg.Name, err) {
+				// the remote is still placed in the frame system, it just contributes no parts of its own
+				r.logger.CDebugf(ctx, "constrained remote %q does not serve a frame system, skipping its parts", remoteCfg.Name)
+				continue
+			}
 			return nil, errors.Wrapf(err, "error from remote %q", remoteCfg.Name)
 		}
 		framesystem.PrefixRemoteParts(remoteFsCfg.Parts, remoteCfg.Name, parentName)
@@ -1083,11 +1167,23 @@ func dialRobotClient(
 ) (*client.RobotClient, error) {
 	rOpts := []client.RobotClientOption{client.WithDialOptions(dialOpts...), client.WithRemoteName(config.Name)}
 
-	if config.ConnectionCheckInterval != 0 {
-		rOpts = append(rOpts, client.WithCheckConnectedEvery(config.ConnectionCheckInterval))
+	checkConnectedEvery, reconnectEvery := config.ConnectionIntervals()
+	if checkConnectedEvery != 0 {
+		rOpts = append(rOpts, client.WithCheckConnectedEvery(checkConnectedEvery))
 	}
-	if config.ReconnectInterval != 0 {
-		rOpts = append(rOpts, client.WithReconnectEvery(config.ReconnectInterval))
+	if reconnectEvery != 0 {
+		rOpts = append(rOpts, client.WithReconnectEvery(reconnectEvery))
+	}
+	if config.Constrained != nil {
+		constrained := config.Constrained.WithDefaults()
+		rOpts = append(rOpts,
+			client.WithRefreshEvery(constrained.RefreshInterval),
+			client.WithUnavailableRetries(uint(constrained.Retries)),
+			client.WithResponseCache(constrained.CacheTTL),
+		)
+		if constrained.SessionHeartbeatInterval != 0 {
+			rOpts = append(rOpts, client.WithSessionHeartbeatInterval(constrained.SessionHeartbeatInterval))
+		}
 	}
 
 	robotClient, err := client.New(
@@ -1123,51 +1219,16 @@ func (r *localRobot) reconfigure(ctx context.Context, newConfig *config.Config,
 	}
 	allErrs = multierr.Combine(allErrs, r.localPackages.Sync(ctx, newConfig.Packages, newConfig.Modules))
 
-	// Add default services and process their dependencies. Dependencies may
-	// already come from config validation so we check that here.
-	seen := make(map[resource.API]int)
-	for idx, val := range newConfig.Services {
-		seen[val.API] = idx
-	}
-	for _, name := range resource.DefaultServices() {
-		existingConfIdx, hasExistingConf := seen[name.API]
-		var svcCfg resource.Config
-		if hasExistingConf {
-			svcCfg = newConfig.Services[existingConfIdx]
-		} else {
-			svcCfg = resource.Config{
-				Name:  name.Name,
-				Model: resource.DefaultServiceModel,
-				API:   name.API,
-			}
-		}
+	// Add default services and process their dependencies.
+	allErrs = multierr.Combine(allErrs, addDefaultServices(newConfig))
 
-		if svcCfg.ConvertedAttributes != nil || svcCfg.Attributes != nil {
-			// previously processed
-			continue
-		}
+	// Simulated components are swapped for their fakes before anything is built from the new config.
+	allErrs = multierr.Combine(allErrs, r.applySimulation(newConfig))
 
-		// we find dependencies through configs, so we must try to validate even a default config
-		if reg, ok := resource.LookupRegistration(svcCfg.API, svcCfg.Model); ok && reg.AttributeMapConverter != nil {
-			converted, err := reg.AttributeMapConverter(utils.AttributeMap{})
-			if err != nil {
-				allErrs = multierr.Combine(allErrs, errors.Wrapf(err, "error converting attributes for %s", svcCfg.API))
-				continue
-			}
-			svcCfg.ConvertedAttributes = converted
-			deps, err := converted.Validate("")
-			if err != nil {
-				allErrs = multierr.Combine(allErrs, errors.Wrapf(err, "error getting default service dependencies for %s", svcCfg.API))
-				continue
-			}
-			svcCfg.ImplicitDependsOn = deps
-		}
-		if hasExistingConf {
-			newConfig.Services[existingConfIdx] = svcCfg
-		} else {
-			newConfig.Services = append(newConfig.Services, svcCfg)
-		}
-	}
+	// shutdown actions follow the config even when no resource changes
+	r.shutdownActionsMu.Lock()
+	r.shutdownActions = append([]config.ShutdownAction(nil), newConfig.ShutdownActions...)
+	r.shutdownActionsMu.Unlock()
 
 	// Now that we have the new config and all references are resolved, diff it
 	// with the current generated config to see what has changed
@@ -1208,6 +1269,7 @@ func (r *localRobot) reconfigure(ctx context.Context, newConfig *config.Config,
 	// update weak dependents.
 	r.manager.completeConfig(ctx, r, forceSync)
 	r.updateWeakDependents(ctx)
+	r.publishResourceAlerts()
 
 	// Finally we actually remove marked resources and Close any that are
 	// still unclosed.
@@ -1224,11 +1286,86 @@ func (r *localRobot) reconfigure(ctx context.Context, newConfig *config.Config,
 
 	if allErrs != nil {
 		r.logger.CErrorw(ctx, "The following errors were gathered during reconfiguration", "errors", allErrs)
+		r.webSvc.PublishEvent(web.NewAlert(nil, "error", "reconfiguration failed: "+allErrs.Error()))
 	} else {
 		r.logger.CInfow(ctx, "Robot (re)configured")
 	}
 }
 
+// addDefaultServices adds the default services missing from a config and processes their dependencies. Dependencies
+// may already come from config validation so we check that here.
+func addDefaultServices(newConfig *config.Config) error {
+	var allErrs error
+	seen := make(map[resource.API]int)
+	for idx, val := range newConfig.Services {
+		seen[val.API] = idx
+	}
+	for _, name := range resource.DefaultServices() {
+		existingConfIdx, hasExistingConf := seen[name.API]
+		var svcCfg resource.Config
+		if hasExistingConf {
+			svcCfg = newConfig.Services[existingConfIdx]
+		} else {
+			svcCfg = resource.Config{
+				Name:  name.Name,
+				Model: resource.DefaultServiceModel,
+				API:   name.API,
+			}
+		}
+
+		if svcCfg.ConvertedAttributes != nil || svcCfg.Attributes != nil {
+			// previously processed
+			continue
+		}
+
+		// we find dependencies through configs, so we must try to validate even a default config
+		if reg, ok := resource.LookupRegistration(svcCfg.API, svcCfg.Model); ok && reg.AttributeMapConverter != nil {
+			converted, err := reg.AttributeMapConverter(utils.AttributeMap{})
+			if err != nil {
+				allErrs = multierr.Combine(allErrs, errors.Wrapf(err, "error converting attributes for %s", svcCfg.API))
+				continue
+			}
+			svcCfg.ConvertedAttributes = converted
+			deps, err := converted.Validate("")
+			if err != nil {
+				allErrs = multierr.Combine(allErrs, errors.Wrapf(err, "error getting default service dependencies for %s", svcCfg.API))
+				continue
+			}
+			svcCfg.ImplicitDependsOn = deps
+		}
+		if hasExistingConf {
+			newConfig.Services[existingConfIdx] = svcCfg
+		} else {
+			newConfig.Services = append(newConfig.Services, svcCfg)
+		}
+	}
+	return allErrs
+}
+
+// publishResourceAlerts publishes an alert on the event feed for every resource which newly failed to build or
+// reconfigure.
+func (r *localRobot) publishResourceAlerts() {
+	r.alertedErrorsMu.Lock()
+	defer r.alertedErrorsMu.Unlock()
+	failed := map[resource.Name]string{}
+	for _, name := range r.manager.resources.Names() {
+		node, ok := r.manager.resources.Node(name)
+		if !ok {
+			continue
+		}
+		err := node.LastError()
+		if err == nil {
+			continue
+		}
+		failed[name] = err.Error()
+		if r.alertedErrors[name] != err.Error() {
+			name := name
+			r.webSvc.PublishEvent(web.NewAlert(&name, "error", err.Error()))
+		}
+	}
+	r.alertedErrors = failed
+}
+
 // checkMaxInstance checks to see if the local robot has reached the maximum number of a specific resource type that are local.
 func (r *localRobot) checkMaxInstance(api resource.API, max int) error {
 	maxInstance := 0
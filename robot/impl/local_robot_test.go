@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"net/http"
 	"os"
 	"path"
 	"strings"
@@ -773,6 +774,71 @@ func TestStopAll(t *testing.T) {
 	test.That(t, stopAllErr, test.ShouldBeNil)
 }
 
+func TestStopTagged(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	model := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(8))
+	arms := map[string]*dummyArm{"left": {}, "right": {}, "gripper_arm": {}}
+	resource.RegisterComponent(
+		arm.API,
+		model,
+		resource.Registration[arm.Arm, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (arm.Arm, error) {
+			return arms[conf.Name], nil
+		}})
+	defer func() {
+		resource.Deregister(arm.API, model)
+	}()
+
+	armConfig := fmt.Sprintf(`{
+		"components": [
+			{"model": "%[1]s", "name": "left", "type": "arm", "tags": ["drive"]},
+			{"model": "%[1]s", "name": "right", "type": "arm", "tags": ["drive", "telemetry"]},
+			{"model": "%[1]s", "name": "gripper_arm", "type": "arm"}
+		]
+	}`, model.String())
+	cfg, err := config.FromReader(context.Background(), "", strings.NewReader(armConfig), logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	ctx := context.Background()
+	r := setupLocalRobot(t, ctx, cfg, logger)
+
+	test.That(t, robot.NamesByTag(r, "drive"), test.ShouldResemble, []resource.Name{arm.Named("left"), arm.Named("right")})
+	test.That(t, robot.NamesByTag(r, "telemetry"), test.ShouldResemble, []resource.Name{arm.Named("right")})
+
+	err = r.StopTagged(ctx, "drive", map[resource.Name]map[string]interface{}{arm.Named("right"): {"foo": "bar"}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, arms["left"].stopCount, test.ShouldEqual, 1)
+	test.That(t, arms["right"].stopCount, test.ShouldEqual, 1)
+	test.That(t, arms["right"].extra, test.ShouldResemble, map[string]interface{}{"foo": "bar"})
+	test.That(t, arms["gripper_arm"].stopCount, test.ShouldEqual, 0)
+
+	err = r.StopTagged(ctx, "lift", nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `no resources are tagged "lift"`)
+
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	options.Network.EnableDebugControl = true
+	test.That(t, r.StartWeb(ctx, options), test.ShouldBeNil)
+	post := func(contentType string) int {
+		t.Helper()
+		resp, err := http.Post("http://"+addr+"/debug/tagged/stop", contentType, strings.NewReader(`{"tag": "telemetry"}`))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp.Body.Close(), test.ShouldBeNil)
+		return resp.StatusCode
+	}
+	// plain text, which other sites can post, is refused
+	test.That(t, post("text/plain"), test.ShouldEqual, http.StatusBadRequest)
+	test.That(t, arms["right"].stopCount, test.ShouldEqual, 1)
+	test.That(t, post("application/json"), test.ShouldEqual, http.StatusOK)
+	test.That(t, arms["right"].stopCount, test.ShouldEqual, 2)
+	test.That(t, arms["left"].stopCount, test.ShouldEqual, 1)
+}
+
 type dummyBoard struct {
 	board.Board
 	closeCount int
@@ -0,0 +1,195 @@
+package robotimpl
+
+import (
+	"context"
+	"sort"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+// PreviewReconfigure reports what reconfiguring the robot with the given config would change, without changing
+// anything. The config is prepared as Reconfigure prepares it, with default services added and simulated components
+// swapped for their fakes, but the given config is not modified.
+func (r *localRobot) PreviewReconfigure(ctx context.Context, newConfig *config.Config) (*robot.ReconfigurePreview, error) {
+	cfg := *newConfig
+	cfg.Components = append([]resource.Config(nil), newConfig.Components...)
+	cfg.Services = append([]resource.Config(nil), newConfig.Services...)
+	if err := addDefaultServices(&cfg); err != nil {
+		return nil, err
+	}
+	if err := r.simulate(&cfg, false); err != nil {
+		return nil, err
+	}
+	diff, err := config.DiffConfigs(*r.Config(), cfg, false)
+	if err != nil {
+		return nil, err
+	}
+	return r.manager.previewUpdate(diff), nil
+}
+
+// previewUpdate reports what updating the resources with the given diff would change, the way markRemoved,
+// updateResources and completeConfig would change them.
+func (manager *resourceManager) previewUpdate(diff *config.Diff) *robot.ReconfigurePreview {
+	manager.configLock.Lock()
+	defer manager.configLock.Unlock()
+
+	preview := &robot.ReconfigurePreview{}
+	if diff.ResourcesEqual {
+		return preview
+	}
+
+	for _, conf := range append(append([]resource.Config{}, diff.Added.Components...), diff.Added.Services...) {
+		preview.Added = append(preview.Added, conf.ResourceName())
+	}
+	for _, remote := range diff.Added.Remotes {
+		preview.Added = append(preview.Added, fromRemoteNameToRemoteNodeName(remote.Name))
+	}
+
+	// removing a resource removes those depending on it with it
+	var toRemove []resource.Name
+	for _, conf := range append(append([]resource.Config{}, diff.Removed.Components...), diff.Removed.Services...) {
+		toRemove = append(toRemove, conf.ResourceName())
+	}
+	for _, remote := range diff.Removed.Remotes {
+		toRemove = append(toRemove, fromRemoteNameToRemoteNodeName(remote.Name))
+	}
+	for _, mod := range diff.Removed.Modules {
+		toRemove = append(toRemove, manager.moduleResourceNames(mod.Name)...)
+	}
+	removed := map[resource.Name]bool{}
+	for _, name := range toRemove {
+		if _, ok := manager.resources.Node(name); !ok {
+			continue
+		}
+		subGraph, err := manager.resources.SubGraphFrom(name)
+		if err != nil {
+			continue
+		}
+		for _, removedName := range subGraph.Names() {
+			removed[removedName] = true
+		}
+	}
+	for name := range removed {
+		preview.Removed = append(preview.Removed, name)
+	}
+
+	changed := map[resource.Name]bool{}
+	for _, conf := range append(append([]resource.Config{}, diff.Modified.Components...), diff.Modified.Services...) {
+		name := conf.ResourceName()
+		gNode, ok := manager.resources.Node(name)
+		switch {
+		case removed[name]:
+			continue
+		case !ok:
+			preview.Added = append(preview.Added, name)
+			continue
+		case gNode.IsUninitialized() || gNode.ResourceModel() != conf.Model || alwaysRebuilds(gNode):
+			preview.Rebuilt = append(preview.Rebuilt, name)
+		default:
+			preview.Reconfigured = append(preview.Reconfigured, name)
+		}
+		changed[name] = true
+	}
+	for _, remote := range diff.Modified.Remotes {
+		name := fromRemoteNameToRemoteNodeName(remote.Name)
+		preview.Rebuilt = append(preview.Rebuilt, name)
+		changed[name] = true
+	}
+	// restarting a module rebuilds its resources
+	for _, mod := range diff.Modified.Modules {
+		for _, name := range manager.moduleResourceNames(mod.Name) {
+			if !removed[name] && !changed[name] {
+				preview.Rebuilt = append(preview.Rebuilt, name)
+				changed[name] = true
+			}
+		}
+	}
+
+	dependents := map[resource.Name]bool{}
+	for name := range changed {
+		if _, ok := manager.resources.Node(name); !ok {
+			continue
+		}
+		subGraph, err := manager.resources.SubGraphFrom(name)
+		if err != nil {
+			continue
+		}
+		for _, dependent := range subGraph.Names() {
+			if !changed[dependent] && !removed[dependent] && !dependent.ContainsRemoteNames() {
+				dependents[dependent] = true
+			}
+		}
+	}
+	for name := range dependents {
+		preview.Dependents = append(preview.Dependents, name)
+	}
+
+	for _, mod := range diff.Added.Modules {
+		preview.ModulesAdded = append(preview.ModulesAdded, mod.Name)
+	}
+	for _, mod := range diff.Removed.Modules {
+		preview.ModulesRemoved = append(preview.ModulesRemoved, mod.Name)
+	}
+	for _, mod := range diff.Modified.Modules {
+		preview.ModulesRestarted = append(preview.ModulesRestarted, mod.Name)
+	}
+	for _, proc := range diff.Added.Processes {
+		preview.ProcessesAdded = append(preview.ProcessesAdded, proc.ID)
+	}
+	for _, proc := range diff.Removed.Processes {
+		preview.ProcessesRemoved = append(preview.ProcessesRemoved, proc.ID)
+	}
+	for _, proc := range diff.Modified.Processes {
+		preview.ProcessesRestarted = append(preview.ProcessesRestarted, proc.ID)
+	}
+
+	for _, names := range [][]resource.Name{
+		preview.Added, preview.Removed, preview.Rebuilt, preview.Reconfigured, preview.Dependents,
+	} {
+		sort.Slice(names, func(i, j int) bool { return names[i].String() < names[j].String() })
+	}
+	for _, names := range [][]string{
+		preview.ModulesAdded, preview.ModulesRemoved, preview.ModulesRestarted,
+		preview.ProcessesAdded, preview.ProcessesRemoved, preview.ProcessesRestarted,
+	} {
+		sort.Strings(names)
+	}
+	return preview
+}
+
+// alwaysRebuilds returns whether the resource of a node is rebuilt rather than reconfigured when its config changes.
+func alwaysRebuilds(gNode *resource.GraphNode) bool {
+	res, err := gNode.Resource()
+	return err == nil && resource.AlwaysRebuilds(res)
+}
+
+// moduleResourceNames returns the names of the resources in the graph of the models the named module provides.
+func (manager *resourceManager) moduleResourceNames(modName string) []resource.Name {
+	if manager.moduleManager == nil {
+		return nil
+	}
+	handlers, ok := manager.moduleManager.Handles()[modName]
+	if !ok {
+		return nil
+	}
+	var names []resource.Name
+	for _, name := range manager.resources.Names() {
+		gNode, ok := manager.resources.Node(name)
+		if !ok {
+			continue
+		}
+		for api, models := range handlers {
+			if api.API != name.API {
+				continue
+			}
+			for _, model := range models {
+				if model == gNode.ResourceModel() {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	return names
+}
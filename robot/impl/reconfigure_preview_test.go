@@ -0,0 +1,109 @@
+package robotimpl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/sensor"
+	_ "go.viam.com/rdk/components/sensor/fake"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+// tunableSensor is reconfigured in place when its config changes.
+type tunableSensor struct {
+	resource.Named
+	resource.TriviallyReconfigurable
+	resource.TriviallyCloseable
+}
+
+func (s *tunableSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return map[string]interface{}{}, nil
+}
+
+func TestPreviewReconfigure(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	realModel := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(8))
+	resource.RegisterComponent(sensor.API, realModel, resource.Registration[sensor.Sensor, resource.NoNativeConfig]{
+		Constructor: func(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (sensor.Sensor, error) {
+			return &realSensor{Named: conf.ResourceName().AsNamed()}, nil
+		},
+	})
+	tunableModel := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(8))
+	resource.RegisterComponent(sensor.API, tunableModel, resource.Registration[sensor.Sensor, resource.NoNativeConfig]{
+		Constructor: func(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (sensor.Sensor, error) {
+			return &tunableSensor{Named: conf.ResourceName().AsNamed()}, nil
+		},
+	})
+	defer func() {
+		resource.Deregister(sensor.API, realModel)
+		resource.Deregister(sensor.API, tunableModel)
+	}()
+
+	cfg, err := config.FromReader(ctx, "", strings.NewReader(fmt.Sprintf(`{
+    "components": [
+        {"name": "base_sensor", "type": "sensor", "model": "%s"},
+        {"name": "fake_sensor", "type": "sensor", "model": "fake"},
+        {"name": "middle", "type": "sensor", "model": "fake", "depends_on": ["base_sensor"]},
+        {"name": "top", "type": "sensor", "model": "fake", "depends_on": ["middle"]},
+        {"name": "swapped", "type": "sensor", "model": "fake"},
+        {"name": "old", "type": "sensor", "model": "fake"},
+        {"name": "old_dependent", "type": "sensor", "model": "fake", "depends_on": ["old"]}
+    ]
+}`, tunableModel)), logger)
+	test.That(t, err, test.ShouldBeNil)
+	r := setupLocalRobot(t, ctx, cfg, logger)
+
+	preview, err := r.PreviewReconfigure(ctx, cfg)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, preview.Empty(), test.ShouldBeTrue)
+
+	newCfg, err := config.FromReader(ctx, "", strings.NewReader(fmt.Sprintf(`{
+    "components": [
+        {"name": "base_sensor", "type": "sensor", "model": "%s", "attributes": {"gain": 2}},
+        {"name": "fake_sensor", "type": "sensor", "model": "fake", "attributes": {"seed": 5}},
+        {"name": "middle", "type": "sensor", "model": "fake", "depends_on": ["base_sensor"]},
+        {"name": "top", "type": "sensor", "model": "fake", "depends_on": ["middle"]},
+        {"name": "swapped", "type": "sensor", "model": "%s"},
+        {"name": "new", "type": "sensor", "model": "fake"}
+    ]
+}`, tunableModel, realModel)), logger)
+	test.That(t, err, test.ShouldBeNil)
+	preview, err = r.PreviewReconfigure(ctx, newCfg)
+	test.That(t, err, test.ShouldBeNil)
+	// the fake sensor always rebuilds, so it is rebuilt rather than reconfigured
+	test.That(t, preview, test.ShouldResemble, &robot.ReconfigurePreview{
+		Added:        []resource.Name{sensor.Named("new")},
+		Removed:      []resource.Name{sensor.Named("old"), sensor.Named("old_dependent")},
+		Rebuilt:      []resource.Name{sensor.Named("fake_sensor"), sensor.Named("swapped")},
+		Reconfigured: []resource.Name{sensor.Named("base_sensor")},
+		Dependents:   []resource.Name{sensor.Named("middle"), sensor.Named("top")},
+	})
+	test.That(t, preview.Empty(), test.ShouldBeFalse)
+
+	// the preview leaves the robot as it is
+	test.That(t, r.Config().Components, test.ShouldHaveLength, 7)
+	_, err = r.ResourceByName(sensor.Named("old"))
+	test.That(t, err, test.ShouldBeNil)
+	_, err = r.ResourceByName(sensor.Named("new"))
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// applying the config changes what the preview said it would
+	r.Reconfigure(ctx, newCfg)
+	_, err = r.ResourceByName(sensor.Named("old_dependent"))
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = r.ResourceByName(sensor.Named("new"))
+	test.That(t, err, test.ShouldBeNil)
+	preview, err = r.PreviewReconfigure(ctx, newCfg)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, preview.Empty(), test.ShouldBeTrue)
+}
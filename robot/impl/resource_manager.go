@@ -184,10 +184,15 @@ func (manager *resourceManager) updateRemoteResourceNames(
 	for _, res := range oldResources {
 		activeResourceNames[res] = false
 	}
+	constrained := manager.constrainedPeerConfig(remoteName)
 
 	anythingChanged := false
 
 	for _, resName := range newResources {
+		if constrained != nil && !constrained.AllowsAPI(resName.API) {
+			// constrained peers may serve APIs this robot is configured not to use
+			continue
+		}
 		remoteResName := resName
 		res, err := rr.ResourceByName(remoteResName) // this returns a remote known OR foreign resource client
 		if err != nil {
@@ -268,6 +273,19 @@ func (manager *resourceManager) updateRemoteResourceNames(
 	return anythingChanged
 }
 
+// constrainedPeerConfig returns the constrained peer config of the named remote node, or nil if it is not one.
+func (manager *resourceManager) constrainedPeerConfig(remoteName resource.Name) *config.ConstrainedPeer {
+	gNode, ok := manager.resources.Node(remoteName)
+	if !ok {
+		return nil
+	}
+	remConf, err := resource.NativeConfig[*config.Remote](gNode.Config())
+	if err != nil {
+		return nil
+	}
+	return remConf.Constrained
+}
+
 func (manager *resourceManager) updateRemotesResourceNames(ctx context.Context) bool {
 	anythingChanged := false
 	for _, name := range manager.resources.Names() {
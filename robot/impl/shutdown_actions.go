@@ -0,0 +1,72 @@
+package robotimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+)
+
+// defaultShutdownActionTimeout is how long a shutdown action takes at most if its config does not say.
+var defaultShutdownActionTimeout = 10 * time.Second
+
+// runShutdownActions parks the actuators with the shutdown actions of the most recent config, in order. An action
+// which fails or times out is logged and the rest are still taken, since the robot is shutting down regardless.
+func (r *localRobot) runShutdownActions(ctx context.Context) {
+	r.shutdownActionsMu.Lock()
+	actions := r.shutdownActions
+	r.shutdownActions = nil
+	r.shutdownActionsMu.Unlock()
+	if len(actions) == 0 || r.manager == nil {
+		return
+	}
+
+	r.logger.CInfow(ctx, "parking actuators before shutting down", "actions", len(actions))
+	for _, action := range actions {
+		if err := action.Validate(""); err != nil {
+			// the config error was already logged
+			continue
+		}
+		if err := r.runShutdownAction(ctx, action); err != nil {
+			r.logger.CErrorw(ctx, "shutdown action failed", "action", action.String(), "error", err)
+			continue
+		}
+		r.logger.CInfow(ctx, "shutdown action done", "action", action.String())
+	}
+}
+
+// runShutdownAction takes a shutdown action once, within its timeout.
+func (r *localRobot) runShutdownAction(ctx context.Context, action config.ShutdownAction) error {
+	timeout := defaultShutdownActionTimeout
+	if action.TimeoutSec > 0 {
+		timeout = time.Duration(action.TimeoutSec * float64(time.Second))
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if action.Method == config.ShutdownActionStop {
+		res, err := r.resourceByShortName(action.Resource)
+		if err != nil {
+			return err
+		}
+		actuator, ok := res.(resource.Actuator)
+		if !ok {
+			return errors.Errorf("resource %q is not an actuator, so cannot be stopped", action.Resource)
+		}
+		return actuator.Stop(ctx, nil)
+	}
+	// the other methods are taken just as startup actions are
+	act, err := r.startupActionFunc(config.StartupAction{
+		Resource:           action.Resource,
+		Method:             action.Method,
+		JointPositionsDegs: action.JointPositionsDegs,
+		Command:            action.Command,
+	})
+	if err != nil {
+		return err
+	}
+	return act(ctx)
+}
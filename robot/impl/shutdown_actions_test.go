@@ -0,0 +1,76 @@
+package robotimpl
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/arm"
+	fakearm "go.viam.com/rdk/components/arm/fake"
+	"go.viam.com/rdk/components/board"
+	fakeboard "go.viam.com/rdk/components/board/fake"
+	"go.viam.com/rdk/components/motor"
+	fakemotor "go.viam.com/rdk/components/motor/fake"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+func TestShutdownActions(t *testing.T) {
+	ctx := context.Background()
+	logger, logs := logging.NewObservedTestLogger(t)
+	cfg := &config.Config{
+		Components: []resource.Config{
+			{
+				Name:  "arm1",
+				API:   arm.API,
+				Model: fakeModel,
+				ConvertedAttributes: &fakearm.Config{
+					ModelFilePath: "../../components/arm/fake/fake_model.json",
+				},
+			},
+			{
+				Name:                "motor1",
+				API:                 motor.API,
+				Model:               fakeModel,
+				ConvertedAttributes: &fakemotor.Config{},
+			},
+			{
+				Name:                "board1",
+				API:                 board.API,
+				Model:               fakeModel,
+				ConvertedAttributes: &fakeboard.Config{},
+			},
+		},
+		ShutdownActions: []config.ShutdownAction{
+			// a board cannot be stopped, which does not keep the rest from being taken
+			{Resource: "board1", Method: config.ShutdownActionStop},
+			{Resource: "motor1", Method: config.ShutdownActionStop},
+			{Resource: "arm1", Method: config.ShutdownActionMoveToJointPositions, JointPositionsDegs: []float64{-20}},
+		},
+	}
+	r, err := New(ctx, cfg, logger, WithViamHomeDir(t.TempDir()))
+	test.That(t, err, test.ShouldBeNil)
+
+	m, err := motor.FromRobot(r, "motor1")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, m.SetPower(ctx, 0.5, nil), test.ShouldBeNil)
+	moving, err := m.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeTrue)
+	a, err := arm.FromRobot(r, "arm1")
+	test.That(t, err, test.ShouldBeNil)
+
+	test.That(t, r.Close(ctx), test.ShouldBeNil)
+	r.(*localRobot).reconfigureWorkers.Wait()
+
+	test.That(t, logs.FilterMessageSnippet("shutdown action failed").Len(), test.ShouldEqual, 1)
+	test.That(t, logs.FilterMessageSnippet("shutdown action done").Len(), test.ShouldEqual, 2)
+	moving, err = m.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeFalse)
+	joints, err := a.JointPositions(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, joints.Values, test.ShouldResemble, []float64{-20})
+}
@@ -0,0 +1,152 @@
+package robotimpl
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
+)
+
+var (
+	// simulatedModel is the model of the fake which stands in for a simulated component.
+	simulatedModel = resource.DefaultModelFamily.WithModel("fake")
+	// armAPI is not taken from the arm package, which is not built without cgo.
+	armAPI = resource.APINamespaceRDK.WithComponentType("arm")
+)
+
+// SetSimulated swaps a component for its fake counterpart, or back to its real driver. The component keeps its
+// name, so its dependents are only reconfigured with the fake in place of the driver.
+func (r *localRobot) SetSimulated(ctx context.Context, name resource.Name, simulated bool) error {
+	cfg := r.Config()
+	idx := -1
+	for i, conf := range cfg.Components {
+		if conf.ResourceName() == name {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return errors.Errorf("no component named %q to simulate", name)
+	}
+
+	r.simulatedMu.Lock()
+	realConf, isSimulated := r.simulated[name]
+	switch {
+	case simulated == isSimulated:
+		r.simulatedMu.Unlock()
+		return nil
+	case simulated:
+		// fail before touching the running driver if there is nothing to swap it for
+		if _, err := simulatedConfig(cfg.Components[idx]); err != nil {
+			r.simulatedMu.Unlock()
+			return err
+		}
+		if r.simulated == nil {
+			r.simulated = map[resource.Name]*resource.Config{}
+		}
+		r.simulated[name] = nil
+	default:
+		delete(r.simulated, name)
+		if realConf != nil {
+			cfg.Components[idx] = *realConf
+		}
+	}
+	r.simulatedMu.Unlock()
+
+	r.logger.CInfow(ctx, "switching component between simulated and real", "name", name, "simulated", simulated)
+	r.reconfigure(ctx, cfg, false)
+	return nil
+}
+
+// Simulated returns the names of the components which are swapped for their fakes.
+func (r *localRobot) Simulated() []resource.Name {
+	r.simulatedMu.Lock()
+	defer r.simulatedMu.Unlock()
+	names := make([]resource.Name, 0, len(r.simulated))
+	for name := range r.simulated {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i].String() < names[j].String()
+	})
+	return names
+}
+
+// applySimulation replaces the configs of simulated components with configs of their fakes, keeping the real
+// configs so the drivers can be brought back with any changes made to them in the meantime.
+func (r *localRobot) applySimulation(cfg *config.Config) error {
+	return r.simulate(cfg, true)
+}
+
+// simulate replaces the configs of simulated components with configs of their fakes, keeping the real configs if
+// keepReal is set.
+func (r *localRobot) simulate(cfg *config.Config, keepReal bool) error {
+	r.simulatedMu.Lock()
+	defer r.simulatedMu.Unlock()
+	var allErrs error
+	for i, conf := range cfg.Components {
+		if _, ok := r.simulated[conf.ResourceName()]; !ok || conf.Model == simulatedModel {
+			continue
+		}
+		fake, err := simulatedConfig(conf)
+		if err != nil {
+			allErrs = errors.Wrapf(err, "cannot simulate %s", conf.ResourceName())
+			continue
+		}
+		if keepReal {
+			realConf := conf
+			r.simulated[conf.ResourceName()] = &realConf
+		}
+		cfg.Components[i] = fake
+	}
+	return allErrs
+}
+
+// simulatedConfig returns the config of the fake which stands in for a component. Its frame and tags are kept and so,
+// for arms, is its kinematics when the fake arm knows the model.
+func simulatedConfig(conf resource.Config) (resource.Config, error) {
+	reg, ok := resource.LookupRegistration(conf.API, simulatedModel)
+	if !ok {
+		return resource.Config{}, errors.Errorf("%s has no fake model to simulate it with", conf.API)
+	}
+	attrs := utils.AttributeMap{}
+	if conf.API == armAPI {
+		attrs["arm-model"] = conf.Model.Name
+	}
+
+	fake := resource.Config{
+		Name:             conf.Name,
+		API:              conf.API,
+		Model:            simulatedModel,
+		Frame:            conf.Frame,
+		LogConfiguration: conf.LogConfiguration,
+		Attributes:       attrs,
+		Tags:             conf.Tags,
+	}
+	if reg.AttributeMapConverter == nil {
+		return fake, nil
+	}
+	converted, err := reg.AttributeMapConverter(attrs)
+	if err == nil {
+		_, err = converted.Validate("")
+	}
+	if err != nil && len(attrs) != 0 {
+		// the fake cannot mimic this model, so it falls back to its defaults
+		fake.Attributes = utils.AttributeMap{}
+		converted, err = reg.AttributeMapConverter(fake.Attributes)
+	}
+	if err != nil {
+		return resource.Config{}, err
+	}
+	deps, err := converted.Validate("")
+	if err != nil {
+		return resource.Config{}, err
+	}
+	fake.ConvertedAttributes = converted
+	fake.ImplicitDependsOn = deps
+	return fake, nil
+}
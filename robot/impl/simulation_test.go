@@ -0,0 +1,167 @@
+package robotimpl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.viam.com/test"
+	"go.viam.com/utils"
+	"go.viam.com/utils/rpc"
+
+	"go.viam.com/rdk/components/sensor"
+	_ "go.viam.com/rdk/components/sensor/fake"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/robottestutils"
+	rutils "go.viam.com/rdk/utils"
+)
+
+// dependentSensor reports the readings of the sensor it depends on.
+type dependentSensor struct {
+	resource.Named
+	resource.TriviallyCloseable
+	source sensor.Sensor
+}
+
+func (s *dependentSensor) Reconfigure(ctx context.Context, deps resource.Dependencies, conf resource.Config) error {
+	source, err := sensor.FromDependencies(deps, "source")
+	if err != nil {
+		return err
+	}
+	s.source = source
+	return nil
+}
+
+func (s *dependentSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return s.source.Readings(ctx, extra)
+}
+
+type realSensor struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+}
+
+func (s *realSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return map[string]interface{}{"real": true}, nil
+}
+
+func TestSetSimulated(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	realModel := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(8))
+	dependentModel := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(8))
+	resource.RegisterComponent(sensor.API, realModel, resource.Registration[sensor.Sensor, resource.NoNativeConfig]{
+		Constructor: func(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (sensor.Sensor, error) {
+			return &realSensor{Named: conf.ResourceName().AsNamed()}, nil
+		},
+	})
+	resource.RegisterComponent(sensor.API, dependentModel, resource.Registration[sensor.Sensor, resource.NoNativeConfig]{
+		Constructor: func(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (sensor.Sensor, error) {
+			s := &dependentSensor{Named: conf.ResourceName().AsNamed()}
+			if err := s.Reconfigure(ctx, deps, conf); err != nil {
+				return nil, err
+			}
+			return s, nil
+		},
+	})
+	defer func() {
+		resource.Deregister(sensor.API, realModel)
+		resource.Deregister(sensor.API, dependentModel)
+	}()
+
+	cfg, err := config.FromReader(ctx, "", strings.NewReader(fmt.Sprintf(`{
+    "components": [
+        {"name": "source", "type": "sensor", "model": "%s", "tags": ["telemetry"]},
+        {"name": "dependent", "type": "sensor", "model": "%s", "depends_on": ["source"]}
+    ]
+}`, realModel, dependentModel)), logger)
+	test.That(t, err, test.ShouldBeNil)
+	r := setupLocalRobot(t, ctx, cfg, logger)
+
+	readings := func(name string) map[string]interface{} {
+		s, err := sensor.FromRobot(r, name)
+		test.That(t, err, test.ShouldBeNil)
+		readings, err := s.Readings(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		return readings
+	}
+	test.That(t, readings("dependent"), test.ShouldResemble, map[string]interface{}{"real": true})
+
+	test.That(t, r.SetSimulated(ctx, sensor.Named("source"), true), test.ShouldBeNil)
+	test.That(t, r.Simulated(), test.ShouldResemble, []resource.Name{sensor.Named("source")})
+	test.That(t, readings("source"), test.ShouldContainKey, "a")
+	test.That(t, readings("dependent"), test.ShouldContainKey, "a")
+	for _, conf := range r.Config().Components {
+		if conf.Name == "source" {
+			test.That(t, conf.Model, test.ShouldResemble, simulatedModel)
+			test.That(t, conf.Tags, test.ShouldResemble, []string{"telemetry"})
+		}
+	}
+
+	// new configs keep the fake in place
+	r.Reconfigure(ctx, cfg)
+	test.That(t, readings("dependent"), test.ShouldContainKey, "a")
+
+	test.That(t, r.SetSimulated(ctx, sensor.Named("source"), false), test.ShouldBeNil)
+	test.That(t, r.Simulated(), test.ShouldBeEmpty)
+	test.That(t, readings("source"), test.ShouldResemble, map[string]interface{}{"real": true})
+	test.That(t, readings("dependent"), test.ShouldResemble, map[string]interface{}{"real": true})
+
+	err = r.SetSimulated(ctx, sensor.Named("missing"), true)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no component named")
+}
+
+func TestSimulatedRoute(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	cfg, err := config.FromReader(ctx, "", strings.NewReader(`{
+    "components": [{"name": "source", "type": "sensor", "model": "fake"}]
+}`), logger)
+	test.That(t, err, test.ShouldBeNil)
+	r := setupLocalRobot(t, ctx, cfg, logger)
+
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	options.Network.EnableDebugControl = true
+	apiKeyID := uuid.New().String()
+	apiKey := utils.RandomAlphaString(32)
+	options.Auth.Handlers = []config.AuthHandlerConfig{
+		{
+			Type:   rpc.CredentialsTypeAPIKey,
+			Config: rutils.AttributeMap{apiKeyID: apiKey, "keys": []string{apiKeyID}},
+		},
+	}
+	test.That(t, r.StartWeb(ctx, options), test.ShouldBeNil)
+
+	post := func(contentType, body string, withKey bool) int {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+addr+"/debug/simulated", strings.NewReader(body))
+		test.That(t, err, test.ShouldBeNil)
+		req.Header.Set("Content-Type", contentType)
+		if withKey {
+			req.SetBasicAuth(apiKeyID, apiKey)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp.Body.Close(), test.ShouldBeNil)
+		return resp.StatusCode
+	}
+
+	body := `{"resource": "rdk:component:sensor/source", "enabled": true}`
+	test.That(t, post("application/json", body, false), test.ShouldEqual, http.StatusUnauthorized)
+	test.That(t, r.Simulated(), test.ShouldBeEmpty)
+	// a form, which other sites can post, is refused
+	test.That(t, post("application/x-www-form-urlencoded", body, true), test.ShouldEqual, http.StatusBadRequest)
+	test.That(t, r.Simulated(), test.ShouldBeEmpty)
+
+	test.That(t, post("application/json", body, true), test.ShouldEqual, http.StatusOK)
+	test.That(t, r.Simulated(), test.ShouldResemble, []resource.Name{sensor.Named("source")})
+}
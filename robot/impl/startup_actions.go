@@ -0,0 +1,170 @@
+package robotimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	pb "go.viam.com/api/component/arm/v1"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/gantry"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/web"
+)
+
+var (
+	// startupActionWait is how long a startup action waits for its resource to be ready.
+	startupActionWait = time.Minute
+	// startupActionRetryInterval is how long a startup action waits to be tried again, and between checks of whether
+	// its resource is ready.
+	startupActionRetryInterval = time.Second
+)
+
+// runStartupActions takes the startup actions in order, each as an operation, until one fails with the stop policy.
+func (r *localRobot) runStartupActions(ctx context.Context, actions []config.StartupAction) {
+	for idx, action := range actions {
+		if err := action.Validate(""); err != nil {
+			// the config error was already logged
+			continue
+		}
+		r.logger.CInfow(ctx, "taking startup action", "action", action.String(), "index", idx)
+		err := r.runStartupAction(ctx, action)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			r.logger.CInfow(ctx, "startup action done", "action", action.String())
+			continue
+		}
+		r.logger.CErrorw(ctx, "startup action failed", "action", action.String(), "error", err)
+		r.webSvc.PublishEvent(web.NewAlert(nil, "error", "startup action "+action.String()+" failed: "+err.Error()))
+		if action.OnFailure == config.StartupActionStop {
+			r.logger.CWarnw(ctx, "skipping the remaining startup actions", "skipped", len(actions)-idx-1)
+			return
+		}
+	}
+}
+
+// runStartupAction tries a startup action until it succeeds or runs out of retries.
+func (r *localRobot) runStartupAction(ctx context.Context, action config.StartupAction) error {
+	ctx, done := r.operations.Create(ctx, "startup_action/"+action.String(), nil)
+	defer done()
+	var err error
+	for try := 0; try <= action.Retries; try++ {
+		if try > 0 {
+			r.logger.CDebugw(ctx, "retrying startup action", "action", action.String(), "try", try+1, "error", err)
+			if !goutils.SelectContextOrWait(ctx, startupActionRetryInterval) {
+				return ctx.Err()
+			}
+		}
+		if err = r.tryStartupAction(ctx, action); err == nil || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// tryStartupAction waits for the resource of a startup action to be ready and takes the action once.
+func (r *localRobot) tryStartupAction(ctx context.Context, action config.StartupAction) error {
+	act, err := r.waitForStartupAction(ctx, action)
+	if err != nil {
+		return err
+	}
+	if action.TimeoutSec > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(action.TimeoutSec*float64(time.Second)))
+		defer cancel()
+	}
+	return act(ctx)
+}
+
+// waitForStartupAction returns the startup action as a function of its resource once the resource is ready.
+func (r *localRobot) waitForStartupAction(
+	ctx context.Context, action config.StartupAction,
+) (func(ctx context.Context) error, error) {
+	deadline := time.Now().Add(startupActionWait)
+	for {
+		act, err := r.startupActionFunc(action)
+		if err == nil {
+			return act, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.Wrapf(err, "resource %q was not ready within %v", action.Resource, startupActionWait)
+		}
+		if !goutils.SelectContextOrWait(ctx, startupActionRetryInterval) {
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// startupActionFunc returns the startup action as a function of its resource, or an error if the resource is not
+// ready.
+func (r *localRobot) startupActionFunc(action config.StartupAction) (func(ctx context.Context) error, error) {
+	switch action.Method {
+	case config.StartupActionHome:
+		g, err := gantry.FromRobot(r, action.Resource)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context) error {
+			homed, err := g.Home(ctx, nil)
+			if err == nil && !homed {
+				err = errors.New("gantry did not home")
+			}
+			return err
+		}, nil
+	case config.StartupActionMoveToJointPositions:
+		a, err := arm.FromRobot(r, action.Resource)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context) error {
+			return a.MoveToJointPositions(ctx, &pb.JointPositions{Values: action.JointPositionsDegs}, nil)
+		}, nil
+	case config.StartupActionSetGPIO:
+		b, err := board.FromRobot(r, action.Resource)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context) error {
+			pin, err := b.GPIOPinByName(action.Pin)
+			if err != nil {
+				return err
+			}
+			return pin.Set(ctx, action.High, nil)
+		}, nil
+	case config.StartupActionDoCommand:
+		res, err := r.resourceByShortName(action.Resource)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context) error {
+			_, err := res.DoCommand(ctx, action.Command)
+			return err
+		}, nil
+	default:
+		return nil, errors.Errorf("unknown startup action method %q", action.Method)
+	}
+}
+
+// resourceByShortName returns the one resource of any API with the name.
+func (r *localRobot) resourceByShortName(name string) (resource.Resource, error) {
+	var found []resource.Name
+	for _, n := range r.ResourceNames() {
+		if n.ShortName() == name {
+			found = append(found, n)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, errors.Errorf("no resource named %q", name)
+	case 1:
+		return r.ResourceByName(found[0])
+	default:
+		return nil, errors.Errorf("more than one resource is named %q: %v", name, found)
+	}
+}
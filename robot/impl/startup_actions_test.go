@@ -0,0 +1,90 @@
+package robotimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/arm"
+	fakearm "go.viam.com/rdk/components/arm/fake"
+	"go.viam.com/rdk/components/board"
+	fakeboard "go.viam.com/rdk/components/board/fake"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+func TestStartupActions(t *testing.T) {
+	origWait, origInterval := startupActionWait, startupActionRetryInterval
+	startupActionWait, startupActionRetryInterval = 100*time.Millisecond, 10*time.Millisecond
+	defer func() {
+		startupActionWait, startupActionRetryInterval = origWait, origInterval
+	}()
+
+	ctx := context.Background()
+	logger, logs := logging.NewObservedTestLogger(t)
+	cfg := &config.Config{
+		Components: []resource.Config{
+			{
+				Name:  "arm1",
+				API:   arm.API,
+				Model: fakeModel,
+				ConvertedAttributes: &fakearm.Config{
+					ModelFilePath: "../../components/arm/fake/fake_model.json",
+				},
+			},
+			{
+				Name:                "board1",
+				API:                 board.API,
+				Model:               fakeModel,
+				ConvertedAttributes: &fakeboard.Config{},
+			},
+		},
+		StartupActions: []config.StartupAction{
+			{Resource: "board1", Method: config.StartupActionSetGPIO, Pin: "37", High: true},
+			{Resource: "arm1", Method: config.StartupActionMoveToJointPositions, JointPositionsDegs: []float64{10}},
+			// a resource which is not ready in time fails the action, and continues by default
+			{Resource: "missing", Method: config.StartupActionDoCommand, Command: map[string]interface{}{"a": 1}},
+			{
+				Name:      "stop here",
+				Resource:  "arm1",
+				Method:    config.StartupActionDoCommand,
+				Command:   map[string]interface{}{"a": 1},
+				Retries:   1,
+				OnFailure: config.StartupActionStop,
+			},
+			{Resource: "board1", Method: config.StartupActionSetGPIO, Pin: "38", High: true},
+		},
+	}
+	r := setupLocalRobot(t, ctx, cfg, logger)
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, logs.FilterMessageSnippet("skipping the remaining startup actions").Len(), test.ShouldEqual, 1)
+	})
+	test.That(t, logs.FilterMessageSnippet("startup action done").Len(), test.ShouldEqual, 2)
+	test.That(t, logs.FilterMessageSnippet("startup action failed").Len(), test.ShouldEqual, 2)
+	test.That(t, logs.FilterMessageSnippet("retrying startup action").Len(), test.ShouldEqual, 1)
+
+	b, err := board.FromRobot(r, "board1")
+	test.That(t, err, test.ShouldBeNil)
+	pin, err := b.GPIOPinByName("37")
+	test.That(t, err, test.ShouldBeNil)
+	high, err := pin.Get(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, high, test.ShouldBeTrue)
+	pin, err = b.GPIOPinByName("38")
+	test.That(t, err, test.ShouldBeNil)
+	high, err = pin.Get(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, high, test.ShouldBeFalse)
+
+	a, err := arm.FromRobot(r, "arm1")
+	test.That(t, err, test.ShouldBeNil)
+	joints, err := a.JointPositions(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, joints.Values, test.ShouldResemble, []float64{10})
+}
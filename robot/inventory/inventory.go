@@ -0,0 +1,255 @@
+// Package inventory stores asset metadata about a machine and its resources, such as serial numbers, firmware
+// versions and maintenance history, for fleet asset management. Metadata is persisted locally so that it outlives
+// restarts and config changes, and is reported in the machine's diagnostics.
+package inventory
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/resource"
+)
+
+// MaintenanceNote records maintenance performed on a machine or resource.
+type MaintenanceNote struct {
+	Time   time.Time `json:"time"`
+	Note   string    `json:"note"`
+	Author string    `json:"author,omitempty"`
+}
+
+// Metadata describes a machine or one of its resources as a physical asset.
+type Metadata struct {
+	SerialNumber     string            `json:"serial_number,omitempty"`
+	Manufacturer     string            `json:"manufacturer,omitempty"`
+	Model            string            `json:"model,omitempty"`
+	FirmwareVersion  string            `json:"firmware_version,omitempty"`
+	InstallDate      *time.Time        `json:"install_date,omitempty"`
+	MaintenanceNotes []MaintenanceNote `json:"maintenance_notes,omitempty"`
+	// Attributes holds any other metadata, such as an asset tag or location.
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+func (md Metadata) clone() Metadata {
+	if md.InstallDate != nil {
+		installDate := *md.InstallDate
+		md.InstallDate = &installDate
+	}
+	md.MaintenanceNotes = slices.Clone(md.MaintenanceNotes)
+	md.Attributes = maps.Clone(md.Attributes)
+	return md
+}
+
+// Query selects metadata by its fields. Empty fields match anything, and every attribute must be present with the
+// given value.
+type Query struct {
+	SerialNumber    string
+	Manufacturer    string
+	Model           string
+	FirmwareVersion string
+	Attributes      map[string]string
+}
+
+// Matches returns whether md satisfies the query.
+func (q Query) Matches(md Metadata) bool {
+	for _, field := range [][2]string{
+		{q.SerialNumber, md.SerialNumber},
+		{q.Manufacturer, md.Manufacturer},
+		{q.Model, md.Model},
+		{q.FirmwareVersion, md.FirmwareVersion},
+	} {
+		if field[0] != "" && field[0] != field[1] {
+			return false
+		}
+	}
+	for key, val := range q.Attributes {
+		if actual, ok := md.Attributes[key]; !ok || actual != val {
+			return false
+		}
+	}
+	return true
+}
+
+// Snapshot is the full contents of a Store, with resources keyed by their full names.
+type Snapshot struct {
+	Machine   Metadata            `json:"machine"`
+	Resources map[string]Metadata `json:"resources,omitempty"`
+}
+
+// Store holds the metadata of a machine and its resources. A Store with a path persists every change to it.
+type Store struct {
+	mu        sync.Mutex
+	path      string
+	machine   Metadata
+	resources map[resource.Name]Metadata
+}
+
+// NewStore returns a Store persisted to path, loading any metadata previously saved there. An empty path returns a
+// Store which is only held in memory.
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, resources: map[resource.Name]Metadata{}}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read inventory")
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse inventory %s", path)
+	}
+	s.machine = snapshot.Machine
+	for nameStr, md := range snapshot.Resources {
+		name, err := resource.NewFromString(nameStr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid resource name in inventory %s", path)
+		}
+		s.resources[name] = md
+	}
+	return s, nil
+}
+
+// Machine returns the metadata of the machine itself.
+func (s *Store) Machine() Metadata {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.machine.clone()
+}
+
+// SetMachine replaces the metadata of the machine itself.
+func (s *Store) SetMachine(md Metadata) error {
+	return s.UpdateMachine(func(current *Metadata) { *current = md })
+}
+
+// UpdateMachine modifies the metadata of the machine itself with update.
+func (s *Store) UpdateMachine(update func(md *Metadata)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev := s.machine
+	md := s.machine.clone()
+	update(&md)
+	s.machine = md.clone()
+	if err := s.save(); err != nil {
+		s.machine = prev
+		return err
+	}
+	return nil
+}
+
+// Resource returns the metadata of the named resource, and whether there is any.
+func (s *Store) Resource(name resource.Name) (Metadata, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	md, ok := s.resources[name]
+	return md.clone(), ok
+}
+
+// SetResource replaces the metadata of the named resource.
+func (s *Store) SetResource(name resource.Name, md Metadata) error {
+	return s.UpdateResource(name, func(current *Metadata) { *current = md })
+}
+
+// UpdateResource modifies the metadata of the named resource with update, starting from empty metadata if it has
+// none.
+func (s *Store) UpdateResource(name resource.Name, update func(md *Metadata)) error {
+	if err := name.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, existed := s.resources[name]
+	md := prev.clone()
+	update(&md)
+	s.resources[name] = md.clone()
+	if err := s.save(); err != nil {
+		if existed {
+			s.resources[name] = prev
+		} else {
+			delete(s.resources, name)
+		}
+		return err
+	}
+	return nil
+}
+
+// RemoveResource removes any metadata of the named resource.
+func (s *Store) RemoveResource(name resource.Name) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, existed := s.resources[name]
+	if !existed {
+		return nil
+	}
+	delete(s.resources, name)
+	if err := s.save(); err != nil {
+		s.resources[name] = prev
+		return err
+	}
+	return nil
+}
+
+// Query returns the metadata of every resource which matches q.
+func (s *Store) Query(q Query) map[resource.Name]Metadata {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	matches := map[resource.Name]Metadata{}
+	for name, md := range s.resources {
+		if q.Matches(md) {
+			matches[name] = md.clone()
+		}
+	}
+	return matches
+}
+
+// Snapshot returns a copy of all metadata in the store.
+func (s *Store) Snapshot() Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshot()
+}
+
+func (s *Store) snapshot() Snapshot {
+	snapshot := Snapshot{Machine: s.machine.clone()}
+	if len(s.resources) != 0 {
+		snapshot.Resources = make(map[string]Metadata, len(s.resources))
+		for name, md := range s.resources {
+			snapshot.Resources[name.String()] = md.clone()
+		}
+	}
+	return snapshot
+}
+
+// save writes the store to its path, replacing the previous file atomically so that a crash cannot corrupt it.
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.snapshot(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return errors.Wrap(err, "failed to create inventory directory")
+	}
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return errors.Wrap(err, "failed to save inventory")
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		utils.UncheckedError(os.Remove(tmpPath))
+		return errors.Wrap(err, "failed to save inventory")
+	}
+	return nil
+}
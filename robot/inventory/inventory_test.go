@@ -0,0 +1,87 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inventory", "inventory.json")
+	store, err := NewStore(path)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, store.Machine(), test.ShouldResemble, Metadata{})
+
+	installDate := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	arm := resource.NewName(resource.APINamespaceRDK.WithComponentType("arm"), "arm1")
+	gripper := resource.NewName(resource.APINamespaceRDK.WithComponentType("gripper"), "gripper1")
+
+	err = store.SetMachine(Metadata{SerialNumber: "M-1", InstallDate: &installDate})
+	test.That(t, err, test.ShouldBeNil)
+	err = store.SetResource(arm, Metadata{
+		SerialNumber:    "A-1",
+		Manufacturer:    "acme",
+		FirmwareVersion: "1.2.0",
+		Attributes:      map[string]string{"cell": "3"},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	err = store.SetResource(gripper, Metadata{SerialNumber: "G-1", Manufacturer: "acme"})
+	test.That(t, err, test.ShouldBeNil)
+
+	note := MaintenanceNote{Time: installDate.Add(time.Hour), Note: "replaced joint 3 encoder", Author: "tech"}
+	err = store.UpdateResource(arm, func(md *Metadata) {
+		md.MaintenanceNotes = append(md.MaintenanceNotes, note)
+	})
+	test.That(t, err, test.ShouldBeNil)
+
+	md, ok := store.Resource(arm)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, md.MaintenanceNotes, test.ShouldResemble, []MaintenanceNote{note})
+
+	// returned metadata is a copy
+	md.Attributes["cell"] = "4"
+	md, _ = store.Resource(arm)
+	test.That(t, md.Attributes["cell"], test.ShouldEqual, "3")
+
+	test.That(t, store.Query(Query{Manufacturer: "acme"}), test.ShouldHaveLength, 2)
+	matches := store.Query(Query{Manufacturer: "acme", Attributes: map[string]string{"cell": "3"}})
+	test.That(t, matches, test.ShouldHaveLength, 1)
+	test.That(t, matches[arm].SerialNumber, test.ShouldEqual, "A-1")
+	test.That(t, store.Query(Query{FirmwareVersion: "2.0.0"}), test.ShouldBeEmpty)
+
+	// metadata survives reloading the store
+	reloaded, err := NewStore(path)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reloaded.Snapshot(), test.ShouldResemble, store.Snapshot())
+	test.That(t, reloaded.Machine().InstallDate.Equal(installDate), test.ShouldBeTrue)
+
+	test.That(t, reloaded.RemoveResource(gripper), test.ShouldBeNil)
+	_, ok = reloaded.Resource(gripper)
+	test.That(t, ok, test.ShouldBeFalse)
+	reloaded, err = NewStore(path)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reloaded.Snapshot().Resources, test.ShouldHaveLength, 1)
+
+	err = store.SetResource(resource.Name{}, Metadata{})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestStoreInMemory(t *testing.T) {
+	store, err := NewStore("")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, store.SetMachine(Metadata{SerialNumber: "M-1"}), test.ShouldBeNil)
+	test.That(t, store.Machine().SerialNumber, test.ShouldEqual, "M-1")
+}
+
+func TestStoreCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inventory.json")
+	test.That(t, os.WriteFile(path, []byte("{"), 0o600), test.ShouldBeNil)
+	_, err := NewStore(path)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "failed to parse inventory")
+}
@@ -4,6 +4,7 @@ package robot
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/robot/inventory"
 	"go.viam.com/rdk/robot/packages"
 	weboptions "go.viam.com/rdk/robot/web/options"
 	"go.viam.com/rdk/session"
@@ -121,6 +123,24 @@ type LocalRobot interface {
 	// visualization.
 	// DOT reference: https://graphviz.org/doc/info/lang.html
 	ExportResourcesAsDot(index int) (resource.GetSnapshotInfo, error)
+
+	// Inventory returns the asset metadata, such as serial numbers and maintenance notes, of the robot
+	// and its resources.
+	Inventory() *inventory.Store
+
+	// SetSimulated swaps a component for its fake counterpart, or back to its real driver, keeping its name and
+	// dependents. It is meant for testing applications safely against a production config.
+	SetSimulated(ctx context.Context, name resource.Name, simulated bool) error
+
+	// Simulated returns the names of the components which are swapped for their fakes.
+	Simulated() []resource.Name
+
+	// StopTagged stops every actuator tagged with the given tag, such as all of those tagged "drive".
+	StopTagged(ctx context.Context, tag string, extra map[resource.Name]map[string]interface{}) error
+
+	// PreviewReconfigure reports what reconfiguring the robot with the given config would change, without changing
+	// anything, so the effect of a config can be checked before it is pushed to a live robot.
+	PreviewReconfigure(ctx context.Context, newConfig *config.Config) (*ReconfigurePreview, error)
 }
 
 // A RemoteRobot is a Robot that was created through a connection.
@@ -143,6 +163,38 @@ type Status struct {
 	Status           interface{}
 }
 
+// ReconfigurePreview describes what reconfiguring a robot with a config would change. Each list is sorted.
+type ReconfigurePreview struct {
+	// Added are the resources which would be built.
+	Added []resource.Name
+	// Removed are the resources which would be closed and removed, along with the resources depending on them.
+	Removed []resource.Name
+	// Rebuilt are the resources which would be closed and built again, as their model or module changed or they
+	// always rebuild when their config changes, or which would be reconnected, for remotes.
+	Rebuilt []resource.Name
+	// Reconfigured are the resources whose config changed but not their model, which would be reconfigured in place,
+	// or rebuilt if they turn out not to be able to.
+	Reconfigured []resource.Name
+	// Dependents are the resources whose config did not change but which depend on a resource which would be rebuilt
+	// or reconfigured. They would be reconfigured with the new resource if it is rebuilt.
+	Dependents []resource.Name
+
+	ModulesAdded     []string
+	ModulesRemoved   []string
+	ModulesRestarted []string
+
+	ProcessesAdded     []string
+	ProcessesRemoved   []string
+	ProcessesRestarted []string
+}
+
+// Empty returns whether the reconfiguration would change nothing.
+func (p *ReconfigurePreview) Empty() bool {
+	return len(p.Added)+len(p.Removed)+len(p.Rebuilt)+len(p.Reconfigured)+len(p.Dependents)+
+		len(p.ModulesAdded)+len(p.ModulesRemoved)+len(p.ModulesRestarted)+
+		len(p.ProcessesAdded)+len(p.ProcessesRemoved)+len(p.ProcessesRestarted) == 0
+}
+
 // RestartModuleRequest is a go mirror of a proto message.
 type RestartModuleRequest struct {
 	ModuleID   string
@@ -179,6 +231,21 @@ func NamesByAPI(r Robot, api resource.API) []string {
 	return names
 }
 
+// NamesByTag returns the sorted names of the components and services of the robot tagged with the given tag.
+func NamesByTag(r LocalRobot, tag string) []resource.Name {
+	cfg := r.Config()
+	names := []resource.Name{}
+	for _, confs := range [][]resource.Config{cfg.Components, cfg.Services} {
+		for _, conf := range confs {
+			if conf.HasTag(tag) {
+				names = append(names, conf.ResourceName())
+			}
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i].String() < names[j].String() })
+	return names
+}
+
 // TypeAndMethodDescFromMethod attempts to determine the resource API and its respective gRPC method information
 // from the given robot and method path. If nothing can be found, grpc.UnimplementedError is returned.
 func TypeAndMethodDescFromMethod(r Robot, method string) (*resource.RPCAPI, *desc.MethodDescriptor, error) {
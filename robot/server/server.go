@@ -67,15 +67,24 @@ func (s *Server) GetOperations(ctx context.Context, req *pb.GetOperationsRequest
 			continue
 		}
 
-		s, err := convertInterfaceToStruct(o.Arguments)
+		args, err := convertInterfaceToStruct(o.Arguments)
 		if err != nil {
 			return nil, err
 		}
+		// a failure to work out the progress of one operation should not fail the listing
+		if progress, ok, err := o.Progress(ctx); err != nil {
+			s.robot.Logger().CDebugw(ctx, "failed to get the progress of an operation", "id", o.ID.String(), "error", err)
+		} else if ok {
+			if args.Fields == nil {
+				args.Fields = map[string]*structpb.Value{}
+			}
+			args.Fields[operation.ProgressKey] = progressToValue(progress)
+		}
 
 		pbOp := &pb.Operation{
 			Id:        o.ID.String(),
 			Method:    o.Method,
-			Arguments: s,
+			Arguments: args,
 			Started:   timestamppb.New(o.Started),
 		}
 		if o.SessionID != uuid.Nil {
@@ -88,6 +97,14 @@ func (s *Server) GetOperations(ctx context.Context, req *pb.GetOperationsRequest
 	return res, nil
 }
 
+func progressToValue(progress operation.Progress) *structpb.Value {
+	fields := map[string]*structpb.Value{"percent_complete": structpb.NewNumberValue(progress.Percent)}
+	if progress.Remaining > 0 {
+		fields["remaining_sec"] = structpb.NewNumberValue(progress.Remaining.Seconds())
+	}
+	return structpb.NewStructValue(&structpb.Struct{Fields: fields})
+}
+
 func convertInterfaceToStruct(i interface{}) (*structpb.Struct, error) {
 	if i == nil {
 		return &structpb.Struct{}, nil
@@ -224,6 +224,10 @@ func TestServer(t *testing.T) {
 
 		op3, cancel3 := injectRobot.OperationManager().Create(sess2Ctx, "something3", nil)
 		defer cancel3()
+		untrack := operation.TrackProgress(op3, func(ctx context.Context) (operation.Progress, error) {
+			return operation.Progress{Percent: 40, Remaining: 1500 * time.Millisecond}, nil
+		})
+		defer untrack()
 
 		opsResp, err = server.GetOperations(context.Background(), &pb.GetOperationsRequest{})
 		test.That(t, err, test.ShouldBeNil)
@@ -252,6 +256,16 @@ func TestServer(t *testing.T) {
 				t.Fail()
 			})
 		}
+		for _, op := range opsResp.Operations {
+			progress, ok := op.Arguments.Fields[operation.ProgressKey]
+			if op.Id != operation.Get(op3).ID.String() {
+				test.That(t, ok, test.ShouldBeFalse)
+				continue
+			}
+			test.That(t, ok, test.ShouldBeTrue)
+			test.That(t, progress.GetStructValue().AsMap(), test.ShouldResemble,
+				map[string]interface{}{"percent_complete": 40., "remaining_sec": 1.5})
+		}
 	})
 
 	t.Run("GetSessions", func(t *testing.T) {
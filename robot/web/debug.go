@@ -0,0 +1,23 @@
+package web
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// decodeJSONBody decodes the JSON body of a request which changes the machine into v. Only requests declaring a JSON
+// body are accepted, since browsers do not send those across origins without a CORS preflight, so other sites cannot
+// forge them.
+func decodeJSONBody(r *http.Request, v interface{}) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return errors.New("the request body must be application/json")
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return errors.Wrap(err, "invalid request body")
+	}
+	return nil
+}
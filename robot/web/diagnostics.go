@@ -0,0 +1,44 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/robot/inventory"
+)
+
+// diagnosticsReport describes a machine for troubleshooting and fleet asset management.
+type diagnosticsReport struct {
+	Version     string   `json:"version"`
+	GitRevision string   `json:"git_revision"`
+	Resources   []string `json:"resources"`
+	// Inventory is the asset metadata of the machine and all of its resources, if the machine keeps any.
+	Inventory *inventoryReport `json:"inventory,omitempty"`
+}
+
+// handleDiagnostics serves the machine's diagnostics as JSON.
+func (svc *webService) handleDiagnostics(w http.ResponseWriter, r *http.Request) {
+	localRobot, isLocal := svc.r.(robot.LocalRobot)
+	if !isLocal {
+		http.NotFound(w, r)
+		return
+	}
+
+	report := diagnosticsReport{Version: config.Version, GitRevision: config.GitRevision, Resources: []string{}}
+	for _, name := range localRobot.ResourceNames() {
+		report.Resources = append(report.Resources, name.String())
+	}
+	sort.Strings(report.Resources)
+	if localRobot.Inventory() != nil {
+		inventoryReport := newInventoryReport(localRobot, inventory.Query{})
+		report.Inventory = &inventoryReport
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		svc.logger.Errorw("failed to write diagnostics", "error", err)
+	}
+}
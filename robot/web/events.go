@@ -0,0 +1,237 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.viam.com/utils"
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+
+	"go.viam.com/rdk/operation"
+	"go.viam.com/rdk/resource"
+)
+
+// Types of the events pushed to subscribers of the event feed.
+const (
+	// EventSnapshot is the first event of every subscription, listing the resources and running operations.
+	EventSnapshot          = "snapshot"
+	EventResourceAdded     = "resource_added"
+	EventResourceRemoved   = "resource_removed"
+	EventOperationStarted  = "operation_started"
+	EventOperationFinished = "operation_finished"
+	EventAlert             = "alert"
+)
+
+const (
+	eventSubscriberCapacity = 64
+	eventWriteTimeout       = 5 * time.Second
+)
+
+// An Event is a change to the machine pushed to subscribers of the event feed as JSON.
+type Event struct {
+	Type     string                 `json:"type"`
+	Time     time.Time              `json:"time"`
+	Resource string                 `json:"resource,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+}
+
+// NewAlert returns an alert event about the named resource, or about the machine as a whole when name is nil.
+func NewAlert(name *resource.Name, severity, message string) Event {
+	event := Event{
+		Type: EventAlert,
+		Time: time.Now(),
+		Data: map[string]interface{}{"severity": severity, "message": message},
+	}
+	if name != nil {
+		event.Resource = name.String()
+	}
+	return event
+}
+
+// eventFeed fans events out to the WebSocket clients subscribed to them.
+type eventFeed struct {
+	mu          sync.Mutex
+	subscribers map[*eventSubscriber]struct{}
+	resources   map[resource.Name]struct{}
+}
+
+type eventSubscriber struct {
+	events chan Event
+	types  map[string]bool
+	// slow is closed when the subscriber fell too far behind and is dropped.
+	slow     chan struct{}
+	slowOnce sync.Once
+}
+
+func newEventFeed() *eventFeed {
+	return &eventFeed{subscribers: map[*eventSubscriber]struct{}{}}
+}
+
+// publish queues the event to every subscriber interested in it. Subscribers which do not keep up are dropped
+// rather than holding up the machine.
+func (f *eventFeed) publish(event Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for sub := range f.subscribers {
+		if len(sub.types) != 0 && !sub.types[event.Type] {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			sub.slowOnce.Do(func() { close(sub.slow) })
+		}
+	}
+}
+
+// subscribe returns a subscriber to the given event types, or to all of them when none are given.
+func (f *eventFeed) subscribe(types []string) (*eventSubscriber, func()) {
+	sub := &eventSubscriber{
+		events: make(chan Event, eventSubscriberCapacity),
+		types:  map[string]bool{},
+		slow:   make(chan struct{}),
+	}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+	f.mu.Lock()
+	f.subscribers[sub] = struct{}{}
+	f.mu.Unlock()
+	return sub, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		delete(f.subscribers, sub)
+	}
+}
+
+// updateResources publishes the resources which were added or removed since the last update.
+func (f *eventFeed) updateResources(names map[resource.Name]resource.Resource) {
+	f.mu.Lock()
+	previous := f.resources
+	f.resources = make(map[resource.Name]struct{}, len(names))
+	for name := range names {
+		f.resources[name] = struct{}{}
+	}
+	f.mu.Unlock()
+
+	now := time.Now()
+	for name := range names {
+		if _, ok := previous[name]; !ok {
+			f.publish(Event{Type: EventResourceAdded, Time: now, Resource: name.String()})
+		}
+	}
+	for name := range previous {
+		if _, ok := names[name]; !ok {
+			f.publish(Event{Type: EventResourceRemoved, Time: now, Resource: name.String()})
+		}
+	}
+}
+
+// watchOperations publishes the operations started and finished by the manager until the returned function is
+// called.
+func (f *eventFeed) watchOperations(manager *operation.Manager) func() {
+	return manager.Subscribe(func(event operation.Event) {
+		if event.Finished {
+			f.publish(Event{
+				Type: EventOperationFinished,
+				Time: time.Now(),
+				Data: operationData(event.Operation, time.Since(event.Operation.Started)),
+			})
+			return
+		}
+		f.publish(Event{Type: EventOperationStarted, Time: time.Now(), Data: operationData(event.Operation, 0)})
+	})
+}
+
+func operationData(op *operation.Operation, elapsed time.Duration) map[string]interface{} {
+	data := map[string]interface{}{
+		"id":      op.ID.String(),
+		"method":  op.Method,
+		"started": op.Started,
+	}
+	if op.SessionID != uuid.Nil {
+		data["session_id"] = op.SessionID.String()
+	}
+	if elapsed != 0 {
+		data["elapsed_ms"] = elapsed.Milliseconds()
+	}
+	return data
+}
+
+func (f *eventFeed) snapshot(ops []*operation.Operation) Event {
+	f.mu.Lock()
+	resources := make([]string, 0, len(f.resources))
+	for name := range f.resources {
+		resources = append(resources, name.String())
+	}
+	f.mu.Unlock()
+	sort.Strings(resources)
+
+	operations := make([]interface{}, 0, len(ops))
+	for _, op := range ops {
+		operations = append(operations, operationData(op, 0))
+	}
+	return Event{
+		Type: EventSnapshot,
+		Time: time.Now(),
+		Data: map[string]interface{}{"resources": resources, "operations": operations},
+	}
+}
+
+// PublishEvent pushes the event to the subscribers of the event feed.
+func (svc *webService) PublishEvent(event Event) {
+	svc.events.publish(event)
+}
+
+// handleEvents upgrades the request to a WebSocket and pushes machine events to it as JSON until the client goes
+// away. The comma separated types query parameter limits which events are sent.
+func (svc *webService) handleEvents(origins []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{OriginPatterns: origins})
+		if err != nil {
+			svc.logger.Debugw("failed to accept event feed subscriber", "error", err)
+			return
+		}
+		defer func() {
+			utils.UncheckedError(conn.Close(websocket.StatusNormalClosure, ""))
+		}()
+
+		var types []string
+		if param := r.URL.Query().Get("types"); param != "" {
+			types = strings.Split(param, ",")
+		}
+		sub, unsubscribe := svc.events.subscribe(types)
+		defer unsubscribe()
+
+		// subscribers only listen, so reading is only needed to notice when they go away
+		ctx := conn.CloseRead(r.Context())
+		if err := writeEvent(ctx, conn, svc.events.snapshot(svc.r.OperationManager().All())); err != nil {
+			return
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sub.slow:
+				utils.UncheckedError(conn.Close(websocket.StatusPolicyViolation, "subscriber fell behind"))
+				return
+			case event := <-sub.events:
+				if err := writeEvent(ctx, conn, event); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+func writeEvent(ctx context.Context, conn *websocket.Conn, event Event) error {
+	ctx, cancel := context.WithTimeout(ctx, eventWriteTimeout)
+	defer cancel()
+	return wsjson.Write(ctx, conn, event)
+}
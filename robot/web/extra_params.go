@@ -0,0 +1,145 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+)
+
+// extraParamsUnaryServerInterceptor checks the extra of calls to resources whose model declared the extra
+// parameters of the method, refusing unknown keys and values of the wrong type and filling in defaults. Calls to
+// resources of models registered in another process, such as a module or a remote, are passed through as is.
+func (svc *webService) extraParamsUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	apis := apisByServiceName()
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+		serviceName, method, ok := splitFullMethod(info.FullMethod)
+		if !ok {
+			return handler(ctx, req)
+		}
+		api, ok := apis[serviceName]
+		if !ok || !resource.HasExtraParams(api, method) {
+			return handler(ctx, req)
+		}
+		return svc.checkExtraParams(ctx, req, msg, api, method, handler)
+	}
+}
+
+// checkExtraParams checks the extra of a call to a method of the API against the params its model declared.
+func (svc *webService) checkExtraParams(ctx context.Context, req interface{}, msg proto.Message, api resource.API,
+	method string, handler grpc.UnaryHandler,
+) (interface{}, error) {
+
+	m := msg.ProtoReflect()
+	nameField := m.Descriptor().Fields().ByName("name")
+	extraField := m.Descriptor().Fields().ByName("extra")
+	if nameField == nil || nameField.Kind() != protoreflect.StringKind ||
+		extraField == nil || extraField.Message() == nil || extraField.Message().FullName() != "google.protobuf.Struct" {
+		return handler(ctx, req)
+	}
+	model, ok := svc.modelOf(resource.NewName(api, m.Get(nameField).String()))
+	if !ok {
+		return handler(ctx, req)
+	}
+	params, ok := resource.LookupExtraParams(api, model, method)
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	var extra map[string]interface{}
+	if m.Has(extraField) {
+		extra = m.Get(extraField).Message().Interface().(*structpb.Struct).AsMap()
+	}
+	withDefaults, err := resource.ValidateExtra(method, params, extra)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if len(withDefaults) != len(extra) {
+		filled, err := structpb.NewStruct(withDefaults)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		m.Set(extraField, protoreflect.ValueOfMessage(filled.ProtoReflect()))
+	}
+	return handler(ctx, req)
+}
+
+// splitFullMethod splits a gRPC method such as "/viam.component.arm.v1.ArmService/MoveToPosition" into its
+// service and method names.
+func splitFullMethod(fullMethod string) (string, string, bool) {
+	serviceName, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return serviceName, method, ok && serviceName != "" && method != ""
+}
+
+// apisByServiceName returns the API served by each registered gRPC service, by service name.
+func apisByServiceName() map[string]resource.API {
+	apis := map[string]resource.API{}
+	for api, reg := range resource.RegisteredAPIs() {
+		if reg.RPCServiceDesc != nil {
+			apis[reg.RPCServiceDesc.ServiceName] = api
+		}
+	}
+	return apis
+}
+
+// configuredRobot is a robot which knows the config of its resources, such as a local robot.
+type configuredRobot interface {
+	Config() *config.Config
+}
+
+// modelOf returns the model a local resource is configured with.
+func (svc *webService) modelOf(name resource.Name) (resource.Model, bool) {
+	r, ok := svc.r.(configuredRobot)
+	if !ok {
+		return resource.Model{}, false
+	}
+	cfg := r.Config()
+	for _, confs := range [][]resource.Config{cfg.Components, cfg.Services} {
+		for _, conf := range confs {
+			if conf.ResourceName() == name {
+				return conf.Model, true
+			}
+		}
+	}
+	return resource.Model{}, false
+}
+
+// handleExtraParams serves the extra parameters declared by the models of the machine's resources as JSON, by
+// resource name and then method, so that clients can discover what each resource accepts.
+func (svc *webService) handleExtraParams(w http.ResponseWriter, r *http.Request) {
+	configured, ok := svc.r.(configuredRobot)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	cfg := configured.Config()
+	declared := map[string]resource.ExtraParams{}
+	for _, confs := range [][]resource.Config{cfg.Components, cfg.Services} {
+		for _, conf := range confs {
+			reg, ok := resource.LookupRegistration(conf.API, conf.Model)
+			if !ok || len(reg.ExtraParams) == 0 {
+				continue
+			}
+			declared[conf.ResourceName().String()] = reg.ExtraParams
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(declared); err != nil {
+		svc.logger.Errorw("failed to write extra params", "error", err)
+	}
+}
@@ -0,0 +1,41 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.viam.com/rdk/grpc"
+)
+
+// faultsBody is the body of a request to /debug/faults and of its responses.
+type faultsBody struct {
+	Faults []grpc.Fault `json:"faults"`
+}
+
+// handleFaults serves the faults injected into the gRPC calls the server handles. A PUT replaces them with the
+// faults in its body.
+func (svc *webService) handleFaults(injector *grpc.FaultInjector) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			var body faultsBody
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := injector.SetFaults(body.Faults); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			svc.logger.Warnw("injecting faults into gRPC calls", "faults", body.Faults)
+		}
+
+		body := faultsBody{Faults: injector.Faults()}
+		if body.Faults == nil {
+			body.Faults = []grpc.Fault{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(body); err != nil {
+			svc.logger.Errorw("failed to write faults", "error", err)
+		}
+	}
+}
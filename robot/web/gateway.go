@@ -0,0 +1,56 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	pb "go.viam.com/api/robot/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/web/openapi"
+)
+
+// gatewayPathPrefix is where the HTTP bindings of the API declare their paths, which the REST gateway serves
+// from /api instead.
+const gatewayPathPrefix = "/viam"
+
+// handleOpenAPI serves an OpenAPI document describing every method reachable through the REST gateway.
+func (svc *webService) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	descs := []*grpc.ServiceDesc{&pb.RobotService_ServiceDesc}
+	for _, reg := range resource.RegisteredAPIs() {
+		if reg.RPCServiceDesc != nil && reg.RPCServiceHandler != nil {
+			descs = append(descs, reg.RPCServiceDesc)
+		}
+	}
+	services := make([]protoreflect.ServiceDescriptor, 0, len(descs))
+	for _, desc := range descs {
+		found, err := openapi.ServiceDescriptors(desc)
+		if err != nil {
+			// APIs registered by modules are only known through reflection
+			svc.logger.Debugw("leaving service out of OpenAPI document", "service", desc.ServiceName, "error", err)
+			continue
+		}
+		services = append(services, found...)
+	}
+
+	version := config.Version
+	if version == "" {
+		version = "dev"
+	}
+	doc := openapi.Generate(openapi.Options{
+		Info: openapi.Info{
+			Title:       "Viam machine REST API",
+			Description: "The resource APIs of this machine, served over HTTP and JSON by a gRPC gateway.",
+			Version:     version,
+		},
+		StripPathPrefix: gatewayPathPrefix,
+	}, services...)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(doc); err != nil {
+		svc.logger.Errorw("failed to write OpenAPI document", "error", err)
+	}
+}
@@ -0,0 +1,60 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/robot/inventory"
+)
+
+// inventoryReport is the diagnostic view of a machine's inventory.
+type inventoryReport struct {
+	inventory.Snapshot
+	// Unconfigured lists resources which have metadata but are not currently part of the machine.
+	Unconfigured []string `json:"unconfigured,omitempty"`
+}
+
+// handleInventory serves the machine's inventory as JSON. The serial_number, manufacturer, model and
+// firmware_version query parameters filter which resources are reported.
+func (svc *webService) handleInventory(w http.ResponseWriter, r *http.Request) {
+	localRobot, isLocal := svc.r.(robot.LocalRobot)
+	if !isLocal || localRobot.Inventory() == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	params := r.URL.Query()
+	report := newInventoryReport(localRobot, inventory.Query{
+		SerialNumber:    params.Get("serial_number"),
+		Manufacturer:    params.Get("manufacturer"),
+		Model:           params.Get("model"),
+		FirmwareVersion: params.Get("firmware_version"),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		svc.logger.Errorw("failed to write inventory", "error", err)
+	}
+}
+
+// newInventoryReport returns the machine's metadata and that of the resources matching the query.
+func newInventoryReport(localRobot robot.LocalRobot, query inventory.Query) inventoryReport {
+	report := inventoryReport{Snapshot: inventory.Snapshot{Machine: localRobot.Inventory().Machine()}}
+	configured := map[string]bool{}
+	for _, name := range localRobot.ResourceNames() {
+		configured[name.String()] = true
+	}
+	for name, md := range localRobot.Inventory().Query(query) {
+		if report.Resources == nil {
+			report.Resources = map[string]inventory.Metadata{}
+		}
+		report.Resources[name.String()] = md
+		if !configured[name.String()] {
+			report.Unconfigured = append(report.Unconfigured, name.String())
+		}
+	}
+	sort.Strings(report.Unconfigured)
+	return report
+}
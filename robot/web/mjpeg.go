@@ -0,0 +1,133 @@
+//go:build !no_cgo || android
+
+package web
+
+import (
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"goji.io"
+	"goji.io/pat"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/gostream"
+	"go.viam.com/rdk/rimage"
+	weboptions "go.viam.com/rdk/robot/web/options"
+	"go.viam.com/rdk/utils"
+)
+
+const (
+	mjpegBoundary   = "mjpegframe"
+	defaultMJPEGFPS = 10.
+	maxMJPEGFPS     = 30.
+)
+
+// installMJPEG serves every camera of the machine as an MJPEG stream and as JPEG snapshots.
+func (svc *webService) installMJPEG(mux *goji.Mux, options weboptions.Options) {
+	mux.HandleFunc(pat.Get("/mjpeg/:camera"), apiKeyAuth(options, "cameras", svc.handleMJPEGStream))
+	mux.HandleFunc(pat.Get("/mjpeg/:camera/snapshot"), apiKeyAuth(options, "cameras", svc.handleMJPEGSnapshot))
+}
+
+// handleMJPEGStream serves a camera as a multipart MJPEG stream until the client goes away. The fps query parameter
+// limits the frame rate, defaulting to 10 frames per second.
+func (svc *webService) handleMJPEGStream(w http.ResponseWriter, r *http.Request) {
+	cam, err := camera.FromRobot(svc.r, pat.Param(r, "camera"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	fps := defaultMJPEGFPS
+	if raw := r.URL.Query().Get("fps"); raw != "" {
+		if fps, err = strconv.ParseFloat(raw, 64); err != nil || fps <= 0 || fps > maxMJPEGFPS {
+			http.Error(w, "fps must be a number above 0 and at most 30", http.StatusBadRequest)
+			return
+		}
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported by this connection", http.StatusInternalServerError)
+		return
+	}
+
+	ctx := gostream.WithMIMETypeHint(r.Context(), utils.MimeTypeJPEG)
+	stream, err := cam.Stream(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer func() {
+		if err := stream.Close(ctx); err != nil {
+			svc.logger.Debugw("failed to close MJPEG camera stream", "error", err)
+		}
+	}()
+
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(mjpegBoundary); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary="+mjpegBoundary)
+	w.Header().Set("Cache-Control", "no-store")
+
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / fps))
+	defer ticker.Stop()
+	for {
+		img, release, err := stream.Next(ctx)
+		if err != nil {
+			svc.logger.Debugw("MJPEG stream ended", "camera", cam.Name(), "error", err)
+			return
+		}
+		data, err := rimage.EncodeImage(ctx, img, utils.MimeTypeJPEG)
+		release()
+		if err != nil {
+			svc.logger.Debugw("cannot encode MJPEG frame", "camera", cam.Name(), "error", err)
+			return
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":   {utils.MimeTypeJPEG},
+			"Content-Length": {strconv.Itoa(len(data))},
+		})
+		if err != nil {
+			return
+		}
+		if _, err := part.Write(data); err != nil {
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// handleMJPEGSnapshot serves the current frame of a camera as a JPEG.
+func (svc *webService) handleMJPEGSnapshot(w http.ResponseWriter, r *http.Request) {
+	cam, err := camera.FromRobot(svc.r, pat.Param(r, "camera"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	ctx := gostream.WithMIMETypeHint(r.Context(), utils.MimeTypeJPEG)
+	img, release, err := camera.ReadImage(ctx, cam)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data, err := rimage.EncodeImage(ctx, img, utils.MimeTypeJPEG)
+	release()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", utils.MimeTypeJPEG)
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := w.Write(data); err != nil {
+		svc.logger.Debugw("failed to write snapshot", "error", err)
+	}
+}
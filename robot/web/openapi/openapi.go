@@ -0,0 +1,438 @@
+// Package openapi describes the REST gateway of gRPC services as an OpenAPI 3 document, derived from the HTTP
+// bindings annotated on their protobuf definitions.
+package openapi
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// Version is the version of the OpenAPI specification documents are written in.
+const Version = "3.0.3"
+
+// Document is an OpenAPI document. Only the parts of the specification needed to describe a gRPC gateway are
+// modeled.
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Tags       []Tag                           `json:"tags,omitempty"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+// Info describes the API.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Tag groups the operations of one service.
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// Operation is a single method of a service bound to an HTTP verb and path.
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter is a path or query parameter of an operation.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody is the JSON body of an operation.
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a possible response of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas of all messages referenced by operations.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is a JSON schema, as used by OpenAPI.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+const jsonMediaType = "application/json"
+
+// Options configure how a document is generated.
+type Options struct {
+	Info Info
+	// StripPathPrefix is removed from the path of every HTTP binding, for gateways which are served under a
+	// different prefix than the one the bindings declare.
+	StripPathPrefix string
+}
+
+// ServiceDescriptors looks up the protobuf descriptors of the given gRPC services.
+func ServiceDescriptors(descs ...*grpc.ServiceDesc) ([]protoreflect.ServiceDescriptor, error) {
+	services := make([]protoreflect.ServiceDescriptor, 0, len(descs))
+	for _, desc := range descs {
+		d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(desc.ServiceName))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to find descriptor of service %q", desc.ServiceName)
+		}
+		svcDesc, ok := d.(protoreflect.ServiceDescriptor)
+		if !ok {
+			return nil, errors.Errorf("%q is not a service", desc.ServiceName)
+		}
+		services = append(services, svcDesc)
+	}
+	return services, nil
+}
+
+// Generate describes every method of the given services which has an HTTP binding. Methods without one are not
+// reachable through the gateway and are left out.
+func Generate(opts Options, services ...protoreflect.ServiceDescriptor) *Document {
+	g := &generator{
+		doc: &Document{
+			OpenAPI:    Version,
+			Info:       opts.Info,
+			Paths:      map[string]map[string]Operation{},
+			Components: Components{Schemas: map[string]*Schema{}},
+		},
+		stripPrefix: opts.StripPathPrefix,
+	}
+	sorted := append([]protoreflect.ServiceDescriptor(nil), services...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].FullName() < sorted[j].FullName() })
+	for _, svc := range sorted {
+		g.addService(svc)
+	}
+	return g.doc
+}
+
+type generator struct {
+	doc         *Document
+	stripPrefix string
+}
+
+func (g *generator) addService(svc protoreflect.ServiceDescriptor) {
+	tag := string(svc.FullName())
+	added := false
+	methods := svc.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		rule, ok := proto.GetExtension(method.Options(), annotations.E_Http).(*annotations.HttpRule)
+		if !ok || rule == nil {
+			continue
+		}
+		for _, binding := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+			verb, path := httpBinding(binding)
+			if verb == "" {
+				continue
+			}
+			g.addOperation(tag, method, verb, path, binding.GetBody())
+			added = true
+		}
+	}
+	if added {
+		g.doc.Tags = append(g.doc.Tags, Tag{Name: tag})
+	}
+}
+
+func httpBinding(rule *annotations.HttpRule) (string, string) {
+	switch pattern := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return "get", pattern.Get
+	case *annotations.HttpRule_Put:
+		return "put", pattern.Put
+	case *annotations.HttpRule_Post:
+		return "post", pattern.Post
+	case *annotations.HttpRule_Delete:
+		return "delete", pattern.Delete
+	case *annotations.HttpRule_Patch:
+		return "patch", pattern.Patch
+	default:
+		return "", ""
+	}
+}
+
+func (g *generator) addOperation(tag string, method protoreflect.MethodDescriptor, verb, path, body string) {
+	path, pathParams := openAPIPath(strings.TrimPrefix(path, g.stripPrefix))
+	input := method.Input()
+	op := Operation{
+		OperationID: string(method.Parent().Name()) + "_" + string(method.Name()),
+		Summary:     leadingComment(method),
+		Tags:        []string{tag},
+		Responses: map[string]Response{
+			"200": g.successResponse(method),
+			"default": {
+				Description: "An error, as a google.rpc.Status.",
+				Content: map[string]MediaType{jsonMediaType: {Schema: &Schema{
+					Type: "object",
+					Properties: map[string]*Schema{
+						"code":    {Type: "integer", Format: "int32"},
+						"message": {Type: "string"},
+						"details": {Type: "array", Items: &Schema{Type: "object"}},
+					},
+				}}},
+			},
+		},
+	}
+
+	inPath := map[string]bool{}
+	for _, name := range pathParams {
+		inPath[name] = true
+		op.Parameters = append(op.Parameters, Parameter{
+			Name: name, In: "path", Required: true, Schema: g.fieldSchema(fieldByPath(input, name)),
+		})
+	}
+	switch body {
+	case "":
+		// every other field that can be written in a query string is a query parameter
+		op.Parameters = append(op.Parameters, g.queryParameters(input, "", inPath, map[protoreflect.FullName]bool{})...)
+	case "*":
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{jsonMediaType: {Schema: g.messageRef(input)}},
+		}
+	default:
+		if field := input.Fields().ByName(protoreflect.Name(body)); field != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{jsonMediaType: {Schema: g.fieldSchema(field)}},
+			}
+		}
+	}
+
+	if g.doc.Paths[path] == nil {
+		g.doc.Paths[path] = map[string]Operation{}
+	}
+	g.doc.Paths[path][verb] = op
+}
+
+func (g *generator) successResponse(method protoreflect.MethodDescriptor) Response {
+	if !method.IsStreamingServer() {
+		return Response{
+			Description: "A successful response.",
+			Content:     map[string]MediaType{jsonMediaType: {Schema: g.messageRef(method.Output())}},
+		}
+	}
+	// the gateway writes every message of a stream as its own line
+	return Response{
+		Description: "A stream of newline delimited JSON objects, each holding one response in result.",
+		Content: map[string]MediaType{jsonMediaType: {Schema: &Schema{
+			Type:       "object",
+			Properties: map[string]*Schema{"result": g.messageRef(method.Output())},
+		}}},
+	}
+}
+
+// queryParameters lists the fields of msg which the gateway reads from the query string, naming nested fields with
+// dots as in to.x. Maps and objects like google.protobuf.Struct cannot be written in a query string.
+func (g *generator) queryParameters(
+	msg protoreflect.MessageDescriptor, prefix string, skip map[string]bool, seen map[protoreflect.FullName]bool,
+) []Parameter {
+	seen[msg.FullName()] = true
+	defer delete(seen, msg.FullName())
+
+	var params []Parameter
+	fields := msg.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		name := prefix + string(field.Name())
+		if skip[name] || field.IsMap() {
+			continue
+		}
+		if field.Kind() != protoreflect.MessageKind && field.Kind() != protoreflect.GroupKind {
+			params = append(params, Parameter{Name: name, In: "query", Schema: g.fieldSchema(field)})
+			continue
+		}
+		if schema, ok := wellKnownSchema(field.Message().FullName()); ok {
+			if schema.Type != "object" && schema.Type != "array" && schema.Type != "" {
+				params = append(params, Parameter{Name: name, In: "query", Schema: g.fieldSchema(field)})
+			}
+			continue
+		}
+		if field.IsList() || seen[field.Message().FullName()] {
+			continue
+		}
+		params = append(params, g.queryParameters(field.Message(), name+".", skip, seen)...)
+	}
+	return params
+}
+
+// openAPIPath turns an HTTP binding path template into an OpenAPI path, returning the names of its parameters.
+// Parameters with sub-patterns, like {name=things/*}, lose the pattern.
+func openAPIPath(template string) (string, []string) {
+	var params []string
+	var out strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			out.WriteString(template)
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			out.WriteString(template)
+			break
+		}
+		out.WriteString(template[:start])
+		name := template[start+1 : start+end]
+		if eq := strings.IndexByte(name, '='); eq >= 0 {
+			name = name[:eq]
+		}
+		params = append(params, name)
+		out.WriteString("{" + name + "}")
+		template = template[start+end+1:]
+	}
+	return out.String(), params
+}
+
+// fieldByPath finds a possibly nested field, such as parent.name, of a message.
+func fieldByPath(msg protoreflect.MessageDescriptor, path string) protoreflect.FieldDescriptor {
+	var field protoreflect.FieldDescriptor
+	for _, part := range strings.Split(path, ".") {
+		if msg == nil {
+			return nil
+		}
+		field = msg.Fields().ByName(protoreflect.Name(part))
+		if field == nil {
+			return nil
+		}
+		msg = field.Message()
+	}
+	return field
+}
+
+func (g *generator) messageRef(msg protoreflect.MessageDescriptor) *Schema {
+	if schema, ok := wellKnownSchema(msg.FullName()); ok {
+		return schema
+	}
+	name := string(msg.FullName())
+	if _, ok := g.doc.Components.Schemas[name]; !ok {
+		schema := &Schema{Type: "object", Description: leadingComment(msg), Properties: map[string]*Schema{}}
+		// registered before the fields are described so that recursive messages terminate
+		g.doc.Components.Schemas[name] = schema
+		fields := msg.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			field := fields.Get(i)
+			schema.Properties[string(field.Name())] = g.fieldSchema(field)
+		}
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (g *generator) fieldSchema(field protoreflect.FieldDescriptor) *Schema {
+	if field == nil {
+		return &Schema{Type: "string"}
+	}
+	if field.IsMap() {
+		return &Schema{Type: "object", AdditionalProperties: g.singularSchema(field.MapValue())}
+	}
+	schema := g.singularSchema(field)
+	if field.IsList() {
+		return &Schema{Type: "array", Items: schema}
+	}
+	return schema
+}
+
+// singularSchema describes a single value of the field as protojson writes it.
+func (g *generator) singularSchema(field protoreflect.FieldDescriptor) *Schema {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return &Schema{Type: "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return &Schema{Type: "integer", Format: "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return &Schema{Type: "integer", Format: "int64"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// 64 bit integers are written as strings so that they survive JSON parsers using doubles
+		return &Schema{Type: "string", Format: "int64"}
+	case protoreflect.FloatKind:
+		return &Schema{Type: "number", Format: "float"}
+	case protoreflect.DoubleKind:
+		return &Schema{Type: "number", Format: "double"}
+	case protoreflect.StringKind:
+		return &Schema{Type: "string"}
+	case protoreflect.BytesKind:
+		return &Schema{Type: "string", Format: "byte"}
+	case protoreflect.EnumKind:
+		values := field.Enum().Values()
+		names := make([]string, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+		return &Schema{Type: "string", Enum: names}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return g.messageRef(field.Message())
+	default:
+		return &Schema{}
+	}
+}
+
+// wellKnownSchema describes the well known types which protojson writes in a special form.
+func wellKnownSchema(name protoreflect.FullName) (*Schema, bool) {
+	switch name {
+	case "google.protobuf.Struct", "google.protobuf.Any", "google.protobuf.Empty":
+		return &Schema{Type: "object"}, true
+	case "google.protobuf.Value":
+		return &Schema{}, true
+	case "google.protobuf.ListValue":
+		return &Schema{Type: "array", Items: &Schema{}}, true
+	case "google.protobuf.Timestamp":
+		return &Schema{Type: "string", Format: "date-time"}, true
+	case "google.protobuf.Duration", "google.protobuf.FieldMask", "google.protobuf.StringValue":
+		return &Schema{Type: "string"}, true
+	case "google.protobuf.BytesValue":
+		return &Schema{Type: "string", Format: "byte"}, true
+	case "google.protobuf.BoolValue":
+		return &Schema{Type: "boolean"}, true
+	case "google.protobuf.Int32Value":
+		return &Schema{Type: "integer", Format: "int32"}, true
+	case "google.protobuf.UInt32Value":
+		return &Schema{Type: "integer", Format: "int64"}, true
+	case "google.protobuf.Int64Value", "google.protobuf.UInt64Value":
+		return &Schema{Type: "string", Format: "int64"}, true
+	case "google.protobuf.FloatValue":
+		return &Schema{Type: "number", Format: "float"}, true
+	case "google.protobuf.DoubleValue":
+		return &Schema{Type: "number", Format: "double"}, true
+	default:
+		return nil, false
+	}
+}
+
+// leadingComment returns the comment written above a definition, when source info was compiled in.
+func leadingComment(desc protoreflect.Descriptor) string {
+	return strings.TrimSpace(desc.ParentFile().SourceLocations().ByDescriptor(desc).LeadingComments)
+}
@@ -0,0 +1,85 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	armpb "go.viam.com/api/component/arm/v1"
+	robotpb "go.viam.com/api/robot/v1"
+	"go.viam.com/test"
+	"google.golang.org/grpc"
+)
+
+func TestGenerate(t *testing.T) {
+	services, err := ServiceDescriptors(&armpb.ArmService_ServiceDesc, &robotpb.RobotService_ServiceDesc)
+	test.That(t, err, test.ShouldBeNil)
+
+	doc := Generate(Options{Info: Info{Title: "test", Version: "1"}, StripPathPrefix: "/viam"}, services...)
+	test.That(t, doc.OpenAPI, test.ShouldEqual, Version)
+	test.That(t, doc.Tags, test.ShouldResemble, []Tag{{Name: "viam.component.arm.v1.ArmService"}, {Name: "viam.robot.v1.RobotService"}})
+
+	position, ok := doc.Paths["/api/v1/component/arm/{name}/position"]
+	test.That(t, ok, test.ShouldBeTrue)
+	get, ok := position["get"]
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, get.OperationID, test.ShouldEqual, "ArmService_GetEndPosition")
+	test.That(t, get.Parameters, test.ShouldHaveLength, 1)
+	test.That(t, get.Parameters[0], test.ShouldResemble, Parameter{Name: "name", In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	test.That(t, get.RequestBody, test.ShouldBeNil)
+	test.That(t, get.Responses["200"].Content[jsonMediaType].Schema.Ref, test.ShouldEqual,
+		"#/components/schemas/viam.component.arm.v1.GetEndPositionResponse")
+
+	// the fields of messages without a body binding are read from the query
+	move, ok := position["put"]
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, move.RequestBody, test.ShouldBeNil)
+	var queryNames []string
+	for _, param := range move.Parameters[1:] {
+		test.That(t, param.In, test.ShouldEqual, "query")
+		queryNames = append(queryNames, param.Name)
+	}
+	test.That(t, queryNames, test.ShouldResemble, []string{"to.x", "to.y", "to.z", "to.o_x", "to.o_y", "to.o_z", "to.theta"})
+
+	// nested messages are described once and referenced
+	resp := doc.Components.Schemas["viam.component.arm.v1.GetEndPositionResponse"]
+	test.That(t, resp, test.ShouldNotBeNil)
+	test.That(t, resp.Properties["pose"].Ref, test.ShouldEqual, "#/components/schemas/viam.common.v1.Pose")
+	test.That(t, doc.Components.Schemas["viam.common.v1.Pose"].Properties["x"], test.ShouldResemble,
+		&Schema{Type: "number", Format: "double"})
+	// requests read from the query have no schema of their own
+	_, ok = doc.Components.Schemas["viam.component.arm.v1.GetEndPositionRequest"]
+	test.That(t, ok, test.ShouldBeFalse)
+	joints := doc.Components.Schemas["viam.component.arm.v1.JointPositions"]
+	test.That(t, joints.Properties["values"], test.ShouldResemble,
+		&Schema{Type: "array", Items: &Schema{Type: "number", Format: "double"}})
+
+	// streamed responses are wrapped
+	var streamStatus *Operation
+	for _, ops := range doc.Paths {
+		for _, op := range ops {
+			if op.OperationID == "RobotService_StreamStatus" {
+				op := op
+				streamStatus = &op
+			}
+		}
+	}
+	test.That(t, streamStatus, test.ShouldNotBeNil)
+	test.That(t, streamStatus.Responses["200"].Content[jsonMediaType].Schema.Properties["result"].Ref, test.ShouldEqual,
+		"#/components/schemas/viam.robot.v1.StreamStatusResponse")
+
+	_, err = json.Marshal(doc)
+	test.That(t, err, test.ShouldBeNil)
+
+	_, err = ServiceDescriptors(&grpc.ServiceDesc{ServiceName: "not.a.Service"})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestOpenAPIPath(t *testing.T) {
+	path, params := openAPIPath("/v1/{parent=things/*}/items/{id}")
+	test.That(t, path, test.ShouldEqual, "/v1/{parent}/items/{id}")
+	test.That(t, params, test.ShouldResemble, []string{"parent", "id"})
+
+	path, params = openAPIPath("/v1/status")
+	test.That(t, path, test.ShouldEqual, "/v1/status")
+	test.That(t, params, test.ShouldBeEmpty)
+}
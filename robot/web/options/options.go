@@ -12,6 +12,7 @@ import (
 	"go.viam.com/utils/rpc"
 
 	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/grpc"
 	"go.viam.com/rdk/utils"
 )
 
@@ -69,6 +70,11 @@ type Options struct {
 	WebRTCOnPeerRemoved func(pc *webrtc.PeerConnection)
 
 	DisableMulticastDNS bool
+
+	// FaultInjector, if set, injects latency, errors and dropped streams into the calls the server handles so
+	// clients can be tested against a degraded connection. Servers with debug control enabled get one without faults
+	// if it is not set, whose faults can be changed with a PUT to /debug/faults.
+	FaultInjector *grpc.FaultInjector
 }
 
 // New returns a default set of options which will have the
@@ -0,0 +1,61 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+// handleReconfigurePreview serves what reconfiguring the robot with the config in the request body would change,
+// without applying it.
+func (svc *webService) handleReconfigurePreview(w http.ResponseWriter, r *http.Request) {
+	localRobot, isLocal := svc.r.(robot.LocalRobot)
+	if !isLocal {
+		http.NotFound(w, r)
+		return
+	}
+
+	cfg, err := config.LocalFromReader(r.Context(), "", r.Body, svc.logger)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	preview, err := localRobot.PreviewReconfigure(r.Context(), cfg)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	names := func(names []resource.Name) []string {
+		strs := []string{}
+		for _, name := range names {
+			strs = append(strs, name.String())
+		}
+		return strs
+	}
+	strs := func(strs []string) []string {
+		if strs == nil {
+			return []string{}
+		}
+		return strs
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string][]string{
+		"added":               names(preview.Added),
+		"removed":             names(preview.Removed),
+		"rebuilt":             names(preview.Rebuilt),
+		"reconfigured":        names(preview.Reconfigured),
+		"dependents":          names(preview.Dependents),
+		"modules_added":       strs(preview.ModulesAdded),
+		"modules_removed":     strs(preview.ModulesRemoved),
+		"modules_restarted":   strs(preview.ModulesRestarted),
+		"processes_added":     strs(preview.ProcessesAdded),
+		"processes_removed":   strs(preview.ProcessesRemoved),
+		"processes_restarted": strs(preview.ProcessesRestarted),
+	}); err != nil {
+		svc.logger.Errorw("failed to write reconfigure preview", "error", err)
+	}
+}
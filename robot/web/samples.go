@@ -0,0 +1,91 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/utils"
+	"goji.io/pat"
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+
+	"go.viam.com/rdk/components/movementsensor"
+)
+
+// A MovementSensorSample is a sample of a movement sensor pushed to subscribers of its stream as JSON. Angular
+// velocities are in degrees per second, and the orientation is a quaternion, left out for sensors which do not
+// measure one.
+type MovementSensorSample struct {
+	Time               time.Time   `json:"time"`
+	LinearAcceleration r3.Vector   `json:"linear_acceleration"`
+	AngularVelocity    r3.Vector   `json:"angular_velocity"`
+	Orientation        *Quaternion `json:"orientation,omitempty"`
+}
+
+// A Quaternion is an orientation in a MovementSensorSample.
+type Quaternion struct {
+	Real float64 `json:"real"`
+	I    float64 `json:"i"`
+	J    float64 `json:"j"`
+	K    float64 `json:"k"`
+}
+
+// handleMovementSensorSamples upgrades the request to a WebSocket and pushes the samples of a movement sensor to it
+// as JSON until the client goes away. Sensors which can stream their samples send every one they measure, and others
+// are polled at the rate_hz query parameter, defaulting to 100Hz. Samples are dropped rather than queued when the
+// client falls behind, since control loops want the latest sample rather than a backlog.
+func (svc *webService) handleMovementSensorSamples(w http.ResponseWriter, r *http.Request) {
+	ms, err := movementsensor.FromRobot(svc.r, pat.Param(r, "movement_sensor"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	var rateHz float64
+	if raw := r.URL.Query().Get("rate_hz"); raw != "" {
+		if rateHz, err = strconv.ParseFloat(raw, 64); err != nil || rateHz <= 0 || rateHz > movementsensor.MaxSampleRateHz {
+			http.Error(w, "rate_hz must be a number above 0 and at most 1000", http.StatusBadRequest)
+			return
+		}
+	}
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		svc.logger.Debugw("failed to accept movement sensor stream subscriber", "error", err)
+		return
+	}
+	defer func() {
+		utils.UncheckedError(conn.Close(websocket.StatusNormalClosure, ""))
+	}()
+
+	// subscribers only listen, so reading is only needed to notice when they go away
+	ctx := conn.CloseRead(r.Context())
+	samples, err := movementsensor.StreamSamples(ctx, ms, rateHz, nil)
+	if err != nil {
+		utils.UncheckedError(conn.Close(websocket.StatusInternalError, err.Error()))
+		return
+	}
+	for sample := range samples {
+		if err := writeSample(ctx, conn, sample); err != nil {
+			svc.logger.Debugw("movement sensor stream ended", "movement_sensor", ms.Name(), "error", err)
+			return
+		}
+	}
+}
+
+func writeSample(ctx context.Context, conn *websocket.Conn, sample movementsensor.Sample) error {
+	msg := MovementSensorSample{
+		Time:               sample.Time,
+		LinearAcceleration: sample.LinearAcceleration,
+		AngularVelocity:    r3.Vector(sample.AngularVelocity),
+	}
+	if sample.Orientation != nil {
+		q := sample.Orientation.Quaternion()
+		msg.Orientation = &Quaternion{Real: q.Real, I: q.Imag, J: q.Jmag, K: q.Kmag}
+	}
+	ctx, cancel := context.WithTimeout(ctx, eventWriteTimeout)
+	defer cancel()
+	return wsjson.Write(ctx, conn, msg)
+}
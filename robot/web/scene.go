@@ -0,0 +1,64 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/robot/framesystem"
+)
+
+// handleScene serves the machine's world as a framesystem.Scene, so that external viewers and the control UI render
+// the same frames, geometries and bounds. The format query parameter picks JSON, the default, or glTF. Frames which
+// move are placed at their current inputs, or at their zero inputs if those cannot be read.
+func (svc *webService) handleScene(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "gltf" {
+		http.Error(w, `format must be "json" or "gltf"`, http.StatusBadRequest)
+		return
+	}
+
+	fsCfg, err := svc.r.FrameSystemConfig(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fs, err := referenceframe.NewFrameSystem(framesystem.LocalFrameSystemName, fsCfg.Parts, fsCfg.AdditionalTransforms)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	inputs := referenceframe.StartPositions(fs)
+	if res, err := svc.r.ResourceByName(framesystem.InternalServiceName); err == nil {
+		if fsSvc, ok := res.(framesystem.Service); ok {
+			current, _, err := fsSvc.CurrentInputs(r.Context())
+			if err != nil {
+				svc.logger.Debugw("failed to get the current inputs of the frame system, drawing the scene at zero inputs", "error", err)
+			} else {
+				inputs = current
+			}
+		}
+	}
+	scene, err := framesystem.NewScene(fs, inputs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if format == "gltf" {
+		data, err := scene.GLTF()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "model/gltf+json")
+		if _, err := w.Write(data); err != nil {
+			svc.logger.Debugw("failed to write scene", "error", err)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(scene); err != nil {
+		svc.logger.Debugw("failed to write scene", "error", err)
+	}
+}
@@ -0,0 +1,53 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+// handleSimulated serves the names of the components which are swapped for their fakes. A POST with a JSON body of
+// the fully qualified name of a component as resource and a boolean enabled swaps it for its fake or back to its real
+// driver.
+func (svc *webService) handleSimulated(w http.ResponseWriter, r *http.Request) {
+	localRobot, isLocal := svc.r.(robot.LocalRobot)
+	if !isLocal {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		var req struct {
+			Resource string `json:"resource"`
+			Enabled  *bool  `json:"enabled"`
+		}
+		if err := decodeJSONBody(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		name, err := resource.NewFromString(req.Resource)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Enabled == nil {
+			http.Error(w, "enabled must be true or false", http.StatusBadRequest)
+			return
+		}
+		if err := localRobot.SetSimulated(r.Context(), name, *req.Enabled); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	simulated := []string{}
+	for _, name := range localRobot.Simulated() {
+		simulated = append(simulated, name.String())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string][]string{"simulated": simulated}); err != nil {
+		svc.logger.Errorw("failed to write simulated components", "error", err)
+	}
+}
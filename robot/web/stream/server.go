@@ -224,6 +224,11 @@ func (ss *Server) AddStream(ctx context.Context, req *streampb.AddStreamRequest)
 			ss.logger.Error(err.Error())
 			return nil, err
 		}
+		// the video adapts to the peer's network from the feedback on its track, read until the track is removed
+		if feedbackStream, ok := streamStateToAdd.Stream.(gostream.FeedbackStream); ok {
+			sender := ps.senders[len(ps.senders)-1]
+			utils.PanicCapturingGo(func() { feedbackStream.ReadRTCP(sender) })
+		}
 	}
 	// if the stream supports audio, add the audio track
 	if trackLocal, haveTrackLocal := streamStateToAdd.Stream.AudioTrackLocal(); haveTrackLocal {
@@ -0,0 +1,49 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.viam.com/rdk/robot"
+)
+
+// handleTagged serves the fully qualified names of the resources tagged with the tag query parameter. A POST with a
+// JSON body of the tag as tag stops every actuator among them.
+func (svc *webService) handleTagged(w http.ResponseWriter, r *http.Request) {
+	localRobot, isLocal := svc.r.(robot.LocalRobot)
+	if !isLocal {
+		http.NotFound(w, r)
+		return
+	}
+
+	tag := r.URL.Query().Get("tag")
+	if r.Method == http.MethodPost {
+		var req struct {
+			Tag string `json:"tag"`
+		}
+		if err := decodeJSONBody(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		tag = req.Tag
+	}
+	if tag == "" {
+		http.Error(w, "tag is required", http.StatusBadRequest)
+		return
+	}
+	if r.Method == http.MethodPost {
+		if err := localRobot.StopTagged(r.Context(), tag, nil); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	tagged := []string{}
+	for _, name := range robot.NamesByTag(localRobot, tag) {
+		tagged = append(tagged, name.String())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string][]string{"resources": tagged}); err != nil {
+		svc.logger.Errorw("failed to write tagged resources", "error", err)
+	}
+}
@@ -193,6 +193,9 @@ type Service interface {
 
 	// Returns the unix socket path the module server listens on.
 	ModuleAddress() string
+
+	// PublishEvent pushes an event, such as an alert, to the subscribers of the event feed.
+	PublishEvent(Event)
 }
 
 var internalWebServiceName = resource.NewName(
@@ -311,7 +314,8 @@ func (svc *webService) StartModule(ctx context.Context) error {
 		streamInterceptors []googlegrpc.StreamServerInterceptor
 	)
 
-	unaryInterceptors = append(unaryInterceptors, grpc.EnsureTimeoutUnaryInterceptor)
+	unaryInterceptors = append(unaryInterceptors,
+		grpc.EnsureTimeoutUnaryInterceptor, grpc.FieldMaskUnaryServerInterceptor, svc.extraParamsUnaryServerInterceptor())
 
 	opManager := svc.r.OperationManager()
 	unaryInterceptors = append(unaryInterceptors,
@@ -360,6 +364,8 @@ func (svc *webService) refreshResources() error {
 }
 
 func (svc *webService) updateResources(resources map[resource.Name]resource.Resource) error {
+	svc.events.updateResources(resources)
+
 	// so group resources by API
 	groupedResources := make(map[resource.API]map[resource.Name]resource.Resource)
 	for n, v := range resources {
@@ -421,6 +427,10 @@ func (svc *webService) stopWeb() {
 	if svc.cancelFunc != nil {
 		svc.cancelFunc()
 	}
+	if svc.stopWatchingOperations != nil {
+		svc.stopWatchingOperations()
+		svc.stopWatchingOperations = nil
+	}
 	svc.isRunning = false
 	svc.webWorkers.Wait()
 }
@@ -502,6 +512,10 @@ func (svc *webService) runWeb(ctx context.Context, options weboptions.Options) (
 		}
 	}
 
+	if options.Network.EnableDebugControl && options.FaultInjector == nil {
+		options.FaultInjector = grpc.NewFaultInjector()
+	}
+
 	rpcOpts, err := svc.initRPCOptions(listenerTCPAddr, options)
 	if err != nil {
 		return err
@@ -528,6 +542,7 @@ func (svc *webService) runWeb(ctx context.Context, options weboptions.Options) (
 	if err := svc.refreshResources(); err != nil {
 		return err
 	}
+	svc.stopWatchingOperations = svc.events.watchOperations(svc.r.OperationManager())
 	if err := svc.initAPIResourceCollections(ctx, false); err != nil {
 		return err
 	}
@@ -639,6 +654,13 @@ func (svc *webService) initRPCOptions(listenerTCPAddr *net.TCPAddr, options webo
 	var unaryInterceptors []googlegrpc.UnaryServerInterceptor
 
 	unaryInterceptors = append(unaryInterceptors, grpc.EnsureTimeoutUnaryInterceptor)
+	streamInterceptors := []googlegrpc.StreamServerInterceptor{}
+	if options.FaultInjector != nil {
+		unaryInterceptors = append(unaryInterceptors, options.FaultInjector.UnaryServerInterceptor)
+		streamInterceptors = append(streamInterceptors, options.FaultInjector.StreamServerInterceptor)
+	}
+	unaryInterceptors = append(unaryInterceptors, grpc.FieldMaskUnaryServerInterceptor, svc.extraParamsUnaryServerInterceptor())
+	streamInterceptors = append(streamInterceptors, grpc.FieldMaskStreamServerInterceptor)
 
 	if options.Debug {
 		rpcOpts = append(rpcOpts, rpc.WithDebug())
@@ -665,8 +687,6 @@ func (svc *webService) initRPCOptions(listenerTCPAddr *net.TCPAddr, options webo
 	}
 	rpcOpts = append(rpcOpts, authOpts...)
 
-	var streamInterceptors []googlegrpc.StreamServerInterceptor
-
 	opManager := svc.r.OperationManager()
 	sessManagerInts := svc.r.SessionManager().ServerInterceptors()
 	if sessManagerInts.UnaryServerInterceptor != nil {
@@ -896,7 +916,34 @@ func (svc *webService) initMux(options weboptions.Options) (*goji.Mux, error) {
 	// TODO: accept params to display different formats
 	mux.HandleFunc(pat.New("/debug/graph"), svc.handleVisualizeResourceGraph)
 
-	prefix := "/viam"
+	// serve asset metadata of the machine and its resources, and the diagnostics which include it
+	mux.HandleFunc(pat.Get("/debug/inventory"), apiKeyAuth(options, "debug", svc.handleInventory))
+	mux.HandleFunc(pat.Get("/debug/diagnostics"), apiKeyAuth(options, "debug", svc.handleDiagnostics))
+
+	// list and toggle components swapped for their fakes
+	mux.HandleFunc(pat.Get("/debug/simulated"), apiKeyAuth(options, "debug", svc.handleSimulated))
+	if options.Network.EnableDebugControl {
+		mux.HandleFunc(pat.Post("/debug/simulated"), apiKeyAuth(options, "debug", svc.handleSimulated))
+	}
+
+	// list and stop the resources with a tag
+	mux.HandleFunc(pat.Get("/debug/tagged"), apiKeyAuth(options, "debug", svc.handleTagged))
+	if options.Network.EnableDebugControl {
+		mux.HandleFunc(pat.Post("/debug/tagged/stop"), apiKeyAuth(options, "debug", svc.handleTagged))
+	}
+
+	// preview what reconfiguring with a config would change
+	mux.HandleFunc(pat.Post("/debug/reconfigure/preview"), apiKeyAuth(options, "debug", svc.handleReconfigurePreview))
+
+	// list and change the faults injected into gRPC calls
+	if options.FaultInjector != nil {
+		mux.HandleFunc(pat.Get("/debug/faults"), apiKeyAuth(options, "debug", svc.handleFaults(options.FaultInjector)))
+		if options.Network.EnableDebugControl {
+			mux.HandleFunc(pat.Put("/debug/faults"), apiKeyAuth(options, "debug", svc.handleFaults(options.FaultInjector)))
+		}
+	}
+
+	prefix := gatewayPathPrefix
 	addPrefix := func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			p := prefix + r.URL.Path
@@ -915,9 +962,29 @@ func (svc *webService) initMux(options weboptions.Options) (*goji.Mux, error) {
 		})
 	}
 
-	// for urls with /api, add /viam to the path so that it matches with the paths defined in protobuf.
 	corsHandler := cors.AllowAll()
-	mux.Handle(pat.New("/api/*"), corsHandler.Handler(addPrefix(svc.rpcServer.GatewayHandler())))
+
+	// serve the extra parameters each resource accepts
+	mux.Handle(pat.Get("/api/extra_params"), corsHandler.Handler(apiKeyAuth(options, "extra params", svc.handleExtraParams)))
+
+	// serve the machine's frames and geometries for viewers
+	mux.Handle(pat.Get("/scene"), corsHandler.Handler(apiKeyAuth(options, "scene", svc.handleScene)))
+
+	// for urls with /api, add /viam to the path so that it matches with the paths defined in protobuf.
+	if !options.Network.DisableRESTGateway {
+		mux.Handle(pat.Get("/api/openapi.json"), corsHandler.Handler(http.HandlerFunc(svc.handleOpenAPI)))
+		mux.Handle(pat.New("/api/*"), corsHandler.Handler(addPrefix(svc.rpcServer.GatewayHandler())))
+	}
+	if options.Network.EnableEventFeed {
+		mux.HandleFunc(pat.Get("/events"), apiKeyAuth(options, "events", svc.handleEvents(options.Network.EventFeedOrigins)))
+	}
+	if options.Network.EnableMJPEG {
+		svc.installMJPEG(mux, options)
+	}
+	if options.Network.EnableMovementSensorStreams {
+		mux.HandleFunc(pat.Get("/movement_sensor/:movement_sensor/samples"),
+			apiKeyAuth(options, "movement sensors", svc.handleMovementSensorSamples))
+	}
 	mux.Handle(pat.New("/*"), corsHandler.Handler(svc.rpcServer.GRPCHandler()))
 
 	return mux, nil
@@ -52,6 +52,7 @@ func New(r robot.Robot, logger logging.Logger, opts ...Option) Service {
 		opts:         wOpts,
 		videoSources: map[string]gostream.HotSwappableVideoSource{},
 		audioSources: map[string]gostream.HotSwappableAudioSource{},
+		events:       newEventFeed(),
 	}
 	return webSvc
 }
@@ -75,6 +76,9 @@ type webService struct {
 	webWorkers   sync.WaitGroup
 	modWorkers   sync.WaitGroup
 
+	events                 *eventFeed
+	stopWatchingOperations func()
+
 	videoSources map[string]gostream.HotSwappableVideoSource
 	audioSources map[string]gostream.HotSwappableAudioSource
 }
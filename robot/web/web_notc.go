@@ -11,6 +11,7 @@ import (
 	"go.viam.com/rdk/robot"
 	weboptions "go.viam.com/rdk/robot/web/options"
 	"go.viam.com/utils/rpc"
+	"goji.io"
 )
 
 // New returns a new web service for the given robot.
@@ -26,6 +27,7 @@ func New(r robot.Robot, logger logging.Logger, opts ...Option) Service {
 		rpcServer: nil,
 		services:  map[resource.API]resource.APIResourceCollection[resource.Resource]{},
 		opts:      wOpts,
+		events:    newEventFeed(),
 	}
 	return webSvc
 }
@@ -47,6 +49,9 @@ type webService struct {
 	isRunning  bool
 	webWorkers sync.WaitGroup
 	modWorkers sync.WaitGroup
+
+	events                 *eventFeed
+	stopWatchingOperations func()
 }
 
 // Update updates the web service when the robot has changed.
@@ -67,5 +72,10 @@ func (svc *webService) initStreamServer(ctx context.Context, options *weboptions
 	return nil
 }
 
+// stub implementation when gostream not available
+func (svc *webService) installMJPEG(mux *goji.Mux, options weboptions.Options) {
+	svc.logger.Warn("MJPEG streams are not available in this build")
+}
+
 // stub for missing gostream
 type options struct{}
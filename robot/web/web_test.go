@@ -6,8 +6,15 @@ import (
 	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"image"
+	"image/jpeg"
+	"mime"
+	"mime/multipart"
 	"net"
+	"net/http"
 	"os"
 	"testing"
 	"time"
@@ -29,10 +36,13 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
 
 	"go.viam.com/rdk/components/arm"
 	"go.viam.com/rdk/components/audioinput"
 	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/movementsensor"
 	"go.viam.com/rdk/config"
 	gizmopb "go.viam.com/rdk/examples/customresources/apis/proto/api/component/gizmo/v1"
 	"go.viam.com/rdk/gostream"
@@ -40,6 +50,8 @@ import (
 	"go.viam.com/rdk/gostream/codec/x264"
 	rgrpc "go.viam.com/rdk/grpc"
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/operation"
+	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot"
 	"go.viam.com/rdk/robot/framesystem"
@@ -1409,3 +1421,381 @@ func signJWKBasedExternalAccessToken(
 
 	return token.SignedString(key)
 }
+
+func TestEventFeed(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx, injectRobot := setupRobotCtx(t)
+
+	svc := web.New(injectRobot, logger)
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	options.Network.EnableEventFeed = true
+	apiKeyID := uuid.New().String()
+	apiKey := utils.RandomAlphaString(32)
+	options.Auth.Handlers = []config.AuthHandlerConfig{
+		{
+			Type:   rpc.CredentialsTypeAPIKey,
+			Config: rutils.AttributeMap{apiKeyID: apiKey, "keys": []string{apiKeyID}},
+		},
+	}
+	err := svc.Start(ctx, options)
+	test.That(t, err, test.ShouldBeNil)
+
+	_, resp, err := websocket.Dial(ctx, "ws://"+addr+"/events", nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusUnauthorized)
+
+	keyParams := "api_key_id=" + apiKeyID + "&api_key=" + apiKey
+	conn, _, err := websocket.Dial(ctx, "ws://"+addr+"/events?"+keyParams, nil)
+	test.That(t, err, test.ShouldBeNil)
+	alerts, _, err := websocket.Dial(ctx, "ws://"+addr+"/events?types=alert&"+keyParams, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	readEvent := func(c *websocket.Conn) web.Event {
+		var event web.Event
+		test.That(t, wsjson.Read(ctx, c, &event), test.ShouldBeNil)
+		return event
+	}
+
+	event := readEvent(conn)
+	test.That(t, event.Type, test.ShouldEqual, web.EventSnapshot)
+	test.That(t, event.Data["resources"], test.ShouldResemble, []interface{}{arm.Named(arm1String).String()})
+	event = readEvent(alerts)
+	test.That(t, event.Type, test.ShouldEqual, web.EventSnapshot)
+
+	opCtx, done := injectRobot.OperationManager().Create(ctx, "/viam.component.arm.v1.ArmService/Stop", nil)
+	done()
+	event = readEvent(conn)
+	test.That(t, event.Type, test.ShouldEqual, web.EventOperationStarted)
+	test.That(t, event.Data["method"], test.ShouldEqual, "/viam.component.arm.v1.ArmService/Stop")
+	test.That(t, event.Data["id"], test.ShouldEqual, operation.Get(opCtx).ID.String())
+	event = readEvent(conn)
+	test.That(t, event.Type, test.ShouldEqual, web.EventOperationFinished)
+
+	name := arm.Named(arm1String)
+	svc.PublishEvent(web.NewAlert(&name, "error", "arm is on fire"))
+	for _, c := range []*websocket.Conn{conn, alerts} {
+		event = readEvent(c)
+		test.That(t, event.Type, test.ShouldEqual, web.EventAlert)
+		test.That(t, event.Resource, test.ShouldEqual, name.String())
+		test.That(t, event.Data["message"], test.ShouldEqual, "arm is on fire")
+	}
+
+	test.That(t, svc.Reconfigure(ctx, resource.Dependencies{}, resource.Config{}), test.ShouldBeNil)
+	event = readEvent(conn)
+	test.That(t, event.Type, test.ShouldEqual, web.EventResourceRemoved)
+	test.That(t, event.Resource, test.ShouldEqual, name.String())
+
+	test.That(t, conn.Close(websocket.StatusNormalClosure, ""), test.ShouldBeNil)
+	test.That(t, alerts.Close(websocket.StatusNormalClosure, ""), test.ShouldBeNil)
+	test.That(t, svc.Close(context.Background()), test.ShouldBeNil)
+
+	// the feed is only served when enabled
+	svc = web.New(injectRobot, logger)
+	options, _, addr = robottestutils.CreateBaseOptionsAndListener(t)
+	test.That(t, svc.Start(ctx, options), test.ShouldBeNil)
+	_, _, err = websocket.Dial(ctx, "ws://"+addr+"/events", nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, svc.Close(context.Background()), test.ShouldBeNil)
+}
+
+func TestMJPEG(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	injectRobot := &inject.Robot{}
+	cam := &inject.Camera{
+		PropertiesFunc: func(ctx context.Context) (camera.Properties, error) {
+			return camera.Properties{}, nil
+		},
+		StreamFunc: func(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error) {
+			return gostream.NewEmbeddedVideoStreamFromReader(gostream.VideoReaderFunc(func(ctx context.Context) (image.Image, func(), error) {
+				return image.NewRGBA(image.Rect(0, 0, 8, 4)), func() {}, nil
+			})), nil
+		},
+	}
+	injectRobot.MockResourcesFromMap(map[resource.Name]resource.Resource{camera.Named("camera1"): cam})
+	injectRobot.LoggerFunc = func() logging.Logger { return logger }
+
+	svc := web.New(injectRobot, logger)
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	options.Network.EnableMJPEG = true
+	apiKeyID := uuid.New().String()
+	apiKey := utils.RandomAlphaString(32)
+	options.Auth.Handlers = []config.AuthHandlerConfig{
+		{
+			Type:   rpc.CredentialsTypeAPIKey,
+			Config: rutils.AttributeMap{apiKeyID: apiKey, "keys": []string{apiKeyID}},
+		},
+	}
+	test.That(t, svc.Start(ctx, options), test.ShouldBeNil)
+	defer func() {
+		test.That(t, svc.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	get := func(path string, withKey bool) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+path, nil)
+		test.That(t, err, test.ShouldBeNil)
+		if withKey {
+			req.SetBasicAuth(apiKeyID, apiKey)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		test.That(t, err, test.ShouldBeNil)
+		return resp
+	}
+
+	resp := get("/mjpeg/camera1/snapshot", false)
+	test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusUnauthorized)
+	test.That(t, resp.Body.Close(), test.ShouldBeNil)
+	resp = get("/mjpeg/camera1/snapshot?api_key_id="+apiKeyID+"&api_key=wrong", false)
+	test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusUnauthorized)
+	test.That(t, resp.Body.Close(), test.ShouldBeNil)
+
+	resp = get("/mjpeg/camera1/snapshot?api_key_id="+apiKeyID+"&api_key="+apiKey, false)
+	test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusOK)
+	test.That(t, resp.Header.Get("Content-Type"), test.ShouldEqual, rutils.MimeTypeJPEG)
+	img, err := jpeg.Decode(resp.Body)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, img.Bounds().Dx(), test.ShouldEqual, 8)
+	test.That(t, resp.Body.Close(), test.ShouldBeNil)
+
+	resp = get("/mjpeg/nope/snapshot", true)
+	test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusNotFound)
+	test.That(t, resp.Body.Close(), test.ShouldBeNil)
+
+	resp = get("/mjpeg/camera1?fps=30", true)
+	test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusOK)
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, mediaType, test.ShouldEqual, "multipart/x-mixed-replace")
+	frames := multipart.NewReader(resp.Body, params["boundary"])
+	for i := 0; i < 2; i++ {
+		part, err := frames.NextPart()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, part.Header.Get("Content-Type"), test.ShouldEqual, rutils.MimeTypeJPEG)
+		img, err := jpeg.Decode(part)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, img.Bounds().Dy(), test.ShouldEqual, 4)
+	}
+	test.That(t, resp.Body.Close(), test.ShouldBeNil)
+
+	resp = get("/mjpeg/camera1?fps=1000", true)
+	test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusBadRequest)
+	test.That(t, resp.Body.Close(), test.ShouldBeNil)
+}
+
+func TestMovementSensorSamples(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	injectRobot := &inject.Robot{}
+	imu := inject.NewMovementSensor("imu1")
+	imu.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+		return &movementsensor.Properties{AngularVelocitySupported: true, OrientationSupported: true}, nil
+	}
+	imu.AngularVelocityFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+		return spatialmath.AngularVelocity{Z: 90}, nil
+	}
+	imu.OrientationFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+		return spatialmath.NewZeroOrientation(), nil
+	}
+	injectRobot.MockResourcesFromMap(map[resource.Name]resource.Resource{movementsensor.Named("imu1"): imu})
+	injectRobot.LoggerFunc = func() logging.Logger { return logger }
+
+	svc := web.New(injectRobot, logger)
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	options.Network.EnableMovementSensorStreams = true
+	test.That(t, svc.Start(ctx, options), test.ShouldBeNil)
+	defer func() {
+		test.That(t, svc.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	conn, _, err := websocket.Dial(ctx, "ws://"+addr+"/movement_sensor/imu1/samples?rate_hz=200", nil)
+	test.That(t, err, test.ShouldBeNil)
+	var previous web.MovementSensorSample
+	for i := 0; i < 3; i++ {
+		var sample web.MovementSensorSample
+		test.That(t, wsjson.Read(ctx, conn, &sample), test.ShouldBeNil)
+		test.That(t, sample.AngularVelocity.Z, test.ShouldEqual, 90)
+		test.That(t, sample.Orientation, test.ShouldResemble, &web.Quaternion{Real: 1})
+		test.That(t, sample.Time.After(previous.Time), test.ShouldBeTrue)
+		previous = sample
+	}
+	test.That(t, conn.Close(websocket.StatusNormalClosure, ""), test.ShouldBeNil)
+
+	_, resp, err := websocket.Dial(ctx, "ws://"+addr+"/movement_sensor/nope/samples", nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusNotFound)
+	_, resp, err = websocket.Dial(ctx, "ws://"+addr+"/movement_sensor/imu1/samples?rate_hz=5000", nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusBadRequest)
+}
+
+func TestScene(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	lif, err := (&referenceframe.LinkConfig{
+		ID:       "table",
+		Parent:   referenceframe.World,
+		Geometry: &spatialmath.GeometryConfig{Type: spatialmath.BoxType, X: 1000, Y: 500, Z: 20},
+	}).ParseConfig()
+	test.That(t, err, test.ShouldBeNil)
+	injectRobot := &inject.Robot{}
+	injectRobot.MockResourcesFromMap(nil)
+	injectRobot.LoggerFunc = func() logging.Logger { return logger }
+	injectRobot.FrameSystemConfigFunc = func(ctx context.Context) (*framesystem.Config, error) {
+		return &framesystem.Config{Parts: []*referenceframe.FrameSystemPart{{FrameConfig: lif}}}, nil
+	}
+
+	svc := web.New(injectRobot, logger)
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	test.That(t, svc.Start(ctx, options), test.ShouldBeNil)
+	defer func() {
+		test.That(t, svc.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	resp, err := http.Get("http://" + addr + "/scene")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusOK)
+	var scene framesystem.Scene
+	test.That(t, json.NewDecoder(resp.Body).Decode(&scene), test.ShouldBeNil)
+	test.That(t, resp.Body.Close(), test.ShouldBeNil)
+	test.That(t, scene.Frames, test.ShouldHaveLength, 2)
+	test.That(t, scene.Geometries, test.ShouldHaveLength, 1)
+	test.That(t, scene.Geometries[0].Frame, test.ShouldEqual, "table_origin")
+	test.That(t, scene.Bounds.Max, test.ShouldResemble, r3.Vector{X: 500, Y: 250, Z: 10})
+
+	resp, err = http.Get("http://" + addr + "/scene?format=gltf")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusOK)
+	test.That(t, resp.Header.Get("Content-Type"), test.ShouldEqual, "model/gltf+json")
+	var doc map[string]interface{}
+	test.That(t, json.NewDecoder(resp.Body).Decode(&doc), test.ShouldBeNil)
+	test.That(t, resp.Body.Close(), test.ShouldBeNil)
+	test.That(t, doc["meshes"], test.ShouldHaveLength, 1)
+
+	resp, err = http.Get("http://" + addr + "/scene?format=obj")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp.Body.Close(), test.ShouldBeNil)
+	test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusBadRequest)
+}
+
+func TestExtraParams(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	model := resource.DefaultModelFamily.WithModel("extra_params_test")
+	resource.RegisterComponent(arm.API, model, resource.Registration[arm.Arm, resource.NoNativeConfig]{
+		Constructor: func(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (arm.Arm, error) {
+			return nil, errors.New("not used")
+		},
+		ExtraParams: resource.ExtraParams{"MoveToPosition": {
+			{Name: "speed", Type: resource.ExtraParamNumber, Default: 10.},
+			{Name: "hosted", Type: resource.ExtraParamBool},
+		}},
+	})
+	defer resource.Deregister(arm.API, model)
+
+	var gotExtra map[string]interface{}
+	injectArm := &inject.Arm{}
+	injectArm.MoveToPositionFunc = func(ctx context.Context, to spatialmath.Pose, extra map[string]interface{}) error {
+		gotExtra = extra
+		return nil
+	}
+	injectRobot := &inject.Robot{}
+	injectRobot.ConfigFunc = func() *config.Config {
+		return &config.Config{Components: []resource.Config{{Name: arm1String, API: arm.API, Model: model}}}
+	}
+	injectRobot.ResourceNamesFunc = func() []resource.Name { return resources }
+	injectRobot.ResourceRPCAPIsFunc = func() []resource.RPCAPI { return nil }
+	injectRobot.ResourceByNameFunc = func(name resource.Name) (resource.Resource, error) { return injectArm, nil }
+	injectRobot.LoggerFunc = func() logging.Logger { return logger }
+	ctx := context.Background()
+
+	svc := web.New(injectRobot, logger)
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	test.That(t, svc.Start(ctx, options), test.ShouldBeNil)
+	conn, err := rgrpc.Dial(ctx, addr, logger)
+	test.That(t, err, test.ShouldBeNil)
+	arm1, err := arm.NewClientFromConn(ctx, conn, "", arm.Named(arm1String), logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	// defaults are filled in
+	test.That(t, arm1.MoveToPosition(ctx, pos, nil), test.ShouldBeNil)
+	test.That(t, gotExtra, test.ShouldResemble, map[string]interface{}{"speed": 10.})
+	test.That(t, arm1.MoveToPosition(ctx, pos, map[string]interface{}{"speed": 2., "hosted": true}), test.ShouldBeNil)
+	test.That(t, gotExtra, test.ShouldResemble, map[string]interface{}{"speed": 2., "hosted": true})
+
+	// unknown keys and values of the wrong type are refused before reaching the arm
+	gotExtra = nil
+	err = arm1.MoveToPosition(ctx, pos, map[string]interface{}{"sped": 2.})
+	test.That(t, status.Code(err), test.ShouldEqual, codes.InvalidArgument)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `unknown extra keys ["sped"]`)
+	err = arm1.MoveToPosition(ctx, pos, map[string]interface{}{"hosted": "yes"})
+	test.That(t, status.Code(err), test.ShouldEqual, codes.InvalidArgument)
+	test.That(t, gotExtra, test.ShouldBeNil)
+
+	// methods without declared params take any extra
+	injectArm.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		gotExtra = extra
+		return nil
+	}
+	test.That(t, arm1.Stop(ctx, map[string]interface{}{"anything": 1.}), test.ShouldBeNil)
+	test.That(t, gotExtra, test.ShouldResemble, map[string]interface{}{"anything": 1.})
+
+	resp, err := http.Get("http://" + addr + "/api/extra_params")
+	test.That(t, err, test.ShouldBeNil)
+	var declared map[string]map[string][]resource.ExtraParam
+	test.That(t, json.NewDecoder(resp.Body).Decode(&declared), test.ShouldBeNil)
+	test.That(t, resp.Body.Close(), test.ShouldBeNil)
+	test.That(t, declared, test.ShouldResemble, map[string]map[string][]resource.ExtraParam{
+		arm.Named(arm1String).String(): {"MoveToPosition": {
+			{Name: "speed", Type: resource.ExtraParamNumber, Default: 10.},
+			{Name: "hosted", Type: resource.ExtraParamBool},
+		}},
+	})
+
+	test.That(t, conn.Close(), test.ShouldBeNil)
+	test.That(t, svc.Close(ctx), test.ShouldBeNil)
+}
+
+func TestExtraParamsAuth(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	injectRobot := &inject.Robot{}
+	injectRobot.ConfigFunc = func() *config.Config { return &config.Config{} }
+	injectRobot.ResourceNamesFunc = func() []resource.Name { return nil }
+	injectRobot.ResourceRPCAPIsFunc = func() []resource.RPCAPI { return nil }
+	injectRobot.LoggerFunc = func() logging.Logger { return logger }
+
+	svc := web.New(injectRobot, logger)
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	apiKeyID := uuid.New().String()
+	apiKey := utils.RandomAlphaString(32)
+	options.Auth.Handlers = []config.AuthHandlerConfig{
+		{
+			Type:   rpc.CredentialsTypeAPIKey,
+			Config: rutils.AttributeMap{apiKeyID: apiKey, "keys": []string{apiKeyID}},
+		},
+	}
+	test.That(t, svc.Start(ctx, options), test.ShouldBeNil)
+	defer func() {
+		test.That(t, svc.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	for _, withKey := range []bool{false, true} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+"/api/extra_params", nil)
+		test.That(t, err, test.ShouldBeNil)
+		if withKey {
+			req.SetBasicAuth(apiKeyID, apiKey)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp.Body.Close(), test.ShouldBeNil)
+		if withKey {
+			test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusOK)
+		} else {
+			test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusUnauthorized)
+		}
+	}
+}
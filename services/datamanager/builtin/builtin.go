@@ -83,10 +83,78 @@ type Config struct {
 	MaximumNumSyncThreads       int      `json:"maximum_num_sync_threads"`
 	DeleteEveryNthWhenDiskFull  int      `json:"delete_every_nth_when_disk_full"`
 	MaximumCaptureFileSizeBytes int64    `json:"maximum_capture_file_size_bytes"`
+	// TaggedCapture captures a method of every resource with a tag, such as the readings of every sensor tagged
+	// "telemetry".
+	TaggedCapture []TaggedCaptureConfig `json:"tagged_capture,omitempty"`
+}
+
+// A TaggedCaptureConfig describes a method to capture from every resource tagged with ResourceTag. Resources whose
+// API has no collector for the method are skipped, as are methods already configured on a resource itself.
+type TaggedCaptureConfig struct {
+	ResourceTag        string            `json:"resource_tag"`
+	Method             string            `json:"method"`
+	CaptureFrequencyHz float32           `json:"capture_frequency_hz"`
+	AdditionalParams   map[string]string `json:"additional_params"`
+	Tags               []string          `json:"tags,omitempty"`
+}
+
+// LinkedTags returns the tags whose resources are captured, implementing resource.TagLinker.
+func (c *Config) LinkedTags() []string {
+	var tags []string
+	for _, capture := range c.TaggedCapture {
+		tags = append(tags, capture.ResourceTag)
+	}
+	return tags
+}
+
+// LinkTagged captures the methods configured for the tags of a resource, implementing resource.TagLinker.
+func (c *Config) LinkTagged(conf, tagged *resource.Config) {
+	name := tagged.ResourceName()
+	var methods []datamanager.DataCaptureConfig
+	if existing, ok := conf.AssociatedAttributes[name].(*datamanager.AssociatedConfig); ok {
+		methods = append(methods, existing.CaptureMethods...)
+	}
+	linked := len(methods)
+	for _, capture := range c.TaggedCapture {
+		if !tagged.HasTag(capture.ResourceTag) ||
+			data.CollectorLookup(data.MethodMetadata{API: name.API, MethodName: capture.Method}) == nil {
+			continue
+		}
+		configured := false
+		for _, method := range methods {
+			configured = configured || method.Method == capture.Method
+		}
+		if configured {
+			continue
+		}
+		methods = append(methods, datamanager.DataCaptureConfig{
+			Name:               name,
+			Method:             capture.Method,
+			CaptureFrequencyHz: capture.CaptureFrequencyHz,
+			AdditionalParams:   capture.AdditionalParams,
+			Tags:               capture.Tags,
+		})
+	}
+	if len(methods) == linked {
+		return
+	}
+	if conf.AssociatedAttributes == nil {
+		conf.AssociatedAttributes = make(map[resource.Name]resource.AssociatedConfig)
+	}
+	conf.AssociatedAttributes[name] = &datamanager.AssociatedConfig{CaptureMethods: methods}
 }
 
 // Validate returns components which will be depended upon weakly due to the above matcher.
 func (c *Config) Validate(path string) ([]string, error) {
+	for idx, capture := range c.TaggedCapture {
+		capturePath := fmt.Sprintf("%s.tagged_capture.%d", path, idx)
+		if capture.ResourceTag == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(capturePath, "resource_tag")
+		}
+		if capture.Method == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(capturePath, "method")
+		}
+	}
 	return []string{cloud.InternalServiceName.String()}, nil
 }
 
@@ -258,11 +326,6 @@ func getDurationFromHz(captureFrequencyHz float32) time.Duration {
 	return time.Duration(float32(time.Second) / captureFrequencyHz)
 }
 
-var metadataToAdditionalParamFields = map[string]string{
-	generateMetadataKey("rdk:component:board", "Analogs"): "reader_name",
-	generateMetadataKey("rdk:component:board", "Gpios"):   "pin_name",
-}
-
 // Initialize a collector for the component/method or update it if it has previously been created.
 // Return the component/method metadata which is used as a key in the collectors map.
 func (svc *builtIn) initializeOrUpdateCollector(
@@ -283,8 +346,6 @@ func (svc *builtIn) initializeOrUpdateCollector(
 		return nil, err
 	}
 
-	// TODO(DATA-451): validate method params
-
 	if storedCollectorAndConfig, ok := svc.collectors[md]; ok {
 		if storedCollectorAndConfig.Config.Equals(&config) &&
 			res == storedCollectorAndConfig.Resource &&
@@ -314,16 +375,11 @@ func (svc *builtIn) initializeOrUpdateCollector(
 	if captureBufferSize == 0 {
 		captureBufferSize = defaultCaptureBufferSize
 	}
-	additionalParamKey, ok := metadataToAdditionalParamFields[generateMetadataKey(
-		md.MethodMetadata.API.String(),
-		md.MethodMetadata.MethodName)]
-	if ok {
-		if _, ok := config.AdditionalParams[additionalParamKey]; !ok {
-			return nil, errors.Errorf("failed to validate additional_params for %s, must supply %s",
-				md.MethodMetadata.API.String(), additionalParamKey)
-		}
+	additionalParams, err := config.MethodParams()
+	if err != nil {
+		return nil, err
 	}
-	methodParams, err := protoutils.ConvertStringMapToAnyPBMap(config.AdditionalParams)
+	methodParams, err := protoutils.ConvertStringMapToAnyPBMap(additionalParams)
 	if err != nil {
 		return nil, err
 	}
@@ -755,10 +811,6 @@ func (svc *builtIn) updateDataCaptureConfigs(
 	return resourceCaptureConfigMap, nil
 }
 
-func generateMetadataKey(component, method string) string {
-	return fmt.Sprintf("%s/%s", component, method)
-}
-
 func pollFilesystem(ctx context.Context, wg *sync.WaitGroup, captureDir string,
 	deleteEveryNth int, syncer datasync.Manager, logger logging.Logger,
 ) {
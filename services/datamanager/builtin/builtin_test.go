@@ -3,10 +3,12 @@ package builtin
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"image"
 	"image/png"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -142,6 +144,58 @@ func TestEmptyConfig(t *testing.T) {
 	test.That(t, deps, test.ShouldBeNil)
 }
 
+func TestTaggedCapture(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	cfg, err := config.FromReader(context.Background(), "", strings.NewReader(`{
+		"components": [
+			{
+				"name": "arm1", "type": "arm", "model": "fake", "tags": ["telemetry"],
+				"service_configs": [
+					{"type": "data_manager", "attributes": {"capture_methods": [{"method": "EndPosition", "capture_frequency_hz": 5}]}}
+				]
+			},
+			{"name": "arm2", "type": "arm", "model": "fake", "tags": ["drive", "telemetry"]},
+			{"name": "arm3", "type": "arm", "model": "fake"},
+			{"name": "sensor1", "type": "sensor", "model": "fake", "tags": ["telemetry"]}
+		],
+		"services": [
+			{
+				"name": "data_manager", "type": "data_manager",
+				"attributes": {"tagged_capture": [
+					{"resource_tag": "telemetry", "method": "JointPositions", "capture_frequency_hz": 1},
+					{"resource_tag": "telemetry", "method": "EndPosition", "capture_frequency_hz": 2}
+				]}
+			}
+		]
+	}`), logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	var svcConf resource.Config
+	for _, conf := range cfg.Services {
+		if conf.API == datamanager.API {
+			svcConf = conf
+		}
+	}
+	captured := map[string][]string{}
+	for name, assocConf := range svcConf.AssociatedAttributes {
+		for _, method := range assocConf.(*datamanager.AssociatedConfig).CaptureMethods {
+			test.That(t, method.Name, test.ShouldResemble, name)
+			captured[name.ShortName()] = append(captured[name.ShortName()], fmt.Sprintf("%s@%v", method.Method, method.CaptureFrequencyHz))
+		}
+	}
+	// methods configured on a resource itself are kept, and sensors have no joint positions to capture
+	test.That(t, captured, test.ShouldResemble, map[string][]string{
+		"arm1": {"EndPosition@5", "JointPositions@1"},
+		"arm2": {"JointPositions@1", "EndPosition@2"},
+	})
+
+	test.That(t, svcConf.ConvertedAttributes.(*Config).LinkedTags(), test.ShouldResemble, []string{"telemetry", "telemetry"})
+
+	_, err = (&Config{TaggedCapture: []TaggedCaptureConfig{{Method: "Readings"}}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "resource_tag")
+}
+
 func TestUntrustedEnv(t *testing.T) {
 	dmsvc, r := newTestDataManager(t)
 	defer dmsvc.Close(context.Background())
@@ -7,8 +7,10 @@ import (
 	"reflect"
 	"slices"
 
+	"github.com/pkg/errors"
 	servicepb "go.viam.com/api/service/datamanager/v1"
 
+	"go.viam.com/rdk/data"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot"
 	"go.viam.com/rdk/utils"
@@ -130,6 +132,17 @@ func (ac *AssociatedConfig) Link(conf *resource.Config) {
 	conf.AssociatedAttributes[name] = &AssociatedConfig{CaptureMethods: captureMethodCopies}
 }
 
+// Validate ensures that each capture method and its additional params are accepted by the collectors registered for
+// the resource's API.
+func (ac *AssociatedConfig) Validate(path string) error {
+	for _, method := range ac.CaptureMethods {
+		if _, err := method.MethodParams(); err != nil {
+			return errors.Wrapf(err, "%s: invalid capture method config for %s", path, method.Name)
+		}
+	}
+	return nil
+}
+
 // DataCaptureConfig is used to initialize a collector for a component or remote.
 type DataCaptureConfig struct {
 	Name               resource.Name     `json:"name"`
@@ -156,6 +169,15 @@ func (c *DataCaptureConfig) Equals(other *DataCaptureConfig) bool {
 		c.CaptureDirectory == other.CaptureDirectory
 }
 
+// MethodParams returns the additional params of the capture method with the defaults of its collector filled in, or an
+// error if the method or its params are not accepted by the collectors registered for the resource's API.
+func (c *DataCaptureConfig) MethodParams() (map[string]string, error) {
+	if c.Method == "" {
+		return c.AdditionalParams, nil
+	}
+	return data.ValidateMethodParams(data.MethodMetadata{API: c.Name.API, MethodName: c.Method}, c.AdditionalParams)
+}
+
 // ShouldSyncKey is a special key we use within a modular sensor to pass a boolean
 // that indicates to the datamanager whether or not we want to sync.
 var ShouldSyncKey = "should_sync"
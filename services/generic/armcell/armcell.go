@@ -0,0 +1,377 @@
+//go:build !no_cgo
+
+// Package armcell implements a generic service which bundles an arm with the gripper and camera mounted on it, and
+// works them as one robot arm cell through task-level methods, so that an application need not orchestrate the
+// three itself. The gripper and camera are located through the frame system, and must be configured as children of
+// the arm, directly or through other frames.
+//
+// Tasks are run with DoCommand, with poses in millimeters and an orientation vector in degrees in the frame given,
+// or the world when none is:
+//
+//	{"command": "look_at", "pose": {"x": 400, "y": 0, "z": 0}}
+//	{"command": "pick_at", "pose": {"x": 400, "y": 0, "z": 20, "o_z": -1}}
+//	{"command": "place_at", "pose": {"x": 0, "y": 400, "z": 20, "o_z": -1}, "frame": "table"}
+//	{"command": "frames"}
+package armcell
+
+import (
+	"context"
+	"sync"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	pb "go.viam.com/api/service/motion/v1"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/gripper"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// Model is the model of the arm cell service.
+var Model = resource.DefaultModelFamily.WithModel("arm_cell")
+
+const defaultApproachDistanceMM = 100.
+
+func init() {
+	resource.RegisterService(generic.API, Model, resource.Registration[resource.Resource, *Config]{
+		Constructor: newCell,
+	})
+}
+
+// Config describes how to configure the service.
+type Config struct {
+	ArmName     string `json:"arm"`
+	GripperName string `json:"gripper"`
+	// CameraName is the camera look_at points. A cell without one can only pick and place.
+	CameraName string `json:"camera,omitempty"`
+	// MotionService plans the moves of the cell, and defaults to the builtin motion service.
+	MotionService string `json:"motion_service,omitempty"`
+	// ApproachDistanceMM is how far back along its z axis the gripper approaches and leaves a pose from, moving in a
+	// straight line between there and the pose. It defaults to 100.
+	ApproachDistanceMM float64 `json:"approach_distance_mm,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.ArmName == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "arm")
+	}
+	if conf.GripperName == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "gripper")
+	}
+	if conf.ApproachDistanceMM < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("approach_distance_mm cannot be negative"))
+	}
+	deps := []string{conf.ArmName, conf.GripperName, framesystem.InternalServiceName.String()}
+	if conf.CameraName != "" {
+		deps = append(deps, conf.CameraName)
+	}
+	return append(deps, motion.Named(conf.motionService()).String()), nil
+}
+
+func (conf *Config) motionService() string {
+	if conf.MotionService == "" {
+		return resource.DefaultServiceName
+	}
+	return conf.MotionService
+}
+
+// A Cell is an arm with a gripper and a camera mounted on it, worked as one resource.
+type Cell interface {
+	resource.Resource
+	resource.Actuator
+	// LookAt turns the camera to face a point, without moving it from where it is.
+	LookAt(ctx context.Context, point *referenceframe.PoseInFrame) error
+	// PickAt opens the gripper, moves it to a pose and grabs there, returning whether it grabbed something.
+	PickAt(ctx context.Context, pose *referenceframe.PoseInFrame) (bool, error)
+	// PlaceAt moves the gripper to a pose and opens it there.
+	PlaceAt(ctx context.Context, pose *referenceframe.PoseInFrame) error
+	// Frames returns the poses of the gripper and camera relative to the end of the arm.
+	Frames(ctx context.Context) (map[string]spatialmath.Pose, error)
+}
+
+type cell struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+
+	logger   logging.Logger
+	arm      arm.Arm
+	gripper  gripper.Gripper
+	camera   camera.Camera
+	fs       framesystem.Service
+	motion   motion.Service
+	approach float64
+
+	// mu runs one task at a time, since each moves the whole cell
+	mu sync.Mutex
+}
+
+func newCell(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (resource.Resource, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	c := &cell{
+		Named:    conf.ResourceName().AsNamed(),
+		logger:   logger,
+		approach: newConf.ApproachDistanceMM,
+	}
+	if c.approach == 0 {
+		c.approach = defaultApproachDistanceMM
+	}
+	if c.arm, err = arm.FromDependencies(deps, newConf.ArmName); err != nil {
+		return nil, err
+	}
+	if c.gripper, err = gripper.FromDependencies(deps, newConf.GripperName); err != nil {
+		return nil, err
+	}
+	if newConf.CameraName != "" {
+		if c.camera, err = camera.FromDependencies(deps, newConf.CameraName); err != nil {
+			return nil, err
+		}
+	}
+	if c.fs, err = framesystem.FromDependencies(deps); err != nil {
+		return nil, err
+	}
+	if c.motion, err = motion.FromDependencies(deps, newConf.motionService()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LookAt turns the camera to face a point, keeping the camera where it is.
+func (c *cell) LookAt(ctx context.Context, point *referenceframe.PoseInFrame) error {
+	if c.camera == nil {
+		return errors.New("the cell has no camera to look with")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.mountedOnArm(ctx, c.camera.Name().ShortName()); err != nil {
+		return err
+	}
+	target, err := c.fs.TransformPose(ctx, point, referenceframe.World, nil)
+	if err != nil {
+		return err
+	}
+	from, err := c.fs.TransformPose(ctx, referenceframe.NewPoseInFrame(c.camera.Name().ShortName(), spatialmath.NewZeroPose()),
+		referenceframe.World, nil)
+	if err != nil {
+		return err
+	}
+	view := target.Pose().Point().Sub(from.Pose().Point())
+	if view.Norm() == 0 {
+		return errors.New("cannot look at a point at the camera")
+	}
+	view = view.Normalize()
+	// the camera looks along its z axis
+	destination := spatialmath.NewPose(from.Pose().Point(), &spatialmath.OrientationVector{OX: view.X, OY: view.Y, OZ: view.Z})
+	_, err = c.motion.Move(ctx, c.camera.Name(), referenceframe.NewPoseInFrame(referenceframe.World, destination), nil, nil, nil)
+	return err
+}
+
+// PickAt opens the gripper, approaches the pose, grabs there and leaves the way it came.
+func (c *cell) PickAt(ctx context.Context, pose *referenceframe.PoseInFrame) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.gripper.Open(ctx, nil); err != nil {
+		return false, err
+	}
+	var grabbed bool
+	err := c.visit(ctx, pose, func() error {
+		var err error
+		grabbed, err = c.gripper.Grab(ctx, nil)
+		return err
+	})
+	return grabbed, err
+}
+
+// PlaceAt approaches the pose, opens the gripper there and leaves the way it came.
+func (c *cell) PlaceAt(ctx context.Context, pose *referenceframe.PoseInFrame) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.visit(ctx, pose, func() error {
+		return c.gripper.Open(ctx, nil)
+	})
+}
+
+// visit moves the gripper to the approach pose of a pose, straight on to the pose, does what is there to be done and
+// moves straight back to the approach pose.
+func (c *cell) visit(ctx context.Context, pose *referenceframe.PoseInFrame, do func() error) error {
+	if err := c.mountedOnArm(ctx, c.gripper.Name().ShortName()); err != nil {
+		return err
+	}
+	approach := referenceframe.NewPoseInFrame(
+		pose.Parent(),
+		spatialmath.Compose(pose.Pose(), spatialmath.NewPoseFromPoint(r3.Vector{Z: -c.approach})),
+	)
+	linear := &pb.Constraints{LinearConstraint: []*pb.LinearConstraint{{}}}
+	if err := c.move(ctx, approach, nil); err != nil {
+		return errors.Wrap(err, "cannot reach the approach pose")
+	}
+	if err := c.move(ctx, pose, linear); err != nil {
+		return err
+	}
+	if err := do(); err != nil {
+		return err
+	}
+	return errors.Wrap(c.move(ctx, approach, linear), "cannot leave the pose")
+}
+
+func (c *cell) move(ctx context.Context, destination *referenceframe.PoseInFrame, constraints *pb.Constraints) error {
+	_, err := c.motion.Move(ctx, c.gripper.Name(), destination, nil, constraints, nil)
+	return err
+}
+
+// mountedOnArm returns an error unless the named frame is a descendant of the arm in the frame system, so that the
+// arm can move it.
+func (c *cell) mountedOnArm(ctx context.Context, name string) error {
+	fs, err := c.fs.FrameSystem(ctx, nil)
+	if err != nil {
+		return err
+	}
+	frame := fs.Frame(name)
+	if frame == nil {
+		return errors.Errorf("%q is not in the frame system", name)
+	}
+	parents, err := fs.TracebackFrame(frame)
+	if err != nil {
+		return err
+	}
+	for _, parent := range parents {
+		if parent.Name() == c.arm.Name().ShortName() {
+			return nil
+		}
+	}
+	return errors.Errorf("%q is not mounted on the arm %q", name, c.arm.Name().ShortName())
+}
+
+// Frames returns the poses of the gripper and camera in the frame of the end of the arm.
+func (c *cell) Frames(ctx context.Context) (map[string]spatialmath.Pose, error) {
+	names := []string{c.gripper.Name().ShortName()}
+	if c.camera != nil {
+		names = append(names, c.camera.Name().ShortName())
+	}
+	frames := map[string]spatialmath.Pose{}
+	for _, name := range names {
+		pose, err := c.fs.TransformPose(ctx, referenceframe.NewPoseInFrame(name, spatialmath.NewZeroPose()),
+			c.arm.Name().ShortName(), nil)
+		if err != nil {
+			return nil, err
+		}
+		frames[name] = pose.Pose()
+	}
+	return frames, nil
+}
+
+// Stop stops the arm and gripper.
+func (c *cell) Stop(ctx context.Context, extra map[string]interface{}) error {
+	return multierr.Combine(c.arm.Stop(ctx, extra), c.gripper.Stop(ctx, extra))
+}
+
+// IsMoving returns whether the arm or gripper is moving.
+func (c *cell) IsMoving(ctx context.Context) (bool, error) {
+	for _, actuator := range []resource.Actuator{c.arm, c.gripper} {
+		moving, err := actuator.IsMoving(ctx)
+		if err != nil || moving {
+			return moving, err
+		}
+	}
+	return false, nil
+}
+
+// DoCommand runs the tasks of the cell.
+func (c *cell) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, _ := cmd["command"].(string)
+	switch name {
+	case "look_at", "pick_at", "place_at":
+		pose, err := poseFromCommand(cmd)
+		if err != nil {
+			return nil, err
+		}
+		switch name {
+		case "look_at":
+			return map[string]interface{}{}, c.LookAt(ctx, pose)
+		case "pick_at":
+			grabbed, err := c.PickAt(ctx, pose)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"grabbed": grabbed}, nil
+		default:
+			return map[string]interface{}{}, c.PlaceAt(ctx, pose)
+		}
+	case "frames":
+		frames, err := c.Frames(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp := map[string]interface{}{}
+		for name, pose := range frames {
+			resp[name] = poseToMap(pose)
+		}
+		return resp, nil
+	default:
+		return nil, errors.Errorf("unknown command %q", name)
+	}
+}
+
+// poseFromCommand parses the pose of a command from a map of x, y, z and an optional orientation vector in degrees,
+// in the frame of the command or the world.
+func poseFromCommand(cmd map[string]interface{}) (*referenceframe.PoseInFrame, error) {
+	m, ok := cmd["pose"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("pose must be a map of x, y, z and optionally o_x, o_y, o_z, theta")
+	}
+	var values [7]float64
+	for i, key := range []string{"x", "y", "z", "o_x", "o_y", "o_z", "theta"} {
+		raw, ok := m[key]
+		if !ok {
+			if i < 3 {
+				return nil, errors.New("pose needs x, y and z")
+			}
+			continue
+		}
+		if values[i], ok = raw.(float64); !ok {
+			return nil, errors.Errorf("pose %s must be a number", key)
+		}
+	}
+	point := r3.Vector{X: values[0], Y: values[1], Z: values[2]}
+	orientation := &spatialmath.OrientationVectorDegrees{OX: values[3], OY: values[4], OZ: values[5], Theta: values[6]}
+	if values[3] == 0 && values[4] == 0 && values[5] == 0 {
+		orientation.OZ = 1
+	}
+	frame := referenceframe.World
+	if raw, ok := cmd["frame"]; ok {
+		if frame, ok = raw.(string); !ok {
+			return nil, errors.New("frame must be a string")
+		}
+	}
+	return referenceframe.NewPoseInFrame(frame, spatialmath.NewPose(point, orientation)), nil
+}
+
+func poseToMap(pose spatialmath.Pose) map[string]interface{} {
+	orientation := pose.Orientation().OrientationVectorDegrees()
+	return map[string]interface{}{
+		"x":     pose.Point().X,
+		"y":     pose.Point().Y,
+		"z":     pose.Point().Z,
+		"o_x":   orientation.OX,
+		"o_y":   orientation.OY,
+		"o_z":   orientation.OZ,
+		"theta": orientation.Theta,
+	}
+}
@@ -0,0 +1,199 @@
+//go:build !no_cgo
+
+package armcell
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	pb "go.viam.com/api/service/motion/v1"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+type move struct {
+	component string
+	pose      spatialmath.Pose
+	linear    bool
+}
+
+// newFrameSystem returns a frame system with the end of an arm 500mm above the world, and a table 500mm along x. The
+// gripper is mounted on the arm, and the camera on the arm or, when it is not, on the table.
+func newFrameSystem(t *testing.T, cameraOnArm bool) referenceframe.FrameSystem {
+	t.Helper()
+	fs := referenceframe.NewEmptyFrameSystem("")
+	add := func(name string, point r3.Vector, parent string) {
+		frame, err := referenceframe.NewStaticFrame(name, spatialmath.NewPoseFromPoint(point))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, fs.AddFrame(frame, fs.Frame(parent)), test.ShouldBeNil)
+	}
+	add("arm", r3.Vector{Z: 500}, referenceframe.World)
+	add("gripper", r3.Vector{Z: 100}, "arm")
+	add("table", r3.Vector{X: 500}, referenceframe.World)
+	if cameraOnArm {
+		add("camera", r3.Vector{X: 50}, "arm")
+	} else {
+		add("camera", r3.Vector{Z: 300}, "table")
+	}
+	return fs
+}
+
+func TestCell(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	fs := newFrameSystem(t, true)
+	fsSvc := inject.NewFrameSystemService("builtin")
+	fsSvc.FrameSystemFunc = func(
+		ctx context.Context, additionalTransforms []*referenceframe.LinkInFrame,
+	) (referenceframe.FrameSystem, error) {
+		return fs, nil
+	}
+	toWorld := func(pose *referenceframe.PoseInFrame, dst string) (*referenceframe.PoseInFrame, error) {
+		transformed, err := fs.Transform(referenceframe.StartPositions(fs), pose, dst)
+		if err != nil {
+			return nil, err
+		}
+		return transformed.(*referenceframe.PoseInFrame), nil
+	}
+	fsSvc.TransformPoseFunc = func(
+		ctx context.Context, pose *referenceframe.PoseInFrame, dst string, additionalTransforms []*referenceframe.LinkInFrame,
+	) (*referenceframe.PoseInFrame, error) {
+		return toWorld(pose, dst)
+	}
+
+	var moves []move
+	motionSvc := inject.NewMotionService("builtin")
+	motionSvc.MoveFunc = func(
+		ctx context.Context,
+		componentName resource.Name,
+		destination *referenceframe.PoseInFrame,
+		worldState *referenceframe.WorldState,
+		constraints *pb.Constraints,
+		extra map[string]interface{},
+	) (bool, error) {
+		inWorld, err := toWorld(destination, referenceframe.World)
+		test.That(t, err, test.ShouldBeNil)
+		moves = append(moves, move{componentName.ShortName(), inWorld.Pose(), len(constraints.GetLinearConstraint()) > 0})
+		return true, nil
+	}
+
+	var gripperActions []string
+	injectedGripper := inject.NewGripper("gripper")
+	injectedGripper.OpenFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		gripperActions = append(gripperActions, "open")
+		return nil
+	}
+	injectedGripper.GrabFunc = func(ctx context.Context, extra map[string]interface{}) (bool, error) {
+		gripperActions = append(gripperActions, "grab")
+		return true, nil
+	}
+	injectedArm := inject.NewArm("arm")
+	injectedCamera := inject.NewCamera("camera")
+
+	deps := resource.Dependencies{
+		injectedArm.Name():                        injectedArm,
+		injectedGripper.Name():                    injectedGripper,
+		injectedCamera.Name():                     injectedCamera,
+		framesystem.InternalServiceName:           fsSvc,
+		motion.Named(resource.DefaultServiceName): motionSvc,
+	}
+	conf := &Config{ArmName: "arm", GripperName: "gripper", CameraName: "camera"}
+	depNames, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, depNames, test.ShouldContain, "rdk:service:motion/builtin")
+	res, err := newCell(ctx, deps, resource.Config{
+		Name:                "cell",
+		API:                 generic.API,
+		Model:               Model,
+		ConvertedAttributes: conf,
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	c := res.(Cell)
+
+	t.Run("picks from above", func(t *testing.T) {
+		moves, gripperActions = nil, nil
+		down := &spatialmath.OrientationVectorDegrees{OZ: -1}
+		grabbed, err := c.PickAt(ctx, referenceframe.NewPoseInFrame(referenceframe.World,
+			spatialmath.NewPose(r3.Vector{X: 400, Z: 20}, down)))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, grabbed, test.ShouldBeTrue)
+		test.That(t, gripperActions, test.ShouldResemble, []string{"open", "grab"})
+		test.That(t, moves, test.ShouldHaveLength, 3)
+		for _, m := range moves {
+			test.That(t, m.component, test.ShouldEqual, "gripper")
+		}
+		// the gripper points down, so it approaches from above
+		test.That(t, spatialmath.R3VectorAlmostEqual(moves[0].pose.Point(), r3.Vector{X: 400, Z: 120}, 1e-6), test.ShouldBeTrue)
+		test.That(t, moves[0].linear, test.ShouldBeFalse)
+		test.That(t, spatialmath.R3VectorAlmostEqual(moves[1].pose.Point(), r3.Vector{X: 400, Z: 20}, 1e-6), test.ShouldBeTrue)
+		test.That(t, moves[1].linear, test.ShouldBeTrue)
+		test.That(t, spatialmath.R3VectorAlmostEqual(moves[2].pose.Point(), r3.Vector{X: 400, Z: 120}, 1e-6), test.ShouldBeTrue)
+		test.That(t, moves[2].linear, test.ShouldBeTrue)
+	})
+
+	t.Run("places in another frame", func(t *testing.T) {
+		moves, gripperActions = nil, nil
+		_, err := c.DoCommand(ctx, map[string]interface{}{
+			"command": "place_at",
+			"pose":    map[string]interface{}{"x": 0., "y": 400., "z": 20., "o_z": -1.},
+			"frame":   "table",
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, gripperActions, test.ShouldResemble, []string{"open"})
+		test.That(t, moves, test.ShouldHaveLength, 3)
+		test.That(t, spatialmath.R3VectorAlmostEqual(moves[1].pose.Point(), r3.Vector{X: 500, Y: 400, Z: 20}, 1e-6), test.ShouldBeTrue)
+	})
+
+	t.Run("looks at a point from where the camera is", func(t *testing.T) {
+		moves = nil
+		_, err := c.DoCommand(ctx, map[string]interface{}{
+			"command": "look_at",
+			"pose":    map[string]interface{}{"x": 50., "y": 0., "z": 0.},
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, moves, test.ShouldHaveLength, 1)
+		test.That(t, moves[0].component, test.ShouldEqual, "camera")
+		test.That(t, spatialmath.R3VectorAlmostEqual(moves[0].pose.Point(), r3.Vector{X: 50, Z: 500}, 1e-6), test.ShouldBeTrue)
+		test.That(t, spatialmath.OrientationAlmostEqual(moves[0].pose.Orientation(), &spatialmath.OrientationVector{OZ: -1}),
+			test.ShouldBeTrue)
+	})
+
+	t.Run("reports the frames of the gripper and camera", func(t *testing.T) {
+		resp, err := c.DoCommand(ctx, map[string]interface{}{"command": "frames"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp["gripper"].(map[string]interface{})["z"], test.ShouldAlmostEqual, 100)
+		test.That(t, resp["camera"].(map[string]interface{})["x"], test.ShouldAlmostEqual, 50)
+	})
+
+	t.Run("cannot look with a camera the arm does not carry", func(t *testing.T) {
+		moves = nil
+		fs = newFrameSystem(t, false)
+		err := c.LookAt(ctx, referenceframe.NewPoseInFrame(referenceframe.World, spatialmath.NewZeroPose()))
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "not mounted on the arm")
+		test.That(t, moves, test.ShouldBeEmpty)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	_, err := (&Config{ArmName: "arm"}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "gripper")
+
+	_, err = (&Config{ArmName: "arm", GripperName: "gripper", ApproachDistanceMM: -1}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	deps, err := (&Config{ArmName: "arm", GripperName: "gripper", MotionService: "planner"}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"arm", "gripper", framesystem.InternalServiceName.String(), "rdk:service:motion/planner"})
+}
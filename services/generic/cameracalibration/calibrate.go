@@ -0,0 +1,339 @@
+package cameracalibration
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+)
+
+const (
+	// minViews is the fewest views of the board which can tell the focal length and principal point apart from the
+	// pose of the board.
+	minViews = 3
+
+	maxIterations = 100
+	maxDamping    = 1e10
+	jacobianStep  = 1e-6
+	// solving stops once an iteration lowers the cost by less than this fraction
+	convergence = 1e-12
+)
+
+// the parameters of the camera come first, followed by the rotation vector and translation of each view
+const (
+	paramFx = iota
+	paramFy
+	paramPpx
+	paramPpy
+	paramK1
+	paramK2
+	paramP1
+	paramP2
+	numCameraParams
+	numViewParams = 6
+)
+
+// A Board is a chessboard with rows by cols inner corners, where four squares meet.
+type Board struct {
+	Rows         int
+	Cols         int
+	SquareSizeMM float64
+}
+
+// points are the inner corners of the board in millimeters, row by row, with x along the rows and y down the
+// columns.
+func (b Board) points() []r3.Vector {
+	points := make([]r3.Vector, 0, b.Rows*b.Cols)
+	for r := 0; r < b.Rows; r++ {
+		for c := 0; c < b.Cols; c++ {
+			points = append(points, r3.Vector{X: float64(c) * b.SquareSizeMM, Y: float64(r) * b.SquareSizeMM})
+		}
+	}
+	return points
+}
+
+// A Result is the outcome of a calibration.
+type Result struct {
+	Intrinsics *transform.PinholeCameraIntrinsics
+	Distortion *transform.BrownConrady
+	// ReprojectionErrorPx is the root mean square distance between the corners found in the views and where the
+	// calibrated camera projects them, and ViewErrorsPx is the same for each view.
+	ReprojectionErrorPx float64
+	ViewErrorsPx        []float64
+}
+
+// Calibrate solves for the intrinsics and distortion of a camera from the corners of a board found in views taken
+// by it, each ordered as Board.points. The focal length comes from the homography of the board in each view with
+// the principal point at the center of the image, as Zhang describes, and is then refined with the principal
+// point, the distortion and the pose of each view to minimize the reprojection error.
+func Calibrate(board Board, views [][]r2.Point, width, height int) (*Result, error) {
+	if len(views) < minViews {
+		return nil, errors.Errorf("need at least %d views of the chessboard to calibrate, only have %d", minViews, len(views))
+	}
+	objectPoints := board.points()
+	planar := make([]r2.Point, len(objectPoints))
+	for i, p := range objectPoints {
+		planar[i] = r2.Point{X: p.X, Y: p.Y}
+	}
+	homographies := make([]*mat.Dense, 0, len(views))
+	for i, corners := range views {
+		if len(corners) != len(objectPoints) {
+			return nil, errors.Errorf("view %d has %d corners, expected %d", i, len(corners), len(objectPoints))
+		}
+		h, err := homography(planar, corners)
+		if err != nil {
+			return nil, errors.Wrapf(err, "view %d", i)
+		}
+		homographies = append(homographies, h)
+	}
+
+	params := make([]float64, numCameraParams+numViewParams*len(views))
+	params[paramPpx], params[paramPpy] = float64(width)/2, float64(height)/2
+	fx, fy, err := initialFocalLength(homographies, params[paramPpx], params[paramPpy])
+	if err != nil {
+		return nil, err
+	}
+	params[paramFx], params[paramFy] = fx, fy
+	for i, h := range homographies {
+		rotation, translation := viewPose(h, fx, fy, params[paramPpx], params[paramPpy])
+		copy(params[numCameraParams+numViewParams*i:], []float64{
+			rotation.X, rotation.Y, rotation.Z, translation.X, translation.Y, translation.Z,
+		})
+	}
+
+	residuals := func(params []float64) []float64 {
+		res := make([]float64, 0, 2*len(objectPoints)*len(views))
+		for i, corners := range views {
+			for j, p := range objectPoints {
+				projected := project(params, i, p)
+				res = append(res, projected.X-corners[j].X, projected.Y-corners[j].Y)
+			}
+		}
+		return res
+	}
+	params = levenbergMarquardt(params, residuals)
+
+	errs := residuals(params)
+	result := &Result{
+		Intrinsics: &transform.PinholeCameraIntrinsics{
+			Width:  width,
+			Height: height,
+			Fx:     params[paramFx],
+			Fy:     params[paramFy],
+			Ppx:    params[paramPpx],
+			Ppy:    params[paramPpy],
+		},
+		Distortion: distortion(params),
+	}
+	perView := 2 * len(objectPoints)
+	for i := range views {
+		viewErrs := errs[i*perView : (i+1)*perView]
+		result.ViewErrorsPx = append(result.ViewErrorsPx, math.Sqrt(floats.Dot(viewErrs, viewErrs)/float64(len(objectPoints))))
+	}
+	result.ReprojectionErrorPx = math.Sqrt(floats.Dot(errs, errs) / float64(len(objectPoints)*len(views)))
+	return result, nil
+}
+
+// normalization returns the transform which moves points to have their centroid at the origin and an average
+// distance of the square root of two from it, which conditions the solving of a homography.
+func normalization(points []r2.Point) *mat.Dense {
+	var centroid r2.Point
+	for _, p := range points {
+		centroid = centroid.Add(p)
+	}
+	centroid = centroid.Mul(1 / float64(len(points)))
+	var dist float64
+	for _, p := range points {
+		dist += p.Sub(centroid).Norm()
+	}
+	scale := math.Sqrt2 * float64(len(points)) / dist
+	return mat.NewDense(3, 3, []float64{scale, 0, -scale * centroid.X, 0, scale, -scale * centroid.Y, 0, 0, 1})
+}
+
+// homography finds the homography which maps the from points to the to points with the direct linear transform.
+func homography(from, to []r2.Point) (*mat.Dense, error) {
+	normFrom, normTo := normalization(from), normalization(to)
+	apply := func(m *mat.Dense, p r2.Point) r2.Point {
+		w := m.At(2, 0)*p.X + m.At(2, 1)*p.Y + m.At(2, 2)
+		return r2.Point{
+			X: (m.At(0, 0)*p.X + m.At(0, 1)*p.Y + m.At(0, 2)) / w,
+			Y: (m.At(1, 0)*p.X + m.At(1, 1)*p.Y + m.At(1, 2)) / w,
+		}
+	}
+	a := mat.NewDense(2*len(from), 9, nil)
+	for i := range from {
+		f, t := apply(normFrom, from[i]), apply(normTo, to[i])
+		a.SetRow(2*i, []float64{-f.X, -f.Y, -1, 0, 0, 0, t.X * f.X, t.X * f.Y, t.X})
+		a.SetRow(2*i+1, []float64{0, 0, 0, -f.X, -f.Y, -1, t.Y * f.X, t.Y * f.Y, t.Y})
+	}
+	var svd mat.SVD
+	if !svd.Factorize(a, mat.SVDFullV) {
+		return nil, errors.New("cannot solve for the homography of the chessboard")
+	}
+	var v mat.Dense
+	svd.VTo(&v)
+	normalized := mat.NewDense(3, 3, mat.Col(nil, 8, &v))
+
+	var inverse mat.Dense
+	if err := inverse.Inverse(normTo); err != nil {
+		return nil, err
+	}
+	var h mat.Dense
+	h.Product(&inverse, normalized, normFrom)
+	h.Scale(1/h.At(2, 2), &h)
+	return &h, nil
+}
+
+// initialFocalLength estimates the focal length from the homographies of the board with the principal point at
+// ppx, ppy. The first two columns of each homography are the x and y axes of the board projected by the camera,
+// which must be perpendicular and of the same length once the camera matrix is undone.
+func initialFocalLength(homographies []*mat.Dense, ppx, ppy float64) (float64, float64, error) {
+	a := mat.NewDense(2*len(homographies), 2, nil)
+	b := mat.NewVecDense(2*len(homographies), nil)
+	for i, h := range homographies {
+		var col [2]r3.Vector
+		for j := range col {
+			w := h.At(2, j)
+			col[j] = r3.Vector{X: h.At(0, j) - ppx*w, Y: h.At(1, j) - ppy*w, Z: w}
+		}
+		scale := 1 / (col[0].Norm() * col[1].Norm())
+		a.SetRow(2*i, []float64{col[0].X * col[1].X * scale, col[0].Y * col[1].Y * scale})
+		b.SetVec(2*i, -col[0].Z*col[1].Z*scale)
+		a.SetRow(2*i+1, []float64{
+			(col[0].X*col[0].X - col[1].X*col[1].X) * scale,
+			(col[0].Y*col[0].Y - col[1].Y*col[1].Y) * scale,
+		})
+		b.SetVec(2*i+1, -(col[0].Z*col[0].Z-col[1].Z*col[1].Z)*scale)
+	}
+	var inverseSquares mat.VecDense
+	if err := inverseSquares.SolveVec(a, b); err != nil || inverseSquares.AtVec(0) <= 0 || inverseSquares.AtVec(1) <= 0 {
+		return 0, 0, errors.New("cannot find the focal length, the chessboard must be tilted away from the camera in some views")
+	}
+	return 1 / math.Sqrt(inverseSquares.AtVec(0)), 1 / math.Sqrt(inverseSquares.AtVec(1)), nil
+}
+
+// viewPose returns the rotation vector and translation of the board in a view from its homography. Undoing the
+// camera matrix leaves the x and y axes of the board and its origin, up to scale.
+func viewPose(h *mat.Dense, fx, fy, ppx, ppy float64) (r3.Vector, r3.Vector) {
+	var col [3]r3.Vector
+	for j := range col {
+		w := h.At(2, j)
+		col[j] = r3.Vector{X: (h.At(0, j) - ppx*w) / fx, Y: (h.At(1, j) - ppy*w) / fy, Z: w}
+	}
+	scale := 2 / (col[0].Norm() + col[1].Norm())
+	if col[2].Z < 0 {
+		// the board is in front of the camera
+		scale = -scale
+	}
+	x, y := col[0].Mul(scale), col[1].Mul(scale)
+	translation := col[2].Mul(scale)
+
+	// the axes are only perpendicular up to noise, so the rotation is the nearest to them
+	m := mat.NewDense(3, 3, nil)
+	for i, axis := range []r3.Vector{x, y, x.Cross(y).Normalize()} {
+		m.SetCol(i, []float64{axis.X, axis.Y, axis.Z})
+	}
+	var svd mat.SVD
+	svd.Factorize(m, mat.SVDFull)
+	var u, v, rotation mat.Dense
+	svd.UTo(&u)
+	svd.VTo(&v)
+	rotation.Mul(&u, v.T())
+	rm, err := spatialmath.NewRotationMatrix(mat.DenseCopyOf(&rotation).RawMatrix().Data)
+	if err != nil {
+		return r3.Vector{}, translation
+	}
+	return rm.AxisAngles().ToR3(), translation
+}
+
+// rotate rotates a point by a rotation vector with Rodrigues' formula.
+func rotate(rotation, p r3.Vector) r3.Vector {
+	theta := rotation.Norm()
+	if theta < 1e-12 {
+		return p.Add(rotation.Cross(p))
+	}
+	axis := rotation.Mul(1 / theta)
+	sin, cos := math.Sincos(theta)
+	return p.Mul(cos).Add(axis.Cross(p).Mul(sin)).Add(axis.Mul(axis.Dot(p) * (1 - cos)))
+}
+
+func distortion(params []float64) *transform.BrownConrady {
+	return &transform.BrownConrady{
+		RadialK1:     params[paramK1],
+		RadialK2:     params[paramK2],
+		TangentialP1: params[paramP1],
+		TangentialP2: params[paramP2],
+	}
+}
+
+// project is the pixel a point on the board projects to in a view.
+func project(params []float64, view int, p r3.Vector) r2.Point {
+	v := params[numCameraParams+numViewParams*view:]
+	camera := rotate(r3.Vector{X: v[0], Y: v[1], Z: v[2]}, p).Add(r3.Vector{X: v[3], Y: v[4], Z: v[5]})
+	x, y := distortion(params).Transform(camera.X/camera.Z, camera.Y/camera.Z)
+	return r2.Point{X: x*params[paramFx] + params[paramPpx], Y: y*params[paramFy] + params[paramPpy]}
+}
+
+// levenbergMarquardt finds the parameters which minimize the sum of the squares of the residuals.
+func levenbergMarquardt(params []float64, residualsOf func([]float64) []float64) []float64 {
+	residuals := residualsOf(params)
+	cost := floats.Dot(residuals, residuals)
+	n := len(params)
+	damping := 1e-3
+	jacobian := mat.NewDense(len(residuals), n, nil)
+	stepped := make([]float64, n)
+	for iter := 0; iter < maxIterations; iter++ {
+		for j := 0; j < n; j++ {
+			copy(stepped, params)
+			stepped[j] += jacobianStep
+			perturbed := residualsOf(stepped)
+			for i := range residuals {
+				jacobian.Set(i, j, (perturbed[i]-residuals[i])/jacobianStep)
+			}
+		}
+		var normal mat.SymDense
+		normal.SymOuterK(1, jacobian.T())
+		var gradient mat.VecDense
+		gradient.MulVec(jacobian.T(), mat.NewVecDense(len(residuals), residuals))
+
+		improved := false
+		for !improved && damping < maxDamping {
+			damped := mat.NewSymDense(n, nil)
+			damped.CopySym(&normal)
+			for j := 0; j < n; j++ {
+				damped.SetSym(j, j, normal.At(j, j)*(1+damping)+damping)
+			}
+			var step mat.VecDense
+			if err := step.SolveVec(damped, &gradient); err != nil {
+				damping *= 10
+				continue
+			}
+			candidate := make([]float64, n)
+			floats.SubTo(candidate, params, step.RawVector().Data)
+			candidateResiduals := residualsOf(candidate)
+			candidateCost := floats.Dot(candidateResiduals, candidateResiduals)
+			if math.IsNaN(candidateCost) || candidateCost >= cost {
+				damping *= 10
+				continue
+			}
+			improved = true
+			damping = math.Max(damping/10, 1e-9)
+			converged := cost-candidateCost < convergence*cost
+			params, residuals, cost = candidate, candidateResiduals, candidateCost
+			if converged {
+				return params
+			}
+		}
+		if !improved {
+			// no step lowers the cost, so the parameters are at a minimum
+			return params
+		}
+	}
+	return params
+}
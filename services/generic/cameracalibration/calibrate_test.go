@@ -0,0 +1,149 @@
+package cameracalibration
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/utils"
+)
+
+var (
+	testBoard      = Board{Rows: 6, Cols: 8, SquareSizeMM: 25}
+	testIntrinsics = &transform.PinholeCameraIntrinsics{Width: 320, Height: 240, Fx: 310, Fy: 300, Ppx: 165, Ppy: 118}
+	testDistortion = &transform.BrownConrady{RadialK1: -0.12, RadialK2: 0.03, TangentialP1: 0.001, TangentialP2: -0.0005}
+)
+
+// testView is the pose of the board in a view, as rotations in degrees and the position of its middle.
+type testView struct {
+	rx, ry, rz float64
+	center     r3.Vector
+}
+
+var testViews = []testView{
+	{0, 0, 5, r3.Vector{X: 0, Y: 0, Z: 450}},
+	{25, 0, 0, r3.Vector{X: 0, Y: 0, Z: 450}},
+	{-25, 10, 0, r3.Vector{X: 10, Y: 0, Z: 480}},
+	{0, 30, -5, r3.Vector{X: 0, Y: 10, Z: 450}},
+	{15, -25, 10, r3.Vector{X: -10, Y: -5, Z: 430}},
+	{-10, -20, 0, r3.Vector{X: 20, Y: 10, Z: 500}},
+}
+
+// pose returns the rotation vector and translation of the board in the view.
+func (v testView) pose() (r3.Vector, r3.Vector) {
+	rotation := r3.Vector{X: utils.DegToRad(v.rx), Y: utils.DegToRad(v.ry), Z: utils.DegToRad(v.rz)}
+	middle := r3.Vector{X: float64(testBoard.Cols-1) * testBoard.SquareSizeMM / 2, Y: float64(testBoard.Rows-1) * testBoard.SquareSizeMM / 2}
+	return rotation, v.center.Sub(rotate(rotation, middle))
+}
+
+// corners are the pixels the inner corners of the board project to in the view.
+func (v testView) corners() []r2.Point {
+	rotation, translation := v.pose()
+	var corners []r2.Point
+	for _, p := range testBoard.points() {
+		c := rotate(rotation, p).Add(translation)
+		x, y := testDistortion.Transform(c.X/c.Z, c.Y/c.Z)
+		corners = append(corners, r2.Point{X: x*testIntrinsics.Fx + testIntrinsics.Ppx, Y: y*testIntrinsics.Fy + testIntrinsics.Ppy})
+	}
+	return corners
+}
+
+// render draws the board as the test camera sees it in the view, casting rays through several points of each pixel
+// onto the board.
+func (v testView) render() image.Image {
+	rotation, translation := v.pose()
+	model := transform.PinholeCameraModel{PinholeCameraIntrinsics: testIntrinsics, Distortion: testDistortion}
+	normal := rotate(rotation, r3.Vector{Z: 1})
+	square := testBoard.SquareSizeMM
+	shade := func(u, w float64) float64 {
+		x, y, z := model.PixelToPoint(u, w, 1)
+		ray := r3.Vector{X: x, Y: y, Z: z}
+		onBoard := rotate(rotation.Mul(-1), ray.Mul(normal.Dot(translation)/normal.Dot(ray)).Sub(translation))
+		col, row := math.Floor(onBoard.X/square)+1, math.Floor(onBoard.Y/square)+1
+		switch {
+		case col < -1 || row < -1 || col > float64(testBoard.Cols)+1 || row > float64(testBoard.Rows)+1:
+			return 100
+		case col < 0 || row < 0 || col > float64(testBoard.Cols) || row > float64(testBoard.Rows):
+			return 255
+		case int(col+row)%2 == 0:
+			return 20
+		default:
+			return 235
+		}
+	}
+	img := image.NewGray(image.Rect(0, 0, testIntrinsics.Width, testIntrinsics.Height))
+	offsets := []float64{-1. / 3, 0, 1. / 3}
+	for y := 0; y < testIntrinsics.Height; y++ {
+		for x := 0; x < testIntrinsics.Width; x++ {
+			var sum float64
+			for _, dy := range offsets {
+				for _, dx := range offsets {
+					sum += shade(float64(x)+dx, float64(y)+dy)
+				}
+			}
+			img.SetGray(x, y, color.Gray{Y: uint8(math.Round(sum / 9))})
+		}
+	}
+	return img
+}
+
+func TestFindChessboardCorners(t *testing.T) {
+	for _, v := range testViews {
+		expected := v.corners()
+		corners, err := FindChessboardCorners(v.render(), testBoard.Rows, testBoard.Cols)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, corners, test.ShouldHaveLength, len(expected))
+		for i, c := range corners {
+			test.That(t, c.Sub(expected[i]).Norm(), test.ShouldBeLessThan, 0.3)
+		}
+	}
+
+	// the board is found turned upside down, starting from the corner nearest the top left
+	upsideDown := testViews[0]
+	upsideDown.rz = 180
+	expected := upsideDown.corners()
+	corners, err := FindChessboardCorners(upsideDown.render(), testBoard.Rows, testBoard.Cols)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, corners[0].Sub(expected[len(expected)-1]).Norm(), test.ShouldBeLessThan, 0.3)
+
+	_, err = FindChessboardCorners(testViews[0].render(), testBoard.Rows+1, testBoard.Cols)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = FindChessboardCorners(image.NewGray(image.Rect(0, 0, 64, 48)), testBoard.Rows, testBoard.Cols)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestCalibrate(t *testing.T) {
+	var views [][]r2.Point
+	for _, v := range testViews {
+		views = append(views, v.corners())
+	}
+	_, err := Calibrate(testBoard, views[:2], testIntrinsics.Width, testIntrinsics.Height)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	result, err := Calibrate(testBoard, views, testIntrinsics.Width, testIntrinsics.Height)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result.ReprojectionErrorPx, test.ShouldBeLessThan, 1e-3)
+	test.That(t, result.ViewErrorsPx, test.ShouldHaveLength, len(views))
+	test.That(t, result.Intrinsics.Width, test.ShouldEqual, testIntrinsics.Width)
+	test.That(t, result.Intrinsics.Fx, test.ShouldAlmostEqual, testIntrinsics.Fx, 0.01)
+	test.That(t, result.Intrinsics.Fy, test.ShouldAlmostEqual, testIntrinsics.Fy, 0.01)
+	test.That(t, result.Intrinsics.Ppx, test.ShouldAlmostEqual, testIntrinsics.Ppx, 0.01)
+	test.That(t, result.Intrinsics.Ppy, test.ShouldAlmostEqual, testIntrinsics.Ppy, 0.01)
+	test.That(t, result.Distortion.RadialK1, test.ShouldAlmostEqual, testDistortion.RadialK1, 1e-4)
+	test.That(t, result.Distortion.RadialK2, test.ShouldAlmostEqual, testDistortion.RadialK2, 1e-4)
+	test.That(t, result.Distortion.TangentialP1, test.ShouldAlmostEqual, testDistortion.TangentialP1, 1e-5)
+
+	// fronto-parallel views cannot tell the focal length from the distance to the board
+	flat := make([][]r2.Point, 3)
+	for i := range flat {
+		flat[i] = testView{center: r3.Vector{X: float64(10 * i), Z: 400 + float64(50*i)}}.corners()
+	}
+	_, err = Calibrate(testBoard, flat, testIntrinsics.Width, testIntrinsics.Height)
+	test.That(t, err, test.ShouldNotBeNil)
+}
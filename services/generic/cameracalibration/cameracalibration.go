@@ -0,0 +1,344 @@
+// Package cameracalibration implements a generic service which calibrates the intrinsics and distortion of a camera
+// from views of a chessboard, reporting the reprojection error and writing the result back into the attributes of
+// the camera in the robot's config file, which is reloaded when it changes.
+//
+// The board is held in front of the camera at a different distance and tilt for each view, and the views are
+// captured and solved with DoCommand:
+//
+//	{"command": "capture"}    // find the chessboard in the next image from the camera and keep its corners
+//	{"command": "clear"}
+//	{"command": "calibrate"}  // solve, and write intrinsic_parameters and distortion_parameters to the config file
+//
+// A capture with a count keeps capturing, every interval_sec, until it has that many views, skipping images the
+// chessboard is not found in, so that the board can be moved between views:
+//
+//	{"command": "capture", "count": 15, "interval_sec": 2}
+//
+// Streamed with resource.DoCommandStream, it sends the number of views kept after each image.
+//
+// Only plain chessboards are found; ChArUco boards are not supported. The distortion is solved as the Brown-Conrady
+// model with two radial and two tangential terms.
+package cameracalibration
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r2"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+)
+
+// Model is the model of the camera calibration service.
+var Model = resource.DefaultModelFamily.WithModel("camera_calibration")
+
+const defaultCaptureIntervalSec = 2.
+
+func init() {
+	resource.RegisterService(generic.API, Model, resource.Registration[resource.Resource, *Config]{
+		Constructor: newCalibrator,
+	})
+}
+
+// Config describes how to configure the service.
+type Config struct {
+	Camera string `json:"camera"`
+	// BoardRows and BoardCols are the number of inner corners of the chessboard, where four squares meet, which is
+	// one less than the number of squares.
+	BoardRows    int     `json:"board_rows"`
+	BoardCols    int     `json:"board_cols"`
+	SquareSizeMM float64 `json:"square_size_mm"`
+	// ConfigFile is the robot config file whose camera attributes are updated with the calibration.
+	ConfigFile string `json:"config_file,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.Camera == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "camera")
+	}
+	if conf.BoardRows < 2 || conf.BoardCols < 2 {
+		return nil, resource.NewConfigValidationError(path, errors.New("board_rows and board_cols must be at least 2"))
+	}
+	if conf.SquareSizeMM <= 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "square_size_mm")
+	}
+	return []string{conf.Camera}, nil
+}
+
+type calibrator struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	logger  logging.Logger
+	camera  camera.Camera
+	conf    *Config
+	board   Board
+	streams *resource.CommandStreams
+
+	mu            sync.Mutex
+	views         [][]r2.Point
+	width, height int
+}
+
+func newCalibrator(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (resource.Resource, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	c := &calibrator{
+		Named:  conf.ResourceName().AsNamed(),
+		logger: logger,
+		conf:   newConf,
+		board:  Board{Rows: newConf.BoardRows, Cols: newConf.BoardCols, SquareSizeMM: newConf.SquareSizeMM},
+	}
+	c.streams = resource.NewCommandStreams(map[string]resource.StreamingCommandHandler{"capture": c.capture})
+	if c.camera, err = camera.FromDependencies(deps, newConf.Camera); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// DoCommand captures views of the chessboard and runs the calibration.
+func (c *calibrator) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	handlers := c.streams.Handlers()
+	handlers["clear"] = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		c.mu.Lock()
+		c.views = nil
+		c.mu.Unlock()
+		return c.viewCount(), nil
+	}
+	handlers["calibrate"] = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		return c.calibrate()
+	}
+	return handlers.DoCommand(ctx, cmd)
+}
+
+// DoCommandStream runs a capture, sending the number of views kept after each image.
+func (c *calibrator) DoCommandStream(
+	ctx context.Context, cmd map[string]interface{}, send func(msg map[string]interface{}) error,
+) (map[string]interface{}, error) {
+	return c.streams.DoCommandStream(ctx, cmd, send)
+}
+
+// Close stops any streamed capture.
+func (c *calibrator) Close(ctx context.Context) error {
+	c.streams.Close()
+	return nil
+}
+
+func (c *calibrator) viewCount() map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return map[string]interface{}{"views": len(c.views)}
+}
+
+// capture keeps the corners of the chessboard in the next image from the camera or, given a count, in images taken
+// every interval until it has that many more views.
+func (c *calibrator) capture(
+	ctx context.Context, cmd map[string]interface{}, send func(msg map[string]interface{}) error,
+) (map[string]interface{}, error) {
+	rawCount, ok := cmd["count"]
+	if !ok {
+		if err := c.captureView(ctx); err != nil {
+			return nil, err
+		}
+		return c.viewCount(), nil
+	}
+	count, ok := rawCount.(float64)
+	if !ok || count < 1 {
+		return nil, errors.New("count must be a positive number")
+	}
+	intervalSec := defaultCaptureIntervalSec
+	if raw, ok := cmd["interval_sec"]; ok {
+		if intervalSec, ok = raw.(float64); !ok || intervalSec < 0 {
+			return nil, errors.New("interval_sec must be a number no less than 0")
+		}
+	}
+	interval := time.Duration(intervalSec * float64(time.Second))
+
+	for kept := 0; kept < int(count); {
+		msg := c.viewCount()
+		corners, width, height, err := c.findView(ctx)
+		if err == nil {
+			if err := c.keepView(corners, width, height); err != nil {
+				return nil, err
+			}
+			kept++
+			msg = c.viewCount()
+		} else {
+			msg["error"] = err.Error()
+		}
+		if err := send(msg); err != nil {
+			return nil, err
+		}
+		if kept < int(count) && !goutils.SelectContextOrWait(ctx, interval) {
+			return nil, ctx.Err()
+		}
+	}
+	return c.viewCount(), nil
+}
+
+// captureView finds the chessboard in the next image from the camera and keeps its corners.
+func (c *calibrator) captureView(ctx context.Context) error {
+	corners, width, height, err := c.findView(ctx)
+	if err != nil {
+		return err
+	}
+	return c.keepView(corners, width, height)
+}
+
+// findView finds the corners of the chessboard in the next image from the camera, returning them with the size of
+// the image.
+func (c *calibrator) findView(ctx context.Context) ([]r2.Point, int, int, error) {
+	img, release, err := camera.ReadImage(ctx, c.camera)
+	if err != nil {
+		return nil, 0, 0, errors.Wrap(err, "cannot read image from camera")
+	}
+	defer release()
+	corners, err := FindChessboardCorners(img, c.board.Rows, c.board.Cols)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return corners, img.Bounds().Dx(), img.Bounds().Dy(), nil
+}
+
+// keepView keeps the corners of a view, as long as it is the same size as the views kept before it.
+func (c *calibrator) keepView(corners []r2.Point, width, height int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.views) > 0 && (width != c.width || height != c.height) {
+		return errors.Errorf("image is %dx%d but earlier views are %dx%d, clear them to calibrate at a new size",
+			width, height, c.width, c.height)
+	}
+	c.views = append(c.views, corners)
+	c.width, c.height = width, height
+	return nil
+}
+
+// calibrate solves for the intrinsics and distortion of the camera from the captured views and writes them to the
+// config file.
+func (c *calibrator) calibrate() (map[string]interface{}, error) {
+	c.mu.Lock()
+	views := append([][]r2.Point(nil), c.views...)
+	width, height := c.width, c.height
+	c.mu.Unlock()
+
+	result, err := Calibrate(c.board, views, width, height)
+	if err != nil {
+		return nil, err
+	}
+	attributes := map[string]interface{}{
+		"intrinsic_parameters": map[string]interface{}{
+			"width_px":  result.Intrinsics.Width,
+			"height_px": result.Intrinsics.Height,
+			"fx":        result.Intrinsics.Fx,
+			"fy":        result.Intrinsics.Fy,
+			"ppx":       result.Intrinsics.Ppx,
+			"ppy":       result.Intrinsics.Ppy,
+		},
+		"distortion_parameters": map[string]interface{}{
+			"rk1": result.Distortion.RadialK1,
+			"rk2": result.Distortion.RadialK2,
+			"rk3": result.Distortion.RadialK3,
+			"tp1": result.Distortion.TangentialP1,
+			"tp2": result.Distortion.TangentialP2,
+		},
+	}
+	viewErrors := make([]interface{}, 0, len(result.ViewErrorsPx))
+	for _, e := range result.ViewErrorsPx {
+		viewErrors = append(viewErrors, e)
+	}
+	resp := map[string]interface{}{
+		"views":                 len(views),
+		"intrinsic_parameters":  attributes["intrinsic_parameters"],
+		"distortion_parameters": attributes["distortion_parameters"],
+		"reprojection_error_px": result.ReprojectionErrorPx,
+		"view_errors_px":        viewErrors,
+	}
+	c.logger.Infow("calibrated camera",
+		"camera", c.conf.Camera,
+		"views", len(views),
+		"reprojection_error_px", result.ReprojectionErrorPx,
+	)
+	if c.conf.ConfigFile == "" {
+		return resp, nil
+	}
+	if err := updateCameraAttributes(c.conf.ConfigFile, c.conf.Camera, attributes); err != nil {
+		return nil, err
+	}
+	resp["config_file"] = c.conf.ConfigFile
+	return resp, nil
+}
+
+// updateCameraAttributes sets attributes of a camera in a robot config file, leaving the rest of the file as it is.
+// The file is replaced whole so that a robot watching it never reads it half written.
+func updateCameraAttributes(path, name string, attributes map[string]interface{}) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.Wrap(err, "cannot read config file")
+	}
+	data, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return errors.Wrap(err, "cannot read config file")
+	}
+	var robotConfig map[string]interface{}
+	if err := json.Unmarshal(data, &robotConfig); err != nil {
+		return errors.Wrapf(err, "cannot parse config file %q", path)
+	}
+	components, _ := robotConfig["components"].([]interface{})
+	var component map[string]interface{}
+	for _, raw := range components {
+		if conf, ok := raw.(map[string]interface{}); ok && conf["name"] == name {
+			component = conf
+			break
+		}
+	}
+	if component == nil {
+		return errors.Errorf("camera %q is not in config file %q", name, path)
+	}
+	existing, _ := component["attributes"].(map[string]interface{})
+	if existing == nil {
+		existing = map[string]interface{}{}
+		component["attributes"] = existing
+	}
+	for k, v := range attributes {
+		existing[k] = v
+	}
+
+	data, err = json.MarshalIndent(robotConfig, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return errors.Wrap(err, "cannot write config file")
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck,gosec
+		return errors.Wrap(err, "cannot write config file")
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close() //nolint:errcheck,gosec
+		return errors.Wrap(err, "cannot write config file")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "cannot write config file")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), path), "cannot write config file")
+}
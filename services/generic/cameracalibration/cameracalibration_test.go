@@ -0,0 +1,134 @@
+package cameracalibration
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/gostream"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestValidate(t *testing.T) {
+	conf := &Config{Camera: "cam", BoardRows: 6, BoardCols: 8, SquareSizeMM: 25}
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"cam"})
+
+	_, err = (&Config{BoardRows: 6, BoardCols: 8, SquareSizeMM: 25}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{Camera: "cam", BoardRows: 1, BoardCols: 8, SquareSizeMM: 25}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{Camera: "cam", BoardRows: 6, BoardCols: 8}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestCameraCalibration(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	var next image.Image
+	cam := inject.NewCamera("cam")
+	cam.StreamFunc = func(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error) {
+		return gostream.NewEmbeddedVideoStreamFromReader(gostream.VideoReaderFunc(func(ctx context.Context) (image.Image, func(), error) {
+			return next, func() {}, nil
+		})), nil
+	}
+
+	configFile := filepath.Join(t.TempDir(), "robot.json")
+	robotConfig := `{
+		"components": [
+			{"name": "arm", "type": "arm", "model": "fake"},
+			{"name": "cam", "type": "camera", "model": "webcam", "attributes": {"video_path": "video0"}}
+		]
+	}`
+	test.That(t, os.WriteFile(configFile, []byte(robotConfig), 0o600), test.ShouldBeNil)
+
+	res, err := newCalibrator(ctx, resource.Dependencies{cam.Name(): cam}, resource.Config{
+		Name:  "calibration",
+		API:   generic.API,
+		Model: Model,
+		ConvertedAttributes: &Config{
+			Camera:       "cam",
+			BoardRows:    testBoard.Rows,
+			BoardCols:    testBoard.Cols,
+			SquareSizeMM: testBoard.SquareSizeMM,
+			ConfigFile:   configFile,
+		},
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer res.Close(ctx)
+
+	// an image without the board is not a view
+	next = image.NewGray(image.Rect(0, 0, testIntrinsics.Width, testIntrinsics.Height))
+	_, err = res.DoCommand(ctx, map[string]interface{}{"command": "capture"})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	for i, v := range testViews {
+		next = v.render()
+		resp, err := res.DoCommand(ctx, map[string]interface{}{"command": "capture"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp["views"], test.ShouldEqual, i+1)
+	}
+	next = image.NewGray(image.Rect(0, 0, 64, 48))
+	_, err = res.DoCommand(ctx, map[string]interface{}{"command": "capture"})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	resp, err := res.DoCommand(ctx, map[string]interface{}{"command": "calibrate"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["views"], test.ShouldEqual, len(testViews))
+	test.That(t, resp["reprojection_error_px"], test.ShouldBeLessThan, 0.3)
+	intrinsics := resp["intrinsic_parameters"].(map[string]interface{})
+	test.That(t, intrinsics["width_px"], test.ShouldEqual, testIntrinsics.Width)
+	test.That(t, intrinsics["fx"], test.ShouldAlmostEqual, testIntrinsics.Fx, 0.01*testIntrinsics.Fx)
+	test.That(t, intrinsics["fy"], test.ShouldAlmostEqual, testIntrinsics.Fy, 0.01*testIntrinsics.Fy)
+	test.That(t, intrinsics["ppx"], test.ShouldAlmostEqual, testIntrinsics.Ppx, 2)
+	test.That(t, intrinsics["ppy"], test.ShouldAlmostEqual, testIntrinsics.Ppy, 2)
+
+	// the camera's attributes are updated in the config file, and nothing else is
+	data, err := os.ReadFile(configFile)
+	test.That(t, err, test.ShouldBeNil)
+	var written struct {
+		Components []struct {
+			Name       string                 `json:"name"`
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"components"`
+	}
+	test.That(t, json.Unmarshal(data, &written), test.ShouldBeNil)
+	test.That(t, written.Components, test.ShouldHaveLength, 2)
+	test.That(t, written.Components[0].Attributes, test.ShouldBeNil)
+	attributes := written.Components[1].Attributes
+	test.That(t, attributes["video_path"], test.ShouldEqual, "video0")
+	test.That(t, attributes["intrinsic_parameters"].(map[string]interface{})["fx"], test.ShouldEqual, intrinsics["fx"])
+	test.That(t, attributes["distortion_parameters"].(map[string]interface{}), test.ShouldContainKey, "rk1")
+
+	resp, err = res.DoCommand(ctx, map[string]interface{}{"command": "clear"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["views"], test.ShouldEqual, 0)
+	_, err = res.DoCommand(ctx, map[string]interface{}{"command": "calibrate"})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// a streamed capture sends the views kept after each image, skipping those without the board
+	next = image.NewGray(image.Rect(0, 0, testIntrinsics.Width, testIntrinsics.Height))
+	var msgs []map[string]interface{}
+	resp, err = resource.DoCommandStream(ctx, res, map[string]interface{}{"command": "capture", "count": 2., "interval_sec": 0.},
+		func(msg map[string]interface{}) error {
+			msgs = append(msgs, msg)
+			next = testViews[len(msgs)-1].render()
+			return nil
+		})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["views"], test.ShouldEqual, 2)
+	test.That(t, msgs, test.ShouldHaveLength, 3)
+	test.That(t, msgs[0]["views"], test.ShouldEqual, 0)
+	test.That(t, msgs[0]["error"], test.ShouldContainSubstring, "chessboard")
+	test.That(t, msgs[2]["views"], test.ShouldEqual, 2)
+}
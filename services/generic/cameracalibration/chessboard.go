@@ -0,0 +1,429 @@
+package cameracalibration
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r2"
+	"github.com/pkg/errors"
+)
+
+const (
+	// blurSigma smooths the image before finding corners so that their saddle is a single peak.
+	blurSigma = 1.
+	// saddleThreshold is the fraction of the strongest saddle response a candidate corner must reach.
+	saddleThreshold = 0.1
+	// suppressionRadius is how far a candidate corner must be the strongest response, in pixels.
+	suppressionRadius = 3
+	// refineRadius is the half size of the window corners are refined to subpixel accuracy in.
+	refineRadius = 4
+	// ringRadius is the radius of the circle sampled around a corner to check it is between four squares.
+	ringRadius  = 4
+	ringSamples = 32
+	// minContrast is the least difference between the dark and light squares around a corner, out of 255.
+	minContrast = 25
+	// gridTolerance is how far a corner may be from where the grid predicts it, as a fraction of a square.
+	gridTolerance = 0.35
+	maxSeeds      = 20
+)
+
+// grayImage is an image as a grid of luminances from 0 to 255.
+type grayImage struct {
+	width, height int
+	pix           []float64
+}
+
+func newGrayImage(img image.Image) *grayImage {
+	bounds := img.Bounds()
+	g := &grayImage{width: bounds.Dx(), height: bounds.Dy(), pix: make([]float64, bounds.Dx()*bounds.Dy())}
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			g.pix[y*g.width+x] = float64(color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray).Y)
+		}
+	}
+	return g
+}
+
+// at is the luminance at a pixel, clamped to the edge of the image.
+func (g *grayImage) at(x, y int) float64 {
+	x = int(math.Max(0, math.Min(float64(g.width-1), float64(x))))
+	y = int(math.Max(0, math.Min(float64(g.height-1), float64(y))))
+	return g.pix[y*g.width+x]
+}
+
+// interpolate is the luminance at a point between pixels.
+func (g *grayImage) interpolate(p r2.Point) float64 {
+	x0, y0 := math.Floor(p.X), math.Floor(p.Y)
+	fx, fy := p.X-x0, p.Y-y0
+	x, y := int(x0), int(y0)
+	return (1-fy)*((1-fx)*g.at(x, y)+fx*g.at(x+1, y)) + fy*((1-fx)*g.at(x, y+1)+fx*g.at(x+1, y+1))
+}
+
+// blur smooths the image with a separable gaussian kernel.
+func (g *grayImage) blur(sigma float64) *grayImage {
+	radius := int(math.Ceil(3 * sigma))
+	kernel := make([]float64, 2*radius+1)
+	var sum float64
+	for i := range kernel {
+		d := float64(i - radius)
+		kernel[i] = math.Exp(-d * d / (2 * sigma * sigma))
+		sum += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+	pass := func(src *grayImage, dx, dy int) *grayImage {
+		dst := &grayImage{width: src.width, height: src.height, pix: make([]float64, len(src.pix))}
+		for y := 0; y < src.height; y++ {
+			for x := 0; x < src.width; x++ {
+				var v float64
+				for i, k := range kernel {
+					v += k * src.at(x+(i-radius)*dx, y+(i-radius)*dy)
+				}
+				dst.pix[y*dst.width+x] = v
+			}
+		}
+		return dst
+	}
+	return pass(pass(g, 1, 0), 0, 1)
+}
+
+// FindChessboardCorners finds the inner corners of a chessboard with rows by cols inner corners in an image. The
+// corners are returned row by row, in pixels, starting from the corner nearest the top left of the image with the
+// rows running down the image and the columns across it.
+func FindChessboardCorners(img image.Image, rows, cols int) ([]r2.Point, error) {
+	if rows < 2 || cols < 2 {
+		return nil, errors.Errorf("a chessboard needs at least 2 by 2 inner corners, got %d by %d", rows, cols)
+	}
+	g := newGrayImage(img).blur(blurSigma)
+	candidates := findSaddles(g)
+	if len(candidates) < rows*cols {
+		return nil, errors.Errorf("found %d chessboard corners, need %d", len(candidates), rows*cols)
+	}
+
+	// seeds near the middle of the candidates are most likely to be on the board
+	var centroid r2.Point
+	for _, c := range candidates {
+		centroid = centroid.Add(c)
+	}
+	centroid = centroid.Mul(1 / float64(len(candidates)))
+	seeds := make([]int, len(candidates))
+	for i := range seeds {
+		seeds[i] = i
+	}
+	sort.Slice(seeds, func(i, j int) bool {
+		return candidates[seeds[i]].Sub(centroid).Norm() < candidates[seeds[j]].Sub(centroid).Norm()
+	})
+	if len(seeds) > maxSeeds {
+		seeds = seeds[:maxSeeds]
+	}
+	var found [2]int
+	for _, seed := range seeds {
+		grid := growGrid(candidates, seed)
+		if grid == nil {
+			continue
+		}
+		if corners := grid.corners(rows, cols); corners != nil {
+			return corners, nil
+		}
+		found = grid.size()
+	}
+	return nil, errors.Errorf("could not find a %d by %d chessboard, largest grid found is %d by %d", rows, cols, found[1], found[0])
+}
+
+// findSaddles finds the points of an image which are the meeting of four squares, to subpixel accuracy. The
+// determinant of the hessian of the image is strongly negative at such saddle points and near zero along edges.
+func findSaddles(g *grayImage) []r2.Point {
+	response := make([]float64, len(g.pix))
+	var strongest float64
+	for y := 1; y < g.height-1; y++ {
+		for x := 1; x < g.width-1; x++ {
+			center := g.at(x, y)
+			dxx := g.at(x+1, y) + g.at(x-1, y) - 2*center
+			dyy := g.at(x, y+1) + g.at(x, y-1) - 2*center
+			dxy := (g.at(x+1, y+1) - g.at(x+1, y-1) - g.at(x-1, y+1) + g.at(x-1, y-1)) / 4
+			r := dxy*dxy - dxx*dyy
+			response[y*g.width+x] = r
+			strongest = math.Max(strongest, r)
+		}
+	}
+	if strongest == 0 {
+		return nil
+	}
+
+	var saddles []r2.Point
+	for y := suppressionRadius; y < g.height-suppressionRadius; y++ {
+		for x := suppressionRadius; x < g.width-suppressionRadius; x++ {
+			r := response[y*g.width+x]
+			if r < saddleThreshold*strongest || !isLocalMax(response, g.width, x, y) {
+				continue
+			}
+			p, ok := refineCorner(g, r2.Point{X: float64(x), Y: float64(y)})
+			if !ok || !isChessboardCorner(g, p) {
+				continue
+			}
+			saddles = append(saddles, p)
+		}
+	}
+	return saddles
+}
+
+// isLocalMax says whether the response at x, y is the greatest within the suppression radius, with ties going to the
+// first pixel.
+func isLocalMax(response []float64, width, x, y int) bool {
+	r := response[y*width+x]
+	for dy := -suppressionRadius; dy <= suppressionRadius; dy++ {
+		for dx := -suppressionRadius; dx <= suppressionRadius; dx++ {
+			other := response[(y+dy)*width+x+dx]
+			if other > r || (other == r && (dy < 0 || (dy == 0 && dx < 0))) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// refineCorner moves a corner to subpixel accuracy. The gradient of the image at each pixel near a corner is
+// perpendicular to the line from the corner to the pixel, so the corner is the point which best satisfies that for
+// every pixel in a window around it.
+func refineCorner(g *grayImage, p r2.Point) (r2.Point, bool) {
+	start := p
+	for iter := 0; iter < 10; iter++ {
+		cx, cy := int(math.Round(p.X)), int(math.Round(p.Y))
+		var a, b, c, bx, by float64
+		for y := cy - refineRadius; y <= cy+refineRadius; y++ {
+			for x := cx - refineRadius; x <= cx+refineRadius; x++ {
+				gx := (g.at(x+1, y) - g.at(x-1, y)) / 2
+				gy := (g.at(x, y+1) - g.at(x, y-1)) / 2
+				a += gx * gx
+				b += gx * gy
+				c += gy * gy
+				bx += gx*gx*float64(x) + gx*gy*float64(y)
+				by += gx*gy*float64(x) + gy*gy*float64(y)
+			}
+		}
+		det := a*c - b*b
+		if det <= 1e-9*a*c {
+			return r2.Point{}, false
+		}
+		next := r2.Point{X: (c*bx - b*by) / det, Y: (a*by - b*bx) / det}
+		moved := next.Sub(p).Norm()
+		p = next
+		if p.Sub(start).Norm() > refineRadius {
+			return r2.Point{}, false
+		}
+		if moved < 0.01 {
+			break
+		}
+	}
+	if p.X < 0 || p.Y < 0 || p.X > float64(g.width-1) || p.Y > float64(g.height-1) {
+		return r2.Point{}, false
+	}
+	return p, true
+}
+
+// isChessboardCorner checks that a circle around a point crosses from dark to light four times, as it does around
+// the meeting of four squares but not along an edge or at the corner of a lone square.
+func isChessboardCorner(g *grayImage, p r2.Point) bool {
+	samples := make([]float64, ringSamples)
+	low, high := math.Inf(1), math.Inf(-1)
+	for i := range samples {
+		angle := 2 * math.Pi * float64(i) / ringSamples
+		samples[i] = g.interpolate(p.Add(r2.Point{X: ringRadius * math.Cos(angle), Y: ringRadius * math.Sin(angle)}))
+		low = math.Min(low, samples[i])
+		high = math.Max(high, samples[i])
+	}
+	if high-low < minContrast {
+		return false
+	}
+	mid := (low + high) / 2
+	crossings := 0
+	for i, s := range samples {
+		if (s > mid) != (samples[(i+1)%ringSamples] > mid) {
+			crossings++
+		}
+	}
+	return crossings == 4
+}
+
+// cell is the column and row of a corner in a grid.
+type cell [2]int
+
+// grid is the corners found around a seed corner, by the cell they are in.
+type grid struct {
+	points map[cell]r2.Point
+	// steps are the vectors between the seed and its neighbors, which predict corners with too few neighbors of
+	// their own to extrapolate from.
+	steps [2]r2.Point
+}
+
+// growGrid finds the corners connected to a seed corner in a grid. The nearest corner to the seed, and the nearest
+// in another direction, are its neighbors along each axis of the grid; from there, each corner's neighbors are
+// predicted by extrapolating the corners already found and matched to the nearest candidate.
+func growGrid(candidates []r2.Point, seed int) *grid {
+	nearest := func(p r2.Point, used map[int]bool, accept func(r2.Point) bool) (int, float64) {
+		best, bestDist := -1, math.Inf(1)
+		for i, c := range candidates {
+			if used[i] {
+				continue
+			}
+			d := c.Sub(p).Norm()
+			if d < bestDist && accept(c) {
+				best, bestDist = i, d
+			}
+		}
+		return best, bestDist
+	}
+	used := map[int]bool{seed: true}
+	origin := candidates[seed]
+	first, _ := nearest(origin, used, func(r2.Point) bool { return true })
+	if first < 0 {
+		return nil
+	}
+	step0 := candidates[first].Sub(origin)
+	second, _ := nearest(origin, used, func(c r2.Point) bool {
+		v := c.Sub(origin)
+		cos := v.Dot(step0) / (v.Norm() * step0.Norm())
+		return math.Abs(cos) < 0.7 && v.Norm() < 3*step0.Norm() && step0.Norm() < 3*v.Norm()
+	})
+	if second < 0 {
+		return nil
+	}
+	gr := &grid{
+		points: map[cell]r2.Point{{0, 0}: origin, {1, 0}: candidates[first], {0, 1}: candidates[second]},
+		steps:  [2]r2.Point{step0, candidates[second].Sub(origin)},
+	}
+	used[first], used[second] = true, true
+
+	for grown := true; grown; {
+		grown = false
+		cells := make([]cell, 0, len(gr.points))
+		for c := range gr.points {
+			cells = append(cells, c)
+		}
+		sort.Slice(cells, func(i, j int) bool {
+			return cells[i][1] < cells[j][1] || (cells[i][1] == cells[j][1] && cells[i][0] < cells[j][0])
+		})
+		for _, c := range cells {
+			for axis := 0; axis < 2; axis++ {
+				for _, sign := range []int{1, -1} {
+					next := c
+					next[axis] += sign
+					if _, ok := gr.points[next]; ok {
+						continue
+					}
+					step := gr.step(c, axis).Mul(float64(sign))
+					predicted := gr.points[c].Add(step)
+					match, dist := nearest(predicted, used, func(r2.Point) bool { return true })
+					if match < 0 || dist > gridTolerance*step.Norm() {
+						continue
+					}
+					gr.points[next] = candidates[match]
+					used[match] = true
+					grown = true
+				}
+			}
+		}
+	}
+	return gr
+}
+
+// step is the vector from a corner to its next neighbor along an axis, taken from the corners beside it when it has
+// them.
+func (gr *grid) step(c cell, axis int) r2.Point {
+	along := func(c cell) (r2.Point, bool) {
+		p, ok := gr.points[c]
+		if !ok {
+			return r2.Point{}, false
+		}
+		prev, next := c, c
+		prev[axis]--
+		next[axis]++
+		if q, ok := gr.points[prev]; ok {
+			return p.Sub(q), true
+		}
+		if q, ok := gr.points[next]; ok {
+			return q.Sub(p), true
+		}
+		return r2.Point{}, false
+	}
+	if s, ok := along(c); ok {
+		return s
+	}
+	for _, sign := range []int{-1, 1} {
+		beside := c
+		beside[1-axis] += sign
+		if s, ok := along(beside); ok {
+			return s
+		}
+	}
+	return gr.steps[axis]
+}
+
+// bounds returns the least and greatest cell of the grid along each axis.
+func (gr *grid) bounds() (cell, cell) {
+	low, high := cell{math.MaxInt, math.MaxInt}, cell{math.MinInt, math.MinInt}
+	for c := range gr.points {
+		for axis := 0; axis < 2; axis++ {
+			low[axis] = min(low[axis], c[axis])
+			high[axis] = max(high[axis], c[axis])
+		}
+	}
+	return low, high
+}
+
+// size is the number of cells of the grid along each axis.
+func (gr *grid) size() [2]int {
+	low, high := gr.bounds()
+	return [2]int{high[0] - low[0] + 1, high[1] - low[1] + 1}
+}
+
+// corners returns the corners of the grid in order when it is a complete board of rows by cols corners, or nil.
+func (gr *grid) corners(rows, cols int) []r2.Point {
+	low, _ := gr.bounds()
+	size := gr.size()
+	if len(gr.points) != size[0]*size[1] {
+		return nil
+	}
+	var orderings [][]r2.Point
+	for _, colAxis := range []int{0, 1} {
+		if size[colAxis] != cols || size[1-colAxis] != rows {
+			continue
+		}
+		for _, flip := range [][2]bool{{false, false}, {true, false}, {false, true}, {true, true}} {
+			points := make([]r2.Point, 0, rows*cols)
+			for r := 0; r < rows; r++ {
+				for c := 0; c < cols; c++ {
+					i, j := c, r
+					if flip[0] {
+						i = cols - 1 - c
+					}
+					if flip[1] {
+						j = rows - 1 - r
+					}
+					var at cell
+					at[colAxis], at[1-colAxis] = low[colAxis]+i, low[1-colAxis]+j
+					points = append(points, gr.points[at])
+				}
+			}
+			// columns run across the image and rows down it, as x and y do
+			across := points[cols-1].Sub(points[0])
+			down := points[(rows-1)*cols].Sub(points[0])
+			if across.Cross(down) > 0 {
+				orderings = append(orderings, points)
+			}
+		}
+	}
+	if len(orderings) == 0 {
+		return nil
+	}
+	best := orderings[0]
+	for _, points := range orderings[1:] {
+		if points[0].X+points[0].Y < best[0].X+best[0].Y {
+			best = points
+		}
+	}
+	return best
+}
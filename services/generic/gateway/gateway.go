@@ -0,0 +1,366 @@
+// Package gateway implements generic services which run a network of Zigbee or Z-Wave smart devices through a USB
+// coordinator stick, so that a machine can read the sensors and work the switches of a building without a separate hub.
+// The zigbee model drives a stick running TI Z-Stack ZNP firmware, and the zwave model a stick speaking the Z-Wave
+// Serial API.
+//
+// Paired devices are read and switched through smart_device sensors, or with DoCommand:
+//
+//	{"command": "devices"}
+//	{"command": "permit_join", "seconds": 60}
+//	{"command": "set", "device": "hall_light", "on": true}
+package gateway
+
+import (
+	"context"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jacobsa/go-serial/serial"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+)
+
+var (
+	// ZigbeeModel is the model of the Zigbee gateway service.
+	ZigbeeModel = resource.DefaultModelFamily.WithModel("zigbee")
+	// ZWaveModel is the model of the Z-Wave gateway service.
+	ZWaveModel = resource.DefaultModelFamily.WithModel("zwave")
+)
+
+const (
+	defaultBaudRate         = 115200
+	defaultPermitJoinSec    = 60
+	maxPermitJoinSec        = 254
+	startTimeout            = 10 * time.Second
+	coordinatorReplyTimeout = 5 * time.Second
+)
+
+func init() {
+	resource.RegisterService(generic.API, ZigbeeModel, resource.Registration[resource.Resource, *Config]{
+		Constructor: func(
+			ctx context.Context, _ resource.Dependencies, conf resource.Config, logger logging.Logger,
+		) (resource.Resource, error) {
+			return newGateway(ctx, conf, logger, newZigbeeCoordinator)
+		},
+	})
+	resource.RegisterService(generic.API, ZWaveModel, resource.Registration[resource.Resource, *Config]{
+		Constructor: func(
+			ctx context.Context, _ resource.Dependencies, conf resource.Config, logger logging.Logger,
+		) (resource.Resource, error) {
+			return newGateway(ctx, conf, logger, newZWaveCoordinator)
+		},
+	})
+}
+
+// DeviceConfig names a paired device.
+type DeviceConfig struct {
+	Name string `json:"name"`
+	// ID is the IEEE address of a Zigbee device, such as 0x00124b001c8a9f31, or node_ and the node ID of a Z-Wave
+	// device, such as node_5.
+	ID string `json:"id"`
+}
+
+// Config describes how to configure the service.
+type Config struct {
+	SerialPath string `json:"serial_path"`
+	// BaudRate defaults to 115200.
+	BaudRate int `json:"baud_rate,omitempty"`
+	// Devices names paired devices, so that they can be referred to by name rather than by ID.
+	Devices []DeviceConfig `json:"devices,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.SerialPath == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "serial_path")
+	}
+	if conf.BaudRate < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("baud_rate cannot be negative"))
+	}
+	names := map[string]bool{}
+	for _, device := range conf.Devices {
+		if device.Name == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "devices.name")
+		}
+		if device.ID == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "devices.id")
+		}
+		if names[device.Name] {
+			return nil, resource.NewConfigValidationError(path, errors.Errorf("device name %q is used twice", device.Name))
+		}
+		names[device.Name] = true
+	}
+	return nil, nil
+}
+
+// A Device is a device paired with a gateway.
+type Device struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Protocol string `json:"protocol"`
+	// Switch is whether the device has been seen to switch on and off.
+	Switch bool `json:"switch"`
+	// Readings are the latest values the device reported, such as "temperature_c" or "on".
+	Readings map[string]interface{} `json:"readings"`
+	// LastSeen is when the device was last heard from, and is zero for a device which has not been heard from since the
+	// gateway started.
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// A Gateway runs a network of smart devices.
+type Gateway interface {
+	resource.Resource
+	// Devices returns the devices paired with the gateway.
+	Devices() []Device
+	// Device returns a paired device by its name or ID.
+	Device(nameOrID string) (Device, error)
+	// PermitJoin lets new devices pair with the gateway for a while.
+	PermitJoin(ctx context.Context, duration time.Duration) error
+	// SetOn switches a device on or off.
+	SetOn(ctx context.Context, nameOrID string, on bool) error
+}
+
+// A coordinator speaks the protocol of a coordinator stick.
+type coordinator interface {
+	// start brings up the network of the stick.
+	start(ctx context.Context) error
+	permitJoin(ctx context.Context, seconds int) error
+	setOn(ctx context.Context, id string, on bool) error
+	// close stops reading the stick, once its port is closed.
+	close()
+}
+
+type newCoordinatorFunc func(port io.ReadWriter, devices *deviceTable, logger logging.Logger) coordinator
+
+// openPort opens the serial port of the stick, and is replaced in tests.
+var openPort = func(path string, baudRate int) (io.ReadWriteCloser, error) {
+	return serial.Open(serial.OpenOptions{
+		PortName:        path,
+		BaudRate:        uint(baudRate),
+		DataBits:        8,
+		StopBits:        1,
+		MinimumReadSize: 1,
+	})
+}
+
+// deviceTable holds what is known of the devices of a network as the coordinator hears from them.
+type deviceTable struct {
+	protocol string
+
+	mu      sync.Mutex
+	devices map[string]*Device
+}
+
+func newDeviceTable(protocol string) *deviceTable {
+	return &deviceTable{protocol: protocol, devices: map[string]*Device{}}
+}
+
+// add adds a device known to be paired, without it having been heard from.
+func (t *deviceTable) add(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.device(id)
+}
+
+// update records that a device was heard from, and changes what is known of it with fn, if it is not nil.
+func (t *deviceTable) update(id string, fn func(d *Device)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	d := t.device(id)
+	d.LastSeen = time.Now()
+	if fn != nil {
+		fn(d)
+	}
+}
+
+func (t *deviceTable) device(id string) *Device {
+	d, ok := t.devices[id]
+	if !ok {
+		d = &Device{ID: id, Protocol: t.protocol, Readings: map[string]interface{}{}}
+		t.devices[id] = d
+	}
+	return d
+}
+
+// list returns copies of the devices, sorted by ID.
+func (t *deviceTable) list() []Device {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	devices := make([]Device, 0, len(t.devices))
+	for _, d := range t.devices {
+		device := *d
+		device.Readings = make(map[string]interface{}, len(d.Readings))
+		for key, value := range d.Readings {
+			device.Readings[key] = value
+		}
+		devices = append(devices, device)
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].ID < devices[j].ID })
+	return devices
+}
+
+type gateway struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger      logging.Logger
+	port        io.ReadWriteCloser
+	devices     *deviceTable
+	coordinator coordinator
+	// names maps the names of devices to their IDs, and ids their IDs to their names.
+	names map[string]string
+	ids   map[string]string
+}
+
+func newGateway(
+	ctx context.Context,
+	conf resource.Config,
+	logger logging.Logger,
+	newCoordinator newCoordinatorFunc,
+) (Gateway, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	baudRate := newConf.BaudRate
+	if baudRate == 0 {
+		baudRate = defaultBaudRate
+	}
+	port, err := openPort(newConf.SerialPath, baudRate)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open coordinator stick at %q", newConf.SerialPath)
+	}
+
+	g := &gateway{
+		Named:   conf.ResourceName().AsNamed(),
+		logger:  logger,
+		port:    port,
+		devices: newDeviceTable(conf.Model.Name),
+		names:   map[string]string{},
+		ids:     map[string]string{},
+	}
+	for _, device := range newConf.Devices {
+		g.names[device.Name] = device.ID
+		g.ids[device.ID] = device.Name
+		g.devices.add(device.ID)
+	}
+	g.coordinator = newCoordinator(port, g.devices, logger)
+
+	startCtx, cancel := context.WithTimeout(ctx, startTimeout)
+	defer cancel()
+	if err := g.coordinator.start(startCtx); err != nil {
+		return nil, multierr.Combine(errors.Wrap(err, "cannot start coordinator stick"), g.Close(ctx))
+	}
+	return g, nil
+}
+
+// id returns the ID of a device given its name or ID.
+func (g *gateway) id(nameOrID string) string {
+	if id, ok := g.names[nameOrID]; ok {
+		return id
+	}
+	return nameOrID
+}
+
+func (g *gateway) Devices() []Device {
+	devices := g.devices.list()
+	for i := range devices {
+		devices[i].Name = g.ids[devices[i].ID]
+	}
+	return devices
+}
+
+func (g *gateway) Device(nameOrID string) (Device, error) {
+	id := g.id(nameOrID)
+	for _, device := range g.Devices() {
+		if device.ID == id {
+			return device, nil
+		}
+	}
+	return Device{}, errors.Errorf("no device %q is paired with the gateway", nameOrID)
+}
+
+func (g *gateway) PermitJoin(ctx context.Context, duration time.Duration) error {
+	seconds := int(duration.Seconds())
+	if seconds <= 0 || seconds > maxPermitJoinSec {
+		return errors.Errorf("devices can be permitted to join for 1 to %d seconds, not %d", maxPermitJoinSec, seconds)
+	}
+	g.logger.CInfow(ctx, "permitting devices to join", "seconds", seconds)
+	return g.coordinator.permitJoin(ctx, seconds)
+}
+
+func (g *gateway) SetOn(ctx context.Context, nameOrID string, on bool) error {
+	id := g.id(nameOrID)
+	if err := g.coordinator.setOn(ctx, id, on); err != nil {
+		return errors.Wrapf(err, "cannot switch device %q", nameOrID)
+	}
+	// the device will report its new state, but until it does it is taken to have switched
+	g.devices.update(id, func(d *Device) {
+		d.Switch = true
+		d.Readings["on"] = on
+	})
+	return nil
+}
+
+func (g *gateway) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	switch cmd["command"] {
+	case "devices":
+		devices := []interface{}{}
+		for _, device := range g.Devices() {
+			devices = append(devices, deviceToMap(device))
+		}
+		return map[string]interface{}{"devices": devices}, nil
+	case "permit_join":
+		seconds := float64(defaultPermitJoinSec)
+		if s, ok := cmd["seconds"].(float64); ok {
+			seconds = s
+		}
+		if err := g.PermitJoin(ctx, time.Duration(seconds*float64(time.Second))); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{}, nil
+	case "set":
+		device, ok := cmd["device"].(string)
+		if !ok {
+			return nil, errors.New("set needs the device to switch")
+		}
+		on, ok := cmd["on"].(bool)
+		if !ok {
+			return nil, errors.New("set needs on to be true or false")
+		}
+		if err := g.SetOn(ctx, device, on); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{}, nil
+	default:
+		return nil, resource.ErrDoUnimplemented
+	}
+}
+
+func deviceToMap(device Device) map[string]interface{} {
+	m := map[string]interface{}{
+		"id":       device.ID,
+		"protocol": device.Protocol,
+		"switch":   device.Switch,
+		"readings": device.Readings,
+	}
+	if device.Name != "" {
+		m["name"] = device.Name
+	}
+	if !device.LastSeen.IsZero() {
+		m["last_seen"] = device.LastSeen.Format(time.RFC3339)
+	}
+	return m
+}
+
+func (g *gateway) Close(ctx context.Context) error {
+	// closing the port ends the reads of the coordinator
+	err := g.port.Close()
+	g.coordinator.close()
+	return err
+}
@@ -0,0 +1,490 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+// The framing and commands of the Z-Stack monitor and test (MT) protocol spoken by a ZNP stick.
+const (
+	znpSOF = 0xfe
+
+	znpTypeSREQ = 0x20
+	znpTypeAREQ = 0x40
+	znpTypeSRSP = 0x60
+	znpTypeMask = 0xe0
+
+	znpSubsystemAF  = 0x04
+	znpSubsystemZDO = 0x05
+
+	znpAFRegister    = 0x00
+	znpAFDataRequest = 0x01
+	znpAFIncomingMsg = 0x81
+
+	znpZDOIEEEAddrReq       = 0x01
+	znpZDOMgmtPermitJoinReq = 0x36
+	znpZDOStartupFromApp    = 0x40
+	znpZDOIEEEAddrRsp       = 0x81
+	znpZDOEndDeviceAnnceInd = 0xc1
+
+	// znpStatusAlreadyRegistered is returned registering an endpoint the stick kept from before.
+	znpStatusAlreadyRegistered = 0xb8
+)
+
+// The parts of the Zigbee Cluster Library the gateway understands.
+const (
+	zigbeeEndpoint         = 1
+	zigbeeProfileHA        = 0x0104
+	zigbeeDeviceConfigTool = 0x0005
+	zigbeeRadius           = 30
+
+	zclFrameTypeMask          = 0x03
+	zclFrameClusterSpecific   = 0x01
+	zclFrameManufacturer      = 0x04
+	zclReadAttributesResponse = 0x01
+	zclReportAttributes       = 0x0a
+
+	zclClusterPowerConfig = 0x0001
+	zclClusterOnOff       = 0x0006
+	zclClusterLevel       = 0x0008
+	zclClusterIlluminance = 0x0400
+	zclClusterTemperature = 0x0402
+	zclClusterPressure    = 0x0403
+	zclClusterHumidity    = 0x0405
+	zclClusterOccupancy   = 0x0406
+)
+
+type znpFrame struct {
+	cmd0, cmd1 byte
+	data       []byte
+}
+
+func (f znpFrame) encode() []byte {
+	frame := append([]byte{znpSOF, byte(len(f.data)), f.cmd0, f.cmd1}, f.data...)
+	var fcs byte
+	for _, b := range frame[1:] {
+		fcs ^= b
+	}
+	return append(frame, fcs)
+}
+
+// decodeZNPFrame reads the next frame, skipping anything before its start.
+func decodeZNPFrame(r *bufio.Reader) (znpFrame, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return znpFrame{}, err
+		}
+		if b == znpSOF {
+			break
+		}
+	}
+	header := make([]byte, 3)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return znpFrame{}, err
+	}
+	rest := make([]byte, int(header[0])+1)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return znpFrame{}, err
+	}
+	var fcs byte
+	for _, b := range append(header, rest...) {
+		fcs ^= b
+	}
+	if fcs != 0 {
+		return znpFrame{}, errors.New("frame check sequence does not match")
+	}
+	return znpFrame{cmd0: header[1], cmd1: header[2], data: rest[:len(rest)-1]}, nil
+}
+
+// zigbeeAddress is where on the network a device is.
+type zigbeeAddress struct {
+	nwk      uint16
+	endpoint byte
+}
+
+// zigbeeCoordinator speaks to a stick running Z-Stack ZNP firmware, which forms the network itself on startup.
+type zigbeeCoordinator struct {
+	port    io.ReadWriter
+	devices *deviceTable
+	logger  logging.Logger
+	workers rdkutils.StoppableWorkers
+
+	// requestMu holds the stick through a request and its response, since it answers one at a time.
+	requestMu sync.Mutex
+	responses chan znpFrame
+	transID   byte
+
+	mu sync.Mutex
+	// ieee maps the network addresses of devices to their IDs, and addresses their IDs to their addresses.
+	ieee      map[uint16]string
+	addresses map[string]zigbeeAddress
+	// unresolved holds the network addresses of devices heard from which the IDs of are to be asked for.
+	unresolved chan uint16
+	resolving  map[uint16]bool
+}
+
+func newZigbeeCoordinator(port io.ReadWriter, devices *deviceTable, logger logging.Logger) coordinator {
+	c := &zigbeeCoordinator{
+		port:       port,
+		devices:    devices,
+		logger:     logger,
+		responses:  make(chan znpFrame, 1),
+		ieee:       map[uint16]string{},
+		addresses:  map[string]zigbeeAddress{},
+		unresolved: make(chan uint16, 16),
+		resolving:  map[uint16]bool{},
+	}
+	c.workers = rdkutils.NewStoppableWorkers(c.readFrames, c.resolveAddresses)
+	return c
+}
+
+func (c *zigbeeCoordinator) readFrames(ctx context.Context) {
+	reader := bufio.NewReader(c.port)
+	for {
+		frame, err := decodeZNPFrame(reader)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) {
+				return
+			}
+			c.logger.CWarnw(ctx, "dropping frame from zigbee stick", "error", err)
+			continue
+		}
+		switch frame.cmd0 & znpTypeMask {
+		case znpTypeSRSP:
+			select {
+			case c.responses <- frame:
+			default:
+				c.logger.CDebugw(ctx, "dropping unexpected response from zigbee stick", "cmd0", frame.cmd0, "cmd1", frame.cmd1)
+			}
+		case znpTypeAREQ:
+			c.handle(ctx, frame)
+		}
+	}
+}
+
+// request sends a synchronous request to the stick and returns its response.
+func (c *zigbeeCoordinator) request(ctx context.Context, subsystem, command byte, data []byte) (znpFrame, error) {
+	c.requestMu.Lock()
+	defer c.requestMu.Unlock()
+	select {
+	case <-c.responses:
+	default:
+	}
+	if _, err := c.port.Write(znpFrame{cmd0: znpTypeSREQ | subsystem, cmd1: command, data: data}.encode()); err != nil {
+		return znpFrame{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, coordinatorReplyTimeout)
+	defer cancel()
+	for {
+		select {
+		case <-ctx.Done():
+			return znpFrame{}, errors.Wrapf(ctx.Err(), "no response from zigbee stick to command 0x%02x%02x", subsystem, command)
+		case response := <-c.responses:
+			if response.cmd0 != znpTypeSRSP|subsystem || response.cmd1 != command {
+				continue
+			}
+			return response, nil
+		}
+	}
+}
+
+// requestStatus sends a request whose response is a status, and returns an error unless it succeeded.
+func (c *zigbeeCoordinator) requestStatus(ctx context.Context, subsystem, command byte, data []byte, ok ...byte) error {
+	response, err := c.request(ctx, subsystem, command, data)
+	if err != nil {
+		return err
+	}
+	if len(response.data) == 0 {
+		return errors.Errorf("empty response from zigbee stick to command 0x%02x%02x", subsystem, command)
+	}
+	status := response.data[0]
+	if status == 0 {
+		return nil
+	}
+	for _, b := range ok {
+		if status == b {
+			return nil
+		}
+	}
+	return errors.Errorf("zigbee stick failed command 0x%02x%02x with status 0x%02x", subsystem, command, status)
+}
+
+func (c *zigbeeCoordinator) start(ctx context.Context) error {
+	// a status of 1 or 0 is a network started anew or restored, and 2 is a stick which left its network
+	response, err := c.request(ctx, znpSubsystemZDO, znpZDOStartupFromApp, []byte{0, 0})
+	if err != nil {
+		return err
+	}
+	if len(response.data) == 0 || response.data[0] > 1 {
+		return errors.New("zigbee stick could not start its network")
+	}
+	register := []byte{zigbeeEndpoint}
+	register = binary.LittleEndian.AppendUint16(register, zigbeeProfileHA)
+	register = binary.LittleEndian.AppendUint16(register, zigbeeDeviceConfigTool)
+	// version, latency, and no input or output clusters, since all are received
+	register = append(register, 0, 0, 0, 0)
+	return c.requestStatus(ctx, znpSubsystemAF, znpAFRegister, register, znpStatusAlreadyRegistered)
+}
+
+func (c *zigbeeCoordinator) permitJoin(ctx context.Context, seconds int) error {
+	// broadcast to all routers and the coordinator itself
+	data := []byte{0x0f}
+	data = binary.LittleEndian.AppendUint16(data, 0xfffc)
+	data = append(data, byte(seconds), 0)
+	return c.requestStatus(ctx, znpSubsystemZDO, znpZDOMgmtPermitJoinReq, data)
+}
+
+func (c *zigbeeCoordinator) setOn(ctx context.Context, id string, on bool) error {
+	c.mu.Lock()
+	address, ok := c.addresses[id]
+	c.mu.Unlock()
+	if !ok {
+		return errors.Errorf("zigbee device %q has not been heard from", id)
+	}
+	command := byte(0x00)
+	if on {
+		command = 0x01
+	}
+	c.requestMu.Lock()
+	c.transID++
+	transID := c.transID
+	c.requestMu.Unlock()
+
+	zcl := []byte{zclFrameClusterSpecific, transID, command}
+	data := binary.LittleEndian.AppendUint16(nil, address.nwk)
+	data = append(data, address.endpoint, zigbeeEndpoint)
+	data = binary.LittleEndian.AppendUint16(data, zclClusterOnOff)
+	data = append(data, transID, 0, zigbeeRadius, byte(len(zcl)))
+	data = append(data, zcl...)
+	return c.requestStatus(ctx, znpSubsystemAF, znpAFDataRequest, data)
+}
+
+// handle handles a message the stick sent of its own accord.
+func (c *zigbeeCoordinator) handle(ctx context.Context, frame znpFrame) {
+	data := frame.data
+	switch {
+	case frame.cmd0 == znpTypeAREQ|znpSubsystemZDO && frame.cmd1 == znpZDOEndDeviceAnnceInd && len(data) >= 12:
+		nwk := binary.LittleEndian.Uint16(data[2:4])
+		id := zigbeeID(binary.LittleEndian.Uint64(data[4:12]))
+		c.logger.CInfow(ctx, "zigbee device joined", "id", id)
+		c.learn(nwk, id, 0)
+		c.devices.update(id, nil)
+	case frame.cmd0 == znpTypeAREQ|znpSubsystemZDO && frame.cmd1 == znpZDOIEEEAddrRsp && len(data) >= 11:
+		if data[0] != 0 {
+			return
+		}
+		id := zigbeeID(binary.LittleEndian.Uint64(data[1:9]))
+		nwk := binary.LittleEndian.Uint16(data[9:11])
+		c.learn(nwk, id, 0)
+		c.devices.add(id)
+	case frame.cmd0 == znpTypeAREQ|znpSubsystemAF && frame.cmd1 == znpAFIncomingMsg && len(data) >= 17:
+		cluster := binary.LittleEndian.Uint16(data[2:4])
+		nwk := binary.LittleEndian.Uint16(data[4:6])
+		endpoint := data[6]
+		length := int(data[16])
+		if len(data) < 17+length {
+			return
+		}
+		c.mu.Lock()
+		id, ok := c.ieee[nwk]
+		c.mu.Unlock()
+		if !ok {
+			// the device joined before the gateway started, so its ID is asked for and what it sent is dropped
+			c.resolve(nwk)
+			return
+		}
+		if cluster == zclClusterOnOff {
+			c.learn(nwk, id, endpoint)
+		}
+		readings := zclReadings(cluster, data[17:17+length])
+		c.devices.update(id, func(d *Device) {
+			for key, value := range readings {
+				d.Readings[key] = value
+			}
+			if cluster == zclClusterOnOff {
+				d.Switch = true
+			}
+		})
+	}
+}
+
+// learn records the address of a device, and the endpoint it switches on, if it is not 0.
+func (c *zigbeeCoordinator) learn(nwk uint16, id string, endpoint byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if old, ok := c.addresses[id]; ok && old.nwk != nwk {
+		delete(c.ieee, old.nwk)
+	}
+	address := c.addresses[id]
+	address.nwk = nwk
+	if endpoint != 0 {
+		address.endpoint = endpoint
+	}
+	if address.endpoint == 0 {
+		address.endpoint = zigbeeEndpoint
+	}
+	c.addresses[id] = address
+	c.ieee[nwk] = id
+	delete(c.resolving, nwk)
+}
+
+// resolve queues a request for the ID of a device, once.
+func (c *zigbeeCoordinator) resolve(nwk uint16) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.resolving[nwk] {
+		return
+	}
+	select {
+	case c.unresolved <- nwk:
+		c.resolving[nwk] = true
+	default:
+	}
+}
+
+// resolveAddresses asks the network for the IDs of devices, apart from the reading of frames, which the responses
+// come through.
+func (c *zigbeeCoordinator) resolveAddresses(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case nwk := <-c.unresolved:
+			data := binary.LittleEndian.AppendUint16(nil, nwk)
+			data = append(data, 0, 0)
+			if err := c.requestStatus(ctx, znpSubsystemZDO, znpZDOIEEEAddrReq, data); err != nil {
+				c.logger.CDebugw(ctx, "cannot ask for the address of zigbee device", "nwk", nwk, "error", err)
+				c.mu.Lock()
+				delete(c.resolving, nwk)
+				c.mu.Unlock()
+			}
+		}
+	}
+}
+
+func (c *zigbeeCoordinator) close() {
+	c.workers.Stop()
+}
+
+func zigbeeID(ieee uint64) string {
+	return fmt.Sprintf("0x%016x", ieee)
+}
+
+// zclReadings returns the readings in a report or read of the attributes of a cluster.
+func zclReadings(cluster uint16, zcl []byte) map[string]interface{} {
+	readings := map[string]interface{}{}
+	if len(zcl) < 3 || zcl[0]&zclFrameTypeMask != 0 {
+		return readings
+	}
+	header := 3
+	if zcl[0]&zclFrameManufacturer != 0 {
+		header = 5
+	}
+	if len(zcl) < header {
+		return readings
+	}
+	command, records := zcl[header-1], zcl[header:]
+	if command != zclReportAttributes && command != zclReadAttributesResponse {
+		return readings
+	}
+	for len(records) >= 3 {
+		attribute := binary.LittleEndian.Uint16(records)
+		records = records[2:]
+		if command == zclReadAttributesResponse {
+			status := records[0]
+			records = records[1:]
+			if status != 0 {
+				continue
+			}
+		}
+		if len(records) < 1 {
+			break
+		}
+		value, size, ok := zclValue(records[0], records[1:])
+		if !ok {
+			break
+		}
+		records = records[1+size:]
+		name, reading := zclReading(cluster, attribute, value)
+		readings[name] = reading
+	}
+	return readings
+}
+
+// zclValue decodes a value of a ZCL data type, returning it as a float64 or bool and how many bytes it took.
+func zclValue(dataType byte, b []byte) (interface{}, int, bool) {
+	var size int
+	switch dataType {
+	case 0x10, 0x18, 0x20, 0x28, 0x30:
+		size = 1
+	case 0x19, 0x21, 0x29, 0x31:
+		size = 2
+	case 0x23, 0x2b, 0x39:
+		size = 4
+	default:
+		return nil, 0, false
+	}
+	if len(b) < size {
+		return nil, 0, false
+	}
+	switch dataType {
+	case 0x10:
+		return b[0] != 0, size, true
+	case 0x18, 0x20, 0x30:
+		return float64(b[0]), size, true
+	case 0x28:
+		return float64(int8(b[0])), size, true
+	case 0x19, 0x21, 0x31:
+		return float64(binary.LittleEndian.Uint16(b)), size, true
+	case 0x29:
+		return float64(int16(binary.LittleEndian.Uint16(b))), size, true
+	case 0x23:
+		return float64(binary.LittleEndian.Uint32(b)), size, true
+	case 0x2b:
+		return float64(int32(binary.LittleEndian.Uint32(b))), size, true
+	default:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))), size, true
+	}
+}
+
+// zclReading names an attribute of a cluster, and converts its value to the units of the name.
+func zclReading(cluster, attribute uint16, value interface{}) (string, interface{}) {
+	v, _ := value.(float64)
+	switch {
+	case cluster == zclClusterOnOff && attribute == 0x0000:
+		return "on", value
+	case cluster == zclClusterLevel && attribute == 0x0000:
+		return "level_pct", v / 254 * 100
+	case cluster == zclClusterTemperature && attribute == 0x0000:
+		return "temperature_c", v / 100
+	case cluster == zclClusterHumidity && attribute == 0x0000:
+		return "humidity_pct", v / 100
+	case cluster == zclClusterPressure && attribute == 0x0000:
+		return "pressure_hpa", v
+	case cluster == zclClusterIlluminance && attribute == 0x0000:
+		if v == 0 {
+			return "illuminance_lux", 0.
+		}
+		return "illuminance_lux", math.Pow(10, (v-1)/10000)
+	case cluster == zclClusterOccupancy && attribute == 0x0000:
+		return "occupied", int(v)&1 == 1
+	case cluster == zclClusterPowerConfig && attribute == 0x0021:
+		return "battery_pct", v / 2
+	default:
+		return fmt.Sprintf("cluster_0x%04x_attribute_0x%04x", cluster, attribute), value
+	}
+}
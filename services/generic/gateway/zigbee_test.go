@@ -0,0 +1,190 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+)
+
+// fakeZigbeeStick answers every request it reads with success, and passes the requests on.
+func fakeZigbeeStick(t *testing.T, stick net.Conn) <-chan znpFrame {
+	t.Helper()
+	requests := make(chan znpFrame, 16)
+	go func() {
+		reader := bufio.NewReader(stick)
+		for {
+			frame, err := decodeZNPFrame(reader)
+			if err != nil {
+				return
+			}
+			requests <- frame
+			response := znpFrame{cmd0: znpTypeSRSP | frame.cmd0&^znpTypeMask, cmd1: frame.cmd1, data: []byte{0}}
+			if _, err := stick.Write(response.encode()); err != nil {
+				return
+			}
+			if frame.cmd0 == znpTypeSREQ|znpSubsystemZDO && frame.cmd1 == znpZDOIEEEAddrReq {
+				nwk := binary.LittleEndian.Uint16(frame.data)
+				rsp := []byte{0}
+				rsp = binary.LittleEndian.AppendUint64(rsp, 0x00124b0000000002)
+				rsp = binary.LittleEndian.AppendUint16(rsp, nwk)
+				areq := znpFrame{cmd0: znpTypeAREQ | znpSubsystemZDO, cmd1: znpZDOIEEEAddrRsp, data: append(rsp, 0, 0)}
+				if _, err := stick.Write(areq.encode()); err != nil {
+					return
+				}
+			}
+		}
+	}()
+	return requests
+}
+
+// nextRequest returns the next request to the stick with the given command, skipping others.
+func nextRequest(t *testing.T, requests <-chan znpFrame, cmd0, cmd1 byte) znpFrame {
+	t.Helper()
+	for request := range requests {
+		if request.cmd0 == cmd0 && request.cmd1 == cmd1 {
+			return request
+		}
+	}
+	t.Fatal("the stick closed")
+	return znpFrame{}
+}
+
+func incomingMsg(nwk, cluster uint16, zcl []byte) []byte {
+	data := []byte{0, 0}
+	data = binary.LittleEndian.AppendUint16(data, cluster)
+	data = binary.LittleEndian.AppendUint16(data, nwk)
+	// source and destination endpoints, broadcast, link quality, security and timestamp
+	data = append(data, 1, zigbeeEndpoint, 0, 255, 0, 0, 0, 0, 0, 0, byte(len(zcl)))
+	data = append(data, zcl...)
+	return znpFrame{cmd0: znpTypeAREQ | znpSubsystemAF, cmd1: znpAFIncomingMsg, data: data}.encode()
+}
+
+func TestZigbee(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	host, stick := net.Pipe()
+	realOpenPort := openPort
+	openPort = func(path string, baudRate int) (io.ReadWriteCloser, error) {
+		test.That(t, baudRate, test.ShouldEqual, defaultBaudRate)
+		return host, nil
+	}
+	defer func() {
+		openPort = realOpenPort
+	}()
+	requests := fakeZigbeeStick(t, stick)
+
+	conf := resource.Config{
+		Name:  "zigbee",
+		API:   generic.API,
+		Model: ZigbeeModel,
+		ConvertedAttributes: &Config{
+			SerialPath: "/dev/ttyACM0",
+			Devices:    []DeviceConfig{{Name: "lamp", ID: "0x00124b0000000001"}},
+		},
+	}
+	gw, err := newGateway(ctx, conf, logger, newZigbeeCoordinator)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, gw.Close(ctx), test.ShouldBeNil)
+	}()
+	startup := <-requests
+	test.That(t, startup.cmd1, test.ShouldEqual, znpZDOStartupFromApp)
+	register := <-requests
+	test.That(t, register.cmd1, test.ShouldEqual, znpAFRegister)
+	test.That(t, register.data[0], test.ShouldEqual, zigbeeEndpoint)
+
+	lamp, err := gw.Device("lamp")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, lamp.ID, test.ShouldEqual, "0x00124b0000000001")
+	test.That(t, lamp.Protocol, test.ShouldEqual, "zigbee")
+	test.That(t, lamp.LastSeen.IsZero(), test.ShouldBeTrue)
+
+	// the lamp announces itself and reports that it is on
+	announce := []byte{0x34, 0x12, 0x34, 0x12}
+	announce = binary.LittleEndian.AppendUint64(announce, 0x00124b0000000001)
+	_, err = stick.Write(znpFrame{cmd0: znpTypeAREQ | znpSubsystemZDO, cmd1: znpZDOEndDeviceAnnceInd, data: append(announce, 0x8e)}.encode())
+	test.That(t, err, test.ShouldBeNil)
+	_, err = stick.Write(incomingMsg(0x1234, zclClusterOnOff, []byte{0x18, 1, zclReportAttributes, 0, 0, 0x10, 1}))
+	test.That(t, err, test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		lamp, err := gw.Device("lamp")
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, lamp.Switch, test.ShouldBeTrue)
+		test.That(tb, lamp.Readings["on"], test.ShouldEqual, true)
+		test.That(tb, lamp.LastSeen.IsZero(), test.ShouldBeFalse)
+	})
+
+	test.That(t, gw.SetOn(ctx, "lamp", false), test.ShouldBeNil)
+	send := nextRequest(t, requests, znpTypeSREQ|znpSubsystemAF, znpAFDataRequest)
+	test.That(t, binary.LittleEndian.Uint16(send.data), test.ShouldEqual, 0x1234)
+	test.That(t, binary.LittleEndian.Uint16(send.data[4:]), test.ShouldEqual, zclClusterOnOff)
+	test.That(t, send.data[len(send.data)-1], test.ShouldEqual, 0x00)
+	lamp, err = gw.Device("lamp")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, lamp.Readings["on"], test.ShouldEqual, false)
+
+	// a sensor which joined before the gateway started is looked up before its reports are read
+	temperature := []byte{0x18, 2, zclReportAttributes, 0, 0, 0x29}
+	temperature = binary.LittleEndian.AppendUint16(temperature, 2150)
+	_, err = stick.Write(incomingMsg(0x5678, zclClusterTemperature, temperature))
+	test.That(t, err, test.ShouldBeNil)
+	lookup := nextRequest(t, requests, znpTypeSREQ|znpSubsystemZDO, znpZDOIEEEAddrReq)
+	test.That(t, binary.LittleEndian.Uint16(lookup.data), test.ShouldEqual, 0x5678)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		_, err := gw.Device("0x00124b0000000002")
+		test.That(tb, err, test.ShouldBeNil)
+	})
+	_, err = stick.Write(incomingMsg(0x5678, zclClusterTemperature, temperature))
+	test.That(t, err, test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		sensor, err := gw.Device("0x00124b0000000002")
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, sensor.Readings["temperature_c"], test.ShouldEqual, 21.5)
+		test.That(tb, sensor.Switch, test.ShouldBeFalse)
+	})
+
+	_, err = gw.DoCommand(ctx, map[string]interface{}{"command": "permit_join", "seconds": 30.})
+	test.That(t, err, test.ShouldBeNil)
+	permit := nextRequest(t, requests, znpTypeSREQ|znpSubsystemZDO, znpZDOMgmtPermitJoinReq)
+	test.That(t, permit.data[3], test.ShouldEqual, 30)
+	_, err = gw.DoCommand(ctx, map[string]interface{}{"command": "permit_join", "seconds": 300.})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	resp, err := gw.DoCommand(ctx, map[string]interface{}{"command": "devices"})
+	test.That(t, err, test.ShouldBeNil)
+	devices := resp["devices"].([]interface{})
+	test.That(t, devices, test.ShouldHaveLength, 2)
+	test.That(t, devices[0].(map[string]interface{})["name"], test.ShouldEqual, "lamp")
+
+	// a device which has not been heard from cannot be switched
+	test.That(t, gw.SetOn(ctx, "0x00124b00000000ff", true), test.ShouldNotBeNil)
+}
+
+func TestZCLReadings(t *testing.T) {
+	humidity := []byte{0x18, 1, zclReadAttributesResponse, 0, 0, 0, 0x21}
+	humidity = binary.LittleEndian.AppendUint16(humidity, 4550)
+	test.That(t, zclReadings(zclClusterHumidity, humidity), test.ShouldResemble, map[string]interface{}{"humidity_pct": 45.5})
+
+	battery := []byte{0x18, 1, zclReportAttributes, 0x21, 0, 0x20, 150}
+	test.That(t, zclReadings(zclClusterPowerConfig, battery), test.ShouldResemble, map[string]interface{}{"battery_pct": 75.})
+
+	occupancy := []byte{0x18, 1, zclReportAttributes, 0, 0, 0x18, 1}
+	test.That(t, zclReadings(zclClusterOccupancy, occupancy), test.ShouldResemble, map[string]interface{}{"occupied": true})
+
+	// commands specific to a cluster are not reports
+	test.That(t, zclReadings(zclClusterOnOff, []byte{zclFrameClusterSpecific, 1, 0x01}), test.ShouldBeEmpty)
+}
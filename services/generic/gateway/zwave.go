@@ -0,0 +1,527 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/logging"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+// The framing and functions of the Z-Wave Serial API.
+const (
+	zwaveSOF = 0x01
+	zwaveACK = 0x06
+	zwaveNAK = 0x15
+	zwaveCAN = 0x18
+
+	zwaveTypeRequest  = 0x00
+	zwaveTypeResponse = 0x01
+
+	zwaveGetInitData           = 0x02
+	zwaveApplicationCommand    = 0x04
+	zwaveSendData              = 0x13
+	zwaveMemoryGetID           = 0x20
+	zwaveApplicationUpdate     = 0x49
+	zwaveAddNodeToNetwork      = 0x4a
+	zwaveAckTimeout            = 1600 * time.Millisecond
+	zwaveMaxSendAttempts       = 3
+	zwaveNodeBitmaskLen        = 29
+	zwaveTransmitOptions       = 0x25 // acknowledged, auto routed and explored
+	zwaveAddNodeAny            = 0x01
+	zwaveAddNodeHighPower      = 0x80
+	zwaveAddNodeStop           = 0x05
+	zwaveAddNodeStatusSlave    = 0x03
+	zwaveAddNodeStatusDone     = 0x05
+	zwaveAddNodeStatusFinished = 0x06
+	zwaveUpdateNodeInfo        = 0x84
+)
+
+// The command classes the gateway understands.
+const (
+	zwaveClassSwitchBinary     = 0x25
+	zwaveClassSensorBinary     = 0x30
+	zwaveClassSensorMultilevel = 0x31
+	zwaveClassBattery          = 0x80
+
+	zwaveSwitchBinarySet   = 0x01
+	zwaveReport            = 0x03
+	zwaveMultilevelReport  = 0x05
+	zwaveBatteryLowWarning = 0xff
+)
+
+type zwaveFrame struct {
+	frameType byte
+	function  byte
+	payload   []byte
+}
+
+func (f zwaveFrame) encode() []byte {
+	frame := append([]byte{zwaveSOF, byte(len(f.payload) + 3), f.frameType, f.function}, f.payload...)
+	checksum := byte(0xff)
+	for _, b := range frame[1:] {
+		checksum ^= b
+	}
+	return append(frame, checksum)
+}
+
+// zwaveCoordinator speaks to a Z-Wave controller stick through its Serial API.
+type zwaveCoordinator struct {
+	port    io.ReadWriter
+	devices *deviceTable
+	logger  logging.Logger
+	workers rdkutils.StoppableWorkers
+
+	// writeMu keeps acknowledgements from being written into the middle of a frame.
+	writeMu sync.Mutex
+	// requestMu holds the stick through a request and its response, since it answers one at a time.
+	requestMu  sync.Mutex
+	acks       chan byte
+	responses  chan zwaveFrame
+	callbackID byte
+
+	mu sync.Mutex
+	// sends are waiting for the transmit status of the frames they sent, by callback ID.
+	sends map[byte]chan byte
+	// inclusion stops devices from being added to the network, and is nil when they cannot be.
+	inclusion *time.Timer
+	// later holds requests to make apart from the reading of frames, which their responses come through.
+	later chan func(ctx context.Context)
+}
+
+func newZWaveCoordinator(port io.ReadWriter, devices *deviceTable, logger logging.Logger) coordinator {
+	c := &zwaveCoordinator{
+		port:      port,
+		devices:   devices,
+		logger:    logger,
+		acks:      make(chan byte, 1),
+		responses: make(chan zwaveFrame, 1),
+		sends:     map[byte]chan byte{},
+		later:     make(chan func(ctx context.Context), 4),
+	}
+	c.workers = rdkutils.NewStoppableWorkers(c.readFrames, c.runLater)
+	return c
+}
+
+func (c *zwaveCoordinator) write(b []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.port.Write(b)
+	return err
+}
+
+func (c *zwaveCoordinator) readFrames(ctx context.Context) {
+	reader := bufio.NewReader(c.port)
+	for {
+		frame, err := c.readFrame(reader)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) {
+				return
+			}
+			c.logger.CWarnw(ctx, "dropping frame from z-wave stick", "error", err)
+			continue
+		}
+		if frame == nil {
+			continue
+		}
+		switch frame.frameType {
+		case zwaveTypeResponse:
+			select {
+			case c.responses <- *frame:
+			default:
+				c.logger.CDebugw(ctx, "dropping unexpected response from z-wave stick", "function", frame.function)
+			}
+		case zwaveTypeRequest:
+			c.handle(ctx, *frame)
+		}
+	}
+}
+
+// readFrame reads the next frame and acknowledges it, or passes on an acknowledgement and returns nil.
+func (c *zwaveCoordinator) readFrame(r *bufio.Reader) (*zwaveFrame, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch b {
+	case zwaveACK, zwaveNAK, zwaveCAN:
+		select {
+		case c.acks <- b:
+		default:
+		}
+		return nil, nil
+	case zwaveSOF:
+	default:
+		return nil, nil
+	}
+	length, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if length < 3 {
+		return nil, errors.Errorf("frame length %d is too short", length)
+	}
+	rest := make([]byte, length)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, err
+	}
+	checksum := byte(0xff) ^ length
+	for _, b := range rest {
+		checksum ^= b
+	}
+	if checksum != 0 {
+		return nil, multierr.Combine(errors.New("frame checksum does not match"), c.write([]byte{zwaveNAK}))
+	}
+	if err := c.write([]byte{zwaveACK}); err != nil {
+		return nil, err
+	}
+	return &zwaveFrame{frameType: rest[0], function: rest[1], payload: rest[2 : len(rest)-1]}, nil
+}
+
+// send sends a frame to the stick until it is acknowledged.
+func (c *zwaveCoordinator) send(ctx context.Context, function byte, payload []byte) error {
+	frame := zwaveFrame{frameType: zwaveTypeRequest, function: function, payload: payload}.encode()
+	for attempt := 0; attempt < zwaveMaxSendAttempts; attempt++ {
+		select {
+		case <-c.acks:
+		default:
+		}
+		if err := c.write(frame); err != nil {
+			return err
+		}
+		timer := time.NewTimer(zwaveAckTimeout)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case ack := <-c.acks:
+			timer.Stop()
+			if ack == zwaveACK {
+				return nil
+			}
+		case <-timer.C:
+		}
+	}
+	return errors.Errorf("z-wave stick did not acknowledge function 0x%02x", function)
+}
+
+// request sends a request to the stick and returns its response, or nil if the function has none.
+func (c *zwaveCoordinator) request(ctx context.Context, function byte, payload []byte, hasResponse bool) ([]byte, error) {
+	c.requestMu.Lock()
+	defer c.requestMu.Unlock()
+	select {
+	case <-c.responses:
+	default:
+	}
+	if err := c.send(ctx, function, payload); err != nil {
+		return nil, err
+	}
+	if !hasResponse {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, coordinatorReplyTimeout)
+	defer cancel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrapf(ctx.Err(), "no response from z-wave stick to function 0x%02x", function)
+		case response := <-c.responses:
+			if response.function == function {
+				return response.payload, nil
+			}
+		}
+	}
+}
+
+// nextCallbackID returns an ID for the stick to call back with, which is never 0, since that asks for no callback.
+func (c *zwaveCoordinator) nextCallbackID() byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.callbackID++
+	if c.callbackID == 0 {
+		c.callbackID = 1
+	}
+	return c.callbackID
+}
+
+func (c *zwaveCoordinator) start(ctx context.Context) error {
+	id, err := c.request(ctx, zwaveMemoryGetID, nil, true)
+	if err != nil {
+		return err
+	}
+	if len(id) < 5 {
+		return errors.New("z-wave stick did not give its node ID")
+	}
+	controller := int(id[4])
+
+	initData, err := c.request(ctx, zwaveGetInitData, nil, true)
+	if err != nil {
+		return err
+	}
+	if len(initData) < 3+zwaveNodeBitmaskLen || initData[2] != zwaveNodeBitmaskLen {
+		return errors.New("z-wave stick did not list the nodes of its network")
+	}
+	for i, b := range initData[3 : 3+zwaveNodeBitmaskLen] {
+		for bit := 0; bit < 8; bit++ {
+			if node := i*8 + bit + 1; b&(1<<bit) != 0 && node != controller {
+				c.devices.add(zwaveID(node))
+			}
+		}
+	}
+	return nil
+}
+
+func (c *zwaveCoordinator) permitJoin(ctx context.Context, seconds int) error {
+	c.mu.Lock()
+	if c.inclusion != nil {
+		c.inclusion.Stop()
+	}
+	c.inclusion = time.AfterFunc(time.Duration(seconds)*time.Second, func() { c.queue(c.stopInclusion) })
+	c.mu.Unlock()
+	_, err := c.request(ctx, zwaveAddNodeToNetwork, []byte{zwaveAddNodeAny | zwaveAddNodeHighPower, c.nextCallbackID()}, false)
+	return err
+}
+
+// stopInclusion stops devices from being added to the network.
+func (c *zwaveCoordinator) stopInclusion(ctx context.Context) {
+	c.mu.Lock()
+	if c.inclusion == nil {
+		c.mu.Unlock()
+		return
+	}
+	c.inclusion.Stop()
+	c.inclusion = nil
+	c.mu.Unlock()
+	if _, err := c.request(ctx, zwaveAddNodeToNetwork, []byte{zwaveAddNodeStop, 0}, false); err != nil {
+		c.logger.CWarnw(ctx, "cannot stop z-wave stick adding devices", "error", err)
+	}
+}
+
+func (c *zwaveCoordinator) setOn(ctx context.Context, id string, on bool) error {
+	node, err := zwaveNode(id)
+	if err != nil {
+		return err
+	}
+	value := byte(0x00)
+	if on {
+		value = 0xff
+	}
+	callbackID := c.nextCallbackID()
+	status := make(chan byte, 1)
+	c.mu.Lock()
+	c.sends[callbackID] = status
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.sends, callbackID)
+		c.mu.Unlock()
+	}()
+
+	command := []byte{zwaveClassSwitchBinary, zwaveSwitchBinarySet, value}
+	payload := append([]byte{byte(node), byte(len(command))}, command...)
+	payload = append(payload, zwaveTransmitOptions, callbackID)
+	queued, err := c.request(ctx, zwaveSendData, payload, true)
+	if err != nil {
+		return err
+	}
+	if len(queued) == 0 || queued[0] == 0 {
+		return errors.New("z-wave stick could not queue the command")
+	}
+	ctx, cancel := context.WithTimeout(ctx, coordinatorReplyTimeout)
+	defer cancel()
+	select {
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "z-wave device did not acknowledge the command")
+	case txStatus := <-status:
+		if txStatus != 0 {
+			return errors.Errorf("z-wave device did not acknowledge the command, transmit status 0x%02x", txStatus)
+		}
+		return nil
+	}
+}
+
+// queue queues a request to be made apart from the reading of frames.
+func (c *zwaveCoordinator) queue(fn func(ctx context.Context)) {
+	select {
+	case c.later <- fn:
+	default:
+		c.logger.Warn("dropping request to z-wave stick, too many are queued")
+	}
+}
+
+func (c *zwaveCoordinator) runLater(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case fn := <-c.later:
+			fn(ctx)
+		}
+	}
+}
+
+// handle handles a request the stick sent, for a callback or of its own accord.
+func (c *zwaveCoordinator) handle(ctx context.Context, frame zwaveFrame) {
+	payload := frame.payload
+	switch frame.function {
+	case zwaveSendData:
+		if len(payload) < 2 {
+			return
+		}
+		c.mu.Lock()
+		status, ok := c.sends[payload[0]]
+		c.mu.Unlock()
+		if ok {
+			select {
+			case status <- payload[1]:
+			default:
+			}
+		}
+	case zwaveAddNodeToNetwork:
+		if len(payload) < 2 {
+			return
+		}
+		switch payload[1] {
+		case zwaveAddNodeStatusSlave:
+			if len(payload) < 4 || payload[2] == 0 {
+				return
+			}
+			c.logger.CInfow(ctx, "z-wave device joined", "id", zwaveID(int(payload[2])))
+			c.learn(int(payload[2]), payload[3:])
+		case zwaveAddNodeStatusDone, zwaveAddNodeStatusFinished:
+			c.queue(c.stopInclusion)
+		}
+	case zwaveApplicationUpdate:
+		if len(payload) < 3 || payload[0] != zwaveUpdateNodeInfo {
+			return
+		}
+		c.learn(int(payload[1]), payload[2:])
+	case zwaveApplicationCommand:
+		if len(payload) < 3 {
+			return
+		}
+		length := int(payload[2])
+		if len(payload) < 3+length {
+			return
+		}
+		c.report(int(payload[1]), payload[3:3+length])
+	}
+}
+
+// learn records the node information of a device: its length, basic, generic and specific device classes, and its
+// command classes.
+func (c *zwaveCoordinator) learn(node int, info []byte) {
+	var commandClasses []byte
+	if len(info) > 4 && int(info[0]) <= len(info)-1 {
+		commandClasses = info[4 : 1+int(info[0])]
+	}
+	c.devices.update(zwaveID(node), func(d *Device) {
+		for _, class := range commandClasses {
+			if class == zwaveClassSwitchBinary {
+				d.Switch = true
+			}
+		}
+	})
+}
+
+// report records the readings in a command a device sent.
+func (c *zwaveCoordinator) report(node int, command []byte) {
+	if len(command) < 3 {
+		c.devices.update(zwaveID(node), nil)
+		return
+	}
+	class, cmd, params := command[0], command[1], command[2:]
+	c.devices.update(zwaveID(node), func(d *Device) {
+		switch {
+		case class == zwaveClassSwitchBinary && cmd == zwaveReport:
+			d.Switch = true
+			d.Readings["on"] = params[0] != 0
+		case class == zwaveClassSensorBinary && cmd == zwaveReport:
+			d.Readings["triggered"] = params[0] != 0
+		case class == zwaveClassBattery && cmd == zwaveReport:
+			if params[0] == zwaveBatteryLowWarning {
+				d.Readings["battery_low"] = true
+				return
+			}
+			d.Readings["battery_pct"] = float64(params[0])
+			d.Readings["battery_low"] = false
+		case class == zwaveClassSensorMultilevel && cmd == zwaveMultilevelReport:
+			if name, value, ok := zwaveMultilevelReading(params); ok {
+				d.Readings[name] = value
+			}
+		}
+	})
+}
+
+// zwaveMultilevelReading decodes a multilevel sensor report into a reading in the units of its name.
+func zwaveMultilevelReading(params []byte) (string, float64, bool) {
+	if len(params) < 2 {
+		return "", 0, false
+	}
+	sensorType, level := params[0], params[1]
+	precision, scale, size := int(level>>5), int(level>>3)&0x03, int(level&0x07)
+	if (size != 1 && size != 2 && size != 4) || len(params) < 2+size {
+		return "", 0, false
+	}
+	var raw int64
+	for _, b := range params[2 : 2+size] {
+		raw = raw<<8 | int64(b)
+	}
+	// the value is a signed integer of its size
+	if raw >= 1<<(8*size-1) {
+		raw -= 1 << (8 * size)
+	}
+	value := float64(raw) / math.Pow10(precision)
+	switch sensorType {
+	case 0x01:
+		if scale == 1 {
+			return "temperature_c", (value - 32) * 5 / 9, true
+		}
+		return "temperature_c", value, true
+	case 0x03:
+		if scale == 1 {
+			return "illuminance_lux", value, true
+		}
+		return "luminance_pct", value, true
+	case 0x04:
+		return "power_w", value, true
+	case 0x05:
+		return "humidity_pct", value, true
+	default:
+		return fmt.Sprintf("sensor_%d", sensorType), value, true
+	}
+}
+
+func (c *zwaveCoordinator) close() {
+	c.mu.Lock()
+	if c.inclusion != nil {
+		c.inclusion.Stop()
+	}
+	c.mu.Unlock()
+	c.workers.Stop()
+}
+
+func zwaveID(node int) string {
+	return "node_" + strconv.Itoa(node)
+}
+
+func zwaveNode(id string) (int, error) {
+	node, err := strconv.Atoi(strings.TrimPrefix(id, "node_"))
+	if err != nil || !strings.HasPrefix(id, "node_") || node < 1 || node > 232 {
+		return 0, errors.Errorf("%q is not a z-wave node, such as node_5", id)
+	}
+	return node, nil
+}
@@ -0,0 +1,180 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+)
+
+// fakeZWaveStick is a controller, node 1, of a network with node 5 in it. It acknowledges every frame it reads and
+// passes the requests on, and writes what is sent on its frames channel.
+type fakeZWaveStick struct {
+	requests chan zwaveFrame
+	frames   chan []byte
+}
+
+func newFakeZWaveStick(stick net.Conn) *fakeZWaveStick {
+	s := &fakeZWaveStick{requests: make(chan zwaveFrame, 16), frames: make(chan []byte, 16)}
+	// writes are made apart from reads, since the host acknowledges what it reads before reading again
+	go func() {
+		for frame := range s.frames {
+			if _, err := stick.Write(frame); err != nil {
+				return
+			}
+		}
+	}()
+	go func() {
+		defer close(s.frames)
+		reader := bufio.NewReader(stick)
+		for {
+			b, err := reader.ReadByte()
+			if err != nil {
+				return
+			}
+			if b != zwaveSOF {
+				continue
+			}
+			length, err := reader.ReadByte()
+			if err != nil {
+				return
+			}
+			rest := make([]byte, length)
+			if _, err := io.ReadFull(reader, rest); err != nil {
+				return
+			}
+			request := zwaveFrame{frameType: rest[0], function: rest[1], payload: rest[2 : len(rest)-1]}
+			s.frames <- []byte{zwaveACK}
+			s.requests <- request
+			switch request.function {
+			case zwaveMemoryGetID:
+				s.respond(request.function, []byte{0xc0, 0xff, 0xee, 0x01, 1})
+			case zwaveGetInitData:
+				nodes := make([]byte, zwaveNodeBitmaskLen)
+				nodes[0] = 1<<0 | 1<<4
+				s.respond(request.function, append(append([]byte{5, 0, zwaveNodeBitmaskLen}, nodes...), 7, 0))
+			case zwaveSendData:
+				s.respond(request.function, []byte{1})
+				s.request(zwaveSendData, []byte{request.payload[len(request.payload)-1], 0})
+			}
+		}
+	}()
+	return s
+}
+
+func (s *fakeZWaveStick) respond(function byte, payload []byte) {
+	s.frames <- zwaveFrame{frameType: zwaveTypeResponse, function: function, payload: payload}.encode()
+}
+
+func (s *fakeZWaveStick) request(function byte, payload []byte) {
+	s.frames <- zwaveFrame{frameType: zwaveTypeRequest, function: function, payload: payload}.encode()
+}
+
+// next returns the next request to the stick for a function.
+func (s *fakeZWaveStick) next(t *testing.T, function byte) zwaveFrame {
+	t.Helper()
+	for request := range s.requests {
+		if request.function == function {
+			return request
+		}
+	}
+	t.Fatal("the stick closed")
+	return zwaveFrame{}
+}
+
+func TestZWave(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	host, stick := net.Pipe()
+	realOpenPort := openPort
+	openPort = func(path string, baudRate int) (io.ReadWriteCloser, error) {
+		return host, nil
+	}
+	defer func() {
+		openPort = realOpenPort
+	}()
+	fake := newFakeZWaveStick(stick)
+
+	conf := resource.Config{
+		Name:  "zwave",
+		API:   generic.API,
+		Model: ZWaveModel,
+		ConvertedAttributes: &Config{
+			SerialPath: "/dev/ttyACM0",
+			Devices:    []DeviceConfig{{Name: "heater", ID: "node_5"}},
+		},
+	}
+	gw, err := newGateway(ctx, conf, logger, newZWaveCoordinator)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, gw.Close(ctx), test.ShouldBeNil)
+	}()
+
+	// the controller itself is not a device
+	devices := gw.Devices()
+	test.That(t, devices, test.ShouldHaveLength, 1)
+	test.That(t, devices[0].ID, test.ShouldEqual, "node_5")
+	test.That(t, devices[0].Name, test.ShouldEqual, "heater")
+	test.That(t, devices[0].Protocol, test.ShouldEqual, "zwave")
+
+	// the heater reports that it is on, and a temperature of 72.5F to one decimal place
+	fake.request(zwaveApplicationCommand, []byte{0, 5, 3, zwaveClassSwitchBinary, zwaveReport, 0xff})
+	fake.request(zwaveApplicationCommand, []byte{0, 5, 6, zwaveClassSensorMultilevel, zwaveMultilevelReport, 0x01, 0x2a, 0x02, 0xd5})
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		heater, err := gw.Device("heater")
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, heater.Switch, test.ShouldBeTrue)
+		test.That(tb, heater.Readings["on"], test.ShouldEqual, true)
+		test.That(tb, heater.Readings["temperature_c"], test.ShouldAlmostEqual, 22.5)
+	})
+
+	_, err = gw.DoCommand(ctx, map[string]interface{}{"command": "set", "device": "heater", "on": false})
+	test.That(t, err, test.ShouldBeNil)
+	send := fake.next(t, zwaveSendData)
+	test.That(t, send.payload[:5], test.ShouldResemble, []byte{5, 3, zwaveClassSwitchBinary, zwaveSwitchBinarySet, 0x00})
+	heater, err := gw.Device("heater")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heater.Readings["on"], test.ShouldEqual, false)
+
+	test.That(t, gw.SetOn(ctx, "lamp", true), test.ShouldNotBeNil)
+
+	// a switch joins the network, after which the stick is stopped adding devices
+	_, err = gw.DoCommand(ctx, map[string]interface{}{"command": "permit_join"})
+	test.That(t, err, test.ShouldBeNil)
+	add := fake.next(t, zwaveAddNodeToNetwork)
+	test.That(t, add.payload[0], test.ShouldEqual, zwaveAddNodeAny|zwaveAddNodeHighPower)
+	callbackID := add.payload[1]
+	fake.request(zwaveAddNodeToNetwork, []byte{callbackID, zwaveAddNodeStatusSlave, 7, 5, 4, 0x10, 1, 0x5e, zwaveClassSwitchBinary})
+	fake.request(zwaveAddNodeToNetwork, []byte{callbackID, zwaveAddNodeStatusDone, 7, 0})
+	stop := fake.next(t, zwaveAddNodeToNetwork)
+	test.That(t, stop.payload[0], test.ShouldEqual, zwaveAddNodeStop)
+	outlet, err := gw.Device("node_7")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, outlet.Switch, test.ShouldBeTrue)
+}
+
+func TestZWaveMultilevelReading(t *testing.T) {
+	name, value, ok := zwaveMultilevelReading([]byte{0x05, 0x01, 48})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, name, test.ShouldEqual, "humidity_pct")
+	test.That(t, value, test.ShouldEqual, 48)
+
+	// a negative temperature of -5.25C to two decimal places
+	name, value, ok = zwaveMultilevelReading([]byte{0x01, 0x42, 0xfd, 0xf3})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, name, test.ShouldEqual, "temperature_c")
+	test.That(t, value, test.ShouldEqual, -5.25)
+
+	_, _, ok = zwaveMultilevelReading([]byte{0x01, 0x04, 0})
+	test.That(t, ok, test.ShouldBeFalse)
+}
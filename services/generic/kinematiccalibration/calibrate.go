@@ -0,0 +1,461 @@
+//go:build !no_cgo
+
+package kinematiccalibration
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+const (
+	// rotation parameters, and the offsets of revolute joints, are solved in milliradians so that they are on the
+	// same scale as the millimeters of the translation parameters.
+	radPerParam = 1e-3
+	// regularization keeps parameters which the samples cannot tell apart near their nominal values.
+	regularization = 1e-3
+
+	maxIterations = 100
+	maxDamping    = 1e10
+	jacobianStep  = 1e-6
+	// solving stops once an iteration lowers the cost by less than this fraction
+	convergence = 1e-10
+)
+
+// A Sample is the pose of the end effector measured with the arm at a set of joint positions.
+type Sample struct {
+	// JointPositions are the inputs of the arm's model, in radians or millimeters.
+	JointPositions []referenceframe.Input
+	// Measured is the pose measured by the fiducial tracker or probe.
+	Measured spatialmath.Pose
+	// PositionOnly says the measurement has no orientation, as with a probe.
+	PositionOnly bool
+}
+
+// Options says which poses besides the arm's kinematics to solve for.
+type Options struct {
+	// MeasurementFrame is the pose of the arm's base in the frame the measurements are taken in, which is the
+	// arm's base when nil. FitMeasurementFrame solves for a correction to it.
+	MeasurementFrame    spatialmath.Pose
+	FitMeasurementFrame bool
+	// MarkerOffset is the pose of the fiducial or probe tip on the end effector, which is the end effector itself
+	// when nil. FitMarkerOffset solves for a correction to it.
+	MarkerOffset    spatialmath.Pose
+	FitMarkerOffset bool
+	// OrientationWeightMMPerDeg weighs a degree of orientation error against a millimeter of position error.
+	OrientationWeightMMPerDeg float64
+}
+
+// A Result is the outcome of a calibration.
+type Result struct {
+	// Kinematics is the refined kinematics of the arm, in the SVA format of a kinematics file.
+	Kinematics *referenceframe.ModelConfig
+	// JointOffsets are the corrections to the zero position of each joint, in degrees or millimeters.
+	JointOffsets []float64
+	// MeasurementFrame and MarkerOffset are the poses of the options, corrected when the options ask for it.
+	MeasurementFrame spatialmath.Pose
+	MarkerOffset     spatialmath.Pose
+	// The root mean square position and orientation errors of the samples before and after calibration.
+	PositionErrorBeforeMM     float64
+	PositionErrorAfterMM      float64
+	OrientationErrorBeforeDeg float64
+	OrientationErrorAfterDeg  float64
+}
+
+// chainFrame is one frame in the kinematic chain of an arm, with the parameters which refine it.
+type chainFrame struct {
+	link  *referenceframe.LinkConfig
+	joint *referenceframe.JointConfig
+	// nominal is the pose of a link.
+	nominal spatialmath.Pose
+	// param is the index of the first parameter of the frame.
+	param int
+}
+
+// numParams is how many parameters refine the frame: a translation and rotation for a link, and an offset for a
+// joint.
+func (f *chainFrame) numParams() int {
+	if f.link != nil {
+		return 6
+	}
+	return 1
+}
+
+// chain is the kinematic chain of an arm with the parameters refining it, from the base out.
+type chain struct {
+	name        string
+	frames      []*chainFrame
+	measurement spatialmath.Pose
+	marker      spatialmath.Pose
+	// the indexes of the measurement frame and marker offset parameters, or -1 when they are not fitted
+	measurementParam int
+	markerParam      int
+	numParams        int
+}
+
+func newChain(model referenceframe.Model, options Options) (*chain, error) {
+	simple, ok := model.(*referenceframe.SimpleModel)
+	if !ok {
+		return nil, errors.Errorf("cannot calibrate kinematics of type %T", model)
+	}
+	c := &chain{
+		name:             model.Name(),
+		measurement:      options.MeasurementFrame,
+		marker:           options.MarkerOffset,
+		measurementParam: -1,
+		markerParam:      -1,
+	}
+	if c.measurement == nil {
+		c.measurement = spatialmath.NewZeroPose()
+	}
+	if c.marker == nil {
+		c.marker = spatialmath.NewZeroPose()
+	}
+	for _, frame := range simple.OrdTransforms {
+		data, err := json.Marshal(frame)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot calibrate frame %q", frame.Name())
+		}
+		cf := &chainFrame{param: c.numParams}
+		switch len(frame.DoF()) {
+		case 0:
+			cf.link = &referenceframe.LinkConfig{}
+			if err := json.Unmarshal(data, cf.link); err != nil {
+				return nil, err
+			}
+			if cf.nominal, err = cf.link.Pose(); err != nil {
+				return nil, err
+			}
+		case 1:
+			cf.joint = &referenceframe.JointConfig{}
+			if err := json.Unmarshal(data, cf.joint); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errors.Errorf("cannot calibrate frame %q with %d degrees of freedom", frame.Name(), len(frame.DoF()))
+		}
+		c.frames = append(c.frames, cf)
+		c.numParams += cf.numParams()
+	}
+	if options.FitMeasurementFrame {
+		c.measurementParam = c.numParams
+		c.numParams += 6
+	}
+	if options.FitMarkerOffset {
+		c.markerParam = c.numParams
+		c.numParams += 6
+	}
+	return c, nil
+}
+
+// paramPose is the pose made by the six parameters starting at i, or the zero pose if i is -1.
+func paramPose(params []float64, i int) spatialmath.Pose {
+	if i < 0 {
+		return spatialmath.NewZeroPose()
+	}
+	translation := r3.Vector{X: params[i], Y: params[i+1], Z: params[i+2]}
+	rotation := r3.Vector{X: params[i+3], Y: params[i+4], Z: params[i+5]}.Mul(radPerParam)
+	theta := rotation.Norm()
+	if theta == 0 {
+		return spatialmath.NewPoseFromPoint(translation)
+	}
+	rotation = rotation.Mul(1 / theta)
+	return spatialmath.NewPose(translation, &spatialmath.R4AA{Theta: theta, RX: rotation.X, RY: rotation.Y, RZ: rotation.Z})
+}
+
+// jointPose is the pose of a joint at an input, shifted by its offset.
+func jointPose(joint *referenceframe.JointConfig, value float64) spatialmath.Pose {
+	axis := r3.Vector(joint.Axis)
+	if joint.Type == referenceframe.PrismaticJoint {
+		return spatialmath.NewPoseFromPoint(axis.Normalize().Mul(value))
+	}
+	axis = axis.Normalize()
+	return spatialmath.NewPoseFromOrientation(&spatialmath.R4AA{Theta: value, RX: axis.X, RY: axis.Y, RZ: axis.Z})
+}
+
+// jointOffset is the correction to the zero position of a joint, in radians or millimeters.
+func (f *chainFrame) jointOffset(params []float64) float64 {
+	if f.joint.Type == referenceframe.PrismaticJoint {
+		return params[f.param]
+	}
+	return params[f.param] * radPerParam
+}
+
+// linkPose is the refined pose of a link.
+func (f *chainFrame) linkPose(params []float64) spatialmath.Pose {
+	return spatialmath.Compose(f.nominal, paramPose(params, f.param))
+}
+
+// transform is the pose of the marker in the measurement frame with the arm at the inputs.
+func (c *chain) transform(params []float64, inputs []referenceframe.Input) (spatialmath.Pose, error) {
+	pose := c.measurementFrame(params)
+	next := 0
+	for _, f := range c.frames {
+		if f.link != nil {
+			pose = spatialmath.Compose(pose, f.linkPose(params))
+			continue
+		}
+		if next >= len(inputs) {
+			return nil, referenceframe.NewIncorrectInputLengthError(len(inputs), next+1)
+		}
+		pose = spatialmath.Compose(pose, jointPose(f.joint, inputs[next].Value+f.jointOffset(params)))
+		next++
+	}
+	if next != len(inputs) {
+		return nil, referenceframe.NewIncorrectInputLengthError(len(inputs), next)
+	}
+	return spatialmath.Compose(pose, c.markerOffset(params)), nil
+}
+
+func (c *chain) measurementFrame(params []float64) spatialmath.Pose {
+	return spatialmath.Compose(c.measurement, paramPose(params, c.measurementParam))
+}
+
+func (c *chain) markerOffset(params []float64) spatialmath.Pose {
+	return spatialmath.Compose(c.marker, paramPose(params, c.markerParam))
+}
+
+// sampleErrors returns the squared position and orientation errors of each sample.
+func (c *chain) sampleErrors(params []float64, samples []Sample) (positions, orientations []float64, err error) {
+	for _, s := range samples {
+		predicted, err := c.transform(params, s.JointPositions)
+		if err != nil {
+			return nil, nil, err
+		}
+		positions = append(positions, predicted.Point().Sub(s.Measured.Point()).Norm2())
+		if !s.PositionOnly {
+			theta := spatialmath.OrientationBetween(s.Measured.Orientation(), predicted.Orientation()).AxisAngles().Theta
+			orientations = append(orientations, utils.RadToDeg(theta)*utils.RadToDeg(theta))
+		}
+	}
+	return positions, orientations, nil
+}
+
+// rms is the root mean square of a list of squared errors.
+func rms(squared []float64) float64 {
+	if len(squared) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, s := range squared {
+		sum += s
+	}
+	return math.Sqrt(sum / float64(len(squared)))
+}
+
+// kinematics returns the refined chain as an SVA kinematics file. Joint offsets cannot be written there, so each is
+// folded into the link after its joint, which moves the same with the joint at its input as it did with the joint
+// at its input plus the offset.
+func (c *chain) kinematics(params []float64) (*referenceframe.ModelConfig, error) {
+	cfg := &referenceframe.ModelConfig{Name: c.name, KinParamType: "SVA"}
+	parent := referenceframe.World
+	var pending spatialmath.Pose
+	var pendingName string
+	addLink := func(id string, pose spatialmath.Pose, geometry *spatialmath.GeometryConfig) error {
+		orientation, err := spatialmath.NewOrientationConfig(pose.Orientation())
+		if err != nil {
+			return err
+		}
+		cfg.Links = append(cfg.Links, referenceframe.LinkConfig{
+			ID:          id,
+			Translation: pose.Point(),
+			Orientation: orientation,
+			Geometry:    geometry,
+			Parent:      parent,
+		})
+		parent = id
+		return nil
+	}
+	flush := func() error {
+		if pending == nil {
+			return nil
+		}
+		err := addLink(pendingName+"_offset", pending, nil)
+		pending = nil
+		return err
+	}
+
+	for _, f := range c.frames {
+		if f.link != nil {
+			pose := f.linkPose(params)
+			if pending != nil {
+				pose = spatialmath.Compose(pending, pose)
+				pending = nil
+			}
+			if err := addLink(f.link.ID, pose, f.link.Geometry); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := flush(); err != nil {
+			return nil, err
+		}
+		joint := *f.joint
+		joint.Parent = parent
+		cfg.Joints = append(cfg.Joints, joint)
+		parent = joint.ID
+		if offset := f.jointOffset(params); offset != 0 {
+			pending = jointPose(f.joint, offset)
+			pendingName = joint.ID
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// residuals returns the position errors of each sample in millimeters and its weighted orientation errors, followed
+// by the regularization of each parameter.
+func (c *chain) residuals(params []float64, samples []Sample, weight float64) ([]float64, error) {
+	residuals := make([]float64, 0, 6*len(samples)+len(params))
+	for _, s := range samples {
+		predicted, err := c.transform(params, s.JointPositions)
+		if err != nil {
+			return nil, err
+		}
+		delta := predicted.Point().Sub(s.Measured.Point())
+		residuals = append(residuals, delta.X, delta.Y, delta.Z)
+		if !s.PositionOnly {
+			rotation := spatialmath.OrientationBetween(s.Measured.Orientation(), predicted.Orientation()).AxisAngles().ToR3()
+			rotation = rotation.Mul(utils.RadToDeg(1) * weight)
+			residuals = append(residuals, rotation.X, rotation.Y, rotation.Z)
+		}
+	}
+	for _, p := range params {
+		residuals = append(residuals, regularization*p)
+	}
+	return residuals, nil
+}
+
+// solve finds the parameters which best fit the samples with the Levenberg-Marquardt method.
+func (c *chain) solve(params []float64, samples []Sample, weight float64) ([]float64, error) {
+	residuals, err := c.residuals(params, samples, weight)
+	if err != nil {
+		return nil, err
+	}
+	cost := floats.Dot(residuals, residuals)
+	n := len(params)
+	damping := 1e-3
+	jacobian := mat.NewDense(len(residuals), n, nil)
+	stepped := make([]float64, n)
+	for iter := 0; iter < maxIterations; iter++ {
+		for j := 0; j < n; j++ {
+			copy(stepped, params)
+			stepped[j] += jacobianStep
+			perturbed, err := c.residuals(stepped, samples, weight)
+			if err != nil {
+				return nil, err
+			}
+			for i := range residuals {
+				jacobian.Set(i, j, (perturbed[i]-residuals[i])/jacobianStep)
+			}
+		}
+		var normal mat.SymDense
+		normal.SymOuterK(1, jacobian.T())
+		var gradient mat.VecDense
+		gradient.MulVec(jacobian.T(), mat.NewVecDense(len(residuals), residuals))
+
+		improved := false
+		for !improved && damping < maxDamping {
+			damped := mat.NewSymDense(n, nil)
+			damped.CopySym(&normal)
+			for j := 0; j < n; j++ {
+				damped.SetSym(j, j, normal.At(j, j)*(1+damping)+damping)
+			}
+			var step mat.VecDense
+			if err := step.SolveVec(damped, &gradient); err != nil {
+				damping *= 10
+				continue
+			}
+			candidate := make([]float64, n)
+			floats.SubTo(candidate, params, step.RawVector().Data)
+			candidateResiduals, err := c.residuals(candidate, samples, weight)
+			if err != nil {
+				return nil, err
+			}
+			candidateCost := floats.Dot(candidateResiduals, candidateResiduals)
+			if candidateCost >= cost {
+				damping *= 10
+				continue
+			}
+			improved = true
+			damping = math.Max(damping/10, 1e-9)
+			converged := cost-candidateCost < convergence*cost
+			params, residuals, cost = candidate, candidateResiduals, candidateCost
+			if converged {
+				return params, nil
+			}
+		}
+		if !improved {
+			// no step lowers the cost, so the parameters are at a minimum
+			return params, nil
+		}
+	}
+	return params, nil
+}
+
+// Calibrate refines the kinematics of an arm to best match the poses measured at each sample, solving for a
+// correction to the pose of each link and the zero position of each joint.
+func Calibrate(model referenceframe.Model, samples []Sample, options Options) (*Result, error) {
+	c, err := newChain(model, options)
+	if err != nil {
+		return nil, err
+	}
+	var numCoordinates int
+	for _, s := range samples {
+		numCoordinates += 3
+		if !s.PositionOnly {
+			numCoordinates += 3
+		}
+	}
+	if numCoordinates < c.numParams {
+		return nil, errors.Errorf("need more samples to solve for %d parameters, only have %d", c.numParams, len(samples))
+	}
+
+	params := make([]float64, c.numParams)
+	positionsBefore, orientationsBefore, err := c.sampleErrors(params, samples)
+	if err != nil {
+		return nil, err
+	}
+	if params, err = c.solve(params, samples, options.OrientationWeightMMPerDeg); err != nil {
+		return nil, err
+	}
+	positionsAfter, orientationsAfter, err := c.sampleErrors(params, samples)
+	if err != nil {
+		return nil, err
+	}
+
+	kinematics, err := c.kinematics(params)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{
+		Kinematics:                kinematics,
+		MeasurementFrame:          c.measurementFrame(params),
+		MarkerOffset:              c.markerOffset(params),
+		PositionErrorBeforeMM:     rms(positionsBefore),
+		PositionErrorAfterMM:      rms(positionsAfter),
+		OrientationErrorBeforeDeg: rms(orientationsBefore),
+		OrientationErrorAfterDeg:  rms(orientationsAfter),
+	}
+	for _, f := range c.frames {
+		if f.joint == nil {
+			continue
+		}
+		offset := f.jointOffset(params)
+		if f.joint.Type != referenceframe.PrismaticJoint {
+			offset = utils.RadToDeg(offset)
+		}
+		result.JointOffsets = append(result.JointOffsets, offset)
+	}
+	return result, nil
+}
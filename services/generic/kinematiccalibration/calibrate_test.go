@@ -0,0 +1,118 @@
+//go:build !no_cgo
+
+package kinematiccalibration
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// perturbedSamples measures an arm whose links and joint zeros are off from its model by small random amounts.
+func perturbedSamples(
+	t *testing.T, model referenceframe.Model, options Options, n int, seed int64,
+) (*chain, []float64, []Sample) {
+	t.Helper()
+	c, err := newChain(model, options)
+	test.That(t, err, test.ShouldBeNil)
+	//nolint:gosec
+	rnd := rand.New(rand.NewSource(seed))
+	actual := make([]float64, c.numParams)
+	for i := range actual {
+		// up to 2mm of translation, 2mrad of rotation and 2mrad of joint offset
+		actual[i] = 4 * (rnd.Float64() - 0.5)
+	}
+
+	samples := make([]Sample, 0, n)
+	for i := 0; i < n; i++ {
+		inputs := referenceframe.FloatsToInputs(referenceframe.GenerateRandomConfiguration(model, rnd))
+		measured, err := c.transform(actual, inputs)
+		test.That(t, err, test.ShouldBeNil)
+		samples = append(samples, Sample{JointPositions: inputs, Measured: measured})
+	}
+	return c, actual, samples
+}
+
+func TestCalibrate(t *testing.T) {
+	model, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/universalrobots/ur5e.json"), "")
+	test.That(t, err, test.ShouldBeNil)
+
+	t.Run("refines links and joint offsets", func(t *testing.T) {
+		c, actual, samples := perturbedSamples(t, model, Options{}, 40, 1)
+
+		result, err := Calibrate(model, samples, Options{OrientationWeightMMPerDeg: 1})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, result.PositionErrorBeforeMM, test.ShouldBeGreaterThan, 1)
+		test.That(t, result.PositionErrorAfterMM, test.ShouldBeLessThan, 0.1)
+		test.That(t, result.OrientationErrorAfterDeg, test.ShouldBeLessThan, 0.01)
+		test.That(t, len(result.JointOffsets), test.ShouldEqual, len(model.DoF()))
+
+		// the written kinematics reproduce the arm away from the samples
+		data, err := json.Marshal(result.Kinematics)
+		test.That(t, err, test.ShouldBeNil)
+		calibrated, err := referenceframe.UnmarshalModelJSON(data, "")
+		test.That(t, err, test.ShouldBeNil)
+		//nolint:gosec
+		rnd := rand.New(rand.NewSource(2))
+		for i := 0; i < 10; i++ {
+			inputs := referenceframe.FloatsToInputs(referenceframe.GenerateRandomConfiguration(model, rnd))
+			expected, err := c.transform(actual, inputs)
+			test.That(t, err, test.ShouldBeNil)
+			pose, err := calibrated.Transform(inputs)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, spatialmath.PoseAlmostCoincidentEps(pose, expected, 0.2), test.ShouldBeTrue)
+		}
+	})
+
+	t.Run("fits the measurement frame and marker offset from positions", func(t *testing.T) {
+		options := Options{
+			MeasurementFrame:    spatialmath.NewPoseFromPoint(r3.Vector{X: 1000, Y: 0, Z: 0}),
+			FitMeasurementFrame: true,
+			MarkerOffset:        spatialmath.NewPoseFromPoint(r3.Vector{X: 0, Y: 0, Z: 50}),
+			FitMarkerOffset:     true,
+		}
+		_, _, samples := perturbedSamples(t, model, options, 60, 3)
+		for i := range samples {
+			samples[i].Measured = spatialmath.NewPoseFromPoint(samples[i].Measured.Point())
+			samples[i].PositionOnly = true
+		}
+
+		result, err := Calibrate(model, samples, options)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, result.PositionErrorAfterMM, test.ShouldBeLessThan, 0.1)
+		test.That(t, result.OrientationErrorAfterDeg, test.ShouldEqual, 0)
+	})
+
+	t.Run("needs enough samples", func(t *testing.T) {
+		_, _, samples := perturbedSamples(t, model, Options{}, 2, 4)
+		_, err := Calibrate(model, samples, Options{})
+		test.That(t, err, test.ShouldBeError)
+	})
+}
+
+func TestPoseFromMap(t *testing.T) {
+	pose, positionOnly, err := poseFromMap(map[string]interface{}{"x": 1., "y": 2., "z": 3.})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, positionOnly, test.ShouldBeTrue)
+	test.That(t, pose.Point(), test.ShouldResemble, r3.Vector{X: 1, Y: 2, Z: 3})
+
+	pose, positionOnly, err = poseFromMap(poseToMap(spatialmath.NewPose(
+		r3.Vector{X: 1, Y: 2, Z: 3}, &spatialmath.OrientationVectorDegrees{OX: 1, Theta: 90},
+	)))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, positionOnly, test.ShouldBeFalse)
+	test.That(t, spatialmath.OrientationAlmostEqual(pose.Orientation(), &spatialmath.OrientationVectorDegrees{OX: 1, Theta: 90}),
+		test.ShouldBeTrue)
+
+	_, _, err = poseFromMap(map[string]interface{}{"x": 1., "y": 2.})
+	test.That(t, err, test.ShouldBeError)
+	_, _, err = poseFromMap(map[string]interface{}{"x": "1", "y": 2., "z": 3.})
+	test.That(t, err, test.ShouldBeError)
+}
@@ -0,0 +1,382 @@
+//go:build !no_cgo
+
+// Package kinematiccalibration implements a generic service which refines the kinematics of an arm from measured
+// poses of its end effector, to improve the absolute accuracy of arms built to loose tolerances.
+//
+// Samples pair the joint positions of the arm with the pose of its end effector, measured by a tracked fiducial or
+// a probe. They are recorded with DoCommand:
+//
+//	{"command": "record_sample"}                       // measure with the configured pose sensor
+//	{"command": "record_sample", "pose": {"x": 400, "y": 0, "z": 300}}
+//	{"command": "collect_samples"}                     // record at each of the configured sample positions
+//	{"command": "clear_samples"}
+//	{"command": "calibrate"}                           // solve and write the corrected kinematics file
+//
+// collect_samples can be streamed with resource.DoCommandStream, which sends the number of samples recorded as the
+// arm reaches each position.
+//
+// Poses are in millimeters with an optional orientation vector in degrees (o_x, o_y, o_z, theta); a pose without an
+// orientation, as measured by a probe, only constrains position.
+package kinematiccalibration
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	pb "go.viam.com/api/component/arm/v1"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// Model is the model of the kinematic calibration service.
+var Model = resource.DefaultModelFamily.WithModel("kinematic_calibration")
+
+const (
+	defaultSettleTimeMS              = 500
+	defaultOrientationWeightMMPerDeg = 1.
+)
+
+func init() {
+	resource.RegisterService(generic.API, Model, resource.Registration[resource.Resource, *Config]{
+		Constructor: newCalibrator,
+	})
+}
+
+// Config describes how to configure the service.
+type Config struct {
+	ArmName string `json:"arm"`
+	// PoseSensor is a sensor whose readings are the measured pose of the end effector.
+	PoseSensor string `json:"pose_sensor,omitempty"`
+	// OutputFile is where the corrected kinematics file is written.
+	OutputFile string `json:"output_file"`
+	// SamplePositionsDeg are the joint positions collect_samples moves the arm through.
+	SamplePositionsDeg [][]float64 `json:"sample_positions_deg,omitempty"`
+	SettleTimeMS       int         `json:"settle_time_ms,omitempty"`
+
+	MeasurementFrame          *referenceframe.LinkConfig `json:"measurement_frame,omitempty"`
+	FitMeasurementFrame       bool                       `json:"fit_measurement_frame,omitempty"`
+	MarkerOffset              *referenceframe.LinkConfig `json:"marker_offset,omitempty"`
+	FitMarkerOffset           bool                       `json:"fit_marker_offset,omitempty"`
+	OrientationWeightMMPerDeg float64                    `json:"orientation_weight_mm_per_deg,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.ArmName == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "arm")
+	}
+	if conf.OutputFile == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "output_file")
+	}
+	if conf.SettleTimeMS < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("settle_time_ms cannot be negative"))
+	}
+	if conf.OrientationWeightMMPerDeg < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("orientation_weight_mm_per_deg cannot be negative"))
+	}
+	if len(conf.SamplePositionsDeg) > 0 && conf.PoseSensor == "" {
+		return nil, resource.NewConfigValidationError(path, errors.New("sample_positions_deg need a pose_sensor to measure with"))
+	}
+	for _, frame := range []*referenceframe.LinkConfig{conf.MeasurementFrame, conf.MarkerOffset} {
+		if frame == nil {
+			continue
+		}
+		if _, err := frame.Pose(); err != nil {
+			return nil, resource.NewConfigValidationError(path, err)
+		}
+	}
+	deps := []string{conf.ArmName}
+	if conf.PoseSensor != "" {
+		deps = append(deps, conf.PoseSensor)
+	}
+	return deps, nil
+}
+
+type calibrator struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	logger     logging.Logger
+	arm        arm.Arm
+	poseSensor sensor.Sensor
+	conf       *Config
+	options    Options
+	streams    *resource.CommandStreams
+
+	mu      sync.Mutex
+	samples []Sample
+}
+
+func newCalibrator(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (resource.Resource, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	c := &calibrator{
+		Named:  conf.ResourceName().AsNamed(),
+		logger: logger,
+		conf:   newConf,
+		options: Options{
+			FitMeasurementFrame:       newConf.FitMeasurementFrame,
+			FitMarkerOffset:           newConf.FitMarkerOffset,
+			OrientationWeightMMPerDeg: newConf.OrientationWeightMMPerDeg,
+		},
+	}
+	c.streams = resource.NewCommandStreams(map[string]resource.StreamingCommandHandler{"collect_samples": c.collectSamples})
+	if c.options.OrientationWeightMMPerDeg == 0 {
+		c.options.OrientationWeightMMPerDeg = defaultOrientationWeightMMPerDeg
+	}
+	if newConf.MeasurementFrame != nil {
+		if c.options.MeasurementFrame, err = newConf.MeasurementFrame.Pose(); err != nil {
+			return nil, err
+		}
+	}
+	if newConf.MarkerOffset != nil {
+		if c.options.MarkerOffset, err = newConf.MarkerOffset.Pose(); err != nil {
+			return nil, err
+		}
+	}
+	if c.arm, err = arm.FromDependencies(deps, newConf.ArmName); err != nil {
+		return nil, err
+	}
+	if newConf.PoseSensor != "" {
+		if c.poseSensor, err = sensor.FromDependencies(deps, newConf.PoseSensor); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// DoCommand records samples and runs the calibration.
+func (c *calibrator) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	handlers := c.streams.Handlers()
+	handlers["record_sample"] = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		var measured map[string]interface{}
+		if raw, ok := cmd["pose"]; ok {
+			if measured, ok = raw.(map[string]interface{}); !ok {
+				return nil, errors.New("pose must be a map of x, y, z and optionally o_x, o_y, o_z, theta")
+			}
+		}
+		if err := c.recordSample(ctx, measured); err != nil {
+			return nil, err
+		}
+		return c.sampleCount(), nil
+	}
+	handlers["clear_samples"] = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		c.mu.Lock()
+		c.samples = nil
+		c.mu.Unlock()
+		return c.sampleCount(), nil
+	}
+	handlers["calibrate"] = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		return c.calibrate()
+	}
+	return handlers.DoCommand(ctx, cmd)
+}
+
+// DoCommandStream runs collect_samples, sending the number of samples recorded at each position.
+func (c *calibrator) DoCommandStream(
+	ctx context.Context, cmd map[string]interface{}, send func(msg map[string]interface{}) error,
+) (map[string]interface{}, error) {
+	return c.streams.DoCommandStream(ctx, cmd, send)
+}
+
+// Close stops any streamed collection of samples.
+func (c *calibrator) Close(ctx context.Context) error {
+	c.streams.Close()
+	return nil
+}
+
+func (c *calibrator) sampleCount() map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return map[string]interface{}{"samples": len(c.samples)}
+}
+
+// recordSample pairs the current joint positions of the arm with the measured pose, read from the pose sensor when
+// none is given.
+func (c *calibrator) recordSample(ctx context.Context, measured map[string]interface{}) error {
+	if measured == nil {
+		if c.poseSensor == nil {
+			return errors.New("record_sample needs a pose when there is no pose_sensor")
+		}
+		readings, err := c.poseSensor.Readings(ctx, nil)
+		if err != nil {
+			return errors.Wrap(err, "cannot read pose sensor")
+		}
+		measured = readings
+	}
+	pose, positionOnly, err := poseFromMap(measured)
+	if err != nil {
+		return err
+	}
+	if moving, err := c.arm.IsMoving(ctx); err != nil {
+		return err
+	} else if moving {
+		return errors.New("cannot record a sample while the arm is moving")
+	}
+	positions, err := c.arm.JointPositions(ctx, nil)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.samples = append(c.samples, Sample{
+		JointPositions: c.arm.ModelFrame().InputFromProtobuf(positions),
+		Measured:       pose,
+		PositionOnly:   positionOnly,
+	})
+	return nil
+}
+
+// collectSamples moves the arm through the configured sample positions, recording a sample at each once the arm
+// has settled and sending the number of samples recorded.
+func (c *calibrator) collectSamples(
+	ctx context.Context, cmd map[string]interface{}, send func(msg map[string]interface{}) error,
+) (map[string]interface{}, error) {
+	if len(c.conf.SamplePositionsDeg) == 0 {
+		return nil, errors.New("no sample_positions_deg to collect samples at")
+	}
+	settleTime := time.Duration(c.conf.SettleTimeMS) * time.Millisecond
+	if c.conf.SettleTimeMS == 0 {
+		settleTime = defaultSettleTimeMS * time.Millisecond
+	}
+	for i, position := range c.conf.SamplePositionsDeg {
+		if err := c.arm.MoveToJointPositions(ctx, &pb.JointPositions{Values: position}, nil); err != nil {
+			return nil, errors.Wrapf(err, "cannot move to sample position %d", i)
+		}
+		if !goutils.SelectContextOrWait(ctx, settleTime) {
+			return nil, ctx.Err()
+		}
+		if err := c.recordSample(ctx, nil); err != nil {
+			return nil, errors.Wrapf(err, "cannot record sample at position %d", i)
+		}
+		msg := c.sampleCount()
+		msg["position"] = i
+		msg["positions"] = len(c.conf.SamplePositionsDeg)
+		if err := send(msg); err != nil {
+			return nil, err
+		}
+	}
+	return c.sampleCount(), nil
+}
+
+// kinematicsFile is the layout of a kinematics file.
+type kinematicsFile struct {
+	Name         string                       `json:"name"`
+	KinParamType string                       `json:"kinematic_param_type"`
+	Links        []referenceframe.LinkConfig  `json:"links"`
+	Joints       []referenceframe.JointConfig `json:"joints"`
+}
+
+// calibrate refines the kinematics of the arm from the recorded samples and writes them to the output file.
+func (c *calibrator) calibrate() (map[string]interface{}, error) {
+	c.mu.Lock()
+	samples := append([]Sample(nil), c.samples...)
+	c.mu.Unlock()
+	if len(samples) == 0 {
+		return nil, errors.New("no samples to calibrate with")
+	}
+
+	result, err := Calibrate(c.arm.ModelFrame(), samples, c.options)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(kinematicsFile{
+		Name:         result.Kinematics.Name,
+		KinParamType: result.Kinematics.KinParamType,
+		Links:        result.Kinematics.Links,
+		Joints:       result.Kinematics.Joints,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(c.conf.OutputFile, data, 0o600); err != nil {
+		return nil, errors.Wrap(err, "cannot write kinematics file")
+	}
+	c.logger.Infow("wrote calibrated kinematics",
+		"file", c.conf.OutputFile,
+		"samples", len(samples),
+		"position_error_before_mm", result.PositionErrorBeforeMM,
+		"position_error_after_mm", result.PositionErrorAfterMM,
+	)
+
+	offsets := make([]interface{}, 0, len(result.JointOffsets))
+	for _, offset := range result.JointOffsets {
+		offsets = append(offsets, offset)
+	}
+	return map[string]interface{}{
+		"kinematics_file":              c.conf.OutputFile,
+		"samples":                      len(samples),
+		"joint_offsets":                offsets,
+		"measurement_frame":            poseToMap(result.MeasurementFrame),
+		"marker_offset":                poseToMap(result.MarkerOffset),
+		"position_error_before_mm":     result.PositionErrorBeforeMM,
+		"position_error_after_mm":      result.PositionErrorAfterMM,
+		"orientation_error_before_deg": result.OrientationErrorBeforeDeg,
+		"orientation_error_after_deg":  result.OrientationErrorAfterDeg,
+	}, nil
+}
+
+// poseFromMap parses a pose from a map of x, y, z and an optional orientation vector in degrees, saying whether it
+// has no orientation.
+func poseFromMap(m map[string]interface{}) (spatialmath.Pose, bool, error) {
+	number := func(key string) (float64, bool, error) {
+		raw, ok := m[key]
+		if !ok {
+			return 0, false, nil
+		}
+		value, ok := raw.(float64)
+		if !ok {
+			return 0, false, errors.Errorf("pose %s must be a number", key)
+		}
+		return value, true, nil
+	}
+	var values [7]float64
+	var present [7]bool
+	for i, key := range []string{"x", "y", "z", "o_x", "o_y", "o_z", "theta"} {
+		var err error
+		if values[i], present[i], err = number(key); err != nil {
+			return nil, false, err
+		}
+	}
+	if !present[0] || !present[1] || !present[2] {
+		return nil, false, errors.New("pose needs x, y and z")
+	}
+	point := r3.Vector{X: values[0], Y: values[1], Z: values[2]}
+	if !present[3] && !present[4] && !present[5] {
+		return spatialmath.NewPoseFromPoint(point), true, nil
+	}
+	orientation := &spatialmath.OrientationVectorDegrees{OX: values[3], OY: values[4], OZ: values[5], Theta: values[6]}
+	return spatialmath.NewPose(point, orientation), false, nil
+}
+
+func poseToMap(pose spatialmath.Pose) map[string]interface{} {
+	orientation := pose.Orientation().OrientationVectorDegrees()
+	return map[string]interface{}{
+		"x":     pose.Point().X,
+		"y":     pose.Point().Y,
+		"z":     pose.Point().Z,
+		"o_x":   orientation.OX,
+		"o_y":   orientation.OY,
+		"o_z":   orientation.OZ,
+		"theta": orientation.Theta,
+	}
+}
@@ -0,0 +1,236 @@
+package powermanager
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/utils"
+)
+
+// The chemistries with a built in discharge curve.
+const (
+	ChemistryLiPo     = "lipo"
+	ChemistryLiFePO4  = "lifepo4"
+	ChemistryLeadAcid = "lead_acid"
+)
+
+const (
+	// restingCRate is the current, as a fraction of the capacity per hour, below which the battery is taken to be at
+	// rest, when its voltage is close to its open circuit voltage.
+	restingCRate = 0.02
+	// The charge follows the voltage curve with these time constants, closely at rest, where the curve is accurate,
+	// and loosely under load, where the charge is counted from the current instead.
+	restingTimeConstant = 10 * time.Second
+	loadTimeConstant    = 30 * time.Minute
+	// currentTimeConstant smooths the current which the time to empty and to full is estimated from.
+	currentTimeConstant = time.Minute
+	// minHealthSpan is how much the charge has to change between two rests for the capacity to be measured.
+	minHealthSpan = 20.
+	// healthWeight is the weight of each new measurement of the capacity.
+	healthWeight = 0.2
+)
+
+// A DischargePoint is the resting voltage of a battery at a charge.
+type DischargePoint struct {
+	Percent float64 `json:"percent"`
+	Volts   float64 `json:"volts"`
+}
+
+// cellCurves are the resting voltages of a single cell of each chemistry.
+var cellCurves = map[string][]DischargePoint{
+	ChemistryLiPo: {
+		{0, 3.00}, {5, 3.45}, {10, 3.68}, {20, 3.74}, {30, 3.77}, {40, 3.79},
+		{50, 3.82}, {60, 3.87}, {70, 3.92}, {80, 3.98}, {90, 4.06}, {100, 4.20},
+	},
+	ChemistryLiFePO4: {
+		{0, 2.50}, {10, 3.00}, {20, 3.20}, {30, 3.22}, {40, 3.25}, {50, 3.26},
+		{60, 3.27}, {70, 3.30}, {80, 3.32}, {90, 3.35}, {100, 3.40},
+	},
+	ChemistryLeadAcid: {
+		{0, 1.750}, {10, 1.918}, {20, 1.943}, {30, 1.968}, {40, 1.993}, {50, 2.017},
+		{60, 2.040}, {70, 2.062}, {80, 2.083}, {90, 2.103}, {100, 2.122},
+	},
+}
+
+// BatteryModel describes a battery well enough to estimate its charge by counting the current through it, corrected
+// by its voltage, rather than from its voltage alone, and to estimate its time to empty and its health.
+type BatteryModel struct {
+	CapacityAmpHours float64 `json:"capacity_amp_hours"`
+	// Chemistry and Cells, the number of cells in series, give the discharge curve of the battery, unless
+	// DischargeCurve gives it directly.
+	Chemistry      string           `json:"chemistry,omitempty"`
+	Cells          int              `json:"cells,omitempty"`
+	DischargeCurve []DischargePoint `json:"discharge_curve,omitempty"`
+	// InternalResistanceOhms corrects the voltage for its sag under load.
+	InternalResistanceOhms float64 `json:"internal_resistance_ohms,omitempty"`
+}
+
+// Validate ensures the battery has a capacity and a discharge curve.
+func (m *BatteryModel) Validate() error {
+	if m.CapacityAmpHours <= 0 {
+		return errors.New("capacity_amp_hours must be positive")
+	}
+	if m.InternalResistanceOhms < 0 {
+		return errors.New("internal_resistance_ohms cannot be negative")
+	}
+	if len(m.DischargeCurve) > 0 {
+		if m.Chemistry != "" {
+			return errors.New("only one of chemistry and discharge_curve can be given")
+		}
+		if len(m.DischargeCurve) < 2 {
+			return errors.New("discharge_curve needs at least 2 points")
+		}
+		curve := m.curve()
+		for i, point := range curve {
+			if point.Percent < 0 || point.Percent > 100 {
+				return errors.New("discharge_curve percentages must be between 0 and 100")
+			}
+			if i > 0 && (point.Volts <= curve[i-1].Volts || point.Percent <= curve[i-1].Percent) {
+				return errors.New("discharge_curve voltages must rise with the charge")
+			}
+		}
+		return nil
+	}
+	if _, ok := cellCurves[m.Chemistry]; !ok {
+		return errors.Errorf("unknown chemistry %q, expected one of %q, %q and %q or a discharge_curve",
+			m.Chemistry, ChemistryLiPo, ChemistryLiFePO4, ChemistryLeadAcid)
+	}
+	if m.Cells < 1 {
+		return errors.New("cells must be at least 1")
+	}
+	return nil
+}
+
+// curve returns the discharge curve of the battery, from empty to full.
+func (m *BatteryModel) curve() []DischargePoint {
+	curve := append([]DischargePoint(nil), m.DischargeCurve...)
+	if len(curve) == 0 {
+		for _, point := range cellCurves[m.Chemistry] {
+			curve = append(curve, DischargePoint{Percent: point.Percent, Volts: point.Volts * float64(m.Cells)})
+		}
+	}
+	sort.Slice(curve, func(i, j int) bool { return curve[i].Volts < curve[j].Volts })
+	return curve
+}
+
+// batteryEstimate is what is known of a battery after a reading.
+type batteryEstimate struct {
+	percent float64
+	// timeToEmpty and timeToFull are 0 when the battery is not discharging or charging.
+	timeToEmpty, timeToFull time.Duration
+	// health is the measured capacity of the battery as a fraction of its rated capacity, or 0 until it has been
+	// measured.
+	health float64
+}
+
+// batteryEstimator estimates the charge of a battery from its readings over time.
+type batteryEstimator struct {
+	model BatteryModel
+	curve []DischargePoint
+
+	last     time.Time
+	estimate batteryEstimate
+	current  float64
+
+	// the charge and the amp hours drawn since the battery was last at rest, which measure its capacity the next time
+	// it rests
+	rested      bool
+	wasResting  bool
+	restPercent float64
+	drawnAh     float64
+}
+
+func newBatteryEstimator(model BatteryModel) *batteryEstimator {
+	return &batteryEstimator{model: model, curve: model.curve()}
+}
+
+// restingPercent returns the charge of the curve at a resting voltage.
+func (e *batteryEstimator) restingPercent(volts float64) float64 {
+	curve := e.curve
+	if volts <= curve[0].Volts {
+		return curve[0].Percent
+	}
+	for i := 1; i < len(curve); i++ {
+		if volts <= curve[i].Volts {
+			t := (volts - curve[i-1].Volts) / (curve[i].Volts - curve[i-1].Volts)
+			return curve[i-1].Percent + t*(curve[i].Percent-curve[i-1].Percent)
+		}
+	}
+	return curve[len(curve)-1].Percent
+}
+
+// update estimates the charge from a reading of the battery, with a positive current while discharging.
+func (e *batteryEstimator) update(volts, amps float64, now time.Time) batteryEstimate {
+	capacity := e.model.CapacityAmpHours
+	if e.estimate.health > 0 {
+		capacity *= e.estimate.health
+	}
+	resting := math.Abs(amps) <= restingCRate*e.model.CapacityAmpHours
+	voltagePercent := e.restingPercent(volts + amps*e.model.InternalResistanceOhms)
+
+	if e.last.IsZero() {
+		e.estimate.percent = voltagePercent
+		e.current = amps
+	} else {
+		dt := now.Sub(e.last)
+		hours := dt.Hours()
+		e.drawnAh += amps * hours
+		e.estimate.percent -= 100 * amps * hours / capacity
+		timeConstant := loadTimeConstant
+		if resting {
+			timeConstant = restingTimeConstant
+		}
+		e.estimate.percent += (voltagePercent - e.estimate.percent) * smoothing(dt, timeConstant)
+		e.estimate.percent = utils.Clamp(e.estimate.percent, 0, 100)
+		e.current += (amps - e.current) * smoothing(dt, currentTimeConstant)
+	}
+	e.last = now
+
+	e.measureHealth(voltagePercent, resting)
+
+	e.estimate.timeToEmpty, e.estimate.timeToFull = 0, 0
+	if math.Abs(e.current) > restingCRate*e.model.CapacityAmpHours {
+		if e.current > 0 {
+			e.estimate.timeToEmpty = hoursToDuration(e.estimate.percent / 100 * capacity / e.current)
+		} else {
+			e.estimate.timeToFull = hoursToDuration((100 - e.estimate.percent) / 100 * capacity / -e.current)
+		}
+	}
+	return e.estimate
+}
+
+// measureHealth measures the capacity of the battery from the amp hours drawn between two rests far enough apart in
+// charge, since the charge at rest is known from the voltage curve alone.
+func (e *batteryEstimator) measureHealth(percent float64, resting bool) {
+	defer func() { e.wasResting = resting }()
+	if !resting {
+		return
+	}
+	switch {
+	case !e.rested || e.wasResting:
+		// the first rest, or the voltage settling over one
+	case math.Abs(e.restPercent-percent) >= minHealthSpan:
+		health := math.Abs(e.drawnAh) / (math.Abs(e.restPercent-percent) / 100) / e.model.CapacityAmpHours
+		if e.estimate.health == 0 {
+			e.estimate.health = health
+		} else {
+			e.estimate.health += (health - e.estimate.health) * healthWeight
+		}
+	default:
+		// too close in charge to the last rest to measure from, so the amp hours are counted on from it
+		return
+	}
+	e.rested, e.restPercent, e.drawnAh = true, percent, 0
+}
+
+// smoothing returns the weight of a new value of an exponential average with a time constant.
+func smoothing(dt, timeConstant time.Duration) float64 {
+	return dt.Seconds() / (timeConstant.Seconds() + dt.Seconds())
+}
+
+func hoursToDuration(hours float64) time.Duration {
+	return time.Duration(hours * float64(time.Hour))
+}
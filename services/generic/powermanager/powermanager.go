@@ -0,0 +1,479 @@
+// Package powermanager implements a generic service which watches the power sensors and battery of a machine and acts
+// as the battery runs down: at a low level it sends a command which returns the machine to its dock, at a critical
+// level it stops resources which are not needed to get home, and at the shutdown level it stops everything it knows of
+// and runs a shutdown command. Nothing is done while the battery is charging.
+//
+// The charge of the battery is estimated from its voltage alone, unless a battery model gives its capacity and
+// discharge curve. The charge is then counted from the current through the battery and corrected by its voltage, and
+// its time to empty or to full and its health, its measured capacity as a percentage of its rated capacity, are
+// estimated along with it.
+//
+// The power state is returned by DoCommand:
+//
+//	{"command": "power_status"}
+package powermanager
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/components/powersensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/utils"
+)
+
+// Model is the model of the power manager service.
+var Model = resource.DefaultModelFamily.WithModel("power_manager")
+
+const (
+	defaultLowBatteryPercent      = 20.
+	defaultCriticalBatteryPercent = 10.
+	defaultShutdownBatteryPercent = 5.
+	defaultHysteresisPercent      = 2.
+	defaultPollFrequencyHz        = 1.
+)
+
+// A Level is how far the battery has run down.
+type Level int
+
+// The levels in order of a battery running down.
+const (
+	LevelNormal Level = iota
+	LevelLow
+	LevelCritical
+	LevelShutdown
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelLow:
+		return "low"
+	case LevelCritical:
+		return "critical"
+	case LevelShutdown:
+		return "shutdown"
+	case LevelNormal:
+	}
+	return "normal"
+}
+
+func init() {
+	resource.RegisterService(generic.API, Model, resource.Registration[resource.Resource, *Config]{
+		Constructor: newPowerManager,
+	})
+}
+
+// A Command is a DoCommand sent to a resource.
+type Command struct {
+	Resource string                 `json:"resource"`
+	Command  map[string]interface{} `json:"command"`
+}
+
+// Config describes how to configure the service.
+type Config struct {
+	// PowerSensors are summed into the power drawn by the machine.
+	PowerSensors []string `json:"power_sensors,omitempty"`
+	// Battery is the power sensor on the battery. Its charge is estimated with BatteryModel when it is given, and
+	// otherwise from its voltage between EmptyVoltage and FullVoltage. It is charging while its current is negative.
+	Battery      string        `json:"battery,omitempty"`
+	FullVoltage  float64       `json:"full_voltage,omitempty"`
+	EmptyVoltage float64       `json:"empty_voltage,omitempty"`
+	BatteryModel *BatteryModel `json:"battery_model,omitempty"`
+
+	LowBatteryPercent      float64 `json:"low_battery_percent,omitempty"`
+	CriticalBatteryPercent float64 `json:"critical_battery_percent,omitempty"`
+	ShutdownBatteryPercent float64 `json:"shutdown_battery_percent,omitempty"`
+	// HysteresisPercent is how far above a level the charge must come back before the level is left, so the actions
+	// of the level run again the next time the battery runs down.
+	HysteresisPercent float64 `json:"hysteresis_percent,omitempty"`
+
+	// Dock is sent when the battery is low, typically to a navigation service or base which returns to the dock.
+	Dock *Command `json:"dock,omitempty"`
+	// ShedResources are stopped when the battery is critical.
+	ShedResources []string `json:"shed_resources,omitempty"`
+	// ShutdownCommand is run when the battery reaches the shutdown level, after all of the resources of the service are
+	// stopped, e.g. ["sudo", "shutdown", "-h", "now"].
+	ShutdownCommand []string `json:"shutdown_command,omitempty"`
+
+	PollFrequencyHz float64 `json:"poll_frequency_hz,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if len(conf.PowerSensors) == 0 && conf.Battery == "" {
+		return nil, resource.NewConfigValidationError(path, errors.New("power_sensors or a battery is required"))
+	}
+	if conf.BatteryModel != nil {
+		if conf.Battery == "" {
+			return nil, resource.NewConfigValidationError(path, errors.New("a battery_model needs a battery to watch"))
+		}
+		if err := conf.BatteryModel.Validate(); err != nil {
+			return nil, resource.NewConfigValidationError(path+".battery_model", err)
+		}
+	} else if conf.Battery != "" && conf.FullVoltage <= conf.EmptyVoltage {
+		return nil, resource.NewConfigValidationError(path, errors.New("full_voltage must be above empty_voltage"))
+	}
+	if conf.Battery == "" && (conf.Dock != nil || len(conf.ShedResources) > 0 || len(conf.ShutdownCommand) > 0) {
+		return nil, resource.NewConfigValidationError(path, errors.New("low battery actions need a battery to watch"))
+	}
+	for _, percent := range []float64{
+		conf.LowBatteryPercent, conf.CriticalBatteryPercent, conf.ShutdownBatteryPercent, conf.HysteresisPercent,
+	} {
+		if percent < 0 || percent > 100 {
+			return nil, resource.NewConfigValidationError(path, errors.New("battery percentages must be between 0 and 100"))
+		}
+	}
+	low, critical, shutdown := conf.thresholds()
+	if !(low > critical && critical > shutdown) {
+		return nil, resource.NewConfigValidationError(path, errors.New(
+			"low_battery_percent must be above critical_battery_percent, which must be above shutdown_battery_percent"))
+	}
+	if conf.Dock != nil && conf.Dock.Resource == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path+".dock", "resource")
+	}
+	if conf.PollFrequencyHz < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("poll_frequency_hz cannot be negative"))
+	}
+
+	deps := append([]string{}, conf.PowerSensors...)
+	if conf.Battery != "" {
+		deps = append(deps, conf.Battery)
+	}
+	if conf.Dock != nil {
+		deps = append(deps, conf.Dock.Resource)
+	}
+	return append(deps, conf.ShedResources...), nil
+}
+
+// thresholds returns the battery percentages at which the battery becomes low, critical and shuts down.
+func (conf *Config) thresholds() (float64, float64, float64) {
+	low, critical, shutdown := conf.LowBatteryPercent, conf.CriticalBatteryPercent, conf.ShutdownBatteryPercent
+	if low == 0 {
+		low = defaultLowBatteryPercent
+	}
+	if critical == 0 {
+		critical = defaultCriticalBatteryPercent
+	}
+	if shutdown == 0 {
+		shutdown = defaultShutdownBatteryPercent
+	}
+	return low, critical, shutdown
+}
+
+// sensorState is the last reading of a power sensor.
+type sensorState struct {
+	voltage, current, power float64
+	isAC                    bool
+	err                     error
+}
+
+type powerManager struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	logger  logging.Logger
+	conf    *Config
+	workers utils.StoppableWorkers
+
+	sensors       map[string]powersensor.PowerSensor
+	battery       powersensor.PowerSensor
+	dock          resource.Resource
+	shed          map[string]resource.Resource
+	low, critical float64
+	shutdown      float64
+	hysteresis    float64
+
+	mu           sync.Mutex
+	readings     map[string]sensorState
+	batteryState *sensorState
+	estimator    *batteryEstimator
+	estimate     batteryEstimate
+	percent      float64
+	charging     bool
+	level        Level
+	actedOn      Level
+	actionErrors []string
+}
+
+func newPowerManager(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (resource.Resource, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	pm := &powerManager{
+		Named:      conf.ResourceName().AsNamed(),
+		logger:     logger,
+		conf:       newConf,
+		sensors:    map[string]powersensor.PowerSensor{},
+		shed:       map[string]resource.Resource{},
+		hysteresis: newConf.HysteresisPercent,
+		readings:   map[string]sensorState{},
+	}
+	pm.low, pm.critical, pm.shutdown = newConf.thresholds()
+	if newConf.BatteryModel != nil {
+		pm.estimator = newBatteryEstimator(*newConf.BatteryModel)
+	}
+	if pm.hysteresis == 0 {
+		pm.hysteresis = defaultHysteresisPercent
+	}
+	for _, name := range newConf.PowerSensors {
+		if pm.sensors[name], err = powersensor.FromDependencies(deps, name); err != nil {
+			return nil, err
+		}
+	}
+	if newConf.Battery != "" {
+		if pm.battery, err = powersensor.FromDependencies(deps, newConf.Battery); err != nil {
+			return nil, err
+		}
+	}
+	if newConf.Dock != nil {
+		if pm.dock, err = dependencyByName(deps, newConf.Dock.Resource); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range newConf.ShedResources {
+		if pm.shed[name], err = dependencyByName(deps, name); err != nil {
+			return nil, err
+		}
+	}
+	pollFrequencyHz := newConf.PollFrequencyHz
+	if pollFrequencyHz == 0 {
+		pollFrequencyHz = defaultPollFrequencyHz
+	}
+
+	pm.poll(ctx)
+	period := time.Duration(float64(time.Second) / pollFrequencyHz)
+	pm.workers = utils.NewStoppableWorkers(func(ctx context.Context) {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			pm.poll(ctx)
+		}
+	})
+	return pm, nil
+}
+
+// dependencyByName returns the dependency with the given name, whatever its API.
+func dependencyByName(deps resource.Dependencies, name string) (resource.Resource, error) {
+	for depName, res := range deps {
+		if depName.ShortName() == name {
+			return res, nil
+		}
+	}
+	return nil, errors.Errorf("resource %q not found in dependencies", name)
+}
+
+// readSensor reads the voltage, current and power of a power sensor.
+func readSensor(ctx context.Context, sensor powersensor.PowerSensor) sensorState {
+	var state sensorState
+	if state.voltage, state.isAC, state.err = sensor.Voltage(ctx, nil); state.err != nil {
+		return state
+	}
+	if state.current, _, state.err = sensor.Current(ctx, nil); state.err != nil {
+		return state
+	}
+	state.power, state.err = sensor.Power(ctx, nil)
+	return state
+}
+
+// poll reads the sensors and runs the actions of any level the battery has run down to.
+func (pm *powerManager) poll(ctx context.Context) {
+	readings := make(map[string]sensorState, len(pm.sensors))
+	for name, sensor := range pm.sensors {
+		readings[name] = readSensor(ctx, sensor)
+	}
+	var batteryState *sensorState
+	if pm.battery != nil {
+		state := readSensor(ctx, pm.battery)
+		batteryState = &state
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	pm.mu.Lock()
+	pm.readings = readings
+	pm.batteryState = batteryState
+	if batteryState == nil {
+		pm.mu.Unlock()
+		return
+	}
+	if batteryState.err != nil {
+		// a battery which cannot be read is not taken to be empty, since shutting down on a loose wire does more harm
+		pm.mu.Unlock()
+		pm.logger.CWarnw(ctx, "cannot read battery", "error", batteryState.err)
+		return
+	}
+	if pm.estimator != nil {
+		pm.estimate = pm.estimator.update(batteryState.voltage, batteryState.current, time.Now())
+		pm.percent = pm.estimate.percent
+	} else {
+		pm.percent = 100 * (batteryState.voltage - pm.conf.EmptyVoltage) / (pm.conf.FullVoltage - pm.conf.EmptyVoltage)
+		pm.percent = utils.Clamp(pm.percent, 0, 100)
+	}
+	pm.charging = batteryState.current < 0
+	previous := pm.level
+	pm.level = pm.levelFor(pm.percent)
+	if pm.level != previous {
+		pm.logger.CInfow(ctx, "battery level changed",
+			"level", pm.level.String(), "battery_percent", pm.percent, "charging", pm.charging)
+	}
+	if pm.level < pm.actedOn {
+		// the battery has recovered, so its actions run again the next time it runs down
+		pm.actedOn = pm.level
+	}
+	from, to := pm.actedOn, pm.level
+	if pm.charging || to <= from {
+		pm.mu.Unlock()
+		return
+	}
+	pm.actedOn = to
+	pm.mu.Unlock()
+
+	var actionErrors []string
+	for level := from + 1; level <= to; level++ {
+		if err := pm.act(ctx, level); err != nil {
+			pm.logger.CErrorw(ctx, "low battery action failed", "level", level.String(), "error", err)
+			actionErrors = append(actionErrors, err.Error())
+		}
+	}
+	pm.mu.Lock()
+	pm.actionErrors = actionErrors
+	pm.mu.Unlock()
+}
+
+// levelFor returns the level of a battery charge, leaving the current level only once the charge is past it by the
+// hysteresis.
+func (pm *powerManager) levelFor(percent float64) Level {
+	thresholds := []float64{pm.low, pm.critical, pm.shutdown}
+	level := LevelNormal
+	for i, threshold := range thresholds {
+		if Level(i+1) <= pm.level {
+			threshold += pm.hysteresis
+		}
+		if percent <= threshold {
+			level = Level(i + 1)
+		}
+	}
+	return level
+}
+
+// act runs the actions of a level the battery has just run down to.
+func (pm *powerManager) act(ctx context.Context, level Level) error {
+	switch level {
+	case LevelLow:
+		if pm.dock == nil {
+			return nil
+		}
+		pm.logger.CWarnw(ctx, "battery low, returning to dock", "resource", pm.conf.Dock.Resource, "battery_percent", pm.percent)
+		_, err := pm.dock.DoCommand(ctx, pm.conf.Dock.Command)
+		return errors.Wrapf(err, "cannot send dock command to %q", pm.conf.Dock.Resource)
+	case LevelCritical:
+		pm.logger.CWarnw(ctx, "battery critical, shedding resources", "resources", pm.conf.ShedResources)
+		var err error
+		for _, name := range pm.conf.ShedResources {
+			err = multierr.Combine(err, stop(ctx, name, pm.shed[name]))
+		}
+		return err
+	case LevelShutdown:
+		pm.logger.CErrorw(ctx, "battery empty, shutting down", "battery_percent", pm.percent)
+		var err error
+		if pm.dock != nil {
+			err = stop(ctx, pm.conf.Dock.Resource, pm.dock)
+		}
+		for _, name := range pm.conf.ShedResources {
+			err = multierr.Combine(err, stop(ctx, name, pm.shed[name]))
+		}
+		if len(pm.conf.ShutdownCommand) == 0 {
+			return err
+		}
+		//nolint:gosec
+		out, cmdErr := exec.CommandContext(ctx, pm.conf.ShutdownCommand[0], pm.conf.ShutdownCommand[1:]...).CombinedOutput()
+		if cmdErr != nil {
+			cmdErr = errors.Wrapf(cmdErr, "shutdown command failed: %s", strings.TrimSpace(string(out)))
+		}
+		return multierr.Combine(err, cmdErr)
+	case LevelNormal:
+	}
+	return nil
+}
+
+// stop stops a resource if it is an actuator.
+func stop(ctx context.Context, name string, res resource.Resource) error {
+	actuator, ok := res.(resource.Actuator)
+	if !ok {
+		return nil
+	}
+	return errors.Wrapf(actuator.Stop(ctx, nil), "cannot stop %q", name)
+}
+
+// DoCommand returns the power state of the machine for "power_status".
+func (pm *powerManager) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if cmd["command"] != "power_status" {
+		return nil, resource.ErrDoUnimplemented
+	}
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	sensors := map[string]interface{}{}
+	var totalPower, totalCurrent float64
+	for name, state := range pm.readings {
+		if state.err != nil {
+			sensors[name] = map[string]interface{}{"error": state.err.Error()}
+			continue
+		}
+		sensors[name] = map[string]interface{}{
+			"volts": state.voltage, "amps": state.current, "watts": state.power, "is_ac": state.isAC,
+		}
+		totalPower += state.power
+		totalCurrent += state.current
+	}
+	resp := map[string]interface{}{"sensors": sensors, "watts": totalPower, "amps": totalCurrent}
+	if pm.batteryState != nil {
+		battery := map[string]interface{}{
+			"percent": pm.percent, "charging": pm.charging, "level": pm.level.String(),
+		}
+		if pm.batteryState.err != nil {
+			battery["error"] = pm.batteryState.err.Error()
+		} else {
+			battery["volts"] = pm.batteryState.voltage
+			battery["amps"] = pm.batteryState.current
+		}
+		if pm.estimate.timeToEmpty > 0 {
+			battery["time_to_empty_sec"] = pm.estimate.timeToEmpty.Seconds()
+		}
+		if pm.estimate.timeToFull > 0 {
+			battery["time_to_full_sec"] = pm.estimate.timeToFull.Seconds()
+		}
+		if pm.estimate.health > 0 {
+			battery["health_percent"] = 100 * pm.estimate.health
+		}
+		if len(pm.actionErrors) > 0 {
+			battery["action_errors"] = pm.actionErrors
+		}
+		resp["battery"] = battery
+	}
+	return resp, nil
+}
+
+func (pm *powerManager) Close(ctx context.Context) error {
+	pm.workers.Stop()
+	return nil
+}
@@ -0,0 +1,309 @@
+package powermanager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestValidate(t *testing.T) {
+	conf := &Config{Battery: "battery", FullVoltage: 12.6, EmptyVoltage: 10}
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"battery"})
+
+	conf.Dock = &Command{Resource: "nav"}
+	conf.ShedResources = []string{"arm"}
+	deps, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"battery", "nav", "arm"})
+
+	_, err = (&Config{}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{Battery: "battery", FullVoltage: 10, EmptyVoltage: 12}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{PowerSensors: []string{"ps"}, ShedResources: []string{"arm"}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{Battery: "battery", FullVoltage: 12.6, EmptyVoltage: 10, CriticalBatteryPercent: 30}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// a battery model replaces the full and empty voltages
+	model := &BatteryModel{CapacityAmpHours: 20, Chemistry: ChemistryLiFePO4, Cells: 4}
+	_, err = (&Config{Battery: "battery", BatteryModel: model}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	_, err = (&Config{PowerSensors: []string{"ps"}, BatteryModel: model}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	for _, tc := range []struct {
+		model BatteryModel
+		err   string
+	}{
+		{BatteryModel{Chemistry: ChemistryLiPo, Cells: 3}, "capacity_amp_hours must be positive"},
+		{BatteryModel{CapacityAmpHours: 5, Chemistry: "zinc", Cells: 3}, `unknown chemistry "zinc"`},
+		{BatteryModel{CapacityAmpHours: 5, Chemistry: ChemistryLiPo}, "cells must be at least 1"},
+		{BatteryModel{CapacityAmpHours: 5, DischargeCurve: []DischargePoint{{0, 10}}}, "at least 2 points"},
+		{BatteryModel{CapacityAmpHours: 5, DischargeCurve: []DischargePoint{{0, 10}, {50, 12}, {100, 11}}}, "must rise"},
+	} {
+		_, err := (&Config{Battery: "battery", BatteryModel: &tc.model}).Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, tc.err)
+	}
+}
+
+func TestBatteryEstimator(t *testing.T) {
+	// a battery whose resting voltage rises linearly from 10V empty to 12.6V full, and sags by 0.05V per amp
+	model := BatteryModel{
+		CapacityAmpHours:       10,
+		DischargeCurve:         []DischargePoint{{Percent: 0, Volts: 10}, {Percent: 100, Volts: 12.6}},
+		InternalResistanceOhms: 0.05,
+	}
+	restingVolts := func(percent float64) float64 {
+		return 10 + 2.6*percent/100
+	}
+
+	for _, tc := range []struct {
+		name     string
+		actualAh float64
+	}{
+		{name: "new", actualAh: 10},
+		{name: "worn", actualAh: 8},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newBatteryEstimator(model)
+			now := time.Now()
+			percent := 50.
+			estimate := e.update(restingVolts(percent), 0, now)
+			test.That(t, estimate.percent, test.ShouldAlmostEqual, 50)
+			test.That(t, estimate.timeToEmpty, test.ShouldEqual, 0)
+			test.That(t, estimate.health, test.ShouldEqual, 0)
+
+			// 2.5Ah drawn at 5A
+			for i := 0; i < 30; i++ {
+				now = now.Add(time.Minute)
+				percent -= 100 * 5. / 60 / tc.actualAh
+				estimate = e.update(restingVolts(percent)-5*model.InternalResistanceOhms, 5, now)
+			}
+			if tc.actualAh == model.CapacityAmpHours {
+				// the count of the current and the voltage agree
+				test.That(t, estimate.percent, test.ShouldAlmostEqual, 25, 0.01)
+				test.That(t, estimate.timeToEmpty.Hours(), test.ShouldAlmostEqual, 0.5, 0.01)
+			} else {
+				// the count of the current overestimates the charge of a worn battery, which the voltage corrects
+				test.That(t, estimate.percent, test.ShouldBeBetween, percent, 25)
+			}
+
+			// at rest, the capacity is measured from the charge drawn
+			now = now.Add(time.Minute)
+			estimate = e.update(restingVolts(percent), 0, now)
+			test.That(t, estimate.health, test.ShouldAlmostEqual, tc.actualAh/model.CapacityAmpHours, 0.01)
+
+			// charging
+			for i := 0; i < 5; i++ {
+				now = now.Add(time.Minute)
+				percent += 100 * 5. / 60 / tc.actualAh
+				estimate = e.update(restingVolts(percent)+5*model.InternalResistanceOhms, -5, now)
+			}
+			test.That(t, estimate.timeToEmpty, test.ShouldEqual, 0)
+			test.That(t, estimate.timeToFull, test.ShouldBeGreaterThan, 0)
+		})
+	}
+
+	// a chemistry's curve is scaled by its cells, and clamped beyond it
+	e := newBatteryEstimator(BatteryModel{CapacityAmpHours: 5, Chemistry: ChemistryLiPo, Cells: 3})
+	test.That(t, e.restingPercent(3*3.82), test.ShouldAlmostEqual, 50)
+	test.That(t, e.restingPercent(13), test.ShouldEqual, 100)
+	test.That(t, e.restingPercent(8), test.ShouldEqual, 0)
+}
+
+func TestPowerManager(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	var mu sync.Mutex
+	volts, amps := 11.3, 2.
+	battery := inject.NewPowerSensor("battery")
+	battery.VoltageFunc = func(ctx context.Context, extra map[string]interface{}) (float64, bool, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return volts, false, nil
+	}
+	battery.CurrentFunc = func(ctx context.Context, extra map[string]interface{}) (float64, bool, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return amps, false, nil
+	}
+	battery.PowerFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return volts * amps, nil
+	}
+	setBattery := func(newVolts, newAmps float64) {
+		mu.Lock()
+		defer mu.Unlock()
+		volts, amps = newVolts, newAmps
+	}
+	motors := inject.NewPowerSensor("motors")
+	motors.VoltageFunc = func(ctx context.Context, extra map[string]interface{}) (float64, bool, error) {
+		return 12, false, nil
+	}
+	motors.CurrentFunc = func(ctx context.Context, extra map[string]interface{}) (float64, bool, error) {
+		return 1.5, false, nil
+	}
+	motors.PowerFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+		return 18, nil
+	}
+
+	var docked, shed int
+	nav := inject.NewGenericComponent("nav")
+	nav.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		test.That(t, cmd, test.ShouldResemble, map[string]interface{}{"command": "dock"})
+		docked++
+		return nil, nil
+	}
+	motor := inject.NewMotor("motor")
+	motor.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		shed++
+		return nil
+	}
+	counts := func() (int, int) {
+		mu.Lock()
+		defer mu.Unlock()
+		return docked, shed
+	}
+
+	marker := filepath.Join(t.TempDir(), "shutdown")
+	conf := &Config{
+		PowerSensors:    []string{"motors"},
+		Battery:         "battery",
+		FullVoltage:     12.6,
+		EmptyVoltage:    10,
+		Dock:            &Command{Resource: "nav", Command: map[string]interface{}{"command": "dock"}},
+		ShedResources:   []string{"motor"},
+		ShutdownCommand: []string{"touch", marker},
+		PollFrequencyHz: 200,
+	}
+	deps := resource.Dependencies{
+		battery.Name(): battery,
+		motors.Name():  motors,
+		nav.Name():     nav,
+		motor.Name():   motor,
+	}
+	res, err := newPowerManager(ctx, deps, resource.Config{
+		Name:                "power",
+		API:                 generic.API,
+		Model:               Model,
+		ConvertedAttributes: conf,
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, res.Close(ctx), test.ShouldBeNil)
+	}()
+
+	status, err := res.DoCommand(ctx, map[string]interface{}{"command": "power_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["watts"], test.ShouldEqual, 18.)
+	batteryStatus := status["battery"].(map[string]interface{})
+	test.That(t, batteryStatus["percent"], test.ShouldAlmostEqual, 50)
+	test.That(t, batteryStatus["level"], test.ShouldEqual, "normal")
+	test.That(t, batteryStatus["charging"], test.ShouldBeFalse)
+
+	// 15%: low, so the machine returns to its dock once
+	setBattery(10.39, 2)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		docked, shed := counts()
+		test.That(tb, docked, test.ShouldEqual, 1)
+		test.That(tb, shed, test.ShouldEqual, 0)
+	})
+
+	// 8%: critical, so resources are shed
+	setBattery(10.21, 2)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		docked, shed := counts()
+		test.That(tb, docked, test.ShouldEqual, 1)
+		test.That(tb, shed, test.ShouldEqual, 1)
+	})
+
+	// charging on the dock, nothing more happens
+	setBattery(10.05, -3)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		status, err := res.DoCommand(ctx, map[string]interface{}{"command": "power_status"})
+		test.That(tb, err, test.ShouldBeNil)
+		batteryStatus := status["battery"].(map[string]interface{})
+		test.That(tb, batteryStatus["level"], test.ShouldEqual, "shutdown")
+		test.That(tb, batteryStatus["charging"], test.ShouldBeTrue)
+	})
+	_, err = os.Stat(marker)
+	test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+
+	// once recovered, running down again docks again, and running out shuts down
+	setBattery(11.3, 2)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		status, err := res.DoCommand(ctx, map[string]interface{}{"command": "power_status"})
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, status["battery"].(map[string]interface{})["level"], test.ShouldEqual, "normal")
+	})
+	setBattery(10.05, 2)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		docked, shed := counts()
+		test.That(tb, docked, test.ShouldEqual, 2)
+		// shed at critical, and again with everything else at shutdown
+		test.That(tb, shed, test.ShouldEqual, 3)
+		_, err := os.Stat(marker)
+		test.That(tb, err, test.ShouldBeNil)
+	})
+}
+
+func TestPowerManagerBatteryModel(t *testing.T) {
+	ctx := context.Background()
+	battery := inject.NewPowerSensor("battery")
+	battery.VoltageFunc = func(ctx context.Context, extra map[string]interface{}) (float64, bool, error) {
+		return 3 * 3.82, false, nil
+	}
+	battery.CurrentFunc = func(ctx context.Context, extra map[string]interface{}) (float64, bool, error) {
+		return 2, false, nil
+	}
+	battery.PowerFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+		return 3 * 3.82 * 2, nil
+	}
+	res, err := newPowerManager(ctx, resource.Dependencies{battery.Name(): battery}, resource.Config{
+		Name:  "power",
+		API:   generic.API,
+		Model: Model,
+		ConvertedAttributes: &Config{
+			Battery:      "battery",
+			BatteryModel: &BatteryModel{CapacityAmpHours: 5, Chemistry: ChemistryLiPo, Cells: 3},
+		},
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, res.Close(ctx), test.ShouldBeNil)
+	}()
+
+	status, err := res.DoCommand(ctx, map[string]interface{}{"command": "power_status"})
+	test.That(t, err, test.ShouldBeNil)
+	batteryStatus := status["battery"].(map[string]interface{})
+	test.That(t, batteryStatus["percent"], test.ShouldAlmostEqual, 50, 0.1)
+	// half of 5Ah at 2A
+	test.That(t, batteryStatus["time_to_empty_sec"], test.ShouldAlmostEqual, 1.25*3600, 10)
+	test.That(t, batteryStatus, test.ShouldNotContainKey, "time_to_full_sec")
+	test.That(t, batteryStatus, test.ShouldNotContainKey, "health_percent")
+}
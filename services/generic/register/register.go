@@ -4,5 +4,10 @@ package register
 import (
 	// register generic.
 	_ "go.viam.com/rdk/services/generic"
+	_ "go.viam.com/rdk/services/generic/cameracalibration"
 	_ "go.viam.com/rdk/services/generic/fake"
+	_ "go.viam.com/rdk/services/generic/gateway"
+	_ "go.viam.com/rdk/services/generic/powermanager"
+	_ "go.viam.com/rdk/services/generic/syntheticdata"
+	_ "go.viam.com/rdk/services/generic/thermalmanager"
 )
@@ -0,0 +1,198 @@
+package syntheticdata
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+const (
+	// placementAttempts is how many places are tried for each object before it is left out of the image.
+	placementAttempts = 10
+	// opaqueAlpha is the alpha from which a pixel of an object counts towards its bounding box, so that the faint edges
+	// left by resampling do not loosen the box.
+	opaqueAlpha = 128
+)
+
+// Augmentations are the random changes made to each object, and to each image, so that a detector trained on the
+// images does not learn the particulars of the crops.
+type Augmentations struct {
+	// MinScale and MaxScale bound the scale each crop is drawn at.
+	MinScale float64 `json:"min_scale,omitempty"`
+	MaxScale float64 `json:"max_scale,omitempty"`
+	// MaxRotationDeg is the most each crop is rotated by, either way.
+	MaxRotationDeg float64 `json:"max_rotation_deg,omitempty"`
+	// Flip mirrors half the crops.
+	Flip bool `json:"flip,omitempty"`
+	// Brightness and Contrast are the most the brightness and contrast of each crop change by, as fractions.
+	Brightness float64 `json:"brightness,omitempty"`
+	Contrast   float64 `json:"contrast,omitempty"`
+	// NoiseStdDev is the standard deviation of the gaussian noise added to each image, in levels out of 255.
+	NoiseStdDev float64 `json:"noise_stddev,omitempty"`
+}
+
+// DefaultAugmentations are used when none are configured.
+var DefaultAugmentations = Augmentations{
+	MinScale:       0.5,
+	MaxScale:       1.5,
+	MaxRotationDeg: 15,
+	Flip:           true,
+	Brightness:     0.2,
+	Contrast:       0.2,
+	NoiseStdDev:    4,
+}
+
+// A BoundingBox is where an object was drawn in an image, in pixels, with the maximums exclusive.
+type BoundingBox struct {
+	Label string `json:"label"`
+	XMin  int    `json:"x_min"`
+	YMin  int    `json:"y_min"`
+	XMax  int    `json:"x_max"`
+	YMax  int    `json:"y_max"`
+}
+
+func (b BoundingBox) rect() image.Rectangle {
+	return image.Rect(b.XMin, b.YMin, b.XMax, b.YMax)
+}
+
+// An objectClass is a label and the crops of objects which have it.
+type objectClass struct {
+	label string
+	crops []image.Image
+}
+
+// compositor draws randomly augmented crops of objects over backgrounds.
+type compositor struct {
+	classes       []objectClass
+	augmentations Augmentations
+	minObjects    int
+	maxObjects    int
+	maxOverlap    float64
+	rng           *rand.Rand
+}
+
+// compose draws between minObjects and maxObjects crops over the background, each in a place where it overlaps the
+// ones before it by at most maxOverlap, and returns the image with the bounding box of each object drawn. Objects
+// which fit nowhere after a few attempts are left out.
+func (c *compositor) compose(background image.Image) (*image.NRGBA, []BoundingBox) {
+	bounds := background.Bounds()
+	canvas := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(canvas, canvas.Bounds(), background, bounds.Min, draw.Src)
+
+	var boxes []BoundingBox
+	count := c.minObjects + c.rng.Intn(c.maxObjects-c.minObjects+1)
+	for i := 0; i < count; i++ {
+		class := c.classes[c.rng.Intn(len(c.classes))]
+		crop := class.crops[c.rng.Intn(len(class.crops))]
+		for attempt := 0; attempt < placementAttempts; attempt++ {
+			object, visible := c.augment(crop)
+			if visible.Empty() || visible.Dx() > canvas.Bounds().Dx() || visible.Dy() > canvas.Bounds().Dy() {
+				continue
+			}
+			at := image.Pt(
+				c.rng.Intn(canvas.Bounds().Dx()-visible.Dx()+1),
+				c.rng.Intn(canvas.Bounds().Dy()-visible.Dy()+1),
+			)
+			placed := visible.Sub(visible.Min).Add(at)
+			if c.overlapsTooMuch(placed, boxes) {
+				continue
+			}
+			draw.Draw(canvas, placed, object, visible.Min, draw.Over)
+			boxes = append(boxes, BoundingBox{
+				Label: class.label,
+				XMin:  placed.Min.X, YMin: placed.Min.Y, XMax: placed.Max.X, YMax: placed.Max.Y,
+			})
+			break
+		}
+	}
+	c.addNoise(canvas)
+	return canvas, boxes
+}
+
+func (c *compositor) overlapsTooMuch(r image.Rectangle, boxes []BoundingBox) bool {
+	for _, b := range boxes {
+		if intersectionOverUnion(r, b.rect()) > c.maxOverlap {
+			return true
+		}
+	}
+	return false
+}
+
+func intersectionOverUnion(a, b image.Rectangle) float64 {
+	inter := a.Intersect(b)
+	if inter.Empty() {
+		return 0
+	}
+	area := func(r image.Rectangle) float64 { return float64(r.Dx() * r.Dy()) }
+	return area(inter) / (area(a) + area(b) - area(inter))
+}
+
+// augment returns the crop randomly scaled, rotated, mirrored and changed in brightness and contrast, on a
+// transparent image, with the bounds of its opaque pixels.
+func (c *compositor) augment(crop image.Image) (*image.NRGBA, image.Rectangle) {
+	aug := c.augmentations
+	scale := aug.MinScale + c.rng.Float64()*(aug.MaxScale-aug.MinScale)
+	angle := (2*c.rng.Float64() - 1) * aug.MaxRotationDeg * math.Pi / 180
+	mirror := 1.
+	if aug.Flip && c.rng.Intn(2) == 1 {
+		mirror = -1
+	}
+
+	src := crop.Bounds()
+	w, h := float64(src.Dx()), float64(src.Dy())
+	sin, cos := math.Sincos(angle)
+	dstW := int(math.Ceil(scale * (math.Abs(w*cos) + math.Abs(h*sin))))
+	dstH := int(math.Ceil(scale * (math.Abs(w*sin) + math.Abs(h*cos))))
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+	if dstW == 0 || dstH == 0 {
+		return dst, image.Rectangle{}
+	}
+
+	// the crop is mirrored, rotated and scaled about its center, which is then moved to the center of dst
+	a, b := scale*mirror*cos, -scale*sin
+	d, e := scale*mirror*sin, scale*cos
+	srcX, srcY := float64(src.Min.X)+w/2, float64(src.Min.Y)+h/2
+	dstX, dstY := float64(dstW)/2, float64(dstH)/2
+	transform := f64.Aff3{a, b, dstX - a*srcX - b*srcY, d, e, dstY - d*srcX - e*srcY}
+	draw.BiLinear.Transform(dst, transform, crop, src, draw.Over, nil)
+
+	brightness := 1 + (2*c.rng.Float64()-1)*aug.Brightness
+	contrast := 1 + (2*c.rng.Float64()-1)*aug.Contrast
+	visible := image.Rectangle{}
+	for y := 0; y < dstH; y++ {
+		for x := 0; x < dstW; x++ {
+			px := dst.NRGBAAt(x, y)
+			if px.A == 0 {
+				continue
+			}
+			adjust := func(v uint8) uint8 {
+				return clampLevel(((float64(v)-128)*contrast + 128) * brightness)
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{R: adjust(px.R), G: adjust(px.G), B: adjust(px.B), A: px.A})
+			if px.A >= opaqueAlpha {
+				visible = visible.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return dst, visible
+}
+
+// addNoise adds gaussian noise to every channel of every pixel of the image.
+func (c *compositor) addNoise(img *image.NRGBA) {
+	if c.augmentations.NoiseStdDev <= 0 {
+		return
+	}
+	for i := 0; i < len(img.Pix); i += 4 {
+		for j := i; j < i+3; j++ {
+			img.Pix[j] = clampLevel(float64(img.Pix[j]) + c.rng.NormFloat64()*c.augmentations.NoiseStdDev)
+		}
+	}
+}
+
+func clampLevel(v float64) uint8 {
+	return uint8(math.Max(0, math.Min(255, math.Round(v))))
+}
@@ -0,0 +1,104 @@
+package syntheticdata
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func uniformImage(w, h int, c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestCompose(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	gray := color.NRGBA{R: 100, G: 100, B: 100, A: 255}
+	background := uniformImage(200, 100, gray)
+
+	t.Run("without augmentations", func(t *testing.T) {
+		c := &compositor{
+			classes:       []objectClass{{label: "block", crops: []image.Image{uniformImage(20, 10, red)}}},
+			augmentations: Augmentations{MinScale: 1, MaxScale: 1},
+			minObjects:    3,
+			maxObjects:    3,
+			rng:           rand.New(rand.NewSource(1)),
+		}
+		img, boxes := c.compose(background)
+		test.That(t, img.Bounds(), test.ShouldResemble, background.Bounds())
+		test.That(t, boxes, test.ShouldHaveLength, 3)
+		for i, box := range boxes {
+			test.That(t, box.Label, test.ShouldEqual, "block")
+			test.That(t, box.XMax-box.XMin, test.ShouldEqual, 20)
+			test.That(t, box.YMax-box.YMin, test.ShouldEqual, 10)
+			test.That(t, img.Bounds().Intersect(box.rect()), test.ShouldResemble, box.rect())
+			test.That(t, img.NRGBAAt((box.XMin+box.XMax)/2, (box.YMin+box.YMax)/2), test.ShouldResemble, red)
+			// no overlap is allowed at all
+			for _, other := range boxes[:i] {
+				test.That(t, box.rect().Overlaps(other.rect()), test.ShouldBeFalse)
+			}
+		}
+		test.That(t, img.NRGBAAt(0, 0) == gray || img.NRGBAAt(0, 0) == red, test.ShouldBeTrue)
+	})
+
+	t.Run("with augmentations", func(t *testing.T) {
+		c := &compositor{
+			classes: []objectClass{
+				{label: "block", crops: []image.Image{uniformImage(20, 10, red)}},
+				{label: "ball", crops: []image.Image{uniformImage(8, 8, color.NRGBA{B: 255, A: 255})}},
+			},
+			augmentations: DefaultAugmentations,
+			minObjects:    1,
+			maxObjects:    4,
+			maxOverlap:    0.3,
+			rng:           rand.New(rand.NewSource(2)),
+		}
+		labels := map[string]bool{}
+		for i := 0; i < 20; i++ {
+			img, boxes := c.compose(background)
+			test.That(t, len(boxes), test.ShouldBeBetweenOrEqual, 1, 4)
+			for j, box := range boxes {
+				labels[box.Label] = true
+				test.That(t, img.Bounds().Intersect(box.rect()), test.ShouldResemble, box.rect())
+				for _, other := range boxes[:j] {
+					test.That(t, intersectionOverUnion(box.rect(), other.rect()), test.ShouldBeLessThanOrEqualTo, 0.3)
+				}
+			}
+		}
+		test.That(t, labels, test.ShouldResemble, map[string]bool{"block": true, "ball": true})
+	})
+
+	t.Run("objects too big for the background are left out", func(t *testing.T) {
+		c := &compositor{
+			classes:       []objectClass{{label: "wall", crops: []image.Image{uniformImage(300, 10, red)}}},
+			augmentations: Augmentations{MinScale: 1, MaxScale: 1},
+			minObjects:    1,
+			maxObjects:    1,
+			rng:           rand.New(rand.NewSource(3)),
+		}
+		_, boxes := c.compose(background)
+		test.That(t, boxes, test.ShouldBeEmpty)
+	})
+}
+
+func TestAugmentRotation(t *testing.T) {
+	c := &compositor{
+		augmentations: Augmentations{MinScale: 2, MaxScale: 2, MaxRotationDeg: 45},
+		rng:           rand.New(rand.NewSource(4)),
+	}
+	object, visible := c.augment(uniformImage(20, 20, color.NRGBA{G: 255, A: 255}))
+	// rotated, the doubled square takes up more room than its side, and leaves its corners transparent
+	test.That(t, object.Bounds().Dx(), test.ShouldBeGreaterThan, 40)
+	test.That(t, visible.Dx(), test.ShouldBeGreaterThan, 40)
+	test.That(t, object.NRGBAAt(0, 0).A, test.ShouldEqual, 0)
+	center := object.NRGBAAt(object.Bounds().Dx()/2, object.Bounds().Dy()/2)
+	test.That(t, center, test.ShouldResemble, color.NRGBA{G: 255, A: 255})
+}
@@ -0,0 +1,370 @@
+// Package syntheticdata implements a generic service which renders labeled synthetic images for bootstrapping the
+// training of detectors, by compositing crops of objects over captured backgrounds with random augmentations. Each
+// image is written as a JPEG next to a JSON file of the same name holding its bounding boxes, in a directory the data
+// manager syncs.
+//
+// Crops are JPEG or PNG files in a directory per label, and PNGs with transparency are composited along their
+// outline rather than as rectangles. Backgrounds are JPEG or PNG files in a directory, or are captured from a camera.
+//
+// Images are generated with DoCommand:
+//
+//	{"command": "generate", "count": 100}
+package syntheticdata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/jpeg"
+	// for decoding PNG crops and backgrounds.
+	_ "image/png"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+)
+
+// Model is the model of the synthetic data service.
+var Model = resource.DefaultModelFamily.WithModel("synthetic_data")
+
+const (
+	defaultMinObjects  = 1
+	defaultMaxObjects  = 3
+	defaultMaxOverlap  = 0.3
+	defaultJPEGQuality = 90
+	maxCount           = 1000
+)
+
+// defaultOutputDir is in the capture directory of the data manager, so images are synced without further
+// configuration.
+var defaultOutputDir = filepath.Join(os.Getenv("HOME"), ".viam", "capture", "synthetic")
+
+func init() {
+	resource.RegisterService(generic.API, Model, resource.Registration[resource.Resource, *Config]{
+		Constructor: newGenerator,
+	})
+}
+
+// An ObjectClass is a label and the directory of crops of objects which have it.
+type ObjectClass struct {
+	Label    string `json:"label"`
+	CropsDir string `json:"crops_dir"`
+}
+
+// Config describes how to configure the service.
+type Config struct {
+	// BackgroundsDir holds the images objects are composited over. If it is not set, a new image is captured from
+	// Camera for each one.
+	BackgroundsDir string        `json:"backgrounds_dir,omitempty"`
+	Camera         string        `json:"camera,omitempty"`
+	Objects        []ObjectClass `json:"objects"`
+	// OutputDir is where images are written, in a directory per service. It defaults to a directory the data manager
+	// syncs.
+	OutputDir string `json:"output_dir,omitempty"`
+	// MinObjectsPerImage and MaxObjectsPerImage bound how many objects are drawn in each image, defaulting to 1 and 3.
+	MinObjectsPerImage int `json:"min_objects_per_image,omitempty"`
+	MaxObjectsPerImage int `json:"max_objects_per_image,omitempty"`
+	// MaxOverlap is the most the bounding box of an object may overlap any other, as intersection over union,
+	// defaulting to 0.3.
+	MaxOverlap *float64 `json:"max_overlap,omitempty"`
+	// Augmentations default to DefaultAugmentations.
+	Augmentations *Augmentations `json:"augmentations,omitempty"`
+	JPEGQuality   int            `json:"jpeg_quality,omitempty"`
+	// Seed makes the images the same from run to run.
+	Seed *int64 `json:"seed,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	var deps []string
+	switch {
+	case conf.BackgroundsDir != "" && conf.Camera != "":
+		return nil, resource.NewConfigValidationError(path, errors.New("only one of backgrounds_dir and camera may be set"))
+	case conf.BackgroundsDir == "" && conf.Camera == "":
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "backgrounds_dir")
+	case conf.Camera != "":
+		deps = append(deps, conf.Camera)
+	}
+	if len(conf.Objects) == 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "objects")
+	}
+	for i, object := range conf.Objects {
+		objectPath := fmt.Sprintf("%s.objects.%d", path, i)
+		if object.Label == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(objectPath, "label")
+		}
+		if object.CropsDir == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(objectPath, "crops_dir")
+		}
+	}
+	if conf.MinObjectsPerImage < 0 || conf.MaxObjectsPerImage < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("objects per image cannot be negative"))
+	}
+	if conf.MaxObjectsPerImage != 0 && conf.MinObjectsPerImage > conf.MaxObjectsPerImage {
+		return nil, resource.NewConfigValidationError(path,
+			errors.New("min_objects_per_image cannot be above max_objects_per_image"))
+	}
+	if conf.MaxOverlap != nil && (*conf.MaxOverlap < 0 || *conf.MaxOverlap > 1) {
+		return nil, resource.NewConfigValidationError(path, errors.New("max_overlap must be between 0 and 1"))
+	}
+	if aug := conf.Augmentations; aug != nil {
+		if aug.MinScale < 0 || aug.MaxScale < aug.MinScale {
+			return nil, resource.NewConfigValidationError(path,
+				errors.New("augmentations must have 0 <= min_scale <= max_scale"))
+		}
+		if aug.MaxRotationDeg < 0 || aug.Brightness < 0 || aug.Contrast < 0 || aug.NoiseStdDev < 0 {
+			return nil, resource.NewConfigValidationError(path, errors.New("augmentations cannot be negative"))
+		}
+	}
+	if conf.JPEGQuality < 0 || conf.JPEGQuality > 100 {
+		return nil, resource.NewConfigValidationError(path, errors.New("jpeg_quality must be between 1 and 100"))
+	}
+	return deps, nil
+}
+
+// An Annotation is the JSON file written next to each image.
+type Annotation struct {
+	Image         string        `json:"image"`
+	Width         int           `json:"width"`
+	Height        int           `json:"height"`
+	BoundingBoxes []BoundingBox `json:"bounding_boxes"`
+}
+
+type generator struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+
+	logger      logging.Logger
+	camera      camera.Camera
+	backgrounds []image.Image
+	outputDir   string
+	jpegQuality int
+
+	mu         sync.Mutex
+	compositor *compositor
+}
+
+func newGenerator(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (resource.Resource, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{
+		Named:       conf.ResourceName().AsNamed(),
+		logger:      logger,
+		outputDir:   filepath.Join(defaultOutputDir, conf.ResourceName().ShortName()),
+		jpegQuality: defaultJPEGQuality,
+	}
+	if newConf.OutputDir != "" {
+		g.outputDir = filepath.Join(newConf.OutputDir, conf.ResourceName().ShortName())
+	}
+	if newConf.JPEGQuality != 0 {
+		g.jpegQuality = newConf.JPEGQuality
+	}
+	if newConf.Camera != "" {
+		if g.camera, err = camera.FromDependencies(deps, newConf.Camera); err != nil {
+			return nil, err
+		}
+	} else if g.backgrounds, err = loadImages(newConf.BackgroundsDir); err != nil {
+		return nil, errors.Wrap(err, "failed to load the backgrounds")
+	}
+
+	seed := time.Now().UnixNano()
+	if newConf.Seed != nil {
+		seed = *newConf.Seed
+	}
+	c := &compositor{
+		augmentations: DefaultAugmentations,
+		minObjects:    defaultMinObjects,
+		maxObjects:    defaultMaxObjects,
+		maxOverlap:    defaultMaxOverlap,
+		//nolint:gosec
+		rng: rand.New(rand.NewSource(seed)),
+	}
+	if newConf.Augmentations != nil {
+		c.augmentations = *newConf.Augmentations
+		if c.augmentations.MaxScale == 0 {
+			c.augmentations.MinScale, c.augmentations.MaxScale = 1, 1
+		}
+	}
+	if newConf.MinObjectsPerImage != 0 {
+		c.minObjects = newConf.MinObjectsPerImage
+	}
+	if newConf.MaxObjectsPerImage != 0 {
+		c.maxObjects = newConf.MaxObjectsPerImage
+	}
+	if c.maxObjects < c.minObjects {
+		c.maxObjects = c.minObjects
+	}
+	if newConf.MaxOverlap != nil {
+		c.maxOverlap = *newConf.MaxOverlap
+	}
+	for _, object := range newConf.Objects {
+		crops, err := loadImages(object.CropsDir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load the crops of %q", object.Label)
+		}
+		c.classes = append(c.classes, objectClass{label: object.Label, crops: crops})
+	}
+	g.compositor = c
+	return g, nil
+}
+
+// loadImages decodes every JPEG and PNG file in the directory, in the order of their names.
+func loadImages(dir string) ([]image.Image, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".jpg", ".jpeg", ".png":
+			if !entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, errors.Errorf("no JPEG or PNG images in %q", dir)
+	}
+	sort.Strings(names)
+	images := make([]image.Image, 0, len(names))
+	for _, name := range names {
+		img, err := decodeImageFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, img)
+	}
+	return images, nil
+}
+
+func decodeImageFile(path string) (image.Image, error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %q", path)
+	}
+	return img, nil
+}
+
+// DoCommand generates images.
+func (g *generator) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, _ := cmd["command"].(string)
+	if name != "generate" {
+		return nil, errors.Errorf("unknown command %q", name)
+	}
+	count := 1
+	if c, ok := cmd["count"].(float64); ok {
+		count = int(c)
+	}
+	if count < 1 || count > maxCount {
+		return nil, errors.Errorf("count must be between 1 and %d", maxCount)
+	}
+	if err := os.MkdirAll(g.outputDir, 0o700); err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	objects := map[string]interface{}{}
+	for i := 0; i < count; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		boxes, err := g.generate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, box := range boxes {
+			n, _ := objects[box.Label].(int)
+			objects[box.Label] = n + 1
+		}
+	}
+	g.logger.Infow("generated synthetic images", "count", count, "output_dir", g.outputDir)
+	return map[string]interface{}{"images": count, "objects": objects, "output_dir": g.outputDir}, nil
+}
+
+// generate composites one image and writes it and its annotation.
+func (g *generator) generate(ctx context.Context) ([]BoundingBox, error) {
+	var background image.Image
+	if g.camera != nil {
+		img, release, err := camera.ReadImage(ctx, g.camera)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot read a background from the camera")
+		}
+		defer release()
+		background = img
+	} else {
+		background = g.backgrounds[g.compositor.rng.Intn(len(g.backgrounds))]
+	}
+	img, boxes := g.compositor.compose(background)
+	if boxes == nil {
+		boxes = []BoundingBox{}
+	}
+
+	base := fmt.Sprintf("%s_%s", g.Name().ShortName(), time.Now().UTC().Format("2006-01-02T15_04_05.000000Z"))
+	imagePath := filepath.Join(g.outputDir, base+".jpg")
+	err := writeFile(imagePath, func(f *os.File) error {
+		return jpeg.Encode(f, img, &jpeg.Options{Quality: g.jpegQuality})
+	})
+	if err != nil {
+		return nil, err
+	}
+	annotation := Annotation{
+		Image:         filepath.Base(imagePath),
+		Width:         img.Bounds().Dx(),
+		Height:        img.Bounds().Dy(),
+		BoundingBoxes: boxes,
+	}
+	err = writeFile(filepath.Join(g.outputDir, base+".json"), func(f *os.File) error {
+		return json.NewEncoder(f).Encode(annotation)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return boxes, nil
+}
+
+// writeFile writes a file under a temporary name and then renames it, so that it is never synced half written.
+func writeFile(path string, write func(f *os.File) error) error {
+	//nolint:gosec
+	f, err := os.Create(path + ".part")
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		//nolint:errcheck
+		f.Close()
+		//nolint:errcheck
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(path+".part", path)
+}
@@ -0,0 +1,180 @@
+package syntheticdata
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/gostream"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestValidate(t *testing.T) {
+	objects := []ObjectClass{{Label: "cup", CropsDir: "/crops/cup"}}
+	negative := -0.1
+	for _, tc := range []struct {
+		conf  Config
+		field string
+		err   string
+		deps  []string
+	}{
+		{conf: Config{Objects: objects}, field: "backgrounds_dir"},
+		{conf: Config{BackgroundsDir: "/bg", Camera: "cam", Objects: objects}, err: "only one of"},
+		{conf: Config{BackgroundsDir: "/bg"}, field: "objects"},
+		{conf: Config{BackgroundsDir: "/bg", Objects: []ObjectClass{{CropsDir: "/crops"}}}, field: "label"},
+		{conf: Config{BackgroundsDir: "/bg", Objects: []ObjectClass{{Label: "cup"}}}, field: "crops_dir"},
+		{conf: Config{BackgroundsDir: "/bg", Objects: objects, MinObjectsPerImage: 4, MaxObjectsPerImage: 2}, err: "cannot be above"},
+		{conf: Config{BackgroundsDir: "/bg", Objects: objects, MaxOverlap: &negative}, err: "max_overlap"},
+		{conf: Config{BackgroundsDir: "/bg", Objects: objects, Augmentations: &Augmentations{MinScale: 2, MaxScale: 1}}, err: "min_scale"},
+		{conf: Config{BackgroundsDir: "/bg", Objects: objects, JPEGQuality: 101}, err: "jpeg_quality"},
+		{conf: Config{BackgroundsDir: "/bg", Objects: objects}},
+		{conf: Config{Camera: "cam", Objects: objects}, deps: []string{"cam"}},
+	} {
+		deps, err := tc.conf.Validate("path")
+		switch {
+		case tc.field != "":
+			test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, tc.field)
+		case tc.err != "":
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldContainSubstring, tc.err)
+		default:
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, deps, test.ShouldResemble, tc.deps)
+		}
+	}
+}
+
+func writeImage(t *testing.T, path string, img image.Image) {
+	t.Helper()
+	f, err := os.Create(path)
+	test.That(t, err, test.ShouldBeNil)
+	if strings.HasSuffix(path, ".png") {
+		test.That(t, png.Encode(f, img), test.ShouldBeNil)
+	} else {
+		test.That(t, jpeg.Encode(f, img, nil), test.ShouldBeNil)
+	}
+	test.That(t, f.Close(), test.ShouldBeNil)
+}
+
+func TestGenerate(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	backgrounds := filepath.Join(dir, "backgrounds")
+	crops := filepath.Join(dir, "crops")
+	test.That(t, os.Mkdir(backgrounds, 0o700), test.ShouldBeNil)
+	test.That(t, os.Mkdir(crops, 0o700), test.ShouldBeNil)
+	writeImage(t, filepath.Join(backgrounds, "floor.jpg"), uniformImage(160, 120, color.NRGBA{R: 90, G: 90, B: 90, A: 255}))
+	// a disc, transparent outside it
+	disc := image.NewNRGBA(image.Rect(0, 0, 30, 30))
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 30; x++ {
+			if (x-15)*(x-15)+(y-15)*(y-15) < 14*14 {
+				disc.SetNRGBA(x, y, color.NRGBA{R: 250, G: 200, A: 255})
+			}
+		}
+	}
+	writeImage(t, filepath.Join(crops, "disc.png"), disc)
+	// files other than images are ignored
+	test.That(t, os.WriteFile(filepath.Join(crops, "README.txt"), []byte("discs"), 0o600), test.ShouldBeNil)
+
+	seed := int64(7)
+	conf := resource.Config{
+		Name:  "bootstrap",
+		API:   generic.API,
+		Model: Model,
+		ConvertedAttributes: &Config{
+			BackgroundsDir: backgrounds,
+			Objects:        []ObjectClass{{Label: "disc", CropsDir: crops}},
+			OutputDir:      filepath.Join(dir, "out"),
+			Seed:           &seed,
+		},
+	}
+	g, err := newGenerator(ctx, nil, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	resp, err := g.DoCommand(ctx, map[string]interface{}{"command": "generate", "count": 3.})
+	test.That(t, err, test.ShouldBeNil)
+	outputDir := filepath.Join(dir, "out", "bootstrap")
+	test.That(t, resp["images"], test.ShouldEqual, 3)
+	test.That(t, resp["output_dir"], test.ShouldEqual, outputDir)
+
+	images, err := filepath.Glob(filepath.Join(outputDir, "*.jpg"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, images, test.ShouldHaveLength, 3)
+	annotations, err := filepath.Glob(filepath.Join(outputDir, "*.json"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, annotations, test.ShouldHaveLength, 3)
+	parts, err := filepath.Glob(filepath.Join(outputDir, "*.part"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, parts, test.ShouldBeEmpty)
+
+	total := 0
+	for _, path := range annotations {
+		data, err := os.ReadFile(path)
+		test.That(t, err, test.ShouldBeNil)
+		var annotation Annotation
+		test.That(t, json.Unmarshal(data, &annotation), test.ShouldBeNil)
+		test.That(t, annotation.Image, test.ShouldEqual, strings.TrimSuffix(filepath.Base(path), ".json")+".jpg")
+		test.That(t, annotation.Width, test.ShouldEqual, 160)
+		test.That(t, annotation.Height, test.ShouldEqual, 120)
+		total += len(annotation.BoundingBoxes)
+		for _, box := range annotation.BoundingBoxes {
+			test.That(t, box.Label, test.ShouldEqual, "disc")
+			test.That(t, box.XMin, test.ShouldBeGreaterThanOrEqualTo, 0)
+			test.That(t, box.XMax, test.ShouldBeLessThanOrEqualTo, 160)
+		}
+	}
+	test.That(t, resp["objects"], test.ShouldResemble, map[string]interface{}{"disc": total})
+
+	_, err = g.DoCommand(ctx, map[string]interface{}{"command": "generate", "count": 0.})
+	test.That(t, err, test.ShouldBeError, "count must be between 1 and 1000")
+	_, err = g.DoCommand(ctx, map[string]interface{}{"command": "train"})
+	test.That(t, err, test.ShouldBeError, `unknown command "train"`)
+
+	t.Run("backgrounds from a camera", func(t *testing.T) {
+		cam := inject.NewCamera("cam")
+		cam.StreamFunc = func(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error) {
+			return gostream.NewEmbeddedVideoStreamFromReader(gostream.VideoReaderFunc(func(ctx context.Context) (image.Image, func(), error) {
+				return uniformImage(64, 48, color.NRGBA{B: 200, A: 255}), func() {}, nil
+			})), nil
+		}
+		conf := conf
+		attrs := *conf.ConvertedAttributes.(*Config)
+		attrs.BackgroundsDir = ""
+		attrs.Camera = "cam"
+		conf.ConvertedAttributes = &attrs
+		g, err := newGenerator(ctx, resource.Dependencies{camera.Named("cam"): cam}, conf, logger)
+		test.That(t, err, test.ShouldBeNil)
+		resp, err := g.DoCommand(ctx, map[string]interface{}{"command": "generate"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp["images"], test.ShouldEqual, 1)
+		annotations, err := filepath.Glob(filepath.Join(outputDir, "*.json"))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, annotations, test.ShouldHaveLength, 4)
+	})
+
+	t.Run("directories without images", func(t *testing.T) {
+		conf := conf
+		attrs := *conf.ConvertedAttributes.(*Config)
+		attrs.Objects = []ObjectClass{{Label: "disc", CropsDir: outputDir + "/missing"}}
+		conf.ConvertedAttributes = &attrs
+		_, err := newGenerator(ctx, nil, conf, logger)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `failed to load the crops of "disc"`)
+	})
+}
@@ -0,0 +1,469 @@
+// Package thermalmanager implements a generic service which watches the temperatures of a machine, drives fans from
+// them along a curve, and throttles workloads while the machine runs hot. Temperatures come from sensors, such as one
+// on a motor, or from the thermal zones of the kernel, such as that of the CPU. Workloads are throttled with a
+// DoCommand to the resource running them, for example one which lowers the frame rate of a camera or the rate at which
+// a vision service runs its model, and restored with another once the machine has cooled.
+//
+// The thermal state is returned by DoCommand:
+//
+//	{"command": "thermal_status"}
+package thermalmanager
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/utils"
+)
+
+// Model is the model of the thermal manager service.
+var Model = resource.DefaultModelFamily.WithModel("thermal_manager")
+
+const (
+	defaultReading         = "temperature_celsius"
+	defaultHysteresisC     = 3.
+	defaultPollFrequencyHz = 1.
+)
+
+func init() {
+	resource.RegisterService(generic.API, Model, resource.Registration[resource.Resource, *Config]{
+		Constructor: newThermalManager,
+	})
+}
+
+// A TemperatureSource is a temperature the service watches, read from either a sensor or a thermal zone.
+type TemperatureSource struct {
+	Name string `json:"name"`
+	// Sensor is a sensor with a reading of the temperature in degrees Celsius, named by Reading which defaults to
+	// "temperature_celsius".
+	Sensor  string `json:"sensor,omitempty"`
+	Reading string `json:"reading,omitempty"`
+	// ThermalZone is a file holding a temperature in millidegrees Celsius, such as
+	// /sys/class/thermal/thermal_zone0/temp for the CPU.
+	ThermalZone string `json:"thermal_zone,omitempty"`
+}
+
+// A CurvePoint is the duty cycle, between 0 and 1, a fan runs at when its sources reach a temperature.
+type CurvePoint struct {
+	TemperatureC float64 `json:"temperature_c"`
+	DutyCycle    float64 `json:"duty_cycle"`
+}
+
+// A Fan is driven by PWM from a pin of a board.
+type Fan struct {
+	Board          string `json:"board"`
+	Pin            string `json:"pin"`
+	PWMFrequencyHz uint   `json:"pwm_frequency_hz,omitempty"`
+	// Sources are the temperatures the fan cools, by default all of them. The hottest drives the fan.
+	Sources []string `json:"sources,omitempty"`
+	// Curve gives the duty cycle at each temperature, interpolated between points. Below the coolest point the fan
+	// runs at its duty cycle and above the hottest at its.
+	Curve []CurvePoint `json:"curve"`
+}
+
+// A Throttle slows down a workload while any of its sources is above a temperature.
+type Throttle struct {
+	Resource string   `json:"resource"`
+	Sources  []string `json:"sources,omitempty"`
+	AboveC   float64  `json:"above_c"`
+	// Command is sent to the resource when it is throttled, and RestoreCommand once its sources are back below AboveC
+	// by the hysteresis.
+	Command        map[string]interface{} `json:"command"`
+	RestoreCommand map[string]interface{} `json:"restore_command,omitempty"`
+}
+
+// Config describes how to configure the service.
+type Config struct {
+	Sources   []TemperatureSource `json:"sources"`
+	Fans      []Fan               `json:"fans,omitempty"`
+	Throttles []Throttle          `json:"throttles,omitempty"`
+	// HysteresisC is how far below its temperature a throttle must cool before it is restored. Defaults to 3.
+	HysteresisC     float64 `json:"hysteresis_c,omitempty"`
+	PollFrequencyHz float64 `json:"poll_frequency_hz,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if len(conf.Sources) == 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "sources")
+	}
+	var deps []string
+	names := map[string]bool{}
+	for i, source := range conf.Sources {
+		sourcePath := fmt.Sprintf("%s.sources.%d", path, i)
+		if source.Name == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(sourcePath, "name")
+		}
+		if names[source.Name] {
+			return nil, resource.NewConfigValidationError(sourcePath, errors.Errorf("duplicate source %q", source.Name))
+		}
+		names[source.Name] = true
+		if (source.Sensor == "") == (source.ThermalZone == "") {
+			return nil, resource.NewConfigValidationError(sourcePath, errors.New("a source needs either a sensor or a thermal_zone"))
+		}
+		if source.Sensor != "" {
+			deps = append(deps, source.Sensor)
+		}
+	}
+	checkSources := func(path string, sources []string) error {
+		for _, name := range sources {
+			if !names[name] {
+				return resource.NewConfigValidationError(path, errors.Errorf("unknown source %q", name))
+			}
+		}
+		return nil
+	}
+	for i, fan := range conf.Fans {
+		fanPath := fmt.Sprintf("%s.fans.%d", path, i)
+		if fan.Board == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(fanPath, "board")
+		}
+		if fan.Pin == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(fanPath, "pin")
+		}
+		if len(fan.Curve) == 0 {
+			return nil, resource.NewConfigValidationFieldRequiredError(fanPath, "curve")
+		}
+		for _, point := range fan.Curve {
+			if point.DutyCycle < 0 || point.DutyCycle > 1 {
+				return nil, resource.NewConfigValidationError(fanPath, errors.New("duty_cycle must be between 0 and 1"))
+			}
+		}
+		if err := checkSources(fanPath, fan.Sources); err != nil {
+			return nil, err
+		}
+		deps = append(deps, fan.Board)
+	}
+	for i, throttle := range conf.Throttles {
+		throttlePath := fmt.Sprintf("%s.throttles.%d", path, i)
+		if throttle.Resource == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(throttlePath, "resource")
+		}
+		if throttle.Command == nil {
+			return nil, resource.NewConfigValidationFieldRequiredError(throttlePath, "command")
+		}
+		if err := checkSources(throttlePath, throttle.Sources); err != nil {
+			return nil, err
+		}
+		deps = append(deps, throttle.Resource)
+	}
+	if conf.HysteresisC < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("hysteresis_c cannot be negative"))
+	}
+	if conf.PollFrequencyHz < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("poll_frequency_hz cannot be negative"))
+	}
+	return deps, nil
+}
+
+// fanState is a fan and the duty cycle it was last set to.
+type fanState struct {
+	conf      Fan
+	name      string
+	pin       board.GPIOPin
+	dutyCycle float64
+	err       error
+}
+
+// throttleState is a throttle and whether it is engaged.
+type throttleState struct {
+	conf      Throttle
+	resource  resource.Resource
+	throttled bool
+	err       error
+}
+
+type thermalManager struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	logger      logging.Logger
+	sources     []TemperatureSource
+	sensors     map[string]sensor.Sensor
+	hysteresisC float64
+	workers     utils.StoppableWorkers
+
+	mu           sync.Mutex
+	temperatures map[string]float64
+	sourceErrors map[string]error
+	fans         []*fanState
+	throttles    []*throttleState
+}
+
+func newThermalManager(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (resource.Resource, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	tm := &thermalManager{
+		Named:        conf.ResourceName().AsNamed(),
+		logger:       logger,
+		sources:      newConf.Sources,
+		sensors:      map[string]sensor.Sensor{},
+		hysteresisC:  newConf.HysteresisC,
+		temperatures: map[string]float64{},
+		sourceErrors: map[string]error{},
+	}
+	if tm.hysteresisC == 0 {
+		tm.hysteresisC = defaultHysteresisC
+	}
+	for _, source := range newConf.Sources {
+		if source.Sensor == "" {
+			continue
+		}
+		if tm.sensors[source.Name], err = sensor.FromDependencies(deps, source.Sensor); err != nil {
+			return nil, err
+		}
+	}
+	for _, fan := range newConf.Fans {
+		b, err := board.FromDependencies(deps, fan.Board)
+		if err != nil {
+			return nil, err
+		}
+		pin, err := b.GPIOPinByName(fan.Pin)
+		if err != nil {
+			return nil, err
+		}
+		if fan.PWMFrequencyHz != 0 {
+			if err := pin.SetPWMFreq(ctx, fan.PWMFrequencyHz, nil); err != nil {
+				return nil, err
+			}
+		}
+		curve := append([]CurvePoint{}, fan.Curve...)
+		sort.Slice(curve, func(i, j int) bool { return curve[i].TemperatureC < curve[j].TemperatureC })
+		fan.Curve = curve
+		tm.fans = append(tm.fans, &fanState{conf: fan, name: fan.Board + "/" + fan.Pin, pin: pin, dutyCycle: -1})
+	}
+	for _, throttle := range newConf.Throttles {
+		res, err := dependencyByName(deps, throttle.Resource)
+		if err != nil {
+			return nil, err
+		}
+		tm.throttles = append(tm.throttles, &throttleState{conf: throttle, resource: res})
+	}
+	pollFrequencyHz := newConf.PollFrequencyHz
+	if pollFrequencyHz == 0 {
+		pollFrequencyHz = defaultPollFrequencyHz
+	}
+
+	tm.poll(ctx)
+	period := time.Duration(float64(time.Second) / pollFrequencyHz)
+	tm.workers = utils.NewStoppableWorkers(func(ctx context.Context) {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			tm.poll(ctx)
+		}
+	})
+	return tm, nil
+}
+
+// dependencyByName returns the dependency with the given name, whatever its API.
+func dependencyByName(deps resource.Dependencies, name string) (resource.Resource, error) {
+	for depName, res := range deps {
+		if depName.ShortName() == name {
+			return res, nil
+		}
+	}
+	return nil, errors.Errorf("resource %q not found in dependencies", name)
+}
+
+// readTemperature reads a temperature source in degrees Celsius.
+func (tm *thermalManager) readTemperature(ctx context.Context, source TemperatureSource) (float64, error) {
+	if source.ThermalZone != "" {
+		//nolint:gosec
+		data, err := os.ReadFile(source.ThermalZone)
+		if err != nil {
+			return 0, err
+		}
+		milliC, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "cannot parse thermal zone %q", source.ThermalZone)
+		}
+		return milliC / 1000, nil
+	}
+	readings, err := tm.sensors[source.Name].Readings(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	reading := source.Reading
+	if reading == "" {
+		reading = defaultReading
+	}
+	temperature, ok := readings[reading].(float64)
+	if !ok {
+		return 0, errors.Errorf("sensor %q has no %q reading", source.Sensor, reading)
+	}
+	return temperature, nil
+}
+
+// hottest returns the highest of the temperatures of the given sources, or of all sources if none are given. A source
+// which cannot be read counts as infinitely hot, so the fans run flat out and workloads are throttled.
+func hottest(temperatures map[string]float64, sources []string) float64 {
+	hottest := math.Inf(-1)
+	if len(sources) == 0 {
+		for _, temperature := range temperatures {
+			hottest = math.Max(hottest, temperature)
+		}
+		return hottest
+	}
+	for _, name := range sources {
+		hottest = math.Max(hottest, temperatures[name])
+	}
+	return hottest
+}
+
+// dutyCycle returns the duty cycle a curve gives for a temperature.
+func dutyCycle(curve []CurvePoint, temperature float64) float64 {
+	if temperature <= curve[0].TemperatureC {
+		return curve[0].DutyCycle
+	}
+	for i := 1; i < len(curve); i++ {
+		if temperature > curve[i].TemperatureC {
+			continue
+		}
+		lo, hi := curve[i-1], curve[i]
+		return lo.DutyCycle + (hi.DutyCycle-lo.DutyCycle)*(temperature-lo.TemperatureC)/(hi.TemperatureC-lo.TemperatureC)
+	}
+	return curve[len(curve)-1].DutyCycle
+}
+
+// poll reads the temperatures, then sets the fans and throttles workloads according to them.
+func (tm *thermalManager) poll(ctx context.Context) {
+	temperatures := make(map[string]float64, len(tm.sources))
+	sourceErrors := map[string]error{}
+	for _, source := range tm.sources {
+		temperature, err := tm.readTemperature(ctx, source)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			sourceErrors[source.Name] = err
+			temperature = math.Inf(1)
+		}
+		temperatures[source.Name] = temperature
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	for name, err := range sourceErrors {
+		if tm.sourceErrors[name] == nil {
+			tm.logger.CWarnw(ctx, "cannot read temperature, treating it as too hot", "source", name, "error", err)
+		}
+	}
+	tm.temperatures = temperatures
+	tm.sourceErrors = sourceErrors
+
+	for _, fan := range tm.fans {
+		duty := dutyCycle(fan.conf.Curve, hottest(temperatures, fan.conf.Sources))
+		if duty == fan.dutyCycle && fan.err == nil {
+			continue
+		}
+		if fan.err = fan.pin.SetPWM(ctx, duty, nil); fan.err != nil {
+			tm.logger.CErrorw(ctx, "cannot set fan speed", "fan", fan.name, "error", fan.err)
+			continue
+		}
+		fan.dutyCycle = duty
+	}
+
+	for _, throttle := range tm.throttles {
+		temperature := hottest(temperatures, throttle.conf.Sources)
+		switch {
+		case !throttle.throttled && temperature > throttle.conf.AboveC:
+			tm.logger.CWarnw(ctx, "throttling workload while the machine is hot",
+				"resource", throttle.conf.Resource, "temperature_c", temperature, "above_c", throttle.conf.AboveC)
+			_, throttle.err = throttle.resource.DoCommand(ctx, throttle.conf.Command)
+			throttle.throttled = throttle.err == nil
+		case throttle.throttled && temperature < throttle.conf.AboveC-tm.hysteresisC:
+			tm.logger.CInfow(ctx, "restoring throttled workload", "resource", throttle.conf.Resource, "temperature_c", temperature)
+			throttle.err = tm.restore(ctx, throttle)
+			throttle.throttled = throttle.err != nil
+		default:
+			continue
+		}
+		if throttle.err != nil {
+			tm.logger.CErrorw(ctx, "cannot throttle workload", "resource", throttle.conf.Resource, "error", throttle.err)
+		}
+	}
+}
+
+// restore sends the restore command of a throttle, if it has one.
+func (tm *thermalManager) restore(ctx context.Context, throttle *throttleState) error {
+	if throttle.conf.RestoreCommand == nil {
+		return nil
+	}
+	_, err := throttle.resource.DoCommand(ctx, throttle.conf.RestoreCommand)
+	return err
+}
+
+// DoCommand returns the temperatures, fan speeds and throttled workloads for "thermal_status".
+func (tm *thermalManager) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if cmd["command"] != "thermal_status" {
+		return nil, resource.ErrDoUnimplemented
+	}
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	temperatures := map[string]interface{}{}
+	for name, temperature := range tm.temperatures {
+		if err := tm.sourceErrors[name]; err != nil {
+			temperatures[name] = map[string]interface{}{"error": err.Error()}
+			continue
+		}
+		temperatures[name] = temperature
+	}
+	fans := map[string]interface{}{}
+	for _, fan := range tm.fans {
+		status := map[string]interface{}{"duty_cycle": fan.dutyCycle}
+		if fan.err != nil {
+			status["error"] = fan.err.Error()
+		}
+		fans[fan.name] = status
+	}
+	throttled := []interface{}{}
+	for _, throttle := range tm.throttles {
+		if throttle.throttled {
+			throttled = append(throttled, throttle.conf.Resource)
+		}
+	}
+	return map[string]interface{}{"temperatures_c": temperatures, "fans": fans, "throttled": throttled}, nil
+}
+
+// Close stops watching the temperatures and restores any throttled workloads. The fans are left running as they are.
+func (tm *thermalManager) Close(ctx context.Context) error {
+	tm.workers.Stop()
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	var err error
+	for _, throttle := range tm.throttles {
+		if throttle.throttled {
+			err = multierr.Combine(err, tm.restore(ctx, throttle))
+			throttle.throttled = false
+		}
+	}
+	return err
+}
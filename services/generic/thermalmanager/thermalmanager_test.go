@@ -0,0 +1,196 @@
+package thermalmanager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestValidate(t *testing.T) {
+	conf := &Config{
+		Sources: []TemperatureSource{
+			{Name: "motor", Sensor: "motor_temp"},
+			{Name: "cpu", ThermalZone: "/sys/class/thermal/thermal_zone0/temp"},
+		},
+		Fans:      []Fan{{Board: "pi", Pin: "12", Sources: []string{"cpu"}, Curve: []CurvePoint{{40, 0.2}, {70, 1}}}},
+		Throttles: []Throttle{{Resource: "camera", AboveC: 80, Command: map[string]interface{}{"fps": 5}}},
+	}
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"motor_temp", "pi", "camera"})
+
+	_, err = (&Config{}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&Config{Sources: []TemperatureSource{{Name: "cpu"}}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.Fans[0].Sources = []string{"gpu"}
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	conf.Fans[0].Sources = nil
+	conf.Fans[0].Curve[1].DutyCycle = 100
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestDutyCycle(t *testing.T) {
+	curve := []CurvePoint{{40, 0.2}, {60, 0.6}, {70, 1}}
+	test.That(t, dutyCycle(curve, 20), test.ShouldEqual, 0.2)
+	test.That(t, dutyCycle(curve, 50), test.ShouldAlmostEqual, 0.4)
+	test.That(t, dutyCycle(curve, 65), test.ShouldAlmostEqual, 0.8)
+	test.That(t, dutyCycle(curve, 90), test.ShouldEqual, 1.)
+}
+
+func TestThermalManager(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	zone := filepath.Join(t.TempDir(), "temp")
+	setCPU := func(milliC string) {
+		// the zone is replaced whole, as the kernel does, so it is never read half written
+		test.That(t, os.WriteFile(zone+".tmp", []byte(milliC+"\n"), 0o600), test.ShouldBeNil)
+		test.That(t, os.Rename(zone+".tmp", zone), test.ShouldBeNil)
+	}
+	setCPU("45000")
+
+	var mu sync.Mutex
+	motorC := 30.
+	var motorErr error
+	motorTemp := inject.NewSensor("motor_temp")
+	motorTemp.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return map[string]interface{}{"temperature_celsius": motorC}, motorErr
+	}
+	duty := -1.
+	pin := &inject.GPIOPin{}
+	pin.SetPWMFunc = func(ctx context.Context, dutyCyclePct float64, extra map[string]interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		duty = dutyCyclePct
+		return nil
+	}
+	pi := inject.NewBoard("pi")
+	pi.GPIOPinByNameFunc = func(name string) (board.GPIOPin, error) {
+		return pin, nil
+	}
+	var commands []map[string]interface{}
+	cam := inject.NewGenericComponent("camera")
+	cam.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		commands = append(commands, cmd)
+		return nil, nil
+	}
+	state := func() (float64, int) {
+		mu.Lock()
+		defer mu.Unlock()
+		return duty, len(commands)
+	}
+
+	conf := &Config{
+		Sources: []TemperatureSource{
+			{Name: "motor", Sensor: "motor_temp"},
+			{Name: "cpu", ThermalZone: zone},
+		},
+		Fans: []Fan{{Board: "pi", Pin: "12", Curve: []CurvePoint{{70, 1}, {40, 0.2}}}},
+		Throttles: []Throttle{{
+			Resource:       "camera",
+			Sources:        []string{"cpu"},
+			AboveC:         80,
+			Command:        map[string]interface{}{"command": "set_fps", "fps": 5.},
+			RestoreCommand: map[string]interface{}{"command": "set_fps", "fps": 30.},
+		}},
+		PollFrequencyHz: 200,
+	}
+	deps := resource.Dependencies{motorTemp.Name(): motorTemp, pi.Name(): pi, cam.Name(): cam}
+	res, err := newThermalManager(ctx, deps, resource.Config{
+		Name:                "thermal",
+		API:                 generic.API,
+		Model:               Model,
+		ConvertedAttributes: conf,
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	// the fan follows the hottest source
+	fanDuty, throttles := state()
+	test.That(t, fanDuty, test.ShouldAlmostEqual, 0.2+0.8*5/30)
+	test.That(t, throttles, test.ShouldEqual, 0)
+	mu.Lock()
+	motorC = 55
+	mu.Unlock()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		fanDuty, _ := state()
+		test.That(tb, fanDuty, test.ShouldAlmostEqual, 0.6)
+	})
+
+	// a hot CPU throttles the camera until it is back below the limit by the hysteresis
+	setCPU("85000")
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		fanDuty, throttles := state()
+		test.That(tb, fanDuty, test.ShouldEqual, 1.)
+		test.That(tb, throttles, test.ShouldEqual, 1)
+	})
+	status, err := res.DoCommand(ctx, map[string]interface{}{"command": "thermal_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["throttled"], test.ShouldResemble, []interface{}{"camera"})
+	test.That(t, status["temperatures_c"].(map[string]interface{})["cpu"], test.ShouldEqual, 85.)
+
+	setCPU("78000")
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		status, err := res.DoCommand(ctx, map[string]interface{}{"command": "thermal_status"})
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, status["temperatures_c"].(map[string]interface{})["cpu"], test.ShouldEqual, 78.)
+	})
+	_, throttles = state()
+	test.That(t, throttles, test.ShouldEqual, 1)
+	setCPU("60000")
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		_, throttles := state()
+		test.That(tb, throttles, test.ShouldEqual, 2)
+	})
+	mu.Lock()
+	test.That(t, commands[1], test.ShouldResemble, conf.Throttles[0].RestoreCommand)
+	mu.Unlock()
+
+	// a sensor which cannot be read runs the fan flat out
+	mu.Lock()
+	motorErr = errors.New("i2c bus error")
+	mu.Unlock()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		fanDuty, _ := state()
+		test.That(tb, fanDuty, test.ShouldEqual, 1.)
+	})
+	status, err = res.DoCommand(ctx, map[string]interface{}{"command": "thermal_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["temperatures_c"].(map[string]interface{})["motor"], test.ShouldResemble,
+		map[string]interface{}{"error": "i2c bus error"})
+
+	// closing restores a throttled workload
+	setCPU("90000")
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		_, throttles := state()
+		test.That(tb, throttles, test.ShouldEqual, 3)
+	})
+	test.That(t, res.Close(ctx), test.ShouldBeNil)
+	_, throttles = state()
+	test.That(t, throttles, test.ShouldEqual, 4)
+}
@@ -0,0 +1,65 @@
+// Package onnx runs ONNX model files, such as the ones exported from PyTorch, with ONNX Runtime, as an
+// implementation of the ML model service. Models run on the CPU, or on an NVIDIA GPU with the "cuda" execution
+// provider. Their metadata comes from the inputs and outputs of their graphs, and inferences take and return the
+// same tensors as the tflite_cpu model's, keyed by the names of the graph's inputs and outputs.
+//
+// The implementation needs the ONNX Runtime shared library, and is only built with the onnxruntime build tag.
+package onnx
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/mlmodel"
+)
+
+const (
+	// ExecutionProviderCPU runs models on the CPU.
+	ExecutionProviderCPU = "cpu"
+	// ExecutionProviderCUDA runs models on an NVIDIA GPU.
+	ExecutionProviderCUDA = "cuda"
+)
+
+// Config contains the parameters specific to an onnx implementation of the MLMS (machine learning model service).
+type Config struct {
+	ModelPath string `json:"model_path"`
+	LabelPath string `json:"label_path,omitempty"`
+	// ExecutionProvider is where the model runs, either "cpu", which is the default, or "cuda".
+	ExecutionProvider string `json:"execution_provider,omitempty"`
+	// DeviceID is the GPU the cuda execution provider runs the model on.
+	DeviceID   int `json:"device_id,omitempty"`
+	NumThreads int `json:"num_threads,omitempty"`
+	// LibraryPath is the path to the ONNX Runtime shared library, which is found on the library path by default. All
+	// models share the library loaded by the first of them.
+	LibraryPath string `json:"library_path,omitempty"`
+	// Scheduler batches concurrent inferences and runs them in more than one session of the model.
+	Scheduler mlmodel.SchedulerConfig `json:"scheduler,omitempty"`
+}
+
+// Validate will check if the config is valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.ModelPath == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "model_path")
+	}
+	switch conf.ExecutionProvider {
+	case "", ExecutionProviderCPU, ExecutionProviderCUDA:
+	default:
+		return nil, resource.NewConfigValidationError(path, errors.Errorf(
+			"unknown execution_provider %q, must be %q or %q", conf.ExecutionProvider, ExecutionProviderCPU, ExecutionProviderCUDA))
+	}
+	if conf.DeviceID < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("device_id cannot be negative"))
+	}
+	if conf.DeviceID != 0 && conf.ExecutionProvider != ExecutionProviderCUDA {
+		return nil, resource.NewConfigValidationError(path, errors.New("device_id is only used by the cuda execution_provider"))
+	}
+	if conf.NumThreads < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("num_threads cannot be negative"))
+	}
+	if err := conf.Scheduler.Validate(fmt.Sprintf("%s.%s", path, "scheduler")); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
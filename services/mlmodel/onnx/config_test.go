@@ -0,0 +1,37 @@
+package onnx
+
+import (
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+)
+
+func TestValidate(t *testing.T) {
+	conf := &Config{}
+	_, err := conf.Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "model_path")
+
+	conf.ModelPath = "model.onnx"
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	conf.ExecutionProvider = "tensorrt"
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "unknown execution_provider")
+
+	conf.ExecutionProvider = ExecutionProviderCPU
+	conf.DeviceID = 1
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.ExecutionProvider = ExecutionProviderCUDA
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	conf.NumThreads = -1
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,362 @@
+//go:build onnxruntime && !no_cgo
+
+package onnx
+
+import (
+	"context"
+	fp "path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	ort "github.com/yalue/onnxruntime_go"
+	"go.opencensus.io/trace"
+	"gorgonia.org/tensor"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/ml"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/mlmodel"
+)
+
+var sModel = resource.DefaultModelFamily.WithModel("onnx")
+
+func init() {
+	resource.RegisterService(mlmodel.API, sModel, resource.Registration[mlmodel.Service, *Config]{
+		Constructor: func(
+			ctx context.Context,
+			_ resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (mlmodel.Service, error) {
+			svcConf, err := resource.NativeConfig[*Config](conf)
+			if err != nil {
+				return nil, err
+			}
+			if svcConf.Scheduler.Enabled() {
+				return mlmodel.NewScheduledService(ctx, svcConf.Scheduler, func(ctx context.Context) (mlmodel.Service, error) {
+					return NewONNXModel(ctx, svcConf, conf.ResourceName(), logger)
+				}, logger)
+			}
+			return NewONNXModel(ctx, svcConf, conf.ResourceName(), logger)
+		},
+	})
+}
+
+// environmentMu guards the ONNX Runtime environment, which all models share.
+var environmentMu sync.Mutex
+
+// initEnvironment loads the ONNX Runtime shared library, unless a model already has.
+func initEnvironment(libraryPath string) error {
+	environmentMu.Lock()
+	defer environmentMu.Unlock()
+	if ort.IsInitialized() {
+		return nil
+	}
+	if libraryPath != "" {
+		ort.SetSharedLibraryPath(libraryPath)
+	}
+	return ort.InitializeEnvironment()
+}
+
+// Model is a struct that implements the ONNX Runtime implementation of the MLMS.
+// It includes the session running the model, its inputs, and the metadata derived from its graph.
+type Model struct {
+	resource.Named
+	resource.AlwaysRebuild
+	session  *ort.DynamicAdvancedSession
+	inputs   []ort.InputOutputInfo
+	outputs  []ort.InputOutputInfo
+	metadata mlmodel.MLMetadata
+	logger   logging.Logger
+}
+
+// NewONNXModel is a constructor that builds an onnx implementation of the MLMS.
+func NewONNXModel(ctx context.Context, params *Config, name resource.Name, logger logging.Logger) (mlmodel.Service, error) {
+	_, span := trace.StartSpan(ctx, "service::mlmodel::NewONNXModel")
+	defer span.End()
+	if params == nil {
+		return nil, errors.New("could not find parameters")
+	}
+	modelPath := params.ModelPath
+	if fullpath, err := fp.Abs(modelPath); err == nil {
+		modelPath = fullpath
+	}
+	if err := initEnvironment(params.LibraryPath); err != nil {
+		return nil, errors.Wrap(err, "could not load onnxruntime")
+	}
+
+	inputs, outputs, err := ort.GetInputOutputInfo(modelPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the inputs and outputs of the model at %s", modelPath)
+	}
+	options, err := ort.NewSessionOptions()
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer options.Destroy()
+	if params.NumThreads > 0 {
+		if err := options.SetIntraOpNumThreads(params.NumThreads); err != nil {
+			return nil, err
+		}
+	}
+	if params.ExecutionProvider == ExecutionProviderCUDA {
+		if err := appendCUDA(options, params.DeviceID); err != nil {
+			return nil, errors.Wrap(err, "could not use the cuda execution provider")
+		}
+	}
+	session, err := ort.NewDynamicAdvancedSession(modelPath, names(inputs), names(outputs), options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not load the model at %s", modelPath)
+	}
+
+	m := &Model{
+		Named:   name.AsNamed(),
+		session: session,
+		inputs:  inputs,
+		outputs: outputs,
+		logger:  logger,
+	}
+	m.metadata = m.graphMetadata(modelPath, params.LabelPath)
+	return m, nil
+}
+
+func appendCUDA(options *ort.SessionOptions, deviceID int) error {
+	cudaOptions, err := ort.NewCUDAProviderOptions()
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer cudaOptions.Destroy()
+	if err := cudaOptions.Update(map[string]string{"device_id": strconv.Itoa(deviceID)}); err != nil {
+		return err
+	}
+	return options.AppendExecutionProviderCUDA(cudaOptions)
+}
+
+func names(infos []ort.InputOutputInfo) []string {
+	out := make([]string, 0, len(infos))
+	for _, info := range infos {
+		out = append(out, info.Name)
+	}
+	return out
+}
+
+// graphMetadata fills in the metadata of the model from the inputs and outputs of its graph, and its name and
+// description from the model's own metadata, if it has them.
+func (m *Model) graphMetadata(modelPath, labelPath string) mlmodel.MLMetadata {
+	var out mlmodel.MLMetadata
+	if md, err := ort.GetModelMetadata(modelPath); err != nil {
+		m.logger.Infow("error finding metadata in onnx file", "error", err)
+	} else {
+		if graphName, err := md.GetGraphName(); err == nil {
+			out.ModelName = graphName
+		}
+		if description, err := md.GetDescription(); err == nil {
+			out.ModelDescription = description
+		}
+		//nolint:errcheck
+		md.Destroy()
+	}
+
+	out.Inputs = make([]mlmodel.TensorInfo, 0, len(m.inputs))
+	for _, info := range m.inputs {
+		out.Inputs = append(out.Inputs, getTensorInfo(info))
+	}
+	out.Outputs = make([]mlmodel.TensorInfo, 0, len(m.outputs))
+	for i, info := range m.outputs {
+		td := getTensorInfo(info)
+		if i == 0 && labelPath != "" {
+			td.Extra = map[string]interface{}{"labels": labelPath}
+		}
+		out.Outputs = append(out.Outputs, td)
+	}
+	return out
+}
+
+// getTensorInfo converts the information about an input or output of the graph to the TensorInfo struct that we
+// use in the mlmodel. Dimensions without a fixed size, such as batch dimensions, are -1.
+func getTensorInfo(info ort.InputOutputInfo) mlmodel.TensorInfo {
+	shape := make([]int, 0, len(info.Dimensions))
+	for _, d := range info.Dimensions {
+		shape = append(shape, int(d))
+	}
+	return mlmodel.TensorInfo{
+		Name:     info.Name,
+		DataType: dataTypeName(info.DataType),
+		Shape:    shape,
+	}
+}
+
+// dataTypeName is the mlmodel name of an ONNX element type, or empty for the ones tensors cannot hold.
+func dataTypeName(dataType ort.TensorElementDataType) string {
+	switch dataType {
+	case ort.TensorElementDataTypeFloat:
+		return "float32"
+	case ort.TensorElementDataTypeDouble:
+		return "float64"
+	case ort.TensorElementDataTypeInt8:
+		return "int8"
+	case ort.TensorElementDataTypeUint8:
+		return "uint8"
+	case ort.TensorElementDataTypeInt16:
+		return "int16"
+	case ort.TensorElementDataTypeUint16:
+		return "uint16"
+	case ort.TensorElementDataTypeInt32:
+		return "int32"
+	case ort.TensorElementDataTypeUint32:
+		return "uint32"
+	case ort.TensorElementDataTypeInt64:
+		return "int64"
+	case ort.TensorElementDataTypeUint64:
+		return "uint64"
+	default:
+		return ""
+	}
+}
+
+// Infer runs the input tensors, keyed by the names of the graph's inputs, through the model, and returns its
+// outputs keyed by the names of the graph's outputs. A model with a single input takes a single tensor of any name.
+func (m *Model) Infer(ctx context.Context, tensors ml.Tensors) (ml.Tensors, error) {
+	_, span := trace.StartSpan(ctx, "service::mlmodel::onnx::Infer")
+	defer span.End()
+
+	inputs := make([]ort.Value, 0, len(m.inputs))
+	defer func() {
+		for _, in := range inputs {
+			//nolint:errcheck
+			in.Destroy()
+		}
+	}()
+	for _, info := range m.inputs {
+		t, ok := tensors[info.Name]
+		if !ok && len(m.inputs) == 1 && len(tensors) == 1 {
+			for _, only := range tensors {
+				t, ok = only, true
+			}
+		}
+		if !ok {
+			return nil, errors.Errorf("missing input tensor %q of model %q", info.Name, m.Name())
+		}
+		in, err := toValue(t, info.DataType)
+		if err != nil {
+			return nil, errors.Wrapf(err, "input tensor %q", info.Name)
+		}
+		inputs = append(inputs, in)
+	}
+
+	// outputs left nil are allocated by the session, as their shapes may depend on the inputs
+	outputs := make([]ort.Value, len(m.outputs))
+	defer func() {
+		for _, out := range outputs {
+			if out != nil {
+				//nolint:errcheck
+				out.Destroy()
+			}
+		}
+	}()
+	if err := m.session.Run(inputs, outputs); err != nil {
+		return nil, errors.Wrapf(err, "couldn't infer from model %q", m.Name())
+	}
+
+	results := ml.Tensors{}
+	for i, out := range outputs {
+		t, err := toDense(out)
+		if err != nil {
+			return nil, errors.Wrapf(err, "output tensor %q", m.outputs[i].Name)
+		}
+		results[m.outputs[i].Name] = t
+	}
+	return results, nil
+}
+
+// toValue wraps the data of a tensor in an ONNX Runtime tensor of the element type the graph expects, which must be
+// the tensor's own.
+func toValue(t *tensor.Dense, dataType ort.TensorElementDataType) (ort.Value, error) {
+	dims := make([]int64, 0, len(t.Shape()))
+	for _, d := range t.Shape() {
+		dims = append(dims, int64(d))
+	}
+	shape := ort.NewShape(dims...)
+	switch dataType {
+	case ort.TensorElementDataTypeFloat:
+		return newValue[float32](t, shape)
+	case ort.TensorElementDataTypeDouble:
+		return newValue[float64](t, shape)
+	case ort.TensorElementDataTypeInt8:
+		return newValue[int8](t, shape)
+	case ort.TensorElementDataTypeUint8:
+		return newValue[uint8](t, shape)
+	case ort.TensorElementDataTypeInt16:
+		return newValue[int16](t, shape)
+	case ort.TensorElementDataTypeUint16:
+		return newValue[uint16](t, shape)
+	case ort.TensorElementDataTypeInt32:
+		return newValue[int32](t, shape)
+	case ort.TensorElementDataTypeUint32:
+		return newValue[uint32](t, shape)
+	case ort.TensorElementDataTypeInt64:
+		return newValue[int64](t, shape)
+	case ort.TensorElementDataTypeUint64:
+		return newValue[uint64](t, shape)
+	default:
+		return nil, errors.Errorf("unsupported element type %v", dataType)
+	}
+}
+
+func newValue[T ort.TensorData](t *tensor.Dense, shape ort.Shape) (ort.Value, error) {
+	data, ok := t.Data().([]T)
+	if !ok {
+		return nil, errors.Errorf("the model takes %T data, not %T", []T(nil), t.Data())
+	}
+	return ort.NewTensor(shape, data)
+}
+
+// toDense copies an output of the session into a tensor, as the session's memory is freed with it.
+func toDense(v ort.Value) (*tensor.Dense, error) {
+	switch out := v.(type) {
+	case *ort.Tensor[float32]:
+		return newDense(out), nil
+	case *ort.Tensor[float64]:
+		return newDense(out), nil
+	case *ort.Tensor[int8]:
+		return newDense(out), nil
+	case *ort.Tensor[uint8]:
+		return newDense(out), nil
+	case *ort.Tensor[int16]:
+		return newDense(out), nil
+	case *ort.Tensor[uint16]:
+		return newDense(out), nil
+	case *ort.Tensor[int32]:
+		return newDense(out), nil
+	case *ort.Tensor[uint32]:
+		return newDense(out), nil
+	case *ort.Tensor[int64]:
+		return newDense(out), nil
+	case *ort.Tensor[uint64]:
+		return newDense(out), nil
+	default:
+		return nil, errors.Errorf("unsupported output %T", v)
+	}
+}
+
+func newDense[T ort.TensorData](out *ort.Tensor[T]) *tensor.Dense {
+	shape := make([]int, 0, len(out.GetShape()))
+	for _, d := range out.GetShape() {
+		shape = append(shape, int(d))
+	}
+	data := append([]T(nil), out.GetData()...)
+	return tensor.New(tensor.WithShape(shape...), tensor.WithBacking(data))
+}
+
+// Metadata returns the metadata derived from the graph of the model.
+func (m *Model) Metadata(ctx context.Context) (mlmodel.MLMetadata, error) {
+	return m.metadata, nil
+}
+
+// Close frees the session running the model.
+func (m *Model) Close(ctx context.Context) error {
+	return m.session.Destroy()
+}
@@ -0,0 +1,8 @@
+//go:build onnxruntime && !no_cgo
+
+package register
+
+import (
+	// register onnx.
+	_ "go.viam.com/rdk/services/mlmodel/onnx"
+)
@@ -0,0 +1,327 @@
+package mlmodel
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"gorgonia.org/tensor"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/ml"
+	"go.viam.com/rdk/resource"
+)
+
+// SchedulerConfig configures how the inferences of a model are scheduled. Concurrent calls to Infer are batched,
+// up to MaxBatchSize of them waiting at most MaxBatchDelayMs for each other, and run in one forward pass by one of
+// NumSessions sessions of the model, which run in parallel.
+//
+// Batching needs a model whose inputs all have a dynamic first dimension, which is -1 in their metadata, and whose
+// outputs are batched along their first dimension too. The tensors of each call are concatenated along it, and the
+// outputs split back along it.
+type SchedulerConfig struct {
+	MaxBatchSize    int     `json:"max_batch_size,omitempty"`
+	MaxBatchDelayMs float64 `json:"max_batch_delay_ms,omitempty"`
+	NumSessions     int     `json:"num_sessions,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf SchedulerConfig) Validate(path string) error {
+	if conf.MaxBatchSize < 0 {
+		return resource.NewConfigValidationError(path, errors.New("max_batch_size cannot be negative"))
+	}
+	if conf.MaxBatchDelayMs < 0 {
+		return resource.NewConfigValidationError(path, errors.New("max_batch_delay_ms cannot be negative"))
+	}
+	if conf.NumSessions < 0 {
+		return resource.NewConfigValidationError(path, errors.New("num_sessions cannot be negative"))
+	}
+	return nil
+}
+
+// Enabled is whether the config asks for more than one inference to run at a time.
+func (conf SchedulerConfig) Enabled() bool {
+	return conf.MaxBatchSize > 1 || conf.NumSessions > 1
+}
+
+type inferRequest struct {
+	ctx     context.Context
+	tensors ml.Tensors
+	result  chan inferResult
+}
+
+type inferResult struct {
+	tensors ml.Tensors
+	err     error
+}
+
+// scheduledService is a Service whose inferences are batched and run by a pool of sessions of a model.
+type scheduledService struct {
+	resource.Named
+	resource.AlwaysRebuild
+	sessions      []Service
+	metadata      MLMetadata
+	maxBatchSize  int
+	maxBatchDelay time.Duration
+	logger        logging.Logger
+
+	requests                chan *inferRequest
+	cancelCtx               context.Context
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+// NewScheduledService returns a Service running the inferences of a model as the config schedules them, on sessions
+// made by newSession. The sessions are closed with the service.
+func NewScheduledService(
+	ctx context.Context, conf SchedulerConfig, newSession func(ctx context.Context) (Service, error), logger logging.Logger,
+) (Service, error) {
+	numSessions := max(conf.NumSessions, 1)
+	sessions := make([]Service, 0, numSessions)
+	closeSessions := func() error {
+		var err error
+		for _, session := range sessions {
+			err = multierr.Combine(err, session.Close(ctx))
+		}
+		return err
+	}
+	for i := 0; i < numSessions; i++ {
+		session, err := newSession(ctx)
+		if err != nil {
+			return nil, multierr.Combine(errors.Wrapf(err, "could not make session %d of the model", i), closeSessions())
+		}
+		sessions = append(sessions, session)
+	}
+	md, err := sessions[0].Metadata(ctx)
+	if err != nil {
+		return nil, multierr.Combine(err, closeSessions())
+	}
+
+	maxBatchSize := max(conf.MaxBatchSize, 1)
+	if maxBatchSize > 1 && !batchable(md) {
+		logger.CWarnw(ctx, "not batching inferences, as the inputs of the model do not all have a dynamic first dimension",
+			"name", sessions[0].Name())
+		maxBatchSize = 1
+	}
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	s := &scheduledService{
+		Named:         sessions[0].Name().AsNamed(),
+		sessions:      sessions,
+		metadata:      md,
+		maxBatchSize:  maxBatchSize,
+		maxBatchDelay: time.Duration(conf.MaxBatchDelayMs * float64(time.Millisecond)),
+		logger:        logger,
+		requests:      make(chan *inferRequest),
+		cancelCtx:     cancelCtx,
+		cancel:        cancel,
+	}
+	for _, session := range sessions {
+		session := session
+		s.activeBackgroundWorkers.Add(1)
+		go func() {
+			defer s.activeBackgroundWorkers.Done()
+			s.work(session)
+		}()
+	}
+	return s, nil
+}
+
+// batchable is whether the inputs of a model all have a dynamic first dimension to batch along.
+func batchable(md MLMetadata) bool {
+	if len(md.Inputs) == 0 {
+		return false
+	}
+	for _, in := range md.Inputs {
+		if len(in.Shape) == 0 || in.Shape[0] != -1 {
+			return false
+		}
+	}
+	return true
+}
+
+// Infer waits for a session to run the tensors, batched with the ones of concurrent calls.
+func (s *scheduledService) Infer(ctx context.Context, tensors ml.Tensors) (ml.Tensors, error) {
+	req := &inferRequest{ctx: ctx, tensors: tensors, result: make(chan inferResult, 1)}
+	select {
+	case s.requests <- req:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.cancelCtx.Done():
+		return nil, errors.New("the model is closed")
+	}
+	select {
+	case res := <-req.result:
+		return res.tensors, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Metadata returns the metadata of the model.
+func (s *scheduledService) Metadata(ctx context.Context) (MLMetadata, error) {
+	return s.metadata, nil
+}
+
+// DoCommand passes the command to the first session of the model.
+func (s *scheduledService) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return s.sessions[0].DoCommand(ctx, cmd)
+}
+
+// Close stops the sessions and closes them.
+func (s *scheduledService) Close(ctx context.Context) error {
+	s.cancel()
+	s.activeBackgroundWorkers.Wait()
+	var err error
+	for _, session := range s.sessions {
+		err = multierr.Combine(err, session.Close(ctx))
+	}
+	return err
+}
+
+// work runs batches of requests on a session until the service is closed. A batch starts with the first request
+// to arrive, and takes the ones arriving within the batch delay after it, up to the batch size.
+func (s *scheduledService) work(session Service) {
+	for {
+		var batch []*inferRequest
+		select {
+		case req := <-s.requests:
+			batch = append(batch, req)
+		case <-s.cancelCtx.Done():
+			return
+		}
+		if s.maxBatchSize > 1 {
+			timer := time.NewTimer(s.maxBatchDelay)
+		collect:
+			for len(batch) < s.maxBatchSize {
+				select {
+				case req := <-s.requests:
+					batch = append(batch, req)
+				case <-timer.C:
+					break collect
+				case <-s.cancelCtx.Done():
+					break collect
+				}
+			}
+			timer.Stop()
+		}
+		s.run(session, batch)
+	}
+}
+
+// run runs a batch of requests in one forward pass, or one by one if their tensors cannot be batched.
+func (s *scheduledService) run(session Service, batch []*inferRequest) {
+	live := make([]*inferRequest, 0, len(batch))
+	for _, req := range batch {
+		if req.ctx.Err() == nil {
+			live = append(live, req)
+		}
+	}
+	if len(live) == 0 {
+		return
+	}
+	if len(live) == 1 {
+		tensors, err := session.Infer(live[0].ctx, live[0].tensors)
+		live[0].result <- inferResult{tensors: tensors, err: err}
+		return
+	}
+
+	inputs, sizes, err := concatTensors(live)
+	if err != nil {
+		s.logger.Debugw("running requests one by one", "error", err)
+		for _, req := range live {
+			tensors, err := session.Infer(req.ctx, req.tensors)
+			req.result <- inferResult{tensors: tensors, err: err}
+		}
+		return
+	}
+	outputs, err := session.Infer(s.cancelCtx, inputs)
+	if err != nil {
+		for _, req := range live {
+			req.result <- inferResult{err: err}
+		}
+		return
+	}
+	results, err := splitTensors(outputs, sizes)
+	for i, req := range live {
+		if err != nil {
+			req.result <- inferResult{err: err}
+			continue
+		}
+		req.result <- inferResult{tensors: results[i]}
+	}
+}
+
+// concatTensors concatenates the tensors of requests along their first dimensions, returning how much of it each
+// request has. The requests must have tensors of the same names, types, and other dimensions.
+func concatTensors(reqs []*inferRequest) (ml.Tensors, []int, error) {
+	sizes := make([]int, 0, len(reqs))
+	for _, req := range reqs {
+		size := -1
+		for name, t := range req.tensors {
+			if t.Dims() == 0 {
+				return nil, nil, errors.Errorf("tensor %q has no dimension to batch along", name)
+			}
+			if size != -1 && t.Shape()[0] != size {
+				return nil, nil, errors.Errorf("tensor %q has a first dimension of %d, not %d", name, t.Shape()[0], size)
+			}
+			size = t.Shape()[0]
+		}
+		if size <= 0 {
+			return nil, nil, errors.New("requests need tensors with a first dimension to batch along")
+		}
+		if len(req.tensors) != len(reqs[0].tensors) {
+			return nil, nil, errors.New("requests have different numbers of tensors")
+		}
+		sizes = append(sizes, size)
+	}
+
+	batched := ml.Tensors{}
+	for name, first := range reqs[0].tensors {
+		rest := make([]*tensor.Dense, 0, len(reqs)-1)
+		for _, req := range reqs[1:] {
+			t, ok := req.tensors[name]
+			if !ok {
+				return nil, nil, errors.Errorf("not every request has tensor %q", name)
+			}
+			rest = append(rest, t)
+		}
+		t, err := first.Concat(0, rest...)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "could not batch tensor %q", name)
+		}
+		batched[name] = t
+	}
+	return batched, sizes, nil
+}
+
+// splitTensors splits the outputs of a batch along their first dimensions into the outputs of each request.
+func splitTensors(outputs ml.Tensors, sizes []int) ([]ml.Tensors, error) {
+	total := 0
+	for _, size := range sizes {
+		total += size
+	}
+	results := make([]ml.Tensors, len(sizes))
+	for i := range results {
+		results[i] = ml.Tensors{}
+	}
+	for name, t := range outputs {
+		if t.Dims() == 0 || t.Shape()[0] != total {
+			return nil, errors.Errorf("output tensor %q is not batched along its first dimension", name)
+		}
+		data := reflect.ValueOf(t.Data())
+		rowLen := data.Len() / total
+		start := 0
+		for i, size := range sizes {
+			part := reflect.MakeSlice(data.Type(), size*rowLen, size*rowLen)
+			reflect.Copy(part, data.Slice(start*rowLen, (start+size)*rowLen))
+			shape := append([]int{size}, t.Shape()[1:]...)
+			results[i][name] = tensor.New(tensor.WithShape(shape...), tensor.WithBacking(part.Interface()))
+			start += size
+		}
+	}
+	return results, nil
+}
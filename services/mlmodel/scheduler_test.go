@@ -0,0 +1,112 @@
+package mlmodel_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+	"gorgonia.org/tensor"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/ml"
+	"go.viam.com/rdk/services/mlmodel"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// doublingSessions make sessions of a model doubling its input, recording the first dimension of each of its
+// inferences.
+type doublingSessions struct {
+	mu         sync.Mutex
+	batchSizes []int
+	closed     int
+	shape      []int
+}
+
+func (d *doublingSessions) newSession(ctx context.Context) (mlmodel.Service, error) {
+	svc := inject.NewMLModelService("model")
+	svc.MetadataFunc = func(ctx context.Context) (mlmodel.MLMetadata, error) {
+		return mlmodel.MLMetadata{Inputs: []mlmodel.TensorInfo{{Name: "in", DataType: "float32", Shape: d.shape}}}, nil
+	}
+	svc.InferFunc = func(ctx context.Context, tensors ml.Tensors) (ml.Tensors, error) {
+		in := tensors["in"]
+		d.mu.Lock()
+		d.batchSizes = append(d.batchSizes, in.Shape()[0])
+		d.mu.Unlock()
+		data := in.Data().([]float32)
+		out := make([]float32, len(data))
+		for i, v := range data {
+			out[i] = 2 * v
+		}
+		return ml.Tensors{"out": tensor.New(tensor.WithShape(in.Shape()...), tensor.WithBacking(out))}, nil
+	}
+	svc.CloseFunc = func(ctx context.Context) error {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.closed++
+		return nil
+	}
+	return svc, nil
+}
+
+// inferConcurrently runs inferences of 1x2 tensors of i and i+0.5 at once, checking their outputs.
+func inferConcurrently(t *testing.T, svc mlmodel.Service, n int) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			in := tensor.New(tensor.WithShape(1, 2), tensor.WithBacking([]float32{float32(i), float32(i) + 0.5}))
+			out, err := svc.Infer(context.Background(), ml.Tensors{"in": in})
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, out["out"].Shape(), test.ShouldResemble, tensor.Shape{1, 2})
+			test.That(t, out["out"].Data(), test.ShouldResemble, []float32{float32(2 * i), float32(2*i + 1)})
+		}()
+	}
+	wg.Wait()
+}
+
+func TestScheduledServiceBatching(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	sessions := &doublingSessions{shape: []int{-1, 2}}
+	conf := mlmodel.SchedulerConfig{MaxBatchSize: 4, MaxBatchDelayMs: 1000}
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+	test.That(t, conf.Enabled(), test.ShouldBeTrue)
+
+	svc, err := mlmodel.NewScheduledService(ctx, conf, sessions.newSession, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, svc.Name(), test.ShouldResemble, mlmodel.Named("model"))
+	md, err := svc.Metadata(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, md.Inputs[0].Name, test.ShouldEqual, "in")
+
+	// a full batch runs without waiting out its delay
+	inferConcurrently(t, svc, 4)
+	test.That(t, sessions.batchSizes, test.ShouldResemble, []int{4})
+
+	test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	test.That(t, sessions.closed, test.ShouldEqual, 1)
+	_, err = svc.Infer(ctx, ml.Tensors{})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestScheduledServiceSessions(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	// a fixed first dimension cannot be batched along
+	sessions := &doublingSessions{shape: []int{1, 2}}
+	svc, err := mlmodel.NewScheduledService(ctx, mlmodel.SchedulerConfig{MaxBatchSize: 4, NumSessions: 3}, sessions.newSession, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	inferConcurrently(t, svc, 6)
+	test.That(t, sessions.batchSizes, test.ShouldResemble, []int{1, 1, 1, 1, 1, 1})
+
+	test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	test.That(t, sessions.closed, test.ShouldEqual, 3)
+
+	test.That(t, mlmodel.SchedulerConfig{MaxBatchSize: -1}.Validate("path"), test.ShouldNotBeNil)
+	test.That(t, mlmodel.SchedulerConfig{NumSessions: 1}.Enabled(), test.ShouldBeFalse)
+}
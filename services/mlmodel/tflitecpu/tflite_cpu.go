@@ -11,6 +11,7 @@ import "C"
 
 import (
 	"context"
+	"fmt"
 	"math"
 	fp "path/filepath"
 	"strconv"
@@ -41,6 +42,11 @@ func init() {
 			if err != nil {
 				return nil, err
 			}
+			if svcConf.Scheduler.Enabled() {
+				return mlmodel.NewScheduledService(ctx, svcConf.Scheduler, func(ctx context.Context) (mlmodel.Service, error) {
+					return NewTFLiteCPUModel(ctx, svcConf, conf.ResourceName())
+				}, logger)
+			}
 			return NewTFLiteCPUModel(ctx, svcConf, conf.ResourceName())
 		},
 	})
@@ -53,6 +59,8 @@ type TFLiteConfig struct {
 	ModelPath  string `json:"model_path"`
 	NumThreads int    `json:"num_threads"`
 	LabelPath  string `json:"label_path"`
+	// Scheduler batches concurrent inferences and runs them on more than one copy of the model.
+	Scheduler mlmodel.SchedulerConfig `json:"scheduler,omitempty"`
 }
 
 // Validate will check if the config is valid.
@@ -60,6 +68,9 @@ func (conf *TFLiteConfig) Validate(path string) ([]string, error) {
 	if conf.ModelPath == "" {
 		return nil, errors.New("model_path attribute cannot be empty")
 	}
+	if err := conf.Scheduler.Validate(fmt.Sprintf("%s.%s", path, "scheduler")); err != nil {
+		return nil, err
+	}
 	return nil, nil
 }
 
@@ -0,0 +1,215 @@
+package builtin
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	pb "go.viam.com/api/component/arm/v1"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// blendPollInterval is how often a blended Move checks how far the component is from its goal.
+const blendPollInterval = 20 * time.Millisecond
+
+// A blendedMotion is an arm trajectory still running after the Move which started it has returned.
+type blendedMotion struct {
+	goal   []referenceframe.Input
+	cancel func()
+	done   chan struct{}
+	err    error
+}
+
+// moveBlendRadius returns the blend radius of a Move, which the "blend_radius_mm" extra overrides.
+func (ms *builtIn) moveBlendRadius(extra map[string]interface{}) (float64, error) {
+	raw, ok := extra["blend_radius_mm"]
+	if !ok {
+		return ms.blendRadiusMM, nil
+	}
+	radius, ok := raw.(float64)
+	if !ok || radius < 0 {
+		return 0, errors.New("blend_radius_mm must be a non-negative number")
+	}
+	return radius, nil
+}
+
+// blendedGoals returns the goals of the arms still running a blended motion.
+func (ms *builtIn) blendedGoals() map[string][]referenceframe.Input {
+	ms.blendMu.Lock()
+	defer ms.blendMu.Unlock()
+	goals := make(map[string][]referenceframe.Input, len(ms.blending))
+	for name, motion := range ms.blending {
+		goals[name] = motion.goal
+	}
+	return goals
+}
+
+// finishBlendedMotions waits for the blended motions still running to end, canceling them first if asked.
+func (ms *builtIn) finishBlendedMotions(cancel bool) {
+	ms.blendMu.Lock()
+	motions := ms.blending
+	ms.blending = nil
+	ms.blendMu.Unlock()
+	for _, motion := range motions {
+		if cancel {
+			motion.cancel()
+		}
+		<-motion.done
+	}
+}
+
+// moveBlended runs a trajectory which moves a single arm as one continuous motion, starting from the goal of the
+// previous blended motion of the arm so the two are joined without stopping. It returns once the component is within
+// the blend radius of its goal, leaving the rest of the motion running, and says whether the trajectory could be
+// blended at all.
+func (ms *builtIn) moveBlended(
+	ctx context.Context,
+	componentName resource.Name,
+	frameSys referenceframe.FrameSystem,
+	fsInputs map[string][]referenceframe.Input,
+	resources map[string]referenceframe.InputEnabled,
+	traj motionplan.Trajectory,
+	blendRadiusMM float64,
+) (bool, error) {
+	if len(traj) == 0 {
+		return false, nil
+	}
+	armName, ok := singleMovingFrame(traj)
+	if !ok {
+		return false, nil
+	}
+	movingArm, ok := resources[armName].(arm.Arm)
+	if !ok {
+		return false, nil
+	}
+	executor, ok := movingArm.(arm.TrajectoryExecutor)
+	if !ok {
+		return false, nil
+	}
+	steps, err := traj.GetFrameInputs(armName)
+	if err != nil {
+		return false, err
+	}
+
+	ms.blendMu.Lock()
+	previous := ms.blending[armName]
+	ms.blendMu.Unlock()
+	if previous == nil {
+		// the first step is where the arm already is
+		steps = steps[1:]
+	}
+	if len(steps) == 0 {
+		return true, nil
+	}
+	model := movingArm.ModelFrame()
+	positions := make([]*pb.JointPositions, 0, len(steps))
+	for _, step := range steps {
+		positions = append(positions, model.ProtobufFromInput(step))
+	}
+
+	goalInputs := make(map[string][]referenceframe.Input, len(fsInputs))
+	for name, inputs := range fsInputs {
+		goalInputs[name] = inputs
+	}
+	goal := steps[len(steps)-1]
+	goalInputs[armName] = goal
+	goalPose, err := componentPose(frameSys, goalInputs, componentName)
+	if err != nil {
+		return false, err
+	}
+
+	// the new motion supersedes the previous one on the arm, which blends from one into the other
+	motionCtx, cancel := context.WithCancel(context.Background())
+	motion := &blendedMotion{goal: goal, cancel: cancel, done: make(chan struct{})}
+	ms.blendMu.Lock()
+	if ms.blending == nil {
+		ms.blending = map[string]*blendedMotion{}
+	}
+	ms.blending[armName] = motion
+	ms.blendMu.Unlock()
+	go func() {
+		defer close(motion.done)
+		defer func() {
+			cancel()
+			// the arm normally ends the previous motion as soon as this one starts, but make sure it is over
+			if previous != nil {
+				previous.cancel()
+				<-previous.done
+			}
+		}()
+		recorder := ms.recordJoints(uuid.New(), armName, movingArm, traj)
+		motion.err = executor.MoveThroughJointPositions(motionCtx, positions, &arm.MoveOptions{BlendRadiusMM: blendRadiusMM}, nil)
+		recorder.finish(motion.err)
+		ms.blendMu.Lock()
+		if ms.blending[armName] == motion {
+			delete(ms.blending, armName)
+		}
+		ms.blendMu.Unlock()
+	}()
+
+	ticker := time.NewTicker(blendPollInterval)
+	defer ticker.Stop()
+	currentInputs := goalInputs
+	for {
+		select {
+		case <-ctx.Done():
+			motion.cancel()
+			<-motion.done
+			if stopErr := movingArm.Stop(context.Background(), nil); stopErr != nil {
+				return true, errors.Wrap(ctx.Err(), stopErr.Error())
+			}
+			return true, ctx.Err()
+		case <-motion.done:
+			return true, motion.err
+		case <-ticker.C:
+		}
+		inputs, err := movingArm.CurrentInputs(ctx)
+		if err != nil {
+			continue
+		}
+		currentInputs[armName] = inputs
+		pose, err := componentPose(frameSys, currentInputs, componentName)
+		if err != nil {
+			return true, err
+		}
+		if spatialmath.PoseDelta(pose, goalPose).Point().Norm() <= blendRadiusMM {
+			return true, nil
+		}
+	}
+}
+
+// singleMovingFrame returns the only frame whose inputs change over a trajectory.
+func singleMovingFrame(traj motionplan.Trajectory) (string, bool) {
+	moving := ""
+	for name, start := range traj[0] {
+		for _, step := range traj[1:] {
+			if referenceframe.InputsL2Distance(start, step[name]) == 0 {
+				continue
+			}
+			if moving != "" && moving != name {
+				return "", false
+			}
+			moving = name
+		}
+	}
+	return moving, moving != ""
+}
+
+// componentPose returns the pose of a component in the world frame for the given inputs.
+func componentPose(
+	frameSys referenceframe.FrameSystem, inputs map[string][]referenceframe.Input, componentName resource.Name,
+) (spatialmath.Pose, error) {
+	tf, err := frameSys.Transform(
+		inputs, referenceframe.NewPoseInFrame(componentName.ShortName(), spatialmath.NewZeroPose()), referenceframe.World,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return tf.(*referenceframe.PoseInFrame).Pose(), nil
+}
@@ -74,10 +74,17 @@ var ErrNotImplemented = errors.New("function coming soon but not yet implemented
 // Config describes how to configure the service; currently only used for specifying dependency on framesystem service.
 type Config struct {
 	LogFilePath string `json:"log_file_path"`
+	// BlendRadiusMM is how close in millimeters an arm comes to the goal of a Move before the call returns and the
+	// next Move may blend into it, so streamed goals become one continuous motion. Zero stops between moves. The
+	// "blend_radius_mm" extra overrides it for a single Move.
+	BlendRadiusMM float64 `json:"blend_radius_mm,omitempty"`
 }
 
 // Validate here adds a dependency on the internal framesystem service.
 func (c *Config) Validate(path string) ([]string, error) {
+	if c.BlendRadiusMM < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("blend_radius_mm cannot be negative"))
+	}
 	return []string{framesystem.InternalServiceName.String()}, nil
 }
 
@@ -86,8 +93,9 @@ func NewBuiltIn(
 	ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
 ) (motion.Service, error) {
 	ms := &builtIn{
-		Named:  conf.ResourceName().AsNamed(),
-		logger: logger,
+		Named:        conf.ResourceName().AsNamed(),
+		logger:       logger,
+		trajectories: &trajectoryLog{},
 	}
 
 	if err := ms.Reconfigure(ctx, deps, conf); err != nil {
@@ -138,6 +146,7 @@ func (ms *builtIn) Reconfigure(
 	ms.slamServices = slamServices
 	ms.visionServices = visionServices
 	ms.components = components
+	ms.blendRadiusMM = config.BlendRadiusMM
 	if ms.state != nil {
 		ms.state.Stop()
 	}
@@ -160,6 +169,21 @@ type builtIn struct {
 	components      map[resource.Name]resource.Resource
 	logger          logging.Logger
 	state           *state.State
+	blendRadiusMM   float64
+
+	blendMu  sync.Mutex
+	blending map[string]*blendedMotion
+
+	trajectories *trajectoryLog
+}
+
+// DoCommand runs the motion service's commands, which report on the trajectories of recent executions and generate
+// and execute motion profiles.
+func (ms *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return resource.CommandHandlers{
+		motion.TrajectoryReportsCommand: resource.TypedCommandHandler(ms.trajectoryReports),
+		motion.MotionProfileCommand:     resource.TypedCommandHandler(ms.motionProfile),
+	}.DoCommand(ctx, cmd)
 }
 
 func (ms *builtIn) Close(ctx context.Context) error {
@@ -168,6 +192,7 @@ func (ms *builtIn) Close(ctx context.Context) error {
 	if ms.state != nil {
 		ms.state.Stop()
 	}
+	ms.finishBlendedMotions(true)
 	return nil
 }
 
@@ -185,6 +210,30 @@ func (ms *builtIn) Move(
 
 	operation.CancelOtherWithLabel(ctx, builtinOpLabel)
 
+	monitor, err := newMoveMonitorConfig(extra)
+	if err != nil {
+		return false, err
+	}
+	if monitor != nil {
+		return ms.moveMonitored(ctx, monitoredMoveReq{
+			componentName: componentName,
+			destination:   destination,
+			worldState:    worldState,
+			constraints:   constraints,
+			extra:         extra,
+			monitor:       monitor,
+		})
+	}
+
+	blendRadiusMM, err := ms.moveBlendRadius(extra)
+	if err != nil {
+		return false, err
+	}
+	if blendRadiusMM == 0 {
+		// an unblended move starts from where the arms stop
+		ms.finishBlendedMotions(true)
+	}
+
 	// get goal frame
 	goalFrameName := destination.Parent()
 	ms.logger.CDebugf(ctx, "goal given in frame of %q", goalFrameName)
@@ -202,6 +251,11 @@ func (ms *builtIn) Move(
 
 	movingFrame := frameSys.Frame(componentName.ShortName())
 
+	// arms still blending into their last goal are planned from that goal
+	for name, goal := range ms.blendedGoals() {
+		fsInputs[name] = goal
+	}
+
 	ms.logger.CDebugf(ctx, "frame system inputs: %v", fsInputs)
 	if movingFrame == nil {
 		return false, fmt.Errorf("component named %s not found in robot frame system", componentName.ShortName())
@@ -230,6 +284,30 @@ func (ms *builtIn) Move(
 		return false, err
 	}
 
+	if blendRadiusMM > 0 {
+		blended, err := ms.moveBlended(ctx, componentName, frameSys, fsInputs, resources, plan.Trajectory(), blendRadiusMM)
+		if blended || err != nil {
+			return err == nil, err
+		}
+		// the plan cannot be blended, so it runs once the previous moves have finished
+		ms.finishBlendedMotions(false)
+	}
+
+	// record all the components which move
+	executionID := uuid.New()
+	var recorders []*trajectoryRecorder
+	for _, name := range movingFrames(plan.Trajectory()) {
+		if r, ok := resources[name]; ok {
+			recorders = append(recorders, ms.recordJoints(executionID, name, r, plan.Trajectory()))
+		}
+	}
+	var moveErr error
+	defer func() {
+		for _, recorder := range recorders {
+			recorder.finish(moveErr)
+		}
+	}()
+
 	// move all the components
 	for _, step := range plan.Trajectory() {
 		for name, inputs := range step {
@@ -238,6 +316,7 @@ func (ms *builtIn) Move(
 			}
 			r := resources[name]
 			if err := r.GoToInputs(ctx, inputs); err != nil {
+				moveErr = err
 				// If there is an error on GoToInputs, stop the component if possible before returning the error
 				if actuator, ok := r.(inputEnabledActuator); ok {
 					if stopErr := actuator.Stop(ctx, nil); stopErr != nil {
@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/golang/geo/r3"
+	"github.com/google/uuid"
 	geo "github.com/kellydunn/golang-geo"
 	"github.com/pkg/errors"
 	commonpb "go.viam.com/api/common/v1"
+	componentpb "go.viam.com/api/component/arm/v1"
 	"go.viam.com/test"
 
 	"go.viam.com/rdk/components/arm"
@@ -21,6 +24,7 @@ import (
 	"go.viam.com/rdk/components/gripper"
 	_ "go.viam.com/rdk/components/register"
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
 	"go.viam.com/rdk/pointcloud"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
@@ -1026,3 +1030,389 @@ func TestPlanHistory(t *testing.T) {
 	test.That(t, err, test.ShouldResemble, resource.NewNotFoundError(req.ComponentName))
 	test.That(t, history, test.ShouldBeNil)
 }
+
+func TestBlendedMove(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	armName := "test-arm"
+	armCfg := resource.Config{
+		Name:                armName,
+		API:                 arm.API,
+		Model:               resource.DefaultModelFamily.WithModel("ur5e"),
+		ConvertedAttributes: &armFake.Config{ArmModel: "ur5e"},
+		Frame:               &referenceframe.LinkConfig{Parent: "world"},
+	}
+	fakeArm, err := armFake.NewArm(ctx, nil, armCfg, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	var mu sync.Mutex
+	var calls [][]*componentpb.JointPositions
+	var blendRadii []float64
+	injectArm := &inject.Arm{Arm: fakeArm}
+	injectArm.MoveThroughJointPositionsFunc = func(
+		ctx context.Context, positions []*componentpb.JointPositions, options *arm.MoveOptions, extra map[string]interface{},
+	) error {
+		mu.Lock()
+		calls = append(calls, positions)
+		blendRadii = append(blendRadii, options.BlendRadiusMM)
+		mu.Unlock()
+		// the arm keeps moving until a later motion supersedes it
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	fsParts := []*referenceframe.FrameSystemPart{
+		{
+			FrameConfig: referenceframe.NewLinkInFrame(referenceframe.World, spatialmath.NewZeroPose(), armName, nil),
+			ModelFrame:  fakeArm.ModelFrame(),
+		},
+	}
+	deps := resource.Dependencies{arm.Named(armName): injectArm}
+	fsSvc, err := createFrameSystemService(ctx, deps, fsParts, logger)
+	test.That(t, err, test.ShouldBeNil)
+	frameSys, err := fsSvc.FrameSystem(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	msService, err := NewBuiltIn(ctx, deps, resource.Config{ConvertedAttributes: &Config{BlendRadiusMM: 1e6}}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	ms := msService.(*builtIn)
+
+	blendRadius, err := ms.moveBlendRadius(nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, blendRadius, test.ShouldEqual, 1e6)
+	blendRadius, err = ms.moveBlendRadius(map[string]interface{}{"blend_radius_mm": 5e5})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, blendRadius, test.ShouldEqual, 5e5)
+	_, err = ms.moveBlendRadius(map[string]interface{}{"blend_radius_mm": "far"})
+	test.That(t, err, test.ShouldBeError, errors.New("blend_radius_mm must be a non-negative number"))
+
+	start := referenceframe.FloatsToInputs([]float64{0, 0, 0, 0, 0, 0})
+	goal1 := referenceframe.FloatsToInputs([]float64{0.1, 0, 0, 0, 0, 0})
+	goal2 := referenceframe.FloatsToInputs([]float64{0.2, 0.1, 0, 0, 0, 0})
+	fsInputs := map[string][]referenceframe.Input{armName: start}
+	resources := map[string]referenceframe.InputEnabled{armName: injectArm}
+
+	// the first move returns within the blend radius of its goal while the arm keeps moving
+	traj := motionplan.Trajectory{{armName: start}, {armName: goal1}}
+	blended, err := ms.moveBlended(ctx, arm.Named(armName), frameSys, fsInputs, resources, traj, 1e6)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, blended, test.ShouldBeTrue)
+	test.That(t, ms.blendedGoals(), test.ShouldResemble, map[string][]referenceframe.Input{armName: goal1})
+
+	// the next move is planned from the goal of the first and passes through it
+	traj = motionplan.Trajectory{{armName: goal1}, {armName: goal2}}
+	blended, err = ms.moveBlended(ctx, arm.Named(armName), frameSys, fsInputs, resources, traj, 5e5)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, blended, test.ShouldBeTrue)
+
+	mu.Lock()
+	test.That(t, calls, test.ShouldHaveLength, 2)
+	test.That(t, calls[0], test.ShouldHaveLength, 1)
+	test.That(t, calls[1], test.ShouldHaveLength, 2)
+	test.That(t, calls[1][0].Values, test.ShouldResemble, calls[0][0].Values)
+	test.That(t, blendRadii, test.ShouldResemble, []float64{1e6, 5e5})
+	mu.Unlock()
+
+	// trajectories moving more than one frame are not blended
+	traj = motionplan.Trajectory{{armName: goal2, "other": {{Value: 0}}}, {armName: goal1, "other": {{Value: 1}}}}
+	blended, err = ms.moveBlended(ctx, arm.Named(armName), frameSys, fsInputs, resources, traj, 5e5)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, blended, test.ShouldBeFalse)
+
+	test.That(t, ms.Close(ctx), test.ShouldBeNil)
+	test.That(t, ms.blendedGoals(), test.ShouldBeEmpty)
+
+	// both blended motions were recorded, the first having been superseded by the second
+	reports, err := motion.TrajectoryReports(ctx, ms, motion.TrajectoryReportsReq{ComponentName: armName})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reports, test.ShouldHaveLength, 2)
+	test.That(t, reports[1].Recording.Error, test.ShouldEqual, context.Canceled.Error())
+
+	_, err = (&Config{BlendRadiusMM: -1}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestTrajectoryReports(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	ms, err := NewBuiltIn(ctx, nil, resource.Config{ConvertedAttributes: &Config{}}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer ms.Close(ctx)
+
+	reports, err := motion.TrajectoryReports(ctx, ms, motion.TrajectoryReportsReq{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reports, test.ShouldBeEmpty)
+
+	// the arm lags 0.1 behind the path on its first joint and ends where it was commanded to
+	var mu sync.Mutex
+	current := []float64{0.1, 0}
+	injectArm := &inject.Arm{}
+	injectArm.CurrentInputsFunc = func(ctx context.Context) ([]referenceframe.Input, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return referenceframe.FloatsToInputs(current), nil
+	}
+	traj := motionplan.Trajectory{
+		{"arm": referenceframe.FloatsToInputs([]float64{0, 0})},
+		{"arm": referenceframe.FloatsToInputs([]float64{0, 1})},
+	}
+	test.That(t, movingFrames(traj), test.ShouldResemble, []string{"arm"})
+	executionID := uuid.New()
+	recorder := ms.(*builtIn).recordJoints(executionID, "arm", injectArm, traj)
+	time.Sleep(3 * trajectorySampleInterval)
+	mu.Lock()
+	current = []float64{0, 1}
+	mu.Unlock()
+	recorder.finish(nil)
+
+	reports, err = motion.TrajectoryReports(ctx, ms, motion.TrajectoryReportsReq{ComponentName: "arm", IncludeSamples: true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reports, test.ShouldHaveLength, 1)
+	rec := reports[0].Recording
+	test.That(t, rec.ComponentName, test.ShouldEqual, "arm")
+	test.That(t, rec.ExecutionID, test.ShouldEqual, executionID.String())
+	test.That(t, rec.Kind, test.ShouldEqual, motion.TrajectoryKindJoints)
+	test.That(t, rec.Error, test.ShouldBeEmpty)
+	test.That(t, rec.Commanded, test.ShouldResemble, [][]float64{{0, 0}, {0, 1}})
+	test.That(t, len(rec.Measured), test.ShouldBeGreaterThan, 2)
+	test.That(t, rec.Measured[len(rec.Measured)-1].Values, test.ShouldResemble, []float64{0, 1})
+	score := reports[0].Score
+	test.That(t, score.Samples, test.ShouldEqual, len(rec.Measured))
+	test.That(t, score.MaxTrackingError, test.ShouldAlmostEqual, 0.1)
+	test.That(t, score.FinalError, test.ShouldAlmostEqual, 0)
+
+	reports, err = motion.TrajectoryReports(ctx, ms, motion.TrajectoryReportsReq{ExecutionID: executionID.String()})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reports, test.ShouldHaveLength, 1)
+	test.That(t, reports[0].Recording.Measured, test.ShouldBeNil)
+
+	reports, err = motion.TrajectoryReports(ctx, ms, motion.TrajectoryReportsReq{ComponentName: "otherArm"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reports, test.ShouldBeEmpty)
+
+	_, err = motion.TrajectoryReports(ctx, ms, motion.TrajectoryReportsReq{ExecutionID: "latest"})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = ms.DoCommand(ctx, map[string]interface{}{"command": "dance"})
+	test.That(t, errors.Is(err, resource.ErrDoUnimplemented), test.ShouldBeTrue)
+}
+
+func TestMotionProfile(t *testing.T) {
+	ctx := context.Background()
+	ms, teardown := setupMotionServiceFromConfig(t, "../data/moving_arm.json")
+	defer teardown()
+
+	grid := &motion.GridProfile{Origin: motion.ProfilePose{X: -800, Y: -250, Z: 60, OY: -1, Theta: 90}, WidthMM: 20, StepMM: 20}
+	resp, err := motion.MotionProfile(ctx, ms, motion.MotionProfileReq{Grid: grid})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp.Frame, test.ShouldEqual, referenceframe.World)
+	test.That(t, resp.Poses, test.ShouldHaveLength, 2)
+	test.That(t, resp.Poses[1].X, test.ShouldAlmostEqual, -780)
+	test.That(t, resp.Executed, test.ShouldEqual, 0)
+
+	_, err = motion.MotionProfile(ctx, ms, motion.MotionProfileReq{Grid: grid, Execute: true, ComponentName: "otherGripper"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `component "otherGripper" not found`)
+}
+
+func TestMonitoredMove(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	monitor, err := newMoveMonitorConfig(nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, monitor, test.ShouldBeNil)
+	detectors := []interface{}{map[string]interface{}{"vision_service": "vis", "camera": "cam"}}
+	monitor, err = newMoveMonitorConfig(map[string]interface{}{
+		"obstacle_detectors": detectors, "obstacle_polling_frequency_hz": 50., "on_obstacle": "stop", "max_replans": 2.,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, monitor.obstacleDetectors, test.ShouldResemble, []motion.ObstacleDetectorName{
+		{VisionServiceName: vision.Named("vis"), CameraName: camera.Named("cam")},
+	})
+	test.That(t, monitor.pollingPeriod, test.ShouldEqual, 20*time.Millisecond)
+	test.That(t, monitor.stopOnObstacle, test.ShouldBeTrue)
+	test.That(t, monitor.maxReplans, test.ShouldEqual, 2)
+	_, err = newMoveMonitorConfig(map[string]interface{}{"obstacle_detectors": []interface{}{}})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = newMoveMonitorConfig(map[string]interface{}{
+		"obstacle_detectors": []interface{}{map[string]interface{}{"vision_service": "vis"}},
+	})
+	test.That(t, err, test.ShouldBeError, errors.New("obstacle detector is missing a camera"))
+	_, err = newMoveMonitorConfig(map[string]interface{}{"obstacle_detectors": detectors, "on_obstacle": "swerve"})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	armName := "test-arm"
+	armCfg := resource.Config{
+		Name:                armName,
+		API:                 arm.API,
+		Model:               resource.DefaultModelFamily.WithModel("ur5e"),
+		ConvertedAttributes: &armFake.Config{ArmModel: "ur5e"},
+		Frame:               &referenceframe.LinkConfig{Parent: "world"},
+	}
+	fakeArm, err := armFake.NewArm(ctx, nil, armCfg, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	var mu sync.Mutex
+	var obstacles []spatialmath.Geometry
+	stops := 0
+	setObstacles := func(geometries ...spatialmath.Geometry) {
+		mu.Lock()
+		defer mu.Unlock()
+		obstacles = geometries
+	}
+	stopCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return stops
+	}
+	arrive := make(chan struct{})
+	injectArm := &inject.Arm{Arm: fakeArm}
+	injectArm.GoToInputsFunc = func(ctx context.Context, inputSteps ...[]referenceframe.Input) error {
+		mu.Lock()
+		arrive := arrive
+		mu.Unlock()
+		// the arm moves until it arrives or is interrupted
+		select {
+		case <-arrive:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	injectArm.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		stops++
+		return nil
+	}
+	vis := inject.NewVisionService("vis")
+	vis.GetObjectPointCloudsFunc = func(ctx context.Context, cameraName string, extra map[string]interface{}) ([]*viz.Object, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		objects := []*viz.Object{}
+		for _, geometry := range obstacles {
+			object, err := viz.NewObjectWithLabel(pointcloud.New(), geometry.Label(), geometry.ToProtobuf())
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, object)
+		}
+		return objects, nil
+	}
+
+	fsParts := []*referenceframe.FrameSystemPart{
+		{
+			FrameConfig: referenceframe.NewLinkInFrame(referenceframe.World, spatialmath.NewZeroPose(), armName, nil),
+			ModelFrame:  fakeArm.ModelFrame(),
+		},
+		{FrameConfig: referenceframe.NewLinkInFrame(referenceframe.World, spatialmath.NewZeroPose(), "cam", nil)},
+	}
+	deps := resource.Dependencies{arm.Named(armName): injectArm, vision.Named("vis"): vis}
+	fsSvc, err := createFrameSystemService(ctx, deps, fsParts, logger)
+	test.That(t, err, test.ShouldBeNil)
+	frameSys, err := fsSvc.FrameSystem(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	msService, err := NewBuiltIn(ctx, deps, resource.Config{ConvertedAttributes: &Config{}}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	ms := msService.(*builtIn)
+	defer ms.Close(ctx)
+
+	// a plan swinging the arm around its base, as it would be planned
+	start := referenceframe.FloatsToInputs([]float64{0, 0, 0, 0, 0, 0})
+	goal := referenceframe.FloatsToInputs([]float64{math.Pi / 2, 0, 0, 0, 0, 0})
+	path := motionplan.Path{}
+	traj := motionplan.Trajectory{}
+	for _, inputs := range [][]referenceframe.Input{start, goal} {
+		tf, err := frameSys.Transform(
+			map[string][]referenceframe.Input{armName: inputs},
+			referenceframe.NewPoseInFrame(armName, spatialmath.NewZeroPose()),
+			referenceframe.World,
+		)
+		test.That(t, err, test.ShouldBeNil)
+		path = append(path, motionplan.PathStep{armName: tf.(*referenceframe.PoseInFrame)})
+		traj = append(traj, map[string][]referenceframe.Input{armName: inputs})
+	}
+	plan := motionplan.NewSimplePlan(path, traj)
+	goalPose := path[1][armName].Pose()
+
+	req := monitoredMoveReq{componentName: arm.Named(armName), monitor: monitor}
+	_, err = ms.newMonitoredMove(ctx, req, nil, 3)
+	test.That(t, err, test.ShouldBeError, errors.New("exceeded maximum number of replans: 2"))
+	newMonitoredMove := func(stopOnObstacle bool) *monitoredMove {
+		t.Helper()
+		req.monitor = &moveMonitorConfig{
+			obstacleDetectors: monitor.obstacleDetectors, pollingPeriod: 10 * time.Millisecond, stopOnObstacle: stopOnObstacle,
+		}
+		pe, err := ms.newMonitoredMove(ctx, req, nil, 0)
+		test.That(t, err, test.ShouldBeNil)
+		mm := pe.(*monitoredMove)
+		// as planned from where the arm is
+		mm.frameSys = frameSys
+		mm.resources = map[string]referenceframe.InputEnabled{armName: injectArm}
+		return mm
+	}
+
+	t.Run("nothing in the way", func(t *testing.T) {
+		mm := newMonitoredMove(false)
+		resp, err := mm.obstaclesIntersectPlan(ctx, plan)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp.Replan, test.ShouldBeFalse)
+
+		// an obstacle away from the path of the arm is no reason to replan
+		away, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(r3.Vector{X: 5000}), r3.Vector{100, 100, 100}, "away")
+		test.That(t, err, test.ShouldBeNil)
+		setObstacles(away)
+		resp, err = mm.obstaclesIntersectPlan(ctx, plan)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp.Replan, test.ShouldBeFalse)
+
+		close(arrive)
+		resp, err = mm.Execute(ctx, plan)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp.Replan, test.ShouldBeFalse)
+	})
+
+	inTheWay, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(goalPose.Point()), r3.Vector{100, 100, 100}, "person")
+	test.That(t, err, test.ShouldBeNil)
+
+	// from here on the arm does not arrive before the obstacles are seen
+	mu.Lock()
+	arrive = make(chan struct{})
+	mu.Unlock()
+
+	t.Run("an obstacle appearing in the way replans", func(t *testing.T) {
+		setObstacles()
+		mm := newMonitoredMove(false)
+		stopsBefore := stopCount()
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			setObstacles(inTheWay)
+		}()
+		resp, err := mm.Execute(ctx, plan)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp.Replan, test.ShouldBeTrue)
+		test.That(t, resp.ReplanReason, test.ShouldNotBeEmpty)
+		test.That(t, stopCount(), test.ShouldBeGreaterThan, stopsBefore)
+	})
+
+	t.Run("an obstacle in the way stops the move when asked to", func(t *testing.T) {
+		setObstacles(inTheWay)
+		mm := newMonitoredMove(true)
+		resp, err := mm.Execute(ctx, plan)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "stopped for an obstacle")
+		test.That(t, resp.Replan, test.ShouldBeFalse)
+	})
+
+	// the detections of a camera outside the frame system cannot be placed
+	setObstacles(inTheWay)
+	mm := newMonitoredMove(false)
+	mm.req.monitor.obstacleDetectors[0].CameraName = camera.Named("lost")
+	mm.detectors = map[vision.Service][]resource.Name{vis: {camera.Named("lost")}}
+	_, err = mm.detectObstacles(ctx, frameSys, referenceframe.StartPositions(frameSys))
+	test.That(t, err, test.ShouldNotBeNil)
+
+	delete(ms.visionServices, vision.Named("vis"))
+	_, err = ms.newMonitoredMove(ctx, req, nil, 0)
+	test.That(t, err, test.ShouldBeError, resource.DependencyNotFoundError(vision.Named("vis")))
+}
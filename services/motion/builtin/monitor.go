@@ -0,0 +1,415 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	servicepb "go.viam.com/api/service/motion/v1"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/services/motion/builtin/state"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/spatialmath"
+)
+
+const (
+	defaultMonitorPollingHz = 10.
+	monitorStatusInterval   = 20 * time.Millisecond
+	onObstacleReplan        = "replan"
+	onObstacleStop          = "stop"
+)
+
+// moveMonitorConfig is how a Move is monitored for obstacles while it is executed, from the extra of the Move.
+type moveMonitorConfig struct {
+	obstacleDetectors []motion.ObstacleDetectorName
+	pollingPeriod     time.Duration
+	stopOnObstacle    bool
+	maxReplans        int
+}
+
+// newMoveMonitorConfig reads how to monitor a Move from its extra, returning nil when the Move names no obstacle
+// detectors and so is not monitored. Obstacle detectors are given as "obstacle_detectors", a list of objects with a
+// "vision_service" and a "camera", and are polled at "obstacle_polling_frequency_hz", 10Hz by default. "on_obstacle"
+// is "replan", the default, to replan the rest of the move around an obstacle found in its way, or "stop" to stop it.
+func newMoveMonitorConfig(extra map[string]interface{}) (*moveMonitorConfig, error) {
+	detectorsRaw, ok := extra["obstacle_detectors"]
+	if !ok {
+		return nil, nil
+	}
+	detectors, ok := detectorsRaw.([]interface{})
+	if !ok {
+		return nil, errors.New("could not interpret obstacle_detectors field as a list")
+	}
+	conf := &moveMonitorConfig{
+		pollingPeriod: time.Duration(float64(time.Second) / defaultMonitorPollingHz),
+		maxReplans:    defaultMaxReplans,
+	}
+	switch replans := extra["max_replans"].(type) {
+	case int:
+		conf.maxReplans = replans
+	case float64:
+		// numbers in the extra of a request over the API are floats
+		conf.maxReplans = int(replans)
+	}
+	for _, detectorRaw := range detectors {
+		detector, ok := detectorRaw.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("each obstacle detector must have a vision_service and a camera")
+		}
+		visionName, ok := detector["vision_service"].(string)
+		if !ok || visionName == "" {
+			return nil, errors.New("obstacle detector is missing a vision_service")
+		}
+		cameraName, ok := detector["camera"].(string)
+		if !ok || cameraName == "" {
+			return nil, errors.New("obstacle detector is missing a camera")
+		}
+		conf.obstacleDetectors = append(conf.obstacleDetectors, motion.ObstacleDetectorName{
+			VisionServiceName: vision.Named(visionName),
+			CameraName:        camera.Named(cameraName),
+		})
+	}
+	if len(conf.obstacleDetectors) == 0 {
+		return nil, errors.New("obstacle_detectors cannot be empty")
+	}
+	if hzRaw, ok := extra["obstacle_polling_frequency_hz"]; ok {
+		hz, ok := hzRaw.(float64)
+		if !ok || hz <= 0 {
+			return nil, errors.New("obstacle_polling_frequency_hz must be a positive number")
+		}
+		conf.pollingPeriod = time.Duration(float64(time.Second) / hz)
+	}
+	if onObstacleRaw, ok := extra["on_obstacle"]; ok {
+		switch onObstacleRaw {
+		case onObstacleReplan:
+		case onObstacleStop:
+			conf.stopOnObstacle = true
+		default:
+			return nil, errors.Errorf("on_obstacle must be %q or %q", onObstacleReplan, onObstacleStop)
+		}
+	}
+	return conf, nil
+}
+
+// monitoredMoveReq is a Move which is executed as an execution of the state, so its replans appear in its plan
+// history and it can be stopped with StopPlan.
+type monitoredMoveReq struct {
+	componentName resource.Name
+	destination   *referenceframe.PoseInFrame
+	worldState    *referenceframe.WorldState
+	constraints   *servicepb.Constraints
+	extra         map[string]interface{}
+	monitor       *moveMonitorConfig
+}
+
+// moveMonitored executes a Move while polling its obstacle detectors, and waits for it to finish.
+func (ms *builtIn) moveMonitored(ctx context.Context, req monitoredMoveReq) (bool, error) {
+	// a monitored move starts from where the arms stop, as it plans again from where they are
+	ms.finishBlendedMotions(true)
+	id, err := state.StartExecution(ctx, ms.state, req.componentName, req, ms.newMonitoredMove)
+	if err != nil {
+		return false, err
+	}
+
+	ticker := time.NewTicker(monitorStatusInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := ms.state.StopExecutionByResource(req.componentName); err != nil {
+				ms.logger.CWarnw(ctx, "cannot stop the monitored move", "error", err)
+			}
+			return false, ctx.Err()
+		case <-ticker.C:
+		}
+		history, err := ms.state.PlanHistory(motion.PlanHistoryReq{
+			ComponentName: req.componentName,
+			ExecutionID:   id,
+			LastPlanOnly:  true,
+		})
+		if err != nil {
+			return false, err
+		}
+		status := history[0].StatusHistory[0]
+		switch status.State {
+		case motion.PlanStateInProgress:
+		case motion.PlanStateSucceeded:
+			return true, nil
+		case motion.PlanStateStopped:
+			return false, errors.New("plan stopped")
+		case motion.PlanStateFailed:
+			err := errors.New("plan failed")
+			if status.Reason != nil {
+				err = errors.Wrap(err, *status.Reason)
+			}
+			return false, err
+		default:
+			return false, fmt.Errorf("invalid plan state %d", status.State)
+		}
+	}
+}
+
+// monitoredMove plans a Move around the obstacles its detectors see, and executes it while checking the rest of the
+// plan against what they see, asking to replan, or stopping, when something is in the way.
+type monitoredMove struct {
+	ms        *builtIn
+	req       monitoredMoveReq
+	detectors map[vision.Service][]resource.Name
+
+	// set by Plan
+	frameSys  referenceframe.FrameSystem
+	resources map[string]referenceframe.InputEnabled
+	// index is the step of the plan being moved to
+	index atomic.Int64
+}
+
+func (ms *builtIn) newMonitoredMove(
+	ctx context.Context,
+	req monitoredMoveReq,
+	seedPlan motionplan.Plan,
+	replanCount int,
+) (state.PlannerExecutor, error) {
+	if req.monitor.maxReplans >= 0 && replanCount > req.monitor.maxReplans {
+		return nil, fmt.Errorf("exceeded maximum number of replans: %d", req.monitor.maxReplans)
+	}
+	detectors := make(map[vision.Service][]resource.Name)
+	for _, detector := range req.monitor.obstacleDetectors {
+		visionSvc, ok := ms.visionServices[detector.VisionServiceName]
+		if !ok {
+			return nil, resource.DependencyNotFoundError(detector.VisionServiceName)
+		}
+		detectors[visionSvc] = append(detectors[visionSvc], detector.CameraName)
+	}
+	return &monitoredMove{ms: ms, req: req, detectors: detectors}, nil
+}
+
+// Plan plans from where the components are now, so a replan only covers the rest of the move.
+func (mm *monitoredMove) Plan(ctx context.Context) (motionplan.Plan, error) {
+	frameSys, err := mm.ms.fsService.FrameSystem(ctx, mm.req.worldState.Transforms())
+	if err != nil {
+		return nil, err
+	}
+	fsInputs, resources, err := mm.ms.fsService.CurrentInputs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	movingFrame := frameSys.Frame(mm.req.componentName.ShortName())
+	if movingFrame == nil {
+		return nil, fmt.Errorf("component named %s not found in robot frame system", mm.req.componentName.ShortName())
+	}
+	tf, err := frameSys.Transform(fsInputs, mm.req.destination, referenceframe.World)
+	if err != nil {
+		return nil, err
+	}
+	goalPose, _ := tf.(*referenceframe.PoseInFrame)
+
+	// the plan avoids what the detectors see now as well as the obstacles of the request
+	detected, err := mm.detectObstacles(ctx, frameSys, fsInputs)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := mm.req.worldState.ObstaclesInWorldFrame(frameSys, fsInputs)
+	if err != nil {
+		return nil, err
+	}
+	worldState, err := referenceframe.NewWorldState([]*referenceframe.GeometriesInFrame{existing, detected}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if worldState, err = worldState.WithMovingObstacles(mm.req.worldState.MovingObstacles()...); err != nil {
+		return nil, err
+	}
+
+	mm.frameSys = frameSys
+	mm.resources = resources
+	return motionplan.PlanMotion(ctx, &motionplan.PlanRequest{
+		Logger:             mm.ms.logger,
+		Goal:               goalPose,
+		Frame:              movingFrame,
+		StartConfiguration: fsInputs,
+		FrameSystem:        frameSys,
+		WorldState:         worldState,
+		ConstraintSpecs:    mm.req.constraints,
+		Options:            mm.req.extra,
+	})
+}
+
+// Execute moves the components through the plan, checking the rest of it against the obstacles seen as they move.
+func (mm *monitoredMove) Execute(ctx context.Context, plan motionplan.Plan) (state.ExecuteResponse, error) {
+	var recorders []*trajectoryRecorder
+	if executionID, ok := state.ExecutionIDFromContext(ctx); ok {
+		for _, name := range movingFrames(plan.Trajectory()) {
+			if r, ok := mm.resources[name]; ok {
+				recorders = append(recorders, mm.ms.recordJoints(executionID, name, r, plan.Trajectory()))
+			}
+		}
+	}
+
+	cancelCtx, cancelFn := context.WithCancel(ctx)
+	var workers sync.WaitGroup
+	obstacle := newReplanner(mm.req.monitor.pollingPeriod, mm.obstaclesIntersectPlan)
+	executed := make(chan error, 1)
+	workers.Add(2)
+	goutils.ManagedGo(func() {
+		obstacle.startPolling(cancelCtx, plan)
+	}, workers.Done)
+	goutils.ManagedGo(func() {
+		executed <- mm.execute(cancelCtx, plan)
+	}, workers.Done)
+
+	var resp state.ExecuteResponse
+	var err error
+	select {
+	case err = <-executed:
+	case replan := <-obstacle.responseChan:
+		resp, err = replan.executeResponse, replan.err
+	}
+	cancelFn()
+	workers.Wait()
+
+	if resp.Replan {
+		// the components are stopped where the obstacle was seen, to either replan from there or stay stopped
+		mm.stop(ctx, plan)
+		mm.ms.logger.CInfow(ctx, "obstacle in the way of the move", "reason", resp.ReplanReason)
+		if mm.req.monitor.stopOnObstacle {
+			resp, err = state.ExecuteResponse{}, errors.Errorf("stopped for an obstacle: %s", resp.ReplanReason)
+		}
+	}
+	for _, recorder := range recorders {
+		recordErr := err
+		if resp.Replan {
+			recordErr = errors.Errorf("replanning: %s", resp.ReplanReason)
+		}
+		recorder.finish(recordErr)
+	}
+	return resp, err
+}
+
+func (mm *monitoredMove) AnchorGeoPose() *spatialmath.GeoPose {
+	return nil
+}
+
+// execute moves the components to each step of the plan in turn.
+func (mm *monitoredMove) execute(ctx context.Context, plan motionplan.Plan) error {
+	for i, step := range plan.Trajectory() {
+		mm.index.Store(int64(i))
+		for name, inputs := range step {
+			if len(inputs) == 0 {
+				continue
+			}
+			r := mm.resources[name]
+			if err := r.GoToInputs(ctx, inputs); err != nil {
+				// If there is an error on GoToInputs, stop the component if possible before returning the error
+				if actuator, ok := r.(inputEnabledActuator); ok {
+					if stopErr := actuator.Stop(context.Background(), nil); stopErr != nil {
+						return errors.Wrap(err, stopErr.Error())
+					}
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// stop stops the components moved by the plan.
+func (mm *monitoredMove) stop(ctx context.Context, plan motionplan.Plan) {
+	for _, name := range movingFrames(plan.Trajectory()) {
+		if actuator, ok := mm.resources[name].(inputEnabledActuator); ok {
+			if err := actuator.Stop(ctx, nil); err != nil {
+				mm.ms.logger.CErrorw(ctx, "cannot stop component for an obstacle", "component", name, "error", err)
+			}
+		}
+	}
+}
+
+// obstaclesIntersectPlan checks the rest of the plan, from the step last reached, against what the obstacle
+// detectors see now, asking to replan when it collides with any of it.
+func (mm *monitoredMove) obstaclesIntersectPlan(ctx context.Context, plan motionplan.Plan) (state.ExecuteResponse, error) {
+	fsInputs, _, err := mm.ms.fsService.CurrentInputs(ctx)
+	if err != nil {
+		return state.ExecuteResponse{}, err
+	}
+	detected, err := mm.detectObstacles(ctx, mm.frameSys, fsInputs)
+	if err != nil {
+		return state.ExecuteResponse{}, err
+	}
+	if len(detected.Geometries()) == 0 {
+		return state.ExecuteResponse{}, nil
+	}
+	// the obstacles of the request were avoided by the plan, so only those seen now are checked
+	worldState, err := referenceframe.NewWorldState([]*referenceframe.GeometriesInFrame{detected}, nil)
+	if err != nil {
+		return state.ExecuteResponse{}, err
+	}
+
+	name := mm.req.componentName.ShortName()
+	tf, err := mm.frameSys.Transform(fsInputs, referenceframe.NewPoseInFrame(name, spatialmath.NewZeroPose()), referenceframe.World)
+	if err != nil {
+		return state.ExecuteResponse{}, err
+	}
+	currentPose, _ := tf.(*referenceframe.PoseInFrame)
+	index := int(mm.index.Load())
+	if index > 0 {
+		// the components are somewhere between the step last reached and the one they are moving to
+		index--
+	}
+	executionState, err := motionplan.NewExecutionState(plan, index, fsInputs, map[string]*referenceframe.PoseInFrame{name: currentPose})
+	if err != nil {
+		return state.ExecuteResponse{}, err
+	}
+	if err := motionplan.CheckPlan(
+		mm.frameSys.Frame(name), executionState, worldState, mm.frameSys, lookAheadDistanceMM, mm.ms.logger,
+	); err != nil {
+		return state.ExecuteResponse{Replan: true, ReplanReason: err.Error()}, nil
+	}
+	return state.ExecuteResponse{}, nil
+}
+
+// detectObstacles returns the geometries seen by the obstacle detectors, in the world frame.
+func (mm *monitoredMove) detectObstacles(
+	ctx context.Context,
+	frameSys referenceframe.FrameSystem,
+	inputs map[string][]referenceframe.Input,
+) (*referenceframe.GeometriesInFrame, error) {
+	geometries := []spatialmath.Geometry{}
+	for visionSvc, cameraNames := range mm.detectors {
+		for _, cameraName := range cameraNames {
+			detections, err := visionSvc.GetObjectPointClouds(ctx, cameraName.Name, nil)
+			if err != nil {
+				return nil, err
+			}
+			if len(detections) == 0 {
+				continue
+			}
+			tf, err := frameSys.Transform(
+				inputs, referenceframe.NewPoseInFrame(cameraName.ShortName(), spatialmath.NewZeroPose()), referenceframe.World,
+			)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot place the detections of camera %q", cameraName.ShortName())
+			}
+			cameraPose, _ := tf.(*referenceframe.PoseInFrame)
+			for i, detection := range detections {
+				geometry := detection.Geometry
+				// update the label of the geometry so we know it is transient
+				label := cameraName.ShortName() + "_transientObstacle_" + strconv.Itoa(i)
+				if geometry.Label() != "" {
+					label += "_" + geometry.Label()
+				}
+				geometry.SetLabel(label)
+				geometries = append(geometries, geometry.Transform(cameraPose.Pose()))
+			}
+		}
+	}
+	return referenceframe.NewGeometriesInFrame(referenceframe.World, geometries), nil
+}
@@ -287,6 +287,35 @@ func TestMoveOnGlobe(t *testing.T) {
 		test.That(t, planResp, test.ShouldBeNil)
 	})
 
+	t.Run("a requested heading is reflected in the goal orientation", func(t *testing.T) {
+		injectedMovementSensor, _, fakeBase, ms := createMoveOnGlobeEnvironment(ctx, t, gpsPoint, nil, 5)
+		defer ms.Close(ctx)
+
+		req := motion.MoveOnGlobeReq{
+			ComponentName:      fakeBase.Name(),
+			Destination:        dst,
+			Heading:            90,
+			MovementSensorName: injectedMovementSensor.Name(),
+			Extra:              map[string]interface{}{"smooth_iter": 5.},
+		}
+		planExecutor, err := ms.(*builtIn).newMoveOnGlobeRequest(ctx, req, nil, 0)
+		test.That(t, err, test.ShouldBeNil)
+		mr, ok := planExecutor.(*moveRequest)
+		test.That(t, ok, test.ShouldBeTrue)
+		goal := mr.planRequest.Goal.Pose()
+		test.That(t, spatialmath.OrientationAlmostEqual(goal.Orientation(), headingToOrientation(90)), test.ShouldBeTrue)
+		test.That(t, goal.Point().X, test.ShouldAlmostEqual, expectedDst.X, epsilonMM)
+
+		// position_only disregards orientation so the heading must not constrain the goal
+		req.Extra = extra
+		planExecutor, err = ms.(*builtIn).newMoveOnGlobeRequest(ctx, req, nil, 0)
+		test.That(t, err, test.ShouldBeNil)
+		mr, ok = planExecutor.(*moveRequest)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, spatialmath.OrientationAlmostEqual(mr.planRequest.Goal.Pose().Orientation(), spatialmath.NewZeroOrientation()),
+			test.ShouldBeTrue)
+	})
+
 	t.Run("check offset constructed correctly", func(t *testing.T) {
 		_, fsSvc, _, ms := createMoveOnGlobeEnvironment(ctx, t, gpsPoint, nil, 5)
 		defer ms.Close(ctx)
@@ -298,3 +327,22 @@ func TestMoveOnGlobe(t *testing.T) {
 		test.That(t, movementSensorToBase.Pose().Point(), test.ShouldResemble, r3.Vector{X: 10, Y: 0, Z: 0})
 	})
 }
+
+func TestHeadingToOrientation(t *testing.T) {
+	for _, tc := range []struct {
+		heading float64
+		theta   float64
+	}{
+		{0, 0},
+		{90, 270},
+		{180, 180},
+		{270, 90},
+		{360, 0},
+		{-90, 90},
+		{450, 270},
+	} {
+		o := headingToOrientation(tc.heading).OrientationVectorDegrees()
+		test.That(t, o.OZ, test.ShouldAlmostEqual, 1)
+		test.That(t, math.Mod(o.Theta+360, 360), test.ShouldAlmostEqual, tc.theta)
+	}
+}
@@ -70,6 +70,7 @@ type moveRequest struct {
 
 	executeBackgroundWorkers *sync.WaitGroup
 	responseChan             chan moveResponse
+	trajectories             *trajectoryLog
 	// replanners for the move request
 	// if we ever have to add additional instances we should figure out how to make this more scalable
 	position, obstacle *replanner
@@ -122,8 +123,43 @@ func (mr *moveRequest) Execute(ctx context.Context, plan motionplan.Plan) (state
 	cancelCtx, cancelFn := context.WithCancel(ctx)
 	defer cancelFn()
 
+	recorder := mr.recordBase(ctx, plan)
 	mr.start(cancelCtx, plan)
-	return mr.listen(cancelCtx)
+	resp, err := mr.listen(cancelCtx)
+	if recorder != nil {
+		recordErr := err
+		if resp.Replan {
+			recordErr = errors.Errorf("replanning: %s", resp.ReplanReason)
+		}
+		recorder.finish(recordErr)
+	}
+	return resp, err
+}
+
+// recordBase starts recording the base following the path of a plan, unless it is not part of an execution.
+func (mr *moveRequest) recordBase(ctx context.Context, plan motionplan.Plan) *trajectoryRecorder {
+	executionID, ok := state.ExecutionIDFromContext(ctx)
+	if !ok || mr.trajectories == nil {
+		return nil
+	}
+	name := mr.kinematicBase.Name().ShortName()
+	poses, err := plan.Path().GetFramePoses(name)
+	if err != nil {
+		mr.logger.CDebugf(ctx, "not recording the trajectory of %s: %s", name, err)
+		return nil
+	}
+	commanded := make([][]float64, 0, len(poses))
+	for _, pose := range poses {
+		commanded = append(commanded, []float64{pose.Point().X, pose.Point().Y})
+	}
+	return mr.trajectories.record(name, executionID, motion.TrajectoryKindBase, commanded,
+		func(ctx context.Context) ([]float64, error) {
+			pif, err := mr.kinematicBase.CurrentPosition(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return []float64{pif.Pose().Point().X, pif.Pose().Point().Y}, nil
+		})
 }
 
 func (mr *moveRequest) AnchorGeoPose() *spatialmath.GeoPose {
@@ -329,6 +365,18 @@ func kbOptionsFromCfg(motionCfg *validatedMotionConfiguration, validatedExtra va
 	return kinematicsOptions
 }
 
+// headingToOrientation converts a left-handed compass heading in degrees (N: 0, E: 90) into a right-handed orientation
+// about +Z in the planning frame, where +Y points north.
+// Headings outside of [0, 360) are wrapped into that range.
+func headingToOrientation(heading float64) spatialmath.Orientation {
+	heading = math.Mod(heading, 360)
+	if heading < 0 {
+		heading += 360
+	}
+	// Use math.Mod to ensure that a heading of 0 reports 0 rather than 360.
+	return &spatialmath.OrientationVectorDegrees{OZ: 1, Theta: math.Mod(360-heading, 360)}
+}
+
 func validateNotNan(f float64, name string) error {
 	if math.IsNaN(f) {
 		return errors.Errorf("%s may not be NaN", name)
@@ -502,7 +550,14 @@ func (ms *builtIn) newMoveOnGlobeRequest(
 	// Important: GeoPointToPose will create a pose such that incrementing latitude towards north increments +Y, and incrementing
 	// longitude towards east increments +X. Heading is not taken into account. This pose must therefore be transformed based on the
 	// orientation of the base such that it is a pose relative to the base's current location.
-	goalPoseRaw := spatialmath.NewPoseFromPoint(spatialmath.GeoPointToPoint(req.Destination, origin))
+	// The goal and obstacles are placed on the plane tangent to the WGS84 ellipsoid at the origin, like the localizer's positions.
+	plane := spatialmath.NewLocalTangentPlane(origin, 0)
+	goalPoseRaw := spatialmath.NewPoseFromPoint(plane.GeoPointToPlanePoint(req.Destination))
+	// If a heading was requested the goal must also carry the corresponding orientation so that the base finishes facing it.
+	// The position_only motion profile disregards orientation entirely, so the heading is ignored in that case.
+	if !math.IsNaN(req.Heading) && valExtra.motionProfile != motionplan.PositionOnlyMotionProfile {
+		goalPoseRaw = spatialmath.NewPose(goalPoseRaw.Point(), headingToOrientation(req.Heading))
+	}
 	// construct limits
 	straightlineDistance := goalPoseRaw.Point().Norm()
 	if straightlineDistance > maxTravelDistanceMM {
@@ -518,7 +573,7 @@ func (ms *builtIn) newMoveOnGlobeRequest(
 		return nil, err
 	}
 
-	geomsRaw := spatialmath.GeoGeometriesToGeometries(obstacles, origin)
+	geomsRaw := plane.GeoGeometriesToGeometries(obstacles)
 
 	mr, err := ms.createBaseMoveRequest(
 		ctx,
@@ -750,6 +805,7 @@ func (ms *builtIn) createBaseMoveRequest(
 		executeBackgroundWorkers: &backgroundWorkers,
 
 		responseChan: make(chan moveResponse, 1),
+		trajectories: ms.trajectories,
 	}
 
 	// TODO: Change deviatedFromPlan to just query positionPollingFreq on the struct & the same for the obstaclesIntersectPlan
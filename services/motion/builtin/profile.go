@@ -0,0 +1,52 @@
+package builtin
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/motion"
+)
+
+// motionProfile generates the poses of a motion profile and, when asked to, moves the component through them one
+// move at a time, stopping at the first which fails.
+func (ms *builtIn) motionProfile(ctx context.Context, req motion.MotionProfileReq) (motion.MotionProfileResp, error) {
+	frame := req.Frame
+	if frame == "" {
+		frame = referenceframe.World
+	}
+	poses := req.Poses()
+	resp := motion.MotionProfileResp{Frame: frame, Poses: make([]motion.ProfilePose, 0, len(poses))}
+	for _, pose := range poses {
+		resp.Poses = append(resp.Poses, motion.NewProfilePose(pose))
+	}
+	if !req.Execute {
+		return resp, nil
+	}
+
+	componentName, err := ms.componentNamed(req.ComponentName)
+	if err != nil {
+		return motion.MotionProfileResp{}, err
+	}
+	for i, pose := range poses {
+		if _, err := ms.Move(ctx, componentName, referenceframe.NewPoseInFrame(frame, pose), nil, nil, req.Extra); err != nil {
+			return motion.MotionProfileResp{}, errors.Wrapf(err, "failed to move to pose %d of %d of the motion profile", i+1, len(poses))
+		}
+		resp.Executed++
+	}
+	return resp, nil
+}
+
+// componentNamed returns the resource name of the component with a short name.
+func (ms *builtIn) componentNamed(shortName string) (resource.Name, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	for name := range ms.components {
+		if name.ShortName() == shortName {
+			return name, nil
+		}
+	}
+	return resource.Name{}, errors.Errorf("component %q not found", shortName)
+}
@@ -53,6 +53,15 @@ type PlannerExecutorConstructor[R any] func(
 	replanCount int,
 ) (PlannerExecutor, error)
 
+type executionIDKey struct{}
+
+// ExecutionIDFromContext returns the ID of the execution a PlannerExecutor was created for, from the context its
+// constructor and Execute are called with.
+func ExecutionIDFromContext(ctx context.Context) (motion.ExecutionID, bool) {
+	id, ok := ctx.Value(executionIDKey{}).(motion.ExecutionID)
+	return id, ok
+}
+
 type componentState struct {
 	executionIDHistory []motion.ExecutionID
 	executionsByID     map[motion.ExecutionID]stateExecution
@@ -368,9 +377,10 @@ func StartExecution[R any](
 	}
 
 	// the state being cancelled should cause all executions derived from that state to also be cancelled
-	cancelCtx, cancelFunc := context.WithCancel(s.cancelCtx)
+	id := uuid.New()
+	cancelCtx, cancelFunc := context.WithCancel(context.WithValue(s.cancelCtx, executionIDKey{}, id))
 	e := execution[R]{
-		id:                         uuid.New(),
+		id:                         id,
 		state:                      s,
 		cancelCtx:                  cancelCtx,
 		cancelFunc:                 cancelFunc,
@@ -0,0 +1,192 @@
+package builtin
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/services/motion"
+)
+
+const (
+	// trajectorySampleInterval is how often the position of a component is measured while it follows a trajectory.
+	trajectorySampleInterval = 50 * time.Millisecond
+	// finalSampleTimeout bounds the measurement of where a component ended up once its trajectory is over.
+	finalSampleTimeout = time.Second
+	// maxTrajectoryRecordings is how many recordings are kept for each component, the oldest being dropped first.
+	maxTrajectoryRecordings = 20
+)
+
+// trajectoryLog keeps the trajectory recordings of the latest executions of each component, newest first.
+type trajectoryLog struct {
+	mu          sync.Mutex
+	byComponent map[string][]*trajectoryRecorder
+}
+
+// A trajectoryRecorder measures the position of a component in the background while it follows a trajectory.
+type trajectoryRecorder struct {
+	log     *trajectoryLog
+	rec     *motion.TrajectoryRecording
+	started time.Time
+	measure func(ctx context.Context) ([]float64, error)
+	cancel  func()
+	done    chan struct{}
+}
+
+// record starts recording a component following the commanded path, measuring its position with measure until the
+// recording is finished.
+func (l *trajectoryLog) record(
+	componentName string,
+	executionID motion.ExecutionID,
+	kind string,
+	commanded [][]float64,
+	measure func(ctx context.Context) ([]float64, error),
+) *trajectoryRecorder {
+	started := time.Now()
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &trajectoryRecorder{
+		log: l,
+		rec: &motion.TrajectoryRecording{
+			ComponentName: componentName,
+			ExecutionID:   executionID.String(),
+			Kind:          kind,
+			Started:       started.Format(time.RFC3339Nano),
+			Commanded:     commanded,
+		},
+		started: started,
+		measure: measure,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	utils.PanicCapturingGo(func() {
+		defer close(r.done)
+		ticker := time.NewTicker(trajectorySampleInterval)
+		defer ticker.Stop()
+		for {
+			r.sample(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+	return r
+}
+
+// sample measures the position of the component, skipping measurements which fail.
+func (r *trajectoryRecorder) sample(ctx context.Context) {
+	values, err := r.measure(ctx)
+	if err != nil {
+		return
+	}
+	r.rec.Measured = append(r.rec.Measured, motion.TrajectorySample{
+		ElapsedSec: time.Since(r.started).Seconds(),
+		Values:     values,
+	})
+}
+
+// finish stops the recording once the trajectory is over, with the error the execution failed with if any, and adds
+// it to the log.
+func (r *trajectoryRecorder) finish(err error) {
+	r.cancel()
+	<-r.done
+	ctx, cancel := context.WithTimeout(context.Background(), finalSampleTimeout)
+	defer cancel()
+	r.sample(ctx)
+	r.rec.DurationSec = time.Since(r.started).Seconds()
+	if err != nil {
+		r.rec.Error = err.Error()
+	}
+
+	r.log.mu.Lock()
+	defer r.log.mu.Unlock()
+	if r.log.byComponent == nil {
+		r.log.byComponent = map[string][]*trajectoryRecorder{}
+	}
+	recs := append([]*trajectoryRecorder{r}, r.log.byComponent[r.rec.ComponentName]...)
+	if len(recs) > maxTrajectoryRecordings {
+		recs = recs[:maxTrajectoryRecordings]
+	}
+	r.log.byComponent[r.rec.ComponentName] = recs
+}
+
+// reports returns the scored recordings selected by a request, newest first.
+func (l *trajectoryLog) reports(req motion.TrajectoryReportsReq) []motion.TrajectoryReport {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var selected []*trajectoryRecorder
+	for name, recs := range l.byComponent {
+		if req.ComponentName != "" && name != req.ComponentName {
+			continue
+		}
+		for _, r := range recs {
+			if req.ExecutionID == "" || r.rec.ExecutionID == req.ExecutionID {
+				selected = append(selected, r)
+			}
+		}
+	}
+	slices.SortStableFunc(selected, func(a, b *trajectoryRecorder) int {
+		return b.started.Compare(a.started)
+	})
+
+	reports := make([]motion.TrajectoryReport, 0, len(selected))
+	for _, r := range selected {
+		report := motion.TrajectoryReport{Recording: *r.rec, Score: r.rec.Score()}
+		if !req.IncludeSamples {
+			report.Recording.Commanded = nil
+			report.Recording.Measured = nil
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+func (ms *builtIn) trajectoryReports(ctx context.Context, req motion.TrajectoryReportsReq) (motion.TrajectoryReportsResp, error) {
+	return motion.TrajectoryReportsResp{Reports: ms.trajectories.reports(req)}, nil
+}
+
+// recordJoints starts recording the joints of a component following its part of a trajectory.
+func (ms *builtIn) recordJoints(
+	executionID motion.ExecutionID,
+	name string,
+	component referenceframe.InputEnabled,
+	traj motionplan.Trajectory,
+) *trajectoryRecorder {
+	var commanded [][]float64
+	for _, step := range traj {
+		if inputs, ok := step[name]; ok {
+			commanded = append(commanded, referenceframe.InputsToFloats(inputs))
+		}
+	}
+	return ms.trajectories.record(name, executionID, motion.TrajectoryKindJoints, commanded,
+		func(ctx context.Context) ([]float64, error) {
+			inputs, err := component.CurrentInputs(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return referenceframe.InputsToFloats(inputs), nil
+		})
+}
+
+// movingFrames returns the frames whose inputs change over a trajectory.
+func movingFrames(traj motionplan.Trajectory) []string {
+	if len(traj) == 0 {
+		return nil
+	}
+	var moving []string
+	for name, start := range traj[0] {
+		for _, step := range traj[1:] {
+			if referenceframe.InputsL2Distance(start, step[name]) > 0 {
+				moving = append(moving, name)
+				break
+			}
+		}
+	}
+	return moving
+}
@@ -49,7 +49,7 @@ func (s *slamLocalizer) CurrentPosition(ctx context.Context) (*referenceframe.Po
 // movementSensorLocalizer is a struct which only wraps an existing movementsensor.
 type movementSensorLocalizer struct {
 	movementsensor.MovementSensor
-	origin      *geo.Point
+	origin      *spatialmath.LocalTangentPlane
 	calibration spatialmath.Pose
 }
 
@@ -57,7 +57,11 @@ type movementSensorLocalizer struct {
 // An origin point must be specified and the localizer will return Poses relative to this point.
 // A calibration pose can also be specified, which will adjust the location after it is calculated relative to the origin.
 func NewMovementSensorLocalizer(ms movementsensor.MovementSensor, origin *geo.Point, calibration spatialmath.Pose) Localizer {
-	return &movementSensorLocalizer{MovementSensor: ms, origin: origin, calibration: calibration}
+	return &movementSensorLocalizer{
+		MovementSensor: ms,
+		origin:         spatialmath.NewLocalTangentPlane(origin, 0),
+		calibration:    calibration,
+	}
 }
 
 // CurrentPosition returns a movementsensor's current position.
@@ -89,7 +93,7 @@ func (m *movementSensorLocalizer) CurrentPosition(ctx context.Context) (*referen
 		return nil, errors.New("could not get orientation from Localizer")
 	}
 
-	pose := spatialmath.NewPose(spatialmath.GeoPointToPoint(gp, m.origin), o)
+	pose := spatialmath.NewPose(m.origin.GeoPointToPlanePoint(gp), o)
 	return referenceframe.NewPoseInFrame(referenceframe.World, spatialmath.Compose(pose, m.calibration)), nil
 }
 
@@ -49,6 +49,7 @@ type MoveOnGlobeReq struct {
 	Destination *geo.Point
 	// Heading the component should have a when it reaches the goal.
 	// Range [0-360] Left Hand Rule (N: 0, E: 90, S: 180, W: 270)
+	// NaN indicates that no particular heading is requested.
 	Heading float64
 	// Name of the momement sensor which can be used to derive Position & Heading
 	MovementSensorName resource.Name
@@ -0,0 +1,296 @@
+package motion
+
+import (
+	"context"
+	"math"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	commonpb "go.viam.com/api/common/v1"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// MotionProfileCommand is the DoCommand of a motion service which generates the poses of a motion profile, and
+// optionally moves a component through them, with a MotionProfileReq as its payload and a MotionProfileResp as its
+// response.
+const MotionProfileCommand = "motion_profile"
+
+// MaxProfilePoses is the most poses a motion profile can generate.
+const MaxProfilePoses = 10000
+
+// The patterns of a PalletProfile.
+const (
+	// PalletPatternColumn stacks each item straight above the item below it.
+	PalletPatternColumn = "column"
+	// PalletPatternBrick shifts every other layer by half a column, with one column fewer, so that each item rests
+	// across two items below it.
+	PalletPatternBrick = "brick"
+)
+
+// ProfilePose is a pose of a motion profile, as the fields of a protobuf Pose: a position in millimeters and an
+// orientation vector with its angle in degrees.
+type ProfilePose struct {
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Z     float64 `json:"z"`
+	OX    float64 `json:"o_x"`
+	OY    float64 `json:"o_y"`
+	OZ    float64 `json:"o_z"`
+	Theta float64 `json:"theta"`
+}
+
+// NewProfilePose returns the ProfilePose of a pose.
+func NewProfilePose(pose spatialmath.Pose) ProfilePose {
+	pb := spatialmath.PoseToProtobuf(pose)
+	return ProfilePose{X: pb.X, Y: pb.Y, Z: pb.Z, OX: pb.OX, OY: pb.OY, OZ: pb.OZ, Theta: pb.Theta}
+}
+
+// Pose returns the pose, pointing along the z axis when no orientation vector is given.
+func (p ProfilePose) Pose() spatialmath.Pose {
+	if p.OX == 0 && p.OY == 0 && p.OZ == 0 {
+		p.OZ = 1
+	}
+	return spatialmath.NewPoseFromProtobuf(&commonpb.Pose{X: p.X, Y: p.Y, Z: p.Z, OX: p.OX, OY: p.OY, OZ: p.OZ, Theta: p.Theta})
+}
+
+// GridProfile scans a rectangular region of the x-y plane of its frame row by row, for inspecting or covering a
+// surface. Rows run along the x axis and follow each other along the y axis.
+type GridProfile struct {
+	// Origin is the corner of the region, whose orientation every pose of the scan has.
+	Origin   ProfilePose `json:"origin"`
+	WidthMM  float64     `json:"width_mm"`
+	LengthMM float64     `json:"length_mm"`
+	StepMM   float64     `json:"step_mm"`
+	// Raster starts every row at the same side of the region, rather than turning back at the end of each row.
+	Raster bool `json:"raster,omitempty"`
+}
+
+// Validate ensures the region can be scanned.
+func (p *GridProfile) Validate() error {
+	if p.WidthMM < 0 || p.LengthMM < 0 {
+		return errors.New("grid width_mm and length_mm cannot be negative")
+	}
+	if p.StepMM <= 0 {
+		return errors.New("grid step_mm must be positive")
+	}
+	return checkProfileSize((math.Floor(p.WidthMM/p.StepMM) + 1) * (math.Floor(p.LengthMM/p.StepMM) + 1))
+}
+
+// Poses returns the poses of the scan in order.
+func (p *GridProfile) Poses() []spatialmath.Pose {
+	origin := p.Origin.Pose()
+	columns := int(p.WidthMM/p.StepMM) + 1
+	rows := int(p.LengthMM/p.StepMM) + 1
+	poses := make([]spatialmath.Pose, 0, rows*columns)
+	for row := 0; row < rows; row++ {
+		for i := 0; i < columns; i++ {
+			column := i
+			if !p.Raster && row%2 == 1 {
+				column = columns - 1 - i
+			}
+			poses = append(poses, offsetPose(origin, r3.Vector{X: float64(column) * p.StepMM, Y: float64(row) * p.StepMM}))
+		}
+	}
+	return poses
+}
+
+// PalletProfile places items on a pallet layer by layer, each layer row by row. Columns run along the x axis of its
+// frame, rows along the y axis and layers up the z axis.
+type PalletProfile struct {
+	// Origin is where the first item is placed, with the orientation every item is placed at.
+	Origin        ProfilePose `json:"origin"`
+	Columns       int         `json:"columns"`
+	Rows          int         `json:"rows"`
+	Layers        int         `json:"layers"`
+	ColumnPitchMM float64     `json:"column_pitch_mm"`
+	RowPitchMM    float64     `json:"row_pitch_mm"`
+	LayerHeightMM float64     `json:"layer_height_mm"`
+	// Pattern is how the layers are stacked, PalletPatternColumn by default.
+	Pattern string `json:"pattern,omitempty"`
+	// ApproachMM, when set, precedes each place pose with a pose this far above it, to come down onto the stack.
+	ApproachMM float64 `json:"approach_mm,omitempty"`
+}
+
+// Validate ensures the pallet has items.
+func (p *PalletProfile) Validate() error {
+	if p.Columns < 1 || p.Rows < 1 || p.Layers < 1 {
+		return errors.New("pallet columns, rows and layers must be at least 1")
+	}
+	if p.ColumnPitchMM < 0 || p.RowPitchMM < 0 || p.LayerHeightMM < 0 || p.ApproachMM < 0 {
+		return errors.New("pallet pitches, layer_height_mm and approach_mm cannot be negative")
+	}
+	switch p.Pattern {
+	case "", PalletPatternColumn:
+	case PalletPatternBrick:
+		if p.Columns < 2 {
+			return errors.New("a brick pallet pattern needs at least 2 columns")
+		}
+	default:
+		return errors.Errorf("unknown pallet pattern %q", p.Pattern)
+	}
+	poses := float64(p.Columns) * float64(p.Rows) * float64(p.Layers)
+	if p.ApproachMM > 0 {
+		poses *= 2
+	}
+	return checkProfileSize(poses)
+}
+
+// Poses returns the poses of the items in the order they are placed.
+func (p *PalletProfile) Poses() []spatialmath.Pose {
+	origin := p.Origin.Pose()
+	var poses []spatialmath.Pose
+	for layer := 0; layer < p.Layers; layer++ {
+		columns, shift := p.Columns, 0.
+		if p.Pattern == PalletPatternBrick && layer%2 == 1 {
+			columns, shift = p.Columns-1, p.ColumnPitchMM/2
+		}
+		for row := 0; row < p.Rows; row++ {
+			for column := 0; column < columns; column++ {
+				place := r3.Vector{
+					X: shift + float64(column)*p.ColumnPitchMM,
+					Y: float64(row) * p.RowPitchMM,
+					Z: float64(layer) * p.LayerHeightMM,
+				}
+				if p.ApproachMM > 0 {
+					poses = append(poses, offsetPose(origin, place.Add(r3.Vector{Z: p.ApproachMM})))
+				}
+				poses = append(poses, offsetPose(origin, place))
+			}
+		}
+	}
+	return poses
+}
+
+// ProfilePoint is a position of a motion profile in millimeters.
+type ProfilePoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// PolarProfile sweeps around a center on a circle about the z axis of its frame, each pose pointing at the center,
+// for scanning an object from all sides.
+type PolarProfile struct {
+	Center   ProfilePoint `json:"center"`
+	RadiusMM float64      `json:"radius_mm"`
+	// HeightMM is how far above the center the circle is.
+	HeightMM float64 `json:"height_mm,omitempty"`
+	// StartDeg and EndDeg are the angles from the x axis the sweep goes between, or the whole circle from StartDeg
+	// when they are equal.
+	StartDeg float64 `json:"start_deg,omitempty"`
+	EndDeg   float64 `json:"end_deg,omitempty"`
+	Steps    int     `json:"steps"`
+}
+
+// Validate ensures the sweep has poses off its center.
+func (p *PolarProfile) Validate() error {
+	if p.RadiusMM < 0 {
+		return errors.New("polar radius_mm cannot be negative")
+	}
+	if p.RadiusMM == 0 && p.HeightMM == 0 {
+		return errors.New("a polar sweep needs a radius_mm or height_mm to be off its center")
+	}
+	if p.Steps < 1 {
+		return errors.New("polar steps must be at least 1")
+	}
+	return checkProfileSize(float64(p.Steps))
+}
+
+// Poses returns the poses of the sweep in order.
+func (p *PolarProfile) Poses() []spatialmath.Pose {
+	center := r3.Vector{X: p.Center.X, Y: p.Center.Y, Z: p.Center.Z}
+	step := 0.
+	switch {
+	case p.StartDeg == p.EndDeg:
+		step = 360 / float64(p.Steps)
+	case p.Steps > 1:
+		step = (p.EndDeg - p.StartDeg) / float64(p.Steps-1)
+	}
+	poses := make([]spatialmath.Pose, 0, p.Steps)
+	for i := 0; i < p.Steps; i++ {
+		angle := (p.StartDeg + float64(i)*step) * math.Pi / 180
+		point := center.Add(r3.Vector{X: p.RadiusMM * math.Cos(angle), Y: p.RadiusMM * math.Sin(angle), Z: p.HeightMM})
+		toCenter := center.Sub(point).Normalize()
+		poses = append(poses, spatialmath.NewPose(point, &spatialmath.OrientationVector{OX: toCenter.X, OY: toCenter.Y, OZ: toCenter.Z}))
+	}
+	return poses
+}
+
+// MotionProfileReq generates the poses of one of the profiles.
+type MotionProfileReq struct {
+	// Frame is the frame the poses are in, the world frame by default.
+	Frame  string         `json:"frame,omitempty"`
+	Grid   *GridProfile   `json:"grid,omitempty"`
+	Pallet *PalletProfile `json:"pallet,omitempty"`
+	Polar  *PolarProfile  `json:"polar,omitempty"`
+	// Execute moves the component through the poses in order, rather than only returning them.
+	Execute bool `json:"execute,omitempty"`
+	// ComponentName is the short name of the component to move, which executing the profile needs.
+	ComponentName string `json:"component_name,omitempty"`
+	// Extra is passed to the move to each pose, for example with a blend_radius_mm to move through the poses
+	// without stopping at each.
+	Extra map[string]interface{} `json:"extra,omitempty"`
+}
+
+// Validate ensures exactly one valid profile is given, and a component to execute it with when it is executed.
+func (req *MotionProfileReq) Validate() error {
+	var profiles []interface{ Validate() error }
+	if req.Grid != nil {
+		profiles = append(profiles, req.Grid)
+	}
+	if req.Pallet != nil {
+		profiles = append(profiles, req.Pallet)
+	}
+	if req.Polar != nil {
+		profiles = append(profiles, req.Polar)
+	}
+	if len(profiles) != 1 {
+		return errors.New("exactly one of grid, pallet and polar must be given")
+	}
+	if req.Execute && req.ComponentName == "" {
+		return errors.New("executing a motion profile needs a component_name")
+	}
+	return profiles[0].Validate()
+}
+
+// Poses returns the poses of the profile of the request.
+func (req *MotionProfileReq) Poses() []spatialmath.Pose {
+	switch {
+	case req.Grid != nil:
+		return req.Grid.Poses()
+	case req.Pallet != nil:
+		return req.Pallet.Poses()
+	case req.Polar != nil:
+		return req.Polar.Poses()
+	default:
+		return nil
+	}
+}
+
+// MotionProfileResp is the response of a MotionProfileCommand.
+type MotionProfileResp struct {
+	Frame string        `json:"frame"`
+	Poses []ProfilePose `json:"poses"`
+	// Executed is how many of the poses the component was moved to.
+	Executed int `json:"executed"`
+}
+
+// MotionProfile generates the poses of a motion profile with a motion service, and moves a component through them
+// when the request is to execute it.
+func MotionProfile(ctx context.Context, svc Service, req MotionProfileReq) (MotionProfileResp, error) {
+	return resource.DoTypedCommand[MotionProfileResp](ctx, svc, MotionProfileCommand, &req)
+}
+
+func checkProfileSize(poses float64) error {
+	if poses > MaxProfilePoses {
+		return errors.Errorf("the motion profile has %.0f poses, more than the %d allowed", poses, MaxProfilePoses)
+	}
+	return nil
+}
+
+// offsetPose returns the pose moved by an offset in the axes of its parent frame.
+func offsetPose(pose spatialmath.Pose, offset r3.Vector) spatialmath.Pose {
+	return spatialmath.NewPose(pose.Point().Add(offset), pose.Orientation())
+}
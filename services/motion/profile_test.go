@@ -0,0 +1,117 @@
+package motion
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+func points(poses []spatialmath.Pose) []r3.Vector {
+	pts := make([]r3.Vector, 0, len(poses))
+	for _, pose := range poses {
+		pt := pose.Point()
+		// rounded so that float error doesn't fail comparisons
+		pts = append(pts, r3.Vector{X: roundMicrons(pt.X), Y: roundMicrons(pt.Y), Z: roundMicrons(pt.Z)})
+	}
+	return pts
+}
+
+func roundMicrons(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
+
+func TestGridProfile(t *testing.T) {
+	down := ProfilePose{X: 100, Y: 200, Z: 50, OZ: -1}
+	grid := GridProfile{Origin: down, WidthMM: 25, LengthMM: 20, StepMM: 10}
+	test.That(t, grid.Validate(), test.ShouldBeNil)
+	poses := grid.Poses()
+	// rows turn back at their ends, and the region is not overshot
+	test.That(t, points(poses), test.ShouldResemble, []r3.Vector{
+		{X: 100, Y: 200, Z: 50}, {X: 110, Y: 200, Z: 50}, {X: 120, Y: 200, Z: 50},
+		{X: 120, Y: 210, Z: 50}, {X: 110, Y: 210, Z: 50}, {X: 100, Y: 210, Z: 50},
+		{X: 100, Y: 220, Z: 50}, {X: 110, Y: 220, Z: 50}, {X: 120, Y: 220, Z: 50},
+	})
+	for _, pose := range poses {
+		test.That(t, spatialmath.OrientationAlmostEqual(pose.Orientation(), down.Pose().Orientation()), test.ShouldBeTrue)
+	}
+
+	grid.Raster = true
+	test.That(t, points(grid.Poses())[3], test.ShouldResemble, r3.Vector{X: 100, Y: 210, Z: 50})
+
+	test.That(t, (&GridProfile{WidthMM: 10}).Validate(), test.ShouldBeError, "grid step_mm must be positive")
+	test.That(t, (&GridProfile{WidthMM: 1e4, LengthMM: 1e4, StepMM: 1}).Validate().Error(),
+		test.ShouldContainSubstring, "more than the 10000 allowed")
+}
+
+func TestPalletProfile(t *testing.T) {
+	pallet := PalletProfile{
+		Origin:        ProfilePose{OZ: -1},
+		Columns:       3,
+		Rows:          1,
+		Layers:        2,
+		ColumnPitchMM: 100,
+		LayerHeightMM: 50,
+		ApproachMM:    30,
+	}
+	test.That(t, pallet.Validate(), test.ShouldBeNil)
+	test.That(t, points(pallet.Poses()), test.ShouldResemble, []r3.Vector{
+		{X: 0, Z: 30}, {X: 0}, {X: 100, Z: 30}, {X: 100}, {X: 200, Z: 30}, {X: 200},
+		{X: 0, Z: 80}, {X: 0, Z: 50}, {X: 100, Z: 80}, {X: 100, Z: 50}, {X: 200, Z: 80}, {X: 200, Z: 50},
+	})
+
+	// every other layer of bricks straddles the layer below it
+	pallet.Pattern = PalletPatternBrick
+	pallet.ApproachMM = 0
+	test.That(t, pallet.Validate(), test.ShouldBeNil)
+	test.That(t, points(pallet.Poses()), test.ShouldResemble, []r3.Vector{
+		{X: 0}, {X: 100}, {X: 200}, {X: 50, Z: 50}, {X: 150, Z: 50},
+	})
+
+	pallet.Pattern = "pinwheel"
+	test.That(t, pallet.Validate(), test.ShouldBeError, `unknown pallet pattern "pinwheel"`)
+	test.That(t, (&PalletProfile{Columns: 1, Rows: 1}).Validate(), test.ShouldNotBeNil)
+}
+
+func TestPolarProfile(t *testing.T) {
+	polar := PolarProfile{Center: ProfilePoint{X: 500, Z: 100}, RadiusMM: 200, HeightMM: 200, Steps: 4}
+	test.That(t, polar.Validate(), test.ShouldBeNil)
+	poses := polar.Poses()
+	test.That(t, points(poses), test.ShouldResemble, []r3.Vector{
+		{X: 700, Z: 300}, {X: 500, Y: 200, Z: 300}, {X: 300, Z: 300}, {X: 500, Y: -200, Z: 300},
+	})
+	// each pose points down at the center, at 45 degrees
+	for _, pose := range poses {
+		toCenter := r3.Vector{X: 500, Z: 100}.Sub(pose.Point()).Normalize()
+		ov := pose.Orientation().OrientationVectorRadians()
+		test.That(t, ov.OX, test.ShouldAlmostEqual, toCenter.X)
+		test.That(t, ov.OY, test.ShouldAlmostEqual, toCenter.Y)
+		test.That(t, ov.OZ, test.ShouldAlmostEqual, toCenter.Z)
+	}
+
+	// a partial sweep includes both its ends
+	polar = PolarProfile{RadiusMM: 100, StartDeg: 0, EndDeg: 90, Steps: 3}
+	test.That(t, points(polar.Poses()), test.ShouldResemble, []r3.Vector{
+		{X: 100}, {X: 70.711, Y: 70.711}, {Y: 100},
+	})
+
+	test.That(t, (&PolarProfile{Steps: 3}).Validate(), test.ShouldNotBeNil)
+	test.That(t, (&PolarProfile{RadiusMM: 10}).Validate(), test.ShouldBeError, "polar steps must be at least 1")
+}
+
+func TestMotionProfileReq(t *testing.T) {
+	grid := &GridProfile{StepMM: 1}
+	test.That(t, (&MotionProfileReq{}).Validate(), test.ShouldBeError, "exactly one of grid, pallet and polar must be given")
+	test.That(t, (&MotionProfileReq{Grid: grid, Polar: &PolarProfile{}}).Validate(), test.ShouldNotBeNil)
+	test.That(t, (&MotionProfileReq{Grid: grid, Execute: true}).Validate(), test.ShouldBeError,
+		"executing a motion profile needs a component_name")
+	test.That(t, (&MotionProfileReq{Grid: grid, Execute: true, ComponentName: "arm"}).Validate(), test.ShouldBeNil)
+
+	pose := spatialmath.NewPose(r3.Vector{X: 1, Y: 2, Z: 3}, &spatialmath.OrientationVectorDegrees{OY: 1, Theta: 30})
+	test.That(t, spatialmath.PoseAlmostEqual(NewProfilePose(pose).Pose(), pose), test.ShouldBeTrue)
+	// a pose without an orientation points up
+	test.That(t, spatialmath.PoseAlmostEqual(ProfilePose{X: 1}.Pose(), spatialmath.NewPoseFromPoint(r3.Vector{X: 1})), test.ShouldBeTrue)
+}
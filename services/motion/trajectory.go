@@ -0,0 +1,206 @@
+package motion
+
+import (
+	"context"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+)
+
+// TrajectoryReportsCommand is the DoCommand of a motion service which returns the TrajectoryReports of recent
+// executions, with a TrajectoryReportsReq as its payload and a TrajectoryReportsResp as its response.
+const TrajectoryReportsCommand = "trajectory_reports"
+
+// The kinds of values of a TrajectoryRecording.
+const (
+	// TrajectoryKindJoints values are the inputs of a component, in radians for revolute joints and millimeters for
+	// prismatic ones.
+	TrajectoryKindJoints = "joints"
+	// TrajectoryKindBase values are the x and y of a base in millimeters.
+	TrajectoryKindBase = "base"
+)
+
+// TrajectorySample is the measured position of a component at a point of a TrajectoryRecording.
+type TrajectorySample struct {
+	ElapsedSec float64   `json:"elapsed_sec"`
+	Values     []float64 `json:"values"`
+}
+
+// TrajectoryRecording is the trajectory a component was commanded to follow during an execution, and the positions
+// it was measured at while following it.
+type TrajectoryRecording struct {
+	// ComponentName is the short name of the component which moved.
+	ComponentName string `json:"component_name"`
+	ExecutionID   string `json:"execution_id"`
+	// Kind says what the values of the commanded and measured positions are.
+	Kind string `json:"kind"`
+	// Started is when the component started executing the trajectory, in RFC 3339 format.
+	Started     string  `json:"started"`
+	DurationSec float64 `json:"duration_sec"`
+	// Error is why the execution failed, if it did.
+	Error string `json:"error,omitempty"`
+	// Commanded is the path through the waypoints of the trajectory.
+	Commanded [][]float64        `json:"commanded,omitempty"`
+	Measured  []TrajectorySample `json:"measured,omitempty"`
+}
+
+// TrajectoryScore summarizes how well a component followed the trajectory it was commanded, in the units of the
+// values of the recording.
+type TrajectoryScore struct {
+	DurationSec float64 `json:"duration_sec"`
+	Samples     int     `json:"samples"`
+	// The tracking error of a sample is its distance from the commanded path.
+	MeanTrackingError float64 `json:"mean_tracking_error"`
+	MaxTrackingError  float64 `json:"max_tracking_error"`
+	// FinalError is the distance of the last sample from the end of the commanded path.
+	FinalError float64 `json:"final_error"`
+	// RMSJerk and MaxJerk are the magnitude of the jerk of the measured motion, per second cubed.
+	RMSJerk float64 `json:"rms_jerk"`
+	MaxJerk float64 `json:"max_jerk"`
+}
+
+// TrajectoryReport is a TrajectoryRecording with its score.
+type TrajectoryReport struct {
+	Recording TrajectoryRecording `json:"recording"`
+	Score     TrajectoryScore     `json:"score"`
+}
+
+// TrajectoryReportsReq selects the recordings of a TrajectoryReportsCommand.
+type TrajectoryReportsReq struct {
+	// ComponentName is the short name of the component to report on, or empty for all of them.
+	ComponentName string `json:"component_name,omitempty"`
+	// ExecutionID, when set, only reports on that execution.
+	ExecutionID string `json:"execution_id,omitempty"`
+	// IncludeSamples returns the commanded and measured positions of the recordings along with their scores.
+	IncludeSamples bool `json:"include_samples,omitempty"`
+}
+
+// Validate ensures the execution ID is a UUID when set.
+func (req *TrajectoryReportsReq) Validate() error {
+	if req.ExecutionID == "" {
+		return nil
+	}
+	if _, err := uuid.Parse(req.ExecutionID); err != nil {
+		return errors.Wrap(err, "execution_id")
+	}
+	return nil
+}
+
+// TrajectoryReportsResp is the response of a TrajectoryReportsCommand, newest first.
+type TrajectoryReportsResp struct {
+	Reports []TrajectoryReport `json:"reports"`
+}
+
+// TrajectoryReports returns the trajectory reports of the recent executions of a motion service, which can be
+// compared across releases to track the quality of the controllers of its components.
+func TrajectoryReports(ctx context.Context, svc Service, req TrajectoryReportsReq) ([]TrajectoryReport, error) {
+	resp, err := resource.DoTypedCommand[TrajectoryReportsResp](ctx, svc, TrajectoryReportsCommand, &req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Reports, nil
+}
+
+// Score scores a recording by its tracking error, final error and jerk.
+func (r *TrajectoryRecording) Score() TrajectoryScore {
+	score := TrajectoryScore{DurationSec: r.DurationSec, Samples: len(r.Measured)}
+	if len(r.Measured) == 0 {
+		return score
+	}
+	if len(r.Commanded) > 0 {
+		for _, sample := range r.Measured {
+			d := distanceToPath(r.Commanded, sample.Values)
+			score.MeanTrackingError += d
+			score.MaxTrackingError = math.Max(score.MaxTrackingError, d)
+		}
+		score.MeanTrackingError /= float64(len(r.Measured))
+		score.FinalError = distance(r.Commanded[len(r.Commanded)-1], r.Measured[len(r.Measured)-1].Values)
+	}
+	jerks := jerkMagnitudes(r.Measured)
+	for _, j := range jerks {
+		score.RMSJerk += j * j
+		score.MaxJerk = math.Max(score.MaxJerk, j)
+	}
+	if len(jerks) > 0 {
+		score.RMSJerk = math.Sqrt(score.RMSJerk / float64(len(jerks)))
+	}
+	return score
+}
+
+// distanceToPath returns the distance of a point from the closest segment of a path.
+func distanceToPath(path [][]float64, p []float64) float64 {
+	closest := distance(path[0], p)
+	for i := 1; i < len(path); i++ {
+		a, b := path[i-1], path[i]
+		n := min(len(a), len(b), len(p))
+		// the closest point of the segment is where the projection of p onto it falls, clamped to its ends
+		var dot, lengthSq float64
+		for k := 0; k < n; k++ {
+			dot += (p[k] - a[k]) * (b[k] - a[k])
+			lengthSq += (b[k] - a[k]) * (b[k] - a[k])
+		}
+		t := 0.
+		if lengthSq > 0 {
+			t = math.Max(0, math.Min(1, dot/lengthSq))
+		}
+		var sq float64
+		for k := 0; k < n; k++ {
+			d := p[k] - (a[k] + t*(b[k]-a[k]))
+			sq += d * d
+		}
+		closest = math.Min(closest, math.Sqrt(sq))
+	}
+	return closest
+}
+
+func distance(a, b []float64) float64 {
+	var sq float64
+	for k := 0; k < min(len(a), len(b)); k++ {
+		sq += (a[k] - b[k]) * (a[k] - b[k])
+	}
+	return math.Sqrt(sq)
+}
+
+// jerkMagnitudes returns the magnitudes of the jerk between samples, by differentiating their positions three
+// times. Samples taken at the same time are skipped.
+func jerkMagnitudes(samples []TrajectorySample) []float64 {
+	times := make([]float64, 0, len(samples))
+	values := make([][]float64, 0, len(samples))
+	for _, s := range samples {
+		if len(times) > 0 && s.ElapsedSec <= times[len(times)-1] {
+			continue
+		}
+		times = append(times, s.ElapsedSec)
+		values = append(values, s.Values)
+	}
+	for order := 0; order < 3; order++ {
+		times, values = differentiate(times, values)
+	}
+	jerks := make([]float64, len(values))
+	for i, v := range values {
+		jerks[i] = distance(v, make([]float64, len(v)))
+	}
+	return jerks
+}
+
+// differentiate returns the derivative of values sampled at times, at the midpoints between the samples.
+func differentiate(times []float64, values [][]float64) ([]float64, [][]float64) {
+	if len(times) < 2 {
+		return nil, nil
+	}
+	midTimes := make([]float64, len(times)-1)
+	derivatives := make([][]float64, len(times)-1)
+	for i := range midTimes {
+		dt := times[i+1] - times[i]
+		midTimes[i] = times[i] + dt/2
+		n := min(len(values[i]), len(values[i+1]))
+		derivatives[i] = make([]float64, n)
+		for k := 0; k < n; k++ {
+			derivatives[i][k] = (values[i+1][k] - values[i][k]) / dt
+		}
+	}
+	return midTimes, derivatives
+}
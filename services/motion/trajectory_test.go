@@ -0,0 +1,44 @@
+package motion
+
+import (
+	"math"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestTrajectoryScore(t *testing.T) {
+	rec := TrajectoryRecording{
+		DurationSec: 2,
+		Commanded:   [][]float64{{0, 0}, {10, 0}, {10, 10}},
+	}
+	test.That(t, rec.Score(), test.ShouldResemble, TrajectoryScore{DurationSec: 2})
+
+	// samples off the path by 0, 1 and 2, ending 1 short of the goal
+	rec.Measured = []TrajectorySample{
+		{ElapsedSec: 0, Values: []float64{0, 0}},
+		{ElapsedSec: 1, Values: []float64{5, 1}},
+		{ElapsedSec: 2, Values: []float64{12, 9}},
+	}
+	score := rec.Score()
+	test.That(t, score.Samples, test.ShouldEqual, 3)
+	test.That(t, score.MeanTrackingError, test.ShouldAlmostEqual, 1)
+	test.That(t, score.MaxTrackingError, test.ShouldAlmostEqual, 2)
+	test.That(t, score.FinalError, test.ShouldAlmostEqual, math.Sqrt(5))
+	// jerk needs four samples
+	test.That(t, score.RMSJerk, test.ShouldEqual, 0)
+
+	// constant acceleration has no jerk, and a change of acceleration does
+	var samples []TrajectorySample
+	for i := 0; i < 6; i++ {
+		x := float64(i * i)
+		samples = append(samples, TrajectorySample{ElapsedSec: float64(i), Values: []float64{x}})
+	}
+	rec = TrajectoryRecording{Measured: samples}
+	test.That(t, rec.Score().MaxJerk, test.ShouldAlmostEqual, 0)
+	samples[5].Values[0] += 6
+	rec = TrajectoryRecording{Measured: append(samples, samples[5])}
+	score = rec.Score()
+	test.That(t, score.MaxJerk, test.ShouldAlmostEqual, 6)
+	test.That(t, score.RMSJerk, test.ShouldAlmostEqual, 6/math.Sqrt(3))
+}
@@ -518,7 +518,11 @@ func (svc *builtIn) Waypoints(ctx context.Context, extra map[string]interface{})
 
 func (svc *builtIn) AddWaypoint(ctx context.Context, point *geo.Point, extra map[string]interface{}) error {
 	svc.logger.CInfof(ctx, "AddWaypoint called with %#v", *point)
-	_, err := svc.store.AddWaypoint(ctx, point)
+	attrs, err := navigation.WaypointAttributesFromExtra(extra)
+	if err != nil {
+		return err
+	}
+	_, err = svc.store.AddWaypoint(ctx, point, attrs)
 	return err
 }
 
@@ -561,17 +565,52 @@ func (svc *builtIn) Close(ctx context.Context) error {
 	return svc.store.Close(ctx)
 }
 
-func (svc *builtIn) moveToWaypoint(ctx context.Context, wp navigation.Waypoint, extra map[string]interface{}) error {
-	req := motion.MoveOnGlobeReq{
+// waypointMoveOnGlobeReq builds the MoveOnGlobe request used to travel to the waypoint, applying any heading and
+// speed limits carried by the waypoint on top of the service's configuration.
+func (svc *builtIn) waypointMoveOnGlobeReq(wp navigation.Waypoint, extra map[string]interface{}) motion.MoveOnGlobeReq {
+	heading := math.NaN()
+	if wp.Heading != nil {
+		heading = *wp.Heading
+		// waypoint mode defaults to position_only which would disregard the heading so plan for the full pose instead
+		if profile, ok := extra["motion_profile"]; ok && profile == "position_only" {
+			extraCopy := make(map[string]interface{}, len(extra))
+			for k, v := range extra {
+				extraCopy[k] = v
+			}
+			delete(extraCopy, "motion_profile")
+			extra = extraCopy
+		}
+	}
+
+	motionCfg := svc.motionCfg
+	if wp.LinearMPerSec > 0 || wp.AngularDegsPerSec > 0 {
+		var cfgCopy motion.MotionConfiguration
+		if motionCfg != nil {
+			cfgCopy = *motionCfg
+		}
+		if wp.LinearMPerSec > 0 {
+			cfgCopy.LinearMPerSec = wp.LinearMPerSec
+		}
+		if wp.AngularDegsPerSec > 0 {
+			cfgCopy.AngularDegsPerSec = wp.AngularDegsPerSec
+		}
+		motionCfg = &cfgCopy
+	}
+
+	return motion.MoveOnGlobeReq{
 		ComponentName:      svc.base.Name(),
 		Destination:        wp.ToPoint(),
-		Heading:            math.NaN(),
+		Heading:            heading,
 		MovementSensorName: svc.movementSensor.Name(),
 		Obstacles:          svc.obstacles,
-		MotionCfg:          svc.motionCfg,
+		MotionCfg:          motionCfg,
 		BoundingRegions:    svc.boundingRegions,
 		Extra:              extra,
 	}
+}
+
+func (svc *builtIn) moveToWaypoint(ctx context.Context, wp navigation.Waypoint, extra map[string]interface{}) error {
+	req := svc.waypointMoveOnGlobeReq(wp, extra)
 	cancelCtx, cancelFn := context.WithCancel(ctx)
 	defer cancelFn()
 	executionID, err := svc.motionService.MoveOnGlobe(cancelCtx, req)
@@ -761,9 +800,11 @@ func (svc *builtIn) Obstacles(ctx context.Context, extra map[string]interface{})
 
 		baseHeading := math.Mod(localizerHeading+localizerBaseThetaDiff, 360)
 
-		// convert geo position into GeoPose
+		// convert geo position into GeoPose, and its pose on the plane tangent to the WGS84 ellipsoid at it
 		robotGeoPose := spatialmath.NewGeoPose(gp, baseHeading)
 		svc.logger.CDebugf(ctx, "robotGeoPose Location: %v, Heading: %v", *robotGeoPose.Location(), robotGeoPose.Heading())
+		plane := spatialmath.NewLocalTangentPlane(gp, 0)
+		robotPose := plane.GeoPoseToPose(robotGeoPose, 0)
 
 		// iterate through all detections and construct a geoGeometry to append
 		for i, detection := range detections {
@@ -799,7 +840,7 @@ func (svc *builtIn) Obstacles(ctx context.Context, extra map[string]interface{})
 			)
 
 			// get the geometry's lat & lng along with its heading with respect to north as a left handed value
-			obstacleGeoPose := spatialmath.PoseToGeoPose(robotGeoPose, manipulatedGeom.Pose())
+			obstacleGeoPose, _ := plane.PoseToGeoPose(spatialmath.Compose(robotPose, manipulatedGeom.Pose()))
 			svc.logger.CDebugf(
 				ctx,
 				"obstacleGeoPose Location: %v, Heading: %v",
@@ -570,6 +570,33 @@ func TestNavSetup(t *testing.T) {
 	err = ns.RemoveWaypoint(ctx, id, nil)
 	test.That(t, err, test.ShouldBeNil)
 
+	// Waypoints may carry a heading and speed limits for the segment which ends at them
+	err = ns.AddWaypoint(ctx, pt, map[string]interface{}{
+		navigation.WaypointHeadingKey:       90.,
+		navigation.WaypointLinearMPerSecKey: 0.5,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	wayPt, err = ns.Waypoints(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(wayPt), test.ShouldEqual, 1)
+	test.That(t, *wayPt[0].Heading, test.ShouldEqual, 90.)
+	test.That(t, wayPt[0].LinearMPerSec, test.ShouldEqual, 0.5)
+
+	req := ns.(*builtIn).waypointMoveOnGlobeReq(wayPt[0], map[string]interface{}{"motion_profile": "position_only"})
+	test.That(t, req.Heading, test.ShouldEqual, 90.)
+	test.That(t, req.Extra["motion_profile"], test.ShouldBeNil)
+	test.That(t, req.MotionCfg.LinearMPerSec, test.ShouldEqual, 0.5)
+	test.That(t, req.MotionCfg.AngularDegsPerSec, test.ShouldEqual, ns.(*builtIn).motionCfg.AngularDegsPerSec)
+	test.That(t, ns.(*builtIn).motionCfg.LinearMPerSec, test.ShouldNotEqual, 0.5)
+
+	err = ns.RemoveWaypoint(ctx, wayPt[0].ID, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	err = ns.AddWaypoint(ctx, pt, map[string]interface{}{navigation.WaypointHeadingKey: 400.})
+	test.That(t, err, test.ShouldNotBeNil)
+	err = ns.AddWaypoint(ctx, pt, map[string]interface{}{navigation.WaypointAngularDegsPerSecKey: "fast"})
+	test.That(t, err, test.ShouldNotBeNil)
+
 	test.That(t, len(ns.(*builtIn).motionCfg.ObstacleDetectors), test.ShouldEqual, 1)
 
 	paths, err := ns.Paths(ctx, nil)
@@ -1888,7 +1915,7 @@ func TestGetObstacles(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, len(dets), test.ShouldEqual, 2)
 	test.That(t, dets[0], test.ShouldResemble, sphereGob)
-	test.That(t, dets[1].Location(), test.ShouldResemble, geo.NewPoint(0.9999998600983906, 1.0000001399229705))
+	test.That(t, dets[1].Location(), test.ShouldResemble, geo.NewPoint(0.9999998593135565, 1.0000001397662066))
 	test.That(t, len(dets[1].Geometries()), test.ShouldEqual, 1)
 	test.That(t, spatialmath.GeometriesAlmostEqual(dets[1].Geometries()[0], manipulatedBoxGeom), test.ShouldBeTrue)
 	test.That(t, dets[1].Geometries()[0].Label(), test.ShouldEqual, manipulatedBoxGeom.Label())
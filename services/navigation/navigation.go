@@ -109,6 +109,9 @@ type Properties struct {
 //	// Add your waypoint to the service's data storage
 //	err := myNav.AddWaypoint(context.Background(), location, nil)
 //
+//	// Optionally, arrive facing east while travelling at no more than half a meter per second
+//	err = myNav.AddWaypoint(context.Background(), location, map[string]interface{}{"heading": 90.0, "linear_m_per_sec": 0.5})
+//
 // RemoveWaypoint example:
 //
 //	// Assumes you have already called AddWaypoint once and the waypoint has not yet been reached
@@ -151,6 +154,7 @@ type Service interface {
 	Waypoints(ctx context.Context, extra map[string]interface{}) ([]Waypoint, error)
 
 	// AddWaypoint adds a waypoint to the service's data storage.
+	// The extra may carry optional waypoint attributes (heading, linear_m_per_sec, angular_degs_per_sec).
 	AddWaypoint(ctx context.Context, point *geo.Point, extra map[string]interface{}) error
 
 	// RemoveWaypoint removes a waypoint from the service's data storage.
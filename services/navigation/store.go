@@ -22,7 +22,7 @@ var errNoMoreWaypoints = errors.New("no more waypoints")
 // NavStore handles the waypoints for a navigation service.
 type NavStore interface {
 	Waypoints(ctx context.Context) ([]Waypoint, error)
-	AddWaypoint(ctx context.Context, point *geo.Point) (Waypoint, error)
+	AddWaypoint(ctx context.Context, point *geo.Point, attrs WaypointAttributes) (Waypoint, error)
 	RemoveWaypoint(ctx context.Context, id primitive.ObjectID) error
 	NextWaypoint(ctx context.Context) (Waypoint, error)
 	WaypointVisited(ctx context.Context, id primitive.ObjectID) error
@@ -75,6 +75,63 @@ type Waypoint struct {
 	Order   int                `bson:"order"`
 	Lat     float64            `bson:"latitude"`
 	Long    float64            `bson:"longitude"`
+
+	WaypointAttributes `bson:",inline"`
+}
+
+// WaypointAttributes are optional properties of a Waypoint which shape how it is approached.
+// The speed limits apply to the segment of travel that ends at the waypoint.
+type WaypointAttributes struct {
+	// Heading the base should have when it reaches the waypoint.
+	// Range [0-360] Left Hand Rule (N: 0, E: 90, S: 180, W: 270). Nil indicates no particular heading.
+	Heading *float64 `bson:"heading,omitempty"`
+	// LinearMPerSec overrides the configured linear velocity while traveling to the waypoint when positive.
+	LinearMPerSec float64 `bson:"linear_m_per_sec,omitempty"`
+	// AngularDegsPerSec overrides the configured angular velocity while traveling to the waypoint when positive.
+	AngularDegsPerSec float64 `bson:"angular_degs_per_sec,omitempty"`
+}
+
+// Waypoint attribute keys which may be provided in the extra of an AddWaypoint call.
+const (
+	WaypointHeadingKey           = "heading"
+	WaypointLinearMPerSecKey     = "linear_m_per_sec"
+	WaypointAngularDegsPerSecKey = "angular_degs_per_sec"
+)
+
+// WaypointAttributesFromExtra parses the optional waypoint attributes out of an AddWaypoint extra.
+func WaypointAttributesFromExtra(extra map[string]interface{}) (WaypointAttributes, error) {
+	attrs := WaypointAttributes{}
+	if extra == nil {
+		return attrs, nil
+	}
+	if raw, ok := extra[WaypointHeadingKey]; ok {
+		heading, ok := raw.(float64)
+		if !ok {
+			return WaypointAttributes{}, errors.Errorf("could not interpret %s field as float", WaypointHeadingKey)
+		}
+		if math.IsNaN(heading) || heading < 0 || heading > 360 {
+			return WaypointAttributes{}, errors.Errorf("%s must be within [0, 360], got %f", WaypointHeadingKey, heading)
+		}
+		attrs.Heading = &heading
+	}
+	for key, dst := range map[string]*float64{
+		WaypointLinearMPerSecKey:     &attrs.LinearMPerSec,
+		WaypointAngularDegsPerSecKey: &attrs.AngularDegsPerSec,
+	} {
+		raw, ok := extra[key]
+		if !ok {
+			continue
+		}
+		val, ok := raw.(float64)
+		if !ok {
+			return WaypointAttributes{}, errors.Errorf("could not interpret %s field as float", key)
+		}
+		if math.IsNaN(val) || val < 0 {
+			return WaypointAttributes{}, errors.Errorf("%s may not be negative or NaN", key)
+		}
+		*dst = val
+	}
+	return attrs, nil
 }
 
 // ToPoint converts the waypoint to a geo.Point.
@@ -118,16 +175,17 @@ func (store *MemoryNavigationStore) Waypoints(ctx context.Context) ([]Waypoint,
 }
 
 // AddWaypoint adds a waypoint to the MemoryNavigationStore.
-func (store *MemoryNavigationStore) AddWaypoint(ctx context.Context, point *geo.Point) (Waypoint, error) {
+func (store *MemoryNavigationStore) AddWaypoint(ctx context.Context, point *geo.Point, attrs WaypointAttributes) (Waypoint, error) {
 	if ctx.Err() != nil {
 		return Waypoint{}, ctx.Err()
 	}
 	store.mu.Lock()
 	defer store.mu.Unlock()
 	newPoint := Waypoint{
-		ID:   primitive.NewObjectID(),
-		Lat:  point.Lat(),
-		Long: point.Lng(),
+		ID:                 primitive.NewObjectID(),
+		Lat:                point.Lat(),
+		Long:               point.Lng(),
+		WaypointAttributes: attrs,
 	}
 	store.waypoints = append(store.waypoints, &newPoint)
 	return newPoint, nil
@@ -265,11 +323,12 @@ func (store *MongoDBNavigationStore) Waypoints(ctx context.Context) ([]Waypoint,
 }
 
 // AddWaypoint adds a waypoint to the MongoDBNavigationStore.
-func (store *MongoDBNavigationStore) AddWaypoint(ctx context.Context, point *geo.Point) (Waypoint, error) {
+func (store *MongoDBNavigationStore) AddWaypoint(ctx context.Context, point *geo.Point, attrs WaypointAttributes) (Waypoint, error) {
 	newPoint := Waypoint{
-		ID:   primitive.NewObjectID(),
-		Lat:  point.Lat(),
-		Long: point.Lng(),
+		ID:                 primitive.NewObjectID(),
+		Lat:                point.Lat(),
+		Long:               point.Lng(),
+		WaypointAttributes: attrs,
 	}
 	if _, err := store.waypointsColl.InsertOne(ctx, newPoint); err != nil {
 		return Waypoint{}, err
@@ -4,6 +4,8 @@ package register
 
 import (
 	// blank import registration pattern.
+	_ "go.viam.com/rdk/services/generic/armcell"
+	_ "go.viam.com/rdk/services/generic/kinematiccalibration"
 	_ "go.viam.com/rdk/services/motion/register"
 	_ "go.viam.com/rdk/services/navigation/register"
 )
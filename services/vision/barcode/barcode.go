@@ -0,0 +1,220 @@
+// Package barcode implements a vision service which finds and decodes QR codes and 1D barcodes, such as EAN-13,
+// UPC-A, Code 128 and Code 39, in the images of a camera. Detections give the bounding boxes of the codes, labeled
+// with their payloads, and the captures of CaptureAllFromCamera give their formats, payloads and corners under
+// CodesExtraKey in their Extra.
+//
+// Given the size of its QR codes, the service also gives their full poses in the frame of the camera as object point
+// clouds, for which the camera needs intrinsics:
+//
+//	{"formats": ["qr_code"], "code_size_mm": 40}
+package barcode
+
+import (
+	"context"
+	"image"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	pc "go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+	viz "go.viam.com/rdk/vision"
+	"go.viam.com/rdk/vision/barcode"
+	"go.viam.com/rdk/vision/fiducial"
+	"go.viam.com/rdk/vision/objectdetection"
+	"go.viam.com/rdk/vision/segmentation"
+	"go.viam.com/rdk/vision/viscapture"
+)
+
+var model = resource.DefaultModelFamily.WithModel("barcode")
+
+// CodesExtraKey is the key of the codes in the Extra of the captures of CaptureAllFromCamera. The codes are a list
+// of maps, with the keys format, payload and corners, the four x, y pairs of the corners of the code in pixels,
+// clockwise from its top left.
+const CodesExtraKey = "codes"
+
+// codeThicknessMM is the thickness of the boxes of QR codes, which are flat.
+const codeThicknessMM = 1.
+
+func init() {
+	resource.RegisterService(vision.API, model, resource.Registration[vision.Service, *Config]{
+		DeprecatedRobotConstructor: func(
+			ctx context.Context, r any, c resource.Config, logger logging.Logger,
+		) (vision.Service, error) {
+			attrs, err := resource.NativeConfig[*Config](c)
+			if err != nil {
+				return nil, err
+			}
+			actualR, err := utils.AssertType[robot.Robot](r)
+			if err != nil {
+				return nil, err
+			}
+			return newBarcodeDetector(ctx, c.ResourceName(), attrs, actualR)
+		},
+	})
+}
+
+// Config is the config of a barcode detector.
+type Config struct {
+	// Formats are the formats of the codes to find, such as "qr_code" and "ean_13", or all of them if empty.
+	Formats []string `json:"formats,omitempty"`
+	// CodeSizeMM is the length of the sides of the QR codes, without their quiet zones. Their poses are only found
+	// when it is given.
+	CodeSizeMM float64 `json:"code_size_mm,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	for _, f := range conf.Formats {
+		if !barcode.ValidFormat(barcode.Format(f)) {
+			return nil, resource.NewConfigValidationError(path, errors.Errorf("unknown format %q, expected one of %v",
+				f, barcode.Formats))
+		}
+	}
+	if conf.CodeSizeMM < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("code_size_mm cannot be negative"))
+	}
+	return nil, nil
+}
+
+type barcodeDetector struct {
+	vision.Service
+	formats []barcode.Format
+	sizeMM  float64
+}
+
+func newBarcodeDetector(ctx context.Context, name resource.Name, conf *Config, r robot.Robot) (vision.Service, error) {
+	_, span := trace.StartSpan(ctx, "service::vision::newBarcodeDetector")
+	defer span.End()
+	bd := &barcodeDetector{sizeMM: conf.CodeSizeMM}
+	for _, f := range conf.Formats {
+		bd.formats = append(bd.formats, barcode.Format(f))
+	}
+	var segmenter segmentation.Segmenter
+	if conf.CodeSizeMM > 0 {
+		segmenter = bd.segment
+	}
+	svc, err := vision.NewService(name, r, nil, nil, bd.detect, segmenter)
+	if err != nil {
+		return nil, err
+	}
+	bd.Service = svc
+	return bd, nil
+}
+
+// detect finds the bounding boxes of codes, labeled with their payloads. Codes are only found once they are
+// decoded, so every detection scores 1.
+func (bd *barcodeDetector) detect(ctx context.Context, img image.Image) ([]objectdetection.Detection, error) {
+	return codeDetections(barcode.Detect(img, bd.formats...)), nil
+}
+
+func codeDetections(codes []barcode.Code) []objectdetection.Detection {
+	var detections []objectdetection.Detection
+	for _, c := range codes {
+		detections = append(detections, objectdetection.NewDetection(c.BoundingBox(), 1, c.Payload))
+	}
+	return detections
+}
+
+// segment finds the poses of QR codes in the frame of the camera. Each is a flat box the size of the code, labeled
+// with its payload, and its point cloud is its four corners.
+func (bd *barcodeDetector) segment(ctx context.Context, src camera.VideoSource) ([]*viz.Object, error) {
+	props, err := src.Properties(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the properties of the camera")
+	}
+	if props.IntrinsicParams == nil {
+		return nil, errors.New("the poses of codes need the intrinsic parameters of the camera")
+	}
+	img, release, err := camera.ReadImage(ctx, src)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get image from %s", src)
+	}
+	defer release()
+
+	objects := []*viz.Object{}
+	for _, c := range barcode.Detect(img, bd.formats...) {
+		if c.Format != barcode.QRCode {
+			continue
+		}
+		// the corners of a QR code are those of a marker, clockwise from its top left
+		pose, err := fiducial.EstimatePose(fiducial.Marker{Corners: c.Corners}, bd.sizeMM,
+			props.IntrinsicParams, props.DistortionParams)
+		if err != nil {
+			return nil, err
+		}
+		geometry, err := spatialmath.NewBox(pose, r3.Vector{X: bd.sizeMM, Y: bd.sizeMM, Z: codeThicknessMM}, c.Payload)
+		if err != nil {
+			return nil, err
+		}
+		cloud := pc.New()
+		for _, corner := range fiducial.MarkerCorners(bd.sizeMM) {
+			p := spatialmath.Compose(pose, spatialmath.NewPoseFromPoint(corner)).Point()
+			if err := cloud.Set(p, pc.NewBasicData()); err != nil {
+				return nil, err
+			}
+		}
+		objects = append(objects, &viz.Object{PointCloud: cloud, Geometry: geometry})
+	}
+	return objects, nil
+}
+
+// CaptureAllFromCamera captures the image of a camera, decoding its codes once for both the detections and all of
+// the codes, under CodesExtraKey in the Extra of the capture.
+func (bd *barcodeDetector) CaptureAllFromCamera(
+	ctx context.Context,
+	cameraName string,
+	opts viscapture.CaptureOptions,
+	extra map[string]interface{},
+) (viscapture.VisCapture, error) {
+	ctx, span := trace.StartSpan(ctx, "service::vision::barcode::CaptureAllFromCamera")
+	defer span.End()
+	imageOpts := opts
+	imageOpts.ReturnImage = true
+	imageOpts.ReturnDetections = false
+	capt, err := bd.Service.CaptureAllFromCamera(ctx, cameraName, imageOpts, extra)
+	if err != nil {
+		return viscapture.VisCapture{}, err
+	}
+	codes := barcode.Detect(capt.Image, bd.formats...)
+	if opts.ReturnDetections {
+		capt.Detections = codeDetections(codes)
+	}
+	if !opts.ReturnImage {
+		capt.Image = nil
+	}
+	if capt.Extra == nil {
+		capt.Extra = map[string]interface{}{}
+	}
+	capt.Extra[CodesExtraKey] = codesToExtra(codes)
+	return capt, nil
+}
+
+// codesToExtra turns codes into values which can be sent as the Extra of a capture.
+func codesToExtra(codes []barcode.Code) []interface{} {
+	out := make([]interface{}, 0, len(codes))
+	for _, c := range codes {
+		corners := make([]interface{}, 0, len(c.Corners))
+		for _, p := range c.Corners {
+			corners = append(corners, pointToExtra(p))
+		}
+		out = append(out, map[string]interface{}{
+			"format":  string(c.Format),
+			"payload": c.Payload,
+			"corners": corners,
+		})
+	}
+	return out
+}
+
+func pointToExtra(p r2.Point) []interface{} {
+	return []interface{}{p.X, p.Y}
+}
@@ -0,0 +1,139 @@
+package barcode
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/vision/viscapture"
+)
+
+// code39Reader serves an image of the Code 39 barcode "A1", whose narrow bars are 3 pixels wide and wide bars 9,
+// starting at 60, 200.
+type code39Reader struct{}
+
+func (r code39Reader) Read(ctx context.Context) (image.Image, func(), error) {
+	// the wide bars and spaces of *, A, 1 and *, from the first bar
+	var modules []bool
+	for _, pattern := range []int{0x094, 0x109, 0x121, 0x094} {
+		dark := true
+		for bit := 8; bit >= 0; bit-- {
+			for i := 0; i < 1+2*(pattern>>bit&1); i++ {
+				modules = append(modules, dark)
+			}
+			dark = !dark
+		}
+		modules = append(modules, false)
+	}
+	img := image.NewGray(image.Rect(0, 0, 640, 480))
+	for y := 0; y < 480; y++ {
+		for x := 0; x < 640; x++ {
+			m := (x - 60) / 3
+			v := uint8(230)
+			if y >= 200 && y < 280 && x >= 60 && m < len(modules) && modules[m] {
+				v = 25
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	return img, func() {}, nil
+}
+
+func (r code39Reader) Close(ctx context.Context) error {
+	return nil
+}
+
+func TestConfigValidate(t *testing.T) {
+	conf := &Config{}
+	_, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	conf.Formats = []string{"qr_code", "code_39"}
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	conf.Formats = []string{"qr_code", "datamatrix"}
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "datamatrix")
+
+	conf.Formats = nil
+	conf.CodeSizeMM = -1
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestBarcodeDetector(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	intrinsics := &transform.PinholeCameraIntrinsics{Width: 640, Height: 480, Fx: 500, Fy: 500, Ppx: 319.5, Ppy: 239.5}
+	src, err := camera.NewVideoSourceFromReader(ctx, code39Reader{},
+		&transform.PinholeCameraModel{PinholeCameraIntrinsics: intrinsics}, camera.ColorStream)
+	test.That(t, err, test.ShouldBeNil)
+	cam := camera.FromVideoSource(camera.Named("cam"), src, logger)
+	r := &inject.Robot{}
+	r.ResourceByNameFunc = func(n resource.Name) (resource.Resource, error) {
+		if n == camera.Named("cam") {
+			return cam, nil
+		}
+		return nil, resource.NewNotFoundError(n)
+	}
+	r.LoggerFunc = func() logging.Logger {
+		return logger
+	}
+
+	svc, err := newBarcodeDetector(ctx, vision.Named("codes"), &Config{}, r)
+	test.That(t, err, test.ShouldBeNil)
+	props, err := svc.GetProperties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.DetectionSupported, test.ShouldBeTrue)
+	test.That(t, props.ObjectPCDsSupported, test.ShouldBeFalse)
+
+	dets, err := svc.DetectionsFromCamera(ctx, "cam", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dets, test.ShouldHaveLength, 1)
+	test.That(t, dets[0].Label(), test.ShouldEqual, "A1")
+	test.That(t, dets[0].Score(), test.ShouldEqual, 1)
+	box := dets[0].BoundingBox()
+	test.That(t, box.Min.X, test.ShouldAlmostEqual, 60, 2)
+	test.That(t, box.Min.Y, test.ShouldAlmostEqual, 200, 2)
+	test.That(t, box.Max.Y, test.ShouldAlmostEqual, 280, 2)
+
+	capt, err := svc.CaptureAllFromCamera(ctx, "cam", viscapture.CaptureOptions{ReturnDetections: true}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, capt.Image, test.ShouldBeNil)
+	test.That(t, capt.Detections, test.ShouldHaveLength, 1)
+	codes, ok := capt.Extra[CodesExtraKey].([]interface{})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, codes, test.ShouldHaveLength, 1)
+	code := codes[0].(map[string]interface{})
+	test.That(t, code["format"], test.ShouldEqual, "code_39")
+	test.That(t, code["payload"], test.ShouldEqual, "A1")
+	corners := code["corners"].([]interface{})
+	test.That(t, corners, test.ShouldHaveLength, 4)
+	topLeft := corners[0].([]interface{})
+	test.That(t, topLeft[0], test.ShouldAlmostEqual, 59.5, 1.5)
+	test.That(t, topLeft[1], test.ShouldAlmostEqual, 200, 1.5)
+
+	// only QR codes are looked for, and only they have poses
+	svc, err = newBarcodeDetector(ctx, vision.Named("qr"), &Config{Formats: []string{"qr_code"}, CodeSizeMM: 40}, r)
+	test.That(t, err, test.ShouldBeNil)
+	props, err = svc.GetProperties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.ObjectPCDsSupported, test.ShouldBeTrue)
+	dets, err = svc.DetectionsFromCamera(ctx, "cam", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dets, test.ShouldBeEmpty)
+	objects, err := svc.GetObjectPointClouds(ctx, "cam", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, objects, test.ShouldBeEmpty)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"image"
+	"strconv"
 
 	"github.com/pkg/errors"
 	"go.opencensus.io/trace"
@@ -12,6 +13,8 @@ import (
 	pb "go.viam.com/api/service/vision/v1"
 	"go.viam.com/utils/protoutils"
 	"go.viam.com/utils/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 
 	"go.viam.com/rdk/gostream"
 	"go.viam.com/rdk/logging"
@@ -209,12 +212,58 @@ func (c *client) GetObjectPointClouds(
 	return protoToObjects(resp.Objects)
 }
 
+// GetObjectPointCloudsPage returns a page of the objects found by the remote vision service.
+func (c *client) GetObjectPointCloudsPage(
+	ctx context.Context,
+	cameraName string,
+	req ObjectPageRequest,
+	extra map[string]interface{},
+) (ObjectPage, error) {
+	ctx, span := trace.StartSpan(ctx, "service::vision::client::GetObjectPointCloudsPage")
+	defer span.End()
+	ext, err := protoutils.StructToStructPb(extra)
+	if err != nil {
+		return ObjectPage{}, err
+	}
+	var header metadata.MD
+	resp, err := c.client.GetObjectPointClouds(req.outgoingContext(ctx), &pb.GetObjectPointCloudsRequest{
+		Name:       c.name,
+		CameraName: cameraName,
+		MimeType:   utils.MimeTypePCD,
+		Extra:      ext,
+	}, grpc.Header(&header))
+	if err != nil {
+		return ObjectPage{}, err
+	}
+	if resp.MimeType != utils.MimeTypePCD {
+		return ObjectPage{}, fmt.Errorf("unknown pc mime type %s", resp.MimeType)
+	}
+	objects, err := protoToObjects(resp.Objects)
+	if err != nil {
+		return ObjectPage{}, err
+	}
+	page := ObjectPage{Objects: objects, TotalObjects: len(objects)}
+	if values := header.Get(NextObjectPageTokenMetadataKey); len(values) > 0 {
+		page.NextPageToken = values[0]
+	}
+	if values := header.Get(TotalObjectsMetadataKey); len(values) > 0 {
+		if page.TotalObjects, err = strconv.Atoi(values[0]); err != nil {
+			return ObjectPage{}, errors.Wrapf(err, "invalid %s", TotalObjectsMetadataKey)
+		}
+	}
+	return page, nil
+}
+
 func protoToObjects(pco []*commonpb.PointCloudObject) ([]*vision.Object, error) {
 	objects := make([]*vision.Object, len(pco))
 	for i, o := range pco {
-		pc, err := pointcloud.ReadPCD(bytes.NewReader(o.PointCloud))
-		if err != nil {
-			return nil, err
+		// the point clouds of objects paged without them are empty
+		pc := pointcloud.New()
+		var err error
+		if len(o.PointCloud) > 0 {
+			if pc, err = pointcloud.ReadPCD(bytes.NewReader(o.PointCloud)); err != nil {
+				return nil, err
+			}
 		}
 		// Sets the label to the first non-empty label of any geometry; defaults to the empty string.
 		label := func() string {
@@ -269,6 +318,7 @@ func (c *client) CaptureAllFromCamera(
 	if err != nil {
 		return viscapture.VisCapture{}, err
 	}
+	var header metadata.MD
 	resp, err := c.client.CaptureAllFromCamera(ctx, &pb.CaptureAllFromCameraRequest{
 		Name:                    c.name,
 		CameraName:              cameraName,
@@ -277,7 +327,7 @@ func (c *client) CaptureAllFromCamera(
 		ReturnClassifications:   captureOptions.ReturnClassifications,
 		ReturnObjectPointClouds: captureOptions.ReturnObject,
 		Extra:                   ext,
-	})
+	}, grpc.Header(&header))
 	if err != nil {
 		return viscapture.VisCapture{}, err
 	}
@@ -308,6 +358,12 @@ func (c *client) CaptureAllFromCamera(
 		Classifications: class,
 		Objects:         objPCD,
 	}
+	if resp.Extra != nil {
+		capt.Extra = resp.Extra.AsMap()
+	}
+	if err := capt.Metadata.ReadGRPCHeader(header); err != nil {
+		return viscapture.VisCapture{}, err
+	}
 
 	return capt, nil
 }
@@ -5,17 +5,20 @@ import (
 	"image"
 	"net"
 	"testing"
+	"time"
 
 	"go.viam.com/test"
 	"go.viam.com/utils/rpc"
 
 	viamgrpc "go.viam.com/rdk/grpc"
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/pointcloud"
 	"go.viam.com/rdk/resource"
 	_ "go.viam.com/rdk/services/register"
 	"go.viam.com/rdk/services/vision"
 	"go.viam.com/rdk/testutils"
 	"go.viam.com/rdk/testutils/inject"
+	viz "go.viam.com/rdk/vision"
 	"go.viam.com/rdk/vision/objectdetection"
 	"go.viam.com/rdk/vision/viscapture"
 )
@@ -56,6 +59,8 @@ func TestClient(t *testing.T) {
 		det1 := objectdetection.NewDetection(image.Rectangle{}, 0.5, "yes")
 		return viscapture.VisCapture{
 			Detections: []objectdetection.Detection{det1},
+			Extra:      map[string]interface{}{"tracks": []interface{}{map[string]interface{}{"id": 1}}},
+			Metadata:   resource.ResponseMetadata{CapturedAt: time.UnixMilli(12345), FrameSequence: 3},
 		}, nil
 	}
 	m := map[resource.Name]vision.Service{
@@ -150,6 +155,10 @@ func TestClient(t *testing.T) {
 		test.That(t, capt.Detections, test.ShouldHaveLength, 1)
 		test.That(t, capt.Detections[0].Label(), test.ShouldEqual, "yes")
 		test.That(t, capt.Detections[0].Score(), test.ShouldEqual, 0.5)
+		test.That(t, capt.Extra, test.ShouldResemble,
+			map[string]interface{}{"tracks": []interface{}{map[string]interface{}{"id": 1.}}})
+		test.That(t, capt.Metadata.CapturedAt.Equal(time.UnixMilli(12345)), test.ShouldBeTrue)
+		test.That(t, capt.Metadata.FrameSequence, test.ShouldEqual, 3)
 		test.That(t, client.Close(context.Background()), test.ShouldBeNil)
 		test.That(t, conn.Close(), test.ShouldBeNil)
 	})
@@ -191,3 +200,101 @@ func TestInjectedServiceClient(t *testing.T) {
 		test.That(t, conn.Close(), test.ShouldBeNil)
 	})
 }
+
+func TestClientObjectPages(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	rpcServer, err := rpc.NewServer(logger.AsZap(), rpc.WithUnauthenticated())
+	test.That(t, err, test.ShouldBeNil)
+
+	segmentations := 0
+	srv := &inject.VisionService{}
+	srv.GetObjectPointCloudsFunc = func(ctx context.Context, cameraName string, extra map[string]interface{}) ([]*viz.Object, error) {
+		segmentations++
+		objects := make([]*viz.Object, 0, 5)
+		for i := 0; i < 5; i++ {
+			cloud := pointcloud.New()
+			test.That(t, cloud.Set(pointcloud.NewVector(float64(i), 0, 0), nil), test.ShouldBeNil)
+			test.That(t, cloud.Set(pointcloud.NewVector(float64(i), 1, 1), nil), test.ShouldBeNil)
+			obj, err := viz.NewObject(cloud)
+			test.That(t, err, test.ShouldBeNil)
+			objects = append(objects, obj)
+		}
+		return objects, nil
+	}
+	otherName := vision.Named("vision2")
+	svc, err := resource.NewAPIResourceCollection(vision.API, map[resource.Name]vision.Service{
+		visName1:  srv,
+		otherName: &inject.VisionService{GetObjectPointCloudsFunc: srv.GetObjectPointCloudsFunc},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	resourceAPI, ok, err := resource.LookupAPIRegistration[vision.Service](vision.API)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, resourceAPI.RegisterRPCService(context.Background(), rpcServer, svc), test.ShouldBeNil)
+	go rpcServer.Serve(listener)
+	defer rpcServer.Stop()
+
+	ctx := context.Background()
+	conn, err := viamgrpc.Dial(ctx, listener.Addr().String(), logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer conn.Close()
+	client, err := vision.NewClientFromConn(ctx, conn, "", visName1, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	// the first page segments and the next pages read the same segmentation
+	page, err := vision.GetObjectPointCloudsPage(ctx, client, "cam", vision.ObjectPageRequest{PageSize: 2, OmitPointClouds: true}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, page.TotalObjects, test.ShouldEqual, 5)
+	test.That(t, len(page.Objects), test.ShouldEqual, 2)
+	test.That(t, page.Objects[0].Size(), test.ShouldEqual, 0)
+	test.That(t, page.Objects[1].Geometry, test.ShouldNotBeNil)
+	token := page.NextPageToken
+	seen := len(page.Objects)
+	for page.NextPageToken != "" && len(page.Objects) > 0 {
+		page, err = vision.GetObjectPointCloudsPage(ctx, client, "cam",
+			vision.ObjectPageRequest{PageSize: 2, PageToken: page.NextPageToken}, nil)
+		test.That(t, err, test.ShouldBeNil)
+		seen += len(page.Objects)
+	}
+	test.That(t, seen, test.ShouldEqual, 5)
+	test.That(t, segmentations, test.ShouldEqual, 1)
+
+	// geometry on demand
+	page, err = vision.GetObjectPointCloudsPage(ctx, client, "cam", vision.ObjectPageRequest{PageToken: token, Indices: []int{4, 1}}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(page.Objects), test.ShouldEqual, 2)
+	_, got := page.Objects[0].At(4, 1, 1)
+	test.That(t, got, test.ShouldBeTrue)
+	_, got = page.Objects[1].At(1, 0, 0)
+	test.That(t, got, test.ShouldBeTrue)
+
+	_, err = vision.GetObjectPointCloudsPage(ctx, client, "cam", vision.ObjectPageRequest{PageToken: token, Indices: []int{5}}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = vision.GetObjectPointCloudsPage(ctx, client, "cam", vision.ObjectPageRequest{PageToken: "gone/0"}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "expired")
+	_, err = vision.GetObjectPointCloudsPage(ctx, client, "cam", vision.ObjectPageRequest{Indices: []int{0}}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// the segmentation of one service cannot be read through another
+	otherClient, err := vision.NewClientFromConn(ctx, conn, "", otherName, logger)
+	test.That(t, err, test.ShouldBeNil)
+	_, err = vision.GetObjectPointCloudsPage(ctx, otherClient, "cam", vision.ObjectPageRequest{PageToken: token, Indices: []int{0}}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `is for vision service "vision1"`)
+
+	// without paging every object is returned as before
+	objects, err := client.GetObjectPointClouds(ctx, "cam", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(objects), test.ShouldEqual, 5)
+	test.That(t, objects[0].Size(), test.ShouldEqual, 2)
+	test.That(t, segmentations, test.ShouldEqual, 2)
+
+	// services in the same process return every object in one page
+	page, err = vision.GetObjectPointCloudsPage(ctx, srv, "cam", vision.ObjectPageRequest{PageSize: 2}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(page.Objects), test.ShouldEqual, 5)
+	test.That(t, page.NextPageToken, test.ShouldEqual, "")
+}
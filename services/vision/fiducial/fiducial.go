@@ -0,0 +1,202 @@
+// Package fiducial implements a vision service which finds square fiducial markers, such as ArUco markers and
+// AprilTags, in the images of a camera. Detections give the markers' bounding boxes, and object point clouds give
+// their full poses in the frame of the camera, which needs intrinsics for them. Each marker is labeled with the
+// name of its dictionary and its id, such as "aruco_original_7".
+//
+// The original ArUco markers are built in. Other families, such as the AprilTag family tag36h11, are configured
+// with their codes, as listed by their sources:
+//
+//	{"family": "tag36h11", "code_bits": 6, "codes": ["0xd7e00984b", ...], "tag_size_mm": 80}
+//
+// TagFrames turns the markers a camera sees into frames of the frame system, so that motions can be planned
+// relative to them.
+package fiducial
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	pc "go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+	viz "go.viam.com/rdk/vision"
+	"go.viam.com/rdk/vision/fiducial"
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+var model = resource.DefaultModelFamily.WithModel("fiducial")
+
+// defaultCustomFamily names dictionaries configured by their codes without a family.
+const defaultCustomFamily = "tag"
+
+// markerThicknessMM is the thickness of the boxes of markers, which are flat.
+const markerThicknessMM = 1.
+
+func init() {
+	resource.RegisterService(vision.API, model, resource.Registration[vision.Service, *Config]{
+		DeprecatedRobotConstructor: func(
+			ctx context.Context, r any, c resource.Config, logger logging.Logger,
+		) (vision.Service, error) {
+			attrs, err := resource.NativeConfig[*Config](c)
+			if err != nil {
+				return nil, err
+			}
+			actualR, err := utils.AssertType[robot.Robot](r)
+			if err != nil {
+				return nil, err
+			}
+			return newFiducialDetector(ctx, c.ResourceName(), attrs, actualR)
+		},
+	})
+}
+
+// Config is the config of a fiducial detector.
+type Config struct {
+	// Family is the dictionary of the markers, which is "aruco_original" unless Codes are given.
+	Family string `json:"family,omitempty"`
+	// Codes are the codes of the markers of a family in hexadecimal, indexed by id, for markers of CodeBits by
+	// CodeBits cells inside their black border.
+	Codes    []string `json:"codes,omitempty"`
+	CodeBits int      `json:"code_bits,omitempty"`
+	// MaxBitErrors is how many cells of a marker may be misread for it to still be found.
+	MaxBitErrors int `json:"max_bit_errors,omitempty"`
+	// TagSizeMM is the length of the sides of the black square of the markers.
+	TagSizeMM float64 `json:"tag_size_mm"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.TagSizeMM <= 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "tag_size_mm")
+	}
+	if _, err := conf.dictionary(); err != nil {
+		return nil, resource.NewConfigValidationError(path, err)
+	}
+	return nil, nil
+}
+
+// dictionary returns the dictionary of the markers the config describes.
+func (conf *Config) dictionary() (*fiducial.Dictionary, error) {
+	if conf.MaxBitErrors < 0 {
+		return nil, errors.New("max_bit_errors cannot be negative")
+	}
+	if len(conf.Codes) == 0 {
+		if conf.Family != "" && conf.Family != fiducial.ArucoOriginal {
+			return nil, errors.Errorf("unknown family %q, only %q is built in and others need their codes",
+				conf.Family, fiducial.ArucoOriginal)
+		}
+		return fiducial.NewArucoOriginal(conf.MaxBitErrors), nil
+	}
+	if conf.CodeBits == 0 {
+		return nil, errors.New("code_bits is required with codes")
+	}
+	codes, err := fiducial.ParseCodes(conf.Codes)
+	if err != nil {
+		return nil, err
+	}
+	family := conf.Family
+	if family == "" {
+		family = defaultCustomFamily
+	}
+	return fiducial.NewDictionary(family, conf.CodeBits, codes, conf.MaxBitErrors)
+}
+
+type fiducialDetector struct {
+	dict   *fiducial.Dictionary
+	sizeMM float64
+}
+
+func newFiducialDetector(ctx context.Context, name resource.Name, conf *Config, r robot.Robot) (vision.Service, error) {
+	_, span := trace.StartSpan(ctx, "service::vision::newFiducialDetector")
+	defer span.End()
+	dict, err := conf.dictionary()
+	if err != nil {
+		return nil, err
+	}
+	fd := &fiducialDetector{dict: dict, sizeMM: conf.TagSizeMM}
+	return vision.NewService(name, r, nil, nil, fd.detect, fd.segment)
+}
+
+func (fd *fiducialDetector) label(m fiducial.Marker) string {
+	return fmt.Sprintf("%s_%d", fd.dict.Name, m.ID)
+}
+
+// detect finds the bounding boxes of markers, scored by the fraction of their cells read as their codes have them.
+func (fd *fiducialDetector) detect(ctx context.Context, img image.Image) ([]objectdetection.Detection, error) {
+	cells := float64(fd.dict.Bits * fd.dict.Bits)
+	var detections []objectdetection.Detection
+	for _, m := range fiducial.Detect(img, fd.dict) {
+		detections = append(detections,
+			objectdetection.NewDetection(m.BoundingBox(), 1-float64(m.BitErrors)/cells, fd.label(m)))
+	}
+	return detections, nil
+}
+
+// segment finds the poses of markers in the frame of the camera. Each is a flat box the size of the marker, and
+// its point cloud is its four corners.
+func (fd *fiducialDetector) segment(ctx context.Context, src camera.VideoSource) ([]*viz.Object, error) {
+	props, err := src.Properties(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the properties of the camera")
+	}
+	if props.IntrinsicParams == nil {
+		return nil, errors.New("the poses of markers need the intrinsic parameters of the camera")
+	}
+	img, release, err := camera.ReadImage(ctx, src)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get image from %s", src)
+	}
+	defer release()
+
+	objects := []*viz.Object{}
+	for _, m := range fiducial.Detect(img, fd.dict) {
+		pose, err := fiducial.EstimatePose(m, fd.sizeMM, props.IntrinsicParams, props.DistortionParams)
+		if err != nil {
+			return nil, err
+		}
+		geometry, err := spatialmath.NewBox(pose, r3.Vector{X: fd.sizeMM, Y: fd.sizeMM, Z: markerThicknessMM}, fd.label(m))
+		if err != nil {
+			return nil, err
+		}
+		cloud := pc.New()
+		for _, corner := range fiducial.MarkerCorners(fd.sizeMM) {
+			p := spatialmath.Compose(pose, spatialmath.NewPoseFromPoint(corner)).Point()
+			if err := cloud.Set(p, pc.NewBasicData()); err != nil {
+				return nil, err
+			}
+		}
+		objects = append(objects, &viz.Object{PointCloud: cloud, Geometry: geometry})
+	}
+	return objects, nil
+}
+
+// TagFrames returns the markers a fiducial vision service finds in the next image from a camera as frames whose
+// parent is the camera, named by their labels. Passed as the transforms of a WorldState, they let motions be
+// planned to poses relative to the markers.
+func TagFrames(
+	ctx context.Context, svc vision.Service, cameraName string, extra map[string]interface{},
+) ([]*referenceframe.LinkInFrame, error) {
+	objects, err := svc.GetObjectPointClouds(ctx, cameraName, extra)
+	if err != nil {
+		return nil, err
+	}
+	frames := make([]*referenceframe.LinkInFrame, 0, len(objects))
+	for _, obj := range objects {
+		if obj.Geometry == nil || obj.Geometry.Label() == "" {
+			continue
+		}
+		frames = append(frames, referenceframe.NewLinkInFrame(cameraName, obj.Geometry.Pose(), obj.Geometry.Label(), nil))
+	}
+	return frames, nil
+}
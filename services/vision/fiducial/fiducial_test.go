@@ -0,0 +1,140 @@
+package fiducial
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/vision/fiducial"
+)
+
+// markerReader serves an image of an upright original ArUco marker whose cells are 10 pixels wide, centered at
+// 320, 240.
+type markerReader struct {
+	id int
+}
+
+func (r markerReader) Read(ctx context.Context) (image.Image, func(), error) {
+	img := image.NewGray(image.Rect(0, 0, 640, 480))
+	code, _ := fiducial.NewArucoOriginal(0).Code(r.id)
+	for y := 0; y < 480; y++ {
+		for x := 0; x < 640; x++ {
+			col, row := int(math.Floor((float64(x)-285)/10)), int(math.Floor((float64(y)-205)/10))
+			v := uint8(230)
+			if col >= 0 && row >= 0 && col < 7 && row < 7 {
+				v = 30
+				if col > 0 && row > 0 && col < 6 && row < 6 && code>>(24-((row-1)*5+col-1))&1 == 1 {
+					v = 230
+				}
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	return img, func() {}, nil
+}
+
+func (r markerReader) Close(ctx context.Context) error {
+	return nil
+}
+
+func TestConfigValidate(t *testing.T) {
+	conf := &Config{}
+	_, err := conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "tag_size_mm")
+
+	conf.TagSizeMM = 50
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	conf.Family = "tag36h11"
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "need their codes")
+
+	conf.Codes = []string{"0xd7e00984b", "0xdda664ca7"}
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "code_bits")
+
+	conf.CodeBits = 6
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	dict, err := conf.dictionary()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dict.Name, test.ShouldEqual, "tag36h11")
+
+	conf.CodeBits = 5
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestFiducialDetector(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	intrinsics := &transform.PinholeCameraIntrinsics{Width: 640, Height: 480, Fx: 500, Fy: 500, Ppx: 319.5, Ppy: 239.5}
+	camModel := &transform.PinholeCameraModel{PinholeCameraIntrinsics: intrinsics}
+	src, err := camera.NewVideoSourceFromReader(ctx, markerReader{id: 42}, camModel, camera.ColorStream)
+	test.That(t, err, test.ShouldBeNil)
+	cam := camera.FromVideoSource(camera.Named("cam"), src, logger)
+	noIntrinsicsSrc, err := camera.NewVideoSourceFromReader(ctx, markerReader{id: 42}, nil, camera.ColorStream)
+	test.That(t, err, test.ShouldBeNil)
+	noIntrinsicsCam := camera.FromVideoSource(camera.Named("nointrinsics"), noIntrinsicsSrc, logger)
+	r := &inject.Robot{}
+	r.ResourceByNameFunc = func(n resource.Name) (resource.Resource, error) {
+		switch n {
+		case camera.Named("cam"):
+			return cam, nil
+		case camera.Named("nointrinsics"):
+			return noIntrinsicsCam, nil
+		}
+		return nil, resource.NewNotFoundError(n)
+	}
+
+	svc, err := newFiducialDetector(ctx, vision.Named("tags"), &Config{TagSizeMM: 70}, r)
+	test.That(t, err, test.ShouldBeNil)
+	props, err := svc.GetProperties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.DetectionSupported, test.ShouldBeTrue)
+	test.That(t, props.ObjectPCDsSupported, test.ShouldBeTrue)
+
+	dets, err := svc.DetectionsFromCamera(ctx, "nointrinsics", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dets, test.ShouldHaveLength, 1)
+	test.That(t, dets[0].Label(), test.ShouldEqual, "aruco_original_42")
+	test.That(t, dets[0].Score(), test.ShouldEqual, 1)
+
+	_, err = svc.GetObjectPointClouds(ctx, "nointrinsics", nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "intrinsic")
+
+	// the marker is 70 pixels wide and 70mm wide, so it is as far from the camera as the focal length, facing it
+	objects, err := svc.GetObjectPointClouds(ctx, "cam", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, objects, test.ShouldHaveLength, 1)
+	test.That(t, objects[0].Size(), test.ShouldEqual, 4)
+	pose := objects[0].Geometry.Pose()
+	test.That(t, pose.Point().Sub(r3.Vector{Z: 500}).Norm(), test.ShouldBeLessThan, 10)
+	facing := &spatialmath.R4AA{Theta: math.Pi, RX: 1}
+	test.That(t, spatialmath.QuatToR3AA(spatialmath.OrientationBetween(pose.Orientation(), facing).Quaternion()).Norm(),
+		test.ShouldBeLessThan, 0.05)
+
+	frames, err := TagFrames(ctx, svc, "cam", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, frames, test.ShouldHaveLength, 1)
+	test.That(t, frames[0].Name(), test.ShouldEqual, "aruco_original_42")
+	test.That(t, frames[0].Parent(), test.ShouldEqual, "cam")
+	test.That(t, spatialmath.PoseAlmostEqual(frames[0].Pose(), pose), test.ShouldBeTrue)
+}
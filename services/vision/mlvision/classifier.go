@@ -49,7 +49,7 @@ func attemptToBuildClassifier(mlm mlmodel.Service,
 		inHeight, inWidth = shape[1], shape[2]
 	}
 	// creates postprocessor to filter on labels and confidences
-	postprocessor := createClassificationFilter(params.DefaultConfidence, params.LabelConfidenceMap)
+	postprocessor := createClassificationFilter(params)
 
 	return func(ctx context.Context, img image.Image) (classification.Classifications, error) {
 		origW, origH := img.Bounds().Dx(), img.Bounds().Dy()
@@ -130,7 +130,7 @@ func attemptToBuildClassifier(mlm mlmodel.Service,
 		if err != nil {
 			return nil, err
 		}
-		confs := checkClassificationScores(probs)
+		confs := checkClassificationScores(probs, params.SoftmaxTemperature)
 		if labels != nil && len(labels) != len(confs) {
 			return nil, errors.New("length of output expected to be length of label list (but is not)")
 		}
@@ -171,13 +171,19 @@ func checkIfClassifierWorks(ctx context.Context, cf classification.Classifier) e
 	return nil
 }
 
-// createClassificationFilter creates a post processor function that filters on the outputs of the model.
-func createClassificationFilter(minConf float64, labelMap map[string]float64) classification.Postprocessor {
-	if len(labelMap) != 0 {
-		return classification.NewLabelConfidenceFilter(labelMap)
+// createClassificationFilter creates a post processor function that renames and filters the outputs of the model.
+func createClassificationFilter(params *MLModelConfig) classification.Postprocessor {
+	f := newLabelFilter(params)
+	if f == nil {
+		return nil
 	}
-	if minConf != 0.0 {
-		return classification.NewScoreFilter(minConf)
+	return func(in classification.Classifications) classification.Classifications {
+		out := make(classification.Classifications, 0, len(in))
+		for _, c := range in {
+			if label, ok := f.filter(c.Label(), c.Score()); ok {
+				out = append(out, classification.NewClassification(c.Score(), label))
+			}
+		}
+		return out
 	}
-	return nil
 }
@@ -64,7 +64,7 @@ func attemptToBuildDetector(mlm mlmodel.Service,
 		inHeight, inWidth = shape[1], shape[2]
 	}
 	// creates postprocessor to filter on labels and confidences
-	postprocessor := createDetectionFilter(params.DefaultConfidence, params.LabelConfidenceMap)
+	postprocessor := createDetectionFilter(params)
 
 	return func(ctx context.Context, img image.Image) ([]objectdetection.Detection, error) {
 		origW, origH := img.Bounds().Dx(), img.Bounds().Dy()
@@ -185,14 +185,16 @@ func attemptToBuildDetector(mlm mlmodel.Service,
 			}
 			rect := image.Rect(int(xmin), int(ymin), int(xmax), int(ymax))
 			labelNum := int(utils.Clamp(categories[i], 0, math.MaxInt))
+			// the scores of detections are independent of each other, so each is calibrated on its own
+			score := scaleScore(scores[i], params.SoftmaxTemperature)
 
 			if labels == nil {
-				detections = append(detections, objectdetection.NewDetection(rect, scores[i], strconv.Itoa(labelNum)))
+				detections = append(detections, objectdetection.NewDetection(rect, score, strconv.Itoa(labelNum)))
 			} else {
 				if labelNum >= len(labels) {
 					return nil, errors.Errorf("cannot access label number %v from label file with %v labels", labelNum, len(labels))
 				}
-				detections = append(detections, objectdetection.NewDetection(rect, scores[i], labels[labelNum]))
+				detections = append(detections, objectdetection.NewDetection(rect, score, labels[labelNum]))
 			}
 		}
 		if postprocessor != nil {
@@ -444,13 +446,19 @@ func checkIfDetectorWorks(ctx context.Context, df objectdetection.Detector) erro
 	return nil
 }
 
-// createDetectionFilter creates a post processor function that filters on the outputs of the model.
-func createDetectionFilter(minConf float64, labelMap map[string]float64) objectdetection.Postprocessor {
-	if len(labelMap) != 0 {
-		return objectdetection.NewLabelConfidenceFilter(labelMap)
+// createDetectionFilter creates a post processor function that renames and filters the outputs of the model.
+func createDetectionFilter(params *MLModelConfig) objectdetection.Postprocessor {
+	f := newLabelFilter(params)
+	if f == nil {
+		return nil
 	}
-	if minConf != 0.0 {
-		return objectdetection.NewScoreFilter(minConf)
+	return func(in []objectdetection.Detection) []objectdetection.Detection {
+		out := make([]objectdetection.Detection, 0, len(in))
+		for _, d := range in {
+			if label, ok := f.filter(d.Label(), d.Score()); ok {
+				out = append(out, objectdetection.NewDetection(*d.BoundingBox(), d.Score(), label))
+			}
+		}
+		return out
 	}
-	return nil
 }
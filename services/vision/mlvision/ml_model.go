@@ -70,6 +70,15 @@ type MLModelConfig struct {
 	IsBGR              bool               `json:"input_image_bgr"`
 	DefaultConfidence  float64            `json:"default_minimum_confidence"`
 	LabelConfidenceMap map[string]float64 `json:"label_confidences"`
+	// optional parameter renaming the labels of the model, such as {"person": "visitor"}. Confidences and allowed
+	// labels refer to the renamed labels.
+	RemapLabels map[string]string `json:"remap_labels,omitempty"`
+	// optional parameter listing the only labels returned. Without it, the labels of label_confidences are the only
+	// labels returned, and with it, allowed labels without a confidence of their own use the default confidence.
+	AllowedLabels []string `json:"allowed_labels,omitempty"`
+	// optional parameter calibrating the scores of the model by dividing its logits by it before they are turned into
+	// confidences. Above 1 it softens overconfident scores, and below 1 it sharpens them.
+	SoftmaxTemperature float64 `json:"softmax_temperature,omitempty"`
 }
 
 // Validate will add the ModelName as an implicit dependency to the robot.
@@ -92,6 +101,9 @@ func (conf *MLModelConfig) Validate(path string) ([]string, error) {
 			return nil, errors.New("input_image_std_dev is not allowed to have 0 values, will cause division by 0")
 		}
 	}
+	if conf.SoftmaxTemperature < 0 {
+		return nil, errors.New("softmax_temperature cannot be negative")
+	}
 	return []string{conf.ModelName}, nil
 }
 
@@ -277,40 +289,125 @@ func getIndex(s []int, num int) int {
 	return -1
 }
 
-// softmax takes the input slice and applies the softmax function.
-func softmax(in []float64) []float64 {
+// softmax takes the input slice and applies the softmax function, dividing the inputs by the temperature first.
+func softmax(in []float64, temperature float64) []float64 {
+	if temperature <= 0 {
+		temperature = 1
+	}
+	maxIn := math.Inf(-1)
+	for _, x := range in {
+		maxIn = math.Max(maxIn, x)
+	}
 	out := make([]float64, 0, len(in))
 	bigSum := 0.0
 	for _, x := range in {
-		bigSum += math.Exp(x)
+		// shifting by the largest input keeps the exponentials from overflowing without changing the result
+		bigSum += math.Exp((x - maxIn) / temperature)
 	}
 	for _, x := range in {
-		out = append(out, math.Exp(x)/bigSum)
+		out = append(out, math.Exp((x-maxIn)/temperature)/bigSum)
 	}
 	return out
 }
 
+// scaleScore calibrates a binary confidence score by dividing its logit by the temperature.
+func scaleScore(p, temperature float64) float64 {
+	if temperature <= 0 || temperature == 1 || p <= 0 || p >= 1 {
+		return p
+	}
+	return 1 / (1 + math.Exp(-math.Log(p/(1-p))/temperature))
+}
+
 // checkClassification scores ensures that the input scores (output of classifier)
-// will represent confidence values (from 0-1).
-func checkClassificationScores(in []float64) []float64 {
+// will represent confidence values (from 0-1), calibrated by the temperature if it is not 0.
+func checkClassificationScores(in []float64, temperature float64) []float64 {
 	if len(in) > 1 {
 		for _, p := range in {
 			if p < 0 || p > 1 { // is logit, needs softmax
-				confs := softmax(in)
+				confs := softmax(in, temperature)
 				return confs
 			}
 		}
+		if temperature > 0 && temperature != 1 {
+			// probabilities are the softmax of their logarithms
+			logs := make([]float64, 0, len(in))
+			for _, p := range in {
+				logs = append(logs, math.Log(p))
+			}
+			return softmax(logs, temperature)
+		}
 		return in // no need to softmax
 	}
 	// otherwise, this is a binary classifier
 	if in[0] < -1 || in[0] > 1 { // needs sigmoid
-		out, err := stats.Sigmoid(in)
+		logit := in[0]
+		if temperature > 0 {
+			logit /= temperature
+		}
+		out, err := stats.Sigmoid([]float64{logit})
 		if err != nil {
 			return in
 		}
 		return out
 	}
-	return in // no need to sigmoid
+	return []float64{scaleScore(in[0], temperature)} // no need to sigmoid
+}
+
+// labelFilter renames the labels of the outputs of a model, and drops the outputs which are not allowed or not
+// confident enough, as configured. Labels are matched regardless of case.
+type labelFilter struct {
+	remap         map[string]string
+	allowed       map[string]bool
+	confidences   map[string]float64
+	minConfidence float64
+}
+
+// newLabelFilter returns the label filter of the config, or nil if it does not filter or rename anything.
+func newLabelFilter(params *MLModelConfig) *labelFilter {
+	if len(params.RemapLabels) == 0 && len(params.AllowedLabels) == 0 && len(params.LabelConfidenceMap) == 0 &&
+		params.DefaultConfidence == 0 {
+		return nil
+	}
+	f := &labelFilter{
+		remap:         map[string]string{},
+		confidences:   map[string]float64{},
+		minConfidence: params.DefaultConfidence,
+	}
+	for from, to := range params.RemapLabels {
+		f.remap[strings.ToLower(from)] = to
+	}
+	for label, conf := range params.LabelConfidenceMap {
+		f.confidences[strings.ToLower(label)] = conf
+	}
+	switch {
+	case len(params.AllowedLabels) != 0:
+		f.allowed = map[string]bool{}
+		for _, label := range params.AllowedLabels {
+			f.allowed[strings.ToLower(label)] = true
+		}
+	case len(f.confidences) != 0:
+		f.allowed = map[string]bool{}
+		for label := range f.confidences {
+			f.allowed[label] = true
+		}
+	}
+	return f
+}
+
+// filter returns the label of an output, renamed if it is remapped, and whether the output is kept.
+func (f *labelFilter) filter(label string, score float64) (string, bool) {
+	if to, ok := f.remap[strings.ToLower(label)]; ok {
+		label = to
+	}
+	key := strings.ToLower(label)
+	if f.allowed != nil && !f.allowed[key] {
+		return label, false
+	}
+	minConfidence := f.minConfidence
+	if conf, ok := f.confidences[key]; ok {
+		minConfidence = conf
+	}
+	return label, score >= minConfidence
 }
 
 // Number interface for converting between numbers.
@@ -4,6 +4,8 @@ package mlvision
 
 import (
 	"context"
+	"image"
+	"math"
 	"sync"
 	"testing"
 
@@ -16,6 +18,7 @@ import (
 	"go.viam.com/rdk/services/mlmodel/tflitecpu"
 	"go.viam.com/rdk/testutils/inject"
 	"go.viam.com/rdk/vision/classification"
+	"go.viam.com/rdk/vision/objectdetection"
 )
 
 func BenchmarkAddMLVisionModel(b *testing.B) {
@@ -640,3 +643,82 @@ func getNClassifications(
 		test.That(t, res[0].Score(), test.ShouldNotBeNil)
 	}
 }
+
+func TestLabelFilter(t *testing.T) {
+	test.That(t, createDetectionFilter(&MLModelConfig{}), test.ShouldBeNil)
+	test.That(t, createClassificationFilter(&MLModelConfig{}), test.ShouldBeNil)
+
+	box := image.Rect(0, 0, 10, 10)
+	detections := []objectdetection.Detection{
+		objectdetection.NewDetection(box, 0.9, "Dog"),
+		objectdetection.NewDetection(box, 0.4, "dog"),
+		objectdetection.NewDetection(box, 0.6, "cat"),
+		objectdetection.NewDetection(box, 0.95, "person"),
+	}
+	labels := func(ds []objectdetection.Detection) []string {
+		out := []string{}
+		for _, d := range ds {
+			out = append(out, d.Label())
+		}
+		return out
+	}
+
+	// label confidences list the only labels returned, as they always have
+	conf := &MLModelConfig{DefaultConfidence: 0.5, LabelConfidenceMap: map[string]float64{"DOG": 0.3}}
+	test.That(t, labels(createDetectionFilter(conf)(detections)), test.ShouldResemble, []string{"Dog", "dog"})
+
+	// allowed labels without confidences of their own use the default
+	conf.AllowedLabels = []string{"dog", "cat"}
+	test.That(t, labels(createDetectionFilter(conf)(detections)), test.ShouldResemble, []string{"Dog", "dog", "cat"})
+
+	// remapped labels are allowed and thresholded by their new names
+	conf.RemapLabels = map[string]string{"Person": "visitor", "cat": "pet"}
+	conf.AllowedLabels = []string{"visitor", "pet"}
+	conf.LabelConfidenceMap = map[string]float64{"pet": 0.7}
+	test.That(t, labels(createDetectionFilter(conf)(detections)), test.ShouldResemble, []string{"visitor"})
+	conf.LabelConfidenceMap = nil
+	filtered := createDetectionFilter(conf)(detections)
+	test.That(t, labels(filtered), test.ShouldResemble, []string{"pet", "visitor"})
+	test.That(t, *filtered[1].BoundingBox(), test.ShouldResemble, box)
+	test.That(t, filtered[1].Score(), test.ShouldEqual, 0.95)
+
+	classifications := classification.Classifications{
+		classification.NewClassification(0.7, "cat"),
+		classification.NewClassification(0.2, "dog"),
+	}
+	got := createClassificationFilter(conf)(classifications)
+	test.That(t, got, test.ShouldHaveLength, 1)
+	test.That(t, got[0].Label(), test.ShouldEqual, "pet")
+	test.That(t, got[0].Score(), test.ShouldEqual, 0.7)
+
+	_, err := (&MLModelConfig{ModelName: "m", SoftmaxTemperature: -1}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestSoftmaxTemperature(t *testing.T) {
+	logits := []float64{2, 1, -1}
+	plain := checkClassificationScores(logits, 0)
+	test.That(t, plain, test.ShouldResemble, checkClassificationScores(logits, 1))
+	test.That(t, plain[0]+plain[1]+plain[2], test.ShouldAlmostEqual, 1)
+
+	// a higher temperature softens the scores, and a lower one sharpens them
+	soft := checkClassificationScores(logits, 2)
+	test.That(t, soft[0], test.ShouldBeLessThan, plain[0])
+	test.That(t, soft[2], test.ShouldBeGreaterThan, plain[2])
+	test.That(t, soft[0]+soft[1]+soft[2], test.ShouldAlmostEqual, 1)
+	sharp := checkClassificationScores(logits, 0.5)
+	test.That(t, sharp[0], test.ShouldBeGreaterThan, plain[0])
+
+	// probabilities are calibrated as the softmax of their logits would be
+	fromProbabilities := checkClassificationScores(plain, 2)
+	for i := range soft {
+		test.That(t, fromProbabilities[i], test.ShouldAlmostEqual, soft[i])
+	}
+	test.That(t, checkClassificationScores(plain, 0), test.ShouldResemble, plain)
+
+	// binary scores scale their logits
+	test.That(t, checkClassificationScores([]float64{0.5}, 3)[0], test.ShouldAlmostEqual, 0.5)
+	test.That(t, checkClassificationScores([]float64{0.9}, 2)[0], test.ShouldAlmostEqual, 0.75)
+	test.That(t, checkClassificationScores([]float64{4}, 2)[0], test.ShouldAlmostEqual, 1/(1+math.Exp(-2)))
+	test.That(t, scaleScore(0.9, 0), test.ShouldEqual, 0.9)
+}
@@ -0,0 +1,177 @@
+// Package objectdetector3d implements a vision service which fuses the 2D detections of a detector with the depth
+// images of an RGB-D camera into 3D objects, each in an oriented bounding box fitted to its points. The boxes are in
+// the frame of the camera, or of any other frame of the robot's frame system, such as the world, when configured:
+//
+//	{"detector_name": "my_detector", "frame": "world"}
+//
+// Obstacles turns the objects into the obstacles of a motion service's WorldState.
+package objectdetector3d
+
+import (
+	"context"
+	"image"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	pc "go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+	viz "go.viam.com/rdk/vision"
+	"go.viam.com/rdk/vision/objectdetection"
+	"go.viam.com/rdk/vision/segmentation"
+)
+
+var model = resource.DefaultModelFamily.WithModel("object_detector_3d")
+
+// defaultConfidenceThresh is the score below which detections are left out by default.
+const defaultConfidenceThresh = 0.5
+
+func init() {
+	resource.RegisterService(vision.API, model, resource.Registration[vision.Service, *Config]{
+		DeprecatedRobotConstructor: func(
+			ctx context.Context, r any, c resource.Config, logger logging.Logger,
+		) (vision.Service, error) {
+			attrs, err := resource.NativeConfig[*Config](c)
+			if err != nil {
+				return nil, err
+			}
+			actualR, err := utils.AssertType[robot.Robot](r)
+			if err != nil {
+				return nil, err
+			}
+			return newObjectDetector3D(ctx, c.ResourceName(), attrs, actualR)
+		},
+	})
+}
+
+// Config is the config of a 3D object detector.
+type Config struct {
+	DetectorName     string  `json:"detector_name"`
+	ConfidenceThresh float64 `json:"confidence_threshold_pct,omitempty"`
+	// MeanK and Sigma configure the statistical filter which removes stray points, such as the background around an
+	// object, from its point cloud.
+	MeanK int     `json:"mean_k,omitempty"`
+	Sigma float64 `json:"sigma,omitempty"`
+	// Frame is the frame of the objects, which is the camera's unless given.
+	Frame string `json:"frame,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.DetectorName == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "detector_name")
+	}
+	if conf.ConfidenceThresh < 0 || conf.ConfidenceThresh > 1 {
+		return nil, resource.NewConfigValidationError(path, errors.New("confidence_threshold_pct must be between 0 and 1"))
+	}
+	if conf.MeanK < 0 || conf.Sigma < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("mean_k and sigma cannot be negative"))
+	}
+	return nil, nil
+}
+
+type objectDetector3D struct {
+	r         robot.Robot
+	segmenter segmentation.Segmenter
+	frame     string
+}
+
+func newObjectDetector3D(ctx context.Context, name resource.Name, conf *Config, r robot.Robot) (vision.Service, error) {
+	_, span := trace.StartSpan(ctx, "service::vision::newObjectDetector3D")
+	defer span.End()
+	detectorService, err := vision.FromRobot(r, conf.DetectorName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find necessary dependency, detector %q", conf.DetectorName)
+	}
+	confThresh := defaultConfidenceThresh
+	if conf.ConfidenceThresh > 0 {
+		confThresh = conf.ConfidenceThresh
+	}
+	detector := func(ctx context.Context, img image.Image) ([]objectdetection.Detection, error) {
+		return detectorService.Detections(ctx, img, nil)
+	}
+	segmenter, err := segmentation.DetectionSegmenter(detector, conf.MeanK, conf.Sigma, confThresh)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create 3D segmenter from detector")
+	}
+	od := &objectDetector3D{r: r, segmenter: segmenter, frame: conf.Frame}
+	return vision.NewService(name, r, nil, nil, detector, od.segment)
+}
+
+// segment finds the objects the detector detects, each in the oriented bounding box of its points, in the
+// configured frame.
+func (od *objectDetector3D) segment(ctx context.Context, src camera.VideoSource) ([]*viz.Object, error) {
+	objects, err := od.segmenter(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	var toFrame spatialmath.Pose
+	if od.frame != "" {
+		cam, ok := src.(resource.Resource)
+		if !ok {
+			return nil, errors.New("the objects can only be put in another frame for cameras of the robot")
+		}
+		camInFrame, err := od.r.TransformPose(ctx,
+			referenceframe.NewPoseInFrame(cam.Name().ShortName(), spatialmath.NewZeroPose()), od.frame, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not find the camera in frame %q", od.frame)
+		}
+		toFrame = camInFrame.Pose()
+	}
+
+	for _, obj := range objects {
+		label := ""
+		if obj.Geometry != nil {
+			label = obj.Geometry.Label()
+		}
+		box, err := pc.OrientedBoundingBoxFromPointCloudWithLabel(obj.PointCloud, label)
+		if err != nil {
+			return nil, err
+		}
+		if toFrame != nil {
+			box = box.Transform(toFrame)
+			cloud := pc.NewWithPrealloc(obj.Size())
+			var setErr error
+			obj.Iterate(0, 0, func(p r3.Vector, d pc.Data) bool {
+				setErr = cloud.Set(spatialmath.Compose(toFrame, spatialmath.NewPoseFromPoint(p)).Point(), d)
+				return setErr == nil
+			})
+			if setErr != nil {
+				return nil, setErr
+			}
+			obj.PointCloud = cloud
+		}
+		obj.Geometry = box
+	}
+	return objects, nil
+}
+
+// Obstacles returns the boxes of the objects a vision service finds in the next image from a camera as obstacles
+// for a motion service's WorldState. The frame is the one the service gives its objects in, which is the camera's
+// if empty.
+func Obstacles(
+	ctx context.Context, svc vision.Service, cameraName, frame string, extra map[string]interface{},
+) (*referenceframe.GeometriesInFrame, error) {
+	objects, err := svc.GetObjectPointClouds(ctx, cameraName, extra)
+	if err != nil {
+		return nil, err
+	}
+	if frame == "" {
+		frame = cameraName
+	}
+	geometries := make([]spatialmath.Geometry, 0, len(objects))
+	for _, obj := range objects {
+		if obj.Geometry != nil {
+			geometries = append(geometries, obj.Geometry)
+		}
+	}
+	return referenceframe.NewGeometriesInFrame(frame, geometries), nil
+}
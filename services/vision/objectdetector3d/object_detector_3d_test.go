@@ -0,0 +1,121 @@
+package objectdetector3d
+
+import (
+	"context"
+	"image"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+func TestValidate(t *testing.T) {
+	conf := &Config{}
+	_, err := conf.Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "detector_name")
+
+	conf.DetectorName = "detector"
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	conf.ConfidenceThresh = 2
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.ConfidenceThresh = 0.5
+	conf.MeanK = -1
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestObjectDetector3D(t *testing.T) {
+	ctx := context.Background()
+	r := &inject.Robot{}
+	detect := func(context.Context, image.Image) ([]objectdetection.Detection, error) {
+		return []objectdetection.Detection{objectdetection.NewDetection(image.Rect(10, 10, 30, 20), 0.9, "box")}, nil
+	}
+	detectorName := vision.Named("detector")
+	detector, err := vision.NewService(detectorName, r, nil, nil, detect, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	cam := inject.NewCamera("fakeCamera")
+	cam.ImagesFunc = func(ctx context.Context) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+		img := rimage.NewImage(100, 100)
+		dm := rimage.NewEmptyDepthMap(100, 100)
+		for x := 10; x < 30; x++ {
+			for y := 10; y < 20; y++ {
+				dm.Set(x, y, rimage.Depth(100+x))
+			}
+		}
+		imgs := []camera.NamedImage{{Image: img, SourceName: "color"}, {Image: dm, SourceName: "depth"}}
+		return imgs, resource.ResponseMetadata{CapturedAt: time.Now()}, nil
+	}
+	cam.ProjectorFunc = func(ctx context.Context) (transform.Projector, error) {
+		return &transform.ParallelProjection{}, nil
+	}
+	r.ResourceByNameFunc = func(n resource.Name) (resource.Resource, error) {
+		switch n.Name {
+		case "fakeCamera":
+			return cam, nil
+		case "detector":
+			return detector, nil
+		default:
+			return nil, resource.NewNotFoundError(n)
+		}
+	}
+	// the camera is 1m above the world, looking down
+	camPose := spatialmath.NewPose(r3.Vector{Z: 1000}, &spatialmath.OrientationVector{OZ: -1})
+	r.TransformPoseFunc = func(
+		ctx context.Context, pose *referenceframe.PoseInFrame, dst string, _ []*referenceframe.LinkInFrame,
+	) (*referenceframe.PoseInFrame, error) {
+		test.That(t, pose.Parent(), test.ShouldEqual, "fakeCamera")
+		test.That(t, dst, test.ShouldEqual, referenceframe.World)
+		return referenceframe.NewPoseInFrame(dst, spatialmath.Compose(camPose, pose.Pose())), nil
+	}
+
+	_, err = newObjectDetector3D(ctx, vision.Named("od"), &Config{DetectorName: "nope"}, r)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "could not find necessary dependency")
+
+	// in the camera's frame
+	svc, err := newObjectDetector3D(ctx, vision.Named("od"), &Config{DetectorName: "detector"}, r)
+	test.That(t, err, test.ShouldBeNil)
+	objects, err := svc.GetObjectPointClouds(ctx, "fakeCamera", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(objects), test.ShouldEqual, 1)
+	test.That(t, objects[0].Size(), test.ShouldEqual, 200)
+	box := objects[0].Geometry
+	test.That(t, box.Label(), test.ShouldEqual, "box")
+	test.That(t, box.Pose().Point().X, test.ShouldAlmostEqual, 19.5)
+	test.That(t, box.Pose().Point().Y, test.ShouldAlmostEqual, 14.5)
+	test.That(t, box.Pose().Point().Z, test.ShouldAlmostEqual, 119.5)
+
+	obstacles, err := Obstacles(ctx, svc, "fakeCamera", "", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, obstacles.Parent(), test.ShouldEqual, "fakeCamera")
+	test.That(t, len(obstacles.Geometries()), test.ShouldEqual, 1)
+
+	// in the world frame
+	svc, err = newObjectDetector3D(ctx, vision.Named("od"), &Config{DetectorName: "detector", Frame: referenceframe.World}, r)
+	test.That(t, err, test.ShouldBeNil)
+	objects, err = svc.GetObjectPointClouds(ctx, "fakeCamera", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(objects), test.ShouldEqual, 1)
+	center := objects[0].Geometry.Pose().Point()
+	test.That(t, center.Z, test.ShouldAlmostEqual, 1000-119.5)
+	test.That(t, objects[0].Geometry.Label(), test.ShouldEqual, "box")
+	md := objects[0].MetaData()
+	test.That(t, md.MaxZ, test.ShouldBeLessThan, 1000-100+1e-6)
+	test.That(t, md.MinZ, test.ShouldBeGreaterThan, 1000-130)
+}
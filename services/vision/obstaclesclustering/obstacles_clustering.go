@@ -0,0 +1,57 @@
+// Package obstaclesclustering uses the clustering pipeline of the RDK vision/segmentation package, which downsamples
+// a point cloud, removes its planes, and clusters what is left, as a vision model.
+package obstaclesclustering
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/utils"
+	"go.viam.com/rdk/vision/segmentation"
+)
+
+var model = resource.DefaultModelFamily.WithModel("obstacles_clustering")
+
+func init() {
+	resource.RegisterService(vision.API, model, resource.Registration[vision.Service, *segmentation.ClusteringPipelineConfig]{
+		DeprecatedRobotConstructor: func(
+			ctx context.Context, r any, c resource.Config, logger logging.Logger,
+		) (vision.Service, error) {
+			attrs, err := resource.NativeConfig[*segmentation.ClusteringPipelineConfig](c)
+			if err != nil {
+				return nil, err
+			}
+			actualR, err := utils.AssertType[robot.Robot](r)
+			if err != nil {
+				return nil, err
+			}
+			return registerClusteringSegmenter(ctx, c.ResourceName(), attrs, actualR)
+		},
+	})
+}
+
+// registerClusteringSegmenter creates a new clustering pipeline segmenter from the config.
+func registerClusteringSegmenter(
+	ctx context.Context,
+	name resource.Name,
+	conf *segmentation.ClusteringPipelineConfig,
+	r robot.Robot,
+) (vision.Service, error) {
+	_, span := trace.StartSpan(ctx, "service::vision::registerObstaclesClustering")
+	defer span.End()
+	if conf == nil {
+		return nil, errors.New("config for obstacles clustering segmenter cannot be nil")
+	}
+	err := conf.CheckValid()
+	if err != nil {
+		return nil, errors.Wrap(err, "obstacles clustering segmenter config error")
+	}
+	segmenter := segmentation.Segmenter(conf.ClusteringPipeline)
+	return vision.NewService(name, r, nil, nil, nil, segmenter)
+}
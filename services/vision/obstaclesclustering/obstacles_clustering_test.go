@@ -0,0 +1,80 @@
+package obstaclesclustering
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	pc "go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/vision/segmentation"
+)
+
+func TestClusteringSegmentation(t *testing.T) {
+	r := &inject.Robot{}
+	cam := &inject.Camera{}
+	cam.NextPointCloudFunc = func(ctx context.Context) (pc.PointCloud, error) {
+		return nil, errors.New("no pointcloud")
+	}
+	r.ResourceNamesFunc = func() []resource.Name {
+		return []resource.Name{camera.Named("fakeCamera")}
+	}
+	r.ResourceByNameFunc = func(n resource.Name) (resource.Resource, error) {
+		switch n.Name {
+		case "fakeCamera":
+			return cam, nil
+		default:
+			return nil, resource.NewNotFoundError(n)
+		}
+	}
+	params := &segmentation.ClusteringPipelineConfig{
+		Algorithm:       segmentation.DBSCANClustering,
+		DBSCANMinPts:    2,
+		MinPtsInSegment: 3,
+	}
+	// bad registration, no parameters
+	name := vision.Named("test_cs")
+	_, err := registerClusteringSegmenter(context.Background(), name, nil, r)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "cannot be nil")
+	// bad registration, no clustering radius
+	_, err = registerClusteringSegmenter(context.Background(), name, params, r)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "segmenter config error")
+	// successful registration
+	params.ClusteringRadiusMm = 2
+	seg, err := registerClusteringSegmenter(context.Background(), name, params, r)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, seg.Name(), test.ShouldResemble, name)
+
+	props, err := seg.GetProperties(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.ObjectPCDsSupported, test.ShouldEqual, true)
+	test.That(t, props.DetectionSupported, test.ShouldEqual, false)
+
+	// fails since camera cannot generate point clouds
+	_, err = seg.GetObjectPointClouds(context.Background(), "fakeCamera", map[string]interface{}{})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no pointcloud")
+
+	// successful, creates two clusters of points and leaves out a stray one
+	cam.NextPointCloudFunc = func(ctx context.Context) (pc.PointCloud, error) {
+		cloud := pc.New()
+		for z := 1.; z <= 4; z++ {
+			test.That(t, cloud.Set(pc.NewVector(1, 1, z), nil), test.ShouldBeNil)
+			test.That(t, cloud.Set(pc.NewVector(2, 2, 100+z), nil), test.ShouldBeNil)
+		}
+		test.That(t, cloud.Set(pc.NewVector(50, 50, 50), nil), test.ShouldBeNil)
+		return cloud, nil
+	}
+	objects, err := seg.GetObjectPointClouds(context.Background(), "fakeCamera", map[string]interface{}{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(objects), test.ShouldEqual, 2)
+	test.That(t, objects[0].Size(), test.ShouldEqual, 4)
+	test.That(t, objects[1].Size(), test.ShouldEqual, 4)
+}
@@ -0,0 +1,284 @@
+package vision
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/vision"
+)
+
+// The metadata keys with which clients page through the objects of GetObjectPointClouds. The objects of dense clouds
+// can be too large to send in one message, so a client asks for a page of them, possibly without their point clouds,
+// and the server keeps the rest of the segmentation around for the following pages.
+const (
+	// ObjectPageSizeMetadataKey is the most objects a client wants in a response.
+	ObjectPageSizeMetadataKey = "viam-object-page-size"
+	// ObjectPageTokenMetadataKey continues from the page before, without segmenting again.
+	ObjectPageTokenMetadataKey = "viam-object-page-token"
+	// ObjectIndicesMetadataKey asks for the objects of a page token's segmentation at the comma separated indices.
+	ObjectIndicesMetadataKey = "viam-object-indices"
+	// OmitPointCloudsMetadataKey asks for the geometries of objects only, when set to "true".
+	OmitPointCloudsMetadataKey = "viam-omit-point-clouds"
+	// NextObjectPageTokenMetadataKey is the response header with the token of the next page, or of the whole
+	// segmentation once it has been paged through.
+	NextObjectPageTokenMetadataKey = "viam-next-object-page-token"
+	// TotalObjectsMetadataKey is the response header with the number of objects of the segmentation.
+	TotalObjectsMetadataKey = "viam-total-objects"
+)
+
+// objectSnapshotTTL is how long a segmentation is kept for its pages after it was last read.
+const objectSnapshotTTL = time.Minute
+
+// maxObjectSnapshots is how many segmentations are kept at once, the least recently read are dropped first.
+const maxObjectSnapshots = 8
+
+// ObjectPageRequest asks for a page of the objects of GetObjectPointClouds.
+type ObjectPageRequest struct {
+	// PageSize is the most objects to return, all of the remaining ones when zero.
+	PageSize int
+	// PageToken is the NextPageToken of a previous page. The first page segments the camera's point cloud.
+	PageToken string
+	// Indices asks for the objects of the PageToken's segmentation at these indices instead of the next page, such as
+	// to fetch the point clouds of the objects picked from pages without them.
+	Indices []int
+	// OmitPointClouds leaves the point clouds of objects out, keeping their geometries.
+	OmitPointClouds bool
+}
+
+// ObjectPage is a page of the objects of GetObjectPointClouds.
+type ObjectPage struct {
+	Objects []*vision.Object
+	// NextPageToken continues with the next page. Once the last page has been returned it still names the
+	// segmentation so that objects can be fetched by index, but the next page is empty.
+	NextPageToken string
+	// TotalObjects is how many objects the segmentation found.
+	TotalObjects int
+}
+
+// An ObjectPager can page through the objects of GetObjectPointClouds, as the clients of remote vision services can.
+type ObjectPager interface {
+	GetObjectPointCloudsPage(
+		ctx context.Context, cameraName string, req ObjectPageRequest, extra map[string]interface{},
+	) (ObjectPage, error)
+}
+
+// GetObjectPointCloudsPage returns a page of the objects found by a vision service. Services which cannot page,
+// such as those in the same process whose objects need not fit in a message, return every object in one page.
+func GetObjectPointCloudsPage(
+	ctx context.Context, svc Service, cameraName string, req ObjectPageRequest, extra map[string]interface{},
+) (ObjectPage, error) {
+	if pager, ok := svc.(ObjectPager); ok {
+		return pager.GetObjectPointCloudsPage(ctx, cameraName, req, extra)
+	}
+	objects, err := svc.GetObjectPointClouds(ctx, cameraName, extra)
+	if err != nil {
+		return ObjectPage{}, err
+	}
+	return ObjectPage{Objects: objects, TotalObjects: len(objects)}, nil
+}
+
+// outgoingContext returns a context carrying the page request to the server.
+func (req ObjectPageRequest) outgoingContext(ctx context.Context) context.Context {
+	kv := []string{ObjectPageSizeMetadataKey, strconv.Itoa(req.PageSize)}
+	if req.PageToken != "" {
+		kv = append(kv, ObjectPageTokenMetadataKey, req.PageToken)
+	}
+	if len(req.Indices) > 0 {
+		indices := make([]string, 0, len(req.Indices))
+		for _, idx := range req.Indices {
+			indices = append(indices, strconv.Itoa(idx))
+		}
+		kv = append(kv, ObjectIndicesMetadataKey, strings.Join(indices, ","))
+	}
+	if req.OmitPointClouds {
+		kv = append(kv, OmitPointCloudsMetadataKey, "true")
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+// objectPageRequestFromIncomingContext returns the page request of a call, and false if it did not ask for one.
+func objectPageRequestFromIncomingContext(ctx context.Context) (ObjectPageRequest, bool, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ObjectPageRequest{}, false, nil
+	}
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return strings.TrimSpace(values[0])
+		}
+		return ""
+	}
+	var req ObjectPageRequest
+	paged := false
+	if size := first(ObjectPageSizeMetadataKey); size != "" {
+		var err error
+		if req.PageSize, err = strconv.Atoi(size); err != nil || req.PageSize < 0 {
+			return req, false, errors.Errorf("invalid %s %q", ObjectPageSizeMetadataKey, size)
+		}
+		paged = true
+	}
+	if req.PageToken = first(ObjectPageTokenMetadataKey); req.PageToken != "" {
+		paged = true
+	}
+	if indices := first(ObjectIndicesMetadataKey); indices != "" {
+		for _, raw := range strings.Split(indices, ",") {
+			idx, err := strconv.Atoi(strings.TrimSpace(raw))
+			if err != nil {
+				return req, false, errors.Errorf("invalid %s %q", ObjectIndicesMetadataKey, indices)
+			}
+			req.Indices = append(req.Indices, idx)
+		}
+		paged = true
+	}
+	if first(OmitPointCloudsMetadataKey) == "true" {
+		req.OmitPointClouds = true
+		paged = true
+	}
+	return req, paged, nil
+}
+
+// objectSnapshot is a segmentation kept for the pages after its first.
+type objectSnapshot struct {
+	objects  []*vision.Object
+	lastRead time.Time
+}
+
+// objectSnapshotKey names a segmentation by the vision service it is of and its id, so that the token of one
+// service's segmentation cannot read it through another.
+type objectSnapshotKey struct {
+	service string
+	id      string
+}
+
+// objectSnapshots holds the segmentations being paged through.
+type objectSnapshots struct {
+	mu        sync.Mutex
+	snapshots map[objectSnapshotKey]*objectSnapshot
+}
+
+// add keeps a segmentation of a service and returns its id.
+func (s *objectSnapshots) add(service string, objects []*vision.Object) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLocked()
+	if s.snapshots == nil {
+		s.snapshots = map[objectSnapshotKey]*objectSnapshot{}
+	}
+	for len(s.snapshots) >= maxObjectSnapshots {
+		var oldest objectSnapshotKey
+		var oldestRead time.Time
+		for key, snap := range s.snapshots {
+			if oldestRead.IsZero() || snap.lastRead.Before(oldestRead) {
+				oldest, oldestRead = key, snap.lastRead
+			}
+		}
+		delete(s.snapshots, oldest)
+	}
+	id := uuid.NewString()
+	s.snapshots[objectSnapshotKey{service: service, id: id}] = &objectSnapshot{objects: objects, lastRead: time.Now()}
+	return id
+}
+
+// get returns a kept segmentation of a service, refreshing its lifetime. It errors if the segmentation has expired
+// or is of another service.
+func (s *objectSnapshots) get(service, id string) ([]*vision.Object, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLocked()
+	snap, ok := s.snapshots[objectSnapshotKey{service: service, id: id}]
+	if !ok {
+		for key := range s.snapshots {
+			if key.id == id {
+				return nil, status.Errorf(codes.InvalidArgument, "object page token is for vision service %q, not %q", key.service, service)
+			}
+		}
+		return nil, status.Errorf(codes.NotFound, "object page token has expired, start again from the first page")
+	}
+	snap.lastRead = time.Now()
+	return snap.objects, nil
+}
+
+func (s *objectSnapshots) expireLocked() {
+	for key, snap := range s.snapshots {
+		if time.Since(snap.lastRead) > objectSnapshotTTL {
+			delete(s.snapshots, key)
+		}
+	}
+}
+
+// objectPageToken names a segmentation and the offset of a page in it.
+func objectPageToken(id string, offset int) string {
+	return id + "/" + strconv.Itoa(offset)
+}
+
+func parseObjectPageToken(token string) (string, int, error) {
+	id, rawOffset, ok := strings.Cut(token, "/")
+	offset, err := strconv.Atoi(rawOffset)
+	if !ok || err != nil || offset < 0 {
+		return "", 0, errors.Errorf("invalid object page token %q", token)
+	}
+	return id, offset, nil
+}
+
+// pageObjects segments with the named service, or reads a kept segmentation of it, and returns the requested page.
+func (server *serviceServer) pageObjects(
+	ctx context.Context, name string, svc Service, cameraName string, req ObjectPageRequest, extra map[string]interface{},
+) ([]*vision.Object, error) {
+	var id string
+	var offset int
+	var objects []*vision.Object
+	if req.PageToken == "" {
+		if len(req.Indices) > 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "%s needs a %s", ObjectIndicesMetadataKey, ObjectPageTokenMetadataKey)
+		}
+		var err error
+		if objects, err = svc.GetObjectPointClouds(ctx, cameraName, extra); err != nil {
+			return nil, err
+		}
+		id = server.objectSnapshots.add(name, objects)
+	} else {
+		var err error
+		if id, offset, err = parseObjectPageToken(req.PageToken); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if objects, err = server.objectSnapshots.get(name, id); err != nil {
+			return nil, err
+		}
+	}
+
+	var page []*vision.Object
+	next := offset
+	if len(req.Indices) > 0 {
+		for _, idx := range req.Indices {
+			if idx < 0 || idx >= len(objects) {
+				return nil, status.Errorf(codes.OutOfRange, "object index %d is out of range of %d objects", idx, len(objects))
+			}
+			page = append(page, objects[idx])
+		}
+	} else {
+		next = len(objects)
+		if req.PageSize > 0 && offset+req.PageSize < len(objects) {
+			next = offset + req.PageSize
+		}
+		if offset < next {
+			page = objects[offset:next]
+		}
+	}
+	if err := grpc.SetHeader(ctx, metadata.Pairs(
+		NextObjectPageTokenMetadataKey, objectPageToken(id, next),
+		TotalObjectsMetadataKey, strconv.Itoa(len(objects)),
+	)); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
@@ -4,5 +4,8 @@ package register
 import (
 	// for vision models.
 	_ "go.viam.com/rdk/services/vision"
+	_ "go.viam.com/rdk/services/vision/barcode"
+	_ "go.viam.com/rdk/services/vision/fiducial"
 	_ "go.viam.com/rdk/services/vision/mlvision"
+	_ "go.viam.com/rdk/services/vision/tracker"
 )
@@ -7,6 +7,8 @@ import (
 	// for vision models.
 	_ "go.viam.com/rdk/services/vision/colordetector"
 	_ "go.viam.com/rdk/services/vision/detectionstosegments"
+	_ "go.viam.com/rdk/services/vision/objectdetector3d"
+	_ "go.viam.com/rdk/services/vision/obstaclesclustering"
 	_ "go.viam.com/rdk/services/vision/obstaclesdepth"
 	_ "go.viam.com/rdk/services/vision/obstaclesdistance"
 	_ "go.viam.com/rdk/services/vision/obstaclespointcloud"
@@ -9,6 +9,11 @@ import (
 	commonpb "go.viam.com/api/common/v1"
 	camerapb "go.viam.com/api/component/camera/v1"
 	pb "go.viam.com/api/service/vision/v1"
+	goprotoutils "go.viam.com/utils/protoutils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"go.viam.com/rdk/pointcloud"
 	"go.viam.com/rdk/protoutils"
@@ -25,6 +30,8 @@ import (
 type serviceServer struct {
 	pb.UnimplementedVisionServiceServer
 	coll resource.APIResourceCollection[Service]
+
+	objectSnapshots objectSnapshots
 }
 
 // NewRPCServiceServer constructs a vision gRPC service server.
@@ -155,6 +162,7 @@ func clasToProto(classifications classification.Classifications) []*pb.Classific
 
 // GetObjectPointClouds returns an array of objects from the frame from a camera of the underlying robot. A specific MIME type
 // can be requested but may not necessarily be the same one returned. Also returns a Vector3 array of the center points of each object.
+// Clients can page through the objects with the object page metadata keys.
 func (server *serviceServer) GetObjectPointClouds(
 	ctx context.Context,
 	req *pb.GetObjectPointCloudsRequest,
@@ -163,11 +171,20 @@ func (server *serviceServer) GetObjectPointClouds(
 	if err != nil {
 		return nil, err
 	}
-	objects, err := svc.GetObjectPointClouds(ctx, req.CameraName, req.Extra.AsMap())
+	pageReq, paged, err := objectPageRequestFromIncomingContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	var objects []*vision.Object
+	if paged {
+		objects, err = server.pageObjects(ctx, req.Name, svc, req.CameraName, pageReq, req.Extra.AsMap())
+	} else {
+		objects, err = svc.GetObjectPointClouds(ctx, req.CameraName, req.Extra.AsMap())
+	}
 	if err != nil {
 		return nil, err
 	}
-	protoSegments, err := segmentsToProto(req.CameraName, objects)
+	protoSegments, err := segmentsToProto(req.CameraName, objects, pageReq.OmitPointClouds)
 	if err != nil {
 		return nil, err
 	}
@@ -178,16 +195,18 @@ func (server *serviceServer) GetObjectPointClouds(
 	}, nil
 }
 
-func segmentsToProto(frame string, segs []*vision.Object) ([]*commonpb.PointCloudObject, error) {
+// segmentsToProto converts objects to protobuf, leaving their point clouds empty when omitPointClouds is set.
+func segmentsToProto(frame string, segs []*vision.Object, omitPointClouds bool) ([]*commonpb.PointCloudObject, error) {
 	protoSegs := make([]*commonpb.PointCloudObject, 0, len(segs))
 	for _, seg := range segs {
 		var buf bytes.Buffer
 		if seg.PointCloud == nil {
 			seg.PointCloud = pointcloud.New()
 		}
-		err := pointcloud.ToPCD(seg, &buf, pointcloud.PCDBinary)
-		if err != nil {
-			return nil, err
+		if !omitPointClouds {
+			if err := pointcloud.ToPCD(seg, &buf, pointcloud.PCDBinary); err != nil {
+				return nil, err
+			}
 		}
 		ps := &commonpb.PointCloudObject{
 			PointCloud: buf.Bytes(),
@@ -248,21 +267,37 @@ func (server *serviceServer) CaptureAllFromCamera(
 		return nil, err
 	}
 
-	objProto, err := segmentsToProto(req.CameraName, capt.Objects)
+	objProto, err := segmentsToProto(req.CameraName, capt.Objects, false)
 	if err != nil {
 		return nil, err
 	}
 
+	// the response has no metadata, so it is sent in the headers
+	if header := capt.Metadata.GRPCHeader(); len(header) > 0 {
+		if err := grpc.SetHeader(ctx, header); err != nil {
+			return nil, err
+		}
+	}
+
 	imgProto, err := imageToProto(ctx, capt.Image, req.CameraName)
 	if err != nil {
 		return nil, err
 	}
 
+	var extProto *structpb.Struct
+	if capt.Extra != nil {
+		extProto, err = goprotoutils.StructToStructPb(capt.Extra)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &pb.CaptureAllFromCameraResponse{
 		Image:           imgProto,
 		Detections:      detsToProto(capt.Detections),
 		Classifications: clasToProto(capt.Classifications),
 		Objects:         objProto,
+		Extra:           extProto,
 	}, nil
 }
 
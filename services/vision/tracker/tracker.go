@@ -0,0 +1,222 @@
+// Package tracker wraps a detector in a vision service which follows the detected objects across frames, giving
+// each a persistent track ID, so that downstream logic can follow specific objects instead of stateless detections.
+package tracker
+
+import (
+	"context"
+	"image"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/utils"
+	"go.viam.com/rdk/vision/objectdetection"
+	"go.viam.com/rdk/vision/tracking"
+	"go.viam.com/rdk/vision/viscapture"
+)
+
+var model = resource.DefaultModelFamily.WithModel("tracker")
+
+// TracksExtraKey is the key of the tracks in the Extra of the captures of CaptureAllFromCamera. The tracks are a
+// list of maps, with the keys id, label, score, x_min, y_min, x_max, y_max, velocity_x_px_per_sec,
+// velocity_y_px_per_sec, age_sec, hits and missed_frames.
+const TracksExtraKey = "tracks"
+
+func init() {
+	resource.RegisterService(vision.API, model, resource.Registration[vision.Service, *Config]{
+		DeprecatedRobotConstructor: func(
+			ctx context.Context, r any, c resource.Config, logger logging.Logger,
+		) (vision.Service, error) {
+			attrs, err := resource.NativeConfig[*Config](c)
+			if err != nil {
+				return nil, err
+			}
+			actualR, err := utils.AssertType[robot.Robot](r)
+			if err != nil {
+				return nil, err
+			}
+			return newTracker(ctx, c.ResourceName(), attrs, actualR)
+		},
+	})
+}
+
+// Config is the config of a tracker.
+type Config struct {
+	DetectorName string `json:"detector_name"`
+	// ConfidenceThresh drops detections scored lower before they are tracked.
+	ConfidenceThresh float64 `json:"confidence_threshold_pct,omitempty"`
+	IoUThreshold     float64 `json:"iou_threshold,omitempty"`
+	MinHits          int     `json:"min_hits,omitempty"`
+	MaxMissedFrames  int     `json:"max_missed_frames,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.DetectorName == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "detector_name")
+	}
+	if conf.ConfidenceThresh < 0 || conf.ConfidenceThresh > 1 {
+		return nil, resource.NewConfigValidationError(path, errors.New("confidence_threshold_pct must be between 0 and 1"))
+	}
+	if conf.IoUThreshold < 0 || conf.IoUThreshold > 1 {
+		return nil, resource.NewConfigValidationError(path, errors.New("iou_threshold must be between 0 and 1"))
+	}
+	if conf.MinHits < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("min_hits cannot be negative"))
+	}
+	if conf.MaxMissedFrames < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("max_missed_frames cannot be negative"))
+	}
+	return []string{resource.NewName(vision.API, conf.DetectorName).String()}, nil
+}
+
+// tracker keeps the tracks of each camera apart, and those of images which are not from a camera under the empty
+// camera name.
+type tracker struct {
+	vision.Service
+	detector vision.Service
+	conf     Config
+
+	mu       sync.Mutex
+	trackers map[string]*tracking.Tracker
+}
+
+func newTracker(ctx context.Context, name resource.Name, conf *Config, r robot.Robot) (vision.Service, error) {
+	_, span := trace.StartSpan(ctx, "service::vision::newTracker")
+	defer span.End()
+	detector, err := vision.FromRobot(r, conf.DetectorName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find necessary dependency, detector %q", conf.DetectorName)
+	}
+	t := &tracker{
+		detector: detector,
+		conf:     *conf,
+		trackers: map[string]*tracking.Tracker{},
+	}
+	detect := func(ctx context.Context, img image.Image) ([]objectdetection.Detection, error) {
+		return t.Detections(ctx, img, nil)
+	}
+	t.Service, err = vision.NewService(name, r, nil, nil, detect, nil)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Detections returns the detections of the tracks which were detected in the given image.
+func (t *tracker) Detections(
+	ctx context.Context,
+	img image.Image,
+	extra map[string]interface{},
+) ([]objectdetection.Detection, error) {
+	ctx, span := trace.StartSpan(ctx, "service::vision::tracker::Detections")
+	defer span.End()
+	detections, err := t.detector.Detections(ctx, img, extra)
+	if err != nil {
+		return nil, err
+	}
+	return detectedTracks(t.update("", detections)), nil
+}
+
+// DetectionsFromCamera returns the detections of the tracks which were detected in the next image from the given
+// camera.
+func (t *tracker) DetectionsFromCamera(
+	ctx context.Context,
+	cameraName string,
+	extra map[string]interface{},
+) ([]objectdetection.Detection, error) {
+	ctx, span := trace.StartSpan(ctx, "service::vision::tracker::DetectionsFromCamera")
+	defer span.End()
+	detections, err := t.detector.DetectionsFromCamera(ctx, cameraName, extra)
+	if err != nil {
+		return nil, err
+	}
+	return detectedTracks(t.update(cameraName, detections)), nil
+}
+
+// CaptureAllFromCamera captures from the detector, returning the detections of the tracks which were detected and
+// all of the tracks, under TracksExtraKey in the Extra of the capture.
+func (t *tracker) CaptureAllFromCamera(
+	ctx context.Context,
+	cameraName string,
+	opts viscapture.CaptureOptions,
+	extra map[string]interface{},
+) (viscapture.VisCapture, error) {
+	ctx, span := trace.StartSpan(ctx, "service::vision::tracker::CaptureAllFromCamera")
+	defer span.End()
+	detectorOpts := opts
+	detectorOpts.ReturnDetections = true
+	capt, err := t.detector.CaptureAllFromCamera(ctx, cameraName, detectorOpts, extra)
+	if err != nil {
+		return viscapture.VisCapture{}, err
+	}
+	tracks := t.update(cameraName, capt.Detections)
+	capt.Detections = nil
+	if opts.ReturnDetections {
+		capt.Detections = detectedTracks(tracks)
+	}
+	if capt.Extra == nil {
+		capt.Extra = map[string]interface{}{}
+	}
+	capt.Extra[TracksExtraKey] = tracksToExtra(tracks)
+	return capt, nil
+}
+
+func (t *tracker) update(cameraName string, detections []objectdetection.Detection) []tracking.Track {
+	filtered := make([]objectdetection.Detection, 0, len(detections))
+	for _, d := range detections {
+		if d.Score() >= t.conf.ConfidenceThresh {
+			filtered = append(filtered, d)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tr, ok := t.trackers[cameraName]
+	if !ok {
+		tr = tracking.NewTracker(tracking.Config{
+			IoUThreshold:    t.conf.IoUThreshold,
+			MinHits:         t.conf.MinHits,
+			MaxMissedFrames: t.conf.MaxMissedFrames,
+		})
+		t.trackers[cameraName] = tr
+	}
+	return tr.Update(filtered, time.Now())
+}
+
+func detectedTracks(tracks []tracking.Track) []objectdetection.Detection {
+	detections := make([]objectdetection.Detection, 0, len(tracks))
+	for _, track := range tracks {
+		if track.MissedFrames == 0 {
+			detections = append(detections, track.Detection())
+		}
+	}
+	return detections
+}
+
+func tracksToExtra(tracks []tracking.Track) []interface{} {
+	extra := make([]interface{}, 0, len(tracks))
+	for _, track := range tracks {
+		extra = append(extra, map[string]interface{}{
+			"id":                    track.ID,
+			"label":                 track.Label,
+			"score":                 track.Score,
+			"x_min":                 track.BoundingBox.Min.X,
+			"y_min":                 track.BoundingBox.Min.Y,
+			"x_max":                 track.BoundingBox.Max.X,
+			"y_max":                 track.BoundingBox.Max.Y,
+			"velocity_x_px_per_sec": track.VelocityX,
+			"velocity_y_px_per_sec": track.VelocityY,
+			"age_sec":               track.Age.Seconds(),
+			"hits":                  track.Hits,
+			"missed_frames":         track.MissedFrames,
+		})
+	}
+	return extra
+}
@@ -0,0 +1,98 @@
+package tracker
+
+import (
+	"context"
+	"image"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/vision/objectdetection"
+	"go.viam.com/rdk/vision/viscapture"
+)
+
+func TestConfigValidate(t *testing.T) {
+	conf := &Config{}
+	_, err := conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "detector_name")
+
+	conf.DetectorName = "detector"
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"rdk:service:vision/detector"})
+
+	conf.IoUThreshold = 1.5
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestTracker(t *testing.T) {
+	ctx := context.Background()
+	frame := 0
+	detections := func() []objectdetection.Detection {
+		frame++
+		return []objectdetection.Detection{
+			objectdetection.NewDetection(image.Rect(10+frame, 10, 50+frame, 90), 0.9, "person"),
+			objectdetection.NewDetection(image.Rect(200, 200, 250, 240), 0.1, "ghost"),
+		}
+	}
+	detector := &inject.VisionService{}
+	detector.DetectionsFromCameraFunc = func(
+		ctx context.Context, cameraName string, extra map[string]interface{},
+	) ([]objectdetection.Detection, error) {
+		return detections(), nil
+	}
+	detector.CaptureAllFromCameraFunc = func(
+		ctx context.Context, cameraName string, opts viscapture.CaptureOptions, extra map[string]interface{},
+	) (viscapture.VisCapture, error) {
+		test.That(t, opts.ReturnDetections, test.ShouldBeTrue)
+		return viscapture.VisCapture{Detections: detections()}, nil
+	}
+	r := &inject.Robot{}
+	r.ResourceByNameFunc = func(n resource.Name) (resource.Resource, error) {
+		if n == vision.Named("detector") {
+			return detector, nil
+		}
+		return nil, resource.NewNotFoundError(n)
+	}
+
+	_, err := newTracker(ctx, vision.Named("tracker"), &Config{DetectorName: "missing"}, r)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "could not find necessary dependency")
+
+	svc, err := newTracker(ctx, vision.Named("tracker"), &Config{DetectorName: "detector", ConfidenceThresh: 0.5, MinHits: 2}, r)
+	test.That(t, err, test.ShouldBeNil)
+	props, err := svc.GetProperties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.DetectionSupported, test.ShouldBeTrue)
+
+	dets, err := svc.DetectionsFromCamera(ctx, "cam", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dets, test.ShouldBeEmpty)
+	dets, err = svc.DetectionsFromCamera(ctx, "cam", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dets, test.ShouldHaveLength, 1)
+	test.That(t, dets[0].Label(), test.ShouldEqual, "person")
+
+	capt, err := svc.CaptureAllFromCamera(ctx, "cam", viscapture.CaptureOptions{}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, capt.Detections, test.ShouldBeNil)
+	tracks, ok := capt.Extra[TracksExtraKey].([]interface{})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, tracks, test.ShouldHaveLength, 1)
+	track := tracks[0].(map[string]interface{})
+	test.That(t, track["id"], test.ShouldEqual, 1)
+	test.That(t, track["label"], test.ShouldEqual, "person")
+	test.That(t, track["hits"], test.ShouldEqual, 3)
+	test.That(t, track["missed_frames"], test.ShouldEqual, 0)
+
+	// another camera has tracks of its own
+	capt, err = svc.CaptureAllFromCamera(ctx, "other", viscapture.CaptureOptions{ReturnDetections: true}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, capt.Detections, test.ShouldBeEmpty)
+	test.That(t, capt.Extra[TracksExtraKey], test.ShouldBeEmpty)
+}
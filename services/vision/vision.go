@@ -337,7 +337,7 @@ func (vm *vizModel) CaptureAllFromCamera(
 	if err != nil {
 		return viscapture.VisCapture{}, errors.Wrapf(err, "could not find camera named %s", cameraName)
 	}
-	img, release, err := camera.ReadImage(ctx, cam)
+	img, md, release, err := camera.ReadImageWithMetadata(ctx, cam)
 	if err != nil {
 		return viscapture.VisCapture{}, errors.Wrapf(err, "could not get image from %s", cameraName)
 	}
@@ -388,6 +388,7 @@ func (vm *vizModel) CaptureAllFromCamera(
 		Detections:      detections,
 		Classifications: classifications,
 		Objects:         objPCD,
+		Metadata:        md,
 	}, nil
 }
 
@@ -0,0 +1,277 @@
+package spatialmath
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/utils"
+)
+
+// The WGS84 ellipsoid, which GPS positions are given on, in meters.
+const (
+	wgs84SemiMajorAxis  = 6378137.0
+	wgs84Flattening     = 1 / 298.257223563
+	wgs84EccentricitySq = wgs84Flattening * (2 - wgs84Flattening)
+)
+
+// The universal transverse mercator projection, which is defined from 80°S to 84°N.
+const (
+	utmScale         = 0.9996
+	utmFalseEasting  = 500000.0
+	utmFalseNorthing = 10000000.0
+	utmMinLat        = -80.0
+	utmMaxLat        = 84.0
+)
+
+// utmSeries are the coefficients of the Krüger series of the transverse mercator projection, accurate to under a millimeter
+// within a zone.
+var utmSeries = func() (s struct {
+	n, a               float64
+	alpha, beta, delta [3]float64
+},
+) {
+	n := wgs84Flattening / (2 - wgs84Flattening)
+	s.n = n
+	n2, n3 := n*n, n*n*n
+	s.a = wgs84SemiMajorAxis / (1 + n) * (1 + n2/4 + n2*n2/64)
+	s.alpha = [3]float64{n/2 - 2*n2/3 + 5*n3/16, 13*n2/48 - 3*n3/5, 61 * n3 / 240}
+	s.beta = [3]float64{n/2 - 2*n2/3 + 37*n3/96, n2/48 + n3/15, 17 * n3 / 480}
+	s.delta = [3]float64{2*n - 2*n2/3 - 2*n3, 7*n2/3 - 8*n3/5, 56 * n3 / 15}
+	return s
+}()
+
+// A UTMPoint is a position in the universal transverse mercator grid, in meters east and north within a zone.
+type UTMPoint struct {
+	Zone int
+	// North is whether the point is in the northern hemisphere, whose northings start at the equator rather than
+	// 10,000km south of it.
+	North    bool
+	Easting  float64
+	Northing float64
+}
+
+// String returns the point as "18N 585628.1 4511322.4".
+func (u UTMPoint) String() string {
+	hemisphere := "S"
+	if u.North {
+		hemisphere = "N"
+	}
+	return fmt.Sprintf("%d%s %.1f %.1f", u.Zone, hemisphere, u.Easting, u.Northing)
+}
+
+// UTMZone returns the zone of the universal transverse mercator grid a point is in, including the exceptions around
+// Norway and Svalbard.
+func UTMZone(p *geo.Point) int {
+	lat, lng := p.Lat(), normalizeLongitude(p.Lng())
+	switch {
+	case lat >= 56 && lat < 64 && lng >= 3 && lng < 12:
+		return 32
+	case lat >= 72 && lat < 84 && lng >= 0 && lng < 42:
+		// zones 32, 34 and 36 are not used on Svalbard
+		switch {
+		case lng < 9:
+			return 31
+		case lng < 21:
+			return 33
+		case lng < 33:
+			return 35
+		default:
+			return 37
+		}
+	}
+	return min(int((lng+180)/6)+1, 60)
+}
+
+// GeoPointToUTM returns the universal transverse mercator coordinates of a point in the zone it is in.
+func GeoPointToUTM(p *geo.Point) (UTMPoint, error) {
+	return GeoPointToUTMZone(p, UTMZone(p))
+}
+
+// GeoPointToUTMZone returns the universal transverse mercator coordinates of a point in the given zone, such as to
+// keep points on both sides of a zone boundary in one grid.
+func GeoPointToUTMZone(p *geo.Point, zone int) (UTMPoint, error) {
+	if zone < 1 || zone > 60 {
+		return UTMPoint{}, errors.Errorf("UTM zone must be from 1 to 60, got %d", zone)
+	}
+	if p.Lat() < utmMinLat || p.Lat() > utmMaxLat || math.IsNaN(p.Lat()) {
+		return UTMPoint{}, errors.Errorf("UTM is only defined from latitude %v to %v, got %v", utmMinLat, utmMaxLat, p.Lat())
+	}
+	phi := utils.DegToRad(p.Lat())
+	lambda := utils.DegToRad(normalizeLongitude(p.Lng() - utmCentralMeridian(zone)))
+
+	c := 2 * math.Sqrt(utmSeries.n) / (1 + utmSeries.n)
+	t := math.Sinh(math.Atanh(math.Sin(phi)) - c*math.Atanh(c*math.Sin(phi)))
+	xi := math.Atan2(t, math.Cos(lambda))
+	eta := math.Atanh(math.Sin(lambda) / math.Sqrt(1+t*t))
+
+	easting, northing := eta, xi
+	for j, alpha := range utmSeries.alpha {
+		k := float64(2 * (j + 1))
+		easting += alpha * math.Cos(k*xi) * math.Sinh(k*eta)
+		northing += alpha * math.Sin(k*xi) * math.Cosh(k*eta)
+	}
+	u := UTMPoint{
+		Zone:     zone,
+		North:    p.Lat() >= 0,
+		Easting:  utmFalseEasting + utmScale*utmSeries.a*easting,
+		Northing: utmScale * utmSeries.a * northing,
+	}
+	if !u.North {
+		u.Northing += utmFalseNorthing
+	}
+	return u, nil
+}
+
+// UTMToGeoPoint returns the latitude and longitude of universal transverse mercator coordinates.
+func UTMToGeoPoint(u UTMPoint) (*geo.Point, error) {
+	if u.Zone < 1 || u.Zone > 60 {
+		return nil, errors.Errorf("UTM zone must be from 1 to 60, got %d", u.Zone)
+	}
+	northing := u.Northing
+	if !u.North {
+		northing -= utmFalseNorthing
+	}
+	xi := northing / (utmScale * utmSeries.a)
+	eta := (u.Easting - utmFalseEasting) / (utmScale * utmSeries.a)
+
+	xiPrime, etaPrime := xi, eta
+	for j, beta := range utmSeries.beta {
+		k := float64(2 * (j + 1))
+		xiPrime -= beta * math.Sin(k*xi) * math.Cosh(k*eta)
+		etaPrime -= beta * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+	chi := math.Asin(math.Sin(xiPrime) / math.Cosh(etaPrime))
+	phi := chi
+	for j, delta := range utmSeries.delta {
+		phi += delta * math.Sin(float64(2*(j+1))*chi)
+	}
+	lambda := math.Atan2(math.Sinh(etaPrime), math.Cos(xiPrime))
+	return geo.NewPoint(utils.RadToDeg(phi), normalizeLongitude(utmCentralMeridian(u.Zone)+utils.RadToDeg(lambda))), nil
+}
+
+func utmCentralMeridian(zone int) float64 {
+	return float64(zone*6 - 183)
+}
+
+// normalizeLongitude returns a longitude in degrees from -180 up to 180.
+func normalizeLongitude(lng float64) float64 {
+	return normalizeAngle(lng+180) - 180
+}
+
+// A LocalTangentPlane is an east, north, up frame tangent to the WGS84 ellipsoid at an origin, in millimeters. Unlike
+// GeoPointToPoint, which approximates the earth as a sphere, its conversions are exact in three dimensions and invert
+// each other, with points far from the origin falling below the plane as the earth curves away.
+type LocalTangentPlane struct {
+	origin         *geo.Point
+	originAltitude float64
+	// originECEF is the earth centered, earth fixed position of the origin in meters, and east, north and up are the
+	// directions of the frame's axes in it.
+	originECEF      r3.Vector
+	east, north, up r3.Vector
+}
+
+// NewLocalTangentPlane returns the frame tangent to the ellipsoid at the origin, whose altitude in meters is the up
+// coordinate 0.
+func NewLocalTangentPlane(origin *geo.Point, altitude float64) *LocalTangentPlane {
+	sinPhi, cosPhi := math.Sincos(utils.DegToRad(origin.Lat()))
+	sinLambda, cosLambda := math.Sincos(utils.DegToRad(origin.Lng()))
+	return &LocalTangentPlane{
+		origin:         origin,
+		originAltitude: altitude,
+		originECEF:     geodeticToECEF(origin.Lat(), origin.Lng(), altitude),
+		east:           r3.Vector{X: -sinLambda, Y: cosLambda},
+		north:          r3.Vector{X: -sinPhi * cosLambda, Y: -sinPhi * sinLambda, Z: cosPhi},
+		up:             r3.Vector{X: cosPhi * cosLambda, Y: cosPhi * sinLambda, Z: sinPhi},
+	}
+}
+
+// Origin returns the point the frame is tangent at and its altitude in meters.
+func (ltp *LocalTangentPlane) Origin() (*geo.Point, float64) {
+	return ltp.origin, ltp.originAltitude
+}
+
+// GeoPointToPoint returns the position in millimeters east, north and up of a point at an altitude in meters.
+func (ltp *LocalTangentPlane) GeoPointToPoint(p *geo.Point, altitude float64) r3.Vector {
+	d := geodeticToECEF(p.Lat(), p.Lng(), altitude).Sub(ltp.originECEF)
+	return r3.Vector{X: d.Dot(ltp.east), Y: d.Dot(ltp.north), Z: d.Dot(ltp.up)}.Mul(1000)
+}
+
+// PointToGeoPoint returns the point and altitude in meters of a position in millimeters east, north and up.
+func (ltp *LocalTangentPlane) PointToGeoPoint(v r3.Vector) (*geo.Point, float64) {
+	v = v.Mul(1e-3)
+	ecef := ltp.originECEF.Add(ltp.east.Mul(v.X)).Add(ltp.north.Mul(v.Y)).Add(ltp.up.Mul(v.Z))
+	lat, lng, altitude := ecefToGeodetic(ecef)
+	return geo.NewPoint(lat, lng), altitude
+}
+
+// GeoPointToPlanePoint returns the position in millimeters east and north of a point, leaving out how far the ground
+// falls away below the plane, for planning across the ground in two dimensions.
+func (ltp *LocalTangentPlane) GeoPointToPlanePoint(p *geo.Point) r3.Vector {
+	v := ltp.GeoPointToPoint(p, ltp.originAltitude)
+	return r3.Vector{X: v.X, Y: v.Y}
+}
+
+// GeoGeometriesToGeometries returns the geometries of the GeoGeometries, each moved to its position on the plane.
+func (ltp *LocalTangentPlane) GeoGeometriesToGeometries(obstacles []*GeoGeometry) []Geometry {
+	geoms := []Geometry{}
+	for _, v := range obstacles {
+		relativePose := NewPoseFromPoint(ltp.GeoPointToPlanePoint(v.location))
+		for _, geom := range v.geometries {
+			geoms = append(geoms, geom.Transform(relativePose))
+		}
+	}
+	return geoms
+}
+
+// GeoPoseToPose returns the pose of a GeoPose at an altitude in meters, whose heading turns the pose about up.
+func (ltp *LocalTangentPlane) GeoPoseToPose(gp *GeoPose, altitude float64) Pose {
+	// headings are left handed from north, and orientations right handed
+	return NewPose(
+		ltp.GeoPointToPoint(gp.Location(), altitude),
+		&OrientationVectorDegrees{OZ: 1, Theta: normalizeAngle(-gp.Heading())},
+	)
+}
+
+// PoseToGeoPose returns the GeoPose and altitude in meters of a pose in the frame.
+func (ltp *LocalTangentPlane) PoseToGeoPose(pose Pose) (*GeoPose, float64) {
+	p, altitude := ltp.PointToGeoPoint(pose.Point())
+	return NewGeoPose(p, normalizeAngle(-pose.Orientation().OrientationVectorDegrees().Theta)), altitude
+}
+
+// geodeticToECEF returns the earth centered, earth fixed position in meters of a latitude and longitude in degrees
+// and altitude in meters.
+func geodeticToECEF(lat, lng, altitude float64) r3.Vector {
+	sinPhi, cosPhi := math.Sincos(utils.DegToRad(lat))
+	sinLambda, cosLambda := math.Sincos(utils.DegToRad(lng))
+	n := wgs84SemiMajorAxis / math.Sqrt(1-wgs84EccentricitySq*sinPhi*sinPhi)
+	return r3.Vector{
+		X: (n + altitude) * cosPhi * cosLambda,
+		Y: (n + altitude) * cosPhi * sinLambda,
+		Z: (n*(1-wgs84EccentricitySq) + altitude) * sinPhi,
+	}
+}
+
+// ecefToGeodetic returns the latitude and longitude in degrees and altitude in meters of an earth centered, earth
+// fixed position in meters.
+func ecefToGeodetic(v r3.Vector) (float64, float64, float64) {
+	p := math.Hypot(v.X, v.Y)
+	phi := math.Atan2(v.Z, p*(1-wgs84EccentricitySq))
+	var n float64
+	// converges to well under a millimeter near the surface
+	for i := 0; i < 6; i++ {
+		sinPhi := math.Sin(phi)
+		n = wgs84SemiMajorAxis / math.Sqrt(1-wgs84EccentricitySq*sinPhi*sinPhi)
+		phi = math.Atan2(v.Z+wgs84EccentricitySq*n*sinPhi, p)
+	}
+	var altitude float64
+	if cosPhi := math.Cos(phi); cosPhi > 1e-9 {
+		altitude = p/cosPhi - n
+	} else {
+		altitude = math.Abs(v.Z) - n*(1-wgs84EccentricitySq)
+	}
+	return utils.RadToDeg(phi), utils.RadToDeg(math.Atan2(v.Y, v.X)), altitude
+}
@@ -0,0 +1,147 @@
+package spatialmath
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/utils"
+)
+
+// meridianArc integrates the distance in meters along the WGS84 meridian from the equator to a latitude.
+func meridianArc(lat float64) float64 {
+	const steps = 100000
+	phi := utils.DegToRad(lat)
+	var arc float64
+	for i := 0; i < steps; i++ {
+		sinPhi := math.Sin((float64(i) + 0.5) / steps * phi)
+		arc += wgs84SemiMajorAxis * (1 - wgs84EccentricitySq) / math.Pow(1-wgs84EccentricitySq*sinPhi*sinPhi, 1.5) * phi / steps
+	}
+	return arc
+}
+
+func TestUTM(t *testing.T) {
+	// on the central meridian the easting is the false easting and the northing the scaled meridian arc
+	u, err := GeoPointToUTM(geo.NewPoint(45, -75))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, u.Zone, test.ShouldEqual, 18)
+	test.That(t, u.North, test.ShouldBeTrue)
+	test.That(t, u.Easting, test.ShouldAlmostEqual, 500000, 1e-6)
+	test.That(t, u.Northing, test.ShouldAlmostEqual, utmScale*meridianArc(45), 1e-3)
+
+	u, err = GeoPointToUTM(geo.NewPoint(-33.5, 141))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, u.Zone, test.ShouldEqual, 54)
+	test.That(t, u.North, test.ShouldBeFalse)
+	test.That(t, u.Northing, test.ShouldAlmostEqual, utmFalseNorthing-utmScale*meridianArc(33.5), 1e-3)
+	test.That(t, u.String(), test.ShouldStartWith, "54S 500000.0 ")
+
+	// points either side of a central meridian mirror each other
+	east, err := GeoPointToUTM(geo.NewPoint(40.7, -72.5))
+	test.That(t, err, test.ShouldBeNil)
+	west, err := GeoPointToUTM(geo.NewPoint(40.7, -77.5))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, east.Easting-500000, test.ShouldAlmostEqual, 500000-west.Easting, 1e-6)
+	test.That(t, east.Northing, test.ShouldAlmostEqual, west.Northing, 1e-6)
+
+	for _, p := range []*geo.Point{
+		geo.NewPoint(40.748440, -73.984559),
+		geo.NewPoint(-33.856784, 151.215297),
+		geo.NewPoint(0.5, 179.9),
+		geo.NewPoint(83.9, -30),
+		geo.NewPoint(-79.9, 2.9),
+	} {
+		u, err := GeoPointToUTM(p)
+		test.That(t, err, test.ShouldBeNil)
+		back, err := UTMToGeoPoint(u)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, back.Lat(), test.ShouldAlmostEqual, p.Lat(), 1e-8)
+		test.That(t, back.Lng(), test.ShouldAlmostEqual, p.Lng(), 1e-8)
+	}
+
+	// a point can be kept in the grid of a neighboring zone
+	u, err = GeoPointToUTMZone(geo.NewPoint(40.7, -71.9), 18)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, u.Easting, test.ShouldBeGreaterThan, 750000)
+	back, err := UTMToGeoPoint(u)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, back.Lng(), test.ShouldAlmostEqual, -71.9, 1e-8)
+
+	test.That(t, UTMZone(geo.NewPoint(60, 5)), test.ShouldEqual, 32)
+	test.That(t, UTMZone(geo.NewPoint(78, 15)), test.ShouldEqual, 33)
+	test.That(t, UTMZone(geo.NewPoint(0, 180)), test.ShouldEqual, 1)
+	test.That(t, UTMZone(geo.NewPoint(0, 179.99)), test.ShouldEqual, 60)
+
+	_, err = GeoPointToUTM(geo.NewPoint(85, 0))
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = GeoPointToUTMZone(geo.NewPoint(0, 0), 61)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = UTMToGeoPoint(UTMPoint{})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestLocalTangentPlane(t *testing.T) {
+	origin := geo.NewPoint(40.7, -74)
+	ltp := NewLocalTangentPlane(origin, 10)
+	p, alt := ltp.Origin()
+	test.That(t, p, test.ShouldEqual, origin)
+	test.That(t, alt, test.ShouldEqual, 10)
+
+	test.That(t, R3VectorAlmostEqual(ltp.GeoPointToPoint(origin, 10), r3.Vector{}, 1e-6), test.ShouldBeTrue)
+	test.That(t, R3VectorAlmostEqual(ltp.GeoPointToPoint(origin, 12), r3.Vector{Z: 2000}, 1e-6), test.ShouldBeTrue)
+
+	// a point due north is along the north axis, at the meridian arc away
+	north := ltp.GeoPointToPoint(geo.NewPoint(40.701, -74), 10)
+	test.That(t, north.X, test.ShouldAlmostEqual, 0, 1e-6)
+	test.That(t, north.Y, test.ShouldAlmostEqual, 1000*(meridianArc(40.701)-meridianArc(40.7)), 1)
+	east := ltp.GeoPointToPoint(geo.NewPoint(40.7, -73.999), 10)
+	test.That(t, east.X, test.ShouldBeGreaterThan, 0)
+	test.That(t, east.Y, test.ShouldAlmostEqual, 0, 1)
+
+	// the conversions invert each other, and agree with the spherical approximation nearby to within its error
+	for _, v := range []r3.Vector{{X: 1000, Y: 2000, Z: 300}, {X: -5e6, Y: 3e6, Z: 0}, {X: 2e8, Y: -1e8, Z: -4e6}} {
+		p, alt := ltp.PointToGeoPoint(v)
+		test.That(t, R3VectorAlmostEqual(ltp.GeoPointToPoint(p, alt), v, 1e-3), test.ShouldBeTrue)
+	}
+	p, _ = ltp.PointToGeoPoint(r3.Vector{X: 30000, Y: 40000})
+	approx := GeoPointToPoint(p, origin)
+	test.That(t, approx.X, test.ShouldAlmostEqual, 30000, 300)
+	test.That(t, approx.Y, test.ShouldAlmostEqual, 40000, 400)
+
+	pose := NewPose(r3.Vector{X: 1000, Y: -2000}, &OrientationVectorDegrees{OZ: 1, Theta: 90})
+	gp, alt := ltp.PoseToGeoPose(pose)
+	test.That(t, gp.Heading(), test.ShouldAlmostEqual, 270)
+	test.That(t, alt, test.ShouldAlmostEqual, 10, 1e-3)
+	test.That(t, PoseAlmostEqualEps(ltp.GeoPoseToPose(gp, alt), pose, 1e-3), test.ShouldBeTrue)
+}
+
+func TestLocalTangentPlaneAccuracy(t *testing.T) {
+	// A few kilometers from the origin the plane stays within a millimeter of the distance along the ellipsoid, where
+	// the spherical approximation is meters off.
+	origin := geo.NewPoint(40.7, -74)
+	ltp := NewLocalTangentPlane(origin, 0)
+	for _, lat := range []float64{40.73, 40.67} {
+		p := geo.NewPoint(lat, -74)
+		arc := 1000 * (meridianArc(lat) - meridianArc(40.7))
+		test.That(t, math.Abs(arc), test.ShouldBeGreaterThan, 3e6)
+
+		planar := ltp.GeoPointToPlanePoint(p)
+		test.That(t, planar.X, test.ShouldAlmostEqual, 0, 1e-3)
+		test.That(t, planar.Y, test.ShouldAlmostEqual, arc, 1)
+		test.That(t, planar.Z, test.ShouldEqual, 0)
+
+		spherical := GeoPointToPoint(p, origin)
+		test.That(t, math.Abs(spherical.Y-arc), test.ShouldBeGreaterThan, 1000)
+	}
+
+	// obstacles are moved to their position on the plane
+	box, err := NewBox(NewZeroPose(), r3.Vector{X: 10, Y: 10, Z: 10}, "box")
+	test.That(t, err, test.ShouldBeNil)
+	location := geo.NewPoint(40.7, -73.97)
+	geoms := ltp.GeoGeometriesToGeometries([]*GeoGeometry{NewGeoGeometry(location, []Geometry{box})})
+	test.That(t, geoms, test.ShouldHaveLength, 1)
+	test.That(t, R3VectorAlmostEqual(geoms[0].Pose().Point(), ltp.GeoPointToPlanePoint(location), 1e-6), test.ShouldBeTrue)
+}
@@ -144,6 +144,7 @@ func GeoPoseToPose(point, origin *GeoPose) Pose {
 
 // GeoPointToPoint returns the point (r3.Vector) which translates the origin to the destination geopoint
 // Because the function we use to project a point on a spheroid to a plane is nonlinear, we linearize it about a specified origin point.
+// It approximates the earth as a sphere, see LocalTangentPlane for conversions on the WGS84 ellipsoid.
 func GeoPointToPoint(point, origin *geo.Point) r3.Vector {
 	latDist, lngDist := GetCartesianDistance(origin, point)
 	azimuth := origin.BearingTo(point)
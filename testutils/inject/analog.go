@@ -9,10 +9,11 @@ import (
 // Analog is an injected analog pin.
 type Analog struct {
 	board.Analog
-	ReadFunc  func(ctx context.Context, extra map[string]interface{}) (board.AnalogValue, error)
-	readCap   []interface{}
-	WriteFunc func(ctx context.Context, value int, extra map[string]interface{}) error
-	writeCap  []interface{}
+	ReadFunc        func(ctx context.Context, extra map[string]interface{}) (board.AnalogValue, error)
+	readCap         []interface{}
+	WriteFunc       func(ctx context.Context, value int, extra map[string]interface{}) error
+	writeCap        []interface{}
+	ReadSamplesFunc func(ctx context.Context, count int, rateHz float64, extra map[string]interface{}) (board.AnalogSamples, error)
 }
 
 // Read calls the injected Read or the real version.
@@ -33,6 +34,20 @@ func (a *Analog) ReadCap() []interface{} {
 	return a.readCap
 }
 
+// ReadSamples calls the injected ReadSamples or the real version.
+func (a *Analog) ReadSamples(
+	ctx context.Context, count int, rateHz float64, extra map[string]interface{},
+) (board.AnalogSamples, error) {
+	if a.ReadSamplesFunc == nil {
+		if sampler, ok := a.Analog.(board.AnalogSampler); ok {
+			return sampler.ReadSamples(ctx, count, rateHz, extra)
+		}
+		// poll the injected Read
+		return board.PollAnalogSamples(ctx, a, count, rateHz, extra)
+	}
+	return a.ReadSamplesFunc(ctx, count, rateHz, extra)
+}
+
 // Write calls the injected Write or the real version.
 func (a *Analog) Write(ctx context.Context, value int, extra map[string]interface{}) error {
 	a.writeCap = []interface{}{ctx, value}
@@ -27,6 +27,12 @@ type Arm struct {
 	CurrentInputsFunc        func(ctx context.Context) ([]referenceframe.Input, error)
 	GoToInputsFunc           func(ctx context.Context, inputSteps ...[]referenceframe.Input) error
 	GeometriesFunc           func(ctx context.Context) ([]spatialmath.Geometry, error)
+	SetJointVelocitiesFunc   func(ctx context.Context, velocitiesDegsPerSec []float64, extra map[string]interface{}) error
+
+	MoveThroughJointPositionsFunc func(
+		ctx context.Context, positions []*pb.JointPositions, options *arm.MoveOptions, extra map[string]interface{},
+	) error
+	ConfigurationMetricsFunc func(ctx context.Context, extra map[string]interface{}) (*arm.ConfigurationMetrics, error)
 }
 
 // NewArm returns a new injected arm.
@@ -71,6 +77,51 @@ func (a *Arm) JointPositions(ctx context.Context, extra map[string]interface{})
 	return a.JointPositionsFunc(ctx, extra)
 }
 
+// SetJointVelocities calls the injected SetJointVelocities or the real version.
+func (a *Arm) SetJointVelocities(ctx context.Context, velocitiesDegsPerSec []float64, extra map[string]interface{}) error {
+	if a.SetJointVelocitiesFunc == nil {
+		return arm.SetJointVelocities(ctx, a.Arm, velocitiesDegsPerSec, extra)
+	}
+	return a.SetJointVelocitiesFunc(ctx, velocitiesDegsPerSec, extra)
+}
+
+// MoveThroughJointPositions calls the injected MoveThroughJointPositions or the real version.
+func (a *Arm) MoveThroughJointPositions(
+	ctx context.Context, positions []*pb.JointPositions, options *arm.MoveOptions, extra map[string]interface{},
+) error {
+	if a.MoveThroughJointPositionsFunc == nil {
+		if executor, ok := a.Arm.(arm.TrajectoryExecutor); ok {
+			return executor.MoveThroughJointPositions(ctx, positions, options, extra)
+		}
+		// stop at every position like arms which cannot blend, which also goes through an injected
+		// MoveToJointPositions
+		for _, position := range positions {
+			if err := a.MoveToJointPositions(ctx, position, extra); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return a.MoveThroughJointPositionsFunc(ctx, positions, options, extra)
+}
+
+// ConfigurationMetrics calls the injected ConfigurationMetrics or the real version.
+func (a *Arm) ConfigurationMetrics(ctx context.Context, extra map[string]interface{}) (*arm.ConfigurationMetrics, error) {
+	if a.ConfigurationMetricsFunc == nil {
+		if reporter, ok := a.Arm.(arm.ConfigurationMetricsReporter); ok {
+			return reporter.ConfigurationMetrics(ctx, extra)
+		}
+		// work them out from the model like arms which do not report them, which also goes through an injected
+		// JointPositions and ModelFrame
+		positions, err := a.JointPositions(ctx, extra)
+		if err != nil {
+			return nil, err
+		}
+		return arm.ComputeConfigurationMetrics(a.ModelFrame(), positions)
+	}
+	return a.ConfigurationMetricsFunc(ctx, extra)
+}
+
 // Stop calls the injected Stop or the real version.
 func (a *Arm) Stop(ctx context.Context, extra map[string]interface{}) error {
 	if a.StopFunc == nil {
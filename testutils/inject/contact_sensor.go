@@ -0,0 +1,51 @@
+package inject
+
+import (
+	"context"
+
+	"go.viam.com/rdk/components/contactsensor"
+	"go.viam.com/rdk/resource"
+)
+
+// ContactSensor is an injected contact sensor.
+type ContactSensor struct {
+	contactsensor.ContactSensor
+	name         resource.Name
+	DoFunc       func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error)
+	ContactsFunc func(ctx context.Context, extra map[string]interface{}) ([]contactsensor.Contact, error)
+	EventsFunc   func(ctx context.Context, extra map[string]interface{}) (<-chan contactsensor.Contact, error)
+}
+
+// NewContactSensor returns a new injected contact sensor.
+func NewContactSensor(name string) *ContactSensor {
+	return &ContactSensor{name: contactsensor.Named(name)}
+}
+
+// Name returns the name of the resource.
+func (s *ContactSensor) Name() resource.Name {
+	return s.name
+}
+
+// Contacts calls the injected Contacts or the real version.
+func (s *ContactSensor) Contacts(ctx context.Context, extra map[string]interface{}) ([]contactsensor.Contact, error) {
+	if s.ContactsFunc == nil {
+		return s.ContactSensor.Contacts(ctx, extra)
+	}
+	return s.ContactsFunc(ctx, extra)
+}
+
+// Events calls the injected Events or the real version.
+func (s *ContactSensor) Events(ctx context.Context, extra map[string]interface{}) (<-chan contactsensor.Contact, error) {
+	if s.EventsFunc == nil {
+		return s.ContactSensor.Events(ctx, extra)
+	}
+	return s.EventsFunc(ctx, extra)
+}
+
+// DoCommand calls the injected DoCommand or the real version.
+func (s *ContactSensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if s.DoFunc == nil {
+		return s.ContactSensor.DoCommand(ctx, cmd)
+	}
+	return s.DoFunc(ctx, cmd)
+}
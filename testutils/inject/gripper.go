@@ -19,6 +19,8 @@ type Gripper struct {
 	IsMovingFunc   func(context.Context) (bool, error)
 	CloseFunc      func(ctx context.Context) error
 	GeometriesFunc func(ctx context.Context) ([]spatialmath.Geometry, error)
+
+	IsHoldingSomethingFunc func(ctx context.Context, extra map[string]interface{}) (gripper.HoldingStatus, error)
 }
 
 // NewGripper returns a new injected gripper.
@@ -47,6 +49,14 @@ func (g *Gripper) Grab(ctx context.Context, extra map[string]interface{}) (bool,
 	return g.GrabFunc(ctx, extra)
 }
 
+// IsHoldingSomething calls the injected IsHoldingSomething or the real version.
+func (g *Gripper) IsHoldingSomething(ctx context.Context, extra map[string]interface{}) (gripper.HoldingStatus, error) {
+	if g.IsHoldingSomethingFunc == nil {
+		return g.Gripper.IsHoldingSomething(ctx, extra)
+	}
+	return g.IsHoldingSomethingFunc(ctx, extra)
+}
+
 // Stop calls the injected Stop or the real version.
 func (g *Gripper) Stop(ctx context.Context, extra map[string]interface{}) error {
 	if g.StopFunc == nil {
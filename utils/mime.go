@@ -21,6 +21,10 @@ const (
 	// MimeTypeRawDepth is for depth images.
 	MimeTypeRawDepth = "image/vnd.viam.dep"
 
+	// MimeTypeRawBayer is for the raw mosaic of Bayer sensors, with the header explained in the comments for
+	// rimage.BayerImageMagicNumber.
+	MimeTypeRawBayer = "image/vnd.viam.bayer"
+
 	// MimeTypeJPEG is regular jpgs.
 	MimeTypeJPEG = "image/jpeg"
 
@@ -30,6 +34,9 @@ const (
 	// MimeTypePCD is for .pcd pountcloud files.
 	MimeTypePCD = "pointcloud/pcd"
 
+	// MimeTypePCDLZ4 is for binary .pcd pointcloud files compressed as an LZ4 frame.
+	MimeTypePCDLZ4 = "pointcloud/pcd+lz4"
+
 	// MimeTypeQOI is for .qoi "Quite OK Image" for lossless, fast encoding/decoding.
 	MimeTypeQOI = "image/qoi"
 
@@ -0,0 +1,60 @@
+// Package realtime applies real-time scheduling hints to the goroutines which run control loops, such as those of
+// encoded motors and steppers, so that they are not delayed by the rest of the process. The hints are most effective
+// on kernels built with PREEMPT_RT.
+package realtime
+
+import (
+	"runtime"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+)
+
+// MaxPriority is the highest SCHED_FIFO priority.
+const MaxPriority = 99
+
+// Config holds the scheduling hints of the control loops of a resource.
+type Config struct {
+	// Priority runs the loops under the SCHED_FIFO policy at this priority, from 1 to 99, preempting every thread of
+	// lower priority. Zero leaves them under the default policy. Setting a priority requires CAP_SYS_NICE, or an
+	// rtprio limit at least as high.
+	Priority int `json:"priority,omitempty"`
+	// CPUs pins the loops to these CPUs, which are best isolated from the rest of the system with isolcpus.
+	CPUs []int `json:"cpus,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *Config) Validate(path string) error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.Priority < 0 || cfg.Priority > MaxPriority {
+		return resource.NewConfigValidationError(path,
+			errors.Errorf("realtime priority must be between 1 and %d, got %d", MaxPriority, cfg.Priority))
+	}
+	for _, cpu := range cfg.CPUs {
+		if cpu < 0 || cpu >= runtime.NumCPU() {
+			return resource.NewConfigValidationError(path,
+				errors.Errorf("realtime cpu %d does not exist, there are %d", cpu, runtime.NumCPU()))
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) enabled() bool {
+	return cfg != nil && (cfg.Priority > 0 || len(cfg.CPUs) > 0)
+}
+
+// Enter applies the scheduling hints of cfg to the calling goroutine, which must be dedicated to a control loop, and
+// does nothing if cfg is nil or empty. The goroutine is locked to its OS thread, and is never unlocked so that the
+// thread, whose scheduling no longer matches the rest of the process, is discarded when the goroutine returns rather
+// than reused for other goroutines. The goroutine stays locked even if the hints could not be applied, for instance
+// because the process is not allowed to raise its priority, in which case the error says why.
+func Enter(cfg *Config) error {
+	if !cfg.enabled() {
+		return nil
+	}
+	runtime.LockOSThread()
+	return errors.Wrap(apply(cfg), "could not apply realtime scheduling")
+}
@@ -0,0 +1,25 @@
+package realtime
+
+import (
+	"go.uber.org/multierr"
+	"golang.org/x/sys/unix"
+)
+
+// apply sets the scheduling of the calling thread, which the zero pid stands for.
+func apply(cfg *Config) error {
+	var errs error
+	if len(cfg.CPUs) > 0 {
+		var set unix.CPUSet
+		for _, cpu := range cfg.CPUs {
+			set.Set(cpu)
+		}
+		errs = multierr.Combine(errs, unix.SchedSetaffinity(0, &set))
+	}
+	if cfg.Priority > 0 {
+		errs = multierr.Combine(errs, unix.SchedSetAttr(0, &unix.SchedAttr{
+			Policy:   unix.SCHED_FIFO,
+			Priority: uint32(cfg.Priority),
+		}, 0))
+	}
+	return errs
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package realtime
+
+import "github.com/pkg/errors"
+
+func apply(cfg *Config) error {
+	return errors.New("realtime scheduling is only supported on linux")
+}
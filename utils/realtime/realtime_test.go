@@ -0,0 +1,39 @@
+package realtime
+
+import (
+	"runtime"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestValidate(t *testing.T) {
+	var cfg *Config
+	test.That(t, cfg.Validate("path"), test.ShouldBeNil)
+	test.That(t, (&Config{Priority: 80, CPUs: []int{0}}).Validate("path"), test.ShouldBeNil)
+
+	err := (&Config{Priority: 100}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "priority")
+
+	err = (&Config{CPUs: []int{runtime.NumCPU()}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "does not exist")
+}
+
+func TestEnter(t *testing.T) {
+	test.That(t, Enter(nil), test.ShouldBeNil)
+	test.That(t, Enter(&Config{}), test.ShouldBeNil)
+
+	// the goroutine keeps its thread locked, so the thread is discarded when it returns
+	errs := make(chan error)
+	go func() {
+		errs <- Enter(&Config{CPUs: []int{0}})
+	}()
+	err := <-errs
+	if runtime.GOOS == "linux" {
+		test.That(t, err, test.ShouldBeNil)
+	} else {
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
@@ -0,0 +1,178 @@
+// Package barcode finds and decodes QR codes and 1D barcodes in images. QR codes are found by the three square
+// finder patterns at their corners, sampled module by module through the homography of the finder patterns and, for
+// larger codes, their bottom right alignment pattern, and corrected with their Reed-Solomon codes. 1D barcodes are
+// read along the rows and columns of the image in both directions, and a barcode is found when enough lines read it
+// alike.
+package barcode
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r2"
+	"gonum.org/v1/gonum/mat"
+)
+
+// A Format is the symbology of a code.
+type Format string
+
+// The formats of the codes which can be found.
+const (
+	QRCode  = Format("qr_code")
+	EAN13   = Format("ean_13")
+	EAN8    = Format("ean_8")
+	UPCA    = Format("upc_a")
+	Code128 = Format("code_128")
+	Code39  = Format("code_39")
+)
+
+// Formats are all the formats of the codes which can be found.
+var Formats = []Format{QRCode, EAN13, EAN8, UPCA, Code128, Code39}
+
+const (
+	// minWindowRadius is the least radius, in pixels, of the window whose mean luminance thresholds a pixel.
+	minWindowRadius = 8
+	// thresholdBias is how much darker than the mean of the window around it a pixel is for it to be dark, out of
+	// 255, so that pixels in flat areas are light.
+	thresholdBias = 4
+)
+
+// A Code is a code found in an image.
+type Code struct {
+	Format  Format
+	Payload string
+	// Corners are the corners of the code in pixels, with the center of the top left pixel of the image at 0, 0.
+	// They go clockwise from the top left of the code as it is printed, whatever its rotation in the image. Those of
+	// a QR code are the corners of its modules, and those of a 1D barcode are the ends of its first and last bars
+	// along the first and last lines which read it.
+	Corners [4]r2.Point
+}
+
+// BoundingBox is the smallest rectangle of pixels containing the code.
+func (c Code) BoundingBox() image.Rectangle {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range c.Corners {
+		minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+		maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+	}
+	return image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
+}
+
+// ValidFormat reports whether a format is one which can be found.
+func ValidFormat(f Format) bool {
+	for _, known := range Formats {
+		if f == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Detect finds the codes of the given formats in an image, or of all formats if none are given, ordered from the
+// top of the image.
+func Detect(img image.Image, formats ...Format) []Code {
+	if len(formats) == 0 {
+		formats = Formats
+	}
+	want := map[Format]bool{}
+	for _, f := range formats {
+		want[f] = true
+	}
+	b := binarize(img)
+	var codes []Code
+	if want[QRCode] {
+		codes = append(codes, detectQRCodes(b)...)
+	}
+	codes = append(codes, detectLinear(b, want)...)
+	sort.SliceStable(codes, func(i, j int) bool {
+		bi, bj := codes[i].BoundingBox(), codes[j].BoundingBox()
+		if bi.Min.Y != bj.Min.Y {
+			return bi.Min.Y < bj.Min.Y
+		}
+		return bi.Min.X < bj.Min.X
+	})
+	return codes
+}
+
+// binaryImage is an image thresholded into dark and light pixels.
+type binaryImage struct {
+	width, height int
+	dark          []bool
+}
+
+// at is whether a pixel is dark, and pixels outside of the image are light.
+func (b *binaryImage) at(x, y int) bool {
+	if x < 0 || y < 0 || x >= b.width || y >= b.height {
+		return false
+	}
+	return b.dark[y*b.width+x]
+}
+
+// binarize marks the dark pixels of an image, those darker than the mean of a window around them, which is large
+// enough to cover the biggest dark areas of the codes it can find.
+func binarize(img image.Image) *binaryImage {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	b := &binaryImage{width: w, height: h, dark: make([]bool, w*h)}
+	if w == 0 || h == 0 {
+		return b
+	}
+	lum := make([]int, w*h)
+	// sums of the luminances of the pixels above and left of each, in an image one larger on each side
+	sums := make([]int, (w+1)*(h+1))
+	for y := 0; y < h; y++ {
+		row := 0
+		for x := 0; x < w; x++ {
+			v := int(color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray).Y)
+			lum[y*w+x] = v
+			row += v
+			sums[(y+1)*(w+1)+x+1] = sums[y*(w+1)+x+1] + row
+		}
+	}
+	radius := max(minWindowRadius, max(w, h)/16)
+	for y := 0; y < h; y++ {
+		y0, y1 := max(y-radius, 0), min(y+radius+1, h)
+		for x := 0; x < w; x++ {
+			x0, x1 := max(x-radius, 0), min(x+radius+1, w)
+			sum := sums[y1*(w+1)+x1] - sums[y0*(w+1)+x1] - sums[y1*(w+1)+x0] + sums[y0*(w+1)+x0]
+			b.dark[y*w+x] = (lum[y*w+x]+thresholdBias)*(y1-y0)*(x1-x0) < sum
+		}
+	}
+	return b
+}
+
+// projective is a homography between two planes.
+type projective struct {
+	m *mat.Dense
+}
+
+// homography returns the homography which maps four points to four others.
+func homography(from, to [4]r2.Point) (projective, bool) {
+	a := mat.NewDense(8, 8, nil)
+	b := mat.NewVecDense(8, nil)
+	for i := range from {
+		f, t := from[i], to[i]
+		a.SetRow(2*i, []float64{f.X, f.Y, 1, 0, 0, 0, -t.X * f.X, -t.X * f.Y})
+		a.SetRow(2*i+1, []float64{0, 0, 0, f.X, f.Y, 1, -t.Y * f.X, -t.Y * f.Y})
+		b.SetVec(2*i, t.X)
+		b.SetVec(2*i+1, t.Y)
+	}
+	var x mat.VecDense
+	if err := x.SolveVec(a, b); err != nil {
+		return projective{}, false
+	}
+	data := append(mat.Col(nil, 0, &x), 1)
+	return projective{m: mat.NewDense(3, 3, data)}, true
+}
+
+func (p projective) apply(pt r2.Point) r2.Point {
+	m := p.m
+	w := m.At(2, 0)*pt.X + m.At(2, 1)*pt.Y + m.At(2, 2)
+	return r2.Point{
+		X: (m.At(0, 0)*pt.X + m.At(0, 1)*pt.Y + m.At(0, 2)) / w,
+		Y: (m.At(1, 0)*pt.X + m.At(1, 1)*pt.Y + m.At(1, 2)) / w,
+	}
+}
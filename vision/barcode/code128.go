@@ -0,0 +1,135 @@
+package barcode
+
+import "strings"
+
+// code128Patterns are the widths of the bars and spaces of the symbols of Code 128 by their values, with the start
+// codes A, B and C at 103, 104 and 105. The stop code is code128Stop.
+var code128Patterns = [][]int{
+	{2, 1, 2, 2, 2, 2}, {2, 2, 2, 1, 2, 2}, {2, 2, 2, 2, 2, 1}, {1, 2, 1, 2, 2, 3}, {1, 2, 1, 3, 2, 2},
+	{1, 3, 1, 2, 2, 2}, {1, 2, 2, 2, 1, 3}, {1, 2, 2, 3, 1, 2}, {1, 3, 2, 2, 1, 2}, {2, 2, 1, 2, 1, 3},
+	{2, 2, 1, 3, 1, 2}, {2, 3, 1, 2, 1, 2}, {1, 1, 2, 2, 3, 2}, {1, 2, 2, 1, 3, 2}, {1, 2, 2, 2, 3, 1},
+	{1, 1, 3, 2, 2, 2}, {1, 2, 3, 1, 2, 2}, {1, 2, 3, 2, 2, 1}, {2, 2, 3, 2, 1, 1}, {2, 2, 1, 1, 3, 2},
+	{2, 2, 1, 2, 3, 1}, {2, 1, 3, 2, 1, 2}, {2, 2, 3, 1, 1, 2}, {3, 1, 2, 1, 3, 1}, {3, 1, 1, 2, 2, 2},
+	{3, 2, 1, 1, 2, 2}, {3, 2, 1, 2, 2, 1}, {3, 1, 2, 2, 1, 2}, {3, 2, 2, 1, 1, 2}, {3, 2, 2, 2, 1, 1},
+	{2, 1, 2, 1, 2, 3}, {2, 1, 2, 3, 2, 1}, {2, 3, 2, 1, 2, 1}, {1, 1, 1, 3, 2, 3}, {1, 3, 1, 1, 2, 3},
+	{1, 3, 1, 3, 2, 1}, {1, 1, 2, 3, 1, 3}, {1, 3, 2, 1, 1, 3}, {1, 3, 2, 3, 1, 1}, {2, 1, 1, 3, 1, 3},
+	{2, 3, 1, 1, 1, 3}, {2, 3, 1, 3, 1, 1}, {1, 1, 2, 1, 3, 3}, {1, 1, 2, 3, 3, 1}, {1, 3, 2, 1, 3, 1},
+	{1, 1, 3, 1, 2, 3}, {1, 1, 3, 3, 2, 1}, {1, 3, 3, 1, 2, 1}, {3, 1, 3, 1, 2, 1}, {2, 1, 1, 3, 3, 1},
+	{2, 3, 1, 1, 3, 1}, {2, 1, 3, 1, 1, 3}, {2, 1, 3, 3, 1, 1}, {2, 1, 3, 1, 3, 1}, {3, 1, 1, 1, 2, 3},
+	{3, 1, 1, 3, 2, 1}, {3, 3, 1, 1, 2, 1}, {3, 1, 2, 1, 1, 3}, {3, 1, 2, 3, 1, 1}, {3, 3, 2, 1, 1, 1},
+	{3, 1, 4, 1, 1, 1}, {2, 2, 1, 4, 1, 1}, {4, 3, 1, 1, 1, 1}, {1, 1, 1, 2, 2, 4}, {1, 1, 1, 4, 2, 2},
+	{1, 2, 1, 1, 2, 4}, {1, 2, 1, 4, 2, 1}, {1, 4, 1, 1, 2, 2}, {1, 4, 1, 2, 2, 1}, {1, 1, 2, 2, 1, 4},
+	{1, 1, 2, 4, 1, 2}, {1, 2, 2, 1, 1, 4}, {1, 2, 2, 4, 1, 1}, {1, 4, 2, 1, 1, 2}, {1, 4, 2, 2, 1, 1},
+	{2, 4, 1, 2, 1, 1}, {2, 2, 1, 1, 1, 4}, {4, 1, 3, 1, 1, 1}, {2, 4, 1, 1, 1, 2}, {1, 3, 4, 1, 1, 1},
+	{1, 1, 1, 2, 4, 2}, {1, 2, 1, 1, 4, 2}, {1, 2, 1, 2, 4, 1}, {1, 1, 4, 2, 1, 2}, {1, 2, 4, 1, 1, 2},
+	{1, 2, 4, 2, 1, 1}, {4, 1, 1, 2, 1, 2}, {4, 2, 1, 1, 1, 2}, {4, 2, 1, 2, 1, 1}, {2, 1, 2, 1, 4, 1},
+	{2, 1, 4, 1, 2, 1}, {4, 1, 2, 1, 2, 1}, {1, 1, 1, 1, 4, 3}, {1, 1, 1, 3, 4, 1}, {1, 3, 1, 1, 4, 1},
+	{1, 1, 4, 1, 1, 3}, {1, 1, 4, 3, 1, 1}, {4, 1, 1, 1, 1, 3}, {4, 1, 1, 3, 1, 1}, {1, 1, 3, 1, 4, 1},
+	{1, 1, 4, 1, 3, 1}, {3, 1, 1, 1, 4, 1}, {4, 1, 1, 1, 3, 1}, {2, 1, 1, 4, 1, 2}, {2, 1, 1, 2, 1, 4},
+	{2, 1, 1, 2, 3, 2},
+}
+
+// code128Stop is the stop code of Code 128, with its final bar.
+var code128Stop = []int{2, 3, 3, 1, 1, 1, 2}
+
+// The special values of Code 128.
+const (
+	code128FNC3   = 96
+	code128FNC2   = 97
+	code128Shift  = 98
+	code128CodeC  = 99
+	code128CodeB  = 100
+	code128CodeA  = 101
+	code128FNC1   = 102
+	code128StartA = 103
+	code128StartC = 105
+)
+
+// decodeCode128 decodes a Code 128 barcode, checking its check symbol. FNC1 is the ASCII group separator, as GS1
+// has it, except as the first symbol, and the other function codes are left out.
+func decodeCode128(rs runs, i int) (Format, string, int, bool) {
+	if i+6 >= len(rs) {
+		return "", "", 0, false
+	}
+	start, ok := bestPattern(rs[i:i+6], code128Patterns[code128StartA:code128StartC+1])
+	if !ok {
+		return "", "", 0, false
+	}
+	start += code128StartA
+	module := float64(rs.sum(i, i+6)) / 11
+	if !rs.quiet(i-1, module) {
+		return "", "", 0, false
+	}
+
+	var values []int
+	j := i + 6
+	for {
+		if j+7 < len(rs) && moduleError(rs[j:j+7], code128Stop, float64(rs.sum(j, j+7))/13) < maxModuleError {
+			j += 7
+			break
+		}
+		if j+6 >= len(rs) {
+			return "", "", 0, false
+		}
+		v, ok := bestPattern(rs[j:j+6], code128Patterns[:code128StartA])
+		if !ok {
+			return "", "", 0, false
+		}
+		values = append(values, v)
+		j += 6
+	}
+	if len(values) < 2 || !rs.quiet(j, module) {
+		return "", "", 0, false
+	}
+	check := start
+	for k, v := range values[:len(values)-1] {
+		check += (k + 1) * v
+	}
+	if check%103 != values[len(values)-1] {
+		return "", "", 0, false
+	}
+
+	var text strings.Builder
+	set := start
+	for k, v := range values[:len(values)-1] {
+		current := set
+		if k > 0 && values[k-1] == code128Shift && set != code128StartC {
+			// a shift switches between A and B for one symbol
+			current = code128StartA + code128StartA + 1 - set
+		}
+		switch {
+		case v == code128FNC1:
+			if k > 0 {
+				text.WriteByte(0x1d)
+			}
+		case current == code128StartC:
+			switch v {
+			case code128CodeB:
+				set = code128StartA + 1
+			case code128CodeA:
+				set = code128StartA
+			default:
+				text.WriteByte(byte('0' + v/10))
+				text.WriteByte(byte('0' + v%10))
+			}
+		case v == code128FNC2 || v == code128FNC3 || v == code128Shift:
+		case v == code128CodeC:
+			set = code128StartC
+		case v == code128CodeB:
+			// FNC4 in code B
+			if current == code128StartA {
+				set = code128StartA + 1
+			}
+		case v == code128CodeA:
+			// FNC4 in code A
+			if current == code128StartA+1 {
+				set = code128StartA
+			}
+		case current == code128StartA && v >= 64:
+			text.WriteByte(byte(v - 64))
+		default:
+			text.WriteByte(byte(v + 32))
+		}
+	}
+	return Code128, text.String(), j, true
+}
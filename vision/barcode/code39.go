@@ -0,0 +1,83 @@
+package barcode
+
+import (
+	"sort"
+	"strings"
+)
+
+// code39Alphabet are the characters of Code 39, whose wide bars and spaces are marked by the bits of
+// code39Patterns, from the most significant for the first bar. '*' starts and stops a barcode.
+const code39Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ-. $/+%*"
+
+var code39Patterns = [len(code39Alphabet)]int{
+	0x034, 0x121, 0x061, 0x160, 0x031, 0x130, 0x070, 0x025, 0x124, 0x064,
+	0x109, 0x049, 0x148, 0x019, 0x118, 0x058, 0x00d, 0x10c, 0x04c, 0x01c,
+	0x103, 0x043, 0x142, 0x013, 0x112, 0x052, 0x007, 0x106, 0x046, 0x016,
+	0x181, 0x0c1, 0x1c0, 0x091, 0x190, 0x0d0, 0x085, 0x184, 0x0c4, 0x0a8,
+	0x0a2, 0x08a, 0x02a, 0x094,
+}
+
+// minCode39WideRatio is the least ratio of the wide bars and spaces of Code 39 to the narrow ones.
+const minCode39WideRatio = 1.5
+
+// decodeCode39 decodes a Code 39 barcode between its start and stop characters, which are left out of its payload.
+// Its optional check character is left in.
+func decodeCode39(rs runs, i int) (Format, string, int, bool) {
+	var text strings.Builder
+	var narrowSum, narrowCount float64
+	j := i
+	for first := true; ; first = false {
+		if j+9 >= len(rs) {
+			return "", "", 0, false
+		}
+		c, narrow, ok := code39Character(rs[j : j+9])
+		if !ok {
+			return "", "", 0, false
+		}
+		narrowSum += narrow * 6
+		narrowCount += 6
+		if first {
+			if c != '*' || !rs.quiet(i-1, narrowSum/narrowCount) {
+				return "", "", 0, false
+			}
+		} else if c == '*' {
+			j += 9
+			break
+		} else {
+			text.WriteByte(c)
+		}
+		// the gap between characters
+		j += 10
+	}
+	if text.Len() == 0 || !rs.quiet(j, narrowSum/narrowCount) {
+		return "", "", 0, false
+	}
+	return Code39, text.String(), j, true
+}
+
+// code39Character reads the character of the nine bars and spaces of a Code 39 character, three of which are wide,
+// returning the width of its narrow ones.
+func code39Character(widths []int) (byte, float64, bool) {
+	sorted := append([]int(nil), widths...)
+	sort.Ints(sorted)
+	narrowMax, wideMin := sorted[5], sorted[6]
+	if float64(wideMin) < minCode39WideRatio*float64(narrowMax) {
+		return 0, 0, false
+	}
+	pattern := 0
+	narrowSum := 0
+	for _, w := range widths {
+		pattern <<= 1
+		if w >= wideMin {
+			pattern |= 1
+		} else {
+			narrowSum += w
+		}
+	}
+	for k, p := range code39Patterns {
+		if p == pattern {
+			return code39Alphabet[k], float64(narrowSum) / 6, true
+		}
+	}
+	return 0, 0, false
+}
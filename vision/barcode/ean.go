@@ -0,0 +1,125 @@
+package barcode
+
+// eanDigits are the widths of the space, bar, space and bar of the digits of the left half of an EAN barcode with
+// odd parity, its L codes. Those with even parity, its G codes, are their reverse, and the digits of the right half
+// are the bar, space, bar and space of the L codes.
+var eanDigits = [10][]int{
+	{3, 2, 1, 1}, {2, 2, 2, 1}, {2, 1, 2, 2}, {1, 4, 1, 1}, {1, 1, 3, 2},
+	{1, 2, 3, 1}, {1, 1, 1, 4}, {1, 3, 1, 2}, {1, 2, 1, 3}, {3, 1, 1, 2},
+}
+
+// eanLeftDigits are the L codes of eanDigits followed by the G codes.
+var eanLeftDigits = func() [][]int {
+	patterns := make([][]int, 0, 20)
+	patterns = append(patterns, eanDigits[:]...)
+	for _, l := range eanDigits {
+		patterns = append(patterns, []int{l[3], l[2], l[1], l[0]})
+	}
+	return patterns
+}()
+
+// eanFirstDigits are the parities of the six digits of the left half of an EAN-13 barcode which encode its first
+// digit, from the most significant bit, set for even parity.
+var eanFirstDigits = [10]int{0x00, 0x0b, 0x0d, 0x0e, 0x13, 0x19, 0x1c, 0x15, 0x16, 0x1a}
+
+var (
+	eanGuard  = []int{1, 1, 1}
+	eanMiddle = []int{1, 1, 1, 1, 1}
+)
+
+// decodeEAN decodes an EAN-13 barcode, which is a UPC-A barcode when its first digit is 0, or an EAN-8 barcode.
+func decodeEAN(rs runs, i int, want map[Format]bool) (Format, string, int, bool) {
+	if want[EAN13] || want[UPCA] {
+		if digits, end, ok := decodeEANDigits(rs, i, 6); ok {
+			if digits[0] == '0' && want[UPCA] {
+				return UPCA, digits[1:], end, true
+			}
+			if want[EAN13] {
+				return EAN13, digits, end, true
+			}
+		}
+	}
+	if want[EAN8] {
+		if digits, end, ok := decodeEANDigits(rs, i, 4); ok {
+			return EAN8, digits, end, true
+		}
+	}
+	return "", "", 0, false
+}
+
+// decodeEANDigits decodes the digits of an EAN barcode with half digits on each side of its middle guard,
+// checking its check digit.
+func decodeEANDigits(rs runs, i, half int) (string, int, bool) {
+	// the guards at either end and in the middle, and the digits of 7 modules each
+	n := 3 + 4*half + 5 + 4*half + 3
+	end := i + n
+	if end >= len(rs) {
+		return "", 0, false
+	}
+	module := float64(rs.sum(i, end)) / float64(11+14*half)
+	if !rs.quiet(i-1, module) || !rs.quiet(end, module) {
+		return "", 0, false
+	}
+	middle := i + 3 + 4*half
+	if moduleError(rs[i:i+3], eanGuard, module) >= maxModuleError ||
+		moduleError(rs[middle:middle+5], eanMiddle, module) >= maxModuleError ||
+		moduleError(rs[end-3:end], eanGuard, module) >= maxModuleError {
+		return "", 0, false
+	}
+
+	digits := make([]byte, 0, 2*half+1)
+	parities := 0
+	for d := 0; d < half; d++ {
+		j := i + 3 + 4*d
+		p, ok := bestPattern(rs[j:j+4], eanLeftDigits)
+		if !ok {
+			return "", 0, false
+		}
+		parities <<= 1
+		if p >= 10 {
+			parities |= 1
+		}
+		digits = append(digits, byte('0'+p%10))
+	}
+	for d := 0; d < half; d++ {
+		j := middle + 5 + 4*d
+		p, ok := bestPattern(rs[j:j+4], eanDigits[:])
+		if !ok {
+			return "", 0, false
+		}
+		digits = append(digits, byte('0'+p))
+	}
+
+	if half == 6 {
+		first := -1
+		for d, parity := range eanFirstDigits {
+			if parity == parities {
+				first = d
+			}
+		}
+		if first < 0 {
+			return "", 0, false
+		}
+		digits = append([]byte{byte('0' + first)}, digits...)
+	} else if parities != 0 {
+		return "", 0, false
+	}
+	if !eanChecksum(digits) {
+		return "", 0, false
+	}
+	return string(digits), end, true
+}
+
+// eanChecksum is whether the last digit of an EAN barcode is its check digit, which makes the sum of its digits,
+// those an odd number of places from the last weighed 3, a multiple of 10.
+func eanChecksum(digits []byte) bool {
+	sum := 0
+	for i, d := range digits {
+		weight := 1
+		if (len(digits)-1-i)%2 == 1 {
+			weight = 3
+		}
+		sum += weight * int(d-'0')
+	}
+	return sum%10 == 0
+}
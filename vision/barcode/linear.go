@@ -0,0 +1,200 @@
+package barcode
+
+import (
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r2"
+)
+
+const (
+	// maxScanLines is about how many rows and how many columns of an image are read for 1D barcodes.
+	maxScanLines = 256
+	// minLineHits is how many lines must read a 1D barcode alike for it to be found.
+	minLineHits = 2
+	// minQuietModules is the least width of the light margins on either side of a 1D barcode, in modules.
+	minQuietModules = 3
+	// maxModuleError is how far off the width of each bar or space of a 1D barcode may be, in modules.
+	maxModuleError = 0.5
+)
+
+// runs are the lengths of the alternating light and dark runs of a line of pixels, starting with a light run, which
+// is empty when the line starts dark. Dark runs are at the odd indices.
+type runs []int
+
+// lineRead is a 1D barcode read along a line, from the start of its first bar to the end of its last.
+type lineRead struct {
+	format     Format
+	payload    string
+	start, end r2.Point
+}
+
+// linearDecoder decodes a 1D barcode starting at the dark run i, returning its payload and the index after its last
+// bar.
+type linearDecoder func(rs runs, i int) (Format, string, int, bool)
+
+// detectLinear finds the 1D barcodes of the given formats along the rows and columns of an image, read each way.
+func detectLinear(b *binaryImage, want map[Format]bool) []Code {
+	var decoders []linearDecoder
+	if want[EAN13] || want[EAN8] || want[UPCA] {
+		decoders = append(decoders, func(rs runs, i int) (Format, string, int, bool) {
+			return decodeEAN(rs, i, want)
+		})
+	}
+	if want[Code128] {
+		decoders = append(decoders, decodeCode128)
+	}
+	if want[Code39] {
+		decoders = append(decoders, decodeCode39)
+	}
+	if len(decoders) == 0 {
+		return nil
+	}
+
+	var reads []lineRead
+	scan := func(n int, at line, point func(float64) r2.Point) {
+		for _, reverse := range []bool{false, true} {
+			get := at
+			pos := func(i int) r2.Point { return point(float64(i) - 0.5) }
+			if reverse {
+				get = func(i int) bool { return at(n - 1 - i) }
+				pos = func(i int) r2.Point { return point(float64(n-i) - 0.5) }
+			}
+			rs, starts := lineRuns(get, n)
+			for i := 1; i < len(rs); i += 2 {
+				for _, decode := range decoders {
+					if format, payload, end, ok := decode(rs, i); ok {
+						reads = append(reads, lineRead{format: format, payload: payload, start: pos(starts[i]), end: pos(starts[end])})
+						i = end - 1
+						break
+					}
+				}
+			}
+		}
+	}
+	step := max(1, b.height/maxScanLines)
+	for y := step / 2; y < b.height; y += step {
+		fy := float64(y)
+		scan(b.width, b.row(y), func(x float64) r2.Point { return r2.Point{X: x, Y: fy} })
+	}
+	step = max(1, b.width/maxScanLines)
+	for x := step / 2; x < b.width; x += step {
+		fx := float64(x)
+		scan(b.height, b.column(x), func(y float64) r2.Point { return r2.Point{X: fx, Y: y} })
+	}
+	return groupReads(reads, float64(2*max(1, b.height/maxScanLines, b.width/maxScanLines)))
+}
+
+// lineRuns returns the runs of a line and the index of the first pixel of each, with one more start for the end of
+// the line.
+func lineRuns(at line, n int) (runs, []int) {
+	rs, starts := runs{0}, []int{0}
+	dark := false
+	for i := 0; i < n; i++ {
+		if at(i) != dark {
+			dark = !dark
+			rs = append(rs, 0)
+			starts = append(starts, i)
+		}
+		rs[len(rs)-1]++
+	}
+	return rs, append(starts, n)
+}
+
+// groupReads groups the lines which read the same code in the same direction next to each other into the codes,
+// whose corners are the ends of the first and last lines of each.
+func groupReads(reads []lineRead, maxGap float64) []Code {
+	type key struct {
+		format    Format
+		payload   string
+		direction [2]int
+	}
+	groups := map[key][]lineRead{}
+	var keys []key
+	for _, read := range reads {
+		d := read.end.Sub(read.start)
+		k := key{format: read.format, payload: read.payload}
+		if math.Abs(d.X) >= math.Abs(d.Y) {
+			k.direction[0] = int(math.Copysign(1, d.X))
+		} else {
+			k.direction[1] = int(math.Copysign(1, d.Y))
+		}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], read)
+	}
+
+	var codes []Code
+	for _, k := range keys {
+		group := groups[k]
+		// down the code as it is printed is a quarter turn clockwise from along it
+		down := r2.Point{X: -float64(k.direction[1]), Y: float64(k.direction[0])}
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].start.Dot(down) < group[j].start.Dot(down)
+		})
+		first := 0
+		for i := 1; i <= len(group); i++ {
+			if i < len(group) && group[i].start.Dot(down)-group[i-1].start.Dot(down) <= maxGap {
+				continue
+			}
+			if i-first >= minLineHits {
+				top, bottom := group[first], group[i-1]
+				codes = append(codes, Code{
+					Format:  k.format,
+					Payload: k.payload,
+					Corners: [4]r2.Point{top.start, top.end, bottom.end, bottom.start},
+				})
+			}
+			first = i
+		}
+	}
+	return codes
+}
+
+// moduleError is the largest difference, in modules, between the widths of runs and a pattern of widths in modules,
+// given the width of a module.
+func moduleError(widths []int, pattern []int, module float64) float64 {
+	worst := 0.
+	for i, w := range widths {
+		worst = math.Max(worst, math.Abs(float64(w)/module-float64(pattern[i])))
+	}
+	return worst
+}
+
+// bestPattern returns the index of the pattern whose widths the runs are closest to, scaled to the width of the
+// runs, if they are close enough to it.
+func bestPattern(widths []int, patterns [][]int) (int, bool) {
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	best, bestErr := -1, maxModuleError
+	for i, pattern := range patterns {
+		modules := 0
+		for _, p := range pattern {
+			modules += p
+		}
+		if err := moduleError(widths, pattern, float64(total)/float64(modules)); err < bestErr {
+			best, bestErr = i, err
+		}
+	}
+	return best, best >= 0
+}
+
+// quiet is whether the light run at an index is a quiet zone for a barcode with modules of a width, which the light
+// at either end of a line always is.
+func (rs runs) quiet(i int, module float64) bool {
+	if i <= 0 || i >= len(rs)-1 {
+		return i >= 0 && i < len(rs)
+	}
+	return float64(rs[i]) >= minQuietModules*module
+}
+
+func (rs runs) sum(from, to int) int {
+	total := 0
+	for _, r := range rs[from:to] {
+		total += r
+	}
+	return total
+}
@@ -0,0 +1,181 @@
+package barcode
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"go.viam.com/test"
+)
+
+// widthsToModules turns the widths of alternating bars and spaces, starting with a bar, into modules.
+func widthsToModules(widths ...[]int) []bool {
+	var modules []bool
+	dark := true
+	for _, ws := range widths {
+		for _, w := range ws {
+			for i := 0; i < w; i++ {
+				modules = append(modules, dark)
+			}
+			dark = !dark
+		}
+	}
+	return modules
+}
+
+func encodeEAN13(digits string) []bool {
+	parities := eanFirstDigits[digits[0]-'0']
+	widths := [][]int{eanGuard}
+	for i, d := range digits[1:7] {
+		// the left digits start with a space
+		p := eanLeftDigits[d-'0']
+		if parities>>(5-i)&1 == 1 {
+			p = eanLeftDigits[10+d-'0']
+		}
+		widths = append(widths, p)
+	}
+	widths = append(widths, eanMiddle)
+	for _, d := range digits[7:] {
+		widths = append(widths, eanDigits[d-'0'])
+	}
+	widths = append(widths, eanGuard)
+	return widthsToModules(widths...)
+}
+
+func encodeEAN8(digits string) []bool {
+	widths := [][]int{eanGuard}
+	for _, d := range digits[:4] {
+		widths = append(widths, eanDigits[d-'0'])
+	}
+	widths = append(widths, eanMiddle)
+	for _, d := range digits[4:] {
+		widths = append(widths, eanDigits[d-'0'])
+	}
+	return widthsToModules(append(widths, eanGuard)...)
+}
+
+func encodeCode128B(text string) []bool {
+	values := []int{code128StartA + 1}
+	check := code128StartA + 1
+	for i, c := range []byte(text) {
+		values = append(values, int(c)-32)
+		check += (i + 1) * (int(c) - 32)
+	}
+	values = append(values, check%103)
+	var widths [][]int
+	for _, v := range values {
+		widths = append(widths, code128Patterns[v])
+	}
+	return widthsToModules(append(widths, code128Stop)...)
+}
+
+func encodeCode39(text string) []bool {
+	var modules []bool
+	for _, c := range "*" + text + "*" {
+		pattern := code39Patterns[strings.IndexRune(code39Alphabet, c)]
+		var widths []int
+		for bit := 8; bit >= 0; bit-- {
+			widths = append(widths, 1+2*(pattern>>bit&1))
+		}
+		modules = append(append(modules, widthsToModules(widths)...), false)
+	}
+	return modules[:len(modules)-1]
+}
+
+// renderBarcode draws the modules of a 1D barcode, each px pixels wide and 40 high, in a quiet zone of 10 modules.
+func renderBarcode(modules []bool, px int) *image.Gray {
+	width := (len(modules) + 20) * px
+	img := image.NewGray(image.Rect(0, 0, width, 60))
+	for y := 0; y < 60; y++ {
+		for x := 0; x < width; x++ {
+			m := x/px - 10
+			v := uint8(230)
+			if y >= 10 && y < 50 && m >= 0 && m < len(modules) && modules[m] {
+				v = 25
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	return img
+}
+
+// turn turns an image a quarter turn clockwise.
+func turn(img *image.Gray) *image.Gray {
+	b := img.Bounds()
+	turned := image.NewGray(image.Rect(0, 0, b.Dy(), b.Dx()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			turned.SetGray(b.Dy()-1-y, x, img.GrayAt(x, y))
+		}
+	}
+	return turned
+}
+
+func TestLinearPatterns(t *testing.T) {
+	for _, p := range code128Patterns {
+		sum := 0
+		for _, w := range p {
+			sum += w
+		}
+		test.That(t, sum, test.ShouldEqual, 11)
+	}
+	for _, p := range code39Patterns {
+		test.That(t, bitCount(p), test.ShouldEqual, 3)
+	}
+	for _, p := range eanLeftDigits {
+		test.That(t, p[0]+p[1]+p[2]+p[3], test.ShouldEqual, 7)
+	}
+}
+
+func TestDetectLinear(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		modules []bool
+		format  Format
+		payload string
+	}{
+		{"ean-13", encodeEAN13("4006381333931"), EAN13, "4006381333931"},
+		{"upc-a", encodeEAN13("0036000291452"), UPCA, "036000291452"},
+		{"ean-8", encodeEAN8("96385074"), EAN8, "96385074"},
+		{"code 128", encodeCode128B("Viam-128"), Code128, "Viam-128"},
+		{"code 39", encodeCode39("RDK 39"), Code39, "RDK 39"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			img := renderBarcode(tc.modules, 3)
+			codes := Detect(img)
+			test.That(t, len(codes), test.ShouldEqual, 1)
+			test.That(t, codes[0].Format, test.ShouldEqual, tc.format)
+			test.That(t, codes[0].Payload, test.ShouldEqual, tc.payload)
+			length := float64(3 * len(tc.modules))
+			// the first and last rows of the bars, 10 to 49
+			expected := [4]r2.Point{{X: 29.5, Y: 10}, {X: 29.5 + length, Y: 10}, {X: 29.5 + length, Y: 49}, {X: 29.5, Y: 49}}
+			for i, corner := range codes[0].Corners {
+				test.That(t, corner.Sub(expected[i]).Norm(), test.ShouldBeLessThan, 1.5)
+			}
+
+			// upside down, the top left is at the bottom right
+			upsideDown := turn(turn(img))
+			codes = Detect(upsideDown)
+			test.That(t, len(codes), test.ShouldEqual, 1)
+			test.That(t, codes[0].Payload, test.ShouldEqual, tc.payload)
+			width := float64(img.Bounds().Dx())
+			test.That(t, codes[0].Corners[0].Sub(r2.Point{X: width - 1 - 29.5, Y: 49}).Norm(), test.ShouldBeLessThan, 1.5)
+
+			// read down the columns
+			codes = Detect(turn(img))
+			test.That(t, len(codes), test.ShouldEqual, 1)
+			test.That(t, codes[0].Payload, test.ShouldEqual, tc.payload)
+		})
+	}
+
+	// the check digit is wrong
+	test.That(t, Detect(renderBarcode(encodeEAN13("4006381333932"), 3)), test.ShouldBeEmpty)
+	// only the formats asked for
+	test.That(t, Detect(renderBarcode(encodeCode39("ABC"), 3), QRCode, Code128), test.ShouldBeEmpty)
+	codes := Detect(renderBarcode(encodeEAN13("0036000291452"), 3), EAN13)
+	test.That(t, len(codes), test.ShouldEqual, 1)
+	test.That(t, codes[0].Format, test.ShouldEqual, EAN13)
+	test.That(t, codes[0].Payload, test.ShouldEqual, "0036000291452")
+}
@@ -0,0 +1,590 @@
+package barcode
+
+import (
+	"math"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/golang/geo/r2"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxFinders is how many of the most often seen finder patterns are matched into QR codes.
+	maxFinders = 12
+	// minFinderHits is how many lines through a finder pattern must see it for it to be kept.
+	minFinderHits = 2
+	// maxFormatErrors is how many bits of the format information of a QR code may be misread.
+	maxFormatErrors = 3
+	// alignmentSearchModules is how far from where it is expected the alignment pattern of a QR code is searched
+	// for, in modules.
+	alignmentSearchModules = 5
+	// eciUTF8 is the ECI designator of UTF-8, and eciLatin1 that of ISO-8859-1.
+	eciUTF8   = 26
+	eciLatin1 = 3
+)
+
+// alphanumeric are the characters of the alphanumeric mode of QR codes, by their values.
+const alphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// finderPattern is a finder pattern of a QR code, with its center in pixels and the size of its modules.
+type finderPattern struct {
+	center     r2.Point
+	moduleSize float64
+	hits       int
+}
+
+// line is a line of pixels of a binary image, with the dark pixels true.
+type line func(i int) bool
+
+// runsAround returns the lengths of the dark run through a pixel of a line and the two runs on either side of it,
+// from the start of the line, and the center of the dark run. It fails if any of the runs reaches the end of the
+// line.
+func runsAround(at line, n, i int) ([5]int, float64, bool) {
+	var runs [5]int
+	if !at(i) {
+		return runs, 0, false
+	}
+	lo, hi := i, i+1
+	for lo > 0 && at(lo-1) {
+		lo--
+	}
+	for hi < n && at(hi) {
+		hi++
+	}
+	runs[2] = hi - lo
+	next := lo
+	for k, dark := 1, false; k >= 0; k, dark = k-1, !dark {
+		start := next
+		for next > 0 && at(next-1) == dark {
+			next--
+		}
+		if next == 0 || next == start {
+			return runs, 0, false
+		}
+		runs[k] = start - next
+	}
+	next = hi
+	for k, dark := 3, false; k <= 4; k, dark = k+1, !dark {
+		start := next
+		for next < n && at(next) == dark {
+			next++
+		}
+		if next == n || next == start {
+			return runs, 0, false
+		}
+		runs[k] = next - start
+	}
+	return runs, float64(lo+hi-1) / 2, true
+}
+
+// finderRatio is whether runs are in the 1:1:3:1:1 ratio of the runs through the center of a finder pattern.
+func finderRatio(runs [5]int) bool {
+	total := 0
+	for _, r := range runs {
+		total += r
+	}
+	if total < 7 {
+		return false
+	}
+	module := float64(total) / 7
+	for i, r := range runs {
+		expected := 1.
+		if i == 2 {
+			expected = 3
+		}
+		if math.Abs(float64(r)-expected*module) >= expected*module/2 {
+			return false
+		}
+	}
+	return true
+}
+
+// alignmentRatio is whether runs look like those through the center of an alignment pattern, whose inner three are
+// a module each. The outer dark runs may run on into the data around the pattern.
+func alignmentRatio(runs [5]int, moduleSize float64) bool {
+	for i, r := range runs {
+		if i == 0 || i == 4 {
+			if float64(r) < moduleSize/2 {
+				return false
+			}
+		} else if math.Abs(float64(r)-moduleSize) >= moduleSize/2 {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *binaryImage) row(y int) line {
+	return func(x int) bool { return b.at(x, y) }
+}
+
+func (b *binaryImage) column(x int) line {
+	return func(y int) bool { return b.at(x, y) }
+}
+
+// detectQRCodes finds the QR codes in an image. Each is found by its three finder patterns, which are matched up as
+// the corners of a right angle.
+func detectQRCodes(b *binaryImage) []Code {
+	finders := findFinderPatterns(b)
+	used := make([]bool, len(finders))
+	var codes []Code
+	for i := range finders {
+		for j := i + 1; j < len(finders); j++ {
+			for k := j + 1; k < len(finders); k++ {
+				if used[i] || used[j] || used[k] {
+					continue
+				}
+				tl, tr, bl, ok := orderFinders(finders[i], finders[j], finders[k])
+				if !ok {
+					continue
+				}
+				code, err := readQRCode(b, tl, tr, bl)
+				if err != nil {
+					continue
+				}
+				used[i], used[j], used[k] = true, true, true
+				codes = append(codes, code)
+			}
+		}
+	}
+	return codes
+}
+
+// findFinderPatterns finds the centers of the patterns whose runs are in the ratios of finder patterns through
+// their centers both across and down the image, keeping the most often seen.
+func findFinderPatterns(b *binaryImage) []finderPattern {
+	var finders []finderPattern
+	for y := 0; y < b.height; y++ {
+		row := b.row(y)
+		for x := 0; x < b.width; x++ {
+			// each dark run is tried once, from its start
+			if !row(x) || (x > 0 && row(x-1)) {
+				continue
+			}
+			hRuns, cx, ok := runsAround(row, b.width, x)
+			if !ok || !finderRatio(hRuns) {
+				continue
+			}
+			vRuns, cy, ok := runsAround(b.column(int(math.Round(cx))), b.height, y)
+			if !ok || !finderRatio(vRuns) {
+				continue
+			}
+			// the center across again, at the center down
+			hRuns, cx, ok = runsAround(b.row(int(math.Round(cy))), b.width, int(math.Round(cx)))
+			if !ok || !finderRatio(hRuns) {
+				continue
+			}
+			hTotal, vTotal := 0, 0
+			for i := range hRuns {
+				hTotal += hRuns[i]
+				vTotal += vRuns[i]
+			}
+			if math.Abs(float64(hTotal-vTotal)) > 0.4*float64(max(hTotal, vTotal)) {
+				continue
+			}
+			finders = addFinder(finders, r2.Point{X: cx, Y: cy}, float64(hTotal+vTotal)/14)
+		}
+	}
+	kept := finders[:0]
+	for _, f := range finders {
+		if f.hits >= minFinderHits {
+			kept = append(kept, f)
+		}
+	}
+	for i := 1; i < len(kept); i++ {
+		for j := i; j > 0 && kept[j].hits > kept[j-1].hits; j-- {
+			kept[j], kept[j-1] = kept[j-1], kept[j]
+		}
+	}
+	if len(kept) > maxFinders {
+		kept = kept[:maxFinders]
+	}
+	return kept
+}
+
+// addFinder adds a sighting of a finder pattern, averaging it into a pattern already seen at about the same place.
+func addFinder(finders []finderPattern, center r2.Point, moduleSize float64) []finderPattern {
+	for i, f := range finders {
+		if f.center.Sub(center).Norm() <= 2*f.moduleSize && moduleSize < 1.5*f.moduleSize && f.moduleSize < 1.5*moduleSize {
+			n := float64(f.hits)
+			finders[i] = finderPattern{
+				center:     f.center.Mul(n).Add(center).Mul(1 / (n + 1)),
+				moduleSize: (f.moduleSize*n + moduleSize) / (n + 1),
+				hits:       f.hits + 1,
+			}
+			return finders
+		}
+	}
+	return append(finders, finderPattern{center: center, moduleSize: moduleSize, hits: 1})
+}
+
+// orderFinders orders three finder patterns as the top left, top right and bottom left corners of a QR code, which
+// are a right angle at the top left going clockwise from the top right to the bottom left.
+func orderFinders(a, b, c finderPattern) (finderPattern, finderPattern, finderPattern, bool) {
+	sizes := []float64{a.moduleSize, b.moduleSize, c.moduleSize}
+	for _, s := range sizes[1:] {
+		if s > 1.5*sizes[0] || sizes[0] > 1.5*s {
+			return a, b, c, false
+		}
+	}
+	// the top left is opposite the longest side
+	ab, bc, ca := a.center.Sub(b.center).Norm(), b.center.Sub(c.center).Norm(), c.center.Sub(a.center).Norm()
+	switch {
+	case bc >= ab && bc >= ca:
+	case ca >= ab && ca >= bc:
+		a, b = b, a
+	default:
+		a, c = c, a
+	}
+	legB, legC := b.center.Sub(a.center), c.center.Sub(a.center)
+	if legB.Norm() > 1.5*legC.Norm() || legC.Norm() > 1.5*legB.Norm() {
+		return a, b, c, false
+	}
+	if math.Abs(legB.Dot(legC)) > 0.3*legB.Norm()*legC.Norm() {
+		return a, b, c, false
+	}
+	if legB.Cross(legC) < 0 {
+		b, c = c, b
+	}
+	return a, b, c, true
+}
+
+// readQRCode samples and decodes the QR code with the given finder patterns.
+func readQRCode(b *binaryImage, tl, tr, bl finderPattern) (Code, error) {
+	moduleSize := (tl.moduleSize + tr.moduleSize + bl.moduleSize) / 3
+	across := int(math.Round(tr.center.Sub(tl.center).Norm() / moduleSize))
+	down := int(math.Round(bl.center.Sub(tl.center).Norm() / moduleSize))
+	dim := (across+down)/2 + 7
+	switch dim % 4 {
+	case 0:
+		dim++
+	case 2:
+		dim--
+	case 3:
+		return Code{}, errors.New("QR code has an impossible dimension")
+	}
+	version := (dim - 17) / 4
+	if version < 1 || version > len(qrVersions) {
+		return Code{}, errors.Errorf("QR code has an unknown version %d", version)
+	}
+
+	// the bottom right is where the finder patterns would put it if the code were not in perspective, and the
+	// alignment pattern near it, when there is one, is where it is
+	far := float64(dim) - 3.5
+	bottomRight := tr.center.Add(bl.center).Sub(tl.center)
+	if version > 1 {
+		near := float64(dim) - 6.5
+		scale := (near - 3.5) / (far - 3.5)
+		expected := tl.center.Add(tr.center.Sub(tl.center).Mul(scale)).Add(bl.center.Sub(tl.center).Mul(scale))
+		if alignment, ok := findAlignmentPattern(b, expected, moduleSize); ok {
+			bottomRight, far = alignment, near
+		}
+	}
+	h, ok := homography(
+		[4]r2.Point{{X: 3.5, Y: 3.5}, {X: float64(dim) - 3.5, Y: 3.5}, {X: far, Y: far}, {X: 3.5, Y: float64(dim) - 3.5}},
+		[4]r2.Point{tl.center, tr.center, bottomRight, bl.center})
+	if !ok {
+		return Code{}, errors.New("cannot solve for the homography of the QR code")
+	}
+	modules := make([][]bool, dim)
+	for r := range modules {
+		modules[r] = make([]bool, dim)
+		for c := range modules[r] {
+			p := h.apply(r2.Point{X: float64(c) + 0.5, Y: float64(r) + 0.5})
+			modules[r][c] = b.at(int(math.Round(p.X)), int(math.Round(p.Y)))
+		}
+	}
+	payload, err := decodeQRModules(modules, version)
+	if err != nil {
+		return Code{}, err
+	}
+	size := float64(dim)
+	code := Code{Format: QRCode, Payload: payload}
+	for i, corner := range [4]r2.Point{{X: 0, Y: 0}, {X: size, Y: 0}, {X: size, Y: size}, {X: 0, Y: size}} {
+		// the edges of the modules are half a pixel from the centers of the pixels
+		code.Corners[i] = h.apply(corner).Sub(r2.Point{X: 0.5, Y: 0.5})
+	}
+	return code, nil
+}
+
+// findAlignmentPattern finds the alignment pattern closest to where it is expected.
+func findAlignmentPattern(b *binaryImage, expected r2.Point, moduleSize float64) (r2.Point, bool) {
+	radius := alignmentSearchModules * moduleSize
+	var best r2.Point
+	bestDist := math.Inf(1)
+	for y := int(expected.Y - radius); y <= int(expected.Y+radius); y++ {
+		row := b.row(y)
+		for x := int(expected.X - radius); x <= int(expected.X+radius); x++ {
+			if !row(x) || row(x-1) {
+				continue
+			}
+			hRuns, cx, ok := runsAround(row, b.width, x)
+			if !ok || !alignmentRatio(hRuns, moduleSize) {
+				continue
+			}
+			vRuns, cy, ok := runsAround(b.column(int(math.Round(cx))), b.height, y)
+			if !ok || !alignmentRatio(vRuns, moduleSize) {
+				continue
+			}
+			center := r2.Point{X: cx, Y: cy}
+			if dist := center.Sub(expected).Norm(); dist < bestDist {
+				best, bestDist = center, dist
+			}
+		}
+	}
+	return best, bestDist <= radius
+}
+
+// decodeQRModules decodes the modules of a QR code of a version, dark modules true, by row and column.
+func decodeQRModules(modules [][]bool, version int) (string, error) {
+	dim := len(modules)
+	// the format information is whichever valid format either copy of it is closest to
+	bestFormat, bestErrors := 0, maxFormatErrors+1
+	for _, positions := range formatModules(dim) {
+		read := 0
+		for _, p := range positions {
+			read <<= 1
+			if modules[p.Y][p.X] {
+				read |= 1
+			}
+		}
+		for data := 0; data < 32; data++ {
+			if errs := bitCount(read ^ formatInfo(data)); errs < bestErrors {
+				bestFormat, bestErrors = data, errs
+			}
+		}
+	}
+	if bestErrors > maxFormatErrors {
+		return "", errors.New("cannot read the format information of the QR code")
+	}
+	level, mask := bestFormat>>3, bestFormat&7
+
+	positions := dataModules(version)
+	raw := make([]byte, len(positions)/8)
+	for i := range raw {
+		var cw byte
+		for _, p := range positions[8*i : 8*i+8] {
+			cw <<= 1
+			if modules[p.Y][p.X] != masked(mask, p.Y, p.X) {
+				cw |= 1
+			}
+		}
+		raw[i] = cw
+	}
+	data, err := correctBlocks(raw, qrVersions[version-1].blocks[level])
+	if err != nil {
+		return "", err
+	}
+	return decodeSegments(data, version)
+}
+
+func bitCount(x int) int {
+	n := 0
+	for ; x != 0; x &= x - 1 {
+		n++
+	}
+	return n
+}
+
+// correctBlocks splits the interleaved codewords of a QR code into their blocks, corrects each, and returns their
+// data codewords in order. The data codewords are interleaved first, a codeword from each block in turn, with the
+// longer blocks having one last codeword each, and the error correction codewords after them.
+func correctBlocks(raw []byte, blocks ecBlocks) ([]byte, error) {
+	var dataLens []int
+	for _, g := range blocks.groups {
+		for i := 0; i < g[0]; i++ {
+			dataLens = append(dataLens, g[1])
+		}
+	}
+	longest := dataLens[len(dataLens)-1]
+	codewords := make([][]byte, len(dataLens))
+	total := 0
+	for i, n := range dataLens {
+		codewords[i] = make([]byte, n+blocks.ecCodewords)
+		total += n + blocks.ecCodewords
+	}
+	if total > len(raw) {
+		return nil, errors.New("QR code has too few codewords")
+	}
+	next := 0
+	for i := 0; i < longest; i++ {
+		for j, n := range dataLens {
+			if i < n {
+				codewords[j][i] = raw[next]
+				next++
+			}
+		}
+	}
+	for i := 0; i < blocks.ecCodewords; i++ {
+		for j, n := range dataLens {
+			codewords[j][n+i] = raw[next]
+			next++
+		}
+	}
+	var data []byte
+	for j, n := range dataLens {
+		if err := rsCorrect(codewords[j], blocks.ecCodewords); err != nil {
+			return nil, err
+		}
+		data = append(data, codewords[j][:n]...)
+	}
+	return data, nil
+}
+
+// bitReader reads bits from the most significant of each byte.
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) available() int {
+	return 8*len(r.data) - r.pos
+}
+
+func (r *bitReader) read(n int) (int, error) {
+	if n > r.available() {
+		return 0, errors.New("QR code data ends early")
+	}
+	v := 0
+	for i := 0; i < n; i++ {
+		v <<= 1
+		if r.data[r.pos/8]&(0x80>>(r.pos%8)) != 0 {
+			v |= 1
+		}
+		r.pos++
+	}
+	return v, nil
+}
+
+// countBits is the size of the character counts of a mode of QR codes of a version, for versions up to 9, 26 and
+// 40.
+func countBits(counts [3]int, version int) int {
+	switch {
+	case version <= 9:
+		return counts[0]
+	case version <= 26:
+		return counts[1]
+	default:
+		return counts[2]
+	}
+}
+
+// decodeSegments decodes the segments of data of a QR code in the numeric, alphanumeric and byte modes. Bytes are
+// text in UTF-8 if they are valid as it, unless an ECI says they are in ISO-8859-1.
+func decodeSegments(data []byte, version int) (string, error) {
+	r := &bitReader{data: data}
+	var text []byte
+	eci := -1
+	for r.available() >= 4 {
+		mode, err := r.read(4)
+		if err != nil {
+			return "", err
+		}
+		switch mode {
+		case 0x0:
+			return string(text), nil
+		case 0x1:
+			count, err := r.read(countBits([3]int{10, 12, 14}, version))
+			if err != nil {
+				return "", err
+			}
+			for ; count > 0; count -= 3 {
+				digits, bits := min(count, 3), [4]int{0, 4, 7, 10}[min(count, 3)]
+				v, err := r.read(bits)
+				if err != nil {
+					return "", err
+				}
+				s := strconv.Itoa(v)
+				if len(s) > digits {
+					return "", errors.New("invalid numeric data in QR code")
+				}
+				for len(s) < digits {
+					s = "0" + s
+				}
+				text = append(text, s...)
+			}
+		case 0x2:
+			count, err := r.read(countBits([3]int{9, 11, 13}, version))
+			if err != nil {
+				return "", err
+			}
+			for ; count > 0; count -= 2 {
+				if count == 1 {
+					v, err := r.read(6)
+					if err != nil {
+						return "", err
+					}
+					if v >= len(alphanumeric) {
+						return "", errors.New("invalid alphanumeric data in QR code")
+					}
+					text = append(text, alphanumeric[v])
+					break
+				}
+				v, err := r.read(11)
+				if err != nil {
+					return "", err
+				}
+				if v >= len(alphanumeric)*len(alphanumeric) {
+					return "", errors.New("invalid alphanumeric data in QR code")
+				}
+				text = append(text, alphanumeric[v/len(alphanumeric)], alphanumeric[v%len(alphanumeric)])
+			}
+		case 0x4:
+			count, err := r.read(countBits([3]int{8, 16, 16}, version))
+			if err != nil {
+				return "", err
+			}
+			bytes := make([]byte, count)
+			for i := range bytes {
+				v, err := r.read(8)
+				if err != nil {
+					return "", err
+				}
+				bytes[i] = byte(v)
+			}
+			if eci == eciLatin1 || (eci != eciUTF8 && !utf8.Valid(bytes)) {
+				for _, c := range bytes {
+					text = utf8.AppendRune(text, rune(c))
+				}
+			} else {
+				text = append(text, bytes...)
+			}
+		case 0x7:
+			first, err := r.read(8)
+			if err != nil {
+				return "", err
+			}
+			switch {
+			case first&0x80 == 0:
+				eci = first
+			case first&0xc0 == 0x80:
+				second, err := r.read(8)
+				if err != nil {
+					return "", err
+				}
+				eci = (first&0x3f)<<8 | second
+			default:
+				rest, err := r.read(16)
+				if err != nil {
+					return "", err
+				}
+				eci = (first&0x1f)<<16 | rest
+			}
+		case 0x3:
+			// structured append, whose other codes hold the rest of the data
+			if _, err := r.read(16); err != nil {
+				return "", err
+			}
+		case 0x5:
+			// FNC1 in the first position, of GS1 data
+		case 0x9:
+			if _, err := r.read(8); err != nil {
+				return "", err
+			}
+		default:
+			return "", errors.Errorf("unsupported QR code mode %d", mode)
+		}
+	}
+	return string(text), nil
+}
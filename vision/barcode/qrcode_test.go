@@ -0,0 +1,341 @@
+package barcode
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"go.viam.com/test"
+)
+
+// rsEncode returns the error correction codewords of a Reed-Solomon block of data.
+func rsEncode(data []byte, ecLen int) []byte {
+	generator := []byte{1}
+	for i := 0; i < ecLen; i++ {
+		next := make([]byte, len(generator)+1)
+		for j, g := range generator {
+			next[j] ^= g
+			next[j+1] ^= gfMul(g, gfExp[i])
+		}
+		generator = next
+	}
+	rem := make([]byte, ecLen)
+	for _, d := range data {
+		factor := d ^ rem[0]
+		rem = append(rem[1:], 0)
+		for k := range rem {
+			rem[k] ^= gfMul(generator[k+1], factor)
+		}
+	}
+	return rem
+}
+
+// encodeQR encodes text in the byte mode as the modules of a QR code, dark modules true.
+func encodeQR(t *testing.T, text string, version, level, mask int) [][]bool {
+	t.Helper()
+	blocks := qrVersions[version-1].blocks[level]
+	capacity := 0
+	var dataLens []int
+	for _, g := range blocks.groups {
+		for i := 0; i < g[0]; i++ {
+			dataLens = append(dataLens, g[1])
+			capacity += g[1]
+		}
+	}
+
+	var bits []bool
+	push := func(v, n int) {
+		for i := n - 1; i >= 0; i-- {
+			bits = append(bits, v>>i&1 == 1)
+		}
+	}
+	push(0x4, 4)
+	push(len(text), countBits([3]int{8, 16, 16}, version))
+	for _, c := range []byte(text) {
+		push(int(c), 8)
+	}
+	test.That(t, len(bits), test.ShouldBeLessThanOrEqualTo, 8*capacity)
+	push(0, min(4, 8*capacity-len(bits)))
+	for len(bits)%8 != 0 {
+		bits = append(bits, false)
+	}
+	data := make([]byte, 0, capacity)
+	for i := 0; i < len(bits); i += 8 {
+		var b byte
+		for _, bit := range bits[i : i+8] {
+			b <<= 1
+			if bit {
+				b |= 1
+			}
+		}
+		data = append(data, b)
+	}
+	for pad := byte(0xec); len(data) < capacity; pad ^= 0xec ^ 0x11 {
+		data = append(data, pad)
+	}
+
+	var blockData, blockEC [][]byte
+	for _, n := range dataLens {
+		blockData = append(blockData, data[:n])
+		blockEC = append(blockEC, rsEncode(data[:n], blocks.ecCodewords))
+		data = data[n:]
+	}
+	var codewords []byte
+	for i := 0; i < dataLens[len(dataLens)-1]; i++ {
+		for _, d := range blockData {
+			if i < len(d) {
+				codewords = append(codewords, d[i])
+			}
+		}
+	}
+	for i := 0; i < blocks.ecCodewords; i++ {
+		for _, ec := range blockEC {
+			codewords = append(codewords, ec[i])
+		}
+	}
+
+	dim := qrDimension(version)
+	modules := make([][]bool, dim)
+	for r := range modules {
+		modules[r] = make([]bool, dim)
+	}
+	for _, corner := range []image.Point{{0, 0}, {dim - 7, 0}, {0, dim - 7}} {
+		for r := 0; r < 7; r++ {
+			for c := 0; c < 7; c++ {
+				ring := max(abs(r-3), abs(c-3))
+				modules[corner.Y+r][corner.X+c] = ring != 2
+			}
+		}
+	}
+	for i := 8; i < dim-8; i++ {
+		modules[6][i], modules[i][6] = i%2 == 0, i%2 == 0
+	}
+	alignment := qrVersions[version-1].alignment
+	last := len(alignment) - 1
+	for i, row := range alignment {
+		for j, col := range alignment {
+			if (i == 0 && (j == 0 || j == last)) || (i == last && j == 0) {
+				continue
+			}
+			for r := -2; r <= 2; r++ {
+				for c := -2; c <= 2; c++ {
+					modules[row+r][col+c] = max(abs(r), abs(c)) != 1
+				}
+			}
+		}
+	}
+	modules[dim-8][8] = true
+	format := formatInfo(level<<3 | mask)
+	for _, positions := range formatModules(dim) {
+		for i, p := range positions {
+			modules[p.Y][p.X] = format>>(14-i)&1 == 1
+		}
+	}
+	for i, p := range dataModules(version) {
+		bit := i < 8*len(codewords) && codewords[i/8]&(0x80>>(i%8)) != 0
+		modules[p.Y][p.X] = bit != masked(mask, p.Y, p.X)
+	}
+	return modules
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// renderQR draws the modules of a QR code in a quiet zone of 4 modules, each module px pixels wide, through a
+// homography of the image of the modules when the corners of the code are given.
+func renderQR(t *testing.T, modules [][]bool, px int, corners *[4]r2.Point) *image.Gray {
+	t.Helper()
+	dim := len(modules)
+	size := (dim + 8) * px
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	toModules := func(p r2.Point) r2.Point {
+		return r2.Point{X: p.X/float64(px) - 4, Y: p.Y/float64(px) - 4}
+	}
+	if corners != nil {
+		d := float64(dim)
+		h, ok := homography(*corners, [4]r2.Point{{X: 0, Y: 0}, {X: d, Y: 0}, {X: d, Y: d}, {X: 0, Y: d}})
+		test.That(t, ok, test.ShouldBeTrue)
+		toModules = h.apply
+	}
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			m := toModules(r2.Point{X: float64(x) + 0.5, Y: float64(y) + 0.5})
+			r, c := int(math.Floor(m.Y)), int(math.Floor(m.X))
+			v := uint8(230)
+			if r >= 0 && c >= 0 && r < dim && c < dim && modules[r][c] {
+				v = 25
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	return img
+}
+
+func rotateModules(modules [][]bool) [][]bool {
+	dim := len(modules)
+	rotated := make([][]bool, dim)
+	for r := range rotated {
+		rotated[r] = make([]bool, dim)
+		for c := range rotated[r] {
+			rotated[r][c] = modules[dim-1-c][r]
+		}
+	}
+	return rotated
+}
+
+func TestQRVersions(t *testing.T) {
+	for version := 1; version <= len(qrVersions); version++ {
+		codewords := len(dataModules(version)) / 8
+		for level, blocks := range qrVersions[version-1].blocks {
+			total := 0
+			for _, g := range blocks.groups {
+				total += g[0] * (g[1] + blocks.ecCodewords)
+			}
+			test.That(t, total, test.ShouldEqual, codewords)
+			test.That(t, level, test.ShouldBeLessThan, 4)
+		}
+	}
+	test.That(t, len(dataModules(1)), test.ShouldEqual, 208)
+	test.That(t, len(dataModules(7))/8, test.ShouldEqual, 196)
+	test.That(t, len(dataModules(40))/8, test.ShouldEqual, 3706)
+
+	// the format information of levels M and L with mask 0
+	test.That(t, formatInfo(ecLevelM<<3), test.ShouldEqual, 0x5412)
+	test.That(t, formatInfo(ecLevelL<<3), test.ShouldEqual, 0x77c4)
+}
+
+func TestReedSolomon(t *testing.T) {
+	// "01234567" as a version 1-M QR code, as in ISO/IEC 18004
+	example := []byte{0x10, 0x20, 0x0c, 0x56, 0x61, 0x80, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11}
+	test.That(t, rsEncode(example, 10), test.ShouldResemble,
+		[]byte{0xa5, 0x24, 0xd4, 0xc1, 0xed, 0x36, 0xc7, 0x87, 0x2c, 0x55})
+
+	data := []byte("reed-solomon")
+	block := append(append([]byte(nil), data...), rsEncode(data, 10)...)
+	test.That(t, rsCorrect(block, 10), test.ShouldBeNil)
+	test.That(t, block[:len(data)], test.ShouldResemble, data)
+
+	for _, i := range []int{0, 3, 7, 15, 21} {
+		block[i] ^= 0x5a
+	}
+	test.That(t, rsCorrect(block, 10), test.ShouldBeNil)
+	test.That(t, block[:len(data)], test.ShouldResemble, data)
+
+	for _, i := range []int{0, 2, 4, 6, 8, 10} {
+		block[i] ^= 0xff
+	}
+	test.That(t, rsCorrect(block, 10), test.ShouldNotBeNil)
+}
+
+func TestDecodeSegments(t *testing.T) {
+	// numeric "01234567" and alphanumeric "AC-42" as in ISO/IEC 18004
+	var bits []bool
+	push := func(v, n int) {
+		for i := n - 1; i >= 0; i-- {
+			bits = append(bits, v>>i&1 == 1)
+		}
+	}
+	push(0x1, 4)
+	push(8, 10)
+	push(12, 10)
+	push(345, 10)
+	push(67, 7)
+	push(0x2, 4)
+	push(5, 9)
+	push(10*45+12, 11)
+	push(41*45+4, 11)
+	push(2, 6)
+	push(0, 4)
+	data := make([]byte, (len(bits)+7)/8)
+	for i, bit := range bits {
+		if bit {
+			data[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	text, err := decodeSegments(data, 1)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, text, test.ShouldEqual, "01234567AC-42")
+}
+
+func TestDetectQRCode(t *testing.T) {
+	for _, tc := range []struct {
+		name                 string
+		text                 string
+		version, level, mask int
+		rotate, perspective  bool
+		flipped              []image.Point
+	}{
+		{name: "version 1", text: "hello", version: 1, level: ecLevelM, mask: 0},
+		{name: "version 2 with an alignment pattern", text: "https://www.viam.com", version: 2, level: ecLevelL, mask: 3},
+		{name: "version 5 with blocks of two sizes", text: "a QR code with two groups of blocks", version: 5, level: ecLevelQ, mask: 6},
+		{name: "version 8", text: "version eight of QR codes", version: 8, level: ecLevelH, mask: 7},
+		{name: "rotated", text: "turned", version: 2, level: ecLevelM, mask: 4, rotate: true},
+		{name: "in perspective", text: "slanted", version: 3, level: ecLevelM, mask: 2, perspective: true},
+		{
+			name: "with errors", text: "smudged", version: 1, level: ecLevelH, mask: 5,
+			flipped: []image.Point{{X: 20, Y: 20}, {X: 19, Y: 18}, {X: 12, Y: 12}, {X: 10, Y: 15}, {X: 9, Y: 20}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			modules := encodeQR(t, tc.text, tc.version, tc.level, tc.mask)
+			for _, p := range tc.flipped {
+				modules[p.Y][p.X] = !modules[p.Y][p.X]
+			}
+			px := 4
+			dim := float64(len(modules))
+			corners := [4]r2.Point{{X: 16, Y: 16}, {X: 16 + dim*4, Y: 16}, {X: 16 + dim*4, Y: 16 + dim*4}, {X: 16, Y: 16 + dim*4}}
+			var warp *[4]r2.Point
+			if tc.rotate {
+				modules = rotateModules(modules)
+				// the top left of the code is now at the top right
+				corners = [4]r2.Point{corners[1], corners[2], corners[3], corners[0]}
+			}
+			if tc.perspective {
+				corners = [4]r2.Point{{X: 20, Y: 14}, {X: 20 + dim*4, Y: 22}, {X: 14 + dim*4, Y: 16 + dim*4}, {X: 24, Y: 10 + dim*4}}
+				warp = &corners
+			}
+			img := renderQR(t, modules, px, warp)
+			codes := Detect(img)
+			test.That(t, len(codes), test.ShouldEqual, 1)
+			test.That(t, codes[0].Format, test.ShouldEqual, QRCode)
+			test.That(t, codes[0].Payload, test.ShouldEqual, tc.text)
+			for i, corner := range codes[0].Corners {
+				// the corners are given at the edges of pixels, half a pixel from their centers
+				expected := corners[i].Sub(r2.Point{X: 0.5, Y: 0.5})
+				test.That(t, corner.Sub(expected).Norm(), test.ShouldBeLessThan, 2)
+			}
+		})
+	}
+}
+
+func TestDetectQRCodes(t *testing.T) {
+	first := renderQR(t, encodeQR(t, "first", 1, ecLevelM, 1), 4, nil)
+	second := renderQR(t, encodeQR(t, "second", 2, ecLevelM, 2), 4, nil)
+	img := image.NewGray(image.Rect(0, 0, 400, 200))
+	for i := range img.Pix {
+		img.Pix[i] = 230
+	}
+	for y := 0; y < first.Bounds().Dy(); y++ {
+		for x := 0; x < first.Bounds().Dx(); x++ {
+			img.SetGray(x+10, y+30, first.GrayAt(x, y))
+		}
+	}
+	for y := 0; y < second.Bounds().Dy(); y++ {
+		for x := 0; x < second.Bounds().Dx(); x++ {
+			img.SetGray(x+200, y+10, second.GrayAt(x, y))
+		}
+	}
+	codes := Detect(img)
+	test.That(t, len(codes), test.ShouldEqual, 2)
+	// from the top of the image
+	test.That(t, codes[0].Payload, test.ShouldEqual, "second")
+	test.That(t, codes[1].Payload, test.ShouldEqual, "first")
+
+	test.That(t, Detect(img, Code128), test.ShouldBeEmpty)
+}
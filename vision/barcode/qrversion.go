@@ -0,0 +1,253 @@
+package barcode
+
+import "image"
+
+// The error correction levels of QR codes, by the two bits of their format information.
+const (
+	ecLevelM = 0
+	ecLevelL = 1
+	ecLevelH = 2
+	ecLevelQ = 3
+)
+
+// ecBlocks are the Reed-Solomon blocks of a QR code of some version and error correction level. Each block has
+// ecCodewords error correction codewords after its data codewords, and the groups give how many blocks have how many
+// data codewords, with the shorter blocks first.
+type ecBlocks struct {
+	ecCodewords int
+	groups      [][2]int
+}
+
+// qrVersion is what a version of QR codes fixes about their layout.
+type qrVersion struct {
+	// alignment are the rows and columns of the centers of the alignment patterns.
+	alignment []int
+	// blocks are the blocks of each error correction level, by the bits of the level.
+	blocks [4]ecBlocks
+}
+
+// qrDimension is the number of modules on a side of a QR code of a version.
+func qrDimension(version int) int {
+	return 17 + 4*version
+}
+
+// qrVersions are the versions of QR codes from 1 to 40, as ISO/IEC 18004 tables them.
+var qrVersions = [40]qrVersion{
+	newQRVersion(nil, 10, [][2]int{{1, 16}}, 7, [][2]int{{1, 19}}, 17, [][2]int{{1, 9}}, 13, [][2]int{{1, 13}}),
+	newQRVersion([]int{6, 18}, 16, [][2]int{{1, 28}}, 10, [][2]int{{1, 34}}, 28, [][2]int{{1, 16}}, 22, [][2]int{{1, 22}}),
+	newQRVersion([]int{6, 22}, 26, [][2]int{{1, 44}}, 15, [][2]int{{1, 55}}, 22, [][2]int{{2, 13}}, 18, [][2]int{{2, 17}}),
+	newQRVersion([]int{6, 26}, 18, [][2]int{{2, 32}}, 20, [][2]int{{1, 80}}, 16, [][2]int{{4, 9}}, 26, [][2]int{{2, 24}}),
+	newQRVersion([]int{6, 30}, 24, [][2]int{{2, 43}}, 26, [][2]int{{1, 108}},
+		22, [][2]int{{2, 11}, {2, 12}}, 18, [][2]int{{2, 15}, {2, 16}}),
+	newQRVersion([]int{6, 34}, 16, [][2]int{{4, 27}}, 18, [][2]int{{2, 68}}, 28, [][2]int{{4, 15}}, 24, [][2]int{{4, 19}}),
+	newQRVersion([]int{6, 22, 38}, 18, [][2]int{{4, 31}}, 20, [][2]int{{2, 78}},
+		26, [][2]int{{4, 13}, {1, 14}}, 18, [][2]int{{2, 14}, {4, 15}}),
+	newQRVersion([]int{6, 24, 42}, 22, [][2]int{{2, 38}, {2, 39}}, 24, [][2]int{{2, 97}},
+		26, [][2]int{{4, 14}, {2, 15}}, 22, [][2]int{{4, 18}, {2, 19}}),
+	newQRVersion([]int{6, 26, 46}, 22, [][2]int{{3, 36}, {2, 37}}, 30, [][2]int{{2, 116}},
+		24, [][2]int{{4, 12}, {4, 13}}, 20, [][2]int{{4, 16}, {4, 17}}),
+	newQRVersion([]int{6, 28, 50}, 26, [][2]int{{4, 43}, {1, 44}}, 18, [][2]int{{2, 68}, {2, 69}},
+		28, [][2]int{{6, 15}, {2, 16}}, 24, [][2]int{{6, 19}, {2, 20}}),
+	newQRVersion([]int{6, 30, 54}, 30, [][2]int{{1, 50}, {4, 51}}, 20, [][2]int{{4, 81}},
+		24, [][2]int{{3, 12}, {8, 13}}, 28, [][2]int{{4, 22}, {4, 23}}),
+	newQRVersion([]int{6, 32, 58}, 22, [][2]int{{6, 36}, {2, 37}}, 24, [][2]int{{2, 92}, {2, 93}},
+		28, [][2]int{{7, 14}, {4, 15}}, 26, [][2]int{{4, 20}, {6, 21}}),
+	newQRVersion([]int{6, 34, 62}, 22, [][2]int{{8, 37}, {1, 38}}, 26, [][2]int{{4, 107}},
+		22, [][2]int{{12, 11}, {4, 12}}, 24, [][2]int{{8, 20}, {4, 21}}),
+	newQRVersion([]int{6, 26, 46, 66}, 24, [][2]int{{4, 40}, {5, 41}}, 30, [][2]int{{3, 115}, {1, 116}},
+		24, [][2]int{{11, 12}, {5, 13}}, 20, [][2]int{{11, 16}, {5, 17}}),
+	newQRVersion([]int{6, 26, 48, 70}, 24, [][2]int{{5, 41}, {5, 42}}, 22, [][2]int{{5, 87}, {1, 88}},
+		24, [][2]int{{11, 12}, {7, 13}}, 30, [][2]int{{5, 24}, {7, 25}}),
+	newQRVersion([]int{6, 26, 50, 74}, 28, [][2]int{{7, 45}, {3, 46}}, 24, [][2]int{{5, 98}, {1, 99}},
+		30, [][2]int{{3, 15}, {13, 16}}, 24, [][2]int{{15, 19}, {2, 20}}),
+	newQRVersion([]int{6, 30, 54, 78}, 28, [][2]int{{10, 46}, {1, 47}}, 28, [][2]int{{1, 107}, {5, 108}},
+		28, [][2]int{{2, 14}, {17, 15}}, 28, [][2]int{{1, 22}, {15, 23}}),
+	newQRVersion([]int{6, 30, 56, 82}, 26, [][2]int{{9, 43}, {4, 44}}, 30, [][2]int{{5, 120}, {1, 121}},
+		28, [][2]int{{2, 14}, {19, 15}}, 28, [][2]int{{17, 22}, {1, 23}}),
+	newQRVersion([]int{6, 30, 58, 86}, 26, [][2]int{{3, 44}, {11, 45}}, 28, [][2]int{{3, 113}, {4, 114}},
+		26, [][2]int{{9, 13}, {16, 14}}, 26, [][2]int{{17, 21}, {4, 22}}),
+	newQRVersion([]int{6, 34, 62, 90}, 26, [][2]int{{3, 41}, {13, 42}}, 28, [][2]int{{3, 107}, {5, 108}},
+		28, [][2]int{{15, 15}, {10, 16}}, 30, [][2]int{{15, 24}, {5, 25}}),
+	newQRVersion([]int{6, 28, 50, 72, 94}, 26, [][2]int{{17, 42}}, 28, [][2]int{{4, 116}, {4, 117}},
+		30, [][2]int{{19, 16}, {6, 17}}, 28, [][2]int{{17, 22}, {6, 23}}),
+	newQRVersion([]int{6, 26, 50, 74, 98}, 28, [][2]int{{17, 46}}, 28, [][2]int{{2, 111}, {7, 112}},
+		24, [][2]int{{34, 13}}, 30, [][2]int{{7, 24}, {16, 25}}),
+	newQRVersion([]int{6, 30, 54, 78, 102}, 28, [][2]int{{4, 47}, {14, 48}}, 30, [][2]int{{4, 121}, {5, 122}},
+		30, [][2]int{{16, 15}, {14, 16}}, 30, [][2]int{{11, 24}, {14, 25}}),
+	newQRVersion([]int{6, 28, 54, 80, 106}, 28, [][2]int{{6, 45}, {14, 46}}, 30, [][2]int{{6, 117}, {4, 118}},
+		30, [][2]int{{30, 16}, {2, 17}}, 30, [][2]int{{11, 24}, {16, 25}}),
+	newQRVersion([]int{6, 32, 58, 84, 110}, 28, [][2]int{{8, 47}, {13, 48}}, 26, [][2]int{{8, 106}, {4, 107}},
+		30, [][2]int{{22, 15}, {13, 16}}, 30, [][2]int{{7, 24}, {22, 25}}),
+	newQRVersion([]int{6, 30, 58, 86, 114}, 28, [][2]int{{19, 46}, {4, 47}}, 28, [][2]int{{10, 114}, {2, 115}},
+		30, [][2]int{{33, 16}, {4, 17}}, 28, [][2]int{{28, 22}, {6, 23}}),
+	newQRVersion([]int{6, 34, 62, 90, 118}, 28, [][2]int{{22, 45}, {3, 46}}, 30, [][2]int{{8, 122}, {4, 123}},
+		30, [][2]int{{12, 15}, {28, 16}}, 30, [][2]int{{8, 23}, {26, 24}}),
+	newQRVersion([]int{6, 26, 50, 74, 98, 122}, 28, [][2]int{{3, 45}, {23, 46}}, 30, [][2]int{{3, 117}, {10, 118}},
+		30, [][2]int{{11, 15}, {31, 16}}, 30, [][2]int{{4, 24}, {31, 25}}),
+	newQRVersion([]int{6, 30, 54, 78, 102, 126}, 28, [][2]int{{21, 45}, {7, 46}}, 30, [][2]int{{7, 116}, {7, 117}},
+		30, [][2]int{{19, 15}, {26, 16}}, 30, [][2]int{{1, 23}, {37, 24}}),
+	newQRVersion([]int{6, 26, 52, 78, 104, 130}, 28, [][2]int{{19, 47}, {10, 48}}, 30, [][2]int{{5, 115}, {10, 116}},
+		30, [][2]int{{23, 15}, {25, 16}}, 30, [][2]int{{15, 24}, {25, 25}}),
+	newQRVersion([]int{6, 30, 56, 82, 108, 134}, 28, [][2]int{{2, 46}, {29, 47}}, 30, [][2]int{{13, 115}, {3, 116}},
+		30, [][2]int{{23, 15}, {28, 16}}, 30, [][2]int{{42, 24}, {1, 25}}),
+	newQRVersion([]int{6, 34, 60, 86, 112, 138}, 28, [][2]int{{10, 46}, {23, 47}}, 30, [][2]int{{17, 115}},
+		30, [][2]int{{19, 15}, {35, 16}}, 30, [][2]int{{10, 24}, {35, 25}}),
+	newQRVersion([]int{6, 30, 58, 86, 114, 142}, 28, [][2]int{{14, 46}, {21, 47}}, 30, [][2]int{{17, 115}, {1, 116}},
+		30, [][2]int{{11, 15}, {46, 16}}, 30, [][2]int{{29, 24}, {19, 25}}),
+	newQRVersion([]int{6, 34, 62, 90, 118, 146}, 28, [][2]int{{14, 46}, {23, 47}}, 30, [][2]int{{13, 115}, {6, 116}},
+		30, [][2]int{{59, 16}, {1, 17}}, 30, [][2]int{{44, 24}, {7, 25}}),
+	newQRVersion([]int{6, 30, 54, 78, 102, 126, 150}, 28, [][2]int{{12, 47}, {26, 48}}, 30, [][2]int{{12, 121}, {7, 122}},
+		30, [][2]int{{22, 15}, {41, 16}}, 30, [][2]int{{39, 24}, {14, 25}}),
+	newQRVersion([]int{6, 24, 50, 76, 102, 128, 154}, 28, [][2]int{{6, 47}, {34, 48}}, 30, [][2]int{{6, 121}, {14, 122}},
+		30, [][2]int{{2, 15}, {64, 16}}, 30, [][2]int{{46, 24}, {10, 25}}),
+	newQRVersion([]int{6, 28, 54, 80, 106, 132, 158}, 28, [][2]int{{29, 46}, {14, 47}}, 30, [][2]int{{17, 122}, {4, 123}},
+		30, [][2]int{{24, 15}, {46, 16}}, 30, [][2]int{{49, 24}, {10, 25}}),
+	newQRVersion([]int{6, 32, 58, 84, 110, 136, 162}, 28, [][2]int{{13, 46}, {32, 47}}, 30, [][2]int{{4, 122}, {18, 123}},
+		30, [][2]int{{42, 15}, {32, 16}}, 30, [][2]int{{48, 24}, {14, 25}}),
+	newQRVersion([]int{6, 26, 54, 82, 110, 138, 166}, 28, [][2]int{{40, 47}, {7, 48}}, 30, [][2]int{{20, 117}, {4, 118}},
+		30, [][2]int{{10, 15}, {67, 16}}, 30, [][2]int{{43, 24}, {22, 25}}),
+	newQRVersion([]int{6, 30, 58, 86, 114, 142, 170}, 28, [][2]int{{18, 47}, {31, 48}}, 30, [][2]int{{19, 118}, {6, 119}},
+		30, [][2]int{{20, 15}, {61, 16}}, 30, [][2]int{{34, 24}, {34, 25}}),
+}
+
+// newQRVersion makes a version from its alignment pattern centers and its blocks at the levels M, L, H and Q, in
+// the order of the bits of the levels.
+func newQRVersion(alignment []int,
+	ecM int, groupsM [][2]int, ecL int, groupsL [][2]int, ecH int, groupsH [][2]int, ecQ int, groupsQ [][2]int,
+) qrVersion {
+	return qrVersion{
+		alignment: alignment,
+		blocks: [4]ecBlocks{
+			ecLevelM: {ecCodewords: ecM, groups: groupsM},
+			ecLevelL: {ecCodewords: ecL, groups: groupsL},
+			ecLevelH: {ecCodewords: ecH, groups: groupsH},
+			ecLevelQ: {ecCodewords: ecQ, groups: groupsQ},
+		},
+	}
+}
+
+// functionModules marks the modules of a QR code of a version which are not data: the finder patterns with their
+// separators and format information, the timing patterns, the alignment patterns and the version information.
+func functionModules(version int) [][]bool {
+	dim := qrDimension(version)
+	marked := make([][]bool, dim)
+	for i := range marked {
+		marked[i] = make([]bool, dim)
+	}
+	region := func(x, y, w, h int) {
+		for r := y; r < y+h; r++ {
+			for c := x; c < x+w; c++ {
+				marked[r][c] = true
+			}
+		}
+	}
+	region(0, 0, 9, 9)
+	region(dim-8, 0, 8, 9)
+	region(0, dim-8, 9, 8)
+	alignment := qrVersions[version-1].alignment
+	last := len(alignment) - 1
+	for i, row := range alignment {
+		for j, col := range alignment {
+			// none overlap the finder patterns
+			if (i == 0 && (j == 0 || j == last)) || (i == last && j == 0) {
+				continue
+			}
+			region(col-2, row-2, 5, 5)
+		}
+	}
+	region(6, 9, 1, dim-17)
+	region(9, 6, dim-17, 1)
+	if version >= 7 {
+		region(dim-11, 0, 3, 6)
+		region(0, dim-11, 6, 3)
+	}
+	return marked
+}
+
+// dataModules returns the modules of the data of a QR code of a version in the order of its bits, which go up and
+// down pairs of columns from the bottom right, right to left in each pair, skipping the vertical timing pattern.
+func dataModules(version int) []image.Point {
+	marked := functionModules(version)
+	dim := len(marked)
+	var modules []image.Point
+	up := true
+	for right := dim - 1; right > 0; right -= 2 {
+		if right == 6 {
+			right--
+		}
+		for i := 0; i < dim; i++ {
+			row := i
+			if up {
+				row = dim - 1 - i
+			}
+			for col := right; col > right-2; col-- {
+				if !marked[row][col] {
+					modules = append(modules, image.Point{X: col, Y: row})
+				}
+			}
+		}
+		up = !up
+	}
+	return modules
+}
+
+// formatModules are the modules of the two copies of the format information of a QR code with dim modules on a
+// side, from its most significant bit.
+func formatModules(dim int) [2][15]image.Point {
+	var copies [2][15]image.Point
+	i := 0
+	for x := 0; x < 6; x++ {
+		copies[0][i] = image.Point{X: x, Y: 8}
+		i++
+	}
+	copies[0][6], copies[0][7], copies[0][8] = image.Point{X: 7, Y: 8}, image.Point{X: 8, Y: 8}, image.Point{X: 8, Y: 7}
+	i = 9
+	for y := 5; y >= 0; y-- {
+		copies[0][i] = image.Point{X: 8, Y: y}
+		i++
+	}
+	i = 0
+	for y := dim - 1; y >= dim-7; y-- {
+		copies[1][i] = image.Point{X: 8, Y: y}
+		i++
+	}
+	for x := dim - 8; x < dim; x++ {
+		copies[1][i] = image.Point{X: x, Y: 8}
+		i++
+	}
+	return copies
+}
+
+// formatInfo returns the 15 bits of format information of a QR code for 5 bits of error correction level and mask,
+// with their BCH code and masked as ISO/IEC 18004 has it.
+func formatInfo(data int) int {
+	rem := data << 10
+	for bit := 14; bit >= 10; bit-- {
+		if rem&(1<<bit) != 0 {
+			rem ^= 0x537 << (bit - 10)
+		}
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// masked is whether a mask inverts the module at a row and column.
+func masked(mask, row, col int) bool {
+	switch mask {
+	case 0:
+		return (row+col)%2 == 0
+	case 1:
+		return row%2 == 0
+	case 2:
+		return col%3 == 0
+	case 3:
+		return (row+col)%3 == 0
+	case 4:
+		return (row/2+col/3)%2 == 0
+	case 5:
+		return (row*col)%6 == 0
+	case 6:
+		return (row*col)%6 < 3
+	default:
+		return (row+col+(row*col)%3)%2 == 0
+	}
+}
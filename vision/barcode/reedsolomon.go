@@ -0,0 +1,129 @@
+package barcode
+
+import "github.com/pkg/errors"
+
+// gfPrimitive is the primitive polynomial of the Galois field GF(256) of QR codes, x^8 + x^4 + x^3 + x^2 + 1.
+const gfPrimitive = 0x11d
+
+// gfExp holds the powers of the generator 2 of the field, twice over so products need no modulo, and gfLog their
+// logarithms.
+var (
+	gfExp [510]byte
+	gfLog [256]int
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i], gfExp[i+255] = byte(x), byte(x)
+		gfLog[x] = i
+		x <<= 1
+		if x >= 256 {
+			x ^= gfPrimitive
+		}
+	}
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[gfLog[a]+gfLog[b]]
+}
+
+func gfInverse(a byte) byte {
+	return gfExp[255-gfLog[a]]
+}
+
+// gfEval evaluates a polynomial, with its coefficients from the lowest degree, at x.
+func gfEval(poly []byte, x byte) byte {
+	var y byte
+	for i := len(poly) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ poly[i]
+	}
+	return y
+}
+
+// rsCorrect corrects the errors in the codewords of a Reed-Solomon block in place, where the last ecLen codewords
+// are for error correction and the first is the coefficient of the highest degree. Up to ecLen/2 codewords can be
+// wrong, and it fails if more are.
+func rsCorrect(codewords []byte, ecLen int) error {
+	n := len(codewords)
+	syndromes := make([]byte, ecLen)
+	clean := true
+	for j := range syndromes {
+		var s byte
+		for _, c := range codewords {
+			s = gfMul(s, gfExp[j]) ^ c
+		}
+		syndromes[j] = s
+		clean = clean && s == 0
+	}
+	if clean {
+		return nil
+	}
+
+	// Berlekamp-Massey finds the error locator, whose roots are the inverses of the error positions
+	locator, prev := []byte{1}, []byte{1}
+	degree, shift, prevDiscrepancy := 0, 1, byte(1)
+	for k := 0; k < ecLen; k++ {
+		discrepancy := syndromes[k]
+		for i := 1; i <= degree && i < len(locator); i++ {
+			discrepancy ^= gfMul(locator[i], syndromes[k-i])
+		}
+		if discrepancy == 0 {
+			shift++
+			continue
+		}
+		coef := gfMul(discrepancy, gfInverse(prevDiscrepancy))
+		next := make([]byte, max(len(locator), len(prev)+shift))
+		copy(next, locator)
+		for i, p := range prev {
+			next[i+shift] ^= gfMul(coef, p)
+		}
+		if 2*degree <= k {
+			prev, degree, prevDiscrepancy, shift = locator, k+1-degree, discrepancy, 1
+		} else {
+			shift++
+		}
+		locator = next
+	}
+	if 2*degree > ecLen {
+		return errors.New("too many errors to correct")
+	}
+
+	// the evaluator is the product of the syndromes and the locator, up to the degree of the syndromes
+	evaluator := make([]byte, ecLen)
+	for i, s := range syndromes {
+		for j, l := range locator {
+			if i+j < ecLen {
+				evaluator[i+j] ^= gfMul(s, l)
+			}
+		}
+	}
+	// in a field of characteristic 2 the derivative of the locator only has its odd terms
+	derivative := make([]byte, len(locator))
+	for i := 1; i < len(locator); i += 2 {
+		derivative[i-1] = locator[i]
+	}
+
+	found := 0
+	for i := 0; i < n; i++ {
+		// the codeword at degree i, whose position is 2^i
+		xInv := gfExp[(255-i)%255]
+		if gfEval(locator, xInv) != 0 {
+			continue
+		}
+		denominator := gfEval(derivative, xInv)
+		if denominator == 0 {
+			return errors.New("cannot correct errors")
+		}
+		magnitude := gfMul(gfExp[i], gfMul(gfEval(evaluator, xInv), gfInverse(denominator)))
+		codewords[n-1-i] ^= magnitude
+		found++
+	}
+	if found != degree {
+		return errors.New("too many errors to correct")
+	}
+	return nil
+}
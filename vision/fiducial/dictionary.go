@@ -0,0 +1,114 @@
+package fiducial
+
+import (
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ArucoOriginal is the name of the dictionary of the original ArUco markers.
+const ArucoOriginal = "aruco_original"
+
+// arucoOriginalRows are the codes of the rows of an original ArUco marker, indexed by the two bits of the id each
+// row carries in its second and fourth cells. The rest of each row is parity.
+var arucoOriginalRows = [4]uint64{0b10000, 0b10111, 0b01001, 0b01110}
+
+// A Dictionary is a family of markers, each a square grid of Bits by Bits cells inside a black border one cell
+// wide. The code of a marker reads its cells row by row from the top left, most significant bit first, with white
+// cells as ones. This is how the AprilTag families such as tag36h11 and the ArUco dictionaries list their codes.
+type Dictionary struct {
+	Name string
+	Bits int
+	// MaxBitErrors is how many cells of a marker may be misread for it to still match its code.
+	MaxBitErrors int
+	codes        []uint64
+	ids          map[uint64]int
+}
+
+// NewDictionary returns a dictionary of markers of bits by bits cells whose codes are indexed by their ids.
+func NewDictionary(name string, bits int, codes []uint64, maxBitErrors int) (*Dictionary, error) {
+	if bits < 2 || bits > 8 {
+		return nil, errors.Errorf("markers must have from 2 to 8 bits on a side, got %d", bits)
+	}
+	if len(codes) == 0 {
+		return nil, errors.Errorf("dictionary %q has no codes", name)
+	}
+	if maxBitErrors < 0 {
+		return nil, errors.New("max bit errors cannot be negative")
+	}
+	d := &Dictionary{Name: name, Bits: bits, MaxBitErrors: maxBitErrors, codes: codes, ids: map[uint64]int{}}
+	for id, code := range codes {
+		if bits < 8 && code>>(bits*bits) != 0 {
+			return nil, errors.Errorf("code %#x of dictionary %q has more than %d bits", code, name, bits*bits)
+		}
+		d.ids[code] = id
+	}
+	return d, nil
+}
+
+// ParseCodes parses codes written in hexadecimal, such as "0xd7e00984b", as listed by the AprilTag sources.
+func ParseCodes(codes []string) ([]uint64, error) {
+	parsed := make([]uint64, 0, len(codes))
+	for _, s := range codes {
+		code, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "0x"), 16, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad code %q", s)
+		}
+		parsed = append(parsed, code)
+	}
+	return parsed, nil
+}
+
+// NewArucoOriginal returns the dictionary of the 1024 original ArUco markers, which are 5 by 5 cells. Each row
+// carries two bits of the id, the first row the most significant, and the rest of the row is parity.
+func NewArucoOriginal(maxBitErrors int) *Dictionary {
+	codes := make([]uint64, 1024)
+	for id := range codes {
+		for row := 0; row < 5; row++ {
+			codes[id] = codes[id]<<5 | arucoOriginalRows[(id>>(2*(4-row)))&3]
+		}
+	}
+	// the codes are valid by construction
+	d, _ := NewDictionary(ArucoOriginal, 5, codes, maxBitErrors)
+	return d
+}
+
+// Code returns the code of the marker with an id.
+func (d *Dictionary) Code(id int) (uint64, bool) {
+	if id < 0 || id >= len(d.codes) {
+		return 0, false
+	}
+	return d.codes[id], true
+}
+
+// match returns the id of the code nearest to a code read from a marker, and how many bits differ between them.
+func (d *Dictionary) match(code uint64) (int, int, bool) {
+	if id, ok := d.ids[code]; ok {
+		return id, 0, true
+	}
+	if d.MaxBitErrors == 0 {
+		return 0, 0, false
+	}
+	best, bestErrors := -1, d.MaxBitErrors+1
+	for id, c := range d.codes {
+		if errs := bits.OnesCount64(c ^ code); errs < bestErrors {
+			best, bestErrors = id, errs
+		}
+	}
+	return best, bestErrors, best >= 0
+}
+
+// rotate returns a code read from a marker turned a quarter turn clockwise.
+func (d *Dictionary) rotate(code uint64) uint64 {
+	n := d.Bits
+	var rotated uint64
+	for r := 0; r < n; r++ {
+		for c := 0; c < n; c++ {
+			// the cell at row r and column c of the turned marker was at row n-1-c and column r
+			rotated = rotated<<1 | code>>(n*n-1-((n-1-c)*n+r))&1
+		}
+	}
+	return rotated
+}
@@ -0,0 +1,390 @@
+// Package fiducial finds square fiducial markers, such as AprilTags and ArUco markers, in images and estimates their
+// poses relative to the camera which saw them. Markers are found as dark blobs whose outline is a quadrilateral,
+// which are then sampled cell by cell through the homography of their corners and matched against the codes of a
+// dictionary in each of their four rotations.
+package fiducial
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r2"
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	// tileSize is the size of the tiles, in pixels, whose darkest and lightest pixels set the local threshold.
+	tileSize = 4
+	// minContrast is the least difference between dark and light around a pixel, or between the border of a marker
+	// and the white around it, out of 255.
+	minContrast = 20
+	// minCellPixels is the least size of a cell of a marker, in pixels, for it to be read.
+	minCellPixels = 2
+	// minFill is the least fraction of the convex hull of a blob its quadrilateral must cover.
+	minFill = 0.9
+	// minBorder is the least fraction of the border cells of a marker which must be dark.
+	minBorder = 0.9
+)
+
+// A Marker is a marker found in an image.
+type Marker struct {
+	ID int
+	// Corners are the corners of the black square of the marker in pixels, with the center of the top left pixel of
+	// the image at 0, 0. They go clockwise from the top left of the marker as it is printed, whatever its rotation
+	// in the image.
+	Corners [4]r2.Point
+	// BitErrors is how many cells of the marker were read differently from its code.
+	BitErrors int
+}
+
+// BoundingBox is the smallest rectangle of pixels containing the marker.
+func (m Marker) BoundingBox() image.Rectangle {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, c := range m.Corners {
+		minX, minY = math.Min(minX, c.X), math.Min(minY, c.Y)
+		maxX, maxY = math.Max(maxX, c.X), math.Max(maxY, c.Y)
+	}
+	return image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
+}
+
+// grayImage is an image as a grid of luminances from 0 to 255.
+type grayImage struct {
+	width, height int
+	pix           []float64
+}
+
+func newGrayImage(img image.Image) *grayImage {
+	bounds := img.Bounds()
+	g := &grayImage{width: bounds.Dx(), height: bounds.Dy(), pix: make([]float64, bounds.Dx()*bounds.Dy())}
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			g.pix[y*g.width+x] = float64(color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray).Y)
+		}
+	}
+	return g
+}
+
+// at is the luminance at a pixel, clamped to the edge of the image.
+func (g *grayImage) at(x, y int) float64 {
+	x = min(max(x, 0), g.width-1)
+	y = min(max(y, 0), g.height-1)
+	return g.pix[y*g.width+x]
+}
+
+// interpolate is the luminance at a point between pixels.
+func (g *grayImage) interpolate(p r2.Point) float64 {
+	x0, y0 := math.Floor(p.X), math.Floor(p.Y)
+	fx, fy := p.X-x0, p.Y-y0
+	x, y := int(x0), int(y0)
+	return (1-fy)*((1-fx)*g.at(x, y)+fx*g.at(x+1, y)) + fy*((1-fx)*g.at(x, y+1)+fx*g.at(x+1, y+1))
+}
+
+// Detect finds the markers of a dictionary in an image, ordered by id.
+func Detect(img image.Image, dict *Dictionary) []Marker {
+	g := newGrayImage(img)
+	var markers []Marker
+	for _, quad := range findQuads(g, threshold(g)) {
+		if m, ok := decode(g, quad, dict); ok {
+			markers = append(markers, m)
+		}
+	}
+	sort.Slice(markers, func(i, j int) bool {
+		return markers[i].ID < markers[j].ID
+	})
+	return markers
+}
+
+// threshold marks the dark pixels of an image, those darker than halfway between the darkest and lightest pixels
+// near them. Pixels with too little contrast around them are never dark.
+func threshold(g *grayImage) []bool {
+	tw, th := (g.width+tileSize-1)/tileSize, (g.height+tileSize-1)/tileSize
+	tileMin, tileMax := make([]float64, tw*th), make([]float64, tw*th)
+	for i := range tileMin {
+		tileMin[i], tileMax[i] = 255, 0
+	}
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			t, v := (y/tileSize)*tw+x/tileSize, g.pix[y*g.width+x]
+			tileMin[t], tileMax[t] = math.Min(tileMin[t], v), math.Max(tileMax[t], v)
+		}
+	}
+
+	// each tile takes the extremes of the tiles around it too, so that its threshold does not jump at its edges
+	nearMin, nearMax := make([]float64, tw*th), make([]float64, tw*th)
+	for ty := 0; ty < th; ty++ {
+		for tx := 0; tx < tw; tx++ {
+			lo, hi := 255., 0.
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					x, y := tx+dx, ty+dy
+					if x >= 0 && y >= 0 && x < tw && y < th {
+						lo, hi = math.Min(lo, tileMin[y*tw+x]), math.Max(hi, tileMax[y*tw+x])
+					}
+				}
+			}
+			nearMin[ty*tw+tx], nearMax[ty*tw+tx] = lo, hi
+		}
+	}
+
+	dark := make([]bool, len(g.pix))
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			t := (y/tileSize)*tw + x/tileSize
+			if nearMax[t]-nearMin[t] >= minContrast {
+				dark[y*g.width+x] = g.pix[y*g.width+x] < (nearMin[t]+nearMax[t])/2
+			}
+		}
+	}
+	return dark
+}
+
+// findQuads finds the connected blobs of dark pixels whose outlines are quadrilaterals, returning their corners
+// clockwise in the image.
+func findQuads(g *grayImage, dark []bool) [][4]r2.Point {
+	visited := make([]bool, len(dark))
+	var quads [][4]r2.Point
+	var stack []int
+	for start := range dark {
+		if !dark[start] || visited[start] {
+			continue
+		}
+		visited[start] = true
+		stack = append(stack[:0], start)
+		var outline []r2.Point
+		onEdge := false
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			x, y := i%g.width, i/g.width
+			if x == 0 || y == 0 || x == g.width-1 || y == g.height-1 {
+				onEdge = true
+			}
+			boundary := false
+			for _, n := range [4][2]int{{x - 1, y}, {x + 1, y}, {x, y - 1}, {x, y + 1}} {
+				if n[0] < 0 || n[1] < 0 || n[0] >= g.width || n[1] >= g.height {
+					continue
+				}
+				j := n[1]*g.width + n[0]
+				if !dark[j] {
+					boundary = true
+					continue
+				}
+				if !visited[j] {
+					visited[j] = true
+					stack = append(stack, j)
+				}
+			}
+			if boundary {
+				// the outline runs around the edges of pixels, half a pixel from their centers
+				fx, fy := float64(x), float64(y)
+				outline = append(outline, r2.Point{X: fx - 0.5, Y: fy - 0.5}, r2.Point{X: fx + 0.5, Y: fy - 0.5},
+					r2.Point{X: fx - 0.5, Y: fy + 0.5}, r2.Point{X: fx + 0.5, Y: fy + 0.5})
+			}
+		}
+		// markers cut off by the edge of the image cannot be read
+		if onEdge || len(outline) < 16 {
+			continue
+		}
+		if quad, ok := fitQuad(convexHull(outline)); ok {
+			quads = append(quads, quad)
+		}
+	}
+	return quads
+}
+
+// convexHull returns the convex hull of points with Andrew's monotone chain.
+func convexHull(points []r2.Point) []r2.Point {
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].X != points[j].X {
+			return points[i].X < points[j].X
+		}
+		return points[i].Y < points[j].Y
+	})
+	hull := make([]r2.Point, 0, 2*len(points))
+	for pass := 0; pass < 2; pass++ {
+		start := len(hull)
+		for _, p := range points {
+			for len(hull) >= start+2 && hull[len(hull)-1].Sub(hull[len(hull)-2]).Cross(p.Sub(hull[len(hull)-2])) <= 0 {
+				hull = hull[:len(hull)-1]
+			}
+			hull = append(hull, p)
+		}
+		// the last point of each chain starts the other
+		hull = hull[:len(hull)-1]
+		for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+			points[i], points[j] = points[j], points[i]
+		}
+	}
+	return hull
+}
+
+// polygonArea is the area of a polygon, positive when it is clockwise in an image.
+func polygonArea(points []r2.Point) float64 {
+	var area float64
+	for i, p := range points {
+		area += p.Cross(points[(i+1)%len(points)])
+	}
+	return area / 2
+}
+
+// fitQuad finds the quadrilateral of the corners of a convex hull, which is the two points furthest apart and the
+// points furthest from the line between them on either side. It fails if the quadrilateral leaves out much of the
+// hull, as it does for blobs which are not quadrilaterals.
+func fitQuad(hull []r2.Point) ([4]r2.Point, bool) {
+	var quad [4]r2.Point
+	if len(hull) < 4 {
+		return quad, false
+	}
+	var centroid r2.Point
+	for _, p := range hull {
+		centroid = centroid.Add(p)
+	}
+	centroid = centroid.Mul(1 / float64(len(hull)))
+	furthest := func(from r2.Point) r2.Point {
+		best := from
+		for _, p := range hull {
+			if p.Sub(from).Norm() > best.Sub(from).Norm() {
+				best = p
+			}
+		}
+		return best
+	}
+	a := furthest(centroid)
+	c := furthest(a)
+	axis := c.Sub(a)
+	var b, d r2.Point
+	var left, right float64
+	for _, p := range hull {
+		if side := axis.Cross(p.Sub(a)); side > left {
+			b, left = p, side
+		} else if side < right {
+			d, right = p, side
+		}
+	}
+	if left == 0 || right == 0 {
+		return quad, false
+	}
+	quad = [4]r2.Point{a, b, c, d}
+	area := polygonArea(quad[:])
+	if area < 0 {
+		quad[1], quad[3] = quad[3], quad[1]
+		area = -area
+	}
+	if area < minFill*math.Abs(polygonArea(hull)) {
+		return quad, false
+	}
+	return quad, true
+}
+
+// decode reads the cells of the marker a quadrilateral might be, matching it against the dictionary in each of its
+// rotations.
+func decode(g *grayImage, quad [4]r2.Point, dict *Dictionary) (Marker, bool) {
+	cells := dict.Bits + 2
+	for i := range quad {
+		if quad[i].Sub(quad[(i+1)%4]).Norm() < float64(cells*minCellPixels) {
+			return Marker{}, false
+		}
+	}
+	size := float64(cells)
+	h, ok := homography(
+		[4]r2.Point{{X: 0, Y: 0}, {X: size, Y: 0}, {X: size, Y: size}, {X: 0, Y: size}}, quad)
+	if !ok {
+		return Marker{}, false
+	}
+	// each cell is the average of points around its center, away from the blur at its edges
+	sample := func(x, y float64) float64 {
+		var sum float64
+		for dy := -1.; dy <= 1; dy++ {
+			for dx := -1.; dx <= 1; dx++ {
+				sum += g.interpolate(h.apply(r2.Point{X: x + 0.25*dx, Y: y + 0.25*dy}))
+			}
+		}
+		return sum / 9
+	}
+
+	// the border cells are dark, and the cells just outside them white
+	var border []float64
+	var darkSum, lightSum float64
+	for i := 0; i < cells; i++ {
+		c := float64(i) + 0.5
+		for _, p := range [4][4]float64{{c, 0.5, c, -0.5}, {c, size - 0.5, c, size + 0.5}, {0.5, c, -0.5, c}, {size - 0.5, c, size + 0.5, c}} {
+			v := sample(p[0], p[1])
+			border = append(border, v)
+			darkSum += v
+			lightSum += sample(p[2], p[3])
+		}
+	}
+	darkMean, lightMean := darkSum/float64(len(border)), lightSum/float64(len(border))
+	if lightMean-darkMean < minContrast {
+		return Marker{}, false
+	}
+	thresh := (darkMean + lightMean) / 2
+	darkBorder := 0
+	for _, v := range border {
+		if v < thresh {
+			darkBorder++
+		}
+	}
+	if float64(darkBorder) < minBorder*float64(len(border)) {
+		return Marker{}, false
+	}
+
+	var code uint64
+	for r := 0; r < dict.Bits; r++ {
+		for c := 0; c < dict.Bits; c++ {
+			code <<= 1
+			if sample(float64(c)+1.5, float64(r)+1.5) >= thresh {
+				code |= 1
+			}
+		}
+	}
+	best := Marker{BitErrors: -1}
+	for turns := 0; turns < 4; turns++ {
+		if id, errs, ok := dict.match(code); ok && (best.BitErrors < 0 || errs < best.BitErrors) {
+			// turning the cells as read by a quarter turn clockwise moves the corner at the bottom left of the
+			// quadrilateral to the top left of the marker
+			best = Marker{ID: id, BitErrors: errs}
+			for i := range best.Corners {
+				best.Corners[i] = quad[(i+4-turns)%4]
+			}
+		}
+		code = dict.rotate(code)
+	}
+	return best, best.BitErrors >= 0
+}
+
+// projective is a homography between two planes.
+type projective struct {
+	m *mat.Dense
+}
+
+// homography returns the homography which maps four points to four others.
+func homography(from, to [4]r2.Point) (projective, bool) {
+	a := mat.NewDense(8, 8, nil)
+	b := mat.NewVecDense(8, nil)
+	for i := range from {
+		f, t := from[i], to[i]
+		a.SetRow(2*i, []float64{f.X, f.Y, 1, 0, 0, 0, -t.X * f.X, -t.X * f.Y})
+		a.SetRow(2*i+1, []float64{0, 0, 0, f.X, f.Y, 1, -t.Y * f.X, -t.Y * f.Y})
+		b.SetVec(2*i, t.X)
+		b.SetVec(2*i+1, t.Y)
+	}
+	var x mat.VecDense
+	if err := x.SolveVec(a, b); err != nil {
+		return projective{}, false
+	}
+	data := append(mat.Col(nil, 0, &x), 1)
+	return projective{m: mat.NewDense(3, 3, data)}, true
+}
+
+func (p projective) apply(pt r2.Point) r2.Point {
+	m := p.m
+	w := m.At(2, 0)*pt.X + m.At(2, 1)*pt.Y + m.At(2, 2)
+	return r2.Point{
+		X: (m.At(0, 0)*pt.X + m.At(0, 1)*pt.Y + m.At(0, 2)) / w,
+		Y: (m.At(1, 0)*pt.X + m.At(1, 1)*pt.Y + m.At(1, 2)) / w,
+	}
+}
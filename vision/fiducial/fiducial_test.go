@@ -0,0 +1,165 @@
+package fiducial
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// drawMarker draws a marker of a dictionary on a white image with its corners at the given pixels, clockwise from
+// its top left.
+func drawMarker(t *testing.T, img *image.Gray, dict *Dictionary, id int, corners [4]r2.Point) {
+	t.Helper()
+	code, ok := dict.Code(id)
+	test.That(t, ok, test.ShouldBeTrue)
+	cells := float64(dict.Bits + 2)
+	toGrid, ok := homography(corners, [4]r2.Point{{X: 0, Y: 0}, {X: cells, Y: 0}, {X: cells, Y: cells}, {X: 0, Y: cells}})
+	test.That(t, ok, test.ShouldBeTrue)
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			p := toGrid.apply(r2.Point{X: float64(x), Y: float64(y)})
+			if p.X < 0 || p.Y < 0 || p.X >= cells || p.Y >= cells {
+				continue
+			}
+			col, row := int(p.X)-1, int(p.Y)-1
+			white := false
+			if col >= 0 && row >= 0 && col < dict.Bits && row < dict.Bits {
+				white = code>>(dict.Bits*dict.Bits-1-(row*dict.Bits+col))&1 == 1
+			}
+			if white {
+				img.SetGray(x, y, color.Gray{Y: 230})
+			} else {
+				img.SetGray(x, y, color.Gray{Y: 30})
+			}
+		}
+	}
+}
+
+func whiteImage(width, height int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 230
+	}
+	return img
+}
+
+func TestDictionary(t *testing.T) {
+	dict := NewArucoOriginal(0)
+	code, ok := dict.Code(0)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, code, test.ShouldEqual, 0b10000_10000_10000_10000_10000)
+	code, ok = dict.Code(1023)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, code, test.ShouldEqual, 0b01110_01110_01110_01110_01110)
+	_, ok = dict.Code(1024)
+	test.That(t, ok, test.ShouldBeFalse)
+
+	code, _ = dict.Code(300)
+	rotated := code
+	for i := 0; i < 4; i++ {
+		rotated = dict.rotate(rotated)
+	}
+	test.That(t, rotated, test.ShouldEqual, code)
+	id, errs, ok := dict.match(code)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, id, test.ShouldEqual, 300)
+	test.That(t, errs, test.ShouldEqual, 0)
+
+	_, _, ok = dict.match(code ^ 0b100)
+	test.That(t, ok, test.ShouldBeFalse)
+	dict.MaxBitErrors = 1
+	id, errs, ok = dict.match(code ^ 0b100)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, id, test.ShouldEqual, 300)
+	test.That(t, errs, test.ShouldEqual, 1)
+
+	_, err := NewDictionary("tag", 3, []uint64{0x1ff, 0x200}, 0)
+	test.That(t, err, test.ShouldNotBeNil)
+	codes, err := ParseCodes([]string{"0x1ff", "0A"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, codes, test.ShouldResemble, []uint64{0x1ff, 0xa})
+	_, err = ParseCodes([]string{"0xno"})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestDetect(t *testing.T) {
+	dict := NewArucoOriginal(0)
+	img := whiteImage(320, 240)
+	// one marker upright, and another turned a quarter turn clockwise and slightly skewed
+	upright := [4]r2.Point{{X: 30, Y: 40}, {X: 100, Y: 40}, {X: 100, Y: 110}, {X: 30, Y: 110}}
+	turned := [4]r2.Point{{X: 260, Y: 120}, {X: 262, Y: 200}, {X: 180, Y: 196}, {X: 182, Y: 122}}
+	drawMarker(t, img, dict, 123, upright)
+	drawMarker(t, img, dict, 7, turned)
+
+	markers := Detect(img, dict)
+	test.That(t, markers, test.ShouldHaveLength, 2)
+	test.That(t, markers[0].ID, test.ShouldEqual, 7)
+	test.That(t, markers[1].ID, test.ShouldEqual, 123)
+	for i, expected := range [][4]r2.Point{turned, upright} {
+		test.That(t, markers[i].BitErrors, test.ShouldEqual, 0)
+		for j, corner := range markers[i].Corners {
+			test.That(t, corner.Sub(expected[j]).Norm(), test.ShouldBeLessThan, 1.5)
+		}
+	}
+	test.That(t, markers[1].BoundingBox().Dx(), test.ShouldAlmostEqual, 70, 2)
+	test.That(t, markers[1].BoundingBox().Dy(), test.ShouldAlmostEqual, 70, 2)
+
+	// markers of other dictionaries are not found
+	other, err := NewDictionary("other", 5, []uint64{0x1ffffff}, 0)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, Detect(img, other), test.ShouldBeEmpty)
+	test.That(t, Detect(whiteImage(64, 64), dict), test.ShouldBeEmpty)
+}
+
+func TestEstimatePose(t *testing.T) {
+	intrinsics := &transform.PinholeCameraIntrinsics{Width: 640, Height: 480, Fx: 500, Fy: 500, Ppx: 320, Ppy: 240}
+	pose := spatialmath.NewPose(r3.Vector{X: 40, Y: -20, Z: 500},
+		&spatialmath.OrientationVectorDegrees{OX: 0.3, OY: -0.2, OZ: -1, Theta: 20})
+	project := func(p r3.Vector) r2.Point {
+		c := spatialmath.Compose(pose, spatialmath.NewPoseFromPoint(p)).Point()
+		return r2.Point{X: intrinsics.Fx*c.X/c.Z + intrinsics.Ppx, Y: intrinsics.Fy*c.Y/c.Z + intrinsics.Ppy}
+	}
+	var marker Marker
+	for i, c := range MarkerCorners(100) {
+		marker.Corners[i] = project(c)
+	}
+
+	// the pose is exact from exact corners
+	estimated, err := EstimatePose(marker, 100, intrinsics, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.PoseAlmostEqualEps(estimated, pose, 1e-6), test.ShouldBeTrue)
+
+	// and close from the corners of the marker found in an image of it
+	dict := NewArucoOriginal(0)
+	img := whiteImage(640, 480)
+	drawMarker(t, img, dict, 42, marker.Corners)
+	markers := Detect(img, dict)
+	test.That(t, markers, test.ShouldHaveLength, 1)
+	estimated, err = EstimatePose(markers[0], 100, intrinsics, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, estimated.Point().Sub(pose.Point()).Norm(), test.ShouldBeLessThan, 10)
+	test.That(t, spatialmath.QuatToR3AA(spatialmath.OrientationBetween(estimated.Orientation(), pose.Orientation()).Quaternion()).Norm(),
+		test.ShouldBeLessThan, 0.1)
+
+	// distortion is undone before solving
+	distortion := &transform.BrownConrady{RadialK1: 0.1, RadialK2: -0.05}
+	for i, c := range MarkerCorners(100) {
+		p := spatialmath.Compose(pose, spatialmath.NewPoseFromPoint(c)).Point()
+		x, y := distortion.Transform(p.X/p.Z, p.Y/p.Z)
+		marker.Corners[i] = r2.Point{X: intrinsics.Fx*x + intrinsics.Ppx, Y: intrinsics.Fy*y + intrinsics.Ppy}
+	}
+	estimated, err = EstimatePose(marker, 100, intrinsics, distortion)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.PoseAlmostEqualEps(estimated, pose, 1e-4), test.ShouldBeTrue)
+
+	_, err = EstimatePose(marker, 100, nil, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,95 @@
+package fiducial
+
+import (
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// undistortIterations is how many times distortion is undone, each time getting closer to the undistorted point.
+const undistortIterations = 10
+
+// MarkerCorners are the corners of a marker of a size in millimeters in its own frame, in the order of the Corners
+// of a Marker.
+func MarkerCorners(sizeMM float64) [4]r3.Vector {
+	s := sizeMM / 2
+	return [4]r3.Vector{{X: -s, Y: s}, {X: s, Y: s}, {X: s, Y: -s}, {X: -s, Y: -s}}
+}
+
+// EstimatePose returns the pose of a marker in the frame of the camera which saw it, given the length of the sides
+// of its black square in millimeters. The frame of the marker is at its center with x to its right, y to its top
+// and z out of its face, as ArUco has it, and the frame of the camera has x to the right of the image, y down it
+// and z out of the lens. The distortion may be nil.
+func EstimatePose(
+	m Marker, sizeMM float64, intrinsics *transform.PinholeCameraIntrinsics, distortion transform.Distorter,
+) (spatialmath.Pose, error) {
+	if intrinsics == nil {
+		return nil, errors.New("the pose of a marker needs the intrinsics of the camera")
+	}
+	if sizeMM <= 0 {
+		return nil, errors.New("the size of a marker must be positive")
+	}
+	var normalized, plane [4]r2.Point
+	for i, c := range m.Corners {
+		normalized[i] = undistort(r2.Point{X: (c.X - intrinsics.Ppx) / intrinsics.Fx, Y: (c.Y - intrinsics.Ppy) / intrinsics.Fy},
+			distortion)
+	}
+	for i, c := range MarkerCorners(sizeMM) {
+		plane[i] = r2.Point{X: c.X, Y: c.Y}
+	}
+	h, ok := homography(plane, normalized)
+	if !ok {
+		return nil, errors.New("cannot solve for the homography of the marker")
+	}
+
+	// undoing the camera leaves the x and y axes of the marker and its center, up to scale
+	var col [3]r3.Vector
+	for j := range col {
+		col[j] = r3.Vector{X: h.m.At(0, j), Y: h.m.At(1, j), Z: h.m.At(2, j)}
+	}
+	scale := 2 / (col[0].Norm() + col[1].Norm())
+	if col[2].Z < 0 {
+		// the marker is in front of the camera
+		scale = -scale
+	}
+	x, y := col[0].Mul(scale), col[1].Mul(scale)
+	translation := col[2].Mul(scale)
+
+	// the axes are only perpendicular up to noise, so the rotation is the nearest to them
+	axes := mat.NewDense(3, 3, nil)
+	for i, axis := range []r3.Vector{x, y, x.Cross(y).Normalize()} {
+		axes.SetCol(i, []float64{axis.X, axis.Y, axis.Z})
+	}
+	var svd mat.SVD
+	if !svd.Factorize(axes, mat.SVDFull) {
+		return nil, errors.New("cannot solve for the orientation of the marker")
+	}
+	var u, v, rotation mat.Dense
+	svd.UTo(&u)
+	svd.VTo(&v)
+	rotation.Mul(&u, v.T())
+	// a RotationMatrix takes its elements column by column
+	rm, err := spatialmath.NewRotationMatrix(mat.DenseCopyOf(rotation.T()).RawMatrix().Data)
+	if err != nil {
+		return nil, err
+	}
+	return spatialmath.NewPose(translation, rm), nil
+}
+
+// undistort returns the normalized point which the distortion moves to a point, found by undoing the distortion
+// at the current guess over and over.
+func undistort(p r2.Point, distortion transform.Distorter) r2.Point {
+	if distortion == nil {
+		return p
+	}
+	guess := p
+	for i := 0; i < undistortIterations; i++ {
+		x, y := distortion.Transform(guess.X, guess.Y)
+		guess = guess.Add(p.Sub(r2.Point{X: x, Y: y}))
+	}
+	return guess
+}
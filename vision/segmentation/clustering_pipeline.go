@@ -0,0 +1,220 @@
+package segmentation
+
+import (
+	"context"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/camera"
+	pc "go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/vision"
+)
+
+// The clustering algorithms of the clustering pipeline.
+const (
+	// EuclideanClustering groups all the points within the clustering radius of each other.
+	EuclideanClustering = "euclidean"
+	// DBSCANClustering grows clusters only through points with enough neighbors within the clustering radius, so
+	// stray points do not join separate objects, and leaves out the points it cannot reach as noise.
+	DBSCANClustering = "dbscan"
+)
+
+// Defaults of the clustering pipeline.
+const (
+	RANSACIterationsDefault = 2000
+	DBSCANMinPointsDefault  = 4
+)
+
+// ClusteringPipelineConfig specifies the steps of a pipeline segmenting a point cloud into objects. The cloud is
+// downsampled to one point per voxel, then up to MaxPlanes of its largest planes are removed with RANSAC, and what is
+// left is clustered, keeping the clusters of between MinPtsInSegment and MaxPtsInSegment points. Each step is skipped
+// if not configured.
+type ClusteringPipelineConfig struct {
+	resource.TriviallyValidateConfig
+	VoxelSizeMm        float64 `json:"voxel_size_mm,omitempty"`
+	MaxPlanes          int     `json:"max_planes,omitempty"`
+	MinPtsInPlane      int     `json:"min_points_in_plane,omitempty"`
+	MaxDistFromPlane   float64 `json:"max_dist_from_plane_mm,omitempty"`
+	RANSACIterations   int     `json:"ransac_iterations,omitempty"`
+	Algorithm          string  `json:"clustering_algorithm,omitempty"`
+	ClusteringRadiusMm float64 `json:"clustering_radius_mm"`
+	DBSCANMinPts       int     `json:"dbscan_min_points,omitempty"`
+	MinPtsInSegment    int     `json:"min_points_in_segment,omitempty"`
+	MaxPtsInSegment    int     `json:"max_points_in_segment,omitempty"`
+	Label              string  `json:"label,omitempty"`
+}
+
+// CheckValid checks to see in the input values are valid, and fills in the defaults.
+func (cpc *ClusteringPipelineConfig) CheckValid() error {
+	if cpc.VoxelSizeMm < 0 {
+		return errors.Errorf("voxel_size_mm cannot be less than 0, got %v", cpc.VoxelSizeMm)
+	}
+	if cpc.MaxPlanes < 0 {
+		return errors.Errorf("max_planes cannot be less than 0, got %v", cpc.MaxPlanes)
+	}
+	if cpc.MinPtsInPlane == 0 {
+		cpc.MinPtsInPlane = MinPtsInPlaneDefault
+	}
+	if cpc.MinPtsInPlane < 0 {
+		return errors.Errorf("min_points_in_plane must be greater than 0, got %v", cpc.MinPtsInPlane)
+	}
+	if cpc.MaxDistFromPlane == 0 {
+		cpc.MaxDistFromPlane = MaxDistFromPlaneDefault
+	}
+	if cpc.MaxDistFromPlane < 0 {
+		return errors.Errorf("max_dist_from_plane_mm must be greater than 0, got %v", cpc.MaxDistFromPlane)
+	}
+	if cpc.RANSACIterations == 0 {
+		cpc.RANSACIterations = RANSACIterationsDefault
+	}
+	if cpc.RANSACIterations < 0 {
+		return errors.Errorf("ransac_iterations must be greater than 0, got %v", cpc.RANSACIterations)
+	}
+	switch cpc.Algorithm {
+	case "":
+		cpc.Algorithm = EuclideanClustering
+	case EuclideanClustering, DBSCANClustering:
+	default:
+		return errors.Errorf("clustering_algorithm must be %q or %q, got %q", EuclideanClustering, DBSCANClustering, cpc.Algorithm)
+	}
+	if cpc.ClusteringRadiusMm <= 0 {
+		return errors.Errorf("clustering_radius_mm must be greater than 0, got %v", cpc.ClusteringRadiusMm)
+	}
+	if cpc.DBSCANMinPts == 0 {
+		cpc.DBSCANMinPts = DBSCANMinPointsDefault
+	}
+	if cpc.DBSCANMinPts < 0 {
+		return errors.Errorf("dbscan_min_points must be greater than 0, got %v", cpc.DBSCANMinPts)
+	}
+	if cpc.MinPtsInSegment < 0 {
+		return errors.Errorf("min_points_in_segment cannot be less than 0, got %v", cpc.MinPtsInSegment)
+	}
+	if cpc.MaxPtsInSegment < 0 || (cpc.MaxPtsInSegment > 0 && cpc.MaxPtsInSegment < cpc.MinPtsInSegment) {
+		return errors.Errorf("max_points_in_segment must be 0 or at least min_points_in_segment, got %v", cpc.MaxPtsInSegment)
+	}
+	return nil
+}
+
+// ClusteringPipeline segments the next point cloud of the source with the pipeline.
+func (cpc *ClusteringPipelineConfig) ClusteringPipeline(ctx context.Context, src camera.VideoSource) ([]*vision.Object, error) {
+	cloud, err := src.NextPointCloud(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cpc.SegmentPointCloud(ctx, cloud)
+}
+
+// SegmentPointCloud runs the pipeline on a point cloud, returning the objects in it.
+func (cpc *ClusteringPipelineConfig) SegmentPointCloud(ctx context.Context, cloud pc.PointCloud) ([]*vision.Object, error) {
+	var err error
+	if cpc.VoxelSizeMm > 0 {
+		cloud, err = pc.VoxelDownsample(cloud, cpc.VoxelSizeMm)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for i := 0; i < cpc.MaxPlanes; i++ {
+		plane, nonPlane, err := SegmentPlane(ctx, cloud, cpc.RANSACIterations, cpc.MaxDistFromPlane)
+		if err != nil {
+			return nil, err
+		}
+		planeCloud, err := plane.PointCloud()
+		if err != nil {
+			return nil, err
+		}
+		if planeCloud.Size() < cpc.MinPtsInPlane {
+			break
+		}
+		cloud = nonPlane
+	}
+
+	var clusters []pc.PointCloud
+	switch cpc.Algorithm {
+	case DBSCANClustering:
+		clusters, err = DBSCAN(cloud, cpc.ClusteringRadiusMm, cpc.DBSCANMinPts)
+	default:
+		clusters, err = EuclideanClusters(cloud, cpc.ClusteringRadiusMm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	clusters = pc.PrunePointClouds(clusters, cpc.MinPtsInSegment)
+	if cpc.MaxPtsInSegment > 0 {
+		small := make([]pc.PointCloud, 0, len(clusters))
+		for _, cluster := range clusters {
+			if cluster.Size() <= cpc.MaxPtsInSegment {
+				small = append(small, cluster)
+			}
+		}
+		clusters = small
+	}
+	segments, err := NewSegmentsFromSlice(clusters, cpc.Label)
+	if err != nil {
+		return nil, err
+	}
+	return segments.Objects, nil
+}
+
+// EuclideanClusters partitions a point cloud into the clusters of points which reach each other through points
+// within radius of each other, however sparse. It is DBSCAN with every point a core point.
+func EuclideanClusters(cloud pc.PointCloud, radius float64) ([]pc.PointCloud, error) {
+	return DBSCAN(cloud, radius, 1)
+}
+
+// DBSCAN clusters a point cloud with the algorithm of "A Density-Based Algorithm for Discovering Clusters in Large
+// Spatial Databases with Noise" by Ester et al. 1996. Points with at least minPts points within eps of them,
+// themselves included, are core points, and a cluster is all the points within eps of the core points it reaches
+// through each other. The points no cluster reaches are noise, and are left out.
+func DBSCAN(cloud pc.PointCloud, eps float64, minPts int) ([]pc.PointCloud, error) {
+	if eps <= 0 {
+		return nil, errors.Errorf("argument eps must be a positive float, got %.2f", eps)
+	}
+	const noise = -1
+	kdt := pc.ToKDTree(cloud)
+	labels := make(map[r3.Vector]int, kdt.Size())
+	var clusters []pc.PointCloud
+	var err error
+	kdt.Iterate(0, 0, func(v r3.Vector, d pc.Data) bool {
+		if _, ok := labels[v]; ok {
+			return true
+		}
+		neighbors := kdt.RadiusNearestNeighbors(v, eps, true)
+		if len(neighbors) < minPts {
+			labels[v] = noise
+			return true
+		}
+		c := len(clusters)
+		cluster := pc.New()
+		clusters = append(clusters, cluster)
+		labels[v] = c
+		if err = cluster.Set(v, d); err != nil {
+			return false
+		}
+		for len(neighbors) > 0 {
+			neighbor := neighbors[0]
+			neighbors = neighbors[1:]
+			label, ok := labels[neighbor.P]
+			if ok && label != noise {
+				continue
+			}
+			labels[neighbor.P] = c
+			if err = cluster.Set(neighbor.P, neighbor.D); err != nil {
+				return false
+			}
+			// a point first seen as noise is on the border of the cluster, not a core point to grow it from
+			if ok {
+				continue
+			}
+			if next := kdt.RadiusNearestNeighbors(neighbor.P, eps, true); len(next) >= minPts {
+				neighbors = append(neighbors, next...)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clusters, nil
+}
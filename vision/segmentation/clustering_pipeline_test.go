@@ -0,0 +1,128 @@
+package segmentation_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	pc "go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/vision/segmentation"
+)
+
+func TestClusteringPipelineValidate(t *testing.T) {
+	cfg := segmentation.ClusteringPipelineConfig{}
+	err := cfg.CheckValid()
+	test.That(t, err.Error(), test.ShouldContainSubstring, "clustering_radius_mm must be greater than 0")
+
+	cfg.ClusteringRadiusMm = 10
+	cfg.Algorithm = "kmeans"
+	err = cfg.CheckValid()
+	test.That(t, err.Error(), test.ShouldContainSubstring, "clustering_algorithm must be")
+
+	cfg.Algorithm = ""
+	cfg.MinPtsInSegment = 10
+	cfg.MaxPtsInSegment = 5
+	err = cfg.CheckValid()
+	test.That(t, err.Error(), test.ShouldContainSubstring, "max_points_in_segment")
+
+	// valid, with the defaults filled in
+	cfg.MaxPtsInSegment = 0
+	err = cfg.CheckValid()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cfg.Algorithm, test.ShouldEqual, segmentation.EuclideanClustering)
+	test.That(t, cfg.RANSACIterations, test.ShouldEqual, segmentation.RANSACIterationsDefault)
+	test.That(t, cfg.DBSCANMinPts, test.ShouldEqual, segmentation.DBSCANMinPointsDefault)
+}
+
+// makeScene makes a floor of 400 points with two cubes of 27 points on it, joined by a sparse line of points, and a
+// stray point.
+func makeScene(t *testing.T) pc.PointCloud {
+	t.Helper()
+	cloud := pc.New()
+	for x := 0.; x < 200; x += 10 {
+		for y := 0.; y < 200; y += 10 {
+			test.That(t, cloud.Set(r3.Vector{X: x, Y: y}, nil), test.ShouldBeNil)
+		}
+	}
+	for _, corner := range []r3.Vector{{X: 20, Y: 20, Z: 50}, {X: 120, Y: 20, Z: 50}} {
+		for i := 0.; i < 3; i++ {
+			for j := 0.; j < 3; j++ {
+				for k := 0.; k < 3; k++ {
+					test.That(t, cloud.Set(corner.Add(r3.Vector{X: 5 * i, Y: 5 * j, Z: 5 * k}), nil), test.ShouldBeNil)
+				}
+			}
+		}
+	}
+	for x := 39.; x < 120; x += 9 {
+		test.That(t, cloud.Set(r3.Vector{X: x, Y: 20, Z: 50}, nil), test.ShouldBeNil)
+	}
+	test.That(t, cloud.Set(r3.Vector{X: 100, Y: 150, Z: 150}, nil), test.ShouldBeNil)
+	return cloud
+}
+
+func TestClusteringPipeline(t *testing.T) {
+	cloud := makeScene(t)
+	cam := &inject.Camera{}
+	cam.NextPointCloudFunc = func(ctx context.Context) (pc.PointCloud, error) {
+		return cloud, nil
+	}
+	cfg := segmentation.ClusteringPipelineConfig{
+		MaxPlanes:          1,
+		MinPtsInPlane:      100,
+		MaxDistFromPlane:   2,
+		ClusteringRadiusMm: 10,
+		MinPtsInSegment:    5,
+		Label:              "obstacle",
+	}
+	test.That(t, cfg.CheckValid(), test.ShouldBeNil)
+
+	// the line joins the cubes in one cluster, and the stray point is pruned
+	objects, err := cfg.ClusteringPipeline(context.Background(), cam)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(objects), test.ShouldEqual, 1)
+	test.That(t, objects[0].Size(), test.ShouldEqual, 2*27+9)
+	test.That(t, objects[0].Geometry.Label(), test.ShouldEqual, "obstacle")
+
+	// the line is too sparse for dbscan to grow through it
+	cfg.Algorithm = segmentation.DBSCANClustering
+	objects, err = cfg.SegmentPointCloud(context.Background(), cloud)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(objects), test.ShouldEqual, 2)
+	for _, obj := range objects {
+		// each cube and the point of the line next to it
+		test.That(t, obj.Size(), test.ShouldEqual, 28)
+	}
+
+	// the cubes are too big
+	cfg.MaxPtsInSegment = 20
+	objects, err = cfg.SegmentPointCloud(context.Background(), cloud)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(objects), test.ShouldEqual, 0)
+
+	// downsampled to one point per cube
+	cfg.MaxPtsInSegment = 0
+	cfg.MinPtsInSegment = 1
+	cfg.DBSCANMinPts = 1
+	cfg.VoxelSizeMm = 30
+	objects, err = cfg.SegmentPointCloud(context.Background(), cloud)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(objects), test.ShouldBeGreaterThan, 2)
+}
+
+func TestDBSCAN(t *testing.T) {
+	_, err := segmentation.DBSCAN(pc.New(), 0, 4)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	clusters, err := segmentation.DBSCAN(makeScene(t), 10, 4)
+	test.That(t, err, test.ShouldBeNil)
+	// the floor, the two cubes, and the noise left out
+	test.That(t, len(clusters), test.ShouldEqual, 3)
+	total := 0
+	for _, cluster := range clusters {
+		total += cluster.Size()
+	}
+	test.That(t, total, test.ShouldBeLessThan, 400+2*27+9+1)
+}
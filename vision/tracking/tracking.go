@@ -0,0 +1,275 @@
+// Package tracking follows objects across the frames of a camera, giving each a persistent track from the stateless
+// detections of a detector. Detections are matched to tracks by the overlap of their bounding boxes with where the
+// tracks are predicted to be, which a constant velocity Kalman filter of each track estimates.
+package tracking
+
+import (
+	"image"
+	"math"
+	"sort"
+	"time"
+
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+// Defaults of the Config of a Tracker.
+const (
+	DefaultIoUThreshold    = 0.3
+	DefaultMinHits         = 3
+	DefaultMaxMissedFrames = 10
+)
+
+// The noise of the Kalman filters of tracks, in pixels.
+const (
+	// measurementVariance is the variance of the bounding boxes of detections.
+	measurementVariance = 16.
+	// initialVelocityVariance is the variance of the velocity of a new track, in pixels per second.
+	initialVelocityVariance = 200. * 200.
+	// accelerationVariance is the variance of the changes of velocity of tracks, in pixels per second squared.
+	accelerationVariance = 400. * 400.
+)
+
+// Config configures a Tracker.
+type Config struct {
+	// IoUThreshold is the least intersection over union between a detection and the predicted bounding box of a
+	// track for the detection to continue the track.
+	IoUThreshold float64
+	// MinHits is how many frames a track must be detected in before it is reported, filtering out spurious detections.
+	MinHits int
+	// MaxMissedFrames is how many frames in a row a track can go undetected, such as while it is occluded, before
+	// it is dropped.
+	MaxMissedFrames int
+}
+
+// A Track is an object followed across frames.
+type Track struct {
+	// ID identifies the track for as long as it lasts. IDs are never reused by a tracker.
+	ID    int
+	Label string
+	// Score is the score of the last detection of the track.
+	Score float64
+	// BoundingBox is where the track is estimated to be, which is predicted from its velocity when it was not
+	// detected in the latest frame.
+	BoundingBox image.Rectangle
+	// VelocityX and VelocityY are the velocity of the center of the track in pixels per second.
+	VelocityX, VelocityY float64
+	// Age is how long ago the track was first detected.
+	Age time.Duration
+	// Hits is how many frames the track was detected in.
+	Hits int
+	// MissedFrames is how many frames in a row the track has not been detected in, zero if it was detected in the
+	// latest frame.
+	MissedFrames int
+}
+
+// Detection returns the track as a detection.
+func (t Track) Detection() objectdetection.Detection {
+	return objectdetection.NewDetection(t.BoundingBox, t.Score, t.Label)
+}
+
+// A Tracker follows the objects detected in the frames of one camera. It is not safe for concurrent use.
+type Tracker struct {
+	conf       Config
+	tracks     []*track
+	nextID     int
+	lastUpdate time.Time
+}
+
+// NewTracker returns a tracker, using the defaults for the fields of conf which are zero.
+func NewTracker(conf Config) *Tracker {
+	if conf.IoUThreshold == 0 {
+		conf.IoUThreshold = DefaultIoUThreshold
+	}
+	if conf.MinHits == 0 {
+		conf.MinHits = DefaultMinHits
+	}
+	if conf.MaxMissedFrames == 0 {
+		conf.MaxMissedFrames = DefaultMaxMissedFrames
+	}
+	return &Tracker{conf: conf, nextID: 1}
+}
+
+// Update matches the detections of a frame captured at the given time to the tracks, starting new tracks for the
+// detections which match none, and returns the confirmed tracks, those detected in at least MinHits frames, sorted
+// by ID. Tracks which were not detected in this frame are included until they are dropped.
+func (tr *Tracker) Update(detections []objectdetection.Detection, at time.Time) []Track {
+	dt := 0.
+	if !tr.lastUpdate.IsZero() {
+		dt = at.Sub(tr.lastUpdate).Seconds()
+	}
+	tr.lastUpdate = at
+	for _, t := range tr.tracks {
+		t.predict(dt)
+	}
+
+	type match struct {
+		track, detection int
+		iou              float64
+	}
+	var candidates []match
+	for i, t := range tr.tracks {
+		predicted := t.boundingBox()
+		for j, d := range detections {
+			if d.Label() != t.label {
+				continue
+			}
+			if iou := IoU(predicted, *d.BoundingBox()); iou >= tr.conf.IoUThreshold {
+				candidates = append(candidates, match{i, j, iou})
+			}
+		}
+	}
+	// greedily take the best overlaps first
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].iou > candidates[j].iou })
+	matchedTracks := make([]bool, len(tr.tracks))
+	matchedDetections := make([]bool, len(detections))
+	for _, m := range candidates {
+		if matchedTracks[m.track] || matchedDetections[m.detection] {
+			continue
+		}
+		matchedTracks[m.track] = true
+		matchedDetections[m.detection] = true
+		tr.tracks[m.track].update(detections[m.detection])
+	}
+
+	kept := tr.tracks[:0]
+	for i, t := range tr.tracks {
+		if !matchedTracks[i] {
+			t.missed++
+		}
+		if t.missed <= tr.conf.MaxMissedFrames {
+			kept = append(kept, t)
+		}
+	}
+	tr.tracks = kept
+	for j, d := range detections {
+		if !matchedDetections[j] {
+			tr.tracks = append(tr.tracks, newTrack(tr.nextID, d, at))
+			tr.nextID++
+		}
+	}
+
+	tracks := make([]Track, 0, len(tr.tracks))
+	for _, t := range tr.tracks {
+		if t.hits < tr.conf.MinHits {
+			continue
+		}
+		vx, vy := t.velocity()
+		tracks = append(tracks, Track{
+			ID:           t.id,
+			Label:        t.label,
+			Score:        t.score,
+			BoundingBox:  t.boundingBox(),
+			VelocityX:    vx,
+			VelocityY:    vy,
+			Age:          at.Sub(t.start),
+			Hits:         t.hits,
+			MissedFrames: t.missed,
+		})
+	}
+	sort.Slice(tracks, func(i, j int) bool { return tracks[i].ID < tracks[j].ID })
+	return tracks
+}
+
+// IoU returns the intersection over union of two rectangles.
+func IoU(a, b image.Rectangle) float64 {
+	inter := a.Intersect(b)
+	if inter.Empty() {
+		return 0
+	}
+	interArea := float64(inter.Dx() * inter.Dy())
+	union := float64(a.Dx()*a.Dy()+b.Dx()*b.Dy()) - interArea
+	if union <= 0 {
+		return 0
+	}
+	return interArea / union
+}
+
+type track struct {
+	id     int
+	label  string
+	score  float64
+	start  time.Time
+	hits   int
+	missed int
+	// the center, width and height of the bounding box
+	cx, cy, w, h kalman
+}
+
+func newTrack(id int, d objectdetection.Detection, at time.Time) *track {
+	box := d.BoundingBox()
+	return &track{
+		id:    id,
+		label: d.Label(),
+		score: d.Score(),
+		start: at,
+		hits:  1,
+		cx:    newKalman(float64(box.Min.X+box.Max.X) / 2),
+		cy:    newKalman(float64(box.Min.Y+box.Max.Y) / 2),
+		w:     newKalman(float64(box.Dx())),
+		h:     newKalman(float64(box.Dy())),
+	}
+}
+
+func (t *track) predict(dt float64) {
+	t.cx.predict(dt)
+	t.cy.predict(dt)
+	t.w.predict(dt)
+	t.h.predict(dt)
+}
+
+func (t *track) update(d objectdetection.Detection) {
+	box := d.BoundingBox()
+	t.cx.update(float64(box.Min.X+box.Max.X) / 2)
+	t.cy.update(float64(box.Min.Y+box.Max.Y) / 2)
+	t.w.update(float64(box.Dx()))
+	t.h.update(float64(box.Dy()))
+	t.score = d.Score()
+	t.hits++
+	t.missed = 0
+}
+
+func (t *track) boundingBox() image.Rectangle {
+	w, h := math.Max(t.w.position, 1), math.Max(t.h.position, 1)
+	return image.Rect(
+		int(math.Round(t.cx.position-w/2)), int(math.Round(t.cy.position-h/2)),
+		int(math.Round(t.cx.position+w/2)), int(math.Round(t.cy.position+h/2)),
+	)
+}
+
+func (t *track) velocity() (float64, float64) {
+	return t.cx.velocity, t.cy.velocity
+}
+
+// kalman is a constant velocity Kalman filter of one coordinate, whose changes of velocity are white noise.
+type kalman struct {
+	position, velocity float64
+	// the covariance of the position and velocity
+	pp, pv, vv float64
+}
+
+func newKalman(position float64) kalman {
+	return kalman{position: position, pp: measurementVariance, vv: initialVelocityVariance}
+}
+
+func (k *kalman) predict(dt float64) {
+	if dt <= 0 {
+		return
+	}
+	k.position += k.velocity * dt
+	dt2 := dt * dt
+	k.pp += 2*dt*k.pv + dt2*k.vv + accelerationVariance*dt2*dt2/4
+	k.pv += dt*k.vv + accelerationVariance*dt2*dt/2
+	k.vv += accelerationVariance * dt2
+}
+
+func (k *kalman) update(measurement float64) {
+	innovation := measurement - k.position
+	s := k.pp + measurementVariance
+	kp, kv := k.pp/s, k.pv/s
+	k.position += kp * innovation
+	k.velocity += kv * innovation
+	pp, pv, vv := k.pp, k.pv, k.vv
+	k.pp = (1 - kp) * pp
+	k.pv = (1 - kp) * pv
+	k.vv = vv - kv*pv
+}
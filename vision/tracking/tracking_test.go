@@ -0,0 +1,73 @@
+package tracking
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+func TestIoU(t *testing.T) {
+	a := image.Rect(0, 0, 10, 10)
+	test.That(t, IoU(a, a), test.ShouldEqual, 1)
+	test.That(t, IoU(a, image.Rect(5, 0, 15, 10)), test.ShouldAlmostEqual, 1./3)
+	test.That(t, IoU(a, image.Rect(20, 20, 30, 30)), test.ShouldEqual, 0)
+}
+
+func TestTracker(t *testing.T) {
+	tr := NewTracker(Config{MinHits: 2, MaxMissedFrames: 2})
+	start := time.Now()
+	frame := func(i int) time.Time {
+		return start.Add(time.Duration(i) * 100 * time.Millisecond)
+	}
+	// a person moving right at 50 pixels per second, and a dog which stays put
+	person := func(i int) objectdetection.Detection {
+		return objectdetection.NewDetection(image.Rect(100+5*i, 100, 140+5*i, 200), 0.9, "person")
+	}
+	dog := objectdetection.NewDetection(image.Rect(300, 300, 360, 340), 0.8, "dog")
+
+	// tracks are only reported once confirmed
+	tracks := tr.Update([]objectdetection.Detection{person(0), dog}, frame(0))
+	test.That(t, tracks, test.ShouldBeEmpty)
+
+	for i := 1; i < 20; i++ {
+		tracks = tr.Update([]objectdetection.Detection{person(i), dog}, frame(i))
+		test.That(t, tracks, test.ShouldHaveLength, 2)
+		test.That(t, tracks[0].ID, test.ShouldEqual, 1)
+		test.That(t, tracks[0].Label, test.ShouldEqual, "person")
+		test.That(t, tracks[1].ID, test.ShouldEqual, 2)
+		test.That(t, tracks[1].Label, test.ShouldEqual, "dog")
+	}
+	test.That(t, tracks[0].Hits, test.ShouldEqual, 20)
+	test.That(t, tracks[0].Age, test.ShouldEqual, 19*100*time.Millisecond)
+	test.That(t, tracks[0].VelocityX, test.ShouldAlmostEqual, 50, 5)
+	test.That(t, tracks[0].VelocityY, test.ShouldAlmostEqual, 0, 1)
+	test.That(t, tracks[1].VelocityX, test.ShouldAlmostEqual, 0, 1)
+	test.That(t, IoU(tracks[0].BoundingBox, *person(19).BoundingBox()), test.ShouldBeGreaterThan, 0.9)
+
+	// while the person is occluded, their track coasts along its velocity
+	tracks = tr.Update([]objectdetection.Detection{dog}, frame(20))
+	test.That(t, tracks, test.ShouldHaveLength, 2)
+	test.That(t, tracks[0].MissedFrames, test.ShouldEqual, 1)
+	test.That(t, tracks[0].BoundingBox.Min.X, test.ShouldBeGreaterThan, person(19).BoundingBox().Min.X)
+	test.That(t, tracks[1].MissedFrames, test.ShouldEqual, 0)
+
+	// and is picked up again when they reappear further along
+	tracks = tr.Update([]objectdetection.Detection{person(21), dog}, frame(21))
+	test.That(t, tracks, test.ShouldHaveLength, 2)
+	test.That(t, tracks[0].ID, test.ShouldEqual, 1)
+	test.That(t, tracks[0].MissedFrames, test.ShouldEqual, 0)
+
+	// a detection with another label does not continue a track
+	cat := objectdetection.NewDetection(*dog.BoundingBox(), 0.8, "cat")
+	for i := 22; i < 25; i++ {
+		tracks = tr.Update([]objectdetection.Detection{person(i), cat}, frame(i))
+	}
+	test.That(t, tracks, test.ShouldHaveLength, 2)
+	test.That(t, tracks[0].ID, test.ShouldEqual, 1)
+	test.That(t, tracks[1].ID, test.ShouldEqual, 3)
+	test.That(t, tracks[1].Label, test.ShouldEqual, "cat")
+}
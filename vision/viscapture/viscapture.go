@@ -4,6 +4,7 @@ package viscapture
 import (
 	"image"
 
+	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/vision"
 	"go.viam.com/rdk/vision/classification"
 	"go.viam.com/rdk/vision/objectdetection"
@@ -15,6 +16,10 @@ type VisCapture struct {
 	Detections      []objectdetection.Detection
 	Classifications classification.Classifications
 	Objects         []*vision.Object
+	// Extra holds anything else a vision service captures, such as the tracks of a tracker.
+	Extra map[string]interface{}
+	// Metadata is the metadata of the capture of the image, such as when it was captured.
+	Metadata resource.ResponseMetadata
 }
 
 // CaptureOptions is a struct to configure CaptureAllFromCamera request.s.
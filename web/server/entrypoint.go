@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"os/signal"
 	"path"
 	"path/filepath"
 	"runtime"
@@ -50,8 +51,15 @@ type Arguments struct {
 	OutputTelemetry            bool   `flag:"output-telemetry,usage=print out telemetry data (metrics and spans)"`
 	DisableMulticastDNS        bool   `flag:"disable-mdns,usage=disable server discovery through multicast DNS"`
 	DumpResourcesPath          string `flag:"dump-resources,usage=dump all resource registrations as json to the provided file path"`
+	VideoCodec                 string `flag:"video-codec,default=h264,usage=codec to stream video with (h264 or h265)"`
 }
 
+// The codecs video can be streamed with.
+const (
+	videoCodecH264 = "h264"
+	videoCodecH265 = "h265"
+)
+
 type robotServer struct {
 	args   Arguments
 	logger logging.Logger
@@ -70,6 +78,10 @@ func RunServer(ctx context.Context, args []string, _ logging.Logger) (err error)
 		return err
 	}
 
+	if argsParsed.VideoCodec != videoCodecH264 && argsParsed.VideoCodec != videoCodecH265 {
+		return errors.Errorf("video-codec must be %q or %q, not %q", videoCodecH264, videoCodecH265, argsParsed.VideoCodec)
+	}
+
 	if argsParsed.DumpResourcesPath != "" {
 		return dumpResourceRegistrations(argsParsed.DumpResourcesPath)
 	}
@@ -291,6 +303,21 @@ func (s *robotServer) serveWeb(ctx context.Context, cfg *config.Config) (err err
 	defer cancel()
 	ctx = rpc.ContextWithDialer(ctx, rpcDialer)
 
+	// the power about to be lost shuts the server down, so the robot parks its actuators while it still can
+	if len(powerLossSignals) != 0 {
+		powerLoss := make(chan os.Signal, 1)
+		signal.Notify(powerLoss, powerLossSignals...)
+		defer signal.Stop(powerLoss)
+		utils.PanicCapturingGo(func() {
+			select {
+			case <-ctx.Done():
+			case sig := <-powerLoss:
+				s.logger.Warnw("power loss signaled; shutting down", "signal", sig.String())
+				cancel()
+			}
+		})
+	}
+
 	processConfig := func(in *config.Config) (*config.Config, error) {
 		tlsCfg := config.NewTLSConfig(cfg)
 		out, err := config.ProcessConfig(in, tlsCfg)
@@ -354,7 +381,7 @@ func (s *robotServer) serveWeb(ctx context.Context, cfg *config.Config) (err err
 		})
 	}
 
-	robotOptions := createRobotOptions()
+	robotOptions := createRobotOptions(s.args.VideoCodec, s.logger)
 	if s.args.RevealSensitiveConfigDiffs {
 		robotOptions = append(robotOptions, robotimpl.WithRevealSensitiveConfigDiffs())
 	}
@@ -3,10 +3,11 @@
 package server
 
 import (
+	"go.viam.com/rdk/logging"
 	robotimpl "go.viam.com/rdk/robot/impl"
 	"go.viam.com/rdk/robot/web"
 )
 
-func createRobotOptions() []robotimpl.Option {
-	return []robotimpl.Option{robotimpl.WithWebOptions(web.WithStreamConfig(makeStreamConfig()))}
+func createRobotOptions(videoCodec string, logger logging.Logger) []robotimpl.Option {
+	return []robotimpl.Option{robotimpl.WithWebOptions(web.WithStreamConfig(makeStreamConfig(videoCodec, logger)))}
 }
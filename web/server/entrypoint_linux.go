@@ -4,13 +4,25 @@ package server
 
 import (
 	"go.viam.com/rdk/gostream"
+	"go.viam.com/rdk/gostream/codec/h265"
 	"go.viam.com/rdk/gostream/codec/opus"
 	"go.viam.com/rdk/gostream/codec/x264"
+	"go.viam.com/rdk/logging"
 )
 
-func makeStreamConfig() gostream.StreamConfig {
+// makeStreamConfig streams video with the requested codec. H.265 falls back to H.264 when there is no H.265
+// encoder on this machine, or for peers which cannot decode it.
+func makeStreamConfig(videoCodec string, logger logging.Logger) gostream.StreamConfig {
 	var streamConfig gostream.StreamConfig
 	streamConfig.AudioEncoderFactory = opus.NewEncoderFactory()
 	streamConfig.VideoEncoderFactory = x264.NewEncoderFactory()
+	if videoCodec == videoCodecH265 {
+		if h265.Available() {
+			streamConfig.VideoEncoderFactory = h265.NewEncoderFactory()
+			streamConfig.FallbackVideoEncoderFactory = x264.NewEncoderFactory()
+		} else {
+			logger.Warnw("no H.265 encoder found, using H.264", "encoders", h265.Encoders)
+		}
+	}
 	return streamConfig
 }
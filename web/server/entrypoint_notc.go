@@ -3,9 +3,10 @@
 package server
 
 import (
+	"go.viam.com/rdk/logging"
 	robotimpl "go.viam.com/rdk/robot/impl"
 )
 
-func createRobotOptions() []robotimpl.Option {
+func createRobotOptions(_ string, _ logging.Logger) []robotimpl.Option {
 	return []robotimpl.Option{}
 }
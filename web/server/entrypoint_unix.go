@@ -6,9 +6,14 @@ import (
 	"go.viam.com/rdk/gostream"
 	"go.viam.com/rdk/gostream/codec/opus"
 	"go.viam.com/rdk/gostream/codec/x264"
+	"go.viam.com/rdk/logging"
 )
 
-func makeStreamConfig() gostream.StreamConfig {
+// makeStreamConfig streams video as H.264, the only codec there is an encoder for here.
+func makeStreamConfig(videoCodec string, logger logging.Logger) gostream.StreamConfig {
+	if videoCodec != videoCodecH264 {
+		logger.Warnw("video codec is not supported on this platform, using H.264", "codec", videoCodec)
+	}
 	var streamConfig gostream.StreamConfig
 	streamConfig.AudioEncoderFactory = opus.NewEncoderFactory()
 	streamConfig.VideoEncoderFactory = x264.NewEncoderFactory()
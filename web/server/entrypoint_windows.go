@@ -5,9 +5,11 @@ package server
 import (
 	"go.viam.com/rdk/gostream"
 	"go.viam.com/rdk/gostream/codec/opus"
+	"go.viam.com/rdk/logging"
 )
 
-func makeStreamConfig() gostream.StreamConfig {
+// makeStreamConfig streams no video, since there is no video encoder here.
+func makeStreamConfig(_ string, _ logging.Logger) gostream.StreamConfig {
 	var streamConfig gostream.StreamConfig
 	// TODO(RSDK-1771): support video on windows
 	streamConfig.AudioEncoderFactory = opus.NewEncoderFactory()
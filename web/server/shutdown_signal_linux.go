@@ -0,0 +1,9 @@
+package server
+
+import (
+	"os"
+	"syscall"
+)
+
+// powerLossSignals are sent, such as by a UPS daemon, when the power is about to be lost.
+var powerLossSignals = []os.Signal{syscall.SIGPWR}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package server
+
+import "os"
+
+// powerLossSignals are sent when the power is about to be lost. There are none here.
+var powerLossSignals []os.Signal